@@ -0,0 +1,162 @@
+// Command promote moves screen changes along the dev, staging, prod chain.
+// It exports the signed bundle of the environment before the target and
+// diffs it against the target, or imports it there with -apply:
+//
+//	promote staging         # what dev would change in staging
+//	promote -apply prod     # import staging's bundle into prod
+//	promote                 # diff every step of the chain
+//
+// Each environment's base URL and admin bearer token are read from
+// PESTGENIE_<ENV>_URL and PESTGENIE_<ENV>_TOKEN, such as PESTGENIE_DEV_URL.
+// Imports are applied all or nothing by the target.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/promotion"
+)
+
+// chain is the order environments are promoted in.
+var chain = []string{"dev", "staging", "prod"}
+
+type environment struct {
+	name, url, token string
+}
+
+func lookup(name string) environment {
+	prefix := "PESTGENIE_" + strings.ToUpper(name)
+	env := environment{name: name, url: strings.TrimRight(os.Getenv(prefix+"_URL"), "/"), token: os.Getenv(prefix + "_TOKEN")}
+	if env.url == "" {
+		log.Fatalf("promote: %s_URL is not set", prefix)
+	}
+	return env
+}
+
+func main() {
+	apply := flag.Bool("apply", false, "import the bundle instead of diffing it")
+	bundlePath := flag.String("bundle", "", "also write the exported bundle to this file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: promote [flags] [%s]\n", strings.Join(chain[1:], "|"))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 || (*apply && flag.NArg() == 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	steps := chain[1:]
+	if flag.NArg() == 1 {
+		steps = nil
+		for _, name := range chain[1:] {
+			if name == flag.Arg(0) {
+				steps = []string{name}
+			}
+		}
+		if steps == nil {
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	for _, target := range steps {
+		from, to := lookup(previous(target)), lookup(target)
+		bundle, err := call(client, http.MethodGet, from.url+"/v1/admin/promotion/bundle", from.token, nil)
+		if err != nil {
+			log.Fatalf("promote: export %s: %v", from.name, err)
+		}
+		if *bundlePath != "" {
+			if err := os.WriteFile(*bundlePath, bundle, 0o600); err != nil {
+				log.Fatalf("promote: %v", err)
+			}
+		}
+		action := "diff"
+		if *apply {
+			action = "import"
+		}
+		body, err := call(client, http.MethodPost, to.url+"/v1/admin/promotion/"+action, to.token, bundle)
+		if err != nil {
+			log.Fatalf("promote: %s %s into %s: %v", action, from.name, to.name, err)
+		}
+		var diff promotion.Diff
+		if err := json.Unmarshal(body, &diff); err != nil {
+			log.Fatalf("promote: decode diff: %v", err)
+		}
+		report(from.name, to.name, diff)
+	}
+}
+
+func previous(target string) string {
+	for i, name := range chain {
+		if name == target {
+			return chain[i-1]
+		}
+	}
+	return ""
+}
+
+func call(client *http.Client, method, url, token string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		if len(data) > 1<<10 {
+			data = data[:1<<10]
+		}
+		return nil, fmt.Errorf("server responded %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func report(from, to string, diff promotion.Diff) {
+	state := "to apply"
+	if diff.Applied {
+		state = "applied"
+	}
+	fmt.Printf("%s -> %s: %d changes %s, %d unchanged\n", from, to, len(diff.Changes), state, diff.Unchanged)
+	for _, c := range diff.Changes {
+		manual := ""
+		if c.Manual {
+			manual = " (manual)"
+		}
+		fmt.Printf("  %s %s %s%s", c.Action, c.Kind, c.ID, manual)
+		if c.Detail != "" {
+			fmt.Printf(": %s", c.Detail)
+		}
+		fmt.Println()
+	}
+	for _, c := range diff.Kept {
+		fmt.Printf("  only in %s: %s %s\n", to, c.Kind, c.ID)
+	}
+}
@@ -10,11 +10,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/your-org/pestgenie-sdui/domain/repository"
 	"github.com/your-org/pestgenie-sdui/internal/app"
+	"github.com/your-org/pestgenie-sdui/internal/chaos"
 	"github.com/your-org/pestgenie-sdui/internal/config"
-	"github.com/your-org/pestgenie-sdui/internal/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/replay"
 	"github.com/your-org/pestgenie-sdui/internal/secret"
+	storefirestore "github.com/your-org/pestgenie-sdui/internal/store/firestore"
 	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+	storepostgres "github.com/your-org/pestgenie-sdui/internal/store/postgres"
+	"github.com/your-org/pestgenie-sdui/internal/tracing"
 )
 
 func main() {
@@ -39,23 +46,34 @@ func main() {
 		provider = secret.EnvProvider{}
 	}
 
+	var cached *secret.CachedProvider
 	if cfg.Secrets.CacheTTL > 0 {
-		provider = secret.NewCachedProvider(provider, cfg.Secrets.CacheTTL)
+		cached = secret.NewCachedProvider(provider, cfg.Secrets.CacheTTL, secret.WithRefreshAhead(cfg.Secrets.RefreshAhead))
+		cached.OnChange(func(name, _ string) {
+			logger.Info("secret rotated", slog.String("secret", name))
+		})
+		provider = cached
 	}
 
-	_ = provider // TODO: inject into services when secret-backed dependencies are added
+	repos, stores, err := newRepositories(cfg.Datastore)
+	if err != nil {
+		log.Fatalf("failed to initialise datastore: %v", err)
+	}
 
-	// Repositories (in-memory for now)
-	store := storememory.NewStore()
-	repos := repository.Repository{
-		Technicians: store,
-		Routes:      store,
-		Screens:     store,
-		Sync:        store,
-		Devices:     store,
+	tracer := tracing.New(cfg.Telemetry, logger)
+	if tracer == nil && cfg.Telemetry.EnableTracing {
+		logger.Warn("tracing enabled but TELEMETRY_OTLP_ENDPOINT is not set; traces are not recorded")
 	}
 
-	srv := app.NewServer(cfg, repos, logger)
+	faults := chaos.New(cfg.Chaos)
+	if faults != nil {
+		logger.Warn("chaos fault injection enabled", slog.Bool("config", cfg.Chaos.Enabled), slog.Bool("header", cfg.Chaos.AllowHeader))
+		// Integration clients leave their transport unset, so wrapping the
+		// default one reaches them all.
+		http.DefaultTransport = faults.Transport(http.DefaultTransport)
+	}
+
+	srv := app.NewServer(cfg, repos, stores, provider, tracer, faults, logger)
 
 	server := &http.Server{
 		Addr:              ":" + cfg.Server.Port,
@@ -66,6 +84,19 @@ func main() {
 		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	go srv.Run(bgCtx)
+	go tracer.Run(bgCtx)
+	go cached.Run(bgCtx)
+
+	// Long local sessions keep the memory store across restarts.
+	snapshot, _ := repos.Technicians.(*storememory.Store)
+	if snapshot != nil && cfg.Datastore.MemorySnapshotPath != "" {
+		go snapshot.Persist(bgCtx, cfg.Datastore.MemorySnapshotPath, cfg.Datastore.MemorySnapshotInterval, logger)
+	} else {
+		snapshot = nil
+	}
+
 	go func() {
 		logger.Info("server started", slog.String("addr", server.Addr), slog.String("env", string(cfg.Environment)))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -82,9 +113,19 @@ func main() {
 	defer cancel()
 
 	logger.Info("shutting down")
+	stopBackground()
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("graceful shutdown failed", slog.Any("error", err))
 	}
+	if snapshot != nil {
+		if err := snapshot.SaveSnapshot(cfg.Datastore.MemorySnapshotPath); err != nil {
+			logger.Warn("snapshot memory store", slog.Any("error", err))
+		}
+	}
+	// Export the spans of requests that finished during shutdown.
+	if err := tracer.Flush(ctx); err != nil {
+		logger.Warn("export traces", slog.Any("error", err))
+	}
 }
 
 func parseLogLevel(level string) slog.Level {
@@ -99,3 +140,35 @@ func parseLogLevel(level string) slog.Level {
 		return slog.LevelInfo
 	}
 }
+
+// newRepositories builds the repositories and server stores for the
+// configured datastore driver. The memory driver serves a single instance,
+// so its stores are in memory too.
+func newRepositories(cfg config.DatastoreConfig) (repository.Repository, app.Stores, error) {
+	switch cfg.Driver {
+	case "firestore":
+		store, err := storefirestore.NewStore(cfg)
+		if err != nil {
+			return repository.Repository{}, app.Stores{}, err
+		}
+		return repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, app.Stores{Flags: store, Nonces: store, Documents: store}, nil
+	case "postgres":
+		// Bounds connecting and, when enabled, migrating the schema.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		store, err := storepostgres.NewStore(ctx, cfg)
+		if err != nil {
+			return repository.Repository{}, app.Stores{}, err
+		}
+		return repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, app.Stores{Flags: store, Nonces: store, Documents: store}, nil
+	default:
+		store := storememory.NewStore()
+		if cfg.MemorySnapshotPath != "" {
+			var err error
+			if store, err = storememory.Open(cfg.MemorySnapshotPath); err != nil {
+				return repository.Repository{}, app.Stores{}, err
+			}
+		}
+		return repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, app.Stores{Flags: flags.NewMemoryStore(), Nonces: replay.NewMemoryStore(), Documents: docstore.NewMemoryStore()}, nil
+	}
+}
@@ -0,0 +1,129 @@
+// Command snapshots records or verifies screen snapshots against a running
+// server, for use in CI before a template or backend change is rolled out:
+//
+//	snapshots -url https://sdui.example.com -template home=templates/home.json verify
+//
+// The admin bearer token is read from PESTGENIE_TOKEN. verify exits with
+// status 1 when any screen changed or failed to render.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/snapshot"
+)
+
+// templateFlags collects -template screenID=path flags.
+type templateFlags map[string]json.RawMessage
+
+func (t templateFlags) String() string { return "" }
+
+func (t templateFlags) Set(v string) error {
+	id, path, ok := strings.Cut(v, "=")
+	if !ok || id == "" || path == "" {
+		return fmt.Errorf("want screenID=path, got %q", v)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	t[id] = data
+	return nil
+}
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080", "server base URL")
+	screens := flag.String("screens", "", "comma-separated screen IDs; empty means every published screen")
+	templates := templateFlags{}
+	flag.Var(templates, "template", "candidate template to verify, as screenID=path; repeatable")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: snapshots [flags] record|verify\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (flag.Arg(0) != "record" && flag.Arg(0) != "verify") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	req := snapshot.Request{Templates: templates}
+	for _, id := range strings.Split(*screens, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			req.ScreenIDs = append(req.ScreenIDs, id)
+		}
+	}
+	report, err := post(strings.TrimRight(*baseURL, "/")+"/v1/admin/snapshots/"+flag.Arg(0), os.Getenv("PESTGENIE_TOKEN"), req)
+	if err != nil {
+		log.Fatalf("snapshots: %v", err)
+	}
+
+	for _, c := range report.Checks {
+		if c.Status == snapshot.StatusMatch || c.Status == snapshot.StatusRecorded {
+			continue
+		}
+		fmt.Printf("%s %s/%s", c.Status, c.ScreenID, c.Case)
+		if c.Error != "" {
+			fmt.Printf(": %s", c.Error)
+		}
+		fmt.Println()
+		for _, d := range c.Diffs {
+			fmt.Printf("  %s %s: %v -> %v\n", d.Kind, d.Path, value(d.Golden), value(d.Render))
+		}
+		if c.Truncated {
+			fmt.Println("  ...")
+		}
+	}
+	fmt.Printf("checked %d: %d recorded, %d matched, %d changed, %d new, %d failed\n",
+		report.Checked, report.Recorded, report.Matched, report.Changed, report.New, report.Failed)
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
+
+func post(url, token string, req snapshot.Request) (snapshot.Report, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return snapshot.Report{}, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return snapshot.Report{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return snapshot.Report{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return snapshot.Report{}, fmt.Errorf("server responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var report snapshot.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return snapshot.Report{}, fmt.Errorf("decode report: %w", err)
+	}
+	return report, nil
+}
+
+// value prints a differing value compactly; absent ones print as -.
+func value(v any) string {
+	if v == nil {
+		return "-"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,214 @@
+// Package models holds the domain records the repositories persist.
+package models
+
+import "time"
+
+// Technician represents a field technician using the app.
+type Technician struct {
+	ID             string
+	TenantID       string // company the record belongs to; empty in single-company deployments
+	Email          string
+	DisplayName    string
+	Role           string
+	Region         string // legacy free-text region; prefer BranchID
+	BranchID       string
+	Certifications []string
+}
+
+// Route represents a technician's assignment for a given date.
+type Route struct {
+	ID            string
+	TenantID      string
+	TechnicianID  string
+	ServiceDate   time.Time
+	CustomerStops []RouteStop
+	Alerts        []RouteAlert
+	LastModified  time.Time
+}
+
+// ServerID is the ID devices know a route by: its own ID, or the technician
+// and service date for routes imported without one.
+func (r Route) ServerID() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return r.TechnicianID + "_" + r.ServiceDate.Format("2006-01-02")
+}
+
+// RouteStop represents an individual customer visit.
+type RouteStop struct {
+	CustomerID   string
+	CustomerName string
+	// Nickname is what the technician calls the stop ("the Miller barn"),
+	// when it differs from the customer's name.
+	Nickname     string
+	Address      string
+	WindowStart  time.Time
+	WindowEnd    time.Time
+	Priority     string
+	Notes        string
+	ServiceType  string // e.g. general_pest, termite; keys duration estimates
+	PropertySqFt int    // property size, 0 when unknown
+	// Latitude and Longitude locate the property for navigation; both are
+	// zero when the stop has not been geocoded.
+	Latitude  float64
+	Longitude float64
+	// PostalAddress is the address as an address validator standardized
+	// it; nil until validated.
+	PostalAddress *PostalAddress
+}
+
+// PostalAddress is a standardized address split into its components.
+type PostalAddress struct {
+	Street string // primary line, e.g. 123 MAIN ST
+	Unit   string // secondary line, e.g. APT 4
+	City   string
+	State  string
+	ZIP    string
+	ZIP4   string
+	// Status is verified, corrected (verified once the validator fixed it),
+	// unverified (standardized only) or undeliverable.
+	Status string
+}
+
+// HasCoordinates reports whether the stop has been geocoded.
+func (s RouteStop) HasCoordinates() bool {
+	return s.Latitude != 0 || s.Longitude != 0
+}
+
+// RouteAlert conveys route-level communications.
+type RouteAlert struct {
+	Type     string
+	Message  string
+	Severity string
+}
+
+// ScreenTemplate represents an SDUI template stored on the backend.
+type ScreenTemplate struct {
+	ID          string
+	TenantID    string // empty for templates every tenant shares
+	Version     int
+	PayloadJSON []byte
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ScreenContext contains personalised data to merge with templates.
+type ScreenContext struct {
+	Technician Technician
+	Route      Route
+	Metadata   map[string]string
+}
+
+// JobUpload contains job data uploaded from the device during sync.
+type JobUpload struct {
+	ID            string
+	TenantID      string
+	TechnicianID  string
+	CustomerName  string
+	Address       string
+	ScheduledDate time.Time
+	Status        string
+	ReceivedAt    time.Time
+	// Latitude and Longitude locate the job's address; both are zero until
+	// the worker geocodes it.
+	Latitude  float64
+	Longitude float64
+	// SignatureID is the photo ID of the customer's signature, once
+	// captured.
+	SignatureID string
+}
+
+// HasCoordinates reports whether the job has been geocoded.
+func (j JobUpload) HasCoordinates() bool {
+	return j.Latitude != 0 || j.Longitude != 0
+}
+
+// ChemicalUpload contains chemical inventory updates.
+type ChemicalUpload struct {
+	ID               string
+	TenantID         string
+	TechnicianID     string
+	Name             string
+	ActiveIngredient string
+	ManufacturerName string
+	EPARegistration  string
+	Concentration    float64
+	UnitOfMeasure    string
+	QuantityInStock  float64
+	ExpirationDate   time.Time
+	Lots             []ChemicalLot // stock by manufacturer lot, when tracked
+	LastModified     time.Time
+}
+
+// ChemicalLot is the part of a chemical's stock from one manufacturer lot.
+type ChemicalLot struct {
+	Number         string
+	ReceivedAt     time.Time
+	ExpirationDate time.Time
+	Quantity       float64
+}
+
+// ChemicalTreatmentUpload contains treatment logs from the field.
+type ChemicalTreatmentUpload struct {
+	ID                 string
+	TenantID           string
+	JobID              string
+	ChemicalID         string
+	LotNumber          string // lot the product was drawn from
+	MixID              string // tank mix recipe the product was applied in
+	EquipmentID        string // calibrated asset the product was applied with
+	TechnicianID       string
+	ApplicatorName     string
+	ApplicationDate    time.Time
+	ApplicationMethod  string
+	TargetPests        string
+	QuantityUsed       float64
+	DosageRate         float64
+	DilutionRatio      string
+	EnvironmentalNotes string
+	WeatherConditions  string // as typed by the technician
+	// Weather is what the server fetched for the treatment's stop and
+	// time; nil when weather is disabled or the stop could not be placed.
+	Weather      *WeatherReading
+	Notes        string
+	LastModified time.Time
+}
+
+// WeatherReading is the conditions a weather provider reported for a place
+// and time.
+type WeatherReading struct {
+	Source       string // provider, e.g. openweather
+	TemperatureC float64
+	WindSpeedKPH float64
+	WindGustKPH  float64 // zero when not reported
+	Summary      string  // e.g. light rain
+	At           time.Time
+}
+
+// Tombstone kinds.
+const (
+	TombstoneJob      = "job"
+	TombstoneRoute    = "route"
+	TombstoneChemical = "chemical"
+)
+
+// Tombstone records that a synced record was soft-deleted, so devices that
+// already hold it can drop their copy.
+type Tombstone struct {
+	Kind         string
+	ID           string // the record's server ID
+	TenantID     string
+	TechnicianID string
+	DeletedAt    time.Time
+}
+
+// DeviceToken associates an APNs token with a technician.
+type DeviceToken struct {
+	Token        string
+	TenantID     string
+	TechnicianID string
+	Platform     string
+	BundleID     string
+	RegisteredAt time.Time
+}
@@ -0,0 +1,229 @@
+// Package repository defines the persistence interfaces the service runs
+// on. The memory, Postgres and Firestore stores implement them; backends
+// built outside this module can too, and check their behavior with the
+// storetest suite.
+package repository
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+)
+
+// Errors the getters return for records that do not exist. Stores return
+// them, or errors errors.Is matches with them, so handlers answer 404.
+var (
+	ErrTechnicianNotFound = apperr.NotFound("technician_not_found", "technician not found")
+	ErrRouteNotFound      = apperr.NotFound("route_not_found", "route not found")
+	ErrTemplateNotFound   = apperr.NotFound("template_not_found", "template not found")
+	ErrJobNotFound        = apperr.NotFound("job_not_found", "job not found")
+)
+
+// TechnicianRepository retrieves technician profiles.
+type TechnicianRepository interface {
+	GetByID(id string) (models.Technician, error)
+	// SaveTechnician creates or replaces a technician profile.
+	SaveTechnician(t models.Technician) error
+	// ListTechnicians returns every technician, ordered by ID.
+	ListTechnicians() ([]models.Technician, error)
+}
+
+// RouteRepository retrieves route assignments.
+type RouteRepository interface {
+	GetRoute(technicianID string, serviceDate time.Time) (models.Route, error)
+	SaveRoute(route models.Route) error
+	// DeleteRoute soft-deletes a route, leaving a tombstone for device sync.
+	// Deleting a missing or already deleted route succeeds; saving the route
+	// again restores it.
+	DeleteRoute(technicianID string, serviceDate time.Time) error
+}
+
+// ScreenRepository manages SDUI templates and variants.
+type ScreenRepository interface {
+	GetTemplate(id string, version int) (models.ScreenTemplate, error)
+	SaveTemplate(template models.ScreenTemplate) error
+	ListTemplates() ([]models.ScreenTemplate, error)
+	// DeleteTemplate removes one version of a template. Deleting a missing
+	// version succeeds.
+	DeleteTemplate(id string, version int) error
+}
+
+// SyncRepository persists sync uploads for downstream processing.
+type SyncRepository interface {
+	// SaveJobUpload keeps the stored job's SignatureID when upload has
+	// none, since devices re-upload jobs without it.
+	SaveJobUpload(upload models.JobUpload) error
+	// GetJobUpload returns the latest version of a job, with ReceivedAt set
+	// to the server write time, or ErrJobNotFound when it is missing or
+	// deleted.
+	GetJobUpload(id string) (models.JobUpload, error)
+	SaveChemicalUpload(upload models.ChemicalUpload) error
+	SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error
+	// The ListPending* queries return uploads in the order the server
+	// stored them, at most limit when it is positive, leaving out those of
+	// deleted records.
+	ListPendingJobs(limit int) ([]models.JobUpload, error)
+	ListPendingChemicals(limit int) ([]models.ChemicalUpload, error)
+	ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error)
+	// ListAllJobs and ListAllChemicals return the latest version of every
+	// job and chemical stored, deleted ones included, oldest first, for
+	// reports on treatments applied before a record was deleted.
+	ListAllJobs() ([]models.JobUpload, error)
+	ListAllChemicals() ([]models.ChemicalUpload, error)
+
+	// The *UpdatesSince queries return the latest version of each record
+	// the server stored after since, leaving out deleted ones, oldest first,
+	// with LastModified (ReceivedAt for jobs) set to the server write time.
+	ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error)
+	ListRouteUpdatesSince(since time.Time) ([]models.Route, error)
+	ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error)
+	ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error)
+	// The List*Updates queries back device delta sync. They page through
+	// the same records in the order the server stored them, with routes
+	// identified by ServerID, so a device catching up after a long time
+	// offline does not load its whole backlog for every page.
+	ListJobUpdates(page Page) ([]models.JobUpload, error)
+	ListRouteUpdates(page Page) ([]models.Route, error)
+	ListChemicalUpdates(page Page) ([]models.ChemicalUpload, error)
+	ListTreatmentUpdates(page Page) ([]models.ChemicalTreatmentUpload, error)
+
+	// The ListUnprocessed* queries back the upload worker. They return, a
+	// page at a time, the records with an ID whose latest version has not
+	// been marked processed, leaving out deleted ones. Records come in the
+	// order the server stored them, with LastModified (ReceivedAt for
+	// jobs) set to the server write time and routes identified by
+	// ServerID.
+	ListUnprocessedJobs(page Page) ([]models.JobUpload, error)
+	ListUnprocessedChemicals(page Page) ([]models.ChemicalUpload, error)
+	ListUnprocessedTreatments(page Page) ([]models.ChemicalTreatmentUpload, error)
+	ListUnprocessedRoutes(page Page) ([]models.Route, error)
+	// MarkProcessed marks the version of a record of kind (one of the
+	// Upload* kinds) the server wrote at savedAt as processed. A record
+	// saved again since stays unprocessed, as does one that is missing.
+	MarkProcessed(kind, id string, savedAt time.Time) error
+
+	// DeleteJob and DeleteChemical soft-delete a record: it drops out of the
+	// delta queries and is reported by ListDeletionsSince instead. Deleting
+	// a missing or already deleted record succeeds; saving it again restores
+	// it. Deleted records drop out of the pending queues as well.
+	DeleteJob(id string) error
+	DeleteChemical(id string) error
+	// ListDeletionsSince returns the tombstones of jobs, routes and
+	// chemicals deleted after since, oldest first.
+	ListDeletionsSince(since time.Time) ([]models.Tombstone, error)
+	// ListDeletions pages through the same tombstones by deletion time,
+	// then ID.
+	ListDeletions(page Page) ([]models.Tombstone, error)
+	// PruneTombstones permanently removes records deleted before cutoff and
+	// reports how many were removed.
+	PruneTombstones(cutoff time.Time) (int, error)
+
+	// SaveBatch applies every write of batch in one transaction: either all
+	// of them are stored or, when it returns an error, none is. Each write
+	// behaves like the method that makes it on its own.
+	SaveBatch(batch Batch) error
+}
+
+// Batch is a set of writes SaveBatch stores together, such as the records
+// a merge rewrites.
+type Batch struct {
+	// Routes are saved as by RouteRepository.SaveRoute.
+	Routes     []models.Route
+	Jobs       []models.JobUpload
+	Treatments []models.ChemicalTreatmentUpload
+	// DeletedJobs are soft-deleted as by DeleteJob, after the saves.
+	DeletedJobs []string
+}
+
+// Upload kinds, as MarkProcessed takes them.
+const (
+	UploadJob       = "job"
+	UploadChemical  = "chemical"
+	UploadTreatment = "treatment"
+	UploadRoute     = "route"
+)
+
+// Page selects a run of records in the order they were stored: those
+// written after After, or at After with an ID above AfterID, at most Limit
+// of them. A zero Page starts from the oldest; Limit <= 0 returns them all.
+type Page struct {
+	After   time.Time
+	AfterID string
+	Limit   int
+}
+
+// Next returns the page following one that ended with the record written
+// at savedAt under id.
+func (p Page) Next(savedAt time.Time, id string) Page {
+	return Page{After: savedAt, AfterID: id, Limit: p.Limit}
+}
+
+// Includes reports whether a record written at savedAt under id falls
+// after the start of p.
+func (p Page) Includes(savedAt time.Time, id string) bool {
+	return savedAt.After(p.After) || savedAt.Equal(p.After) && id > p.AfterID
+}
+
+// TreatmentScanner is implemented by sync repositories that can stream
+// treatments by application date, so reports over long ranges do not load
+// every treatment at once. ScanTreatments calls fn with the latest version
+// of each treatment applied in [from, to), in application order, and stops
+// at the first error fn returns.
+type TreatmentScanner interface {
+	ScanTreatments(from, to time.Time, fn func(models.ChemicalTreatmentUpload) error) error
+}
+
+// DeviceRepository stores device registration tokens.
+type DeviceRepository interface {
+	// SaveDeviceToken upserts a registration keyed by its token, so
+	// re-registering a device refreshes it instead of adding a duplicate.
+	SaveDeviceToken(token models.DeviceToken) error
+	// ListDeviceTokens returns a technician's registrations, most recently
+	// registered first.
+	ListDeviceTokens(technicianID string) ([]models.DeviceToken, error)
+	// DeleteDeviceToken removes a registration. Unknown tokens are not an
+	// error.
+	DeleteDeviceToken(token string) error
+	// PruneDeviceTokens removes registrations last refreshed before cutoff and
+	// reports how many were removed.
+	PruneDeviceTokens(cutoff time.Time) (int, error)
+}
+
+// Repository aggregates all dependencies for service construction.
+type Repository struct {
+	Technicians TechnicianRepository
+	Routes      RouteRepository
+	Screens     ScreenRepository
+	Sync        SyncRepository
+	Devices     DeviceRepository
+}
+
+// Validate ensures all dependencies are present.
+func (r Repository) Validate() error {
+	if r.Technicians == nil {
+		return ErrMissingRepository{"technicians"}
+	}
+	if r.Routes == nil {
+		return ErrMissingRepository{"routes"}
+	}
+	if r.Screens == nil {
+		return ErrMissingRepository{"screens"}
+	}
+	if r.Sync == nil {
+		return ErrMissingRepository{"sync"}
+	}
+	if r.Devices == nil {
+		return ErrMissingRepository{"devices"}
+	}
+	return nil
+}
+
+// ErrMissingRepository indicates a required repository has not been provided.
+type ErrMissingRepository struct {
+	Name string
+}
+
+func (e ErrMissingRepository) Error() string {
+	return "missing repository: " + e.Name
+}
@@ -0,0 +1,117 @@
+// Package access restricts where admin and sign-in requests may come from.
+// A policy allows a list of addresses and CIDR ranges and blocks a list of
+// countries; the configured policy applies to every request, and tenants
+// add policies of their own. Refused attempts are recorded in the activity
+// feed.
+package access
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidPolicy wraps policy validation failures.
+var ErrInvalidPolicy = errors.New("invalid access policy")
+
+// Policy restricts the addresses and countries requests may come from. The
+// zero Policy allows everything.
+type Policy struct {
+	// AllowedIPs are addresses and CIDR ranges, such as 203.0.113.0/24;
+	// empty allows every address.
+	AllowedIPs []string `json:"allowedIps,omitempty"`
+	// BlockedCountries are ISO 3166-1 alpha-2 codes, such as RU.
+	BlockedCountries []string `json:"blockedCountries,omitempty"`
+}
+
+// Problems lists what is wrong with the policy, sorted; none means it is
+// valid.
+func (p Policy) Problems() []string {
+	var problems []string
+	for _, ip := range p.AllowedIPs {
+		if _, ok := parseRange(ip); !ok {
+			problems = append(problems, fmt.Sprintf("allowedIps: %q is not an address or CIDR range", ip))
+		}
+	}
+	for _, c := range p.BlockedCountries {
+		if countryCode(c) == "" {
+			problems = append(problems, fmt.Sprintf("blockedCountries: %q is not a two-letter country code", c))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// Validate checks a policy.
+func (p Policy) Validate() error {
+	if problems := p.Problems(); len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPolicy, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// refusal is why a policy refuses a request.
+type refusal struct {
+	title, detail string
+}
+
+// refuses checks a request from ip, which may be invalid when unknown, and
+// country, empty when unknown, and reports why the policy refuses it, if
+// it does. A request from an unknown country passes the country blocks, as
+// without a trusted proxy reporting it there is nothing to check.
+func (p Policy) refuses(ip netip.Addr, country string) (refusal, bool) {
+	if len(p.AllowedIPs) > 0 {
+		allowed := false
+		for _, entry := range p.AllowedIPs {
+			if r, ok := parseRange(entry); ok && ip.IsValid() && r.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return refusal{"address not allowed", "requests from " + describe(ip) + " are not allowed"}, true
+		}
+	}
+	if country != "" {
+		for _, c := range p.BlockedCountries {
+			if countryCode(c) == country {
+				return refusal{"country blocked", "requests from " + country + " are not allowed"}, true
+			}
+		}
+	}
+	return refusal{}, false
+}
+
+// parseRange parses an address or CIDR range as a prefix.
+func parseRange(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+	if p, err := netip.ParsePrefix(s); err == nil {
+		return p.Masked(), true
+	}
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	a = a.Unmap()
+	return netip.PrefixFrom(a, a.BitLen()), true
+}
+
+// countryCode normalizes a country as a proxy reports it, such as "us" or
+// "US,California", to its upper-case code, or "" when it is not one.
+func countryCode(s string) string {
+	s, _, _ = strings.Cut(s, ",")
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != 2 || s[0] < 'A' || s[0] > 'Z' || s[1] < 'A' || s[1] > 'Z' {
+		return ""
+	}
+	return s
+}
+
+func describe(ip netip.Addr) string {
+	if !ip.IsValid() {
+		return "an unknown address"
+	}
+	return ip.String()
+}
@@ -0,0 +1,119 @@
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+type tenantPolicies []Policy
+
+func (p tenantPolicies) AccessPolicies(*http.Request) ([]Policy, error) {
+	return p, nil
+}
+
+type failingPolicies struct{}
+
+func (failingPolicies) AccessPolicies(*http.Request) ([]Policy, error) {
+	return nil, errors.New("store down")
+}
+
+func newTestGuard(t *testing.T, cfg config.AccessConfig, tenants Policies) (http.Handler, *activity.MemoryStore) {
+	t.Helper()
+	store := activity.NewMemoryStore()
+	feed := activity.NewService(config.ActivityConfig{MaxEvents: 100}, store, repository.Repository{}, nil)
+	g, err := NewGuard(cfg, tenants, feed)
+	if err != nil {
+		t.Fatalf("new guard: %v", err)
+	}
+	h := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return middleware.RealIP([]string{"10.0.0.0/8"})(h), store
+}
+
+func request(peer, forwarded, country string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-tokens", nil)
+	req.RemoteAddr = peer
+	if forwarded != "" {
+		req.Header.Set("X-Forwarded-For", forwarded)
+	}
+	if country != "" {
+		req.Header.Set("X-Client-Region", country)
+	}
+	return req
+}
+
+func serve(h http.Handler, req *http.Request) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var p struct {
+		Type string `json:"type"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&p)
+	return rec.Code, p.Type
+}
+
+func TestGuardEnforcesAllowlistsAndCountryBlocks(t *testing.T) {
+	cfg := config.AccessConfig{AllowedIPs: []string{"203.0.113.0/24", "198.51.100.7"}, CountryHeader: "X-Client-Region"}
+	h, store := newTestGuard(t, cfg, tenantPolicies{{BlockedCountries: []string{"ru", "KP"}}})
+
+	if code, _ := serve(h, request("10.0.0.1:443", "203.0.113.9", "US,Texas")); code != http.StatusNoContent {
+		t.Fatalf("expected an allowed address through, got %d", code)
+	}
+	if code, _ := serve(h, request("198.51.100.7:443", "", "")); code != http.StatusNoContent {
+		t.Fatalf("expected a single allowed address through, got %d", code)
+	}
+	if code, typ := serve(h, request("10.0.0.1:443", "192.0.2.1", "")); code != http.StatusForbidden || typ != "/problems/access-restricted" {
+		t.Fatalf("expected an address outside the allowlist refused, got %d %s", code, typ)
+	}
+	if code, _ := serve(h, request("192.0.2.1:443", "203.0.113.9", "")); code != http.StatusForbidden {
+		t.Fatalf("expected a forwarded address from an untrusted peer ignored, got %d", code)
+	}
+	if code, _ := serve(h, request("10.0.0.1:443", "203.0.113.9", "RU")); code != http.StatusForbidden {
+		t.Fatalf("expected the tenant's blocked country refused, got %d", code)
+	}
+	if code, _ := serve(h, request("198.51.100.7:443", "", "RU")); code != http.StatusNoContent {
+		t.Fatalf("expected a country header from an untrusted peer ignored, got %d", code)
+	}
+
+	events, _ := store.ListEvents(activity.Filter{Types: []string{activity.AccessBlocked}}, 0)
+	if len(events) != 3 || events[0].ActorID != activity.SystemActor {
+		t.Fatalf("expected every refusal audited, got %+v", events)
+	}
+	var subjects []string
+	for _, e := range events {
+		subjects = append(subjects, e.SubjectName)
+	}
+	want := "POST /v1/admin/api-tokens from 203.0.113.9 (RU): country blocked"
+	found := false
+	for _, s := range subjects {
+		found = found || s == want
+	}
+	if !found {
+		t.Fatalf("expected %q among %q", want, subjects)
+	}
+}
+
+func TestGuardWithoutPolicies(t *testing.T) {
+	h, _ := newTestGuard(t, config.AccessConfig{}, nil)
+	if code, _ := serve(h, request("192.0.2.1:443", "", "")); code != http.StatusNoContent {
+		t.Fatalf("expected everything allowed without a policy, got %d", code)
+	}
+
+	h, _ = newTestGuard(t, config.AccessConfig{}, failingPolicies{})
+	if code, _ := serve(h, request("192.0.2.1:443", "", "")); code != http.StatusInternalServerError {
+		t.Fatalf("expected a failed lookup to fail closed, got %d", code)
+	}
+
+	if _, err := NewGuard(config.AccessConfig{AllowedIPs: []string{"10.0.0/8"}, BlockedCountries: []string{"Russia"}}, nil, nil); !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected an invalid config refused, got %v", err)
+	}
+}
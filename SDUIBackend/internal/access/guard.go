@@ -0,0 +1,90 @@
+package access
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Policies returns the policies of the tenants a request is held to, on
+// top of the configured one; *tenant.Service implements it.
+type Policies interface {
+	AccessPolicies(r *http.Request) ([]Policy, error)
+}
+
+// Guard refuses requests that a policy does not allow.
+type Guard struct {
+	policy  Policy
+	header  string
+	tenants Policies
+	feed    *activity.Service
+}
+
+// NewGuard returns a guard enforcing cfg and the tenants' policies; tenants
+// may be nil. Refused requests are recorded in feed, which may be nil.
+func NewGuard(cfg config.AccessConfig, tenants Policies, feed *activity.Service) (*Guard, error) {
+	policy := Policy{AllowedIPs: cfg.AllowedIPs, BlockedCountries: cfg.BlockedCountries}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("access config: %w", err)
+	}
+	return &Guard{policy: policy, header: cfg.CountryHeader, tenants: tenants, feed: feed}, nil
+}
+
+// Middleware refuses requests from addresses outside an allowlist or from
+// a blocked country. The country is read from the configured header only
+// on requests arriving through a trusted proxy, as resolved by
+// middleware.RealIP; anyone else could set the header themselves. Mount it
+// after authentication, so the policy of the tenant a verified token
+// belongs to applies.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policies := []Policy{g.policy}
+		if g.tenants != nil {
+			tenants, err := g.tenants.AccessPolicies(r)
+			if err != nil {
+				middleware.LoggerFrom(r.Context()).Error("failed to check access", slog.Any("error", err))
+				respond.Error(w, http.StatusInternalServerError, "failed to check access", "temporary error, please retry", respond.WithCause(err))
+				return
+			}
+			policies = append(policies, tenants...)
+		}
+
+		var ip netip.Addr
+		var country string
+		if client, ok := middleware.ClientFrom(r.Context()); ok {
+			ip = client.IP
+			if client.Proxied && g.header != "" {
+				country = countryCode(r.Header.Get(g.header))
+			}
+		}
+		for _, p := range policies {
+			if refused, ok := p.refuses(ip, country); ok {
+				g.refuse(w, r, ip, country, refused)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// refuse answers a refused request and records the attempt.
+func (g *Guard) refuse(w http.ResponseWriter, r *http.Request, ip netip.Addr, country string, refused refusal) {
+	from := describe(ip)
+	if country != "" {
+		from += " (" + country + ")"
+	}
+	middleware.LoggerFrom(r.Context()).Warn("request refused", slog.String("reason", refused.title), slog.String("client", from), slog.String("path", r.URL.Path))
+	g.feed.Record(r.Context(), activity.Event{
+		Type:        activity.AccessBlocked,
+		SubjectID:   describe(ip),
+		SubjectName: fmt.Sprintf("%s %s from %s: %s", r.Method, r.URL.Path, from, refused.title),
+	})
+	respond.Error(w, http.StatusForbidden, refused.title, refused.detail)
+}
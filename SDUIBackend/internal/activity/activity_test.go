@@ -0,0 +1,85 @@
+package activity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newTestService(t *testing.T) (*Service, *time.Time) {
+	t.Helper()
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "admin-1", DisplayName: "Ana Lopez", Email: "Ana@Example.com"})
+	cfg := config.ActivityConfig{GroupWindow: 10 * time.Minute, MaxEvents: 100, AvatarURL: "https://avatars.test/{hash}"}
+	svc := NewService(cfg, NewMemoryStore(), repository.Repository{Technicians: store}, nil)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestFeedCollapsesRunsOfEvents(t *testing.T) {
+	svc, now := newTestService(t)
+	admin := auth.ContextWithIdentity(context.Background(), auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin})
+
+	svc.Record(admin, Event{Type: TemplatePublished, SubjectID: "home", SubjectName: "home v2"})
+	for _, id := range []string{"c1", "c2", "c3"} {
+		*now = now.Add(time.Minute)
+		svc.Record(context.Background(), Event{Type: ChemicalUpdated, ActorID: "tech-9", SubjectID: id})
+	}
+	*now = now.Add(time.Hour)
+	svc.Record(context.Background(), Event{Type: ChemicalUpdated, ActorID: "tech-9", SubjectID: "c4"})
+	svc.Record(context.Background(), Event{Type: RouteImported, SubjectID: "r1"})
+
+	items, err := svc.Feed(Filter{}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var summaries []string
+	for _, item := range items {
+		summaries = append(summaries, item.Summary)
+	}
+	want := []string{"System imported route r1", "tech-9 updated chemical c4", "tech-9 updated 3 chemicals", "Ana Lopez published template home v2"}
+	if strings.Join(summaries, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected feed %q", summaries)
+	}
+	if grouped := items[2]; grouped.Count != 3 || len(grouped.Subjects) != 3 || !grouped.FirstAt.Before(grouped.At) {
+		t.Fatalf("unexpected grouped item %+v", grouped)
+	}
+	sum := sha256.Sum256([]byte("ana@example.com"))
+	if got := items[3].Actor.AvatarURL; got != "https://avatars.test/"+hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected the avatar hashed from the email, got %s", got)
+	}
+
+	items, _ = svc.Feed(Filter{Types: []string{ChemicalUpdated}}, 1)
+	if len(items) != 1 || items[0].Count != 1 {
+		t.Fatalf("expected the limit to count items, got %+v", items)
+	}
+}
+
+func TestGetFeedRejectsBadFilters(t *testing.T) {
+	svc, _ := newTestService(t)
+	h := NewHandler(svc)
+	for _, query := range []string{"type=route.deleted", "since=yesterday", "limit=0", "limit=500"} {
+		rec := httptest.NewRecorder()
+		h.GetFeed(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/activity?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.GetFeed(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/activity?type=template.published,route.imported&actorId=admin-1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
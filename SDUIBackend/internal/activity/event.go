@@ -0,0 +1,134 @@
+// Package activity keeps an audit trail of notable admin and technician
+// actions and presents it as the console's activity feed: related events
+// are collapsed into one readable item with the actor's name and avatar.
+package activity
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	TemplatePublished = "template.published"
+	TemplateDeleted   = "template.deleted"
+	RouteImported     = "route.imported"
+	RouteOptimized    = "route.optimized"
+	ChemicalUpdated   = "chemical.updated"
+	PlaybackViewed    = "playback.viewed"
+	AccessBlocked     = "access.blocked"
+	AuthLockedOut     = "auth.locked_out"
+)
+
+// verbs phrase each event type for feed summaries.
+var verbs = map[string]struct{ one, many string }{
+	TemplatePublished: {"published template", "published %d templates"},
+	TemplateDeleted:   {"deleted template", "deleted %d templates"},
+	RouteImported:     {"imported route", "imported %d routes"},
+	RouteOptimized:    {"optimized route", "optimized %d routes"},
+	ChemicalUpdated:   {"updated chemical", "updated %d chemicals"},
+	PlaybackViewed:    {"replayed day", "replayed %d days"},
+	AccessBlocked:     {"refused request", "refused %d requests"},
+	AuthLockedOut:     {"locked out", "locked out %d callers"},
+}
+
+// Types lists the event types, sorted.
+func Types() []string {
+	out := make([]string, 0, len(verbs))
+	for t := range verbs {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SystemActor is the actor of events no user caused, such as scheduled
+// partner feed imports.
+const SystemActor = "system"
+
+// Event is one audited action.
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// ActorID is the user who acted, or SystemActor.
+	ActorID string `json:"actorId"`
+	// SubjectID identifies what was acted on, such as a screen ID or route
+	// ID; SubjectName is how the feed shows it.
+	SubjectID   string    `json:"subjectId"`
+	SubjectName string    `json:"subjectName,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Filter narrows the events listed. Zero fields match everything.
+type Filter struct {
+	Types   []string
+	ActorID string
+	Since   time.Time
+	Until   time.Time
+}
+
+// Matches reports whether e passes the filter.
+func (f Filter) Matches(e Event) bool {
+	if f.ActorID != "" && e.ActorID != f.ActorID {
+		return false
+	}
+	if !f.Since.IsZero() && e.At.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.At.Before(f.Until) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if e.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists the audit trail.
+type Store interface {
+	// AppendEvent records e, dropping the oldest events beyond max.
+	AppendEvent(e Event, max int) error
+	// ListEvents returns the events matching f, newest first, up to limit.
+	ListEvents(f Filter, limit int) ([]Event, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	events []Event // oldest first
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) AppendEvent(e Event, max int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	if over := len(m.events) - max; max > 0 && over > 0 {
+		m.events = append([]Event(nil), m.events[over:]...)
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListEvents(f Filter, limit int) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Event
+	for i := len(m.events) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		if f.Matches(m.events[i]) {
+			out = append(out, m.events[i])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package activity
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Feed sizes.
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// Handler exposes the activity feed to the admin console.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an activity handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the activity endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.GetFeed)
+}
+
+// GetFeed returns the activity feed, newest first. It is filtered by
+// ?type= (comma separated), ?actorId=, and ?since= and ?until= (RFC3339),
+// and sized by ?limit=.
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	f, limit, problem := parseFilter(r)
+	if problem != "" {
+		respond.Error(w, http.StatusBadRequest, "invalid activity filter", problem)
+		return
+	}
+	items, err := h.service.Feed(f, limit)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to list activity", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to list activity", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// parseFilter reads the feed's query parameters, returning a problem detail
+// when one is invalid.
+func parseFilter(r *http.Request) (Filter, int, string) {
+	q := r.URL.Query()
+	f := Filter{ActorID: q.Get("actorId")}
+	if raw := q.Get("type"); raw != "" {
+		types := Types()
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(types, t) {
+				return Filter{}, 0, "type must be one of " + strings.Join(types, ", ")
+			}
+			f.Types = append(f.Types, t)
+		}
+	}
+	for _, p := range []struct {
+		name string
+		to   *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return Filter{}, 0, p.name + " must be an RFC3339 timestamp"
+		}
+		*p.to = t
+	}
+	limit := defaultLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxLimit {
+			return Filter{}, 0, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+		}
+		limit = n
+	}
+	return f, limit, ""
+}
@@ -0,0 +1,185 @@
+package activity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// maxSubjects bounds the subjects listed on one feed item; Count still
+// covers them all.
+const maxSubjects = 10
+
+// Item is one entry of the activity feed: an event, or a run of one actor's
+// events of the same type that happened close together.
+type Item struct {
+	// ID is the newest event's ID.
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Summary  string    `json:"summary"`
+	Actor    Actor     `json:"actor"`
+	Subjects []Subject `json:"subjects"`
+	Count    int       `json:"count"`
+	// At is when the newest event happened, FirstAt the oldest.
+	At      time.Time `json:"at"`
+	FirstAt time.Time `json:"firstAt"`
+}
+
+// Actor is who caused a feed item.
+type Actor struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+// Subject is what an event acted on.
+type Subject struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Service records audit events and builds the activity feed from them.
+type Service struct {
+	cfg         config.ActivityConfig
+	store       Store
+	technicians repository.TechnicianRepository
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewService wires an activity service. Actors are looked up among the
+// technician profiles in repos.
+func NewService(cfg config.ActivityConfig, store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, technicians: repos.Technicians, logger: logger, now: time.Now}
+}
+
+// Record appends e to the audit trail. Without an ActorID the event is
+// attributed to the authenticated user in ctx, or to SystemActor. Failures
+// are logged rather than returned so auditing never fails the action, and a
+// nil service records nothing.
+func (s *Service) Record(ctx context.Context, e Event) {
+	if s == nil {
+		return
+	}
+	if e.ActorID == "" {
+		e.ActorID = SystemActor
+		if id, ok := auth.FromContext(ctx); ok && id.Subject != "" {
+			e.ActorID = id.Subject
+		}
+	}
+	e.ID = uuid.NewString()
+	e.At = s.now().UTC()
+	if err := s.store.AppendEvent(e, s.cfg.MaxEvents); err != nil {
+		s.logger.Error("record activity", slog.String("type", e.Type), slog.String("subject", e.SubjectID), slog.Any("error", err))
+	}
+}
+
+// Feed returns up to limit feed items built from the events matching f,
+// newest first.
+func (s *Service) Feed(f Filter, limit int) ([]Item, error) {
+	events, err := s.store.ListEvents(f, 0)
+	if err != nil {
+		return nil, err
+	}
+	actors := make(map[string]Actor)
+	items := make([]Item, 0)
+	for _, e := range events {
+		if n := len(items); n > 0 && s.joins(items[n-1], e) {
+			last := &items[n-1]
+			last.Count++
+			last.FirstAt = e.At
+			if len(last.Subjects) < maxSubjects {
+				last.Subjects = append(last.Subjects, Subject{ID: e.SubjectID, Name: e.SubjectName})
+			}
+			continue
+		}
+		if len(items) == limit {
+			break
+		}
+		actor, ok := actors[e.ActorID]
+		if !ok {
+			actor = s.actor(e.ActorID)
+			actors[e.ActorID] = actor
+		}
+		items = append(items, Item{
+			ID:       e.ID,
+			Type:     e.Type,
+			Actor:    actor,
+			Subjects: []Subject{{ID: e.SubjectID, Name: e.SubjectName}},
+			Count:    1,
+			At:       e.At,
+			FirstAt:  e.At,
+		})
+	}
+	for i := range items {
+		items[i].Summary = summary(items[i])
+	}
+	return items, nil
+}
+
+// joins reports whether e, older than every event in item, collapses into
+// it: same actor and type, within the group window of the item's oldest
+// event.
+func (s *Service) joins(item Item, e Event) bool {
+	return s.cfg.GroupWindow > 0 &&
+		item.Type == e.Type &&
+		item.Actor.ID == e.ActorID &&
+		item.FirstAt.Sub(e.At) <= s.cfg.GroupWindow
+}
+
+// actor resolves an actor's display name and avatar from their technician
+// profile, falling back to the ID for users without one.
+func (s *Service) actor(id string) Actor {
+	if id == SystemActor {
+		return Actor{ID: id, Name: "System", AvatarURL: s.avatar(id)}
+	}
+	a := Actor{ID: id, Name: id, AvatarURL: s.avatar(id)}
+	tech, err := s.technicians.GetByID(id)
+	if err != nil {
+		return a
+	}
+	if tech.DisplayName != "" {
+		a.Name = tech.DisplayName
+	}
+	if tech.Email != "" {
+		a.AvatarURL = s.avatar(tech.Email)
+	}
+	return a
+}
+
+// avatar fills the avatar URL template with the hash of key, an email or ID.
+func (s *Service) avatar(key string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(key))))
+	return strings.ReplaceAll(s.cfg.AvatarURL, "{hash}", hex.EncodeToString(sum[:]))
+}
+
+// summary phrases an item, such as "Ana Lopez published template home" or
+// "Ana Lopez updated 3 chemicals".
+func summary(item Item) string {
+	verb, ok := verbs[item.Type]
+	if !ok {
+		verb.one, verb.many = item.Type, item.Type+" %d times"
+	}
+	if item.Count > 1 {
+		return item.Actor.Name + " " + fmt.Sprintf(verb.many, item.Count)
+	}
+	subject := item.Subjects[0].Name
+	if subject == "" {
+		subject = item.Subjects[0].ID
+	}
+	return item.Actor.Name + " " + verb.one + " " + subject
+}
@@ -0,0 +1,171 @@
+// Package address standardizes and validates street addresses. Imported
+// route stops arrive with addresses typed every which way; validating them
+// against USPS data, directly or through Google's Address Validation API,
+// gives geocoding and duplicate detection one spelling per place, splits
+// out the unit and ZIP+4, and flags addresses that receive no mail.
+package address
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Validation statuses, as stored on models.PostalAddress.
+const (
+	StatusVerified = "verified"
+	// StatusCorrected is a verified address the validator had to fix, such
+	// as a wrong ZIP or a misspelled street.
+	StatusCorrected = "corrected"
+	// StatusUnverified is an address standardized without being checked.
+	StatusUnverified = "unverified"
+	// StatusUndeliverable is an address the validator could not confirm
+	// receives mail.
+	StatusUndeliverable = "undeliverable"
+)
+
+var (
+	// ErrDisabled is returned by a nil Service.
+	ErrDisabled = errors.New("address validation is disabled")
+	// ErrNotFound is returned when an address has no cached result.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidAddress is returned for blank addresses.
+	ErrInvalidAddress = errors.New("invalid address")
+)
+
+// Result is the outcome of validating an address.
+type Result struct {
+	Input string `json:"input"`
+	// Address is the standardized address on one line, as in "123 MAIN ST
+	// APT 4, AUSTIN, TX 78701-1234"; for undeliverable addresses it is the
+	// best the validator could make of the input.
+	Address    string               `json:"address"`
+	Components models.PostalAddress `json:"components"`
+	// Latitude and Longitude are set when the validator also geocoded the
+	// address.
+	Latitude    float64   `json:"latitude,omitempty"`
+	Longitude   float64   `json:"longitude,omitempty"`
+	Provider    string    `json:"provider"`
+	ValidatedAt time.Time `json:"validatedAt"`
+}
+
+// Status returns the result's validation status.
+func (r Result) Status() string {
+	return r.Components.Status
+}
+
+// Deliverable reports whether the address may receive mail: every status
+// but undeliverable.
+func (r Result) Deliverable() bool {
+	return r.Components.Status != StatusUndeliverable
+}
+
+// Validator standardizes and checks an address.
+type Validator interface {
+	Name() string
+	Validate(ctx context.Context, address string) (Result, error)
+}
+
+// NewValidator returns the validator selected by cfg, or nil when address
+// validation is disabled.
+func NewValidator(cfg config.AddressConfig, secrets secret.Provider) Validator {
+	client := &http.Client{Timeout: cfg.RequestTimeout}
+	switch cfg.Provider {
+	case "local":
+		return Local{}
+	case "google":
+		u := cfg.URL
+		if u == "" {
+			u = GoogleURL
+		}
+		return Google{URL: u, KeySecret: cfg.KeySecret, Secrets: secrets, Client: client}
+	case "usps":
+		u := cfg.URL
+		if u == "" {
+			u = USPSURL
+		}
+		return &USPS{URL: u, ClientIDSecret: cfg.ClientIDSecret, SecretName: cfg.KeySecret, Secrets: secrets, Client: client}
+	default:
+		return nil
+	}
+}
+
+// Local standardizes addresses by rule, without checking them, so every
+// result is unverified.
+type Local struct{}
+
+func (Local) Name() string { return "local" }
+
+func (Local) Validate(_ context.Context, address string) (Result, error) {
+	parts := parse(address)
+	parts.Status = StatusUnverified
+	return Result{Input: address, Address: format(parts), Components: parts, Provider: "local"}, nil
+}
+
+// key is what results are cached by: the input, ignoring case, spacing and
+// periods.
+func key(address string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(strings.ReplaceAll(address, ".", ""))), " ")
+}
+
+// Store caches validation results by input.
+type Store interface {
+	SaveResult(r Result) error
+	GetResult(input string) (Result, error)
+	// ListResults returns every result, most recently validated first.
+	ListResults() ([]Result, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	results map[string]Result
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{results: make(map[string]Result)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveResult(r Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[key(r.Input)] = r
+	return nil
+}
+
+func (m *MemoryStore) GetResult(input string) (Result, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.results[key(input)]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *MemoryStore) ListResults() ([]Result, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Result, 0, len(m.results))
+	for _, r := range m.results {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ValidatedAt.Equal(out[j].ValidatedAt) {
+			return out[i].ValidatedAt.After(out[j].ValidatedAt)
+		}
+		return out[i].Input < out[j].Input
+	})
+	return out, nil
+}
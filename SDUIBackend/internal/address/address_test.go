@@ -0,0 +1,177 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	v, ok := s[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+var _ secret.Provider = staticSecrets(nil)
+
+// countingValidator returns the local standardization and counts calls.
+type countingValidator struct {
+	calls int
+	err   error
+}
+
+func (*countingValidator) Name() string { return "counting" }
+
+func (c *countingValidator) Validate(ctx context.Context, address string) (Result, error) {
+	c.calls++
+	if c.err != nil {
+		return Result{}, c.err
+	}
+	return Local{}.Validate(ctx, address)
+}
+
+func TestParseStandardizesComponents(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"123 Main Street, Apt. 4, Austin, TX 78701-1234", "123 MAIN ST APT 4, AUSTIN, TX 78701-1234"},
+		{"  42 north oak avenue suite 200, Round Rock, tx 78664", "42 N OAK AVE STE 200, ROUND ROCK, TX 78664"},
+		{"9 Elm Rd, Austin TX 787011234", "9 ELM RD, AUSTIN, TX 78701-1234"},
+		{"1 Main St", "1 MAIN ST"},
+	}
+	for _, tc := range cases {
+		if got := format(parse(tc.in)); got != tc.want {
+			t.Errorf("parse(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	p := parse("123 Main Street, Apt. 4, Austin, TX 78701-1234")
+	if p.Street != "123 MAIN ST" || p.Unit != "APT 4" || p.City != "AUSTIN" || p.State != "TX" || p.ZIP != "78701" || p.ZIP4 != "1234" {
+		t.Fatalf("unexpected components: %+v", p)
+	}
+}
+
+func TestServiceCachesResults(t *testing.T) {
+	v := &countingValidator{}
+	svc := NewService(config.AddressConfig{CacheTTL: time.Hour}, v, NewMemoryStore(), nil)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := svc.Validate(ctx, "1 Main Street, Austin, TX 78701")
+	if err != nil || first.Address != "1 MAIN ST, AUSTIN, TX 78701" || first.Status() != StatusUnverified {
+		t.Fatalf("unexpected result %+v (%v)", first, err)
+	}
+	if _, err := svc.Validate(ctx, "1 main street,  austin, tx 78701"); err != nil || v.calls != 1 {
+		t.Fatalf("expected cached result, got %d calls (%v)", v.calls, err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := svc.Validate(ctx, "1 Main Street, Austin, TX 78701"); err != nil || v.calls != 2 {
+		t.Fatalf("expected expired result to be revalidated, got %d calls (%v)", v.calls, err)
+	}
+
+	v.err = errors.New("unavailable")
+	if _, err := svc.Validate(ctx, "2 Oak Ln, Austin, TX"); err == nil {
+		t.Fatal("expected validator error")
+	}
+	if results, _ := svc.Results(""); len(results) != 1 {
+		t.Fatalf("expected failures not to be cached, got %+v", results)
+	}
+	if _, err := svc.Validate(ctx, "  "); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("expected invalid address, got %v", err)
+	}
+	var disabled *Service
+	if _, err := disabled.Validate(ctx, "1 Main St"); !errors.Is(err, ErrDisabled) {
+		t.Fatalf("expected disabled, got %v", err)
+	}
+}
+
+func TestStandardizeKeepsExistingCoordinates(t *testing.T) {
+	svc := NewService(config.AddressConfig{CacheTTL: time.Hour}, &countingValidator{}, NewMemoryStore(), nil)
+	stop := models.RouteStop{Address: "5 Pine Court, Austin, TX 78702", Latitude: 30.2, Longitude: -97.7}
+	if _, err := svc.Standardize(context.Background(), &stop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stop.Address != "5 PINE CT, AUSTIN, TX 78702" || stop.PostalAddress == nil || stop.PostalAddress.ZIP != "78702" {
+		t.Fatalf("unexpected stop: %+v", stop)
+	}
+	if stop.Latitude != 30.2 || stop.Longitude != -97.7 {
+		t.Fatalf("expected coordinates to be kept, got %v,%v", stop.Latitude, stop.Longitude)
+	}
+}
+
+func TestGoogleReadsUSPSData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "k" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{
+			"verdict":{"addressComplete":true,"hasReplacedComponents":true},
+			"geocode":{"location":{"latitude":30.27,"longitude":-97.74}},
+			"uspsData":{"standardizedAddress":{"firstAddressLine":"123 MAIN ST APT 4","city":"AUSTIN","state":"TX","zipCode":"78701","zipCodeExtension":"1234"},"dpvConfirmation":"Y"}}}`))
+	}))
+	defer srv.Close()
+
+	g := Google{URL: srv.URL, KeySecret: "address-key", Secrets: staticSecrets{"address-key": "k"}, Client: srv.Client()}
+	res, err := g.Validate(context.Background(), "123 Main St #4, Austin TX 78702")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Address != "123 MAIN ST APT 4, AUSTIN, TX 78701-1234" || res.Status() != StatusCorrected || res.Latitude != 30.27 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestUSPSUndeliverableAndTokenReuse(t *testing.T) {
+	tokens := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/v3/token":
+			tokens++
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "t", "expires_in": 3600})
+		case "/addresses/v3/address":
+			if r.Header.Get("Authorization") != "Bearer t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("streetAddress") == "999 NOWHERE RD" {
+				http.Error(w, `{"error":{"message":"Address Not Found."}}`, http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"address":{"streetAddress":"1 MAIN ST","city":"AUSTIN","state":"TX","ZIPCode":"78701","ZIPPlus4":"0001"},"additionalInfo":{"DPVConfirmation":"Y"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	u := &USPS{URL: srv.URL, ClientIDSecret: "id", SecretName: "secret", Secrets: staticSecrets{"id": "i", "secret": "s"}, Client: srv.Client()}
+	ctx := context.Background()
+	res, err := u.Validate(ctx, "1 Main Street, Austin, TX 78701")
+	if err != nil || res.Status() != StatusVerified || res.Address != "1 MAIN ST, AUSTIN, TX 78701-0001" {
+		t.Fatalf("unexpected result %+v (%v)", res, err)
+	}
+	res, err = u.Validate(ctx, "999 Nowhere Road, Austin, TX")
+	if err != nil || res.Deliverable() {
+		t.Fatalf("expected undeliverable, got %+v (%v)", res, err)
+	}
+	if tokens != 1 {
+		t.Fatalf("expected the token to be reused, fetched %d", tokens)
+	}
+	if res, err := u.Validate(ctx, "1 Main Street"); err != nil || res.Status() != StatusUnverified {
+		t.Fatalf("expected unverified without state or zip, got %+v (%v)", res, err)
+	}
+}
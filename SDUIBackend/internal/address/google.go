@@ -0,0 +1,138 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// GoogleURL is the Address Validation API endpoint.
+const GoogleURL = "https://addressvalidation.googleapis.com/v1:validateAddress"
+
+// Google calls Google's Address Validation API with USPS CASS checks, which
+// standardizes US addresses to USPS data and confirms delivery points.
+type Google struct {
+	URL       string
+	KeySecret string // secret name of the API key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (Google) Name() string { return "google" }
+
+func (g Google) Validate(ctx context.Context, address string) (Result, error) {
+	apiKey, err := g.Secrets.Get(g.KeySecret)
+	if err != nil {
+		return Result{}, fmt.Errorf("address validation key: %w", err)
+	}
+	payload, err := json.Marshal(map[string]any{
+		"address":        map[string]any{"regionCode": "US", "addressLines": []string{address}},
+		"enableUspsCass": true,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.URL+"?key="+url.QueryEscape(apiKey), bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var body struct {
+		Result struct {
+			Verdict struct {
+				AddressComplete       bool `json:"addressComplete"`
+				HasInferredComponents bool `json:"hasInferredComponents"`
+				HasReplacedComponents bool `json:"hasReplacedComponents"`
+			} `json:"verdict"`
+			Address struct {
+				FormattedAddress string `json:"formattedAddress"`
+			} `json:"address"`
+			Geocode struct {
+				Location struct {
+					Latitude  float64 `json:"latitude"`
+					Longitude float64 `json:"longitude"`
+				} `json:"location"`
+			} `json:"geocode"`
+			USPSData struct {
+				StandardizedAddress struct {
+					FirstAddressLine string `json:"firstAddressLine"`
+					City             string `json:"city"`
+					State            string `json:"state"`
+					ZIPCode          string `json:"zipCode"`
+					ZIPCodeExtension string `json:"zipCodeExtension"`
+				} `json:"standardizedAddress"`
+				// DPVConfirmation is Y when the delivery point is
+				// confirmed, S or D when only the building is, and N
+				// when USPS does not deliver there.
+				DPVConfirmation string `json:"dpvConfirmation"`
+			} `json:"uspsData"`
+		} `json:"result"`
+	}
+	if err := do(g.Client, req, &body); err != nil {
+		return Result{}, err
+	}
+
+	res := body.Result
+	usps := res.USPSData.StandardizedAddress
+	var parts models.PostalAddress
+	if usps.FirstAddressLine != "" {
+		parts.Street, parts.Unit = splitUnit(usps.FirstAddressLine)
+		parts.City, parts.State, parts.ZIP, parts.ZIP4 = usps.City, usps.State, usps.ZIPCode, usps.ZIPCodeExtension
+	} else {
+		parts = parse(firstNonEmpty(res.Address.FormattedAddress, address))
+	}
+	switch dpv := res.USPSData.DPVConfirmation; {
+	case dpv == "N", dpv == "" && !res.Verdict.AddressComplete:
+		parts.Status = StatusUndeliverable
+	case res.Verdict.HasInferredComponents || res.Verdict.HasReplacedComponents:
+		parts.Status = StatusCorrected
+	default:
+		parts.Status = StatusVerified
+	}
+	loc := res.Geocode.Location
+	return Result{Input: address, Address: format(parts), Components: parts, Latitude: loc.Latitude, Longitude: loc.Longitude, Provider: "google"}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// do sends req and decodes a JSON response into out.
+func do(client *http.Client, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return &statusError{code: resp.StatusCode, msg: fmt.Sprintf("address validator responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode address validation response: %w", err)
+	}
+	return nil
+}
+
+// statusError is a non-2xx response, kept so USPS's 404 for unknown
+// addresses can be told from outages.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
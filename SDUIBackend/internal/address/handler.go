@@ -0,0 +1,75 @@
+package address
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes address validation in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an address handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListResults)
+	r.Post("/validate", h.Validate)
+}
+
+// ListResults returns validated addresses with ?status=, such as
+// undeliverable, or all of them, most recently validated first.
+func (h *Handler) ListResults(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", StatusVerified, StatusCorrected, StatusUnverified, StatusUndeliverable:
+	default:
+		respond.Error(w, http.StatusBadRequest, "invalid status", "expected verified, corrected, unverified or undeliverable")
+		return
+	}
+	results, err := h.service.Results(status)
+	if err != nil {
+		h.fail(w, r, "failed to list addresses", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"addresses": results})
+}
+
+// Validate standardizes and checks {"address"}.
+func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	res, err := h.service.Validate(r.Context(), payload.Address)
+	if err != nil {
+		h.fail(w, r, "failed to validate address", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidAddress):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,157 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// suffixes maps street suffixes to their USPS abbreviations.
+var suffixes = map[string]string{
+	"STREET": "ST", "AVENUE": "AVE", "AV": "AVE", "ROAD": "RD", "DRIVE": "DR",
+	"BOULEVARD": "BLVD", "LANE": "LN", "COURT": "CT", "PLACE": "PL",
+	"CIRCLE": "CIR", "HIGHWAY": "HWY", "PARKWAY": "PKWY", "TERRACE": "TER",
+	"TRAIL": "TRL", "SQUARE": "SQ", "WAY": "WAY", "EXPRESSWAY": "EXPY",
+}
+
+// directions maps directionals to their USPS abbreviations.
+var directions = map[string]string{
+	"NORTH": "N", "SOUTH": "S", "EAST": "E", "WEST": "W", "NORTHEAST": "NE",
+	"NORTHWEST": "NW", "SOUTHEAST": "SE", "SOUTHWEST": "SW",
+}
+
+// units maps secondary unit designators to their USPS abbreviations.
+var units = map[string]string{
+	"APARTMENT": "APT", "APT": "APT", "SUITE": "STE", "STE": "STE",
+	"UNIT": "UNIT", "BUILDING": "BLDG", "BLDG": "BLDG", "FLOOR": "FL",
+	"FL": "FL", "ROOM": "RM", "RM": "RM", "LOT": "LOT", "#": "#",
+}
+
+var (
+	zipPattern   = regexp.MustCompile(`^(\d{5})(?:-?(\d{4}))?$`)
+	statePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// parse splits a one-line US address such as "123 Main Street, Apt 4,
+// Austin, TX 78701" into USPS-style components. It only rewrites what is
+// there; missing parts are left empty.
+func parse(address string) models.PostalAddress {
+	address = strings.ToUpper(strings.ReplaceAll(address, ".", ""))
+	var parts []string
+	for _, p := range strings.Split(address, ",") {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	var out models.PostalAddress
+	if len(parts) == 0 {
+		return out
+	}
+
+	// The last part ends with "ST 12345", or is the state and ZIP alone.
+	if len(parts) > 1 {
+		last := strings.Fields(parts[len(parts)-1])
+		if n := len(last); n > 0 {
+			if m := zipPattern.FindStringSubmatch(last[n-1]); m != nil {
+				out.ZIP, out.ZIP4 = m[1], m[2]
+				last = last[:n-1]
+			}
+		}
+		if n := len(last); n > 0 && statePattern.MatchString(last[n-1]) {
+			out.State = last[n-1]
+			last = last[:n-1]
+		}
+		if len(last) > 0 {
+			out.City = strings.Join(last, " ")
+			parts = parts[:len(parts)-1]
+		} else {
+			parts = parts[:len(parts)-1]
+			if n := len(parts); n > 1 && !isUnit(parts[n-1]) {
+				out.City = parts[n-1]
+				parts = parts[:n-1]
+			}
+		}
+	}
+
+	if out.Street, out.Unit = splitUnit(parts[0]); out.Street == "" {
+		out.Street, out.Unit = parts[0], ""
+	}
+	for _, p := range parts[1:] {
+		if isUnit(p) && out.Unit == "" {
+			_, out.Unit = splitUnit(p)
+		}
+	}
+	out.Street = abbreviate(out.Street)
+	return out
+}
+
+// splitUnit separates a trailing secondary unit, as in "123 MAIN ST APT 4"
+// or "123 MAIN ST #4", from the primary line. street is empty when line is
+// only a unit.
+func splitUnit(line string) (street, unit string) {
+	words := strings.Fields(line)
+	for i := len(words) - 1; i >= 0; i-- {
+		w := words[i]
+		if strings.HasPrefix(w, "#") && len(w) > 1 {
+			return strings.Join(words[:i], " "), "# " + strings.Join(append([]string{w[1:]}, words[i+1:]...), " ")
+		}
+		if short, ok := units[w]; ok && i < len(words)-1 {
+			return strings.Join(words[:i], " "), short + " " + strings.Join(words[i+1:], " ")
+		}
+	}
+	return line, ""
+}
+
+// isUnit reports whether part is a secondary unit alone, as in "APT 4".
+func isUnit(part string) bool {
+	street, unit := splitUnit(part)
+	return street == "" && unit != ""
+}
+
+// abbreviate applies USPS abbreviations to the suffix and directionals of
+// a primary line, leaving street names such as "COURT ST" intact.
+func abbreviate(street string) string {
+	words := strings.Fields(street)
+	n := len(words)
+	if n < 3 {
+		return street
+	}
+	if short, ok := directions[words[1]]; ok && n > 3 {
+		words[1] = short
+	}
+	last := n - 1
+	if short, ok := directions[words[last]]; ok && last > 2 {
+		words[last] = short
+		last--
+	}
+	if short, ok := suffixes[words[last]]; ok {
+		words[last] = short
+	}
+	return strings.Join(words, " ")
+}
+
+// format renders components as one line, e.g. "123 MAIN ST APT 4, AUSTIN,
+// TX 78701-1234".
+func format(p models.PostalAddress) string {
+	line := p.Street
+	if p.Unit != "" {
+		line += " " + p.Unit
+	}
+	out := []string{line}
+	if p.City != "" {
+		out = append(out, p.City)
+	}
+	region := p.State
+	if p.ZIP != "" {
+		zip := p.ZIP
+		if p.ZIP4 != "" {
+			zip += "-" + p.ZIP4
+		}
+		region = strings.TrimSpace(region + " " + zip)
+	}
+	if region != "" {
+		out = append(out, region)
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,103 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Service validates addresses, caching results so an address imported
+// every week is checked once a CacheTTL. A nil *Service validates nothing.
+type Service struct {
+	cfg       config.AddressConfig
+	validator Validator
+	store     Store
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService wires an address service. It returns nil when validator is
+// nil, that is when address validation is disabled.
+func NewService(cfg config.AddressConfig, validator Validator, store Store, logger *slog.Logger) *Service {
+	if validator == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, validator: validator, store: store, logger: logger, now: time.Now}
+}
+
+// Validate standardizes and checks address, from the cache when it was
+// validated within CacheTTL.
+func (s *Service) Validate(ctx context.Context, address string) (Result, error) {
+	if s == nil {
+		return Result{}, ErrDisabled
+	}
+	if strings.TrimSpace(address) == "" {
+		return Result{}, fmt.Errorf("%w: address is required", ErrInvalidAddress)
+	}
+	cached, err := s.store.GetResult(address)
+	switch {
+	case err == nil && s.now().Sub(cached.ValidatedAt) < s.cfg.CacheTTL:
+		return cached, nil
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return Result{}, err
+	}
+	res, err := s.validator.Validate(ctx, address)
+	if err != nil {
+		return Result{}, fmt.Errorf("validate address: %w", err)
+	}
+	res.Input = address
+	res.ValidatedAt = s.now().UTC()
+	if !res.Deliverable() {
+		s.logger.Warn("undeliverable address", slog.String("validator", s.validator.Name()), slog.String("address", address))
+	}
+	return res, s.store.SaveResult(res)
+}
+
+// Standardize validates stop's address and replaces it with the
+// standardized one, keeping the components on the stop. Stops without
+// coordinates take any the validator found.
+func (s *Service) Standardize(ctx context.Context, stop *models.RouteStop) (Result, error) {
+	res, err := s.Validate(ctx, stop.Address)
+	if err != nil {
+		return Result{}, err
+	}
+	stop.Address = res.Address
+	parts := res.Components
+	stop.PostalAddress = &parts
+	if !stop.HasCoordinates() {
+		stop.Latitude, stop.Longitude = res.Latitude, res.Longitude
+	}
+	return res, nil
+}
+
+// Results returns cached results with status, or all when status is empty,
+// most recently validated first.
+func (s *Service) Results(status string) ([]Result, error) {
+	if s == nil {
+		return nil, ErrDisabled
+	}
+	all, err := s.store.ListResults()
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		return all, nil
+	}
+	out := make([]Result, 0, len(all))
+	for _, r := range all {
+		if r.Status() == status {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// USPSURL is the base URL of the USPS APIs.
+const USPSURL = "https://apis.usps.com"
+
+// USPS calls the USPS Addresses API (v3), authenticating with an OAuth
+// client credentials token it renews shortly before it expires.
+type USPS struct {
+	URL            string
+	ClientIDSecret string // secret name of the OAuth client ID
+	SecretName     string // secret name of the OAuth client secret
+	Secrets        secret.Provider
+	Client         *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (*USPS) Name() string { return "usps" }
+
+// Validate checks address, which must name at least a street and either a
+// state or a ZIP code; USPS cannot look up anything less, so such
+// addresses come back standardized but unverified.
+func (u *USPS) Validate(ctx context.Context, address string) (Result, error) {
+	parts := parse(address)
+	if parts.Street == "" || (parts.State == "" && parts.ZIP == "") {
+		parts.Status = StatusUnverified
+		return Result{Input: address, Address: format(parts), Components: parts, Provider: "usps"}, nil
+	}
+	token, err := u.accessToken(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	q := url.Values{}
+	q.Set("streetAddress", parts.Street)
+	if parts.Unit != "" {
+		q.Set("secondaryAddress", parts.Unit)
+	}
+	if parts.City != "" {
+		q.Set("city", parts.City)
+	}
+	if parts.State != "" {
+		q.Set("state", parts.State)
+	}
+	if parts.ZIP != "" {
+		q.Set("ZIPCode", parts.ZIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL+"/addresses/v3/address?"+q.Encode(), nil)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		Address struct {
+			StreetAddress    string `json:"streetAddress"`
+			SecondaryAddress string `json:"secondaryAddress"`
+			City             string `json:"city"`
+			State            string `json:"state"`
+			ZIPCode          string `json:"ZIPCode"`
+			ZIPPlus4         string `json:"ZIPPlus4"`
+		} `json:"address"`
+		AdditionalInfo struct {
+			DPVConfirmation string `json:"DPVConfirmation"`
+		} `json:"additionalInfo"`
+		Corrections []struct {
+			Code string `json:"code"`
+		} `json:"corrections"`
+	}
+	err = do(u.Client, req, &body)
+	var status *statusError
+	if errors.As(err, &status) && (status.code == http.StatusNotFound || status.code == http.StatusBadRequest) {
+		// USPS answers 404 for addresses it does not know and 400 for
+		// ones it cannot parse; neither will improve with a retry.
+		parts.Status = StatusUndeliverable
+		return Result{Input: address, Address: format(parts), Components: parts, Provider: "usps"}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	a := body.Address
+	checked := parts
+	checked.Street, checked.Unit = a.StreetAddress, a.SecondaryAddress
+	checked.City, checked.State, checked.ZIP, checked.ZIP4 = a.City, a.State, a.ZIPCode, a.ZIPPlus4
+	switch {
+	case body.AdditionalInfo.DPVConfirmation == "N":
+		checked.Status = StatusUndeliverable
+	case len(body.Corrections) > 0 || a.StreetAddress != parts.Street || (parts.ZIP != "" && a.ZIPCode != parts.ZIP):
+		checked.Status = StatusCorrected
+	default:
+		checked.Status = StatusVerified
+	}
+	return Result{Input: address, Address: format(checked), Components: checked, Provider: "usps"}, nil
+}
+
+// accessToken returns the cached token, fetching a new one when it expires
+// within a minute.
+func (u *USPS) accessToken(ctx context.Context) (string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.token != "" && time.Until(u.expires) > time.Minute {
+		return u.token, nil
+	}
+	id, err := u.Secrets.Get(u.ClientIDSecret)
+	if err != nil {
+		return "", fmt.Errorf("usps client id: %w", err)
+	}
+	clientSecret, err := u.Secrets.Get(u.SecretName)
+	if err != nil {
+		return "", fmt.Errorf("usps client secret: %w", err)
+	}
+	payload, err := json.Marshal(map[string]string{"grant_type": "client_credentials", "client_id": id, "client_secret": clientSecret})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.URL+"/oauth2/v3/token", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := do(u.Client, req, &body); err != nil {
+		return "", fmt.Errorf("usps token: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("usps token: empty access token")
+	}
+	u.token = body.AccessToken
+	u.expires = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return u.token, nil
+}
@@ -0,0 +1,115 @@
+// Package anomaly screens chemical treatments for quantities far from what
+// was applied on similar jobs: the same product, in the same unit, on the
+// same service type and property size. Such quantities are usually data
+// entry errors, sometimes misuse, so flagged treatments wait in a review
+// queue and are held out of usage reports until a supervisor approves them.
+package anomaly
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Flag statuses.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved" // the quantity is right; reports include it
+	StatusRejected = "rejected" // the quantity is wrong; held until corrected
+	// StatusCleared marks a flag whose treatment was corrected back within
+	// the norm before review.
+	StatusCleared = "cleared"
+)
+
+var (
+	// ErrNotFound is returned when a treatment has no flag.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidReview wraps reviews of flags not waiting for one.
+	ErrInvalidReview = errors.New("invalid review")
+)
+
+// Flag is a treatment whose quantity was outside the norm.
+type Flag struct {
+	TreatmentID     string    `json:"treatmentId"`
+	JobID           string    `json:"jobId"`
+	TechnicianID    string    `json:"technicianId"`
+	ChemicalID      string    `json:"chemicalId"`
+	ChemicalName    string    `json:"chemicalName"`
+	ApplicationDate time.Time `json:"applicationDate"`
+	Quantity        float64   `json:"quantity"`
+	UnitOfMeasure   string    `json:"unitOfMeasure"`
+	ServiceType     string    `json:"serviceType,omitempty"`
+	// SizeBucket is the property size the norm was drawn from; empty when
+	// it spans every size, for lack of samples or of a known size.
+	SizeBucket string `json:"sizeBucket,omitempty"`
+	// Median is the norm's typical quantity and Score how far Quantity is
+	// from it, in robust standard deviations; Samples is the norm's size.
+	Median     float64    `json:"median"`
+	Score      float64    `json:"score"`
+	Samples    int        `json:"samples"`
+	Status     string     `json:"status"`
+	FlaggedAt  time.Time  `json:"flaggedAt"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// Held reports whether the flag keeps its treatment out of reports.
+func (f Flag) Held() bool {
+	return f.Status == StatusPending || f.Status == StatusRejected
+}
+
+// Store persists flags, one per treatment.
+type Store interface {
+	SaveFlag(f Flag) error
+	GetFlag(treatmentID string) (Flag, error)
+	// ListFlags returns every flag, most recently flagged first.
+	ListFlags() ([]Flag, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{flags: make(map[string]Flag)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveFlag(f Flag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flags[f.TreatmentID] = f
+	return nil
+}
+
+func (m *MemoryStore) GetFlag(treatmentID string) (Flag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.flags[treatmentID]
+	if !ok {
+		return Flag{}, ErrNotFound
+	}
+	return f, nil
+}
+
+func (m *MemoryStore) ListFlags() ([]Flag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Flag, 0, len(m.flags))
+	for _, f := range m.flags {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].FlaggedAt.Equal(out[j].FlaggedAt) {
+			return out[i].FlaggedAt.After(out[j].FlaggedAt)
+		}
+		return out[i].TreatmentID < out[j].TreatmentID
+	})
+	return out, nil
+}
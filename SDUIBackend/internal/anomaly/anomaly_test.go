@@ -0,0 +1,85 @@
+package anomaly
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+func TestOutlyingQuantitiesWaitForReview(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "c1", TechnicianID: "tech-1", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "gal"})
+	route := models.Route{TechnicianID: "tech-1", ServiceDate: day}
+	treat := func(id string, i int, quantity float64) {
+		t.Helper()
+		if err := store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: id, JobID: fmt.Sprintf("job-%d", i), ChemicalID: "c1", TechnicianID: "tech-1", ApplicationDate: day, QuantityUsed: quantity}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 24; i++ {
+		customer := fmt.Sprintf("Customer %d", i)
+		route.CustomerStops = append(route.CustomerStops, models.RouteStop{CustomerName: customer, ServiceType: "Termite", PropertySqFt: 2000})
+		_ = store.SaveJobUpload(models.JobUpload{ID: fmt.Sprintf("job-%d", i), TechnicianID: "tech-1", CustomerName: customer, ScheduledDate: day})
+		if i < 22 {
+			treat(fmt.Sprintf("t-%d", i), i, 1+float64(i%5)/20)
+		}
+	}
+	_ = store.SaveRoute(route)
+	treat("t-typo", 22, 11)
+	treat("t-big", 23, 4)
+
+	cfg := config.AnomalyConfig{Enabled: true, Threshold: 3.5, MinSamples: 20, Window: 365 * 24 * time.Hour, CheckInterval: time.Hour}
+	svc := NewService(cfg, NewMemoryStore(), repos, nil)
+	svc.now = func() time.Time { return day.Add(20 * time.Hour) }
+
+	if n, err := svc.Check(); err != nil || n != 2 {
+		t.Fatalf("expected two treatments flagged, got %d, %v", n, err)
+	}
+	pending, _ := svc.Flags(StatusPending)
+	if len(pending) != 2 {
+		t.Fatalf("unexpected queue %+v", pending)
+	}
+	typo, _ := svc.Flag("t-typo")
+	if typo.ServiceType != "termite" || typo.SizeBucket != "medium" || typo.Median != 1.1 || typo.Score < 3.5 || typo.Samples != 24 {
+		t.Errorf("unexpected flag %+v", typo)
+	}
+	if held, _ := svc.Held(); !held["t-typo"] || !held["t-big"] || len(held) != 2 {
+		t.Errorf("expected both treatments held from reports, got %v", held)
+	}
+
+	if _, err := svc.Reject("t-typo", "supervisor", "should be 1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Approve("t-typo", "supervisor", ""); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("expected a reviewed flag refused, got %v", err)
+	}
+	if f, err := svc.Approve("t-big", "supervisor", "heavy infestation"); err != nil || f.ReviewedBy != "supervisor" {
+		t.Fatalf("unexpected approval %+v, %v", f, err)
+	}
+
+	// The technician corrects the typo; the approved quantity stays approved.
+	time.Sleep(time.Millisecond)
+	treat("t-typo", 22, 1.1)
+	treat("t-big", 23, 4)
+	if n, err := svc.Check(); err != nil || n != 0 {
+		t.Fatalf("expected nothing flagged, got %d, %v", n, err)
+	}
+	if f, _ := svc.Flag("t-typo"); f.Status != StatusCleared || f.Quantity != 1.1 {
+		t.Errorf("expected the corrected flag cleared, got %+v", f)
+	}
+	if f, _ := svc.Flag("t-big"); f.Status != StatusApproved {
+		t.Errorf("expected the approval kept, got %+v", f)
+	}
+	if held, _ := svc.Held(); len(held) != 0 {
+		t.Errorf("expected nothing held, got %v", held)
+	}
+}
@@ -0,0 +1,109 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the review queue to supervisors in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a review queue handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListFlags)
+	r.Get("/{treatmentId}", h.GetFlag)
+	r.Post("/{treatmentId}/approve", h.Approve)
+	r.Post("/{treatmentId}/reject", h.Reject)
+}
+
+// ListFlags returns flagged treatments with ?status= (default pending, or
+// all), most recently flagged first.
+func (h *Handler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = StatusPending
+	case "all":
+		status = ""
+	case StatusPending, StatusApproved, StatusRejected, StatusCleared:
+	default:
+		respond.Error(w, http.StatusBadRequest, "invalid status", "expected pending, approved, rejected, cleared or all")
+		return
+	}
+	flags, err := h.service.Flags(status)
+	if err != nil {
+		h.fail(w, r, "failed to list flagged treatments", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"flags": flags})
+}
+
+// GetFlag returns the flag of treatment {treatmentId}.
+func (h *Handler) GetFlag(w http.ResponseWriter, r *http.Request) {
+	f, err := h.service.Flag(chi.URLParam(r, "treatmentId"))
+	if err != nil {
+		h.fail(w, r, "failed to load flagged treatment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+// Approve accepts a flagged quantity, with an optional {"note"}.
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, "failed to approve treatment", h.service.Approve)
+}
+
+// Reject marks a flagged quantity as wrong, with an optional {"note"}.
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, "failed to reject treatment", h.service.Reject)
+}
+
+func (h *Handler) review(w http.ResponseWriter, r *http.Request, title string, review func(treatmentID, reviewer, note string) (Flag, error)) {
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+			return
+		}
+	}
+	reviewer := "admin"
+	if id, ok := auth.FromContext(r.Context()); ok {
+		reviewer = id.Subject
+	}
+	f, err := review(chi.URLParam(r, "treatmentId"), reviewer, payload.Note)
+	if err != nil {
+		h.fail(w, r, title, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidReview):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
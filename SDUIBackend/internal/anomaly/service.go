@@ -0,0 +1,398 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+)
+
+// madScale turns a median absolute deviation into a standard deviation
+// for normally distributed quantities.
+const madScale = 0.6745
+
+// Service screens treatments and keeps the review queue.
+type Service struct {
+	cfg    config.AnomalyConfig
+	store  Store
+	repos  repository.Repository
+	logger *slog.Logger
+	now    func() time.Time
+
+	// mu serializes screening and reviews; since is the write time of the
+	// newest treatment screened.
+	mu    sync.Mutex
+	since time.Time
+}
+
+// NewService wires an anomaly service.
+func NewService(cfg config.AnomalyConfig, store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, logger: logger, now: time.Now}
+}
+
+// Run screens new treatments every CheckInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.Check(); err != nil {
+				s.logger.Error("screen treatments", slog.Any("error", err))
+			} else if n > 0 {
+				s.logger.Info("treatments flagged for review", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// norm groups past treatments that quantities are compared with. size is
+// empty for the norm over every property size.
+type norm struct {
+	product, unit, serviceType, size string
+}
+
+// lookup is what describing a treatment needs.
+type lookup struct {
+	jobs      map[string]models.JobUpload
+	routes    map[string]models.Route // by technician and service date
+	chemicals map[string]models.ChemicalUpload
+}
+
+// Check screens the treatments stored since the last check, which on the
+// first check is every treatment, and reports how many it flagged. A
+// treatment stored again is screened again: a corrected quantity clears
+// its flag, unless a supervisor already reviewed that quantity.
+func (s *Service) Check() (int, error) {
+	if s == nil || !s.cfg.Enabled {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updates, err := s.repos.Sync.ListTreatmentUpdatesSince(s.since)
+	if err != nil {
+		return 0, err
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	l, err := s.lookup()
+	if err != nil {
+		return 0, err
+	}
+	samples, err := s.samples(l)
+	if err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	now := s.now().UTC()
+	for _, t := range updates {
+		ok, err := s.screen(t, l, samples, now)
+		if err != nil {
+			return flagged, err
+		}
+		if ok {
+			flagged++
+		}
+		if t.LastModified.After(s.since) {
+			s.since = t.LastModified
+		}
+	}
+	return flagged, nil
+}
+
+// screen flags t when its quantity is outside its norm, and clears its
+// flag when a corrected quantity no longer is.
+func (s *Service) screen(t models.ChemicalTreatmentUpload, l lookup, samples map[norm][]float64, now time.Time) (bool, error) {
+	existing, err := s.store.GetFlag(t.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	found := err == nil
+	if found && existing.Status != StatusPending && existing.Quantity == t.QuantityUsed {
+		return false, nil
+	}
+
+	n, chem := l.describe(t)
+	past := samples[n]
+	if len(past) < s.cfg.MinSamples && n.size != "" {
+		n.size = ""
+		past = samples[n]
+	}
+	var median, score float64
+	screened := len(past) >= s.cfg.MinSamples && !t.ApplicationDate.Before(now.Add(-s.cfg.Window))
+	if screened {
+		median, score = robustScore(t.QuantityUsed, past)
+	}
+	if !screened || math.Abs(score) <= s.cfg.Threshold {
+		if found && existing.Status != StatusCleared {
+			existing.Status = StatusCleared
+			existing.Quantity = t.QuantityUsed
+			return false, s.store.SaveFlag(existing)
+		}
+		return false, nil
+	}
+
+	f := Flag{
+		TreatmentID:     t.ID,
+		JobID:           t.JobID,
+		TechnicianID:    t.TechnicianID,
+		ChemicalID:      t.ChemicalID,
+		ChemicalName:    chem.Name,
+		ApplicationDate: t.ApplicationDate,
+		Quantity:        t.QuantityUsed,
+		UnitOfMeasure:   chem.UnitOfMeasure,
+		ServiceType:     n.serviceType,
+		SizeBucket:      n.size,
+		Median:          median,
+		Score:           score,
+		Samples:         len(past),
+		Status:          StatusPending,
+		FlaggedAt:       now,
+	}
+	if found && existing.Status == StatusPending {
+		f.FlaggedAt = existing.FlaggedAt
+	}
+	if err := s.store.SaveFlag(f); err != nil {
+		return false, err
+	}
+	s.logger.Warn("treatment quantity flagged", slog.String("treatment", t.ID), slog.String("technician", t.TechnicianID),
+		slog.Float64("quantity", t.QuantityUsed), slog.Float64("median", median), slog.Float64("score", score))
+	return true, nil
+}
+
+// robustScore returns the median of samples and how far x is from it in
+// standard deviations estimated from the median absolute deviation. The
+// deviation is floored at 5% of the median, so uniform samples do not
+// flag every small difference.
+func robustScore(x float64, samples []float64) (float64, float64) {
+	median := medianOf(samples)
+	deviations := make([]float64, len(samples))
+	for i, v := range samples {
+		deviations[i] = math.Abs(v - median)
+	}
+	spread := math.Max(medianOf(deviations), math.Max(0.05*math.Abs(median), 1e-6))
+	score := madScale * (x - median) / spread
+	return math.Round(median*1e4) / 1e4, math.Round(score*100) / 100
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// samples returns the quantities of the treatments applied within the
+// window by norm, leaving out those held for review. Treatments being
+// screened count towards their own norm, which a single outlier barely
+// moves, so the first check after a restart has norms to screen with.
+func (s *Service) samples(l lookup) (map[norm][]float64, error) {
+	flags, err := s.store.ListFlags()
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[string]bool)
+	for _, f := range flags {
+		if f.Held() {
+			held[f.TreatmentID] = true
+		}
+	}
+	now := s.now()
+	out := make(map[norm][]float64)
+	err = s.scan(now.Add(-s.cfg.Window), now.Add(24*time.Hour), func(t models.ChemicalTreatmentUpload) error {
+		if held[t.ID] {
+			return nil
+		}
+		n, _ := l.describe(t)
+		out[n] = append(out[n], t.QuantityUsed)
+		if n.size != "" {
+			n.size = ""
+			out[n] = append(out[n], t.QuantityUsed)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// scan streams the treatments applied in [from, to) to fn. Stores that
+// cannot scan are read whole and filtered.
+func (s *Service) scan(from, to time.Time, fn func(models.ChemicalTreatmentUpload) error) error {
+	if scanner, ok := s.repos.Sync.(repository.TreatmentScanner); ok {
+		return scanner.ScanTreatments(from, to, fn)
+	}
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, t := range treatments {
+		if t.ApplicationDate.Before(from) || !t.ApplicationDate.Before(to) {
+			continue
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) lookup() (lookup, error) {
+	l := lookup{jobs: map[string]models.JobUpload{}, routes: map[string]models.Route{}, chemicals: map[string]models.ChemicalUpload{}}
+	jobs, err := s.repos.Sync.ListAllJobs()
+	if err != nil {
+		return lookup{}, err
+	}
+	for _, j := range jobs {
+		l.jobs[j.ID] = j
+	}
+	routes, err := s.repos.Sync.ListRouteUpdatesSince(time.Time{})
+	if err != nil {
+		return lookup{}, err
+	}
+	for _, r := range routes {
+		l.routes[r.TechnicianID+"|"+r.ServiceDate.Format("2006-01-02")] = r
+	}
+	chemicals, err := s.repos.Sync.ListAllChemicals()
+	if err != nil {
+		return lookup{}, err
+	}
+	for _, c := range chemicals {
+		l.chemicals[c.ID] = c
+	}
+	return l, nil
+}
+
+// describe returns the norm of a treatment and its chemical. Products are
+// matched across trucks by EPA registration or name; the service type and
+// property size come from the route stop of the treatment's job, matched
+// by customer name or address.
+func (l lookup) describe(t models.ChemicalTreatmentUpload) (norm, models.ChemicalUpload) {
+	chem := l.chemicals[t.ChemicalID]
+	n := norm{product: chem.EPARegistration, unit: strings.ToLower(strings.TrimSpace(chem.UnitOfMeasure))}
+	if n.product == "" {
+		n.product = strings.ToLower(strings.TrimSpace(chem.Name))
+	}
+	if n.product == "" {
+		n.product = "id:" + t.ChemicalID
+	}
+	job, ok := l.jobs[t.JobID]
+	if !ok {
+		return n, chem
+	}
+	day := job.ScheduledDate
+	if day.IsZero() {
+		day = t.ApplicationDate
+	}
+	for _, stop := range l.routes[t.TechnicianID+"|"+day.Format("2006-01-02")].CustomerStops {
+		if strings.EqualFold(stop.CustomerName, job.CustomerName) || (job.Address != "" && strings.EqualFold(stop.Address, job.Address)) {
+			n.serviceType = strings.ToLower(strings.TrimSpace(stop.ServiceType))
+			n.size = schedule.SizeBucket(stop.PropertySqFt)
+			break
+		}
+	}
+	return n, chem
+}
+
+// Flags screens new treatments, then returns the flags with a status,
+// most recently flagged first.
+func (s *Service) Flags(status string) ([]Flag, error) {
+	if _, err := s.Check(); err != nil {
+		return nil, err
+	}
+	flags, err := s.store.ListFlags()
+	if err != nil {
+		return nil, err
+	}
+	out := []Flag{}
+	for _, f := range flags {
+		if status == "" || f.Status == status {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// Flag returns the flag of a treatment.
+func (s *Service) Flag(treatmentID string) (Flag, error) {
+	return s.store.GetFlag(treatmentID)
+}
+
+// Approve accepts a flagged quantity as right, releasing the treatment to
+// reports.
+func (s *Service) Approve(treatmentID, reviewer, note string) (Flag, error) {
+	return s.review(treatmentID, StatusApproved, reviewer, note)
+}
+
+// Reject marks a flagged quantity as wrong. The treatment stays out of
+// reports until the technician corrects it.
+func (s *Service) Reject(treatmentID, reviewer, note string) (Flag, error) {
+	return s.review(treatmentID, StatusRejected, reviewer, note)
+}
+
+func (s *Service) review(treatmentID, status, reviewer, note string) (Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.store.GetFlag(treatmentID)
+	if err != nil {
+		return Flag{}, err
+	}
+	if f.Status != StatusPending {
+		return Flag{}, fmt.Errorf("%w: flag is %s, not waiting for review", ErrInvalidReview, f.Status)
+	}
+	now := s.now().UTC()
+	f.Status = status
+	f.ReviewedAt = &now
+	f.ReviewedBy = reviewer
+	f.Note = strings.TrimSpace(note)
+	if err := s.store.SaveFlag(f); err != nil {
+		return Flag{}, err
+	}
+	s.logger.Info("flagged treatment reviewed", slog.String("treatment", treatmentID), slog.String("status", status), slog.String("reviewer", reviewer))
+	return f, nil
+}
+
+// Held screens new treatments, then returns the IDs of those held out of
+// reports. A nil service holds none.
+func (s *Service) Held() (map[string]bool, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if _, err := s.Check(); err != nil {
+		return nil, err
+	}
+	flags, err := s.store.ListFlags()
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[string]bool)
+	for _, f := range flags {
+		if f.Held() {
+			held[f.TreatmentID] = true
+		}
+	}
+	return held, nil
+}
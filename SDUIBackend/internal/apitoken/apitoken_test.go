@@ -0,0 +1,136 @@
+package apitoken
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+func newTestService(required bool) *Service {
+	return NewService(config.APITokenConfig{Required: required, DefaultRateLimit: 100}, NewMemoryStore(), nil, nil)
+}
+
+func serve(h http.Handler, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if _, found := FromContext(r.Context()); !found {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestCreateAuthenticateAndRevoke(t *testing.T) {
+	svc := newTestService(false)
+	if _, _, err := svc.Create(Token{Name: "x", Owner: "acme", Scopes: []string{"admin:all"}}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected unknown scope to be rejected, got %v", err)
+	}
+
+	tok, secret, err := svc.Create(Token{Name: "CRM sync", Owner: "acme", Scopes: []string{ScopeScreensRead}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok.Hash == secret || tok.Prefix != secret[:len(tok.Prefix)] {
+		t.Fatalf("expected only a hash and prefix to be stored: %+v", tok)
+	}
+	if got, err := svc.Authenticate(secret); err != nil || got.ID != tok.ID {
+		t.Fatalf("expected secret to authenticate, got %+v (%v)", got, err)
+	}
+
+	if _, err := svc.Revoke(tok.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Authenticate(secret); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestTokensAreSharedThroughTheDocumentStore(t *testing.T) {
+	docs := docstore.NewMemoryStore()
+	cfg := config.APITokenConfig{DefaultRateLimit: 100}
+	issuer := NewService(cfg, NewDocumentStore(docs), nil, nil)
+	other := NewService(cfg, NewDocumentStore(docs), nil, nil)
+
+	tok, secret, err := issuer.Create(Token{Name: "CRM sync", Owner: "acme", Scopes: []string{ScopeScreensRead}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := other.Authenticate(secret); err != nil || got.ID != tok.ID {
+		t.Fatalf("expected another instance to authenticate the secret, got %+v (%v)", got, err)
+	}
+	if _, err := issuer.Revoke(tok.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := other.Authenticate(secret); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected the revocation to reach another instance, got %v", err)
+	}
+}
+
+func TestExpiredTokenRejected(t *testing.T) {
+	svc := newTestService(false)
+	expires := time.Now().Add(time.Hour)
+	_, secret, _ := svc.Create(Token{Name: "short", Owner: "acme", Scopes: []string{ScopeScreensRead}, ExpiresAt: &expires})
+	svc.now = func() time.Time { return expires.Add(time.Second) }
+	if _, err := svc.Authenticate(secret); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestRequireEnforcesScopeRateLimitAndRecordsUsage(t *testing.T) {
+	svc := newTestService(false)
+	h := svc.Require(ScopeScreensRead)(okHandler)
+
+	if rec := serve(h, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected anonymous request to pass through, got %d", rec.Code)
+	}
+	if rec := serve(h, "pg_unknown"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unknown token to be rejected, got %d", rec.Code)
+	}
+
+	writer, writerSecret, _ := svc.Create(Token{Name: "writer", Owner: "acme", Scopes: []string{ScopeJobsWrite}})
+	if rec := serve(h, writerSecret); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected missing scope to be forbidden, got %d", rec.Code)
+	}
+
+	reader, readerSecret, _ := svc.Create(Token{Name: "reader", Owner: "acme", Scopes: []string{ScopeScreensRead}, RateLimit: 2})
+	for i := 0; i < 2; i++ {
+		if rec := serve(h, readerSecret); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, rec.Code)
+		}
+	}
+	rec := serve(h, readerSecret)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("expected rate limit, got %d", rec.Code)
+	}
+
+	usage, _ := svc.Usage(reader.ID, 0)
+	if len(usage) != 3 || usage[0].Status != http.StatusTooManyRequests {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if got, _ := svc.Token(reader.ID); got.LastUsedAt == nil {
+		t.Fatalf("expected last use to be tracked")
+	}
+	if usage, _ := svc.Usage(writer.ID, 0); len(usage) != 1 || usage[0].Status != http.StatusForbidden {
+		t.Fatalf("expected forbidden request to be audited: %+v", usage)
+	}
+}
+
+func TestRequireRejectsAnonymousWhenRequired(t *testing.T) {
+	h := newTestService(true).Require(ScopeScreensRead)(okHandler)
+	if rec := serve(h, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package apitoken
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes admin endpoints for API token management.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a token handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the token endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListTokens)
+	r.Post("/", h.CreateToken)
+	r.Get("/scopes", h.ListScopes)
+	r.Get("/{tokenId}", h.GetToken)
+	r.Delete("/{tokenId}", h.RevokeToken)
+	r.Get("/{tokenId}/usage", h.ListUsage)
+}
+
+// CreateToken issues a token. The response is the only time the secret is
+// returned.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var payload Token
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	t, secret, err := h.service.Create(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create token", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, map[string]any{"token": t, "secret": secret})
+}
+
+// ListTokens returns every token without secrets.
+func (h *Handler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.service.Tokens()
+	if err != nil {
+		h.fail(w, r, "failed to list tokens", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+}
+
+// ListScopes returns the scopes a token can be granted.
+func (h *Handler) ListScopes(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]any{"scopes": Scopes})
+}
+
+// GetToken returns a token without its secret.
+func (h *Handler) GetToken(w http.ResponseWriter, r *http.Request) {
+	t, err := h.service.Token(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch token", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// RevokeToken disables a token.
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	t, err := h.service.Revoke(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		h.fail(w, r, "failed to revoke token", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// ListUsage returns a token's recent requests, newest first.
+func (h *Handler) ListUsage(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	usage, err := h.service.Usage(chi.URLParam(r, "tokenId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list token usage", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"usage": usage})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidToken):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
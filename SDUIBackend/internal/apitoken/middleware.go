@@ -0,0 +1,138 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// tokenKey is the context key for the authenticated token.
+type tokenKey struct{}
+
+// FromContext returns the token that authenticated the request, if any.
+func FromContext(ctx context.Context) (Token, bool) {
+	t, ok := ctx.Value(tokenKey{}).(Token)
+	return t, ok
+}
+
+// Require authenticates bearer tokens and enforces scope and rate limits. An
+// empty scope accepts any valid token. Requests without a token pass through
+// unless tokens are required, so first-party clients keep working while
+// third parties adopt tokens. Sandbox tokens are served by the sandbox.
+func (s *Service) Require(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret, ok := bearer(r)
+			if !ok {
+				if s.cfg.Required {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie"`)
+					respond.Error(w, http.StatusUnauthorized, "authentication required", "provide an API token as a bearer token")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			t, err := s.Authenticate(secret)
+			if err != nil {
+				if !errors.Is(err, ErrUnauthorized) {
+					middleware.LoggerFrom(r.Context()).Error("api token lookup failed", slog.Any("error", err))
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie", error="invalid_token"`)
+				respond.Error(w, http.StatusUnauthorized, "invalid token", "token is unknown, expired, or revoked")
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				s.record(t, Usage{
+					TokenID: t.ID,
+					Method:  r.Method,
+					Path:    r.URL.Path,
+					Status:  rec.status,
+					IP:      r.RemoteAddr,
+					At:      s.now().UTC(),
+				})
+			}()
+
+			if scope != "" && !t.HasScope(scope) {
+				respond.Error(rec, http.StatusForbidden, "insufficient scope", "token lacks scope "+scope)
+				return
+			}
+			if current, ok := tenant.FromContext(r.Context()); ok && t.TenantID != "" && current.ID != t.TenantID {
+				respond.Error(rec, http.StatusForbidden, "tenant mismatch", "token belongs to tenant "+t.TenantID)
+				return
+			}
+			allowed, limit, remaining := s.Allow(t)
+			rec.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+			rec.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			if !allowed {
+				respond.Error(rec, http.StatusTooManyRequests, "rate limit exceeded", "retry after the current minute", respond.WithRetryAfter(time.Minute))
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), tokenKey{}, t))
+			if t.Sandbox {
+				if s.sandbox == nil {
+					respond.Error(rec, http.StatusServiceUnavailable, "sandbox unavailable", "sandbox mode is not enabled on this server")
+					return
+				}
+				rec.Header().Set("X-PestGenie-Sandbox", t.Namespace())
+				s.sandbox.Handler(t.Namespace()).ServeHTTP(rec, r)
+				return
+			}
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// CredentialTenant returns the tenant the request's API token is bound to,
+// empty for unbound tokens. ok is false without a valid API token; Require
+// answers those requests itself.
+func (s *Service) CredentialTenant(r *http.Request) (string, bool) {
+	secret, ok := bearer(r)
+	if !ok {
+		return "", false
+	}
+	t, err := s.Authenticate(secret)
+	if err != nil {
+		return "", false
+	}
+	return t.TenantID, true
+}
+
+// bearer extracts a PestGenie API token from the Authorization header. Other
+// bearer credentials are left for later authentication layers.
+func bearer(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, value, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, secretPrefix) {
+		return "", false
+	}
+	return value, true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,179 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// secretPrefix marks PestGenie API token secrets so they are easy to spot in
+// logs and secret scanners.
+const secretPrefix = "pg_"
+
+// Sandbox serves requests made with sandbox tokens from an isolated
+// environment.
+type Sandbox interface {
+	Handler(namespace string) http.Handler
+}
+
+// Service issues, authenticates, and rate limits API tokens.
+type Service struct {
+	cfg     config.APITokenConfig
+	store   Store
+	sandbox Sandbox
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]window
+}
+
+// window is a fixed one-minute rate limiting window for a token.
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewService wires a token service. Requests with sandbox tokens are handed to
+// sandbox; when it is nil they are refused.
+func NewService(cfg config.APITokenConfig, store Store, sandbox Sandbox, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, sandbox: sandbox, logger: logger, now: time.Now, windows: make(map[string]window)}
+}
+
+// Create issues a token and returns it with its plaintext secret, which is
+// not retrievable afterwards.
+func (s *Service) Create(t Token) (Token, string, error) {
+	if err := t.Validate(); err != nil {
+		return Token{}, "", err
+	}
+	now := s.now().UTC()
+	if t.ExpiresAt != nil && !t.ExpiresAt.After(now) {
+		return Token{}, "", fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidToken)
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Token{}, "", err
+	}
+	secret := secretPrefix + hex.EncodeToString(raw)
+
+	t.ID = uuid.NewString()
+	t.Prefix = secret[:len(secretPrefix)+6]
+	t.Hash = hashSecret(secret)
+	t.CreatedAt = now
+	t.LastUsedAt = nil
+	t.LastUsedIP = ""
+	t.RevokedAt = nil
+	if err := s.store.SaveToken(t); err != nil {
+		return Token{}, "", err
+	}
+	s.logger.Info("api token created", slog.String("token", t.ID), slog.String("owner", t.Owner), slog.Any("scopes", t.Scopes))
+	return t, secret, nil
+}
+
+// Revoke disables a token immediately. Revoked tokens stay listed for audit.
+func (s *Service) Revoke(id string) (Token, error) {
+	t, err := s.store.GetToken(id)
+	if err != nil {
+		return Token{}, err
+	}
+	if t.RevokedAt == nil {
+		now := s.now().UTC()
+		t.RevokedAt = &now
+		if err := s.store.SaveToken(t); err != nil {
+			return Token{}, err
+		}
+		s.logger.Info("api token revoked", slog.String("token", t.ID), slog.String("owner", t.Owner))
+	}
+	return t, nil
+}
+
+// Token returns a single token.
+func (s *Service) Token(id string) (Token, error) {
+	return s.store.GetToken(id)
+}
+
+// Tokens lists every token, newest first.
+func (s *Service) Tokens() ([]Token, error) {
+	return s.store.ListTokens()
+}
+
+// Usage returns a token's recent requests, newest first.
+func (s *Service) Usage(id string, limit int) ([]Usage, error) {
+	if _, err := s.store.GetToken(id); err != nil {
+		return nil, err
+	}
+	return s.store.ListUsage(id, limit)
+}
+
+// Authenticate resolves a plaintext secret to an active token.
+func (s *Service) Authenticate(secret string) (Token, error) {
+	if !strings.HasPrefix(secret, secretPrefix) {
+		return Token{}, ErrUnauthorized
+	}
+	t, err := s.store.GetTokenByHash(hashSecret(secret))
+	if errors.Is(err, ErrNotFound) {
+		return Token{}, ErrUnauthorized
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	if !t.Active(s.now()) {
+		return Token{}, ErrUnauthorized
+	}
+	return t, nil
+}
+
+// Allow counts a request against the token's per-minute limit and returns
+// whether it may proceed, the limit, and the requests remaining.
+func (s *Service) Allow(t Token) (bool, int, int) {
+	limit := t.RateLimit
+	if limit <= 0 {
+		limit = s.cfg.DefaultRateLimit
+	}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.windows[t.ID]
+	if now.Sub(w.start) >= time.Minute {
+		w = window{start: now}
+	}
+	if w.count >= limit {
+		s.windows[t.ID] = w
+		return false, limit, 0
+	}
+	w.count++
+	s.windows[t.ID] = w
+	return true, limit, limit - w.count
+}
+
+// record updates last-used tracking and appends to the usage audit.
+func (s *Service) record(t Token, u Usage) {
+	if err := s.store.Touch(t.ID, u.At, u.IP); err != nil {
+		s.logger.Warn("record api token use", slog.String("token", t.ID), slog.Any("error", err))
+	}
+	if err := s.store.SaveUsage(u); err != nil {
+		s.logger.Warn("record api token usage", slog.String("token", t.ID), slog.Any("error", err))
+	}
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
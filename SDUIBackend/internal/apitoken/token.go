@@ -0,0 +1,300 @@
+package apitoken
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// Scopes grant access to groups of public endpoints.
+const (
+	ScopeScreensRead     = "screens:read"
+	ScopeUpdatesRead     = "updates:read"
+	ScopeJobsRead        = "jobs:read"
+	ScopeJobsWrite       = "jobs:write"
+	ScopeChemicalsRead   = "chemicals:read"
+	ScopeChemicalsWrite  = "chemicals:write"
+	ScopeTreatmentsWrite = "treatments:write"
+	ScopeDevicesRead     = "devices:read"
+	ScopeDevicesWrite    = "devices:write"
+	ScopePhotosRead      = "photos:read"
+	ScopePhotosWrite     = "photos:write"
+	ScopeInventoryRead   = "inventory:read"
+	ScopeInventoryWrite  = "inventory:write"
+	ScopeDisposalsRead   = "disposals:read"
+	ScopeDisposalsWrite  = "disposals:write"
+	ScopeTankMixesRead   = "tank-mixes:read"
+	ScopeEquipmentRead   = "equipment:read"
+	ScopeEquipmentWrite  = "equipment:write"
+	ScopeInboxRead       = "inbox:read"
+)
+
+// Scopes lists every scope a token can be granted.
+var Scopes = []string{
+	ScopeChemicalsRead,
+	ScopeChemicalsWrite,
+	ScopeDevicesRead,
+	ScopeDevicesWrite,
+	ScopeDisposalsRead,
+	ScopeDisposalsWrite,
+	ScopeEquipmentRead,
+	ScopeEquipmentWrite,
+	ScopeInboxRead,
+	ScopeInventoryRead,
+	ScopeInventoryWrite,
+	ScopeJobsRead,
+	ScopeJobsWrite,
+	ScopePhotosRead,
+	ScopePhotosWrite,
+	ScopeScreensRead,
+	ScopeTankMixesRead,
+	ScopeTreatmentsWrite,
+	ScopeUpdatesRead,
+}
+
+var (
+	// ErrNotFound is returned when a token does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidToken wraps token definition validation failures.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnauthorized is returned for unknown, expired, or revoked secrets.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Token is a scoped credential issued to a third-party developer. Only a
+// hash of the secret is stored; the secret itself is shown once at creation.
+type Token struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Owner      string     `json:"owner"`
+	Prefix     string     `json:"prefix"` // first characters of the secret, for identification
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rateLimit,omitempty"` // requests per minute, 0 uses the default
+	Sandbox    bool       `json:"sandbox"`             // route requests to the owner's sandbox
+	TenantID   string     `json:"tenantId,omitempty"`  // the only tenant the token may act for, when set
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Validate checks a token definition.
+func (t Token) Validate() error {
+	var problems []string
+	if t.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if t.Owner == "" {
+		problems = append(problems, "owner is required")
+	}
+	if len(t.Scopes) == 0 {
+		problems = append(problems, "at least one scope is required")
+	}
+	for _, s := range t.Scopes {
+		if !knownScope(s) {
+			problems = append(problems, fmt.Sprintf("unknown scope %q", s))
+		}
+	}
+	if t.RateLimit < 0 {
+		problems = append(problems, "rateLimit must be >= 0")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidToken, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Namespace is the sandbox namespace for a sandbox token. Tokens of the same
+// owner share one sandbox.
+func (t Token) Namespace() string {
+	return t.Owner
+}
+
+// Active reports whether the token can be used at now.
+func (t Token) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted scope.
+func (t Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func knownScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage records one authenticated request for the admin audit.
+type Usage struct {
+	TokenID string    `json:"tokenId"`
+	Method  string    `json:"method"`
+	Path    string    `json:"path"`
+	Status  int       `json:"status"`
+	IP      string    `json:"ip"`
+	At      time.Time `json:"at"`
+}
+
+// Store persists tokens and their usage.
+type Store interface {
+	SaveToken(t Token) error
+	GetToken(id string) (Token, error)
+	GetTokenByHash(hash string) (Token, error)
+	ListTokens() ([]Token, error)
+	// Touch records the last use of a token.
+	Touch(id string, at time.Time, ip string) error
+	SaveUsage(u Usage) error
+	ListUsage(tokenID string, limit int) ([]Usage, error)
+}
+
+// maxUsage bounds the usage log kept for each token.
+const maxUsage = 1000
+
+// storedToken is a token as it is saved, with the hash its JSON leaves out.
+type storedToken struct {
+	Token
+	Hash string `json:"hash"`
+}
+
+// usageLog is the recent usage of one token, oldest first.
+type usageLog struct {
+	Entries []Usage `json:"entries"`
+}
+
+// DocumentStore keeps tokens and their usage in the shared document store,
+// so a token issued through one instance authenticates on every other and
+// survives restarts.
+type DocumentStore struct {
+	tokens docstore.Collection[storedToken]
+	usage  docstore.Collection[usageLog] // keyed by token ID
+}
+
+// NewDocumentStore keeps tokens and their usage in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{
+		tokens: docstore.NewCollection(docs, "api_tokens", func(t storedToken) docstore.Keys {
+			return docstore.Keys{"hash": t.Hash}
+		}),
+		usage: docstore.NewCollection[usageLog](docs, "api_token_usage", nil),
+	}
+}
+
+// NewMemoryStore keeps tokens in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveToken(t Token) error {
+	return d.tokens.Put(t.ID, storedToken{Token: t, Hash: t.Hash})
+}
+
+func (d *DocumentStore) GetToken(id string) (Token, error) {
+	st, err := d.tokens.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Token{}, ErrNotFound
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	return st.token(), nil
+}
+
+func (d *DocumentStore) GetTokenByHash(hash string) (Token, error) {
+	found, err := d.tokens.Find(docstore.Keys{"hash": hash})
+	if err != nil {
+		return Token{}, err
+	}
+	if len(found) == 0 {
+		return Token{}, ErrNotFound
+	}
+	return found[0].token(), nil
+}
+
+// ListTokens returns every token, newest first.
+func (d *DocumentStore) ListTokens() ([]Token, error) {
+	found, err := d.tokens.Find(nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Token, len(found))
+	for i, st := range found {
+		out[i] = st.token()
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (d *DocumentStore) Touch(id string, at time.Time, ip string) error {
+	_, err := d.tokens.Update(id, func(current *storedToken) (storedToken, error) {
+		if current == nil {
+			return storedToken{}, ErrNotFound
+		}
+		st := *current
+		st.LastUsedAt = &at
+		st.LastUsedIP = ip
+		return st, nil
+	})
+	return err
+}
+
+func (d *DocumentStore) SaveUsage(u Usage) error {
+	_, err := d.usage.Update(u.TokenID, func(current *usageLog) (usageLog, error) {
+		var log usageLog
+		if current != nil {
+			log = *current
+		}
+		log.Entries = append(log.Entries, u)
+		if len(log.Entries) > maxUsage {
+			log.Entries = log.Entries[len(log.Entries)-maxUsage:]
+		}
+		return log, nil
+	})
+	return err
+}
+
+// ListUsage returns a token's usage, newest first.
+func (d *DocumentStore) ListUsage(tokenID string, limit int) ([]Usage, error) {
+	log, err := d.usage.Get(tokenID)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Usage
+	for i := len(log.Entries) - 1; i >= 0; i-- {
+		out = append(out, log.Entries[i])
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// token returns the stored token with its hash.
+func (st storedToken) token() Token {
+	t := st.Token
+	t.Hash = st.Hash
+	return t
+}
@@ -1,96 +1,810 @@
 package app
 
 import (
-    "net/http"
-    "os"
-    "path/filepath"
-
-    "github.com/go-chi/chi/v5"
-    chimw "github.com/go-chi/chi/v5/middleware"
-
-    "log/slog"
-
-    "github.com/your-org/pestgenie-sdui/internal/config"
-    domrepo "github.com/your-org/pestgenie-sdui/internal/domain/repository"
-    "github.com/your-org/pestgenie-sdui/internal/http/respond"
-    "github.com/your-org/pestgenie-sdui/internal/middleware"
-    "github.com/your-org/pestgenie-sdui/internal/sdui"
-    "github.com/your-org/pestgenie-sdui/internal/swaggerui"
-    syncapi "github.com/your-org/pestgenie-sdui/internal/sync"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"log/slog"
+
+	domrepo "github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/access"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/address"
+	"github.com/your-org/pestgenie-sdui/internal/anomaly"
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/asset"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+	"github.com/your-org/pestgenie-sdui/internal/capability"
+	"github.com/your-org/pestgenie-sdui/internal/carplay"
+	"github.com/your-org/pestgenie-sdui/internal/chaos"
+	"github.com/your-org/pestgenie-sdui/internal/chemical"
+	"github.com/your-org/pestgenie-sdui/internal/clientconfig"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/connector"
+	"github.com/your-org/pestgenie-sdui/internal/dedupe"
+	"github.com/your-org/pestgenie-sdui/internal/disposal"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/eta"
+	"github.com/your-org/pestgenie-sdui/internal/events"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/export"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/geocode"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/impersonate"
+	"github.com/your-org/pestgenie-sdui/internal/ingest"
+	"github.com/your-org/pestgenie-sdui/internal/intents"
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+	"github.com/your-org/pestgenie-sdui/internal/liveactivity"
+	"github.com/your-org/pestgenie-sdui/internal/localization"
+	"github.com/your-org/pestgenie-sdui/internal/lockout"
+	"github.com/your-org/pestgenie-sdui/internal/messaging"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/notify"
+	"github.com/your-org/pestgenie-sdui/internal/operation"
+	"github.com/your-org/pestgenie-sdui/internal/outbound"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/playback"
+	"github.com/your-org/pestgenie-sdui/internal/profit"
+	"github.com/your-org/pestgenie-sdui/internal/promotion"
+	"github.com/your-org/pestgenie-sdui/internal/ratelimit"
+	"github.com/your-org/pestgenie-sdui/internal/recall"
+	"github.com/your-org/pestgenie-sdui/internal/replay"
+	"github.com/your-org/pestgenie-sdui/internal/routing"
+	"github.com/your-org/pestgenie-sdui/internal/sandbox"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+	"github.com/your-org/pestgenie-sdui/internal/serviceplan"
+	"github.com/your-org/pestgenie-sdui/internal/servicereport"
+	"github.com/your-org/pestgenie-sdui/internal/signature"
+	"github.com/your-org/pestgenie-sdui/internal/simulate"
+	"github.com/your-org/pestgenie-sdui/internal/snapshot"
+	"github.com/your-org/pestgenie-sdui/internal/statuspage"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+	"github.com/your-org/pestgenie-sdui/internal/stream"
+	"github.com/your-org/pestgenie-sdui/internal/swaggerui"
+	syncapi "github.com/your-org/pestgenie-sdui/internal/sync"
+	"github.com/your-org/pestgenie-sdui/internal/tankmix"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+	"github.com/your-org/pestgenie-sdui/internal/tracing"
+	"github.com/your-org/pestgenie-sdui/internal/usage"
+	"github.com/your-org/pestgenie-sdui/internal/voicenote"
+	"github.com/your-org/pestgenie-sdui/internal/weather"
+	"github.com/your-org/pestgenie-sdui/internal/widget"
+	"github.com/your-org/pestgenie-sdui/internal/worker"
 )
 
 // Server wraps the HTTP router so main can expose it cleanly.
 type Server struct {
-    Router chi.Router
-    cfg    config.Config
-    repos  domrepo.Repository
-    logger *slog.Logger
+	Router   chi.Router
+	cfg      config.Config
+	repos    domrepo.Repository
+	brownout *brownout.Monitor
+	deferred *brownout.DeferredWrites
+	assets   *asset.Service
+	uploads  *syncapi.Handler
+	jobs     *jobqueue.Queue
+	bus      *events.Bus
+	tenants  *tenantEnvs
+	logger   *slog.Logger
 }
 
-// NewServer wires routing, middleware, and feature handlers.
-func NewServer(cfg config.Config, repos domrepo.Repository, logger *slog.Logger) *Server {
-    if err := repos.Validate(); err != nil {
-        panic(err)
-    }
-
-    router := chi.NewRouter()
-
-    router.Use(chimw.RequestID)
-    router.Use(chimw.RealIP)
-    router.Use(chimw.Logger)
-    router.Use(chimw.Recoverer)
-    router.Use(chimw.Timeout(cfg.Server.ReadTimeout))
-    router.Use(middleware.Correlation())
-    router.Use(middleware.WithLogger(logger))
-    router.Use(middleware.RequestLogger(logger))
-
-    staticDir := os.Getenv("SCREEN_TEMPLATE_DIR")
-    if staticDir == "" {
-        staticDir = filepath.Join("static", "screens")
-    }
-
-    sduiService := sdui.NewService(staticDir, repos, logger)
-    sduiHandler := sdui.NewHandler(sduiService)
-    syncHandler := syncapi.NewHandler(repos, cfg.Sync, logger)
-
-    router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        w.WriteHeader(http.StatusOK)
-        _, _ = w.Write([]byte(`{"status":"ok"}`))
-    })
-
-    router.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
-        if err := repos.Validate(); err != nil {
-            respond.Error(w, http.StatusServiceUnavailable, "service not ready", err.Error())
-            return
-        }
-        respond.JSON(w, http.StatusOK, map[string]string{"status": "ready"})
-    })
-
-    if cfg.Server.EnableSwagger {
-        router.Get("/swagger", swaggerui.UIHandler)
-        router.Get("/swagger/doc.json", swaggerui.SpecHandler)
-    }
+// Stores holds the stores the server keeps besides the domain
+// repositories. Every instance must share them, so main builds them for the
+// configured datastore.
+type Stores struct {
+	// Flags keeps the flags defined through the admin API.
+	Flags flags.Store
+	// Nonces remembers the nonces of signed requests.
+	Nonces replay.NonceStore
+	// Documents keeps the records of features without a schema of their
+	// own, such as tenants.
+	Documents docstore.Store
+}
 
-	router.Route("/v1", func(r chi.Router) {
-		r.Route("/screens", func(sr chi.Router) {
-			sr.Get("/{screenId}", sduiHandler.GetScreen)
+// Validate ensures all stores are present.
+func (s Stores) Validate() error {
+	if s.Flags == nil {
+		return domrepo.ErrMissingRepository{Name: "flags"}
+	}
+	if s.Nonces == nil {
+		return domrepo.ErrMissingRepository{Name: "nonces"}
+	}
+	if s.Documents == nil {
+		return domrepo.ErrMissingRepository{Name: "documents"}
+	}
+	return nil
+}
+
+// NewServer wires routing, middleware, and feature handlers. tracer and
+// faults may be nil when tracing and fault injection are off.
+func NewServer(cfg config.Config, repos domrepo.Repository, stores Stores, secrets secret.Provider, tracer *tracing.Tracer, faults *chaos.Injector, logger *slog.Logger) *Server {
+	if err := repos.Validate(); err != nil {
+		panic(err)
+	}
+	if err := stores.Validate(); err != nil {
+		panic(err)
+	}
+
+	monitor := brownout.NewMonitor(cfg.Brownout)
+	deferred := brownout.NewDeferredWrites(monitor, cfg.Brownout.DeferredQueueSize, logger)
+	// Injected latency counts towards brownout like real latency.
+	repos = brownout.Instrument(faults.Repository(repos), monitor)
+
+	// Every save below is emitted as a domain event. Tenant environments
+	// also record route and job saves for playback and publish them to
+	// device streams.
+	eventBus := events.NewBus(cfg.Events, events.NewPublisher(cfg.Events), logger)
+	repos = events.Publish(repos, eventBus)
+
+	router := chi.NewRouter()
+
+	router.Use(chimw.RequestID)
+	router.Use(middleware.RealIP(cfg.Server.TrustedProxies))
+	router.Use(chimw.Logger)
+	router.Use(chimw.Recoverer)
+	router.Use(middleware.Timeout(cfg.Server.ReadTimeout, "/v1/stream", "/v1/ws"))
+	router.Use(middleware.Correlation())
+	router.Use(middleware.WithLogger(logger))
+	router.Use(tracer.Middleware)
+	router.Use(middleware.RequestLogger(logger))
+	router.Use(respond.Localize)
+	router.Use(middleware.CORS(cfg.Server.AllowedOrigins, cfg.Environment == config.EnvProd))
+	if cfg.Server.Compression {
+		router.Use(middleware.Compress(cfg.Server.CompressionMinBytes, cfg.Server.CompressionLevel))
+	}
+	if cfg.Server.Decompression {
+		router.Use(middleware.Decompress(cfg.Server.MaxDecompressedBytes))
+	}
+	router.Use(faults.Middleware)
+
+	staticDir := os.Getenv("SCREEN_TEMPLATE_DIR")
+	if staticDir == "" {
+		staticDir = filepath.Join("static", "screens")
+	}
+
+	activityService := activity.NewService(cfg.Activity, activity.NewMemoryStore(), repos, logger)
+	activityHandler := activity.NewHandler(activityService)
+
+	// Chemical uploads are checked against the catalog, which the app
+	// also searches when a technician adds a chemical.
+	catalog, err := chemical.Load(cfg.Chemicals)
+	if err != nil {
+		panic(err)
+	}
+	catalogHandler := chemical.NewHandler(catalog)
+
+	experimentService := experiment.NewService(experiment.NewMemoryStore(), repos, logger)
+	weatherService := weather.NewService(cfg.Weather, weather.NewProvider(cfg.Weather, secrets), logger)
+	experimentHandler := experiment.NewHandler(experimentService)
+
+	// Flags come from the tenant's flag service when one is configured, so
+	// screens are targeted with the flags it already manages. Flags set
+	// through the admin API override it.
+	flagProvider, err := flags.NewProvider(cfg.Flags, secrets)
+	if err != nil {
+		panic(err)
+	}
+	runtimeFlags := flags.NewRuntimeProvider(flagProvider, stores.Flags, cfg.Flags.CacheTTL, logger)
+	flagClient := flags.NewClient(runtimeFlags, logger)
+	flagHandler := flags.NewHandler(flagClient, runtimeFlags, repos.Technicians)
+
+	// Tenants override flags and brand screens; with tenancy disabled
+	// tenantService is nil and every request sees every record.
+	tenantService, err := tenant.NewService(cfg.Tenancy, tenant.NewDocumentStore(stores.Documents), logger)
+	if err != nil {
+		panic(err)
+	}
+	tenantHandler := tenant.NewHandler(tenantService)
+	// Admin and sign-in requests must come from the configured networks
+	// and countries, and those of the tenants they act for.
+	accessGuard, err := access.NewGuard(cfg.Access, tenantService, activityService)
+	if err != nil {
+		panic(err)
+	}
+	// Repeated failed sign-ins from an IP or on a technician are slowed
+	// down, then locked out; lockouts are alerted through the feed and
+	// domain events.
+	lockouts := lockout.NewTracker(cfg.Lockout, lockout.NewDocumentStore(stores.Documents), activityService, eventBus, logger)
+
+	sduiService := sdui.NewService(staticDir, cfg.Screens, repos, monitor, cfg.Brownout.StaleTTL, experimentService, weatherService, tenantService.Flags(flagClient), logger)
+	sduiHandler := sdui.NewHandler(sduiService, activityService, tenantService)
+	snapshotHandler := snapshot.NewHandler(snapshot.NewService(snapshot.NewMemoryStore(), sduiService, logger))
+	simulationHandler := simulate.NewHandler(simulate.NewService(simulate.NewMemoryStore(), sduiService, logger))
+	promotionHandler := promotion.NewHandler(promotion.NewService(cfg.Promotion, cfg.Environment, secrets, sduiService, experimentService, flagProvider, logger))
+
+	// Uploaded translations are layered over the catalog files, now and
+	// whenever they change. Machine translation drafts start from the
+	// screens' untranslated strings.
+	translator := localization.NewMachineTranslator(cfg.Translation, secrets)
+	translationService := localization.NewService(cfg.Translation, localization.NewMemoryStore(), translator, logger)
+	translationService.OnChange(sduiService.TranslationsUploaded)
+	translationHandler := localization.NewHandler(translationService, sduiService)
+	if uploaded, err := translationService.Catalogs(); err != nil {
+		logger.Warn("uploaded translations unavailable", slog.Any("error", err))
+	} else {
+		for _, c := range uploaded {
+			sduiService.TranslationsUploaded(c.Locale, c.Messages)
+		}
+	}
+
+	// Warm the template cache before accepting traffic so the first requests
+	// after a deploy don't pay for parsing.
+	report := sduiService.Precompile()
+	for _, failure := range report.Failed {
+		logger.Warn("template precompilation failed",
+			slog.String("screen", failure.ScreenID),
+			slog.String("source", failure.Source),
+			slog.String("error", failure.Error),
+		)
+	}
+	if len(report.NeedsManualFix) > 0 {
+		logger.Warn("templates use retired components", slog.Any("screens", report.NeedsManualFix))
+	}
+	logger.Info("templates precompiled", slog.Int("compiled", len(report.Compiled)), slog.Int("failed", len(report.Failed)), slog.Int("locales", len(report.Locales)))
+
+	blobs, err := storage.New(cfg.Photos, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	// Templates are served the current URL of the assets they name, now and
+	// whenever an upload replaces one.
+	assetService := asset.NewService(cfg.Assets, asset.NewMemoryStore(), blobs, sduiService, logger)
+	assetService.OnChange(sduiService.AssetChanged)
+	assetHandler := asset.NewHandler(assetService)
+	if assets, err := assetService.Assets(); err != nil {
+		logger.Warn("assets unavailable", slog.Any("error", err))
+	} else {
+		for _, a := range assets {
+			sduiService.AssetChanged(a.ID, a.Current().URL)
+		}
+	}
+
+	reportLayout, err := servicereport.LoadLayout(cfg.Reports.LayoutPath)
+	if err != nil {
+		panic(err)
+	}
+	reportRenderer := servicereport.NewRenderer(cfg.Reports)
+
+	clientConfigHandler := clientconfig.NewHandler(clientconfig.NewService(cfg.Sync, clientconfig.NewMemoryStore(), logger))
+	capabilityHandler := capability.NewHandler(capability.NewService(cfg, false))
+	sandboxCapabilities := capability.NewHandler(capability.NewService(cfg, true))
+
+	// Sandbox environments run the same public API over isolated seeded
+	// stores and their own prefix of the blob store, without brownout,
+	// deferred writes, connector events, branches, branch calendars, screen
+	// experiments, template assets, weather, or transcription.
+	var sandboxes *sandbox.Manager
+	sandboxes = sandbox.NewManager(func(namespace string, repos domrepo.Repository, blobs storage.BlobStore) http.Handler {
+		bus := stream.NewBus(cfg.Stream)
+		repos = stream.Publish(repos, bus)
+		screens := sdui.NewService(staticDir, cfg.Screens, repos, nil, cfg.Brownout.StaleTTL, nil, nil, nil, logger)
+		screens.Precompile()
+		sr := chi.NewRouter()
+		sr.Route("/v1", func(r chi.Router) {
+			notes := voicenote.NewService(cfg.VoiceNotes, voicenote.NewMemoryStore(), voicenote.NewIndex(), nil, logger)
+			photos := photo.NewService(cfg.Photos, photo.NewMemoryStore(), blobs, logger)
+			plans := serviceplan.NewService(cfg.ServicePlan, serviceplan.NewMemoryStore(), repos, nil, logger)
+			durations := schedule.NewService(cfg.Schedule, schedule.NewMemoryStore(), repos, nil, nil, logger)
+			stock := inventory.NewService(cfg.Inventory, inventory.NewMemoryStore(), repos, nil, logger)
+			waste := disposal.NewService(disposal.NewMemoryStore(), repos, nil, logger)
+			equip := calibration.NewService(cfg.Calibration, calibration.NewMemoryStore(), repos, logger)
+			mixes := tankmix.NewService(tankmix.NewMemoryStore(), repos, nil, equip, logger)
+			live := liveactivity.NewService(cfg.Live, liveactivity.NewMemoryStore(), repos, nil, logger)
+			reports := servicereport.NewService(cfg.Reports, repos, photos, blobs, reportRenderer, reportLayout, logger)
+			publicRoutes(r, sdui.NewHandler(screens, nil, nil), syncapi.NewHandler(repos, cfg.Sync, nil, nil, nil, equip, live, nil, catalog, photos, logger), voicenote.NewHandler(notes), photo.NewHandler(photos), serviceplan.NewHandler(plans), schedule.NewHandler(durations), inventory.NewHandler(stock), disposal.NewHandler(waste), tankmix.NewHandler(mixes), calibration.NewHandler(equip), notify.NewHandler(notify.NewService(cfg.Notify, notify.NewMemoryStore(), repos, nil, logger)), liveactivity.NewHandler(live), widget.NewHandler(widget.NewService(cfg.Widget, repos)), carplay.NewHandler(carplay.NewService(cfg.CarPlay, repos)), intents.NewHandler(intents.NewService(cfg.Intents, repos)), catalogHandler, servicereport.NewHandler(reports), signature.NewHandler(signature.NewService(repos, photos, logger)), playback.NewHandler(playback.NewService(cfg.Playback, playback.NewMemoryStore(), nil, logger), nil), stream.NewHandler(cfg.Stream, bus), messaging.NewHandler(messaging.NewService(cfg.Messaging, messaging.NewMemoryStore(), repos, logger)), clientConfigHandler, sandboxCapabilities, unscoped)
+			r.Post("/sandbox/reset", sandboxes.ResetOwn(namespace))
+		})
+		return sr
+	}, blobs, logger)
+	sandboxHandler := sandbox.NewHandler(sandboxes)
+
+	tokenService := apitoken.NewService(cfg.APITokens, apitoken.NewDocumentStore(stores.Documents), sandboxes, logger)
+	tokenHandler := apitoken.NewHandler(tokenService)
+
+	verifier := auth.NewVerifier(cfg.Auth, repos.Technicians)
+	// Signed writes are checked for replays; nil when signing is off.
+	replayGuard := replay.NewGuard(cfg.Signing, secrets, stores.Nonces)
+	authHandler := auth.NewHandler(verifier, repos.Technicians)
+	switch {
+	case verifier.Signing():
+		logger.Warn("jwt hmac signing mode enabled; anyone reaching /v1/auth/dev-tokens can sign in as any technician or role")
+	case !verifier.Enabled():
+		logger.Warn("jwt authentication not configured; admin routes are not role-checked")
+	}
+
+	impersonation := impersonate.NewService(cfg.Impersonate, impersonate.NewDocumentStore(stores.Documents), repos.Technicians, logger)
+	impersonationHandler := impersonate.NewHandler(impersonation)
+
+	counties, err := usage.LoadCounties(cfg.Usage.CountiesPath)
+	if err != nil {
+		panic(err)
+	}
+	distances := routing.NewDistanceProvider(cfg.Routing, secrets)
+	addressService := address.NewService(cfg.Address, address.NewValidator(cfg.Address, secrets), address.NewMemoryStore(), logger)
+	addressHandler := address.NewHandler(addressService)
+
+	jobs, err := jobqueue.New(cfg.Queue, logger)
+	if err != nil {
+		panic(err)
+	}
+	// Stale devices and tombstones are pruned across every tenant at once.
+	pruner := syncapi.NewHandler(repos, cfg.Sync, nil, nil, nil, nil, nil, nil, catalog, nil, logger)
+
+	// Each tenant gets the services below over repositories scoped to it,
+	// with stores of their own and jobs run from its own queue partition,
+	// so no tenant's requests or background loops touch another's records.
+	tenants := newTenantEnvs(func(scope tenant.Scope, scoped bool) *tenantEnv {
+		repos, jobs, docs, tenantID := repos, jobs, stores.Documents, ""
+		if scoped {
+			tenantID = scope.Tenant.ID
+			repos = scope.Repository(repos)
+			jobs = jobs.Partition(scope.Tenant.ID)
+			docs = docstore.Partition(docs, scope.Tenant.ID)
+		}
+
+		calendarService, err := calendar.NewService(cfg.Calendar, calendar.NewMemoryStore(), repos, logger)
+		if err != nil {
+			panic(err)
+		}
+		// Every route and job save below is recorded for playback, as are
+		// treatments and device registrations. Device streams follow the
+		// tenant's domain events, so they see saves made through any
+		// instance.
+		playbackService := playback.NewService(cfg.Playback, playback.NewMemoryStore(), calendarService, logger)
+		repos = playback.Record(repos, playbackService)
+		streamBus := stream.NewBus(cfg.Stream)
+		stream.Follow(eventBus, tenantID, streamBus)
+
+		notifyService := notify.NewService(cfg.Notify, notify.NewMemoryStore(), repos, nil, logger)
+		liveService := liveactivity.NewService(cfg.Live, liveactivity.NewMemoryStore(), repos, nil, logger)
+
+		branchService := branch.NewService(branch.NewMemoryStore(), repos, logger)
+		inventoryService := inventory.NewService(cfg.Inventory, inventory.NewMemoryStore(), repos, branchService, logger)
+		inventoryHandler := inventory.NewHandler(inventoryService)
+		disposalHandler := disposal.NewHandler(disposal.NewService(disposal.NewMemoryStore(), repos, branchService, logger))
+		reviewService := anomaly.NewService(cfg.Anomaly, anomaly.NewMemoryStore(), repos, logger)
+		etaService := eta.NewService(cfg.ETA, eta.NewDocumentStore(docs), repos, calendarService, logger)
+		etaHandler := eta.NewHandler(etaService)
+		connectorService := connector.NewService(cfg.Connector, connector.NewDocumentStore(docs), jobs, secrets, logger)
+		calibrationService := calibration.NewService(cfg.Calibration, calibration.NewMemoryStore(), repos, logger)
+		calibrationHandler := calibration.NewHandler(calibrationService)
+		photoService := photo.NewService(cfg.Photos, photo.NewMemoryStore(), blobs, logger)
+		syncHandler := syncapi.NewHandler(repos, cfg.Sync, deferred, connectorService, activityService, calibrationService, liveService, weatherService, catalog, photoService, logger)
+		tankMixHandler := tankmix.NewHandler(tankmix.NewService(tankmix.NewMemoryStore(), repos, connectorService, calibrationService, logger))
+		voiceService := voicenote.NewService(cfg.VoiceNotes, voicenote.NewMemoryStore(), voicenote.NewIndex(), voicenote.NewTranscriber(cfg.VoiceNotes, secrets), logger)
+		voiceHandler := voicenote.NewHandler(voiceService)
+		playbackHandler := playback.NewHandler(playbackService, activityService)
+		// Messages are stored in the tenant's documents and announced as
+		// domain events, so a technician's socket receives them whichever
+		// instance holds it.
+		messagingService := messaging.NewService(cfg.Messaging, messaging.NewDocumentStore(docs), repos, logger)
+		messagingService.Broadcast(eventBus, tenantID)
+		messagingHandler := messaging.NewHandler(messagingService)
+		planHandler := serviceplan.NewHandler(serviceplan.NewService(cfg.ServicePlan, serviceplan.NewMemoryStore(), repos, calendarService, logger))
+
+		scheduleService := schedule.NewService(cfg.Schedule, schedule.NewMemoryStore(), repos, connectorService, calendarService, logger)
+		scheduleHandler := schedule.NewHandler(scheduleService)
+		routingService := routing.NewService(cfg.Routing, repos, distances, scheduleService, activityService, notifyService, logger)
+		profitService := profit.NewService(cfg.Profit, profit.NewMemoryStore(), repos, scheduleService, distances, inventoryService, logger)
+		profitHandler := profit.NewHandler(profitService)
+		dedupeService := dedupe.NewService(cfg.Dedupe, dedupe.NewMemoryStore(), repos, profitService, logger)
+
+		operationService := operation.NewService(cfg.Operations, operation.NewDocumentStore(docs), jobs, logger)
+		exportService := export.NewService(cfg.Export, export.NewMemoryStore(), repos, tenantService, calibrationService, secrets, export.NewHTTPObjectWriter(cfg.Export.RequestTimeout), logger)
+		outboundService := outbound.NewService(cfg.Outbound, tenantID, outbound.NewMemoryStore(), repos.Sync, map[string]outbound.Transport{
+			outbound.MethodDirectory: outbound.DirectoryTransport{Root: cfg.Outbound.DropDir},
+			outbound.MethodSFTP:      outbound.SFTPTransport{Secrets: secrets},
+		}, logger)
+		ingestService := ingest.NewService(cfg.Inbound, ingest.NewMemoryStore(), repos, map[string]ingest.Source{
+			ingest.MethodDirectory: ingest.DirectorySource{Root: cfg.Inbound.DropDir},
+			ingest.MethodSFTP:      ingest.SFTPSource{Secrets: secrets},
+		}, scheduleService, addressService, activityService, notifyService, logger)
+		workerService := worker.NewService(cfg.Worker, repos, jobs, worker.NewDocumentStore(docs), docs, geocode.NewGeocoder(cfg.Geocoding, secrets), logger)
+		workerHandler := worker.NewHandler(workerService)
+
+		reports := servicereport.NewService(cfg.Reports, repos, photoService, blobs, reportRenderer, reportLayout, logger)
+
+		public := chi.NewRouter()
+		public.Route("/v1", func(r chi.Router) {
+			publicRoutes(r, sduiHandler, syncHandler, voiceHandler, photo.NewHandler(photoService), planHandler, scheduleHandler, inventoryHandler, disposalHandler, tankMixHandler, calibrationHandler, notify.NewHandler(notifyService), liveactivity.NewHandler(liveService), widget.NewHandler(widget.NewService(cfg.Widget, repos)), carplay.NewHandler(carplay.NewService(cfg.CarPlay, repos)), intents.NewHandler(intents.NewService(cfg.Intents, repos)), catalogHandler, servicereport.NewHandler(reports), signature.NewHandler(signature.NewService(repos, photoService, logger)), playbackHandler, stream.NewHandler(cfg.Stream, streamBus), messagingHandler, clientConfigHandler, capabilityHandler, tokenService.Require)
+			r.Route("/operations", operation.NewHandler(operationService).Routes)
 		})
 
-		r.Route("/jobs", func(jr chi.Router) {
-			jr.Post("/", syncHandler.CreateJob)
+		admin := chi.NewRouter()
+		admin.Route("/v1/admin", func(ar chi.Router) {
+			ar.Group(func(adm chi.Router) {
+				adm.Use(verifier.RequireRole(auth.RoleAdmin))
+				adm.Route("/exports/destinations", export.NewHandler(exportService, operationService).Routes)
+				adm.Route("/integrations/outbound", outbound.NewHandler(outboundService, operationService).Routes)
+				adm.Route("/integrations/inbound", ingest.NewHandler(ingestService, operationService).Routes)
+				adm.Route("/integrations/connectors", connector.NewHandler(connectorService).Routes)
+				adm.Route("/profitability/invoices", profitHandler.InvoiceRoutes)
+				adm.Route("/profitability/rates", profitHandler.RateRoutes)
+				adm.Route("/duplicates", dedupe.NewHandler(dedupeService).Routes)
+			})
+			ar.Route("/eta-links", etaHandler.Routes)
+			ar.Route("/voice-notes", voiceHandler.Routes)
+			ar.Route("/service-plans", planHandler.Routes)
+			ar.Route("/schedule", scheduleHandler.Routes)
+			ar.Route("/routes", routing.NewHandler(routingService).Routes)
+			ar.Route("/calendars", calendar.NewHandler(calendarService).Routes)
+			ar.Route("/playback", playbackHandler.Routes)
+			ar.Route("/messages", messagingHandler.Routes)
+			ar.Route("/branches", branch.NewHandler(branchService).Routes)
+			ar.Route("/inventory", inventoryHandler.Routes)
+			ar.Route("/recalls", recall.NewHandler(recall.NewService(repos)).Routes)
+			ar.Route("/disposals", disposalHandler.Routes)
+			ar.Route("/reports", usage.NewHandler(usage.NewService(repos, branchService, counties, reviewService)).Routes)
+			ar.Route("/treatment-reviews", anomaly.NewHandler(reviewService).Routes)
+			ar.Route("/profitability", profitHandler.Routes)
+			ar.Route("/tank-mixes", tankMixHandler.Routes)
+			ar.Route("/equipment", calibrationHandler.Routes)
+			ar.Route("/sync", syncHandler.AdminRoutes)
+			ar.Route("/processing", workerHandler.Routes)
+			ar.Route("/dead-letters", workerHandler.DeadLetterRoutes)
 		})
-		r.Route("/chemicals", func(cr chi.Router) {
-			cr.Post("/", syncHandler.CreateChemical)
+
+		return &tenantEnv{
+			public:    public,
+			admin:     admin,
+			eta:       etaService,
+			etaPublic: etaHandler.Public,
+			connector: connectorService,
+			worker:    workerService,
+			notify:    notifyService,
+			live:      liveService,
+			loops: []func(context.Context){
+				exportService.Run,
+				outboundService.Run,
+				ingestService.Run,
+				notifyService.Run,
+				connectorService.Run,
+				voiceService.Run,
+				inventoryService.Run,
+				workerService.Run,
+				operationService.Run,
+				playbackService.Run,
+				messagingService.Run,
+				reviewService.Run,
+				dedupeService.Run,
+			},
+		}
+	}, tenantService, logger)
+	// Jobs pushed from other instances build their tenant's environment here.
+	jobs.OnMissingPartition(tenants.buildPartition)
+	// The default tenant's environment is built up front, so configuration
+	// errors stop startup rather than its first request.
+	if tenantService == nil || cfg.Tenancy.Default != "" {
+		id := ""
+		if tenantService != nil {
+			id = cfg.Tenancy.Default
+		}
+		if _, err := tenants.get(id); err != nil {
+			panic(err)
+		}
+	}
+
+	limiter := ratelimit.New(cfg.RateLimit)
+
+	// The status page answers "is it down?" for branch managers. Its checks
+	// read what the server already tracks: stale screens under brownout and
+	// sync writes deferred until the datastore recovers.
+	statusService := statuspage.NewService(cfg.StatusPage, []statuspage.Component{
+		{ID: "app", Name: "Technician app", Check: func() string {
+			if monitor.Degraded() {
+				return statuspage.StatusDegraded
+			}
+			return statuspage.StatusOperational
+		}},
+		{ID: "sync", Name: "Sync", Check: func() string {
+			if deferred.Len() > 0 {
+				return statuspage.StatusDegraded
+			}
+			return statuspage.StatusOperational
+		}},
+		{ID: "notifications", Name: "Push notifications"},
+		{ID: "integrations", Name: "Partner integrations"},
+	}, statuspage.NewMemoryStore(), logger)
+	statusHandler := statuspage.NewHandler(statusService)
+
+	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	router.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := repos.Validate(); err != nil {
+			respond.Error(w, http.StatusServiceUnavailable, "service not ready", err.Error())
+			return
+		}
+		// Brownout still reports ready: degraded instances keep serving stale
+		// screens, so pulling them from the load balancer would only make the
+		// datastore pressure worse elsewhere.
+		status := monitor.Status()
+		body := map[string]any{"status": "ready", "brownout": status}
+		if status.Degraded {
+			body["status"] = "degraded"
+		}
+		respond.JSON(w, http.StatusOK, body)
+	})
+
+	// Unlike the probes above, the status page is for people.
+	router.Route("/status", statusHandler.PublicRoutes)
+
+	router.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		legacyServed, legacyRejected := sduiHandler.LegacyUserIDs()
+		var connectorDropped, workerDeadLettered int64
+		var notifications, liveActivities []map[string]int64
+		for _, env := range tenants.all() {
+			connectorDropped += env.connector.Dropped()
+			workerDeadLettered += env.worker.DeadLettered()
+			notifications = append(notifications, env.notify.Stats())
+			liveActivities = append(liveActivities, env.live.Stats())
+		}
+		respond.JSON(w, http.StatusOK, map[string]any{
+			"brownout": monitor.Status(),
+			"deferredWrites": map[string]int{
+				"pending": deferred.Len(),
+				"dropped": deferred.Dropped(),
+			},
+			"connector": map[string]int64{
+				"dropped": connectorDropped,
+			},
+			"worker": map[string]int64{
+				"deadLettered": workerDeadLettered,
+			},
+			"queue": map[string]int64{
+				"deadLettered": jobs.DeadLettered(),
+			},
+			"tracing": map[string]int64{
+				"dropped": tracer.Dropped(),
+			},
+			"chaos": faults.Stats(),
+			"rateLimit": map[string]int64{
+				"limited": limiter.Limited(),
+			},
+			"notifications":  sum(notifications),
+			"liveActivities": sum(liveActivities),
+			"screens": map[string]int64{
+				"legacyUserId":         legacyServed,
+				"legacyUserIdRejected": legacyRejected,
+			},
+		})
+	})
+
+	// Cloud Tasks and Pub/Sub push jobs here, authenticated by the queue's
+	// push token rather than a user's.
+	router.Post(jobqueue.PushPath, jobs.PushHandler)
+
+	router.Get(respond.ProblemsPath, respond.ProblemIndex)
+	router.Get(respond.ProblemsPath+"/{slug}", respond.ProblemDoc)
+
+	if cfg.Server.EnableSwagger {
+		router.Get("/swagger", swaggerui.UIHandler)
+		router.Get("/swagger/doc.json", swaggerui.SpecHandler)
+	}
+
+	router.Route("/v1", func(r chi.Router) {
+		r.Group(func(pr chi.Router) {
+			pr.Use(verifier.Middleware)
+			pr.Use(auth.OwnData)
+			pr.Use(impersonation.Middleware)
+			pr.Use(tenantService.Middleware(tokenService, impersonation))
+			pr.Use(limiter.Middleware)
+			pr.Use(replayGuard.Middleware)
+			// The tenant's environment serves the rest of the public API.
+			pr.Handle("/*", http.HandlerFunc(tenants.Public))
 		})
-		r.Route("/chemical-treatments", func(tr chi.Router) {
-			tr.Post("/", syncHandler.CreateChemicalTreatment)
+		// Customer-facing and unauthenticated: the link token is the credential.
+		r.Get("/public/eta/{token}", tenants.PublicETA)
+		// Locally stored photos; the URL signature is the credential.
+		if local, ok := blobs.(*storage.Local); ok {
+			r.Get("/blobs/*", local.ServeHTTP)
+			// Template assets are public and versioned by content; without
+			// a CDN they are served from here.
+			r.Get("/assets/*", local.Public("assets"))
+		}
+
+		// Sandbox tokens are served by their sandbox, which resets itself;
+		// anyone else reaching this route is not in a sandbox.
+		r.With(tokenService.Require("")).Post("/sandbox/reset", func(w http.ResponseWriter, r *http.Request) {
+			respond.Error(w, http.StatusForbidden, "not a sandbox token", "sandbox reset requires a sandbox API token")
 		})
-		r.Route("/devices", func(dr chi.Router) {
-			dr.Post("/register", syncHandler.RegisterDevice)
+
+		// Local/dev sign-in; only mounted in the HMAC signing mode, which
+		// config rejects in prod.
+		if verifier.Signing() {
+			r.With(accessGuard.Middleware, lockouts.Middleware(auth.SignInSubject)).Route("/auth", authHandler.Routes)
+		}
+
+		// Dispatchers run day-to-day operations; credentials, integrations
+		// and impersonation are admin-only.
+		r.Route("/admin", func(ar chi.Router) {
+			ar.Use(verifier.Middleware)
+			// After verification, so the tenant a token belongs to is known.
+			ar.Use(accessGuard.Middleware)
+			ar.Use(verifier.RequireRole(auth.RoleAdmin, auth.RoleDispatcher))
+			// What every tenant shares is managed by the platform's staff.
+			ar.Group(func(pa chi.Router) {
+				pa.Use(tenantService.RequirePlatform)
+				pa.Group(func(adm chi.Router) {
+					adm.Use(verifier.RequireRole(auth.RoleAdmin))
+					adm.Route("/api-tokens", tokenHandler.Routes)
+					adm.Route("/sandboxes", sandboxHandler.Routes)
+					adm.Route("/impersonations", impersonationHandler.Routes)
+					adm.Route("/client-config", clientConfigHandler.Routes)
+					if addressService != nil {
+						adm.Route("/addresses", addressHandler.Routes)
+					}
+					adm.Route("/status", statusHandler.Routes)
+					if tenantService != nil {
+						adm.Route("/tenants", tenantHandler.Routes)
+					}
+					if lockouts != nil {
+						adm.Route("/lockouts", lockout.NewHandler(lockouts).Routes)
+					}
+					// Promotion needs the signing key the chain's environments share.
+					if cfg.Promotion.SigningKeySecret != "" {
+						adm.Route("/promotion", promotionHandler.Routes)
+					}
+					if cached, ok := secrets.(*secret.CachedProvider); ok {
+						adm.Route("/secrets", secret.NewHandler(cached).Routes)
+					}
+				})
+				pa.Route("/activity", activityHandler.Routes)
+				pa.Route("/screens", sduiHandler.Routes)
+				pa.Route("/translations", translationHandler.Routes)
+				pa.Route("/assets", assetHandler.Routes)
+				pa.Route("/snapshots", snapshotHandler.Routes)
+				pa.Route("/simulations", simulationHandler.Routes)
+				pa.Route("/experiments", experimentHandler.Routes)
+				pa.Route("/flags", flagHandler.Routes)
+			})
+			// The rest acts on one tenant's records, in its environment.
+			ar.With(tenantService.Middleware()).Handle("/*", http.HandlerFunc(tenants.Admin))
 		})
-		r.Get("/updates", syncHandler.GetUpdates)
 	})
 
-    return &Server{Router: router, cfg: cfg, repos: repos, logger: logger}
+	return &Server{
+		Router:   router,
+		cfg:      cfg,
+		repos:    repos,
+		brownout: monitor,
+		deferred: deferred,
+		assets:   assetService,
+		uploads:  pruner,
+		jobs:     jobs,
+		bus:      eventBus,
+		tenants:  tenants,
+		logger:   logger,
+	}
+}
+
+// publicRoutes mounts the device and third-party API. scope guards each route
+// with the API token scope it needs.
+func publicRoutes(r chi.Router, screens *sdui.Handler, uploads *syncapi.Handler, notes *voicenote.Handler, photos *photo.Handler, plans *serviceplan.Handler, durations *schedule.Handler, stock *inventory.Handler, waste *disposal.Handler, mixes *tankmix.Handler, equip *calibration.Handler, inbox *notify.Handler, live *liveactivity.Handler, widgets *widget.Handler, cars *carplay.Handler, vocab *intents.Handler, catalog *chemical.Handler, reports *servicereport.Handler, signatures *signature.Handler, trace *playback.Handler, changes *stream.Handler, chat *messaging.Handler, tuning *clientconfig.Handler, capabilities *capability.Handler, scope func(string) func(http.Handler) http.Handler) {
+	r.Route("/screens", func(sr chi.Router) {
+		sr.With(scope(apitoken.ScopeScreensRead)).Get("/{screenId}", screens.GetScreen)
+	})
+
+	r.Route("/jobs", func(jr chi.Router) {
+		jr.With(scope(apitoken.ScopeJobsWrite)).Post("/", uploads.CreateJob)
+		jr.Route("/{jobId}", func(r chi.Router) {
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/report.pdf", reports.GetReport)
+			r.With(scope(apitoken.ScopeJobsWrite)).Post("/signature", signatures.UploadSignature)
+			r.With(scope(apitoken.ScopeJobsWrite)).Post("/voice-notes", notes.UploadNote)
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/voice-notes", notes.ListNotes)
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/voice-notes/{noteId}", notes.GetNote)
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/voice-notes/{noteId}/audio", notes.GetAudio)
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/history", notes.JobHistory)
+			r.With(scope(apitoken.ScopeJobsRead)).Get("/checklist", plans.GetChecklist)
+			r.With(scope(apitoken.ScopeJobsWrite)).Post("/duration", durations.RecordDuration)
+		})
+	})
+	r.Route("/chemicals", func(cr chi.Router) {
+		cr.With(scope(apitoken.ScopeChemicalsWrite)).Post("/", uploads.CreateChemical)
+		cr.With(scope(apitoken.ScopeChemicalsRead)).Get("/catalog", catalog.SearchCatalog)
+	})
+	r.Route("/chemical-treatments", func(tr chi.Router) {
+		tr.With(scope(apitoken.ScopeTreatmentsWrite)).Post("/", uploads.CreateChemicalTreatment)
+	})
+	r.Route("/devices", func(dr chi.Router) {
+		dr.With(scope(apitoken.ScopeDevicesRead)).Get("/", uploads.ListDevices)
+		dr.With(scope(apitoken.ScopeDevicesWrite)).Post("/register", uploads.RegisterDevice)
+		dr.With(scope(apitoken.ScopeDevicesWrite)).Delete("/{token}", uploads.DeleteDevice)
+		dr.With(scope(apitoken.ScopeDevicesWrite)).Post("/live-activities", live.Register)
+		dr.With(scope(apitoken.ScopeDevicesWrite)).Delete("/live-activities/{token}", live.Delete)
+	})
+	r.With(scope(apitoken.ScopeJobsRead)).Get("/widgets/timeline", widgets.GetTimeline)
+	r.With(scope(apitoken.ScopeJobsRead)).Get("/carplay/stops", cars.GetStops)
+	r.With(scope(apitoken.ScopeJobsRead)).Get("/intents/vocabulary", vocab.GetVocabulary)
+	r.With(scope(apitoken.ScopeDevicesRead)).Get("/client-config", tuning.GetClientConfig)
+	// Any token may ask what it can do.
+	r.With(scope("")).Get("/capabilities", capabilities.GetCapabilities)
+	// Items are checked against the scope of their own endpoint.
+	r.With(scope("")).Post("/batch", uploads.UploadBatch)
+	r.With(scope(apitoken.ScopeUpdatesRead)).Get("/updates", uploads.GetUpdates)
+
+	r.With(scope(apitoken.ScopeJobsRead)).Get("/notes/search", notes.SearchNotes)
+	r.Route("/photos", func(pr chi.Router) {
+		pr.With(scope(apitoken.ScopePhotosRead)).Get("/", photos.ListPhotos)
+		pr.With(scope(apitoken.ScopePhotosWrite)).Post("/", photos.UploadPhoto)
+		pr.With(scope(apitoken.ScopePhotosRead)).Get("/vocabulary", photos.GetVocabulary)
+		pr.With(scope(apitoken.ScopePhotosRead)).Get("/{photoId}", photos.GetPhoto)
+		pr.With(scope(apitoken.ScopePhotosRead)).Get("/{photoId}/url", photos.GetPhotoURL)
+		pr.With(scope(apitoken.ScopePhotosWrite)).Put("/{photoId}/annotation", photos.AnnotatePhoto)
+	})
+	r.With(scope(apitoken.ScopeDevicesWrite)).Post("/location", trace.ReportLocation)
+	r.Route("/inventory/transfers", func(ir chi.Router) {
+		ir.With(scope(apitoken.ScopeInventoryRead)).Get("/", stock.ListMyTransfers)
+		ir.With(scope(apitoken.ScopeInventoryWrite)).Post("/", stock.SendTransfer)
+		ir.With(scope(apitoken.ScopeInventoryWrite)).Post("/{transferId}/confirm", stock.ConfirmTransfer)
+		ir.With(scope(apitoken.ScopeInventoryWrite)).Post("/{transferId}/decline", stock.DeclineTransfer)
+	})
+	r.Route("/inventory/counts", func(ir chi.Router) {
+		ir.With(scope(apitoken.ScopeInventoryRead)).Get("/", stock.ListMyCounts)
+		ir.With(scope(apitoken.ScopeInventoryWrite)).Post("/{countId}", stock.SubmitCount)
+	})
+	r.Route("/disposals", func(dr chi.Router) {
+		dr.With(scope(apitoken.ScopeDisposalsRead)).Get("/", waste.ListMyDisposals)
+		dr.With(scope(apitoken.ScopeDisposalsWrite)).Post("/", waste.LogDisposal)
+	})
+	r.Route("/tank-mixes", func(mr chi.Router) {
+		mr.With(scope(apitoken.ScopeTankMixesRead)).Get("/", mixes.ListRecipes)
+		mr.With(scope(apitoken.ScopeTankMixesRead)).Get("/{mixId}", mixes.GetRecipe)
+		mr.With(scope(apitoken.ScopeTankMixesRead)).Get("/{mixId}/batch", mixes.CalculateBatch)
+		mr.With(scope(apitoken.ScopeTreatmentsWrite)).Post("/{mixId}/applications", mixes.ApplyMix)
+	})
+	r.Route("/equipment", func(er chi.Router) {
+		er.With(scope(apitoken.ScopeEquipmentRead)).Get("/", equip.ListMyEquipment)
+		er.With(scope(apitoken.ScopeEquipmentRead)).Get("/{assetId}", equip.GetAsset)
+		er.With(scope(apitoken.ScopeEquipmentWrite)).Post("/{assetId}/calibrations", equip.RecordMyCalibration)
+	})
+	r.With(scope(apitoken.ScopeInboxRead)).Get("/inbox", inbox.ListInbox)
+	// Staff without ?userId= follow every technician's changes.
+	r.With(scope(apitoken.ScopeUpdatesRead)).Get("/stream", changes.Stream)
+	r.With(scope(apitoken.ScopeInboxRead)).Get("/ws", chat.Socket)
+}
+
+// unscoped is used where the token was already checked before dispatch.
+func unscoped(string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return next }
+}
+
+// Run starts background loops owned by the server and blocks until ctx is
+// cancelled and every loop has returned.
+func (s *Server) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	loops := []func(context.Context){
+		func(ctx context.Context) { s.deferred.Run(ctx, s.cfg.Brownout.FlushInterval) },
+		s.assets.Run,
+		s.uploads.PruneDevices,
+		s.uploads.PruneTombstones,
+		s.jobs.Run,
+		s.bus.Run,
+		s.tenants.Run,
+	}
+	for _, loop := range loops {
+		wg.Add(1)
+		go func(loop func(context.Context)) {
+			defer wg.Done()
+			loop(ctx)
+		}(loop)
+	}
+	wg.Wait()
 }
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/replay"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+const testSigningSecret = "test-signing-secret-of-at-least-32-bytes"
+
+// newTestServer builds the full router over a memory store, with
+// authentication in the HMAC signing mode and tenancy enabled.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("AUTH_JWT_HMAC_SECRET", testSigningSecret)
+	t.Setenv("TENANCY_ENABLED", "true")
+	t.Setenv("TENANT_DEFAULT", "acme")
+	t.Setenv("SCREEN_TEMPLATE_DIR", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	stores := Stores{Flags: flags.NewMemoryStore(), Nonces: replay.NewMemoryStore(), Documents: docstore.NewMemoryStore()}
+	return NewServer(cfg, repos, stores, secret.EnvProvider{}, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// testToken signs an HS256 token for subject with role and, when set, a
+// tenant claim.
+func testToken(t *testing.T, subject, role, tenantID string) string {
+	t.Helper()
+	claims := map[string]any{"sub": subject, "role": role, "exp": time.Now().Add(time.Hour).Unix()}
+	if tenantID != "" {
+		claims["tenant_id"] = tenantID
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte(signed))
+	return signed + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestAdminEnforcesTheTokenTenantsAllowlist(t *testing.T) {
+	srv := newTestServer(t)
+	serve := func(method, path, body, token, from string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = from + ":40000"
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		var p struct {
+			Title string `json:"title"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&p)
+		return rec.Code, p.Title
+	}
+
+	platform := testToken(t, "admin-1", "admin", "")
+	if status, title := serve(http.MethodPut, "/v1/admin/tenants/bugsbgone", `{"name":"Bugs B Gone","access":{"allowedIps":["203.0.113.0/24"]}}`, platform, "198.51.100.7"); status != http.StatusOK {
+		t.Fatalf("save tenant: %d %s", status, title)
+	}
+
+	tenantAdmin := testToken(t, "admin-2", "admin", "bugsbgone")
+	if status, title := serve(http.MethodGet, "/v1/admin/activity", "", tenantAdmin, "198.51.100.7"); status != http.StatusForbidden || title != "address not allowed" {
+		t.Fatalf("expected the tenant's allowlist enforced, got %d %q", status, title)
+	}
+	// From an allowed address the request gets past the guard, and is then
+	// refused as the activity feed is the platform's.
+	if status, title := serve(http.MethodGet, "/v1/admin/activity", "", tenantAdmin, "203.0.113.9"); status != http.StatusForbidden || title != "platform staff only" {
+		t.Fatalf("expected the allowlisted address admitted by the guard, got %d %q", status, title)
+	}
+	// The header does not pick, or escape, a tenant's policy.
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/activity", nil)
+	req.RemoteAddr = "198.51.100.7:40000"
+	req.Header.Set("Authorization", "Bearer "+platform)
+	req.Header.Set("X-Tenant-ID", "bugsbgone")
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a platform token admitted from anywhere, got %d", rec.Code)
+	}
+}
+
+func TestTenantsGetTheirOwnPartitionedAPI(t *testing.T) {
+	srv := newTestServer(t)
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	platform := testToken(t, "admin-1", "admin", "")
+	if rec := serve(http.MethodPut, "/v1/admin/tenants/bugsbgone", `{"name":"Bugs B Gone"}`, platform); rec.Code != http.StatusOK {
+		t.Fatalf("save tenant: %d %s", rec.Code, rec.Body)
+	}
+
+	bugsAdmin := testToken(t, "admin-2", "admin", "bugsbgone")
+	acmeAdmin := testToken(t, "admin-3", "admin", "acme")
+	if rec := serve(http.MethodPut, "/v1/admin/branches/north", `{"name":"North","timeZone":"America/Chicago"}`, bugsAdmin); rec.Code != http.StatusOK {
+		t.Fatalf("expected a tenant admin to manage its branches, got %d %s", rec.Code, rec.Body)
+	}
+	list := func(token string) []json.RawMessage {
+		rec := serve(http.MethodGet, "/v1/admin/branches", "", token)
+		var body struct {
+			Branches []json.RawMessage `json:"branches"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("list branches: %d (%v)", rec.Code, err)
+		}
+		return body.Branches
+	}
+	if got := list(bugsAdmin); len(got) != 1 {
+		t.Fatalf("expected the tenant's branch listed, got %s", got)
+	}
+	if got := list(acmeAdmin); len(got) != 0 {
+		t.Fatalf("expected another tenant not to see it, got %s", got)
+	}
+
+	// Public routes once fenced off to the default tenant serve the others.
+	tech := testToken(t, "tech-1", "technician", "bugsbgone")
+	if rec := serve(http.MethodGet, "/v1/photos", "", tech); rec.Code != http.StatusOK {
+		t.Fatalf("expected a tenant's technician served, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestNewServerRequiresTheStores(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a server without its stores refused")
+		}
+	}()
+	NewServer(cfg, repos, Stores{}, secret.EnvProvider{}, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
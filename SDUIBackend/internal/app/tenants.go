@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/connector"
+	"github.com/your-org/pestgenie-sdui/internal/eta"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/liveactivity"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/notify"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+	"github.com/your-org/pestgenie-sdui/internal/worker"
+)
+
+// tenantRefreshInterval is how often tenants registered on other instances
+// get their environment, and so their background loops, on this one.
+const tenantRefreshInterval = time.Minute
+
+// tenantEnv is one tenant's API. Its services run over repositories scoped
+// to the tenant, with stores, background loops and job queue topics of
+// their own, so no tenant reads, writes or processes another's records.
+type tenantEnv struct {
+	// public and admin route the full /v1 and /v1/admin paths.
+	public http.Handler
+	admin  http.Handler
+
+	eta       *eta.Service
+	etaPublic http.HandlerFunc
+	connector *connector.Service
+	worker    *worker.Service
+	notify    *notify.Service
+	live      *liveactivity.Service
+	loops     []func(context.Context)
+}
+
+// tenantBuilder builds the environment of the tenant scope names; scoped is
+// false when tenancy is disabled and the environment sees every record.
+type tenantBuilder func(scope tenant.Scope, scoped bool) *tenantEnv
+
+// tenantEnvs keeps one environment per tenant, built on first use and for
+// every registered tenant once Run starts. With tenancy disabled there is
+// one, under the empty ID.
+type tenantEnvs struct {
+	build   tenantBuilder
+	tenants *tenant.Service
+	logger  *slog.Logger
+
+	mu   sync.Mutex
+	envs map[string]*tenantEnv
+	// ctx is Run's, once it has started; loops started under it are
+	// counted in wg.
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+func newTenantEnvs(build tenantBuilder, tenants *tenant.Service, logger *slog.Logger) *tenantEnvs {
+	return &tenantEnvs{build: build, tenants: tenants, logger: logger, envs: make(map[string]*tenantEnv)}
+}
+
+// get returns tenant id's environment, building it when this instance has
+// not yet. Unknown tenants return tenant.ErrNotFound.
+func (t *tenantEnvs) get(id string) (*tenantEnv, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if env, ok := t.envs[id]; ok {
+		return env, nil
+	}
+	scope, scoped, err := t.tenants.Scope(id)
+	if err != nil {
+		return nil, err
+	}
+	env := t.build(scope, scoped)
+	t.envs[id] = env
+	if t.ctx != nil {
+		t.start(env)
+	}
+	t.logger.Info("tenant environment built", slog.String("tenant", id))
+	return env, nil
+}
+
+// start runs env's loops under Run's context. Callers hold t.mu.
+func (t *tenantEnvs) start(env *tenantEnv) {
+	for _, loop := range env.loops {
+		t.wg.Add(1)
+		go func(loop func(context.Context)) {
+			defer t.wg.Done()
+			loop(t.ctx)
+		}(loop)
+	}
+}
+
+// all returns every environment built so far, ordered by tenant.
+func (t *tenantEnvs) all() []*tenantEnv {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]string, 0, len(t.envs))
+	for id := range t.envs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	out := make([]*tenantEnv, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, t.envs[id])
+	}
+	return out
+}
+
+// Run starts the loops of every environment, building those of registered
+// tenants, and of tenants registered later, and blocks until ctx is
+// cancelled and every loop has returned.
+func (t *tenantEnvs) Run(ctx context.Context) {
+	t.mu.Lock()
+	t.ctx = ctx
+	for _, env := range t.envs {
+		t.start(env)
+	}
+	t.mu.Unlock()
+
+	ticker := time.NewTicker(tenantRefreshInterval)
+	defer ticker.Stop()
+	for {
+		t.refresh()
+		select {
+		case <-ctx.Done():
+			t.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh builds the environments of registered tenants.
+func (t *tenantEnvs) refresh() {
+	if t.tenants == nil {
+		if _, err := t.get(""); err != nil {
+			t.logger.Error("build environment", slog.Any("error", err))
+		}
+		return
+	}
+	list, err := t.tenants.Tenants()
+	if err != nil {
+		t.logger.Error("list tenants", slog.Any("error", err))
+		return
+	}
+	for _, tn := range list {
+		if _, err := t.get(tn.ID); err != nil {
+			t.logger.Error("build tenant environment", slog.String("tenant", tn.ID), slog.Any("error", err))
+		}
+	}
+}
+
+// buildPartition sets up the environment a job queue partition belongs to.
+func (t *tenantEnvs) buildPartition(id string) {
+	if _, err := t.get(id); err != nil {
+		t.logger.Error("build tenant environment for job", slog.String("tenant", id), slog.Any("error", err))
+	}
+}
+
+// Public serves a public API request from the environment of the tenant it
+// acts for.
+func (t *tenantEnvs) Public(w http.ResponseWriter, r *http.Request) {
+	if env, ok := t.forRequest(w, r); ok {
+		dispatch(env.public, w, r)
+	}
+}
+
+// Admin serves an admin API request from the environment of the tenant it
+// acts for.
+func (t *tenantEnvs) Admin(w http.ResponseWriter, r *http.Request) {
+	if env, ok := t.forRequest(w, r); ok {
+		dispatch(env.admin, w, r)
+	}
+}
+
+// PublicETA serves a customer's ETA link from the environment that issued
+// it. The token is the only credential, so the tenant is not known up front.
+func (t *tenantEnvs) PublicETA(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	for _, env := range t.all() {
+		issued, err := env.eta.Issued(token)
+		if err != nil {
+			middleware.LoggerFrom(r.Context()).Error("failed to resolve eta link", slog.Any("error", err))
+			respond.Error(w, http.StatusInternalServerError, "failed to load status", "temporary error, please retry", respond.WithCause(err))
+			return
+		}
+		if issued {
+			env.etaPublic(w, r)
+			return
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respond.Error(w, http.StatusNotFound, "link not found", "this link has expired or is no longer valid")
+}
+
+func (t *tenantEnvs) forRequest(w http.ResponseWriter, r *http.Request) (*tenantEnv, bool) {
+	id := ""
+	if scope, ok := tenant.ScopeFrom(r.Context()); ok {
+		id = scope.Tenant.ID
+	}
+	env, err := t.get(id)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("tenant environment unavailable", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "tenant unavailable", "temporary error, please retry", respond.WithCause(err))
+		return nil, false
+	}
+	return env, true
+}
+
+// dispatch serves r from h, dropping the outer router's routing state so h
+// matches the full request path, as sandbox environments do.
+func dispatch(h http.Handler, w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, nil)
+	h.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// sum adds up the counters each environment's service reports.
+func sum(counters []map[string]int64) map[string]int64 {
+	out := map[string]int64{}
+	for _, c := range counters {
+		for k, v := range c {
+			out[k] += v
+		}
+	}
+	return out
+}
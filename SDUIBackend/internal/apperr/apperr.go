@@ -0,0 +1,177 @@
+// Package apperr classifies the errors the application answers clients
+// with. An *Error has a Kind, which decides its HTTP status, and a stable
+// machine-readable code clients can branch on, such as
+// "datastore_unavailable"; a code never changes meaning once released.
+// Handlers pass errors to respond.Error with respond.WithCause, which
+// answers with the status, code and detail of an *Error found in the chain.
+// Packages declare their sentinel errors as *Error values and report each
+// occurrence with Detailf.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Kind is a class of error.
+type Kind int
+
+// Kinds of error. The zero Kind is an unexpected failure.
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindRateLimited
+	KindUnavailable
+)
+
+var kinds = map[Kind]struct {
+	status int
+	code   string
+}{
+	KindInternal:    {http.StatusInternalServerError, "internal"},
+	KindNotFound:    {http.StatusNotFound, "not_found"},
+	KindConflict:    {http.StatusConflict, "conflict"},
+	KindValidation:  {http.StatusBadRequest, "validation_failed"},
+	KindRateLimited: {http.StatusTooManyRequests, "rate_limited"},
+	KindUnavailable: {http.StatusServiceUnavailable, "unavailable"},
+}
+
+// Status returns the HTTP status errors of the kind are answered with.
+func (k Kind) Status() int {
+	if d, ok := kinds[k]; ok {
+		return d.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Code returns the code of errors of the kind that carry none of their
+// own.
+func (k Kind) Code() string {
+	if d, ok := kinds[k]; ok {
+		return d.code
+	}
+	return kinds[KindInternal].code
+}
+
+// KindForStatus returns the kind answered with status, for responses
+// written without an *Error.
+func KindForStatus(status int) (Kind, bool) {
+	for k, d := range kinds {
+		if d.status == status {
+			return k, true
+		}
+	}
+	return KindInternal, false
+}
+
+// Error is an error with the kind and code clients see.
+type Error struct {
+	Kind Kind
+	// Code is a stable, snake_case identifier of the error, such as
+	// "datastore_unavailable"; empty means the kind's code.
+	Code string
+	// Detail explains the error to clients; it must not leak internals.
+	Detail string
+	// RetryAfter is how long clients should wait before retrying.
+	RetryAfter time.Duration
+	// Err is the underlying cause; it is logged, never shown to clients.
+	Err error
+}
+
+// New returns an error of kind with code and detail.
+func New(kind Kind, code, detail string) *Error {
+	return &Error{Kind: kind, Code: code, Detail: detail}
+}
+
+// NotFound returns an error for a missing resource.
+func NotFound(code, detail string) *Error {
+	return New(KindNotFound, code, detail)
+}
+
+// Conflict returns an error for a request that clashes with the current
+// state of a resource.
+func Conflict(code, detail string) *Error {
+	return New(KindConflict, code, detail)
+}
+
+// Validation returns an error for a request that is not valid.
+func Validation(code, detail string) *Error {
+	return New(KindValidation, code, detail)
+}
+
+// RateLimited returns an error asking the client to slow down and retry
+// after retryAfter.
+func RateLimited(code, detail string, retryAfter time.Duration) *Error {
+	e := New(KindRateLimited, code, detail)
+	e.RetryAfter = retryAfter
+	return e
+}
+
+// Unavailable returns an error for a dependency that failed in a way a
+// retry can get past, caused by cause.
+func Unavailable(code, detail string, cause error) *Error {
+	e := New(KindUnavailable, code, detail)
+	e.Err = cause
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := e.ErrorCode()
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same kind and code, so a
+// sentinel such as a package's ErrNotFound matches every error reported
+// with its code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.ErrorCode() == e.ErrorCode()
+}
+
+// Detailf returns a copy of e with the detail format gives, for reporting
+// one occurrence of a sentinel error; errors.Is matches the copy with e.
+func (e *Error) Detailf(format string, args ...any) *Error {
+	c := *e
+	c.Detail = fmt.Sprintf(format, args...)
+	return &c
+}
+
+// ErrorCode returns the error's code, or its kind's when it has none.
+func (e *Error) ErrorCode() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return e.Kind.Code()
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf returns the kind of the first *Error in err's chain, or
+// KindInternal.
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,61 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestKindsMapToStatusesAndCodes(t *testing.T) {
+	cases := []struct {
+		err    *Error
+		status int
+		code   string
+	}{
+		{NotFound("route_not_found", "no route r-1"), http.StatusNotFound, "route_not_found"},
+		{Conflict("", "route changed"), http.StatusConflict, "conflict"},
+		{Validation("", "date is required"), http.StatusBadRequest, "validation_failed"},
+		{RateLimited("", "slow down", time.Minute), http.StatusTooManyRequests, "rate_limited"},
+		{Unavailable("datastore_unavailable", "the datastore is unavailable", errors.New("timeout")), http.StatusServiceUnavailable, "datastore_unavailable"},
+		{&Error{}, http.StatusInternalServerError, "internal"},
+	}
+	for _, tc := range cases {
+		if tc.err.Kind.Status() != tc.status || tc.err.ErrorCode() != tc.code {
+			t.Errorf("%v: expected %d %s, got %d %s", tc.err, tc.status, tc.code, tc.err.Kind.Status(), tc.err.ErrorCode())
+		}
+		if k, ok := KindForStatus(tc.status); !ok || k != tc.err.Kind {
+			t.Errorf("expected status %d to map back to kind %d, got %d", tc.status, tc.err.Kind, k)
+		}
+	}
+	if _, ok := KindForStatus(http.StatusForbidden); ok {
+		t.Fatal("expected no kind for 403")
+	}
+}
+
+func TestAsFindsWrappedErrors(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("save route: %w", Unavailable("datastore_unavailable", "the datastore is unavailable", cause))
+	e, ok := As(err)
+	if !ok || e.Code != "datastore_unavailable" || !errors.Is(err, cause) || KindOf(err) != KindUnavailable {
+		t.Fatalf("expected the app error found in the chain, got %v %v", e, ok)
+	}
+	if err.Error() != "save route: datastore_unavailable: the datastore is unavailable: connection refused" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if KindOf(cause) != KindInternal {
+		t.Fatal("expected plain errors to be internal")
+	}
+}
+
+func TestDetailfMatchesItsSentinel(t *testing.T) {
+	errMissing := NotFound("route_not_found", "route not found")
+	err := fmt.Errorf("optimize: %w", errMissing.Detailf("no route %s", "r-1"))
+	if !errors.Is(err, errMissing) || errors.Is(err, NotFound("template_not_found", "")) {
+		t.Fatalf("expected the error matched by code, got %v", err)
+	}
+	if e, _ := As(err); e.Detail != "no route r-1" || errMissing.Detail != "route not found" {
+		t.Fatalf("expected the detail set on a copy, got %q and %q", e.Detail, errMissing.Detail)
+	}
+}
@@ -0,0 +1,58 @@
+// Package appversion compares the dotted app versions clients report, such
+// as "2.14.1". A leading "v" is ignored and missing parts count as zero, so
+// "2.4" equals "2.4.0".
+package appversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse splits a dotted version into numbers.
+func Parse(v string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// Compare orders parsed versions, returning -1, 0 or 1.
+func Compare(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Below reports whether version is older than min. Versions that do not
+// parse are never below: an unknown client is not held back.
+func Below(version, min string) bool {
+	v, err := Parse(version)
+	if err != nil {
+		return false
+	}
+	m, err := Parse(min)
+	if err != nil {
+		return false
+	}
+	return Compare(v, m) < 0
+}
@@ -0,0 +1,22 @@
+package appversion
+
+import "testing"
+
+func TestBelow(t *testing.T) {
+	cases := []struct {
+		version, min string
+		want         bool
+	}{
+		{"2.3.9", "2.4", true},
+		{"2.4", "2.4.0", false},
+		{"v2.10", "2.9", false},
+		{"", "2.4", false},
+		{"2.4", "", false},
+		{"beta", "2.4", false},
+	}
+	for _, tc := range cases {
+		if got := Below(tc.version, tc.min); got != tc.want {
+			t.Errorf("Below(%q, %q) = %v, want %v", tc.version, tc.min, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package asset manages the icons and illustrations templates show. Each
+// upload becomes a new version stored under a content-addressed key, so its
+// URL can be cached by the CDN forever; templates name the asset and screens
+// are served the current version's URL. Assets no template references are
+// garbage-collected after a grace period.
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an asset does not exist.
+	ErrNotFound = errors.New("asset not found")
+	// ErrInvalidAsset wraps upload validation failures.
+	ErrInvalidAsset = errors.New("invalid asset")
+	// ErrTooLarge is returned when an upload exceeds the configured limit.
+	ErrTooLarge = errors.New("asset too large")
+	// ErrInUse is returned when deleting an asset a template references.
+	ErrInUse = errors.New("asset in use")
+)
+
+// idPattern is what templates can name an asset by.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidID reports whether id can name an asset.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// Asset is an uploaded image and its versions, oldest first.
+type Asset struct {
+	ID        string    `json:"id"`
+	Versions  []Version `json:"versions"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// UnreferencedSince is when garbage collection last found no template
+	// referencing the asset; nil while one does.
+	UnreferencedSince *time.Time `json:"unreferencedSince,omitempty"`
+}
+
+// Version is one upload of an asset.
+type Version struct {
+	Version     int       `json:"version"`
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Bytes       int64     `json:"bytes"`
+	SHA256      string    `json:"sha256"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+	// ReplacedAt is when a newer version was uploaded.
+	ReplacedAt *time.Time `json:"replacedAt,omitempty"`
+}
+
+// Current returns the latest version.
+func (a Asset) Current() Version {
+	if len(a.Versions) == 0 {
+		return Version{}
+	}
+	return a.Versions[len(a.Versions)-1]
+}
+
+// checkID rejects IDs templates could not name.
+func checkID(id string) error {
+	if !ValidID(id) {
+		return fmt.Errorf("%w: id %q must be 1-64 lowercase letters, digits, - or _", ErrInvalidAsset, id)
+	}
+	return nil
+}
+
+// Store persists asset metadata; the images live in blob storage.
+type Store interface {
+	SaveAsset(a Asset) error
+	GetAsset(id string) (Asset, error)
+	ListAssets() ([]Asset, error)
+	DeleteAsset(id string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	assets map[string]Asset
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{assets: make(map[string]Asset)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveAsset(a Asset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.Versions = append([]Version(nil), a.Versions...)
+	m.assets[a.ID] = a
+	return nil
+}
+
+func (m *MemoryStore) GetAsset(id string) (Asset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.assets[id]
+	if !ok {
+		return Asset{}, ErrNotFound
+	}
+	a.Versions = append([]Version(nil), a.Versions...)
+	return a, nil
+}
+
+func (m *MemoryStore) ListAssets() ([]Asset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Asset, 0, len(m.assets))
+	for _, a := range m.assets {
+		a.Versions = append([]Version(nil), a.Versions...)
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteAsset(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.assets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.assets, id)
+	return nil
+}
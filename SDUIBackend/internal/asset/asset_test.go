@@ -0,0 +1,163 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+)
+
+type references map[string]bool
+
+func (r references) AssetReferences() (map[string]bool, error) { return r, nil }
+
+func pngBytes(t *testing.T, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newTestService(t *testing.T, refs references) (*Service, string, *time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	blobs, err := storage.NewLocal(dir, "http://localhost"+storage.LocalPathPrefix, []byte("k"))
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	cfg := config.AssetConfig{
+		CDNBaseURL:   "https://cdn.example.com",
+		MaxBytes:     1 << 20,
+		AllowedTypes: []string{"image/png", "image/svg+xml"},
+		GCInterval:   time.Hour,
+		GCGrace:      24 * time.Hour,
+	}
+	svc := NewService(cfg, NewMemoryStore(), blobs, refs, nil)
+	now := time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, dir, &now
+}
+
+func TestUploadVersionsByContent(t *testing.T) {
+	svc, dir, _ := newTestService(t, nil)
+	heard := make(map[string]string)
+	svc.OnChange(func(id, url string) { heard[id] = url })
+	ctx := context.Background()
+
+	a, err := svc.Upload(ctx, "termite", "image/png", bytes.NewReader(pngBytes(t, 2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v1 := a.Current()
+	if v1.Version != 1 || !strings.HasPrefix(v1.URL, "https://cdn.example.com/assets/termite/1-") || !strings.HasSuffix(v1.URL, ".png") || heard["termite"] != v1.URL {
+		t.Fatalf("unexpected first version %+v, heard %v", v1, heard)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(v1.Key))); err != nil {
+		t.Fatalf("expected the blob stored: %v", err)
+	}
+
+	if a, _ := svc.Upload(ctx, "termite", "image/png", bytes.NewReader(pngBytes(t, 2))); len(a.Versions) != 1 {
+		t.Errorf("expected the same content to keep its version, got %+v", a.Versions)
+	}
+	a, err = svc.Upload(ctx, "termite", "image/png", bytes.NewReader(pngBytes(t, 3)))
+	if err != nil || a.Current().Version != 2 || a.Versions[0].ReplacedAt == nil || heard["termite"] != a.Current().URL {
+		t.Fatalf("expected a second version, got %+v, %v", a, err)
+	}
+
+	for _, tc := range []struct {
+		id, contentType, body string
+		want                  error
+	}{
+		{"Bad ID", "image/png", string(pngBytes(t, 2)), ErrInvalidAsset},
+		{"logo", "image/gif", "GIF89a", ErrInvalidAsset},
+		{"logo", "image/png", "<svg/>", ErrInvalidAsset},
+		{"logo", "image/svg+xml", `<svg><script>alert(1)</script></svg>`, ErrInvalidAsset},
+		{"logo", "image/png", string(make([]byte, 2<<20)), ErrTooLarge},
+	} {
+		if _, err := svc.Upload(ctx, tc.id, tc.contentType, strings.NewReader(tc.body)); !errors.Is(err, tc.want) {
+			t.Errorf("%s %s: expected %v, got %v", tc.id, tc.contentType, tc.want, err)
+		}
+	}
+	if _, err := svc.Upload(ctx, "logo", "image/svg+xml; charset=utf-8", strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg"/>`)); err != nil {
+		t.Errorf("expected a plain SVG accepted, got %v", err)
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	refs := references{"termite": true}
+	svc, dir, now := newTestService(t, refs)
+	ctx := context.Background()
+	for _, id := range []string{"termite", "old-logo"} {
+		if _, err := svc.Upload(ctx, id, "image/png", bytes.NewReader(pngBytes(t, 2))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	a, _ := svc.Upload(ctx, "termite", "image/png", bytes.NewReader(pngBytes(t, 4)))
+	replaced := a.Versions[0].Key
+
+	if err := svc.Delete(ctx, "termite"); !errors.Is(err, ErrInUse) {
+		t.Fatalf("expected a referenced asset kept, got %v", err)
+	}
+
+	// The first pass marks old-logo unreferenced; nothing is old enough yet.
+	if c, err := svc.CollectGarbage(ctx); err != nil || len(c.Assets) != 0 || c.Versions != 0 {
+		t.Fatalf("expected nothing collected within the grace, got %+v, %v", c, err)
+	}
+	*now = now.Add(25 * time.Hour)
+	c, err := svc.CollectGarbage(ctx)
+	if err != nil || strings.Join(c.Assets, ",") != "old-logo" || c.Versions != 1 {
+		t.Fatalf("expected old-logo and the replaced version collected, got %+v, %v", c, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(replaced))); !os.IsNotExist(err) {
+		t.Errorf("expected the replaced blob deleted, got %v", err)
+	}
+	if a, err := svc.Asset("termite"); err != nil || len(a.Versions) != 1 || a.Current().Version != 2 {
+		t.Errorf("expected the current version kept, got %+v, %v", a, err)
+	}
+	if _, err := svc.Asset("old-logo"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected old-logo gone, got %v", err)
+	}
+}
+
+func TestUploadHandler(t *testing.T) {
+	svc, _, _ := newTestService(t, nil)
+	r := chi.NewRouter()
+	r.Route("/assets", NewHandler(svc).Routes)
+
+	req := httptest.NewRequest(http.MethodPut, "/assets/termite", bytes.NewReader(pngBytes(t, 2)))
+	req.Header.Set("Content-Type", "image/png")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"url":"https://cdn.example.com/assets/termite/1-`) {
+		t.Fatalf("expected the uploaded asset, got %d: %s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/assets/big", bytes.NewReader(make([]byte, 2<<20)))
+	req.Header.Set("Content-Type", "image/png")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/assets/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
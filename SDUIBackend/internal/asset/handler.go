@@ -0,0 +1,103 @@
+package asset
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes asset management to template authors.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an asset handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListAssets)
+	r.Post("/gc", h.CollectGarbage)
+	r.Get("/{assetId}", h.GetAsset)
+	r.Put("/{assetId}", h.UploadAsset)
+	r.Delete("/{assetId}", h.DeleteAsset)
+}
+
+// ListAssets lists every asset with its versions.
+func (h *Handler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	assets, err := h.service.Assets()
+	if err != nil {
+		h.fail(w, r, "failed to list assets", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"assets": assets})
+}
+
+// GetAsset returns an asset with its versions.
+func (h *Handler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	a, err := h.service.Asset(chi.URLParam(r, "assetId"))
+	if err != nil {
+		h.fail(w, r, "failed to load asset", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, a)
+}
+
+// UploadAsset stores the request body, an image of the Content-Type it
+// declares, as the asset's new version.
+func (h *Handler) UploadAsset(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, h.service.cfg.MaxBytes+1)
+	a, err := h.service.Upload(r.Context(), chi.URLParam(r, "assetId"), r.Header.Get("Content-Type"), body)
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		h.fail(w, r, "failed to upload asset", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, a)
+}
+
+// DeleteAsset removes an asset no template references.
+func (h *Handler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "assetId")); err != nil {
+		h.fail(w, r, "failed to delete asset", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CollectGarbage runs garbage collection now rather than on its schedule.
+func (h *Handler) CollectGarbage(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.CollectGarbage(r.Context())
+	if err != nil {
+		h.fail(w, r, "failed to collect assets", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidAsset):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, ErrTooLarge):
+		respond.Error(w, http.StatusRequestEntityTooLarge, title, err.Error())
+	case errors.Is(err, ErrInUse):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
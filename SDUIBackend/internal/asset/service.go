@@ -0,0 +1,307 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+)
+
+// keyPrefix is where assets live in the blob store.
+const keyPrefix = "assets"
+
+// extensions maps the accepted content types to key extensions, which is
+// also what the local store serves them as.
+var extensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/webp":    ".webp",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+}
+
+// ReferenceSource reports which assets templates reference; *sdui.Service
+// implements it.
+type ReferenceSource interface {
+	AssetReferences() (map[string]bool, error)
+}
+
+// Service manages asset uploads and garbage collection.
+type Service struct {
+	cfg    config.AssetConfig
+	store  Store
+	blobs  storage.BlobStore
+	refs   ReferenceSource
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	hooks []func(id, url string)
+}
+
+// NewService wires an asset service. Assets referenced according to refs
+// are never collected or deleted.
+func NewService(cfg config.AssetConfig, store Store, blobs storage.BlobStore, refs ReferenceSource, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, blobs: blobs, refs: refs, logger: logger, now: time.Now}
+}
+
+// OnChange registers fn to be called with an asset's current URL whenever
+// it changes; a deleted asset is reported with an empty URL.
+func (s *Service) OnChange(fn func(id, url string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// Upload stores body as a new version of asset id, creating the asset on
+// its first upload. Uploading the current version's content again changes
+// nothing.
+func (s *Service) Upload(ctx context.Context, id, contentType string, body io.Reader) (Asset, error) {
+	if err := checkID(id); err != nil {
+		return Asset{}, err
+	}
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	ext, ok := extensions[contentType]
+	if !ok || !allowed(s.cfg.AllowedTypes, contentType) {
+		return Asset{}, fmt.Errorf("%w: content type %q is not allowed (allowed: %s)", ErrInvalidAsset, contentType, strings.Join(s.cfg.AllowedTypes, ", "))
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.cfg.MaxBytes+1))
+	if err != nil {
+		return Asset{}, err
+	}
+	if int64(len(data)) > s.cfg.MaxBytes {
+		return Asset{}, fmt.Errorf("%w: limit is %d bytes", ErrTooLarge, s.cfg.MaxBytes)
+	}
+	if err := checkContent(contentType, data); err != nil {
+		return Asset{}, err
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	now := s.now().UTC()
+	a, err := s.store.GetAsset(id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		a = Asset{ID: id, CreatedAt: now}
+	case err != nil:
+		return Asset{}, err
+	}
+	if current := a.Current(); current.SHA256 == digest && current.ContentType == contentType {
+		return a, nil
+	}
+
+	v := Version{Version: a.Current().Version + 1, ContentType: contentType, Bytes: int64(len(data)), SHA256: digest, UploadedAt: now}
+	v.Key = fmt.Sprintf("%s/%s/%d-%s%s", keyPrefix, id, v.Version, digest[:16], ext)
+	v.URL = s.url(v.Key)
+	if _, err := s.blobs.Put(ctx, v.Key, contentType, bytes.NewReader(data)); err != nil {
+		return Asset{}, fmt.Errorf("store asset: %w", err)
+	}
+	if n := len(a.Versions); n > 0 {
+		a.Versions[n-1].ReplacedAt = &now
+	}
+	a.Versions = append(a.Versions, v)
+	a.UpdatedAt = now
+	if err := s.store.SaveAsset(a); err != nil {
+		_ = s.blobs.Delete(context.WithoutCancel(ctx), v.Key)
+		return Asset{}, err
+	}
+	s.logger.Info("asset uploaded", slog.String("asset", id), slog.Int("version", v.Version), slog.Int64("bytes", v.Bytes))
+	s.changed(id, v.URL)
+	return a, nil
+}
+
+// Asset returns an asset with its versions.
+func (s *Service) Asset(id string) (Asset, error) {
+	return s.store.GetAsset(id)
+}
+
+// Assets lists every asset.
+func (s *Service) Assets() ([]Asset, error) {
+	return s.store.ListAssets()
+}
+
+// Delete removes an asset and every version of it. Assets a template
+// references cannot be deleted.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	a, err := s.store.GetAsset(id)
+	if err != nil {
+		return err
+	}
+	refs, err := s.references()
+	if err != nil {
+		return err
+	}
+	if refs[id] {
+		return fmt.Errorf("%w: a template references %s", ErrInUse, id)
+	}
+	return s.remove(ctx, a)
+}
+
+// Collection reports what garbage collection removed.
+type Collection struct {
+	Assets   []string `json:"assets"`
+	Versions int      `json:"versions"`
+}
+
+// CollectGarbage removes assets no template has referenced for GCGrace, and
+// versions replaced more than GCGrace ago. The grace keeps URLs alive for
+// screens that clients cached before the change.
+func (s *Service) CollectGarbage(ctx context.Context) (Collection, error) {
+	out := Collection{Assets: []string{}}
+	refs, err := s.references()
+	if err != nil {
+		return out, err
+	}
+	assets, err := s.store.ListAssets()
+	if err != nil {
+		return out, err
+	}
+	now := s.now().UTC()
+	cutoff := now.Add(-s.cfg.GCGrace)
+	for _, a := range assets {
+		if !refs[a.ID] {
+			if a.UnreferencedSince != nil && !a.UnreferencedSince.After(cutoff) {
+				if err := s.remove(ctx, a); err != nil {
+					return out, err
+				}
+				out.Assets = append(out.Assets, a.ID)
+				continue
+			}
+		}
+		dirty := false
+		switch {
+		case refs[a.ID] && a.UnreferencedSince != nil:
+			a.UnreferencedSince, dirty = nil, true
+		case !refs[a.ID] && a.UnreferencedSince == nil:
+			a.UnreferencedSince, dirty = &now, true
+		}
+		kept := a.Versions[:0:0]
+		for i, v := range a.Versions {
+			if i < len(a.Versions)-1 && v.ReplacedAt != nil && !v.ReplacedAt.After(cutoff) {
+				if err := s.blobs.Delete(ctx, v.Key); err != nil {
+					return out, fmt.Errorf("delete %s: %w", v.Key, err)
+				}
+				out.Versions++
+				dirty = true
+				continue
+			}
+			kept = append(kept, v)
+		}
+		a.Versions = kept
+		if dirty {
+			if err := s.store.SaveAsset(a); err != nil {
+				return out, err
+			}
+		}
+	}
+	if len(out.Assets) > 0 || out.Versions > 0 {
+		s.logger.Info("assets collected", slog.Int("assets", len(out.Assets)), slog.Int("versions", out.Versions))
+	}
+	return out, nil
+}
+
+// Run collects garbage every GCInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.GCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CollectGarbage(ctx); err != nil {
+				s.logger.Error("collect assets", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+func (s *Service) remove(ctx context.Context, a Asset) error {
+	for _, v := range a.Versions {
+		if err := s.blobs.Delete(ctx, v.Key); err != nil {
+			return fmt.Errorf("delete %s: %w", v.Key, err)
+		}
+	}
+	if err := s.store.DeleteAsset(a.ID); err != nil {
+		return err
+	}
+	s.logger.Info("asset deleted", slog.String("asset", a.ID))
+	s.changed(a.ID, "")
+	return nil
+}
+
+func (s *Service) references() (map[string]bool, error) {
+	if s.refs == nil {
+		return map[string]bool{}, nil
+	}
+	refs, err := s.refs.AssetReferences()
+	if err != nil {
+		return nil, fmt.Errorf("asset references: %w", err)
+	}
+	return refs, nil
+}
+
+// url is where clients fetch key: through the CDN, or from the API when
+// there is none.
+func (s *Service) url(key string) string {
+	if s.cfg.CDNBaseURL != "" {
+		return s.cfg.CDNBaseURL + "/" + key
+	}
+	return s.cfg.PublicBaseURL + "/v1/" + key
+}
+
+func (s *Service) changed(id, url string) {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	for _, hook := range hooks {
+		hook(id, url)
+	}
+}
+
+// checkContent makes sure data is what its content type claims. SVGs are
+// rejected when they carry scripts, which would run wherever the URL is
+// opened in a browser.
+func checkContent(contentType string, data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: asset is empty", ErrInvalidAsset)
+	}
+	if contentType == "image/svg+xml" {
+		lower := bytes.ToLower(data)
+		if !bytes.Contains(lower, []byte("<svg")) {
+			return fmt.Errorf("%w: content is not an SVG image", ErrInvalidAsset)
+		}
+		if bytes.Contains(lower, []byte("<script")) || bytes.Contains(lower, []byte("javascript:")) {
+			return fmt.Errorf("%w: SVG images must not contain scripts", ErrInvalidAsset)
+		}
+		return nil
+	}
+	if sniffed := http.DetectContentType(data); sniffed != contentType {
+		return fmt.Errorf("%w: content looks like %s, not %s", ErrInvalidAsset, sniffed, contentType)
+	}
+	return nil
+}
+
+func allowed(types []string, contentType string) bool {
+	for _, t := range types {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var testNow = time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+
+func newHMACVerifier(cfg config.AuthConfig) *Verifier {
+	cfg.HMACSecret = testSecret
+	v := NewVerifier(cfg, nil)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+// sign builds a token with an arbitrary header, signed by signer.
+func sign(t *testing.T, h header, c Claims, signer func(digest []byte) []byte) string {
+	t.Helper()
+	rawHeader, _ := json.Marshal(h)
+	payload, _ := json.Marshal(c)
+	input := encode(rawHeader) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(input))
+	return input + "." + encode(signer(digest[:]))
+}
+
+func TestHMACIssueAndVerify(t *testing.T) {
+	v := newHMACVerifier(config.AuthConfig{Issuer: "pestgenie-dev", Audience: "sdui", ClockSkew: time.Minute})
+	token, _, err := v.Issue("tech-1", "", time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	claims, err := v.Verify(context.Background(), token)
+	if err != nil || claims.Subject != "tech-1" || !claims.Audience.Contains("sdui") {
+		t.Fatalf("unexpected claims %+v (%v)", claims, err)
+	}
+
+	v.now = func() time.Time { return testNow.Add(time.Hour + 2*time.Minute) }
+	if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected expired token rejected, got %v", err)
+	}
+
+	other := newHMACVerifier(config.AuthConfig{Issuer: "someone-else"})
+	if _, err := other.Verify(context.Background(), token); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected wrong issuer rejected, got %v", err)
+	}
+
+	unsigned := sign(t, header{Alg: "none"}, Claims{Subject: "tech-1", ExpiresAt: testNow.Add(time.Hour).Unix()}, func([]byte) []byte { return nil })
+	if _, err := v.Verify(context.Background(), unsigned); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected alg none rejected, got %v", err)
+	}
+
+	if _, _, err := NewVerifier(config.AuthConfig{}, nil).Issue("tech-1", "", time.Hour); !errors.Is(err, ErrSigningDisabled) {
+		t.Fatalf("expected signing disabled without a secret, got %v", err)
+	}
+}
+
+func TestJWKSVerification(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var fetches atomic.Int32
+	keys := []map[string]string{{"kid": "rsa-1", "kty": "RSA", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	v := NewVerifier(config.AuthConfig{JWKSURL: server.URL, Issuer: "https://idp.example", JWKSRefresh: time.Hour, RequestTimeout: time.Second}, nil)
+	clock := testNow
+	v.now = func() time.Time { return clock }
+	claims := Claims{Issuer: "https://idp.example", Subject: "tech-1", ExpiresAt: testNow.Add(time.Hour).Unix()}
+
+	rsaToken := sign(t, header{Alg: AlgRS256, Kid: "rsa-1"}, claims, func(digest []byte) []byte {
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		return sig
+	})
+	if got, err := v.Verify(context.Background(), rsaToken); err != nil || got.Subject != "tech-1" {
+		t.Fatalf("unexpected rs256 result %+v (%v)", got, err)
+	}
+
+	// A rolled key is picked up by refetching on the unknown kid, but not more
+	// often than minRefetch.
+	keys = append(keys, map[string]string{"kid": "ec-1", "kty": "EC", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))})
+	ecToken := sign(t, header{Alg: AlgES256, Kid: "ec-1"}, claims, func(digest []byte) []byte {
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, digest)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+	if _, err := v.Verify(context.Background(), ecToken); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected unknown kid inside the refetch interval, got %v", err)
+	}
+	clock = clock.Add(minRefetch)
+	if got, err := v.Verify(context.Background(), ecToken); err != nil || got.Subject != "tech-1" {
+		t.Fatalf("unexpected es256 result %+v (%v)", got, err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("expected 2 jwks fetches, got %d", n)
+	}
+
+	// HS256 signed with the public modulus must not verify against the JWKS.
+	forged := sign(t, header{Alg: AlgHS256, Kid: "rsa-1"}, claims, func([]byte) []byte { return []byte("x") })
+	if _, err := v.Verify(context.Background(), forged); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected hs256 rejected in jwks mode, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v := newHMACVerifier(config.AuthConfig{Required: true})
+	var seen, session string
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = TechnicianID(r)
+		id, _ := FromContext(r.Context())
+		session = id.Session
+	}))
+	serve := func(authorization string) int {
+		seen, session = "", ""
+		req := httptest.NewRequest(http.MethodGet, "/v1/screens/today?userId=someone-else", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	token, _, _ := v.Issue("tech-1", "", time.Hour)
+	if code := serve("Bearer " + token); code != http.StatusOK || seen != "tech-1" {
+		t.Fatalf("expected the subject to replace userId, got %d %q", code, seen)
+	}
+	if code := serve(""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", code)
+	}
+	if code := serve("Bearer " + strings.Replace(token, ".", ".x", 1)); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a tampered token, got %d", code)
+	}
+	for _, c := range []struct{ sid, jti, want string }{{"s-1", "t-1", "s-1"}, {"", "t-1", "t-1"}} {
+		signed, _ := signHS256(Claims{Subject: "tech-1", ExpiresAt: time.Now().Add(time.Hour).Unix(), SessionID: c.sid, TokenID: c.jti}, v.cfg.RoleClaim, v.secret)
+		if code := serve("Bearer " + signed); code != http.StatusOK || session != c.want {
+			t.Errorf("expected session %q from sid %q and jti %q, got %d %q", c.want, c.sid, c.jti, code, session)
+		}
+	}
+	// API and impersonation tokens are left to their own middleware.
+	if code := serve("Bearer pg_abc123"); code != http.StatusOK || seen != "someone-else" {
+		t.Fatalf("expected other bearer tokens to pass through, got %d %q", code, seen)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "disp-1", Role: "Dispatcher"})
+	v := newHMACVerifier(config.AuthConfig{RoleClaim: "role"})
+	v.technicians = store
+
+	var seen string
+	handler := v.Middleware(OwnData(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = TechnicianID(r)
+	})))
+	admin := v.Middleware(v.RequireRole(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	serve := func(h http.Handler, target, subject, role string) int {
+		seen = ""
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if subject != "" {
+			token, _, err := v.Issue(subject, role, time.Hour)
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if _, _, err := v.Issue("tech-1", "owner", time.Hour); err == nil {
+		t.Fatal("expected unknown role rejected")
+	}
+	if code := serve(handler, "/v1/screens/today?userId=tech-2", "tech-1", ""); code != http.StatusForbidden {
+		t.Fatalf("expected technician blocked from another technician's data, got %d", code)
+	}
+	if code := serve(handler, "/v1/screens/today?userId=tech-1", "tech-1", ""); code != http.StatusOK || seen != "tech-1" {
+		t.Fatalf("expected technician to reach their own data, got %d %q", code, seen)
+	}
+	// The dispatcher role comes from the technician profile.
+	if code := serve(handler, "/v1/screens/today?userId=tech-2", "disp-1", ""); code != http.StatusOK || seen != "tech-2" {
+		t.Fatalf("expected dispatcher to act for tech-2, got %d %q", code, seen)
+	}
+
+	if code := serve(admin, "/admin/api-tokens", "", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without an identity, got %d", code)
+	}
+	if code := serve(admin, "/admin/api-tokens", "disp-1", ""); code != http.StatusForbidden {
+		t.Fatalf("expected dispatcher forbidden from admin routes, got %d", code)
+	}
+	if code := serve(admin, "/admin/api-tokens", "tech-1", RoleAdmin); code != http.StatusOK {
+		t.Fatalf("expected the role claim to grant admin, got %d", code)
+	}
+
+	disabled := NewVerifier(config.AuthConfig{}, nil).RequireRole(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if code := serve(disabled, "/admin/api-tokens", "", ""); code != http.StatusOK {
+		t.Fatalf("expected roles unchecked without authentication configured, got %d", code)
+	}
+}
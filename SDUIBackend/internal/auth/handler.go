@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// defaultDevTokenTTL applies when a dev token request omits ttlSeconds.
+const defaultDevTokenTTL = 12 * time.Hour
+
+// Handler issues tokens in the local/dev HMAC signing mode.
+type Handler struct {
+	verifier    *Verifier
+	technicians repository.TechnicianRepository
+}
+
+// NewHandler creates an auth handler.
+func NewHandler(verifier *Verifier, technicians repository.TechnicianRepository) *Handler {
+	return &Handler{verifier: verifier, technicians: technicians}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Post("/dev-tokens", h.IssueDevToken)
+}
+
+// maxSignInPeek bounds how much of a sign-in body SignInSubject reads.
+const maxSignInPeek = 64 << 10
+
+// SignInSubject returns the technician a dev token request names, so failed
+// sign-ins can be counted per identity, and leaves the body for the
+// handler to read.
+func SignInSubject(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	peeked, _ := io.ReadAll(io.LimitReader(r.Body, maxSignInPeek))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	var payload struct {
+		TechnicianID string `json:"technicianId"`
+	}
+	_ = json.Unmarshal(peeked, &payload)
+	return payload.TechnicianID
+}
+
+// IssueDevToken signs a token for a technician so local clients and tests can
+// authenticate without an identity provider.
+func (h *Handler) IssueDevToken(w http.ResponseWriter, r *http.Request) {
+	if !h.verifier.Signing() {
+		respond.Error(w, http.StatusNotFound, "dev tokens unavailable", "the server is not in the hmac signing mode")
+		return
+	}
+	var payload struct {
+		TechnicianID string `json:"technicianId"`
+		Role         string `json:"role,omitempty"` // defaults to the technician's profile role
+		TTLSeconds   int    `json:"ttlSeconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	if _, err := h.technicians.GetByID(payload.TechnicianID); errors.Is(err, repository.ErrTechnicianNotFound) {
+		respond.Error(w, http.StatusUnauthorized, "unknown technician", "technicianId does not name a known technician")
+		return
+	} else if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to issue token", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	ttl := time.Duration(payload.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultDevTokenTTL
+	}
+	token, claims, err := h.verifier.Issue(payload.TechnicianID, payload.Role, ttl)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "failed to issue token", err.Error())
+		return
+	}
+	respond.JSON(w, http.StatusCreated, map[string]any{
+		"token":     token,
+		"tokenType": "Bearer",
+		"subject":   claims.Subject,
+		"roles":     claims.Roles,
+		"expiresAt": time.Unix(claims.ExpiresAt, 0).UTC(),
+	})
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefetch bounds how often an unknown key ID can trigger a JWKS fetch, so
+// tokens with made-up kids cannot hammer the identity provider.
+const minRefetch = time.Minute
+
+// jwks caches the identity provider's signing keys. Keys are refreshed every
+// refresh interval, and early when a token names a key ID not yet seen, which
+// is how providers roll keys.
+type jwks struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetched   time.Time
+	attempted time.Time
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the public key for kid, fetching the key set when it is stale
+// or lacks kid.
+func (k *jwks) key(ctx context.Context, kid string) (any, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	key, ok := k.keys[kid]
+	if ok && now.Sub(k.fetched) < k.refresh {
+		return key, nil
+	}
+	if now.Sub(k.attempted) < minRefetch {
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthorized, kid)
+		}
+		return key, nil
+	}
+	k.attempted = now
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		if ok {
+			// Keep verifying with the cached key while the provider is down.
+			return key, nil
+		}
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	k.keys, k.fetched = keys, now
+	if key, ok = keys[kid]; !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthorized, kid)
+	}
+	return key, nil
+}
+
+func (k *jwks) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned %s", resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		// Keys we cannot use are skipped rather than failing the whole set.
+		if key, err := j.publicKey(); err == nil {
+			keys[j.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (j jwk) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decode(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(j.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := decode(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("point not on curve")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
@@ -0,0 +1,259 @@
+// Package auth authenticates technicians and staff with bearer JWTs and
+// authorizes them by role. Tokens are verified against the identity
+// provider's JWKS, or against a shared HMAC secret in the local/dev signing
+// mode, and the token subject becomes the technician the request acts for.
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is returned for tokens that fail verification.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrSigningDisabled is returned when issuing tokens outside the HMAC
+	// signing mode.
+	ErrSigningDisabled = errors.New("token signing is disabled")
+)
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// Claims are the registered JWT claims the service reads, plus the profile
+// claims identity providers commonly include.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	// SessionID is the sign-in the token was issued for, which identity
+	// providers keep across refreshes, and TokenID the token itself.
+	SessionID string `json:"sid,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+	// Roles are read from the configured role claim, which may hold a
+	// string or a list.
+	Roles []string `json:"-"`
+	// Tenant is read from the configured tenant claim.
+	Tenant string `json:"-"`
+}
+
+// Audience is the aud claim, which may be a single string or a list.
+type Audience []string
+
+// UnmarshalJSON accepts either form.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("aud must be a string or list of strings")
+	}
+	*a = many
+	return nil
+}
+
+// MarshalJSON writes a single audience as a string.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// Contains reports whether aud lists want.
+func (a Audience) Contains(want string) bool {
+	for _, v := range a {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// signHS256 encodes and signs claims with secret, writing Roles under
+// roleClaim.
+func signHS256(c Claims, roleClaim string, secret []byte) (string, error) {
+	h, err := json.Marshal(header{Alg: AlgHS256, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	if len(c.Roles) > 0 && roleClaim != "" {
+		var fields map[string]any
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return "", err
+		}
+		fields[roleClaim] = c.Roles
+		if payload, err = json.Marshal(fields); err != nil {
+			return "", err
+		}
+	}
+	signingInput := encode(h) + "." + encode(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + encode(mac.Sum(nil)), nil
+}
+
+// parsed is a token split into its parts with the header decoded.
+type parsed struct {
+	header       header
+	payload      []byte
+	signingInput string
+	signature    []byte
+}
+
+func parse(token string) (parsed, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return parsed{}, fmt.Errorf("%w: malformed token", ErrUnauthorized)
+	}
+	var p parsed
+	rawHeader, err := decode(parts[0])
+	if err != nil {
+		return parsed{}, fmt.Errorf("%w: malformed header", ErrUnauthorized)
+	}
+	if err := json.Unmarshal(rawHeader, &p.header); err != nil {
+		return parsed{}, fmt.Errorf("%w: malformed header", ErrUnauthorized)
+	}
+	if p.payload, err = decode(parts[1]); err != nil {
+		return parsed{}, fmt.Errorf("%w: malformed payload", ErrUnauthorized)
+	}
+	if p.signature, err = decode(parts[2]); err != nil {
+		return parsed{}, fmt.Errorf("%w: malformed signature", ErrUnauthorized)
+	}
+	p.signingInput = parts[0] + "." + parts[1]
+	return p, nil
+}
+
+// roles reads the role claim from a token payload.
+func roles(payload []byte, claim string) []string {
+	if claim == "" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(payload, &fields) != nil {
+		return nil
+	}
+	raw, ok := fields[claim]
+	if !ok {
+		return nil
+	}
+	var aud Audience // same string-or-list shape
+	if aud.UnmarshalJSON(raw) != nil {
+		return nil
+	}
+	return aud
+}
+
+// stringClaim returns claim from payload when it is a string.
+func stringClaim(payload []byte, claim string) string {
+	if claim == "" {
+		return ""
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(payload, &fields) != nil {
+		return ""
+	}
+	var v string
+	if json.Unmarshal(fields[claim], &v) != nil {
+		return ""
+	}
+	return v
+}
+
+// verifySignature checks the token signature with key, which must suit alg.
+func (p parsed) verifySignature(key any) error {
+	digest := sha256.Sum256([]byte(p.signingInput))
+	switch p.header.Alg {
+	case AlgHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			break
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(p.signingInput))
+		if hmac.Equal(mac.Sum(nil), p.signature) {
+			return nil
+		}
+		return fmt.Errorf("%w: bad signature", ErrUnauthorized)
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			break
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], p.signature) == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: bad signature", ErrUnauthorized)
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(p.signature) != 64 {
+			break
+		}
+		r := new(big.Int).SetBytes(p.signature[:32])
+		s := new(big.Int).SetBytes(p.signature[32:])
+		if ecdsa.Verify(pub, digest[:], r, s) {
+			return nil
+		}
+		return fmt.Errorf("%w: bad signature", ErrUnauthorized)
+	}
+	return fmt.Errorf("%w: key does not match algorithm %s", ErrUnauthorized, p.header.Alg)
+}
+
+// validate checks the time, issuer, audience and subject claims.
+func (c Claims) validate(now time.Time, skew time.Duration, issuer, audience string) error {
+	switch {
+	case c.ExpiresAt == 0:
+		return fmt.Errorf("%w: exp is required", ErrUnauthorized)
+	case now.Add(-skew).After(time.Unix(c.ExpiresAt, 0)):
+		return fmt.Errorf("%w: token expired", ErrUnauthorized)
+	case c.NotBefore != 0 && now.Add(skew).Before(time.Unix(c.NotBefore, 0)):
+		return fmt.Errorf("%w: token not yet valid", ErrUnauthorized)
+	case issuer != "" && c.Issuer != issuer:
+		return fmt.Errorf("%w: unexpected issuer %q", ErrUnauthorized, c.Issuer)
+	case audience != "" && !c.Audience.Contains(audience):
+		return fmt.Errorf("%w: token not issued for this audience", ErrUnauthorized)
+	case strings.TrimSpace(c.Subject) == "":
+		return fmt.Errorf("%w: sub is required", ErrUnauthorized)
+	}
+	return nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Identity is the authenticated user behind a request.
+type Identity struct {
+	Subject string // technician ID for technicians
+	Role    string // technician, dispatcher or admin
+	Name    string
+	Email   string
+	Issuer  string
+	Tenant  string // tenant the token belongs to, when it names one
+	// Session is the token's sid claim, else its jti: the sign-in, and so
+	// the device, the request comes from. Empty when the token has neither.
+	Session string
+}
+
+// identityKey is the context key for the authenticated identity.
+type identityKey struct{}
+
+// FromContext returns the identity that authenticated the request, if any.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// ContextWithIdentity returns ctx carrying id.
+func ContextWithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// TechnicianID returns the technician a request acts for: the authenticated
+// subject when there is one, else the legacy ?userId= query parameter (which
+// impersonation also sets). Staff act for the ?userId= technician when they
+// name one.
+func TechnicianID(r *http.Request) string {
+	userID := r.URL.Query().Get("userId")
+	if id, ok := FromContext(r.Context()); ok && !(id.Staff() && userID != "") {
+		return id.Subject
+	}
+	return userID
+}
+
+// Middleware authenticates bearer JWTs and puts the technician's identity in
+// the request context. Other bearer credentials (API and impersonation
+// tokens) are left for their own middleware. Requests without a bearer
+// credential pass through unless authentication is required.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, present := bearerJWT(r)
+		if token == "" {
+			if !present && v.cfg.Required {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie"`)
+				respond.Error(w, http.StatusUnauthorized, "authentication required", "provide a bearer token")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !v.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := v.Verify(r.Context(), token)
+		if err != nil {
+			logger := middleware.LoggerFrom(r.Context())
+			if errors.Is(err, ErrUnauthorized) {
+				logger.Info("jwt rejected", slog.Any("error", err))
+			} else {
+				logger.Error("jwt verification failed", slog.Any("error", err))
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie", error="invalid_token"`)
+			respond.Error(w, http.StatusUnauthorized, "invalid token", "token is malformed, expired, or not trusted")
+			return
+		}
+
+		id := Identity{Subject: claims.Subject, Role: v.role(claims), Name: claims.Name, Email: claims.Email, Issuer: claims.Issuer, Tenant: claims.Tenant, Session: claims.SessionID}
+		if id.Session == "" {
+			id.Session = claims.TokenID
+		}
+		ctx := ContextWithIdentity(r.Context(), id)
+		ctx = middleware.ContextWithLogger(ctx, middleware.LoggerFrom(ctx).With(slog.String("subject", id.Subject), slog.String("role", id.Role)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerJWT returns the bearer token when it is shaped like a JWT. present
+// reports whether the request carried any bearer credential.
+func bearerJWT(r *http.Request) (token string, present bool) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	if strings.Count(value, ".") != 2 {
+		return "", value != ""
+	}
+	return value, true
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Roles, from least to most privileged.
+const (
+	RoleTechnician = "technician"
+	RoleDispatcher = "dispatcher"
+	RoleAdmin      = "admin"
+)
+
+var rank = map[string]int{RoleTechnician: 1, RoleDispatcher: 2, RoleAdmin: 3}
+
+func knownRole(role string) bool {
+	_, ok := rank[role]
+	return ok
+}
+
+// highestRole returns the most privileged known role in roles, or "".
+func highestRole(roles []string) string {
+	best := ""
+	for _, r := range roles {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if rank[r] > rank[best] {
+			best = r
+		}
+	}
+	return best
+}
+
+// role resolves the role of a verified token: its role claim, else the
+// subject's technician profile, else technician.
+func (v *Verifier) role(c Claims) string {
+	if role := highestRole(c.Roles); role != "" {
+		return role
+	}
+	if v.technicians != nil {
+		if tech, err := v.technicians.GetByID(c.Subject); err == nil {
+			if role := highestRole([]string{tech.Role}); role != "" {
+				return role
+			}
+		}
+	}
+	return RoleTechnician
+}
+
+// Staff reports whether the identity is a dispatcher or admin, who may act
+// for any technician.
+func (id Identity) Staff() bool {
+	return id.Role == RoleDispatcher || id.Role == RoleAdmin
+}
+
+// RequireRole admits authenticated identities holding one of roles. When JWT
+// authentication is not configured there are no identities to check and
+// every request is admitted, as before authentication existed; config
+// refuses that in prod.
+func (v *Verifier) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !v.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, ok := FromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie"`)
+				respond.Error(w, http.StatusUnauthorized, "authentication required", "provide a bearer token")
+				return
+			}
+			for _, role := range roles {
+				if id.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			respond.Error(w, http.StatusForbidden, "forbidden", "requires role "+strings.Join(roles, " or "))
+		})
+	}
+}
+
+// OwnData stops technicians from naming another technician with ?userId=.
+// Staff may act for anyone; requests without an identity pass through.
+func OwnData(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := FromContext(r.Context())
+		if ok && !id.Staff() {
+			if other := r.URL.Query().Get("userId"); other != "" && other != id.Subject {
+				respond.Error(w, http.StatusForbidden, "forbidden", "technicians can only access their own data")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Verifier validates bearer JWTs according to AuthConfig.
+type Verifier struct {
+	cfg         config.AuthConfig
+	secret      []byte
+	keys        *jwks
+	technicians repository.TechnicianRepository
+	now         func() time.Time
+}
+
+// NewVerifier creates a verifier. It verifies against the JWKS when JWKSURL is
+// set and with the HMAC secret otherwise; with neither, Enabled is false.
+// Tokens without a role claim take the role of the subject's technician
+// profile in technicians, which may be nil.
+func NewVerifier(cfg config.AuthConfig, technicians repository.TechnicianRepository) *Verifier {
+	v := &Verifier{cfg: cfg, technicians: technicians, now: time.Now}
+	switch {
+	case cfg.JWKSURL != "":
+		v.keys = &jwks{url: cfg.JWKSURL, client: &http.Client{Timeout: cfg.RequestTimeout}, refresh: cfg.JWKSRefresh, now: func() time.Time { return v.now() }}
+	case cfg.HMACSecret != "":
+		v.secret = []byte(cfg.HMACSecret)
+	}
+	return v
+}
+
+// Enabled reports whether the verifier has a key to verify with.
+func (v *Verifier) Enabled() bool {
+	return v.keys != nil || v.secret != nil
+}
+
+// Signing reports whether the verifier is in the local/dev HMAC mode and can
+// issue tokens.
+func (v *Verifier) Signing() bool {
+	return v.secret != nil
+}
+
+// Verify checks a token's signature and claims and returns its claims.
+// Verification failures wrap ErrUnauthorized; other errors mean the keys
+// could not be loaded.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	if !v.Enabled() {
+		return Claims{}, fmt.Errorf("%w: jwt authentication is not configured", ErrUnauthorized)
+	}
+	p, err := parse(token)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	var key any
+	switch {
+	case v.secret != nil:
+		if p.header.Alg != AlgHS256 {
+			return Claims{}, fmt.Errorf("%w: algorithm %q not accepted", ErrUnauthorized, p.header.Alg)
+		}
+		key = v.secret
+	case p.header.Alg == AlgRS256 || p.header.Alg == AlgES256:
+		if key, err = v.keys.key(ctx, p.header.Kid); err != nil {
+			return Claims{}, err
+		}
+	default:
+		return Claims{}, fmt.Errorf("%w: algorithm %q not accepted", ErrUnauthorized, p.header.Alg)
+	}
+	if err := p.verifySignature(key); err != nil {
+		return Claims{}, err
+	}
+
+	var c Claims
+	if err := json.Unmarshal(p.payload, &c); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed claims", ErrUnauthorized)
+	}
+	if err := c.validate(v.now(), v.cfg.ClockSkew, v.cfg.Issuer, v.cfg.Audience); err != nil {
+		return Claims{}, err
+	}
+	c.Roles = roles(p.payload, v.cfg.RoleClaim)
+	c.Tenant = stringClaim(p.payload, v.cfg.TenantClaim)
+	return c, nil
+}
+
+// Issue signs a token for subject valid for ttl, carrying role when set. It
+// is only available in the HMAC signing mode, for local testing and
+// development.
+func (v *Verifier) Issue(subject, role string, ttl time.Duration) (string, Claims, error) {
+	if !v.Signing() {
+		return "", Claims{}, ErrSigningDisabled
+	}
+	subject = strings.TrimSpace(subject)
+	if subject == "" || ttl <= 0 {
+		return "", Claims{}, fmt.Errorf("subject and a positive ttl are required")
+	}
+	now := v.now()
+	c := Claims{
+		Issuer:    v.cfg.Issuer,
+		Subject:   subject,
+		ExpiresAt: now.Add(ttl).Unix(),
+		IssuedAt:  now.Unix(),
+	}
+	if v.cfg.Audience != "" {
+		c.Audience = Audience{v.cfg.Audience}
+	}
+	if role != "" {
+		if !knownRole(role) {
+			return "", Claims{}, fmt.Errorf("unknown role %q", role)
+		}
+		c.Roles = []string{role}
+	}
+	token, err := signHS256(c, v.cfg.RoleClaim, v.secret)
+	return token, c, err
+}
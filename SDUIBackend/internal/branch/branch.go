@@ -0,0 +1,103 @@
+// Package branch models the offices between a tenant and its technicians.
+// Technicians belong to a branch, and routes, inventory and dashboard
+// rollups are scoped through that membership.
+package branch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a branch or technician does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidBranch wraps branch validation failures.
+	ErrInvalidBranch = errors.New("invalid branch")
+)
+
+// Branch is an office that technicians work out of. Its ID also keys the
+// branch's business hours calendar.
+type Branch struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenantId,omitempty"`
+	Name      string    `json:"name"`
+	Region    string    `json:"region,omitempty"`
+	Address   string    `json:"address,omitempty"`
+	TimeZone  string    `json:"timeZone"`
+	ManagerID string    `json:"managerId,omitempty"` // technician ID of the branch manager
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks a branch.
+func (b Branch) Validate() error {
+	var problems []string
+	if strings.TrimSpace(b.ID) == "" {
+		problems = append(problems, "id is required")
+	} else if strings.ContainsAny(b.ID, "/ ") {
+		problems = append(problems, "id must not contain slashes or spaces")
+	}
+	if strings.TrimSpace(b.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if _, err := time.LoadLocation(b.TimeZone); err != nil || b.TimeZone == "" {
+		problems = append(problems, fmt.Sprintf("timeZone %q is not a known IANA zone", b.TimeZone))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidBranch, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Store persists branches.
+type Store interface {
+	SaveBranch(b Branch) error
+	GetBranch(id string) (Branch, error)
+	ListBranches() ([]Branch, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	branches map[string]Branch
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{branches: make(map[string]Branch)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveBranch(b Branch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.branches[b.ID] = b
+	return nil
+}
+
+func (m *MemoryStore) GetBranch(id string) (Branch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	b, ok := m.branches[id]
+	if !ok {
+		return Branch{}, ErrNotFound
+	}
+	return b, nil
+}
+
+func (m *MemoryStore) ListBranches() ([]Branch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Branch, 0, len(m.branches))
+	for _, b := range m.branches {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
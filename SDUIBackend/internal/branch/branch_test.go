@@ -0,0 +1,148 @@
+package branch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	svc := NewService(NewMemoryStore(), repos, nil)
+	svc.now = func() time.Time { return day.Add(9 * time.Hour) }
+	return svc, store
+}
+
+func TestSaveValidatesBranch(t *testing.T) {
+	svc, store := newTestService(t)
+	if _, err := svc.Save("north", Branch{TimeZone: "Mars/Olympus"}); !errors.Is(err, ErrInvalidBranch) {
+		t.Fatalf("expected invalid branch, got %v", err)
+	}
+	if _, err := svc.Save("north", Branch{Name: "North", TimeZone: "America/Chicago", ManagerID: "nobody"}); !errors.Is(err, ErrInvalidBranch) {
+		t.Fatalf("expected unknown manager rejected, got %v", err)
+	}
+	store.AddTechnician(models.Technician{ID: "mgr"})
+	b, err := svc.Save("north", Branch{TenantID: "acme", Name: "North", TimeZone: "America/Chicago", ManagerID: "mgr"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.ID != "north" || b.CreatedAt.IsZero() {
+		t.Fatalf("unexpected branch %+v", b)
+	}
+	if list, _ := svc.Branches("other"); len(list) != 0 {
+		t.Fatalf("expected no branches for another tenant, got %+v", list)
+	}
+}
+
+func TestBranchScopesRoutesInventoryAndRollup(t *testing.T) {
+	svc, store := newTestService(t)
+	for _, id := range []string{"north", "south"} {
+		if _, err := svc.Save(id, Branch{Name: id, Region: id, TimeZone: "UTC"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"t1", "t2", "t3", "t4"} {
+		store.AddTechnician(models.Technician{ID: id, Region: "legacy"})
+	}
+	for tech, b := range map[string]string{"t1": "north", "t2": "north", "t3": "south"} {
+		if _, err := svc.Assign(b, tech); err != nil {
+			t.Fatalf("assign %s: %v", tech, err)
+		}
+	}
+	if _, err := svc.Assign("east", "t4"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected unknown branch, got %v", err)
+	}
+	if tech, _ := store.GetByID("t1"); tech.BranchID != "north" || tech.Region != "north" {
+		t.Fatalf("expected branch and region set, got %+v", tech)
+	}
+
+	_ = store.SaveRoute(models.Route{ID: "r1", TechnicianID: "t1", ServiceDate: day, CustomerStops: make([]models.RouteStop, 3), Alerts: make([]models.RouteAlert, 1)})
+	_ = store.SaveRoute(models.Route{ID: "r3", TechnicianID: "t3", ServiceDate: day, CustomerStops: make([]models.RouteStop, 2)})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j1", TechnicianID: "t1", ScheduledDate: day, Status: "completed"})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j2", TechnicianID: "t2", ScheduledDate: day, Status: "pending"})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j3", TechnicianID: "t2", ScheduledDate: day.AddDate(0, 0, 1), Status: "pending"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "c1", TechnicianID: "t1", Name: "Termidor", EPARegistration: "7969-210", QuantityInStock: 2})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "c2", TechnicianID: "t2", Name: "Termidor SC", EPARegistration: "7969-210", QuantityInStock: 1.5, ExpirationDate: day})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "c3", TechnicianID: "t3", Name: "Talstar", QuantityInStock: 4})
+
+	routes, err := svc.Routes("north", day)
+	if err != nil || len(routes) != 1 || routes[0].ID != "r1" {
+		t.Fatalf("expected north's one route, got %+v (%v)", routes, err)
+	}
+
+	inv, err := svc.Inventory("north")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inv.Items) != 2 || !inv.Items[1].Expired || len(inv.Totals) != 1 || inv.Totals[0].QuantityInStock != 3.5 || inv.Totals[0].Technicians != 2 {
+		t.Fatalf("unexpected inventory %+v", inv)
+	}
+
+	rollup, err := svc.Rollup(day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rollup) != 3 || rollup[2].BranchID != Unassigned || rollup[2].Technicians != 1 {
+		t.Fatalf("expected north, south and unassigned, got %+v", rollup)
+	}
+	north := rollup[0]
+	if north.Technicians != 2 || north.Routes != 1 || north.Stops != 3 || north.Alerts != 1 ||
+		north.Jobs["completed"] != 1 || north.Jobs["pending"] != 1 || north.InventoryItems != 2 || north.ExpiredChemicals != 1 {
+		t.Fatalf("unexpected north summary %+v", north)
+	}
+
+	if err := svc.Unassign("south", "t1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected unassigning from the wrong branch to fail, got %v", err)
+	}
+	if err := svc.Unassign("north", "t1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if techs, _ := svc.Technicians("north"); len(techs) != 1 {
+		t.Fatalf("expected one north technician left, got %+v", techs)
+	}
+}
+
+func TestAssignTechniciansReportsEachTechnician(t *testing.T) {
+	svc, store := newTestService(t)
+	if _, err := svc.Save("north", Branch{Name: "North", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+	store.AddTechnician(models.Technician{ID: "t1"})
+	store.AddTechnician(models.Technician{ID: "t2"})
+
+	router := chi.NewRouter()
+	router.Route("/branches", NewHandler(svc).Routes)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/branches/north/technicians", strings.NewReader(`{"technicianIds":["t1","ghost","t2"]}`)))
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rec.Code, rec.Body)
+	}
+	var body respond.MultiStatusBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Succeeded != 2 || body.Failed != 1 || body.Retryable != 0 {
+		t.Fatalf("unexpected counts %+v", body)
+	}
+	if miss := body.Results[1]; miss.EntityID != "ghost" || miss.Status != http.StatusNotFound || miss.Problem == nil || miss.Problem.Retryable {
+		t.Fatalf("unexpected failed item %+v", miss)
+	}
+	if techs, _ := svc.Technicians("north"); len(techs) != 2 {
+		t.Fatalf("expected both known technicians assigned, got %+v", techs)
+	}
+}
@@ -0,0 +1,235 @@
+package branch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes branch management and branch-scoped reports.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a branch handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListBranches)
+	r.Get("/rollup", h.GetRollup)
+	r.Get("/{branchId}", h.GetBranch)
+	r.Put("/{branchId}", h.SaveBranch)
+	r.Get("/{branchId}/technicians", h.ListTechnicians)
+	r.Post("/{branchId}/technicians", h.AssignTechnicians)
+	r.Put("/{branchId}/technicians/{technicianId}", h.AssignTechnician)
+	r.Delete("/{branchId}/technicians/{technicianId}", h.UnassignTechnician)
+	r.Get("/{branchId}/routes", h.ListRoutes)
+	r.Get("/{branchId}/inventory", h.GetInventory)
+	r.Get("/{branchId}/summary", h.GetSummary)
+}
+
+// ListBranches returns branches, optionally for one ?tenantId=.
+func (h *Handler) ListBranches(w http.ResponseWriter, r *http.Request) {
+	branches, err := h.service.Branches(r.URL.Query().Get("tenantId"))
+	if err != nil {
+		h.fail(w, r, "failed to list branches", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"branches": branches})
+}
+
+// GetBranch returns a branch.
+func (h *Handler) GetBranch(w http.ResponseWriter, r *http.Request) {
+	b, err := h.service.Branch(chi.URLParam(r, "branchId"))
+	if err != nil {
+		h.fail(w, r, "failed to load branch", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, b)
+}
+
+// SaveBranch creates or replaces a branch.
+func (h *Handler) SaveBranch(w http.ResponseWriter, r *http.Request) {
+	var payload Branch
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	b, err := h.service.Save(chi.URLParam(r, "branchId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save branch", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, b)
+}
+
+// ListTechnicians returns a branch's technicians.
+func (h *Handler) ListTechnicians(w http.ResponseWriter, r *http.Request) {
+	techs, err := h.service.Technicians(chi.URLParam(r, "branchId"))
+	if err != nil {
+		h.fail(w, r, "failed to list technicians", err)
+		return
+	}
+	out := make([]map[string]any, 0, len(techs))
+	for _, t := range techs {
+		out = append(out, map[string]any{"id": t.ID, "displayName": t.DisplayName, "role": t.Role, "branchId": t.BranchID})
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"technicians": out})
+}
+
+// AssignTechnician moves a technician into the branch.
+func (h *Handler) AssignTechnician(w http.ResponseWriter, r *http.Request) {
+	tech, err := h.service.Assign(chi.URLParam(r, "branchId"), chi.URLParam(r, "technicianId"))
+	if err != nil {
+		h.fail(w, r, "failed to assign technician", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"id": tech.ID, "branchId": tech.BranchID})
+}
+
+// AssignTechnicians moves the technicians listed in the body into the
+// branch, answering 207 Multi-Status with a result per technician.
+// Assigning is idempotent, so failed technicians can simply be resent.
+func (h *Handler) AssignTechnicians(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TechnicianIDs []string `json:"technicianIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	if len(payload.TechnicianIDs) == 0 {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", "technicianIds is required")
+		return
+	}
+	branchID := chi.URLParam(r, "branchId")
+	results := make([]respond.ItemResult, 0, len(payload.TechnicianIDs))
+	for i, id := range payload.TechnicianIDs {
+		result := respond.ItemResult{Index: i, Kind: "technician", EntityID: id}
+		tech, err := h.service.Assign(branchID, id)
+		if err != nil {
+			status, detail := h.failure(r, "failed to assign technician", err)
+			problem := respond.NewProblem(w, status, "failed to assign technician", detail, respond.WithEntityID(id))
+			result.Status, result.Problem = status, &problem
+		} else {
+			result.Status, result.Result = http.StatusOK, map[string]any{"id": tech.ID, "branchId": tech.BranchID}
+		}
+		results = append(results, result)
+	}
+	respond.MultiStatus(w, results)
+}
+
+// UnassignTechnician removes a technician from the branch.
+func (h *Handler) UnassignTechnician(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Unassign(chi.URLParam(r, "branchId"), chi.URLParam(r, "technicianId")); err != nil {
+		h.fail(w, r, "failed to unassign technician", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRoutes returns the branch's routes on ?serviceDate= (default today).
+func (h *Handler) ListRoutes(w http.ResponseWriter, r *http.Request) {
+	date, ok := h.serviceDate(w, r)
+	if !ok {
+		return
+	}
+	routes, err := h.service.Routes(chi.URLParam(r, "branchId"), date)
+	if err != nil {
+		h.fail(w, r, "failed to list routes", err)
+		return
+	}
+	out := make([]map[string]any, 0, len(routes))
+	for _, route := range routes {
+		out = append(out, map[string]any{
+			"id":           route.ID,
+			"technicianId": route.TechnicianID,
+			"serviceDate":  route.ServiceDate.Format("2006-01-02"),
+			"stops":        len(route.CustomerStops),
+			"alerts":       len(route.Alerts),
+			"lastModified": route.LastModified,
+		})
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"routes": out})
+}
+
+// GetInventory returns the chemical stock held by the branch.
+func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	inv, err := h.service.Inventory(chi.URLParam(r, "branchId"))
+	if err != nil {
+		h.fail(w, r, "failed to load inventory", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, inv)
+}
+
+// GetSummary returns the branch dashboard on ?serviceDate= (default today).
+func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	date, ok := h.serviceDate(w, r)
+	if !ok {
+		return
+	}
+	sum, err := h.service.Summary(chi.URLParam(r, "branchId"), date)
+	if err != nil {
+		h.fail(w, r, "failed to summarize branch", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, sum)
+}
+
+// GetRollup returns every branch's dashboard on ?serviceDate= (default today).
+func (h *Handler) GetRollup(w http.ResponseWriter, r *http.Request) {
+	date, ok := h.serviceDate(w, r)
+	if !ok {
+		return
+	}
+	summaries, err := h.service.Rollup(date)
+	if err != nil {
+		h.fail(w, r, "failed to roll up branches", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"branches": summaries})
+}
+
+func (h *Handler) serviceDate(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	v := r.URL.Query().Get("serviceDate")
+	if v == "" {
+		now := h.service.now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+	date, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	status, detail := h.failure(r, title, err)
+	respond.Error(w, status, title, detail, respond.WithCause(err))
+}
+
+// failure maps err to a status and detail, logging unexpected errors.
+func (h *Handler) failure(r *http.Request, title string, err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, ErrInvalidBranch):
+		return http.StatusBadRequest, err.Error()
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		return http.StatusInternalServerError, "temporary error, please retry"
+	}
+}
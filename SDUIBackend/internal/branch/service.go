@@ -0,0 +1,352 @@
+package branch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Unassigned is the rollup key for technicians without a branch.
+const Unassigned = "unassigned"
+
+// InventoryItem is one technician's stock of a chemical.
+type InventoryItem struct {
+	ChemicalID      string    `json:"chemicalId"`
+	TechnicianID    string    `json:"technicianId"`
+	Name            string    `json:"name"`
+	EPARegistration string    `json:"epaRegistration,omitempty"`
+	UnitOfMeasure   string    `json:"unitOfMeasure,omitempty"`
+	QuantityInStock float64   `json:"quantityInStock"`
+	ExpirationDate  time.Time `json:"expirationDate,omitempty"`
+	Expired         bool      `json:"expired"`
+}
+
+// InventoryTotal is a branch's stock of one product across its technicians,
+// keyed by EPA registration number or, without one, by name.
+type InventoryTotal struct {
+	Product         string  `json:"product"`
+	UnitOfMeasure   string  `json:"unitOfMeasure,omitempty"`
+	QuantityInStock float64 `json:"quantityInStock"`
+	Technicians     int     `json:"technicians"`
+}
+
+// Inventory is the chemical stock held by a branch's technicians.
+type Inventory struct {
+	BranchID string           `json:"branchId"`
+	Items    []InventoryItem  `json:"items"`
+	Totals   []InventoryTotal `json:"totals"`
+}
+
+// Summary is a branch's dashboard for one service date.
+type Summary struct {
+	BranchID         string         `json:"branchId"`
+	Name             string         `json:"name"`
+	ServiceDate      string         `json:"serviceDate"`
+	Technicians      int            `json:"technicians"`
+	Routes           int            `json:"routes"`
+	Stops            int            `json:"stops"`
+	Alerts           int            `json:"alerts"`
+	Jobs             map[string]int `json:"jobs"` // by status
+	InventoryItems   int            `json:"inventoryItems"`
+	ExpiredChemicals int            `json:"expiredChemicals"`
+}
+
+// Service manages branches and technician membership, and scopes routes,
+// inventory and dashboards to a branch.
+type Service struct {
+	store  Store
+	repos  repository.Repository
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService wires a branch service.
+func NewService(store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, repos: repos, logger: logger, now: time.Now}
+}
+
+// Save creates or replaces a branch.
+func (s *Service) Save(id string, b Branch) (Branch, error) {
+	b.ID = strings.TrimSpace(id)
+	if err := b.Validate(); err != nil {
+		return Branch{}, err
+	}
+	if b.ManagerID != "" {
+		if _, err := s.repos.Technicians.GetByID(b.ManagerID); err != nil {
+			return Branch{}, fmt.Errorf("%w: manager %q not found", ErrInvalidBranch, b.ManagerID)
+		}
+	}
+	now := s.now().UTC()
+	b.CreatedAt, b.UpdatedAt = now, now
+	if existing, err := s.store.GetBranch(b.ID); err == nil {
+		b.CreatedAt = existing.CreatedAt
+	}
+	if err := s.store.SaveBranch(b); err != nil {
+		return Branch{}, err
+	}
+	s.logger.Info("branch saved", slog.String("branch", b.ID), slog.String("tenant", b.TenantID))
+	return b, nil
+}
+
+// Branch returns a branch.
+func (s *Service) Branch(id string) (Branch, error) {
+	return s.store.GetBranch(id)
+}
+
+// Branches lists branches, limited to a tenant when tenantID is set.
+func (s *Service) Branches(tenantID string) ([]Branch, error) {
+	all, err := s.store.ListBranches()
+	if err != nil || tenantID == "" {
+		return all, err
+	}
+	out := make([]Branch, 0, len(all))
+	for _, b := range all {
+		if b.TenantID == tenantID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// Assign moves a technician into a branch. An empty branchID removes them
+// from their branch.
+func (s *Service) Assign(branchID, technicianID string) (models.Technician, error) {
+	var b Branch
+	if branchID != "" {
+		var err error
+		if b, err = s.store.GetBranch(branchID); err != nil {
+			return models.Technician{}, err
+		}
+	}
+	tech, err := s.repos.Technicians.GetByID(technicianID)
+	if err != nil {
+		return models.Technician{}, fmt.Errorf("technician %q: %w", technicianID, ErrNotFound)
+	}
+	tech.BranchID = b.ID
+	if b.Region != "" {
+		tech.Region = b.Region
+	}
+	if err := s.repos.Technicians.SaveTechnician(tech); err != nil {
+		return models.Technician{}, err
+	}
+	s.logger.Info("technician branch assigned", slog.String("technician", tech.ID), slog.String("branch", b.ID))
+	return tech, nil
+}
+
+// Unassign removes a technician from a branch.
+func (s *Service) Unassign(branchID, technicianID string) error {
+	tech, err := s.repos.Technicians.GetByID(technicianID)
+	if err != nil || tech.BranchID != branchID {
+		return fmt.Errorf("technician %q in branch %q: %w", technicianID, branchID, ErrNotFound)
+	}
+	_, err = s.Assign("", technicianID)
+	return err
+}
+
+// Technicians lists a branch's technicians.
+func (s *Service) Technicians(branchID string) ([]models.Technician, error) {
+	if _, err := s.store.GetBranch(branchID); err != nil {
+		return nil, err
+	}
+	return s.members(branchID)
+}
+
+func (s *Service) members(branchID string) ([]models.Technician, error) {
+	all, err := s.repos.Technicians.ListTechnicians()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Technician, 0, len(all))
+	for _, t := range all {
+		if t.BranchID == branchID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Routes returns the routes of a branch's technicians on a date.
+func (s *Service) Routes(branchID string, serviceDate time.Time) ([]models.Route, error) {
+	techs, err := s.Technicians(branchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.routes(techs, serviceDate), nil
+}
+
+// routes loads the technicians' routes, skipping those without one.
+func (s *Service) routes(techs []models.Technician, serviceDate time.Time) []models.Route {
+	out := make([]models.Route, 0, len(techs))
+	for _, t := range techs {
+		if route, err := s.repos.Routes.GetRoute(t.ID, serviceDate); err == nil {
+			out = append(out, route)
+		}
+	}
+	return out
+}
+
+// Inventory returns the chemical stock of a branch's technicians.
+func (s *Service) Inventory(branchID string) (Inventory, error) {
+	techs, err := s.Technicians(branchID)
+	if err != nil {
+		return Inventory{}, err
+	}
+	chemicals, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return Inventory{}, err
+	}
+	return s.inventory(branchID, techs, chemicals), nil
+}
+
+func (s *Service) inventory(branchID string, techs []models.Technician, chemicals []models.ChemicalUpload) Inventory {
+	member := make(map[string]bool, len(techs))
+	for _, t := range techs {
+		member[t.ID] = true
+	}
+	now := s.now()
+	inv := Inventory{BranchID: branchID, Items: []InventoryItem{}, Totals: []InventoryTotal{}}
+	totals := make(map[string]*InventoryTotal)
+	holders := make(map[string]map[string]bool)
+	for _, c := range chemicals {
+		if !member[c.TechnicianID] {
+			continue
+		}
+		inv.Items = append(inv.Items, InventoryItem{
+			ChemicalID:      c.ID,
+			TechnicianID:    c.TechnicianID,
+			Name:            c.Name,
+			EPARegistration: c.EPARegistration,
+			UnitOfMeasure:   c.UnitOfMeasure,
+			QuantityInStock: c.QuantityInStock,
+			ExpirationDate:  c.ExpirationDate,
+			Expired:         !c.ExpirationDate.IsZero() && c.ExpirationDate.Before(now),
+		})
+		product := c.EPARegistration
+		if product == "" {
+			product = c.Name
+		}
+		total, ok := totals[product]
+		if !ok {
+			total = &InventoryTotal{Product: product, UnitOfMeasure: c.UnitOfMeasure}
+			totals[product] = total
+			holders[product] = make(map[string]bool)
+		}
+		total.QuantityInStock += c.QuantityInStock
+		holders[product][c.TechnicianID] = true
+	}
+	for product, total := range totals {
+		total.Technicians = len(holders[product])
+		inv.Totals = append(inv.Totals, *total)
+	}
+	sort.Slice(inv.Items, func(i, j int) bool {
+		if inv.Items[i].TechnicianID != inv.Items[j].TechnicianID {
+			return inv.Items[i].TechnicianID < inv.Items[j].TechnicianID
+		}
+		return inv.Items[i].ChemicalID < inv.Items[j].ChemicalID
+	})
+	sort.Slice(inv.Totals, func(i, j int) bool { return inv.Totals[i].Product < inv.Totals[j].Product })
+	return inv
+}
+
+// Summary returns a branch's dashboard for a service date.
+func (s *Service) Summary(branchID string, serviceDate time.Time) (Summary, error) {
+	b, err := s.store.GetBranch(branchID)
+	if err != nil {
+		return Summary{}, err
+	}
+	summaries, err := s.summarize([]Branch{b}, serviceDate)
+	if err != nil {
+		return Summary{}, err
+	}
+	return summaries[0], nil
+}
+
+// Rollup returns every branch's dashboard for a service date, followed by
+// one for technicians without a branch when there are any.
+func (s *Service) Rollup(serviceDate time.Time) ([]Summary, error) {
+	branches, err := s.store.ListBranches()
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.summarize(append(branches, Branch{Name: "Unassigned"}), serviceDate)
+	if err != nil {
+		return nil, err
+	}
+	if last := summaries[len(summaries)-1]; last.Technicians == 0 {
+		summaries = summaries[:len(summaries)-1]
+	}
+	return summaries, nil
+}
+
+// summarize builds the dashboards of branches, loading shared data once. A
+// branch with an empty ID collects the technicians without a branch.
+func (s *Service) summarize(branches []Branch, serviceDate time.Time) ([]Summary, error) {
+	techs, err := s.repos.Technicians.ListTechnicians()
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	chemicals, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	byBranch := make(map[string][]models.Technician)
+	branchOf := make(map[string]string, len(techs))
+	for _, t := range techs {
+		byBranch[t.BranchID] = append(byBranch[t.BranchID], t)
+		branchOf[t.ID] = t.BranchID
+	}
+	day := serviceDate.Format("2006-01-02")
+	jobCounts := make(map[string]map[string]int)
+	for _, j := range jobs {
+		if j.ScheduledDate.Format("2006-01-02") != day {
+			continue
+		}
+		branchID, ok := branchOf[j.TechnicianID]
+		if !ok {
+			continue
+		}
+		if jobCounts[branchID] == nil {
+			jobCounts[branchID] = make(map[string]int)
+		}
+		jobCounts[branchID][j.Status]++
+	}
+
+	out := make([]Summary, 0, len(branches))
+	for _, b := range branches {
+		members := byBranch[b.ID]
+		sum := Summary{BranchID: b.ID, Name: b.Name, ServiceDate: day, Technicians: len(members), Jobs: jobCounts[b.ID]}
+		if b.ID == "" {
+			sum.BranchID = Unassigned
+		}
+		if sum.Jobs == nil {
+			sum.Jobs = map[string]int{}
+		}
+		for _, route := range s.routes(members, serviceDate) {
+			sum.Routes++
+			sum.Stops += len(route.CustomerStops)
+			sum.Alerts += len(route.Alerts)
+		}
+		inv := s.inventory(b.ID, members, chemicals)
+		sum.InventoryItems = len(inv.Items)
+		for _, item := range inv.Items {
+			if item.Expired {
+				sum.ExpiredChemicals++
+			}
+		}
+		out = append(out, sum)
+	}
+	return out, nil
+}
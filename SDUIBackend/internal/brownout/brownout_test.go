@@ -0,0 +1,139 @@
+package brownout
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func testConfig() config.BrownoutConfig {
+	return config.BrownoutConfig{
+		Enabled:           true,
+		P99Threshold:      100 * time.Millisecond,
+		RecoveryThreshold: 50 * time.Millisecond,
+		Window:            time.Minute,
+		MaxSamples:        10,
+		MinSamples:        5,
+		DeferredQueueSize: 2,
+	}
+}
+
+func TestMonitorTripsAndRecovers(t *testing.T) {
+	now := time.Now()
+	m := NewMonitor(testConfig())
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		m.Observe(10 * time.Millisecond)
+	}
+	if m.Degraded() {
+		t.Fatalf("expected healthy with fast samples")
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Observe(200 * time.Millisecond)
+	}
+	if !m.Degraded() {
+		t.Fatalf("expected brownout after slow samples")
+	}
+
+	// Between recovery and trip thresholds: stays degraded (hysteresis).
+	for i := 0; i < 10; i++ {
+		m.Observe(75 * time.Millisecond)
+	}
+	if !m.Degraded() {
+		t.Fatalf("expected to remain degraded above recovery threshold")
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Observe(10 * time.Millisecond)
+	}
+	if m.Degraded() {
+		t.Fatalf("expected recovery once p99 drops")
+	}
+	if got := m.Status().Trips; got != 1 {
+		t.Errorf("expected 1 trip, got %d", got)
+	}
+}
+
+func TestMonitorRecoversWhenSamplesExpire(t *testing.T) {
+	now := time.Now()
+	m := NewMonitor(testConfig())
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		m.Observe(time.Second)
+	}
+	if !m.Degraded() {
+		t.Fatalf("expected brownout")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if m.Degraded() {
+		t.Fatalf("expected recovery after samples aged out of the window")
+	}
+}
+
+func TestMonitorDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Enabled = false
+	m := NewMonitor(cfg)
+	for i := 0; i < 10; i++ {
+		m.Observe(time.Second)
+	}
+	if m.Degraded() {
+		t.Fatalf("disabled monitor must never degrade")
+	}
+}
+
+func TestDeferredWritesFlush(t *testing.T) {
+	now := time.Now()
+	m := NewMonitor(testConfig())
+	m.now = func() time.Time { return now }
+	for i := 0; i < 10; i++ {
+		m.Observe(time.Second)
+	}
+
+	q := NewDeferredWrites(m, 2, nil)
+	if !q.Active() {
+		t.Fatalf("expected deferral active during brownout")
+	}
+
+	var applied int
+	write := func() error { applied++; return nil }
+	if err := q.Defer("a", write); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Defer("b", write); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Defer("c", write); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	if n := q.Flush(); n != 0 {
+		t.Fatalf("expected no writes flushed while degraded, got %d", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if n := q.Flush(); n != 2 || applied != 2 {
+		t.Fatalf("expected 2 writes applied, got %d (%d)", n, applied)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expected empty queue, got %d", q.Len())
+	}
+}
+
+func TestDeferredWritesDropAfterRepeatedFailure(t *testing.T) {
+	q := NewDeferredWrites(NewMonitor(testConfig()), 10, nil)
+	_ = q.Defer("failing", func() error { return errors.New("boom") })
+
+	for i := 0; i < maxFlushAttempts; i++ {
+		q.Flush()
+	}
+	if q.Len() != 0 || q.Dropped() != 1 {
+		t.Fatalf("expected write dropped, pending=%d dropped=%d", q.Len(), q.Dropped())
+	}
+}
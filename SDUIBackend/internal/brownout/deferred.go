@@ -0,0 +1,142 @@
+package brownout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"log/slog"
+)
+
+// ErrQueueFull is returned when the deferred write buffer is at capacity and
+// the caller should fall back to writing synchronously.
+var ErrQueueFull = errors.New("deferred write queue full")
+
+// maxFlushAttempts bounds how often a deferred write is replayed before it is
+// dropped and logged.
+const maxFlushAttempts = 5
+
+// DeferredWrites buffers non-critical writes while the monitor reports
+// brownout and replays them once the datastore recovers.
+type DeferredWrites struct {
+	monitor *Monitor
+	max     int
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	pending []deferredWrite
+	dropped int
+}
+
+type deferredWrite struct {
+	name     string
+	fn       func() error
+	queuedAt time.Time
+	attempts int
+}
+
+// NewDeferredWrites creates a bounded deferred write queue.
+func NewDeferredWrites(monitor *Monitor, max int, logger *slog.Logger) *DeferredWrites {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DeferredWrites{monitor: monitor, max: max, logger: logger}
+}
+
+// Active reports whether callers should defer non-critical writes right now.
+func (q *DeferredWrites) Active() bool {
+	return q != nil && q.monitor.Degraded()
+}
+
+// Defer queues fn for later execution.
+func (q *DeferredWrites) Defer(name string, fn func() error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= q.max {
+		return ErrQueueFull
+	}
+	q.pending = append(q.pending, deferredWrite{name: name, fn: fn, queuedAt: time.Now()})
+	return nil
+}
+
+// Len returns the number of queued writes.
+func (q *DeferredWrites) Len() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Dropped returns how many writes were abandoned after repeated failures.
+func (q *DeferredWrites) Dropped() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Flush replays queued writes while the datastore is healthy. Writes that fail
+// are requeued until they exhaust maxFlushAttempts. It returns the number of
+// writes applied.
+func (q *DeferredWrites) Flush() int {
+	q.mu.Lock()
+	batch := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	applied := 0
+	var retry []deferredWrite
+	for i, w := range batch {
+		if q.monitor.Degraded() {
+			retry = append(retry, batch[i:]...)
+			break
+		}
+		if err := w.fn(); err != nil {
+			w.attempts++
+			if w.attempts >= maxFlushAttempts {
+				q.logger.Error("dropping deferred write", slog.String("write", w.name), slog.Time("queuedAt", w.queuedAt), slog.Any("error", err))
+				q.mu.Lock()
+				q.dropped++
+				q.mu.Unlock()
+				continue
+			}
+			retry = append(retry, w)
+			continue
+		}
+		applied++
+	}
+
+	if len(retry) > 0 {
+		q.mu.Lock()
+		q.pending = append(retry, q.pending...)
+		q.mu.Unlock()
+	}
+	return applied
+}
+
+// Run flushes the queue on every tick until ctx is cancelled.
+func (q *DeferredWrites) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if q.Len() == 0 || q.monitor.Degraded() {
+				continue
+			}
+			if n := q.Flush(); n > 0 {
+				q.logger.Info("flushed deferred writes", slog.Int("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package brownout
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Monitor tracks datastore call latency over a sliding window and reports
+// whether the service should degrade. It enters brownout once the window p99
+// exceeds the configured threshold and only recovers once p99 falls below the
+// recovery threshold, so a single slow call cannot make it flap.
+type Monitor struct {
+	cfg config.BrownoutConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	samples  []sample
+	next     int
+	count    int
+	p99      time.Duration
+	degraded bool
+	since    time.Time
+	trips    int
+}
+
+type sample struct {
+	at      time.Time
+	latency time.Duration
+}
+
+// Status is a point-in-time view of the monitor used by /readyz and /metrics.
+type Status struct {
+	Enabled           bool       `json:"enabled"`
+	Degraded          bool       `json:"degraded"`
+	Since             *time.Time `json:"since,omitempty"`
+	P99               string     `json:"p99"`
+	P99Threshold      string     `json:"p99Threshold"`
+	RecoveryThreshold string     `json:"recoveryThreshold"`
+	Samples           int        `json:"samples"`
+	Trips             int        `json:"trips"`
+}
+
+// NewMonitor creates a monitor from configuration.
+func NewMonitor(cfg config.BrownoutConfig) *Monitor {
+	size := cfg.MaxSamples
+	if size <= 0 {
+		size = 500
+	}
+	return &Monitor{cfg: cfg, now: time.Now, samples: make([]sample, size)}
+}
+
+// Observe records the latency of a single datastore call.
+func (m *Monitor) Observe(latency time.Duration) {
+	if m == nil || !m.cfg.Enabled {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.samples[m.next] = sample{at: now, latency: latency}
+	m.next = (m.next + 1) % len(m.samples)
+	if m.count < len(m.samples) {
+		m.count++
+	}
+	m.evaluate(now)
+}
+
+// Degraded reports whether the service is currently in brownout.
+func (m *Monitor) Degraded() bool {
+	if m == nil || !m.cfg.Enabled {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evaluate(m.now())
+	return m.degraded
+}
+
+// Status returns the current monitor state.
+func (m *Monitor) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cfg.Enabled {
+		m.evaluate(m.now())
+	}
+
+	status := Status{
+		Enabled:           m.cfg.Enabled,
+		Degraded:          m.degraded,
+		P99:               m.p99.String(),
+		P99Threshold:      m.cfg.P99Threshold.String(),
+		RecoveryThreshold: m.cfg.RecoveryThreshold.String(),
+		Samples:           len(m.recent(m.now())),
+		Trips:             m.trips,
+	}
+	if m.degraded {
+		since := m.since
+		status.Since = &since
+	}
+	return status
+}
+
+// track is deferred by instrumented repositories with the call start time.
+func (m *Monitor) track(start time.Time) {
+	m.Observe(time.Since(start))
+}
+
+// evaluate recomputes p99 and flips state. Callers must hold m.mu.
+func (m *Monitor) evaluate(now time.Time) {
+	latencies := m.recent(now)
+	if len(latencies) < m.cfg.MinSamples {
+		// Not enough recent traffic to justify staying degraded; let requests
+		// through again so fresh samples can decide.
+		m.p99 = 0
+		if m.degraded {
+			m.degraded = false
+			m.since = now
+		}
+		return
+	}
+
+	m.p99 = percentile(latencies, 0.99)
+	switch {
+	case !m.degraded && m.p99 > m.cfg.P99Threshold:
+		m.degraded = true
+		m.since = now
+		m.trips++
+	case m.degraded && m.p99 <= m.cfg.RecoveryThreshold:
+		m.degraded = false
+		m.since = now
+	}
+}
+
+// recent returns latencies observed within the configured window.
+func (m *Monitor) recent(now time.Time) []time.Duration {
+	cutoff := now.Add(-m.cfg.Window)
+	out := make([]time.Duration, 0, m.count)
+	for _, s := range m.samples[:m.count] {
+		if m.cfg.Window <= 0 || !s.at.Before(cutoff) {
+			out = append(out, s.latency)
+		}
+	}
+	return out
+}
+
+func percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	idx := int(float64(len(values))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(values) {
+		idx = len(values) - 1
+	}
+	return values[idx]
+}
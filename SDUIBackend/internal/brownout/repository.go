@@ -0,0 +1,265 @@
+package brownout
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Instrument wraps every repository so call latency feeds the monitor. Nil
+// repositories are left nil so Repository.Validate still reports them.
+func Instrument(repos repository.Repository, m *Monitor) repository.Repository {
+	out := repos
+	if repos.Technicians != nil {
+		out.Technicians = technicians{base: repos.Technicians, m: m}
+	}
+	if repos.Routes != nil {
+		out.Routes = routes{base: repos.Routes, m: m}
+	}
+	if repos.Screens != nil {
+		out.Screens = screens{base: repos.Screens, m: m}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{base: repos.Sync, m: m}
+	}
+	if repos.Devices != nil {
+		out.Devices = devices{base: repos.Devices, m: m}
+	}
+	return out
+}
+
+type technicians struct {
+	base repository.TechnicianRepository
+	m    *Monitor
+}
+
+func (t technicians) GetByID(id string) (models.Technician, error) {
+	defer t.m.track(time.Now())
+	return t.base.GetByID(id)
+}
+
+func (t technicians) SaveTechnician(tech models.Technician) error {
+	defer t.m.track(time.Now())
+	return t.base.SaveTechnician(tech)
+}
+
+func (t technicians) ListTechnicians() ([]models.Technician, error) {
+	defer t.m.track(time.Now())
+	return t.base.ListTechnicians()
+}
+
+type routes struct {
+	base repository.RouteRepository
+	m    *Monitor
+}
+
+func (r routes) GetRoute(technicianID string, serviceDate time.Time) (models.Route, error) {
+	defer r.m.track(time.Now())
+	return r.base.GetRoute(technicianID, serviceDate)
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	defer r.m.track(time.Now())
+	return r.base.SaveRoute(route)
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	defer r.m.track(time.Now())
+	return r.base.DeleteRoute(technicianID, serviceDate)
+}
+
+type screens struct {
+	base repository.ScreenRepository
+	m    *Monitor
+}
+
+func (s screens) GetTemplate(id string, version int) (models.ScreenTemplate, error) {
+	defer s.m.track(time.Now())
+	return s.base.GetTemplate(id, version)
+}
+
+func (s screens) SaveTemplate(template models.ScreenTemplate) error {
+	defer s.m.track(time.Now())
+	return s.base.SaveTemplate(template)
+}
+
+func (s screens) ListTemplates() ([]models.ScreenTemplate, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListTemplates()
+}
+
+func (s screens) DeleteTemplate(id string, version int) error {
+	defer s.m.track(time.Now())
+	return s.base.DeleteTemplate(id, version)
+}
+
+type syncRepo struct {
+	base repository.SyncRepository
+	m    *Monitor
+}
+
+func (s syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	defer s.m.track(time.Now())
+	return s.base.SaveJobUpload(upload)
+}
+
+func (s syncRepo) GetJobUpload(id string) (models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.GetJobUpload(id)
+}
+
+func (s syncRepo) SaveChemicalUpload(upload models.ChemicalUpload) error {
+	defer s.m.track(time.Now())
+	return s.base.SaveChemicalUpload(upload)
+}
+
+func (s syncRepo) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	defer s.m.track(time.Now())
+	return s.base.SaveChemicalTreatment(upload)
+}
+
+func (s syncRepo) ListPendingJobs(limit int) ([]models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListPendingJobs(limit)
+}
+
+func (s syncRepo) ListPendingChemicals(limit int) ([]models.ChemicalUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListPendingChemicals(limit)
+}
+
+func (s syncRepo) ListAllJobs() ([]models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListAllJobs()
+}
+
+func (s syncRepo) ListAllChemicals() ([]models.ChemicalUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListAllChemicals()
+}
+
+func (s syncRepo) ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListPendingTreatments(limit)
+}
+
+func (s syncRepo) ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListJobUpdatesSince(since)
+}
+
+func (s syncRepo) ListRouteUpdatesSince(since time.Time) ([]models.Route, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListRouteUpdatesSince(since)
+}
+
+func (s syncRepo) ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListChemicalUpdatesSince(since)
+}
+
+func (s syncRepo) ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListTreatmentUpdatesSince(since)
+}
+
+func (s syncRepo) ListJobUpdates(page repository.Page) ([]models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListJobUpdates(page)
+}
+
+func (s syncRepo) ListRouteUpdates(page repository.Page) ([]models.Route, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListRouteUpdates(page)
+}
+
+func (s syncRepo) ListChemicalUpdates(page repository.Page) ([]models.ChemicalUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListChemicalUpdates(page)
+}
+
+func (s syncRepo) ListTreatmentUpdates(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListTreatmentUpdates(page)
+}
+
+func (s syncRepo) ListUnprocessedJobs(page repository.Page) ([]models.JobUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListUnprocessedJobs(page)
+}
+
+func (s syncRepo) ListUnprocessedChemicals(page repository.Page) ([]models.ChemicalUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListUnprocessedChemicals(page)
+}
+
+func (s syncRepo) ListUnprocessedTreatments(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListUnprocessedTreatments(page)
+}
+
+func (s syncRepo) ListUnprocessedRoutes(page repository.Page) ([]models.Route, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListUnprocessedRoutes(page)
+}
+
+func (s syncRepo) MarkProcessed(kind, id string, savedAt time.Time) error {
+	defer s.m.track(time.Now())
+	return s.base.MarkProcessed(kind, id, savedAt)
+}
+
+func (s syncRepo) DeleteJob(id string) error {
+	defer s.m.track(time.Now())
+	return s.base.DeleteJob(id)
+}
+
+func (s syncRepo) DeleteChemical(id string) error {
+	defer s.m.track(time.Now())
+	return s.base.DeleteChemical(id)
+}
+
+func (s syncRepo) ListDeletionsSince(since time.Time) ([]models.Tombstone, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListDeletionsSince(since)
+}
+
+func (s syncRepo) ListDeletions(page repository.Page) ([]models.Tombstone, error) {
+	defer s.m.track(time.Now())
+	return s.base.ListDeletions(page)
+}
+
+func (s syncRepo) PruneTombstones(cutoff time.Time) (int, error) {
+	defer s.m.track(time.Now())
+	return s.base.PruneTombstones(cutoff)
+}
+
+func (s syncRepo) SaveBatch(batch repository.Batch) error {
+	defer s.m.track(time.Now())
+	return s.base.SaveBatch(batch)
+}
+
+type devices struct {
+	base repository.DeviceRepository
+	m    *Monitor
+}
+
+func (d devices) SaveDeviceToken(token models.DeviceToken) error {
+	defer d.m.track(time.Now())
+	return d.base.SaveDeviceToken(token)
+}
+
+func (d devices) ListDeviceTokens(technicianID string) ([]models.DeviceToken, error) {
+	defer d.m.track(time.Now())
+	return d.base.ListDeviceTokens(technicianID)
+}
+
+func (d devices) DeleteDeviceToken(token string) error {
+	defer d.m.track(time.Now())
+	return d.base.DeleteDeviceToken(token)
+}
+
+func (d devices) PruneDeviceTokens(cutoff time.Time) (int, error) {
+	defer d.m.track(time.Now())
+	return d.base.PruneDeviceTokens(cutoff)
+}
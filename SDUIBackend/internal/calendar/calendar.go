@@ -0,0 +1,164 @@
+// Package calendar holds per-branch business hours and holiday calendars,
+// and answers business-time questions against them for scheduling, SLA due
+// times, customer notification timing, and plan recurrence.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBranch is the branch whose calendar applies to branches without one
+// of their own. When it is not stored, the configured calendar is used.
+const DefaultBranch = "default"
+
+var (
+	// ErrNotFound is returned when a calendar does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidCalendar wraps calendar validation failures.
+	ErrInvalidCalendar = errors.New("invalid calendar")
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Calendar is a branch's weekly hours and holidays. BranchID is a branch ID,
+// or a technician region for technicians not yet assigned to a branch.
+type Calendar struct {
+	BranchID  string     `json:"branchId"`
+	TimeZone  string     `json:"timeZone"`
+	Hours     []DayHours `json:"hours"` // days not listed are closed
+	Holidays  []Holiday  `json:"holidays,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// DayHours are the opening hours on one weekday.
+type DayHours struct {
+	Day   string `json:"day"`   // sun, mon, ... sat
+	Open  string `json:"open"`  // HH:MM
+	Close string `json:"close"` // HH:MM
+}
+
+// Holiday closes the branch on a date, or shortens its hours when Open and
+// Close are set.
+type Holiday struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Name  string `json:"name"`
+	Open  string `json:"open,omitempty"`
+	Close string `json:"close,omitempty"`
+}
+
+// Validate checks a calendar.
+func (c Calendar) Validate() error {
+	var problems []string
+	if strings.TrimSpace(c.BranchID) == "" {
+		problems = append(problems, "branchId is required")
+	}
+	if _, err := time.LoadLocation(c.TimeZone); err != nil || c.TimeZone == "" {
+		problems = append(problems, fmt.Sprintf("timeZone %q is not a known IANA zone", c.TimeZone))
+	}
+	if len(c.Hours) == 0 {
+		problems = append(problems, "at least one day of hours is required")
+	}
+	seenDays := make(map[string]bool)
+	for i, h := range c.Hours {
+		prefix := fmt.Sprintf("hours[%d]", i)
+		if _, ok := weekdays[h.Day]; !ok {
+			problems = append(problems, prefix+".day must be one of sun, mon, tue, wed, thu, fri, sat")
+		} else if seenDays[h.Day] {
+			problems = append(problems, prefix+".day "+h.Day+" is listed twice")
+		}
+		seenDays[h.Day] = true
+		if _, err := parseSpan(h.Open, h.Close); err != nil {
+			problems = append(problems, prefix+": "+err.Error())
+		}
+	}
+	seenDates := make(map[string]bool)
+	for i, h := range c.Holidays {
+		prefix := fmt.Sprintf("holidays[%d]", i)
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			problems = append(problems, prefix+".date must be YYYY-MM-DD")
+		} else if seenDates[h.Date] {
+			problems = append(problems, prefix+".date "+h.Date+" is listed twice")
+		}
+		seenDates[h.Date] = true
+		if strings.TrimSpace(h.Name) == "" {
+			problems = append(problems, prefix+".name is required")
+		}
+		if h.Open != "" || h.Close != "" {
+			if _, err := parseSpan(h.Open, h.Close); err != nil {
+				problems = append(problems, prefix+": "+err.Error())
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidCalendar, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Store persists calendars.
+type Store interface {
+	SaveCalendar(c Calendar) error
+	GetCalendar(branchID string) (Calendar, error)
+	ListCalendars() ([]Calendar, error)
+	DeleteCalendar(branchID string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	calendars map[string]Calendar
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{calendars: make(map[string]Calendar)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveCalendar(c Calendar) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calendars[c.BranchID] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCalendar(branchID string) (Calendar, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.calendars[branchID]
+	if !ok {
+		return Calendar{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) ListCalendars() ([]Calendar, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Calendar, 0, len(m.calendars))
+	for _, c := range m.calendars {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].BranchID < out[j].BranchID })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteCalendar(branchID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.calendars[branchID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.calendars, branchID)
+	return nil
+}
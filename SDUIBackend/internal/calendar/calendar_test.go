@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func weekdayHours(open, close string) []DayHours {
+	var out []DayHours
+	for _, day := range []string{"mon", "tue", "wed", "thu", "fri"} {
+		out = append(out, DayHours{Day: day, Open: open, Close: close})
+	}
+	return out
+}
+
+func newTestService(t *testing.T) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.CalendarConfig{TimeZone: "UTC", Open: "09:00", Close: "17:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}}
+	svc, err := NewService(cfg, NewMemoryStore(), repos, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc, store
+}
+
+func TestValidate(t *testing.T) {
+	bad := Calendar{
+		BranchID: "north",
+		TimeZone: "Mars/Olympus",
+		Hours:    []DayHours{{Day: "mon", Open: "17:00", Close: "08:00"}, {Day: "mon", Open: "08:00", Close: "17:00"}, {Day: "funday", Open: "08:00", Close: "17:00"}},
+		Holidays: []Holiday{{Date: "12/25/2026", Name: "Christmas"}, {Date: "2026-07-03", Open: "08:00"}},
+	}
+	err := bad.Validate()
+	if !errors.Is(err, ErrInvalidCalendar) {
+		t.Fatalf("expected invalid calendar, got %v", err)
+	}
+	for _, want := range []string{"timeZone", "hours[0]: close must be after open", "mon is listed twice", "hours[2].day", "holidays[0].date", "holidays[1].name", "holidays[1]: close must be HH:MM"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestBusinessHoursInBranchTimeZone(t *testing.T) {
+	c := Calendar{
+		BranchID: "east",
+		TimeZone: "America/New_York",
+		Hours:    weekdayHours("08:00", "17:00"),
+		Holidays: []Holiday{
+			{Date: "2026-07-03", Name: "Independence Day (observed)"},
+			{Date: "2026-12-24", Name: "Christmas Eve", Open: "08:00", Close: "12:00"},
+		},
+	}
+	h, err := c.BusinessHours()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, ny)
+	}
+
+	// Thursday 2 July 2026: 8:00-17:00 Eastern is 12:00-21:00 UTC.
+	opens, closes, ok := h.Window(time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC))
+	if !ok || !opens.Equal(local(7, 2, 8, 0)) || !closes.Equal(local(7, 2, 17, 0)) {
+		t.Fatalf("unexpected window %s-%s (%v)", opens, closes, ok)
+	}
+	if !h.IsOpen(time.Date(2026, 7, 2, 20, 0, 0, 0, time.UTC)) || h.IsOpen(time.Date(2026, 7, 2, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected open checks in the branch's zone")
+	}
+
+	// Closing time Thursday rolls over the Friday holiday and the weekend.
+	if got := h.NextOpen(local(7, 2, 17, 0)); !got.Equal(local(7, 6, 8, 0)) {
+		t.Errorf("unexpected next opening %s", got)
+	}
+	// A 4h SLA raised at 15:00 Thursday uses 2h Thursday and 2h Monday.
+	if got := h.Add(local(7, 2, 15, 0), 4*time.Hour); !got.Equal(local(7, 6, 10, 0)) {
+		t.Errorf("unexpected due time %s", got)
+	}
+	if got := h.NextBusinessDay(time.Date(2026, 7, 3, 0, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next business day %s", got)
+	}
+
+	eve := h.Day(time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC))
+	if !eve.Open || eve.Holiday != "Christmas Eve" || !eve.Closes.Equal(local(12, 24, 12, 0)) {
+		t.Errorf("expected shortened holiday hours, got %+v", eve)
+	}
+}
+
+func TestForFallsBackToDefaultCalendars(t *testing.T) {
+	svc, store := newTestService(t)
+	store.AddTechnician(models.Technician{ID: "tech-1", Region: "north"})
+	monday := time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC)
+
+	if opens, _, _ := svc.ForTechnician("tech-1").Window(monday); opens.Hour() != 9 {
+		t.Fatalf("expected configured hours, got %s", opens)
+	}
+	if _, err := svc.Save(DefaultBranch, Calendar{TimeZone: "UTC", Hours: weekdayHours("07:00", "15:00")}); err != nil {
+		t.Fatal(err)
+	}
+	if opens, _, _ := svc.ForTechnician("tech-1").Window(monday); opens.Hour() != 7 {
+		t.Fatalf("expected stored default hours, got %s", opens)
+	}
+	if _, err := svc.Save("north", Calendar{TimeZone: "UTC", Hours: []DayHours{{Day: "sat", Open: "10:00", Close: "14:00"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if h := svc.ForTechnician("tech-1"); h.BranchID() != "north" || h.Day(monday).Open {
+		t.Fatalf("expected the branch's own calendar, got %+v", h.Day(monday))
+	}
+
+	if err := svc.Delete("north"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete("north"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	var none *Service
+	if none.For("north") != nil {
+		t.Fatalf("a nil service has no calendar")
+	}
+}
@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes branch calendar management and business-time lookups.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a calendar handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListCalendars)
+	r.Get("/{branchId}", h.GetCalendar)
+	r.Put("/{branchId}", h.SaveCalendar)
+	r.Delete("/{branchId}", h.DeleteCalendar)
+	r.Get("/{branchId}/days/{date}", h.GetDay)
+	r.Get("/{branchId}/due", h.GetDue)
+}
+
+// ListCalendars returns the stored branch calendars.
+func (h *Handler) ListCalendars(w http.ResponseWriter, r *http.Request) {
+	calendars, err := h.service.Calendars()
+	if err != nil {
+		h.fail(w, r, "failed to list calendars", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"calendars": calendars})
+}
+
+// GetCalendar returns a branch's own calendar.
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Calendar(chi.URLParam(r, "branchId"))
+	if err != nil {
+		h.fail(w, r, "failed to load calendar", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// SaveCalendar creates or replaces a branch's calendar.
+func (h *Handler) SaveCalendar(w http.ResponseWriter, r *http.Request) {
+	var payload Calendar
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	c, err := h.service.Save(chi.URLParam(r, "branchId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save calendar", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// DeleteCalendar reverts a branch to the default calendar.
+func (h *Handler) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(chi.URLParam(r, "branchId")); err != nil {
+		h.fail(w, r, "failed to delete calendar", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDay returns the hours that apply to a branch on a date.
+func (h *Handler) GetDay(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid date", "expected YYYY-MM-DD")
+		return
+	}
+	respond.JSON(w, http.StatusOK, h.service.For(chi.URLParam(r, "branchId")).Day(date))
+}
+
+// GetDue returns when ?within= business time after ?from= (RFC 3339, default
+// now) falls at a branch, e.g. an SLA due time.
+func (h *Handler) GetDue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	within, err := time.ParseDuration(q.Get("within"))
+	if err != nil || within < 0 {
+		respond.Error(w, http.StatusBadRequest, "invalid within", "expected a non-negative duration such as 4h")
+		return
+	}
+	from := h.service.now().UTC()
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid from", "expected RFC 3339")
+			return
+		}
+	}
+	branchID := chi.URLParam(r, "branchId")
+	respond.JSON(w, http.StatusOK, map[string]any{
+		"branchId": branchID,
+		"from":     from,
+		"within":   within.String(),
+		"due":      h.service.Due(branchID, from, within),
+	})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidCalendar):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
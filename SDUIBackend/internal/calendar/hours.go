@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// maxSearchDays bounds searches for the next open day. A valid calendar is
+// open at least one weekday, so only holidays can push a search this far.
+const maxSearchDays = 400
+
+// span is a range of minutes after local midnight.
+type span struct{ open, close int }
+
+func parseSpan(open, close string) (span, error) {
+	o, err := time.Parse("15:04", open)
+	if err != nil {
+		return span{}, fmt.Errorf("open must be HH:MM")
+	}
+	c, err := time.Parse("15:04", close)
+	if err != nil {
+		return span{}, fmt.Errorf("close must be HH:MM")
+	}
+	s := span{open: o.Hour()*60 + o.Minute(), close: c.Hour()*60 + c.Minute()}
+	if s.close <= s.open {
+		return span{}, fmt.Errorf("close must be after open")
+	}
+	return s, nil
+}
+
+type holiday struct {
+	name  string
+	hours *span // nil when closed all day
+}
+
+// BusinessHours answers business-time questions for one calendar. Dates
+// (time values standing for a day, like route service dates) are taken by
+// their year, month, and day as given; instants are converted to the
+// calendar's time zone.
+type BusinessHours struct {
+	branchID string
+	loc      *time.Location
+	week     [7]*span
+	holidays map[string]holiday
+}
+
+// BusinessHours compiles the calendar.
+func (c Calendar) BusinessHours() (*BusinessHours, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	loc, _ := time.LoadLocation(c.TimeZone)
+	h := &BusinessHours{branchID: c.BranchID, loc: loc, holidays: make(map[string]holiday, len(c.Holidays))}
+	for _, d := range c.Hours {
+		s, _ := parseSpan(d.Open, d.Close)
+		h.week[weekdays[d.Day]] = &s
+	}
+	for _, d := range c.Holidays {
+		entry := holiday{name: d.Name}
+		if d.Open != "" {
+			s, _ := parseSpan(d.Open, d.Close)
+			entry.hours = &s
+		}
+		h.holidays[d.Date] = entry
+	}
+	return h, nil
+}
+
+// fromConfig is the calendar configured for branches without their own.
+func fromConfig(cfg config.CalendarConfig) Calendar {
+	c := Calendar{BranchID: DefaultBranch, TimeZone: cfg.TimeZone}
+	for _, day := range cfg.Days {
+		c.Hours = append(c.Hours, DayHours{Day: day, Open: cfg.Open, Close: cfg.Close})
+	}
+	return c
+}
+
+// BranchID is the branch the hours belong to.
+func (h *BusinessHours) BranchID() string { return h.branchID }
+
+// Location is the time zone the hours are in.
+func (h *BusinessHours) Location() *time.Location { return h.loc }
+
+// Day describes a date's business hours.
+type Day struct {
+	BranchID string     `json:"branchId"`
+	Date     string     `json:"date"`
+	Open     bool       `json:"open"`
+	Opens    *time.Time `json:"opens,omitempty"`
+	Closes   *time.Time `json:"closes,omitempty"`
+	Holiday  string     `json:"holiday,omitempty"`
+}
+
+// Day describes the business hours on date.
+func (h *BusinessHours) Day(date time.Time) Day {
+	out := Day{BranchID: h.branchID, Date: date.Format("2006-01-02")}
+	if hol, ok := h.holidays[out.Date]; ok {
+		out.Holiday = hol.name
+	}
+	if opens, closes, ok := h.Window(date); ok {
+		out.Open, out.Opens, out.Closes = true, &opens, &closes
+	}
+	return out
+}
+
+// Window returns when the branch opens and closes on date, or false when it
+// is closed all day.
+func (h *BusinessHours) Window(date time.Time) (opens, closes time.Time, ok bool) {
+	s := h.week[date.Weekday()]
+	if hol, isHoliday := h.holidays[date.Format("2006-01-02")]; isHoliday {
+		s = hol.hours
+	}
+	if s == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, s.open, 0, 0, h.loc), time.Date(y, m, d, 0, s.close, 0, 0, h.loc), true
+}
+
+// IsOpen reports whether the branch is open at t.
+func (h *BusinessHours) IsOpen(t time.Time) bool {
+	opens, closes, ok := h.Window(h.dateOf(t))
+	return ok && !t.Before(opens) && t.Before(closes)
+}
+
+// NextOpen returns t when the branch is open, otherwise when it next opens.
+func (h *BusinessHours) NextOpen(t time.Time) time.Time {
+	date := h.dateOf(t)
+	for i := 0; i < maxSearchDays; i++ {
+		if opens, closes, ok := h.Window(date.AddDate(0, 0, i)); ok && t.Before(closes) {
+			if t.Before(opens) {
+				return opens
+			}
+			return t
+		}
+	}
+	return t
+}
+
+// Add returns the time d of business hours after start, e.g. an SLA due time.
+func (h *BusinessHours) Add(start time.Time, d time.Duration) time.Time {
+	t := start
+	for i := 0; d > 0 && i < maxSearchDays; i++ {
+		t = h.NextOpen(t)
+		_, closes, ok := h.Window(h.dateOf(t))
+		if !ok {
+			break
+		}
+		if left := closes.Sub(t); d > left {
+			d -= left
+			t = closes
+			continue
+		}
+		return t.Add(d)
+	}
+	return t
+}
+
+// NextBusinessDay returns date when the branch opens that day, otherwise the
+// next date it does. The result is midnight UTC, like route service dates.
+func (h *BusinessHours) NextBusinessDay(date time.Time) time.Time {
+	y, m, d := date.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxSearchDays; i++ {
+		if _, _, ok := h.Window(day.AddDate(0, 0, i)); ok {
+			return day.AddDate(0, 0, i)
+		}
+	}
+	return day
+}
+
+// dateOf is the local calendar date of an instant.
+func (h *BusinessHours) dateOf(t time.Time) time.Time {
+	y, m, d := t.In(h.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Service manages branch calendars and resolves the hours that apply to a
+// branch or technician. Lookups fall back from the branch's own calendar to
+// the stored default branch and then to the configured calendar.
+type Service struct {
+	fallback *BusinessHours
+	store    Store
+	repos    repository.Repository
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService wires a calendar service. It fails when the configured calendar
+// is invalid.
+func NewService(cfg config.CalendarConfig, store Store, repos repository.Repository, logger *slog.Logger) (*Service, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	fallback, err := fromConfig(cfg).BusinessHours()
+	if err != nil {
+		return nil, fmt.Errorf("configured business hours: %w", err)
+	}
+	return &Service{fallback: fallback, store: store, repos: repos, logger: logger, now: time.Now}, nil
+}
+
+// Save creates or replaces the calendar for a branch.
+func (s *Service) Save(branchID string, c Calendar) (Calendar, error) {
+	c.BranchID = strings.TrimSpace(branchID)
+	if err := c.Validate(); err != nil {
+		return Calendar{}, err
+	}
+	c.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveCalendar(c); err != nil {
+		return Calendar{}, err
+	}
+	s.logger.Info("branch calendar saved", slog.String("branch", c.BranchID), slog.Int("holidays", len(c.Holidays)))
+	return c, nil
+}
+
+// Calendar returns a branch's own calendar.
+func (s *Service) Calendar(branchID string) (Calendar, error) {
+	return s.store.GetCalendar(branchID)
+}
+
+// Calendars lists the stored calendars.
+func (s *Service) Calendars() ([]Calendar, error) {
+	return s.store.ListCalendars()
+}
+
+// Delete removes a branch's calendar, reverting it to the default.
+func (s *Service) Delete(branchID string) error {
+	return s.store.DeleteCalendar(branchID)
+}
+
+// For returns the hours that apply to a branch. A nil service returns nil,
+// which callers treat as "no calendar".
+func (s *Service) For(branchID string) *BusinessHours {
+	if s == nil {
+		return nil
+	}
+	for _, id := range []string{branchID, DefaultBranch} {
+		if id == "" {
+			continue
+		}
+		c, err := s.store.GetCalendar(id)
+		if err == nil {
+			var h *BusinessHours
+			if h, err = c.BusinessHours(); err == nil {
+				return h
+			}
+		}
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Warn("branch calendar unavailable", slog.String("branch", id), slog.Any("error", err))
+		}
+	}
+	return s.fallback
+}
+
+// ForTechnician returns the hours of the technician's branch, falling back
+// to their legacy region for technicians not yet assigned to a branch.
+func (s *Service) ForTechnician(technicianID string) *BusinessHours {
+	if s == nil {
+		return nil
+	}
+	tech, err := s.repos.Technicians.GetByID(technicianID)
+	if err != nil {
+		return s.For("")
+	}
+	if tech.BranchID != "" {
+		return s.For(tech.BranchID)
+	}
+	return s.For(tech.Region)
+}
+
+// Due returns the time within of business hours after from at a branch.
+func (s *Service) Due(branchID string, from time.Time, within time.Duration) time.Time {
+	return s.For(branchID).Add(from, within)
+}
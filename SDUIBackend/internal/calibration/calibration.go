@@ -0,0 +1,184 @@
+// Package calibration tracks application equipment (sprayers and similar
+// assets) and their calibration events. An asset's calibration lapses a set
+// interval after it was last calibrated; treatments by methods that depend
+// on a calibrated output rate are refused on lapsed equipment, and other
+// treatments on it carry a warning.
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Calibration states.
+const (
+	StateCurrent = "current"
+	StateDueSoon = "due_soon"
+	StateOverdue = "overdue"
+	StateNever   = "never" // no calibration recorded
+)
+
+var (
+	// ErrNotFound is returned when an asset does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidAsset wraps asset validation failures.
+	ErrInvalidAsset = errors.New("invalid asset")
+	// ErrInvalidCalibration wraps calibration validation failures.
+	ErrInvalidCalibration = errors.New("invalid calibration")
+	// ErrCalibrationOverdue is returned when a treatment method requires
+	// calibrated equipment and the equipment's calibration has lapsed.
+	ErrCalibrationOverdue = errors.New("calibration overdue")
+)
+
+// Asset is a piece of application equipment.
+type Asset struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Kind         string `json:"kind,omitempty"` // e.g. backpack sprayer, power sprayer
+	SerialNumber string `json:"serialNumber,omitempty"`
+	TechnicianID string `json:"technicianId,omitempty"` // assigned technician
+	// IntervalDays overrides the configured calibration interval.
+	IntervalDays int       `json:"intervalDays,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Validate checks an asset.
+func (a Asset) Validate() error {
+	var problems []string
+	if strings.TrimSpace(a.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if a.IntervalDays < 0 {
+		problems = append(problems, "intervalDays must be >= 0")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidAsset, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Calibration is one calibration of an asset.
+type Calibration struct {
+	ID           string    `json:"id"`
+	AssetID      string    `json:"assetId"`
+	TechnicianID string    `json:"technicianId"`
+	CalibratedAt time.Time `json:"calibratedAt"`
+	OutputRate   float64   `json:"outputRate"`
+	OutputUnit   string    `json:"outputUnit"` // e.g. gal/min, gal/1000 sq ft
+	Notes        string    `json:"notes,omitempty"`
+	RecordedAt   time.Time `json:"recordedAt"`
+}
+
+// Validate checks a calibration.
+func (c Calibration) Validate() error {
+	var problems []string
+	if c.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if c.CalibratedAt.IsZero() {
+		problems = append(problems, "calibratedAt is required")
+	}
+	if c.OutputRate <= 0 {
+		problems = append(problems, "outputRate must be > 0")
+	}
+	if strings.TrimSpace(c.OutputUnit) == "" {
+		problems = append(problems, "outputUnit is required")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidCalibration, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Store persists assets and their calibrations.
+type Store interface {
+	SaveAsset(a Asset) error
+	GetAsset(id string) (Asset, error)
+	ListAssets() ([]Asset, error)
+	// DeleteAsset removes an asset and its calibrations.
+	DeleteAsset(id string) error
+
+	SaveCalibration(c Calibration) error
+	// ListCalibrations returns an asset's calibrations, oldest first.
+	ListCalibrations(assetID string) ([]Calibration, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	assets       map[string]Asset
+	calibrations map[string][]Calibration
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{assets: make(map[string]Asset), calibrations: make(map[string][]Calibration)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveAsset(a Asset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.assets[a.ID] = a
+	return nil
+}
+
+func (m *MemoryStore) GetAsset(id string) (Asset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.assets[id]
+	if !ok {
+		return Asset{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (m *MemoryStore) ListAssets() ([]Asset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Asset, 0, len(m.assets))
+	for _, a := range m.assets {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteAsset(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.assets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.assets, id)
+	delete(m.calibrations, id)
+	return nil
+}
+
+func (m *MemoryStore) SaveCalibration(c Calibration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := append(m.calibrations[c.AssetID], c)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CalibratedAt.Before(list[j].CalibratedAt) })
+	m.calibrations[c.AssetID] = list
+	return nil
+}
+
+func (m *MemoryStore) ListCalibrations(assetID string) ([]Calibration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Calibration{}, m.calibrations[assetID]...), nil
+}
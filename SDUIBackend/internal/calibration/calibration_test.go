@@ -0,0 +1,96 @@
+package calibration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newService(t *testing.T, required ...string) (*Service, *time.Time) {
+	t.Helper()
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-a"})
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	svc := NewService(config.CalibrationConfig{Interval: 30 * 24 * time.Hour, DueSoon: 7 * 24 * time.Hour, RequiredMethods: required}, NewMemoryStore(), repos, nil)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestStatusFollowsInterval(t *testing.T) {
+	svc, now := newService(t)
+	if _, err := svc.CreateAsset(Asset{Name: "Sprayer", TechnicianID: "ghost"}); !errors.Is(err, ErrInvalidAsset) {
+		t.Fatalf("expected an unknown technician rejected, got %v", err)
+	}
+	a, err := svc.CreateAsset(Asset{Name: "Backpack sprayer", TechnicianID: "tech-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := svc.Status(a.ID); st.State != StateNever || st.DueAt != nil {
+		t.Fatalf("expected never calibrated, got %+v", st)
+	}
+
+	if _, err := svc.Record(a.ID, Calibration{TechnicianID: "tech-a", CalibratedAt: now.Add(time.Hour), OutputRate: 1, OutputUnit: "gal/min"}); !errors.Is(err, ErrInvalidCalibration) {
+		t.Fatalf("expected a future calibration rejected, got %v", err)
+	}
+	if _, err := svc.Record(a.ID, Calibration{TechnicianID: "tech-a", CalibratedAt: *now, OutputRate: 1.2, OutputUnit: "gal/min"}); err != nil {
+		t.Fatal(err)
+	}
+	for offset, want := range map[time.Duration]string{
+		-time.Hour:          StateNever,
+		20 * 24 * time.Hour: StateCurrent,
+		25 * 24 * time.Hour: StateDueSoon,
+		30 * 24 * time.Hour: StateOverdue,
+	} {
+		if st, err := svc.StatusAt(a.ID, now.Add(offset)); err != nil || st.State != want {
+			t.Errorf("at %v: expected %s, got %+v (%v)", offset, want, st, err)
+		}
+	}
+
+	a.IntervalDays = 60
+	if _, err := svc.UpdateAsset(a.ID, a); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := svc.StatusAt(a.ID, now.Add(30*24*time.Hour)); st.State != StateCurrent {
+		t.Fatalf("expected the asset's own interval used, got %+v", st)
+	}
+	if list, _ := svc.Statuses("tech-a", StateCurrent); len(list) != 1 {
+		t.Fatalf("expected the technician's asset listed, got %+v", list)
+	}
+}
+
+func TestCheckGatesRequiredMethods(t *testing.T) {
+	svc, now := newService(t, "fumigation")
+	a, _ := svc.CreateAsset(Asset{Name: "Fogger"})
+
+	if _, err := svc.Check("", "Fumigation", time.Time{}); !errors.Is(err, ErrCalibrationOverdue) {
+		t.Fatalf("expected gated treatments to name equipment, got %v", err)
+	}
+	if _, err := svc.Check(a.ID, "fumigation", time.Time{}); !errors.Is(err, ErrCalibrationOverdue) {
+		t.Fatalf("expected uncalibrated equipment rejected, got %v", err)
+	}
+	if warning, err := svc.Check(a.ID, "spray", time.Time{}); err != nil || warning == "" {
+		t.Fatalf("expected a warning for ungated methods, got %q (%v)", warning, err)
+	}
+	if _, err := svc.Check("missing", "spray", time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected unknown equipment reported, got %v", err)
+	}
+
+	_, _ = svc.Record(a.ID, Calibration{TechnicianID: "tech-a", CalibratedAt: now.Add(-10 * 24 * time.Hour), OutputRate: 1, OutputUnit: "gal/min"})
+	if warning, err := svc.Check(a.ID, "fumigation", time.Time{}); err != nil || warning != "" {
+		t.Fatalf("expected calibrated equipment to pass, got %q (%v)", warning, err)
+	}
+	if warning, err := svc.Check(a.ID, "fumigation", now.Add(15*24*time.Hour)); err != nil || warning == "" {
+		t.Fatalf("expected a due-soon warning, got %q (%v)", warning, err)
+	}
+
+	var none *Service
+	if warning, err := none.Check("anything", "fumigation", time.Time{}); err != nil || warning != "" {
+		t.Fatalf("expected a nil service to check nothing, got %q (%v)", warning, err)
+	}
+}
@@ -0,0 +1,171 @@
+package calibration
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes equipment and calibration logging to technicians, and
+// asset management in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a calibration handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListAssets)
+	r.Post("/", h.CreateAsset)
+	r.Get("/{assetId}", h.GetAsset)
+	r.Put("/{assetId}", h.UpdateAsset)
+	r.Delete("/{assetId}", h.DeleteAsset)
+	r.Get("/{assetId}/calibrations", h.ListCalibrations)
+	r.Post("/{assetId}/calibrations", h.RecordCalibration)
+}
+
+// ListAssets returns every asset's calibration status, optionally for one
+// ?technicianId= or in one ?state=.
+func (h *Handler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.Statuses(r.URL.Query().Get("technicianId"), r.URL.Query().Get("state"))
+	if err != nil {
+		h.fail(w, r, "failed to list equipment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"equipment": statuses})
+}
+
+// ListMyEquipment returns the calibration status of the authenticated
+// technician's assigned equipment.
+func (h *Handler) ListMyEquipment(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	statuses, err := h.service.Statuses(me, "")
+	if err != nil {
+		h.fail(w, r, "failed to list equipment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"equipment": statuses})
+}
+
+// GetAsset returns an asset's calibration status.
+func (h *Handler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(chi.URLParam(r, "assetId"))
+	if err != nil {
+		h.fail(w, r, "failed to load equipment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, status)
+}
+
+// CreateAsset adds an asset.
+func (h *Handler) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	var payload Asset
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	a, err := h.service.CreateAsset(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create equipment", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, a)
+}
+
+// UpdateAsset replaces an asset's details.
+func (h *Handler) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	var payload Asset
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	a, err := h.service.UpdateAsset(chi.URLParam(r, "assetId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update equipment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, a)
+}
+
+// DeleteAsset removes an asset and its calibrations.
+func (h *Handler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteAsset(chi.URLParam(r, "assetId")); err != nil {
+		h.fail(w, r, "failed to delete equipment", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCalibrations returns an asset's calibrations.
+func (h *Handler) ListCalibrations(w http.ResponseWriter, r *http.Request) {
+	calibrations, err := h.service.Calibrations(chi.URLParam(r, "assetId"))
+	if err != nil {
+		h.fail(w, r, "failed to list calibrations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"calibrations": calibrations})
+}
+
+// RecordCalibration logs a calibration on behalf of the payload's
+// technicianId.
+func (h *Handler) RecordCalibration(w http.ResponseWriter, r *http.Request) {
+	var payload Calibration
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	h.record(w, r, payload)
+}
+
+// RecordMyCalibration logs a calibration by the authenticated technician.
+func (h *Handler) RecordMyCalibration(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var payload Calibration
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	payload.TechnicianID = me
+	h.record(w, r, payload)
+}
+
+func (h *Handler) record(w http.ResponseWriter, r *http.Request, payload Calibration) {
+	c, err := h.service.Record(chi.URLParam(r, "assetId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to record calibration", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, c)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidAsset), errors.Is(err, ErrInvalidCalibration):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
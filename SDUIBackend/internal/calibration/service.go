@@ -0,0 +1,244 @@
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// clockSkew tolerates device clocks running slightly ahead of the server.
+const clockSkew = 5 * time.Minute
+
+// Status is an asset's calibration state at a point in time.
+type Status struct {
+	Asset           Asset        `json:"asset"`
+	State           string       `json:"state"`
+	LastCalibration *Calibration `json:"lastCalibration,omitempty"`
+	DueAt           *time.Time   `json:"dueAt,omitempty"`
+}
+
+// Service manages assets and calibrations and checks treatments against
+// them. A nil *Service checks nothing.
+type Service struct {
+	cfg    config.CalibrationConfig
+	store  Store
+	repos  repository.Repository
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService creates a calibration service.
+func NewService(cfg config.CalibrationConfig, store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, logger: logger, now: time.Now}
+}
+
+// CreateAsset adds an asset.
+func (s *Service) CreateAsset(a Asset) (Asset, error) {
+	a.ID = uuid.NewString()
+	a.CreatedAt = time.Time{}
+	return s.saveAsset(a)
+}
+
+// UpdateAsset replaces an asset's details; its calibrations are kept.
+func (s *Service) UpdateAsset(id string, a Asset) (Asset, error) {
+	existing, err := s.store.GetAsset(id)
+	if err != nil {
+		return Asset{}, err
+	}
+	a.ID, a.CreatedAt = id, existing.CreatedAt
+	return s.saveAsset(a)
+}
+
+func (s *Service) saveAsset(a Asset) (Asset, error) {
+	a.Name = strings.TrimSpace(a.Name)
+	if err := a.Validate(); err != nil {
+		return Asset{}, err
+	}
+	if a.TechnicianID != "" {
+		if _, err := s.repos.Technicians.GetByID(a.TechnicianID); err != nil {
+			return Asset{}, fmt.Errorf("%w: technician %q not found", ErrInvalidAsset, a.TechnicianID)
+		}
+	}
+	now := s.now().UTC()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+	a.UpdatedAt = now
+	if err := s.store.SaveAsset(a); err != nil {
+		return Asset{}, err
+	}
+	return a, nil
+}
+
+// DeleteAsset removes an asset and its calibration history.
+func (s *Service) DeleteAsset(id string) error {
+	return s.store.DeleteAsset(id)
+}
+
+// Record logs a calibration of an asset by a technician.
+func (s *Service) Record(assetID string, c Calibration) (Calibration, error) {
+	if _, err := s.store.GetAsset(assetID); err != nil {
+		return Calibration{}, err
+	}
+	c.AssetID = assetID
+	c.OutputUnit = strings.TrimSpace(c.OutputUnit)
+	if err := c.Validate(); err != nil {
+		return Calibration{}, err
+	}
+	now := s.now().UTC()
+	if c.CalibratedAt.After(now.Add(clockSkew)) {
+		return Calibration{}, fmt.Errorf("%w: calibratedAt is in the future", ErrInvalidCalibration)
+	}
+	c.ID = uuid.NewString()
+	c.CalibratedAt = c.CalibratedAt.UTC()
+	c.RecordedAt = now
+	if err := s.store.SaveCalibration(c); err != nil {
+		return Calibration{}, err
+	}
+	s.logger.Info("equipment calibrated", slog.String("asset", assetID), slog.String("technician", c.TechnicianID), slog.Float64("outputRate", c.OutputRate))
+	return c, nil
+}
+
+// Calibrations returns an asset's calibrations, oldest first.
+func (s *Service) Calibrations(assetID string) ([]Calibration, error) {
+	if _, err := s.store.GetAsset(assetID); err != nil {
+		return nil, err
+	}
+	return s.store.ListCalibrations(assetID)
+}
+
+// Status returns an asset's calibration state now.
+func (s *Service) Status(assetID string) (Status, error) {
+	return s.StatusAt(assetID, s.now())
+}
+
+// StatusAt returns an asset's calibration state at a time, from the
+// calibrations made up to then.
+func (s *Service) StatusAt(assetID string, at time.Time) (Status, error) {
+	a, err := s.store.GetAsset(assetID)
+	if err != nil {
+		return Status{}, err
+	}
+	return s.status(a, at)
+}
+
+// Statuses returns the calibration state now of every asset, or of the
+// assets assigned to technicianID when it is set, optionally only those in
+// state.
+func (s *Service) Statuses(technicianID, state string) ([]Status, error) {
+	assets, err := s.store.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	out := []Status{}
+	for _, a := range assets {
+		if technicianID != "" && a.TechnicianID != technicianID {
+			continue
+		}
+		st, err := s.status(a, now)
+		if err != nil {
+			return nil, err
+		}
+		if state == "" || st.State == state {
+			out = append(out, st)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) status(a Asset, at time.Time) (Status, error) {
+	calibrations, err := s.store.ListCalibrations(a.ID)
+	if err != nil {
+		return Status{}, err
+	}
+	st := Status{Asset: a, State: StateNever}
+	for i := len(calibrations) - 1; i >= 0; i-- {
+		if !calibrations[i].CalibratedAt.After(at) {
+			st.LastCalibration = &calibrations[i]
+			break
+		}
+	}
+	if st.LastCalibration == nil {
+		return st, nil
+	}
+	interval := s.cfg.Interval
+	if a.IntervalDays > 0 {
+		interval = time.Duration(a.IntervalDays) * 24 * time.Hour
+	}
+	due := st.LastCalibration.CalibratedAt.Add(interval)
+	st.DueAt = &due
+	switch {
+	case !at.Before(due):
+		st.State = StateOverdue
+	case !at.Before(due.Add(-s.cfg.DueSoon)):
+		st.State = StateDueSoon
+	default:
+		st.State = StateCurrent
+	}
+	return st, nil
+}
+
+// Check vets a treatment applied with an asset by a method at a time (now
+// when zero). Methods in the configured RequiredMethods must name an asset
+// whose calibration has not lapsed, or ErrCalibrationOverdue is returned;
+// other treatments on lapsing or lapsed equipment pass with a warning.
+// An unknown asset is ErrNotFound.
+func (s *Service) Check(assetID, method string, at time.Time) (warning string, err error) {
+	if s == nil {
+		return "", nil
+	}
+	method = strings.ToLower(strings.TrimSpace(method))
+	required := s.requires(method)
+	if assetID == "" {
+		if required {
+			return "", fmt.Errorf("%w: %s treatments must name the equipment used (equipmentId)", ErrCalibrationOverdue, method)
+		}
+		return "", nil
+	}
+	if at.IsZero() {
+		at = s.now()
+	}
+	st, err := s.StatusAt(assetID, at)
+	if errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("%w: equipment %q", ErrNotFound, assetID)
+	}
+	if err != nil {
+		return "", err
+	}
+	switch st.State {
+	case StateNever, StateOverdue:
+		msg := fmt.Sprintf("%s has no calibration on record", st.Asset.Name)
+		if st.State == StateOverdue {
+			msg = fmt.Sprintf("%s calibration was due %s", st.Asset.Name, st.DueAt.UTC().Format("2006-01-02"))
+		}
+		if required {
+			return "", fmt.Errorf("%w: %s; recalibrate before %s treatments", ErrCalibrationOverdue, msg, method)
+		}
+		return msg, nil
+	case StateDueSoon:
+		return fmt.Sprintf("%s calibration is due %s", st.Asset.Name, st.DueAt.UTC().Format("2006-01-02")), nil
+	}
+	return "", nil
+}
+
+// requires reports whether method is one of the configured RequiredMethods.
+func (s *Service) requires(method string) bool {
+	for _, m := range s.cfg.RequiredMethods {
+		if method != "" && m == method {
+			return true
+		}
+	}
+	return false
+}
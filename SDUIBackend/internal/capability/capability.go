@@ -0,0 +1,207 @@
+// Package capability describes what this deployment offers its callers:
+// the features enabled, the component types screens may contain, payload
+// limits and the integrations configured. Clients and partners read it to
+// adapt to an environment instead of hardcoding how environments differ.
+package capability
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/appversion"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+)
+
+// APIVersions are the API versions served, oldest first.
+var APIVersions = []string{"v1"}
+
+// Capabilities is what a caller is told about the deployment.
+type Capabilities struct {
+	Environment string `json:"environment"`
+	// Sandbox is set when the caller is served by a sandbox, which runs
+	// the public API without the integrations of the live environment.
+	Sandbox        bool            `json:"sandbox"`
+	APIVersions    []string        `json:"apiVersions"`
+	Features       []Feature       `json:"features"`
+	ComponentTypes []ComponentType `json:"componentTypes"`
+	Limits         Limits          `json:"limits"`
+	Integrations   []Integration   `json:"integrations"`
+	// Token describes the API token of the caller; nil for callers
+	// without one.
+	Token *Token `json:"token,omitempty"`
+}
+
+// Feature is a group of public endpoints.
+type Feature struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Scopes are the API token scopes of the feature's endpoints.
+	Scopes []string `json:"scopes"`
+	// Available reports whether the caller can use the feature: it is
+	// enabled and, for callers with a token, the token has one of Scopes.
+	Available bool `json:"available"`
+}
+
+// ComponentType is a component type screens may contain.
+type ComponentType struct {
+	Type string `json:"type"`
+	// MinAppVersion is the first app version that renders the type, when
+	// older versions are sent a fallback instead.
+	MinAppVersion string `json:"minAppVersion,omitempty"`
+}
+
+// Limits are the largest payloads the API accepts and returns.
+type Limits struct {
+	PhotoBytes      int64    `json:"photoBytes"`
+	PhotoTypes      []string `json:"photoTypes"`
+	VoiceNoteBytes  int64    `json:"voiceNoteBytes"`
+	BatchItems      int      `json:"batchItems"`
+	UpdatesPageSize int      `json:"updatesPageSize"`
+}
+
+// Integration is an external system the deployment works with. Provider
+// names the implementation in use, for integrations with several.
+type Integration struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Token describes the caller's API token.
+type Token struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rateLimit"` // requests per minute
+	Sandbox   bool       `json:"sandbox"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Service works out the capabilities of callers.
+type Service struct {
+	cfg         config.Config
+	sandbox     bool
+	minVersions map[string]string
+}
+
+// NewService creates a capability service for the live environment, or
+// for sandboxes when sandbox is set.
+func NewService(cfg config.Config, sandbox bool) *Service {
+	s := &Service{cfg: cfg, sandbox: sandbox}
+	// Config validation rejects unparsable versions.
+	s.minVersions, _ = cfg.Screens.MinVersions()
+	return s
+}
+
+// For returns the capabilities of a caller. token is the caller's API
+// token, if any. Component types the app at appVersion cannot render are
+// left out; an empty appVersion lists every type.
+func (s *Service) For(token *apitoken.Token, appVersion string) Capabilities {
+	c := Capabilities{
+		Environment:    string(s.cfg.Environment),
+		Sandbox:        s.sandbox,
+		APIVersions:    APIVersions,
+		Features:       s.features(token),
+		ComponentTypes: s.componentTypes(appVersion),
+		Limits: Limits{
+			PhotoBytes:      s.cfg.Photos.MaxBytes,
+			PhotoTypes:      s.cfg.Photos.AllowedTypes,
+			VoiceNoteBytes:  s.cfg.VoiceNotes.MaxBytes,
+			BatchItems:      s.cfg.Sync.BatchMaxItems,
+			UpdatesPageSize: s.cfg.Sync.UpdatesMaxLimit,
+		},
+		Integrations: s.integrations(),
+	}
+	if token != nil {
+		limit := token.RateLimit
+		if limit == 0 {
+			limit = s.cfg.APITokens.DefaultRateLimit
+		}
+		c.Token = &Token{Name: token.Name, Scopes: token.Scopes, RateLimit: limit, Sandbox: token.Sandbox, ExpiresAt: token.ExpiresAt}
+	}
+	return c
+}
+
+func (s *Service) features(token *apitoken.Token) []Feature {
+	live := !s.sandbox
+	features := []Feature{
+		{Name: "screens", Enabled: true, Scopes: []string{apitoken.ScopeScreensRead}},
+		{Name: "screenExperiments", Enabled: live, Scopes: []string{apitoken.ScopeScreensRead}},
+		{Name: "featureFlags", Enabled: live, Scopes: []string{apitoken.ScopeScreensRead}},
+		{Name: "jobs", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead, apitoken.ScopeJobsWrite}},
+		{Name: "voiceNotes", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead, apitoken.ScopeJobsWrite}},
+		{Name: "transcription", Enabled: live && s.cfg.VoiceNotes.Transcriber != "none", Scopes: []string{apitoken.ScopeJobsRead}},
+		{Name: "serviceReports", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead}},
+		{Name: "signatures", Enabled: true, Scopes: []string{apitoken.ScopeJobsWrite}},
+		{Name: "widgets", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead}},
+		{Name: "carPlay", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead}},
+		{Name: "siriVocabulary", Enabled: true, Scopes: []string{apitoken.ScopeJobsRead}},
+		{Name: "chemicals", Enabled: true, Scopes: []string{apitoken.ScopeChemicalsRead, apitoken.ScopeChemicalsWrite}},
+		{Name: "treatments", Enabled: true, Scopes: []string{apitoken.ScopeTreatmentsWrite}},
+		{Name: "sprayConditions", Enabled: live && s.cfg.Weather.Provider != "none", Scopes: []string{apitoken.ScopeTreatmentsWrite}},
+		{Name: "tankMixes", Enabled: true, Scopes: []string{apitoken.ScopeTankMixesRead, apitoken.ScopeTreatmentsWrite}},
+		{Name: "photos", Enabled: true, Scopes: []string{apitoken.ScopePhotosRead, apitoken.ScopePhotosWrite}},
+		{Name: "devices", Enabled: true, Scopes: []string{apitoken.ScopeDevicesRead, apitoken.ScopeDevicesWrite}},
+		{Name: "liveActivities", Enabled: true, Scopes: []string{apitoken.ScopeDevicesWrite}},
+		{Name: "locationTrace", Enabled: s.cfg.Playback.LocationRetention > 0, Scopes: []string{apitoken.ScopeDevicesWrite}},
+		{Name: "clientConfig", Enabled: true, Scopes: []string{apitoken.ScopeDevicesRead}},
+		{Name: "inventory", Enabled: true, Scopes: []string{apitoken.ScopeInventoryRead, apitoken.ScopeInventoryWrite}},
+		{Name: "inventoryCounts", Enabled: live && s.cfg.Inventory.CountsEnabled, Scopes: []string{apitoken.ScopeInventoryRead, apitoken.ScopeInventoryWrite}},
+		{Name: "disposals", Enabled: true, Scopes: []string{apitoken.ScopeDisposalsRead, apitoken.ScopeDisposalsWrite}},
+		{Name: "equipment", Enabled: true, Scopes: []string{apitoken.ScopeEquipmentRead, apitoken.ScopeEquipmentWrite}},
+		{Name: "inbox", Enabled: true, Scopes: []string{apitoken.ScopeInboxRead}},
+		{Name: "updates", Enabled: true, Scopes: []string{apitoken.ScopeUpdatesRead}},
+		{Name: "updateStream", Enabled: true, Scopes: []string{apitoken.ScopeUpdatesRead}},
+		{Name: "messaging", Enabled: true, Scopes: []string{apitoken.ScopeInboxRead}},
+	}
+	for i, f := range features {
+		features[i].Available = f.Enabled && (token == nil || hasAny(*token, f.Scopes))
+	}
+	return features
+}
+
+func hasAny(t apitoken.Token, scopes []string) bool {
+	for _, scope := range scopes {
+		if t.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) componentTypes(appVersion string) []ComponentType {
+	var types []ComponentType
+	for _, typ := range validate.ComponentTypes() {
+		minVersion := s.minVersions[typ]
+		if appVersion != "" && appversion.Below(appVersion, minVersion) {
+			continue
+		}
+		types = append(types, ComponentType{Type: typ, MinAppVersion: minVersion})
+	}
+	return types
+}
+
+// integrations lists the external systems configured. Sandboxes only
+// store blobs and render reports; everything else is live-only.
+func (s *Service) integrations() []Integration {
+	live := !s.sandbox
+	integrations := []Integration{
+		{Name: "storage", Enabled: true, Provider: s.cfg.Photos.Storage},
+		{Name: "reportRenderer", Enabled: true, Provider: s.cfg.Reports.Renderer},
+		{Name: "connectors", Enabled: live && s.cfg.Connector.Enabled},
+		{Name: "exports", Enabled: live && s.cfg.Export.Enabled},
+		{Name: "outboundFiles", Enabled: live && s.cfg.Outbound.Enabled},
+		{Name: "inboundFiles", Enabled: live && s.cfg.Inbound.Enabled},
+	}
+	for _, p := range []struct{ name, provider string }{
+		{"flags", s.cfg.Flags.Provider},
+		{"geocoding", s.cfg.Geocoding.Provider},
+		{"routing", s.cfg.Routing.Provider},
+		{"transcription", s.cfg.VoiceNotes.Transcriber},
+		{"translation", s.cfg.Translation.Provider},
+		{"weather", s.cfg.Weather.Provider},
+	} {
+		integrations = append(integrations, Integration{Name: p.name, Enabled: live && p.provider != "none", Provider: p.provider})
+	}
+	return integrations
+}
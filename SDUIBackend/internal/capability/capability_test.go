@@ -0,0 +1,121 @@
+package capability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func testConfig() config.Config {
+	return config.Config{
+		Environment: config.EnvDev,
+		Sync:        config.SyncConfig{BatchMaxItems: 500, UpdatesMaxLimit: 1000},
+		Photos:      config.PhotoConfig{Storage: "local", MaxBytes: 10 << 20, AllowedTypes: []string{"image/jpeg"}},
+		VoiceNotes:  config.VoiceNoteConfig{MaxBytes: 5 << 20, Transcriber: "http"},
+		Weather:     config.WeatherConfig{Provider: "none"},
+		Flags:       config.FlagsConfig{Provider: "none"},
+		Geocoding:   config.GeocodingConfig{Provider: "none"},
+		Translation: config.TranslationConfig{Provider: "none"},
+		Routing:     config.RoutingConfig{Provider: "haversine"},
+		Reports:     config.ReportConfig{Renderer: "builtin"},
+		Connector:   config.ConnectorConfig{Enabled: true},
+		APITokens:   config.APITokenConfig{DefaultRateLimit: 60},
+		Screens:     config.ScreenConfig{ComponentMinVersions: "chart=2.3"},
+	}
+}
+
+func feature(c Capabilities, name string) Feature {
+	for _, f := range c.Features {
+		if f.Name == name {
+			return f
+		}
+	}
+	return Feature{}
+}
+
+func integration(c Capabilities, name string) Integration {
+	for _, i := range c.Integrations {
+		if i.Name == name {
+			return i
+		}
+	}
+	return Integration{}
+}
+
+func hasType(c Capabilities, typ string) (ComponentType, bool) {
+	for _, t := range c.ComponentTypes {
+		if t.Type == typ {
+			return t, true
+		}
+	}
+	return ComponentType{}, false
+}
+
+func TestCapabilities(t *testing.T) {
+	live := NewService(testConfig(), false).For(nil, "")
+	if live.Limits.PhotoBytes != 10<<20 || live.Limits.BatchItems != 500 || live.APIVersions[0] != "v1" || live.Token != nil {
+		t.Fatalf("unexpected capabilities %+v", live)
+	}
+	if f := feature(live, "transcription"); !f.Enabled || !f.Available {
+		t.Errorf("expected transcription enabled, got %+v", f)
+	}
+	if f := feature(live, "sprayConditions"); f.Enabled {
+		t.Errorf("expected spray conditions off without a weather provider, got %+v", f)
+	}
+	if i := integration(live, "connectors"); !i.Enabled {
+		t.Errorf("expected connectors enabled, got %+v", i)
+	}
+	if i := integration(live, "routing"); !i.Enabled || i.Provider != "haversine" {
+		t.Errorf("expected the routing provider named, got %+v", i)
+	}
+	if chart, ok := hasType(live, "chart"); !ok || chart.MinAppVersion != "2.3" {
+		t.Errorf("expected chart listed with its min version, got %+v", chart)
+	}
+
+	if _, ok := hasType(NewService(testConfig(), false).For(nil, "2.2.9"), "chart"); ok {
+		t.Error("expected chart left out for an app too old to render it")
+	}
+	if _, ok := hasType(NewService(testConfig(), false).For(nil, "2.3"), "chart"); !ok {
+		t.Error("expected chart listed for an app that renders it")
+	}
+
+	sandbox := NewService(testConfig(), true).For(nil, "")
+	if !sandbox.Sandbox || feature(sandbox, "transcription").Enabled || integration(sandbox, "connectors").Enabled || integration(sandbox, "routing").Enabled {
+		t.Errorf("expected live-only features and integrations off in sandboxes, got %+v", sandbox)
+	}
+	if !feature(sandbox, "screens").Enabled || !integration(sandbox, "storage").Enabled {
+		t.Errorf("expected the public API in sandboxes, got %+v", sandbox)
+	}
+}
+
+func TestHandlerReportsToken(t *testing.T) {
+	h := NewHandler(NewService(testConfig(), false))
+	tokens := apitoken.NewService(config.APITokenConfig{DefaultRateLimit: 60}, apitoken.NewMemoryStore(), nil, nil)
+	_, secret, err := tokens.Create(apitoken.Token{Name: "partner", Owner: "acme", Scopes: []string{apitoken.ScopePhotosRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/capabilities", nil).WithContext(context.Background())
+	req.Header.Set("Authorization", "Bearer "+secret)
+	rec := httptest.NewRecorder()
+	tokens.Require("")(http.HandlerFunc(h.GetCapabilities)).ServeHTTP(rec, req)
+	var got Capabilities
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	if got.Token == nil || got.Token.Name != "partner" || got.Token.RateLimit != 60 {
+		t.Fatalf("expected the token described, got %+v", got.Token)
+	}
+	if f := feature(got, "photos"); !f.Available {
+		t.Errorf("expected photos available to the token, got %+v", f)
+	}
+	if f := feature(got, "jobs"); !f.Enabled || f.Available {
+		t.Errorf("expected jobs unavailable without its scopes, got %+v", f)
+	}
+}
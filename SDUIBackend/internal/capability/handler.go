@@ -0,0 +1,30 @@
+package capability
+
+import (
+	"net/http"
+
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Handler serves capabilities to clients and partners.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a capability handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetCapabilities returns the caller's capabilities. Apps pass
+// ?appVersion= to only be sent the component types they render.
+func (h *Handler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	var token *apitoken.Token
+	if t, ok := apitoken.FromContext(r.Context()); ok {
+		token = &t
+	}
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Add("Vary", "Authorization")
+	respond.JSON(w, http.StatusOK, h.service.For(token, r.URL.Query().Get("appVersion")))
+}
@@ -0,0 +1,37 @@
+// Package carplay serves the stop list shown on CarPlay. Apps may only put
+// fixed, glanceable templates in front of a driver, so the feed is a typed
+// list rather than an SDUI tree: each stop carries its navigation
+// destination and at most two short lines of plain text.
+package carplay
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Feed is the technician's remaining stops for one service date.
+type Feed struct {
+	ServiceDate string `json:"serviceDate"`
+	// DistractionSafe marks the payload as fit to show while driving: fixed
+	// fields and short plain text only.
+	DistractionSafe bool `json:"distractionSafe"`
+	// Remaining counts every unfinished stop, including any past MaxStops.
+	Remaining int    `json:"remaining"`
+	Stops     []Stop `json:"stops"`
+}
+
+// Stop is one entry of the CarPlay list.
+type Stop struct {
+	ID string `json:"id"`
+	// Position is the stop's place on the day's route, from 1.
+	Position int    `json:"position"`
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+	// Coordinate is the navigation destination. Stops that have not been
+	// geocoded carry their Address for Maps to resolve instead.
+	Coordinate  *models.Coordinate `json:"coordinate,omitempty"`
+	Address     string             `json:"address,omitempty"`
+	WindowStart *time.Time         `json:"windowStart,omitempty"`
+	WindowEnd   *time.Time         `json:"windowEnd,omitempty"`
+}
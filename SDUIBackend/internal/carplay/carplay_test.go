@@ -0,0 +1,54 @@
+package carplay
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestStopsAreOrderedPlainAndNavigable(t *testing.T) {
+	day := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	store := storememory.NewStore()
+	_ = store.SaveRoute(domain.Route{
+		TechnicianID: "tech-1",
+		ServiceDate:  day,
+		CustomerStops: []domain.RouteStop{
+			{CustomerID: "c3", CustomerName: "Third", Address: "3 Pine St", WindowStart: at(13), Latitude: 30.28, Longitude: -97.73},
+			{CustomerID: "c1", CustomerName: "First", Address: "1 Oak St", WindowStart: at(8)},
+			{CustomerID: "c2", CustomerName: "Riverside Apartments\nBuilding C — ask for the manager", Address: "25 River Rd,\n Austin, TX", WindowStart: at(10), Notes: "Gate code 1234"},
+		},
+	})
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "j1", TechnicianID: "tech-1", CustomerName: "First", ScheduledDate: day, Status: "completed"})
+	svc := NewService(config.CarPlayConfig{MaxStops: 1, MaxText: 24}, repository.Repository{Routes: store, Sync: store})
+
+	feed, err := svc.Stops(context.Background(), "tech-1", day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !feed.DistractionSafe || feed.Remaining != 2 || len(feed.Stops) != 1 {
+		t.Fatalf("expected one of two remaining stops, got %+v", feed)
+	}
+	next := feed.Stops[0]
+	if next.ID != "c2" || next.Position != 2 || next.Title != "Riverside Apartments Bu…" || next.Subtitle != "25 River Rd" {
+		t.Fatalf("expected the next stop in short plain text, got %+v", next)
+	}
+	if next.Coordinate != nil || next.Address != "25 River Rd, Austin, TX" {
+		t.Fatalf("expected a stop without coordinates to carry its address, got %+v", next)
+	}
+
+	svc.cfg.MaxStops = 5
+	feed, _ = svc.Stops(context.Background(), "tech-1", day)
+	if last := feed.Stops[1]; last.Coordinate == nil || last.Coordinate.Latitude != 30.28 || last.Address != "" {
+		t.Fatalf("expected a geocoded stop to carry its coordinate only, got %+v", last)
+	}
+	if body := strings.Join([]string{feed.Stops[0].Title, feed.Stops[0].Subtitle}, " "); strings.Contains(body, "Gate") {
+		t.Fatalf("expected notes left out, got %q", body)
+	}
+}
@@ -0,0 +1,48 @@
+package carplay
+
+import (
+	"net/http"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the CarPlay stop feed to the app.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a CarPlay handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetStops returns the authenticated technician's remaining stops for
+// ?serviceDate=, today by default.
+func (h *Handler) GetStops(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var date time.Time
+	if v := r.URL.Query().Get("serviceDate"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+	feed, err := h.service.Stops(r.Context(), me, date)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to load carplay stops", slog.String("technician", me), slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to load carplay stops", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	respond.JSON(w, http.StatusOK, feed)
+}
@@ -0,0 +1,139 @@
+package carplay
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// finishedStatuses are uploaded job statuses that take a stop off the list.
+var finishedStatuses = map[string]bool{"completed": true, "skipped": true}
+
+// Service builds CarPlay feeds from routes and uploaded job progress.
+type Service struct {
+	cfg   config.CarPlayConfig
+	repos repository.Repository
+	now   func() time.Time
+}
+
+// NewService wires a CarPlay service over the routes and job uploads in
+// repos.
+func NewService(cfg config.CarPlayConfig, repos repository.Repository) *Service {
+	return &Service{cfg: cfg, repos: repos, now: time.Now}
+}
+
+// Stops returns the technician's unfinished stops for date, today when
+// zero, in route order. A technician without a route gets an empty feed.
+// Reads are scoped to the tenant ctx acts for.
+func (s *Service) Stops(ctx context.Context, technicianID string, date time.Time) (Feed, error) {
+	repos := tenant.Repository(ctx, s.repos)
+	if date.IsZero() {
+		date = s.now()
+	}
+	day := startOfDay(date)
+	feed := Feed{ServiceDate: day.Format(time.DateOnly), DistractionSafe: true, Stops: []Stop{}}
+
+	route, err := repos.Routes.GetRoute(technicianID, day)
+	if err != nil {
+		return feed, nil
+	}
+	stops := append([]domain.RouteStop(nil), route.CustomerStops...)
+	sort.SliceStable(stops, func(i, j int) bool {
+		if stops[i].WindowStart.IsZero() || stops[j].WindowStart.IsZero() {
+			return false
+		}
+		return stops[i].WindowStart.Before(stops[j].WindowStart)
+	})
+	finished, err := s.finished(repos.Sync, technicianID, day, stops)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	for i, stop := range stops {
+		if finished[i] {
+			continue
+		}
+		feed.Remaining++
+		if len(feed.Stops) < s.cfg.MaxStops {
+			feed.Stops = append(feed.Stops, s.stop(stop, i+1))
+		}
+	}
+	return feed, nil
+}
+
+// stop reduces a route stop to what the CarPlay list shows.
+func (s *Service) stop(stop domain.RouteStop, position int) Stop {
+	street, _, _ := strings.Cut(stop.Address, ",")
+	out := Stop{
+		ID:       stop.CustomerID,
+		Position: position,
+		Title:    s.plain(stop.CustomerName),
+		Subtitle: s.plain(street),
+	}
+	if out.ID == "" {
+		out.ID = "stop-" + strconv.Itoa(position)
+	}
+	if stop.HasCoordinates() {
+		out.Coordinate = &models.Coordinate{Latitude: stop.Latitude, Longitude: stop.Longitude}
+	} else {
+		out.Address = strings.Join(strings.Fields(stop.Address), " ")
+	}
+	if !stop.WindowStart.IsZero() {
+		start := stop.WindowStart.UTC()
+		out.WindowStart = &start
+	}
+	if !stop.WindowEnd.IsZero() {
+		end := stop.WindowEnd.UTC()
+		out.WindowEnd = &end
+	}
+	return out
+}
+
+// finished reports, in stop order, which stops have a completed or skipped
+// job, matched by customer name or address.
+func (s *Service) finished(uploads repository.SyncRepository, technicianID string, day time.Time, stops []domain.RouteStop) ([]bool, error) {
+	jobs, err := uploads.ListJobUpdatesSince(day)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].ReceivedAt.Before(jobs[j].ReceivedAt) })
+	out := make([]bool, len(stops))
+	for _, job := range jobs {
+		if job.TechnicianID != technicianID || (!job.ScheduledDate.IsZero() && !startOfDay(job.ScheduledDate).Equal(day)) {
+			continue
+		}
+		for i, stop := range stops {
+			if strings.EqualFold(stop.CustomerName, job.CustomerName) || (job.Address != "" && strings.EqualFold(stop.Address, job.Address)) {
+				out[i] = finishedStatuses[job.Status]
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// plain strips control characters and runs of whitespace from s and cuts
+// it to MaxText characters.
+func (s *Service) plain(text string) string {
+	text = strings.Join(strings.FieldsFunc(text, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }), " ")
+	if utf8.RuneCountInString(text) <= s.cfg.MaxText {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:s.cfg.MaxText-1])) + "…"
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,160 @@
+// Package chaos injects faults into repository and integration calls, so
+// retries, dead-lettering, redelivery and brownout can be exercised end to
+// end outside prod. Faults set in config apply to every call; a request's
+// X-Chaos header sets faults for the integration calls made while serving
+// it. Repositories take no context, so only config faults reach them.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// ErrInjected is the error of an injected failure.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Faults are the faults injected into a call: Latency plus up to Jitter
+// before it, then ErrorPercent of calls fail without running and
+// PartialPercent run but report failure.
+type Faults struct {
+	Latency        time.Duration `json:"latency"`
+	Jitter         time.Duration `json:"jitter"`
+	ErrorPercent   int           `json:"errorPercent"`
+	PartialPercent int           `json:"partialPercent"`
+}
+
+// ParseFaults reads faults written as comma-separated key=value pairs, e.g.
+// "latency=300ms, jitter=100ms, error=20, partial=5".
+func ParseFaults(s string) (Faults, error) {
+	var f Faults
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Faults{}, fmt.Errorf("%q is not key=value", part)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "latency":
+			f.Latency, err = time.ParseDuration(value)
+		case "jitter":
+			f.Jitter, err = time.ParseDuration(value)
+		case "error":
+			f.ErrorPercent, err = strconv.Atoi(value)
+		case "partial":
+			f.PartialPercent, err = strconv.Atoi(value)
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q", key)
+		}
+		if err != nil {
+			return Faults{}, fmt.Errorf("invalid %s %q", key, value)
+		}
+	}
+	if f.Latency < 0 || f.Jitter < 0 {
+		return Faults{}, fmt.Errorf("latency and jitter must be >= 0")
+	}
+	if f.ErrorPercent < 0 || f.PartialPercent < 0 || f.ErrorPercent+f.PartialPercent > 100 {
+		return Faults{}, fmt.Errorf("error and partial must be >= 0 and total at most 100")
+	}
+	return f, nil
+}
+
+// Stats counts the faults injected since the server started.
+type Stats struct {
+	Delayed int64 `json:"delayed"`
+	Failed  int64 `json:"failed"`
+	Partial int64 `json:"partial"`
+}
+
+// Injector injects faults into calls. A nil Injector injects nothing, so
+// chaos can be turned off without nil checks.
+type Injector struct {
+	faults  Faults // applied to every call when enabled
+	enabled bool
+	header  bool
+
+	delayed atomic.Int64
+	failed  atomic.Int64
+	partial atomic.Int64
+}
+
+// New returns an injector for cfg, or nil when neither config nor header
+// faults are enabled.
+func New(cfg config.ChaosConfig) *Injector {
+	if !cfg.Enabled && !cfg.AllowHeader {
+		return nil
+	}
+	return &Injector{
+		faults: Faults{
+			Latency:        cfg.Latency,
+			Jitter:         cfg.Jitter,
+			ErrorPercent:   cfg.ErrorPercent,
+			PartialPercent: cfg.PartialPercent,
+		},
+		enabled: cfg.Enabled,
+		header:  cfg.AllowHeader,
+	}
+}
+
+// Stats returns the faults injected so far.
+func (i *Injector) Stats() Stats {
+	if i == nil {
+		return Stats{}
+	}
+	return Stats{Delayed: i.delayed.Load(), Failed: i.failed.Load(), Partial: i.partial.Load()}
+}
+
+// faultsFor returns the faults for a call made under ctx: the request's
+// header faults, else the configured ones.
+func (i *Injector) faultsFor(ctx context.Context) (Faults, bool) {
+	if f, ok := ctx.Value(faultsKey{}).(Faults); ok {
+		return f, true
+	}
+	return i.faults, i.enabled
+}
+
+// do runs call under f: it waits out the latency, then fails the call
+// without running it, runs it and reports failure anyway, or just runs it.
+func (i *Injector) do(ctx context.Context, f Faults, call func() error) error {
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += rand.N(f.Jitter)
+	}
+	if delay > 0 {
+		i.delayed.Add(1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	roll := rand.IntN(100)
+	switch {
+	case roll < f.ErrorPercent:
+		i.failed.Add(1)
+		return ErrInjected
+	case roll < f.ErrorPercent+f.PartialPercent:
+		if err := call(); err != nil {
+			return err
+		}
+		i.partial.Add(1)
+		return ErrInjected
+	}
+	return call()
+}
+
+type faultsKey struct{}
@@ -0,0 +1,107 @@
+package chaos
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestParseFaults(t *testing.T) {
+	f, err := ParseFaults("latency=300ms, Jitter=50ms,error=20 , partial=5")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if f != (Faults{Latency: 300 * time.Millisecond, Jitter: 50 * time.Millisecond, ErrorPercent: 20, PartialPercent: 5}) {
+		t.Fatalf("unexpected faults %+v", f)
+	}
+	for _, bad := range []string{"latency", "latency=soon", "error=80, partial=30", "error=-1", "drop=5"} {
+		if _, err := ParseFaults(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestRepositoryFaults(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store}
+
+	if New(config.ChaosConfig{}).Repository(repos) != repos {
+		t.Fatal("expected repositories untouched without chaos")
+	}
+
+	failing := New(config.ChaosConfig{Enabled: true, ErrorPercent: 100}).Repository(repos)
+	if err := failing.Technicians.SaveTechnician(models.Technician{ID: "tech-1"}); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected an injected error, got %v", err)
+	}
+	if _, err := store.GetByID("tech-1"); err == nil {
+		t.Fatal("expected a failed call not to run")
+	}
+
+	partial := New(config.ChaosConfig{Enabled: true, PartialPercent: 100})
+	if err := partial.Repository(repos).Technicians.SaveTechnician(models.Technician{ID: "tech-1"}); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected an injected error, got %v", err)
+	}
+	if _, err := store.GetByID("tech-1"); err != nil {
+		t.Fatalf("expected a partial failure to run the call: %v", err)
+	}
+	if s := partial.Stats(); s.Partial != 1 || s.Failed != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	slow := New(config.ChaosConfig{Enabled: true, Latency: 20 * time.Millisecond})
+	start := time.Now()
+	if _, err := slow.Repository(repos).Technicians.GetByID("tech-1"); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond || slow.Stats().Delayed != 1 {
+		t.Fatal("expected the call delayed")
+	}
+}
+
+func TestHeaderFaultsReachIntegrationCalls(t *testing.T) {
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer upstream.Close()
+
+	faults := New(config.ChaosConfig{AllowHeader: true})
+	client := &http.Client{Transport: faults.Transport(http.DefaultTransport)}
+	handler := faults.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodPost, upstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		resp.Body.Close()
+	}))
+
+	serve := func(header string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if header != "" {
+			req.Header.Set(Header, header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(""); code != http.StatusOK || hits != 1 {
+		t.Fatalf("expected calls without the header untouched, got %d after %d hits", code, hits)
+	}
+	if code := serve("error=100"); code != http.StatusBadGateway || hits != 1 {
+		t.Fatalf("expected the call failed before sending, got %d after %d hits", code, hits)
+	}
+	if code := serve("partial=100"); code != http.StatusBadGateway || hits != 2 {
+		t.Fatalf("expected the call sent and its response lost, got %d after %d hits", code, hits)
+	}
+	if code := serve("error=lots"); code != http.StatusBadRequest {
+		t.Fatalf("expected a malformed header rejected, got %d", code)
+	}
+}
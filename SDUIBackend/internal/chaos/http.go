@@ -0,0 +1,75 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Header sets faults for the integration calls made while serving a
+// request, in the form ParseFaults reads.
+const Header = "X-Chaos"
+
+// Middleware applies a request's X-Chaos header when header faults are
+// allowed; otherwise the header is ignored.
+func (i *Injector) Middleware(next http.Handler) http.Handler {
+	if i == nil || !i.header {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := r.Header.Get(Header)
+		if h == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		f, err := ParseFaults(h)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid chaos header", err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), faultsKey{}, f)))
+	})
+}
+
+// Transport wraps base so requests sent through it suffer the faults of
+// their context. Partial failures send the request and discard the
+// response, as when a webhook is delivered but the reply is lost.
+func (i *Injector) Transport(base http.RoundTripper) http.RoundTripper {
+	if i == nil {
+		return base
+	}
+	return transport{base: base, i: i}
+}
+
+type transport struct {
+	base http.RoundTripper
+	i    *Injector
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, ok := t.i.faultsFor(req.Context())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	var resp *http.Response
+	sent := false
+	err := t.i.do(req.Context(), f, func() (err error) {
+		sent = true
+		resp, err = t.base.RoundTrip(req)
+		return err
+	})
+	if err == nil {
+		return resp, nil
+	}
+	if resp != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	// A RoundTripper closes the request body even when it never sends it.
+	if !sent && req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, err
+}
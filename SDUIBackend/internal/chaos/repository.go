@@ -0,0 +1,345 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Repository wraps every repository so calls suffer the configured faults;
+// without config faults repos is returned as is. Nil repositories are left
+// nil so Repository.Validate still reports them.
+func (i *Injector) Repository(repos repository.Repository) repository.Repository {
+	if i == nil || !i.enabled {
+		return repos
+	}
+	out := repos
+	if repos.Technicians != nil {
+		out.Technicians = technicians{base: repos.Technicians, i: i}
+	}
+	if repos.Routes != nil {
+		out.Routes = routes{base: repos.Routes, i: i}
+	}
+	if repos.Screens != nil {
+		out.Screens = screens{base: repos.Screens, i: i}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{base: repos.Sync, i: i}
+	}
+	if repos.Devices != nil {
+		out.Devices = devices{base: repos.Devices, i: i}
+	}
+	return out
+}
+
+// call runs a repository call under the configured faults.
+func (i *Injector) call(fn func() error) error {
+	return i.do(context.Background(), i.faults, fn)
+}
+
+type technicians struct {
+	base repository.TechnicianRepository
+	i    *Injector
+}
+
+func (t technicians) GetByID(id string) (out models.Technician, err error) {
+	err = t.i.call(func() (err error) {
+		out, err = t.base.GetByID(id)
+		return err
+	})
+	return out, err
+}
+
+func (t technicians) SaveTechnician(tech models.Technician) error {
+	return t.i.call(func() error { return t.base.SaveTechnician(tech) })
+}
+
+func (t technicians) ListTechnicians() (out []models.Technician, err error) {
+	err = t.i.call(func() (err error) {
+		out, err = t.base.ListTechnicians()
+		return err
+	})
+	return out, err
+}
+
+type routes struct {
+	base repository.RouteRepository
+	i    *Injector
+}
+
+func (r routes) GetRoute(technicianID string, serviceDate time.Time) (out models.Route, err error) {
+	err = r.i.call(func() (err error) {
+		out, err = r.base.GetRoute(technicianID, serviceDate)
+		return err
+	})
+	return out, err
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	return r.i.call(func() error { return r.base.SaveRoute(route) })
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	return r.i.call(func() error { return r.base.DeleteRoute(technicianID, serviceDate) })
+}
+
+type screens struct {
+	base repository.ScreenRepository
+	i    *Injector
+}
+
+func (s screens) GetTemplate(id string, version int) (out models.ScreenTemplate, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.GetTemplate(id, version)
+		return err
+	})
+	return out, err
+}
+
+func (s screens) SaveTemplate(template models.ScreenTemplate) error {
+	return s.i.call(func() error { return s.base.SaveTemplate(template) })
+}
+
+func (s screens) ListTemplates() (out []models.ScreenTemplate, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListTemplates()
+		return err
+	})
+	return out, err
+}
+
+func (s screens) DeleteTemplate(id string, version int) error {
+	return s.i.call(func() error { return s.base.DeleteTemplate(id, version) })
+}
+
+type syncRepo struct {
+	base repository.SyncRepository
+	i    *Injector
+}
+
+func (s syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	return s.i.call(func() error { return s.base.SaveJobUpload(upload) })
+}
+
+func (s syncRepo) GetJobUpload(id string) (out models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.GetJobUpload(id)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) SaveChemicalUpload(upload models.ChemicalUpload) error {
+	return s.i.call(func() error { return s.base.SaveChemicalUpload(upload) })
+}
+
+func (s syncRepo) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	return s.i.call(func() error { return s.base.SaveChemicalTreatment(upload) })
+}
+
+func (s syncRepo) ListPendingJobs(limit int) (out []models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListPendingJobs(limit)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListPendingChemicals(limit int) (out []models.ChemicalUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListPendingChemicals(limit)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListAllJobs() (out []models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListAllJobs()
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListAllChemicals() (out []models.ChemicalUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListAllChemicals()
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListPendingTreatments(limit int) (out []models.ChemicalTreatmentUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListPendingTreatments(limit)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListJobUpdatesSince(since time.Time) (out []models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListJobUpdatesSince(since)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListRouteUpdatesSince(since time.Time) (out []models.Route, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListRouteUpdatesSince(since)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListChemicalUpdatesSince(since time.Time) (out []models.ChemicalUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListChemicalUpdatesSince(since)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListTreatmentUpdatesSince(since time.Time) (out []models.ChemicalTreatmentUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListTreatmentUpdatesSince(since)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListJobUpdates(page repository.Page) (out []models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListJobUpdates(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListRouteUpdates(page repository.Page) (out []models.Route, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListRouteUpdates(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListChemicalUpdates(page repository.Page) (out []models.ChemicalUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListChemicalUpdates(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListTreatmentUpdates(page repository.Page) (out []models.ChemicalTreatmentUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListTreatmentUpdates(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListUnprocessedJobs(page repository.Page) (out []models.JobUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListUnprocessedJobs(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListUnprocessedChemicals(page repository.Page) (out []models.ChemicalUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListUnprocessedChemicals(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListUnprocessedTreatments(page repository.Page) (out []models.ChemicalTreatmentUpload, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListUnprocessedTreatments(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListUnprocessedRoutes(page repository.Page) (out []models.Route, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListUnprocessedRoutes(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) MarkProcessed(kind, id string, savedAt time.Time) error {
+	return s.i.call(func() error { return s.base.MarkProcessed(kind, id, savedAt) })
+}
+
+func (s syncRepo) DeleteJob(id string) error {
+	return s.i.call(func() error { return s.base.DeleteJob(id) })
+}
+
+func (s syncRepo) DeleteChemical(id string) error {
+	return s.i.call(func() error { return s.base.DeleteChemical(id) })
+}
+
+func (s syncRepo) ListDeletionsSince(since time.Time) (out []models.Tombstone, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListDeletionsSince(since)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) ListDeletions(page repository.Page) (out []models.Tombstone, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.ListDeletions(page)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) PruneTombstones(cutoff time.Time) (out int, err error) {
+	err = s.i.call(func() (err error) {
+		out, err = s.base.PruneTombstones(cutoff)
+		return err
+	})
+	return out, err
+}
+
+func (s syncRepo) SaveBatch(batch repository.Batch) error {
+	return s.i.call(func() error { return s.base.SaveBatch(batch) })
+}
+
+type devices struct {
+	base repository.DeviceRepository
+	i    *Injector
+}
+
+func (d devices) SaveDeviceToken(token models.DeviceToken) error {
+	return d.i.call(func() error { return d.base.SaveDeviceToken(token) })
+}
+
+func (d devices) ListDeviceTokens(technicianID string) (out []models.DeviceToken, err error) {
+	err = d.i.call(func() (err error) {
+		out, err = d.base.ListDeviceTokens(technicianID)
+		return err
+	})
+	return out, err
+}
+
+func (d devices) DeleteDeviceToken(token string) error {
+	return d.i.call(func() error { return d.base.DeleteDeviceToken(token) })
+}
+
+func (d devices) PruneDeviceTokens(cutoff time.Time) (out int, err error) {
+	err = d.i.call(func() (err error) {
+		out, err = d.base.PruneDeviceTokens(cutoff)
+		return err
+	})
+	return out, err
+}
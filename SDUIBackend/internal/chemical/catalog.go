@@ -0,0 +1,229 @@
+// Package chemical holds the reference catalog of registered pesticide
+// products. Chemical uploads are checked against it, so a mistyped EPA
+// registration number or an active ingredient that does not belong to the
+// product is flagged to the technician, and the app searches it to fill in
+// new chemicals.
+package chemical
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// ErrInvalidCatalog wraps problems with a catalog file.
+var ErrInvalidCatalog = errors.New("invalid chemical catalog")
+
+// defaultCatalog is used when no catalog file is configured: common
+// structural pest control products.
+//
+//go:embed catalog.csv
+var defaultCatalog []byte
+
+// registrationPattern is an EPA registration number: the registrant's
+// company number and the product number, then the distributor's company
+// number for supplementally registered products, as in 432-1283-1021.
+var registrationPattern = regexp.MustCompile(`^[1-9]\d{0,6}-[1-9]\d{0,5}(-[1-9]\d{0,6})?$`)
+
+// ValidRegistration reports whether reg is formatted as an EPA
+// registration number.
+func ValidRegistration(reg string) bool {
+	return registrationPattern.MatchString(reg)
+}
+
+// baseRegistration drops the distributor number, since distributor
+// products share the registration of the product they relabel.
+func baseRegistration(reg string) string {
+	if i := strings.LastIndex(reg, "-"); strings.Count(reg, "-") == 2 {
+		return reg[:i]
+	}
+	return reg
+}
+
+// Product is a registered product in the catalog.
+type Product struct {
+	EPARegistration   string   `json:"epaRegistration"`
+	Name              string   `json:"name"`
+	Registrant        string   `json:"registrant,omitempty"`
+	ActiveIngredients []string `json:"activeIngredients"`
+}
+
+// Catalog is the products, by base registration number. A nil *Catalog
+// knows no products and checks nothing.
+type Catalog struct {
+	products map[string]Product
+	sorted   []Product
+	limit    int
+}
+
+// Load reads the catalog file named by cfg, a CSV file with an
+// epaRegistration,name,registrant,activeIngredients header, ingredients
+// separated by semicolons, or a JSON array of products. Without a file the
+// built-in catalog is loaded.
+func Load(cfg config.ChemicalCatalogConfig) (*Catalog, error) {
+	if cfg.Path == "" {
+		return parse(defaultCatalog, ".csv", cfg.SearchLimit)
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data, strings.ToLower(filepath.Ext(cfg.Path)), cfg.SearchLimit)
+}
+
+func parse(data []byte, ext string, limit int) (*Catalog, error) {
+	var products []Product
+	switch ext {
+	case ".json":
+		if err := json.Unmarshal(data, &products); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCatalog, err)
+		}
+	case ".csv":
+		var err error
+		if products, err = parseCSV(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported file type %q", ErrInvalidCatalog, ext)
+	}
+	return New(products, limit)
+}
+
+func parseCSV(data []byte) ([]Product, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 4
+	r.TrimLeadingSpace = true
+	if _, err := r.Read(); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidCatalog, err)
+	}
+	var products []Product
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return products, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCatalog, err)
+		}
+		p := Product{EPARegistration: record[0], Name: record[1], Registrant: record[2]}
+		for _, ai := range strings.Split(record[3], ";") {
+			if ai = strings.TrimSpace(ai); ai != "" {
+				p.ActiveIngredients = append(p.ActiveIngredients, ai)
+			}
+		}
+		products = append(products, p)
+	}
+}
+
+// New returns a catalog of products; searches return at most limit of
+// them. Every product needs a valid registration number, a name and an
+// active ingredient, and a number may only be listed once.
+func New(products []Product, limit int) (*Catalog, error) {
+	c := &Catalog{products: make(map[string]Product, len(products)), limit: limit}
+	for _, p := range products {
+		p.EPARegistration = strings.TrimSpace(p.EPARegistration)
+		p.Name = strings.TrimSpace(p.Name)
+		switch {
+		case !ValidRegistration(p.EPARegistration):
+			return nil, fmt.Errorf("%w: %q is not an EPA registration number", ErrInvalidCatalog, p.EPARegistration)
+		case p.Name == "" || len(p.ActiveIngredients) == 0:
+			return nil, fmt.Errorf("%w: %s needs a name and an active ingredient", ErrInvalidCatalog, p.EPARegistration)
+		}
+		key := baseRegistration(p.EPARegistration)
+		if _, ok := c.products[key]; ok {
+			return nil, fmt.Errorf("%w: %s is listed more than once", ErrInvalidCatalog, key)
+		}
+		c.products[key] = p
+		c.sorted = append(c.sorted, p)
+	}
+	sort.Slice(c.sorted, func(i, j int) bool { return strings.ToLower(c.sorted[i].Name) < strings.ToLower(c.sorted[j].Name) })
+	return c, nil
+}
+
+// Lookup returns the product registered under reg, a distributor
+// product resolving to the product it relabels.
+func (c *Catalog) Lookup(reg string) (Product, bool) {
+	if c == nil {
+		return Product{}, false
+	}
+	p, ok := c.products[baseRegistration(strings.TrimSpace(reg))]
+	return p, ok
+}
+
+// Search returns the products whose name, registration number, registrant
+// or active ingredients contain query, those whose name starts with it
+// first. An empty query returns the catalog from the top, by name.
+func (c *Catalog) Search(query string) []Product {
+	out := []Product{}
+	if c == nil {
+		return out
+	}
+	q := strings.ToLower(strings.TrimSpace(query))
+	var prefixed, contained []Product
+	for _, p := range c.sorted {
+		switch name := strings.ToLower(p.Name); {
+		case strings.HasPrefix(name, q):
+			prefixed = append(prefixed, p)
+		case strings.Contains(name, q) || strings.HasPrefix(p.EPARegistration, q) ||
+			strings.Contains(strings.ToLower(p.Registrant), q) || strings.Contains(strings.ToLower(strings.Join(p.ActiveIngredients, " ")), q):
+			contained = append(contained, p)
+		}
+	}
+	out = append(append(out, prefixed...), contained...)
+	if c.limit > 0 && len(out) > c.limit {
+		out = out[:c.limit]
+	}
+	return out
+}
+
+// Check cross-references a chemical's registration number and active
+// ingredient with the catalog and describes any mismatch. Chemicals
+// without a registration number are not checked.
+func (c *Catalog) Check(reg, activeIngredient string) []string {
+	reg = strings.TrimSpace(reg)
+	if c == nil || reg == "" {
+		return nil
+	}
+	p, ok := c.Lookup(reg)
+	if !ok {
+		return []string{fmt.Sprintf("EPA registration %s is not in the chemical catalog", reg)}
+	}
+	if strings.TrimSpace(activeIngredient) != "" && !ingredientMatches(activeIngredient, p.ActiveIngredients) {
+		return []string{fmt.Sprintf("Active ingredient %q does not match EPA registration %s (%s: %s)", activeIngredient, reg, p.Name, strings.Join(p.ActiveIngredients, ", "))}
+	}
+	return nil
+}
+
+// ingredientMatches reports whether the typed ingredient names one of the
+// product's, ignoring case, spacing and punctuation, so "Lambda
+// Cyhalothrin 9.7%" matches lambda-cyhalothrin.
+func ingredientMatches(typed string, ingredients []string) bool {
+	t := letters(typed)
+	for _, ai := range ingredients {
+		if a := letters(ai); a != "" && (strings.Contains(t, a) || strings.Contains(a, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func letters(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package chemical
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func TestValidRegistration(t *testing.T) {
+	for reg, want := range map[string]bool{
+		"100-1066": true, "432-1283-1021": true, "7969-210": true,
+		"": false, "100": false, "100-": false, "0100-1066": false, "EPA Reg. 100-1066": false, "100-1066-": false, "1-2-3-4": false,
+	} {
+		if got := ValidRegistration(reg); got != want {
+			t.Errorf("%q: expected %v, got %v", reg, want, got)
+		}
+	}
+}
+
+func TestBuiltInCatalog(t *testing.T) {
+	c, err := Load(config.ChemicalCatalogConfig{SearchLimit: 2})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if p, ok := c.Lookup("432-1483-1021"); !ok || p.Name != "Temprid FX" {
+		t.Fatalf("expected a distributor number to resolve to its product, got %+v", p)
+	}
+
+	for ingredient, mismatch := range map[string]bool{"": false, "Imidacloprid 21%": false, "beta cyfluthrin": false, "fipronil": true} {
+		if got := c.Check("432-1483", ingredient); (len(got) == 1) != mismatch {
+			t.Errorf("%q: expected mismatch %v, got %v", ingredient, mismatch, got)
+		}
+	}
+	if got := c.Check("999-1", "fipronil"); len(got) != 1 || !strings.Contains(got[0], "999-1") {
+		t.Errorf("expected an unknown number flagged, got %v", got)
+	}
+	if got := c.Check("", "fipronil"); got != nil {
+		t.Errorf("expected a chemical without a number unchecked, got %v", got)
+	}
+
+	if got := c.Search("t"); len(got) != 2 || got[0].Name != "Talstar P Professional" || got[1].Name != "Tempo SC Ultra" {
+		t.Fatalf("expected name prefixes first, capped at the limit, got %+v", got)
+	}
+	if got := c.Search("FIPRONIL"); len(got) != 1 || got[0].EPARegistration != "7969-210" {
+		t.Fatalf("expected a search by ingredient, got %+v", got)
+	}
+	if got := c.Search("432-"); len(got) != 2 {
+		t.Fatalf("expected a search by registration number, got %+v", got)
+	}
+	var none *Catalog
+	if got := none.Search("t"); len(got) != 0 || none.Check("1-1", "x") != nil {
+		t.Fatal("expected a nil catalog to know nothing")
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	c, err := Load(config.ChemicalCatalogConfig{Path: write("catalog.json", `[{"epaRegistration":"1-1","name":"Own Label","activeIngredients":["permethrin"]}]`), SearchLimit: 5})
+	if err != nil {
+		t.Fatalf("load json: %v", err)
+	}
+	if _, ok := c.Lookup("1-1"); !ok {
+		t.Fatal("expected the JSON catalog loaded")
+	}
+	for name, body := range map[string]string{
+		"bad.csv":   "epaRegistration,name,registrant,activeIngredients\nEPA-1,X,Y,z\n",
+		"empty.csv": "epaRegistration,name,registrant,activeIngredients\n1-1,X,Y,\n",
+		"dup.json":  `[{"epaRegistration":"1-1","name":"A","activeIngredients":["a"]},{"epaRegistration":"1-1-7","name":"B","activeIngredients":["b"]}]`,
+		"list.txt":  "1-1",
+	} {
+		if _, err := Load(config.ChemicalCatalogConfig{Path: write(name, body)}); !errors.Is(err, ErrInvalidCatalog) {
+			t.Errorf("%s: expected the catalog refused, got %v", name, err)
+		}
+	}
+}
+
+func TestSearchCatalogHandler(t *testing.T) {
+	c, _ := Load(config.ChemicalCatalogConfig{SearchLimit: 10})
+	rec := httptest.NewRecorder()
+	NewHandler(c).SearchCatalog(rec, httptest.NewRequest(http.MethodGet, "/v1/chemicals/catalog?query=demand", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"epaRegistration":"100-1066"`) || rec.Header().Get("Cache-Control") == "" {
+		t.Fatalf("unexpected response %d %v: %s", rec.Code, rec.Header(), rec.Body)
+	}
+}
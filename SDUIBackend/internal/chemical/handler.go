@@ -0,0 +1,25 @@
+package chemical
+
+import (
+	"net/http"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Handler exposes the catalog to the app.
+type Handler struct {
+	catalog *Catalog
+}
+
+// NewHandler creates a catalog handler.
+func NewHandler(catalog *Catalog) *Handler {
+	return &Handler{catalog: catalog}
+}
+
+// SearchCatalog returns the products matching ?query=, for autocomplete
+// when a technician adds a chemical. The catalog only changes with a
+// deploy, so answers can be cached for an hour.
+func (h *Handler) SearchCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	respond.JSON(w, http.StatusOK, map[string]any{"products": h.catalog.Search(r.URL.Query().Get("query"))})
+}
@@ -0,0 +1,207 @@
+// Package clientconfig serves devices their sync tuning and rolls new
+// tuning out to a percentage of devices. Devices are bucketed by hashing
+// their ID with the rollout's, so a device stays in its cohort on every
+// fetch and raising the percentage only adds devices. Rolling back sends
+// every device the defaults on its next fetch.
+package clientconfig
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a rollout does not exist.
+	ErrNotFound = errors.New("rollout not found")
+	// ErrInvalidRollout wraps rollout validation failures.
+	ErrInvalidRollout = errors.New("invalid rollout")
+)
+
+// Rollout statuses. Only active rollouts change what devices are sent.
+const (
+	StatusDraft      = "draft"
+	StatusActive     = "active"
+	StatusRolledBack = "rolled_back"
+)
+
+// Cohorts of devices while a rollout is active.
+const (
+	CohortRollout = "rollout"
+	CohortControl = "control"
+)
+
+// buckets is the resolution of rollout percentages: 100 buckets per percent.
+const buckets = 10000
+
+// Bounds on rolled out tuning, so a mistyped value cannot stall devices or
+// flood the API.
+const (
+	minPollSeconds    = 5
+	maxPollSeconds    = 3600
+	minBackoffSeconds = 1
+	maxBackoffSeconds = 1800
+)
+
+// Tuning is how devices sync. In a rollout, zero fields keep the defaults.
+type Tuning struct {
+	PollIntervalSeconds int `json:"pollIntervalSeconds,omitempty"`
+	BatchSize           int `json:"batchSize,omitempty"`
+	BackoffSeconds      int `json:"backoffSeconds,omitempty"`
+}
+
+// over returns t with the fields override sets replaced.
+func (t Tuning) over(override Tuning) Tuning {
+	if override.PollIntervalSeconds != 0 {
+		t.PollIntervalSeconds = override.PollIntervalSeconds
+	}
+	if override.BatchSize != 0 {
+		t.BatchSize = override.BatchSize
+	}
+	if override.BackoffSeconds != 0 {
+		t.BackoffSeconds = override.BackoffSeconds
+	}
+	return t
+}
+
+// Rollout sends new tuning to a percentage of devices.
+type Rollout struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	Tuning      Tuning `json:"tuning"`
+	// Percent is the share of devices in the rollout, 0-100.
+	Percent int `json:"percent"`
+	// DeviceIDs are in the rollout whatever the percentage, such as QA
+	// devices.
+	DeviceIDs    []string   `json:"deviceIds,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	RolledBackAt *time.Time `json:"rolledBackAt,omitempty"`
+}
+
+// Validate checks a rollout's own fields; batch sizes may not exceed
+// maxBatch, the most items a batch upload accepts.
+func (r Rollout) Validate(maxBatch int) error {
+	var problems []string
+	if strings.TrimSpace(r.ID) == "" {
+		problems = append(problems, "id is required")
+	} else if strings.ContainsAny(r.ID, "/ ") {
+		problems = append(problems, "id must not contain slashes or spaces")
+	}
+	switch r.Status {
+	case StatusDraft, StatusActive, StatusRolledBack:
+	default:
+		problems = append(problems, fmt.Sprintf("status must be %s, %s or %s", StatusDraft, StatusActive, StatusRolledBack))
+	}
+	if r.Percent < 0 || r.Percent > 100 {
+		problems = append(problems, "percent must be between 0 and 100")
+	}
+	t := r.Tuning
+	if t == (Tuning{}) {
+		problems = append(problems, "tuning must set pollIntervalSeconds, batchSize or backoffSeconds")
+	}
+	if t.PollIntervalSeconds != 0 && (t.PollIntervalSeconds < minPollSeconds || t.PollIntervalSeconds > maxPollSeconds) {
+		problems = append(problems, fmt.Sprintf("tuning.pollIntervalSeconds must be between %d and %d", minPollSeconds, maxPollSeconds))
+	}
+	if t.BatchSize != 0 && (t.BatchSize < 1 || t.BatchSize > maxBatch) {
+		problems = append(problems, fmt.Sprintf("tuning.batchSize must be between 1 and %d", maxBatch))
+	}
+	if t.BackoffSeconds != 0 && (t.BackoffSeconds < minBackoffSeconds || t.BackoffSeconds > maxBackoffSeconds) {
+		problems = append(problems, fmt.Sprintf("tuning.backoffSeconds must be between %d and %d", minBackoffSeconds, maxBackoffSeconds))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidRollout, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Includes reports whether a device is in the rollout's cohort, whatever
+// the rollout's status. Devices without an ID are only included at 100
+// percent.
+func (r Rollout) Includes(deviceID string) bool {
+	for _, id := range r.DeviceIDs {
+		if id == deviceID && id != "" {
+			return true
+		}
+	}
+	if deviceID == "" {
+		return r.Percent >= 100
+	}
+	sum := sha256.Sum256([]byte(r.ID + "/" + deviceID))
+	return int(binary.BigEndian.Uint64(sum[:8])%buckets) < r.Percent*buckets/100
+}
+
+// ClientConfig is what a device is sent.
+type ClientConfig struct {
+	Sync Tuning `json:"sync"`
+	// Rollout names the active rollout, and Cohort whether the device
+	// is in it; both are empty when no rollout is active.
+	Rollout string `json:"rollout,omitempty"`
+	Cohort  string `json:"cohort,omitempty"`
+}
+
+// Store persists rollouts.
+type Store interface {
+	SaveRollout(r Rollout) error
+	GetRollout(id string) (Rollout, error)
+	ListRollouts() ([]Rollout, error)
+	DeleteRollout(id string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	rollouts map[string]Rollout
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rollouts: make(map[string]Rollout)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveRollout(r Rollout) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollouts[r.ID] = r
+	return nil
+}
+
+func (m *MemoryStore) GetRollout(id string) (Rollout, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.rollouts[id]
+	if !ok {
+		return Rollout{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *MemoryStore) ListRollouts() ([]Rollout, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Rollout, 0, len(m.rollouts))
+	for _, r := range m.rollouts {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteRollout(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.rollouts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.rollouts, id)
+	return nil
+}
@@ -0,0 +1,127 @@
+package clientconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func newTestService() *Service {
+	cfg := config.SyncConfig{BatchMaxItems: 500, ClientPollInterval: time.Minute, ClientBatchSize: 100, ClientBackoff: 5 * time.Second}
+	return NewService(cfg, NewMemoryStore(), nil)
+}
+
+func TestRolloutCohorts(t *testing.T) {
+	svc := newTestService()
+	if got := svc.Resolve("device-1"); got.Sync != (Tuning{PollIntervalSeconds: 60, BatchSize: 100, BackoffSeconds: 5}) || got.Cohort != "" {
+		t.Fatalf("expected the defaults outside a rollout, got %+v", got)
+	}
+
+	r := Rollout{Status: StatusActive, Percent: 20, Tuning: Tuning{BatchSize: 250}, DeviceIDs: []string{"qa-phone"}}
+	if _, err := svc.Save("bigger-batches", r); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	enrolled := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("device-%d", i)
+		got := svc.Resolve(id)
+		if got.Rollout != "bigger-batches" {
+			t.Fatalf("expected the active rollout named, got %+v", got)
+		}
+		if got.Cohort == CohortRollout {
+			enrolled[id] = true
+			if got.Sync.BatchSize != 250 || got.Sync.PollIntervalSeconds != 60 {
+				t.Fatalf("expected only the batch size overridden, got %+v", got.Sync)
+			}
+		} else if got.Sync.BatchSize != 100 {
+			t.Fatalf("expected the control cohort on the defaults, got %+v", got.Sync)
+		}
+		if again := svc.Resolve(id); again.Cohort != got.Cohort {
+			t.Fatalf("expected %s to stay in its cohort", id)
+		}
+	}
+	if n := len(enrolled); n < 150 || n > 250 {
+		t.Errorf("expected about 20%% of devices enrolled, got %d", n)
+	}
+	if got := svc.Resolve("qa-phone"); got.Cohort != CohortRollout {
+		t.Errorf("expected a listed device enrolled, got %+v", got)
+	}
+
+	r.Percent = 50
+	if _, err := svc.Save("bigger-batches", r); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	for id := range enrolled {
+		if svc.Resolve(id).Cohort != CohortRollout {
+			t.Fatalf("expected %s to stay enrolled as the rollout grows", id)
+		}
+	}
+
+	if _, err := svc.Save("faster-polls", Rollout{Status: StatusActive, Percent: 5, Tuning: Tuning{PollIntervalSeconds: 30}}); !errors.Is(err, ErrInvalidRollout) {
+		t.Errorf("expected a second active rollout refused, got %v", err)
+	}
+	rolledBack, err := svc.Rollback("bigger-batches")
+	if err != nil || rolledBack.Status != StatusRolledBack || rolledBack.RolledBackAt == nil {
+		t.Fatalf("rollback: %+v %v", rolledBack, err)
+	}
+	for id := range enrolled {
+		if got := svc.Resolve(id); got.Sync.BatchSize != 100 || got.Cohort != "" {
+			t.Fatalf("expected the defaults after a rollback, got %+v", got)
+		}
+	}
+}
+
+func TestRolloutValidation(t *testing.T) {
+	svc := newTestService()
+	for name, r := range map[string]Rollout{
+		"no tuning":      {Percent: 10},
+		"percent":        {Percent: 120, Tuning: Tuning{BatchSize: 10}},
+		"poll too fast":  {Tuning: Tuning{PollIntervalSeconds: 1}},
+		"batch too big":  {Tuning: Tuning{BatchSize: 501}},
+		"backoff":        {Tuning: Tuning{BackoffSeconds: 7200}},
+		"unknown status": {Status: "paused", Tuning: Tuning{BatchSize: 10}},
+	} {
+		if _, err := svc.Save("r", r); !errors.Is(err, ErrInvalidRollout) {
+			t.Errorf("%s: expected ErrInvalidRollout, got %v", name, err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(newTestService())
+	r := chi.NewRouter()
+	r.Get("/client-config", h.GetClientConfig)
+	r.Route("/admin/client-config", h.Routes)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/admin/client-config/rollouts/slow-backoff", `{"status": "active", "percent": 100, "tuning": {"backoffSeconds": 30}}`); rec.Code != http.StatusOK {
+		t.Fatalf("save: %d %s", rec.Code, rec.Body)
+	}
+	rec := do(http.MethodGet, "/client-config?deviceId=abc", "")
+	var got ClientConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Sync.BackoffSeconds != 30 || got.Cohort != CohortRollout || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected config %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "/admin/client-config/rollouts/slow-backoff/rollback", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"rolled_back"`) {
+		t.Fatalf("rollback: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, "/admin/client-config/preview?deviceId=abc", ""); !strings.Contains(rec.Body.String(), `"backoffSeconds":5`) {
+		t.Errorf("expected the defaults previewed after the rollback, got %s", rec.Body)
+	}
+	if rec := do(http.MethodPost, "/admin/client-config/rollouts/nope/rollback", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown rollout, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package clientconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler serves client config to devices and rollouts to admins.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a client config handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.GetDefaults)
+	r.Get("/rollouts", h.ListRollouts)
+	r.Get("/rollouts/{rolloutId}", h.GetRollout)
+	r.Put("/rollouts/{rolloutId}", h.SaveRollout)
+	r.Delete("/rollouts/{rolloutId}", h.DeleteRollout)
+	r.Post("/rollouts/{rolloutId}/rollback", h.RollbackRollout)
+	r.Get("/preview", h.Preview)
+}
+
+// GetClientConfig returns the calling device's config. Devices pass
+// ?deviceId=; without one, the technician's ID places them in cohorts.
+func (h *Handler) GetClientConfig(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.URL.Query().Get("deviceId")
+	if deviceID == "" {
+		deviceID = auth.TechnicianID(r)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respond.JSON(w, http.StatusOK, h.service.Resolve(deviceID))
+}
+
+// GetDefaults returns the tuning devices outside a rollout are sent.
+func (h *Handler) GetDefaults(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]any{"sync": h.service.Defaults()})
+}
+
+// Preview returns the config ?deviceId= would be sent.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.URL.Query().Get("deviceId")
+	if deviceID == "" {
+		respond.Error(w, http.StatusBadRequest, "missing deviceId", "deviceId query parameter is required")
+		return
+	}
+	respond.JSON(w, http.StatusOK, h.service.Resolve(deviceID))
+}
+
+// ListRollouts returns every rollout.
+func (h *Handler) ListRollouts(w http.ResponseWriter, r *http.Request) {
+	rollouts, err := h.service.Rollouts()
+	if err != nil {
+		h.fail(w, r, "failed to list rollouts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"rollouts": rollouts})
+}
+
+// GetRollout returns a rollout.
+func (h *Handler) GetRollout(w http.ResponseWriter, r *http.Request) {
+	rollout, err := h.service.Rollout(chi.URLParam(r, "rolloutId"))
+	if err != nil {
+		h.fail(w, r, "failed to load rollout", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, rollout)
+}
+
+// SaveRollout creates or replaces a rollout.
+func (h *Handler) SaveRollout(w http.ResponseWriter, r *http.Request) {
+	var payload Rollout
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	rollout, err := h.service.Save(chi.URLParam(r, "rolloutId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save rollout", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, rollout)
+}
+
+// RollbackRollout sends every device the defaults again.
+func (h *Handler) RollbackRollout(w http.ResponseWriter, r *http.Request) {
+	rollout, err := h.service.Rollback(chi.URLParam(r, "rolloutId"))
+	if err != nil {
+		h.fail(w, r, "failed to roll back rollout", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, rollout)
+}
+
+// DeleteRollout removes a rollout.
+func (h *Handler) DeleteRollout(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(chi.URLParam(r, "rolloutId")); err != nil {
+		h.fail(w, r, "failed to delete rollout", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidRollout):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
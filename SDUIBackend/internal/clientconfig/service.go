@@ -0,0 +1,132 @@
+package clientconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Service manages rollouts and resolves what each device is sent.
+type Service struct {
+	cfg    config.SyncConfig
+	store  Store
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService wires a client config service. Devices are sent the client
+// tuning in cfg outside of rollouts.
+func NewService(cfg config.SyncConfig, store Store, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, logger: logger, now: time.Now}
+}
+
+// Defaults is the tuning devices outside a rollout are sent.
+func (s *Service) Defaults() Tuning {
+	return Tuning{
+		PollIntervalSeconds: int(s.cfg.ClientPollInterval / time.Second),
+		BatchSize:           s.cfg.ClientBatchSize,
+		BackoffSeconds:      int(s.cfg.ClientBackoff / time.Second),
+	}
+}
+
+// Resolve returns the config of a device: the defaults, overridden by the
+// active rollout when the device is in it. A store failure sends the
+// defaults, which are always safe.
+func (s *Service) Resolve(deviceID string) ClientConfig {
+	out := ClientConfig{Sync: s.Defaults()}
+	all, err := s.store.ListRollouts()
+	if err != nil {
+		s.logger.Warn("list client config rollouts", slog.Any("error", err))
+		return out
+	}
+	for _, r := range all {
+		if r.Status != StatusActive {
+			continue
+		}
+		out.Rollout, out.Cohort = r.ID, CohortControl
+		if r.Includes(deviceID) {
+			out.Sync, out.Cohort = out.Sync.over(r.Tuning), CohortRollout
+		}
+		break
+	}
+	return out
+}
+
+// Save creates or replaces a rollout. One rollout is active at a time.
+func (s *Service) Save(id string, r Rollout) (Rollout, error) {
+	r.ID = strings.TrimSpace(id)
+	if r.Status == "" {
+		r.Status = StatusDraft
+	}
+	if err := r.Validate(s.cfg.BatchMaxItems); err != nil {
+		return Rollout{}, err
+	}
+	all, err := s.store.ListRollouts()
+	if err != nil {
+		return Rollout{}, err
+	}
+	now := s.now().UTC()
+	r.CreatedAt, r.RolledBackAt = now, nil
+	for _, other := range all {
+		if other.ID == r.ID {
+			r.CreatedAt = other.CreatedAt
+			if r.Status == StatusRolledBack {
+				r.RolledBackAt = other.RolledBackAt
+			}
+			continue
+		}
+		if r.Status == StatusActive && other.Status == StatusActive {
+			return Rollout{}, fmt.Errorf("%w: rollout %s is already active", ErrInvalidRollout, other.ID)
+		}
+	}
+	if r.Status == StatusRolledBack && r.RolledBackAt == nil {
+		r.RolledBackAt = &now
+	}
+	r.UpdatedAt = now
+	if err := s.store.SaveRollout(r); err != nil {
+		return Rollout{}, err
+	}
+	s.logger.Info("client config rollout saved", slog.String("rollout", r.ID), slog.String("status", r.Status), slog.Int("percent", r.Percent))
+	return r, nil
+}
+
+// Rollback stops a rollout: every device is sent the defaults on its next
+// fetch. The rollout is kept, so it can be fixed and made active again.
+func (s *Service) Rollback(id string) (Rollout, error) {
+	r, err := s.store.GetRollout(id)
+	if err != nil {
+		return Rollout{}, err
+	}
+	if r.Status == StatusRolledBack {
+		return r, nil
+	}
+	now := s.now().UTC()
+	r.Status, r.RolledBackAt, r.UpdatedAt = StatusRolledBack, &now, now
+	if err := s.store.SaveRollout(r); err != nil {
+		return Rollout{}, err
+	}
+	s.logger.Warn("client config rollout rolled back", slog.String("rollout", r.ID), slog.Int("percent", r.Percent))
+	return r, nil
+}
+
+// Rollout returns a rollout.
+func (s *Service) Rollout(id string) (Rollout, error) {
+	return s.store.GetRollout(id)
+}
+
+// Rollouts lists rollouts by ID.
+func (s *Service) Rollouts() ([]Rollout, error) {
+	return s.store.ListRollouts()
+}
+
+// Delete removes a rollout; an active one is rolled back by its removal.
+func (s *Service) Delete(id string) error {
+	return s.store.DeleteRollout(id)
+}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/appversion"
 )
 
 // Environment represents the deployment environment.
@@ -25,6 +30,57 @@ type Config struct {
 	Secrets     SecretsConfig
 	Datastore   DatastoreConfig
 	Sync        SyncConfig
+	Brownout    BrownoutConfig
+	Export      ExportConfig
+	Outbound    OutboundConfig
+	Inbound     InboundConfig
+	Connector   ConnectorConfig
+	APITokens   APITokenConfig
+	Impersonate ImpersonationConfig
+	ETA         ETAConfig
+	VoiceNotes  VoiceNoteConfig
+	Photos      PhotoConfig
+	Assets      AssetConfig
+	ServicePlan ServicePlanConfig
+	Schedule    ScheduleConfig
+	Screens     ScreenConfig
+	Calendar    CalendarConfig
+	Auth        AuthConfig
+	Inventory   InventoryConfig
+	Calibration CalibrationConfig
+	Worker      WorkerConfig
+	Operations  OperationConfig
+	Queue       QueueConfig
+	Chaos       ChaosConfig
+	RateLimit   RateLimitConfig
+	Activity    ActivityConfig
+	Notify      NotifyConfig
+	Live        LiveActivityConfig
+	Widget      WidgetConfig
+	CarPlay     CarPlayConfig
+	Intents     IntentsConfig
+	Translation TranslationConfig
+	Routing     RoutingConfig
+	Geocoding   GeocodingConfig
+	Address     AddressConfig
+	Weather     WeatherConfig
+	Chemicals   ChemicalCatalogConfig
+	Usage       UsageReportConfig
+	Flags       FlagsConfig
+	Reports     ReportConfig
+	Promotion   PromotionConfig
+	Playback    PlaybackConfig
+	Stream      StreamConfig
+	Profit      ProfitConfig
+	Messaging   MessagingConfig
+	Events      EventsConfig
+	Anomaly     AnomalyConfig
+	Dedupe      DedupeConfig
+	Tenancy     TenancyConfig
+	StatusPage  StatusPageConfig
+	Signing     SigningConfig
+	Access      AccessConfig
+	Lockout     LockoutConfig
 }
 
 // ServerConfig controls HTTP behaviour.
@@ -35,6 +91,21 @@ type ServerConfig struct {
 	IdleTimeout    time.Duration
 	AllowedOrigins []string
 	EnableSwagger  bool
+	// Compression gzips responses of at least CompressionMinBytes for
+	// clients that accept it, at CompressionLevel (1-9, or -1 for gzip's
+	// default).
+	Compression         bool
+	CompressionMinBytes int
+	CompressionLevel    int
+	// Decompression accepts gzip-encoded request bodies, inflating each to
+	// at most MaxDecompressedBytes.
+	Decompression        bool
+	MaxDecompressedBytes int64
+	// TrustedProxies are the addresses and CIDR ranges of the proxies and
+	// load balancers in front of the server. Forwarding headers are
+	// honored only on requests arriving from one of them, so clients
+	// cannot choose the address the server sees. "none" trusts no proxy.
+	TrustedProxies []string
 }
 
 // TelemetryConfig controls structured logging and tracing.
@@ -50,19 +121,745 @@ type SecretsConfig struct {
 	Provider  string // env, gcp
 	ProjectID string
 	CacheTTL  time.Duration
+	// RefreshAhead re-fetches cached secrets this long before they expire,
+	// so rotations are seen without a request waiting on the fetch. Zero
+	// leaves secrets to expire.
+	RefreshAhead time.Duration
 }
 
 // DatastoreConfig defines persistence options (Firestore by default).
 type DatastoreConfig struct {
-	Driver            string // memory, firestore
+	Driver            string // memory, firestore, postgres
 	FirestoreProject  string
+	FirestoreDatabase string
 	FirestoreEmulator string
+	RequestTimeout    time.Duration
+
+	// PostgresURL is a libpq connection string or URL, e.g. a Cloud SQL
+	// instance reached through the Auth Proxy.
+	PostgresURL             string
+	PostgresMaxConns        int
+	PostgresMinConns        int
+	PostgresMaxConnLifetime time.Duration
+	PostgresMaxConnIdleTime time.Duration
+	// PostgresMigrate applies the embedded schema migrations at startup.
+	PostgresMigrate bool
+
+	// MemorySnapshotPath, when set, keeps the memory store across restarts:
+	// it is loaded from the file at startup, written back every
+	// MemorySnapshotInterval and again on shutdown. Local and dev only.
+	MemorySnapshotPath     string
+	MemorySnapshotInterval time.Duration
 }
 
 // SyncConfig captures retry/backoff settings for sync processing.
 type SyncConfig struct {
 	MaxRetries int
 	Backoff    time.Duration
+	// Device registrations not refreshed within DeviceStaleAfter are pruned
+	// every DevicePruneInterval; zero disables pruning.
+	DeviceStaleAfter    time.Duration
+	DevicePruneInterval time.Duration
+	// UpdatesDefaultLimit is the page size of updates for clients that pass
+	// no limit, zero meaning UpdatesMaxLimit; UpdatesMaxLimit caps every page.
+	UpdatesDefaultLimit int
+	UpdatesMaxLimit     int
+	// BatchMaxItems caps the items of one batch upload.
+	BatchMaxItems int
+	// Deleted records are kept as tombstones for TombstoneRetention, and
+	// pruned every TombstonePruneInterval; zero keeps them forever. Devices
+	// whose watermark is older must resync from scratch.
+	TombstoneRetention     time.Duration
+	TombstonePruneInterval time.Duration
+	// ClientPollInterval, ClientBatchSize and ClientBackoff are the sync
+	// tuning /v1/client-config sends devices outside of a rollout: how often
+	// they poll for updates, how many records they upload per batch, and how
+	// long they wait before retrying a failed sync.
+	ClientPollInterval time.Duration
+	ClientBatchSize    int
+	ClientBackoff      time.Duration
+}
+
+// BrownoutConfig controls adaptive degradation when datastore latency spikes.
+type BrownoutConfig struct {
+	Enabled           bool
+	P99Threshold      time.Duration // enter brownout above this p99
+	RecoveryThreshold time.Duration // leave brownout once p99 drops below this
+	Window            time.Duration // how far back latency samples count
+	MaxSamples        int
+	MinSamples        int
+	StaleTTL          time.Duration // oldest cached screen served while degraded
+	DeferredQueueSize int
+	FlushInterval     time.Duration
+}
+
+// ExportConfig controls scheduled tenant data exports.
+type ExportConfig struct {
+	Enabled        bool
+	CheckInterval  time.Duration
+	RequestTimeout time.Duration
+}
+
+// OutboundConfig controls partner flat-file generation and delivery.
+type OutboundConfig struct {
+	Enabled       bool
+	CheckInterval time.Duration
+	MaxAttempts   int
+	RetryBackoff  time.Duration
+	DropDir       string // root for the "directory" delivery method
+}
+
+// InboundConfig controls polling of partner drop folders.
+type InboundConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	DropDir      string // root for the "directory" source method
+	MaxRowErrors int    // row errors kept per run
+}
+
+// ConnectorConfig controls delivery of normalized events to no-code
+// integration URLs.
+type ConnectorConfig struct {
+	Enabled     bool
+	QueueSize   int // events buffered before new ones are dropped
+	MaxAttempts int
+	// RetryBackoff is the wait before the first retry; it doubles for each
+	// later one, up to MaxBackoff.
+	RetryBackoff   time.Duration
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	// Concurrency bounds the deliveries this instance makes at once with
+	// the memory queue driver.
+	Concurrency int
+}
+
+// APITokenConfig controls third-party API token enforcement.
+type APITokenConfig struct {
+	// Required rejects public API calls without a token. Off by default so
+	// first-party clients keep working until they authenticate.
+	Required         bool
+	DefaultRateLimit int // requests per minute for tokens without their own limit
+}
+
+// RateLimitConfig throttles the device API with token buckets per device,
+// or per client IP for requests without an authenticated technician. Reads
+// (GET) and writes draw on separate buckets, so an upload retry storm
+// cannot lock a technician out of their screens.
+type RateLimitConfig struct {
+	Enabled bool
+	// Buckets refill at the per-minute rate and hold up to the burst.
+	ReadPerMinute  int
+	ReadBurst      int
+	WritePerMinute int
+	WriteBurst     int
+}
+
+// ActivityConfig controls the admin activity feed.
+type ActivityConfig struct {
+	// GroupWindow is how close together one actor's events of one type must
+	// be to collapse into a single feed item. Zero never collapses.
+	GroupWindow time.Duration
+	// MaxEvents is how many audit events are kept; the oldest are dropped.
+	MaxEvents int
+	// AvatarURL is the actor avatar template. {hash} is replaced by the hex
+	// SHA-256 of the actor's lowercased email, or of their ID without one.
+	AvatarURL string
+}
+
+// NotifyConfig controls technician notification digests. A category's
+// notifications are held for its window after the first arrives and then
+// sent as one summarized push and inbox message.
+type NotifyConfig struct {
+	// DigestWindows sets windows per category as comma-separated
+	// category=duration pairs, such as "route=2m, inventory=10m".
+	DigestWindows string
+	// DefaultWindow applies to categories without a window. Zero sends
+	// their notifications at once.
+	DefaultWindow time.Duration
+}
+
+// Windows parses DigestWindows.
+func (c NotifyConfig) Windows() (map[string]time.Duration, error) {
+	windows := make(map[string]time.Duration)
+	for _, pair := range splitAndTrim(c.DigestWindows) {
+		category, raw, ok := strings.Cut(pair, "=")
+		category = strings.TrimSpace(category)
+		if !ok || category == "" {
+			return nil, fmt.Errorf("notification digest window %q is not category=duration", pair)
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || window < 0 {
+			return nil, fmt.Errorf("invalid notification digest window for %s: %s", category, raw)
+		}
+		windows[category] = window
+	}
+	return windows, nil
+}
+
+// LiveActivityConfig controls pushes to the iOS Live Activity following a
+// technician's current job.
+type LiveActivityConfig struct {
+	// StaleAfter marks an update's content stale on the device if no newer
+	// update arrives in time.
+	StaleAfter time.Duration
+	// DismissAfter keeps an ended activity on the lock screen this long.
+	DismissAfter time.Duration
+	// TokenTTL drops activity tokens not re-registered within it; iOS ends
+	// activities after at most twelve hours.
+	TokenTTL time.Duration
+}
+
+// WidgetConfig shapes the timeline served to the iOS home-screen widget.
+type WidgetConfig struct {
+	// MaxStops is how many upcoming stops the timeline lists.
+	MaxStops int
+	// MinRefresh and MaxRefresh bound the reload time suggested to the
+	// widget, which WidgetKit budgets to a few dozen reloads a day.
+	MinRefresh time.Duration
+	MaxRefresh time.Duration
+}
+
+// CarPlayConfig bounds the stop feed shown on CarPlay, where the driver
+// can only glance at the screen.
+type CarPlayConfig struct {
+	// MaxStops caps the stops listed; CarPlay lists show a dozen items.
+	MaxStops int
+	// MaxText caps each line of text, in characters.
+	MaxText int
+}
+
+// IntentsConfig bounds the vocabulary the app registers with Siri.
+type IntentsConfig struct {
+	// Days is how many days of routes, from today, the vocabulary covers.
+	Days int
+	// MaxTerms caps each list of terms; Siri weighs earlier terms higher.
+	MaxTerms int
+}
+
+// ImpersonationConfig bounds support impersonation sessions.
+type ImpersonationConfig struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// ETAConfig controls customer-facing ETA sharing links.
+type ETAConfig struct {
+	PublicBaseURL string        // prefix for generated links, e.g. https://status.example.com
+	StopDuration  time.Duration // assumed time per stop when estimating arrival
+	ExpiryGrace   time.Duration // links stay valid this long after the visit window
+}
+
+// VoiceNoteConfig controls job voice note uploads and transcription.
+type VoiceNoteConfig struct {
+	MaxBytes       int64  // largest accepted audio upload
+	Transcriber    string // none, http
+	TranscriberURL string // speech-to-text endpoint for the "http" transcriber
+	TranscriberKey string // secret name holding the endpoint's bearer token
+	RequestTimeout time.Duration
+	QueueSize      int // notes buffered for transcription
+}
+
+// TranslationConfig controls machine translation of template strings into
+// draft catalogs.
+type TranslationConfig struct {
+	Provider       string // none, deepl, google
+	URL            string // provider endpoint; empty uses the provider's public API
+	KeySecret      string // secret name holding the provider's API key
+	SourceLocale   string // locale of the templates' own text: SDUI_DEFAULT_LOCALE
+	RequestTimeout time.Duration
+}
+
+// PhotoConfig controls job and treatment photo uploads.
+type PhotoConfig struct {
+	Storage        string // local, gcs
+	LocalDir       string // root for the "local" blob store
+	Bucket         string // bucket for the "gcs" blob store
+	MaxBytes       int64
+	AllowedTypes   []string
+	URLTTL         time.Duration // lifetime of signed photo URLs
+	PublicBaseURL  string        // prefix for local signed URLs, e.g. https://api.example.com
+	SigningKey     string        // HMAC key for local signed URLs; random per process when empty
+	RequestTimeout time.Duration
+}
+
+// AssetConfig controls the images templates reference. Assets share the
+// photos' blob store.
+type AssetConfig struct {
+	// CDNBaseURL fronts the blob store, e.g. https://cdn.example.com; asset
+	// keys are appended to it. Empty serves assets from the API under
+	// PublicBaseURL, which only works with local storage.
+	CDNBaseURL    string
+	PublicBaseURL string // PHOTOS_PUBLIC_BASE_URL
+	MaxBytes      int64
+	AllowedTypes  []string
+	GCInterval    time.Duration // how often unreferenced assets are collected
+	GCGrace       time.Duration // how long an unreferenced asset or version is kept
+}
+
+// ServicePlanConfig controls drift detection for service plan programs.
+type ServicePlanConfig struct {
+	ScheduleTolerance time.Duration // visits done further than this from plan are off schedule
+	MissedGrace       time.Duration // visits still open this long after their date are missed
+}
+
+// RoutingConfig controls route optimization.
+type RoutingConfig struct {
+	Provider       string        // haversine, google
+	URL            string        // Distance Matrix endpoint; empty uses Google's public API
+	KeySecret      string        // secret name holding the Distance Matrix API key
+	SpeedKPH       int           // average driving speed the haversine provider assumes
+	MaxStops       int           // largest route that can be optimized
+	ShiftStart     time.Duration // SCHEDULE_SHIFT_START: when the day starts
+	FallbackLeg    time.Duration // SCHEDULE_DRIVE_TIME_PER_STOP: travel to or from a stop without coordinates
+	RequestTimeout time.Duration
+}
+
+// GeocodingConfig selects the geocoder the worker resolves job and route
+// stop addresses to coordinates with.
+type GeocodingConfig struct {
+	Provider       string // none, google, nominatim
+	URL            string // geocoding endpoint; empty uses the provider's public API
+	KeySecret      string // secret name holding the API key (google)
+	RequestTimeout time.Duration
+}
+
+// AddressConfig selects the validator imported route stop addresses are
+// standardized with.
+type AddressConfig struct {
+	Provider       string // none, local, google, usps
+	URL            string // API base URL; empty uses the provider's public API
+	KeySecret      string // secret name of the API key (google) or client secret (usps)
+	ClientIDSecret string // secret name of the OAuth client ID (usps)
+	// CacheTTL is how long a validated address is reused before it is
+	// checked again.
+	CacheTTL       time.Duration
+	RequestTimeout time.Duration
+}
+
+// WeatherConfig selects the provider spray conditions are fetched from for
+// route stops and treatments, and the limits that raise warnings.
+type WeatherConfig struct {
+	Provider  string // none, openweather, nws
+	URL       string // API base URL; empty uses the provider's public API
+	KeySecret string // secret name holding the API key (openweather)
+	// CacheTTL is how long conditions for a place and hour are reused.
+	CacheTTL time.Duration
+	// MaxWindKPH is the wind speed, or gust, from which spraying is
+	// discouraged.
+	MaxWindKPH int
+	// MinTemperatureC and MaxTemperatureC bound the temperatures most
+	// labels allow applications in.
+	MinTemperatureC int
+	MaxTemperatureC int
+	RequestTimeout  time.Duration
+}
+
+// ScheduleConfig controls job duration estimates and route feasibility checks.
+type ScheduleConfig struct {
+	DefaultJobDuration time.Duration // estimate when too few durations were observed
+	DriveTimePerStop   time.Duration // assumed travel between consecutive stops
+	ShiftStart         time.Duration // offset from midnight UTC when the day starts, without branch calendars
+	ShiftLength        time.Duration
+	MinSamples         int // observations needed before they replace the default
+	SampleWindow       int // most recent observations an estimate is based on
+}
+
+// ChemicalCatalogConfig controls the reference catalog chemical uploads
+// are checked against.
+type ChemicalCatalogConfig struct {
+	// Path is a CSV or JSON file of registered products; empty uses the
+	// built-in catalog.
+	Path string
+	// SearchLimit caps the products returned by a catalog search.
+	SearchLimit int
+}
+
+// UsageReportConfig controls the chemical usage reports.
+type UsageReportConfig struct {
+	// CountiesPath is a CSV file of zip,county rows placing job addresses
+	// in counties; empty reports usage by region only.
+	CountiesPath string
+}
+
+// FlagsConfig selects the feature flag provider screens and toggles are
+// evaluated with.
+type FlagsConfig struct {
+	Provider    string // none, env, file, ofrep
+	Values      string // comma-separated key=value flags (env)
+	File        string // flag definitions (file)
+	URL         string // flag service base URL (ofrep)
+	TokenSecret string // secret name holding the flag service's bearer token (ofrep)
+	// CacheTTL is how long a flag service's evaluation of a flag for a
+	// context is reused, and how long flags set through the admin API take
+	// to reach other instances.
+	CacheTTL       time.Duration
+	RequestTimeout time.Duration
+}
+
+// ReportConfig controls the PDF service reports of completed jobs.
+type ReportConfig struct {
+	// LayoutPath is a JSON layout replacing the built-in one.
+	LayoutPath     string
+	Renderer       string // builtin, remote
+	RendererURL    string // endpoint the layout and report are POSTed to (remote)
+	RequestTimeout time.Duration
+	// MaxPhotos is how many of a job's photos a report shows, oldest first.
+	MaxPhotos int
+}
+
+// PromotionConfig controls the bundles screen changes are promoted between
+// environments with.
+type PromotionConfig struct {
+	// SigningKeySecret is the secret name holding the HMAC key bundles are
+	// signed and verified with; every environment of a promotion chain
+	// shares it. Empty disables promotion.
+	SigningKeySecret string
+}
+
+// PlaybackConfig controls the records technicians' days are replayed from.
+type PlaybackConfig struct {
+	// Route, stop and job changes are kept for Retention, and location
+	// traces for LocationRetention; a zero LocationRetention collects no
+	// location. Both are pruned every PruneInterval.
+	Retention         time.Duration
+	LocationRetention time.Duration
+	PruneInterval     time.Duration
+	// MaxPoints bounds the locations a device reports at once.
+	MaxPoints int
+}
+
+// StreamConfig controls the server-sent event stream of route and job
+// changes.
+type StreamConfig struct {
+	// HeartbeatInterval is how often an idle stream sends a keepalive
+	// comment, so proxies and the app notice dead connections.
+	HeartbeatInterval time.Duration
+	// MaxDuration ends streams after a while so connections spread over
+	// instances; the app reconnects and resumes.
+	MaxDuration time.Duration
+	// Replay is how many recent events are kept for clients resuming with
+	// Last-Event-ID, and Buffer how many may queue for a slow client
+	// before it is told to resync.
+	Replay int
+	Buffer int
+	// MaxClients bounds the streams open on an instance.
+	MaxClients int
+}
+
+// ProfitConfig controls job profitability.
+type ProfitConfig struct {
+	// LaborRate is what a technician's hour costs, on site or driving,
+	// unless the technician has a rate of their own. VehicleRate is what
+	// an hour of driving costs besides labor.
+	LaborRate   float64
+	VehicleRate float64
+	Currency    string
+}
+
+// EventsConfig selects where domain events, such as uploaded jobs and
+// changed routes, are published.
+type EventsConfig struct {
+	// Driver is "memory" (in-process subscribers only) or "pubsub".
+	Driver string
+	// BufferSize is how many events may wait to be published before new
+	// ones are dropped; they are published in batches of up to BatchSize,
+	// at least every FlushInterval.
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+
+	Project        string
+	PubSubTopic    string
+	PubSubEmulator string // host:port of the Pub/Sub emulator, if any
+	RequestTimeout time.Duration
+}
+
+// AnomalyConfig controls screening of treatment quantities against the
+// norms of the same product, service type and property size.
+type AnomalyConfig struct {
+	Enabled bool
+	// Threshold is the robust z-score (from the median and median absolute
+	// deviation) past which a quantity is flagged for review.
+	Threshold float64
+	// MinSamples is how many past treatments a norm needs before
+	// quantities are screened against it.
+	MinSamples int
+	// Window is how far back past treatments count towards norms.
+	Window time.Duration
+	// CheckInterval is how often new treatments are screened; reports
+	// and the review queue also screen before reading.
+	CheckInterval time.Duration
+}
+
+// DedupeConfig controls detection of near-duplicate customers and jobs.
+type DedupeConfig struct {
+	Enabled bool
+	// Threshold is the similarity, from 0 to 1, of names and addresses
+	// past which two records are queued for review.
+	Threshold    float64
+	ScanInterval time.Duration
+}
+
+// TenancyConfig controls partitioning data between the pest control
+// companies sharing one backend.
+type TenancyConfig struct {
+	Enabled bool
+	// Header names the request header platform staff, whose tokens carry
+	// no tenant, select one with.
+	Header string
+	// Default is the tenant of requests bound to none, and owns records
+	// written before tenancy was enabled. Empty rejects such requests.
+	Default string
+}
+
+// StatusPageConfig controls the public status page.
+type StatusPageConfig struct {
+	// History is how far back the incident history goes.
+	History time.Duration
+	// MaxAge is how long clients and proxies may cache the page.
+	MaxAge time.Duration
+}
+
+// SigningConfig controls signed requests: writes carrying an HMAC of the
+// request, a timestamp and a nonce, so a captured request cannot be sent
+// again.
+type SigningConfig struct {
+	// KeySecret names the secret holding the HMAC key; empty disables
+	// signed requests.
+	KeySecret string
+	// Required refuses unsigned writes; otherwise only signed writes are
+	// checked.
+	Required bool
+	// Window is how far a request's timestamp may be from the server's
+	// clock, and how long its nonce is remembered after that.
+	Window time.Duration
+}
+
+// AccessConfig restricts where admin and sign-in requests may come from.
+// Tenants add restrictions of their own.
+type AccessConfig struct {
+	// AllowedIPs are the addresses and CIDR ranges allowed; empty allows
+	// every address.
+	AllowedIPs []string
+	// BlockedCountries are ISO 3166-1 alpha-2 codes of the countries
+	// refused.
+	BlockedCountries []string
+	// CountryHeader names the header a trusted proxy reports the client's
+	// country in, such as a load balancer custom header set to
+	// {client_region}.
+	CountryHeader string
+}
+
+// LockoutConfig slows down and then locks out credential guessing. Failed
+// authentications are counted per client IP, and per identity where the
+// endpoint knows one, over Window.
+type LockoutConfig struct {
+	Enabled bool
+	Window  time.Duration
+	// After DelayAfter failures each further attempt must wait BaseDelay,
+	// doubling with every failure up to MaxDelay.
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	// LockAfter failures lock the IP or identity out for LockFor.
+	LockAfter int
+	LockFor   time.Duration
+}
+
+// MessagingConfig controls dispatcher-technician messaging over WebSocket.
+type MessagingConfig struct {
+	// PingInterval is how often an idle socket is pinged; a socket silent
+	// for two intervals is closed.
+	PingInterval time.Duration
+	// MaxLength bounds a message body, in characters.
+	MaxLength int
+	// MaxClients bounds the sockets open on an instance.
+	MaxClients int
+	// Retention is how long messages are kept; unacknowledged messages are
+	// not delivered after it.
+	Retention     time.Duration
+	PruneInterval time.Duration
+}
+
+// ScreenConfig controls server-side rendering of SDUI screens.
+type ScreenConfig struct {
+	// UnresolvedPlaceholders is what happens to {{key}} placeholders the
+	// server has no value for: keep (left for the client), blank, or drop
+	// (the component is removed).
+	UnresolvedPlaceholders string
+	// MaxDepth is the deepest component nesting a template may have.
+	MaxDepth int
+	// ValidateResponses validates every rendered screen before it is served
+	// and fails the request when it is invalid. Meant for dev environments.
+	ValidateResponses bool
+	// LegacyUserIDCutoff is the date, as YYYY-MM-DD in UTC, from which
+	// unauthenticated screen requests naming their technician with ?userId=
+	// are rejected. Until then they are served with deprecation headers.
+	// Empty leaves the legacy form accepted with no end date.
+	LegacyUserIDCutoff string
+	// WatchMaxItems and WatchMaxText bound lists and text in screens
+	// simplified for watches that have no watch template.
+	WatchMaxItems int
+	WatchMaxText  int
+	// TranslationsDir holds the <locale>.json and <locale>.po catalogs
+	// textKey components and dates are rendered with. Empty renders every
+	// screen in the templates' own text.
+	TranslationsDir string
+	// DefaultLocale ends every locale's fallback chain.
+	DefaultLocale string
+	// RequiredLocales must have every textKey of a template translated
+	// before the template can be published.
+	RequiredLocales []string
+	// ComponentMinVersions sets the first app version that renders each
+	// component type, as comma-separated type=version pairs such as
+	// "chart=2.3, qrScanner=2.5". Templates can also set minAppVersion on
+	// a component or the whole screen.
+	ComponentMinVersions string
+	// UpdateFallback is the component, as JSON, shown in place of one the
+	// app is too old to render. "none" removes such components instead.
+	UpdateFallback string
+	// ComponentMigrations is a JSON file of renamed, deprecated and retired
+	// component types and fields; templates are rewritten by it as they
+	// are loaded. Empty migrates nothing.
+	ComponentMigrations string
+	// TemplateRefresh is how often a server checks the screen repository
+	// for templates published or deleted through another instance.
+	TemplateRefresh time.Duration
+}
+
+// DefaultUpdateFallback asks technicians on old app builds to update.
+const DefaultUpdateFallback = `{"type":"text","textKey":"update.required","text":"Update PestGenie to see everything on this screen.","font":"footnote","color":"secondary"}`
+
+// MinVersions parses ComponentMinVersions.
+func (c ScreenConfig) MinVersions() (map[string]string, error) {
+	versions := make(map[string]string)
+	for _, pair := range splitAndTrim(c.ComponentMinVersions) {
+		typ, version, ok := strings.Cut(pair, "=")
+		typ, version = strings.TrimSpace(typ), strings.TrimSpace(version)
+		if !ok || typ == "" {
+			return nil, fmt.Errorf("component min version %q is not type=version", pair)
+		}
+		if _, err := appversion.Parse(version); err != nil {
+			return nil, fmt.Errorf("invalid min app version for %s: %s", typ, version)
+		}
+		versions[typ] = version
+	}
+	return versions, nil
+}
+
+// InventoryConfig controls periodic truck stock counts.
+type InventoryConfig struct {
+	CountsEnabled        bool
+	CountInterval        time.Duration // how often each technician counts their truck
+	CountCheckInterval   time.Duration // how often due counts are generated
+	CountDueAfter        time.Duration // time a technician has to submit a count
+	CountVariancePercent int           // variances above this share of the expected quantity need approval
+}
+
+// CalibrationConfig controls application equipment calibration checks.
+type CalibrationConfig struct {
+	Interval time.Duration // how long a calibration stays current, unless the asset sets its own
+	DueSoon  time.Duration // warn this long before a calibration lapses
+	// RequiredMethods are application methods (lower case) refused on
+	// equipment whose calibration is overdue or was never recorded; they must
+	// name the equipment used.
+	RequiredMethods []string
+}
+
+// WorkerConfig controls background processing of uploaded sync records.
+type WorkerConfig struct {
+	Enabled      bool
+	Concurrency  int           // records processed at once
+	PollInterval time.Duration // how often pending uploads are scanned
+	BatchSize    int           // most records queued per scan
+	// Records queued but neither processed nor dead-lettered within
+	// RetryAfter, e.g. because an in-process queue was lost in a restart,
+	// are queued again.
+	RetryAfter time.Duration
+}
+
+// QueueConfig selects the job queue behind background work, such as upload
+// processing and long-running operations, and its retry policy.
+type QueueConfig struct {
+	// Driver is "memory" (in-process), "cloudtasks" or "pubsub"; the last
+	// two push jobs back to this service at PushURL.
+	Driver      string
+	BufferSize  int // most jobs the memory driver holds
+	MaxAttempts int // deliveries before a job is dead-lettered
+	// Retries wait MinBackoff, doubling per attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// PushURL is this service's base URL, which push drivers deliver jobs
+	// to; the push endpoint requires PushToken.
+	PushURL        string
+	PushToken      string
+	Project        string
+	RequestTimeout time.Duration
+
+	CloudTasksLocation string
+	CloudTasksQueue    string
+	CloudTasksEndpoint string // API host, overridable for tests
+
+	// PubSubTopic needs a push subscription to PushURL; its retry policy
+	// paces retries and delayed jobs.
+	PubSubTopic    string
+	PubSubEmulator string // host:port of the Pub/Sub emulator, if any
+}
+
+// ChaosConfig injects faults into repository and integration calls, to
+// exercise retries, dead-lettering and brownout end to end. It is refused in
+// prod.
+type ChaosConfig struct {
+	// Enabled injects the faults below into every call.
+	Enabled bool
+	// AllowHeader lets a request set faults for its own integration calls
+	// with the X-Chaos header.
+	AllowHeader bool
+	Latency     time.Duration // added before each call
+	Jitter      time.Duration // random extra latency, up to this
+	// ErrorPercent of calls fail without running; PartialPercent run but
+	// report failure, as when a write lands and its acknowledgement is lost.
+	ErrorPercent   int
+	PartialPercent int
+}
+
+// OperationConfig controls long-running operations such as manual exports
+// and feed polls.
+type OperationConfig struct {
+	// Timeout cancels operations still running after it; zero never does.
+	Timeout time.Duration
+	// Finished operations are kept for Retention, and pruned every
+	// PruneInterval.
+	Retention     time.Duration
+	PruneInterval time.Duration
+	// Concurrency bounds the operations this instance runs at once when
+	// the job queue delivers in-process.
+	Concurrency int
+}
+
+// CalendarConfig is the business calendar for branches without their own.
+type CalendarConfig struct {
+	TimeZone string   // IANA zone the hours are in
+	Open     string   // HH:MM
+	Close    string   // HH:MM
+	Days     []string // weekdays, e.g. mon,tue,wed,thu,fri
+}
+
+// AuthConfig controls technician authentication with bearer JWTs. Tokens are
+// verified against JWKSURL, or against HMACSecret in the local/dev signing
+// mode; with neither set, JWT authentication is off.
+type AuthConfig struct {
+	Issuer         string // required iss claim; empty accepts any issuer
+	Audience       string // required aud entry; empty accepts any audience
+	JWKSURL        string
+	JWKSRefresh    time.Duration
+	HMACSecret     string
+	RoleClaim      string // claim carrying the role(s); falls back to the technician profile
+	TenantClaim    string // claim carrying the tenant the token belongs to
+	Required       bool   // reject requests without any bearer credential
+	ClockSkew      time.Duration
+	RequestTimeout time.Duration
 }
 
 // Load reads configuration from environment variables with sane defaults.
@@ -79,6 +876,16 @@ func Load() (Config, error) {
 		IdleTimeout:    getDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		AllowedOrigins: splitAndTrim(getEnv("SERVER_ALLOWED_ORIGINS", "")),
 		EnableSwagger:  getBool("SERVER_ENABLE_SWAGGER", env == EnvLocal),
+
+		Compression:          getBool("SERVER_COMPRESSION", true),
+		CompressionMinBytes:  getInt("SERVER_COMPRESSION_MIN_BYTES", 1024),
+		CompressionLevel:     getInt("SERVER_COMPRESSION_LEVEL", -1),
+		Decompression:        getBool("SERVER_DECOMPRESSION", true),
+		MaxDecompressedBytes: int64(getInt("SERVER_MAX_DECOMPRESSED_BYTES", 32<<20)),
+		TrustedProxies:       splitAndTrim(getEnv("SERVER_TRUSTED_PROXIES", "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,169.254.0.0/16,fc00::/7")),
+	}
+	if len(server.TrustedProxies) == 1 && server.TrustedProxies[0] == "none" {
+		server.TrustedProxies = nil
 	}
 
 	telemetry := TelemetryConfig{
@@ -92,17 +899,447 @@ func Load() (Config, error) {
 		Provider:  strings.ToLower(getEnv("SECRETS_PROVIDER", "env")),
 		ProjectID: getEnv("SECRETS_PROJECT_ID", getEnv("GOOGLE_CLOUD_PROJECT", "")),
 		CacheTTL:  getDuration("SECRETS_CACHE_TTL", 5*time.Minute),
+
+		RefreshAhead: getDuration("SECRETS_REFRESH_AHEAD", 0),
 	}
 
 	datastore := DatastoreConfig{
 		Driver:            strings.ToLower(getEnv("DATASTORE_DRIVER", "memory")),
 		FirestoreProject:  getEnv("DATASTORE_FIRESTORE_PROJECT", secrets.ProjectID),
+		FirestoreDatabase: getEnv("DATASTORE_FIRESTORE_DATABASE", "(default)"),
 		FirestoreEmulator: getEnv("FIRESTORE_EMULATOR_HOST", ""),
+		RequestTimeout:    getDuration("DATASTORE_REQUEST_TIMEOUT", 10*time.Second),
+
+		PostgresURL:             getEnv("DATASTORE_POSTGRES_URL", ""),
+		PostgresMaxConns:        getInt("DATASTORE_POSTGRES_MAX_CONNS", 10),
+		PostgresMinConns:        getInt("DATASTORE_POSTGRES_MIN_CONNS", 0),
+		PostgresMaxConnLifetime: getDuration("DATASTORE_POSTGRES_MAX_CONN_LIFETIME", time.Hour),
+		PostgresMaxConnIdleTime: getDuration("DATASTORE_POSTGRES_MAX_CONN_IDLE_TIME", 30*time.Minute),
+		PostgresMigrate:         getBool("DATASTORE_POSTGRES_MIGRATE", false),
+
+		MemorySnapshotPath:     getEnv("DATASTORE_MEMORY_SNAPSHOT", ""),
+		MemorySnapshotInterval: getDuration("DATASTORE_MEMORY_SNAPSHOT_INTERVAL", 30*time.Second),
 	}
 
 	syncCfg := SyncConfig{
 		MaxRetries: getInt("SYNC_MAX_RETRIES", 5),
 		Backoff:    getDuration("SYNC_BACKOFF", time.Second*2),
+
+		DeviceStaleAfter:    getDuration("SYNC_DEVICE_STALE_AFTER", 90*24*time.Hour),
+		DevicePruneInterval: getDuration("SYNC_DEVICE_PRUNE_INTERVAL", 24*time.Hour),
+
+		UpdatesDefaultLimit: getInt("SYNC_UPDATES_DEFAULT_LIMIT", 0),
+		UpdatesMaxLimit:     getInt("SYNC_UPDATES_MAX_LIMIT", 1000),
+		BatchMaxItems:       getInt("SYNC_BATCH_MAX_ITEMS", 500),
+
+		TombstoneRetention:     getDuration("SYNC_TOMBSTONE_RETENTION", 30*24*time.Hour),
+		TombstonePruneInterval: getDuration("SYNC_TOMBSTONE_PRUNE_INTERVAL", 24*time.Hour),
+
+		ClientPollInterval: getDuration("SYNC_CLIENT_POLL_INTERVAL", time.Minute),
+		ClientBatchSize:    getInt("SYNC_CLIENT_BATCH_SIZE", 100),
+		ClientBackoff:      getDuration("SYNC_CLIENT_BACKOFF", 5*time.Second),
+	}
+
+	brownout := BrownoutConfig{
+		Enabled:           getBool("BROWNOUT_ENABLED", true),
+		P99Threshold:      getDuration("BROWNOUT_P99_THRESHOLD", 750*time.Millisecond),
+		RecoveryThreshold: getDuration("BROWNOUT_RECOVERY_THRESHOLD", 300*time.Millisecond),
+		Window:            getDuration("BROWNOUT_WINDOW", time.Minute),
+		MaxSamples:        getInt("BROWNOUT_MAX_SAMPLES", 500),
+		MinSamples:        getInt("BROWNOUT_MIN_SAMPLES", 20),
+		StaleTTL:          getDuration("BROWNOUT_STALE_TTL", 30*time.Minute),
+		DeferredQueueSize: getInt("BROWNOUT_DEFERRED_QUEUE_SIZE", 1000),
+		FlushInterval:     getDuration("BROWNOUT_FLUSH_INTERVAL", 5*time.Second),
+	}
+
+	export := ExportConfig{
+		Enabled:        getBool("EXPORT_ENABLED", true),
+		CheckInterval:  getDuration("EXPORT_CHECK_INTERVAL", time.Minute),
+		RequestTimeout: getDuration("EXPORT_REQUEST_TIMEOUT", 2*time.Minute),
+	}
+
+	outbound := OutboundConfig{
+		Enabled:       getBool("OUTBOUND_ENABLED", true),
+		CheckInterval: getDuration("OUTBOUND_CHECK_INTERVAL", time.Minute),
+		MaxAttempts:   getInt("OUTBOUND_MAX_ATTEMPTS", 3),
+		RetryBackoff:  getDuration("OUTBOUND_RETRY_BACKOFF", 30*time.Second),
+		DropDir:       getEnv("OUTBOUND_DROP_DIR", filepath.Join(os.TempDir(), "pestgenie-outbound")),
+	}
+
+	inbound := InboundConfig{
+		Enabled:      getBool("INBOUND_ENABLED", true),
+		PollInterval: getDuration("INBOUND_POLL_INTERVAL", 5*time.Minute),
+		DropDir:      getEnv("INBOUND_DROP_DIR", filepath.Join(os.TempDir(), "pestgenie-inbound")),
+		MaxRowErrors: getInt("INBOUND_MAX_ROW_ERRORS", 500),
+	}
+
+	connector := ConnectorConfig{
+		Enabled:        getBool("CONNECTOR_ENABLED", true),
+		QueueSize:      getInt("CONNECTOR_QUEUE_SIZE", 1000),
+		MaxAttempts:    getInt("CONNECTOR_MAX_ATTEMPTS", 3),
+		RetryBackoff:   getDuration("CONNECTOR_RETRY_BACKOFF", 2*time.Second),
+		MaxBackoff:     getDuration("CONNECTOR_MAX_BACKOFF", time.Minute),
+		RequestTimeout: getDuration("CONNECTOR_REQUEST_TIMEOUT", 10*time.Second),
+		Concurrency:    getInt("CONNECTOR_CONCURRENCY", 4),
+	}
+
+	apiTokens := APITokenConfig{
+		Required:         getBool("API_TOKENS_REQUIRED", false),
+		DefaultRateLimit: getInt("API_TOKENS_DEFAULT_RATE_LIMIT", 600),
+	}
+
+	rateLimit := RateLimitConfig{
+		Enabled:        getBool("RATE_LIMIT_ENABLED", true),
+		ReadPerMinute:  getInt("RATE_LIMIT_READ_PER_MINUTE", 300),
+		ReadBurst:      getInt("RATE_LIMIT_READ_BURST", 60),
+		WritePerMinute: getInt("RATE_LIMIT_WRITE_PER_MINUTE", 120),
+		WriteBurst:     getInt("RATE_LIMIT_WRITE_BURST", 60),
+	}
+
+	activity := ActivityConfig{
+		GroupWindow: getDuration("ACTIVITY_GROUP_WINDOW", 15*time.Minute),
+		MaxEvents:   getInt("ACTIVITY_MAX_EVENTS", 10000),
+		AvatarURL:   getEnv("ACTIVITY_AVATAR_URL", "https://www.gravatar.com/avatar/{hash}?d=identicon&s=64"),
+	}
+
+	notify := NotifyConfig{
+		DigestWindows: getEnv("NOTIFY_DIGEST_WINDOWS", "route=2m, job=1m, inventory=10m"),
+		DefaultWindow: getDuration("NOTIFY_DEFAULT_WINDOW", 0),
+	}
+
+	live := LiveActivityConfig{
+		StaleAfter:   getDuration("LIVE_ACTIVITY_STALE_AFTER", 30*time.Minute),
+		DismissAfter: getDuration("LIVE_ACTIVITY_DISMISS_AFTER", 15*time.Minute),
+		TokenTTL:     getDuration("LIVE_ACTIVITY_TOKEN_TTL", 12*time.Hour),
+	}
+
+	widget := WidgetConfig{
+		MaxStops:   getInt("WIDGET_MAX_STOPS", 3),
+		MinRefresh: getDuration("WIDGET_MIN_REFRESH", 15*time.Minute),
+		MaxRefresh: getDuration("WIDGET_MAX_REFRESH", 2*time.Hour),
+	}
+
+	carPlay := CarPlayConfig{
+		MaxStops: getInt("CARPLAY_MAX_STOPS", 12),
+		MaxText:  getInt("CARPLAY_MAX_TEXT", 40),
+	}
+
+	intents := IntentsConfig{
+		Days:     getInt("INTENTS_VOCABULARY_DAYS", 7),
+		MaxTerms: getInt("INTENTS_MAX_TERMS", 100),
+	}
+
+	impersonate := ImpersonationConfig{
+		DefaultTTL: getDuration("IMPERSONATION_DEFAULT_TTL", 15*time.Minute),
+		MaxTTL:     getDuration("IMPERSONATION_MAX_TTL", time.Hour),
+	}
+
+	eta := ETAConfig{
+		PublicBaseURL: strings.TrimSuffix(getEnv("ETA_PUBLIC_BASE_URL", "http://localhost:8080"), "/"),
+		StopDuration:  getDuration("ETA_STOP_DURATION", 45*time.Minute),
+		ExpiryGrace:   getDuration("ETA_EXPIRY_GRACE", 2*time.Hour),
+	}
+
+	voiceNotes := VoiceNoteConfig{
+		MaxBytes:       int64(getInt("VOICE_NOTES_MAX_BYTES", 10<<20)),
+		Transcriber:    strings.ToLower(getEnv("VOICE_NOTES_TRANSCRIBER", "none")),
+		TranscriberURL: getEnv("VOICE_NOTES_TRANSCRIBER_URL", ""),
+		TranscriberKey: getEnv("VOICE_NOTES_TRANSCRIBER_KEY_SECRET", ""),
+		RequestTimeout: getDuration("VOICE_NOTES_REQUEST_TIMEOUT", time.Minute),
+		QueueSize:      getInt("VOICE_NOTES_QUEUE_SIZE", 100),
+	}
+
+	photos := PhotoConfig{
+		Storage:        strings.ToLower(getEnv("PHOTOS_STORAGE", "local")),
+		LocalDir:       getEnv("PHOTOS_LOCAL_DIR", filepath.Join(os.TempDir(), "pestgenie-photos")),
+		Bucket:         getEnv("PHOTOS_GCS_BUCKET", ""),
+		MaxBytes:       int64(getInt("PHOTOS_MAX_BYTES", 15<<20)),
+		AllowedTypes:   splitAndTrim(getEnv("PHOTOS_ALLOWED_TYPES", "image/jpeg,image/png,image/heic,image/heif")),
+		URLTTL:         getDuration("PHOTOS_URL_TTL", 15*time.Minute),
+		PublicBaseURL:  strings.TrimSuffix(getEnv("PHOTOS_PUBLIC_BASE_URL", "http://localhost:8080"), "/"),
+		SigningKey:     getEnv("PHOTOS_URL_SIGNING_KEY", ""),
+		RequestTimeout: getDuration("PHOTOS_REQUEST_TIMEOUT", 2*time.Minute),
+	}
+
+	assets := AssetConfig{
+		CDNBaseURL:    strings.TrimSuffix(getEnv("ASSET_CDN_BASE_URL", ""), "/"),
+		PublicBaseURL: photos.PublicBaseURL,
+		MaxBytes:      int64(getInt("ASSET_MAX_BYTES", 2<<20)),
+		AllowedTypes:  splitAndTrim(getEnv("ASSET_ALLOWED_TYPES", "image/png,image/jpeg,image/webp,image/svg+xml")),
+		GCInterval:    getDuration("ASSET_GC_INTERVAL", 24*time.Hour),
+		GCGrace:       getDuration("ASSET_GC_GRACE", 7*24*time.Hour),
+	}
+
+	servicePlan := ServicePlanConfig{
+		ScheduleTolerance: getDuration("SERVICE_PLANS_SCHEDULE_TOLERANCE", 7*24*time.Hour),
+		MissedGrace:       getDuration("SERVICE_PLANS_MISSED_GRACE", 3*24*time.Hour),
+	}
+
+	schedule := ScheduleConfig{
+		DefaultJobDuration: getDuration("SCHEDULE_DEFAULT_JOB_DURATION", 45*time.Minute),
+		DriveTimePerStop:   getDuration("SCHEDULE_DRIVE_TIME_PER_STOP", 20*time.Minute),
+		ShiftStart:         getDuration("SCHEDULE_SHIFT_START", 8*time.Hour),
+		ShiftLength:        getDuration("SCHEDULE_SHIFT_LENGTH", 8*time.Hour),
+		MinSamples:         getInt("SCHEDULE_MIN_SAMPLES", 3),
+		SampleWindow:       getInt("SCHEDULE_SAMPLE_WINDOW", 50),
+	}
+
+	routing := RoutingConfig{
+		Provider:       strings.ToLower(getEnv("ROUTING_DISTANCE_PROVIDER", "haversine")),
+		URL:            getEnv("ROUTING_DISTANCE_URL", ""),
+		KeySecret:      getEnv("ROUTING_DISTANCE_KEY_SECRET", ""),
+		SpeedKPH:       getInt("ROUTING_SPEED_KPH", 40),
+		MaxStops:       getInt("ROUTING_MAX_STOPS", 25),
+		ShiftStart:     schedule.ShiftStart,
+		FallbackLeg:    schedule.DriveTimePerStop,
+		RequestTimeout: getDuration("ROUTING_REQUEST_TIMEOUT", 10*time.Second),
+	}
+
+	geocoding := GeocodingConfig{
+		Provider:       strings.ToLower(getEnv("GEOCODING_PROVIDER", "none")),
+		URL:            getEnv("GEOCODING_URL", ""),
+		KeySecret:      getEnv("GEOCODING_KEY_SECRET", ""),
+		RequestTimeout: getDuration("GEOCODING_REQUEST_TIMEOUT", 10*time.Second),
+	}
+
+	address := AddressConfig{
+		Provider:       strings.ToLower(getEnv("ADDRESS_PROVIDER", "none")),
+		URL:            getEnv("ADDRESS_URL", ""),
+		KeySecret:      getEnv("ADDRESS_KEY_SECRET", ""),
+		ClientIDSecret: getEnv("ADDRESS_CLIENT_ID_SECRET", ""),
+		CacheTTL:       getDuration("ADDRESS_CACHE_TTL", 30*24*time.Hour),
+		RequestTimeout: getDuration("ADDRESS_REQUEST_TIMEOUT", 10*time.Second),
+	}
+
+	weather := WeatherConfig{
+		Provider:        strings.ToLower(getEnv("WEATHER_PROVIDER", "none")),
+		URL:             getEnv("WEATHER_URL", ""),
+		KeySecret:       getEnv("WEATHER_KEY_SECRET", ""),
+		CacheTTL:        getDuration("WEATHER_CACHE_TTL", 30*time.Minute),
+		MaxWindKPH:      getInt("WEATHER_MAX_WIND_KPH", 16),
+		MinTemperatureC: getInt("WEATHER_MIN_TEMPERATURE_C", 4),
+		MaxTemperatureC: getInt("WEATHER_MAX_TEMPERATURE_C", 32),
+		RequestTimeout:  getDuration("WEATHER_REQUEST_TIMEOUT", 10*time.Second),
+	}
+
+	chemicals := ChemicalCatalogConfig{
+		Path:        getEnv("CHEMICAL_CATALOG_PATH", ""),
+		SearchLimit: getInt("CHEMICAL_CATALOG_SEARCH_LIMIT", 20),
+	}
+
+	usage := UsageReportConfig{
+		CountiesPath: getEnv("USAGE_REPORT_COUNTIES_PATH", ""),
+	}
+
+	flags := FlagsConfig{
+		Provider:       strings.ToLower(getEnv("FLAGS_PROVIDER", "none")),
+		Values:         getEnv("FLAGS_VALUES", ""),
+		File:           getEnv("FLAGS_FILE", ""),
+		URL:            getEnv("FLAGS_OFREP_URL", ""),
+		TokenSecret:    getEnv("FLAGS_OFREP_TOKEN_SECRET", ""),
+		CacheTTL:       getDuration("FLAGS_CACHE_TTL", 30*time.Second),
+		RequestTimeout: getDuration("FLAGS_REQUEST_TIMEOUT", 2*time.Second),
+	}
+
+	reports := ReportConfig{
+		LayoutPath:     getEnv("REPORT_LAYOUT_PATH", ""),
+		Renderer:       strings.ToLower(getEnv("REPORT_RENDERER", "builtin")),
+		RendererURL:    getEnv("REPORT_RENDERER_URL", ""),
+		RequestTimeout: getDuration("REPORT_RENDERER_TIMEOUT", 30*time.Second),
+		MaxPhotos:      getInt("REPORT_MAX_PHOTOS", 12),
+	}
+
+	playback := PlaybackConfig{
+		Retention:         getDuration("PLAYBACK_RETENTION", 90*24*time.Hour),
+		LocationRetention: getDuration("PLAYBACK_LOCATION_RETENTION", 30*24*time.Hour),
+		PruneInterval:     getDuration("PLAYBACK_PRUNE_INTERVAL", time.Hour),
+		MaxPoints:         getInt("PLAYBACK_MAX_POINTS", 500),
+	}
+
+	stream := StreamConfig{
+		HeartbeatInterval: getDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second),
+		MaxDuration:       getDuration("STREAM_MAX_DURATION", 30*time.Minute),
+		Replay:            getInt("STREAM_REPLAY", 1000),
+		Buffer:            getInt("STREAM_BUFFER", 64),
+		MaxClients:        getInt("STREAM_MAX_CLIENTS", 5000),
+	}
+
+	profit := ProfitConfig{
+		LaborRate:   getFloat("PROFIT_LABOR_RATE", 30),
+		VehicleRate: getFloat("PROFIT_VEHICLE_RATE", 12),
+		Currency:    getEnv("PROFIT_CURRENCY", "USD"),
+	}
+
+	messaging := MessagingConfig{
+		PingInterval:  getDuration("MESSAGING_PING_INTERVAL", 30*time.Second),
+		MaxLength:     getInt("MESSAGING_MAX_LENGTH", 2000),
+		MaxClients:    getInt("MESSAGING_MAX_CLIENTS", 5000),
+		Retention:     getDuration("MESSAGING_RETENTION", 30*24*time.Hour),
+		PruneInterval: getDuration("MESSAGING_PRUNE_INTERVAL", time.Hour),
+	}
+
+	events := EventsConfig{
+		Driver:         getEnv("EVENTS_DRIVER", "memory"),
+		BufferSize:     getInt("EVENTS_BUFFER_SIZE", 10000),
+		BatchSize:      getInt("EVENTS_BATCH_SIZE", 100),
+		FlushInterval:  getDuration("EVENTS_FLUSH_INTERVAL", time.Second),
+		Project:        getEnv("EVENTS_PROJECT", secrets.ProjectID),
+		PubSubTopic:    getEnv("EVENTS_PUBSUB_TOPIC", "pestgenie-events"),
+		PubSubEmulator: getEnv("PUBSUB_EMULATOR_HOST", ""),
+		RequestTimeout: getDuration("EVENTS_REQUEST_TIMEOUT", 10*time.Second),
+	}
+
+	anomaly := AnomalyConfig{
+		Enabled:       getBool("ANOMALY_ENABLED", true),
+		Threshold:     getFloat("ANOMALY_THRESHOLD", 3.5),
+		MinSamples:    getInt("ANOMALY_MIN_SAMPLES", 20),
+		Window:        getDuration("ANOMALY_WINDOW", 365*24*time.Hour),
+		CheckInterval: getDuration("ANOMALY_CHECK_INTERVAL", 5*time.Minute),
+	}
+
+	dedupe := DedupeConfig{
+		Enabled:      getBool("DEDUPE_ENABLED", true),
+		Threshold:    getFloat("DEDUPE_THRESHOLD", 0.9),
+		ScanInterval: getDuration("DEDUPE_SCAN_INTERVAL", time.Hour),
+	}
+
+	tenancy := TenancyConfig{
+		Enabled: getBool("TENANCY_ENABLED", false),
+		Header:  getEnv("TENANT_HEADER", "X-Tenant-ID"),
+		Default: getEnv("TENANT_DEFAULT", ""),
+	}
+
+	statusPage := StatusPageConfig{
+		History: getDuration("STATUS_HISTORY", 90*24*time.Hour),
+		MaxAge:  getDuration("STATUS_MAX_AGE", 30*time.Second),
+	}
+
+	signing := SigningConfig{
+		KeySecret: getEnv("REQUEST_SIGNING_KEY_SECRET", ""),
+		Required:  getBool("REQUEST_SIGNING_REQUIRED", false),
+		Window:    getDuration("REQUEST_SIGNING_WINDOW", 5*time.Minute),
+	}
+
+	access := AccessConfig{
+		AllowedIPs:       splitAndTrim(getEnv("ACCESS_ALLOWED_IPS", "")),
+		BlockedCountries: splitAndTrim(getEnv("ACCESS_BLOCKED_COUNTRIES", "")),
+		CountryHeader:    getEnv("ACCESS_COUNTRY_HEADER", "X-Client-Region"),
+	}
+
+	lockout := LockoutConfig{
+		Enabled:    getBool("AUTH_LOCKOUT_ENABLED", true),
+		Window:     getDuration("AUTH_LOCKOUT_WINDOW", 15*time.Minute),
+		DelayAfter: getInt("AUTH_LOCKOUT_DELAY_AFTER", 10),
+		BaseDelay:  getDuration("AUTH_LOCKOUT_BASE_DELAY", time.Second),
+		MaxDelay:   getDuration("AUTH_LOCKOUT_MAX_DELAY", 30*time.Second),
+		LockAfter:  getInt("AUTH_LOCKOUT_LOCK_AFTER", 50),
+		LockFor:    getDuration("AUTH_LOCKOUT_LOCK_FOR", 15*time.Minute),
+	}
+
+	promotion := PromotionConfig{
+		SigningKeySecret: getEnv("PROMOTION_SIGNING_KEY_SECRET", ""),
+	}
+
+	screens := ScreenConfig{
+		UnresolvedPlaceholders: strings.ToLower(getEnv("SDUI_UNRESOLVED_PLACEHOLDERS", "keep")),
+		MaxDepth:               getInt("SDUI_MAX_DEPTH", 32),
+		ValidateResponses:      getBool("SDUI_VALIDATE_RESPONSES", false),
+		LegacyUserIDCutoff:     getEnv("SDUI_LEGACY_USERID_CUTOFF", ""),
+		WatchMaxItems:          getInt("SDUI_WATCH_MAX_ITEMS", 5),
+		WatchMaxText:           getInt("SDUI_WATCH_MAX_TEXT", 80),
+		TranslationsDir:        getEnv("SDUI_TRANSLATIONS_DIR", ""),
+		DefaultLocale:          getEnv("SDUI_DEFAULT_LOCALE", "en"),
+		RequiredLocales:        splitAndTrim(getEnv("SDUI_REQUIRED_LOCALES", "")),
+		ComponentMinVersions:   getEnv("SDUI_COMPONENT_MIN_VERSIONS", ""),
+		UpdateFallback:         getEnv("SDUI_UPDATE_FALLBACK", DefaultUpdateFallback),
+		ComponentMigrations:    getEnv("SDUI_COMPONENT_MIGRATIONS", ""),
+		TemplateRefresh:        getDuration("SDUI_TEMPLATE_REFRESH", 10*time.Second),
+	}
+
+	translation := TranslationConfig{
+		Provider:       strings.ToLower(getEnv("TRANSLATION_PROVIDER", "none")),
+		URL:            getEnv("TRANSLATION_URL", ""),
+		KeySecret:      getEnv("TRANSLATION_KEY_SECRET", ""),
+		SourceLocale:   screens.DefaultLocale,
+		RequestTimeout: getDuration("TRANSLATION_REQUEST_TIMEOUT", 30*time.Second),
+	}
+
+	calendar := CalendarConfig{
+		TimeZone: getEnv("BUSINESS_HOURS_TIME_ZONE", "UTC"),
+		Open:     getEnv("BUSINESS_HOURS_OPEN", "08:00"),
+		Close:    getEnv("BUSINESS_HOURS_CLOSE", "17:00"),
+		Days:     splitAndTrim(strings.ToLower(getEnv("BUSINESS_DAYS", "mon,tue,wed,thu,fri"))),
+	}
+
+	inventory := InventoryConfig{
+		CountsEnabled:        getBool("INVENTORY_COUNTS_ENABLED", true),
+		CountInterval:        getDuration("INVENTORY_COUNT_INTERVAL", 7*24*time.Hour),
+		CountCheckInterval:   getDuration("INVENTORY_COUNT_CHECK_INTERVAL", time.Hour),
+		CountDueAfter:        getDuration("INVENTORY_COUNT_DUE_AFTER", 48*time.Hour),
+		CountVariancePercent: getInt("INVENTORY_COUNT_VARIANCE_PERCENT", 5),
+	}
+
+	calibration := CalibrationConfig{
+		Interval:        getDuration("CALIBRATION_INTERVAL", 90*24*time.Hour),
+		DueSoon:         getDuration("CALIBRATION_DUE_SOON", 14*24*time.Hour),
+		RequiredMethods: splitAndTrim(strings.ToLower(getEnv("CALIBRATION_REQUIRED_METHODS", ""))),
+	}
+
+	worker := WorkerConfig{
+		Enabled:      getBool("WORKER_ENABLED", true),
+		Concurrency:  getInt("WORKER_CONCURRENCY", 4),
+		PollInterval: getDuration("WORKER_POLL_INTERVAL", 30*time.Second),
+		BatchSize:    getInt("WORKER_BATCH_SIZE", 500),
+		RetryAfter:   getDuration("WORKER_RETRY_AFTER", 30*time.Minute),
+	}
+
+	queue := QueueConfig{
+		Driver:      strings.ToLower(getEnv("QUEUE_DRIVER", "memory")),
+		BufferSize:  getInt("QUEUE_BUFFER_SIZE", 10000),
+		MaxAttempts: getInt("QUEUE_MAX_ATTEMPTS", 5),
+		MinBackoff:  getDuration("QUEUE_MIN_BACKOFF", 10*time.Second),
+		MaxBackoff:  getDuration("QUEUE_MAX_BACKOFF", 10*time.Minute),
+
+		PushURL:        strings.TrimSuffix(getEnv("QUEUE_PUSH_URL", ""), "/"),
+		PushToken:      getEnv("QUEUE_PUSH_TOKEN", ""),
+		Project:        getEnv("QUEUE_PROJECT", secrets.ProjectID),
+		RequestTimeout: getDuration("QUEUE_REQUEST_TIMEOUT", 30*time.Second),
+
+		CloudTasksLocation: getEnv("QUEUE_CLOUDTASKS_LOCATION", "us-central1"),
+		CloudTasksQueue:    getEnv("QUEUE_CLOUDTASKS_QUEUE", "pestgenie-jobs"),
+		CloudTasksEndpoint: getEnv("QUEUE_CLOUDTASKS_ENDPOINT", "https://cloudtasks.googleapis.com"),
+
+		PubSubTopic:    getEnv("QUEUE_PUBSUB_TOPIC", "pestgenie-jobs"),
+		PubSubEmulator: getEnv("PUBSUB_EMULATOR_HOST", ""),
+	}
+
+	chaos := ChaosConfig{
+		Enabled:        getBool("CHAOS_ENABLED", false),
+		AllowHeader:    getBool("CHAOS_ALLOW_HEADER", false),
+		Latency:        getDuration("CHAOS_LATENCY", 0),
+		Jitter:         getDuration("CHAOS_JITTER", 0),
+		ErrorPercent:   getInt("CHAOS_ERROR_PERCENT", 0),
+		PartialPercent: getInt("CHAOS_PARTIAL_PERCENT", 0),
+	}
+
+	operations := OperationConfig{
+		Timeout:       getDuration("OPERATION_TIMEOUT", 30*time.Minute),
+		Retention:     getDuration("OPERATION_RETENTION", 7*24*time.Hour),
+		PruneInterval: getDuration("OPERATION_PRUNE_INTERVAL", time.Hour),
+		Concurrency:   getInt("OPERATION_CONCURRENCY", 4),
+	}
+
+	auth := AuthConfig{
+		Issuer:         getEnv("AUTH_JWT_ISSUER", ""),
+		Audience:       getEnv("AUTH_JWT_AUDIENCE", ""),
+		JWKSURL:        getEnv("AUTH_JWKS_URL", ""),
+		JWKSRefresh:    getDuration("AUTH_JWKS_REFRESH", time.Hour),
+		HMACSecret:     getEnv("AUTH_JWT_HMAC_SECRET", ""),
+		RoleClaim:      getEnv("AUTH_JWT_ROLE_CLAIM", "role"),
+		TenantClaim:    getEnv("AUTH_JWT_TENANT_CLAIM", "tenant_id"),
+		Required:       getBool("AUTH_REQUIRED", false),
+		ClockSkew:      getDuration("AUTH_CLOCK_SKEW", time.Minute),
+		RequestTimeout: getDuration("AUTH_JWKS_REQUEST_TIMEOUT", 5*time.Second),
 	}
 
 	cfg := Config{
@@ -112,6 +1349,57 @@ func Load() (Config, error) {
 		Secrets:     secrets,
 		Datastore:   datastore,
 		Sync:        syncCfg,
+		Brownout:    brownout,
+		Export:      export,
+		Outbound:    outbound,
+		Inbound:     inbound,
+		Connector:   connector,
+		APITokens:   apiTokens,
+		Impersonate: impersonate,
+		ETA:         eta,
+		VoiceNotes:  voiceNotes,
+		Photos:      photos,
+		Assets:      assets,
+		ServicePlan: servicePlan,
+		Schedule:    schedule,
+		Screens:     screens,
+		Calendar:    calendar,
+		Auth:        auth,
+		Inventory:   inventory,
+		Calibration: calibration,
+		Worker:      worker,
+		Operations:  operations,
+		Queue:       queue,
+		Chaos:       chaos,
+		RateLimit:   rateLimit,
+		Activity:    activity,
+		Notify:      notify,
+		Live:        live,
+		Widget:      widget,
+		CarPlay:     carPlay,
+		Intents:     intents,
+		Translation: translation,
+		Routing:     routing,
+		Geocoding:   geocoding,
+		Weather:     weather,
+		Chemicals:   chemicals,
+		Usage:       usage,
+		Flags:       flags,
+		Reports:     reports,
+		Promotion:   promotion,
+		Playback:    playback,
+		Stream:      stream,
+		Profit:      profit,
+		Messaging:   messaging,
+		Events:      events,
+		Anomaly:     anomaly,
+		Dedupe:      dedupe,
+		Tenancy:     tenancy,
+		StatusPage:  statusPage,
+		Signing:     signing,
+		Access:      access,
+		Lockout:     lockout,
+		Address:     address,
 	}
 
 	return cfg, cfg.validate()
@@ -121,15 +1409,482 @@ func (c Config) validate() error {
 	if c.Secrets.Provider != "env" && c.Secrets.Provider != "gcp" {
 		return fmt.Errorf("invalid secrets provider: %s", c.Secrets.Provider)
 	}
-	if c.Datastore.Driver != "memory" && c.Datastore.Driver != "firestore" {
+	if c.Server.CompressionMinBytes < 0 {
+		return fmt.Errorf("server compression min bytes must be >= 0")
+	}
+	if c.Server.CompressionLevel < -1 || c.Server.CompressionLevel == 0 || c.Server.CompressionLevel > 9 {
+		return fmt.Errorf("server compression level must be -1 or between 1 and 9")
+	}
+	if c.Server.MaxDecompressedBytes <= 0 {
+		return fmt.Errorf("server max decompressed bytes must be > 0")
+	}
+	if c.Secrets.RefreshAhead < 0 || (c.Secrets.RefreshAhead > 0 && c.Secrets.RefreshAhead >= c.Secrets.CacheTTL) {
+		return fmt.Errorf("secrets refresh ahead must be >= 0 and shorter than the cache ttl")
+	}
+	switch c.Datastore.Driver {
+	case "memory", "firestore":
+	case "postgres":
+		if c.Datastore.PostgresURL == "" {
+			return fmt.Errorf("postgres datastore requires DATASTORE_POSTGRES_URL")
+		}
+		if c.Datastore.PostgresMaxConns <= 0 || c.Datastore.PostgresMinConns < 0 || c.Datastore.PostgresMinConns > c.Datastore.PostgresMaxConns {
+			return fmt.Errorf("postgres connections must satisfy 0 <= min <= max and max > 0")
+		}
+	default:
 		return fmt.Errorf("invalid datastore driver: %s", c.Datastore.Driver)
 	}
+	if c.Datastore.MemorySnapshotPath != "" {
+		if c.Datastore.Driver != "memory" {
+			return fmt.Errorf("memory store snapshots need the memory datastore driver")
+		}
+		if c.Environment == EnvProd {
+			return fmt.Errorf("memory store snapshots are not allowed in prod")
+		}
+		if c.Datastore.MemorySnapshotInterval <= 0 {
+			return fmt.Errorf("memory snapshot interval must be > 0")
+		}
+	}
 	if c.Sync.MaxRetries < 0 {
 		return fmt.Errorf("sync max retries must be >= 0")
 	}
 	if c.Sync.Backoff < 0 {
 		return fmt.Errorf("sync backoff must be >= 0")
 	}
+	if c.Sync.DeviceStaleAfter < 0 || (c.Sync.DeviceStaleAfter > 0 && c.Sync.DevicePruneInterval <= 0) {
+		return fmt.Errorf("device pruning needs a stale age >= 0 and a positive interval")
+	}
+	if c.Sync.UpdatesMaxLimit <= 0 || c.Sync.UpdatesDefaultLimit < 0 || c.Sync.UpdatesDefaultLimit > c.Sync.UpdatesMaxLimit {
+		return fmt.Errorf("sync updates limits need 0 <= default <= max and a positive max")
+	}
+	if c.Sync.BatchMaxItems <= 0 {
+		return fmt.Errorf("sync batch max items must be > 0")
+	}
+	if c.Sync.TombstoneRetention < 0 || (c.Sync.TombstoneRetention > 0 && c.Sync.TombstonePruneInterval <= 0) {
+		return fmt.Errorf("tombstone pruning needs a retention >= 0 and a positive interval")
+	}
+	if c.Sync.ClientPollInterval < time.Second || c.Sync.ClientBackoff < time.Second {
+		return fmt.Errorf("sync client poll interval and backoff must be at least 1s")
+	}
+	if c.Sync.ClientBatchSize <= 0 || c.Sync.ClientBatchSize > c.Sync.BatchMaxItems {
+		return fmt.Errorf("sync client batch size must be between 1 and the batch max items")
+	}
+	if c.Outbound.MaxAttempts <= 0 {
+		return fmt.Errorf("outbound max attempts must be > 0")
+	}
+	if c.Connector.MaxAttempts <= 0 || c.Connector.QueueSize <= 0 || c.Connector.Concurrency <= 0 {
+		return fmt.Errorf("connector max attempts, queue size and concurrency must be > 0")
+	}
+	if c.Connector.MaxBackoff < c.Connector.RetryBackoff {
+		return fmt.Errorf("connector max backoff must be at least the retry backoff")
+	}
+	if c.APITokens.DefaultRateLimit <= 0 {
+		return fmt.Errorf("api token default rate limit must be > 0")
+	}
+	if c.RateLimit.Enabled && (c.RateLimit.ReadPerMinute <= 0 || c.RateLimit.ReadBurst <= 0 || c.RateLimit.WritePerMinute <= 0 || c.RateLimit.WriteBurst <= 0) {
+		return fmt.Errorf("rate limit rates and bursts must be > 0")
+	}
+	if c.Activity.GroupWindow < 0 || c.Activity.MaxEvents <= 0 {
+		return fmt.Errorf("activity group window must be >= 0 and max events > 0")
+	}
+	if _, err := c.Notify.Windows(); err != nil {
+		return err
+	}
+	if c.Notify.DefaultWindow < 0 {
+		return fmt.Errorf("notification default window must be >= 0")
+	}
+	if c.Live.StaleAfter < 0 || c.Live.DismissAfter < 0 || c.Live.TokenTTL <= 0 {
+		return fmt.Errorf("live activity stale and dismiss delays must be >= 0 and token ttl > 0")
+	}
+	if c.Widget.MaxStops <= 0 {
+		return fmt.Errorf("widget max stops must be > 0")
+	}
+	if c.Widget.MinRefresh <= 0 || c.Widget.MaxRefresh < c.Widget.MinRefresh {
+		return fmt.Errorf("widget refresh must satisfy 0 < min <= max")
+	}
+	if c.CarPlay.MaxStops <= 0 || c.CarPlay.MaxText <= 0 {
+		return fmt.Errorf("carplay stop and text limits must be > 0")
+	}
+	if c.Intents.Days <= 0 || c.Intents.MaxTerms <= 0 {
+		return fmt.Errorf("intents vocabulary days and max terms must be > 0")
+	}
+	if c.Impersonate.DefaultTTL <= 0 || c.Impersonate.MaxTTL < c.Impersonate.DefaultTTL {
+		return fmt.Errorf("impersonation ttl must satisfy 0 < default <= max")
+	}
+	if c.VoiceNotes.MaxBytes <= 0 || c.VoiceNotes.QueueSize <= 0 {
+		return fmt.Errorf("voice note max bytes and queue size must be > 0")
+	}
+	switch c.VoiceNotes.Transcriber {
+	case "none":
+	case "http":
+		if c.VoiceNotes.TranscriberURL == "" {
+			return fmt.Errorf("voice note transcriber url is required for the http transcriber")
+		}
+	default:
+		return fmt.Errorf("invalid voice note transcriber: %s", c.VoiceNotes.Transcriber)
+	}
+	switch c.Translation.Provider {
+	case "none":
+	case "deepl", "google":
+		if c.Translation.KeySecret == "" {
+			return fmt.Errorf("translation key secret is required for the %s provider", c.Translation.Provider)
+		}
+	default:
+		return fmt.Errorf("invalid translation provider: %s", c.Translation.Provider)
+	}
+	switch c.Routing.Provider {
+	case "haversine":
+		if c.Routing.SpeedKPH <= 0 {
+			return fmt.Errorf("routing speed must be > 0")
+		}
+	case "google":
+		if c.Routing.KeySecret == "" {
+			return fmt.Errorf("routing distance key secret is required for the google provider")
+		}
+	default:
+		return fmt.Errorf("invalid routing distance provider: %s", c.Routing.Provider)
+	}
+	if c.Routing.MaxStops <= 0 {
+		return fmt.Errorf("routing max stops must be > 0")
+	}
+	switch c.Geocoding.Provider {
+	case "none", "nominatim":
+	case "google":
+		if c.Geocoding.KeySecret == "" {
+			return fmt.Errorf("geocoding key secret is required for the google provider")
+		}
+	default:
+		return fmt.Errorf("invalid geocoding provider: %s", c.Geocoding.Provider)
+	}
+	switch c.Weather.Provider {
+	case "none", "nws":
+	case "openweather":
+		if c.Weather.KeySecret == "" {
+			return fmt.Errorf("weather key secret is required for the openweather provider")
+		}
+	default:
+		return fmt.Errorf("invalid weather provider: %s", c.Weather.Provider)
+	}
+	if c.Weather.MaxWindKPH <= 0 {
+		return fmt.Errorf("weather max wind must be > 0")
+	}
+	if c.Weather.MinTemperatureC >= c.Weather.MaxTemperatureC {
+		return fmt.Errorf("weather min temperature must be below the max temperature")
+	}
+	switch c.Flags.Provider {
+	case "none":
+	case "env":
+		if c.Flags.Values == "" {
+			return fmt.Errorf("flags values are required for the env provider")
+		}
+	case "file":
+		if c.Flags.File == "" {
+			return fmt.Errorf("flags file is required for the file provider")
+		}
+	case "ofrep":
+		if c.Flags.URL == "" {
+			return fmt.Errorf("flags ofrep url is required for the ofrep provider")
+		}
+	default:
+		return fmt.Errorf("invalid flags provider: %s", c.Flags.Provider)
+	}
+	if c.Flags.CacheTTL < 0 {
+		return fmt.Errorf("flags cache ttl must be >= 0")
+	}
+	switch c.Reports.Renderer {
+	case "builtin":
+	case "remote":
+		if c.Reports.RendererURL == "" {
+			return fmt.Errorf("report renderer url is required for the remote renderer")
+		}
+	default:
+		return fmt.Errorf("invalid report renderer: %s", c.Reports.Renderer)
+	}
+	if c.Reports.RequestTimeout <= 0 {
+		return fmt.Errorf("report renderer timeout must be > 0")
+	}
+	if c.Reports.MaxPhotos < 0 {
+		return fmt.Errorf("report max photos must be >= 0")
+	}
+	if c.Chemicals.SearchLimit <= 0 {
+		return fmt.Errorf("chemical catalog search limit must be > 0")
+	}
+	switch c.Photos.Storage {
+	case "local":
+	case "gcs":
+		if c.Photos.Bucket == "" {
+			return fmt.Errorf("photos gcs bucket is required for gcs storage")
+		}
+	default:
+		return fmt.Errorf("invalid photo storage: %s", c.Photos.Storage)
+	}
+	if c.Photos.MaxBytes <= 0 || len(c.Photos.AllowedTypes) == 0 {
+		return fmt.Errorf("photos max bytes must be > 0 and at least one type allowed")
+	}
+	if c.Photos.URLTTL <= 0 || c.Photos.URLTTL > 7*24*time.Hour {
+		return fmt.Errorf("photos url ttl must be between 0 and 7 days")
+	}
+	if c.Assets.MaxBytes <= 0 || len(c.Assets.AllowedTypes) == 0 {
+		return fmt.Errorf("asset max bytes must be > 0 and at least one type allowed")
+	}
+	if c.Assets.GCInterval <= 0 || c.Assets.GCGrace < 0 {
+		return fmt.Errorf("asset gc interval must be > 0 and grace >= 0")
+	}
+	if c.Photos.Storage == "gcs" && c.Assets.CDNBaseURL == "" {
+		return fmt.Errorf("asset cdn base url is required for gcs storage")
+	}
+	if c.ServicePlan.ScheduleTolerance < 0 || c.ServicePlan.MissedGrace < 0 {
+		return fmt.Errorf("service plan tolerances must be >= 0")
+	}
+	if c.Schedule.DefaultJobDuration <= 0 || c.Schedule.ShiftLength <= 0 || c.Schedule.DriveTimePerStop < 0 {
+		return fmt.Errorf("schedule default job duration and shift length must be > 0")
+	}
+	if c.Schedule.MinSamples <= 0 || c.Schedule.SampleWindow < c.Schedule.MinSamples {
+		return fmt.Errorf("schedule samples must satisfy 0 < min <= window")
+	}
+	switch c.Screens.UnresolvedPlaceholders {
+	case "keep", "blank", "drop":
+	default:
+		return fmt.Errorf("invalid unresolved placeholder policy: %s", c.Screens.UnresolvedPlaceholders)
+	}
+	if c.Screens.MaxDepth <= 0 {
+		return fmt.Errorf("screen max depth must be > 0")
+	}
+	if c.Screens.WatchMaxItems <= 0 || c.Screens.WatchMaxText <= 0 {
+		return fmt.Errorf("watch screen item and text limits must be > 0")
+	}
+	if strings.TrimSpace(c.Screens.DefaultLocale) == "" {
+		return fmt.Errorf("default screen locale is required")
+	}
+	if _, err := c.Screens.MinVersions(); err != nil {
+		return err
+	}
+	if c.Screens.UpdateFallback != "none" {
+		var fallback struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(c.Screens.UpdateFallback), &fallback); err != nil || fallback.Type == "" {
+			return fmt.Errorf("update fallback must be a component with a type, or none")
+		}
+	}
+	if c.Screens.ValidateResponses && c.Environment == EnvProd {
+		return fmt.Errorf("screen response validation is not allowed in prod")
+	}
+	if c.Screens.LegacyUserIDCutoff != "" {
+		if _, err := time.Parse(time.DateOnly, c.Screens.LegacyUserIDCutoff); err != nil {
+			return fmt.Errorf("invalid legacy userId cutoff date: %s", c.Screens.LegacyUserIDCutoff)
+		}
+	}
+	if c.Inventory.CountInterval <= 0 || c.Inventory.CountCheckInterval <= 0 || c.Inventory.CountDueAfter <= 0 {
+		return fmt.Errorf("inventory count interval, check interval and due time must be > 0")
+	}
+	if c.Inventory.CountVariancePercent < 0 {
+		return fmt.Errorf("inventory count variance percent must be >= 0")
+	}
+	if c.Calibration.Interval <= 0 || c.Calibration.DueSoon < 0 || c.Calibration.DueSoon >= c.Calibration.Interval {
+		return fmt.Errorf("calibration interval must be > 0 and due-soon window within it")
+	}
+	if c.Playback.Retention <= 0 || c.Playback.LocationRetention < 0 || c.Playback.PruneInterval <= 0 || c.Playback.MaxPoints <= 0 {
+		return fmt.Errorf("playback retention, prune interval and max points must be > 0, and location retention >= 0")
+	}
+	if c.Stream.HeartbeatInterval <= 0 || c.Stream.MaxDuration <= 0 || c.Stream.Replay < 0 || c.Stream.Buffer <= 0 || c.Stream.MaxClients <= 0 {
+		return fmt.Errorf("stream heartbeat interval, max duration, buffer and max clients must be > 0, and replay >= 0")
+	}
+	if c.Profit.LaborRate < 0 || c.Profit.VehicleRate < 0 || c.Profit.Currency == "" {
+		return fmt.Errorf("profit labor and vehicle rates must be >= 0 and currency set")
+	}
+	if c.Messaging.PingInterval <= 0 || c.Messaging.MaxLength <= 0 || c.Messaging.MaxClients <= 0 || c.Messaging.Retention <= 0 || c.Messaging.PruneInterval <= 0 {
+		return fmt.Errorf("messaging ping interval, max length, max clients, retention and prune interval must be > 0")
+	}
+	if c.Operations.Timeout < 0 || c.Operations.Retention <= 0 || c.Operations.PruneInterval <= 0 || c.Operations.Concurrency <= 0 {
+		return fmt.Errorf("operation timeout must be >= 0, and retention, prune interval and concurrency > 0")
+	}
+	if err := c.Worker.validate(); err != nil {
+		return err
+	}
+	if err := c.Queue.validate(); err != nil {
+		return err
+	}
+	if c.Queue.Driver != "memory" && c.Datastore.Driver == "memory" {
+		// A pushed job can reach any instance, which must find the
+		// operation or record it was queued for.
+		return fmt.Errorf("push queue drivers need a shared datastore, postgres or firestore")
+	}
+	if c.Anomaly.Threshold <= 0 || c.Anomaly.MinSamples <= 0 || c.Anomaly.Window <= 0 || c.Anomaly.CheckInterval <= 0 {
+		return fmt.Errorf("anomaly threshold, min samples, window and check interval must be > 0")
+	}
+	if c.Dedupe.Threshold <= 0 || c.Dedupe.Threshold > 1 || c.Dedupe.ScanInterval <= 0 {
+		return fmt.Errorf("dedupe threshold must be in (0, 1] and scan interval > 0")
+	}
+	if err := c.Address.validate(); err != nil {
+		return err
+	}
+	if c.Tenancy.Enabled && (c.Tenancy.Header == "" || c.Auth.TenantClaim == "") {
+		return fmt.Errorf("tenancy requires a tenant header and auth tenant claim")
+	}
+	if c.StatusPage.History <= 0 || c.StatusPage.MaxAge < 0 {
+		return fmt.Errorf("status history must be > 0 and max age >= 0")
+	}
+	if c.Signing.Required && c.Signing.KeySecret == "" {
+		return fmt.Errorf("request signing key secret is required when signing is required")
+	}
+	if c.Signing.Window <= 0 || c.Signing.Window > time.Hour {
+		return fmt.Errorf("request signing window must be between 0 and 1h")
+	}
+	for _, p := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(p); err != nil {
+			if _, err := netip.ParseAddr(p); err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: must be an address or CIDR range", p)
+			}
+		}
+	}
+	if c.Lockout.Enabled && (c.Lockout.Window <= 0 || c.Lockout.DelayAfter <= 0 || c.Lockout.BaseDelay <= 0 || c.Lockout.MaxDelay < c.Lockout.BaseDelay || c.Lockout.LockAfter <= c.Lockout.DelayAfter || c.Lockout.LockFor <= 0) {
+		return fmt.Errorf("auth lockout needs a window, delays and lock duration > 0, max delay >= base delay and lock after > delay after")
+	}
+	if len(c.Access.BlockedCountries) > 0 && c.Access.CountryHeader == "" {
+		return fmt.Errorf("blocking countries requires ACCESS_COUNTRY_HEADER")
+	}
+	if err := c.Events.validate(); err != nil {
+		return err
+	}
+	if err := c.Chaos.validate(c.Environment); err != nil {
+		return err
+	}
+	if err := c.Calendar.validate(); err != nil {
+		return err
+	}
+	if err := c.Auth.validate(c.Environment); err != nil {
+		return err
+	}
+	if c.Brownout.RecoveryThreshold > c.Brownout.P99Threshold {
+		return fmt.Errorf("brownout recovery threshold must be <= p99 threshold")
+	}
+	if c.Brownout.MinSamples <= 0 || c.Brownout.MaxSamples < c.Brownout.MinSamples {
+		return fmt.Errorf("brownout samples must satisfy 0 < min <= max")
+	}
+	return nil
+}
+
+func (c ChaosConfig) validate(env Environment) error {
+	if (c.Enabled || c.AllowHeader) && env == EnvProd {
+		return fmt.Errorf("chaos fault injection is not allowed in prod")
+	}
+	if c.Latency < 0 || c.Jitter < 0 {
+		return fmt.Errorf("chaos latency and jitter must be >= 0")
+	}
+	if c.ErrorPercent < 0 || c.PartialPercent < 0 || c.ErrorPercent+c.PartialPercent > 100 {
+		return fmt.Errorf("chaos error and partial percents must be >= 0 and total at most 100")
+	}
+	return nil
+}
+
+func (c AuthConfig) validate(env Environment) error {
+	switch {
+	case c.JWKSURL != "" && c.HMACSecret != "":
+		return fmt.Errorf("auth jwks url and hmac secret are mutually exclusive")
+	case c.HMACSecret != "" && env == EnvProd:
+		return fmt.Errorf("auth hmac signing is for local and dev environments only")
+	case c.JWKSURL == "" && env == EnvProd:
+		// Without a verifier the admin API is not role-checked.
+		return fmt.Errorf("auth jwks url is required in prod")
+	case c.HMACSecret != "" && len(c.HMACSecret) < 32:
+		return fmt.Errorf("auth hmac secret must be at least 32 bytes")
+	case c.Required && c.JWKSURL == "" && c.HMACSecret == "":
+		return fmt.Errorf("auth required needs a jwks url or hmac secret")
+	case c.JWKSRefresh <= 0 || c.RequestTimeout <= 0 || c.ClockSkew < 0:
+		return fmt.Errorf("auth jwks refresh and request timeout must be > 0 and clock skew >= 0")
+	}
+	return nil
+}
+
+func (c CalendarConfig) validate() error {
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		return fmt.Errorf("invalid business hours time zone: %s", c.TimeZone)
+	}
+	open, err := time.Parse("15:04", c.Open)
+	if err != nil {
+		return fmt.Errorf("invalid business hours open time: %s", c.Open)
+	}
+	closing, err := time.Parse("15:04", c.Close)
+	if err != nil || !closing.After(open) {
+		return fmt.Errorf("business hours close time must be a HH:MM after %s", c.Open)
+	}
+	if len(c.Days) == 0 {
+		return fmt.Errorf("at least one business day is required")
+	}
+	for _, day := range c.Days {
+		switch day {
+		case "sun", "mon", "tue", "wed", "thu", "fri", "sat":
+		default:
+			return fmt.Errorf("invalid business day: %s", day)
+		}
+	}
+	return nil
+}
+
+func (c WorkerConfig) validate() error {
+	if c.Concurrency <= 0 || c.PollInterval <= 0 || c.BatchSize <= 0 || c.RetryAfter <= 0 {
+		return fmt.Errorf("worker concurrency, poll interval, batch size and retry delay must be > 0")
+	}
+	return nil
+}
+
+func (c EventsConfig) validate() error {
+	switch c.Driver {
+	case "memory":
+	case "pubsub":
+		if c.Project == "" || c.PubSubTopic == "" {
+			return fmt.Errorf("pubsub events need a project and topic")
+		}
+	default:
+		return fmt.Errorf("invalid events driver: %s", c.Driver)
+	}
+	// Pub/Sub takes at most 1000 messages per publish request.
+	if c.BufferSize <= 0 || c.BatchSize <= 0 || c.BatchSize > 1000 || c.FlushInterval <= 0 || c.RequestTimeout <= 0 {
+		return fmt.Errorf("events buffer size, flush interval and request timeout must be > 0, and batch size between 1 and 1000")
+	}
+	return nil
+}
+
+func (c QueueConfig) validate() error {
+	switch c.Driver {
+	case "memory":
+	case "cloudtasks":
+		if c.Project == "" || c.CloudTasksLocation == "" || c.CloudTasksQueue == "" {
+			return fmt.Errorf("cloudtasks queue needs a project, location and queue")
+		}
+	case "pubsub":
+		if c.Project == "" || c.PubSubTopic == "" {
+			return fmt.Errorf("pubsub queue needs a project and topic")
+		}
+	default:
+		return fmt.Errorf("invalid queue driver: %s", c.Driver)
+	}
+	if c.Driver != "memory" && (c.PushURL == "" || len(c.PushToken) < 16) {
+		return fmt.Errorf("push queue drivers need a push URL and a push token of at least 16 characters")
+	}
+	if c.BufferSize <= 0 || c.MaxAttempts <= 0 || c.MinBackoff <= 0 || c.MaxBackoff < c.MinBackoff {
+		return fmt.Errorf("queue buffer size and max attempts must be > 0, and backoff 0 < min <= max")
+	}
+	return nil
+}
+
+func (c AddressConfig) validate() error {
+	switch c.Provider {
+	case "none", "local":
+	case "google":
+		if c.KeySecret == "" {
+			return fmt.Errorf("address provider google requires ADDRESS_KEY_SECRET")
+		}
+	case "usps":
+		if c.KeySecret == "" || c.ClientIDSecret == "" {
+			return fmt.Errorf("address provider usps requires ADDRESS_CLIENT_ID_SECRET and ADDRESS_KEY_SECRET")
+		}
+	default:
+		return fmt.Errorf("invalid address provider: %s", c.Provider)
+	}
+	if c.CacheTTL <= 0 || c.RequestTimeout <= 0 {
+		return fmt.Errorf("address cache ttl and request timeout must be > 0")
+	}
 	return nil
 }
 
@@ -164,6 +1919,18 @@ func getInt(key string, fallback int) int {
 	return value
 }
 
+func getFloat(key string, fallback float64) float64 {
+	str := getEnv(key, "")
+	if str == "" {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
 func getBool(key string, fallback bool) bool {
 	str := strings.ToLower(getEnv(key, ""))
 	if str == "" {
@@ -30,6 +30,7 @@ func TestLoadOverrides(t *testing.T) {
 	t.Cleanup(func() { os.Clearenv() })
 
 	os.Setenv("SDUI_ENV", "prod")
+	os.Setenv("AUTH_JWKS_URL", "https://auth.example.com/.well-known/jwks.json")
 	os.Setenv("PORT", "9090")
 	os.Setenv("SERVER_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com")
 	os.Setenv("SECRETS_PROVIDER", "gcp")
@@ -76,3 +77,136 @@ func TestInvalidProvider(t *testing.T) {
 		t.Fatalf("expected error for invalid secrets provider")
 	}
 }
+
+func TestInvalidBrownoutThresholds(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("BROWNOUT_P99_THRESHOLD", "100ms")
+	os.Setenv("BROWNOUT_RECOVERY_THRESHOLD", "200ms")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error when recovery threshold exceeds p99 threshold")
+	}
+}
+
+func TestChaosRefusedInProd(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("SDUI_ENV", "prod")
+	os.Setenv("CHAOS_ALLOW_HEADER", "true")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error when chaos is enabled in prod")
+	}
+}
+
+func TestMemorySnapshotsNeedTheMemoryDriver(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("DATASTORE_MEMORY_SNAPSHOT", "/tmp/store.json")
+	if _, err := Load(); err != nil {
+		t.Fatalf("expected snapshots allowed locally, got %v", err)
+	}
+	os.Setenv("DATASTORE_DRIVER", "firestore")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error when snapshotting another driver")
+	}
+}
+
+func TestInvalidBusinessHours(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("BUSINESS_HOURS_OPEN", "17:00")
+	os.Setenv("BUSINESS_HOURS_CLOSE", "08:00")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error when business hours close before they open")
+	}
+	os.Clearenv()
+	os.Setenv("BUSINESS_DAYS", "mon,funday")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for an unknown business day")
+	}
+}
+
+func TestInvalidQueue(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("QUEUE_DRIVER", "sqs")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for an unknown queue driver")
+	}
+	os.Clearenv()
+	os.Setenv("QUEUE_DRIVER", "cloudtasks")
+	os.Setenv("QUEUE_PROJECT", "pestgenie")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for a push driver without a push URL and token")
+	}
+	os.Setenv("QUEUE_PUSH_URL", "https://api.pestgenie.example/")
+	os.Setenv("QUEUE_PUSH_TOKEN", "0123456789abcdef")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for a push driver over the memory datastore")
+	}
+	os.Setenv("DATASTORE_DRIVER", "firestore")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected the cloudtasks queue accepted, got %v", err)
+	}
+	if cfg.Queue.PushURL != "https://api.pestgenie.example" {
+		t.Fatalf("expected the push URL without its trailing slash, got %q", cfg.Queue.PushURL)
+	}
+	os.Setenv("QUEUE_MAX_BACKOFF", "1s")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for a max backoff below the min")
+	}
+}
+
+func TestInvalidAuth(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("SDUI_ENV", "prod")
+	os.Setenv("AUTH_JWT_HMAC_SECRET", "0123456789abcdef0123456789abcdef")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for hmac signing in prod")
+	}
+	os.Clearenv()
+	os.Setenv("AUTH_REQUIRED", "true")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error when auth is required without a verification key")
+	}
+	os.Clearenv()
+	os.Setenv("SDUI_ENV", "prod")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for prod without a jwks url")
+	}
+}
+
+func TestLegacyUserIDCutoffMustBeADate(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("SDUI_LEGACY_USERID_CUTOFF", "2026-06-01")
+	if _, err := Load(); err != nil {
+		t.Fatalf("expected a date accepted, got %v", err)
+	}
+	os.Setenv("SDUI_LEGACY_USERID_CUTOFF", "June 1st")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for a cutoff that is not YYYY-MM-DD")
+	}
+}
+
+func TestNotifyDigestWindows(t *testing.T) {
+	t.Cleanup(func() { os.Clearenv() })
+
+	os.Setenv("NOTIFY_DIGEST_WINDOWS", "route=2m, inventory = 10m")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	windows, _ := cfg.Notify.Windows()
+	if windows["route"] != 2*time.Minute || windows["inventory"] != 10*time.Minute {
+		t.Fatalf("unexpected windows %v", windows)
+	}
+	for _, bad := range []string{"route", "route=soon", "route=-1m"} {
+		os.Setenv("NOTIFY_DIGEST_WINDOWS", bad)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package connector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+)
+
+// newTestService returns a service over a memory queue, whose deliveries
+// are made by draining it.
+func newTestService(cfg config.ConnectorConfig, secrets staticSecrets) (*Service, *jobqueue.Queue) {
+	jobs := jobqueue.NewMemory(config.QueueConfig{BufferSize: 10, MaxAttempts: 1, MinBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}, nil)
+	return NewService(cfg, NewMemoryStore(), jobs, secrets, nil), jobs
+}
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	v, ok := s[name]
+	if !ok {
+		return "", errors.New("missing")
+	}
+	return v, nil
+}
+
+func TestValidateChecksFieldsAgainstCatalog(t *testing.T) {
+	sub := Subscription{
+		Name:       "Zap",
+		URL:        "https://hooks.example.com/catch/1",
+		EventTypes: []string{EventJobUploaded, EventTreatmentRecorded},
+		Fields:     map[string]string{"customer": "customerName"},
+	}
+	if err := sub.Validate(); !errors.Is(err, ErrInvalidSubscription) {
+		t.Fatalf("expected customerName to be rejected for treatments, got %v", err)
+	}
+	sub.Fields = map[string]string{"job": "jobId", "tech": "technicianId"}
+	if err := sub.Validate(); err != nil {
+		t.Fatalf("expected valid subscription, got %v", err)
+	}
+}
+
+func TestDispatchFiltersMapsAndSigns(t *testing.T) {
+	var got []map[string]any
+	var signature string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		var payload map[string]any
+		_ = json.Unmarshal(body, &payload)
+		got = append(got, payload)
+	}))
+	defer srv.Close()
+
+	cfg := config.ConnectorConfig{Enabled: true, QueueSize: 10, MaxAttempts: 1}
+	svc, jobs := newTestService(cfg, staticSecrets{"zap-key": "s3cret"})
+	sub, err := svc.CreateSubscription(Subscription{
+		Name:          "Completed jobs",
+		Enabled:       true,
+		URL:           srv.URL,
+		EventTypes:    []string{EventJobUploaded},
+		Filters:       []Filter{{Field: "status", Op: OpEquals, Value: "completed"}},
+		Fields:        map[string]string{"customer": "customerName"},
+		SigningSecret: "zap-key",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.dispatch(context.Background(), JobUploaded(domain.JobUpload{ID: "J1", CustomerName: "Smith", Status: "scheduled"}))
+	svc.dispatch(context.Background(), JobUploaded(domain.JobUpload{ID: "J2", CustomerName: "Jones", Status: "Completed"}))
+	jobs.Drain(context.Background())
+
+	if len(got) != 1 {
+		t.Fatalf("expected one delivery, got %d", len(got))
+	}
+	data, _ := got[0]["data"].(map[string]any)
+	if got[0]["event"] != EventJobUploaded || len(data) != 1 || data["customer"] != "Jones" {
+		t.Fatalf("unexpected payload: %v", got[0])
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if signature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature %q", signature)
+	}
+
+	deliveries, _ := svc.Deliveries(sub.ID, 0)
+	if len(deliveries) != 1 || deliveries[0].Status != StatusDelivered {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+func TestDeliveriesRetryServerErrorsOnly(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	cfg := config.ConnectorConfig{Enabled: true, QueueSize: 10, MaxAttempts: 3, RetryBackoff: time.Nanosecond}
+	svc, jobs := newTestService(cfg, staticSecrets{})
+	sub, _ := svc.CreateSubscription(Subscription{Name: "CRM", Enabled: true, URL: srv.URL, EventTypes: []string{EventJobUploaded}})
+	ctx := context.Background()
+
+	svc.dispatch(ctx, sampleEvent(EventJobUploaded))
+	if n := jobs.Drain(ctx); n != 3 {
+		t.Fatalf("expected each attempt queued on its own, got %d deliveries", n)
+	}
+	deliveries, _ := svc.Deliveries(sub.ID, 0)
+	if len(deliveries) != 1 || deliveries[0].Status != StatusFailed || deliveries[0].Attempts != 3 || len(deliveries[0].History) != 3 || calls.Load() != 3 {
+		t.Fatalf("expected three attempts on 503, got %+v (%d calls)", deliveries, calls.Load())
+	}
+
+	calls.Store(0)
+	status = http.StatusBadRequest
+	svc.dispatch(ctx, sampleEvent(EventJobUploaded))
+	jobs.Drain(ctx)
+	deliveries, _ = svc.Deliveries(sub.ID, 1)
+	if d := deliveries[0]; d.Attempts != 1 || d.ResponseStatus != http.StatusBadRequest || d.Status != StatusFailed || calls.Load() != 1 {
+		t.Fatalf("expected a single attempt on 400, got %+v", d)
+	}
+}
+
+func TestSlowEndpointDoesNotHoldUpOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	var fastCalls atomic.Int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastCalls.Add(1)
+	}))
+	defer fast.Close()
+
+	svc, jobs := newTestService(config.ConnectorConfig{Enabled: true, QueueSize: 10, MaxAttempts: 1, Concurrency: 2}, staticSecrets{})
+	_, _ = svc.CreateSubscription(Subscription{Name: "A slow", Enabled: true, URL: slow.URL, EventTypes: []string{EventJobUploaded}})
+	_, _ = svc.CreateSubscription(Subscription{Name: "B fast", Enabled: true, URL: fast.URL, EventTypes: []string{EventJobUploaded}})
+
+	// Dispatching only queues the deliveries; neither endpoint is called
+	// until the queue delivers them.
+	svc.dispatch(context.Background(), sampleEvent(EventJobUploaded))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go jobs.Run(ctx)
+	deadline := time.Now().Add(5 * time.Second)
+	for fastCalls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the fast endpoint delivered while the slow one hangs")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPublishDropsWhenQueueFull(t *testing.T) {
+	svc, _ := newTestService(config.ConnectorConfig{Enabled: true, QueueSize: 1}, staticSecrets{})
+	svc.Publish(sampleEvent(EventJobUploaded))
+	svc.Publish(sampleEvent(EventJobUploaded))
+	if svc.Dropped() != 1 {
+		t.Fatalf("expected one dropped event, got %d", svc.Dropped())
+	}
+
+	var nilService *Service
+	nilService.Publish(sampleEvent(EventJobUploaded))
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	svc, _ := newTestService(config.ConnectorConfig{RetryBackoff: time.Second, MaxBackoff: 5 * time.Second}, staticSecrets{})
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := svc.backoff(i); got != w {
+			t.Fatalf("backoff(%d) = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestRedeliverResendsPayloadWithHistory(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.ConnectorConfig{Enabled: true, QueueSize: 10, MaxAttempts: 2, RetryBackoff: time.Nanosecond, MaxBackoff: time.Minute}
+	svc, jobs := newTestService(cfg, staticSecrets{})
+	sub, err := svc.CreateSubscription(Subscription{Name: "CRM", Enabled: true, URL: srv.URL, EventTypes: []string{EventJobCompleted}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.dispatch(context.Background(), JobCompleted(domain.JobUpload{ID: "J1", Status: "completed"}))
+	jobs.Drain(context.Background())
+	deliveries, _ := svc.Deliveries(sub.ID, 0)
+	if len(deliveries) != 1 || deliveries[0].Status != StatusFailed || len(deliveries[0].History) != 2 || deliveries[0].History[1].ResponseStatus != http.StatusBadGateway {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+
+	failed := deliveries[0]
+	if _, err := svc.Redeliver(context.Background(), "other", failed.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found for another subscription, got %v", err)
+	}
+	d, err := svc.Redeliver(context.Background(), sub.ID, failed.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Status != StatusDelivered || d.RedeliveryOf != failed.ID || d.EventID != failed.EventID || len(d.History) != 1 {
+		t.Fatalf("unexpected redelivery: %+v", d)
+	}
+	if len(bodies) != 3 || bodies[2] != bodies[0] {
+		t.Fatalf("expected the original payload to be resent, got %v", bodies)
+	}
+	if got, _ := svc.Delivery(sub.ID, d.ID); got.ID != d.ID {
+		t.Fatalf("expected the redelivery to be stored, got %+v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package connector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// Event types. The schema is intentionally flat so no-code tools can map
+// fields without navigating nested objects.
+const (
+	EventJobUploaded       = "job.uploaded"
+	EventJobCompleted      = "job.completed"
+	EventChemicalUpdated   = "chemical.updated"
+	EventTreatmentRecorded = "treatment.recorded"
+	EventRouteOverbooked   = "route.overbooked"
+)
+
+// Event is a normalized domain event.
+type Event struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"event"`
+	OccurredAt time.Time      `json:"occurredAt"`
+	Data       map[string]any `json:"data"`
+}
+
+// catalog lists the data fields each event type carries. It drives
+// subscription validation and the field picker in the admin UI.
+var catalog = map[string][]string{
+	EventJobUploaded: {
+		"jobId", "technicianId", "customerName", "address", "scheduledDate", "status",
+	},
+	EventJobCompleted: {
+		"jobId", "technicianId", "customerName", "address", "scheduledDate", "status",
+	},
+	EventChemicalUpdated: {
+		"chemicalId", "technicianId", "name", "activeIngredient", "manufacturer",
+		"epaRegistration", "concentration", "unitOfMeasure", "quantityInStock", "expirationDate",
+	},
+	EventTreatmentRecorded: {
+		"treatmentId", "jobId", "chemicalId", "technicianId", "applicatorName", "applicationDate",
+		"applicationMethod", "targetPests", "quantityUsed", "dosageRate", "dilutionRatio", "notes",
+	},
+	EventRouteOverbooked: {
+		"routeId", "technicianId", "serviceDate", "stops", "estimatedMinutes", "shiftMinutes",
+	},
+}
+
+// EventType describes an event type and its fields.
+type EventType struct {
+	Type   string   `json:"event"`
+	Fields []string `json:"fields"`
+}
+
+// EventTypes returns the catalog sorted by type.
+func EventTypes() []EventType {
+	out := make([]EventType, 0, len(catalog))
+	for t, fields := range catalog {
+		out = append(out, EventType{Type: t, Fields: fields})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+func newEvent(eventType string, data map[string]any) Event {
+	return Event{ID: uuid.NewString(), Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+// JobUploaded builds the event for a job synced from a device.
+func JobUploaded(j domain.JobUpload) Event {
+	return newEvent(EventJobUploaded, map[string]any{
+		"jobId":         j.ID,
+		"technicianId":  j.TechnicianID,
+		"customerName":  j.CustomerName,
+		"address":       j.Address,
+		"scheduledDate": formatTime(j.ScheduledDate),
+		"status":        j.Status,
+	})
+}
+
+// JobCompleted builds the event for a job synced with status completed. It
+// is built for every such upload, so receivers should deduplicate on jobId.
+func JobCompleted(j domain.JobUpload) Event {
+	e := JobUploaded(j)
+	e.Type = EventJobCompleted
+	return e
+}
+
+// ChemicalUpdated builds the event for a chemical inventory update.
+func ChemicalUpdated(c domain.ChemicalUpload) Event {
+	return newEvent(EventChemicalUpdated, map[string]any{
+		"chemicalId":       c.ID,
+		"technicianId":     c.TechnicianID,
+		"name":             c.Name,
+		"activeIngredient": c.ActiveIngredient,
+		"manufacturer":     c.ManufacturerName,
+		"epaRegistration":  c.EPARegistration,
+		"concentration":    c.Concentration,
+		"unitOfMeasure":    c.UnitOfMeasure,
+		"quantityInStock":  c.QuantityInStock,
+		"expirationDate":   formatTime(c.ExpirationDate),
+	})
+}
+
+// TreatmentRecorded builds the event for a chemical treatment log.
+func TreatmentRecorded(t domain.ChemicalTreatmentUpload) Event {
+	return newEvent(EventTreatmentRecorded, map[string]any{
+		"treatmentId":       t.ID,
+		"jobId":             t.JobID,
+		"chemicalId":        t.ChemicalID,
+		"lotNumber":         t.LotNumber,
+		"mixId":             t.MixID,
+		"equipmentId":       t.EquipmentID,
+		"technicianId":      t.TechnicianID,
+		"applicatorName":    t.ApplicatorName,
+		"applicationDate":   formatTime(t.ApplicationDate),
+		"applicationMethod": t.ApplicationMethod,
+		"targetPests":       t.TargetPests,
+		"quantityUsed":      t.QuantityUsed,
+		"dosageRate":        t.DosageRate,
+		"dilutionRatio":     t.DilutionRatio,
+		"notes":             t.Notes,
+	})
+}
+
+// RouteOverbooked builds the event for an imported route that does not fit
+// in the technician's shift.
+func RouteOverbooked(r domain.Route, estimatedMinutes, shiftMinutes int) Event {
+	return newEvent(EventRouteOverbooked, map[string]any{
+		"routeId":          r.ID,
+		"technicianId":     r.TechnicianID,
+		"serviceDate":      r.ServiceDate.UTC().Format("2006-01-02"),
+		"stops":            len(r.CustomerStops),
+		"estimatedMinutes": estimatedMinutes,
+		"shiftMinutes":     shiftMinutes,
+	})
+}
+
+// sampleEvent returns an event with placeholder values for test deliveries.
+func sampleEvent(eventType string) Event {
+	data := make(map[string]any, len(catalog[eventType]))
+	for _, field := range catalog[eventType] {
+		data[field] = "sample-" + field
+	}
+	return newEvent(eventType, data)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
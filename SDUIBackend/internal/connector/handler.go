@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes admin endpoints for connector subscriptions.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a connector handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the connector endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/events", h.ListEventTypes)
+	r.Get("/subscriptions", h.ListSubscriptions)
+	r.Post("/subscriptions", h.CreateSubscription)
+	r.Get("/subscriptions/{subscriptionId}", h.GetSubscription)
+	r.Put("/subscriptions/{subscriptionId}", h.UpdateSubscription)
+	r.Delete("/subscriptions/{subscriptionId}", h.DeleteSubscription)
+	r.Post("/subscriptions/{subscriptionId}/test", h.SendTest)
+	r.Get("/subscriptions/{subscriptionId}/deliveries", h.ListDeliveries)
+	r.Get("/subscriptions/{subscriptionId}/deliveries/{deliveryId}", h.GetDelivery)
+	r.Post("/subscriptions/{subscriptionId}/deliveries/{deliveryId}/redeliver", h.Redeliver)
+}
+
+// ListEventTypes returns the event catalog with each type's fields.
+func (h *Handler) ListEventTypes(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]any{"events": EventTypes()})
+}
+
+// CreateSubscription registers a connector subscription.
+func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var payload Subscription
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	sub, err := h.service.CreateSubscription(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create subscription", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, sub)
+}
+
+// UpdateSubscription replaces a connector subscription.
+func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	var payload Subscription
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	sub, err := h.service.UpdateSubscription(chi.URLParam(r, "subscriptionId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update subscription", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, sub)
+}
+
+// GetSubscription returns a connector subscription.
+func (h *Handler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.service.Subscription(chi.URLParam(r, "subscriptionId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch subscription", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, sub)
+}
+
+// ListSubscriptions returns every connector subscription.
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.service.Subscriptions()
+	if err != nil {
+		h.fail(w, r, "failed to list subscriptions", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"subscriptions": subs})
+}
+
+// DeleteSubscription removes a connector subscription.
+func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSubscription(chi.URLParam(r, "subscriptionId")); err != nil {
+		h.fail(w, r, "failed to delete subscription", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendTest posts a sample event for the requested (or first subscribed) type.
+func (h *Handler) SendTest(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.SendTest(r.Context(), chi.URLParam(r, "subscriptionId"), r.URL.Query().Get("event"))
+	if err != nil {
+		h.fail(w, r, "failed to send test event", err)
+		return
+	}
+	status := http.StatusOK
+	if d.Status == StatusFailed {
+		status = http.StatusBadGateway
+	}
+	respond.JSON(w, status, d)
+}
+
+// ListDeliveries returns recent deliveries for a subscription, newest first.
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	deliveries, err := h.service.Deliveries(chi.URLParam(r, "subscriptionId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list deliveries", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+// GetDelivery returns a delivery with its attempt history and payload.
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Delivery(chi.URLParam(r, "subscriptionId"), chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch delivery", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// Redeliver resends a delivery's payload and returns the new delivery.
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Redeliver(r.Context(), chi.URLParam(r, "subscriptionId"), chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		h.fail(w, r, "failed to redeliver event", err)
+		return
+	}
+	status := http.StatusOK
+	if d.Status == StatusFailed {
+		status = http.StatusBadGateway
+	}
+	respond.JSON(w, status, d)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidSubscription), errors.Is(err, ErrNoPayload):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,479 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when the
+// subscription has a signing secret.
+const SignatureHeader = "X-PestGenie-Signature"
+
+// Topic is the job queue topic deliveries are made through.
+const Topic = "connector-deliveries"
+
+const (
+	// maxDeliveries bounds the deliveries kept for each subscription.
+	maxDeliveries = 1000
+	// pruneInterval is how often deliveries past maxDeliveries are deleted.
+	pruneInterval = time.Hour
+)
+
+// Service fans published events out to matching subscriptions.
+type Service struct {
+	cfg     config.ConnectorConfig
+	store   Store
+	jobs    *jobqueue.Queue
+	secrets secret.Provider
+	client  *http.Client
+	queue   chan Event
+	dropped atomic.Int64
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// task is the job that makes the next attempt of a delivery.
+type task struct {
+	DeliveryID string `json:"deliveryId"`
+}
+
+// NewService wires a connector service. Each delivery goes through jobs,
+// so one slow or failing endpoint does not hold up the others, and its
+// retries wait in the queue rather than on an instance.
+func NewService(cfg config.ConnectorConfig, store Store, jobs *jobqueue.Queue, secrets secret.Provider, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = 1000
+	}
+	s := &Service{
+		cfg:     cfg,
+		store:   store,
+		jobs:    jobs,
+		secrets: secrets,
+		client:  &http.Client{Timeout: cfg.RequestTimeout},
+		queue:   make(chan Event, size),
+		logger:  logger,
+		now:     time.Now,
+	}
+	jobs.Subscribe(jobqueue.Subscription{Topic: Topic, Handler: s.handle, DeadLetter: s.deadLetter, Concurrency: cfg.Concurrency})
+	return s
+}
+
+// Publish queues e for delivery without blocking the caller. Events are
+// dropped when the queue is full or the service is nil or disabled.
+func (s *Service) Publish(e Event) {
+	if s == nil || !s.cfg.Enabled {
+		return
+	}
+	select {
+	case s.queue <- e:
+	default:
+		s.dropped.Add(1)
+		s.logger.Warn("connector queue full, event dropped", slog.String("event", e.Type), slog.String("id", e.ID))
+	}
+}
+
+// Dropped returns how many events were discarded because the queue was full.
+func (s *Service) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Run queues a delivery of each published event to every subscription
+// that wants it, and prunes old deliveries every pruneInterval, until ctx
+// is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.queue:
+			s.dispatch(ctx, e)
+		case <-ticker.C:
+			s.prune()
+		}
+	}
+}
+
+func (s *Service) dispatch(ctx context.Context, e Event) {
+	subs, err := s.store.ListSubscriptions()
+	if err != nil {
+		s.logger.Error("list connector subscriptions", slog.Any("error", err))
+		return
+	}
+	for _, sub := range subs {
+		if !sub.Wants(e) {
+			continue
+		}
+		d := s.prepare(sub, e)
+		if d.Status == StatusPending {
+			s.enqueue(ctx, d)
+			continue
+		}
+		if err := s.store.SaveDelivery(d); err != nil {
+			s.logger.Error("save connector delivery", slog.String("subscription", sub.ID), slog.Any("error", err))
+		}
+	}
+}
+
+// enqueue saves d and queues its first attempt, failing d when it cannot
+// be queued.
+func (s *Service) enqueue(ctx context.Context, d Delivery) {
+	logger := s.logger.With(slog.String("subscription", d.SubscriptionID), slog.String("delivery", d.ID))
+	if err := s.store.SaveDelivery(d); err != nil {
+		logger.Error("save connector delivery", slog.Any("error", err))
+		return
+	}
+	if _, err := s.jobs.Enqueue(ctx, Topic, task{DeliveryID: d.ID}, 0); err != nil {
+		logger.Error("queue connector delivery", slog.Any("error", err))
+		d.Status = StatusFailed
+		d.Error = fmt.Sprintf("could not be queued: %v", err)
+		if err := s.store.SaveDelivery(d); err != nil {
+			logger.Error("save connector delivery", slog.Any("error", err))
+		}
+	}
+}
+
+// handle makes the next attempt of a queued delivery. A retryable failure
+// queues the attempt after it, backed off, until MaxAttempts attempts
+// have been made.
+func (s *Service) handle(ctx context.Context, j jobqueue.Job) error {
+	var t task
+	if err := j.Decode(&t); err != nil {
+		s.logger.Error("decode connector delivery", slog.String("job", j.ID), slog.Any("error", err))
+		return nil
+	}
+	d, err := s.store.GetDelivery(t.DeliveryID)
+	if errors.Is(err, ErrNotFound) || (err == nil && d.Status != StatusPending) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sub, err := s.store.GetSubscription(d.SubscriptionID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		d.Status = StatusFailed
+		d.Error = "subscription deleted"
+		return s.store.SaveDelivery(d)
+	case err != nil:
+		return err
+	}
+
+	retry := s.attempt(ctx, sub, &d)
+	if d.Status == StatusPending && ctx.Err() != nil {
+		// Cut short by shutdown: the queue delivers the job again.
+		if err := s.store.SaveDelivery(d); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+	if retry && d.Attempts < s.maxAttempts() {
+		if err := s.store.SaveDelivery(d); err != nil {
+			return err
+		}
+		_, err := s.jobs.Enqueue(ctx, Topic, t, s.backoff(d.Attempts-1))
+		return err
+	}
+	if d.Status == StatusPending {
+		d.Status = StatusFailed
+		s.logger.Warn("connector delivery failed", slog.String("subscription", sub.ID), slog.String("event", d.EventType), slog.Int("attempts", d.Attempts), slog.String("error", d.Error))
+	}
+	return s.store.SaveDelivery(d)
+}
+
+// deadLetter fails a delivery the queue could not attempt.
+func (s *Service) deadLetter(_ context.Context, j jobqueue.Job, err error) {
+	var t task
+	if derr := j.Decode(&t); derr != nil {
+		return
+	}
+	d, gerr := s.store.GetDelivery(t.DeliveryID)
+	if gerr != nil || d.Status != StatusPending {
+		return
+	}
+	d.Status = StatusFailed
+	d.Error = "could not be attempted: " + err.Error()
+	if serr := s.store.SaveDelivery(d); serr != nil {
+		s.logger.Error("save connector delivery", slog.String("delivery", d.ID), slog.Any("error", serr))
+	}
+}
+
+func (s *Service) prune() {
+	subs, err := s.store.ListSubscriptions()
+	if err != nil {
+		s.logger.Error("list connector subscriptions", slog.Any("error", err))
+		return
+	}
+	for _, sub := range subs {
+		if _, err := s.store.PruneDeliveries(sub.ID, maxDeliveries); err != nil {
+			s.logger.Error("prune connector deliveries", slog.String("subscription", sub.ID), slog.Any("error", err))
+		}
+	}
+}
+
+func (s *Service) maxAttempts() int {
+	return max(s.cfg.MaxAttempts, 1)
+}
+
+// CreateSubscription validates and stores a subscription.
+func (s *Service) CreateSubscription(sub Subscription) (Subscription, error) {
+	if err := s.validate(sub); err != nil {
+		return Subscription{}, err
+	}
+	now := s.now().UTC()
+	sub.ID = uuid.NewString()
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	return sub, s.store.SaveSubscription(sub)
+}
+
+// UpdateSubscription replaces a subscription.
+func (s *Service) UpdateSubscription(id string, sub Subscription) (Subscription, error) {
+	existing, err := s.store.GetSubscription(id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if err := s.validate(sub); err != nil {
+		return Subscription{}, err
+	}
+	sub.ID = id
+	sub.CreatedAt = existing.CreatedAt
+	sub.UpdatedAt = s.now().UTC()
+	return sub, s.store.SaveSubscription(sub)
+}
+
+// validate also checks that the signing secret resolves so mistakes surface
+// when the subscription is saved rather than on the first event.
+func (s *Service) validate(sub Subscription) error {
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	if sub.SigningSecret != "" {
+		if _, err := s.secrets.Get(sub.SigningSecret); err != nil {
+			return fmt.Errorf("%w: signing secret %q cannot be resolved", ErrInvalidSubscription, sub.SigningSecret)
+		}
+	}
+	return nil
+}
+
+// Subscription returns a single subscription.
+func (s *Service) Subscription(id string) (Subscription, error) {
+	return s.store.GetSubscription(id)
+}
+
+// Subscriptions lists every subscription.
+func (s *Service) Subscriptions() ([]Subscription, error) {
+	return s.store.ListSubscriptions()
+}
+
+// DeleteSubscription removes a subscription.
+func (s *Service) DeleteSubscription(id string) error {
+	return s.store.DeleteSubscription(id)
+}
+
+// Deliveries lists a subscription's deliveries, newest first.
+func (s *Service) Deliveries(id string, limit int) ([]Delivery, error) {
+	if _, err := s.store.GetSubscription(id); err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(id, limit)
+}
+
+// SendTest posts a sample event so users can map fields in their no-code
+// tool before real events flow. It ignores filters and the enabled flag,
+// and makes a single attempt while the caller waits.
+func (s *Service) SendTest(ctx context.Context, id, eventType string) (Delivery, error) {
+	sub, err := s.store.GetSubscription(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if eventType == "" && len(sub.EventTypes) > 0 {
+		eventType = sub.EventTypes[0]
+	}
+	if _, ok := catalog[eventType]; !ok {
+		return Delivery{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, eventType)
+	}
+	d := s.prepare(sub, sampleEvent(eventType))
+	d.Test = true
+	s.once(ctx, sub, &d)
+	return d, s.store.SaveDelivery(d)
+}
+
+// Delivery returns one of a subscription's deliveries.
+func (s *Service) Delivery(subscriptionID, id string) (Delivery, error) {
+	d, err := s.store.GetDelivery(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if d.SubscriptionID != subscriptionID {
+		return Delivery{}, ErrNotFound
+	}
+	return d, nil
+}
+
+// Redeliver resends the body of one of a subscription's deliveries as a new
+// delivery, to the subscription's current URL and signed with its current
+// secret. It ignores filters and the enabled flag, and makes a single
+// attempt while the caller waits.
+func (s *Service) Redeliver(ctx context.Context, subscriptionID, id string) (Delivery, error) {
+	sub, err := s.store.GetSubscription(subscriptionID)
+	if err != nil {
+		return Delivery{}, err
+	}
+	original, err := s.Delivery(subscriptionID, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if len(original.Payload) == 0 {
+		return Delivery{}, ErrNoPayload
+	}
+	d := Delivery{
+		ID:             uuid.NewString(),
+		SubscriptionID: sub.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Test:           original.Test,
+		RedeliveryOf:   original.ID,
+		Status:         StatusPending,
+		Payload:        original.Payload,
+		CreatedAt:      s.now().UTC(),
+	}
+	s.once(ctx, sub, &d)
+	return d, s.store.SaveDelivery(d)
+}
+
+// prepare builds a pending delivery of the mapped event's body, or a
+// failed one when the body cannot be built.
+func (s *Service) prepare(sub Subscription, e Event) Delivery {
+	d := Delivery{
+		ID:             uuid.NewString(),
+		SubscriptionID: sub.ID,
+		EventID:        e.ID,
+		EventType:      e.Type,
+		Status:         StatusPending,
+		CreatedAt:      s.now().UTC(),
+	}
+	body, err := json.Marshal(sub.Payload(e))
+	if err != nil {
+		d.Status = StatusFailed
+		d.Error = err.Error()
+		return d
+	}
+	d.Payload = body
+	return d
+}
+
+// once makes a pending delivery's only attempt.
+func (s *Service) once(ctx context.Context, sub Subscription, d *Delivery) {
+	if d.Status != StatusPending {
+		return
+	}
+	s.attempt(ctx, sub, d)
+	if d.Status == StatusPending {
+		d.Status = StatusFailed
+	}
+}
+
+// attempt posts d.Payload once and records the attempt in d.History. d is
+// delivered on success, failed when the attempt cannot be made, and left
+// pending otherwise; attempt reports whether the failure is worth
+// retrying.
+func (s *Service) attempt(ctx context.Context, sub Subscription, d *Delivery) bool {
+	var signature string
+	if sub.SigningSecret != "" {
+		key, err := s.secrets.Get(sub.SigningSecret)
+		if err != nil {
+			d.Status = StatusFailed
+			d.Error = fmt.Sprintf("resolve signing secret: %v", err)
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(d.Payload)
+		signature = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	d.Attempts++
+	started := s.now()
+	status, retry, err := s.post(ctx, sub.URL, d.Payload, signature)
+	d.ResponseStatus = status
+	attempt := Attempt{At: started.UTC(), ResponseStatus: status, DurationMs: s.now().Sub(started).Milliseconds()}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	d.History = append(d.History, attempt)
+	if err == nil {
+		d.Status = StatusDelivered
+		d.Error = ""
+		return false
+	}
+	d.Error = err.Error()
+	return retry
+}
+
+// backoff returns the wait after the given failed attempt, counted from
+// zero: RetryBackoff doubled for each earlier retry, capped at MaxBackoff.
+func (s *Service) backoff(attempt int) time.Duration {
+	wait := s.cfg.RetryBackoff
+	for ; attempt > 0; attempt-- {
+		if s.cfg.MaxBackoff > 0 && wait >= s.cfg.MaxBackoff {
+			break
+		}
+		wait *= 2
+	}
+	if s.cfg.MaxBackoff > 0 && wait > s.cfg.MaxBackoff {
+		wait = s.cfg.MaxBackoff
+	}
+	return wait
+}
+
+// post sends one request. Client errors other than 408 and 429 are not
+// retried since the receiving hook rejected the payload itself.
+func (s *Service) post(ctx context.Context, target string, body []byte, signature string) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PestGenie-Connector/1.0")
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return resp.StatusCode, retry, fmt.Errorf("endpoint responded %s", resp.Status)
+}
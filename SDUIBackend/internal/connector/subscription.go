@@ -0,0 +1,315 @@
+package connector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// Filter operators.
+const (
+	OpEquals    = "eq"
+	OpNotEquals = "ne"
+	OpContains  = "contains"
+	OpExists    = "exists"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending" // queued, or waiting to be retried
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrNotFound is returned when a subscription or delivery does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidSubscription wraps subscription validation failures.
+	ErrInvalidSubscription = errors.New("invalid subscription")
+	// ErrNoPayload is returned when redelivering a delivery whose body was
+	// never built.
+	ErrNoPayload = errors.New("delivery has no payload to resend")
+)
+
+// Subscription posts matching events to a URL, typically a Zapier or Make
+// catch hook.
+type Subscription struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Enabled    bool     `json:"enabled"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Filters must all match for an event to be sent.
+	Filters []Filter `json:"filters,omitempty"`
+	// Fields maps output keys to event data fields. When empty the event
+	// data is sent unchanged.
+	Fields map[string]string `json:"fields,omitempty"`
+	// SigningSecret names a secret used to sign the body with HMAC-SHA256.
+	SigningSecret string    `json:"signingSecret,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Filter compares an event data field with a value.
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value string `json:"value,omitempty"`
+}
+
+// Validate checks a subscription against the event catalog.
+func (s Subscription) Validate() error {
+	var problems []string
+	if s.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		problems = append(problems, "url must be an absolute http(s) URL")
+	}
+	if len(s.EventTypes) == 0 {
+		problems = append(problems, "at least one event type is required")
+	}
+
+	// Fields referenced by filters and mappings must exist on every
+	// subscribed event type.
+	known := make(map[string]int)
+	for _, t := range s.EventTypes {
+		fields, ok := catalog[t]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown event type %q", t))
+			continue
+		}
+		for _, f := range fields {
+			known[f]++
+		}
+	}
+	hasField := func(field string) bool { return known[field] == len(s.EventTypes) }
+
+	for i, f := range s.Filters {
+		if !hasField(f.Field) {
+			problems = append(problems, fmt.Sprintf("filters[%d].field %q is not available on every event type", i, f.Field))
+		}
+		switch f.Op {
+		case OpEquals, OpNotEquals, OpContains, OpExists:
+		default:
+			problems = append(problems, fmt.Sprintf("filters[%d].op must be eq, ne, contains, or exists", i))
+		}
+	}
+	for out, field := range s.Fields {
+		if out == "" {
+			problems = append(problems, "fields keys must not be empty")
+		}
+		if !hasField(field) {
+			problems = append(problems, fmt.Sprintf("fields.%s source %q is not available on every event type", out, field))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Wants reports whether the subscription should receive e.
+func (s Subscription) Wants(e Event) bool {
+	if !s.Enabled {
+		return false
+	}
+	subscribed := false
+	for _, t := range s.EventTypes {
+		if t == e.Type {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return false
+	}
+	for _, f := range s.Filters {
+		if !f.matches(e.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) matches(data map[string]any) bool {
+	value, ok := data[f.Field]
+	text := ""
+	if ok && value != nil {
+		text = fmt.Sprint(value)
+	}
+	switch f.Op {
+	case OpEquals:
+		return strings.EqualFold(text, f.Value)
+	case OpNotEquals:
+		return !strings.EqualFold(text, f.Value)
+	case OpContains:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(f.Value))
+	case OpExists:
+		return text != ""
+	}
+	return false
+}
+
+// Payload builds the JSON body sent for e, applying the field mapping.
+func (s Subscription) Payload(e Event) Event {
+	if len(s.Fields) == 0 {
+		return e
+	}
+	data := make(map[string]any, len(s.Fields))
+	for out, field := range s.Fields {
+		data[out] = e.Data[field]
+	}
+	e.Data = data
+	return e
+}
+
+// Delivery records sending an event to a subscription, retries included.
+type Delivery struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscriptionId"`
+	EventID        string `json:"eventId"`
+	EventType      string `json:"event"`
+	Test           bool   `json:"test,omitempty"`
+	// RedeliveryOf is the delivery this one resent, if any.
+	RedeliveryOf   string    `json:"redeliveryOf,omitempty"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	ResponseStatus int       `json:"responseStatus,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	History        []Attempt `json:"history,omitempty"`
+	// Payload is the body as sent, kept so it can be redelivered.
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Attempt is one request made for a delivery.
+type Attempt struct {
+	At             time.Time `json:"at"`
+	ResponseStatus int       `json:"responseStatus,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"durationMs"`
+}
+
+// Store persists subscriptions and their delivery log.
+type Store interface {
+	SaveSubscription(s Subscription) error
+	GetSubscription(id string) (Subscription, error)
+	ListSubscriptions() ([]Subscription, error)
+	DeleteSubscription(id string) error
+	SaveDelivery(d Delivery) error
+	GetDelivery(id string) (Delivery, error)
+	ListDeliveries(subscriptionID string, limit int) ([]Delivery, error)
+	// PruneDeliveries deletes all but a subscription's keep newest
+	// deliveries, returning how many it deleted.
+	PruneDeliveries(subscriptionID string, keep int) (int, error)
+}
+
+// DocumentStore keeps subscriptions and their deliveries in the shared
+// document store, so whichever instance a queued delivery reaches finds
+// them.
+type DocumentStore struct {
+	subscriptions docstore.Collection[Subscription]
+	deliveries    docstore.Collection[Delivery] // keyed by subscription
+}
+
+// NewDocumentStore keeps subscriptions and deliveries in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{
+		subscriptions: docstore.NewCollection[Subscription](docs, "connector_subscriptions", nil),
+		deliveries: docstore.NewCollection(docs, "connector_deliveries", func(d Delivery) docstore.Keys {
+			return docstore.Keys{"subscription": d.SubscriptionID}
+		}),
+	}
+}
+
+// NewMemoryStore keeps subscriptions in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveSubscription(s Subscription) error {
+	return d.subscriptions.Put(s.ID, s)
+}
+
+func (d *DocumentStore) GetSubscription(id string) (Subscription, error) {
+	s, err := d.subscriptions.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Subscription{}, ErrNotFound
+	}
+	return s, err
+}
+
+// ListSubscriptions returns every subscription by name.
+func (d *DocumentStore) ListSubscriptions() ([]Subscription, error) {
+	out, err := d.subscriptions.Find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (d *DocumentStore) DeleteSubscription(id string) error {
+	if _, err := d.GetSubscription(id); err != nil {
+		return err
+	}
+	return d.subscriptions.Delete(id)
+}
+
+func (d *DocumentStore) SaveDelivery(del Delivery) error {
+	return d.deliveries.Put(del.ID, del)
+}
+
+func (d *DocumentStore) GetDelivery(id string) (Delivery, error) {
+	del, err := d.deliveries.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Delivery{}, ErrNotFound
+	}
+	return del, err
+}
+
+// ListDeliveries returns a subscription's deliveries, newest first.
+func (d *DocumentStore) ListDeliveries(subscriptionID string, limit int) ([]Delivery, error) {
+	out, err := d.newestDeliveries(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// PruneDeliveries deletes all but a subscription's keep newest deliveries.
+func (d *DocumentStore) PruneDeliveries(subscriptionID string, keep int) (int, error) {
+	all, err := d.newestDeliveries(subscriptionID)
+	if err != nil || len(all) <= keep {
+		return 0, err
+	}
+	for _, del := range all[keep:] {
+		if err := d.deliveries.Delete(del.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(all) - keep, nil
+}
+
+func (d *DocumentStore) newestDeliveries(subscriptionID string) ([]Delivery, error) {
+	out, err := d.deliveries.Find(docstore.Keys{"subscription": subscriptionID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
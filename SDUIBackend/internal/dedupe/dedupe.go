@@ -0,0 +1,139 @@
+// Package dedupe finds customers and jobs entered twice, such as "123 Main
+// St" imported from the CRM and "123 Main Street" typed in the field, and
+// merges them. Scans queue likely pairs for review; merging one rewrites
+// every reference to the duplicate to point at the record kept.
+package dedupe
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of records compared.
+const (
+	// KindCustomer compares the customers of route stops, by customer ID.
+	KindCustomer = "customer"
+	// KindJob compares jobs scheduled on the same day.
+	KindJob = "job"
+)
+
+// Candidate statuses.
+const (
+	StatusPending   = "pending"
+	StatusMerged    = "merged"
+	StatusDismissed = "dismissed" // not duplicates; scans do not raise the pair again
+)
+
+var (
+	// ErrNotFound is returned when a candidate, customer or job does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidMerge wraps merge requests that name the wrong records.
+	ErrInvalidMerge = errors.New("invalid merge")
+	// ErrConflict is returned when a candidate was already reviewed or the
+	// records cannot be merged as they are.
+	ErrConflict = errors.New("conflict")
+)
+
+// Record is one side of a candidate pair as it was when detected.
+type Record struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Date    string `json:"date,omitempty"` // jobs' scheduled date, 2006-01-02
+}
+
+// Candidate is a pair of records that look like the same customer or job.
+type Candidate struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Records [2]Record `json:"records"`
+	// Score is how similar the names and addresses are, from 0 to 1.
+	Score      float64      `json:"score"`
+	Status     string       `json:"status"`
+	DetectedAt time.Time    `json:"detectedAt"`
+	ReviewedAt *time.Time   `json:"reviewedAt,omitempty"`
+	ReviewedBy string       `json:"reviewedBy,omitempty"`
+	Note       string       `json:"note,omitempty"`
+	Merge      *MergeResult `json:"merge,omitempty"`
+}
+
+// involves reports whether id is one of the candidate's records.
+func (c Candidate) involves(id string) bool {
+	return c.Records[0].ID == id || c.Records[1].ID == id
+}
+
+// MergeResult reports what a merge rewrote.
+type MergeResult struct {
+	Kind            string `json:"kind"`
+	FromID          string `json:"fromId"`
+	IntoID          string `json:"intoId"`
+	RoutesUpdated   int    `json:"routesUpdated,omitempty"`
+	JobsUpdated     int    `json:"jobsUpdated,omitempty"`
+	TreatmentsMoved int    `json:"treatmentsMoved,omitempty"`
+	InvoiceMoved    bool   `json:"invoiceMoved,omitempty"`
+}
+
+// Store persists candidates.
+type Store interface {
+	SaveCandidate(c Candidate) error
+	GetCandidate(id string) (Candidate, error)
+	// ListCandidates returns every candidate, most recently detected first.
+	ListCandidates() ([]Candidate, error)
+	DeleteCandidate(id string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	candidates map[string]Candidate
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{candidates: make(map[string]Candidate)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveCandidate(c Candidate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.candidates[c.ID] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCandidate(id string) (Candidate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.candidates[id]
+	if !ok {
+		return Candidate{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) ListCandidates() ([]Candidate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Candidate, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DetectedAt.Equal(out[j].DetectedAt) {
+			return out[i].DetectedAt.After(out[j].DetectedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteCandidate(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.candidates, id)
+	return nil
+}
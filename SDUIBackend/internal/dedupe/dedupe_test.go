@@ -0,0 +1,108 @@
+package dedupe
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/profit"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+type failingBatches struct {
+	repository.SyncRepository
+}
+
+func (failingBatches) SaveBatch(repository.Batch) error { return errors.New("datastore unavailable") }
+
+func TestScanAndMergeRewritesReferences(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	_ = store.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day, CustomerStops: []models.RouteStop{
+		{CustomerID: "c1", CustomerName: "Smith Residence", Address: "123 Main St", Latitude: 30.1, Longitude: -97.1},
+		{CustomerID: "c3", CustomerName: "Smith Residence", Address: "125 Main St"},
+	}})
+	_ = store.SaveRoute(models.Route{TechnicianID: "tech-2", ServiceDate: day, CustomerStops: []models.RouteStop{
+		{CustomerID: "c2", CustomerName: "Smith Residence", Address: "123 Main Street."},
+	}})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j1", CustomerName: "Smith Residence", Address: "123 Main St", ScheduledDate: day})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j2", CustomerName: "Smith Residence", Address: "123 Main Street", ScheduledDate: day.Add(3 * time.Hour)})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "j3", CustomerName: "Smith Residence", Address: "123 Main Street.", ScheduledDate: day.AddDate(0, 0, 7)})
+	_ = store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t1", JobID: "j2", ChemicalID: "chem-1"})
+
+	invoices := profit.NewMemoryStore()
+	profits := profit.NewService(config.ProfitConfig{}, invoices, repos, nil, nil, nil, nil)
+	_ = invoices.SaveInvoices(profit.Invoice{JobID: "j1", Amount: 120}, profit.Invoice{JobID: "j2", Amount: 95})
+
+	cfg := config.DedupeConfig{Enabled: true, Threshold: 0.9, ScanInterval: time.Hour}
+	svc := NewService(cfg, NewMemoryStore(), repos, profits, nil)
+	if n, err := svc.Scan(); err != nil || n != 2 {
+		t.Fatalf("expected a customer and a job pair, got %d, %v", n, err)
+	}
+	if n, _ := svc.Scan(); n != 0 {
+		t.Fatalf("expected a rescan to queue nothing new, got %d", n)
+	}
+	jobPairs, _ := svc.Candidates(StatusPending, KindJob)
+	customerPairs, _ := svc.Candidates(StatusPending, KindCustomer)
+	if len(jobPairs) != 1 || len(customerPairs) != 1 || customerPairs[0].Records[1].ID != "c2" {
+		t.Fatalf("unexpected candidates %+v %+v", jobPairs, customerPairs)
+	}
+
+	if _, err := svc.MergeCandidate(jobPairs[0].ID, "j1", "office", ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected both invoices to conflict, got %v", err)
+	}
+	_ = invoices.DeleteInvoice("j1")
+
+	failing := svc.repos
+	failing.Sync = failingBatches{store}
+	svc.repos = failing
+	if _, err := svc.MergeCandidate(jobPairs[0].ID, "j1", "office", ""); err == nil {
+		t.Fatal("expected the failed batch to fail the merge")
+	}
+	treatments, _ := store.ListTreatmentUpdatesSince(time.Time{})
+	if inv, err := profits.Invoice("j2"); err != nil || inv.Amount != 95 || treatments[0].JobID != "j2" {
+		t.Fatalf("expected nothing of the failed merge kept, got %+v, %v, %+v", inv, err, treatments)
+	}
+	svc.repos = repos
+
+	merged, err := svc.MergeCandidate(jobPairs[0].ID, "j1", "office", "entered twice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Status != StatusMerged || merged.Merge.TreatmentsMoved != 1 || !merged.Merge.InvoiceMoved {
+		t.Fatalf("unexpected merge %+v", merged)
+	}
+	treatments, _ = store.ListTreatmentUpdatesSince(time.Time{})
+	if inv, err := profits.Invoice("j1"); err != nil || inv.Amount != 95 || treatments[0].JobID != "j1" {
+		t.Fatalf("expected the treatment and invoice on j1, got %+v, %v, %+v", inv, err, treatments)
+	}
+	if deleted, _ := store.ListDeletionsSince(time.Time{}); len(deleted) != 1 || deleted[0].ID != "j2" {
+		t.Fatalf("expected j2 to be deleted, got %+v", deleted)
+	}
+	if _, err := svc.MergeCandidate(jobPairs[0].ID, "j1", "office", ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected a reviewed candidate to be rejected, got %v", err)
+	}
+
+	res, err := svc.Merge(KindCustomer, "c2", "c1")
+	if err != nil || res.RoutesUpdated != 1 || res.JobsUpdated != 1 {
+		t.Fatalf("unexpected customer merge %+v, %v", res, err)
+	}
+	route, _ := store.GetRoute("tech-2", day)
+	if st := route.CustomerStops[0]; st.CustomerID != "c1" || st.Address != "123 Main St" || st.Latitude != 30.1 {
+		t.Fatalf("unexpected stop %+v", st)
+	}
+	jobs, _ := store.ListJobUpdatesSince(time.Time{})
+	for _, j := range jobs {
+		if j.ID == "j3" && j.Address != "123 Main St" {
+			t.Fatalf("expected j3 to take c1's address, got %+v", j)
+		}
+	}
+	if pending, _ := svc.Candidates(StatusPending, ""); len(pending) != 0 {
+		t.Fatalf("expected the merged customer's pair to be dropped, got %+v", pending)
+	}
+}
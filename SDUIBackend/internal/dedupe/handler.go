@@ -0,0 +1,161 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the duplicate review queue and merges in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a dedupe handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListCandidates)
+	r.Post("/scan", h.Scan)
+	r.Post("/merge", h.Merge)
+	r.Get("/{candidateId}", h.GetCandidate)
+	r.Post("/{candidateId}/merge", h.MergeCandidate)
+	r.Post("/{candidateId}/dismiss", h.Dismiss)
+}
+
+// ListCandidates returns candidates with ?status= (default pending, or all)
+// and ?kind= (customer or job), most recently detected first.
+func (h *Handler) ListCandidates(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = StatusPending
+	case "all":
+		status = ""
+	case StatusPending, StatusMerged, StatusDismissed:
+	default:
+		respond.Error(w, http.StatusBadRequest, "invalid status", "expected pending, merged, dismissed or all")
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && kind != KindCustomer && kind != KindJob {
+		respond.Error(w, http.StatusBadRequest, "invalid kind", "expected customer or job")
+		return
+	}
+	candidates, err := h.service.Candidates(status, kind)
+	if err != nil {
+		h.fail(w, r, "failed to list duplicates", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"candidates": candidates})
+}
+
+// Scan looks for duplicates now rather than at the next scheduled scan.
+func (h *Handler) Scan(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.Scan()
+	if err != nil {
+		h.fail(w, r, "failed to scan for duplicates", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"queued": n})
+}
+
+// GetCandidate returns candidate {candidateId}.
+func (h *Handler) GetCandidate(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Candidate(chi.URLParam(r, "candidateId"))
+	if err != nil {
+		h.fail(w, r, "failed to load duplicate", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// Merge merges {"kind", "fromId", "intoId"} directly.
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Kind   string `json:"kind"`
+		FromID string `json:"fromId"`
+		IntoID string `json:"intoId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	res, err := h.service.Merge(payload.Kind, payload.FromID, payload.IntoID)
+	if err != nil {
+		h.fail(w, r, "failed to merge records", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, res)
+}
+
+// MergeCandidate merges the candidate's other record into {"keepId"}, with
+// an optional {"note"}.
+func (h *Handler) MergeCandidate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		KeepID string `json:"keepId"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	c, err := h.service.MergeCandidate(chi.URLParam(r, "candidateId"), payload.KeepID, reviewer(r), payload.Note)
+	if err != nil {
+		h.fail(w, r, "failed to merge records", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// Dismiss marks the candidate's records as distinct, with an optional
+// {"note"}.
+func (h *Handler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+			return
+		}
+	}
+	c, err := h.service.Dismiss(chi.URLParam(r, "candidateId"), reviewer(r), payload.Note)
+	if err != nil {
+		h.fail(w, r, "failed to dismiss duplicate", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+func reviewer(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		return id.Subject
+	}
+	return "admin"
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidMerge):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, ErrConflict):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
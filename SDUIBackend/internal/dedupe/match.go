@@ -0,0 +1,134 @@
+package dedupe
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// abbreviations maps words to the USPS abbreviations they are compared as.
+var abbreviations = map[string]string{
+	"street": "st", "avenue": "ave", "av": "ave", "road": "rd", "drive": "dr",
+	"boulevard": "blvd", "lane": "ln", "court": "ct", "place": "pl",
+	"circle": "cir", "highway": "hwy", "parkway": "pkwy", "terrace": "ter",
+	"trail": "trl", "square": "sq", "north": "n", "south": "s", "east": "e",
+	"west": "w", "northeast": "ne", "northwest": "nw", "southeast": "se",
+	"southwest": "sw", "apartment": "apt", "suite": "ste",
+}
+
+// normalize lowercases s, drops punctuation and abbreviates street words.
+func normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		if short, ok := abbreviations[f]; ok {
+			fields[i] = short
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// similarity compares normalized strings by edit distance: 1 when equal, 0
+// when nothing is shared or either is empty.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// identity is a record's normalized name and address.
+type identity struct {
+	name, address string
+}
+
+func identify(name, address string) identity {
+	return identity{name: normalize(name), address: normalize(address)}
+}
+
+// score weighs address similarity over name similarity, since names are
+// spelt more freely ("Smith" and "John Smith"); without both names it is
+// the address similarity alone.
+func score(a, b identity) float64 {
+	addr := similarity(a.address, b.address)
+	if a.name == "" || b.name == "" {
+		return addr
+	}
+	return 0.7*addr + 0.3*similarity(a.name, b.name)
+}
+
+// block is the key records must share to be compared: the first word of
+// the address, normally the house number, so neighbours at 123 and 125
+// Main St are never paired however alike they read. Jobs also share the
+// scheduled date. Records without an address are not compared.
+func block(r Record) string {
+	first, _, _ := strings.Cut(normalize(r.Address), " ")
+	if first == "" {
+		return ""
+	}
+	return r.Date + "|" + first
+}
+
+// detect pairs records of the same block scoring at least threshold.
+func detect(kind string, records []Record, threshold float64) []Candidate {
+	blocks := make(map[string][]Record)
+	for _, r := range records {
+		if key := block(r); key != "" {
+			blocks[key] = append(blocks[key], r)
+		}
+	}
+	var out []Candidate
+	for _, group := range blocks {
+		sort.Slice(group, func(i, j int) bool { return group[i].ID < group[j].ID })
+		for i := range group {
+			a := identify(group[i].Name, group[i].Address)
+			for j := i + 1; j < len(group); j++ {
+				sc := score(a, identify(group[j].Name, group[j].Address))
+				if sc < threshold {
+					continue
+				}
+				out = append(out, Candidate{
+					ID:      candidateID(kind, group[i].ID, group[j].ID),
+					Kind:    kind,
+					Records: [2]Record{group[i], group[j]},
+					Score:   sc,
+					Status:  StatusPending,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// candidateID is stable across scans so reviews stick to the pair.
+func candidateID(kind, a, b string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(kind+"\x00"+a+"\x00"+b)).String()
+}
@@ -0,0 +1,407 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/profit"
+)
+
+// Service detects duplicates and merges them.
+type Service struct {
+	cfg      config.DedupeConfig
+	store    Store
+	repos    repository.Repository
+	invoices *profit.Service
+	logger   *slog.Logger
+	now      func() time.Time
+
+	// mu serializes scans and merges, so a merge plans against records no
+	// other merge is rewriting.
+	mu sync.Mutex
+}
+
+// NewService wires a dedupe service. Job merges move invoices through
+// invoices; when it is nil, invoices are left where they are.
+func NewService(cfg config.DedupeConfig, store Store, repos repository.Repository, invoices *profit.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, invoices: invoices, logger: logger, now: time.Now}
+}
+
+// Run scans for duplicates every ScanInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.ScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.Scan(); err != nil {
+				s.logger.Error("scan for duplicates", slog.Any("error", err))
+			} else if n > 0 {
+				s.logger.Info("duplicates queued for review", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// Scan compares every customer and job, queues pairs not seen before and
+// drops pending pairs that no longer match. It returns how many pairs were
+// queued.
+func (s *Service) Scan() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routes, err := s.repos.Sync.ListRouteUpdatesSince(time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("list routes: %w", err)
+	}
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("list jobs: %w", err)
+	}
+	var customers []Record
+	for id, stop := range latestStops(routes) {
+		customers = append(customers, Record{ID: id, Name: stop.CustomerName, Address: stop.Address})
+	}
+	jobRecords := make([]Record, 0, len(jobs))
+	for _, j := range jobs {
+		if j.ID != "" {
+			jobRecords = append(jobRecords, Record{ID: j.ID, Name: j.CustomerName, Address: j.Address, Date: j.ScheduledDate.UTC().Format("2006-01-02")})
+		}
+	}
+	found := append(detect(KindCustomer, customers, s.cfg.Threshold), detect(KindJob, jobRecords, s.cfg.Threshold)...)
+
+	existing, err := s.store.ListCandidates()
+	if err != nil {
+		return 0, err
+	}
+	known := make(map[string]Candidate, len(existing))
+	for _, c := range existing {
+		known[c.ID] = c
+	}
+	now := s.now().UTC()
+	queued := 0
+	for _, c := range found {
+		old, ok := known[c.ID]
+		delete(known, c.ID)
+		switch {
+		case ok && old.Status != StatusPending:
+			continue
+		case ok:
+			c.DetectedAt = old.DetectedAt
+		default:
+			c.DetectedAt = now
+			queued++
+		}
+		if err := s.store.SaveCandidate(c); err != nil {
+			return queued, err
+		}
+	}
+	for id, c := range known {
+		if c.Status == StatusPending {
+			if err := s.store.DeleteCandidate(id); err != nil {
+				return queued, err
+			}
+		}
+	}
+	return queued, nil
+}
+
+// latestStops returns each customer's stop on their latest route.
+func latestStops(routes []models.Route) map[string]models.RouteStop {
+	stops := make(map[string]models.RouteStop)
+	dates := make(map[string]time.Time)
+	for _, r := range routes {
+		for _, st := range r.CustomerStops {
+			if st.CustomerID == "" {
+				continue
+			}
+			if d, ok := dates[st.CustomerID]; !ok || r.ServiceDate.After(d) {
+				stops[st.CustomerID] = st
+				dates[st.CustomerID] = r.ServiceDate
+			}
+		}
+	}
+	return stops
+}
+
+// Candidates returns candidates with status, or all when status is empty,
+// and kind, or both kinds when kind is empty, most recently detected first.
+func (s *Service) Candidates(status, kind string) ([]Candidate, error) {
+	all, err := s.store.ListCandidates()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Candidate, 0, len(all))
+	for _, c := range all {
+		if (status == "" || c.Status == status) && (kind == "" || c.Kind == kind) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Candidate returns a single candidate.
+func (s *Service) Candidate(id string) (Candidate, error) {
+	return s.store.GetCandidate(id)
+}
+
+// Dismiss records that a pending candidate's records are not duplicates.
+func (s *Service) Dismiss(id, reviewer, note string) (Candidate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.pending(id)
+	if err != nil {
+		return Candidate{}, err
+	}
+	s.review(&c, StatusDismissed, reviewer, note)
+	return c, s.store.SaveCandidate(c)
+}
+
+// MergeCandidate merges a pending candidate's other record into keepID.
+func (s *Service) MergeCandidate(id, keepID, reviewer, note string) (Candidate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.pending(id)
+	if err != nil {
+		return Candidate{}, err
+	}
+	var from string
+	switch keepID {
+	case c.Records[0].ID:
+		from = c.Records[1].ID
+	case c.Records[1].ID:
+		from = c.Records[0].ID
+	default:
+		return Candidate{}, fmt.Errorf("%w: keepId must be %s or %s", ErrInvalidMerge, c.Records[0].ID, c.Records[1].ID)
+	}
+	res, err := s.merge(c.Kind, from, keepID)
+	if err != nil {
+		return Candidate{}, err
+	}
+	s.review(&c, StatusMerged, reviewer, note)
+	c.Merge = &res
+	return c, s.store.SaveCandidate(c)
+}
+
+// Merge merges customer or job fromID into intoID, whether or not a scan
+// paired them.
+func (s *Service) Merge(kind, fromID, intoID string) (MergeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.merge(kind, fromID, intoID)
+}
+
+func (s *Service) pending(id string) (Candidate, error) {
+	c, err := s.store.GetCandidate(id)
+	if err != nil {
+		return Candidate{}, err
+	}
+	if c.Status != StatusPending {
+		return Candidate{}, fmt.Errorf("%w: candidate is already %s", ErrConflict, c.Status)
+	}
+	return c, nil
+}
+
+func (s *Service) review(c *Candidate, status, reviewer, note string) {
+	now := s.now().UTC()
+	c.Status = status
+	c.ReviewedAt = &now
+	c.ReviewedBy = reviewer
+	c.Note = note
+}
+
+// merge plans every write first, so missing records and conflicts fail the
+// merge before anything is written, then stores the records in one batch.
+// An invoice lives in another store: it is moved before the batch and moved
+// back if the batch fails.
+func (s *Service) merge(kind, fromID, intoID string) (MergeResult, error) {
+	if fromID == "" || intoID == "" || fromID == intoID {
+		return MergeResult{}, fmt.Errorf("%w: fromId and intoId must be two different records", ErrInvalidMerge)
+	}
+	res := MergeResult{Kind: kind, FromID: fromID, IntoID: intoID}
+	var batch repository.Batch
+	var err error
+	switch kind {
+	case KindCustomer:
+		batch, err = s.planCustomers(&res)
+	case KindJob:
+		batch, err = s.planJobs(&res)
+	default:
+		return MergeResult{}, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidMerge, KindCustomer, KindJob)
+	}
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if res.InvoiceMoved {
+		if _, err := s.invoices.MoveInvoice(fromID, intoID); err != nil {
+			return MergeResult{}, fmt.Errorf("merge %s %s into %s: move invoice: %w", kind, fromID, intoID, err)
+		}
+	}
+	if err := s.repos.Sync.SaveBatch(batch); err != nil {
+		if res.InvoiceMoved {
+			if _, uerr := s.invoices.MoveInvoice(intoID, fromID); uerr != nil {
+				s.logger.Error("move invoice back after a failed merge", slog.String("kind", kind), slog.String("from", fromID), slog.String("into", intoID), slog.Any("error", uerr))
+			}
+		}
+		return MergeResult{}, fmt.Errorf("merge %s %s into %s: %w", kind, fromID, intoID, err)
+	}
+	s.forget(kind, fromID)
+	s.logger.Info("records merged", slog.String("kind", kind), slog.String("from", fromID), slog.String("into", intoID))
+	return res, nil
+}
+
+// forget drops pending candidates pairing a record that was merged away.
+func (s *Service) forget(kind, id string) {
+	all, err := s.store.ListCandidates()
+	if err != nil {
+		s.logger.Error("list duplicate candidates", slog.Any("error", err))
+		return
+	}
+	for _, c := range all {
+		if c.Kind == kind && c.Status == StatusPending && c.involves(id) {
+			if err := s.store.DeleteCandidate(c.ID); err != nil {
+				s.logger.Error("delete duplicate candidate", slog.String("candidate", c.ID), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// planCustomers points every route stop of customer FromID at IntoID, with
+// IntoID's name and address, and renames the jobs recorded under FromID's
+// names and addresses. Treatments and invoices belong to jobs and follow
+// them.
+func (s *Service) planCustomers(res *MergeResult) (repository.Batch, error) {
+	routes, err := s.repos.Sync.ListRouteUpdatesSince(time.Time{})
+	if err != nil {
+		return repository.Batch{}, fmt.Errorf("list routes: %w", err)
+	}
+	latest := latestStops(routes)
+	into, ok := latest[res.IntoID]
+	if !ok {
+		return repository.Batch{}, fmt.Errorf("%w: customer %s", ErrNotFound, res.IntoID)
+	}
+	if _, ok := latest[res.FromID]; !ok {
+		return repository.Batch{}, fmt.Errorf("%w: customer %s", ErrNotFound, res.FromID)
+	}
+	now := s.now().UTC()
+	aliases := make(map[identity]bool)
+	var batch repository.Batch
+	for _, r := range routes {
+		updated := r
+		updated.CustomerStops = make([]models.RouteStop, len(r.CustomerStops))
+		copy(updated.CustomerStops, r.CustomerStops)
+		changed := false
+		for i, st := range updated.CustomerStops {
+			if st.CustomerID != res.FromID {
+				continue
+			}
+			aliases[identify(st.CustomerName, st.Address)] = true
+			st.CustomerID, st.CustomerName, st.Address = into.CustomerID, into.CustomerName, into.Address
+			if into.HasCoordinates() {
+				st.Latitude, st.Longitude = into.Latitude, into.Longitude
+			}
+			updated.CustomerStops[i] = st
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		updated.LastModified = now
+		batch.Routes = append(batch.Routes, updated)
+		res.RoutesUpdated++
+	}
+
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return repository.Batch{}, fmt.Errorf("list jobs: %w", err)
+	}
+	for _, j := range jobs {
+		if !aliases[identify(j.CustomerName, j.Address)] || (j.CustomerName == into.CustomerName && j.Address == into.Address) {
+			continue
+		}
+		j.CustomerName, j.Address = into.CustomerName, into.Address
+		batch.Jobs = append(batch.Jobs, j)
+		res.JobsUpdated++
+	}
+	return batch, nil
+}
+
+// planJobs moves job FromID's treatments and invoice to IntoID and deletes
+// FromID, leaving a tombstone so devices drop it.
+func (s *Service) planJobs(res *MergeResult) (repository.Batch, error) {
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return repository.Batch{}, fmt.Errorf("list jobs: %w", err)
+	}
+	fromFound, intoFound := false, false
+	for _, j := range jobs {
+		switch j.ID {
+		case res.FromID:
+			fromFound = true
+		case res.IntoID:
+			intoFound = true
+		}
+	}
+	if !fromFound {
+		return repository.Batch{}, fmt.Errorf("%w: job %s", ErrNotFound, res.FromID)
+	}
+	if !intoFound {
+		return repository.Batch{}, fmt.Errorf("%w: job %s", ErrNotFound, res.IntoID)
+	}
+	if s.invoices != nil {
+		if res.InvoiceMoved, err = s.hasInvoice(res.FromID); err != nil {
+			return repository.Batch{}, err
+		}
+		taken, err := s.hasInvoice(res.IntoID)
+		if err != nil {
+			return repository.Batch{}, err
+		}
+		if res.InvoiceMoved && taken {
+			return repository.Batch{}, fmt.Errorf("%w: both jobs are invoiced; remove one invoice before merging", ErrConflict)
+		}
+	}
+
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return repository.Batch{}, fmt.Errorf("list treatments: %w", err)
+	}
+	now := s.now().UTC()
+	var batch repository.Batch
+	for _, t := range treatments {
+		if t.JobID != res.FromID {
+			continue
+		}
+		t.LastModified, t.JobID = now, res.IntoID
+		batch.Treatments = append(batch.Treatments, t)
+		res.TreatmentsMoved++
+	}
+	batch.DeletedJobs = []string{res.FromID}
+	return batch, nil
+}
+
+func (s *Service) hasInvoice(jobID string) (bool, error) {
+	_, err := s.invoices.Invoice(jobID)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, profit.ErrNotFound):
+		return false, nil
+	default:
+		return false, fmt.Errorf("load invoice of job %s: %w", jobID, err)
+	}
+}
@@ -0,0 +1,273 @@
+// Package disposal records what technicians do with pesticide waste: empty
+// containers rinsed and where they went, and leftover product taken to a
+// disposal site. Jurisdictions can require extra fields on top of the
+// baseline record.
+package disposal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rinse methods for empty containers.
+const (
+	RinseTriple   = "triple"
+	RinsePressure = "pressure"
+	RinseNone     = "none" // refillable or returnable containers
+)
+
+var (
+	// ErrNotFound is returned when a disposal or jurisdiction does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidDisposal wraps disposal validation failures.
+	ErrInvalidDisposal = errors.New("invalid disposal")
+	// ErrInvalidJurisdiction wraps jurisdiction validation failures.
+	ErrInvalidJurisdiction = errors.New("invalid jurisdiction")
+)
+
+// Site is where waste was taken.
+type Site struct {
+	Name         string `json:"name"`
+	Address      string `json:"address,omitempty"`
+	PermitNumber string `json:"permitNumber,omitempty"` // facility permit or EPA ID
+}
+
+// Disposal is one disposal event logged by a technician.
+type Disposal struct {
+	ID               string `json:"id"`
+	TechnicianID     string `json:"technicianId"`
+	ChemicalID       string `json:"chemicalId"`
+	ChemicalName     string `json:"chemicalName,omitempty"`
+	EPARegistration  string `json:"epaRegistration,omitempty"`
+	LotNumber        string `json:"lotNumber,omitempty"`
+	Jurisdiction     string `json:"jurisdiction,omitempty"`
+	ContainerType    string `json:"containerType,omitempty"` // e.g. 1 gal HDPE jug
+	ContainersRinsed int    `json:"containersRinsed"`
+	RinseMethod      string `json:"rinseMethod,omitempty"`
+	// RinsateDisposition says what happened to the rinse water, e.g. added
+	// to the spray tank and applied to a labelled site.
+	RinsateDisposition string    `json:"rinsateDisposition,omitempty"`
+	Quantity           float64   `json:"quantity"` // leftover product disposed of
+	UnitOfMeasure      string    `json:"unitOfMeasure,omitempty"`
+	Site               Site      `json:"site"`
+	ManifestNumber     string    `json:"manifestNumber,omitempty"`
+	Notes              string    `json:"notes,omitempty"`
+	DisposedAt         time.Time `json:"disposedAt"`
+	RecordedAt         time.Time `json:"recordedAt"`
+}
+
+// Validate checks the baseline fields every jurisdiction requires.
+func (d Disposal) Validate() error {
+	var problems []string
+	if d.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if d.ChemicalID == "" {
+		problems = append(problems, "chemicalId is required")
+	}
+	if d.DisposedAt.IsZero() {
+		problems = append(problems, "disposedAt is required")
+	}
+	if d.ContainersRinsed < 0 || d.Quantity < 0 {
+		problems = append(problems, "containersRinsed and quantity must be >= 0")
+	} else if d.ContainersRinsed == 0 && d.Quantity == 0 {
+		problems = append(problems, "containersRinsed or quantity is required")
+	}
+	switch d.RinseMethod {
+	case "", RinseTriple, RinsePressure, RinseNone:
+	default:
+		problems = append(problems, "rinseMethod must be triple, pressure or none")
+	}
+	if d.ContainersRinsed > 0 && d.RinseMethod == "" {
+		problems = append(problems, "rinseMethod is required when containers are disposed of")
+	}
+	if strings.TrimSpace(d.Site.Name) == "" {
+		problems = append(problems, "site.name is required")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidDisposal, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// fields are the optional disposal fields a jurisdiction can require.
+var fields = map[string]func(Disposal) bool{
+	"containerType":      func(d Disposal) bool { return d.ContainerType != "" },
+	"lotNumber":          func(d Disposal) bool { return d.LotNumber != "" },
+	"manifestNumber":     func(d Disposal) bool { return d.ManifestNumber != "" },
+	"notes":              func(d Disposal) bool { return d.Notes != "" },
+	"rinsateDisposition": func(d Disposal) bool { return d.ContainersRinsed == 0 || d.RinsateDisposition != "" },
+	"site.address":       func(d Disposal) bool { return d.Site.Address != "" },
+	"site.permitNumber":  func(d Disposal) bool { return d.Site.PermitNumber != "" },
+}
+
+// Fields lists the field names a jurisdiction can require.
+func Fields() []string {
+	out := make([]string, 0, len(fields))
+	for name := range fields {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Jurisdiction holds the extra fields a state or county requires on
+// disposal records. Code matches a branch's region (or a technician's
+// legacy region), compared case-insensitively.
+type Jurisdiction struct {
+	Code           string    `json:"code"`
+	Name           string    `json:"name,omitempty"`
+	RequiredFields []string  `json:"requiredFields"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Validate checks a jurisdiction.
+func (j Jurisdiction) Validate() error {
+	var problems []string
+	if j.Code == "" {
+		problems = append(problems, "code is required")
+	} else if strings.ContainsAny(j.Code, "/ ") {
+		problems = append(problems, "code must not contain slashes or spaces")
+	}
+	for _, f := range j.RequiredFields {
+		if _, ok := fields[f]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown field %q (expected one of %s)", f, strings.Join(Fields(), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidJurisdiction, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Missing lists the required fields d lacks.
+func (j Jurisdiction) Missing(d Disposal) []string {
+	var missing []string
+	for _, f := range j.RequiredFields {
+		if present, ok := fields[f]; ok && !present(d) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// Filter narrows a disposal listing. Zero values match everything; To is
+// exclusive.
+type Filter struct {
+	TechnicianID string
+	Jurisdiction string
+	From, To     time.Time
+}
+
+func (f Filter) match(d Disposal) bool {
+	return (f.TechnicianID == "" || d.TechnicianID == f.TechnicianID) &&
+		(f.Jurisdiction == "" || d.Jurisdiction == f.Jurisdiction) &&
+		(f.From.IsZero() || !d.DisposedAt.Before(f.From)) &&
+		(f.To.IsZero() || d.DisposedAt.Before(f.To))
+}
+
+// Store persists disposals and jurisdiction requirements.
+type Store interface {
+	SaveDisposal(d Disposal) error
+	GetDisposal(id string) (Disposal, error)
+	// ListDisposals returns matching disposals, oldest first.
+	ListDisposals(f Filter) ([]Disposal, error)
+
+	SaveJurisdiction(j Jurisdiction) error
+	GetJurisdiction(code string) (Jurisdiction, error)
+	ListJurisdictions() ([]Jurisdiction, error)
+	DeleteJurisdiction(code string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	disposals     map[string]Disposal
+	jurisdictions map[string]Jurisdiction
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{disposals: make(map[string]Disposal), jurisdictions: make(map[string]Jurisdiction)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveDisposal(d Disposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disposals[d.ID] = d
+	return nil
+}
+
+func (m *MemoryStore) GetDisposal(id string) (Disposal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.disposals[id]
+	if !ok {
+		return Disposal{}, ErrNotFound
+	}
+	return d, nil
+}
+
+func (m *MemoryStore) ListDisposals(f Filter) ([]Disposal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Disposal{}
+	for _, d := range m.disposals {
+		if f.match(d) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DisposedAt.Equal(out[j].DisposedAt) {
+			return out[i].DisposedAt.Before(out[j].DisposedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) SaveJurisdiction(j Jurisdiction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jurisdictions[j.Code] = j
+	return nil
+}
+
+func (m *MemoryStore) GetJurisdiction(code string) (Jurisdiction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jurisdictions[code]
+	if !ok {
+		return Jurisdiction{}, ErrNotFound
+	}
+	return j, nil
+}
+
+func (m *MemoryStore) ListJurisdictions() ([]Jurisdiction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Jurisdiction, 0, len(m.jurisdictions))
+	for _, j := range m.jurisdictions {
+		out = append(out, j)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteJurisdiction(code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jurisdictions[code]; !ok {
+		return ErrNotFound
+	}
+	delete(m.jurisdictions, code)
+	return nil
+}
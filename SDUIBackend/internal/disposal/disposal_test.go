@@ -0,0 +1,82 @@
+package disposal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestDisposalsFollowJurisdictionRules(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	branches := branch.NewService(branch.NewMemoryStore(), repos, nil)
+	if _, err := branches.Save("sac", branch.Branch{Name: "Sacramento", Region: "ca", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+	store.AddTechnician(models.Technician{ID: "tech-a", BranchID: "sac"})
+	store.AddTechnician(models.Technician{ID: "tech-b", Region: "NV"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "a-termidor", TechnicianID: "tech-a", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "gal"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "b-talstar", TechnicianID: "tech-b", Name: "Talstar P", EPARegistration: "279-3206", UnitOfMeasure: "oz"})
+
+	svc := NewService(NewMemoryStore(), repos, branches, nil)
+	now := time.Date(2026, 4, 2, 17, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.SaveJurisdiction("ca", Jurisdiction{RequiredFields: []string{"site.permitNumber", "colour"}}); !errors.Is(err, ErrInvalidJurisdiction) {
+		t.Fatalf("expected an unknown field rejected, got %v", err)
+	}
+	ca, err := svc.SaveJurisdiction("ca", Jurisdiction{Name: "California", RequiredFields: []string{"site.permitNumber", "rinsateDisposition", "site.permitNumber"}})
+	if err != nil || ca.Code != "CA" || !reflect.DeepEqual(ca.RequiredFields, []string{"rinsateDisposition", "site.permitNumber"}) {
+		t.Fatalf("unexpected jurisdiction %+v (%v)", ca, err)
+	}
+
+	rinse := Disposal{ChemicalID: "a-termidor", ContainersRinsed: 3, RinseMethod: "Triple", Site: Site{Name: "County HHW"}, DisposedAt: now.Add(-time.Hour)}
+	for name, d := range map[string]Disposal{
+		"nothing disposed": {ChemicalID: "a-termidor", Site: Site{Name: "County HHW"}, DisposedAt: now},
+		"no rinse method":  {ChemicalID: "a-termidor", ContainersRinsed: 1, Site: Site{Name: "County HHW"}, DisposedAt: now},
+		"future":           {ChemicalID: "a-termidor", ContainersRinsed: 1, RinseMethod: RinseTriple, Site: Site{Name: "County HHW"}, DisposedAt: now.Add(time.Hour)},
+		"not on truck":     {ChemicalID: "b-talstar", ContainersRinsed: 1, RinseMethod: RinseTriple, Site: Site{Name: "County HHW"}, DisposedAt: now},
+		"missing CA field": rinse,
+	} {
+		if _, err := svc.Log("tech-a", d); !errors.Is(err, ErrInvalidDisposal) {
+			t.Errorf("%s: expected invalid disposal, got %v", name, err)
+		}
+	}
+
+	rinse.Site.PermitNumber, rinse.RinsateDisposition = "CAD000000001", "added to spray tank"
+	logged, err := svc.Log("tech-a", rinse)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logged.Jurisdiction != "CA" || logged.ChemicalName != "Termidor SC" || logged.UnitOfMeasure != "gal" || logged.RinseMethod != RinseTriple {
+		t.Fatalf("unexpected disposal %+v", logged)
+	}
+	// Jurisdictions without rules only need the baseline fields.
+	if _, err := svc.Log("tech-b", Disposal{ChemicalID: "b-talstar", Quantity: 4, Site: Site{Name: "Reno HHW"}, DisposedAt: now}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Rules added later flag existing records in the report.
+	if _, err := svc.SaveJurisdiction("CA", Jurisdiction{RequiredFields: []string{"site.permitNumber", "containerType"}}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := svc.Report(Filter{Jurisdiction: "ca", From: now.Add(-24 * time.Hour), To: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Disposals != 1 || report.ContainersRinsed != 3 || len(report.ByChemical) != 1 || len(report.BySite) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.Incomplete) != 1 || !reflect.DeepEqual(report.Incomplete[0].Missing, []string{"containerType"}) {
+		t.Fatalf("expected the record flagged for containerType, got %+v", report.Incomplete)
+	}
+	if all, _ := svc.Report(Filter{}); all.Disposals != 2 || len(all.ByChemical) != 2 {
+		t.Fatalf("expected both disposals, got %+v", all)
+	}
+}
@@ -0,0 +1,193 @@
+package disposal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes disposal logging to technicians and disposal records,
+// jurisdiction requirements and compliance reports in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a disposal handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListDisposals)
+	r.Get("/report", h.GetReport)
+	r.Get("/jurisdictions", h.ListJurisdictions)
+	r.Get("/jurisdictions/{code}", h.GetJurisdiction)
+	r.Put("/jurisdictions/{code}", h.SaveJurisdiction)
+	r.Delete("/jurisdictions/{code}", h.DeleteJurisdiction)
+	r.Get("/{disposalId}", h.GetDisposal)
+}
+
+// LogDisposal records a disposal by the authenticated technician.
+func (h *Handler) LogDisposal(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var payload Disposal
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	d, err := h.service.Log(me, payload)
+	if err != nil {
+		h.fail(w, r, "failed to log disposal", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, d)
+}
+
+// ListMyDisposals returns the authenticated technician's disposals between
+// ?from= and ?to=.
+func (h *Handler) ListMyDisposals(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	f, ok := filter(w, r)
+	if !ok {
+		return
+	}
+	f.TechnicianID, f.Jurisdiction = me, ""
+	disposals, err := h.service.Disposals(f)
+	if err != nil {
+		h.fail(w, r, "failed to list disposals", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"disposals": disposals})
+}
+
+// ListDisposals returns disposals, optionally for one ?technicianId= or
+// ?jurisdiction= between ?from= and ?to=.
+func (h *Handler) ListDisposals(w http.ResponseWriter, r *http.Request) {
+	f, ok := filter(w, r)
+	if !ok {
+		return
+	}
+	disposals, err := h.service.Disposals(f)
+	if err != nil {
+		h.fail(w, r, "failed to list disposals", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"disposals": disposals})
+}
+
+// GetDisposal returns a disposal.
+func (h *Handler) GetDisposal(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Disposal(chi.URLParam(r, "disposalId"))
+	if err != nil {
+		h.fail(w, r, "failed to load disposal", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// GetReport returns the compliance report for the same filters as
+// ListDisposals.
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	f, ok := filter(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.service.Report(f)
+	if err != nil {
+		h.fail(w, r, "failed to build disposal report", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// ListJurisdictions returns every jurisdiction's required fields, and the
+// fields that can be required.
+func (h *Handler) ListJurisdictions(w http.ResponseWriter, r *http.Request) {
+	jurisdictions, err := h.service.Jurisdictions()
+	if err != nil {
+		h.fail(w, r, "failed to list jurisdictions", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"jurisdictions": jurisdictions, "fields": Fields()})
+}
+
+// GetJurisdiction returns a jurisdiction's required fields.
+func (h *Handler) GetJurisdiction(w http.ResponseWriter, r *http.Request) {
+	j, err := h.service.Jurisdiction(chi.URLParam(r, "code"))
+	if err != nil {
+		h.fail(w, r, "failed to load jurisdiction", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, j)
+}
+
+// SaveJurisdiction creates or replaces a jurisdiction's required fields.
+func (h *Handler) SaveJurisdiction(w http.ResponseWriter, r *http.Request) {
+	var payload Jurisdiction
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	j, err := h.service.SaveJurisdiction(chi.URLParam(r, "code"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save jurisdiction", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, j)
+}
+
+// DeleteJurisdiction removes a jurisdiction's required fields.
+func (h *Handler) DeleteJurisdiction(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteJurisdiction(chi.URLParam(r, "code")); err != nil {
+		h.fail(w, r, "failed to delete jurisdiction", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// filter reads ?technicianId=, ?jurisdiction=, and RFC 3339 ?from= and ?to=.
+func filter(w http.ResponseWriter, r *http.Request) (Filter, bool) {
+	q := r.URL.Query()
+	f := Filter{TechnicianID: q.Get("technicianId"), Jurisdiction: q.Get("jurisdiction")}
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				respond.Error(w, http.StatusBadRequest, "invalid "+name, "expected RFC 3339")
+				return Filter{}, false
+			}
+			*dst = t
+		}
+	}
+	return f, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidDisposal), errors.Is(err, ErrInvalidJurisdiction):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
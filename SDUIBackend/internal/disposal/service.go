@@ -0,0 +1,292 @@
+package disposal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+)
+
+// clockSkew tolerates device clocks running slightly ahead of the server.
+const clockSkew = 5 * time.Minute
+
+// Service logs disposals against technicians' chemicals and reports them per
+// jurisdiction.
+type Service struct {
+	store    Store
+	repos    repository.Repository
+	branches *branch.Service
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService creates a disposal service. A technician's jurisdiction is their
+// branch's region, or their legacy region when branches is nil or they have
+// no branch.
+func NewService(store Store, repos repository.Repository, branches *branch.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, repos: repos, branches: branches, logger: logger, now: time.Now}
+}
+
+// Log records a disposal by a technician. The chemical must be one of the
+// technician's synced chemicals; its name, EPA registration and (unless
+// given) unit are copied onto the record so it still reads correctly after
+// the chemical is gone.
+// Unless the request names one, the jurisdiction is the technician's, and its
+// required fields must be present.
+func (s *Service) Log(technicianID string, d Disposal) (Disposal, error) {
+	d.TechnicianID = technicianID
+	d.ChemicalID = strings.TrimSpace(d.ChemicalID)
+	d.RinseMethod = strings.ToLower(strings.TrimSpace(d.RinseMethod))
+	d.Site.Name = strings.TrimSpace(d.Site.Name)
+	d.Jurisdiction = normalizeCode(d.Jurisdiction)
+	if err := d.Validate(); err != nil {
+		return Disposal{}, err
+	}
+	now := s.now().UTC()
+	if d.DisposedAt.After(now.Add(clockSkew)) {
+		return Disposal{}, fmt.Errorf("%w: disposedAt is in the future", ErrInvalidDisposal)
+	}
+
+	chemical, err := s.chemical(technicianID, d.ChemicalID)
+	if err != nil {
+		return Disposal{}, err
+	}
+	d.ChemicalName, d.EPARegistration = chemical.Name, chemical.EPARegistration
+	if d.UnitOfMeasure == "" {
+		d.UnitOfMeasure = chemical.UnitOfMeasure
+	}
+	if d.Quantity > 0 && d.UnitOfMeasure == "" {
+		return Disposal{}, fmt.Errorf("%w: unitOfMeasure is required with a quantity", ErrInvalidDisposal)
+	}
+
+	if d.Jurisdiction == "" {
+		d.Jurisdiction = s.jurisdictionOf(technicianID)
+	}
+	if d.Jurisdiction != "" {
+		j, err := s.store.GetJurisdiction(d.Jurisdiction)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return Disposal{}, err
+		default:
+			if missing := j.Missing(d); len(missing) > 0 {
+				return Disposal{}, fmt.Errorf("%w: %s requires %s", ErrInvalidDisposal, d.Jurisdiction, strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	d.ID = uuid.NewString()
+	d.DisposedAt = d.DisposedAt.UTC()
+	d.RecordedAt = now
+	if err := s.store.SaveDisposal(d); err != nil {
+		return Disposal{}, err
+	}
+	s.logger.Info("disposal logged", slog.String("disposal", d.ID), slog.String("technician", technicianID), slog.String("chemical", d.ChemicalID), slog.Int("containers", d.ContainersRinsed))
+	return d, nil
+}
+
+// Disposal returns a disposal.
+func (s *Service) Disposal(id string) (Disposal, error) {
+	return s.store.GetDisposal(id)
+}
+
+// Disposals lists disposals matching f, oldest first.
+func (s *Service) Disposals(f Filter) ([]Disposal, error) {
+	f.Jurisdiction = normalizeCode(f.Jurisdiction)
+	return s.store.ListDisposals(f)
+}
+
+// SaveJurisdiction creates or replaces a jurisdiction's required fields.
+// Existing disposals are not revalidated; Report lists the ones that fall
+// short.
+func (s *Service) SaveJurisdiction(code string, j Jurisdiction) (Jurisdiction, error) {
+	j.Code = normalizeCode(code)
+	if err := j.Validate(); err != nil {
+		return Jurisdiction{}, err
+	}
+	j.RequiredFields = dedupe(j.RequiredFields)
+	j.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveJurisdiction(j); err != nil {
+		return Jurisdiction{}, err
+	}
+	s.logger.Info("disposal jurisdiction saved", slog.String("jurisdiction", j.Code), slog.Any("required", j.RequiredFields))
+	return j, nil
+}
+
+// Jurisdiction returns a jurisdiction's required fields.
+func (s *Service) Jurisdiction(code string) (Jurisdiction, error) {
+	return s.store.GetJurisdiction(normalizeCode(code))
+}
+
+// Jurisdictions lists every jurisdiction with required fields.
+func (s *Service) Jurisdictions() ([]Jurisdiction, error) {
+	return s.store.ListJurisdictions()
+}
+
+// DeleteJurisdiction removes a jurisdiction's required fields.
+func (s *Service) DeleteJurisdiction(code string) error {
+	return s.store.DeleteJurisdiction(normalizeCode(code))
+}
+
+// ChemicalTotal is a chemical's disposals within a report.
+type ChemicalTotal struct {
+	ChemicalName     string  `json:"chemicalName"`
+	EPARegistration  string  `json:"epaRegistration,omitempty"`
+	Disposals        int     `json:"disposals"`
+	ContainersRinsed int     `json:"containersRinsed"`
+	Quantity         float64 `json:"quantity"`
+	UnitOfMeasure    string  `json:"unitOfMeasure,omitempty"`
+}
+
+// SiteTotal is what was taken to one disposal site within a report.
+// Quantities are in ByChemical, where they share a unit.
+type SiteTotal struct {
+	Site             Site `json:"site"`
+	Disposals        int  `json:"disposals"`
+	ContainersRinsed int  `json:"containersRinsed"`
+}
+
+// Incomplete is a disposal missing fields its jurisdiction now requires.
+type Incomplete struct {
+	DisposalID   string   `json:"disposalId"`
+	TechnicianID string   `json:"technicianId"`
+	Missing      []string `json:"missing"`
+}
+
+// Report summarises disposals for a compliance filing.
+type Report struct {
+	Jurisdiction     string          `json:"jurisdiction,omitempty"`
+	From             *time.Time      `json:"from,omitempty"`
+	To               *time.Time      `json:"to,omitempty"`
+	Disposals        int             `json:"disposals"`
+	ContainersRinsed int             `json:"containersRinsed"`
+	ByChemical       []ChemicalTotal `json:"byChemical"`
+	BySite           []SiteTotal     `json:"bySite"`
+	Incomplete       []Incomplete    `json:"incomplete"`
+	Records          []Disposal      `json:"records"`
+}
+
+// Report totals the disposals matching f by chemical and by site, and lists
+// those missing fields their jurisdiction requires.
+func (s *Service) Report(f Filter) (Report, error) {
+	disposals, err := s.Disposals(f)
+	if err != nil {
+		return Report{}, err
+	}
+	jurisdictions, err := s.store.ListJurisdictions()
+	if err != nil {
+		return Report{}, err
+	}
+	rules := make(map[string]Jurisdiction, len(jurisdictions))
+	for _, j := range jurisdictions {
+		rules[j.Code] = j
+	}
+
+	report := Report{Jurisdiction: normalizeCode(f.Jurisdiction), Disposals: len(disposals),
+		ByChemical: []ChemicalTotal{}, BySite: []SiteTotal{}, Incomplete: []Incomplete{}, Records: disposals}
+	if !f.From.IsZero() {
+		report.From = &f.From
+	}
+	if !f.To.IsZero() {
+		report.To = &f.To
+	}
+	chemicals := make(map[string]int)
+	sites := make(map[string]int)
+	for _, d := range disposals {
+		report.ContainersRinsed += d.ContainersRinsed
+
+		// Quantities only add up within a unit.
+		key := d.EPARegistration + "|" + d.ChemicalName + "|" + d.UnitOfMeasure
+		i, ok := chemicals[key]
+		if !ok {
+			i = len(report.ByChemical)
+			chemicals[key] = i
+			report.ByChemical = append(report.ByChemical, ChemicalTotal{ChemicalName: d.ChemicalName, EPARegistration: d.EPARegistration, UnitOfMeasure: d.UnitOfMeasure})
+		}
+		c := &report.ByChemical[i]
+		c.Disposals++
+		c.ContainersRinsed += d.ContainersRinsed
+		c.Quantity += d.Quantity
+
+		siteKey := strings.ToLower(d.Site.Name + "|" + d.Site.PermitNumber)
+		i, ok = sites[siteKey]
+		if !ok {
+			i = len(report.BySite)
+			sites[siteKey] = i
+			report.BySite = append(report.BySite, SiteTotal{Site: d.Site})
+		}
+		st := &report.BySite[i]
+		st.Disposals++
+		st.ContainersRinsed += d.ContainersRinsed
+
+		if missing := rules[d.Jurisdiction].Missing(d); len(missing) > 0 {
+			report.Incomplete = append(report.Incomplete, Incomplete{DisposalID: d.ID, TechnicianID: d.TechnicianID, Missing: missing})
+		}
+	}
+	sort.Slice(report.ByChemical, func(i, j int) bool {
+		if report.ByChemical[i].ChemicalName != report.ByChemical[j].ChemicalName {
+			return report.ByChemical[i].ChemicalName < report.ByChemical[j].ChemicalName
+		}
+		return report.ByChemical[i].UnitOfMeasure < report.ByChemical[j].UnitOfMeasure
+	})
+	sort.Slice(report.BySite, func(i, j int) bool { return report.BySite[i].Site.Name < report.BySite[j].Site.Name })
+	return report, nil
+}
+
+// chemical returns one of a technician's chemicals.
+func (s *Service) chemical(technicianID, chemicalID string) (models.ChemicalUpload, error) {
+	all, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return models.ChemicalUpload{}, err
+	}
+	for _, c := range all {
+		if c.ID == chemicalID && c.TechnicianID == technicianID {
+			return c, nil
+		}
+	}
+	return models.ChemicalUpload{}, fmt.Errorf("%w: chemical %q is not on technician %q's truck", ErrInvalidDisposal, chemicalID, technicianID)
+}
+
+// jurisdictionOf returns a technician's jurisdiction code, or "" when it is
+// unknown.
+func (s *Service) jurisdictionOf(technicianID string) string {
+	tech, err := s.repos.Technicians.GetByID(technicianID)
+	if err != nil {
+		return ""
+	}
+	if s.branches != nil && tech.BranchID != "" {
+		if b, err := s.branches.Branch(tech.BranchID); err == nil && b.Region != "" {
+			return normalizeCode(b.Region)
+		}
+	}
+	return normalizeCode(tech.Region)
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := []string{}
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
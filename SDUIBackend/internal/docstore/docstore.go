@@ -0,0 +1,278 @@
+// Package docstore persists the records of features that need no schema of
+// their own, such as tenants, API tokens and ETA links, as JSON documents
+// in the configured datastore. Every instance of the backend shares them,
+// and they survive restarts, unlike the in-process stores the features
+// started with.
+//
+// A document is found by its ID or by the secondary keys it is saved with;
+// anything else, such as ordering and limits, is left to the feature,
+// which knows its collections stay small.
+package docstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a document does not exist.
+	ErrNotFound = errors.New("document not found")
+	// ErrExists is returned when creating a document that exists.
+	ErrExists = errors.New("document already exists")
+)
+
+// Keys are the secondary keys a document is found by, such as the hash of
+// a token or the technician a record belongs to.
+type Keys map[string]string
+
+// Document is a JSON body stored under an ID in a collection.
+type Document struct {
+	ID        string          `json:"id"`
+	Keys      Keys            `json:"keys,omitempty"`
+	Body      json.RawMessage `json:"body"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// Store persists documents; the memory, Postgres and Firestore stores
+// implement it. Collection names are short identifiers of letters, digits
+// and underscores.
+type Store interface {
+	// PutDocument creates or replaces a document.
+	PutDocument(collection string, doc Document) error
+	// CreateDocument writes a new document, returning ErrExists when one
+	// with its ID exists.
+	CreateDocument(collection string, doc Document) error
+	// GetDocument returns ErrNotFound when the document does not exist.
+	GetDocument(collection, id string) (Document, error)
+	// FindDocuments returns the documents saved with every key in keys,
+	// ordered by ID; empty keys return the whole collection.
+	FindDocuments(collection string, keys Keys) ([]Document, error)
+	// UpdateDocument replaces a document with what fn returns for its
+	// current version, or for nil when it does not exist, without losing
+	// concurrent updates: fn may be called again with a newer version. An
+	// error from fn aborts the update and is returned. fn must not use the
+	// store.
+	UpdateDocument(collection, id string, fn func(current *Document) (Document, error)) (Document, error)
+	// DeleteDocument removes a document; deleting a missing one succeeds.
+	DeleteDocument(collection, id string) error
+}
+
+// MemoryStore is an in-process Store for local development and tests.
+type MemoryStore struct {
+	mu   sync.Mutex
+	docs map[string]map[string]Document
+	now  func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{docs: make(map[string]map[string]Document), now: time.Now}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) put(collection string, doc Document) Document {
+	if m.docs[collection] == nil {
+		m.docs[collection] = make(map[string]Document)
+	}
+	doc.UpdatedAt = m.now().UTC()
+	m.docs[collection][doc.ID] = clone(doc)
+	return doc
+}
+
+func (m *MemoryStore) PutDocument(collection string, doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(collection, doc)
+	return nil
+}
+
+func (m *MemoryStore) CreateDocument(collection string, doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.docs[collection][doc.ID]; ok {
+		return ErrExists
+	}
+	m.put(collection, doc)
+	return nil
+}
+
+func (m *MemoryStore) GetDocument(collection, id string) (Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	doc, ok := m.docs[collection][id]
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	return clone(doc), nil
+}
+
+func (m *MemoryStore) FindDocuments(collection string, keys Keys) ([]Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []Document{}
+	for _, doc := range m.docs[collection] {
+		if doc.Keys.match(keys) {
+			out = append(out, clone(doc))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *MemoryStore) UpdateDocument(collection, id string, fn func(current *Document) (Document, error)) (Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var current *Document
+	if doc, ok := m.docs[collection][id]; ok {
+		doc = clone(doc)
+		current = &doc
+	}
+	next, err := fn(current)
+	if err != nil {
+		return Document{}, err
+	}
+	next.ID = id
+	return m.put(collection, next), nil
+}
+
+func (m *MemoryStore) DeleteDocument(collection, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.docs[collection], id)
+	return nil
+}
+
+// match reports whether k holds every key in want.
+func (k Keys) match(want Keys) bool {
+	for name, v := range want {
+		if got, ok := k[name]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// clone copies doc so callers cannot change a stored document.
+func clone(doc Document) Document {
+	if doc.Keys != nil {
+		keys := make(Keys, len(doc.Keys))
+		for k, v := range doc.Keys {
+			keys[k] = v
+		}
+		doc.Keys = keys
+	}
+	doc.Body = append(json.RawMessage(nil), doc.Body...)
+	return doc
+}
+
+// Collection stores values of T as documents of one collection, saving
+// each with the keys keyOf returns for it.
+type Collection[T any] struct {
+	store Store
+	name  string
+	keyOf func(T) Keys
+}
+
+// NewCollection returns the collection name of store. keyOf may be nil
+// when values are only found by ID.
+func NewCollection[T any](store Store, name string, keyOf func(T) Keys) Collection[T] {
+	if keyOf == nil {
+		keyOf = func(T) Keys { return nil }
+	}
+	return Collection[T]{store: store, name: name, keyOf: keyOf}
+}
+
+func (c Collection[T]) document(id string, v T) (Document, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return Document{}, fmt.Errorf("encode %s %s: %w", c.name, id, err)
+	}
+	return Document{ID: id, Keys: c.keyOf(v), Body: body}, nil
+}
+
+func (c Collection[T]) decode(doc Document) (T, error) {
+	var v T
+	if err := json.Unmarshal(doc.Body, &v); err != nil {
+		return v, fmt.Errorf("decode %s %s: %w", c.name, doc.ID, err)
+	}
+	return v, nil
+}
+
+// Put creates or replaces the value stored under id.
+func (c Collection[T]) Put(id string, v T) error {
+	doc, err := c.document(id, v)
+	if err != nil {
+		return err
+	}
+	return c.store.PutDocument(c.name, doc)
+}
+
+// Create stores v under id, returning ErrExists when id is taken.
+func (c Collection[T]) Create(id string, v T) error {
+	doc, err := c.document(id, v)
+	if err != nil {
+		return err
+	}
+	return c.store.CreateDocument(c.name, doc)
+}
+
+// Get returns the value stored under id, or ErrNotFound.
+func (c Collection[T]) Get(id string) (T, error) {
+	doc, err := c.store.GetDocument(c.name, id)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return c.decode(doc)
+}
+
+// Find returns the values saved with every key in keys, ordered by ID;
+// nil keys return them all.
+func (c Collection[T]) Find(keys Keys) ([]T, error) {
+	docs, err := c.store.FindDocuments(c.name, keys)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		v, err := c.decode(doc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Update stores what fn returns for the value under id, which is nil when
+// there is none, as Store.UpdateDocument does.
+func (c Collection[T]) Update(id string, fn func(current *T) (T, error)) (T, error) {
+	var out T
+	_, err := c.store.UpdateDocument(c.name, id, func(current *Document) (Document, error) {
+		var v *T
+		if current != nil {
+			decoded, err := c.decode(*current)
+			if err != nil {
+				return Document{}, err
+			}
+			v = &decoded
+		}
+		next, err := fn(v)
+		if err != nil {
+			return Document{}, err
+		}
+		out = next
+		return c.document(id, next)
+	})
+	return out, err
+}
+
+// Delete removes the value under id; deleting a missing one succeeds.
+func (c Collection[T]) Delete(id string) error {
+	return c.store.DeleteDocument(c.name, id)
+}
@@ -0,0 +1,64 @@
+package docstore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/storetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.RunDocuments(t, func(*testing.T) docstore.Store { return docstore.NewMemoryStore() })
+}
+
+func TestPartitionConformance(t *testing.T) {
+	storetest.RunDocuments(t, func(*testing.T) docstore.Store { return docstore.Partition(docstore.NewMemoryStore(), "acme") })
+}
+
+func TestPartitionsKeepDocumentsApart(t *testing.T) {
+	shared := docstore.NewMemoryStore()
+	acme := docstore.NewCollection[widget](docstore.Partition(shared, "acme"), "widgets", nil)
+	bugs := docstore.NewCollection[widget](docstore.Partition(shared, "bugsbgone"), "widgets", nil)
+	_ = acme.Put("w1", widget{Name: "acme's"})
+	_ = bugs.Put("w1", widget{Name: "bugsbgone's"})
+	if got, err := acme.Get("w1"); err != nil || got.Name != "acme's" {
+		t.Fatalf("expected acme's own widget, got %+v (%v)", got, err)
+	}
+	if list, err := bugs.Find(nil); err != nil || len(list) != 1 || list[0].Name != "bugsbgone's" {
+		t.Fatalf("expected only bugsbgone's widgets, got %+v (%v)", list, err)
+	}
+}
+
+type widget struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	Count int    `json:"count"`
+}
+
+func TestCollection(t *testing.T) {
+	widgets := docstore.NewCollection(docstore.NewMemoryStore(), "widgets", func(w widget) docstore.Keys {
+		return docstore.Keys{"owner": w.Owner}
+	})
+	if _, err := widgets.Get("w1"); !errors.Is(err, docstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_ = widgets.Put("w1", widget{Name: "first", Owner: "tech-1"})
+	_ = widgets.Put("w2", widget{Name: "second", Owner: "tech-2"})
+	if err := widgets.Create("w1", widget{}); !errors.Is(err, docstore.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if list, err := widgets.Find(docstore.Keys{"owner": "tech-2"}); err != nil || len(list) != 1 || list[0].Name != "second" {
+		t.Fatalf("unexpected find %+v (%v)", list, err)
+	}
+	got, err := widgets.Update("w1", func(w *widget) (widget, error) {
+		w.Count++
+		return *w, nil
+	})
+	if err != nil || got.Count != 1 || got.Name != "first" {
+		t.Fatalf("unexpected update %+v (%v)", got, err)
+	}
+	if stored, _ := widgets.Get("w1"); stored.Count != 1 {
+		t.Fatalf("expected the update stored, got %+v", stored)
+	}
+}
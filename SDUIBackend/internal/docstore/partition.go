@@ -0,0 +1,89 @@
+package docstore
+
+import "strings"
+
+// partitionKey is the key a partitioned document is saved with, so finds
+// stay within the partition.
+const partitionKey = "partition"
+
+// Partition returns a view of store holding only name's documents, such as
+// one tenant's: IDs are prefixed with name, and finds see no other
+// partition's documents. An empty name returns store itself.
+func Partition(store Store, name string) Store {
+	if name == "" {
+		return store
+	}
+	return partition{base: store, prefix: name + "/", name: name}
+}
+
+type partition struct {
+	base   Store
+	prefix string
+	name   string
+}
+
+func (p partition) in(doc Document) Document {
+	keys := make(Keys, len(doc.Keys)+1)
+	for k, v := range doc.Keys {
+		keys[k] = v
+	}
+	keys[partitionKey] = p.name
+	doc.ID, doc.Keys = p.prefix+doc.ID, keys
+	return doc
+}
+
+func (p partition) out(doc Document) Document {
+	doc.ID = strings.TrimPrefix(doc.ID, p.prefix)
+	delete(doc.Keys, partitionKey)
+	return doc
+}
+
+func (p partition) PutDocument(collection string, doc Document) error {
+	return p.base.PutDocument(collection, p.in(doc))
+}
+
+func (p partition) CreateDocument(collection string, doc Document) error {
+	return p.base.CreateDocument(collection, p.in(doc))
+}
+
+func (p partition) GetDocument(collection, id string) (Document, error) {
+	doc, err := p.base.GetDocument(collection, p.prefix+id)
+	if err != nil {
+		return Document{}, err
+	}
+	return p.out(doc), nil
+}
+
+func (p partition) FindDocuments(collection string, keys Keys) ([]Document, error) {
+	docs, err := p.base.FindDocuments(collection, p.in(Document{Keys: keys}).Keys)
+	if err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		docs[i] = p.out(docs[i])
+	}
+	return docs, nil
+}
+
+func (p partition) UpdateDocument(collection, id string, fn func(current *Document) (Document, error)) (Document, error) {
+	doc, err := p.base.UpdateDocument(collection, p.prefix+id, func(current *Document) (Document, error) {
+		if current != nil {
+			doc := p.out(*current)
+			current = &doc
+		}
+		next, err := fn(current)
+		if err != nil {
+			return Document{}, err
+		}
+		next.ID = id
+		return p.in(next), nil
+	})
+	if err != nil {
+		return Document{}, err
+	}
+	return p.out(doc), nil
+}
+
+func (p partition) DeleteDocument(collection, id string) error {
+	return p.base.DeleteDocument(collection, p.prefix+id)
+}
@@ -0,0 +1,125 @@
+package eta
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func at(hour, minute int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-1", DisplayName: "Maria Lopez"})
+	_ = store.SaveRoute(models.Route{
+		TechnicianID: "tech-1",
+		ServiceDate:  day,
+		CustomerStops: []models.RouteStop{
+			{CustomerID: "c3", CustomerName: "Third", WindowStart: at(13, 0), WindowEnd: at(14, 0)},
+			{CustomerID: "c1", CustomerName: "First", WindowStart: at(8, 0), WindowEnd: at(9, 0)},
+			{CustomerID: "c2", CustomerName: "Second", WindowStart: at(10, 0), WindowEnd: at(11, 0)},
+		},
+	})
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.ETAConfig{PublicBaseURL: "https://status.example.com", StopDuration: 45 * time.Minute, ExpiryGrace: 2 * time.Hour}
+	svc := NewService(cfg, NewMemoryStore(), repos, nil, nil)
+	svc.now = func() time.Time { return at(7, 0) }
+	return svc
+}
+
+func tokenFrom(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func TestStatusProgressesThroughTheDay(t *testing.T) {
+	svc := newTestService(t)
+	link, url, err := svc.Create(Request{TechnicianID: "tech-1", ServiceDate: day, CustomerID: "c3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(url, "https://status.example.com/v1/public/eta/") || !link.ExpiresAt.Equal(at(16, 0)) {
+		t.Fatalf("unexpected link %+v at %s", link, url)
+	}
+
+	cases := []struct {
+		now       time.Time
+		status    string
+		stopsAway int
+		arrival   time.Time
+	}{
+		{at(7, 0), StatusScheduled, 2, at(13, 0)},
+		{at(8, 20), StatusEnRoute, 2, at(13, 0)},
+		{at(11, 50), StatusNext, 0, at(13, 0)}, // earlier windows closed
+		{at(12, 40), StatusNext, 0, at(13, 0)},
+		{at(13, 20), StatusNext, 0, at(13, 30)}, // running late rounds up
+		{at(14, 30), StatusCompleted, 0, time.Time{}},
+	}
+	for _, tc := range cases {
+		svc.now = func() time.Time { return tc.now }
+		got, err := svc.Status(tokenFrom(url))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.now.Format("15:04"), err)
+		}
+		if got.Status != tc.status || got.StopsAway != tc.stopsAway || got.TechnicianFirstName != "Maria" {
+			t.Errorf("%s: unexpected status %+v", tc.now.Format("15:04"), got)
+		}
+		if tc.arrival.IsZero() != (got.ArrivalWindowStart == nil) || (got.ArrivalWindowStart != nil && !got.ArrivalWindowStart.Equal(tc.arrival)) {
+			t.Errorf("%s: unexpected arrival %v", tc.now.Format("15:04"), got.ArrivalWindowStart)
+		}
+	}
+}
+
+func TestStatusHiddenAfterExpiryOrRevocation(t *testing.T) {
+	svc := newTestService(t)
+	link, url, _ := svc.Create(Request{TechnicianID: "tech-1", ServiceDate: day, CustomerID: "c1"})
+
+	svc.now = func() time.Time { return link.ExpiresAt }
+	if _, err := svc.Status(tokenFrom(url)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected expired link to be hidden, got %v", err)
+	}
+
+	svc.now = func() time.Time { return at(7, 0) }
+	_, _ = svc.Revoke(link.ID)
+	if _, err := svc.Status(tokenFrom(url)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected revoked link to be hidden, got %v", err)
+	}
+}
+
+func TestCreateRejectsUnknownStops(t *testing.T) {
+	svc := newTestService(t)
+	if _, _, err := svc.Create(Request{TechnicianID: "tech-1", ServiceDate: day, CustomerID: "nobody"}); !errors.Is(err, ErrInvalidLink) {
+		t.Fatalf("expected invalid link, got %v", err)
+	}
+}
+
+func TestNotifyAtFollowsBusinessHours(t *testing.T) {
+	svc := newTestService(t)
+	calendars, err := calendar.NewService(config.CalendarConfig{TimeZone: "UTC", Open: "08:00", Close: "17:00", Days: []string{"thu"}}, calendar.NewMemoryStore(), svc.repos, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.calendars = calendars
+
+	// Created at 7:00, before opening: the afternoon stop is notified at 8:00,
+	// the 8:00 stop right away rather than as the visit starts.
+	later, _, _ := svc.Create(Request{TechnicianID: "tech-1", ServiceDate: day, CustomerID: "c3"})
+	if !later.NotifyAt.Equal(at(8, 0)) {
+		t.Errorf("expected notification at opening, got %s", later.NotifyAt)
+	}
+	first, _, _ := svc.Create(Request{TechnicianID: "tech-1", ServiceDate: day, CustomerID: "c1"})
+	if !first.NotifyAt.Equal(at(7, 0)) {
+		t.Errorf("expected immediate notification, got %s", first.NotifyAt)
+	}
+}
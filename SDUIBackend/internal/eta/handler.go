@@ -0,0 +1,152 @@
+package eta
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the public status endpoint and admin link management.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an ETA handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin link endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListLinks)
+	r.Post("/", h.CreateLink)
+	r.Delete("/{linkId}", h.RevokeLink)
+}
+
+// CreateLink issues a sharing link for a stop.
+func (h *Handler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	var payload Request
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	link, url, err := h.service.Create(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create link", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, map[string]any{"link": link, "url": url})
+}
+
+// ListLinks returns links for ?technicianId= and optional ?serviceDate=.
+func (h *Handler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("technicianId") == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technicianId", "technicianId query parameter is required")
+		return
+	}
+	var date time.Time
+	if v := q.Get("serviceDate"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+	links, err := h.service.Links(q.Get("technicianId"), date)
+	if err != nil {
+		h.fail(w, r, "failed to list links", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"links": links})
+}
+
+// RevokeLink disables a link.
+func (h *Handler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	link, err := h.service.Revoke(chi.URLParam(r, "linkId"))
+	if err != nil {
+		h.fail(w, r, "failed to revoke link", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, link)
+}
+
+// Public serves the customer status for a link token, as HTML for browsers
+// and JSON otherwise.
+func (h *Handler) Public(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	status, err := h.service.Status(chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			respond.Error(w, http.StatusNotFound, "link not found", "this link has expired or is no longer valid")
+			return
+		}
+		middleware.LoggerFrom(r.Context()).Error("failed to resolve eta link", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to load status", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := statusPage.Execute(w, status); err != nil {
+			middleware.LoggerFrom(r.Context()).Error("failed to render eta page", slog.Any("error", err))
+		}
+		return
+	}
+	respond.JSON(w, http.StatusOK, status)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidLink):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
+
+var statusPage = template.Must(template.New("eta").Funcs(template.FuncMap{
+	"clock": func(t *time.Time) string { return t.Format("3:04 PM") },
+}).Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>Your service visit</title>
+<style>body{font-family:system-ui,sans-serif;max-width:32rem;margin:3rem auto;padding:0 1rem;color:#1d2b1f}h1{font-size:1.4rem}.eta{font-size:1.2rem;font-weight:600}</style>
+</head>
+<body>
+<h1>Your service visit</h1>
+{{- if eq .Status "completed"}}
+<p>{{.TechnicianFirstName}} has completed your visit. Thank you!</p>
+{{- else}}
+{{- if eq .Status "next"}}
+<p>{{.TechnicianFirstName}} is heading to you next.</p>
+{{- else if eq .Status "en_route"}}
+<p>{{.TechnicianFirstName}} is {{.StopsAway}} stop{{if ne .StopsAway 1}}s{{end}} away.</p>
+{{- else}}
+<p>{{.TechnicianFirstName}} is scheduled to visit you today.</p>
+{{- end}}
+{{- if .ArrivalWindowStart}}
+<p class="eta">Estimated arrival: {{clock .ArrivalWindowStart}} &ndash; {{clock .ArrivalWindowEnd}} (UTC)</p>
+{{- end}}
+{{- end}}
+<p><small>Updated {{.UpdatedAt.Format "3:04 PM"}} UTC. This page refreshes automatically.</small></p>
+</body>
+</html>
+`))
@@ -0,0 +1,182 @@
+package eta
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// Visit statuses shown to customers.
+const (
+	StatusScheduled = "scheduled"
+	StatusEnRoute   = "en_route" // technician is working earlier stops
+	StatusNext      = "next"     // customer is the next stop
+	StatusCompleted = "completed"
+)
+
+var (
+	// ErrNotFound is returned for unknown, expired, or revoked links.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidLink wraps link request validation failures.
+	ErrInvalidLink = errors.New("invalid link")
+)
+
+// Request asks for a sharing link for one stop on a technician's route.
+type Request struct {
+	TechnicianID string    `json:"technicianId"`
+	ServiceDate  time.Time `json:"serviceDate"`
+	CustomerID   string    `json:"customerId"`
+	JobID        string    `json:"jobId,omitempty"`
+}
+
+// Validate checks a link request.
+func (r Request) Validate() error {
+	var problems []string
+	if r.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if r.ServiceDate.IsZero() {
+		problems = append(problems, "serviceDate is required")
+	}
+	if r.CustomerID == "" {
+		problems = append(problems, "customerId is required")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidLink, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Link is a tokenized customer status page for a single visit.
+type Link struct {
+	ID           string    `json:"id"`
+	TechnicianID string    `json:"technicianId"`
+	ServiceDate  time.Time `json:"serviceDate"`
+	CustomerID   string    `json:"customerId"`
+	JobID        string    `json:"jobId,omitempty"`
+	Hash         string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// NotifyAt is when the link should be sent to the customer.
+	NotifyAt  time.Time  `json:"notifyAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Views     int        `json:"views"`
+}
+
+// Active reports whether the link can be viewed at now.
+func (l Link) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// Status is the public, deliberately coarse view of a visit.
+type Status struct {
+	Status              string     `json:"status"`
+	TechnicianFirstName string     `json:"technicianFirstName"`
+	StopsAway           int        `json:"stopsAway"`
+	ArrivalWindowStart  *time.Time `json:"arrivalWindowStart,omitempty"`
+	ArrivalWindowEnd    *time.Time `json:"arrivalWindowEnd,omitempty"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// Store persists links.
+type Store interface {
+	SaveLink(l Link) error
+	GetLink(id string) (Link, error)
+	GetLinkByHash(hash string) (Link, error)
+	ListLinks(technicianID string, serviceDate time.Time) ([]Link, error)
+	// CountView increments a link's view counter.
+	CountView(id string) error
+}
+
+// storedLink is a link as it is saved, with the hash its JSON leaves out.
+type storedLink struct {
+	Link
+	Hash string `json:"hash"`
+}
+
+// link returns the stored link with its hash.
+func (st storedLink) link() Link {
+	l := st.Link
+	l.Hash = st.Hash
+	return l
+}
+
+// DocumentStore keeps links in the shared document store, so a link sent
+// to a customer opens on every instance and survives restarts.
+type DocumentStore struct {
+	links docstore.Collection[storedLink]
+}
+
+// NewDocumentStore keeps links in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{links: docstore.NewCollection(docs, "eta_links", func(l storedLink) docstore.Keys {
+		return docstore.Keys{"hash": l.Hash, "technician": l.TechnicianID, "day": l.ServiceDate.Format("2006-01-02")}
+	})}
+}
+
+// NewMemoryStore keeps links in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveLink(l Link) error {
+	return d.links.Put(l.ID, storedLink{Link: l, Hash: l.Hash})
+}
+
+func (d *DocumentStore) GetLink(id string) (Link, error) {
+	st, err := d.links.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Link{}, ErrNotFound
+	}
+	if err != nil {
+		return Link{}, err
+	}
+	return st.link(), nil
+}
+
+func (d *DocumentStore) GetLinkByHash(hash string) (Link, error) {
+	found, err := d.links.Find(docstore.Keys{"hash": hash})
+	if err != nil {
+		return Link{}, err
+	}
+	if len(found) == 0 {
+		return Link{}, ErrNotFound
+	}
+	return found[0].link(), nil
+}
+
+func (d *DocumentStore) ListLinks(technicianID string, serviceDate time.Time) ([]Link, error) {
+	keys := docstore.Keys{"technician": technicianID}
+	if !serviceDate.IsZero() {
+		keys["day"] = serviceDate.Format("2006-01-02")
+	}
+	found, err := d.links.Find(keys)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Link, len(found))
+	for i, st := range found {
+		out[i] = st.link()
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (d *DocumentStore) CountView(id string) error {
+	_, err := d.links.Update(id, func(current *storedLink) (storedLink, error) {
+		if current == nil {
+			return storedLink{}, ErrNotFound
+		}
+		st := *current
+		st.Views++
+		return st, nil
+	})
+	return err
+}
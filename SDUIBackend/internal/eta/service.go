@@ -0,0 +1,256 @@
+package eta
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// etaRounding keeps published arrival times coarse.
+const etaRounding = 15 * time.Minute
+
+// etaWindow is the width of the published arrival window.
+const etaWindow = 30 * time.Minute
+
+// Service issues ETA links and computes the public status behind them.
+type Service struct {
+	cfg       config.ETAConfig
+	store     Store
+	repos     repository.Repository
+	calendars *calendar.Service
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService wires an ETA link service. Links are due to be sent to
+// customers during the business hours of the technician's branch; nil
+// calendars means they are due immediately.
+func NewService(cfg config.ETAConfig, store Store, repos repository.Repository, calendars *calendar.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, calendars: calendars, logger: logger, now: time.Now}
+}
+
+// Create issues a link for a stop and returns it with its public URL. The
+// link expires ExpiryGrace after the stop's window, or after the service day
+// when the stop has no window.
+func (s *Service) Create(req Request) (Link, string, error) {
+	if err := req.Validate(); err != nil {
+		return Link{}, "", err
+	}
+	route, err := s.repos.Routes.GetRoute(req.TechnicianID, req.ServiceDate)
+	if err != nil {
+		return Link{}, "", fmt.Errorf("%w: no route for technician on %s", ErrInvalidLink, req.ServiceDate.Format("2006-01-02"))
+	}
+	stop, ok := findStop(route, req.CustomerID)
+	if !ok {
+		return Link{}, "", fmt.Errorf("%w: customer %q is not on the route", ErrInvalidLink, req.CustomerID)
+	}
+
+	now := s.now().UTC()
+	end := stop.WindowEnd
+	if end.IsZero() {
+		day := route.ServiceDate.UTC()
+		end = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour)
+	}
+	expires := end.Add(s.cfg.ExpiryGrace)
+	if !expires.After(now) {
+		return Link{}, "", fmt.Errorf("%w: visit has already ended", ErrInvalidLink)
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Link{}, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link := Link{
+		ID:           uuid.NewString(),
+		TechnicianID: req.TechnicianID,
+		ServiceDate:  route.ServiceDate,
+		CustomerID:   req.CustomerID,
+		JobID:        req.JobID,
+		Hash:         hashToken(token),
+		CreatedAt:    now,
+		NotifyAt:     s.notifyAt(req.TechnicianID, now, stop.WindowStart),
+		ExpiresAt:    expires,
+	}
+	if err := s.store.SaveLink(link); err != nil {
+		return Link{}, "", err
+	}
+	return link, s.cfg.PublicBaseURL + "/v1/public/eta/" + token, nil
+}
+
+// notifyAt is when a new link should reach the customer: now during the
+// branch's business hours, otherwise when it next opens, unless the visit's
+// window would have started by then.
+func (s *Service) notifyAt(technicianID string, now, windowStart time.Time) time.Time {
+	hours := s.calendars.ForTechnician(technicianID)
+	if hours == nil {
+		return now
+	}
+	at := hours.NextOpen(now).UTC()
+	if !windowStart.IsZero() && !at.Before(windowStart) {
+		return now
+	}
+	return at
+}
+
+// Revoke disables a link immediately.
+func (s *Service) Revoke(id string) (Link, error) {
+	link, err := s.store.GetLink(id)
+	if err != nil {
+		return Link{}, err
+	}
+	if link.RevokedAt == nil {
+		now := s.now().UTC()
+		link.RevokedAt = &now
+		if err := s.store.SaveLink(link); err != nil {
+			return Link{}, err
+		}
+	}
+	return link, nil
+}
+
+// Links lists the links issued for a technician's day.
+func (s *Service) Links(technicianID string, serviceDate time.Time) ([]Link, error) {
+	return s.store.ListLinks(technicianID, serviceDate)
+}
+
+// Issued reports whether token is that of a link this service issued,
+// whatever its state, so the service holding a tenant's links can be found.
+func (s *Service) Issued(token string) (bool, error) {
+	_, err := s.store.GetLinkByHash(hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Status resolves a public token to the visit status. Unknown, expired, and
+// revoked links are indistinguishable to the caller.
+func (s *Service) Status(token string) (Status, error) {
+	link, err := s.store.GetLinkByHash(hashToken(token))
+	if err != nil {
+		return Status{}, err
+	}
+	now := s.now().UTC()
+	if !link.Active(now) {
+		return Status{}, ErrNotFound
+	}
+	route, err := s.repos.Routes.GetRoute(link.TechnicianID, link.ServiceDate)
+	if err != nil {
+		return Status{}, err
+	}
+	tech, err := s.repos.Technicians.GetByID(link.TechnicianID)
+	if err != nil {
+		return Status{}, err
+	}
+	if err := s.store.CountView(link.ID); err != nil {
+		s.logger.Warn("count eta link view", slog.String("link", link.ID), slog.Any("error", err))
+	}
+
+	status := s.estimate(route, link.CustomerID, now)
+	status.TechnicianFirstName = firstName(tech.DisplayName)
+	return status, nil
+}
+
+// estimate derives a coarse status from the route schedule. Stops whose
+// window has closed are treated as done; the arrival estimate assumes
+// StopDuration per remaining stop but never precedes the booked window.
+func (s *Service) estimate(route models.Route, customerID string, now time.Time) Status {
+	stops := append([]models.RouteStop(nil), route.CustomerStops...)
+	sort.SliceStable(stops, func(i, j int) bool {
+		if stops[i].WindowStart.IsZero() || stops[j].WindowStart.IsZero() {
+			return false
+		}
+		return stops[i].WindowStart.Before(stops[j].WindowStart)
+	})
+
+	out := Status{UpdatedAt: now}
+	started := false
+	for _, stop := range stops {
+		if stop.CustomerID == customerID {
+			if done(stop, now) {
+				out.Status = StatusCompleted
+				return out
+			}
+			break
+		}
+		if !stop.WindowStart.IsZero() && !now.Before(stop.WindowStart) {
+			started = true
+		}
+		if !done(stop, now) {
+			out.StopsAway++
+		}
+	}
+	stop, _ := findStop(models.Route{CustomerStops: stops}, customerID)
+
+	switch {
+	case out.StopsAway == 0 && (started || (!stop.WindowStart.IsZero() && !now.Before(stop.WindowStart.Add(-etaWindow)))):
+		out.Status = StatusNext
+	case started:
+		out.Status = StatusEnRoute
+	default:
+		out.Status = StatusScheduled
+	}
+
+	arrival := now.Add(time.Duration(out.StopsAway) * s.cfg.StopDuration)
+	if arrival.Before(stop.WindowStart) {
+		arrival = stop.WindowStart
+	}
+	start := roundUp(arrival, etaRounding)
+	end := start.Add(etaWindow)
+	out.ArrivalWindowStart = &start
+	out.ArrivalWindowEnd = &end
+	return out
+}
+
+func done(stop models.RouteStop, now time.Time) bool {
+	return !stop.WindowEnd.IsZero() && now.After(stop.WindowEnd)
+}
+
+func findStop(route models.Route, customerID string) (models.RouteStop, bool) {
+	for _, stop := range route.CustomerStops {
+		if stop.CustomerID == customerID {
+			return stop, true
+		}
+	}
+	return models.RouteStop{}, false
+}
+
+func roundUp(t time.Time, d time.Duration) time.Time {
+	r := t.Truncate(d)
+	if r.Before(t) {
+		r = r.Add(d)
+	}
+	return r
+}
+
+func firstName(displayName string) string {
+	if fields := strings.Fields(displayName); len(fields) > 0 {
+		return fields[0]
+	}
+	return "Your technician"
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,313 @@
+// Package events publishes domain events, such as uploaded jobs and changed
+// routes, so services outside the request path can react to writes.
+// Repository writes emit events onto a Bus, which publishes them in batches
+// to in-process subscribers or to a Google Cloud Pub/Sub topic; with
+// Pub/Sub, the bus's subscribers receive the events every instance
+// publishes. Publishing is at most once: events are dropped when the buffer is full or a publish
+// fails, so subscribers needing every record should reconcile with the
+// delta sync queries.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Event types.
+const (
+	EventJobUploaded      = "job.uploaded"
+	EventJobDeleted       = "job.deleted"
+	EventRouteChanged     = "route.changed"
+	EventTreatmentLogged  = "treatment.logged"
+	EventDeviceRegistered = "device.registered"
+	EventAuthLockedOut    = "auth.locked_out"
+	EventMessageSent      = "message.sent"
+)
+
+// Event is a domain event. Data is one of the *Data types, by Type.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	TenantID     string    `json:"tenantId,omitempty"`
+	TechnicianID string    `json:"technicianId,omitempty"`
+	OccurredAt   time.Time `json:"occurredAt"`
+	Data         any       `json:"data"`
+}
+
+// JobUploadedData is the data of a job.uploaded event.
+type JobUploadedData struct {
+	JobID         string    `json:"jobId"`
+	Status        string    `json:"status"`
+	ScheduledDate time.Time `json:"scheduledDate"`
+}
+
+// Decode stores e's data in v, a pointer to the *Data type of e.Type.
+// Events received from Pub/Sub carry their data as decoded JSON.
+func (e Event) Decode(v any) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// JobDeletedData is the data of a job.deleted event.
+type JobDeletedData struct {
+	JobID string `json:"jobId"`
+}
+
+// RouteChangedData is the data of a route.changed event: the route was
+// saved or, when Deleted, deleted.
+type RouteChangedData struct {
+	RouteID     string `json:"routeId"`
+	ServiceDate string `json:"serviceDate"` // 2006-01-02
+	Stops       int    `json:"stops"`
+	Deleted     bool   `json:"deleted,omitempty"`
+}
+
+// TreatmentLoggedData is the data of a treatment.logged event.
+type TreatmentLoggedData struct {
+	TreatmentID     string    `json:"treatmentId"`
+	JobID           string    `json:"jobId"`
+	ChemicalID      string    `json:"chemicalId"`
+	QuantityUsed    float64   `json:"quantityUsed"`
+	ApplicationDate time.Time `json:"applicationDate"`
+}
+
+// DeviceRegisteredData is the data of a device.registered event. The push
+// token itself is never published.
+type DeviceRegisteredData struct {
+	Platform string `json:"platform"`
+	BundleID string `json:"bundleId,omitempty"`
+}
+
+// AuthLockedOutData is the data of an auth.locked_out event: Key, such as
+// ip:203.0.113.9, failed to authenticate Failures times and is locked out
+// until LockedUntil. Subscribers alert on it.
+type AuthLockedOutData struct {
+	Key         string    `json:"key"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+// MessageSentData is the data of a message.sent event: a dispatcher's
+// message to the technician was stored. The body is never published.
+type MessageSentData struct {
+	MessageID string `json:"messageId"`
+}
+
+func newEvent(eventType, tenantID, technicianID string, data any) Event {
+	return Event{ID: uuid.NewString(), Type: eventType, TenantID: tenantID, TechnicianID: technicianID, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+// JobUploaded builds the event for a stored job.
+func JobUploaded(j models.JobUpload) Event {
+	return newEvent(EventJobUploaded, j.TenantID, j.TechnicianID, JobUploadedData{JobID: j.ID, Status: j.Status, ScheduledDate: j.ScheduledDate})
+}
+
+// JobDeleted builds the event for a deleted job from its tombstone.
+func JobDeleted(t models.Tombstone) Event {
+	return newEvent(EventJobDeleted, t.TenantID, t.TechnicianID, JobDeletedData{JobID: t.ID})
+}
+
+// RouteChanged builds the event for a saved or deleted route.
+func RouteChanged(r models.Route, deleted bool) Event {
+	return newEvent(EventRouteChanged, r.TenantID, r.TechnicianID, RouteChangedData{RouteID: r.ServerID(), ServiceDate: r.ServiceDate.Format("2006-01-02"), Stops: len(r.CustomerStops), Deleted: deleted})
+}
+
+// TreatmentLogged builds the event for a stored chemical treatment.
+func TreatmentLogged(t models.ChemicalTreatmentUpload) Event {
+	return newEvent(EventTreatmentLogged, t.TenantID, t.TechnicianID, TreatmentLoggedData{TreatmentID: t.ID, JobID: t.JobID, ChemicalID: t.ChemicalID, QuantityUsed: t.QuantityUsed, ApplicationDate: t.ApplicationDate})
+}
+
+// DeviceRegistered builds the event for a registered device.
+func DeviceRegistered(d models.DeviceToken) Event {
+	return newEvent(EventDeviceRegistered, d.TenantID, d.TechnicianID, DeviceRegisteredData{Platform: d.Platform, BundleID: d.BundleID})
+}
+
+// MessageSent builds the event for a stored message to a technician.
+func MessageSent(tenantID, technicianID, messageID string) Event {
+	return newEvent(EventMessageSent, tenantID, technicianID, MessageSentData{MessageID: messageID})
+}
+
+// AuthLockedOut builds the event for a lockout that tripped.
+func AuthLockedOut(key string, failures int, lockedUntil time.Time) Event {
+	return newEvent(EventAuthLockedOut, "", "", AuthLockedOutData{Key: key, Failures: failures, LockedUntil: lockedUntil})
+}
+
+// Publisher delivers batches of events to subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, batch []Event) error
+}
+
+// subscriber is a Publisher whose events can be subscribed to.
+type subscriber interface {
+	Subscribe(fn func(context.Context, Event))
+}
+
+// receiver is a Publisher that delivers events to its subscribers from a
+// loop of its own, such as pulling a Pub/Sub subscription.
+type receiver interface {
+	receive(ctx context.Context, logger *slog.Logger)
+}
+
+// NewPublisher creates the publisher cfg selects.
+func NewPublisher(cfg config.EventsConfig) Publisher {
+	if cfg.Driver == "pubsub" {
+		return newPubSubPublisher(cfg)
+	}
+	return NewMemoryPublisher()
+}
+
+// MemoryPublisher hands events to subscribers in this process.
+type MemoryPublisher struct {
+	mu   sync.RWMutex
+	subs []func(context.Context, Event)
+}
+
+// NewMemoryPublisher creates a MemoryPublisher without subscribers.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Subscribe calls fn with every event published from now on, on the bus's
+// goroutine; slow subscribers delay the others.
+func (m *MemoryPublisher) Subscribe(fn func(context.Context, Event)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs = append(m.subs, fn)
+}
+
+func (m *MemoryPublisher) Publish(ctx context.Context, batch []Event) error {
+	m.mu.RLock()
+	subs := m.subs // Subscribe only appends
+	m.mu.RUnlock()
+	for _, e := range batch {
+		for _, fn := range subs {
+			fn(ctx, e)
+		}
+	}
+	return nil
+}
+
+// Bus buffers emitted events and publishes them in batches.
+type Bus struct {
+	cfg       config.EventsConfig
+	publisher Publisher
+	queue     chan Event
+	dropped   atomic.Int64
+	logger    *slog.Logger
+}
+
+// NewBus creates a bus publishing to publisher.
+func NewBus(cfg config.EventsConfig, publisher Publisher, logger *slog.Logger) *Bus {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Bus{cfg: cfg, publisher: publisher, queue: make(chan Event, cfg.BufferSize), logger: logger}
+}
+
+// Emit queues e for publishing without blocking the caller. Events are
+// dropped when the buffer is full or the bus is nil.
+func (b *Bus) Emit(e Event) {
+	if b == nil {
+		return
+	}
+	select {
+	case b.queue <- e:
+	default:
+		b.dropped.Add(1)
+		b.logger.Warn("events buffer full, event dropped", slog.String("event", e.Type), slog.String("id", e.ID))
+	}
+}
+
+// Subscribe calls fn with every event published from now on: by this
+// instance with the memory publisher, by every instance with Pub/Sub.
+// Events published before Run starts receiving may be missed, and with
+// Pub/Sub an event can be delivered more than once.
+func (b *Bus) Subscribe(fn func(context.Context, Event)) {
+	if b == nil {
+		return
+	}
+	if s, ok := b.publisher.(subscriber); ok {
+		s.Subscribe(fn)
+	}
+}
+
+// Dropped returns how many events were discarded because the buffer was
+// full or their publish failed.
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Run publishes emitted events until ctx is cancelled, then publishes what
+// is still buffered. Publishers with a receive loop, such as Pub/Sub, run
+// it alongside.
+func (b *Bus) Run(ctx context.Context) {
+	if r, ok := b.publisher.(receiver); ok {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.receive(ctx, b.logger)
+		}()
+		defer func() { <-done }()
+	}
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch []Event
+	for {
+		select {
+		case <-ctx.Done():
+			b.drain(batch)
+			return
+		case e := <-b.queue:
+			if batch = append(batch, e); len(batch) >= b.cfg.BatchSize {
+				b.publish(ctx, batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.publish(ctx, batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// drain publishes batch and the buffered events on shutdown, giving up
+// after a few seconds.
+func (b *Bus) drain(batch []Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		select {
+		case e := <-b.queue:
+			if batch = append(batch, e); len(batch) < b.cfg.BatchSize {
+				continue
+			}
+		default:
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.publish(ctx, batch)
+		batch = nil
+	}
+}
+
+func (b *Bus) publish(ctx context.Context, batch []Event) {
+	if err := b.publisher.Publish(ctx, batch); err != nil {
+		b.dropped.Add(int64(len(batch)))
+		b.logger.Error("publish events", slog.Int("events", len(batch)), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var cfg = config.EventsConfig{Driver: "memory", BufferSize: 2, BatchSize: 10, FlushInterval: time.Hour, RequestTimeout: time.Second}
+
+func TestRepositoryWritesEmitEvents(t *testing.T) {
+	store := storememory.NewStore()
+	publisher := NewMemoryPublisher()
+	var mu sync.Mutex
+	var got []Event
+	publisher.Subscribe(func(_ context.Context, e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e)
+	})
+	bus := NewBus(cfg, publisher, nil)
+	repos := Publish(repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, bus)
+
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	_ = repos.Sync.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1", Status: "completed"})
+	_ = repos.Devices.SaveDeviceToken(models.DeviceToken{Token: "secret-token", TechnicianID: "tech-1", Platform: "ios"})
+	// Past the buffer: dropped rather than blocking the write.
+	if err := repos.Routes.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day}); err != nil {
+		t.Fatal(err)
+	}
+	if bus.Dropped() != 1 {
+		t.Errorf("expected one event dropped, got %d", bus.Dropped())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bus.Run(ctx)
+	if len(got) != 2 || got[0].Type != EventJobUploaded || got[1].Type != EventDeviceRegistered {
+		t.Fatalf("unexpected events %+v", got)
+	}
+	if data, _ := json.Marshal(got[1]); strings.Contains(string(data), "secret-token") {
+		t.Errorf("expected the push token left out, got %s", data)
+	}
+
+	got = nil
+	_ = repos.Routes.DeleteRoute("tech-1", day)
+	_ = repos.Sync.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", JobID: "job-1", TechnicianID: "tech-1", QuantityUsed: 2})
+	bus.Run(ctx)
+	if len(got) != 2 || got[0].Data != (RouteChangedData{RouteID: "tech-1_2026-10-15", ServiceDate: "2026-10-15", Deleted: true}) || got[1].Type != EventTreatmentLogged {
+		t.Fatalf("unexpected events %+v", got)
+	}
+}
+
+func TestPubSubPublisher(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Data       string            `json:"data"`
+			Attributes map[string]string `json:"attributes"`
+		} `json:"messages"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/demo/topics/pestgenie-events:publish" || r.Header.Get("Authorization") != "Bearer owner" {
+			http.Error(w, "unexpected request "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"messageIds":["1"]}`))
+	}))
+	defer srv.Close()
+
+	c := cfg
+	c.Driver, c.Project, c.PubSubTopic, c.PubSubEmulator = "pubsub", "demo", "pestgenie-events", strings.TrimPrefix(srv.URL, "http://")
+	e := JobUploaded(models.JobUpload{ID: "job-1", TechnicianID: "tech-1"})
+	if err := NewPublisher(c).Publish(context.Background(), []Event{e}); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Messages) != 1 || body.Messages[0].Attributes["type"] != EventJobUploaded || body.Messages[0].Attributes["technicianId"] != "tech-1" {
+		t.Fatalf("unexpected publish %+v", body)
+	}
+	data, _ := base64.StdEncoding.DecodeString(body.Messages[0].Data)
+	var decoded struct {
+		ID   string          `json:"id"`
+		Data JobUploadedData `json:"data"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.ID != e.ID || decoded.Data.JobID != "job-1" {
+		t.Errorf("unexpected message data %s", data)
+	}
+
+	c.PubSubTopic = "missing"
+	if err := NewPublisher(c).Publish(context.Background(), []Event{e}); err == nil {
+		t.Error("expected a failed publish reported")
+	}
+}
+
+func TestPubSubSubscribe(t *testing.T) {
+	e := RouteChanged(models.Route{TenantID: "acme", TechnicianID: "tech-1", ServiceDate: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)}, true)
+	data, _ := json.Marshal(e)
+	var mu sync.Mutex
+	var calls []string
+	pulled := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		first := !pulled && strings.HasSuffix(r.URL.Path, ":pull")
+		if first {
+			pulled = true
+		}
+		mu.Unlock()
+		switch {
+		case first:
+			_ = json.NewEncoder(w).Encode(map[string]any{"receivedMessages": []map[string]any{
+				{"ackId": "a-1", "message": map[string]string{"data": base64.StdEncoding.EncodeToString(data)}},
+				{"ackId": "a-2", "message": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("not an event"))}},
+			}})
+		case strings.HasSuffix(r.URL.Path, ":pull"):
+			_, _ = io.Copy(io.Discard, r.Body) // so the cancelled pull is noticed
+			<-r.Context().Done()
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := cfg
+	c.Driver, c.Project, c.PubSubTopic, c.PubSubEmulator = "pubsub", "demo", "pestgenie-events", strings.TrimPrefix(srv.URL, "http://")
+	bus := NewBus(c, NewPublisher(c), nil)
+	got := make(chan Event, 1)
+	bus.Subscribe(func(_ context.Context, e Event) { got <- e })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bus.Run(ctx)
+		close(done)
+	}()
+
+	received := <-got
+	var route RouteChangedData
+	if err := received.Decode(&route); err != nil || received.ID != e.ID || received.TenantID != "acme" || route.RouteID != "tech-1_2026-10-15" || !route.Deleted {
+		t.Fatalf("unexpected event %+v (%v)", received, err)
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) < 4 || !strings.HasPrefix(calls[0], "PUT pestgenie-events-") || calls[2] != "POST "+strings.TrimPrefix(calls[0], "PUT ")+":acknowledge" {
+		t.Fatalf("unexpected requests %q", calls)
+	}
+	if last := calls[len(calls)-1]; last != "DELETE "+strings.TrimPrefix(calls[0], "PUT ") {
+		t.Errorf("expected the subscription deleted on shutdown, got %q", calls)
+	}
+}
@@ -0,0 +1,243 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/gcp"
+)
+
+const (
+	// pullWait bounds each pull, which Pub/Sub holds open until messages
+	// arrive.
+	pullWait = 30 * time.Second
+	// pullRetry is how long receiving waits after a failed request.
+	pullRetry = 5 * time.Second
+	// subscriptionTTL expires the subscriptions of instances that stopped
+	// without deleting theirs; one day is the shortest Pub/Sub allows.
+	subscriptionTTL = "86400s"
+)
+
+// pubSubPublisher publishes events to a Pub/Sub topic, one message per
+// event. Messages carry the event type and technician as attributes, so
+// subscriptions can filter on them. Once subscribed to, it receives the
+// topic's events through a subscription of this instance's own, so every
+// instance sees every event.
+type pubSubPublisher struct {
+	topic        string // .../projects/{project}/topics/{topic}
+	topicName    string // projects/{project}/topics/{topic}
+	subscription string // .../projects/{project}/subscriptions/{topic}-{instance}
+	client       *http.Client
+	puller       *http.Client
+	tokens       gcp.TokenSource
+
+	mu         sync.RWMutex
+	subs       []func(context.Context, Event)
+	subscribed chan struct{} // closed by the first Subscribe
+}
+
+func newPubSubPublisher(cfg config.EventsConfig) *pubSubPublisher {
+	host := "https://pubsub.googleapis.com"
+	var tokens gcp.TokenSource = gcp.NewMetadataTokenSource()
+	if cfg.PubSubEmulator != "" {
+		host = "http://" + strings.TrimPrefix(cfg.PubSubEmulator, "http://")
+		// The emulator does not check credentials.
+		tokens = gcp.StaticToken("owner")
+	}
+	project := host + "/v1/projects/" + url.PathEscape(cfg.Project)
+	return &pubSubPublisher{
+		topic:        project + "/topics/" + url.PathEscape(cfg.PubSubTopic),
+		topicName:    "projects/" + cfg.Project + "/topics/" + cfg.PubSubTopic,
+		subscription: project + "/subscriptions/" + url.PathEscape(cfg.PubSubTopic+"-"+uuid.NewString()),
+		client:       &http.Client{Timeout: cfg.RequestTimeout},
+		puller:       &http.Client{},
+		tokens:       tokens,
+		subscribed:   make(chan struct{}),
+	}
+}
+
+func (p *pubSubPublisher) Publish(ctx context.Context, batch []Event) error {
+	messages := make([]map[string]any, 0, len(batch))
+	for _, e := range batch {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		attributes := map[string]string{"type": e.Type, "id": e.ID}
+		if e.TechnicianID != "" {
+			attributes["technicianId"] = e.TechnicianID
+		}
+		messages = append(messages, map[string]any{"data": base64.StdEncoding.EncodeToString(data), "attributes": attributes})
+	}
+	return p.do(ctx, p.client, http.MethodPost, p.topic+":publish", map[string]any{"messages": messages}, nil)
+}
+
+// Subscribe calls fn with every event the topic receives once the bus
+// runs, on the receiving goroutine.
+func (p *pubSubPublisher) Subscribe(fn func(context.Context, Event)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.subs = append(p.subs, fn); len(p.subs) == 1 {
+		close(p.subscribed)
+	}
+}
+
+// receive creates this instance's subscription once something subscribes,
+// then pulls it until ctx is cancelled and deletes it. Messages are
+// acknowledged before they are handed to subscribers.
+func (p *pubSubPublisher) receive(ctx context.Context, logger *slog.Logger) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-p.subscribed:
+	}
+	for {
+		err := p.do(ctx, p.client, http.MethodPut, p.subscription, map[string]any{
+			"topic":            p.topicName,
+			"expirationPolicy": map[string]string{"ttl": subscriptionTTL},
+		}, nil)
+		if err == nil || errors.Is(err, errAlreadyExists) {
+			break
+		}
+		logger.Error("create events subscription", slog.Any("error", err))
+		if !wait(ctx, pullRetry) {
+			return
+		}
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := p.do(ctx, p.client, http.MethodDelete, p.subscription, nil, nil); err != nil {
+			logger.Warn("delete events subscription", slog.Any("error", err))
+		}
+	}()
+
+	for ctx.Err() == nil {
+		batch, err := p.pull(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("pull events", slog.Any("error", err))
+				wait(ctx, pullRetry)
+			}
+			continue
+		}
+		p.mu.RLock()
+		subs := p.subs // Subscribe only appends
+		p.mu.RUnlock()
+		for _, e := range batch {
+			for _, fn := range subs {
+				fn(ctx, e)
+			}
+		}
+	}
+}
+
+// pull receives and acknowledges the next messages of the subscription.
+// Messages that are not events are acknowledged and skipped.
+func (p *pubSubPublisher) pull(ctx context.Context) ([]Event, error) {
+	pullCtx, cancel := context.WithTimeout(ctx, pullWait)
+	defer cancel()
+	var resp struct {
+		ReceivedMessages []struct {
+			AckID   string `json:"ackId"`
+			Message struct {
+				Data string `json:"data"`
+			} `json:"message"`
+		} `json:"receivedMessages"`
+	}
+	err := p.do(pullCtx, p.puller, http.MethodPost, p.subscription+":pull", map[string]any{"maxMessages": 100}, &resp)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, nil // nothing published while the pull was open
+	}
+	if err != nil || len(resp.ReceivedMessages) == 0 {
+		return nil, err
+	}
+	ackIDs := make([]string, 0, len(resp.ReceivedMessages))
+	batch := make([]Event, 0, len(resp.ReceivedMessages))
+	for _, m := range resp.ReceivedMessages {
+		ackIDs = append(ackIDs, m.AckID)
+		data, err := base64.StdEncoding.DecodeString(m.Message.Data)
+		if err != nil {
+			continue
+		}
+		var e Event
+		if json.Unmarshal(data, &e) == nil && e.Type != "" {
+			batch = append(batch, e)
+		}
+	}
+	if err := p.do(ctx, p.client, http.MethodPost, p.subscription+":acknowledge", map[string]any{"ackIds": ackIDs}, nil); err != nil {
+		return nil, fmt.Errorf("acknowledge: %w", err)
+	}
+	return batch, nil
+}
+
+// errAlreadyExists is returned by do for 409 responses.
+var errAlreadyExists = errors.New("already exists")
+
+// do sends body, when not nil, as JSON and decodes the response into out,
+// when not nil.
+func (p *pubSubPublisher) do(ctx context.Context, client *http.Client, method, target string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, payload)
+	if err != nil {
+		return err
+	}
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("pubsub token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return errAlreadyExists
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// wait sleeps for d, returning false if ctx is cancelled first.
+func wait(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Publish wraps the route, sync and device repositories so their writes
+// emit domain events onto bus once they succeed; with a nil bus repos is
+// returned as is. Nil repositories are left nil so Repository.Validate
+// still reports them.
+func Publish(repos repository.Repository, bus *Bus) repository.Repository {
+	if bus == nil {
+		return repos
+	}
+	out := repos
+	if repos.Routes != nil {
+		out.Routes = routes{RouteRepository: repos.Routes, bus: bus}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{SyncRepository: repos.Sync, bus: bus}
+	}
+	if repos.Devices != nil {
+		out.Devices = devices{DeviceRepository: repos.Devices, bus: bus}
+	}
+	return out
+}
+
+type routes struct {
+	repository.RouteRepository
+	bus *Bus
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	if err := r.RouteRepository.SaveRoute(route); err != nil {
+		return err
+	}
+	r.bus.Emit(RouteChanged(route, false))
+	return nil
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	route, err := r.GetRoute(technicianID, serviceDate)
+	if err != nil {
+		route = models.Route{TechnicianID: technicianID, ServiceDate: serviceDate}
+	}
+	if err := r.RouteRepository.DeleteRoute(technicianID, serviceDate); err != nil {
+		return err
+	}
+	r.bus.Emit(RouteChanged(route, true))
+	return nil
+}
+
+type syncRepo struct {
+	repository.SyncRepository
+	bus *Bus
+}
+
+func (r syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	if err := r.SyncRepository.SaveJobUpload(upload); err != nil {
+		return err
+	}
+	r.bus.Emit(JobUploaded(upload))
+	return nil
+}
+
+// DeleteJob emits the deletion for the technician its tombstone names,
+// since the job itself is gone once deleted.
+func (r syncRepo) DeleteJob(id string) error {
+	since := time.Now().Add(-time.Second)
+	if err := r.SyncRepository.DeleteJob(id); err != nil {
+		return err
+	}
+	r.emitDeleted(since, id)
+	return nil
+}
+
+func (r syncRepo) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	if err := r.SyncRepository.SaveChemicalTreatment(upload); err != nil {
+		return err
+	}
+	r.bus.Emit(TreatmentLogged(upload))
+	return nil
+}
+
+// SaveBatch emits the events of the batch's writes once all of them are
+// stored.
+func (r syncRepo) SaveBatch(batch repository.Batch) error {
+	since := time.Now().Add(-time.Second)
+	if err := r.SyncRepository.SaveBatch(batch); err != nil {
+		return err
+	}
+	for _, route := range batch.Routes {
+		r.bus.Emit(RouteChanged(route, false))
+	}
+	for _, j := range batch.Jobs {
+		r.bus.Emit(JobUploaded(j))
+	}
+	for _, t := range batch.Treatments {
+		r.bus.Emit(TreatmentLogged(t))
+	}
+	r.emitDeleted(since, batch.DeletedJobs...)
+	return nil
+}
+
+// emitDeleted emits the deletions of jobs ids deleted after since. The
+// jobs are deleted either way; subscribers reconcile on their next query.
+func (r syncRepo) emitDeleted(since time.Time, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	deletions, err := r.ListDeletionsSince(since)
+	if err != nil {
+		return
+	}
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	for _, t := range deletions {
+		if t.Kind == models.TombstoneJob && wanted[t.ID] {
+			r.bus.Emit(JobDeleted(t))
+			delete(wanted, t.ID)
+		}
+	}
+}
+
+type devices struct {
+	repository.DeviceRepository
+	bus *Bus
+}
+
+func (r devices) SaveDeviceToken(token models.DeviceToken) error {
+	if err := r.DeviceRepository.SaveDeviceToken(token); err != nil {
+		return err
+	}
+	r.bus.Emit(DeviceRegistered(token))
+	return nil
+}
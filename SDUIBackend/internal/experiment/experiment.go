@@ -0,0 +1,284 @@
+// Package experiment runs A/B tests and staged rollouts of screen
+// templates. An experiment splits the technicians eligible for a screen
+// between variants, each served a published version of the screen's
+// template. Technicians are bucketed by hashing their ID with the
+// experiment's, so a technician sees the same variant on every request and
+// raising the rollout percentage only adds technicians.
+package experiment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/appversion"
+)
+
+var (
+	// ErrNotFound is returned when an experiment does not exist.
+	ErrNotFound = errors.New("experiment not found")
+	// ErrInvalidExperiment wraps experiment validation failures.
+	ErrInvalidExperiment = errors.New("invalid experiment")
+)
+
+// Experiment statuses. Only running experiments assign variants.
+const (
+	StatusDraft   = "draft"
+	StatusRunning = "running"
+	StatusStopped = "stopped"
+)
+
+// buckets is the resolution of rollout percentages: 100 buckets per percent.
+const buckets = 10000
+
+// Experiment trials template versions of one screen.
+type Experiment struct {
+	ID          string    `json:"id"`
+	ScreenID    string    `json:"screenId"`
+	Description string    `json:"description,omitempty"`
+	Status      string    `json:"status"`
+	Variants    []Variant `json:"variants"`
+	Cohort      Cohort    `json:"cohort"`
+	// Rollout is the percentage of eligible technicians enrolled, 0-100.
+	// Technicians listed in the cohort are always enrolled.
+	Rollout   int       `json:"rollout"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Variant is one arm of an experiment.
+type Variant struct {
+	Name string `json:"name"`
+	// Version is the published template version served to the variant; 0
+	// serves the screen's current template, as a control.
+	Version int `json:"version"`
+	// Weight is the variant's share of enrolled technicians relative to
+	// the other variants.
+	Weight int `json:"weight"`
+}
+
+// Cohort restricts who is eligible for an experiment. Empty lists and
+// versions do not restrict.
+type Cohort struct {
+	// TechnicianIDs are enrolled whatever the rollout percentage, and
+	// whatever the other criteria.
+	TechnicianIDs []string `json:"technicianIds,omitempty"`
+	Regions       []string `json:"regions,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	// MinAppVersion and MaxAppVersion bound the app version, inclusive,
+	// compared as dotted numbers.
+	MinAppVersion string `json:"minAppVersion,omitempty"`
+	MaxAppVersion string `json:"maxAppVersion,omitempty"`
+}
+
+// Subject is who a screen is requested for.
+type Subject struct {
+	TechnicianID string
+	Region       string
+	Role         string
+	AppVersion   string
+}
+
+// Assignment is the variant a technician was bucketed into.
+type Assignment struct {
+	ExperimentID string `json:"experimentId"`
+	Variant      string `json:"variant"`
+	Version      int    `json:"version"`
+}
+
+// Header formats the assignment for the X-SDUI-Experiment response header.
+func (a Assignment) Header() string {
+	return a.ExperimentID + "=" + a.Variant
+}
+
+// Validate checks an experiment's own fields.
+func (e Experiment) Validate() error {
+	var problems []string
+	if strings.TrimSpace(e.ID) == "" {
+		problems = append(problems, "id is required")
+	} else if strings.ContainsAny(e.ID, "/ =,") {
+		problems = append(problems, "id must not contain slashes, spaces, '=' or ','")
+	}
+	if strings.TrimSpace(e.ScreenID) == "" {
+		problems = append(problems, "screenId is required")
+	}
+	switch e.Status {
+	case StatusDraft, StatusRunning, StatusStopped:
+	default:
+		problems = append(problems, fmt.Sprintf("status must be %s, %s or %s", StatusDraft, StatusRunning, StatusStopped))
+	}
+	if e.Rollout < 0 || e.Rollout > 100 {
+		problems = append(problems, "rollout must be between 0 and 100")
+	}
+	if len(e.Variants) == 0 {
+		problems = append(problems, "at least one variant is required")
+	}
+	names := make(map[string]bool, len(e.Variants))
+	for i, v := range e.Variants {
+		switch {
+		case strings.TrimSpace(v.Name) == "":
+			problems = append(problems, fmt.Sprintf("variants[%d].name is required", i))
+		case strings.ContainsAny(v.Name, " =,"):
+			problems = append(problems, fmt.Sprintf("variants[%d].name must not contain spaces, '=' or ','", i))
+		case names[v.Name]:
+			problems = append(problems, fmt.Sprintf("variant %s is listed more than once", v.Name))
+		}
+		names[v.Name] = true
+		if v.Version < 0 {
+			problems = append(problems, fmt.Sprintf("variants[%d].version must not be negative", i))
+		}
+		if v.Weight <= 0 {
+			problems = append(problems, fmt.Sprintf("variants[%d].weight must be positive", i))
+		}
+	}
+	for field, v := range map[string]string{"cohort.minAppVersion": e.Cohort.MinAppVersion, "cohort.maxAppVersion": e.Cohort.MaxAppVersion} {
+		if _, err := appversion.Parse(v); v != "" && err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q is not a dotted version", field, v))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidExperiment, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Assign buckets s into one of e's variants. It reports false when s is
+// not eligible or not enrolled.
+func (e Experiment) Assign(s Subject) (Assignment, bool) {
+	if e.Status != StatusRunning || s.TechnicianID == "" || len(e.Variants) == 0 {
+		return Assignment{}, false
+	}
+	if !e.Cohort.pinned(s.TechnicianID) {
+		if !e.Cohort.matches(s) || bucket(e.ID, "rollout", s.TechnicianID, buckets) >= e.Rollout*buckets/100 {
+			return Assignment{}, false
+		}
+	}
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	// A separate hash from the rollout's, so a technician's variant does
+	// not depend on when they were enrolled.
+	n := bucket(e.ID, "variant", s.TechnicianID, total)
+	for _, v := range e.Variants {
+		if n < v.Weight {
+			return Assignment{ExperimentID: e.ID, Variant: v.Name, Version: v.Version}, true
+		}
+		n -= v.Weight
+	}
+	return Assignment{}, false
+}
+
+func (c Cohort) pinned(technicianID string) bool {
+	for _, id := range c.TechnicianIDs {
+		if id == technicianID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Cohort) matches(s Subject) bool {
+	if !oneOf(s.Region, c.Regions) || !oneOf(s.Role, c.Roles) {
+		return false
+	}
+	if c.MinAppVersion == "" && c.MaxAppVersion == "" {
+		return true
+	}
+	version, err := appversion.Parse(s.AppVersion)
+	if err != nil {
+		return false
+	}
+	if min, err := appversion.Parse(c.MinAppVersion); err == nil && c.MinAppVersion != "" && appversion.Compare(version, min) < 0 {
+		return false
+	}
+	if max, err := appversion.Parse(c.MaxAppVersion); err == nil && c.MaxAppVersion != "" && appversion.Compare(version, max) > 0 {
+		return false
+	}
+	return true
+}
+
+// oneOf reports whether value is in allowed, ignoring case. An empty
+// allowed list accepts anything.
+func oneOf(value string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(a), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucket hashes a technician into [0, n) for one use within an experiment.
+func bucket(experimentID, salt, technicianID string, n int) int {
+	sum := sha256.Sum256([]byte(experimentID + "/" + salt + "/" + technicianID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(n))
+}
+
+// Store persists experiments.
+type Store interface {
+	SaveExperiment(e Experiment) error
+	GetExperiment(id string) (Experiment, error)
+	ListExperiments() ([]Experiment, error)
+	DeleteExperiment(id string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	experiments map[string]Experiment
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{experiments: make(map[string]Experiment)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveExperiment(e Experiment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.experiments[e.ID] = e
+	return nil
+}
+
+func (m *MemoryStore) GetExperiment(id string) (Experiment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.experiments[id]
+	if !ok {
+		return Experiment{}, ErrNotFound
+	}
+	return e, nil
+}
+
+func (m *MemoryStore) ListExperiments() ([]Experiment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Experiment, 0, len(m.experiments))
+	for _, e := range m.experiments {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteExperiment(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.experiments[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.experiments, id)
+	return nil
+}
@@ -0,0 +1,85 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestAssignmentIsDeterministicAndStableAsRolloutGrows(t *testing.T) {
+	e := Experiment{
+		ID: "home-cards", ScreenID: "home", Status: StatusRunning, Rollout: 20,
+		Variants: []Variant{{Name: "control", Weight: 1}, {Name: "cards", Version: 2, Weight: 1}},
+	}
+	enrolled := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		id := fmt.Sprintf("tech-%d", i)
+		if a, ok := e.Assign(Subject{TechnicianID: id}); ok {
+			enrolled[id] = a.Variant
+			counts[a.Variant]++
+			if again, _ := e.Assign(Subject{TechnicianID: id}); again != a {
+				t.Fatalf("expected %s assigned the same way twice, got %+v and %+v", id, a, again)
+			}
+		}
+	}
+	if n := len(enrolled); n < 320 || n > 480 {
+		t.Fatalf("expected about 20%% of 2000 enrolled, got %d", n)
+	}
+	if counts["control"] < 120 || counts["cards"] < 120 {
+		t.Fatalf("expected enrolled technicians split between variants, got %v", counts)
+	}
+
+	e.Rollout = 50
+	for id, variant := range enrolled {
+		if a, ok := e.Assign(Subject{TechnicianID: id}); !ok || a.Variant != variant {
+			t.Fatalf("expected %s to stay in %s as the rollout grows, got %+v", id, variant, a)
+		}
+	}
+}
+
+func TestCohortRestrictsEligibility(t *testing.T) {
+	e := Experiment{
+		ID: "pilot", ScreenID: "home", Status: StatusRunning, Rollout: 100,
+		Variants: []Variant{{Name: "new", Version: 3, Weight: 1}},
+		Cohort:   Cohort{Regions: []string{"north"}, Roles: []string{"technician"}, MinAppVersion: "2.4", TechnicianIDs: []string{"qa-1"}},
+	}
+	cases := []struct {
+		subject Subject
+		want    bool
+	}{
+		{Subject{TechnicianID: "t1", Region: "North", Role: "technician", AppVersion: "2.10.0"}, true},
+		{Subject{TechnicianID: "t1", Region: "north", Role: "technician", AppVersion: "2.3.9"}, false},
+		{Subject{TechnicianID: "t1", Region: "south", Role: "technician", AppVersion: "3.0"}, false},
+		{Subject{TechnicianID: "t1", Region: "north", Role: "technician"}, false},
+		{Subject{TechnicianID: "qa-1", Region: "south"}, true},
+		{Subject{Region: "north", Role: "technician", AppVersion: "3.0"}, false},
+	}
+	for _, tc := range cases {
+		if _, ok := e.Assign(tc.subject); ok != tc.want {
+			t.Errorf("%+v: expected enrolled=%v", tc.subject, tc.want)
+		}
+	}
+}
+
+func TestSaveChecksVersionsAndRunningExperiments(t *testing.T) {
+	store := storememory.NewStore()
+	_ = store.SaveTemplate(domain.ScreenTemplate{ID: "home", Version: 2, PayloadJSON: []byte(`{}`)})
+	svc := NewService(NewMemoryStore(), repository.Repository{Screens: store}, nil)
+
+	e := Experiment{ScreenID: "home", Status: StatusRunning, Rollout: 10, Variants: []Variant{{Name: "control", Weight: 9}, {Name: "v2", Version: 2, Weight: 1}}}
+	if _, err := svc.Save("first", e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Save("second", e); !errors.Is(err, ErrInvalidExperiment) {
+		t.Fatalf("expected a second running experiment on the screen rejected, got %v", err)
+	}
+	e.Variants[1].Version = 7
+	if _, err := svc.Save("first", e); !errors.Is(err, ErrInvalidExperiment) {
+		t.Fatalf("expected an unpublished version rejected, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package experiment
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes experiment management.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an experiment handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListExperiments)
+	r.Get("/{experimentId}", h.GetExperiment)
+	r.Put("/{experimentId}", h.SaveExperiment)
+	r.Delete("/{experimentId}", h.DeleteExperiment)
+	r.Get("/{experimentId}/assignment", h.PreviewAssignment)
+}
+
+// ListExperiments returns experiments, optionally for one ?screenId=.
+func (h *Handler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	experiments, err := h.service.Experiments(r.URL.Query().Get("screenId"))
+	if err != nil {
+		h.fail(w, r, "failed to list experiments", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"experiments": experiments})
+}
+
+// GetExperiment returns an experiment.
+func (h *Handler) GetExperiment(w http.ResponseWriter, r *http.Request) {
+	e, err := h.service.Experiment(chi.URLParam(r, "experimentId"))
+	if err != nil {
+		h.fail(w, r, "failed to load experiment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, e)
+}
+
+// SaveExperiment creates or replaces an experiment.
+func (h *Handler) SaveExperiment(w http.ResponseWriter, r *http.Request) {
+	var payload Experiment
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	e, err := h.service.Save(chi.URLParam(r, "experimentId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save experiment", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, e)
+}
+
+// DeleteExperiment removes an experiment.
+func (h *Handler) DeleteExperiment(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(chi.URLParam(r, "experimentId")); err != nil {
+		h.fail(w, r, "failed to delete experiment", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewAssignment returns the variant ?technicianId= would get, with an
+// optional ?appVersion=, whether or not the experiment is running.
+func (h *Handler) PreviewAssignment(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	technicianID := q.Get("technicianId")
+	if technicianID == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technicianId", "technicianId query parameter is required")
+		return
+	}
+	a, enrolled, err := h.service.Preview(chi.URLParam(r, "experimentId"), technicianID, q.Get("appVersion"))
+	if err != nil {
+		h.fail(w, r, "failed to preview assignment", err)
+		return
+	}
+	if !enrolled {
+		respond.JSON(w, http.StatusOK, map[string]any{"enrolled": false})
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"enrolled": true, "assignment": a})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidExperiment):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
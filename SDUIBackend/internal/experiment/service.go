@@ -0,0 +1,133 @@
+package experiment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Service manages experiments and assigns technicians to their variants.
+type Service struct {
+	store  Store
+	repos  repository.Repository
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService wires an experiment service. Variant template versions are
+// checked against the ScreenRepository in repos.
+func NewService(store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, repos: repos, logger: logger, now: time.Now}
+}
+
+// Save creates or replaces an experiment. Variants must name published
+// template versions of the screen, and a screen runs one experiment at a
+// time.
+func (s *Service) Save(id string, e Experiment) (Experiment, error) {
+	e.ID = strings.TrimSpace(id)
+	if e.Status == "" {
+		e.Status = StatusDraft
+	}
+	if err := e.Validate(); err != nil {
+		return Experiment{}, err
+	}
+	for _, v := range e.Variants {
+		if v.Version == 0 {
+			continue
+		}
+		if _, err := s.repos.Screens.GetTemplate(e.ScreenID, v.Version); err != nil {
+			return Experiment{}, fmt.Errorf("%w: variant %s: screen %s has no version %d", ErrInvalidExperiment, v.Name, e.ScreenID, v.Version)
+		}
+	}
+	all, err := s.store.ListExperiments()
+	if err != nil {
+		return Experiment{}, err
+	}
+	now := s.now().UTC()
+	e.CreatedAt = now
+	for _, other := range all {
+		if other.ID == e.ID {
+			e.CreatedAt = other.CreatedAt
+			continue
+		}
+		if e.Status == StatusRunning && other.Status == StatusRunning && other.ScreenID == e.ScreenID {
+			return Experiment{}, fmt.Errorf("%w: experiment %s is already running on screen %s", ErrInvalidExperiment, other.ID, e.ScreenID)
+		}
+	}
+	e.UpdatedAt = now
+	if err := s.store.SaveExperiment(e); err != nil {
+		return Experiment{}, err
+	}
+	s.logger.Info("experiment saved", slog.String("experiment", e.ID), slog.String("screen", e.ScreenID), slog.String("status", e.Status), slog.Int("rollout", e.Rollout))
+	return e, nil
+}
+
+// Experiment returns an experiment.
+func (s *Service) Experiment(id string) (Experiment, error) {
+	return s.store.GetExperiment(id)
+}
+
+// Experiments lists experiments, for one screen when screenID is set.
+func (s *Service) Experiments(screenID string) ([]Experiment, error) {
+	all, err := s.store.ListExperiments()
+	if err != nil {
+		return nil, err
+	}
+	out := all[:0]
+	for _, e := range all {
+		if screenID == "" || e.ScreenID == screenID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Delete removes an experiment; its technicians go back to the screen's
+// current template.
+func (s *Service) Delete(id string) error {
+	return s.store.DeleteExperiment(id)
+}
+
+// Assign returns the variant of the experiment running on screenID that
+// subject is bucketed into. It reports false when no experiment is running
+// on the screen, subject is not enrolled, or the service is nil.
+func (s *Service) Assign(screenID string, subject Subject) (Assignment, bool) {
+	if s == nil {
+		return Assignment{}, false
+	}
+	all, err := s.store.ListExperiments()
+	if err != nil {
+		s.logger.Warn("list experiments", slog.String("screen", screenID), slog.Any("error", err))
+		return Assignment{}, false
+	}
+	for _, e := range all {
+		if e.ScreenID == screenID && e.Status == StatusRunning {
+			return e.Assign(subject)
+		}
+	}
+	return Assignment{}, false
+}
+
+// Preview returns the variant a technician would be assigned by an
+// experiment, as if it were running, so rollouts can be checked before they
+// start. The technician's region and role come from the repository.
+func (s *Service) Preview(id, technicianID, appVersion string) (Assignment, bool, error) {
+	e, err := s.store.GetExperiment(id)
+	if err != nil {
+		return Assignment{}, false, err
+	}
+	subject := Subject{TechnicianID: technicianID, AppVersion: appVersion}
+	if tech, err := s.repos.Technicians.GetByID(technicianID); err == nil {
+		subject.Region, subject.Role = tech.Region, tech.Role
+	}
+	e.Status = StatusRunning
+	a, ok := e.Assign(subject)
+	return a, ok, nil
+}
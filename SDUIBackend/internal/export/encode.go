@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+)
+
+// dataset is one exported table.
+type dataset struct {
+	name    string
+	columns []string
+	rows    [][]string
+}
+
+// collect reads every exported dataset from repos, and equipment
+// calibration status from equip unless it is nil. Only assets owned reports
+// true for are exported; repos is expected to be scoped to the same tenant.
+func collect(repos repository.Repository, equip *calibration.Service, owned func(calibration.Asset) bool) ([]dataset, error) {
+	jobs, err := repos.Sync.ListPendingJobs(0)
+	if err != nil {
+		return nil, err
+	}
+	chemicals, err := repos.Sync.ListPendingChemicals(0)
+	if err != nil {
+		return nil, err
+	}
+	treatments, err := repos.Sync.ListPendingTreatments(0)
+	if err != nil {
+		return nil, err
+	}
+	// A treatment's calibration status is as of its application date, so
+	// re-exports report what applied at the time.
+	states := make([]string, len(treatments))
+	for i, t := range treatments {
+		if t.EquipmentID == "" || equip == nil {
+			continue
+		}
+		st, err := equip.StatusAt(t.EquipmentID, t.ApplicationDate)
+		switch {
+		case errors.Is(err, calibration.ErrNotFound):
+			states[i] = "unknown_equipment"
+		case err != nil:
+			return nil, err
+		default:
+			states[i] = st.State
+		}
+	}
+	datasets := []dataset{jobsDataset(jobs), chemicalsDataset(chemicals), treatmentsDataset(treatments, states)}
+	if equip != nil {
+		statuses, err := equip.Statuses("", "")
+		if err != nil {
+			return nil, err
+		}
+		out := statuses[:0]
+		for _, st := range statuses {
+			if owned(st.Asset) {
+				out = append(out, st)
+			}
+		}
+		datasets = append(datasets, calibrationDataset(out))
+	}
+	return datasets, nil
+}
+
+func jobsDataset(jobs []models.JobUpload) dataset {
+	ds := dataset{
+		name:    "jobs",
+		columns: []string{"id", "technician_id", "customer_name", "address", "scheduled_date", "status", "received_at"},
+	}
+	for _, j := range jobs {
+		ds.rows = append(ds.rows, []string{
+			j.ID, j.TechnicianID, j.CustomerName, j.Address,
+			formatTime(j.ScheduledDate), j.Status, formatTime(j.ReceivedAt),
+		})
+	}
+	return ds
+}
+
+func chemicalsDataset(chemicals []models.ChemicalUpload) dataset {
+	ds := dataset{
+		name: "chemicals",
+		columns: []string{"id", "technician_id", "name", "active_ingredient", "manufacturer_name", "epa_registration",
+			"concentration", "unit_of_measure", "quantity_in_stock", "expiration_date", "last_modified"},
+	}
+	for _, c := range chemicals {
+		ds.rows = append(ds.rows, []string{
+			c.ID, c.TechnicianID, c.Name, c.ActiveIngredient, c.ManufacturerName, c.EPARegistration,
+			formatFloat(c.Concentration), c.UnitOfMeasure, formatFloat(c.QuantityInStock),
+			formatTime(c.ExpirationDate), formatTime(c.LastModified),
+		})
+	}
+	return ds
+}
+
+// treatmentsDataset renders treatments; states[i] is the calibration state
+// of treatment i's equipment when it was applied. New columns go at the end
+// so positional readers keep working.
+func treatmentsDataset(treatments []models.ChemicalTreatmentUpload, states []string) dataset {
+	ds := dataset{
+		name: "chemical_treatments",
+		columns: []string{"id", "job_id", "chemical_id", "lot_number", "technician_id", "applicator_name", "application_date",
+			"application_method", "target_pests", "quantity_used", "dosage_rate", "dilution_ratio",
+			"environmental_notes", "weather_conditions", "notes", "last_modified", "equipment_id", "calibration_status"},
+	}
+	for i, t := range treatments {
+		ds.rows = append(ds.rows, []string{
+			t.ID, t.JobID, t.ChemicalID, t.LotNumber, t.TechnicianID, t.ApplicatorName, formatTime(t.ApplicationDate),
+			t.ApplicationMethod, t.TargetPests, formatFloat(t.QuantityUsed), formatFloat(t.DosageRate), t.DilutionRatio,
+			t.EnvironmentalNotes, t.WeatherConditions, t.Notes, formatTime(t.LastModified), t.EquipmentID, states[i],
+		})
+	}
+	return ds
+}
+
+func calibrationDataset(statuses []calibration.Status) dataset {
+	ds := dataset{
+		name: "equipment_calibration",
+		columns: []string{"asset_id", "name", "kind", "serial_number", "technician_id", "status",
+			"last_calibrated_at", "calibrated_by", "output_rate", "output_unit", "due_at"},
+	}
+	for _, st := range statuses {
+		row := []string{st.Asset.ID, st.Asset.Name, st.Asset.Kind, st.Asset.SerialNumber, st.Asset.TechnicianID, st.State, "", "", "", "", ""}
+		if c := st.LastCalibration; c != nil {
+			row[6], row[7], row[8], row[9] = formatTime(c.CalibratedAt), c.TechnicianID, formatFloat(c.OutputRate), c.OutputUnit
+		}
+		if st.DueAt != nil {
+			row[10] = formatTime(*st.DueAt)
+		}
+		ds.rows = append(ds.rows, row)
+	}
+	return ds
+}
+
+// encodeCSV renders a dataset with a header row.
+func encodeCSV(ds dataset) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(ds.columns); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(ds.rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
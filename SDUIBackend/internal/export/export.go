@@ -0,0 +1,197 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Destination kinds.
+const (
+	KindGCS = "gcs"
+	KindS3  = "s3"
+)
+
+// Export formats.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Delivery statuses.
+const (
+	StatusRunning   = "running"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrNotFound is returned when a destination does not exist.
+	ErrNotFound = errors.New("export destination not found")
+	// ErrInvalidDestination wraps every destination validation failure.
+	ErrInvalidDestination = errors.New("invalid destination")
+)
+
+// Destination is a customer-owned bucket that receives nightly exports.
+// Credentials are never stored here: CredentialsSecret names the secret that
+// holds them so they stay in the secret provider.
+type Destination struct {
+	ID                string    `json:"id"`
+	TenantID          string    `json:"tenantId"`
+	Kind              string    `json:"kind"`
+	Bucket            string    `json:"bucket"`
+	Prefix            string    `json:"prefix,omitempty"`
+	Region            string    `json:"region,omitempty"`
+	Endpoint          string    `json:"endpoint,omitempty"`
+	CredentialsSecret string    `json:"credentialsSecret"`
+	Format            string    `json:"format"`
+	HourUTC           int       `json:"hourUtc"`
+	Enabled           bool      `json:"enabled"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// Validate checks a destination before it is stored.
+func (d Destination) Validate() error {
+	var problems []string
+	if d.TenantID == "" {
+		problems = append(problems, "tenantId is required")
+	}
+	if d.Kind != KindGCS && d.Kind != KindS3 {
+		problems = append(problems, "kind must be gcs or s3")
+	}
+	if d.Bucket == "" {
+		problems = append(problems, "bucket is required")
+	}
+	if d.Kind == KindS3 && d.Region == "" && d.Endpoint == "" {
+		problems = append(problems, "region is required for s3")
+	}
+	if d.CredentialsSecret == "" {
+		problems = append(problems, "credentialsSecret is required")
+	}
+	switch d.Format {
+	case FormatCSV, FormatParquet:
+	default:
+		problems = append(problems, "format must be csv or parquet")
+	}
+	if d.HourUTC < 0 || d.HourUTC > 23 {
+		problems = append(problems, "hourUtc must be between 0 and 23")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDestination, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Delivery records one export run to a destination.
+type Delivery struct {
+	ID            string     `json:"id"`
+	DestinationID string     `json:"destinationId"`
+	TenantID      string     `json:"tenantId"`
+	Trigger       string     `json:"trigger"` // schedule, manual
+	Status        string     `json:"status"`
+	StartedAt     time.Time  `json:"startedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	Objects       []string   `json:"objects,omitempty"`
+	Rows          int        `json:"rows"`
+	Bytes         int        `json:"bytes"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// Store persists destinations and delivery history.
+type Store interface {
+	SaveDestination(d Destination) error
+	GetDestination(id string) (Destination, error)
+	ListDestinations() ([]Destination, error)
+	DeleteDestination(id string) error
+	SaveDelivery(d Delivery) error
+	ListDeliveries(destinationID string, limit int) ([]Delivery, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	destinations map[string]Destination
+	deliveries   map[string][]Delivery // by destination, oldest first
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		destinations: make(map[string]Destination),
+		deliveries:   make(map[string][]Delivery),
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveDestination(d Destination) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.destinations[d.ID] = d
+	return nil
+}
+
+func (m *MemoryStore) GetDestination(id string) (Destination, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.destinations[id]
+	if !ok {
+		return Destination{}, ErrNotFound
+	}
+	return d, nil
+}
+
+func (m *MemoryStore) ListDestinations() ([]Destination, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Destination, 0, len(m.destinations))
+	for _, d := range m.destinations {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteDestination(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.destinations[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.destinations, id)
+	delete(m.deliveries, id)
+	return nil
+}
+
+// SaveDelivery inserts or updates a delivery by ID.
+func (m *MemoryStore) SaveDelivery(d Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	history := m.deliveries[d.DestinationID]
+	for i := range history {
+		if history[i].ID == d.ID {
+			history[i] = d
+			return nil
+		}
+	}
+	m.deliveries[d.DestinationID] = append(history, d)
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries first.
+func (m *MemoryStore) ListDeliveries(destinationID string, limit int) ([]Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	history := m.deliveries[destinationID]
+	if limit <= 0 || limit > len(history) {
+		limit = len(history)
+	}
+	out := make([]Delivery, 0, limit)
+	for i := len(history) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, history[i])
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/operation"
+)
+
+// OperationKind is the kind of operation a manual export runs as.
+const OperationKind = "export"
+
+// Handler exposes admin endpoints for export destinations and deliveries.
+type Handler struct {
+	service *Service
+	ops     *operation.Service
+}
+
+// NewHandler creates an export handler. Manual exports run as operations
+// on ops.
+func NewHandler(service *Service, ops *operation.Service) *Handler {
+	h := &Handler{service: service, ops: ops}
+	ops.Register(OperationKind, h.run)
+	return h
+}
+
+// Routes mounts the export endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListDestinations)
+	r.Post("/", h.CreateDestination)
+	r.Delete("/{destinationId}", h.DeleteDestination)
+	r.Post("/{destinationId}/run", h.RunNow)
+	r.Get("/{destinationId}/deliveries", h.ListDeliveries)
+}
+
+// CreateDestination registers a customer bucket.
+func (h *Handler) CreateDestination(w http.ResponseWriter, r *http.Request) {
+	var payload Destination
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+
+	dest, err := h.service.CreateDestination(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create export destination", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, dest)
+}
+
+// ListDestinations returns destinations, optionally filtered by ?tenantId=.
+func (h *Handler) ListDestinations(w http.ResponseWriter, r *http.Request) {
+	destinations, err := h.service.Destinations(r.URL.Query().Get("tenantId"))
+	if err != nil {
+		h.fail(w, r, "failed to list export destinations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"destinations": destinations})
+}
+
+// DeleteDestination removes a destination.
+func (h *Handler) DeleteDestination(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteDestination(chi.URLParam(r, "destinationId")); err != nil {
+		h.fail(w, r, "failed to delete export destination", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunNow starts an export outside the nightly schedule and answers with the
+// operation running it; the operation's result is the delivery.
+func (h *Handler) RunNow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "destinationId")
+	if _, err := h.service.Destination(id); err != nil {
+		h.fail(w, r, "failed to run export", err)
+		return
+	}
+	op, err := h.ops.Start(r.Context(), OperationKind, id)
+	if err != nil {
+		h.fail(w, r, "failed to run export", err)
+		return
+	}
+	operation.Accepted(w, op)
+}
+
+// run exports to destination id as an operation.
+func (h *Handler) run(ctx context.Context, id string, t *operation.Tracker) (any, error) {
+	delivery, err := h.service.RunNow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Link("deliveries", "/v1/admin/exports/destinations/"+id+"/deliveries")
+	if delivery.Status == StatusFailed {
+		return delivery, errors.New(delivery.Error)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries reports delivery status for a destination, newest first.
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+	deliveries, err := h.service.Deliveries(chi.URLParam(r, "destinationId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list export deliveries", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidDestination):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
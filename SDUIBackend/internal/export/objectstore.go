@@ -0,0 +1,179 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Credentials are the customer-supplied HMAC keys stored in the secret
+// provider as JSON. GCS destinations use interoperability HMAC keys so both
+// kinds share the same signing path.
+type Credentials struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+}
+
+func parseCredentials(raw string) (Credentials, error) {
+	var creds Credentials
+	if err := json.Unmarshal([]byte(raw), &creds); err != nil {
+		return Credentials{}, fmt.Errorf("parse credentials: %w", err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, errors.New("credentials must include accessKeyId and secretAccessKey")
+	}
+	return creds, nil
+}
+
+// ObjectWriter uploads a single object to a destination bucket.
+type ObjectWriter interface {
+	Put(ctx context.Context, dest Destination, creds Credentials, key, contentType string, body []byte) error
+}
+
+// HTTPObjectWriter writes objects through the S3 XML API, which GCS also
+// serves for HMAC-authenticated clients.
+type HTTPObjectWriter struct {
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewHTTPObjectWriter creates a writer with the given request timeout.
+func NewHTTPObjectWriter(timeout time.Duration) *HTTPObjectWriter {
+	return &HTTPObjectWriter{Client: &http.Client{Timeout: timeout}, now: time.Now}
+}
+
+// Put uploads body to key using a SigV4-signed PUT.
+func (w *HTTPObjectWriter) Put(ctx context.Context, dest Destination, creds Credentials, key, contentType string, body []byte) error {
+	endpoint, region := endpointFor(dest)
+	target := strings.TrimSuffix(endpoint, "/") + "/" + dest.Bucket + "/" + escapePath(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	sum := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(sum[:]), creds, region, "s3", w.now().UTC())
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload %s: %s: %s", key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func endpointFor(dest Destination) (endpoint, region string) {
+	region = dest.Region
+	switch {
+	case dest.Endpoint != "":
+		endpoint = dest.Endpoint
+	case dest.Kind == KindGCS:
+		endpoint = "https://storage.googleapis.com"
+	default:
+		endpoint = "https://s3." + dest.Region + ".amazonaws.com"
+	}
+	if region == "" {
+		region = "auto"
+	}
+	return endpoint, region
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. Every header already set
+// on the request is signed along with host.
+func signV4(req *http.Request, payloadHash string, creds Credentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), values[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = awsEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsEscape percent-encodes everything except RFC 3986 unreserved characters.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Every Parquet column is a required UTF-8 string holding the same text as
+// the CSV cell, so both formats load alike; warehouses cast columns on load.
+// Each column is one uncompressed PLAIN data page in a single row group.
+const (
+	parquetMagic = "PAR1"
+
+	parquetByteArray    = 6 // Type
+	parquetRequired     = 0 // FieldRepetitionType
+	parquetUTF8         = 0 // ConvertedType
+	parquetPlain        = 0 // Encoding
+	parquetRLE          = 3 // Encoding
+	parquetDataPage     = 0 // PageType
+	parquetUncompressed = 0 // CompressionCodec
+)
+
+// encodeParquet renders a dataset as a Parquet file.
+func encodeParquet(ds dataset) ([]byte, error) {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	offsets := make([]int64, len(ds.columns))
+	sizes := make([]int64, len(ds.columns))
+	for i := range ds.columns {
+		var values []byte
+		for _, row := range ds.rows {
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(row[i])))
+			values = append(values, row[i]...)
+		}
+
+		var page thriftWriter
+		page.i32(1, parquetDataPage)
+		page.i32(2, int32(len(values)))
+		page.i32(3, int32(len(values)))
+		page.begin(5)
+		page.i32(1, int32(len(ds.rows)))
+		page.i32(2, parquetPlain)
+		page.i32(3, parquetRLE)
+		page.i32(4, parquetRLE)
+		page.end()
+		page.end()
+
+		offsets[i] = int64(file.Len())
+		sizes[i] = int64(page.buf.Len() + len(values))
+		file.Write(page.buf.Bytes())
+		file.Write(values)
+	}
+
+	var meta thriftWriter
+	meta.i32(1, 1)
+	meta.list(2, thriftStruct, len(ds.columns)+1)
+	meta.element()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(ds.columns)))
+	meta.end()
+	for _, name := range ds.columns {
+		meta.element()
+		meta.i32(1, parquetByteArray)
+		meta.i32(3, parquetRequired)
+		meta.binary(4, name)
+		meta.i32(6, parquetUTF8)
+		meta.end()
+	}
+	meta.i64(3, int64(len(ds.rows)))
+	meta.list(4, thriftStruct, 1)
+	meta.element()
+	meta.list(1, thriftStruct, len(ds.columns))
+	var total int64
+	for i, name := range ds.columns {
+		total += sizes[i]
+		meta.element()
+		meta.i64(2, offsets[i])
+		meta.begin(3)
+		meta.i32(1, parquetByteArray)
+		meta.list(2, thriftI32, 2)
+		meta.varint(parquetPlain)
+		meta.varint(parquetRLE)
+		meta.list(3, thriftBinary, 1)
+		meta.raw(name)
+		meta.i32(4, parquetUncompressed)
+		meta.i64(5, int64(len(ds.rows)))
+		meta.i64(6, sizes[i])
+		meta.i64(7, sizes[i])
+		meta.i64(9, offsets[i])
+		meta.end()
+		meta.end()
+	}
+	meta.i64(2, total)
+	meta.i64(3, int64(len(ds.rows)))
+	meta.end()
+	meta.binary(6, "pestgenie export")
+	meta.end()
+
+	file.Write(meta.buf.Bytes())
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(meta.buf.Len())))
+	file.WriteString(parquetMagic)
+	return file.Bytes(), nil
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes the Thrift compact protocol, as much of it as Parquet
+// metadata needs. Field headers carry the delta from the previous field id
+// in the same struct, so the writer tracks it across nested structs.
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16
+	outer []int16
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binary(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.raw(s)
+}
+
+// list writes the header of a list field holding n elements of type elem,
+// which the caller writes next: varint or raw for scalars, element and end
+// around each struct.
+func (w *thriftWriter) list(id int16, elem byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		w.buf.WriteByte(0xf0 | elem)
+		w.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+}
+
+// begin starts a struct field; end closes it.
+func (w *thriftWriter) begin(id int16) {
+	w.field(id, thriftStruct)
+	w.element()
+}
+
+// element starts a struct list element; end closes it.
+func (w *thriftWriter) element() {
+	w.outer = append(w.outer, w.last)
+	w.last = 0
+}
+
+// end closes the innermost struct, or the top-level one.
+func (w *thriftWriter) end() {
+	w.buf.WriteByte(0)
+	if n := len(w.outer); n > 0 {
+		w.last = w.outer[n-1]
+		w.outer = w.outer[:n-1]
+	}
+}
+
+// varint writes a zigzag-encoded integer.
+func (w *thriftWriter) varint(v int64) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+// raw writes a length-prefixed string.
+func (w *thriftWriter) raw(s string) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	w.buf.WriteString(s)
+}
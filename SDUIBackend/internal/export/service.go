@@ -0,0 +1,260 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// Service manages export destinations and runs scheduled deliveries.
+type Service struct {
+	cfg     config.ExportConfig
+	store   Store
+	repos   repository.Repository
+	tenants *tenant.Service
+	equip   *calibration.Service
+	secrets secret.Provider
+	writer  ObjectWriter
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewService wires an export service. Each destination receives its
+// tenant's records only, as tenants scopes them; a nil tenants exports
+// every record. Equipment calibration status is exported from equip; a nil
+// equip leaves it out.
+func NewService(cfg config.ExportConfig, store Store, repos repository.Repository, tenants *tenant.Service, equip *calibration.Service, secrets secret.Provider, writer ObjectWriter, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, tenants: tenants, equip: equip, secrets: secrets, writer: writer, logger: logger, now: time.Now}
+}
+
+// CreateDestination validates and stores a new destination. The credentials
+// secret must resolve so misconfigured destinations fail at creation rather
+// than at 2am.
+func (s *Service) CreateDestination(d Destination) (Destination, error) {
+	if err := d.Validate(); err != nil {
+		return Destination{}, err
+	}
+	if _, err := s.credentials(d); err != nil {
+		return Destination{}, fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	d.ID = uuid.NewString()
+	d.CreatedAt = s.now().UTC()
+	if err := s.store.SaveDestination(d); err != nil {
+		return Destination{}, err
+	}
+	return d, nil
+}
+
+// Destinations lists configured destinations, optionally filtered by tenant.
+func (s *Service) Destinations(tenantID string) ([]Destination, error) {
+	all, err := s.store.ListDestinations()
+	if err != nil || tenantID == "" {
+		return all, err
+	}
+	out := make([]Destination, 0, len(all))
+	for _, d := range all {
+		if d.TenantID == tenantID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// Destination returns a destination.
+func (s *Service) Destination(id string) (Destination, error) {
+	return s.store.GetDestination(id)
+}
+
+// DeleteDestination removes a destination and its delivery history.
+func (s *Service) DeleteDestination(id string) error {
+	return s.store.DeleteDestination(id)
+}
+
+// Deliveries returns the delivery history for a destination, newest first.
+func (s *Service) Deliveries(destinationID string, limit int) ([]Delivery, error) {
+	if _, err := s.store.GetDestination(destinationID); err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(destinationID, limit)
+}
+
+// RunNow exports to a destination immediately and returns the delivery record.
+func (s *Service) RunNow(ctx context.Context, destinationID string) (Delivery, error) {
+	dest, err := s.store.GetDestination(destinationID)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return s.deliver(ctx, dest, "manual"), nil
+}
+
+// Run checks for due destinations every CheckInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	interval := s.cfg.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *Service) runDue(ctx context.Context) {
+	destinations, err := s.store.ListDestinations()
+	if err != nil {
+		s.logger.Error("list export destinations", slog.Any("error", err))
+		return
+	}
+	now := s.now().UTC()
+	for _, dest := range destinations {
+		if due, err := s.due(dest, now); err != nil {
+			s.logger.Error("check export schedule", slog.String("destination", dest.ID), slog.Any("error", err))
+		} else if due {
+			s.deliver(ctx, dest, "schedule")
+		}
+	}
+}
+
+// due reports whether today's scheduled export has not yet been delivered.
+// Failed runs are retried on the next check until one succeeds.
+func (s *Service) due(dest Destination, now time.Time) (bool, error) {
+	if !dest.Enabled || now.Hour() < dest.HourUTC {
+		return false, nil
+	}
+	history, err := s.store.ListDeliveries(dest.ID, 0)
+	if err != nil {
+		return false, err
+	}
+	today := now.Format("2006-01-02")
+	for _, d := range history {
+		if d.Trigger != "schedule" || d.StartedAt.UTC().Format("2006-01-02") != today {
+			continue
+		}
+		if d.Status == StatusDelivered || d.Status == StatusRunning {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *Service) deliver(ctx context.Context, dest Destination, trigger string) Delivery {
+	started := s.now().UTC()
+	delivery := Delivery{
+		ID:            uuid.NewString(),
+		DestinationID: dest.ID,
+		TenantID:      dest.TenantID,
+		Trigger:       trigger,
+		Status:        StatusRunning,
+		StartedAt:     started,
+	}
+	_ = s.store.SaveDelivery(delivery)
+
+	err := s.upload(ctx, dest, started, &delivery)
+
+	finished := s.now().UTC()
+	delivery.FinishedAt = &finished
+	delivery.Status = StatusDelivered
+	if err != nil {
+		delivery.Status = StatusFailed
+		delivery.Error = err.Error()
+		s.logger.Error("export delivery failed", slog.String("destination", dest.ID), slog.String("tenant", dest.TenantID), slog.Any("error", err))
+	} else {
+		s.logger.Info("export delivered", slog.String("destination", dest.ID), slog.String("tenant", dest.TenantID), slog.Int("rows", delivery.Rows))
+	}
+	if err := s.store.SaveDelivery(delivery); err != nil {
+		s.logger.Error("save export delivery", slog.String("destination", dest.ID), slog.Any("error", err))
+	}
+	return delivery
+}
+
+func (s *Service) upload(ctx context.Context, dest Destination, started time.Time, delivery *Delivery) error {
+	creds, err := s.credentials(dest)
+	if err != nil {
+		return err
+	}
+	datasets, err := s.collect(dest)
+	if err != nil {
+		return fmt.Errorf("collect data: %w", err)
+	}
+
+	// One folder per run keeps nightly copies side by side in the bucket.
+	folder := path.Join(dest.Prefix, dest.TenantID, started.Format("2006-01-02T150405Z"))
+	encode, ext, contentType := encodeCSV, ".csv", "text/csv"
+	if dest.Format == FormatParquet {
+		encode, ext, contentType = encodeParquet, ".parquet", "application/vnd.apache.parquet"
+	}
+	for _, ds := range datasets {
+		body, err := encode(ds)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", ds.name, err)
+		}
+		key := path.Join(folder, ds.name+ext)
+		if err := s.writer.Put(ctx, dest, creds, key, contentType, body); err != nil {
+			return err
+		}
+		delivery.Objects = append(delivery.Objects, key)
+		delivery.Rows += len(ds.rows)
+		delivery.Bytes += len(body)
+	}
+	return nil
+}
+
+// collect reads the datasets of dest's tenant. Equipment goes with the
+// tenant of the technician it is assigned to; unassigned equipment, and
+// equipment of unknown technicians, goes with the default tenant, which
+// also owns the records written before tenancy.
+func (s *Service) collect(dest Destination) ([]dataset, error) {
+	scope, ok, err := s.tenants.Scope(dest.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tenant %q: %w", dest.TenantID, err)
+	}
+	if !ok {
+		return collect(s.repos, s.equip, func(calibration.Asset) bool { return true })
+	}
+	repos := scope.Repository(s.repos)
+	return collect(repos, s.equip, func(a calibration.Asset) bool {
+		if a.TechnicianID == "" || repos.Technicians == nil {
+			return scope.Untagged
+		}
+		_, err := repos.Technicians.GetByID(a.TechnicianID)
+		if errors.Is(err, repository.ErrTechnicianNotFound) {
+			if _, err := s.repos.Technicians.GetByID(a.TechnicianID); errors.Is(err, repository.ErrTechnicianNotFound) {
+				return scope.Untagged
+			}
+			return false
+		}
+		return err == nil
+	})
+}
+
+func (s *Service) credentials(dest Destination) (Credentials, error) {
+	raw, err := s.secrets.Get(dest.CredentialsSecret)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("resolve credentials secret %q: %w", dest.CredentialsSecret, err)
+	}
+	return parseCredentials(raw)
+}
@@ -0,0 +1,157 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// NewProvider returns the provider selected by cfg, or nil when flags are
+// disabled.
+func NewProvider(cfg config.FlagsConfig, secrets secret.Provider) (Provider, error) {
+	switch cfg.Provider {
+	case "file":
+		return NewFileProvider(cfg.File)
+	case "env":
+		return NewEnvProvider(cfg.Values)
+	case "ofrep":
+		client := &http.Client{Timeout: cfg.RequestTimeout}
+		return NewOFREPProvider(strings.TrimRight(cfg.URL, "/"), cfg.TokenSecret, secrets, client, cfg.CacheTTL), nil
+	default:
+		return nil, nil
+	}
+}
+
+// Evaluation is a flag resolved for a context, as the admin API reports it.
+type Evaluation struct {
+	Key      string       `json:"key"`
+	Value    any          `json:"value"`
+	Reason   Reason       `json:"reason"`
+	Variant  string       `json:"variant,omitempty"`
+	Error    ErrorCode    `json:"errorCode,omitempty"`
+	Detail   string       `json:"errorDetails,omitempty"`
+	Metadata FlagMetadata `json:"metadata,omitempty"`
+}
+
+// Client evaluates flags with a provider, logging failed evaluations and
+// falling back to the caller's default. A nil *Client has no flags: every
+// evaluation returns the default.
+type Client struct {
+	provider Provider
+	logger   *slog.Logger
+}
+
+// NewClient wraps provider. It returns nil when provider is nil, that is
+// when flags are disabled.
+func NewClient(provider Provider, logger *slog.Logger) *Client {
+	if provider == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Client{provider: provider, logger: logger}
+}
+
+// ProviderName names the provider, or "none".
+func (c *Client) ProviderName() string {
+	if c == nil {
+		return "none"
+	}
+	return c.provider.Metadata().Name
+}
+
+// Bool evaluates a boolean flag, returning fallback when it cannot be.
+func (c *Client) Bool(ctx context.Context, key string, fallback bool, evalCtx FlattenedContext) bool {
+	if c == nil {
+		return fallback
+	}
+	d := c.provider.BooleanEvaluation(ctx, key, fallback, evalCtx)
+	c.logFailure(key, d.ProviderResolutionDetail)
+	return d.Value
+}
+
+// Flag evaluates a flag of any type for a screen, formatted as a
+// placeholder value: booleans as true or false, numbers in their shortest
+// form, strings as is and anything else as JSON. It reports false when
+// the flag did not resolve.
+func (c *Client) Flag(ctx context.Context, key string, evalCtx FlattenedContext) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	d := c.provider.ObjectEvaluation(ctx, key, nil, evalCtx)
+	c.logFailure(key, d.ProviderResolutionDetail)
+	switch v := d.Value.(type) {
+	case nil:
+		return "", false
+	case bool:
+		return strconv.FormatBool(v), true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+// Evaluate resolves a flag for a context with the provider's full detail.
+func (c *Client) Evaluate(ctx context.Context, key string, evalCtx FlattenedContext) Evaluation {
+	if c == nil {
+		return Evaluation{Key: key, Reason: ErrorReason, Error: ProviderNotReadyCode, Detail: "flags are disabled"}
+	}
+	d := c.provider.ObjectEvaluation(ctx, key, nil, evalCtx)
+	return Evaluation{
+		Key:      key,
+		Value:    d.Value,
+		Reason:   d.Reason,
+		Variant:  d.Variant,
+		Error:    d.ResolutionError.Code,
+		Detail:   d.ResolutionError.Message,
+		Metadata: d.FlagMetadata,
+	}
+}
+
+// logFailure logs evaluations that failed for a reason other than the
+// flag not being defined, which is how toggles fall back to config.
+func (c *Client) logFailure(key string, d ProviderResolutionDetail) {
+	var rerr ResolutionError
+	if err := d.Error(); errors.As(err, &rerr) && rerr.Code != FlagNotFoundCode {
+		c.logger.Warn("flag evaluation failed", slog.String("flag", key), slog.String("provider", c.provider.Metadata().Name), slog.String("error", rerr.Error()))
+	}
+}
+
+// TechnicianContext is the evaluation context of a technician: their ID
+// as the targeting key, their profile, and attributes such as the device
+// details of the request.
+func TechnicianContext(tech domain.Technician, attributes map[string]string) FlattenedContext {
+	evalCtx := FlattenedContext{}
+	for key, value := range attributes {
+		evalCtx[key] = value
+	}
+	for key, value := range map[string]string{
+		TargetingKey: tech.ID,
+		"email":      tech.Email,
+		"role":       tech.Role,
+		"region":     tech.Region,
+		"branchId":   tech.BranchID,
+	} {
+		if value != "" {
+			evalCtx[key] = value
+		}
+	}
+	return evalCtx
+}
@@ -0,0 +1,211 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFlags wraps problems with flag definitions.
+var ErrInvalidFlags = errors.New("invalid flag definition")
+
+// Flag states.
+const (
+	StateEnabled  = "ENABLED"
+	StateDisabled = "DISABLED"
+)
+
+// Flag is a flag definition in the layout flagd uses: named variants, the
+// default one, and whether the flag is enabled. Rules, evaluated in order,
+// pick another variant for matching contexts.
+type Flag struct {
+	State          string         `json:"state"`
+	Variants       map[string]any `json:"variants"`
+	DefaultVariant string         `json:"defaultVariant"`
+	Rules          []Rule         `json:"rules,omitempty"`
+}
+
+// Rule serves Variant to contexts whose Attribute is one of In, ignoring
+// case, and to the Rollout percent of targeting keys, hashed per flag so
+// rollouts are stable. A rule without a condition matches every context.
+type Rule struct {
+	Attribute string   `json:"attribute,omitempty"`
+	In        []string `json:"in,omitempty"`
+	Rollout   int      `json:"rollout,omitempty"`
+	Variant   string   `json:"variant"`
+}
+
+// FileProvider serves the flags of a JSON file, read once when it is
+// created:
+//
+//	{"flags": {"newHomeLayout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off",
+//	  "rules": [{"attribute": "region", "in": ["TX"], "variant": "on"}, {"rollout": 10, "variant": "on"}]}}}
+type FileProvider struct {
+	typed
+	name  string
+	flags map[string]Flag
+}
+
+// NewFileProvider loads the flags defined in path.
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Flags map[string]Flag `json:"flags"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFlags, path, err)
+	}
+	return NewStaticProvider(file.Flags)
+}
+
+// NewStaticProvider serves flags, each checked as checkFlag does.
+func NewStaticProvider(flags map[string]Flag) (*FileProvider, error) {
+	checked := make(map[string]Flag, len(flags))
+	for key, f := range flags {
+		f, err := checkFlag(key, f)
+		if err != nil {
+			return nil, err
+		}
+		checked[key] = f
+	}
+	p := &FileProvider{name: "file", flags: checked}
+	p.typed = typed{r: p}
+	return p, nil
+}
+
+// NewEnvProvider serves flags set in an environment variable as
+// comma-separated key=value pairs, such as "gamificationMetrics=false,
+// homeBanner=Spring promo". Booleans get on and off variants so they can
+// be flipped at runtime; numbers and strings are served as is.
+func NewEnvProvider(values string) (*FileProvider, error) {
+	flags := make(map[string]Flag)
+	for _, pair := range strings.Split(values, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(pair, "=")
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q is not key=value", ErrInvalidFlags, pair)
+		}
+		if on, err := strconv.ParseBool(raw); err == nil {
+			f := Flag{Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off"}
+			if on {
+				f.DefaultVariant = "on"
+			}
+			flags[key] = f
+			continue
+		}
+		var value any = raw
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			value = n
+		}
+		flags[key] = Flag{Variants: map[string]any{"value": value}, DefaultVariant: "value"}
+	}
+	p, err := NewStaticProvider(flags)
+	if err != nil {
+		return nil, err
+	}
+	p.name = "env"
+	return p, nil
+}
+
+// checkFlag validates a flag definition. Every flag needs a known state
+// and its default variant, and rules may only name its variants and roll
+// out up to 100 percent. A flag without a state is enabled.
+func checkFlag(key string, f Flag) (Flag, error) {
+	if f.State == "" {
+		f.State = StateEnabled
+	}
+	if f.State != StateEnabled && f.State != StateDisabled {
+		return Flag{}, fmt.Errorf("%w: %s has state %q", ErrInvalidFlags, key, f.State)
+	}
+	if _, ok := f.Variants[f.DefaultVariant]; !ok {
+		return Flag{}, fmt.Errorf("%w: %s has no variant %q", ErrInvalidFlags, key, f.DefaultVariant)
+	}
+	for i, r := range f.Rules {
+		if _, ok := f.Variants[r.Variant]; !ok {
+			return Flag{}, fmt.Errorf("%w: %s rule %d has no variant %q", ErrInvalidFlags, key, i, r.Variant)
+		}
+		if (r.Attribute == "") != (len(r.In) == 0) {
+			return Flag{}, fmt.Errorf("%w: %s rule %d needs both an attribute and its values", ErrInvalidFlags, key, i)
+		}
+		if r.Rollout < 0 || r.Rollout > 100 {
+			return Flag{}, fmt.Errorf("%w: %s rule %d rolls out to %d%%", ErrInvalidFlags, key, i, r.Rollout)
+		}
+	}
+	return f, nil
+}
+
+func (p *FileProvider) Metadata() Metadata { return Metadata{Name: p.name} }
+
+// Flags returns a copy of the flag definitions served.
+func (p *FileProvider) Flags() map[string]Flag {
+	out := make(map[string]Flag, len(p.flags))
+	for key, f := range p.flags {
+		out[key] = f
+	}
+	return out
+}
+
+func (p *FileProvider) resolve(_ context.Context, key string, evalCtx FlattenedContext) (any, ProviderResolutionDetail) {
+	f, ok := p.flags[key]
+	if !ok {
+		return nil, failed(FlagNotFoundCode, key)
+	}
+	if f.State == StateDisabled {
+		return nil, ProviderResolutionDetail{Reason: DisabledReason}
+	}
+	for _, r := range f.Rules {
+		if r.Attribute != "" && !matches(evalCtx[r.Attribute], r.In) {
+			continue
+		}
+		if r.Rollout > 0 && bucket(key, evalCtx) >= r.Rollout {
+			continue
+		}
+		reason := TargetingMatchReason
+		if r.Rollout > 0 {
+			reason = SplitReason
+		}
+		return f.Variants[r.Variant], ProviderResolutionDetail{Reason: reason, Variant: r.Variant}
+	}
+	reason := DefaultReason
+	if len(f.Rules) == 0 {
+		reason = StaticReason
+	}
+	return f.Variants[f.DefaultVariant], ProviderResolutionDetail{Reason: reason, Variant: f.DefaultVariant}
+}
+
+func matches(value any, in []string) bool {
+	if value == nil {
+		return false
+	}
+	s := fmt.Sprint(value)
+	for _, v := range in {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucket places a context's targeting key in one of 100 buckets for a
+// flag. Contexts without a key are all in the last bucket, so only full
+// rollouts include them.
+func bucket(key string, evalCtx FlattenedContext) int {
+	target, _ := evalCtx[TargetingKey].(string)
+	if target == "" {
+		return 99
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key + "/" + target))
+	return int(h.Sum32() % 100)
+}
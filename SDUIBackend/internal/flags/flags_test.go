@@ -0,0 +1,291 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("no secret " + name)
+}
+
+const flagFile = `{"flags": {
+  "newHome": {"variants": {"on": true, "off": false}, "defaultVariant": "off",
+    "rules": [{"attribute": "region", "in": ["tx"], "variant": "on"}, {"rollout": 100, "variant": "on"}]},
+  "half": {"variants": {"on": true, "off": false}, "defaultVariant": "off", "rules": [{"rollout": 50, "variant": "on"}]},
+  "banner": {"state": "ENABLED", "variants": {"a": "Spring promo", "b": 3}, "defaultVariant": "a"},
+  "retired": {"state": "DISABLED", "variants": {"on": true}, "defaultVariant": "on"}
+}}`
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(flagFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewFileProvider(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	ctx := context.Background()
+	tx := FlattenedContext{TargetingKey: "sam", "region": "TX"}
+
+	if d := p.BooleanEvaluation(ctx, "newHome", false, tx); !d.Value || d.Reason != TargetingMatchReason || d.Variant != "on" {
+		t.Errorf("expected the region rule to match, got %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "newHome", false, FlattenedContext{TargetingKey: "ana"}); !d.Value || d.Reason != SplitReason {
+		t.Errorf("expected the full rollout to match, got %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "half", true, FlattenedContext{}); d.Value || d.Reason != DefaultReason {
+		t.Errorf("expected a context without a targeting key left out of the rollout, got %+v", d)
+	}
+	if d := p.StringEvaluation(ctx, "banner", "", tx); d.Value != "Spring promo" || d.Reason != StaticReason {
+		t.Errorf("unexpected string evaluation %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "banner", true, tx); !d.Value || d.ResolutionError.Code != TypeMismatchCode {
+		t.Errorf("expected a type mismatch with the default, got %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "retired", false, tx); d.Value || d.Reason != DisabledReason || d.Error() != nil {
+		t.Errorf("expected a disabled flag to return the default, got %+v", d)
+	}
+	if d := p.IntEvaluation(ctx, "missing", 7, tx); d.Value != 7 || d.ResolutionError.Code != FlagNotFoundCode {
+		t.Errorf("expected flag not found, got %+v", d)
+	}
+
+	if _, err := NewStaticProvider(map[string]Flag{"x": {Variants: map[string]any{"on": true}, DefaultVariant: "off"}}); !errors.Is(err, ErrInvalidFlags) {
+		t.Errorf("expected a missing default variant refused, got %v", err)
+	}
+	if _, err := NewStaticProvider(map[string]Flag{"x": {Variants: map[string]any{"on": true}, DefaultVariant: "on", Rules: []Rule{{Attribute: "region", Variant: "on"}}}}); !errors.Is(err, ErrInvalidFlags) {
+		t.Errorf("expected a rule without values refused, got %v", err)
+	}
+}
+
+func TestOFREPProvider(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Context FlattenedContext `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/ofrep/v1/evaluate/flags/newHome":
+			on := body.Context["region"] == "TX"
+			_ = json.NewEncoder(w).Encode(map[string]any{"key": "newHome", "value": on, "reason": "TARGETING_MATCH", "variant": "on"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"key": "x", "errorCode": "FLAG_NOT_FOUND", "errorDetails": "no such flag"})
+		}
+	}))
+	defer srv.Close()
+
+	p := NewOFREPProvider(srv.URL, "flags-token", staticSecrets{"flags-token": "s3cret"}, srv.Client(), time.Minute)
+	ctx := context.Background()
+	tx := FlattenedContext{TargetingKey: "sam", "region": "TX"}
+	if d := p.BooleanEvaluation(ctx, "newHome", false, tx); !d.Value || d.Reason != TargetingMatchReason || d.Variant != "on" {
+		t.Fatalf("unexpected evaluation %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "newHome", false, tx); !d.Value || d.Reason != CachedReason || calls.Load() != 1 {
+		t.Fatalf("expected the second evaluation cached, got %+v after %d calls", d, calls.Load())
+	}
+	if d := p.BooleanEvaluation(ctx, "newHome", true, FlattenedContext{TargetingKey: "ana", "region": "OK"}); d.Value {
+		t.Errorf("expected another context evaluated separately, got %+v", d)
+	}
+	for i := 0; i < 2; i++ {
+		if d := p.StringEvaluation(ctx, "missing", "fallback", tx); d.Value != "fallback" || d.ResolutionError.Code != FlagNotFoundCode {
+			t.Errorf("expected flag not found, got %+v", d)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected unknown flags cached, got %d calls", calls.Load())
+	}
+
+	unauthorized := NewOFREPProvider(srv.URL, "", nil, srv.Client(), time.Minute)
+	if d := unauthorized.BooleanEvaluation(ctx, "newHome", true, tx); !d.Value || d.ResolutionError.Code != GeneralCode {
+		t.Errorf("expected a general error with the default, got %+v", d)
+	}
+}
+
+func TestClientAndHandler(t *testing.T) {
+	p, err := NewStaticProvider(map[string]Flag{
+		"newHome": {Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off", Rules: []Rule{{Attribute: "region", In: []string{"TX"}, Variant: "on"}}},
+		"limits":  {Variants: map[string]any{"v": map[string]any{"stops": 12.0}}, DefaultVariant: "v"},
+		"ratio":   {Variants: map[string]any{"v": 0.25}, DefaultVariant: "v"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(p, nil)
+	ctx := context.Background()
+	evalCtx := TechnicianContext(domain.Technician{ID: "sam", Region: "TX"}, map[string]string{"appVersion": "3.2.0"})
+	if evalCtx[TargetingKey] != "sam" || evalCtx["appVersion"] != "3.2.0" {
+		t.Fatalf("unexpected context %+v", evalCtx)
+	}
+	for key, want := range map[string]string{"newHome": "true", "limits": `{"stops":12}`, "ratio": "0.25"} {
+		if got, ok := client.Flag(ctx, key, evalCtx); !ok || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", key, want, got, ok)
+		}
+	}
+	if _, ok := client.Flag(ctx, "missing", evalCtx); ok {
+		t.Error("expected a missing flag unresolved")
+	}
+	var none *Client
+	if !none.Bool(ctx, "newHome", true, evalCtx) || none.ProviderName() != "none" {
+		t.Error("expected a nil client to return defaults")
+	}
+
+	store := storememory.NewStore()
+	if err := store.SaveTechnician(domain.Technician{ID: "sam", Region: "TX"}); err != nil {
+		t.Fatal(err)
+	}
+	r := chi.NewRouter()
+	r.Route("/flags", NewHandler(client, NewRuntimeProvider(p, NewMemoryStore(), 0, nil), store).Routes)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/flags/newHome?technicianId=sam", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"value":true`) || !strings.Contains(rec.Body.String(), `"reason":"TARGETING_MATCH"`) {
+		t.Fatalf("unexpected evaluation %d: %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/flags/newHome?technicianId=nobody", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown technician, got %d", rec.Code)
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	p, err := NewEnvProvider("gamificationMetrics=false, communicationsSection=true,banner=Spring promo,maxStops=12")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ctx := context.Background()
+	if d := p.BooleanEvaluation(ctx, "gamificationMetrics", true, nil); d.Value || d.Variant != "off" {
+		t.Errorf("expected the metrics row off, got %+v", d)
+	}
+	if d := p.BooleanEvaluation(ctx, "communicationsSection", false, nil); !d.Value {
+		t.Errorf("expected communications on, got %+v", d)
+	}
+	if d := p.StringEvaluation(ctx, "banner", "", nil); d.Value != "Spring promo" {
+		t.Errorf("unexpected string flag %+v", d)
+	}
+	if d := p.IntEvaluation(ctx, "maxStops", 0, nil); d.Value != 12 {
+		t.Errorf("unexpected number flag %+v", d)
+	}
+	if p.Metadata().Name != "env" {
+		t.Errorf("unexpected provider name %q", p.Metadata().Name)
+	}
+	if _, err := NewEnvProvider("gamificationMetrics"); !errors.Is(err, ErrInvalidFlags) {
+		t.Errorf("expected a pair without a value refused, got %v", err)
+	}
+}
+
+func TestRuntimeFlags(t *testing.T) {
+	base, err := NewEnvProvider("gamificationMetrics=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryStore()
+	p := NewRuntimeProvider(base, store, time.Minute, nil)
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if d := p.BooleanEvaluation(ctx, "gamificationMetrics", false, nil); !d.Value || p.Metadata().Name != "env" {
+		t.Fatalf("expected the configured flag served, got %+v", d)
+	}
+	if _, err := p.Flip("gamificationMetrics", Flip{DefaultVariant: "off"}); err != nil {
+		t.Fatalf("flip: %v", err)
+	}
+	if d := p.BooleanEvaluation(ctx, "gamificationMetrics", true, nil); d.Value {
+		t.Fatalf("expected the flip served immediately, got %+v", d)
+	}
+	if _, err := p.Flip("unknown", Flip{State: StateDisabled}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an undefined flag not found, got %v", err)
+	}
+	if _, err := p.Flip("gamificationMetrics", Flip{DefaultVariant: "maybe"}); !errors.Is(err, ErrInvalidFlags) {
+		t.Errorf("expected an unknown variant refused, got %v", err)
+	}
+
+	// Another instance writes to the store; this one sees it after ttl.
+	if err := store.SaveFlag(StoredFlag{Key: "communicationsSection", Flag: Flag{State: StateEnabled, Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "on"}}); err != nil {
+		t.Fatal(err)
+	}
+	if d := p.BooleanEvaluation(ctx, "communicationsSection", false, nil); d.Value {
+		t.Fatalf("expected the cached flags served within ttl, got %+v", d)
+	}
+	now = now.Add(time.Minute)
+	if d := p.BooleanEvaluation(ctx, "communicationsSection", false, nil); !d.Value {
+		t.Fatalf("expected the stored flag after ttl, got %+v", d)
+	}
+
+	defs, err := p.Definitions()
+	if err != nil || len(defs) != 2 || defs[0].Key != "communicationsSection" || defs[1].Source != "runtime" || defs[1].DefaultVariant != "off" {
+		t.Fatalf("unexpected definitions %+v (%v)", defs, err)
+	}
+	if err := p.Delete("gamificationMetrics"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if d := p.BooleanEvaluation(ctx, "gamificationMetrics", false, nil); !d.Value {
+		t.Fatalf("expected the configured flag back after delete, got %+v", d)
+	}
+	if defs, _ := p.Definitions(); len(defs) != 2 || defs[1].Source != "env" {
+		t.Fatalf("unexpected definitions after delete %+v", defs)
+	}
+}
+
+func TestFlagAdminAPI(t *testing.T) {
+	p := NewRuntimeProvider(nil, NewMemoryStore(), time.Minute, nil)
+	r := chi.NewRouter()
+	r.Route("/flags", NewHandler(NewClient(p, nil), p, storememory.NewStore()).Routes)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/flags/communicationsSection", `{"variants":{"on":true,"off":false},"defaultVariant":"on"}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"state":"ENABLED"`) {
+		t.Fatalf("unexpected set %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPatch, "/flags/communicationsSection", `{"state":"DISABLED"}`); rec.Code != http.StatusOK {
+		t.Fatalf("unexpected flip %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, "/flags/communicationsSection", ""); !strings.Contains(rec.Body.String(), `"reason":"DISABLED"`) {
+		t.Fatalf("expected the flag disabled, got %s", rec.Body)
+	}
+	if rec := do(http.MethodGet, "/flags", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"provider":"runtime"`) || !strings.Contains(rec.Body.String(), `"key":"communicationsSection"`) {
+		t.Fatalf("unexpected listing %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPut, "/flags/broken", `{"variants":{"on":true},"defaultVariant":"off"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid definition refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/flags/communicationsSection", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an empty flip refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/flags/communicationsSection", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected delete %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/flags/communicationsSection", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected a second delete not found, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler lets admins check how flags evaluate for a technician and
+// change them at runtime.
+type Handler struct {
+	client      *Client
+	runtime     *RuntimeProvider
+	technicians repository.TechnicianRepository
+}
+
+// NewHandler creates a flags handler.
+func NewHandler(client *Client, runtime *RuntimeProvider, technicians repository.TechnicianRepository) *Handler {
+	return &Handler{client: client, runtime: runtime, technicians: technicians}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.GetProvider)
+	r.Get("/{key}", h.EvaluateFlag)
+	r.Put("/{key}", h.SetFlag)
+	r.Patch("/{key}", h.FlipFlag)
+	r.Delete("/{key}", h.DeleteFlag)
+}
+
+// GetProvider names the configured provider and lists the flags defined.
+func (h *Handler) GetProvider(w http.ResponseWriter, r *http.Request) {
+	defs, err := h.runtime.Definitions()
+	if err != nil {
+		h.fail(w, r, "failed to list flags", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"provider": h.client.ProviderName(), "flags": defs})
+}
+
+// EvaluateFlag evaluates a flag for the technician named by technicianId,
+// with the deviceModel, appVersion, locale and screenId query parameters
+// as screens pass them, and without a technician for an anonymous context.
+func (h *Handler) EvaluateFlag(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var tech domain.Technician
+	if id := q.Get("technicianId"); id != "" {
+		var err error
+		if tech, err = h.technicians.GetByID(id); err != nil {
+			respond.Error(w, http.StatusNotFound, "unknown technician", "technicianId does not name a known technician")
+			return
+		}
+	}
+	attributes := map[string]string{}
+	for _, name := range []string{"deviceModel", "appVersion", "locale", "screenId"} {
+		if v := q.Get(name); v != "" {
+			attributes[name] = v
+		}
+	}
+	respond.JSON(w, http.StatusOK, h.client.Evaluate(r.Context(), chi.URLParam(r, "key"), TechnicianContext(tech, attributes)))
+}
+
+// SetFlag defines a flag at runtime from a flag definition.
+func (h *Handler) SetFlag(w http.ResponseWriter, r *http.Request) {
+	var payload Flag
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	f, err := h.runtime.Set(chi.URLParam(r, "key"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to set flag", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+// FlipFlag changes a flag's state or default variant, as in
+// {"state":"DISABLED"} or {"defaultVariant":"on"}.
+func (h *Handler) FlipFlag(w http.ResponseWriter, r *http.Request) {
+	var payload Flip
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	f, err := h.runtime.Flip(chi.URLParam(r, "key"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to flip flag", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+// DeleteFlag removes a flag's runtime definition.
+func (h *Handler) DeleteFlag(w http.ResponseWriter, r *http.Request) {
+	if err := h.runtime.Delete(chi.URLParam(r, "key")); err != nil {
+		h.fail(w, r, "failed to delete flag", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidFlags):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,165 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// maxCacheEntries bounds the OFREP cache; past it, expired evaluations
+// are dropped, and everything when none have expired.
+const maxCacheEntries = 10000
+
+// OFREPProvider evaluates flags with a flag service over the OpenFeature
+// Remote Evaluation Protocol, one flag per request. Evaluations, including
+// flags the service does not know, are cached for CacheTTL per flag and
+// context; failed requests are not.
+type OFREPProvider struct {
+	typed
+	// URL is the service's base URL, without /ofrep/v1.
+	URL         string
+	TokenSecret string // secret name of the bearer token; empty sends none
+	Secrets     secret.Provider
+	Client      *http.Client
+	CacheTTL    time.Duration
+
+	now   func() time.Time
+	mu    sync.Mutex
+	cache map[string]cachedEvaluation
+}
+
+type cachedEvaluation struct {
+	value     any
+	detail    ProviderResolutionDetail
+	expiresAt time.Time
+}
+
+// NewOFREPProvider creates a provider calling the service at baseURL.
+func NewOFREPProvider(baseURL, tokenSecret string, secrets secret.Provider, client *http.Client, cacheTTL time.Duration) *OFREPProvider {
+	p := &OFREPProvider{URL: baseURL, TokenSecret: tokenSecret, Secrets: secrets, Client: client, CacheTTL: cacheTTL, now: time.Now, cache: make(map[string]cachedEvaluation)}
+	p.typed = typed{r: p}
+	return p
+}
+
+func (p *OFREPProvider) Metadata() Metadata { return Metadata{Name: "ofrep"} }
+
+// ofrepResponse is a successful evaluation or, with ErrorCode, a failed
+// one.
+type ofrepResponse struct {
+	Key          string       `json:"key"`
+	Value        any          `json:"value"`
+	Reason       Reason       `json:"reason"`
+	Variant      string       `json:"variant"`
+	Metadata     FlagMetadata `json:"metadata"`
+	ErrorCode    ErrorCode    `json:"errorCode"`
+	ErrorDetails string       `json:"errorDetails"`
+}
+
+func (p *OFREPProvider) resolve(ctx context.Context, key string, evalCtx FlattenedContext) (any, ProviderResolutionDetail) {
+	body, err := json.Marshal(map[string]any{"context": evalCtx})
+	if err != nil {
+		return nil, failed(InvalidContextCode, err.Error())
+	}
+	// Marshaled maps have sorted keys, so equal contexts share an entry.
+	cacheKey := key + "\x00" + string(body)
+	if v, d, ok := p.cached(cacheKey); ok {
+		return v, d
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+"/ofrep/v1/evaluate/flags/"+url.PathEscape(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, failed(GeneralCode, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.TokenSecret != "" {
+		token, err := p.Secrets.Get(p.TokenSecret)
+		if err != nil {
+			return nil, failed(ProviderNotReadyCode, fmt.Sprintf("flag service token: %v", err))
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, failed(GeneralCode, err.Error())
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, failed(GeneralCode, err.Error())
+	}
+
+	var out ofrepResponse
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusBadRequest, http.StatusNotFound:
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, failed(ParseErrorCode, fmt.Sprintf("flag service response: %v", err))
+		}
+	default:
+		return nil, failed(GeneralCode, fmt.Sprintf("flag service returned %s", resp.Status))
+	}
+	if resp.StatusCode == http.StatusNotFound && out.ErrorCode == "" {
+		out.ErrorCode = FlagNotFoundCode
+	}
+	if out.ErrorCode != "" {
+		d := failed(out.ErrorCode, out.ErrorDetails)
+		if out.ErrorCode == FlagNotFoundCode {
+			p.store(cacheKey, nil, d)
+		}
+		return nil, d
+	}
+	if out.Reason == "" {
+		out.Reason = UnknownReason
+	}
+	d := ProviderResolutionDetail{Reason: out.Reason, Variant: out.Variant, FlagMetadata: out.Metadata}
+	p.store(cacheKey, out.Value, d)
+	return out.Value, d
+}
+
+func (p *OFREPProvider) cached(key string) (any, ProviderResolutionDetail, bool) {
+	if p.CacheTTL <= 0 {
+		return nil, ProviderResolutionDetail{}, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.cache[key]
+	if !ok || !p.now().Before(entry.expiresAt) {
+		return nil, ProviderResolutionDetail{}, false
+	}
+	d := entry.detail
+	if d.ResolutionError.Code == "" {
+		d.Reason = CachedReason
+	}
+	return entry.value, d, true
+}
+
+func (p *OFREPProvider) store(key string, value any, d ProviderResolutionDetail) {
+	if p.CacheTTL <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if len(p.cache) >= maxCacheEntries {
+		for k, e := range p.cache {
+			if !now.Before(e.expiresAt) {
+				delete(p.cache, k)
+			}
+		}
+		if len(p.cache) >= maxCacheEntries {
+			p.cache = make(map[string]cachedEvaluation)
+		}
+	}
+	p.cache[key] = cachedEvaluation{value: value, detail: d, expiresAt: now.Add(p.CacheTTL)}
+}
+
+func failed(code ErrorCode, message string) ProviderResolutionDetail {
+	return ProviderResolutionDetail{Reason: ErrorReason, ResolutionError: ResolutionError{Code: code, Message: message}}
+}
@@ -0,0 +1,203 @@
+// Package flags evaluates feature flags through OpenFeature-compatible
+// providers, so a tenant already managing flags in a flag service targets
+// screens and toggles server behavior from it instead of from a second
+// system. Provider mirrors the evaluation methods of the OpenFeature Go
+// SDK's FeatureProvider; flag services are reached over the OpenFeature
+// Remote Evaluation Protocol (OFREP), and file and env providers serve
+// flags defined next to the deployment. Flags set through the admin API
+// are layered over all of them.
+package flags
+
+import (
+	"context"
+	"fmt"
+)
+
+// TargetingKey is the evaluation context attribute identifying the
+// subject of an evaluation, here the technician.
+const TargetingKey = "targetingKey"
+
+// FlattenedContext is an evaluation context: the targeting key and the
+// attributes rules may match on.
+type FlattenedContext map[string]any
+
+// FlagMetadata is provider-specific detail about a flag.
+type FlagMetadata map[string]any
+
+// Reason explains a resolved value, as defined by the OpenFeature spec.
+type Reason string
+
+// Resolution reasons.
+const (
+	StaticReason         Reason = "STATIC"
+	DefaultReason        Reason = "DEFAULT"
+	TargetingMatchReason Reason = "TARGETING_MATCH"
+	SplitReason          Reason = "SPLIT"
+	CachedReason         Reason = "CACHED"
+	DisabledReason       Reason = "DISABLED"
+	UnknownReason        Reason = "UNKNOWN"
+	ErrorReason          Reason = "ERROR"
+)
+
+// ErrorCode classifies a failed resolution, as defined by the OpenFeature
+// spec.
+type ErrorCode string
+
+// Resolution error codes.
+const (
+	ProviderNotReadyCode    ErrorCode = "PROVIDER_NOT_READY"
+	FlagNotFoundCode        ErrorCode = "FLAG_NOT_FOUND"
+	ParseErrorCode          ErrorCode = "PARSE_ERROR"
+	TypeMismatchCode        ErrorCode = "TYPE_MISMATCH"
+	TargetingKeyMissingCode ErrorCode = "TARGETING_KEY_MISSING"
+	InvalidContextCode      ErrorCode = "INVALID_CONTEXT"
+	GeneralCode             ErrorCode = "GENERAL"
+)
+
+// ResolutionError is why a flag could not be resolved; the zero value is
+// no error.
+type ResolutionError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e ResolutionError) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// ProviderResolutionDetail is what a provider reports with every value.
+type ProviderResolutionDetail struct {
+	ResolutionError ResolutionError
+	Reason          Reason
+	Variant         string
+	FlagMetadata    FlagMetadata
+}
+
+// Error returns the resolution error, or nil when the flag resolved.
+func (d ProviderResolutionDetail) Error() error {
+	if d.ResolutionError.Code == "" {
+		return nil
+	}
+	return d.ResolutionError
+}
+
+// BoolResolutionDetail is a resolved boolean flag.
+type BoolResolutionDetail struct {
+	Value bool
+	ProviderResolutionDetail
+}
+
+// StringResolutionDetail is a resolved string flag.
+type StringResolutionDetail struct {
+	Value string
+	ProviderResolutionDetail
+}
+
+// FloatResolutionDetail is a resolved number flag.
+type FloatResolutionDetail struct {
+	Value float64
+	ProviderResolutionDetail
+}
+
+// IntResolutionDetail is a resolved integer flag.
+type IntResolutionDetail struct {
+	Value int64
+	ProviderResolutionDetail
+}
+
+// InterfaceResolutionDetail is a flag resolved to any JSON value.
+type InterfaceResolutionDetail struct {
+	Value any
+	ProviderResolutionDetail
+}
+
+// Metadata describes a provider.
+type Metadata struct {
+	Name string
+}
+
+// Provider resolves flags. Each method returns defaultValue, with the
+// reason and error, when the flag cannot be resolved to its type. A
+// provider written for the OpenFeature Go SDK is adapted by converting
+// between its types and these.
+type Provider interface {
+	Metadata() Metadata
+	BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail
+	StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail
+	FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail
+	IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail
+	ObjectEvaluation(ctx context.Context, flag string, defaultValue any, evalCtx FlattenedContext) InterfaceResolutionDetail
+}
+
+// resolver resolves a flag to its raw JSON value; typed turns it into a
+// Provider.
+type resolver interface {
+	resolve(ctx context.Context, flag string, evalCtx FlattenedContext) (any, ProviderResolutionDetail)
+}
+
+// typed implements the typed evaluations of a Provider over a resolver.
+type typed struct {
+	r resolver
+}
+
+func (t typed) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail {
+	v, d := t.r.resolve(ctx, flag, evalCtx)
+	b, ok := v.(bool)
+	if d, ok = checkType(d, ok); !ok {
+		return BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: d}
+	}
+	return BoolResolutionDetail{Value: b, ProviderResolutionDetail: d}
+}
+
+func (t typed) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail {
+	v, d := t.r.resolve(ctx, flag, evalCtx)
+	s, ok := v.(string)
+	if d, ok = checkType(d, ok); !ok {
+		return StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: d}
+	}
+	return StringResolutionDetail{Value: s, ProviderResolutionDetail: d}
+}
+
+func (t typed) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail {
+	v, d := t.r.resolve(ctx, flag, evalCtx)
+	f, ok := v.(float64)
+	if d, ok = checkType(d, ok); !ok {
+		return FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: d}
+	}
+	return FloatResolutionDetail{Value: f, ProviderResolutionDetail: d}
+}
+
+func (t typed) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail {
+	v, d := t.r.resolve(ctx, flag, evalCtx)
+	// JSON numbers decode as float64; only whole ones are integers.
+	f, ok := v.(float64)
+	ok = ok && f == float64(int64(f))
+	if d, ok = checkType(d, ok); !ok {
+		return IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: d}
+	}
+	return IntResolutionDetail{Value: int64(f), ProviderResolutionDetail: d}
+}
+
+func (t typed) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, evalCtx FlattenedContext) InterfaceResolutionDetail {
+	v, d := t.r.resolve(ctx, flag, evalCtx)
+	if d.ResolutionError.Code != "" || v == nil {
+		return InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: d}
+	}
+	return InterfaceResolutionDetail{Value: v, ProviderResolutionDetail: d}
+}
+
+// checkType turns a resolved value of the wrong type into a type mismatch.
+func checkType(d ProviderResolutionDetail, ok bool) (ProviderResolutionDetail, bool) {
+	switch {
+	case d.ResolutionError.Code != "":
+		return d, false
+	case d.Reason == DisabledReason:
+		return d, false
+	case !ok:
+		return ProviderResolutionDetail{Reason: ErrorReason, ResolutionError: ResolutionError{Code: TypeMismatchCode, Message: "flag value has another type"}}, false
+	}
+	return d, true
+}
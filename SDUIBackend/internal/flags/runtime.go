@@ -0,0 +1,278 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"log/slog"
+)
+
+// ErrNotFound is returned when a flag has no runtime definition.
+var ErrNotFound = errors.New("flag not found")
+
+// StoredFlag is a flag defined at runtime through the admin API.
+type StoredFlag struct {
+	Key string `json:"key"`
+	Flag
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store persists runtime flag definitions.
+type Store interface {
+	SaveFlag(f StoredFlag) error
+	GetFlag(key string) (StoredFlag, error)
+	ListFlags() ([]StoredFlag, error)
+	DeleteFlag(key string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	flags map[string]StoredFlag
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{flags: make(map[string]StoredFlag)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveFlag(f StoredFlag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flags[f.Key] = f
+	return nil
+}
+
+func (m *MemoryStore) GetFlag(key string) (StoredFlag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.flags[key]
+	if !ok {
+		return StoredFlag{}, ErrNotFound
+	}
+	return f, nil
+}
+
+func (m *MemoryStore) ListFlags() ([]StoredFlag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]StoredFlag, 0, len(m.flags))
+	for _, f := range m.flags {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteFlag(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.flags[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.flags, key)
+	return nil
+}
+
+// Definition is a flag as the admin API lists it, with where it is
+// defined: "runtime" for flags set through the API, otherwise the
+// configured provider.
+type Definition struct {
+	Key string `json:"key"`
+	Flag
+	Source    string     `json:"source"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// definer is a provider whose definitions can be listed and copied.
+type definer interface {
+	Flags() map[string]Flag
+}
+
+// RuntimeProvider layers flags defined at runtime over the configured
+// provider, which may be nil. Runtime definitions are read from the store
+// at most once per ttl, and again after every change made through this
+// provider, so other instances see a flip within ttl.
+type RuntimeProvider struct {
+	typed
+	base   Provider
+	store  Store
+	ttl    time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	served   *FileProvider
+	stored   []StoredFlag
+	loadedAt time.Time
+}
+
+// NewRuntimeProvider serves the flags of store over base.
+func NewRuntimeProvider(base Provider, store Store, ttl time.Duration, logger *slog.Logger) *RuntimeProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	p := &RuntimeProvider{base: base, store: store, ttl: ttl, logger: logger, now: time.Now}
+	p.typed = typed{r: p}
+	return p
+}
+
+// Metadata names the configured provider, or "runtime" without one.
+func (p *RuntimeProvider) Metadata() Metadata {
+	if p.base == nil {
+		return Metadata{Name: "runtime"}
+	}
+	return p.base.Metadata()
+}
+
+func (p *RuntimeProvider) resolve(ctx context.Context, key string, evalCtx FlattenedContext) (any, ProviderResolutionDetail) {
+	if served := p.snapshot(); served != nil {
+		if _, ok := served.flags[key]; ok {
+			return served.resolve(ctx, key, evalCtx)
+		}
+	}
+	if p.base == nil {
+		return nil, failed(FlagNotFoundCode, key)
+	}
+	d := p.base.ObjectEvaluation(ctx, key, nil, evalCtx)
+	return d.Value, d.ProviderResolutionDetail
+}
+
+// snapshot returns the runtime flags, reloading them when they are older
+// than ttl. A failed reload keeps serving the flags last loaded.
+func (p *RuntimeProvider) snapshot() *FileProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.served != nil && p.now().Sub(p.loadedAt) < p.ttl {
+		return p.served
+	}
+	if err := p.load(); err != nil {
+		p.logger.Warn("runtime flags unavailable", slog.Any("error", err))
+	}
+	return p.served
+}
+
+// load reads the runtime flags; p.mu must be held.
+func (p *RuntimeProvider) load() error {
+	stored, err := p.store.ListFlags()
+	if err != nil {
+		return err
+	}
+	defs := make(map[string]Flag, len(stored))
+	for _, f := range stored {
+		defs[f.Key] = f.Flag
+	}
+	served, err := NewStaticProvider(defs)
+	if err != nil {
+		return err
+	}
+	p.served, p.stored, p.loadedAt = served, stored, p.now()
+	return nil
+}
+
+// Definitions lists the runtime flags and those of the configured
+// provider they do not override, by key. Flags of a flag service cannot
+// be listed and are left out.
+func (p *RuntimeProvider) Definitions() ([]Definition, error) {
+	stored, err := p.store.ListFlags()
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]Definition, 0, len(stored))
+	seen := make(map[string]bool, len(stored))
+	for _, f := range stored {
+		updated := f.UpdatedAt
+		defs = append(defs, Definition{Key: f.Key, Flag: f.Flag, Source: "runtime", UpdatedAt: &updated})
+		seen[f.Key] = true
+	}
+	if base, ok := p.base.(definer); ok {
+		for key, f := range base.Flags() {
+			if !seen[key] {
+				defs = append(defs, Definition{Key: key, Flag: f, Source: p.base.Metadata().Name})
+			}
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs, nil
+}
+
+// Set defines a flag at runtime, replacing any earlier definition and
+// overriding the configured provider's.
+func (p *RuntimeProvider) Set(key string, f Flag) (StoredFlag, error) {
+	f, err := checkFlag(key, f)
+	if err != nil {
+		return StoredFlag{}, err
+	}
+	stored := StoredFlag{Key: key, Flag: f, UpdatedAt: p.now().UTC()}
+	if err := p.store.SaveFlag(stored); err != nil {
+		return StoredFlag{}, err
+	}
+	p.changed()
+	p.logger.Info("flag set", slog.String("flag", key), slog.String("state", f.State), slog.String("defaultVariant", f.DefaultVariant))
+	return stored, nil
+}
+
+// Flip is a change to a flag's state or default variant.
+type Flip struct {
+	State          string `json:"state,omitempty"`
+	DefaultVariant string `json:"defaultVariant,omitempty"`
+}
+
+// Flip changes a flag's state or default variant. A flag defined only by
+// the configured provider is copied to a runtime definition first; one
+// served by a flag service has to be flipped there.
+func (p *RuntimeProvider) Flip(key string, flip Flip) (StoredFlag, error) {
+	if flip.State == "" && flip.DefaultVariant == "" {
+		return StoredFlag{}, fmt.Errorf("%w: %s: state or defaultVariant is required", ErrInvalidFlags, key)
+	}
+	stored, err := p.store.GetFlag(key)
+	if errors.Is(err, ErrNotFound) {
+		base, ok := p.base.(definer)
+		if !ok {
+			return StoredFlag{}, err
+		}
+		f, defined := base.Flags()[key]
+		if !defined {
+			return StoredFlag{}, err
+		}
+		stored, err = StoredFlag{Key: key, Flag: f}, nil
+	}
+	if err != nil {
+		return StoredFlag{}, err
+	}
+	if flip.State != "" {
+		stored.State = flip.State
+	}
+	if flip.DefaultVariant != "" {
+		stored.DefaultVariant = flip.DefaultVariant
+	}
+	return p.Set(key, stored.Flag)
+}
+
+// Delete removes a flag's runtime definition, so the configured provider
+// serves it again.
+func (p *RuntimeProvider) Delete(key string) error {
+	if err := p.store.DeleteFlag(key); err != nil {
+		return err
+	}
+	p.changed()
+	p.logger.Info("flag deleted", slog.String("flag", key))
+	return nil
+}
+
+// changed reloads the runtime flags after a change, so this instance
+// serves it immediately.
+func (p *RuntimeProvider) changed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.load(); err != nil {
+		p.logger.Warn("runtime flags unavailable", slog.Any("error", err))
+		p.served = nil
+	}
+}
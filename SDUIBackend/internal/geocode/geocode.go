@@ -0,0 +1,168 @@
+// Package geocode resolves street addresses to coordinates. The worker uses
+// it to enrich uploaded jobs and imported route stops, whose addresses
+// arrive as plain strings, so devices can map and geofence them.
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Public endpoints of the supported providers.
+const (
+	GoogleURL    = "https://maps.googleapis.com/maps/api/geocode/json"
+	NominatimURL = "https://nominatim.openstreetmap.org/search"
+)
+
+// ErrNoMatch is returned when the provider cannot place an address. It is
+// final: asking again will not help.
+var ErrNoMatch = errors.New("address not found")
+
+// Geocoder resolves an address to coordinates.
+type Geocoder interface {
+	Name() string
+	Geocode(ctx context.Context, address string) (models.Coordinate, error)
+}
+
+// NewGeocoder returns the geocoder selected by cfg, or nil when geocoding
+// is disabled.
+func NewGeocoder(cfg config.GeocodingConfig, secrets secret.Provider) Geocoder {
+	client := &http.Client{Timeout: cfg.RequestTimeout}
+	switch cfg.Provider {
+	case "google":
+		u := cfg.URL
+		if u == "" {
+			u = GoogleURL
+		}
+		return Google{URL: u, KeySecret: cfg.KeySecret, Secrets: secrets, Client: client}
+	case "nominatim":
+		u := cfg.URL
+		if u == "" {
+			u = NominatimURL
+		}
+		return Nominatim{URL: u, Client: client}
+	default:
+		return nil
+	}
+}
+
+// Google calls the Google Maps Geocoding API.
+type Google struct {
+	URL       string
+	KeySecret string // secret name of the API key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (Google) Name() string { return "google" }
+
+func (g Google) Geocode(ctx context.Context, address string) (models.Coordinate, error) {
+	key, err := g.Secrets.Get(g.KeySecret)
+	if err != nil {
+		return models.Coordinate{}, fmt.Errorf("geocoding key: %w", err)
+	}
+	q := url.Values{}
+	q.Set("address", address)
+	q.Set("key", key)
+	var body struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Results      []struct {
+			Geometry struct {
+				Location struct {
+					Lat float64 `json:"lat"`
+					Lng float64 `json:"lng"`
+				} `json:"location"`
+			} `json:"geometry"`
+		} `json:"results"`
+	}
+	if err := get(ctx, g.Client, g.URL+"?"+q.Encode(), &body); err != nil {
+		return models.Coordinate{}, err
+	}
+	switch body.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return models.Coordinate{}, ErrNoMatch
+	default:
+		return models.Coordinate{}, fmt.Errorf("geocoding status %s: %s", body.Status, body.ErrorMessage)
+	}
+	if len(body.Results) == 0 {
+		return models.Coordinate{}, ErrNoMatch
+	}
+	loc := body.Results[0].Geometry.Location
+	return models.Coordinate{Latitude: loc.Lat, Longitude: loc.Lng}, nil
+}
+
+// Nominatim calls an OpenStreetMap Nominatim server. The public one allows
+// a request a second; larger fleets should run their own.
+type Nominatim struct {
+	URL    string
+	Client *http.Client
+}
+
+func (Nominatim) Name() string { return "nominatim" }
+
+func (n Nominatim) Geocode(ctx context.Context, address string) (models.Coordinate, error) {
+	q := url.Values{}
+	q.Set("q", address)
+	q.Set("format", "jsonv2")
+	q.Set("limit", "1")
+	var body []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := get(ctx, n.Client, n.URL+"?"+q.Encode(), &body); err != nil {
+		return models.Coordinate{}, err
+	}
+	if len(body) == 0 {
+		return models.Coordinate{}, ErrNoMatch
+	}
+	lat, err := strconv.ParseFloat(body[0].Lat, 64)
+	if err != nil {
+		return models.Coordinate{}, fmt.Errorf("decode geocoding latitude: %w", err)
+	}
+	lng, err := strconv.ParseFloat(body[0].Lon, 64)
+	if err != nil {
+		return models.Coordinate{}, fmt.Errorf("decode geocoding longitude: %w", err)
+	}
+	return models.Coordinate{Latitude: lat, Longitude: lng}, nil
+}
+
+func get(ctx context.Context, client *http.Client, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	// Nominatim's usage policy requires an identifying user agent.
+	req.Header.Set("User-Agent", "pestgenie-sdui")
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("geocoder responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode geocoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	v, ok := s[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+func TestGoogle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "k" {
+			t.Errorf("expected the API key sent, got %q", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("address") {
+		case "100 Main St":
+			_, _ = w.Write([]byte(`{"status":"OK","results":[{"geometry":{"location":{"lat":30.2682,"lng":-97.7429}}}]}`))
+		case "Nowhere":
+			_, _ = w.Write([]byte(`{"status":"ZERO_RESULTS","results":[]}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"OVER_QUERY_LIMIT","error_message":"slow down"}`))
+		}
+	}))
+	defer srv.Close()
+	g := NewGeocoder(config.GeocodingConfig{Provider: "google", URL: srv.URL, KeySecret: "maps"}, staticSecrets{"maps": "k"})
+	ctx := context.Background()
+
+	c, err := g.Geocode(ctx, "100 Main St")
+	if err != nil || c.Latitude != 30.2682 || c.Longitude != -97.7429 {
+		t.Fatalf("unexpected coordinate %+v (%v)", c, err)
+	}
+	if _, err := g.Geocode(ctx, "Nowhere"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected no match, got %v", err)
+	}
+	if _, err := g.Geocode(ctx, "busy"); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+}
+
+func TestNominatim(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("expected an identifying user agent")
+		}
+		switch r.URL.Query().Get("q") {
+		case "100 Main St":
+			_, _ = w.Write([]byte(`[{"lat":"30.2682","lon":"-97.7429"}]`))
+		case "Nowhere":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+	g := NewGeocoder(config.GeocodingConfig{Provider: "nominatim", URL: srv.URL}, nil)
+	ctx := context.Background()
+
+	c, err := g.Geocode(ctx, "100 Main St")
+	if err != nil || c.Latitude != 30.2682 || c.Longitude != -97.7429 {
+		t.Fatalf("unexpected coordinate %+v (%v)", c, err)
+	}
+	if _, err := g.Geocode(ctx, "Nowhere"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected no match, got %v", err)
+	}
+	if _, err := g.Geocode(ctx, "busy"); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+	if NewGeocoder(config.GeocodingConfig{Provider: "none"}, nil) != nil {
+		t.Error("expected no geocoder when disabled")
+	}
+}
@@ -0,0 +1,67 @@
+package respond
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var problemPage = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if .One}}{{.One.Title}} - {{end}}PestGenie API problem types</title>
+<style>body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;padding:0 1rem;line-height:1.5}code{background:#f3f3f3;padding:0 .25rem}section{border-top:1px solid #ddd;padding-top:1rem}</style>
+</head>
+<body>
+{{if .One}}<p><a href="{{.Index}}">All problem types</a></p>{{else}}<h1>PestGenie API problem types</h1>
+<p>Error responses are <a href="https://www.rfc-editor.org/rfc/rfc9457">problem details</a>. Branch on <code>type</code>; <code>title</code> and <code>detail</code> follow Accept-Language and may change. <code>retryable</code> says whether resending the same request can succeed, after <code>retryAfter</code> seconds when set, and uploads name the record they carried in <code>entityId</code>.</p>{{end}}
+{{range .Problems}}<section id="{{.Slug}}">
+<h2>{{.Title}}</h2>
+<p><code>type: {{.Type}}</code> &middot; <code>status: {{.Status}}</code>{{if .Retryable}} &middot; retryable{{end}}</p>
+<p>{{.Description}}</p>
+<p><strong>What to do:</strong> {{.Remediation}}</p>
+</section>
+{{end}}</body>
+</html>
+`))
+
+// ProblemIndex documents every problem type, as HTML or, when the client
+// accepts it, JSON.
+func ProblemIndex(w http.ResponseWriter, r *http.Request) {
+	list := Problems()
+	if wantsJSON(r) {
+		JSON(w, http.StatusOK, map[string]any{"problems": list})
+		return
+	}
+	renderProblems(w, list, false)
+}
+
+// ProblemDoc documents the problem type at ProblemsPath/<slug>.
+func ProblemDoc(w http.ResponseWriter, r *http.Request) {
+	p, ok := LookupProblem(strings.TrimPrefix(r.URL.Path, ProblemsPath+"/"))
+	if !ok {
+		Error(w, http.StatusNotFound, "problem type not found", "see "+ProblemsPath+" for every problem type")
+		return
+	}
+	if wantsJSON(r) {
+		JSON(w, http.StatusOK, p)
+		return
+	}
+	renderProblems(w, []Problem{p}, true)
+}
+
+func renderProblems(w http.ResponseWriter, list []Problem, one bool) {
+	data := map[string]any{"Problems": list, "Index": ProblemsPath}
+	if one {
+		data["One"] = list[0]
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = problemPage.Execute(w, data)
+}
+
+// wantsJSON reports whether the client asked for JSON rather than a page.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,140 @@
+package respond
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language problems are written in by handlers.
+const DefaultLanguage = "en"
+
+// messages holds a catalog per language, named <language>.json.
+//
+//go:embed messages/*.json
+var messages embed.FS
+
+// catalog translates problems into one language. Titles are keyed by their
+// English slug (see titleKey); details are fixed English strings keyed by
+// themselves. Details built from errors, such as validation failures, are
+// not in a catalog and stay in English.
+type catalog struct {
+	Titles  map[string]string `json:"titles"`
+	Details map[string]string `json:"details"`
+}
+
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]catalog {
+	files, err := messages.ReadDir("messages")
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]catalog, len(files))
+	for _, f := range files {
+		raw, err := messages.ReadFile(path.Join("messages", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var c catalog
+		if err := json.Unmarshal(raw, &c); err != nil {
+			panic(fmt.Sprintf("message catalog %s: %v", f.Name(), err))
+		}
+		out[strings.TrimSuffix(f.Name(), ".json")] = c
+	}
+	return out
+}
+
+// Languages lists the languages problems can be returned in.
+func Languages() []string {
+	out := []string{DefaultLanguage}
+	for lang := range catalogs {
+		out = append(out, lang)
+	}
+	sort.Strings(out[1:])
+	return out
+}
+
+// Localize negotiates a language from the request's Accept-Language header
+// and has Error write problems in it. Untranslated titles and details fall
+// back to English.
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(&languageWriter{ResponseWriter: w, lang: Negotiate(r.Header.Get("Accept-Language"))}, r)
+	})
+}
+
+// Negotiate picks the supported language the Accept-Language header value
+// prefers most, matching on the primary subtag so es-MX selects es. It
+// returns DefaultLanguage when nothing supported is acceptable.
+func Negotiate(header string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if lang == "*" {
+			lang = DefaultLanguage
+		}
+		if _, ok := catalogs[lang]; !ok && lang != DefaultLanguage {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// languageWriter carries the negotiated language from Localize to Error.
+type languageWriter struct {
+	http.ResponseWriter
+	lang string
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (lw *languageWriter) Unwrap() http.ResponseWriter { return lw.ResponseWriter }
+
+// languageOf finds the language Localize negotiated for w, looking through
+// writers that wrap it, or "" outside Localize.
+func languageOf(w http.ResponseWriter) string {
+	for w != nil {
+		if lw, ok := w.(*languageWriter); ok {
+			return lw.lang
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return ""
+		}
+		w = u.Unwrap()
+	}
+	return ""
+}
+
+// translate returns title and detail in lang where its catalog has them.
+func translate(lang, title, detail string) (string, string) {
+	c, ok := catalogs[lang]
+	if !ok {
+		return title, detail
+	}
+	if t, ok := c.Titles[titleKey(title)]; ok {
+		title = t
+	}
+	if d, ok := c.Details[detail]; ok {
+		detail = d
+	}
+	return title, detail
+}
@@ -0,0 +1,61 @@
+package respond
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ItemResult is the outcome of one item of a batch request. Status is the
+// status the item would have had as a request of its own; a failed item
+// carries its Problem, a successful one its Result.
+type ItemResult struct {
+	Index    int             `json:"index"` // position in the request's list of Kind
+	Kind     string          `json:"kind,omitempty"`
+	EntityID string          `json:"entityId,omitempty"`
+	Status   int             `json:"status"`
+	Result   any             `json:"result,omitempty"`
+	Problem  *ProblemDetails `json:"problem,omitempty"`
+}
+
+// Failed reports whether the item failed.
+func (i ItemResult) Failed() bool {
+	return i.Status >= http.StatusBadRequest
+}
+
+// MultiStatusBody is the body of a batch response.
+//
+// Items are applied independently, and are idempotent by EntityID: to
+// recover from partial failure, resend only the failed items whose problem
+// is retryable (after its retryAfter), and surface the rest to the user.
+type MultiStatusBody struct {
+	Results   []ItemResult `json:"results"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	// Retryable counts failed items that can succeed if resent unchanged.
+	Retryable int `json:"retryable"`
+}
+
+// MultiStatus writes results as a 207 Multi-Status response, whether or not
+// any item failed. Retry-After is the longest wait any failed item asks for.
+func MultiStatus(w http.ResponseWriter, results []ItemResult) {
+	body := MultiStatusBody{Results: results}
+	if body.Results == nil {
+		body.Results = []ItemResult{}
+	}
+	retryAfter := 0
+	for _, item := range results {
+		if !item.Failed() {
+			body.Succeeded++
+			continue
+		}
+		body.Failed++
+		if item.Problem != nil && item.Problem.Retryable {
+			body.Retryable++
+			retryAfter = max(retryAfter, item.Problem.RetryAfter)
+		}
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	JSON(w, http.StatusMultiStatus, body)
+}
@@ -0,0 +1,230 @@
+package respond
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ProblemsPath is where problem types are documented; each type's URI is
+// ProblemsPath/<slug>, relative to the API host.
+const ProblemsPath = "/problems"
+
+// Problem is a class of error. Its Type URI is stable, so clients can branch
+// on it instead of on the (translated) title or detail.
+type Problem struct {
+	Slug        string `json:"slug"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Remediation string `json:"remediation"`
+	// Retryable reports whether resending the same request can succeed.
+	Retryable bool `json:"retryable"`
+}
+
+// problems is the registry of problem types, in documentation order.
+var problems = []Problem{
+	{
+		Slug:        "invalid-request",
+		Title:       "Invalid request",
+		Status:      http.StatusBadRequest,
+		Description: "The request body could not be decoded, or a path or query parameter is missing or malformed. The title names the payload or parameter.",
+		Remediation: "Send JSON matching the schema in the API reference, and format parameters as documented (dates as RFC 3339 or YYYY-MM-DD, counts as positive integers). Resending the same request will fail again.",
+	},
+	{
+		Slug:        "validation-failed",
+		Title:       "Validation failed",
+		Status:      http.StatusBadRequest,
+		Description: "The request was well-formed but breaks a rule of the resource, such as a required field, an unknown reference, or an out-of-range value. The detail lists every problem, separated by semicolons; when the body failed field checks, errors lists each field with its problem.",
+		Remediation: "Correct each problem listed in the detail and resend.",
+	},
+	{
+		Slug:        "missing-technician",
+		Title:       "Missing technician",
+		Status:      http.StatusBadRequest,
+		Description: "The endpoint acts for a technician, and the request named none.",
+		Remediation: "Authenticate with the technician's bearer token, or pass userId on deployments without authentication.",
+	},
+	{
+		Slug:        "lot-required",
+		Title:       "Lot required",
+		Status:      http.StatusBadRequest,
+		Description: "The chemical tracks lots, and the treatment did not name one on the technician's truck.",
+		Remediation: "Set lotNumber to a lot of the chemical the technician holds; sync chemicals first if the lot was received recently.",
+	},
+	{
+		Slug:        "calibration-overdue",
+		Title:       "Calibration overdue",
+		Status:      http.StatusBadRequest,
+		Description: "The application method requires calibrated equipment, and the equipment named was never calibrated or its calibration has lapsed, or no equipment was named.",
+		Remediation: "Calibrate the equipment and log it with POST /v1/equipment/{assetId}/calibrations, then resend the treatment with its equipmentId.",
+	},
+	{
+		Slug:        "authentication-required",
+		Title:       "Authentication required",
+		Status:      http.StatusUnauthorized,
+		Description: "The request carried no credentials, or its token is malformed, expired, or revoked.",
+		Remediation: "Sign in again or request a new token, then resend with it as a bearer token.",
+	},
+	{
+		Slug:        "forbidden",
+		Title:       "Forbidden",
+		Status:      http.StatusForbidden,
+		Description: "The caller is authenticated but not allowed to do this, for example a technician reading another technician's data, a missing role, or a write while impersonating.",
+		Remediation: "Use an account with the required role, or act only on the caller's own data. Resending will fail again.",
+	},
+	{
+		Slug:        "insufficient-scope",
+		Title:       "Insufficient scope",
+		Status:      http.StatusForbidden,
+		Description: "The API token was not granted the scope the endpoint requires. The detail names the scope.",
+		Remediation: "Ask an administrator for a token granted the scope in the detail.",
+	},
+	{
+		Slug:        "origin-not-allowed",
+		Title:       "Origin not allowed",
+		Status:      http.StatusForbidden,
+		Description: "A browser sent the request from a web origin the server does not allow cross-origin requests from. The detail names the origin.",
+		Remediation: "Call the API from an allowed origin, or ask an operator to add the origin to SERVER_ALLOWED_ORIGINS. Mobile apps send no origin and are never refused this way.",
+	},
+	{
+		Slug:        "access-restricted",
+		Title:       "Access restricted",
+		Status:      http.StatusForbidden,
+		Description: "An admin or sign-in request came from an address outside the allowlist or from a blocked country. The detail names the address or country; the attempt is recorded in the activity feed.",
+		Remediation: "Connect from an allowed network, such as the office or its VPN, or ask an administrator to allow the address with ACCESS_ALLOWED_IPS or the tenant's access policy.",
+	},
+	{
+		Slug:        "not-found",
+		Title:       "Not found",
+		Status:      http.StatusNotFound,
+		Description: "The resource in the path does not exist, or is not visible to the caller.",
+		Remediation: "Check the ID; the resource may have been deleted. Refresh local data with GET /v1/updates.",
+	},
+	{
+		Slug:        "conflict",
+		Title:       "Conflict",
+		Status:      http.StatusConflict,
+		Description: "The resource already exists or changed in a way that conflicts with the request.",
+		Remediation: "Fetch the current resource, reconcile, and resend.",
+	},
+	{
+		Slug:        "replayed-request",
+		Title:       "Replayed request",
+		Status:      http.StatusConflict,
+		Description: "The signed request's nonce was already used, or its timestamp is outside the validity window, so it may be a captured request sent again. The server did not act on it.",
+		Remediation: "Sign the write again with a new nonce and the current time, and check the device clock when timestamps are refused. Resending the same signed request will fail again.",
+	},
+	{
+		Slug:        "resync-required",
+		Title:       "Resync required",
+		Status:      http.StatusGone,
+		Description: "The sync watermark or cursor is older than the server keeps deletion tombstones, so deletions since then can no longer be reported.",
+		Remediation: "Discard the records synced from the server and call GET /v1/updates again without since or cursor.",
+	},
+	{
+		Slug:        "payload-too-large",
+		Title:       "Payload too large",
+		Status:      http.StatusRequestEntityTooLarge,
+		Description: "The request body is larger than the endpoint accepts.",
+		Remediation: "Compress or shorten the upload, such as a lower photo resolution or a shorter voice note.",
+	},
+	{
+		Slug:        "rate-limited",
+		Title:       "Rate limited",
+		Status:      http.StatusTooManyRequests,
+		Description: "The API token, technician or client exceeded its request rate. Reads and writes are limited separately.",
+		Remediation: "Wait the retryAfter seconds and resend, spreading requests out; retry storms only extend the wait.",
+		Retryable:   true,
+	},
+	{
+		Slug:        "temporary-failure",
+		Title:       "Temporary failure",
+		Status:      http.StatusInternalServerError,
+		Description: "The server failed while handling the request. The failure was logged under the request's correlation ID, which the response carries as correlationId and traceId.",
+		Remediation: "Resend with exponential backoff; uploads are idempotent by ID, so resending does not duplicate them. Quote the correlationId if reporting the failure.",
+		Retryable:   true,
+	},
+	{
+		Slug:        "service-unavailable",
+		Title:       "Service unavailable",
+		Status:      http.StatusServiceUnavailable,
+		Description: "The server, the feature requested or a service it depends on, such as the datastore, is not available right now. The code names the cause, such as datastore_unavailable.",
+		Remediation: "Resend later. Sandbox requests fail this way when the server does not enable sandboxes.",
+		Retryable:   true,
+	},
+}
+
+// problemsByTitle classifies titles whose class the status alone does not
+// give.
+var problemsByTitle = map[string]string{
+	"address not allowed": "access-restricted",
+	"calibration overdue": "calibration-overdue",
+	"country blocked":     "access-restricted",
+	"insufficient scope":  "insufficient-scope",
+	"invalid chemical":    "validation-failed",
+	"invalid device":      "validation-failed",
+	"invalid job":         "validation-failed",
+	"invalid recall":      "validation-failed",
+	"invalid treatment":   "validation-failed",
+	"lot required":        "lot-required",
+	"missing technician":  "missing-technician",
+	"origin not allowed":  "origin-not-allowed",
+	"request expired":     "replayed-request",
+	"request replayed":    "replayed-request",
+}
+
+// problemsByStatus is the class of every other title with a status.
+var problemsByStatus = map[int]string{
+	http.StatusUnauthorized:          "authentication-required",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not-found",
+	http.StatusConflict:              "conflict",
+	http.StatusGone:                  "resync-required",
+	http.StatusRequestEntityTooLarge: "payload-too-large",
+	http.StatusTooManyRequests:       "rate-limited",
+	http.StatusInternalServerError:   "temporary-failure",
+	http.StatusServiceUnavailable:    "service-unavailable",
+}
+
+// Problems returns the registered problem types.
+func Problems() []Problem {
+	out := make([]Problem, len(problems))
+	for i, p := range problems {
+		p.Type = ProblemsPath + "/" + p.Slug
+		out[i] = p
+	}
+	return out
+}
+
+// LookupProblem returns the problem type with slug.
+func LookupProblem(slug string) (Problem, bool) {
+	for _, p := range Problems() {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+	return Problem{}, false
+}
+
+// classify returns the registered type of a problem with status and title,
+// or an about:blank type when none covers it. Bad requests titled
+// "invalid <x>" or "missing <x>" are malformed requests; other bad requests
+// failed validation.
+func classify(status int, title string) Problem {
+	slug, ok := problemsByTitle[title]
+	if !ok && status == http.StatusBadRequest {
+		slug = "validation-failed"
+		if strings.HasPrefix(title, "invalid ") || strings.HasPrefix(title, "missing ") {
+			slug = "invalid-request"
+		}
+		ok = true
+	}
+	if !ok {
+		slug, ok = problemsByStatus[status]
+	}
+	if p, found := LookupProblem(slug); ok && found {
+		return p
+	}
+	return Problem{Type: "about:blank", Status: status}
+}
@@ -3,8 +3,12 @@ package respond
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/validate"
 )
 
 // JSON writes a payload as JSON with given status code.
@@ -17,27 +21,124 @@ func JSON(w http.ResponseWriter, status int, payload any) {
 	_ = json.NewEncoder(w).Encode(payload)
 }
 
-// Error writes RFC7807-style problem details response.
-func Error(w http.ResponseWriter, status int, title, detail string) {
+// Error writes RFC7807-style problem details response. The problem type is
+// the registered class of status and title (see Problems), and under Localize
+// the title and detail are translated into the request's language. A cause
+// given with WithCause that is an *apperr.Error replaces status and detail
+// with its own.
+func Error(w http.ResponseWriter, status int, title, detail string, opts ...Option) {
+	problem := NewProblem(w, status, title, detail, opts...)
+	if problem.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(problem.RetryAfter))
+	}
+	JSON(w, problem.Status, problem)
+}
+
+// NewProblem builds the problem Error would write to w, for responses that
+// carry problems in their body, such as MultiStatus items.
+func NewProblem(w http.ResponseWriter, status int, title, detail string, opts ...Option) ProblemDetails {
 	if status == 0 {
 		status = http.StatusInternalServerError
 	}
-
+	// The correlation ID is set on the response by middleware.Correlation,
+	// and request logs carry it, so a problem can be traced to its logs.
+	correlationID := w.Header().Get("X-Correlation-ID")
 	problem := ProblemDetails{
-		Type:    "about:blank",
-		Title:   title,
-		Status:  status,
-		Detail:  detail,
-		TraceID: uuid.NewString(),
+		Title:         title,
+		Status:        status,
+		Detail:        detail,
+		TraceID:       correlationID,
+		CorrelationID: correlationID,
+	}
+	for _, opt := range opts {
+		opt(&problem)
+	}
+	if e, ok := apperr.As(problem.cause); ok {
+		problem.Status, problem.Code = e.Kind.Status(), e.ErrorCode()
+		if e.Detail != "" {
+			problem.Detail = e.Detail
+		}
+		WithRetryAfter(e.RetryAfter)(&problem)
 	}
-	JSON(w, status, problem)
+	if kind, ok := apperr.KindForStatus(problem.Status); ok && problem.Code == "" {
+		problem.Code = kind.Code()
+	}
+
+	class := classify(problem.Status, problem.Title)
+	problem.Type, problem.Retryable = class.Type, class.Retryable
+	if lang := languageOf(w); lang != "" && lang != DefaultLanguage {
+		problem.Title, problem.Detail = translate(lang, problem.Title, problem.Detail)
+		w.Header().Set("Content-Language", lang)
+	}
+	return problem
 }
 
 // ProblemDetails represents a RFC7807 error payload.
 type ProblemDetails struct {
-	Type    string `json:"type"`
-	Title   string `json:"title"`
-	Status  int    `json:"status"`
-	Detail  string `json:"detail,omitempty"`
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code is a stable machine-readable identifier of the error, such as
+	// "datastore_unavailable"; see package apperr.
+	Code string `json:"code,omitempty"`
+	// TraceID is the request's correlation ID, which its logs carry.
 	TraceID string `json:"traceId,omitempty"`
+	// Retryable reports whether resending the same request can succeed,
+	// after RetryAfter seconds when that is set.
+	Retryable  bool `json:"retryable"`
+	RetryAfter int  `json:"retryAfter,omitempty"`
+	// CorrelationID echoes the request's X-Correlation-ID.
+	CorrelationID string `json:"correlationId,omitempty"`
+	// EntityID is the client ID of the record the request carried.
+	EntityID string `json:"entityId,omitempty"`
+	// Errors lists every invalid field of the request body.
+	Errors validate.Errors `json:"errors,omitempty"`
+
+	cause error
+}
+
+// Option adds to a problem written by Error.
+type Option func(*ProblemDetails)
+
+// WithEntityID names the client record the failed request carried.
+func WithEntityID(id string) Option {
+	return func(p *ProblemDetails) { p.EntityID = id }
+}
+
+// WithErrors lists the invalid fields carried by err, when it carries any.
+func WithErrors(err error) Option {
+	return func(p *ProblemDetails) { p.Errors = validate.FieldErrors(err) }
+}
+
+// WithCause gives the error the problem reports. When err is or wraps an
+// *apperr.Error, the problem takes its status, code, detail and retry
+// delay; other errors change nothing.
+func WithCause(err error) Option {
+	return func(p *ProblemDetails) { p.cause = err }
+}
+
+// WithRetryAfter asks the client to wait d, rounded up to whole seconds,
+// before retrying; it is also sent as the Retry-After header.
+func WithRetryAfter(d time.Duration) Option {
+	return func(p *ProblemDetails) {
+		if d > 0 {
+			p.RetryAfter = int((d + time.Second - 1) / time.Second)
+		}
+	}
+}
+
+// titleKey is the catalog key of an English title, such as invalid-payload
+// for "invalid payload".
+func titleKey(title string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, strings.TrimSpace(title))
+	return slug
 }
@@ -0,0 +1,216 @@
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+)
+
+func TestNegotiate(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                          "en",
+		"es":                        "es",
+		"es-MX,es;q=0.9,en;q=0.8":   "es",
+		"en-US,en;q=0.9,es;q=0.8":   "en",
+		"fr-FR,es;q=0.5":            "es",
+		"fr":                        "en",
+		"es;q=0":                    "en",
+		"en;q=0.2, es;q=0.7, *;q=0": "es",
+		"es;q=abc, en":              "en",
+	} {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestErrorLocalizesThroughWrappers(t *testing.T) {
+	handler := Localize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Error(&wrapped{w}, http.StatusBadRequest, "invalid payload", "temporary error, please retry")
+	}))
+	serve := func(lang string) (ProblemDetails, http.Header) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", lang)
+		handler.ServeHTTP(rec, req)
+		var p ProblemDetails
+		if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p, rec.Header()
+	}
+
+	p, h := serve("es-MX")
+	if p.Title != "Contenido de la solicitud no válido" || p.Detail != "error temporal, inténtelo de nuevo" || h.Get("Content-Language") != "es" {
+		t.Fatalf("expected a Spanish problem, got %+v %v", p, h)
+	}
+	if p.Type != "/problems/invalid-request" {
+		t.Fatalf("expected the type of the English title, got %q", p.Type)
+	}
+	if p, h := serve("en"); p.Title != "invalid payload" || h.Get("Content-Language") != "" || h.Get("Vary") != "Accept-Language" {
+		t.Fatalf("expected an English problem, got %+v %v", p, h)
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	if langs := Languages(); len(langs) < 2 || langs[0] != DefaultLanguage {
+		t.Fatalf("unexpected languages %v", langs)
+	}
+	for lang, c := range catalogs {
+		for key, title := range c.Titles {
+			if title == "" || titleKey(key) != key {
+				t.Errorf("%s: bad title entry %q: %q", lang, key, title)
+			}
+		}
+		for key, detail := range c.Details {
+			if detail == "" {
+				t.Errorf("%s: empty detail for %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestErrorRetryContract(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Correlation-ID", "corr-1")
+	Error(rec, http.StatusInternalServerError, "failed to queue job", "temporary error, please retry", WithEntityID("job-1"), WithRetryAfter(1500*time.Millisecond))
+	var p ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Retryable || p.RetryAfter != 2 || p.CorrelationID != "corr-1" || p.TraceID != "corr-1" || p.Code != "internal" || p.EntityID != "job-1" || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("unexpected problem %+v (Retry-After %q)", p, rec.Header().Get("Retry-After"))
+	}
+
+	rec = httptest.NewRecorder()
+	Error(rec, http.StatusBadRequest, "lot required", "name a lot")
+	p = ProblemDetails{}
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Retryable || p.RetryAfter != 0 || rec.Header().Get("Retry-After") != "" {
+		t.Fatalf("expected a non-retryable problem, got %+v (%v)", p, err)
+	}
+}
+
+func TestErrorMapsAppErrors(t *testing.T) {
+	cause := apperr.Unavailable("datastore_unavailable", "the datastore is unavailable", errors.New("dial tcp: connection refused"))
+	cause.RetryAfter = 5 * time.Second
+	rec := httptest.NewRecorder()
+	Error(rec, http.StatusInternalServerError, "failed to load route", "temporary error, please retry", WithCause(fmt.Errorf("load route r-1: %w", cause)))
+	var p ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || p.Status != http.StatusServiceUnavailable || p.Code != "datastore_unavailable" || p.Type != "/problems/service-unavailable" || p.Detail != "the datastore is unavailable" || p.RetryAfter != 5 || !p.Retryable {
+		t.Fatalf("expected the app error mapped, got %d %+v", rec.Code, p)
+	}
+
+	rec = httptest.NewRecorder()
+	Error(rec, http.StatusInternalServerError, "failed to load route", "temporary error, please retry", WithCause(apperr.NotFound("", "")))
+	p = ProblemDetails{}
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || rec.Code != http.StatusNotFound || p.Code != "not_found" || p.Detail != "temporary error, please retry" {
+		t.Fatalf("expected the kind's status and code, got %d %+v (%v)", rec.Code, p, err)
+	}
+
+	rec = httptest.NewRecorder()
+	Error(rec, http.StatusConflict, "failed to save route", "route changed", WithCause(errors.New("plain")))
+	p = ProblemDetails{}
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || rec.Code != http.StatusConflict || p.Code != "conflict" {
+		t.Fatalf("expected plain causes to change nothing, got %d %+v (%v)", rec.Code, p, err)
+	}
+}
+
+func TestMultiStatusCountsRetryableItems(t *testing.T) {
+	rec := httptest.NewRecorder()
+	busy := NewProblem(rec, http.StatusInternalServerError, "failed to queue job", "", WithRetryAfter(3*time.Second))
+	limited := NewProblem(rec, http.StatusTooManyRequests, "rate limit exceeded", "", WithRetryAfter(time.Minute))
+	invalid := NewProblem(rec, http.StatusBadRequest, "invalid payload", "")
+	MultiStatus(rec, []ItemResult{
+		{Index: 0, Status: http.StatusAccepted},
+		{Index: 1, Status: busy.Status, Problem: &busy},
+		{Index: 2, Status: limited.Status, Problem: &limited},
+		{Index: 3, Status: invalid.Status, Problem: &invalid},
+	})
+	var body MultiStatusBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusMultiStatus || body.Succeeded != 1 || body.Failed != 3 || body.Retryable != 2 || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("unexpected response %d %+v (Retry-After %q)", rec.Code, body, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		title  string
+		want   string
+	}{
+		{http.StatusBadRequest, "invalid payload", "/problems/invalid-request"},
+		{http.StatusBadRequest, "missing screenId", "/problems/invalid-request"},
+		{http.StatusBadRequest, "invalid chemical", "/problems/validation-failed"},
+		{http.StatusBadRequest, "failed to create tank mix", "/problems/validation-failed"},
+		{http.StatusBadRequest, "missing technician", "/problems/missing-technician"},
+		{http.StatusBadRequest, "calibration overdue", "/problems/calibration-overdue"},
+		{http.StatusForbidden, "insufficient scope", "/problems/insufficient-scope"},
+		{http.StatusForbidden, "forbidden", "/problems/forbidden"},
+		{http.StatusForbidden, "country blocked", "/problems/access-restricted"},
+		{http.StatusNotFound, "failed to load photo", "/problems/not-found"},
+		{http.StatusConflict, "request replayed", "/problems/replayed-request"},
+		{http.StatusConflict, "failed to register device", "/problems/conflict"},
+		{http.StatusInternalServerError, "failed to load photo", "/problems/temporary-failure"},
+		{http.StatusTeapot, "teapot", "about:blank"},
+	} {
+		if got := classify(tc.status, tc.title).Type; got != tc.want {
+			t.Errorf("classify(%d, %q) = %q, want %q", tc.status, tc.title, got, tc.want)
+		}
+	}
+	for _, slug := range problemsByTitle {
+		if _, ok := LookupProblem(slug); !ok {
+			t.Errorf("%q is not registered", slug)
+		}
+	}
+	for _, slug := range problemsByStatus {
+		if _, ok := LookupProblem(slug); !ok {
+			t.Errorf("%q is not registered", slug)
+		}
+	}
+}
+
+func TestProblemDocs(t *testing.T) {
+	get := func(handler http.HandlerFunc, target, accept string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", accept)
+		handler(rec, req)
+		return rec
+	}
+
+	rec := get(ProblemDoc, "/problems/calibration-overdue", "text/html")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "What to do:") {
+		t.Fatalf("expected an HTML page, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = get(ProblemDoc, "/problems/rate-limited", "application/json")
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Type != "/problems/rate-limited" || !p.Retryable || p.Remediation == "" {
+		t.Fatalf("unexpected problem %+v (%v)", p, err)
+	}
+	if rec := get(ProblemDoc, "/problems/nope", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown type, got %d", rec.Code)
+	}
+
+	rec = get(ProblemIndex, "/problems", "application/json")
+	var index struct{ Problems []Problem }
+	if err := json.NewDecoder(rec.Body).Decode(&index); err != nil || len(index.Problems) != len(problems) {
+		t.Fatalf("unexpected index %+v (%v)", index, err)
+	}
+}
+
+type wrapped struct{ http.ResponseWriter }
+
+func (w *wrapped) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,369 @@
+// Package i18n holds the translation catalogs screens are rendered with.
+// Catalogs are loaded from a directory of <locale>.json files, flat or
+// nested objects of strings, and <locale>.po gettext files. Lookups walk a
+// fallback chain from the requested locale to its language and then the
+// default locale, so es-MX falls back to es and then en.
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Catalog keys that configure date and time formatting. Layouts use Go's
+// reference time; month and weekday names are translated with the
+// month.<name> and weekday.<name> keys, such as month.january, month.jan
+// and weekday.mon.
+const (
+	KeyDateFormat     = "format.date"
+	KeyTimeFormat     = "format.time"
+	KeyDateTimeFormat = "format.dateTime"
+)
+
+// Layouts used when no catalog in the chain sets one.
+const (
+	DefaultDateFormat     = "Jan 2, 2006"
+	DefaultTimeFormat     = "3:04 PM"
+	DefaultDateTimeFormat = "Jan 2, 3:04 PM"
+)
+
+// Catalogs are the translations of every loaded locale.
+type Catalogs struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// Load reads every catalog in dir. A locale with both a JSON and a PO file
+// gets the keys of both, the JSON file winning. Files that cannot be read
+// or parsed are skipped and reported in the returned error; the catalogs
+// that loaded are still returned. An empty dir loads nothing.
+func Load(dir, defaultLocale string) (*Catalogs, error) {
+	c := &Catalogs{defaultLocale: Canonical(defaultLocale), messages: make(map[string]map[string]string)}
+	if dir == "" {
+		return c, nil
+	}
+	var errs []error
+	for _, ext := range []string{".po", ".json"} {
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return c, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			var messages map[string]string
+			if ext == ".json" {
+				messages, err = ParseJSON(data)
+			} else {
+				messages, err = ParsePO(data)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+				continue
+			}
+			c.Add(strings.TrimSuffix(filepath.Base(path), ext), messages)
+		}
+	}
+	return c, errors.Join(errs...)
+}
+
+// Add merges messages into locale's catalog.
+func (c *Catalogs) Add(locale string, messages map[string]string) {
+	locale = Canonical(locale)
+	catalog, ok := c.messages[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		c.messages[locale] = catalog
+	}
+	for k, v := range messages {
+		catalog[k] = v
+	}
+}
+
+// Clone returns a copy of c that can be added to without changing c.
+func (c *Catalogs) Clone() *Catalogs {
+	if c == nil {
+		return nil
+	}
+	out := &Catalogs{defaultLocale: c.defaultLocale, messages: make(map[string]map[string]string, len(c.messages))}
+	for locale, messages := range c.messages {
+		out.Add(locale, messages)
+	}
+	return out
+}
+
+// Translated reports whether key is translated for locale itself or its
+// language, without falling back to the default locale.
+func (c *Catalogs) Translated(locale, key string) bool {
+	if c == nil {
+		return false
+	}
+	for _, l := range Chain(locale, "") {
+		if _, ok := c.messages[l][key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Locales lists the loaded locales in order.
+func (c *Catalogs) Locales() []string {
+	if c == nil {
+		return nil
+	}
+	out := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		out = append(out, locale)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// For returns a translator for locale. An empty locale uses the default.
+// A nil Catalogs returns a translator that translates nothing and formats
+// with the default layouts.
+func (c *Catalogs) For(locale string) *Translator {
+	t := &Translator{}
+	if c == nil {
+		return t
+	}
+	for _, l := range Chain(locale, c.defaultLocale) {
+		if catalog, ok := c.messages[l]; ok {
+			t.chain = append(t.chain, catalog)
+		}
+	}
+	return t
+}
+
+// Canonical normalizes a locale tag: "es_mx" becomes "es-MX".
+func Canonical(locale string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool { return r == '-' || r == '_' })
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Chain lists the locales looked up for locale, most specific first, ending
+// with defaultLocale: Chain("es-MX", "en") is es-MX, es, en.
+func Chain(locale, defaultLocale string) []string {
+	var out []string
+	add := func(l string) {
+		for _, seen := range out {
+			if seen == l {
+				return
+			}
+		}
+		out = append(out, l)
+	}
+	for l := Canonical(locale); l != ""; {
+		add(l)
+		i := strings.LastIndex(l, "-")
+		if i < 0 {
+			break
+		}
+		l = l[:i]
+	}
+	if d := Canonical(defaultLocale); d != "" {
+		add(d)
+	}
+	return out
+}
+
+// Preferred returns the locale an Accept-Language header value ranks
+// highest, or "" when it names none.
+func Preferred(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag == "" || tag == "*" {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = Canonical(tag), q
+		}
+	}
+	return best
+}
+
+// Translator looks up messages and formats dates for one locale.
+type Translator struct {
+	chain []map[string]string
+}
+
+// Text returns the message for key from the first catalog in the chain that
+// has it.
+func (t *Translator) Text(key string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	for _, catalog := range t.chain {
+		if v, ok := catalog[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Lookup returns the message for key, or fallback when no catalog has it.
+func (t *Translator) Lookup(key, fallback string) string {
+	if v, ok := t.Text(key); ok {
+		return v
+	}
+	return fallback
+}
+
+// Sprintf formats the message for key, or fallback, with args. Messages
+// use fmt verbs, as c-format gettext entries do.
+func (t *Translator) Sprintf(key, fallback string, args ...any) string {
+	return fmt.Sprintf(t.Lookup(key, fallback), args...)
+}
+
+// FormatDate formats a date with the locale's date layout.
+func (t *Translator) FormatDate(d time.Time) string {
+	return t.format(d, t.Lookup(KeyDateFormat, DefaultDateFormat))
+}
+
+// FormatTime formats a time of day with the locale's time layout.
+func (t *Translator) FormatTime(d time.Time) string {
+	return t.format(d, t.Lookup(KeyTimeFormat, DefaultTimeFormat))
+}
+
+// FormatDateTime formats a moment with the locale's date and time layout.
+func (t *Translator) FormatDateTime(d time.Time) string {
+	return t.format(d, t.Lookup(KeyDateTimeFormat, DefaultDateTimeFormat))
+}
+
+// format formats d with layout, then swaps the English month and weekday
+// names Go produces for the locale's.
+func (t *Translator) format(d time.Time, layout string) string {
+	out := d.Format(layout)
+	names := []struct{ prefix, long, short string }{
+		{"month.", d.Month().String(), d.Month().String()[:3]},
+		{"weekday.", d.Weekday().String(), d.Weekday().String()[:3]},
+	}
+	for _, n := range names {
+		// Long names first: the short name is a prefix of the long one.
+		if strings.Contains(out, n.long) {
+			if v, ok := t.Text(n.prefix + strings.ToLower(n.long)); ok {
+				out = strings.ReplaceAll(out, n.long, v)
+				continue
+			}
+		}
+		if strings.Contains(out, n.short) && !strings.Contains(out, n.long) {
+			if v, ok := t.Text(n.prefix + strings.ToLower(n.short)); ok {
+				out = strings.ReplaceAll(out, n.short, v)
+			}
+		}
+	}
+	return out
+}
+
+// ParseJSON reads a catalog of strings; nested objects are flattened into
+// dotted keys.
+func ParseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	if err := flatten("", raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func flatten(prefix string, raw map[string]any, out map[string]string) error {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			out[key] = v
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: value must be a string or an object", key)
+		}
+	}
+	return nil
+}
+
+// ParsePO reads the msgid/msgstr pairs of a gettext catalog. Comments,
+// contexts and plural forms are ignored, as are untranslated entries and
+// the header.
+func ParsePO(data []byte) (map[string]string, error) {
+	out := make(map[string]string)
+	var id, str *strings.Builder
+	var current *strings.Builder
+	flush := func() {
+		if id != nil && str != nil && id.Len() > 0 && str.Len() > 0 {
+			out[id.String()] = str.String()
+		}
+		id, str, current = nil, nil, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		keyword, rest, _ := strings.Cut(line, " ")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case keyword == "msgid":
+			flush()
+			id = &strings.Builder{}
+			current = id
+		case keyword == "msgstr":
+			str = &strings.Builder{}
+			current = str
+		case strings.HasPrefix(line, `"`):
+			rest = line
+		default:
+			// msgctxt, msgid_plural and msgstr[n] lines.
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		current.WriteString(s)
+	}
+	flush()
+	return out, scanner.Err()
+}
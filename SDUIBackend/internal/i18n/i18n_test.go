@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	cases := map[string]string{
+		"es-MX":      "es-MX,es,en",
+		"es_mx":      "es-MX,es,en",
+		"zh-hant-TW": "zh-Hant-TW,zh-Hant,zh,en",
+		"en-GB":      "en-GB,en",
+		"":           "en",
+	}
+	for locale, want := range cases {
+		if got := strings.Join(Chain(locale, "en"), ","); got != want {
+			t.Errorf("Chain(%q) = %s, want %s", locale, got, want)
+		}
+	}
+	if got := Preferred("fr;q=0.4, es-mx;q=0.9, *;q=1"); got != "es-MX" {
+		t.Errorf("expected the highest ranked tag, got %q", got)
+	}
+}
+
+func TestLoadFormatsAndFallsBack(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.json": `{"greeting":"Hello"}`,
+		"es.json": `{"greeting":"Hola","format":{"date":"Monday 2 January"},"weekday":{"monday":"lunes"},"month":{"january":"enero"}}`,
+		"es-MX.po": `# comment
+msgid ""
+msgstr ""
+"Language: es-MX\n"
+
+msgctxt "menu"
+msgid "greeting"
+msgstr "Qué onda"
+
+msgid "farewell"
+msgid_plural "farewells"
+msgstr[0] "adiós"
+
+msgid "untranslated"
+msgstr ""
+`,
+		"broken.json": `{"greeting":1}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := Load(dir, "en")
+	if err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Fatalf("expected the broken catalog reported, got %v", err)
+	}
+	if got := strings.Join(c.Locales(), ","); got != "en,es,es-MX" {
+		t.Fatalf("unexpected locales %s", got)
+	}
+
+	mx := c.For("es-MX")
+	if got := mx.Lookup("greeting", ""); got != "Qué onda" {
+		t.Errorf("expected the es-MX translation, got %q", got)
+	}
+	if _, ok := mx.Text("farewell"); ok {
+		t.Errorf("expected plural entries ignored")
+	}
+	if got := mx.Lookup("untranslated", "fallback"); got != "fallback" {
+		t.Errorf("expected empty msgstr ignored, got %q", got)
+	}
+	day := time.Date(2026, 1, 5, 14, 30, 0, 0, time.UTC)
+	if got := mx.FormatDate(day); got != "lunes 5 enero" {
+		t.Errorf("expected es-MX dates formatted with es names, got %q", got)
+	}
+	if got := c.For("de").FormatTime(day); got != "2:30 PM" {
+		t.Errorf("expected the default layout, got %q", got)
+	}
+	if got := (*Catalogs)(nil).For("es").Lookup("greeting", "Hi"); got != "Hi" {
+		t.Errorf("expected nil catalogs to fall back, got %q", got)
+	}
+}
+
+func TestTranslatedIgnoresTheDefaultLocale(t *testing.T) {
+	base := &Catalogs{defaultLocale: "en", messages: map[string]map[string]string{}}
+	base.Add("en", map[string]string{"a": "A", "b": "B"})
+	base.Add("es", map[string]string{"a": "a"})
+	c := base.Clone()
+	c.Add("es-MX", map[string]string{"b": "b"})
+
+	if !c.Translated("es-MX", "a") || !c.Translated("es_mx", "b") {
+		t.Errorf("expected es-MX to count its own and its language's keys")
+	}
+	if c.Translated("es", "b") || c.Translated("fr", "a") {
+		t.Errorf("falling back to the default locale must not count as translated")
+	}
+	if base.Translated("es-MX", "b") {
+		t.Errorf("adding to a clone must leave the original alone")
+	}
+}
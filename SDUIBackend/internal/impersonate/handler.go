@@ -0,0 +1,105 @@
+package impersonate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes admin endpoints for impersonation sessions.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an impersonation handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the impersonation endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListSessions)
+	r.Post("/", h.StartSession)
+	r.Get("/{sessionId}", h.GetSession)
+	r.Delete("/{sessionId}", h.EndSession)
+	r.Get("/{sessionId}/audit", h.ListAudit)
+}
+
+// StartSession issues a short-lived impersonation token for a technician.
+func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
+	var payload Request
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	// An authenticated admin starts sessions as themselves.
+	if id, ok := auth.FromContext(r.Context()); ok {
+		payload.AdminID = id.Subject
+	}
+	session, token, err := h.service.Start(payload)
+	if err != nil {
+		h.fail(w, r, "failed to start impersonation", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, map[string]any{"session": session, "token": token})
+}
+
+// ListSessions returns sessions, optionally filtered by ?technicianId=.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.service.Sessions(r.URL.Query().Get("technicianId"))
+	if err != nil {
+		h.fail(w, r, "failed to list sessions", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+}
+
+// GetSession returns a session.
+func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
+	session, err := h.service.Session(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch session", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, session)
+}
+
+// EndSession revokes a session's token.
+func (h *Handler) EndSession(w http.ResponseWriter, r *http.Request) {
+	session, err := h.service.End(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		h.fail(w, r, "failed to end session", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, session)
+}
+
+// ListAudit returns every request made during a session.
+func (h *Handler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.Audit(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		h.fail(w, r, "failed to list audit", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"audit": entries})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidRequest):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
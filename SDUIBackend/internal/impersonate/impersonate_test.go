@@ -0,0 +1,140 @@
+package impersonate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newTestService() *Service {
+	return newSharedService(NewMemoryStore())
+}
+
+func newSharedService(sessions Store) *Service {
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+	store.AddTechnician(models.Technician{ID: "tech-2", TenantID: "bugsbgone"})
+	cfg := config.ImpersonationConfig{DefaultTTL: 15 * time.Minute, MaxTTL: time.Hour}
+	return NewService(cfg, sessions, store, nil)
+}
+
+func TestStartValidatesRequest(t *testing.T) {
+	svc := newTestService()
+	cases := []Request{
+		{AdminID: "admin", TechnicianID: "tech-1"},
+		{AdminID: "admin", TechnicianID: "missing", Reason: "ticket 1"},
+		{AdminID: "admin", TechnicianID: "tech-1", Reason: "ticket 1", TTLSeconds: 7200},
+	}
+	for _, req := range cases {
+		if _, _, err := svc.Start(req); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+}
+
+func TestMiddlewareForcesTechnicianAndAudits(t *testing.T) {
+	svc := newTestService()
+	session, token, err := svc.Start(Request{AdminID: "admin", TechnicianID: "tech-1", Reason: "ticket 42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var seenUser string
+	h := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenUser = r.URL.Query().Get("userId")
+		if _, ok := FromContext(r.Context()); !ok {
+			t.Errorf("expected session in context")
+		}
+	}))
+	serve := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/screens/today?userId=someone-else", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodGet); rec.Code != http.StatusOK || rec.Header().Get(Header) != session.ID {
+		t.Fatalf("unexpected response: %d %v", rec.Code, rec.Header())
+	}
+	if seenUser != "tech-1" {
+		t.Fatalf("expected impersonated technician, got %q", seenUser)
+	}
+	if rec := serve(http.MethodPost); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected writes to be blocked, got %d", rec.Code)
+	}
+
+	audit, _ := svc.Audit(session.ID)
+	if len(audit) != 2 || audit[0].Status != http.StatusOK || audit[1].Status != http.StatusForbidden {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+	if got, _ := svc.Session(session.ID); got.Requests != 2 {
+		t.Fatalf("expected request count, got %+v", got)
+	}
+
+	if _, err := svc.End(session.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec := serve(http.MethodGet); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected ended session to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestSessionsAreSharedThroughTheDocumentStore(t *testing.T) {
+	docs := docstore.NewMemoryStore()
+	issuer := newSharedService(NewDocumentStore(docs))
+	other := newSharedService(NewDocumentStore(docs))
+
+	session, token, err := issuer.Start(Request{AdminID: "admin", TechnicianID: "tech-1", Reason: "ticket 9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := other.Resolve(token); err != nil || got.ID != session.ID {
+		t.Fatalf("expected another instance to resolve the session, got %+v (%v)", got, err)
+	}
+	if _, err := issuer.End(session.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := other.Resolve(token); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected the ended session to be rejected elsewhere, got %v", err)
+	}
+}
+
+func TestSessionExpires(t *testing.T) {
+	svc := newTestService()
+	session, token, _ := svc.Start(Request{AdminID: "admin", TechnicianID: "tech-1", Reason: "ticket 7", TTLSeconds: 60})
+	svc.now = func() time.Time { return session.ExpiresAt }
+	if _, err := svc.Resolve(token); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected expired session to be rejected, got %v", err)
+	}
+}
+
+func TestSessionBindsTheTechniciansTenant(t *testing.T) {
+	svc := newTestService()
+	session, token, err := svc.Start(Request{AdminID: "admin", TechnicianID: "tech-2", Reason: "ticket 9"})
+	if err != nil || session.TenantID != "bugsbgone" {
+		t.Fatalf("expected the technician's tenant on the session, got %+v (%v)", session, err)
+	}
+
+	var tenantID string
+	var bound bool
+	h := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, bound = svc.CredentialTenant(r)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if !bound || tenantID != "bugsbgone" {
+		t.Fatalf("expected the request bound to bugsbgone, got %q (%v)", tenantID, bound)
+	}
+	if _, ok := svc.CredentialTenant(httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)); ok {
+		t.Fatal("expected requests without a session left unbound")
+	}
+}
@@ -0,0 +1,116 @@
+package impersonate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Header identifies impersonated responses so support tooling and logs can
+// never mistake them for the technician's own traffic.
+const Header = "X-Impersonation-Session"
+
+// sessionKey is the context key for the active impersonation session.
+type sessionKey struct{}
+
+// FromContext returns the impersonation session for the request, if any.
+func FromContext(ctx context.Context) (Session, bool) {
+	s, ok := ctx.Value(sessionKey{}).(Session)
+	return s, ok
+}
+
+// Middleware applies impersonation tokens to public API requests. The
+// technician is forced onto the request in place of any userId supplied, only
+// reads are allowed, and every request is audited. Requests without an
+// impersonation token pass through untouched.
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := impersonationToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, err := s.Resolve(token)
+		if err != nil {
+			if !errors.Is(err, ErrUnauthorized) {
+				middleware.LoggerFrom(r.Context()).Error("impersonation lookup failed", slog.Any("error", err))
+			}
+			respond.Error(w, http.StatusUnauthorized, "invalid impersonation token", "session is unknown, expired, or ended")
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		rec.Header().Set(Header, session.ID)
+		entry := AuditEntry{
+			SessionID:    session.ID,
+			AdminID:      session.AdminID,
+			TechnicianID: session.TechnicianID,
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			Query:        r.URL.RawQuery,
+			IP:           r.RemoteAddr,
+		}
+		defer func() {
+			entry.Status = rec.status
+			entry.At = s.now().UTC()
+			if err := s.store.AppendAudit(entry); err != nil {
+				s.logger.Error("record impersonation audit", slog.String("session", session.ID), slog.Any("error", err))
+			}
+		}()
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			respond.Error(rec, http.StatusForbidden, "impersonation is read-only", "writes are not allowed while impersonating")
+			return
+		}
+
+		q := r.URL.Query()
+		q.Set("userId", session.TechnicianID)
+		r.URL.RawQuery = q.Encode()
+
+		logger := middleware.LoggerFrom(r.Context()).With(
+			slog.String("impersonationSession", session.ID),
+			slog.String("impersonatedBy", session.AdminID),
+		)
+		ctx := context.WithValue(r.Context(), sessionKey{}, session)
+		ctx = middleware.ContextWithLogger(ctx, logger)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+	})
+}
+
+// CredentialTenant reports the tenant of the technician a request's
+// impersonation session views, so the request acts for that tenant as the
+// technician's own would. It implements tenant.Credentials; run Middleware
+// first.
+func (s *Service) CredentialTenant(r *http.Request) (string, bool) {
+	session, ok := FromContext(r.Context())
+	return session.TenantID, ok
+}
+
+// impersonationToken reads a bearer token carrying the impersonation prefix.
+func impersonationToken(r *http.Request) (string, bool) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	return value, strings.HasPrefix(value, tokenPrefix)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
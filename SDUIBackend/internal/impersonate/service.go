@@ -0,0 +1,142 @@
+package impersonate
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// tokenPrefix marks impersonation tokens; it differs from API tokens so the
+// two can never be confused.
+const tokenPrefix = "pgi_"
+
+// Service issues and resolves impersonation sessions.
+type Service struct {
+	cfg         config.ImpersonationConfig
+	store       Store
+	technicians repository.TechnicianRepository
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewService wires an impersonation service.
+func NewService(cfg config.ImpersonationConfig, store Store, technicians repository.TechnicianRepository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, technicians: technicians, logger: logger, now: time.Now}
+}
+
+// Start opens a session and returns it with its token, which is only
+// available in this response.
+func (s *Service) Start(req Request) (Session, string, error) {
+	if err := req.Validate(); err != nil {
+		return Session{}, "", err
+	}
+	tech, err := s.technicians.GetByID(req.TechnicianID)
+	if err != nil {
+		return Session{}, "", fmt.Errorf("%w: technician %q not found", ErrInvalidRequest, req.TechnicianID)
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = s.cfg.DefaultTTL
+	}
+	if ttl > s.cfg.MaxTTL {
+		return Session{}, "", fmt.Errorf("%w: ttlSeconds must be <= %d", ErrInvalidRequest, int(s.cfg.MaxTTL.Seconds()))
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Session{}, "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(raw)
+
+	now := s.now().UTC()
+	session := Session{
+		ID:           uuid.NewString(),
+		AdminID:      req.AdminID,
+		TechnicianID: req.TechnicianID,
+		TenantID:     tech.TenantID,
+		Reason:       strings.TrimSpace(req.Reason),
+		Hash:         hashToken(token),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(ttl),
+	}
+	if err := s.store.SaveSession(session); err != nil {
+		return Session{}, "", err
+	}
+	s.logger.Warn("impersonation started",
+		slog.String("session", session.ID),
+		slog.String("admin", session.AdminID),
+		slog.String("technician", session.TechnicianID),
+		slog.String("reason", session.Reason),
+		slog.Time("expiresAt", session.ExpiresAt),
+	)
+	return session, token, nil
+}
+
+// End closes a session before it expires.
+func (s *Service) End(id string) (Session, error) {
+	session, err := s.store.GetSession(id)
+	if err != nil {
+		return Session{}, err
+	}
+	if session.EndedAt == nil {
+		now := s.now().UTC()
+		session.EndedAt = &now
+		if err := s.store.SaveSession(session); err != nil {
+			return Session{}, err
+		}
+		s.logger.Warn("impersonation ended", slog.String("session", session.ID), slog.String("admin", session.AdminID))
+	}
+	return session, nil
+}
+
+// Session returns a single session.
+func (s *Service) Session(id string) (Session, error) {
+	return s.store.GetSession(id)
+}
+
+// Sessions lists sessions, optionally for one technician.
+func (s *Service) Sessions(technicianID string) ([]Session, error) {
+	return s.store.ListSessions(technicianID)
+}
+
+// Audit returns every request made during a session.
+func (s *Service) Audit(id string) ([]AuditEntry, error) {
+	return s.store.ListAudit(id)
+}
+
+// Resolve maps a token to an active session.
+func (s *Service) Resolve(token string) (Session, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return Session{}, ErrUnauthorized
+	}
+	session, err := s.store.GetSessionByHash(hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return Session{}, ErrUnauthorized
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	if !session.Active(s.now()) {
+		return Session{}, ErrUnauthorized
+	}
+	return session, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
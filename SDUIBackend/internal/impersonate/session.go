@@ -0,0 +1,205 @@
+package impersonate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+var (
+	// ErrNotFound is returned when a session does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidRequest wraps impersonation request validation failures.
+	ErrInvalidRequest = errors.New("invalid impersonation request")
+	// ErrUnauthorized is returned for unknown, expired, or ended sessions.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Request asks to view the app as a technician.
+type Request struct {
+	AdminID      string `json:"adminId"`
+	TechnicianID string `json:"technicianId"`
+	Reason       string `json:"reason"` // e.g. support ticket reference
+	TTLSeconds   int    `json:"ttlSeconds,omitempty"`
+}
+
+// Validate checks an impersonation request.
+func (r Request) Validate() error {
+	var problems []string
+	if r.AdminID == "" {
+		problems = append(problems, "adminId is required")
+	}
+	if r.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		problems = append(problems, "reason is required")
+	}
+	if r.TTLSeconds < 0 {
+		problems = append(problems, "ttlSeconds must be >= 0")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidRequest, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Session is a short-lived, read-only impersonation grant.
+type Session struct {
+	ID           string `json:"id"`
+	AdminID      string `json:"adminId"`
+	TechnicianID string `json:"technicianId"`
+	// TenantID is the technician's tenant, which requests made during the
+	// session act for.
+	TenantID  string     `json:"tenantId,omitempty"`
+	Reason    string     `json:"reason"`
+	Hash      string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Requests  int        `json:"requests"`
+}
+
+// Active reports whether the session can be used at now.
+func (s Session) Active(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AuditEntry records one request made while impersonating.
+type AuditEntry struct {
+	SessionID    string    `json:"sessionId"`
+	AdminID      string    `json:"adminId"`
+	TechnicianID string    `json:"technicianId"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Query        string    `json:"query,omitempty"`
+	Status       int       `json:"status"`
+	IP           string    `json:"ip"`
+	At           time.Time `json:"at"`
+}
+
+// Store persists sessions and their audit trail.
+type Store interface {
+	SaveSession(s Session) error
+	GetSession(id string) (Session, error)
+	GetSessionByHash(hash string) (Session, error)
+	ListSessions(technicianID string) ([]Session, error)
+	// AppendAudit records an entry and increments the session's request count.
+	AppendAudit(e AuditEntry) error
+	ListAudit(sessionID string) ([]AuditEntry, error)
+}
+
+// storedSession is a session as it is saved, with the hash its JSON leaves
+// out.
+type storedSession struct {
+	Session
+	Hash string `json:"hash"`
+}
+
+// session returns the stored session with its hash.
+func (st storedSession) session() Session {
+	s := st.Session
+	s.Hash = st.Hash
+	return s
+}
+
+// DocumentStore keeps sessions and their audit trail in the shared
+// document store, so a session started through one instance is honoured,
+// and audited, by every other.
+type DocumentStore struct {
+	sessions docstore.Collection[storedSession]
+	audit    docstore.Collection[AuditEntry] // keyed by session ID and request number
+}
+
+// NewDocumentStore keeps sessions and their audit trail in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{
+		sessions: docstore.NewCollection(docs, "impersonation_sessions", func(s storedSession) docstore.Keys {
+			return docstore.Keys{"hash": s.Hash, "technician": s.TechnicianID}
+		}),
+		audit: docstore.NewCollection(docs, "impersonation_audit", func(e AuditEntry) docstore.Keys {
+			return docstore.Keys{"session": e.SessionID}
+		}),
+	}
+}
+
+// NewMemoryStore keeps sessions in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveSession(s Session) error {
+	return d.sessions.Put(s.ID, storedSession{Session: s, Hash: s.Hash})
+}
+
+func (d *DocumentStore) GetSession(id string) (Session, error) {
+	st, err := d.sessions.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Session{}, ErrNotFound
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	return st.session(), nil
+}
+
+func (d *DocumentStore) GetSessionByHash(hash string) (Session, error) {
+	found, err := d.sessions.Find(docstore.Keys{"hash": hash})
+	if err != nil {
+		return Session{}, err
+	}
+	if len(found) == 0 {
+		return Session{}, ErrNotFound
+	}
+	return found[0].session(), nil
+}
+
+// ListSessions returns sessions newest first, optionally for one technician.
+func (d *DocumentStore) ListSessions(technicianID string) ([]Session, error) {
+	var keys docstore.Keys
+	if technicianID != "" {
+		keys = docstore.Keys{"technician": technicianID}
+	}
+	found, err := d.sessions.Find(keys)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Session, len(found))
+	for i, st := range found {
+		out[i] = st.session()
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// AppendAudit numbers the entry with the session's new request count, so
+// entries from every instance keep their request order.
+func (d *DocumentStore) AppendAudit(e AuditEntry) error {
+	st, err := d.sessions.Update(e.SessionID, func(current *storedSession) (storedSession, error) {
+		if current == nil {
+			return storedSession{}, ErrNotFound
+		}
+		st := *current
+		st.Requests++
+		return st, nil
+	})
+	if err != nil {
+		return err
+	}
+	return d.audit.Put(fmt.Sprintf("%s/%09d", e.SessionID, st.Requests), e)
+}
+
+// ListAudit returns a session's audit trail in request order.
+func (d *DocumentStore) ListAudit(sessionID string) ([]AuditEntry, error) {
+	if _, err := d.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+	return d.audit.Find(docstore.Keys{"session": sessionID})
+}
@@ -0,0 +1,274 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/sftp"
+)
+
+// Source methods.
+const (
+	MethodSFTP      = "sftp"
+	MethodDirectory = "directory"
+)
+
+// Run statuses.
+const (
+	StatusProcessed = "processed"
+	StatusPartial   = "partial" // some rows rejected
+	StatusFailed    = "failed"
+	StatusDuplicate = "duplicate"
+)
+
+var (
+	// ErrNotFound is returned when a feed or run does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidFeed wraps feed validation failures.
+	ErrInvalidFeed = errors.New("invalid feed")
+)
+
+// Feed is a partner drop location polled for new files.
+type Feed struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Enabled   bool       `json:"enabled"`
+	Source    Connection `json:"source"`
+	Parser    ParserSpec `json:"parser"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+
+	LastPolledAt *time.Time `json:"lastPolledAt,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+}
+
+// Connection describes where partner files are picked up. For SFTP the
+// password or private key is read from CredentialsSecret, and HostKey is the
+// server's public key in authorized_keys form.
+type Connection struct {
+	Method            string `json:"method"`
+	Host              string `json:"host,omitempty"`
+	Port              int    `json:"port,omitempty"`
+	Username          string `json:"username,omitempty"`
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	HostKey           string `json:"hostKey,omitempty"`
+	RemoteDir         string `json:"remoteDir,omitempty"`
+	Pattern           string `json:"pattern,omitempty"` // glob, default "*"
+}
+
+// ParserSpec selects a registered parser and maps its fields to columns,
+// either by header name or by zero-based index (e.g. "3").
+type ParserSpec struct {
+	Kind       string            `json:"kind"`
+	Delimiter  string            `json:"delimiter,omitempty"`
+	HasHeader  bool              `json:"hasHeader"`
+	DateFormat string            `json:"dateFormat,omitempty"`
+	TimeFormat string            `json:"timeFormat,omitempty"`
+	Columns    map[string]string `json:"columns"`
+}
+
+// Validate checks a feed definition against the registered parsers.
+func (f Feed) Validate() error {
+	var problems []string
+	if f.Name == "" {
+		problems = append(problems, "name is required")
+	}
+
+	switch f.Source.Method {
+	case MethodDirectory:
+	case MethodSFTP:
+		if f.Source.Host == "" || f.Source.Username == "" || f.Source.CredentialsSecret == "" {
+			problems = append(problems, "sftp source requires host, username, and credentialsSecret")
+		}
+		if f.Source.HostKey == "" {
+			problems = append(problems, "sftp source requires hostKey")
+		} else if _, err := sftp.ParseHostKey(f.Source.HostKey); err != nil {
+			problems = append(problems, "source.hostKey must be a public key in authorized_keys form")
+		}
+	default:
+		problems = append(problems, "source.method must be sftp or directory")
+	}
+	if f.Source.Pattern != "" {
+		if _, err := path.Match(f.Source.Pattern, ""); err != nil {
+			problems = append(problems, "source.pattern is not a valid glob")
+		}
+	}
+
+	parser, ok := parsers[f.Parser.Kind]
+	if !ok {
+		problems = append(problems, fmt.Sprintf("parser.kind must be one of %s", strings.Join(parserKinds(), ", ")))
+	} else {
+		for _, field := range parser.Required() {
+			if f.Parser.Columns[field] == "" {
+				problems = append(problems, fmt.Sprintf("parser.columns.%s is required", field))
+			}
+		}
+	}
+	if f.Parser.Delimiter != "" && len([]rune(f.Parser.Delimiter)) != 1 {
+		problems = append(problems, "parser.delimiter must be a single character")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidFeed, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RowError describes one rejected input row.
+type RowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Run records the ingestion of a single file.
+type Run struct {
+	ID              string     `json:"id"`
+	FeedID          string     `json:"feedId"`
+	FileName        string     `json:"fileName"`
+	Checksum        string     `json:"checksum"`
+	Status          string     `json:"status"`
+	DuplicateOf     string     `json:"duplicateOf,omitempty"`
+	Rows            int        `json:"rows"`
+	Imported        int        `json:"imported"`
+	Rejected        int        `json:"rejected"`
+	Error           string     `json:"error,omitempty"`
+	Errors          []RowError `json:"errors,omitempty"`
+	ErrorsTruncated bool       `json:"errorsTruncated,omitempty"`
+	Warnings        []string   `json:"warnings,omitempty"` // e.g. overbooked days
+	StartedAt       time.Time  `json:"startedAt"`
+	FinishedAt      time.Time  `json:"finishedAt"`
+}
+
+// Store persists feeds, runs, and the checksums already ingested.
+type Store interface {
+	SaveFeed(f Feed) error
+	GetFeed(id string) (Feed, error)
+	ListFeeds() ([]Feed, error)
+	DeleteFeed(id string) error
+	SaveRun(r Run) error
+	GetRun(id string) (Run, error)
+	ListRuns(feedID string, limit int) ([]Run, error)
+	// FindChecksum returns the run that first ingested checksum for a feed.
+	FindChecksum(feedID, checksum string) (Run, bool, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	feeds     map[string]Feed
+	runs      map[string]Run
+	order     []string
+	checksums map[string]string // feedID|checksum -> run ID
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		feeds:     make(map[string]Feed),
+		runs:      make(map[string]Run),
+		checksums: make(map[string]string),
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveFeed(f Feed) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feeds[f.ID] = f
+	return nil
+}
+
+func (m *MemoryStore) GetFeed(id string) (Feed, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.feeds[id]
+	if !ok {
+		return Feed{}, ErrNotFound
+	}
+	return f, nil
+}
+
+func (m *MemoryStore) ListFeeds() ([]Feed, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Feed, 0, len(m.feeds))
+	for _, f := range m.feeds {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteFeed(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.feeds[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.feeds, id)
+	return nil
+}
+
+// SaveRun stores a run; successful runs register their checksum so the same
+// content is recognised as a duplicate later.
+func (m *MemoryStore) SaveRun(r Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.runs[r.ID]; !exists {
+		m.order = append(m.order, r.ID)
+	}
+	m.runs[r.ID] = r
+	if r.Status == StatusProcessed || r.Status == StatusPartial {
+		key := r.FeedID + "|" + r.Checksum
+		if _, seen := m.checksums[key]; !seen {
+			m.checksums[key] = r.ID
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetRun(id string) (Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.runs[id]
+	if !ok {
+		return Run{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// ListRuns returns the feed's runs, newest first.
+func (m *MemoryStore) ListRuns(feedID string, limit int) ([]Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Run
+	for i := len(m.order) - 1; i >= 0; i-- {
+		r := m.runs[m.order[i]]
+		if r.FeedID != feedID {
+			continue
+		}
+		out = append(out, r)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) FindChecksum(feedID, checksum string) (Run, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.checksums[feedID+"|"+checksum]
+	if !ok {
+		return Run{}, false, nil
+	}
+	return m.runs[id], true, nil
+}
@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/operation"
+)
+
+// OperationKind is the kind of operation a manual poll runs as.
+const OperationKind = "import"
+
+// Handler exposes admin endpoints for inbound partner feeds.
+type Handler struct {
+	service *Service
+	ops     *operation.Service
+}
+
+// NewHandler creates an ingestion handler. Manual polls run as operations
+// on ops.
+func NewHandler(service *Service, ops *operation.Service) *Handler {
+	h := &Handler{service: service, ops: ops}
+	ops.Register(OperationKind, h.poll)
+	return h
+}
+
+// Routes mounts the ingestion endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/feeds", h.ListFeeds)
+	r.Post("/feeds", h.CreateFeed)
+	r.Get("/feeds/{feedId}", h.GetFeed)
+	r.Put("/feeds/{feedId}", h.UpdateFeed)
+	r.Delete("/feeds/{feedId}", h.DeleteFeed)
+	r.Post("/feeds/{feedId}/poll", h.PollNow)
+	r.Get("/feeds/{feedId}/runs", h.ListRuns)
+	r.Get("/runs/{runId}", h.GetRun)
+}
+
+// CreateFeed registers a partner feed.
+func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	var payload Feed
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	f, err := h.service.CreateFeed(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create feed", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, f)
+}
+
+// UpdateFeed replaces a partner feed definition.
+func (h *Handler) UpdateFeed(w http.ResponseWriter, r *http.Request) {
+	var payload Feed
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	f, err := h.service.UpdateFeed(chi.URLParam(r, "feedId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update feed", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+// GetFeed returns a feed and its last poll status.
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	f, err := h.service.Feed(chi.URLParam(r, "feedId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch feed", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+// ListFeeds returns every feed.
+func (h *Handler) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.service.Feeds()
+	if err != nil {
+		h.fail(w, r, "failed to list feeds", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"feeds": feeds})
+}
+
+// DeleteFeed removes a feed.
+func (h *Handler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteFeed(chi.URLParam(r, "feedId")); err != nil {
+		h.fail(w, r, "failed to delete feed", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PollNow starts checking a feed for new files outside the polling interval
+// and answers with the operation doing it. Its progress counts files, it
+// links each run, and its result is the runs and the feed's last error.
+func (h *Handler) PollNow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "feedId")
+	if _, err := h.service.Feed(id); err != nil {
+		h.fail(w, r, "failed to poll feed", err)
+		return
+	}
+	op, err := h.ops.Start(r.Context(), OperationKind, id)
+	if err != nil {
+		h.fail(w, r, "failed to poll feed", err)
+		return
+	}
+	operation.Accepted(w, op)
+}
+
+// poll checks feed id for new files as an operation.
+func (h *Handler) poll(ctx context.Context, id string, t *operation.Tracker) (any, error) {
+	runs, err := h.service.PollNow(ctx, id, t.Progress)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		t.Link("run", "/v1/admin/integrations/inbound/runs/"+run.ID)
+	}
+	f, err := h.service.Feed(id)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{"runs": runs, "lastError": f.LastError}
+	if f.LastError != "" {
+		return result, errors.New(f.LastError)
+	}
+	return result, nil
+}
+
+// ListRuns returns a feed's ingestion runs, newest first.
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	runs, err := h.service.Runs(chi.URLParam(r, "feedId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list runs", err)
+		return
+	}
+	// Row errors can be large; the run detail endpoint returns them.
+	for i := range runs {
+		runs[i].Errors = nil
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"runs": runs})
+}
+
+// GetRun returns a run with its row-level errors.
+func (h *Handler) GetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.RunDetail(chi.URLParam(r, "runId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch run", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, run)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidFeed):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,185 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/address"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func routeFeed() Feed {
+	return Feed{
+		Name:    "Franchise routes",
+		Enabled: true,
+		Source:  Connection{Method: MethodDirectory, Pattern: "*.csv"},
+		Parser: ParserSpec{
+			Kind:      "route_stops",
+			HasHeader: true,
+			Columns: map[string]string{
+				"technicianId": "tech",
+				"serviceDate":  "date",
+				"customerName": "customer",
+				"address":      "address",
+				"windowStart":  "start",
+			},
+		},
+	}
+}
+
+const routeFile = "tech,date,customer,address,start\n" +
+	"T1,2026-04-02,Smith,1 Main St,08:30\n" +
+	"T1,2026-04-02,Jones,2 Main St,bogus\n" +
+	"T1,04/02/2026,Brown,3 Main St,\n" +
+	"T1,2026-04-02,Lee,4 Main St,10:00\n"
+
+func memoryRepos(store *storememory.Store) repository.Repository {
+	return repository.Repository{
+		Technicians: store,
+		Routes:      store,
+		Screens:     store,
+		Sync:        store,
+		Devices:     store,
+	}
+}
+
+func TestValidateRequiresParserColumns(t *testing.T) {
+	f := routeFeed()
+	delete(f.Parser.Columns, "address")
+	if err := f.Validate(); !errors.Is(err, ErrInvalidFeed) {
+		t.Fatalf("expected invalid feed, got %v", err)
+	}
+	if err := routeFeed().Validate(); err != nil {
+		t.Fatalf("expected valid feed, got %v", err)
+	}
+}
+
+func TestValidateRequiresSFTPHostKey(t *testing.T) {
+	f := routeFeed()
+	f.Source = Connection{Method: MethodSFTP, Host: "sftp.example.com", Username: "pestgenie", CredentialsSecret: "FEED_SFTP"}
+	if err := f.Validate(); !errors.Is(err, ErrInvalidFeed) {
+		t.Fatalf("expected a missing host key rejected, got %v", err)
+	}
+	f.Source.HostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	if err := f.Validate(); err != nil {
+		t.Fatalf("expected valid feed, got %v", err)
+	}
+}
+
+// routeCheckerFunc adapts a function to RouteChecker.
+type routeCheckerFunc func([]models.Route) []string
+
+func (f routeCheckerFunc) CheckRoutes(routes []models.Route) []string { return f(routes) }
+
+func TestPollImportsRowsAndSkipsDuplicates(t *testing.T) {
+	root := t.TempDir()
+	repo := storememory.NewStore()
+	var checked []models.Route
+	checker := routeCheckerFunc(func(routes []models.Route) []string {
+		checked = append(checked, routes...)
+		return []string{"overbooked"}
+	})
+	svc := NewService(config.InboundConfig{Enabled: true}, NewMemoryStore(), memoryRepos(repo), map[string]Source{
+		MethodDirectory: DirectorySource{Root: root},
+	}, checker, nil, nil, nil, nil)
+
+	f, err := svc.CreateFeed(routeFeed())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := filepath.Join(root, f.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "a.csv"), []byte(routeFile), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "b.csv"), []byte(routeFile), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+
+	runs, err := svc.PollNow(context.Background(), f.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected two runs, got %d", len(runs))
+	}
+	first, second := runs[0], runs[1]
+	if first.Status != StatusPartial || first.Imported != 2 || first.Rejected != 2 || len(first.Errors) != 2 {
+		t.Fatalf("unexpected first run: %+v", first)
+	}
+	if first.Errors[0].Line != 3 || first.Errors[0].Field != "windowStart" {
+		t.Fatalf("unexpected row error: %+v", first.Errors[0])
+	}
+	if len(checked) != 1 || len(first.Warnings) != 1 || second.Warnings != nil {
+		t.Fatalf("expected the imported route to be checked once, got %d checks and warnings %v", len(checked), first.Warnings)
+	}
+	if second.Status != StatusDuplicate || second.DuplicateOf != first.ID {
+		t.Fatalf("expected duplicate of first run, got %+v", second)
+	}
+
+	route, err := repo.GetRoute("T1", time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(route.CustomerStops) != 2 {
+		t.Fatalf("expected imported route with two stops, got %+v (%v)", route, err)
+	}
+
+	for _, name := range []string{"a.csv", "b.csv"} {
+		if _, err := os.Stat(filepath.Join(dir, "processed", name)); err != nil {
+			t.Errorf("expected %s to be moved to processed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("expected unmatched file to be left alone: %v", err)
+	}
+}
+
+func TestPollRecordsSourceErrorsOnFeed(t *testing.T) {
+	svc := NewService(config.InboundConfig{}, NewMemoryStore(), memoryRepos(storememory.NewStore()), nil, nil, nil, nil, nil, nil)
+	f, _ := svc.CreateFeed(routeFeed())
+
+	if _, err := svc.PollNow(context.Background(), f.ID, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := svc.Feed(f.ID)
+	if got.LastPolledAt == nil || got.LastError == "" {
+		t.Fatalf("expected poll error to be recorded, got %+v", got)
+	}
+}
+
+// undeliverable flags one address and standardizes the rest locally.
+type undeliverable string
+
+func (undeliverable) Name() string { return "test" }
+
+func (u undeliverable) Validate(ctx context.Context, in string) (address.Result, error) {
+	res, err := address.Local{}.Validate(ctx, in)
+	if in == string(u) {
+		res.Components.Status = address.StatusUndeliverable
+	}
+	return res, err
+}
+
+func TestApplyStandardizesAddresses(t *testing.T) {
+	repo := storememory.NewStore()
+	addrs := address.NewService(config.AddressConfig{CacheTTL: time.Hour}, undeliverable("4 Main St"), address.NewMemoryStore(), nil)
+	rows, err := readRows([]byte(routeFile), routeFeed().Parser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := routeStopsParser{}.Apply(context.Background(), memoryRepos(repo), addrs, rows, routeFeed().Parser)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied.Imported != 2 || len(applied.Warnings) != 1 {
+		t.Fatalf("expected both valid rows imported with one warning, got %+v", applied)
+	}
+	stops := applied.Routes[0].CustomerStops
+	if stops[0].Address != "1 MAIN ST" || stops[0].PostalAddress == nil || stops[1].PostalAddress.Status != address.StatusUndeliverable {
+		t.Fatalf("unexpected stops: %+v", stops)
+	}
+}
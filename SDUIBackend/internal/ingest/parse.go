@@ -0,0 +1,267 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/address"
+)
+
+// Parser maps partner rows onto domain models and persists them.
+type Parser interface {
+	// Required lists the fields that must be mapped to a column.
+	Required() []string
+	// Apply imports rows, standardizing addresses unless addresses is nil.
+	// A non-nil error aborts the whole file.
+	Apply(ctx context.Context, repos repository.Repository, addresses *address.Service, rows []Row, spec ParserSpec) (Applied, error)
+}
+
+// Applied is the outcome of importing a file's rows.
+type Applied struct {
+	Imported int
+	Errors   []RowError     // rejected rows
+	Routes   []models.Route // routes created or replaced
+	Warnings []string       // imported rows worth a look, such as undeliverable addresses
+}
+
+// parsers is the registry of parser kinds a feed can select.
+var parsers = map[string]Parser{
+	"route_stops": routeStopsParser{},
+}
+
+func parserKinds() []string {
+	kinds := make([]string, 0, len(parsers))
+	for k := range parsers {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Row is one data line with its mapped columns.
+type Row struct {
+	Line   int
+	values map[string]string
+}
+
+// Get returns the trimmed value for a mapped field.
+func (r Row) Get(field string) string {
+	return strings.TrimSpace(r.values[field])
+}
+
+// readRows decodes a delimited file and resolves the spec's column mapping.
+func readRows(data []byte, spec ParserSpec) ([]Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if spec.Delimiter != "" {
+		reader.Comma = []rune(spec.Delimiter)[0]
+	}
+
+	var header map[string]int
+	var rows []Row
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if spec.HasHeader && header == nil {
+			header = make(map[string]int, len(record))
+			for i, name := range record {
+				header[strings.TrimSpace(name)] = i
+			}
+			continue
+		}
+
+		values := make(map[string]string, len(spec.Columns))
+		for field, column := range spec.Columns {
+			idx, err := columnIndex(column, header)
+			if err != nil {
+				return nil, err
+			}
+			if idx < len(record) {
+				values[field] = record[idx]
+			}
+		}
+		rows = append(rows, Row{Line: line, values: values})
+	}
+	return rows, nil
+}
+
+func columnIndex(column string, header map[string]int) (int, error) {
+	if idx, ok := header[column]; ok {
+		return idx, nil
+	}
+	if idx, err := strconv.Atoi(column); err == nil && idx >= 0 {
+		return idx, nil
+	}
+	return 0, fmt.Errorf("column %q not found in header", column)
+}
+
+// routeStopsParser imports one customer stop per row and groups stops into
+// routes by technician and service date. The partner file is authoritative:
+// an existing route's stops are replaced, its alerts are kept.
+type routeStopsParser struct{}
+
+func (routeStopsParser) Required() []string {
+	return []string{"technicianId", "serviceDate", "customerName", "address"}
+}
+
+func (p routeStopsParser) Apply(ctx context.Context, repos repository.Repository, addresses *address.Service, rows []Row, spec ParserSpec) (Applied, error) {
+	dateFormat := spec.DateFormat
+	if dateFormat == "" {
+		dateFormat = "2006-01-02"
+	}
+	timeFormat := spec.TimeFormat
+	if timeFormat == "" {
+		timeFormat = "15:04"
+	}
+
+	type routeKey struct {
+		technicianID string
+		date         string
+	}
+	routes := make(map[routeKey]*models.Route)
+	var keys []routeKey
+	var out Applied
+	validate := addresses != nil
+
+	for _, row := range rows {
+		var rowErrs []RowError
+		for _, field := range p.Required() {
+			if row.Get(field) == "" {
+				rowErrs = append(rowErrs, RowError{Line: row.Line, Field: field, Message: "value is required"})
+			}
+		}
+		date, err := time.Parse(dateFormat, row.Get("serviceDate"))
+		if err != nil && row.Get("serviceDate") != "" {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "serviceDate", Message: "expected format " + dateFormat})
+		}
+		windowStart, err := parseWindow(date, row.Get("windowStart"), timeFormat)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "windowStart", Message: err.Error()})
+		}
+		windowEnd, err := parseWindow(date, row.Get("windowEnd"), timeFormat)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "windowEnd", Message: err.Error()})
+		}
+		if !windowStart.IsZero() && !windowEnd.IsZero() && windowEnd.Before(windowStart) {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "windowEnd", Message: "window ends before it starts"})
+		}
+		sqft := 0
+		if v := row.Get("propertySqft"); v != "" {
+			if sqft, err = strconv.Atoi(v); err != nil || sqft < 0 {
+				rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "propertySqft", Message: "expected a non-negative integer"})
+			}
+		}
+		lat, err := parseCoordinate(row.Get("latitude"), 90)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "latitude", Message: err.Error()})
+		}
+		lng, err := parseCoordinate(row.Get("longitude"), 180)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: row.Line, Field: "longitude", Message: err.Error()})
+		}
+		if len(rowErrs) > 0 {
+			out.Errors = append(out.Errors, rowErrs...)
+			continue
+		}
+
+		key := routeKey{technicianID: row.Get("technicianId"), date: date.Format("2006-01-02")}
+		route, ok := routes[key]
+		if !ok {
+			route = &models.Route{ID: row.Get("routeId"), TechnicianID: key.technicianID, ServiceDate: date}
+			routes[key] = route
+			keys = append(keys, key)
+		}
+		stop := models.RouteStop{
+			CustomerID:   row.Get("customerId"),
+			CustomerName: row.Get("customerName"),
+			Address:      row.Get("address"),
+			WindowStart:  windowStart,
+			WindowEnd:    windowEnd,
+			Priority:     row.Get("priority"),
+			Nickname:     row.Get("nickname"),
+			Notes:        row.Get("notes"),
+			ServiceType:  row.Get("serviceType"),
+			PropertySqFt: sqft,
+			Latitude:     lat,
+			Longitude:    lng,
+		}
+		if validate {
+			// Undeliverable addresses are still imported: the partner's
+			// schedule stands, the office just needs to check the address.
+			// When the validator is down the rest of the file goes in as
+			// sent rather than failing the import.
+			res, err := addresses.Standardize(ctx, &stop)
+			switch {
+			case err != nil:
+				validate = false
+				out.Warnings = append(out.Warnings, fmt.Sprintf("line %d onwards: address validation unavailable, addresses imported as sent: %v", row.Line, err))
+			case !res.Deliverable():
+				out.Warnings = append(out.Warnings, fmt.Sprintf("line %d: %s at %q is undeliverable", row.Line, stop.CustomerName, stop.Address))
+			}
+		}
+		route.CustomerStops = append(route.CustomerStops, stop)
+		out.Imported++
+	}
+
+	for _, key := range keys {
+		route := routes[key]
+		if existing, err := repos.Routes.GetRoute(route.TechnicianID, route.ServiceDate); err == nil {
+			if route.ID == "" {
+				route.ID = existing.ID
+			}
+			route.Alerts = existing.Alerts
+		}
+		if route.ID == "" {
+			route.ID = route.TechnicianID + "-" + key.date
+		}
+		route.LastModified = time.Now()
+		if err := repos.Routes.SaveRoute(*route); err != nil {
+			return Applied{Errors: out.Errors}, fmt.Errorf("save route %s: %w", route.ID, err)
+		}
+		out.Routes = append(out.Routes, *route)
+	}
+	return out, nil
+}
+
+// parseWindow accepts either a time of day (combined with the service date)
+// or a full RFC3339 timestamp. Empty values are allowed.
+func parseWindow(date time.Time, value, layout string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected format %s or RFC3339", layout)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+}
+
+// parseCoordinate reads an optional coordinate in decimal degrees within
+// ±limit. Empty values are allowed.
+func parseCoordinate(value string, limit float64) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < -limit || f > limit {
+		return 0, fmt.Errorf("expected decimal degrees between -%g and %g", limit, limit)
+	}
+	return f, nil
+}
@@ -0,0 +1,267 @@
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/address"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/notify"
+)
+
+// RouteChecker vets routes after a file has been imported and returns
+// warnings to record on the run.
+type RouteChecker interface {
+	CheckRoutes(routes []models.Route) []string
+}
+
+// Service polls partner feeds and imports their files.
+type Service struct {
+	cfg     config.InboundConfig
+	store   Store
+	repos   repository.Repository
+	sources map[string]Source
+	checker RouteChecker
+	addrs   *address.Service
+	feed    *activity.Service
+	notes   *notify.Service
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewService wires an ingestion service. sources is keyed by source method;
+// a nil checker skips route checks. Imported routes are recorded in the
+// activity feed unless feed is nil, and their technicians notified unless
+// notes is nil. Stop addresses are standardized unless addrs is nil.
+func NewService(cfg config.InboundConfig, store Store, repos repository.Repository, sources map[string]Source, checker RouteChecker, addrs *address.Service, feed *activity.Service, notes *notify.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, sources: sources, checker: checker, addrs: addrs, feed: feed, notes: notes, logger: logger, now: time.Now}
+}
+
+// CreateFeed validates and stores a new feed.
+func (s *Service) CreateFeed(f Feed) (Feed, error) {
+	if err := f.Validate(); err != nil {
+		return Feed{}, err
+	}
+	now := s.now().UTC()
+	f.ID = uuid.NewString()
+	f.CreatedAt = now
+	f.UpdatedAt = now
+	f.LastPolledAt = nil
+	f.LastError = ""
+	return f, s.store.SaveFeed(f)
+}
+
+// UpdateFeed replaces a feed definition, keeping its poll status.
+func (s *Service) UpdateFeed(id string, f Feed) (Feed, error) {
+	existing, err := s.store.GetFeed(id)
+	if err != nil {
+		return Feed{}, err
+	}
+	if err := f.Validate(); err != nil {
+		return Feed{}, err
+	}
+	f.ID = id
+	f.CreatedAt = existing.CreatedAt
+	f.UpdatedAt = s.now().UTC()
+	f.LastPolledAt = existing.LastPolledAt
+	f.LastError = existing.LastError
+	return f, s.store.SaveFeed(f)
+}
+
+// Feed returns a single feed.
+func (s *Service) Feed(id string) (Feed, error) {
+	return s.store.GetFeed(id)
+}
+
+// Feeds lists every feed.
+func (s *Service) Feeds() ([]Feed, error) {
+	return s.store.ListFeeds()
+}
+
+// DeleteFeed removes a feed. Its run history is kept.
+func (s *Service) DeleteFeed(id string) error {
+	return s.store.DeleteFeed(id)
+}
+
+// Runs lists a feed's ingestion runs, newest first.
+func (s *Service) Runs(feedID string, limit int) ([]Run, error) {
+	if _, err := s.store.GetFeed(feedID); err != nil {
+		return nil, err
+	}
+	return s.store.ListRuns(feedID, limit)
+}
+
+// RunDetail returns a run including its row-level errors.
+func (s *Service) RunDetail(id string) (Run, error) {
+	return s.store.GetRun(id)
+}
+
+// PollNow polls a single feed immediately and returns the runs it produced.
+// progress, when set, is told how many of the feed's new files are done.
+func (s *Service) PollNow(ctx context.Context, feedID string, progress func(done, total int)) ([]Run, error) {
+	f, err := s.store.GetFeed(feedID)
+	if err != nil {
+		return nil, err
+	}
+	return s.poll(ctx, f, progress), nil
+}
+
+// Run polls every enabled feed each PollInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	interval := s.cfg.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			feeds, err := s.store.ListFeeds()
+			if err != nil {
+				s.logger.Error("list ingestion feeds", slog.Any("error", err))
+				continue
+			}
+			for _, f := range feeds {
+				if f.Enabled {
+					s.poll(ctx, f, nil)
+				}
+			}
+		}
+	}
+}
+
+// poll ingests every new file in the feed and records the poll outcome on
+// the feed itself so connection problems are visible without a run.
+func (s *Service) poll(ctx context.Context, f Feed, progress func(done, total int)) []Run {
+	var runs []Run
+	err := func() error {
+		source, ok := s.sources[f.Source.Method]
+		if !ok {
+			return fmt.Errorf("no source for method %q", f.Source.Method)
+		}
+		names, err := source.List(ctx, f)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(0, len(names))
+		}
+		for i, name := range names {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			run := s.ingest(ctx, source, f, name)
+			if err := s.store.SaveRun(run); err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			if err := source.Done(ctx, f, name); err != nil {
+				return fmt.Errorf("move %s to processed: %w", name, err)
+			}
+			if progress != nil {
+				progress(i+1, len(names))
+			}
+		}
+		return nil
+	}()
+
+	polled := s.now().UTC()
+	f.LastPolledAt = &polled
+	f.LastError = ""
+	if err != nil {
+		f.LastError = err.Error()
+		s.logger.Error("ingestion poll failed", slog.String("feed", f.ID), slog.Any("error", err))
+	}
+	if err := s.store.SaveFeed(f); err != nil {
+		s.logger.Error("save ingestion feed status", slog.String("feed", f.ID), slog.Any("error", err))
+	}
+	return runs
+}
+
+func (s *Service) ingest(ctx context.Context, source Source, f Feed, name string) Run {
+	run := Run{ID: uuid.NewString(), FeedID: f.ID, FileName: name, StartedAt: s.now().UTC()}
+	defer func() { run.FinishedAt = s.now().UTC() }()
+
+	data, err := source.Fetch(ctx, f, name)
+	if err != nil {
+		return s.failed(run, fmt.Errorf("fetch: %w", err))
+	}
+	sum := sha256.Sum256(data)
+	run.Checksum = hex.EncodeToString(sum[:])
+
+	if previous, seen, err := s.store.FindChecksum(f.ID, run.Checksum); err != nil {
+		return s.failed(run, err)
+	} else if seen {
+		run.Status = StatusDuplicate
+		run.DuplicateOf = previous.ID
+		s.logger.Warn("duplicate partner file skipped", slog.String("feed", f.ID), slog.String("file", name), slog.String("duplicateOf", previous.ID))
+		return run
+	}
+
+	rows, err := readRows(data, f.Parser)
+	if err != nil {
+		return s.failed(run, err)
+	}
+	run.Rows = len(rows)
+
+	applied, err := parsers[f.Parser.Kind].Apply(ctx, s.repos, s.addrs, rows, f.Parser)
+	if err != nil {
+		return s.failed(run, err)
+	}
+	run.Imported = applied.Imported
+	run.Rejected = run.Rows - applied.Imported
+	run.Errors = applied.Errors
+	run.Warnings = applied.Warnings
+	for _, route := range applied.Routes {
+		name := fmt.Sprintf("%s for %s on %s", route.ID, route.TechnicianID, route.ServiceDate.Format(time.DateOnly))
+		s.feed.Record(ctx, activity.Event{Type: activity.RouteImported, SubjectID: route.ID, SubjectName: name})
+		s.notes.Notify(ctx, notify.Notification{
+			TechnicianID: route.TechnicianID,
+			Category:     notify.CategoryRoute,
+			CollapseKey:  route.ID,
+			Title:        "Route for " + route.ServiceDate.Format("Mon Jan 2") + " updated",
+			Body:         fmt.Sprintf("%d stops", len(route.CustomerStops)),
+			Data:         map[string]string{"routeId": route.ID, "serviceDate": route.ServiceDate.Format(time.DateOnly)},
+		})
+	}
+	if s.checker != nil && len(applied.Routes) > 0 {
+		run.Warnings = append(run.Warnings, s.checker.CheckRoutes(applied.Routes)...)
+	}
+	if max := s.cfg.MaxRowErrors; max > 0 && len(run.Errors) > max {
+		run.Errors = run.Errors[:max]
+		run.ErrorsTruncated = true
+	}
+
+	run.Status = StatusProcessed
+	if run.Rejected > 0 {
+		run.Status = StatusPartial
+	}
+	s.logger.Info("partner file ingested", slog.String("feed", f.ID), slog.String("file", name), slog.Int("imported", run.Imported), slog.Int("rejected", run.Rejected), slog.Int("warnings", len(run.Warnings)))
+	return run
+}
+
+func (s *Service) failed(run Run, err error) Run {
+	run.Status = StatusFailed
+	run.Error = err.Error()
+	s.logger.Error("partner file ingestion failed", slog.String("feed", run.FeedID), slog.String("file", run.FileName), slog.Any("error", err))
+	return run
+}
@@ -0,0 +1,152 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+	"github.com/your-org/pestgenie-sdui/internal/sftp"
+)
+
+// Source lists and fetches partner files for a feed and moves them out of the
+// way once handled so they are not picked up again.
+type Source interface {
+	List(ctx context.Context, f Feed) ([]string, error)
+	Fetch(ctx context.Context, f Feed, name string) ([]byte, error)
+	// Done moves a handled file into the feed's processed area.
+	Done(ctx context.Context, f Feed, name string) error
+}
+
+// DirectorySource reads files from Root/<feed id>/<remote dir>, moving handled
+// files into a processed/ subdirectory.
+type DirectorySource struct {
+	Root string
+}
+
+func (d DirectorySource) dir(f Feed) string {
+	return filepath.Join(d.Root, f.ID, filepath.Clean("/"+f.Source.RemoteDir))
+}
+
+func (d DirectorySource) List(_ context.Context, f Feed) ([]string, error) {
+	entries, err := os.ReadDir(d.dir(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pattern := f.Source.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if ok, _ := path.Match(pattern, e.Name()); ok {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d DirectorySource) Fetch(_ context.Context, f Feed, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.dir(f), filepath.Base(name)))
+}
+
+func (d DirectorySource) Done(_ context.Context, f Feed, name string) error {
+	processed := filepath.Join(d.dir(f), "processed")
+	if err := os.MkdirAll(processed, 0o755); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(d.dir(f), filepath.Base(name)), filepath.Join(processed, filepath.Base(name)))
+}
+
+// SFTPSource picks files up from RemoteDir on the partner's SFTP server,
+// relative to the login directory, moving handled files into a processed/
+// subdirectory. Each call opens its own connection.
+type SFTPSource struct {
+	Secrets secret.Provider
+}
+
+func (s SFTPSource) dial(ctx context.Context, f Feed) (*sftp.Client, error) {
+	credentials, err := s.Secrets.Get(f.Source.CredentialsSecret)
+	if err != nil {
+		return nil, fmt.Errorf("resolve sftp credentials %q: %w", f.Source.CredentialsSecret, err)
+	}
+	return sftp.Dial(ctx, sftp.Config{
+		Host:        f.Source.Host,
+		Port:        f.Source.Port,
+		Username:    f.Source.Username,
+		HostKey:     f.Source.HostKey,
+		Credentials: credentials,
+	})
+}
+
+func (s SFTPSource) dir(f Feed) string {
+	return path.Clean(f.Source.RemoteDir)
+}
+
+func (s SFTPSource) List(ctx context.Context, f Feed) ([]string, error) {
+	c, err := s.dial(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	defer c.CloseWhenDone(ctx)()
+
+	entries, err := c.ReadDir(s.dir(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pattern := f.Source.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if ok, _ := path.Match(pattern, e.Name()); ok {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s SFTPSource) Fetch(ctx context.Context, f Feed, name string) ([]byte, error) {
+	c, err := s.dial(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	defer c.CloseWhenDone(ctx)()
+	return c.ReadFile(path.Join(s.dir(f), path.Base(name)))
+}
+
+func (s SFTPSource) Done(ctx context.Context, f Feed, name string) error {
+	c, err := s.dial(ctx, f)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	defer c.CloseWhenDone(ctx)()
+	processed := path.Join(s.dir(f), "processed")
+	if err := c.MkdirAll(processed); err != nil {
+		return err
+	}
+	return c.Rename(path.Join(s.dir(f), path.Base(name)), path.Join(processed, path.Base(name)))
+}
@@ -0,0 +1,47 @@
+package intents
+
+import (
+	"net/http"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the Siri vocabulary to the app.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an intents handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetVocabulary returns the authenticated technician's vocabulary. The
+// version is also the ETag, so an app checking for changes is answered
+// with 304 until there are some.
+func (h *Handler) GetVocabulary(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	v, err := h.service.Vocabulary(r.Context(), me)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to load vocabulary", slog.String("technician", me), slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to load vocabulary", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+
+	etag := `"` + v.Version + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	respond.JSON(w, http.StatusOK, v)
+}
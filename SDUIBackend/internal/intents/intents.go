@@ -0,0 +1,39 @@
+// Package intents serves the vocabulary the app registers with Siri and App
+// Intents, so technicians can say "Hey Siri, start my next PestGenie job at
+// the Miller barn". Siri only recognises names it has been taught, so each
+// technician gets the customers, stop nicknames and chemicals they are
+// likely to speak: those on their upcoming routes and on their truck.
+package intents
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Vocabulary is one technician's spoken terms. Each list is ordered by
+// importance, today's stops first, as Siri weighs earlier terms higher.
+type Vocabulary struct {
+	// Version identifies the terms. It changes whenever the technician's
+	// routes or chemicals change what is listed; the app re-registers its
+	// vocabulary when it does.
+	Version       string `json:"version"`
+	Customers     []Term `json:"customers"`
+	StopNicknames []Term `json:"stopNicknames"`
+	Chemicals     []Term `json:"chemicals"`
+}
+
+// Term is a phrase and the ID an intent resolves it to: the customer for
+// names and nicknames, the chemical for chemical names.
+type Term struct {
+	ID     string `json:"id"`
+	Phrase string `json:"phrase"`
+}
+
+// version hashes the terms of v.
+func (v Vocabulary) version() string {
+	v.Version = ""
+	body, _ := json.Marshal(v)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
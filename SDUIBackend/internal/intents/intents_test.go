@@ -0,0 +1,75 @@
+package intents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestVocabularyFollowsRoutes(t *testing.T) {
+	today := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	store := storememory.NewStore()
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: today.AddDate(0, 0, 1), CustomerStops: []domain.RouteStop{
+		{CustomerID: "c3", CustomerName: "Oak Street Clinic"},
+		{CustomerID: "c1", CustomerName: "acme  bakery"},
+	}})
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: today, CustomerStops: []domain.RouteStop{
+		{CustomerID: "c1", CustomerName: "Acme Bakery", Nickname: "acme bakery"},
+		{CustomerID: "c2", CustomerName: "Miller Farm", Nickname: "the Miller barn"},
+	}})
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: today.AddDate(0, 0, 5), CustomerStops: []domain.RouteStop{{CustomerID: "c9", CustomerName: "Too Far Out"}}})
+	_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: "chem-2", TechnicianID: "tech-1", Name: "Termidor SC"})
+	_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: "chem-1", TechnicianID: "tech-1", Name: "Advion Gel"})
+	_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: "chem-3", TechnicianID: "tech-2", Name: "Demand CS"})
+	svc := NewService(config.IntentsConfig{Days: 3, MaxTerms: 10}, repository.Repository{Routes: store, Sync: store})
+	svc.now = func() time.Time { return today.Add(9 * time.Hour) }
+
+	v, err := svc.Vocabulary(context.Background(), "tech-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := phrases(v.Customers); got != "Acme Bakery|Miller Farm|Oak Street Clinic" {
+		t.Errorf("expected today's customers first without duplicates, got %s", got)
+	}
+	if got := phrases(v.StopNicknames); got != "the Miller barn" || v.StopNicknames[0].ID != "c2" {
+		t.Errorf("expected nicknames resolving to their customer, got %+v", v.StopNicknames)
+	}
+	if got := phrases(v.Chemicals); got != "Advion Gel|Termidor SC" {
+		t.Errorf("expected the technician's chemicals, got %s", got)
+	}
+
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: today, CustomerStops: []domain.RouteStop{{CustomerID: "c4", CustomerName: "Harbor Deli"}}})
+	changed, _ := svc.Vocabulary(context.Background(), "tech-1")
+	if changed.Version == v.Version || changed.Customers[0].Phrase != "Harbor Deli" {
+		t.Errorf("expected a route change to regenerate the vocabulary, got %+v", changed)
+	}
+
+	h := NewHandler(svc)
+	req := httptest.NewRequest(http.MethodGet, "/v1/intents/vocabulary", nil)
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician}))
+	req.Header.Set("If-None-Match", `"`+changed.Version+`"`)
+	rec := httptest.NewRecorder()
+	h.GetVocabulary(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for an unchanged vocabulary, got %d", rec.Code)
+	}
+}
+
+func phrases(terms []Term) string {
+	out := ""
+	for i, term := range terms {
+		if i > 0 {
+			out += "|"
+		}
+		out += term.Phrase
+	}
+	return out
+}
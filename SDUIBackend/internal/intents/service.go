@@ -0,0 +1,94 @@
+package intents
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// Service builds vocabularies from routes and the chemicals synced from
+// technicians' trucks.
+type Service struct {
+	cfg   config.IntentsConfig
+	repos repository.Repository
+	now   func() time.Time
+}
+
+// NewService wires an intents service over the routes and chemicals in
+// repos.
+func NewService(cfg config.IntentsConfig, repos repository.Repository) *Service {
+	return &Service{cfg: cfg, repos: repos, now: time.Now}
+}
+
+// Vocabulary returns the technician's terms for the configured number of
+// days of routes from today. It is built on each call, so a route import
+// or edit shows up, with a new Version, on the next request. Reads are
+// scoped to the tenant ctx acts for.
+func (s *Service) Vocabulary(ctx context.Context, technicianID string) (Vocabulary, error) {
+	repos := tenant.Repository(ctx, s.repos)
+	v := Vocabulary{Customers: []Term{}, StopNicknames: []Term{}, Chemicals: []Term{}}
+	customers, nicknames := newTerms(s.cfg.MaxTerms), newTerms(s.cfg.MaxTerms)
+	today := startOfDay(s.now())
+	for d := 0; d < s.cfg.Days; d++ {
+		route, err := repos.Routes.GetRoute(technicianID, today.AddDate(0, 0, d))
+		if err != nil {
+			continue
+		}
+		for _, stop := range route.CustomerStops {
+			customers.add(stop.CustomerID, stop.CustomerName)
+			if !strings.EqualFold(strings.TrimSpace(stop.Nickname), strings.TrimSpace(stop.CustomerName)) {
+				nicknames.add(stop.CustomerID, stop.Nickname)
+			}
+		}
+	}
+
+	chemicals, err := repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return Vocabulary{}, err
+	}
+	sort.SliceStable(chemicals, func(i, j int) bool { return strings.ToLower(chemicals[i].Name) < strings.ToLower(chemicals[j].Name) })
+	products := newTerms(s.cfg.MaxTerms)
+	for _, c := range chemicals {
+		if c.TechnicianID == technicianID {
+			products.add(c.ID, c.Name)
+		}
+	}
+
+	v.Customers = append(v.Customers, customers.list...)
+	v.StopNicknames = append(v.StopNicknames, nicknames.list...)
+	v.Chemicals = append(v.Chemicals, products.list...)
+	v.Version = v.version()
+	return v, nil
+}
+
+// terms collects up to max distinct phrases in the order they are added.
+// Phrases differing only in case or spacing count as one.
+type terms struct {
+	max  int
+	seen map[string]bool
+	list []Term
+}
+
+func newTerms(max int) *terms {
+	return &terms{max: max, seen: make(map[string]bool)}
+}
+
+func (t *terms) add(id, phrase string) {
+	phrase = strings.Join(strings.Fields(phrase), " ")
+	key := strings.ToLower(phrase)
+	if phrase == "" || t.seen[key] || len(t.list) >= t.max {
+		return
+	}
+	t.seen[key] = true
+	t.list = append(t.list, Term{ID: id, Phrase: phrase})
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
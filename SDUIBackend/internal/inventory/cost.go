@@ -0,0 +1,331 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidReceipt wraps receipt validation failures.
+var ErrInvalidReceipt = errors.New("invalid receipt")
+
+// Receipt is stock a branch received from a supplier, at a unit cost.
+type Receipt struct {
+	ID       string  `json:"id"`
+	BranchID string  `json:"branchId"`
+	Product  Product `json:"product"`
+	Supplier string  `json:"supplier"`
+	Quantity float64 `json:"quantity"`
+	UnitCost float64 `json:"unitCost"`
+	// ReceivedAt defaults to when the receipt is recorded, RecordedAt.
+	ReceivedAt time.Time `json:"receivedAt"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Validate checks a receipt before it is stored.
+func (r Receipt) Validate() error {
+	var problems []string
+	if strings.TrimSpace(r.BranchID) == "" {
+		problems = append(problems, "branchId is required")
+	}
+	if r.Product.Key() == "" {
+		problems = append(problems, "product name or epaRegistration is required")
+	}
+	if strings.TrimSpace(r.Product.UnitOfMeasure) == "" {
+		problems = append(problems, "product unitOfMeasure is required")
+	}
+	if strings.TrimSpace(r.Supplier) == "" {
+		problems = append(problems, "supplier is required")
+	}
+	if !(r.Quantity > 0) || math.IsInf(r.Quantity, 0) {
+		problems = append(problems, "quantity must be > 0")
+	}
+	if r.UnitCost < 0 || math.IsNaN(r.UnitCost) || math.IsInf(r.UnitCost, 0) {
+		problems = append(problems, "unitCost must be a number >= 0")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidReceipt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// PricePoint is a product's unit cost from a time on, set by receiving
+// stock or by hand.
+type PricePoint struct {
+	Product  Product `json:"product"`
+	UnitCost float64 `json:"unitCost"`
+	Supplier string  `json:"supplier,omitempty"`
+	// ReceiptID is set on costs recorded by receiving.
+	ReceiptID string    `json:"receiptId,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// Receive records stock a branch received: the branch's ledger is credited
+// and the product's cost history gains the receipt's unit cost, which
+// becomes its price unless a later cost is already known.
+func (s *Service) Receive(r Receipt) (Receipt, error) {
+	if err := r.Validate(); err != nil {
+		return Receipt{}, err
+	}
+	now := s.now().UTC()
+	if r.ReceivedAt.IsZero() {
+		r.ReceivedAt = now
+	}
+	r.ReceivedAt = r.ReceivedAt.UTC()
+	if r.ReceivedAt.After(now) {
+		return Receipt{}, fmt.Errorf("%w: receivedAt is in the future", ErrInvalidReceipt)
+	}
+	if s.branches == nil {
+		return Receipt{}, fmt.Errorf("%w: branches are not available", ErrInvalidReceipt)
+	}
+	if _, err := s.branches.Branch(r.BranchID); err != nil {
+		return Receipt{}, fmt.Errorf("%w: branch %q not found", ErrInvalidReceipt, r.BranchID)
+	}
+	r.ID = uuid.NewString()
+	r.Supplier = strings.TrimSpace(r.Supplier)
+	r.RecordedAt = now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history, err := s.history(r.Product.Key())
+	if err != nil {
+		return Receipt{}, err
+	}
+	if err := s.store.SaveReceipt(r); err != nil {
+		return Receipt{}, err
+	}
+	if err := s.store.AppendEntries(Entry{ID: uuid.NewString(), ReceiptID: r.ID, Party: Branch(r.BranchID), Product: r.Product, Delta: r.Quantity, At: now}); err != nil {
+		return Receipt{}, err
+	}
+	if err := s.store.AppendPricePoint(PricePoint{Product: r.Product, UnitCost: r.UnitCost, Supplier: r.Supplier, ReceiptID: r.ID, At: r.ReceivedAt}); err != nil {
+		return Receipt{}, err
+	}
+	// A receipt entered late does not replace a newer cost.
+	if len(history) == 0 || !history[len(history)-1].At.After(r.ReceivedAt) {
+		if err := s.store.SavePrice(Price{Product: r.Product, UnitCost: r.UnitCost, Supplier: r.Supplier, UpdatedAt: now}); err != nil {
+			return Receipt{}, err
+		}
+	}
+	s.logger.Info("inventory received", slog.String("receipt", r.ID), slog.String("branch", r.BranchID), slog.String("product", r.Product.Key()),
+		slog.String("supplier", r.Supplier), slog.Float64("unitCost", r.UnitCost))
+	return r, nil
+}
+
+// Receipts returns receipts, most recently received first, optionally
+// only a branch's or a supplier's.
+func (s *Service) Receipts(branchID, supplier string) ([]Receipt, error) {
+	all, err := s.store.ListReceipts()
+	if err != nil {
+		return nil, err
+	}
+	out := []Receipt{}
+	for _, r := range all {
+		if (branchID == "" || r.BranchID == branchID) && (supplier == "" || strings.EqualFold(r.Supplier, supplier)) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// PriceHistory returns the costs recorded for the product with a key,
+// oldest first.
+func (s *Service) PriceHistory(key string) ([]PricePoint, error) {
+	history, err := s.history(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: no costs for %q", ErrNotFound, key)
+	}
+	return history, nil
+}
+
+func (s *Service) history(key string) ([]PricePoint, error) {
+	all, err := s.store.ListPricePoints()
+	if err != nil {
+		return nil, err
+	}
+	var out []PricePoint
+	for _, p := range all {
+		if p.Product.Key() == key {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// PriceBook is the price list with its history, to cost products as of a
+// time.
+type PriceBook struct {
+	prices  []Price
+	history map[string][]PricePoint // by product key, oldest first
+}
+
+// PriceBook loads the price list and its history.
+func (s *Service) PriceBook() (PriceBook, error) {
+	prices, err := s.store.ListPrices()
+	if err != nil {
+		return PriceBook{}, err
+	}
+	points, err := s.store.ListPricePoints()
+	if err != nil {
+		return PriceBook{}, err
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].At.Before(points[j].At) })
+	b := PriceBook{prices: prices, history: make(map[string][]PricePoint)}
+	for _, p := range points {
+		b.history[p.Product.Key()] = append(b.history[p.Product.Key()], p)
+	}
+	return b, nil
+}
+
+// Cost returns the unit cost of a product recorded in unit as of at: the
+// latest cost known by then or, for earlier times, the first one. Products
+// off the price list have none.
+func (b PriceBook) Cost(product Product, unit string, at time.Time) (float64, bool) {
+	for _, p := range b.prices {
+		if !p.Covers(product, unit) {
+			continue
+		}
+		cost, found := p.UnitCost, false
+		for _, pt := range b.history[p.Product.Key()] {
+			if !(Price{Product: pt.Product}).Covers(product, unit) {
+				continue
+			}
+			if pt.At.After(at) {
+				if !found {
+					cost = pt.UnitCost
+				}
+				break
+			}
+			cost, found = pt.UnitCost, true
+		}
+		return cost, true
+	}
+	return 0, false
+}
+
+// CostPeriod summarizes a month of receipts of a product from a supplier.
+type CostPeriod struct {
+	Month    string  `json:"month"`
+	Receipts int     `json:"receipts"`
+	Quantity float64 `json:"quantity"`
+	// AverageUnitCost is weighted by quantity.
+	AverageUnitCost float64 `json:"averageUnitCost"`
+	MinUnitCost     float64 `json:"minUnitCost"`
+	MaxUnitCost     float64 `json:"maxUnitCost"`
+}
+
+// CostTrend is how a product's cost from a supplier moved, by month.
+type CostTrend struct {
+	Product  Product      `json:"product"`
+	Supplier string       `json:"supplier"`
+	Periods  []CostPeriod `json:"periods"`
+	// Change is the relative change of the average unit cost from the first
+	// month with receipts to the last; absent with fewer than two.
+	Change *float64 `json:"change,omitempty"`
+}
+
+// TrendQuery selects the receipts of cost trends: those received in
+// [From, To), optionally of one product key and supplier.
+type TrendQuery struct {
+	From       time.Time
+	To         time.Time
+	ProductKey string
+	Supplier   string
+}
+
+// CostTrends returns monthly receipt costs per product, unit of measure and
+// supplier, by product name and supplier.
+func (s *Service) CostTrends(q TrendQuery) ([]CostTrend, error) {
+	receipts, err := s.store.ListReceipts()
+	if err != nil {
+		return nil, err
+	}
+	type key struct{ product, unit, supplier string }
+	trends := make(map[key]*CostTrend)
+	periods := make(map[key]map[string]*CostPeriod)
+	var costs = make(map[key]map[string]float64) // quantity × cost by month
+	for _, r := range receipts {
+		if r.ReceivedAt.Before(q.From) || !r.ReceivedAt.Before(q.To) ||
+			(q.ProductKey != "" && r.Product.Key() != q.ProductKey) || (q.Supplier != "" && !strings.EqualFold(r.Supplier, q.Supplier)) {
+			continue
+		}
+		k := key{r.Product.Key(), strings.ToLower(strings.TrimSpace(r.Product.UnitOfMeasure)), strings.ToLower(r.Supplier)}
+		if trends[k] == nil {
+			trends[k] = &CostTrend{Product: r.Product, Supplier: r.Supplier}
+			periods[k] = make(map[string]*CostPeriod)
+			costs[k] = make(map[string]float64)
+		}
+		month := r.ReceivedAt.Format("2006-01")
+		p := periods[k][month]
+		if p == nil {
+			p = &CostPeriod{Month: month, MinUnitCost: r.UnitCost, MaxUnitCost: r.UnitCost}
+			periods[k][month] = p
+		}
+		p.Receipts++
+		p.Quantity += r.Quantity
+		p.MinUnitCost, p.MaxUnitCost = math.Min(p.MinUnitCost, r.UnitCost), math.Max(p.MaxUnitCost, r.UnitCost)
+		costs[k][month] += r.Quantity * r.UnitCost
+	}
+
+	out := make([]CostTrend, 0, len(trends))
+	for k, t := range trends {
+		for month, p := range periods[k] {
+			p.AverageUnitCost = math.Round(costs[k][month]/p.Quantity*10000) / 10000
+			t.Periods = append(t.Periods, *p)
+		}
+		sort.Slice(t.Periods, func(i, j int) bool { return t.Periods[i].Month < t.Periods[j].Month })
+		if first, last := t.Periods[0], t.Periods[len(t.Periods)-1]; len(t.Periods) > 1 && first.AverageUnitCost > 0 {
+			change := math.Round((last.AverageUnitCost/first.AverageUnitCost-1)*1000) / 1000
+			t.Change = &change
+		}
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Product.Name != out[j].Product.Name {
+			return out[i].Product.Name < out[j].Product.Name
+		}
+		if out[i].Product.UnitOfMeasure != out[j].Product.UnitOfMeasure {
+			return out[i].Product.UnitOfMeasure < out[j].Product.UnitOfMeasure
+		}
+		return out[i].Supplier < out[j].Supplier
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) AppendPricePoint(p PricePoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = append(m.history, p)
+	return nil
+}
+
+func (m *MemoryStore) ListPricePoints() ([]PricePoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]PricePoint(nil), m.history...), nil
+}
+
+func (m *MemoryStore) SaveReceipt(r Receipt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.receipts = append(m.receipts, r)
+	return nil
+}
+
+func (m *MemoryStore) ListReceipts() ([]Receipt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := append([]Receipt(nil), m.receipts...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ReceivedAt.After(out[j].ReceivedAt) })
+	return out, nil
+}
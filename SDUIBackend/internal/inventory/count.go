@@ -0,0 +1,419 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// Count statuses.
+const (
+	CountOpen      = "open"      // waiting for the technician
+	CountSubmitted = "submitted" // variances flagged, waiting for a manager
+	CountApproved  = "approved"
+	CountRejected  = "rejected"
+)
+
+// AutoApprover is the reviewer recorded on counts approved on submission
+// because no variance was flagged.
+const AutoApprover = "auto"
+
+// ErrInvalidCount wraps count validation and state failures.
+var ErrInvalidCount = errors.New("invalid stock count")
+
+// Count is a cycle count task: a technician counts what is on their truck
+// and the counted quantities are compared with the stock on record.
+type Count struct {
+	ID           string      `json:"id"`
+	TechnicianID string      `json:"technicianId"`
+	BranchID     string      `json:"branchId,omitempty"`
+	Status       string      `json:"status"`
+	Lines        []CountLine `json:"lines"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	DueAt        time.Time   `json:"dueAt"`
+	SubmittedAt  *time.Time  `json:"submittedAt,omitempty"`
+	ReviewedAt   *time.Time  `json:"reviewedAt,omitempty"`
+	ReviewedBy   string      `json:"reviewedBy,omitempty"`
+	Reason       string      `json:"reason,omitempty"` // why it was rejected
+}
+
+// CountLine is one chemical on the truck. Expected is recorded when the
+// count is submitted, so technicians count blind.
+type CountLine struct {
+	ChemicalID string   `json:"chemicalId"`
+	Product    Product  `json:"product"`
+	Counted    *float64 `json:"counted,omitempty"`
+	Expected   float64  `json:"expected"`
+	Variance   float64  `json:"variance"` // counted - expected
+	Flagged    bool     `json:"flagged"`
+}
+
+// Flagged reports whether any line needs a manager's approval.
+func (c Count) Flagged() bool {
+	for _, l := range c.Lines {
+		if l.Flagged {
+			return true
+		}
+	}
+	return false
+}
+
+// CountedQuantity is a technician's count of one chemical.
+type CountedQuantity struct {
+	ChemicalID string  `json:"chemicalId"`
+	Quantity   float64 `json:"quantity"`
+}
+
+// CreateCount opens a count for a technician covering every chemical on
+// their truck. A technician has at most one unfinished count.
+func (s *Service) CreateCount(technicianID string) (Count, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tech, err := s.repos.Technicians.GetByID(technicianID)
+	if err != nil {
+		return Count{}, fmt.Errorf("%w: technician %q not found", ErrInvalidCount, technicianID)
+	}
+	counts, err := s.Counts(technicianID, "")
+	if err != nil {
+		return Count{}, err
+	}
+	for _, c := range counts {
+		if c.Status == CountOpen || c.Status == CountSubmitted {
+			return Count{}, fmt.Errorf("%w: technician %q already has %s count %s", ErrInvalidCount, technicianID, c.Status, c.ID)
+		}
+	}
+	return s.openCount(tech)
+}
+
+// GenerateDueCounts opens counts for technicians with stock on their truck
+// whose last count is older than the count interval. It returns how many
+// were opened.
+func (s *Service) GenerateDueCounts() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	techs, err := s.repos.Technicians.ListTechnicians()
+	if err != nil {
+		return 0, err
+	}
+	all, err := s.store.ListCounts()
+	if err != nil {
+		return 0, err
+	}
+	latest := make(map[string]Count)
+	for _, c := range all {
+		if prev, ok := latest[c.TechnicianID]; !ok || c.CreatedAt.After(prev.CreatedAt) {
+			latest[c.TechnicianID] = c
+		}
+	}
+	now := s.now().UTC()
+	opened := 0
+	for _, tech := range techs {
+		last, ok := latest[tech.ID]
+		if ok && (last.Status == CountOpen || last.Status == CountSubmitted || now.Before(last.CreatedAt.Add(s.cfg.CountInterval))) {
+			continue
+		}
+		chemicals, err := s.chemicals(tech.ID)
+		if err != nil {
+			return opened, err
+		}
+		if len(chemicals) == 0 {
+			continue
+		}
+		if _, err := s.openCount(tech); err != nil {
+			return opened, err
+		}
+		opened++
+	}
+	return opened, nil
+}
+
+// Run generates due counts every CountCheckInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.CountsEnabled {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.CountCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.GenerateDueCounts(); err != nil {
+				s.logger.Error("generate stock counts", slog.Any("error", err))
+			} else if n > 0 {
+				s.logger.Info("stock counts generated", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// SubmitCount records a technician's counted quantities, one for every line.
+// Variances against the stock on record are computed; a count with no
+// flagged variance is approved straight away, otherwise it waits for a
+// manager.
+func (s *Service) SubmitCount(technicianID, id string, counted []CountedQuantity) (Count, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.store.GetCount(id)
+	if err != nil {
+		return Count{}, err
+	}
+	if c.TechnicianID != technicianID {
+		return Count{}, ErrNotFound
+	}
+	if c.Status != CountOpen {
+		return Count{}, fmt.Errorf("%w: count is already %s", ErrInvalidCount, c.Status)
+	}
+	quantities := make(map[string]float64, len(counted))
+	var problems []string
+	for _, q := range counted {
+		if q.Quantity < 0 {
+			problems = append(problems, fmt.Sprintf("quantity for %s must be >= 0", q.ChemicalID))
+		}
+		quantities[q.ChemicalID] = q.Quantity
+	}
+	for _, l := range c.Lines {
+		if _, ok := quantities[l.ChemicalID]; !ok {
+			problems = append(problems, fmt.Sprintf("%s (%s) was not counted", l.ChemicalID, l.Product.Name))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return Count{}, fmt.Errorf("%w: %s", ErrInvalidCount, strings.Join(problems, "; "))
+	}
+
+	chemicals, err := s.chemicals(technicianID)
+	if err != nil {
+		return Count{}, err
+	}
+	onRecord := make(map[string]float64, len(chemicals))
+	for _, ch := range chemicals {
+		onRecord[ch.ID] = ch.QuantityInStock
+	}
+	for i := range c.Lines {
+		l := &c.Lines[i]
+		q := quantities[l.ChemicalID]
+		l.Counted = &q
+		l.Expected = onRecord[l.ChemicalID]
+		l.Variance = q - l.Expected
+		l.Flagged = math.Abs(l.Variance) > l.Expected*float64(s.cfg.CountVariancePercent)/100
+	}
+	now := s.now().UTC()
+	c.SubmittedAt = &now
+	c.Status = CountSubmitted
+	if !c.Flagged() {
+		if err := s.approve(&c, AutoApprover, now); err != nil {
+			return Count{}, err
+		}
+	}
+	if err := s.store.SaveCount(c); err != nil {
+		return Count{}, err
+	}
+	s.logger.Info("stock count submitted", slog.String("count", c.ID), slog.String("technician", technicianID), slog.String("status", c.Status))
+	return c, nil
+}
+
+// ApproveCount accepts a submitted count, adjusting the truck's stock by
+// each line's variance and posting the adjustments to the ledger.
+func (s *Service) ApproveCount(id, reviewer string) (Count, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.submitted(id)
+	if err != nil {
+		return Count{}, err
+	}
+	if err := s.approve(&c, reviewer, s.now().UTC()); err != nil {
+		return Count{}, err
+	}
+	if err := s.store.SaveCount(c); err != nil {
+		return Count{}, err
+	}
+	return c, nil
+}
+
+// RejectCount closes a submitted count without adjusting stock, e.g. so the
+// technician can be asked to recount.
+func (s *Service) RejectCount(id, reviewer, reason string) (Count, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.submitted(id)
+	if err != nil {
+		return Count{}, err
+	}
+	now := s.now().UTC()
+	c.Status = CountRejected
+	c.ReviewedAt = &now
+	c.ReviewedBy = reviewer
+	c.Reason = strings.TrimSpace(reason)
+	if err := s.store.SaveCount(c); err != nil {
+		return Count{}, err
+	}
+	s.logger.Info("stock count rejected", slog.String("count", c.ID), slog.String("reviewer", reviewer))
+	return c, nil
+}
+
+// Count returns a count.
+func (s *Service) Count(id string) (Count, error) {
+	return s.store.GetCount(id)
+}
+
+// Counts lists counts newest first, limited to a technician and status when
+// those are set.
+func (s *Service) Counts(technicianID, status string) ([]Count, error) {
+	all, err := s.store.ListCounts()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Count, 0, len(all))
+	for _, c := range all {
+		if (technicianID == "" || c.TechnicianID == technicianID) && (status == "" || c.Status == status) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// BranchCounts lists the counts of a branch's technicians.
+func (s *Service) BranchCounts(branchID, status string) ([]Count, error) {
+	all, err := s.Counts("", status)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Count, 0, len(all))
+	for _, c := range all {
+		if c.BranchID == branchID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// openCount saves a new open count of the technician's chemicals.
+func (s *Service) openCount(tech models.Technician) (Count, error) {
+	chemicals, err := s.chemicals(tech.ID)
+	if err != nil {
+		return Count{}, err
+	}
+	if len(chemicals) == 0 {
+		return Count{}, fmt.Errorf("%w: technician %q has no chemicals on their truck", ErrInvalidCount, tech.ID)
+	}
+	now := s.now().UTC()
+	c := Count{
+		ID:           uuid.NewString(),
+		TechnicianID: tech.ID,
+		BranchID:     tech.BranchID,
+		Status:       CountOpen,
+		Lines:        make([]CountLine, 0, len(chemicals)),
+		CreatedAt:    now,
+		DueAt:        now.Add(s.cfg.CountDueAfter),
+	}
+	for _, ch := range chemicals {
+		c.Lines = append(c.Lines, CountLine{
+			ChemicalID: ch.ID,
+			Product:    Product{Name: ch.Name, EPARegistration: ch.EPARegistration, UnitOfMeasure: ch.UnitOfMeasure},
+		})
+	}
+	sort.Slice(c.Lines, func(i, j int) bool { return c.Lines[i].Product.Name < c.Lines[j].Product.Name })
+	if err := s.store.SaveCount(c); err != nil {
+		return Count{}, err
+	}
+	s.logger.Info("stock count opened", slog.String("count", c.ID), slog.String("technician", tech.ID))
+	return c, nil
+}
+
+// approve applies each line's variance to the technician's chemical record
+// and the ledger. Variances are applied to the current stock rather than
+// overwriting it, so transfers completed since the count was submitted are
+// kept.
+func (s *Service) approve(c *Count, reviewer string, now time.Time) error {
+	var entries []Entry
+	for _, l := range c.Lines {
+		if l.Variance == 0 {
+			continue
+		}
+		ch, err := s.chemical(c.TechnicianID, l.ChemicalID)
+		if err != nil {
+			return err
+		}
+		ch.QuantityInStock = math.Max(ch.QuantityInStock+l.Variance, 0)
+		ch.LastModified = now
+		if err := s.repos.Sync.SaveChemicalUpload(ch); err != nil {
+			return err
+		}
+		entries = append(entries, Entry{ID: uuid.NewString(), CountID: c.ID, Party: Technician(c.TechnicianID), Product: l.Product, Delta: l.Variance, At: now})
+	}
+	if len(entries) > 0 {
+		if err := s.store.AppendEntries(entries...); err != nil {
+			return err
+		}
+	}
+	c.Status = CountApproved
+	c.ReviewedAt = &now
+	c.ReviewedBy = reviewer
+	s.logger.Info("stock count approved", slog.String("count", c.ID), slog.String("reviewer", reviewer), slog.Int("adjustments", len(entries)))
+	return nil
+}
+
+// submitted loads a count waiting for review.
+func (s *Service) submitted(id string) (Count, error) {
+	c, err := s.store.GetCount(id)
+	if err != nil {
+		return Count{}, err
+	}
+	if c.Status != CountSubmitted {
+		return Count{}, fmt.Errorf("%w: count is %s, not waiting for review", ErrInvalidCount, c.Status)
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) SaveCount(c Count) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c.Lines = append([]CountLine(nil), c.Lines...)
+	m.counts[c.ID] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCount(id string) (Count, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.counts[id]
+	if !ok {
+		return Count{}, ErrNotFound
+	}
+	c.Lines = append([]CountLine(nil), c.Lines...)
+	return c, nil
+}
+
+func (m *MemoryStore) ListCounts() ([]Count, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Count, 0, len(m.counts))
+	for _, c := range m.counts {
+		c.Lines = append([]CountLine(nil), c.Lines...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
@@ -0,0 +1,491 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes transfers and stock counts to technicians in the app and
+// to branch staff in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an inventory handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints, which act for branches.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/transfers", h.ListTransfers)
+	r.Get("/transfers/{transferId}", h.GetTransfer)
+	r.Post("/transfers/{transferId}/confirm", h.ConfirmBranchTransfer)
+	r.Post("/transfers/{transferId}/decline", h.DeclineBranchTransfer)
+	r.Post("/restocks", h.Restock)
+	r.Get("/ledger", h.GetLedger)
+	r.Get("/counts", h.ListCounts)
+	r.Post("/counts", h.CreateCount)
+	r.Post("/counts/generate", h.GenerateCounts)
+	r.Get("/counts/{countId}", h.GetCount)
+	r.Post("/counts/{countId}/approve", h.ApproveCount)
+	r.Post("/counts/{countId}/reject", h.RejectCount)
+	r.Get("/prices", h.ListPrices)
+	r.Put("/prices", h.SetPrice)
+	r.Delete("/prices/{key}", h.DeletePrice)
+	r.Get("/prices/{key}/history", h.GetPriceHistory)
+	r.Get("/receipts", h.ListReceipts)
+	r.Post("/receipts", h.Receive)
+	r.Get("/costs/trends", h.GetCostTrends)
+}
+
+// ListMyTransfers returns the authenticated technician's transfers,
+// optionally filtered by ?status=.
+func (h *Handler) ListMyTransfers(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	transfers, err := h.service.Transfers(me, r.URL.Query().Get("status"))
+	if err != nil {
+		h.fail(w, r, "failed to list transfers", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"transfers": transfers})
+}
+
+// SendTransfer hands stock from the authenticated technician's truck to
+// another technician or a branch.
+func (h *Handler) SendTransfer(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	var payload SendRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	t, err := h.service.Send(me.ID, payload)
+	if err != nil {
+		h.fail(w, r, "failed to send transfer", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, t)
+}
+
+// ConfirmTransfer records the authenticated technician's confirmation.
+func (h *Handler) ConfirmTransfer(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.service.Confirm(chi.URLParam(r, "transferId"), me)
+	if err != nil {
+		h.fail(w, r, "failed to confirm transfer", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// DeclineTransfer rejects an incoming transfer or cancels an outgoing one for
+// the authenticated technician.
+func (h *Handler) DeclineTransfer(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	h.decline(w, r, me)
+}
+
+// ListTransfers returns transfers, optionally for one ?technicianId= or
+// ?branchId= and ?status=.
+func (h *Handler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var party Party
+	switch {
+	case q.Get("technicianId") != "":
+		party = Technician(q.Get("technicianId"))
+	case q.Get("branchId") != "":
+		party = Branch(q.Get("branchId"))
+	}
+	transfers, err := h.service.Transfers(party, q.Get("status"))
+	if err != nil {
+		h.fail(w, r, "failed to list transfers", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"transfers": transfers})
+}
+
+// GetTransfer returns a transfer.
+func (h *Handler) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	t, err := h.service.Transfer(chi.URLParam(r, "transferId"))
+	if err != nil {
+		h.fail(w, r, "failed to load transfer", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// ConfirmBranchTransfer confirms the branch side of a transfer, e.g. a
+// technician's return arriving at the branch.
+func (h *Handler) ConfirmBranchTransfer(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.branchParty(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.service.Confirm(chi.URLParam(r, "transferId"), b)
+	if err != nil {
+		h.fail(w, r, "failed to confirm transfer", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// DeclineBranchTransfer rejects or cancels the branch side of a transfer.
+func (h *Handler) DeclineBranchTransfer(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.branchParty(w, r)
+	if !ok {
+		return
+	}
+	h.decline(w, r, b)
+}
+
+// Restock loads stock from a branch onto a technician's truck.
+func (h *Handler) Restock(w http.ResponseWriter, r *http.Request) {
+	var payload RestockRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	t, err := h.service.Restock(payload)
+	if err != nil {
+		h.fail(w, r, "failed to restock", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, t)
+}
+
+// GetLedger returns the ledger for ?technicianId= or ?branchId=.
+func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var party Party
+	switch {
+	case q.Get("technicianId") != "":
+		party = Technician(q.Get("technicianId"))
+	case q.Get("branchId") != "":
+		party = Branch(q.Get("branchId"))
+	default:
+		respond.Error(w, http.StatusBadRequest, "missing party", "technicianId or branchId is required")
+		return
+	}
+	st, err := h.service.Ledger(party)
+	if err != nil {
+		h.fail(w, r, "failed to load ledger", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, st)
+}
+
+// ListPrices returns the price list.
+func (h *Handler) ListPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.service.Prices()
+	if err != nil {
+		h.fail(w, r, "failed to list prices", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"prices": prices})
+}
+
+// SetPrice stores the price of the product in the body.
+func (h *Handler) SetPrice(w http.ResponseWriter, r *http.Request) {
+	var payload Price
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	p, err := h.service.SetPrice(payload)
+	if err != nil {
+		h.fail(w, r, "failed to set price", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, p)
+}
+
+// DeletePrice removes the price of the product with {key}.
+func (h *Handler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePrice(chi.URLParam(r, "key")); err != nil {
+		h.fail(w, r, "failed to delete price", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPriceHistory returns the costs recorded for the product with {key},
+// oldest first.
+func (h *Handler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.PriceHistory(chi.URLParam(r, "key"))
+	if err != nil {
+		h.fail(w, r, "failed to load price history", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"history": history})
+}
+
+// ListReceipts returns stock receipts, optionally only ?branchId='s or
+// ?supplier='s.
+func (h *Handler) ListReceipts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	receipts, err := h.service.Receipts(q.Get("branchId"), q.Get("supplier"))
+	if err != nil {
+		h.fail(w, r, "failed to list receipts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"receipts": receipts})
+}
+
+// Receive records stock a branch received from a supplier.
+func (h *Handler) Receive(w http.ResponseWriter, r *http.Request) {
+	var payload Receipt
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	receipt, err := h.service.Receive(payload)
+	if err != nil {
+		h.fail(w, r, "failed to receive stock", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, receipt)
+}
+
+// GetCostTrends returns monthly receipt costs per product and supplier
+// from ?from=YYYY-MM to ?to=YYYY-MM inclusive, the last 12 months by
+// default, optionally only ?product='s (a product key) or ?supplier='s.
+func (h *Handler) GetCostTrends(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, -11, 0)
+	for _, m := range []struct {
+		raw string
+		t   *time.Time
+	}{{q.Get("from"), &from}, {q.Get("to"), &to}} {
+		if m.raw == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01", m.raw)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid month", "expected YYYY-MM")
+			return
+		}
+		*m.t = t
+	}
+	if to.Before(from) {
+		respond.Error(w, http.StatusBadRequest, "invalid month", "from must not be after to")
+		return
+	}
+	trends, err := h.service.CostTrends(TrendQuery{From: from, To: to.AddDate(0, 1, 0), ProductKey: q.Get("product"), Supplier: q.Get("supplier")})
+	if err != nil {
+		h.fail(w, r, "failed to compute cost trends", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"from": from.Format("2006-01"), "to": to.Format("2006-01"), "trends": trends})
+}
+
+// ListMyCounts returns the authenticated technician's stock counts,
+// optionally filtered by ?status=.
+func (h *Handler) ListMyCounts(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	counts, err := h.service.Counts(me.ID, r.URL.Query().Get("status"))
+	if err != nil {
+		h.fail(w, r, "failed to list counts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"counts": counts})
+}
+
+// SubmitCount records the authenticated technician's counted quantities.
+func (h *Handler) SubmitCount(w http.ResponseWriter, r *http.Request) {
+	me, ok := technician(w, r)
+	if !ok {
+		return
+	}
+	var payload struct {
+		Lines []CountedQuantity `json:"lines"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	c, err := h.service.SubmitCount(me.ID, chi.URLParam(r, "countId"), payload.Lines)
+	if err != nil {
+		h.fail(w, r, "failed to submit count", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// ListCounts returns stock counts, optionally for one ?technicianId= or
+// ?branchId= and ?status=.
+func (h *Handler) ListCounts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var (
+		counts []Count
+		err    error
+	)
+	if q.Get("branchId") != "" {
+		counts, err = h.service.BranchCounts(q.Get("branchId"), q.Get("status"))
+	} else {
+		counts, err = h.service.Counts(q.Get("technicianId"), q.Get("status"))
+	}
+	if err != nil {
+		h.fail(w, r, "failed to list counts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"counts": counts})
+}
+
+// CreateCount opens a count for a technician outside the regular schedule.
+func (h *Handler) CreateCount(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TechnicianID string `json:"technicianId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	c, err := h.service.CreateCount(payload.TechnicianID)
+	if err != nil {
+		h.fail(w, r, "failed to create count", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, c)
+}
+
+// GenerateCounts opens every count that is due now.
+func (h *Handler) GenerateCounts(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.GenerateDueCounts()
+	if err != nil {
+		h.fail(w, r, "failed to generate counts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"opened": n})
+}
+
+// GetCount returns a stock count.
+func (h *Handler) GetCount(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Count(chi.URLParam(r, "countId"))
+	if err != nil {
+		h.fail(w, r, "failed to load count", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// ApproveCount accepts a submitted count's variances.
+func (h *Handler) ApproveCount(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.ApproveCount(chi.URLParam(r, "countId"), reviewer(r))
+	if err != nil {
+		h.fail(w, r, "failed to approve count", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// RejectCount closes a submitted count without adjusting stock.
+func (h *Handler) RejectCount(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+			return
+		}
+	}
+	c, err := h.service.RejectCount(chi.URLParam(r, "countId"), reviewer(r), payload.Reason)
+	if err != nil {
+		h.fail(w, r, "failed to reject count", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// reviewer names the staff member approving or rejecting a count.
+func reviewer(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		return id.Subject
+	}
+	return "admin"
+}
+
+func (h *Handler) decline(w http.ResponseWriter, r *http.Request, by Party) {
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+			return
+		}
+	}
+	t, err := h.service.Decline(chi.URLParam(r, "transferId"), by, payload.Reason)
+	if err != nil {
+		h.fail(w, r, "failed to decline transfer", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// branchParty returns the branch side of the transfer in the URL.
+func (h *Handler) branchParty(w http.ResponseWriter, r *http.Request) (Party, bool) {
+	t, err := h.service.Transfer(chi.URLParam(r, "transferId"))
+	if err != nil {
+		h.fail(w, r, "failed to load transfer", err)
+		return Party{}, false
+	}
+	for _, p := range []Party{t.From, t.To} {
+		if p.Kind == PartyBranch {
+			return p, true
+		}
+	}
+	respond.Error(w, http.StatusBadRequest, "not a branch transfer", "only the technicians involved can confirm this transfer")
+	return Party{}, false
+}
+
+// technician returns the authenticated technician's party, writing a 400
+// when the request does not identify one.
+func technician(w http.ResponseWriter, r *http.Request) (Party, bool) {
+	id := auth.TechnicianID(r)
+	if id == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return Party{}, false
+	}
+	return Technician(id), true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidTransfer), errors.Is(err, ErrInvalidCount), errors.Is(err, ErrInvalidPrice), errors.Is(err, ErrInvalidReceipt):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,272 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newTestService(t *testing.T) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	branches := branch.NewService(branch.NewMemoryStore(), repos, nil)
+	if _, err := branches.Save("north", branch.Branch{Name: "North", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+	store.AddTechnician(models.Technician{ID: "tech-a", BranchID: "north"})
+	store.AddTechnician(models.Technician{ID: "tech-b", BranchID: "north"})
+	store.AddTechnician(models.Technician{ID: "tech-c"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "a-termidor", TechnicianID: "tech-a", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "gal", QuantityInStock: 5, ActiveIngredient: "fipronil"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "b-termidor", TechnicianID: "tech-b", Name: "Termidor", EPARegistration: "7969-210", UnitOfMeasure: "gal", QuantityInStock: 1})
+	cfg := config.InventoryConfig{CountsEnabled: true, CountInterval: 7 * 24 * time.Hour, CountCheckInterval: time.Hour, CountDueAfter: 48 * time.Hour, CountVariancePercent: 5}
+	svc := NewService(cfg, NewMemoryStore(), repos, branches, nil)
+	svc.now = func() time.Time { return time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC) }
+	return svc, store
+}
+
+func stock(t *testing.T, store *storememory.Store, technicianID string) map[string]float64 {
+	t.Helper()
+	chemicals, _ := store.ListChemicalUpdatesSince(time.Time{})
+	out := make(map[string]float64)
+	for _, c := range chemicals {
+		if c.TechnicianID == technicianID {
+			out[c.ID] = c.QuantityInStock
+		}
+	}
+	return out
+}
+
+func TestTransferBetweenTechniciansNeedsBothConfirmations(t *testing.T) {
+	svc, store := newTestService(t)
+
+	if _, err := svc.Send("tech-a", SendRequest{To: Technician("tech-b"), ChemicalID: "a-termidor", Quantity: 6}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected sending more than is on the truck to fail, got %v", err)
+	}
+	tr, err := svc.Send("tech-a", SendRequest{To: Technician("tech-b"), ChemicalID: "a-termidor", Quantity: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Send("tech-a", SendRequest{To: Technician("tech-c"), ChemicalID: "a-termidor", Quantity: 4}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected stock promised to a pending transfer to be unavailable, got %v", err)
+	}
+	if got := stock(t, store, "tech-a"); got["a-termidor"] != 5 {
+		t.Fatalf("stock must not move before the receiver confirms, got %v", got)
+	}
+
+	// The sender confirming again changes nothing; outsiders cannot see it.
+	if again, err := svc.Confirm(tr.ID, Technician("tech-a")); err != nil || again.Status != StatusPending {
+		t.Fatalf("expected a repeat sender confirmation to be harmless, got %+v (%v)", again, err)
+	}
+	if _, err := svc.Confirm(tr.ID, Technician("tech-c")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected outsiders to get not found, got %v", err)
+	}
+
+	done, err := svc.Confirm(tr.ID, Technician("tech-b"))
+	if err != nil || done.Status != StatusCompleted || done.ReceiverChemicalID != "b-termidor" {
+		t.Fatalf("expected completion into tech-b's record, got %+v (%v)", done, err)
+	}
+	if a, b := stock(t, store, "tech-a"), stock(t, store, "tech-b"); a["a-termidor"] != 3 || b["b-termidor"] != 3 {
+		t.Fatalf("unexpected stock after transfer a=%v b=%v", a, b)
+	}
+	if _, err := svc.Decline(tr.ID, Technician("tech-b"), ""); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected a completed transfer to stay closed, got %v", err)
+	}
+
+	ledger, err := svc.Ledger(Technician("tech-a"))
+	if err != nil || len(ledger.Entries) != 1 || len(ledger.Balances) != 1 || ledger.Balances[0].Quantity != -2 {
+		t.Fatalf("unexpected ledger %+v (%v)", ledger, err)
+	}
+}
+
+func TestRestockAndReturn(t *testing.T) {
+	svc, store := newTestService(t)
+	product := Product{Name: "Talstar P", EPARegistration: "279-3206", UnitOfMeasure: "gal"}
+
+	if _, err := svc.Restock(RestockRequest{BranchID: "north", TechnicianID: "tech-c", Product: product, Quantity: 1}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected restocking another branch's technician to fail, got %v", err)
+	}
+	tr, err := svc.Restock(RestockRequest{BranchID: "north", TechnicianID: "tech-a", Product: product, Quantity: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done, err := svc.Confirm(tr.ID, Technician("tech-a"))
+	if err != nil || done.Status != StatusCompleted {
+		t.Fatalf("expected the restock to complete, got %+v (%v)", done, err)
+	}
+	if got := stock(t, store, "tech-a"); got[done.ReceiverChemicalID] != 4 || len(got) != 2 {
+		t.Fatalf("expected a new chemical record on the truck, got %v", got)
+	}
+
+	ret, err := svc.Send("tech-a", SendRequest{To: Branch("north"), ChemicalID: done.ReceiverChemicalID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancelled, err := svc.Decline(ret.ID, Technician("tech-a"), "miscounted")
+	if err != nil || cancelled.Status != StatusCancelled {
+		t.Fatalf("expected the sender to cancel, got %+v (%v)", cancelled, err)
+	}
+
+	branchLedger, _ := svc.Ledger(Branch("north"))
+	if len(branchLedger.Balances) != 1 || branchLedger.Balances[0].Quantity != -4 {
+		t.Fatalf("unexpected branch ledger %+v", branchLedger)
+	}
+}
+
+func TestStockCountFlagsVariancesForApproval(t *testing.T) {
+	svc, store := newTestService(t)
+
+	opened, err := svc.GenerateDueCounts()
+	if err != nil || opened != 2 {
+		t.Fatalf("expected counts for the two technicians with stock, got %d (%v)", opened, err)
+	}
+	if again, _ := svc.GenerateDueCounts(); again != 0 {
+		t.Fatalf("expected no new counts while counts are open, got %d", again)
+	}
+	if _, err := svc.CreateCount("tech-a"); !errors.Is(err, ErrInvalidCount) {
+		t.Fatalf("expected a second open count rejected, got %v", err)
+	}
+	open, _ := svc.Counts("tech-a", CountOpen)
+	if len(open) != 1 || len(open[0].Lines) != 1 || open[0].BranchID != "north" {
+		t.Fatalf("unexpected open counts %+v", open)
+	}
+	c := open[0]
+
+	if _, err := svc.SubmitCount("tech-b", c.ID, []CountedQuantity{{ChemicalID: "a-termidor", Quantity: 4}}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected another technician's count to be hidden, got %v", err)
+	}
+	if _, err := svc.SubmitCount("tech-a", c.ID, nil); !errors.Is(err, ErrInvalidCount) {
+		t.Fatalf("expected uncounted lines rejected, got %v", err)
+	}
+	submitted, err := svc.SubmitCount("tech-a", c.ID, []CountedQuantity{{ChemicalID: "a-termidor", Quantity: 4}})
+	if err != nil || submitted.Status != CountSubmitted || !submitted.Lines[0].Flagged || submitted.Lines[0].Variance != -1 {
+		t.Fatalf("expected a flagged variance waiting for approval, got %+v (%v)", submitted, err)
+	}
+	if got := stock(t, store, "tech-a"); got["a-termidor"] != 5 {
+		t.Fatalf("stock must not change before approval, got %v", got)
+	}
+
+	approved, err := svc.ApproveCount(c.ID, "mgr")
+	if err != nil || approved.Status != CountApproved || approved.ReviewedBy != "mgr" {
+		t.Fatalf("unexpected approval %+v (%v)", approved, err)
+	}
+	if got := stock(t, store, "tech-a"); got["a-termidor"] != 4 {
+		t.Fatalf("expected the variance applied, got %v", got)
+	}
+	ledger, _ := svc.Ledger(Technician("tech-a"))
+	if len(ledger.Entries) != 1 || ledger.Entries[0].CountID != c.ID || ledger.Balances[0].Quantity != -1 {
+		t.Fatalf("expected an adjustment in the ledger, got %+v", ledger)
+	}
+	if _, err := svc.RejectCount(c.ID, "mgr", ""); !errors.Is(err, ErrInvalidCount) {
+		t.Fatalf("expected an approved count to stay closed, got %v", err)
+	}
+
+	// A count that matches the records is approved on submission.
+	bc, _ := svc.Counts("tech-b", CountOpen)
+	matched, err := svc.SubmitCount("tech-b", bc[0].ID, []CountedQuantity{{ChemicalID: "b-termidor", Quantity: 1}})
+	if err != nil || matched.Status != CountApproved || matched.ReviewedBy != AutoApprover {
+		t.Fatalf("expected an exact count auto-approved, got %+v (%v)", matched, err)
+	}
+
+	svc.now = func() time.Time { return time.Date(2026, 4, 9, 10, 0, 0, 0, time.UTC) }
+	if due, _ := svc.GenerateDueCounts(); due != 2 {
+		t.Fatalf("expected new counts after the interval, got %d", due)
+	}
+}
+
+func TestTransfersMoveLots(t *testing.T) {
+	svc, store := newTestService(t)
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "a-termidor", TechnicianID: "tech-a", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "gal", QuantityInStock: 5,
+		Lots: []models.ChemicalLot{{Number: "L1", Quantity: 3}, {Number: "L2", Quantity: 2}}})
+
+	if _, err := svc.Send("tech-a", SendRequest{To: Technician("tech-c"), ChemicalID: "a-termidor", Quantity: 1}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected a lot to be required, got %v", err)
+	}
+	if _, err := svc.Send("tech-a", SendRequest{To: Technician("tech-c"), ChemicalID: "a-termidor", LotNumber: "L2", Quantity: 3}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("expected more than the lot holds rejected, got %v", err)
+	}
+	tr, err := svc.Send("tech-a", SendRequest{To: Technician("tech-c"), ChemicalID: "a-termidor", LotNumber: "L2", Quantity: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done, err := svc.Confirm(tr.ID, Technician("tech-c"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chemicals, _ := store.ListChemicalUpdatesSince(time.Time{})
+	lots := make(map[string][]models.ChemicalLot)
+	for _, c := range chemicals {
+		lots[c.ID] = c.Lots
+	}
+	if got := lots["a-termidor"]; len(got) != 2 || got[0].Quantity != 3 || got[1].Quantity != 0 {
+		t.Fatalf("expected lot L2 drawn down on the sender, got %+v", got)
+	}
+	if got := lots[done.ReceiverChemicalID]; len(got) != 1 || got[0].Number != "L2" || got[0].Quantity != 2 {
+		t.Fatalf("expected lot L2 on the receiver, got %+v", got)
+	}
+}
+
+func TestReceivingRecordsCostHistoryAndTrends(t *testing.T) {
+	svc, _ := newTestService(t)
+	termidor := Product{Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "gal"}
+
+	if _, err := svc.Receive(Receipt{BranchID: "south", Product: termidor, Supplier: "Univar", Quantity: 4, UnitCost: 100}); !errors.Is(err, ErrInvalidReceipt) {
+		t.Fatalf("expected an unknown branch rejected, got %v", err)
+	}
+	for _, r := range []Receipt{
+		{BranchID: "north", Product: termidor, Supplier: "Univar", Quantity: 4, UnitCost: 100, ReceivedAt: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{BranchID: "north", Product: termidor, Supplier: "Univar", Quantity: 4, UnitCost: 110, ReceivedAt: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{BranchID: "north", Product: termidor, Supplier: "Univar", Quantity: 12, UnitCost: 130, ReceivedAt: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+		// Entered late: it must not replace March's cost.
+		{BranchID: "north", Product: termidor, Supplier: "Target Specialty", Quantity: 2, UnitCost: 90, ReceivedAt: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+	} {
+		if _, err := svc.Receive(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	st, _ := svc.Ledger(Branch("north"))
+	if len(st.Balances) != 1 || st.Balances[0].Quantity != 22 {
+		t.Fatalf("expected receipts credited to the branch, got %+v", st.Balances)
+	}
+	prices, _ := svc.Prices()
+	if len(prices) != 1 || prices[0].UnitCost != 130 || prices[0].Supplier != "Univar" {
+		t.Fatalf("expected the latest receipt's cost, got %+v", prices)
+	}
+	history, _ := svc.PriceHistory("7969-210")
+	if len(history) != 4 || history[0].UnitCost != 90 {
+		t.Fatalf("expected the history oldest first, got %+v", history)
+	}
+
+	book, _ := svc.PriceBook()
+	for at, want := range map[time.Time]float64{
+		time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC): 90,
+		time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC): 110,
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC):  130,
+	} {
+		if got, ok := book.Cost(termidor, "GAL", at); !ok || got != want {
+			t.Errorf("expected %v on %s, got %v", want, at.Format("2006-01-02"), got)
+		}
+	}
+	if _, ok := book.Cost(termidor, "oz", time.Now()); ok {
+		t.Error("expected no cost in another unit")
+	}
+
+	trends, _ := svc.CostTrends(TrendQuery{From: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)})
+	if len(trends) != 1 || trends[0].Supplier != "Univar" || len(trends[0].Periods) != 2 {
+		t.Fatalf("unexpected trends %+v", trends)
+	}
+	if march := trends[0].Periods[1]; march.AverageUnitCost != 125 || march.MinUnitCost != 110 || march.MaxUnitCost != 130 {
+		t.Errorf("expected a quantity-weighted March average, got %+v", march)
+	}
+	if c := trends[0].Change; c == nil || *c != 0.25 {
+		t.Errorf("expected a 25%% rise, got %v", c)
+	}
+}
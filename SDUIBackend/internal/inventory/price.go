@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+)
+
+// ErrInvalidPrice wraps price validation failures.
+var ErrInvalidPrice = errors.New("invalid price")
+
+// Price is what the company pays for a product, per its unit of measure.
+// Chemicals applied on jobs are costed with it. Receiving stock updates it.
+type Price struct {
+	Product  Product `json:"product"`
+	UnitCost float64 `json:"unitCost"`
+	// Supplier is who the last receipt setting the price came from.
+	Supplier  string    `json:"supplier,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks a price before it is stored.
+func (p Price) Validate() error {
+	var problems []string
+	if p.Product.Key() == "" {
+		problems = append(problems, "product name or epaRegistration is required")
+	}
+	if strings.TrimSpace(p.Product.UnitOfMeasure) == "" {
+		problems = append(problems, "product unitOfMeasure is required")
+	}
+	if p.UnitCost < 0 || math.IsNaN(p.UnitCost) || math.IsInf(p.UnitCost, 0) {
+		problems = append(problems, "unitCost must be a number >= 0")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPrice, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Covers reports whether the price applies to quantities of a product
+// recorded in unit: the same product, priced in the same unit.
+func (p Price) Covers(product Product, unit string) bool {
+	return p.Product.Key() == product.Key() && strings.EqualFold(strings.TrimSpace(p.Product.UnitOfMeasure), strings.TrimSpace(unit))
+}
+
+// SetPrice stores a product's price, replacing its previous one, and
+// adds it to the product's cost history.
+func (s *Service) SetPrice(p Price) (Price, error) {
+	if err := p.Validate(); err != nil {
+		return Price{}, err
+	}
+	p.UpdatedAt = s.now().UTC()
+	if err := s.store.SavePrice(p); err != nil {
+		return Price{}, err
+	}
+	if err := s.store.AppendPricePoint(PricePoint{Product: p.Product, UnitCost: p.UnitCost, Supplier: p.Supplier, At: p.UpdatedAt}); err != nil {
+		return Price{}, err
+	}
+	s.logger.Info("inventory price set", slog.String("product", p.Product.Key()), slog.Float64("unitCost", p.UnitCost))
+	return p, nil
+}
+
+// Prices returns the price list, by product name.
+func (s *Service) Prices() ([]Price, error) {
+	prices, err := s.store.ListPrices()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].Product.Name != prices[j].Product.Name {
+			return prices[i].Product.Name < prices[j].Product.Name
+		}
+		return prices[i].Product.Key() < prices[j].Product.Key()
+	})
+	return prices, nil
+}
+
+// DeletePrice removes the price of the product with a key: its EPA
+// registration, or its lowercased name when it has none. The product's
+// cost history is kept, but no longer used.
+func (s *Service) DeletePrice(key string) error {
+	return s.store.DeletePrice(key)
+}
+
+func (m *MemoryStore) SavePrice(p Price) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices[p.Product.Key()] = p
+	return nil
+}
+
+func (m *MemoryStore) ListPrices() ([]Price, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Price, 0, len(m.prices))
+	for _, p := range m.prices {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) DeletePrice(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.prices[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.prices, key)
+	return nil
+}
@@ -0,0 +1,457 @@
+package inventory
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// SendRequest is a technician handing stock from their truck to another
+// technician or back to a branch.
+type SendRequest struct {
+	To         Party   `json:"to"`
+	ChemicalID string  `json:"chemicalId"`          // the sender's chemical record
+	LotNumber  string  `json:"lotNumber,omitempty"` // required when the chemical tracks lots
+	Quantity   float64 `json:"quantity"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// RestockRequest is a branch loading stock onto one of its technicians'
+// trucks.
+type RestockRequest struct {
+	BranchID     string  `json:"branchId"`
+	TechnicianID string  `json:"technicianId"`
+	Product      Product `json:"product"`
+	LotNumber    string  `json:"lotNumber,omitempty"`
+	// LotExpiration is the lot's expiration date, recorded on the truck
+	// with the lot.
+	LotExpiration *time.Time `json:"lotExpiration,omitempty"`
+	Quantity      float64    `json:"quantity"`
+	Note          string     `json:"note,omitempty"`
+}
+
+// Balance is a party's net ledger quantity of a product.
+type Balance struct {
+	Product  Product `json:"product"`
+	Quantity float64 `json:"quantity"`
+}
+
+// Statement is a party's ledger with per-product balances.
+type Statement struct {
+	Party    Party     `json:"party"`
+	Entries  []Entry   `json:"entries"`
+	Balances []Balance `json:"balances"`
+}
+
+// Service records transfers and posts them to the ledger once both parties
+// confirm, and runs periodic stock counts.
+type Service struct {
+	cfg      config.InventoryConfig
+	store    Store
+	repos    repository.Repository
+	branches *branch.Service
+	logger   *slog.Logger
+	now      func() time.Time
+
+	// mu serializes state changes so stock checks and chemical record
+	// updates of concurrent confirmations cannot interleave.
+	mu sync.Mutex
+}
+
+// NewService wires an inventory service. Branch parties are rejected when
+// branches is nil.
+func NewService(cfg config.InventoryConfig, store Store, repos repository.Repository, branches *branch.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, branches: branches, logger: logger, now: time.Now}
+}
+
+// Send starts a transfer from a technician's truck. The sender's side is
+// confirmed by sending; the stock moves once the receiver confirms.
+func (s *Service) Send(technicianID string, req SendRequest) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chemical, err := s.chemical(technicianID, req.ChemicalID)
+	if err != nil {
+		return Transfer{}, err
+	}
+	now := s.now().UTC()
+	t := Transfer{
+		ID:              uuid.NewString(),
+		From:            Technician(technicianID),
+		To:              req.To,
+		Product:         Product{Name: chemical.Name, EPARegistration: chemical.EPARegistration, UnitOfMeasure: chemical.UnitOfMeasure},
+		LotNumber:       req.LotNumber,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+		ChemicalID:      chemical.ID,
+		Status:          StatusPending,
+		CreatedAt:       now,
+		FromConfirmedAt: &now,
+	}
+	if err := s.check(t); err != nil {
+		return Transfer{}, err
+	}
+	committed, err := s.committed(t.From, t.ChemicalID, "")
+	if err != nil {
+		return Transfer{}, err
+	}
+	if available := chemical.QuantityInStock - committed; req.Quantity > available {
+		return Transfer{}, fmt.Errorf("%w: only %g %s of %s available to send", ErrInvalidTransfer, available, chemical.UnitOfMeasure, chemical.Name)
+	}
+	if len(chemical.Lots) > 0 {
+		lot, ok := findLot(chemical.Lots, req.LotNumber)
+		if !ok {
+			return Transfer{}, fmt.Errorf("%w: %s tracks lots; lotNumber must name one of them", ErrInvalidTransfer, chemical.Name)
+		}
+		committed, err := s.committed(t.From, t.ChemicalID, lot.Number)
+		if err != nil {
+			return Transfer{}, err
+		}
+		if available := lot.Quantity - committed; req.Quantity > available {
+			return Transfer{}, fmt.Errorf("%w: only %g %s of lot %s available to send", ErrInvalidTransfer, available, chemical.UnitOfMeasure, lot.Number)
+		}
+	}
+	if err := s.store.SaveTransfer(t); err != nil {
+		return Transfer{}, err
+	}
+	s.logger.Info("inventory transfer sent", slog.String("transfer", t.ID), slog.String("from", t.From.String()), slog.String("to", t.To.String()))
+	return t, nil
+}
+
+// Restock starts a transfer from a branch to one of its technicians. The
+// branch side is confirmed by the request; the technician confirms receipt.
+func (s *Service) Restock(req RestockRequest) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	t := Transfer{
+		ID:              uuid.NewString(),
+		From:            Branch(req.BranchID),
+		To:              Technician(req.TechnicianID),
+		Product:         req.Product,
+		LotNumber:       strings.TrimSpace(req.LotNumber),
+		LotExpiration:   req.LotExpiration,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+		Status:          StatusPending,
+		CreatedAt:       now,
+		FromConfirmedAt: &now,
+	}
+	if err := s.check(t); err != nil {
+		return Transfer{}, err
+	}
+	if tech, _ := s.repos.Technicians.GetByID(req.TechnicianID); tech.BranchID != req.BranchID {
+		return Transfer{}, fmt.Errorf("%w: technician %q is not in branch %q", ErrInvalidTransfer, req.TechnicianID, req.BranchID)
+	}
+	if err := s.store.SaveTransfer(t); err != nil {
+		return Transfer{}, err
+	}
+	s.logger.Info("inventory restock sent", slog.String("transfer", t.ID), slog.String("branch", req.BranchID), slog.String("technician", req.TechnicianID))
+	return t, nil
+}
+
+// Confirm records by's confirmation of a pending transfer, completing it when
+// both sides have confirmed. Confirming twice is harmless.
+func (s *Service) Confirm(id string, by Party) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.pending(id, by)
+	if err != nil {
+		return Transfer{}, err
+	}
+	now := s.now().UTC()
+	switch by {
+	case t.From:
+		if t.FromConfirmedAt == nil {
+			t.FromConfirmedAt = &now
+		}
+	case t.To:
+		if t.ToConfirmedAt == nil {
+			t.ToConfirmedAt = &now
+		}
+	}
+	if t.FromConfirmedAt != nil && t.ToConfirmedAt != nil {
+		if err := s.complete(&t, now); err != nil {
+			return Transfer{}, err
+		}
+	}
+	if err := s.store.SaveTransfer(t); err != nil {
+		return Transfer{}, err
+	}
+	return t, nil
+}
+
+// Decline closes a pending transfer without moving stock: rejected when the
+// receiver declines, cancelled when the sender withdraws.
+func (s *Service) Decline(id string, by Party, reason string) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.pending(id, by)
+	if err != nil {
+		return Transfer{}, err
+	}
+	now := s.now().UTC()
+	t.Status = StatusRejected
+	if by == t.From {
+		t.Status = StatusCancelled
+	}
+	t.Reason = strings.TrimSpace(reason)
+	t.ClosedAt = &now
+	if err := s.store.SaveTransfer(t); err != nil {
+		return Transfer{}, err
+	}
+	s.logger.Info("inventory transfer declined", slog.String("transfer", t.ID), slog.String("status", t.Status), slog.String("by", by.String()))
+	return t, nil
+}
+
+// Transfer returns a transfer.
+func (s *Service) Transfer(id string) (Transfer, error) {
+	return s.store.GetTransfer(id)
+}
+
+// Transfers lists transfers newest first, limited to those involving party
+// when it is set and to status when that is set.
+func (s *Service) Transfers(party Party, status string) ([]Transfer, error) {
+	all, err := s.store.ListTransfers()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Transfer, 0, len(all))
+	for _, t := range all {
+		if (party == Party{} || t.Involves(party)) && (status == "" || t.Status == status) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Ledger returns a party's ledger entries, oldest first, and balances.
+func (s *Service) Ledger(p Party) (Statement, error) {
+	entries, err := s.store.ListEntries(p)
+	if err != nil {
+		return Statement{}, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	st := Statement{Party: p, Entries: entries, Balances: []Balance{}}
+	if st.Entries == nil {
+		st.Entries = []Entry{}
+	}
+	index := make(map[string]int)
+	for _, e := range entries {
+		i, ok := index[e.Product.Key()]
+		if !ok {
+			i = len(st.Balances)
+			index[e.Product.Key()] = i
+			st.Balances = append(st.Balances, Balance{Product: e.Product})
+		}
+		st.Balances[i].Quantity += e.Delta
+	}
+	sort.Slice(st.Balances, func(i, j int) bool { return st.Balances[i].Product.Key() < st.Balances[j].Product.Key() })
+	return st, nil
+}
+
+// complete moves the stock: the sending technician's chemical record is
+// drawn down, the receiving technician's record for the product (created if
+// they have none) is topped up, and both sides are posted to the ledger.
+func (s *Service) complete(t *Transfer, now time.Time) error {
+	var template models.ChemicalUpload
+	if t.From.Kind == PartyTechnician {
+		sender, err := s.chemical(t.From.ID, t.ChemicalID)
+		if err != nil {
+			return err
+		}
+		if sender.QuantityInStock < t.Quantity {
+			return fmt.Errorf("%w: sender has only %g %s of %s left", ErrInvalidTransfer, sender.QuantityInStock, sender.UnitOfMeasure, sender.Name)
+		}
+		template = sender
+		if t.LotNumber != "" {
+			sender.Lots = append([]models.ChemicalLot(nil), sender.Lots...)
+			for i := range sender.Lots {
+				if sender.Lots[i].Number == t.LotNumber {
+					template.ExpirationDate = sender.Lots[i].ExpirationDate
+					sender.Lots[i].Quantity = math.Max(sender.Lots[i].Quantity-t.Quantity, 0)
+				}
+			}
+		}
+		sender.QuantityInStock -= t.Quantity
+		sender.LastModified = now
+		if err := s.repos.Sync.SaveChemicalUpload(sender); err != nil {
+			return err
+		}
+	}
+	if t.To.Kind == PartyTechnician {
+		receiver, err := s.receiving(t.To.ID, t.Product, template)
+		if err != nil {
+			return err
+		}
+		receiver.QuantityInStock += t.Quantity
+		if t.LotNumber != "" {
+			receiver.Lots = receiveLot(receiver.Lots, t, template.ExpirationDate, now)
+		}
+		receiver.LastModified = now
+		if err := s.repos.Sync.SaveChemicalUpload(receiver); err != nil {
+			return err
+		}
+		t.ReceiverChemicalID = receiver.ID
+	}
+	if err := s.store.AppendEntries(
+		Entry{ID: uuid.NewString(), TransferID: t.ID, Party: t.From, Product: t.Product, Delta: -t.Quantity, At: now},
+		Entry{ID: uuid.NewString(), TransferID: t.ID, Party: t.To, Product: t.Product, Delta: t.Quantity, At: now},
+	); err != nil {
+		return err
+	}
+	t.Status = StatusCompleted
+	t.ClosedAt = &now
+	s.logger.Info("inventory transfer completed", slog.String("transfer", t.ID), slog.String("from", t.From.String()), slog.String("to", t.To.String()), slog.Float64("quantity", t.Quantity))
+	return nil
+}
+
+// receiving returns the technician's chemical record for product, or a new
+// one modelled on template (the sender's record, when there is one).
+func (s *Service) receiving(technicianID string, product Product, template models.ChemicalUpload) (models.ChemicalUpload, error) {
+	chemicals, err := s.chemicals(technicianID)
+	if err != nil {
+		return models.ChemicalUpload{}, err
+	}
+	for _, c := range chemicals {
+		if (Product{Name: c.Name, EPARegistration: c.EPARegistration}).Key() == product.Key() {
+			return c, nil
+		}
+	}
+	template.ID = uuid.NewString()
+	template.TechnicianID = technicianID
+	template.Name = product.Name
+	template.EPARegistration = product.EPARegistration
+	template.UnitOfMeasure = product.UnitOfMeasure
+	template.QuantityInStock = 0
+	template.Lots = nil
+	return template, nil
+}
+
+// pending loads a pending transfer by is a party to. Transfers by is not a
+// party to are reported as not found.
+func (s *Service) pending(id string, by Party) (Transfer, error) {
+	t, err := s.store.GetTransfer(id)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if !t.Involves(by) {
+		return Transfer{}, ErrNotFound
+	}
+	if t.Status != StatusPending {
+		return Transfer{}, fmt.Errorf("%w: transfer is already %s", ErrInvalidTransfer, t.Status)
+	}
+	return t, nil
+}
+
+// check validates a new transfer and that both parties exist.
+func (s *Service) check(t Transfer) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	for _, p := range []Party{t.From, t.To} {
+		switch p.Kind {
+		case PartyTechnician:
+			if _, err := s.repos.Technicians.GetByID(p.ID); err != nil {
+				return fmt.Errorf("%w: technician %q not found", ErrInvalidTransfer, p.ID)
+			}
+		case PartyBranch:
+			if s.branches == nil {
+				return fmt.Errorf("%w: branch transfers are not available", ErrInvalidTransfer)
+			}
+			if _, err := s.branches.Branch(p.ID); err != nil {
+				return fmt.Errorf("%w: branch %q not found", ErrInvalidTransfer, p.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// committed sums the quantity of a chemical record, or of one of its lots
+// when lotNumber is set, already promised in pending transfers from p.
+func (s *Service) committed(p Party, chemicalID, lotNumber string) (float64, error) {
+	pending, err := s.Transfers(p, StatusPending)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, t := range pending {
+		if t.From == p && t.ChemicalID == chemicalID && (lotNumber == "" || t.LotNumber == lotNumber) {
+			total += t.Quantity
+		}
+	}
+	return total, nil
+}
+
+// receiveLot returns lots with t's quantity added to its lot, which is added
+// to the list if the receiver has none of it yet. Restocks carry their own
+// expiration date; transfers between technicians keep the sender's.
+func receiveLot(lots []models.ChemicalLot, t *Transfer, expiration time.Time, now time.Time) []models.ChemicalLot {
+	lots = append([]models.ChemicalLot(nil), lots...)
+	for i := range lots {
+		if lots[i].Number == t.LotNumber {
+			lots[i].Quantity += t.Quantity
+			return lots
+		}
+	}
+	if t.LotExpiration != nil {
+		expiration = *t.LotExpiration
+	}
+	return append(lots, models.ChemicalLot{Number: t.LotNumber, ReceivedAt: now, ExpirationDate: expiration, Quantity: t.Quantity})
+}
+
+func findLot(lots []models.ChemicalLot, number string) (models.ChemicalLot, bool) {
+	for _, lot := range lots {
+		if number != "" && lot.Number == number {
+			return lot, true
+		}
+	}
+	return models.ChemicalLot{}, false
+}
+
+// chemical returns a technician's chemical record.
+func (s *Service) chemical(technicianID, chemicalID string) (models.ChemicalUpload, error) {
+	chemicals, err := s.chemicals(technicianID)
+	if err != nil {
+		return models.ChemicalUpload{}, err
+	}
+	for _, c := range chemicals {
+		if c.ID == chemicalID {
+			return c, nil
+		}
+	}
+	return models.ChemicalUpload{}, fmt.Errorf("%w: chemical %q is not on technician %q's truck", ErrInvalidTransfer, chemicalID, technicianID)
+}
+
+// chemicals returns the latest chemical records of a technician.
+func (s *Service) chemicals(technicianID string) ([]models.ChemicalUpload, error) {
+	all, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.ChemicalUpload, 0, len(all))
+	for _, c := range all {
+		if c.TechnicianID == technicianID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,236 @@
+// Package inventory records chemical stock movements between technicians'
+// trucks and branches. A transfer only moves stock once both parties have
+// confirmed it; completion posts the movement to the ledger and updates the
+// technicians' synced chemical records so truck-level stock stays accurate.
+// Periodic stock counts reconcile the records with what is actually on the
+// truck, posting approved variances to the ledger as adjustments.
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Party kinds.
+const (
+	PartyTechnician = "technician"
+	PartyBranch     = "branch"
+)
+
+// Transfer statuses.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusRejected  = "rejected"  // the receiver declined
+	StatusCancelled = "cancelled" // the sender withdrew
+)
+
+var (
+	// ErrNotFound is returned when a transfer does not exist or is not
+	// visible to the party asking.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidTransfer wraps transfer validation and state failures.
+	ErrInvalidTransfer = errors.New("invalid transfer")
+)
+
+// Party is one side of a transfer: a technician's truck or a branch.
+type Party struct {
+	Kind string `json:"kind"` // technician, branch
+	ID   string `json:"id"`
+}
+
+// Technician returns the party for a technician's truck.
+func Technician(id string) Party { return Party{Kind: PartyTechnician, ID: id} }
+
+// Branch returns the party for a branch.
+func Branch(id string) Party { return Party{Kind: PartyBranch, ID: id} }
+
+func (p Party) String() string { return p.Kind + ":" + p.ID }
+
+// Product identifies what is moved. Stock is matched across parties by EPA
+// registration number or, without one, by name.
+type Product struct {
+	Name            string `json:"name"`
+	EPARegistration string `json:"epaRegistration,omitempty"`
+	UnitOfMeasure   string `json:"unitOfMeasure,omitempty"`
+}
+
+// Key is the product's ledger key.
+func (p Product) Key() string {
+	if p.EPARegistration != "" {
+		return p.EPARegistration
+	}
+	return strings.ToLower(strings.TrimSpace(p.Name))
+}
+
+// Transfer moves a quantity of a product from one party to another.
+type Transfer struct {
+	ID       string  `json:"id"`
+	From     Party   `json:"from"`
+	To       Party   `json:"to"`
+	Product  Product `json:"product"`
+	Quantity float64 `json:"quantity"`
+	Note     string  `json:"note,omitempty"`
+	// LotNumber is the manufacturer lot the stock is drawn from, when the
+	// product tracks lots; LotExpiration its expiration date for restocks.
+	LotNumber     string     `json:"lotNumber,omitempty"`
+	LotExpiration *time.Time `json:"lotExpiration,omitempty"`
+	// ChemicalID is the sending technician's chemical record the stock
+	// leaves; ReceiverChemicalID the receiving technician's record it lands
+	// in, set on completion.
+	ChemicalID         string     `json:"chemicalId,omitempty"`
+	ReceiverChemicalID string     `json:"receiverChemicalId,omitempty"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"` // why it was rejected or cancelled
+	CreatedAt          time.Time  `json:"createdAt"`
+	FromConfirmedAt    *time.Time `json:"fromConfirmedAt,omitempty"`
+	ToConfirmedAt      *time.Time `json:"toConfirmedAt,omitempty"`
+	ClosedAt           *time.Time `json:"closedAt,omitempty"`
+}
+
+// Involves reports whether p is either side of the transfer.
+func (t Transfer) Involves(p Party) bool {
+	return t.From == p || t.To == p
+}
+
+// Validate checks a transfer.
+func (t Transfer) Validate() error {
+	var problems []string
+	for name, p := range map[string]Party{"from": t.From, "to": t.To} {
+		if p.Kind != PartyTechnician && p.Kind != PartyBranch {
+			problems = append(problems, name+".kind must be technician or branch")
+		}
+		if strings.TrimSpace(p.ID) == "" {
+			problems = append(problems, name+".id is required")
+		}
+	}
+	if t.From == t.To {
+		problems = append(problems, "from and to must differ")
+	}
+	if t.From.Kind == PartyBranch && t.To.Kind == PartyBranch {
+		problems = append(problems, "branch to branch transfers are not supported")
+	}
+	if t.Product.Key() == "" {
+		problems = append(problems, "product name or epaRegistration is required")
+	}
+	if t.Quantity <= 0 {
+		problems = append(problems, "quantity must be > 0")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidTransfer, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Entry is one side of a completed transfer, or a stock count adjustment, in
+// the ledger. Delta is negative for the party stock left and positive for the
+// party it reached.
+type Entry struct {
+	ID         string    `json:"id"`
+	TransferID string    `json:"transferId,omitempty"`
+	CountID    string    `json:"countId,omitempty"`
+	ReceiptID  string    `json:"receiptId,omitempty"`
+	Party      Party     `json:"party"`
+	Product    Product   `json:"product"`
+	Delta      float64   `json:"delta"`
+	At         time.Time `json:"at"`
+}
+
+// Store persists transfers, stock counts and the ledger.
+type Store interface {
+	SaveTransfer(t Transfer) error
+	GetTransfer(id string) (Transfer, error)
+	ListTransfers() ([]Transfer, error)
+	AppendEntries(entries ...Entry) error
+	ListEntries(p Party) ([]Entry, error)
+	SaveCount(c Count) error
+	GetCount(id string) (Count, error)
+	ListCounts() ([]Count, error)
+	// SavePrice stores a product's price, replacing the one of the same
+	// product key.
+	SavePrice(p Price) error
+	ListPrices() ([]Price, error)
+	DeletePrice(key string) error
+	// AppendPricePoint adds to the history of product costs, which
+	// ListPricePoints returns oldest first.
+	AppendPricePoint(p PricePoint) error
+	ListPricePoints() ([]PricePoint, error)
+	SaveReceipt(r Receipt) error
+	// ListReceipts returns receipts, most recently received first.
+	ListReceipts() ([]Receipt, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	transfers map[string]Transfer
+	counts    map[string]Count
+	prices    map[string]Price // by product key
+	entries   []Entry
+	history   []PricePoint
+	receipts  []Receipt
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{transfers: make(map[string]Transfer), counts: make(map[string]Count), prices: make(map[string]Price)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveTransfer(t Transfer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transfers[t.ID] = t
+	return nil
+}
+
+func (m *MemoryStore) GetTransfer(id string) (Transfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.transfers[id]
+	if !ok {
+		return Transfer{}, ErrNotFound
+	}
+	return t, nil
+}
+
+func (m *MemoryStore) ListTransfers() ([]Transfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Transfer, 0, len(m.transfers))
+	for _, t := range m.transfers {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) AppendEntries(entries ...Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entries...)
+	return nil
+}
+
+func (m *MemoryStore) ListEntries(p Party) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Entry
+	for _, e := range m.entries {
+		if e.Party == p {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,254 @@
+// Package jobqueue delivers background jobs to the handlers subscribed to
+// their topic, with delayed delivery, retries with exponential backoff and
+// dead-lettering. In-process delivery serves local development; in prod
+// Cloud Tasks or Pub/Sub push jobs back to this service over HTTP, so any
+// instance may handle them.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Queue drivers.
+const (
+	DriverMemory     = "memory"
+	DriverCloudTasks = "cloudtasks"
+	DriverPubSub     = "pubsub"
+)
+
+// ErrFull is returned when the memory driver holds BufferSize jobs.
+var ErrFull = errors.New("job queue full")
+
+// Job is one delivery of work to a topic's handler.
+type Job struct {
+	ID      string          `json:"id"`
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempt is the delivery this is, from 1.
+	Attempt   int       `json:"attempt"`
+	NotBefore time.Time `json:"notBefore,omitempty"`
+}
+
+// Decode unmarshals the job's payload into v.
+func (j Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Handler does a job's work. An error has the job delivered again after a
+// backoff, until MaxAttempts deliveries have failed.
+type Handler func(ctx context.Context, job Job) error
+
+// DeadLetterFunc is told about a job whose last delivery failed with err.
+type DeadLetterFunc func(ctx context.Context, job Job, err error)
+
+// Subscription routes a topic's jobs to Handler. Concurrency bounds the
+// jobs handled at once by the memory driver; push drivers are bounded by
+// their queue's dispatch rate instead.
+type Subscription struct {
+	Topic       string
+	Handler     Handler
+	DeadLetter  DeadLetterFunc
+	Concurrency int
+}
+
+// driver sends jobs on their way to deliver.
+type driver interface {
+	send(ctx context.Context, job Job) error
+}
+
+// Queue enqueues jobs through its driver and delivers them to subscribers.
+type Queue struct {
+	cfg    config.QueueConfig
+	driver driver
+	logger *slog.Logger
+	now    func() time.Time
+	// prefix qualifies the topics of a partition.
+	prefix string
+
+	*subscribers
+}
+
+// subscribers are shared by a queue and its partitions.
+type subscribers struct {
+	mu   sync.RWMutex
+	subs map[string]Subscription
+	// missing sets up a partition this instance has not subscribed to yet.
+	missing func(partition string)
+
+	deadLettered atomic.Int64
+}
+
+// New returns the queue selected by cfg.
+func New(cfg config.QueueConfig, logger *slog.Logger) (*Queue, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	q := &Queue{cfg: cfg, logger: logger, now: time.Now, subscribers: &subscribers{subs: make(map[string]Subscription)}}
+	switch cfg.Driver {
+	case DriverMemory, "":
+		q.driver = newMemoryDriver(q, cfg.BufferSize)
+	case DriverCloudTasks:
+		q.driver = newCloudTasksDriver(cfg)
+	case DriverPubSub:
+		q.driver = newPubSubDriver(cfg)
+	default:
+		return nil, fmt.Errorf("unknown queue driver %q", cfg.Driver)
+	}
+	return q, nil
+}
+
+// NewMemory returns an in-process queue, for tests and tools.
+func NewMemory(cfg config.QueueConfig, logger *slog.Logger) *Queue {
+	cfg.Driver = DriverMemory
+	q, _ := New(cfg, logger)
+	return q
+}
+
+// Partition returns a queue whose topics are kept apart from those of q
+// and its other partitions, so the same subscribers can be set up once per
+// tenant. It shares q's driver and Run. name must not contain a slash.
+func (q *Queue) Partition(name string) *Queue {
+	p := *q
+	p.prefix = name + "/"
+	return &p
+}
+
+// OnMissingPartition has build called with the name of a partition a job
+// is delivered to before anything subscribed to it on this instance, as
+// happens when a push driver hands it another instance's job. build must
+// subscribe to the partition's topics; the job is then delivered to them.
+func (q *Queue) OnMissingPartition(build func(partition string)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.missing = build
+}
+
+// Subscribe routes sub.Topic's jobs to sub.Handler. Subscriptions made
+// after Run has started are handled from then on.
+func (q *Queue) Subscribe(sub Subscription) {
+	if sub.Concurrency <= 0 {
+		sub.Concurrency = 1
+	}
+	sub.Topic = q.prefix + sub.Topic
+	q.mu.Lock()
+	q.subs[sub.Topic] = sub
+	q.mu.Unlock()
+	if m, ok := q.driver.(*memoryDriver); ok {
+		m.start(sub)
+	}
+}
+
+// Enqueue queues payload, marshalled to JSON, for topic's handler no sooner
+// than delay from now, and returns the job's ID.
+func (q *Queue) Enqueue(ctx context.Context, topic string, payload any, delay time.Duration) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	job := Job{ID: uuid.NewString(), Topic: q.prefix + topic, Payload: data, Attempt: 1}
+	if delay > 0 {
+		job.NotBefore = q.now().Add(delay).UTC()
+	}
+	if err := q.driver.send(ctx, job); err != nil {
+		return "", fmt.Errorf("enqueue %s job: %w", topic, err)
+	}
+	return job.ID, nil
+}
+
+// Run delivers queued jobs until ctx is cancelled, for drivers that deliver
+// in-process, and waits for the jobs being handled. Push drivers deliver
+// through PushHandler instead, and Run just waits for ctx.
+func (q *Queue) Run(ctx context.Context) {
+	if m, ok := q.driver.(*memoryDriver); ok {
+		m.run(ctx)
+		return
+	}
+	<-ctx.Done()
+}
+
+// Drain handles the memory driver's due jobs in the calling goroutine,
+// including retries that come due meanwhile, and returns how many
+// deliveries it made. It is for tests and tools that need jobs handled
+// before they go on; other drivers deliver nothing here.
+func (q *Queue) Drain(ctx context.Context) int {
+	if m, ok := q.driver.(*memoryDriver); ok {
+		return m.drain(ctx)
+	}
+	return 0
+}
+
+// DeadLettered returns how many jobs have been dead-lettered since the
+// server started.
+func (q *Queue) DeadLettered() int64 {
+	return q.deadLettered.Load()
+}
+
+// deliver hands job to its subscriber. It returns an error when the job
+// should be delivered again; a job whose last attempt failed is
+// dead-lettered instead.
+func (q *Queue) deliver(ctx context.Context, job Job) error {
+	sub, ok := q.subscriber(job.Topic)
+	logger := q.logger.With(slog.String("topic", job.Topic), slog.String("job", job.ID), slog.Int("attempt", job.Attempt))
+
+	err := fmt.Errorf("no handler for topic %q", job.Topic)
+	if ok {
+		err = sub.Handler(ctx, job)
+	}
+	if err == nil {
+		return nil
+	}
+	// A job cut short by shutdown did not get a fair attempt.
+	if ok && (job.Attempt < q.cfg.MaxAttempts || ctx.Err() != nil) {
+		logger.Warn("job failed, will retry", slog.Any("error", err))
+		return err
+	}
+	q.deadLettered.Add(1)
+	logger.Error("job dead-lettered", slog.Any("error", err))
+	if ok && sub.DeadLetter != nil {
+		sub.DeadLetter(ctx, job, err)
+	}
+	return nil
+}
+
+// subscriber returns topic's subscription, setting up its partition first
+// when nothing on this instance subscribed to it yet.
+func (q *Queue) subscriber(topic string) (Subscription, bool) {
+	q.mu.RLock()
+	sub, ok := q.subs[topic]
+	missing := q.missing
+	q.mu.RUnlock()
+	partition, _, partitioned := strings.Cut(topic, "/")
+	if ok || !partitioned || missing == nil {
+		return sub, ok
+	}
+	missing(partition)
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	sub, ok = q.subs[topic]
+	return sub, ok
+}
+
+// backoff is the delay before delivering a job that failed attempt times.
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.cfg.MinBackoff
+	for i := 1; i < attempt && d < q.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > q.cfg.MaxBackoff {
+		d = q.cfg.MaxBackoff
+	}
+	return d
+}
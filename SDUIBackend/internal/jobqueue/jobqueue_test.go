@@ -0,0 +1,207 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/gcp"
+)
+
+const token = "0123456789abcdef0123"
+
+func TestMemoryRetriesThenDeadLetters(t *testing.T) {
+	q := NewMemory(config.QueueConfig{BufferSize: 10, MaxAttempts: 3, MinBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}, nil)
+	var attempts []int
+	var dead []Job
+	q.Subscribe(Subscription{
+		Topic: "t",
+		Handler: func(_ context.Context, job Job) error {
+			attempts = append(attempts, job.Attempt)
+			var n int
+			_ = job.Decode(&n)
+			if n == 1 {
+				return nil
+			}
+			return errors.New("boom")
+		},
+		DeadLetter: func(_ context.Context, job Job, err error) { dead = append(dead, job) },
+	})
+	ctx := context.Background()
+	if _, err := q.Enqueue(ctx, "t", 1, 0); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, "t", 2, 0); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if n := q.Drain(ctx); n != 4 {
+		t.Fatalf("expected one delivery and three attempts, got %d (%v)", n, attempts)
+	}
+	if len(dead) != 1 || dead[0].Attempt != 3 || q.DeadLettered() != 1 {
+		t.Fatalf("expected the failing job dead-lettered after three attempts, got %+v", dead)
+	}
+}
+
+func TestMemoryDelaysAndBacksOff(t *testing.T) {
+	q := NewMemory(config.QueueConfig{BufferSize: 1, MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: 3 * time.Second}, nil)
+	q.Subscribe(Subscription{Topic: "t", Handler: func(context.Context, Job) error { return nil }})
+	ctx := context.Background()
+	if _, err := q.Enqueue(ctx, "t", "later", time.Hour); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if n := q.Drain(ctx); n != 0 {
+		t.Fatalf("expected a delayed job held back, got %d deliveries", n)
+	}
+	if _, err := q.Enqueue(ctx, "t", "more", 0); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected a full buffer to refuse jobs, got %v", err)
+	}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second, 9: 3 * time.Second} {
+		if got := q.backoff(attempt); got != want {
+			t.Fatalf("backoff after attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+}
+
+func TestPartitionsKeepTopicsApart(t *testing.T) {
+	q := NewMemory(config.QueueConfig{BufferSize: 10, MaxAttempts: 1}, nil)
+	var got []string
+	for _, name := range []string{"acme", "bugsbgone"} {
+		name := name
+		q.Partition(name).Subscribe(Subscription{Topic: "t", Handler: func(_ context.Context, job Job) error {
+			got = append(got, name+":"+job.Topic)
+			return nil
+		}})
+	}
+	ctx := context.Background()
+	if _, err := q.Partition("bugsbgone").Enqueue(ctx, "t", 1, 0); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, "t", 2, 0); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if n := q.Drain(ctx); n != 2 || len(got) != 1 || got[0] != "bugsbgone:bugsbgone/t" || q.DeadLettered() != 1 {
+		t.Fatalf("expected only the partition's subscriber to get its job, got %v", got)
+	}
+
+	// A job pushed for a partition this instance has not set up yet sets it up.
+	var built []string
+	q.OnMissingPartition(func(name string) {
+		built = append(built, name)
+		q.Partition(name).Subscribe(Subscription{Topic: "t", Handler: func(context.Context, Job) error { return nil }})
+	})
+	if err := q.deliver(ctx, Job{ID: "j1", Topic: "termitex/t", Attempt: 1}); err != nil || len(built) != 1 || built[0] != "termitex" {
+		t.Fatalf("expected the missing partition built, got %v (%v)", built, err)
+	}
+	if err := q.deliver(ctx, Job{ID: "j2", Topic: "termitex/t", Attempt: 1}); err != nil || len(built) != 1 {
+		t.Fatalf("expected the partition built once, got %v (%v)", built, err)
+	}
+}
+
+func TestPushHandler(t *testing.T) {
+	cfg := config.QueueConfig{Driver: DriverCloudTasks, PushToken: token, MaxAttempts: 2}
+	q, _ := New(cfg, nil)
+	var got []Job
+	q.Subscribe(Subscription{Topic: "t", Handler: func(_ context.Context, job Job) error {
+		got = append(got, job)
+		return errors.New("boom")
+	}})
+	body, _ := json.Marshal(Job{ID: "j1", Topic: "t", Payload: json.RawMessage(`{}`), Attempt: 1})
+	push := func(q *Queue, target, tok string, header http.Header, body string) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if tok != "" {
+			req.Header.Set(pushTokenHeader, tok)
+		}
+		rec := httptest.NewRecorder()
+		q.PushHandler(rec, req)
+		return rec.Code
+	}
+
+	if code := push(q, PushPath, "wrong", nil, string(body)); code != http.StatusUnauthorized {
+		t.Fatalf("expected a bad token refused, got %d", code)
+	}
+	if code := push(q, PushPath, token, nil, string(body)); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a failed first attempt retried, got %d", code)
+	}
+	if code := push(q, PushPath, token, http.Header{"X-Cloudtasks-Taskretrycount": {"1"}}, string(body)); code != http.StatusNoContent {
+		t.Fatalf("expected the last attempt dead-lettered and acknowledged, got %d", code)
+	}
+	if len(got) != 2 || got[1].Attempt != 2 || q.DeadLettered() != 1 {
+		t.Fatalf("expected the retry count taken from Cloud Tasks, got %+v", got)
+	}
+	if code := push(q, PushPath, token, nil, "not json"); code != http.StatusNoContent {
+		t.Fatalf("expected a malformed job acknowledged, got %d", code)
+	}
+
+	cfg.Driver = DriverPubSub
+	ps, _ := New(cfg, nil)
+	ps.Subscribe(Subscription{Topic: "t", Handler: func(_ context.Context, job Job) error {
+		got = append(got, job)
+		return nil
+	}})
+	envelope, _ := json.Marshal(map[string]any{
+		"message":         map[string]string{"data": base64.StdEncoding.EncodeToString(body)},
+		"deliveryAttempt": 3,
+	})
+	if code := push(ps, PushPath+"?token="+token, "", nil, string(envelope)); code != http.StatusNoContent {
+		t.Fatalf("expected a Pub/Sub push delivered, got %d", code)
+	}
+	if last := got[len(got)-1]; last.ID != "j1" || last.Attempt != 3 {
+		t.Fatalf("expected the job unwrapped from its envelope, got %+v", last)
+	}
+	early, _ := json.Marshal(Job{ID: "j2", Topic: "t", Attempt: 1, NotBefore: time.Now().Add(time.Minute)})
+	envelope, _ = json.Marshal(map[string]any{"message": map[string]string{"data": base64.StdEncoding.EncodeToString(early)}})
+	if code := push(ps, PushPath+"?token="+token, "", nil, string(envelope)); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected an early job sent back, got %d", code)
+	}
+}
+
+func TestCloudTasksCreatesTask(t *testing.T) {
+	var path, auth string
+	var body struct {
+		Task struct {
+			ScheduleTime string `json:"scheduleTime"`
+			HTTPRequest  struct {
+				URL     string            `json:"url"`
+				Headers map[string]string `json:"headers"`
+				Body    string            `json:"body"`
+			} `json:"httpRequest"`
+		} `json:"task"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	q, _ := New(config.QueueConfig{
+		Driver: DriverCloudTasks, PushURL: "https://api.example.com", PushToken: token, Project: "p",
+		CloudTasksLocation: "us-central1", CloudTasksQueue: "jobs", CloudTasksEndpoint: srv.URL,
+	}, nil)
+	q.driver.(*cloudTasksDriver).tokens = gcp.StaticToken("secret")
+	if _, err := q.Enqueue(context.Background(), "t", map[string]string{"id": "op-1"}, time.Minute); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if path != "/v2/projects/p/locations/us-central1/queues/jobs/tasks" || auth != "Bearer secret" {
+		t.Fatalf("unexpected request to %s with %q", path, auth)
+	}
+	req := body.Task.HTTPRequest
+	if req.URL != "https://api.example.com"+PushPath || req.Headers[pushTokenHeader] != token || body.Task.ScheduleTime == "" {
+		t.Fatalf("expected a delayed task pushing to the service, got %+v", body.Task)
+	}
+	data, _ := base64.StdEncoding.DecodeString(req.Body)
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil || job.Topic != "t" || string(job.Payload) != `{"id":"op-1"}` {
+		t.Fatalf("expected the job as the task body, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,227 @@
+package jobqueue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"log/slog"
+)
+
+// memoryDriver holds jobs in process, in a queue per topic ordered by when
+// they are due. Jobs are lost on restart; callers that cannot lose work,
+// like the upload worker, queue it again.
+type memoryDriver struct {
+	q    *Queue
+	size int
+
+	mu     sync.Mutex
+	topics map[string]*memoryTopic
+	count  int   // jobs held across topics
+	seq    int64 // keeps jobs due at once in FIFO order
+
+	// ctx is run's, once it has started; workers holds the topics whose
+	// workers it started.
+	ctx     context.Context
+	workers map[string]bool
+	wg      sync.WaitGroup
+}
+
+type memoryTopic struct {
+	pending jobHeap
+	// wake is signalled when a job is added, or one of several due jobs is
+	// taken, so an idle worker looks again.
+	wake chan struct{}
+}
+
+func newMemoryDriver(q *Queue, size int) *memoryDriver {
+	if size <= 0 {
+		size = 10000
+	}
+	return &memoryDriver{q: q, size: size, topics: make(map[string]*memoryTopic), workers: make(map[string]bool)}
+}
+
+// topic returns a topic's queue, creating it. Callers hold m.mu.
+func (m *memoryDriver) topic(name string) *memoryTopic {
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memoryTopic{wake: make(chan struct{}, 1)}
+		m.topics[name] = t
+	}
+	return t
+}
+
+func (m *memoryDriver) send(_ context.Context, job Job) error {
+	m.mu.Lock()
+	if m.count >= m.size {
+		m.mu.Unlock()
+		return ErrFull
+	}
+	t := m.topic(job.Topic)
+	m.seq++
+	heap.Push(&t.pending, queued{job: job, seq: m.seq})
+	m.count++
+	m.mu.Unlock()
+	notify(t.wake)
+	return nil
+}
+
+// run starts each subscription's workers, and those of subscriptions made
+// meanwhile, and waits for them to return once ctx is cancelled.
+func (m *memoryDriver) run(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	m.q.mu.RLock()
+	subs := make([]Subscription, 0, len(m.q.subs))
+	for _, sub := range m.q.subs {
+		subs = append(subs, sub)
+	}
+	m.q.mu.RUnlock()
+	for _, sub := range subs {
+		m.start(sub)
+	}
+	<-ctx.Done()
+	m.wg.Wait()
+}
+
+// start starts sub's workers once run has, unless they were started.
+func (m *memoryDriver) start(sub Subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx == nil || m.ctx.Err() != nil || m.workers[sub.Topic] {
+		return
+	}
+	m.workers[sub.Topic] = true
+	t := m.topic(sub.Topic)
+	for i := 0; i < sub.Concurrency; i++ {
+		m.wg.Add(1)
+		go func(ctx context.Context) {
+			defer m.wg.Done()
+			m.work(ctx, t)
+		}(m.ctx)
+	}
+}
+
+func (m *memoryDriver) work(ctx context.Context, t *memoryTopic) {
+	for {
+		job, ok := m.next(ctx, t)
+		if !ok {
+			return
+		}
+		m.handle(ctx, job)
+	}
+}
+
+// handle delivers job, queueing it again after a backoff if it failed.
+func (m *memoryDriver) handle(ctx context.Context, job Job) {
+	if err := m.q.deliver(ctx, job); err == nil || ctx.Err() != nil {
+		return
+	}
+	job.NotBefore = m.q.now().Add(m.q.backoff(job.Attempt)).UTC()
+	job.Attempt++
+	if err := m.send(ctx, job); err != nil {
+		m.q.logger.Error("requeue failed job", slog.String("topic", job.Topic), slog.String("job", job.ID), slog.Any("error", err))
+	}
+}
+
+// drain handles due jobs in the calling goroutine until none are left.
+func (m *memoryDriver) drain(ctx context.Context) int {
+	n := 0
+	for ctx.Err() == nil {
+		m.mu.Lock()
+		var job Job
+		found := false
+		for _, t := range m.topics {
+			if job, _, found = m.pop(t); found {
+				break
+			}
+		}
+		m.mu.Unlock()
+		if !found {
+			break
+		}
+		m.handle(ctx, job)
+		n++
+	}
+	return n
+}
+
+// pop takes t's next job if it is due, or reports how long until it is;
+// a negative wait means t is empty. Callers hold m.mu.
+func (m *memoryDriver) pop(t *memoryTopic) (Job, time.Duration, bool) {
+	if len(t.pending) == 0 {
+		return Job{}, -1, false
+	}
+	if wait := t.pending[0].job.NotBefore.Sub(m.q.now()); wait > 0 {
+		return Job{}, wait, false
+	}
+	job := heap.Pop(&t.pending).(queued).job
+	m.count--
+	return job, 0, true
+}
+
+// next waits for t's next due job, or for ctx to be cancelled.
+func (m *memoryDriver) next(ctx context.Context, t *memoryTopic) (Job, bool) {
+	for {
+		m.mu.Lock()
+		job, wait, ok := m.pop(t)
+		more := len(t.pending) > 0
+		m.mu.Unlock()
+		if ok {
+			if more {
+				notify(t.wake)
+			}
+			return job, true
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return Job{}, false
+		case <-t.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+type queued struct {
+	job Job
+	seq int64
+}
+
+// jobHeap orders jobs by when they are due, then by when they were sent.
+type jobHeap []queued
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if !h[i].job.NotBefore.Equal(h[j].job.NotBefore) {
+		return h[i].job.NotBefore.Before(h[j].job.NotBefore)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(queued)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,230 @@
+package jobqueue
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/gcp"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// PushPath is where push drivers deliver jobs, relative to the service's
+// base URL.
+const PushPath = "/internal/jobs"
+
+// pushTokenHeader carries the push token on Cloud Tasks requests; Pub/Sub
+// push endpoints carry it as the token query parameter instead.
+const pushTokenHeader = "X-Queue-Token"
+
+// PushHandler receives jobs pushed by Cloud Tasks or Pub/Sub. A failed job
+// is answered 503 so the platform delivers it again, following its retry
+// policy; once MaxAttempts deliveries have failed the job is dead-lettered
+// and acknowledged.
+func (q *Queue) PushHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(pushTokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if q.cfg.PushToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(q.cfg.PushToken)) != 1 {
+		respond.Error(w, http.StatusUnauthorized, "authentication required", "provide the queue push token")
+		return
+	}
+
+	var job Job
+	var err error
+	switch q.cfg.Driver {
+	case DriverCloudTasks:
+		job, err = cloudTasksJob(r)
+	case DriverPubSub:
+		job, err = pubSubJob(r)
+	default:
+		respond.Error(w, http.StatusNotFound, "not found", "this queue does not take pushed jobs")
+		return
+	}
+	if err != nil {
+		// Redelivering a job that does not decode cannot help.
+		q.logger.Error("decode pushed job", slog.Any("error", err))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// Pub/Sub cannot delay delivery, so early jobs go back to wait for the
+	// subscription's retry backoff.
+	if job.NotBefore.After(q.now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(job.NotBefore.Sub(q.now()).Seconds())+1))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if err := q.deliver(r.Context(), job); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// cloudTasksJob decodes a job pushed by Cloud Tasks, which counts its
+// retries in a header.
+func cloudTasksJob(r *http.Request) (Job, error) {
+	var job Job
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		return Job{}, err
+	}
+	if retries, err := strconv.Atoi(r.Header.Get("X-CloudTasks-TaskRetryCount")); err == nil {
+		job.Attempt = retries + 1
+	}
+	return job, nil
+}
+
+// pubSubJob decodes a job from a Pub/Sub push envelope. deliveryAttempt is
+// only set on subscriptions with a dead-letter policy; without one the job
+// is handled as its first attempt until the subscription gives up.
+func pubSubJob(r *http.Request) (Job, error) {
+	var envelope struct {
+		Message struct {
+			Data string `json:"data"`
+		} `json:"message"`
+		DeliveryAttempt int `json:"deliveryAttempt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&envelope); err != nil {
+		return Job{}, err
+	}
+	data, err := base64.StdEncoding.DecodeString(envelope.Message.Data)
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, err
+	}
+	if envelope.DeliveryAttempt > 0 {
+		job.Attempt = envelope.DeliveryAttempt
+	}
+	return job, nil
+}
+
+// cloudTasksDriver creates an HTTP task per job, targeting PushHandler.
+// Delay is the task's schedule time; retries follow the Cloud Tasks queue's
+// retry config, which should allow at least MaxAttempts.
+type cloudTasksDriver struct {
+	cfg    config.QueueConfig
+	tasks  string // .../projects/{project}/locations/{location}/queues/{queue}/tasks
+	client *http.Client
+	tokens gcp.TokenSource
+}
+
+func newCloudTasksDriver(cfg config.QueueConfig) *cloudTasksDriver {
+	host := strings.TrimSuffix(cfg.CloudTasksEndpoint, "/")
+	if host == "" {
+		host = "https://cloudtasks.googleapis.com"
+	}
+	return &cloudTasksDriver{
+		cfg: cfg,
+		tasks: host + "/v2/projects/" + url.PathEscape(cfg.Project) +
+			"/locations/" + url.PathEscape(cfg.CloudTasksLocation) +
+			"/queues/" + url.PathEscape(cfg.CloudTasksQueue) + "/tasks",
+		client: &http.Client{Timeout: requestTimeout(cfg)},
+		tokens: gcp.NewMetadataTokenSource(),
+	}
+}
+
+func (d *cloudTasksDriver) send(ctx context.Context, job Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	task := map[string]any{
+		"httpRequest": map[string]any{
+			"httpMethod": "POST",
+			"url":        d.cfg.PushURL + PushPath,
+			"headers":    map[string]string{"Content-Type": "application/json", pushTokenHeader: d.cfg.PushToken},
+			"body":       base64.StdEncoding.EncodeToString(body),
+		},
+	}
+	if !job.NotBefore.IsZero() {
+		task["scheduleTime"] = job.NotBefore.UTC().Format(time.RFC3339Nano)
+	}
+	return post(ctx, d.client, d.tokens, d.tasks, map[string]any{"task": task})
+}
+
+// pubSubDriver publishes jobs to a topic with a push subscription to
+// PushHandler.
+type pubSubDriver struct {
+	topic  string // .../projects/{project}/topics/{topic}
+	client *http.Client
+	tokens gcp.TokenSource
+}
+
+func newPubSubDriver(cfg config.QueueConfig) *pubSubDriver {
+	host := "https://pubsub.googleapis.com"
+	var tokens gcp.TokenSource = gcp.NewMetadataTokenSource()
+	if cfg.PubSubEmulator != "" {
+		host = "http://" + strings.TrimPrefix(cfg.PubSubEmulator, "http://")
+		// The emulator does not check credentials.
+		tokens = gcp.StaticToken("owner")
+	}
+	return &pubSubDriver{
+		topic:  host + "/v1/projects/" + url.PathEscape(cfg.Project) + "/topics/" + url.PathEscape(cfg.PubSubTopic),
+		client: &http.Client{Timeout: requestTimeout(cfg)},
+		tokens: tokens,
+	}
+}
+
+func (d *pubSubDriver) send(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	body := map[string]any{"messages": []map[string]any{{
+		"data":       base64.StdEncoding.EncodeToString(data),
+		"attributes": map[string]string{"topic": job.Topic, "job": job.ID},
+	}}}
+	return post(ctx, d.client, d.tokens, d.topic+":publish", body)
+}
+
+func requestTimeout(cfg config.QueueConfig) time.Duration {
+	if cfg.RequestTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return cfg.RequestTimeout
+}
+
+// post sends body as JSON to a Google REST API.
+func post(ctx context.Context, client *http.Client, tokens gcp.TokenSource, target string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("queue token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package liveactivity drives the iOS Live Activity (lock screen and Dynamic
+// Island) that follows a technician's current job. The app starts the
+// activity on the device and registers its push token here; the server then
+// pushes content updates as jobs start and finish. Activity tokens are a
+// separate channel from the device tokens used for alert notifications: they
+// address one running activity, expire with it, and carry content state
+// rather than an alert.
+package liveactivity
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"log/slog"
+)
+
+// ErrNotFound is returned for unknown activity tokens.
+var ErrNotFound = errors.New("live activity token not found")
+
+// Phases of the technician's day shown by the activity.
+const (
+	// PhaseOnSite: a job has started and the technician is at the customer.
+	PhaseOnSite = "on_site"
+	// PhaseEnRoute: the last job finished and the next stop is ahead.
+	PhaseEnRoute = "en_route"
+	// PhaseDone: no stops remain; sent with EventEnd.
+	PhaseDone = "done"
+)
+
+// Update events, as understood by ActivityKit.
+const (
+	EventUpdate = "update"
+	EventEnd    = "end"
+)
+
+// Token is the push token of one running Live Activity.
+type Token struct {
+	Token        string `json:"token"`
+	TechnicianID string `json:"technicianId"`
+	// ActivityID is the ActivityKit identifier of the activity, used by the
+	// app to match updates; it is not interpreted by the server.
+	ActivityID   string    `json:"activityId,omitempty"`
+	BundleID     string    `json:"bundleId,omitempty"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// ContentState is the activity's dynamic content. Its JSON must decode into
+// the app's ActivityAttributes.ContentState.
+type ContentState struct {
+	Phase          string `json:"phase"`
+	JobID          string `json:"jobId,omitempty"`
+	CustomerName   string `json:"customerName,omitempty"`
+	Address        string `json:"address,omitempty"`
+	StopsRemaining int    `json:"stopsRemaining"`
+	// StartedAt is when the current job started, for PhaseOnSite.
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// ETAStart and ETAEnd bound the arrival at the next stop, for
+	// PhaseEnRoute.
+	ETAStart *time.Time `json:"etaStart,omitempty"`
+	ETAEnd   *time.Time `json:"etaEnd,omitempty"`
+}
+
+// Update is the aps dictionary of a Live Activity push.
+type Update struct {
+	Timestamp    int64        `json:"timestamp"`
+	Event        string       `json:"event"`
+	ContentState ContentState `json:"content-state"`
+	// StaleDate marks the content out of date if no newer update arrives.
+	StaleDate int64 `json:"stale-date,omitempty"`
+	// DismissalDate removes an ended activity from the lock screen.
+	DismissalDate int64 `json:"dismissal-date,omitempty"`
+}
+
+// Sender delivers an update to one activity.
+type Sender interface {
+	Send(ctx context.Context, token Token, u Update) error
+}
+
+// LogSender logs updates instead of delivering them, for environments
+// without push credentials.
+type LogSender struct {
+	Logger *slog.Logger
+}
+
+// Send logs u.
+func (s LogSender) Send(_ context.Context, token Token, u Update) error {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("live activity update", slog.String("technician", token.TechnicianID), slog.String("event", u.Event), slog.String("phase", u.ContentState.Phase), slog.String("job", u.ContentState.JobID))
+	return nil
+}
+
+// Store persists activity tokens.
+type Store interface {
+	// SaveToken upserts a token keyed by its value.
+	SaveToken(t Token) error
+	// ListTokens returns a technician's tokens, most recently registered
+	// first.
+	ListTokens(technicianID string) ([]Token, error)
+	// DeleteToken removes a token. Unknown tokens are not an error.
+	DeleteToken(token string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tokens map[string]Token
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]Token)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveToken(t Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[t.Token] = t
+	return nil
+}
+
+func (m *MemoryStore) ListTokens(technicianID string) ([]Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Token
+	for _, t := range m.tokens {
+		if t.TechnicianID == technicianID {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RegisteredAt.After(out[j].RegisteredAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteToken(token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, token)
+	return nil
+}
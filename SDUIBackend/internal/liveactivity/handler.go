@@ -0,0 +1,88 @@
+package liveactivity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/validate"
+)
+
+// Registration is the payload the app sends after starting an activity.
+type Registration struct {
+	Token      string `json:"token"`
+	ActivityID string `json:"activityId"`
+	BundleID   string `json:"bundleId"`
+}
+
+// Validate reports every field of the registration that breaks a rule.
+func (p Registration) Validate() error {
+	var errs validate.Errors
+	errs.Required("token", p.Token)
+	return errs.Err()
+}
+
+// Handler exposes activity token registration to the app.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Live Activity handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Register stores the push token of an activity the app started for the
+// authenticated technician.
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var payload Registration
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid live activity", err.Error(), respond.WithErrors(err))
+		return
+	}
+	t, err := h.service.Register(Token{Token: payload.Token, TechnicianID: me, ActivityID: payload.ActivityID, BundleID: payload.BundleID})
+	if err != nil {
+		h.fail(w, r, "failed to register live activity", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, t)
+}
+
+// Delete removes one of the authenticated technician's activity tokens.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	if err := h.service.Unregister(me, chi.URLParam(r, "token")); err != nil {
+		h.fail(w, r, "failed to remove live activity", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	if errors.Is(err, ErrNotFound) {
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+		return
+	}
+	middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+	respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+}
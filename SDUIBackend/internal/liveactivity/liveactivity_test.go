@@ -0,0 +1,81 @@
+package liveactivity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+type recordingSender struct {
+	updates []Update
+}
+
+func (s *recordingSender) Send(_ context.Context, _ Token, u Update) error {
+	s.updates = append(s.updates, u)
+	return nil
+}
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time {
+	return day.Add(time.Duration(hour) * time.Hour)
+}
+
+func TestJobProgressUpdatesTheActivity(t *testing.T) {
+	store := storememory.NewStore()
+	_ = store.SaveRoute(domain.Route{
+		TechnicianID: "tech-1",
+		ServiceDate:  day,
+		CustomerStops: []domain.RouteStop{
+			{CustomerID: "c2", CustomerName: "Second", Address: "2 Elm St", WindowStart: at(10), WindowEnd: at(11)},
+			{CustomerID: "c1", CustomerName: "First", Address: "1 Oak St", WindowStart: at(8), WindowEnd: at(9)},
+		},
+	})
+	sender := &recordingSender{}
+	cfg := config.LiveActivityConfig{StaleAfter: 30 * time.Minute, DismissAfter: 15 * time.Minute, TokenTTL: 12 * time.Hour}
+	svc := NewService(cfg, NewMemoryStore(), repository.Repository{Routes: store}, sender, nil)
+	now := at(8)
+	svc.now = func() time.Time { return now }
+	if _, err := svc.Register(Token{Token: "act-1", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	ctx := context.Background()
+	job := domain.JobUpload{ID: "j1", TechnicianID: "tech-1", CustomerName: "First", ScheduledDate: day, Status: "inProgress"}
+	svc.JobUpdated(ctx, job)
+	job.Status = "completed"
+	now = at(9)
+	svc.JobUpdated(ctx, job)
+	svc.JobUpdated(ctx, domain.JobUpload{ID: "j2", TechnicianID: "tech-1", CustomerName: "Second", ScheduledDate: day, Status: "completed"})
+
+	if len(sender.updates) != 3 {
+		t.Fatalf("expected 3 updates, got %+v", sender.updates)
+	}
+	started, next, end := sender.updates[0], sender.updates[1], sender.updates[2]
+	if started.ContentState.Phase != PhaseOnSite || started.ContentState.StopsRemaining != 1 || started.StaleDate != at(8).Add(30*time.Minute).Unix() {
+		t.Errorf("unexpected job started update %+v", started)
+	}
+	if next.ContentState.Phase != PhaseEnRoute || next.ContentState.CustomerName != "Second" || !next.ContentState.ETAStart.Equal(at(10)) || !next.ContentState.ETAEnd.Equal(at(11)) {
+		t.Errorf("unexpected next stop update %+v", next)
+	}
+	if end.Event != EventEnd || end.ContentState.Phase != PhaseDone || end.DismissalDate != at(9).Add(15*time.Minute).Unix() {
+		t.Errorf("unexpected end update %+v", end)
+	}
+	if tokens, _ := svc.Tokens("tech-1"); len(tokens) != 0 {
+		t.Fatalf("expected the ended activity's token dropped, got %+v", tokens)
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(svc).Register(rec, httptest.NewRequest(http.MethodPost, "/v1/devices/live-activities?userId=tech-1", strings.NewReader(`{"activityId":"a1"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a registration without a token rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,199 @@
+package liveactivity
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Job statuses, as uploaded by the app, that move the activity on.
+var (
+	startedStatuses  = map[string]bool{"inProgress": true, "in_progress": true}
+	finishedStatuses = map[string]bool{"completed": true, "skipped": true}
+)
+
+// Service keeps activity tokens and pushes job progress to them.
+type Service struct {
+	cfg    config.LiveActivityConfig
+	store  Store
+	routes repository.RouteRepository
+	sender Sender
+	logger *slog.Logger
+	now    func() time.Time
+
+	sent   atomic.Int64
+	failed atomic.Int64
+}
+
+// NewService wires a Live Activity service. Updates follow the routes in
+// repos and go out through sender; a nil sender logs them.
+func NewService(cfg config.LiveActivityConfig, store Store, repos repository.Repository, sender Sender, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if sender == nil {
+		sender = LogSender{Logger: logger}
+	}
+	return &Service{cfg: cfg, store: store, routes: repos.Routes, sender: sender, logger: logger, now: time.Now}
+}
+
+// Register stores an activity token for the technician. Registering a
+// token again refreshes it.
+func (s *Service) Register(t Token) (Token, error) {
+	t.RegisteredAt = s.now().UTC()
+	if err := s.store.SaveToken(t); err != nil {
+		return Token{}, err
+	}
+	return t, nil
+}
+
+// Tokens returns the technician's live activity tokens, dropping any older
+// than TokenTTL.
+func (s *Service) Tokens(technicianID string) ([]Token, error) {
+	tokens, err := s.store.ListTokens(technicianID)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := s.now().Add(-s.cfg.TokenTTL)
+	live := tokens[:0]
+	for _, t := range tokens {
+		if t.RegisteredAt.Before(cutoff) {
+			if err := s.store.DeleteToken(t.Token); err != nil {
+				s.logger.Warn("delete expired live activity token", slog.String("technician", technicianID), slog.Any("error", err))
+			}
+			continue
+		}
+		live = append(live, t)
+	}
+	return live, nil
+}
+
+// Unregister removes one of the technician's tokens, for an activity the
+// user dismissed.
+func (s *Service) Unregister(technicianID, token string) error {
+	tokens, err := s.store.ListTokens(technicianID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if t.Token == token {
+			return s.store.DeleteToken(token)
+		}
+	}
+	return ErrNotFound
+}
+
+// JobUpdated moves the technician's activity on after an uploaded job
+// changes status: a started job is shown on site, and a finished one hands
+// over to the next stop on the route with its arrival window, or ends the
+// activity when none remain. Other statuses, and a nil service, are
+// ignored.
+func (s *Service) JobUpdated(ctx context.Context, job domain.JobUpload) {
+	if s == nil || job.TechnicianID == "" {
+		return
+	}
+	started, finished := startedStatuses[job.Status], finishedStatuses[job.Status]
+	if !started && !finished {
+		return
+	}
+	tokens, err := s.Tokens(job.TechnicianID)
+	if err != nil {
+		s.logger.Error("list live activity tokens", slog.String("technician", job.TechnicianID), slog.Any("error", err))
+		return
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	now := s.now().UTC()
+	ahead := s.stopsAfter(job, now)
+	u := Update{Timestamp: now.Unix(), Event: EventUpdate}
+	switch {
+	case started:
+		u.ContentState = ContentState{Phase: PhaseOnSite, JobID: job.ID, CustomerName: job.CustomerName, Address: job.Address, StopsRemaining: len(ahead), StartedAt: &now}
+	case len(ahead) > 0:
+		next := ahead[0]
+		u.ContentState = ContentState{Phase: PhaseEnRoute, CustomerName: next.CustomerName, Address: next.Address, StopsRemaining: len(ahead)}
+		u.ContentState.ETAStart, u.ContentState.ETAEnd = arrivalWindow(next, now)
+	default:
+		u.Event = EventEnd
+		u.ContentState = ContentState{Phase: PhaseDone, JobID: job.ID}
+		u.DismissalDate = now.Add(s.cfg.DismissAfter).Unix()
+	}
+	if u.Event == EventUpdate && s.cfg.StaleAfter > 0 {
+		u.StaleDate = now.Add(s.cfg.StaleAfter).Unix()
+	}
+
+	for _, t := range tokens {
+		if err := s.sender.Send(ctx, t, u); err != nil {
+			s.failed.Add(1)
+			s.logger.Warn("live activity update failed", slog.String("technician", t.TechnicianID), slog.Any("error", err))
+			continue
+		}
+		s.sent.Add(1)
+		if u.Event == EventEnd {
+			if err := s.store.DeleteToken(t.Token); err != nil {
+				s.logger.Warn("delete ended live activity token", slog.String("technician", t.TechnicianID), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// stopsAfter returns the route stops still ahead of job, in window order:
+// those after the job's own stop, matched by customer name or address, or
+// when it is not on the route, those whose window has not closed.
+func (s *Service) stopsAfter(job domain.JobUpload, now time.Time) []domain.RouteStop {
+	route, err := s.routes.GetRoute(job.TechnicianID, job.ScheduledDate)
+	if err != nil {
+		return nil
+	}
+	stops := append([]domain.RouteStop(nil), route.CustomerStops...)
+	sort.SliceStable(stops, func(i, j int) bool {
+		if stops[i].WindowStart.IsZero() || stops[j].WindowStart.IsZero() {
+			return false
+		}
+		return stops[i].WindowStart.Before(stops[j].WindowStart)
+	})
+	for i, stop := range stops {
+		if strings.EqualFold(stop.CustomerName, job.CustomerName) || (job.Address != "" && strings.EqualFold(stop.Address, job.Address)) {
+			return stops[i+1:]
+		}
+	}
+	ahead := stops[:0]
+	for _, stop := range stops {
+		if stop.WindowEnd.IsZero() || stop.WindowEnd.After(now) {
+			ahead = append(ahead, stop)
+		}
+	}
+	return ahead
+}
+
+// arrivalWindow estimates the arrival at stop: now, or its booked window if
+// that opens later, until the window closes.
+func arrivalWindow(stop domain.RouteStop, now time.Time) (*time.Time, *time.Time) {
+	start := now
+	if stop.WindowStart.After(start) {
+		start = stop.WindowStart.UTC()
+	}
+	if !stop.WindowEnd.After(start) {
+		return &start, nil
+	}
+	end := stop.WindowEnd.UTC()
+	return &start, &end
+}
+
+// Stats reports updates sent and failed.
+func (s *Service) Stats() map[string]int64 {
+	if s == nil {
+		return map[string]int64{}
+	}
+	return map[string]int64{"sent": s.sent.Load(), "failed": s.failed.Load()}
+}
@@ -0,0 +1,185 @@
+package localization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+)
+
+// maxBatch bounds the strings sent to the provider in one request; both
+// providers cap the size of a request.
+const maxBatch = 50
+
+// Glossary returns the protected terms.
+func (s *Service) Glossary() (Glossary, error) {
+	return s.store.GetGlossary()
+}
+
+// SaveGlossary replaces the protected terms. Existing drafts keep the
+// wording they were drafted with.
+func (s *Service) SaveGlossary(g Glossary) (Glossary, error) {
+	if err := g.Validate(); err != nil {
+		return Glossary{}, err
+	}
+	if g.Terms == nil {
+		g.Terms = []GlossaryTerm{}
+	}
+	g.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveGlossary(g); err != nil {
+		return Glossary{}, err
+	}
+	s.logger.Info("glossary saved", slog.Int("terms", len(g.Terms)))
+	return g, nil
+}
+
+// DraftTranslations machine translates sources, keyed by textKey, into
+// locale and adds them to the locale's draft. Keys the locale's uploaded
+// catalog has, reviewed entries and entries drafted from the same source
+// are kept as they are.
+func (s *Service) DraftTranslations(ctx context.Context, locale string, sources map[string]string) (Draft, error) {
+	if s.mt == nil {
+		return Draft{}, fmt.Errorf("%w: machine translation is not configured", ErrInvalidCatalog)
+	}
+	locale = i18n.Canonical(locale)
+	if locale == "" || locale == i18n.Canonical(s.cfg.SourceLocale) {
+		return Draft{}, fmt.Errorf("%w: cannot draft translations into the source locale %q", ErrInvalidCatalog, s.cfg.SourceLocale)
+	}
+	glossary, err := s.store.GetGlossary()
+	if err != nil {
+		return Draft{}, err
+	}
+	uploaded, err := s.store.GetCatalog(locale)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Draft{}, err
+	}
+
+	s.drafts.Lock()
+	defer s.drafts.Unlock()
+	d, err := s.store.GetDraft(locale)
+	switch {
+	case errors.Is(err, ErrDraftNotFound):
+		d = Draft{Locale: locale, Entries: make(map[string]DraftEntry), CreatedAt: s.now().UTC()}
+	case err != nil:
+		return Draft{}, err
+	}
+	d.Provider = s.mt.Name()
+
+	var keys []string
+	for key, source := range sources {
+		if _, ok := uploaded.Messages[key]; ok || strings.TrimSpace(source) == "" {
+			continue
+		}
+		if e, ok := d.Entries[key]; ok && (e.Reviewed || e.Source == source) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), maxBatch)]
+		keys = keys[len(batch):]
+		prepared := make([]protected, len(batch))
+		texts := make([]string, len(batch))
+		for i, key := range batch {
+			prepared[i] = glossary.protect(sources[key], locale)
+			texts[i] = prepared[i].markup
+		}
+		translated, err := s.mt.Translate(ctx, s.cfg.SourceLocale, locale, texts)
+		if err != nil {
+			return Draft{}, fmt.Errorf("machine translation: %w", err)
+		}
+		for i, key := range batch {
+			text, warnings := prepared[i].restore(translated[i])
+			d.Entries[key] = DraftEntry{Source: sources[key], Text: text, Warnings: warnings}
+		}
+	}
+
+	d.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveDraft(d); err != nil {
+		return Draft{}, err
+	}
+	s.logger.Info("translations drafted", slog.String("locale", locale), slog.String("provider", d.Provider), slog.Int("entries", len(d.Entries)))
+	return d, nil
+}
+
+// Draft returns a locale's draft.
+func (s *Service) Draft(locale string) (Draft, error) {
+	return s.store.GetDraft(i18n.Canonical(locale))
+}
+
+// ReviewDraft sets the text of drafted entries, keyed by textKey, and marks
+// them reviewed.
+func (s *Service) ReviewDraft(locale string, edits map[string]string) (Draft, error) {
+	s.drafts.Lock()
+	defer s.drafts.Unlock()
+	d, err := s.store.GetDraft(i18n.Canonical(locale))
+	if err != nil {
+		return Draft{}, err
+	}
+	var unknown []string
+	for key, text := range edits {
+		e, ok := d.Entries[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		e.Text, e.Reviewed = text, true
+		d.Entries[key] = e
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return Draft{}, fmt.Errorf("%w: the draft has no %s", ErrInvalidCatalog, strings.Join(unknown, ", "))
+	}
+	d.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveDraft(d); err != nil {
+		return Draft{}, err
+	}
+	return d, nil
+}
+
+// ApproveDraft merges a locale's draft into its catalog and discards the
+// draft. It fails with ErrUnreviewed while an entry with warnings has not
+// been reviewed.
+func (s *Service) ApproveDraft(locale string) (Catalog, error) {
+	locale = i18n.Canonical(locale)
+	s.drafts.Lock()
+	defer s.drafts.Unlock()
+	d, err := s.store.GetDraft(locale)
+	if err != nil {
+		return Catalog{}, err
+	}
+	var unreviewed []string
+	messages := make(map[string]string, len(d.Entries))
+	for key, e := range d.Entries {
+		if len(e.Warnings) > 0 && !e.Reviewed {
+			unreviewed = append(unreviewed, key)
+		}
+		messages[key] = e.Text
+	}
+	if len(unreviewed) > 0 {
+		sort.Strings(unreviewed)
+		return Catalog{}, fmt.Errorf("%w: %s", ErrUnreviewed, strings.Join(unreviewed, ", "))
+	}
+	c, err := s.Merge(locale, messages)
+	if err != nil {
+		return Catalog{}, err
+	}
+	if err := s.store.DeleteDraft(locale); err != nil {
+		return Catalog{}, err
+	}
+	return c, nil
+}
+
+// DeleteDraft discards a locale's draft.
+func (s *Service) DeleteDraft(locale string) error {
+	s.drafts.Lock()
+	defer s.drafts.Unlock()
+	return s.store.DeleteDraft(i18n.Canonical(locale))
+}
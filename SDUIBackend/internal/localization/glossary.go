@@ -0,0 +1,179 @@
+package localization
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+)
+
+// Glossary lists the terms machine translation must not touch: product
+// names, regulatory wording and the like.
+type Glossary struct {
+	Terms     []GlossaryTerm `json:"terms"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// GlossaryTerm is a protected term. It is kept verbatim unless Translations
+// has the approved wording for the target locale or its language.
+type GlossaryTerm struct {
+	Term         string            `json:"term"`
+	Translations map[string]string `json:"translations,omitempty"`
+	Note         string            `json:"note,omitempty"`
+}
+
+// Validate checks for empty and duplicate terms.
+func (g Glossary) Validate() error {
+	var problems []string
+	seen := make(map[string]bool)
+	for i, t := range g.Terms {
+		switch {
+		case strings.TrimSpace(t.Term) == "":
+			problems = append(problems, fmt.Sprintf("terms[%d]: term is required", i))
+		case seen[t.Term]:
+			problems = append(problems, fmt.Sprintf("terms[%d]: %q is listed twice", i, t.Term))
+		}
+		seen[t.Term] = true
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidCatalog, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// placeholderPattern matches the {{key}} placeholders screens interpolate.
+var placeholderPattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// markerPattern matches the markers protect puts in place of protected
+// text. Both providers pass markup through untranslated.
+var markerPattern = regexp.MustCompile(`<x\s+i="(\d+)"\s*/>`)
+
+// protected is a source string prepared for machine translation: markup
+// with a marker for each placeholder and glossary term, and what each
+// marker stands for in the target locale.
+type protected struct {
+	markup string
+	values []string
+	labels []string
+}
+
+// span is a protected part of a source string.
+type span struct {
+	start, end int
+	value      string
+	label      string
+}
+
+// protect escapes text as markup and replaces its placeholders and the
+// glossary's terms, matched case-sensitively on word boundaries, with
+// markers.
+func (g Glossary) protect(text, locale string) protected {
+	var spans []span
+	for _, loc := range placeholderPattern.FindAllStringIndex(text, -1) {
+		p := text[loc[0]:loc[1]]
+		spans = append(spans, span{loc[0], loc[1], p, "placeholder " + p})
+	}
+	// Longer terms first, so "Termidor SC" wins over "Termidor".
+	terms := append([]GlossaryTerm(nil), g.Terms...)
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i].Term) > len(terms[j].Term) })
+	for _, t := range terms {
+		for from := 0; ; {
+			i := strings.Index(text[from:], t.Term)
+			if i < 0 {
+				break
+			}
+			start, end := from+i, from+i+len(t.Term)
+			from = end
+			if !wordBoundary(text, start, end) || overlaps(spans, start, end) {
+				continue
+			}
+			spans = append(spans, span{start, end, t.translation(locale), "glossary term " + t.Term})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	p := protected{}
+	last := 0
+	for i, s := range spans {
+		b.WriteString(html.EscapeString(text[last:s.start]))
+		b.WriteString(`<x i="` + strconv.Itoa(i) + `"/>`)
+		p.values = append(p.values, s.value)
+		p.labels = append(p.labels, s.label)
+		last = s.end
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	p.markup = b.String()
+	return p
+}
+
+// restore turns translated markup back into text, putting the protected
+// values in place of their markers. Markers the translation lost are
+// reported as warnings.
+func (p protected) restore(markup string) (string, []string) {
+	var b strings.Builder
+	used := make([]bool, len(p.values))
+	last := 0
+	for _, m := range markerPattern.FindAllStringSubmatchIndex(markup, -1) {
+		b.WriteString(html.UnescapeString(markup[last:m[0]]))
+		i, err := strconv.Atoi(markup[m[2]:m[3]])
+		if err == nil && i < len(p.values) {
+			b.WriteString(p.values[i])
+			used[i] = true
+		}
+		last = m[1]
+	}
+	b.WriteString(html.UnescapeString(markup[last:]))
+
+	var warnings []string
+	for i, ok := range used {
+		if !ok {
+			warnings = append(warnings, "translation lost the "+p.labels[i])
+		}
+	}
+	return b.String(), warnings
+}
+
+// translation is the term's approved wording for locale, or the term.
+func (t GlossaryTerm) translation(locale string) string {
+	for _, l := range i18n.Chain(locale, "") {
+		for k, v := range t.Translations {
+			if i18n.Canonical(k) == l && v != "" {
+				return v
+			}
+		}
+	}
+	return t.Term
+}
+
+// wordBoundary reports whether text[start:end] is not part of a longer
+// word.
+func wordBoundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWord(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWord(r) {
+		return false
+	}
+	return true
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func overlaps(spans []span, start, end int) bool {
+	for _, s := range spans {
+		if start < s.end && s.start < end {
+			return true
+		}
+	}
+	return false
+}
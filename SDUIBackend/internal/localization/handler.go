@@ -0,0 +1,272 @@
+package localization
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// maxCatalogBytes bounds an uploaded catalog.
+const maxCatalogBytes = 4 << 20
+
+// StringSource supplies the source text of a screen's strings that a
+// locale does not translate yet. found is false when the screen or version
+// does not exist.
+type StringSource interface {
+	UntranslatedStrings(screenID string, version int, locale string) (sources map[string]string, found bool, err error)
+}
+
+// Handler exposes catalog uploads, the glossary and machine translation
+// drafts.
+type Handler struct {
+	service *Service
+	sources StringSource
+}
+
+// NewHandler creates a localization handler. sources may be nil, in which
+// case drafts are requested with the strings themselves.
+func NewHandler(service *Service, sources StringSource) *Handler {
+	return &Handler{service: service, sources: sources}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/glossary", h.GetGlossary)
+	r.Put("/glossary", h.SaveGlossary)
+	r.Get("/{locale}/draft", h.GetDraft)
+	r.Post("/{locale}/draft", h.CreateDraft)
+	r.Patch("/{locale}/draft", h.ReviewDraft)
+	r.Delete("/{locale}/draft", h.DeleteDraft)
+	r.Post("/{locale}/draft/approve", h.ApproveDraft)
+	r.Get("/", h.ListCatalogs)
+	r.Get("/{locale}", h.GetCatalog)
+	r.Put("/{locale}", h.ReplaceCatalog)
+	r.Patch("/{locale}", h.MergeCatalog)
+	r.Delete("/{locale}", h.DeleteCatalog)
+}
+
+// ListCatalogs summarises the uploaded catalogs.
+func (h *Handler) ListCatalogs(w http.ResponseWriter, r *http.Request) {
+	catalogs, err := h.service.Catalogs()
+	if err != nil {
+		h.fail(w, r, "failed to list translations", err)
+		return
+	}
+	type summary struct {
+		Locale    string    `json:"locale"`
+		Keys      int       `json:"keys"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+	out := make([]summary, 0, len(catalogs))
+	for _, c := range catalogs {
+		out = append(out, summary{Locale: c.Locale, Keys: len(c.Messages), UpdatedAt: c.UpdatedAt})
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"catalogs": out})
+}
+
+// GetCatalog returns a locale's uploaded catalog.
+func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Catalog(chi.URLParam(r, "locale"))
+	if err != nil {
+		h.fail(w, r, "failed to load translations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// ReplaceCatalog uploads a locale's catalog in place of the previous one.
+func (h *Handler) ReplaceCatalog(w http.ResponseWriter, r *http.Request) {
+	messages, ok := readMessages(w, r)
+	if !ok {
+		return
+	}
+	c, err := h.service.Replace(chi.URLParam(r, "locale"), messages)
+	if err != nil {
+		h.fail(w, r, "failed to upload translations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// MergeCatalog adds keys to a locale's catalog.
+func (h *Handler) MergeCatalog(w http.ResponseWriter, r *http.Request) {
+	messages, ok := readMessages(w, r)
+	if !ok {
+		return
+	}
+	c, err := h.service.Merge(chi.URLParam(r, "locale"), messages)
+	if err != nil {
+		h.fail(w, r, "failed to upload translations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// DeleteCatalog removes a locale's uploaded catalog.
+func (h *Handler) DeleteCatalog(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(chi.URLParam(r, "locale")); err != nil {
+		h.fail(w, r, "failed to delete translations", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGlossary returns the protected terms.
+func (h *Handler) GetGlossary(w http.ResponseWriter, r *http.Request) {
+	g, err := h.service.Glossary()
+	if err != nil {
+		h.fail(w, r, "failed to load glossary", err)
+		return
+	}
+	if g.Terms == nil {
+		g.Terms = []GlossaryTerm{}
+	}
+	respond.JSON(w, http.StatusOK, g)
+}
+
+// SaveGlossary replaces the protected terms.
+func (h *Handler) SaveGlossary(w http.ResponseWriter, r *http.Request) {
+	var g Glossary
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCatalogBytes)).Decode(&g); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	g, err := h.service.SaveGlossary(g)
+	if err != nil {
+		h.fail(w, r, "failed to save glossary", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, g)
+}
+
+// CreateDraft machine translates a screen's untranslated strings, or the
+// strings given, into the locale's draft.
+func (h *Handler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ScreenID string            `json:"screenId"`
+		Version  int               `json:"version"`
+		Strings  map[string]string `json:"strings"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCatalogBytes)).Decode(&body); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+	sources := body.Strings
+	switch {
+	case body.ScreenID != "" && h.sources != nil:
+		untranslated, found, err := h.sources.UntranslatedStrings(body.ScreenID, body.Version, locale)
+		if err != nil {
+			h.fail(w, r, "failed to draft translations", err)
+			return
+		}
+		if !found {
+			respond.Error(w, http.StatusNotFound, "failed to draft translations", "screen not found")
+			return
+		}
+		sources = untranslated
+	case body.ScreenID != "":
+		respond.Error(w, http.StatusBadRequest, "invalid payload", "drafting from a screen is not available")
+		return
+	case len(sources) == 0:
+		respond.Error(w, http.StatusBadRequest, "invalid payload", "screenId or strings is required")
+		return
+	}
+	d, err := h.service.DraftTranslations(r.Context(), locale, sources)
+	if err != nil {
+		h.fail(w, r, "failed to draft translations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// GetDraft returns a locale's draft.
+func (h *Handler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Draft(chi.URLParam(r, "locale"))
+	if err != nil {
+		h.fail(w, r, "failed to load draft", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// ReviewDraft sets reviewed text for drafted keys.
+func (h *Handler) ReviewDraft(w http.ResponseWriter, r *http.Request) {
+	var edits map[string]string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCatalogBytes)).Decode(&edits); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	d, err := h.service.ReviewDraft(chi.URLParam(r, "locale"), edits)
+	if err != nil {
+		h.fail(w, r, "failed to review draft", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// ApproveDraft merges a locale's draft into its catalog.
+func (h *Handler) ApproveDraft(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.ApproveDraft(chi.URLParam(r, "locale"))
+	if err != nil {
+		h.fail(w, r, "failed to approve draft", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// DeleteDraft discards a locale's draft.
+func (h *Handler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteDraft(chi.URLParam(r, "locale")); err != nil {
+		h.fail(w, r, "failed to delete draft", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readMessages reads an uploaded catalog: a gettext PO file when the
+// Content-Type says so, otherwise a JSON object of strings, flat or nested
+// like the files in SDUI_TRANSLATIONS_DIR.
+func readMessages(w http.ResponseWriter, r *http.Request) (map[string]string, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCatalogBytes))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return nil, false
+	}
+	var messages map[string]string
+	if ct := r.Header.Get("Content-Type"); strings.Contains(ct, "gettext") || strings.Contains(ct, "x-po") {
+		messages, err = i18n.ParsePO(body)
+	} else {
+		messages, err = i18n.ParseJSON(body)
+	}
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return nil, false
+	}
+	return messages, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrDraftNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrUnreviewed):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	case errors.Is(err, ErrInvalidCatalog):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,185 @@
+// Package localization manages the translation catalogs uploaded through
+// the admin API. Uploaded catalogs are layered over the ones in
+// SDUI_TRANSLATIONS_DIR: a key uploaded for a locale wins over the file's.
+// Screens hear about every change through OnChange hooks, so translators
+// can fix a string without a deploy. An optional machine translation
+// provider drafts missing strings for review, keeping glossary terms and
+// placeholders intact.
+package localization
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no catalog was uploaded for a locale.
+	ErrNotFound = errors.New("catalog not found")
+	// ErrInvalidCatalog wraps catalog validation failures.
+	ErrInvalidCatalog = errors.New("invalid catalog")
+	// ErrDraftNotFound is returned when a locale has no machine translation
+	// draft.
+	ErrDraftNotFound = errors.New("draft not found")
+	// ErrUnreviewed is returned when a draft is approved with entries that
+	// need a human look.
+	ErrUnreviewed = errors.New("draft has unreviewed entries")
+)
+
+// Catalog is the uploaded translations of one locale.
+type Catalog struct {
+	Locale    string            `json:"locale"`
+	Messages  map[string]string `json:"messages"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// Validate checks the catalog's keys. Empty translations are allowed: they
+// are how a translator marks a string as deliberately blank.
+func (c Catalog) Validate() error {
+	var problems []string
+	if c.Locale == "" {
+		problems = append(problems, "locale is required")
+	}
+	for key := range c.Messages {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, " \t\n") {
+			problems = append(problems, fmt.Sprintf("key %q must be a non-empty word", key))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidCatalog, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Draft is a machine translation of a locale's missing strings, waiting
+// for review. Approving it merges its entries into the locale's catalog.
+type Draft struct {
+	Locale    string                `json:"locale"`
+	Provider  string                `json:"provider"`
+	Entries   map[string]DraftEntry `json:"entries"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// DraftEntry is one drafted string.
+type DraftEntry struct {
+	Source   string `json:"source"`
+	Text     string `json:"text"`
+	Reviewed bool   `json:"reviewed"`
+	// Warnings flag what the provider lost, such as a placeholder or a
+	// glossary term. An entry with warnings must be reviewed.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Store persists uploaded catalogs, the glossary and drafts.
+type Store interface {
+	SaveCatalog(c Catalog) error
+	GetCatalog(locale string) (Catalog, error)
+	ListCatalogs() ([]Catalog, error)
+	DeleteCatalog(locale string) error
+
+	SaveGlossary(g Glossary) error
+	// GetGlossary returns an empty glossary when none was saved.
+	GetGlossary() (Glossary, error)
+
+	SaveDraft(d Draft) error
+	GetDraft(locale string) (Draft, error)
+	DeleteDraft(locale string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	catalogs map[string]Catalog
+	glossary Glossary
+	drafts   map[string]Draft
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{catalogs: make(map[string]Catalog), drafts: make(map[string]Draft)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveCatalog(c Catalog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.catalogs[c.Locale] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCatalog(locale string) (Catalog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.catalogs[locale]
+	if !ok {
+		return Catalog{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) ListCatalogs() ([]Catalog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Catalog, 0, len(m.catalogs))
+	for _, c := range m.catalogs {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Locale < out[j].Locale })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteCatalog(locale string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.catalogs[locale]; !ok {
+		return ErrNotFound
+	}
+	delete(m.catalogs, locale)
+	return nil
+}
+
+func (m *MemoryStore) SaveGlossary(g Glossary) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.glossary = g
+	return nil
+}
+
+func (m *MemoryStore) GetGlossary() (Glossary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.glossary, nil
+}
+
+func (m *MemoryStore) SaveDraft(d Draft) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drafts[d.Locale] = d
+	return nil
+}
+
+func (m *MemoryStore) GetDraft(locale string) (Draft, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.drafts[locale]
+	if !ok {
+		return Draft{}, ErrDraftNotFound
+	}
+	return d, nil
+}
+
+func (m *MemoryStore) DeleteDraft(locale string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.drafts[locale]; !ok {
+		return ErrDraftNotFound
+	}
+	delete(m.drafts, locale)
+	return nil
+}
@@ -0,0 +1,188 @@
+package localization
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func TestUploadsNotifyHooks(t *testing.T) {
+	svc := NewService(config.TranslationConfig{}, NewMemoryStore(), nil, nil)
+	heard := make(map[string]map[string]string)
+	svc.OnChange(func(locale string, messages map[string]string) { heard[locale] = messages })
+
+	if _, err := svc.Replace("es_mx", map[string]string{"a": "uno", "b": "dos"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c, err := svc.Merge("es-MX", map[string]string{"b": "DOS", "c": "tres"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Locale != "es-MX" || len(c.Messages) != 3 || c.Messages["b"] != "DOS" || heard["es-MX"]["c"] != "tres" {
+		t.Fatalf("expected merged keys reported to hooks, got %+v, heard %+v", c, heard)
+	}
+	if c, _ := svc.Replace("es-MX", map[string]string{"a": "uno"}); len(c.Messages) != 1 {
+		t.Errorf("expected replace to drop missing keys, got %+v", c)
+	}
+	if _, err := svc.Replace("es", map[string]string{"bad key": "x"}); !errors.Is(err, ErrInvalidCatalog) {
+		t.Errorf("expected invalid catalog, got %v", err)
+	}
+	if err := svc.Delete("es-MX"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if messages, ok := heard["es-MX"]; !ok || messages != nil {
+		t.Errorf("expected deletion reported with nil messages, got %+v", messages)
+	}
+	if _, err := svc.Catalog("es-MX"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestUploadPO(t *testing.T) {
+	svc := NewService(config.TranslationConfig{}, NewMemoryStore(), nil, nil)
+	r := chi.NewRouter()
+	r.Route("/translations", NewHandler(svc, nil).Routes)
+
+	body := "msgid \"\"\nmsgstr \"Language: fr\\n\"\n\nmsgid \"home.title\"\nmsgstr \"Aujourd'hui\"\n"
+	req := httptest.NewRequest(http.MethodPut, "/translations/fr", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/x-gettext-translation")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if c, err := svc.Catalog("fr"); err != nil || c.Messages["home.title"] != "Aujourd'hui" {
+		t.Fatalf("expected the PO entries stored, got %+v, %v", c, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/translations/fr", strings.NewReader(`{"home":{"title":1}}`))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-string value, got %d", rec.Code)
+	}
+}
+
+// upperTranslator "translates" by upper-casing text outside markup and can
+// be told to drop a marker.
+type upperTranslator struct {
+	drop  string
+	calls int
+}
+
+func (*upperTranslator) Name() string { return "fake" }
+
+func (f *upperTranslator) Translate(_ context.Context, _, _ string, texts []string) ([]string, error) {
+	f.calls++
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		parts := markerPattern.Split(t, -1)
+		markers := markerPattern.FindAllString(t, -1)
+		var b strings.Builder
+		for j, p := range parts {
+			b.WriteString(strings.ToUpper(p))
+			if j < len(markers) && markers[j] != f.drop {
+				b.WriteString(markers[j])
+			}
+		}
+		out[i] = b.String()
+	}
+	return out, nil
+}
+
+func TestDraftProtectsGlossaryAndPlaceholders(t *testing.T) {
+	mt := &upperTranslator{}
+	svc := NewService(config.TranslationConfig{SourceLocale: "en"}, NewMemoryStore(), mt, nil)
+	if _, err := svc.SaveGlossary(Glossary{Terms: []GlossaryTerm{
+		{Term: "Termidor"},
+		{Term: "Termidor SC"},
+		{Term: "restricted use", Translations: map[string]string{"es": "uso restringido"}},
+	}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Replace("es-MX", map[string]string{"done": "Listo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d, err := svc.DraftTranslations(context.Background(), "es_MX", map[string]string{
+		"apply": "Apply Termidor SC at {{site}} & Termidorish areas",
+		"label": "A restricted use product",
+		"done":  "Done",
+		"blank": " ",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := d.Entries["apply"].Text; got != "APPLY Termidor SC AT {{site}} & TERMIDORISH AREAS" {
+		t.Errorf("expected the term and placeholder kept, got %q", got)
+	}
+	if got := d.Entries["label"].Text; got != "A uso restringido PRODUCT" {
+		t.Errorf("expected the approved wording for the language, got %q", got)
+	}
+	if _, ok := d.Entries["done"]; ok {
+		t.Error("expected keys the catalog has to be skipped")
+	}
+	if _, ok := d.Entries["blank"]; ok {
+		t.Error("expected blank sources to be skipped")
+	}
+
+	// Drafting again leaves unchanged sources alone.
+	if _, err := svc.DraftTranslations(context.Background(), "es-MX", map[string]string{"label": "A restricted use product"}); err != nil || mt.calls != 1 {
+		t.Errorf("expected no second provider call, got %d calls, %v", mt.calls, err)
+	}
+	if _, err := svc.DraftTranslations(context.Background(), "en", map[string]string{"x": "y"}); !errors.Is(err, ErrInvalidCatalog) {
+		t.Errorf("expected drafting into the source locale to be refused, got %v", err)
+	}
+}
+
+func TestApproveDraftRequiresReviewOfWarnings(t *testing.T) {
+	mt := &upperTranslator{drop: `<x i="0"/>`}
+	svc := NewService(config.TranslationConfig{SourceLocale: "en"}, NewMemoryStore(), mt, nil)
+	r := chi.NewRouter()
+	r.Route("/translations", NewHandler(svc, nil).Routes)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/translations/fr/draft", `{"strings":{"greet":"Hello {{name}}","bye":"Goodbye"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	d, _ := svc.Draft("fr")
+	if w := d.Entries["greet"].Warnings; len(w) != 1 || !strings.Contains(w[0], "{{name}}") {
+		t.Fatalf("expected a warning for the lost placeholder, got %+v", d.Entries["greet"])
+	}
+	if rec := do(http.MethodPost, "/translations/fr/draft/approve", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while a warning is unreviewed, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/translations/fr/draft", `{"nope":"x"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown key, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/translations/fr/draft", `{"greet":"Bonjour {{name}}"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "/translations/fr/draft/approve", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	c, err := svc.Catalog("fr")
+	if err != nil || c.Messages["greet"] != "Bonjour {{name}}" || c.Messages["bye"] != "GOODBYE" {
+		t.Fatalf("expected the draft merged, got %+v, %v", c, err)
+	}
+	if _, err := svc.Draft("fr"); !errors.Is(err, ErrDraftNotFound) {
+		t.Errorf("expected the draft discarded, got %v", err)
+	}
+
+	off := NewService(config.TranslationConfig{}, NewMemoryStore(), nil, nil)
+	if _, err := off.DraftTranslations(context.Background(), "fr", map[string]string{"a": "b"}); !errors.Is(err, ErrInvalidCatalog) {
+		t.Errorf("expected an error without a provider, got %v", err)
+	}
+}
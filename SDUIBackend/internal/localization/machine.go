@@ -0,0 +1,160 @@
+package localization
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Public endpoints of the supported providers.
+const (
+	DeepLURL  = "https://api.deepl.com/v2/translate"
+	GoogleURL = "https://translation.googleapis.com/language/translate/v2"
+)
+
+// MachineTranslator translates markup from one locale to another. Texts
+// carry <x i="N"/> markers that must come back unchanged.
+type MachineTranslator interface {
+	Name() string
+	Translate(ctx context.Context, source, target string, texts []string) ([]string, error)
+}
+
+// NewMachineTranslator returns the provider selected by cfg, or nil when
+// machine translation is disabled.
+func NewMachineTranslator(cfg config.TranslationConfig, secrets secret.Provider) MachineTranslator {
+	client := &http.Client{Timeout: cfg.RequestTimeout}
+	switch cfg.Provider {
+	case "deepl":
+		return DeepLTranslator{URL: orDefault(cfg.URL, DeepLURL), KeySecret: cfg.KeySecret, Secrets: secrets, Client: client}
+	case "google":
+		return GoogleTranslator{URL: orDefault(cfg.URL, GoogleURL), KeySecret: cfg.KeySecret, Secrets: secrets, Client: client}
+	default:
+		return nil
+	}
+}
+
+// DeepLTranslator calls the DeepL v2 API with XML tag handling.
+type DeepLTranslator struct {
+	URL       string
+	KeySecret string // secret name of the auth key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (DeepLTranslator) Name() string { return "deepl" }
+
+func (t DeepLTranslator) Translate(ctx context.Context, source, target string, texts []string) ([]string, error) {
+	key, err := t.Secrets.Get(t.KeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("deepl key: %w", err)
+	}
+	// DeepL takes a bare source language and upper-case target codes such
+	// as PT-BR.
+	lang, _, _ := strings.Cut(source, "-")
+	body := map[string]any{
+		"text":         texts,
+		"source_lang":  strings.ToUpper(lang),
+		"target_lang":  strings.ToUpper(target),
+		"tag_handling": "xml",
+	}
+	var out struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := post(ctx, t.Client, t.URL, map[string]string{"Authorization": "DeepL-Auth-Key " + key}, body, &out); err != nil {
+		return nil, fmt.Errorf("deepl: %w", err)
+	}
+	translated := make([]string, 0, len(out.Translations))
+	for _, tr := range out.Translations {
+		translated = append(translated, tr.Text)
+	}
+	return checkCount(translated, texts)
+}
+
+// GoogleTranslator calls the Cloud Translation v2 API in HTML mode.
+type GoogleTranslator struct {
+	URL       string
+	KeySecret string // secret name of the API key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (GoogleTranslator) Name() string { return "google" }
+
+func (t GoogleTranslator) Translate(ctx context.Context, source, target string, texts []string) ([]string, error) {
+	key, err := t.Secrets.Get(t.KeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("google translate key: %w", err)
+	}
+	body := map[string]any{"q": texts, "source": source, "target": target, "format": "html"}
+	var out struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := post(ctx, t.Client, t.URL, map[string]string{"X-Goog-Api-Key": key}, body, &out); err != nil {
+		return nil, fmt.Errorf("google translate: %w", err)
+	}
+	translated := make([]string, 0, len(out.Data.Translations))
+	for _, tr := range out.Data.Translations {
+		translated = append(translated, tr.TranslatedText)
+	}
+	return checkCount(translated, texts)
+}
+
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func checkCount(translated, texts []string) ([]string, error) {
+	if len(translated) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(translated), len(texts))
+	}
+	return translated, nil
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
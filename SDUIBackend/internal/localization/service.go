@@ -0,0 +1,114 @@
+package localization
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+)
+
+// Service manages uploaded catalogs and machine translation drafts.
+type Service struct {
+	cfg    config.TranslationConfig
+	store  Store
+	mt     MachineTranslator // nil when machine translation is off
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	hooks []func(locale string, messages map[string]string)
+
+	drafts sync.Mutex // serialises draft updates
+}
+
+// NewService wires a localization service over store. mt may be nil.
+func NewService(cfg config.TranslationConfig, store Store, mt MachineTranslator, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, mt: mt, logger: logger, now: time.Now}
+}
+
+// OnChange registers fn to be called with a locale's uploaded messages
+// whenever they change; a deleted catalog is reported with nil messages.
+// Hooks run on the uploading goroutine.
+func (s *Service) OnChange(fn func(locale string, messages map[string]string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// Replace uploads locale's catalog, dropping keys the upload leaves out.
+func (s *Service) Replace(locale string, messages map[string]string) (Catalog, error) {
+	return s.save(Catalog{Locale: i18n.Canonical(locale), Messages: messages})
+}
+
+// Merge adds messages to locale's catalog, keeping the keys they leave out.
+func (s *Service) Merge(locale string, messages map[string]string) (Catalog, error) {
+	c := Catalog{Locale: i18n.Canonical(locale), Messages: make(map[string]string)}
+	existing, err := s.store.GetCatalog(c.Locale)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return Catalog{}, err
+	default:
+		for k, v := range existing.Messages {
+			c.Messages[k] = v
+		}
+	}
+	for k, v := range messages {
+		c.Messages[k] = v
+	}
+	return s.save(c)
+}
+
+func (s *Service) save(c Catalog) (Catalog, error) {
+	if c.Messages == nil {
+		c.Messages = map[string]string{}
+	}
+	if err := c.Validate(); err != nil {
+		return Catalog{}, err
+	}
+	c.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveCatalog(c); err != nil {
+		return Catalog{}, err
+	}
+	s.logger.Info("translations uploaded", slog.String("locale", c.Locale), slog.Int("keys", len(c.Messages)))
+	s.changed(c.Locale, c.Messages)
+	return c, nil
+}
+
+// Catalog returns a locale's uploaded catalog.
+func (s *Service) Catalog(locale string) (Catalog, error) {
+	return s.store.GetCatalog(i18n.Canonical(locale))
+}
+
+// Catalogs lists every uploaded catalog.
+func (s *Service) Catalogs() ([]Catalog, error) {
+	return s.store.ListCatalogs()
+}
+
+// Delete removes a locale's uploaded catalog; the locale falls back to its
+// file, if any.
+func (s *Service) Delete(locale string) error {
+	locale = i18n.Canonical(locale)
+	if err := s.store.DeleteCatalog(locale); err != nil {
+		return err
+	}
+	s.logger.Info("translations deleted", slog.String("locale", locale))
+	s.changed(locale, nil)
+	return nil
+}
+
+func (s *Service) changed(locale string, messages map[string]string) {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	for _, hook := range hooks {
+		hook(locale, messages)
+	}
+}
@@ -0,0 +1,59 @@
+package lockout
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes lockouts in the admin API.
+type Handler struct {
+	tracker *Tracker
+}
+
+// NewHandler creates a lockout handler.
+func NewHandler(tracker *Tracker) *Handler {
+	return &Handler{tracker: tracker}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListAttempts)
+	r.Delete("/{key}", h.Unlock)
+}
+
+// ListAttempts returns the IPs and identities with recent failed attempts,
+// including those slowed down or locked out.
+func (h *Handler) ListAttempts(w http.ResponseWriter, r *http.Request) {
+	attempts, err := h.tracker.Attempts()
+	if err != nil {
+		h.fail(w, r, "failed to list lockouts", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"attempts": attempts})
+}
+
+// Unlock lifts a lockout, such as ip:203.0.113.9, before it expires.
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
+	if err := h.tracker.Unlock(chi.URLParam(r, "key")); err != nil {
+		h.fail(w, r, "failed to unlock", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, "no failed attempts are recorded for the key")
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
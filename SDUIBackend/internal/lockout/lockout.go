@@ -0,0 +1,154 @@
+// Package lockout slows down and then locks out credential guessing at
+// the sign-in endpoint. Failed sign-ins are counted per client IP and per
+// identity they name, in the shared datastore, with attempts still in
+// progress counted alongside them. Past a threshold each further attempt
+// must wait progressively longer, and past a second one the IP or identity
+// is locked out for a while. Lockouts are recorded in the activity feed and
+// published as auth.locked_out events to alert on, and admins can lift
+// them early.
+package lockout
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// ErrNotFound is returned when a key has no failed attempts.
+var ErrNotFound = errors.New("not found")
+
+// Keys name what attempts are counted against.
+const (
+	ipPrefix       = "ip:"
+	identityPrefix = "identity:"
+)
+
+// IPKey is the key of attempts from a client IP.
+func IPKey(ip string) string { return ipPrefix + ip }
+
+// IdentityKey is the key of attempts on an identity, such as a technician
+// ID or email.
+func IdentityKey(id string) string { return identityPrefix + id }
+
+// Attempts are the recent failed authentications of a key.
+type Attempts struct {
+	Key      string    `json:"key"`
+	Failures int       `json:"failures"`
+	FirstAt  time.Time `json:"firstAt"`
+	LastAt   time.Time `json:"lastAt"`
+	// RetryAt is when the next attempt is let through, while attempts are
+	// being slowed down.
+	RetryAt     *time.Time `json:"retryAt,omitempty"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	// ExpiresAt is when the attempts are forgotten.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store persists failed attempts.
+type Store interface {
+	// UpdateAttempts saves what fn returns for a key's attempts, which are
+	// nil when it has none, without losing concurrent updates: fn may be
+	// called again with newer attempts.
+	UpdateAttempts(key string, fn func(current *Attempts) Attempts) (Attempts, error)
+	GetAttempts(key string) (Attempts, error)
+	ListAttempts() ([]Attempts, error)
+	DeleteAttempts(key string) error
+}
+
+// DocumentStore keeps failed attempts in the shared document store, so
+// every instance counts the same attempts and honours the same lockouts.
+type DocumentStore struct {
+	attempts docstore.Collection[Attempts]
+	now      func() time.Time
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+// NewDocumentStore keeps failed attempts in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{attempts: docstore.NewCollection[Attempts](docs, "auth_attempts", nil), now: time.Now}
+}
+
+// NewMemoryStore keeps failed attempts in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+// pruneInterval is how often an instance drops expired attempts.
+const pruneInterval = time.Minute
+
+func (d *DocumentStore) UpdateAttempts(key string, fn func(current *Attempts) Attempts) (Attempts, error) {
+	if err := d.prune(); err != nil {
+		return Attempts{}, err
+	}
+	return d.attempts.Update(key, func(current *Attempts) (Attempts, error) {
+		return fn(current), nil
+	})
+}
+
+// prune deletes expired attempts, at most every pruneInterval.
+func (d *DocumentStore) prune() error {
+	d.mu.Lock()
+	now := d.now()
+	due := now.Sub(d.prunedAt) >= pruneInterval
+	if due {
+		d.prunedAt = now
+	}
+	d.mu.Unlock()
+	if !due {
+		return nil
+	}
+	all, err := d.attempts.Find(nil)
+	if err != nil {
+		return err
+	}
+	for _, a := range all {
+		if !now.Before(a.ExpiresAt) {
+			if err := d.attempts.Delete(a.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *DocumentStore) GetAttempts(key string) (Attempts, error) {
+	a, err := d.attempts.Get(key)
+	if errors.Is(err, docstore.ErrNotFound) || (err == nil && !d.now().Before(a.ExpiresAt)) {
+		return Attempts{}, ErrNotFound
+	}
+	return a, err
+}
+
+// ListAttempts returns the unexpired attempts ordered by key.
+func (d *DocumentStore) ListAttempts() ([]Attempts, error) {
+	all, err := d.attempts.Find(nil)
+	if err != nil {
+		return nil, err
+	}
+	now := d.now()
+	out := make([]Attempts, 0, len(all))
+	for _, a := range all {
+		if now.Before(a.ExpiresAt) {
+			out = append(out, a)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (d *DocumentStore) DeleteAttempts(key string) error {
+	if _, err := d.attempts.Get(key); err != nil {
+		if errors.Is(err, docstore.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return d.attempts.Delete(key)
+}
@@ -0,0 +1,269 @@
+package lockout
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+var testNow = time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestTracker(t *testing.T) (*Tracker, *activity.MemoryStore, *time.Time) {
+	t.Helper()
+	now := testNow
+	clock := func() time.Time { return now }
+	store := NewMemoryStore()
+	store.now = clock
+	events := activity.NewMemoryStore()
+	feed := activity.NewService(config.ActivityConfig{MaxEvents: 100}, events, repository.Repository{}, nil)
+	cfg := config.LockoutConfig{Enabled: true, Window: 15 * time.Minute, DelayAfter: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second, LockAfter: 5, LockFor: 10 * time.Minute}
+	tr := NewTracker(cfg, store, feed, nil, nil)
+	tr.now = clock
+	return tr, events, &now
+}
+
+func TestTrackerSlowsDownThenLocksOut(t *testing.T) {
+	tr, events, now := newTestTracker(t)
+	ctx := context.Background()
+	key := IPKey("203.0.113.9")
+
+	for i := 1; i <= 4; i++ {
+		if err := tr.Fail(ctx, key); err != nil {
+			t.Fatalf("fail: %v", err)
+		}
+		wait, locked, err := tr.Wait(key)
+		want := map[int]time.Duration{1: 0, 2: time.Second, 3: 2 * time.Second, 4: 4 * time.Second}[i]
+		if err != nil || locked || wait != want {
+			t.Fatalf("after %d failures expected to wait %v, got %v (locked %v, %v)", i, want, wait, locked, err)
+		}
+		*now = now.Add(wait)
+	}
+	if wait, _, _ := tr.Wait(key); wait != 0 {
+		t.Fatalf("expected the delay to pass, still waiting %v", wait)
+	}
+
+	if err := tr.Fail(ctx, key); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	wait, locked, _ := tr.Wait(key, IPKey("198.51.100.7"))
+	if !locked || wait != 10*time.Minute {
+		t.Fatalf("expected a 10m lockout, got %v (locked %v)", wait, locked)
+	}
+	feed, _ := events.ListEvents(activity.Filter{Types: []string{activity.AuthLockedOut}}, 0)
+	if len(feed) != 1 || feed[0].SubjectID != key || feed[0].ActorID != activity.SystemActor {
+		t.Fatalf("expected the lockout alerted once, got %+v", feed)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if wait, locked, _ := tr.Wait(key); wait != 0 || locked {
+		t.Fatalf("expected the lockout to expire, got %v (locked %v)", wait, locked)
+	}
+	if err := tr.Fail(ctx, key); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if a, _ := tr.store.GetAttempts(key); a.Failures != 1 {
+		t.Fatalf("expected the count to start over after the lockout, got %d", a.Failures)
+	}
+}
+
+func TestTrackerForgetsSucceededIdentities(t *testing.T) {
+	tr, _, now := newTestTracker(t)
+	ctx := context.Background()
+	ip, identity := IPKey("203.0.113.9"), IdentityKey("tech-1")
+	for i := 0; i < 3; i++ {
+		_ = tr.Fail(ctx, ip, identity)
+	}
+	if err := tr.Succeed(identity); err != nil {
+		t.Fatalf("succeed: %v", err)
+	}
+	if _, err := tr.store.GetAttempts(identity); err != ErrNotFound {
+		t.Fatalf("expected the identity's attempts forgotten, got %v", err)
+	}
+	if wait, _, _ := tr.Wait(ip); wait == 0 {
+		t.Fatal("expected the IP still slowed down")
+	}
+
+	*now = now.Add(16 * time.Minute)
+	if attempts, _ := tr.Attempts(); len(attempts) != 0 {
+		t.Fatalf("expected attempts forgotten after the window, got %+v", attempts)
+	}
+
+	var disabled *Tracker
+	if NewTracker(config.LockoutConfig{}, nil, nil, nil, nil) != nil || disabled.Fail(ctx, ip) != nil || disabled.Middleware(nil)(http.NotFoundHandler()) == nil {
+		t.Fatal("expected lockouts disabled")
+	}
+}
+
+func TestMiddlewareCountsFailedSignIns(t *testing.T) {
+	tr, _, _ := newTestTracker(t)
+	status := http.StatusUnauthorized
+	h := tr.Middleware(func(r *http.Request) string { return r.URL.Query().Get("user") })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func(ip, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/dev-tokens?user="+user, nil)
+		req.RemoteAddr = ip + ":4431"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	code := func(rec *httptest.ResponseRecorder) string {
+		var p struct {
+			Code string `json:"code"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&p)
+		return p.Code
+	}
+
+	status = http.StatusBadRequest
+	for i := 0; i < 3; i++ {
+		serve("203.0.113.9", "tech-1")
+	}
+	if attempts, _ := tr.Attempts(); len(attempts) != 0 {
+		t.Fatalf("expected malformed sign-ins not counted, got %+v", attempts)
+	}
+	status = http.StatusUnauthorized
+	serve("203.0.113.9", "tech-1")
+	serve("203.0.113.9", "tech-1")
+	rec := serve("203.0.113.9", "tech-1")
+	if rec.Code != http.StatusTooManyRequests || code(rec) != "auth_throttled" || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected the third guess slowed down, got %d %s", rec.Code, rec.Body)
+	}
+	// The identity is slowed down from any address, and other identities
+	// from the same address.
+	if rec := serve("198.51.100.7", "tech-1"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected guesses on the identity slowed down from another IP, got %d", rec.Code)
+	}
+	if rec := serve("203.0.113.9", "tech-2"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected guesses from the IP slowed down for another identity, got %d", rec.Code)
+	}
+
+	admin := chi.NewRouter()
+	admin.Route("/v1/admin/lockouts", NewHandler(tr).Routes)
+	for _, key := range []string{"ip:203.0.113.9", "identity:tech-1"} {
+		rec = httptest.NewRecorder()
+		admin.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/admin/lockouts/"+key, nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected %s unlocked, got %d", key, rec.Code)
+		}
+	}
+	serve("203.0.113.9", "tech-1")
+	status = http.StatusCreated
+	if rec := serve("203.0.113.9", "tech-1"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected sign-ins through after the unlock, got %d", rec.Code)
+	}
+	if _, err := tr.store.GetAttempts(IdentityKey("tech-1")); err != ErrNotFound {
+		t.Fatalf("expected a successful sign-in to forget the identity's failures, got %v", err)
+	}
+	if a, err := tr.store.GetAttempts(IPKey("203.0.113.9")); err != nil || a.Failures != 1 {
+		t.Fatalf("expected the IP's failures kept, got %+v (%v)", a, err)
+	}
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/admin/lockouts/identity:tech-1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown keys to 404, got %d", rec.Code)
+	}
+}
+
+func TestBeginCountsAttemptsInProgress(t *testing.T) {
+	tr, _, _ := newTestTracker(t)
+	ctx := context.Background()
+	ip := IPKey("203.0.113.9")
+
+	// Two attempts may be in progress at once, as two failures may be
+	// made in a row; a third must wait for them to end.
+	for i := 0; i < 2; i++ {
+		if wait, _, err := tr.Begin(ip); wait != 0 || err != nil {
+			t.Fatalf("expected attempt %d admitted, got wait %v (%v)", i+1, wait, err)
+		}
+	}
+	if wait, locked, _ := tr.Begin(ip); wait != time.Second || locked {
+		t.Fatalf("expected a third concurrent attempt to wait 1s, got %v (locked %v)", wait, locked)
+	}
+	if err := tr.End(ctx, Inconclusive, ip); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if wait, _, _ := tr.Begin(ip); wait != 0 {
+		t.Fatalf("expected an attempt admitted once another ended, got wait %v", wait)
+	}
+	_ = tr.End(ctx, Failed, ip)
+	_ = tr.End(ctx, Failed, ip)
+	if wait, _, _ := tr.Wait(ip); wait != time.Second {
+		t.Fatalf("expected two failures to slow the IP down, got wait %v", wait)
+	}
+	if len(tr.pending) != 0 {
+		t.Fatalf("expected no attempts in progress, got %v", tr.pending)
+	}
+}
+
+func TestConcurrentSignInsCannotSkipTheDelay(t *testing.T) {
+	tr, _, _ := newTestTracker(t)
+	release := make(chan struct{})
+	var admitted atomic.Int32
+	h := tr.Middleware(func(*http.Request) string { return "tech-1" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admitted.Add(1)
+		<-release
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	var wg sync.WaitGroup
+	refused := make(chan int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/dev-tokens", nil)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code == http.StatusTooManyRequests {
+				refused <- rec.Code
+			}
+		}()
+	}
+	// Every request is either admitted and blocked, or refused.
+	for admitted.Load()+int32(len(refused)) < 20 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if n := admitted.Load(); n != 2 {
+		t.Fatalf("expected only the two attempts allowed before the delay admitted, got %d", n)
+	}
+}
+
+func TestInstancesShareAttempts(t *testing.T) {
+	docs := docstore.NewMemoryStore()
+	cfg := config.LockoutConfig{Enabled: true, Window: 15 * time.Minute, DelayAfter: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second, LockAfter: 3, LockFor: 10 * time.Minute}
+	first := NewTracker(cfg, NewDocumentStore(docs), nil, nil, nil)
+	second := NewTracker(cfg, NewDocumentStore(docs), nil, nil, nil)
+	ctx := context.Background()
+	key := IPKey("203.0.113.9")
+
+	_ = first.Fail(ctx, key)
+	_ = second.Fail(ctx, key)
+	if wait, _, _ := first.Wait(key); wait == 0 {
+		t.Fatalf("expected failures on either instance to add up, waiting %v", wait)
+	}
+	_ = first.Fail(ctx, key)
+	if _, locked, _ := second.Wait(key); !locked {
+		t.Fatal("expected the lockout honoured by the other instance")
+	}
+	if err := second.Unlock(key); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if wait, locked, _ := first.Wait(key); wait != 0 || locked {
+		t.Fatalf("expected the unlock to reach the other instance, got %v (locked %v)", wait, locked)
+	}
+}
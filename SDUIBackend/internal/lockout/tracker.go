@@ -0,0 +1,329 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/events"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Tracker counts failed authentications and decides when attempts must
+// wait. A nil *Tracker means lockouts are disabled: every attempt may go
+// ahead.
+type Tracker struct {
+	cfg    config.LockoutConfig
+	store  Store
+	feed   *activity.Service
+	bus    *events.Bus
+	logger *slog.Logger
+	now    func() time.Time
+
+	// mu serializes checking and recording attempts, so concurrent
+	// attempts on a key all see each other.
+	mu sync.Mutex
+	// pending counts the attempts Begin admitted that have not ended, by
+	// key.
+	pending map[string]int
+}
+
+// Outcome is how an attempt admitted by Begin ended.
+type Outcome int
+
+// Outcomes of an attempt. An inconclusive attempt, such as a malformed
+// request, neither counts as a failure nor clears earlier ones.
+const (
+	Inconclusive Outcome = iota
+	Failed
+	Succeeded
+)
+
+// NewTracker wires a tracker, or returns nil when lockouts are disabled.
+// Lockouts are recorded in feed and published on bus; both may be nil.
+func NewTracker(cfg config.LockoutConfig, store Store, feed *activity.Service, bus *events.Bus, logger *slog.Logger) *Tracker {
+	if !cfg.Enabled {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Tracker{cfg: cfg, store: store, feed: feed, bus: bus, logger: logger, now: time.Now, pending: make(map[string]int)}
+}
+
+// Wait returns how long attempts on keys must wait before one may go
+// ahead, zero if one may now, and whether a key is locked out rather than
+// slowed down.
+func (t *Tracker) Wait(keys ...string) (time.Duration, bool, error) {
+	if t == nil {
+		return 0, false, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wait(keys)
+}
+
+// Begin admits an attempt on keys unless it must wait, checking and
+// admitting in one step: until End, the attempt counts as a failure to
+// later checks, so concurrent attempts cannot all get past a delay. It
+// returns the wait, and whether a key is locked out, for attempts it
+// refuses.
+func (t *Tracker) Begin(keys ...string) (time.Duration, bool, error) {
+	if t == nil {
+		return 0, false, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wait, locked, err := t.wait(keys)
+	if err != nil || wait > 0 {
+		return wait, locked, err
+	}
+	for _, key := range keys {
+		t.pending[key]++
+	}
+	return 0, false, nil
+}
+
+// End records the outcome of an attempt Begin admitted on keys.
+func (t *Tracker) End(ctx context.Context, outcome Outcome, keys ...string) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		if t.pending[key]--; t.pending[key] <= 0 {
+			delete(t.pending, key)
+		}
+	}
+	switch outcome {
+	case Failed:
+		return t.fail(ctx, keys)
+	case Succeeded:
+		var identities []string
+		for _, key := range keys {
+			if strings.HasPrefix(key, identityPrefix) {
+				identities = append(identities, key)
+			}
+		}
+		return t.succeed(identities)
+	}
+	return nil
+}
+
+// wait is Wait with t.mu held. Attempts in progress count as failures.
+func (t *Tracker) wait(keys []string) (time.Duration, bool, error) {
+	now := t.now()
+	var wait time.Duration
+	locked := false
+	for _, key := range keys {
+		a, err := t.store.GetAttempts(key)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			a = Attempts{}
+		case err != nil:
+			return 0, false, err
+		}
+		if a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+			locked = true
+			wait = max(wait, a.LockedUntil.Sub(now))
+		} else if a.RetryAt != nil && now.Before(*a.RetryAt) {
+			wait = max(wait, a.RetryAt.Sub(now))
+		}
+		if p := t.pending[key]; p > 0 && a.Failures+p >= t.cfg.DelayAfter {
+			wait = max(wait, t.delay(a.Failures+p))
+		}
+	}
+	return wait, locked, nil
+}
+
+// Fail records a failed attempt on each key. Past DelayAfter failures
+// within the window the key's next attempt must wait, twice as long after
+// each failure; at LockAfter the key is locked out and the lockout alerted.
+func (t *Tracker) Fail(ctx context.Context, keys ...string) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.fail(ctx, keys)
+}
+
+func (t *Tracker) fail(ctx context.Context, keys []string) error {
+	now := t.now()
+	for _, key := range keys {
+		tripped := false
+		a, err := t.store.UpdateAttempts(key, func(current *Attempts) Attempts {
+			a := Attempts{Key: key, FirstAt: now}
+			if current != nil && now.Before(current.ExpiresAt) {
+				a = *current
+			}
+			a.Failures++
+			a.LastAt = now
+			a.RetryAt = nil
+			a.ExpiresAt = a.FirstAt.Add(t.cfg.Window)
+			tripped = false
+			switch {
+			case a.Failures >= t.cfg.LockAfter:
+				tripped = a.LockedUntil == nil
+				until := now.Add(t.cfg.LockFor)
+				a.LockedUntil = &until
+				a.ExpiresAt = until
+			case a.Failures >= t.cfg.DelayAfter:
+				retry := now.Add(t.delay(a.Failures))
+				a.RetryAt = &retry
+				if retry.After(a.ExpiresAt) {
+					a.ExpiresAt = retry
+				}
+			}
+			return a
+		})
+		if err != nil {
+			return err
+		}
+		if tripped {
+			t.alert(ctx, a)
+		}
+	}
+	return nil
+}
+
+// delay is how long the attempt after the nth failure must wait.
+func (t *Tracker) delay(failures int) time.Duration {
+	d := t.cfg.BaseDelay
+	for i := t.cfg.DelayAfter; i < failures && d < t.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, t.cfg.MaxDelay)
+}
+
+// alert logs a lockout, records it in the activity feed and publishes it.
+func (t *Tracker) alert(ctx context.Context, a Attempts) {
+	t.logger.Warn("authentication locked out", slog.String("key", a.Key), slog.Int("failures", a.Failures), slog.Time("lockedUntil", *a.LockedUntil))
+	t.feed.Record(ctx, activity.Event{Type: activity.AuthLockedOut, SubjectID: a.Key, SubjectName: fmt.Sprintf("%s after %d failed attempts", a.Key, a.Failures)})
+	t.bus.Emit(events.AuthLockedOut(a.Key, a.Failures, *a.LockedUntil))
+}
+
+// Succeed forgets the failed attempts on keys after a successful
+// authentication. Callers pass the identity that authenticated, not the
+// IP, so one valid credential cannot clear an IP's guesses at others.
+func (t *Tracker) Succeed(keys ...string) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.succeed(keys)
+}
+
+func (t *Tracker) succeed(keys []string) error {
+	for _, key := range keys {
+		if err := t.store.DeleteAttempts(key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attempts lists the keys with recent failed attempts, by key.
+func (t *Tracker) Attempts() ([]Attempts, error) {
+	return t.store.ListAttempts()
+}
+
+// Unlock lifts a key's lockout or delay and forgets its failed attempts.
+func (t *Tracker) Unlock(key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.store.DeleteAttempts(key); err != nil {
+		return err
+	}
+	t.logger.Info("authentication unlocked", slog.String("key", key))
+	return nil
+}
+
+// Middleware guards a sign-in endpoint. Each request is an attempt on its
+// client IP and on the identity it names, as identity returns it; empty
+// means none. Attempts answered 401 are failures and 2xx successes, and
+// attempts that must wait are refused with 429.
+func (t *Tracker) Middleware(identity func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if t == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys := []string{IPKey(clientIP(r))}
+			if id := identity(r); id != "" {
+				keys = append(keys, IdentityKey(id))
+			}
+			wait, locked, err := t.Begin(keys...)
+			if err != nil {
+				middleware.LoggerFrom(r.Context()).Error("failed to check attempts", slog.Any("error", err))
+				respond.Error(w, http.StatusInternalServerError, "failed to check attempts", "temporary error, please retry", respond.WithCause(err))
+				return
+			}
+			if wait > 0 {
+				Refuse(w, wait, locked)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			outcome := Inconclusive
+			defer func() {
+				if err := t.End(r.Context(), outcome, keys...); err != nil {
+					middleware.LoggerFrom(r.Context()).Error("failed to record attempt", slog.Any("error", err))
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			switch {
+			case rec.status == http.StatusUnauthorized:
+				outcome = Failed
+			case rec.status >= 200 && rec.status < 300:
+				outcome = Succeeded
+			}
+		})
+	}
+}
+
+// Refuse answers an attempt that must wait.
+func Refuse(w http.ResponseWriter, wait time.Duration, locked bool) {
+	cause := apperr.RateLimited("auth_throttled", "too many failed attempts; retry after the Retry-After delay", wait)
+	if locked {
+		cause = apperr.RateLimited("auth_locked_out", "locked out after repeated failed attempts; retry after the Retry-After delay", wait)
+	}
+	respond.Error(w, http.StatusTooManyRequests, "too many failed attempts", cause.Detail, respond.WithCause(cause))
+}
+
+// clientIP returns the request's client address, as resolved by
+// middleware.RealIP.
+func clientIP(r *http.Request) string {
+	if c, ok := middleware.ClientFrom(r.Context()); ok {
+		return c.IP.String()
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,141 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Conversation sizes.
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Handler serves technicians' sockets and the dispatcher messaging API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a messaging handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the dispatcher API.
+func (h *Handler) Routes(r chi.Router) {
+	r.Post("/", h.SendMessage)
+	r.Get("/connected", h.ListConnected)
+	r.Get("/technicians/{technicianId}", h.ListConversation)
+}
+
+// Socket upgrades to a WebSocket carrying the technician's messages.
+// Sockets need a signed-in identity: technicians connect as themselves and
+// staff as ?userId=. Tokens travel in the Authorization header, which
+// browsers cannot set on other sites' sockets.
+func (h *Handler) Socket(w http.ResponseWriter, r *http.Request) {
+	id, ok := auth.FromContext(r.Context())
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie"`)
+		respond.Error(w, http.StatusUnauthorized, "authentication required", "provide a bearer token")
+		return
+	}
+	me := id.Subject
+	if id.Staff() {
+		me = r.URL.Query().Get("userId")
+	}
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "pass userId")
+		return
+	}
+	key, ok := handshakeKey(r)
+	if !ok {
+		respond.Error(w, http.StatusBadRequest, "websocket upgrade required", "connect with a WebSocket client")
+		return
+	}
+	c, pending, err := h.service.connect(me)
+	if err != nil {
+		if errors.Is(err, ErrTooManyClients) {
+			respond.Error(w, http.StatusServiceUnavailable, "too many connections", "reconnect later", respond.WithRetryAfter(30*time.Second))
+			return
+		}
+		h.fail(w, r, "failed to connect", err)
+		return
+	}
+	// A JSON-escaped body may be several times its length in characters.
+	ws, err := upgrade(w, key, int64(h.service.cfg.MaxLength)*8+1024, 2*h.service.cfg.PingInterval)
+	if err != nil {
+		h.service.disconnect(c)
+		middleware.LoggerFrom(r.Context()).Warn("websocket upgrade failed", slog.Any("error", err))
+		return
+	}
+	h.service.serve(c, ws, pending)
+}
+
+// SendMessage sends a message to {"technicianId"} or everyone on
+// {"routeId"}.
+func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	var payload Send
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	sender := "dispatch"
+	if id, ok := auth.FromContext(r.Context()); ok {
+		sender = id.Subject
+	}
+	messages, err := h.service.Send(sender, payload)
+	if err != nil {
+		h.fail(w, r, "failed to send message", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, map[string]any{"messages": messages})
+}
+
+// ListConnected returns the technicians with sockets open on this
+// instance.
+func (h *Handler) ListConnected(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]any{"technicians": h.service.Connected()})
+}
+
+// ListConversation returns a technician's messages, newest first, up to
+// ?limit=, with their delivery and acknowledgement times.
+func (h *Handler) ListConversation(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxLimit {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and "+strconv.Itoa(maxLimit))
+			return
+		}
+		limit = n
+	}
+	messages, err := h.service.Conversation(chi.URLParam(r, "technicianId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list messages", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"messages": messages})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidMessage):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,175 @@
+// Package messaging carries messages between dispatchers and technicians.
+// The app holds a WebSocket open to /v1/ws; dispatchers send through the
+// admin API, to a technician or everyone on a route. Messages are stored
+// before they are delivered, so technicians who are offline receive them
+// when they reconnect, and each records when it reached a socket and when
+// the app acknowledged it.
+package messaging
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+var (
+	// ErrNotFound is returned when a message, technician or route does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidMessage wraps message validation failures.
+	ErrInvalidMessage = errors.New("invalid message")
+	// ErrTooManyClients is returned when an instance holds as many sockets
+	// as it allows.
+	ErrTooManyClients = errors.New("too many connections")
+)
+
+// Directions of a message.
+const (
+	ToTechnician   = "toTechnician"
+	FromTechnician = "fromTechnician"
+)
+
+// Message is one message of a technician's conversation with dispatch.
+type Message struct {
+	ID           string `json:"id"`
+	TechnicianID string `json:"technicianId"`
+	Direction    string `json:"direction"`
+	// Sender is the dispatcher who wrote a message to a technician.
+	Sender string `json:"sender,omitempty"`
+	// RouteID is set on messages sent to everyone on a route.
+	RouteID   string    `json:"routeId,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	// DeliveredAt is when the message was first written to one of the
+	// technician's sockets, and AckedAt when the app confirmed it.
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	AckedAt     *time.Time `json:"ackedAt,omitempty"`
+}
+
+// Store persists messages.
+type Store interface {
+	SaveMessage(m Message) error
+	GetMessage(id string) (Message, error)
+	// MarkDelivered and MarkAcked record the first delivery and
+	// acknowledgement of a message; later calls leave the time unchanged.
+	MarkDelivered(id string, at time.Time) error
+	MarkAcked(id string, at time.Time) error
+	// ListMessages returns a technician's conversation, newest first, up
+	// to limit.
+	ListMessages(technicianID string, limit int) ([]Message, error)
+	// ListUnacked returns the messages to a technician the app has not
+	// acknowledged, oldest first.
+	ListUnacked(technicianID string) ([]Message, error)
+	// PruneMessages removes messages created before cutoff and reports how
+	// many were removed.
+	PruneMessages(cutoff time.Time) (int, error)
+}
+
+// DocumentStore keeps messages in the shared document store, so a message
+// stored by one instance reaches the technician's socket on another and
+// survives restarts.
+type DocumentStore struct {
+	messages docstore.Collection[Message]
+}
+
+// NewDocumentStore keeps messages in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{messages: docstore.NewCollection(docs, "messages", func(m Message) docstore.Keys {
+		return docstore.Keys{"technician": m.TechnicianID, "unacked": strconv.FormatBool(m.Direction == ToTechnician && m.AckedAt == nil)}
+	})}
+}
+
+// NewMemoryStore keeps messages in process memory, for sandboxes and tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveMessage(m Message) error {
+	return d.messages.Put(m.ID, m)
+}
+
+func (d *DocumentStore) GetMessage(id string) (Message, error) {
+	m, err := d.messages.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Message{}, ErrNotFound
+	}
+	return m, err
+}
+
+func (d *DocumentStore) MarkDelivered(id string, at time.Time) error {
+	return d.mark(id, func(m *Message) {
+		if m.DeliveredAt == nil {
+			m.DeliveredAt = &at
+		}
+	})
+}
+
+func (d *DocumentStore) MarkAcked(id string, at time.Time) error {
+	return d.mark(id, func(m *Message) {
+		if m.AckedAt == nil {
+			m.AckedAt = &at
+		}
+	})
+}
+
+func (d *DocumentStore) mark(id string, set func(*Message)) error {
+	_, err := d.messages.Update(id, func(current *Message) (Message, error) {
+		if current == nil {
+			return Message{}, ErrNotFound
+		}
+		m := *current
+		set(&m)
+		return m, nil
+	})
+	return err
+}
+
+func (d *DocumentStore) ListMessages(technicianID string, limit int) ([]Message, error) {
+	found, err := d.messages.Find(docstore.Keys{"technician": technicianID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].CreatedAt.After(found[j].CreatedAt) })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (d *DocumentStore) ListUnacked(technicianID string) ([]Message, error) {
+	found, err := d.messages.Find(docstore.Keys{"technician": technicianID, "unacked": "true"})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].CreatedAt.Before(found[j].CreatedAt) })
+	return found, nil
+}
+
+func (d *DocumentStore) PruneMessages(cutoff time.Time) (int, error) {
+	all, err := d.messages.Find(nil)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, m := range all {
+		if !m.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := d.messages.Delete(m.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Connection is a technician's open sockets, as the hub tracks them.
+type Connection struct {
+	TechnicianID string `json:"technicianId"`
+	Sockets      int    `json:"sockets"`
+}
@@ -0,0 +1,233 @@
+package messaging
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/events"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var cfg = config.MessagingConfig{PingInterval: 50 * time.Millisecond, MaxLength: 20, MaxClients: 1, Retention: time.Hour, PruneInterval: time.Hour}
+
+// testClient is the app's end of a socket.
+type testClient struct {
+	t  *testing.T
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+// dial opens a socket as tech-1, returning the handshake response when the
+// upgrade is refused.
+func dial(t *testing.T, url string) (*testClient, *http.Response) {
+	t.Helper()
+	nc, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, url+"/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(nc); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		nc.Close()
+		return nil, resp
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected accept key %q", got)
+	}
+	_ = nc.SetDeadline(time.Now().Add(5 * time.Second))
+	return &testClient{t: t, nc: nc, r: r}, resp
+}
+
+// send writes a masked frame.
+func (c *testClient) send(op byte, payload []byte) {
+	c.t.Helper()
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	var mask [4]byte
+	_, _ = rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.nc.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) sendJSON(f Frame) {
+	data, _ := json.Marshal(f)
+	c.send(opText, data)
+}
+
+// next returns the next data frame, skipping pings, or the close code.
+func (c *testClient) next() (Frame, uint16) {
+	c.t.Helper()
+	for {
+		var head [2]byte
+		if _, err := io.ReadFull(c.r, head[:]); err != nil {
+			c.t.Fatal(err)
+		}
+		n := int(head[1])
+		if n == 126 {
+			var ext [2]byte
+			_, _ = io.ReadFull(c.r, ext[:])
+			n = int(binary.BigEndian.Uint16(ext[:]))
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			c.t.Fatal(err)
+		}
+		switch head[0] & 0x0f {
+		case opPing:
+			continue
+		case opClose:
+			return Frame{}, binary.BigEndian.Uint16(payload)
+		}
+		var f Frame
+		if err := json.Unmarshal(payload, &f); err != nil {
+			c.t.Fatalf("bad frame %q: %v", payload, err)
+		}
+		return f, 0
+	}
+}
+
+func TestSocket(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+	_ = store.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)})
+	svc := NewService(cfg, NewMemoryStore(), repos, nil)
+	h := NewHandler(svc)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Socket(w, r.WithContext(auth.ContextWithIdentity(r.Context(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician})))
+	}))
+	defer srv.Close()
+
+	if _, err := svc.Send("dispatcher-1", Send{TechnicianID: "nobody", Body: "hi"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown technician refused, got %v", err)
+	}
+	if _, err := svc.Send("dispatcher-1", Send{TechnicianID: "tech-1", RouteID: "tech-1_2026-10-15", Body: "hi"}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected one recipient kind required, got %v", err)
+	}
+	offline, err := svc.Send("dispatcher-1", Send{TechnicianID: "tech-1", Body: "call the office"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := dial(t, srv.URL)
+	if _, resp := dial(t, srv.URL); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected sockets past the limit refused, got %d", resp.StatusCode)
+	}
+	if f, _ := app.next(); f.Type != FrameMessage || f.Message.ID != offline[0].ID {
+		t.Errorf("expected the message sent while offline, got %+v", f)
+	}
+	routed, err := svc.Send("dispatcher-1", Send{RouteID: "tech-1_2026-10-15", Body: "gate code 1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := app.next(); f.Message == nil || f.Message.ID != routed[0].ID || f.Message.RouteID != "tech-1_2026-10-15" {
+		t.Errorf("expected the route's message, got %+v", f)
+	}
+
+	app.sendJSON(Frame{Type: FrameAck, ID: offline[0].ID})
+	app.sendJSON(Frame{Type: FrameMessage, ClientID: "c-1", Body: "on my way"})
+	if f, _ := app.next(); f.Type != FrameSent || f.ClientID != "c-1" || f.Message.Direction != FromTechnician {
+		t.Errorf("expected the reply confirmed, got %+v", f)
+	}
+	app.sendJSON(Frame{Type: FrameMessage, ClientID: "c-2", Body: strings.Repeat("x", 21)})
+	if f, _ := app.next(); f.Type != FrameError || f.ClientID != "c-2" {
+		t.Errorf("expected a long reply refused, got %+v", f)
+	}
+	app.send(opClose, binary.BigEndian.AppendUint16(nil, 1000))
+	if _, code := app.next(); code != 1000 {
+		t.Errorf("expected the close echoed, got %d", code)
+	}
+
+	conversation, _ := svc.Conversation("tech-1", 10)
+	if len(conversation) != 3 || conversation[0].Body != "on my way" {
+		t.Fatalf("unexpected conversation %+v", conversation)
+	}
+	if m := conversation[1]; m.DeliveredAt == nil || m.AckedAt != nil {
+		t.Errorf("expected the route's message delivered but not acknowledged, got %+v", m)
+	}
+	if m := conversation[2]; m.AckedAt == nil {
+		t.Errorf("expected the first message acknowledged, got %+v", m)
+	}
+
+	// Only the unacknowledged message is delivered again.
+	deadline := time.Now().Add(time.Second)
+	for len(svc.Connected()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	app, _ = dial(t, srv.URL)
+	if f, _ := app.next(); f.Message == nil || f.Message.ID != routed[0].ID {
+		t.Errorf("expected the unacknowledged message redelivered, got %+v", f)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.Run(ctx)
+	if _, code := app.next(); code != closeGoingAway {
+		t.Errorf("expected sockets closed on shutdown, got %d", code)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+	bus := events.NewBus(config.EventsConfig{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour}, events.NewMemoryPublisher(), nil)
+	messages := NewMemoryStore()
+	sender, holder, other := NewService(cfg, messages, repos, nil), NewService(cfg, messages, repos, nil), NewService(cfg, messages, repos, nil)
+	sender.Broadcast(bus, "acme")
+	holder.Broadcast(bus, "acme")
+	other.Broadcast(bus, "globex")
+	c, _, err := holder.connect("tech-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	elsewhere, _, _ := other.connect("tech-1")
+
+	sent, err := sender.Send("dispatcher-1", Send{TechnicianID: "tech-1", Body: "call the office"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bus.Run(ctx)
+	select {
+	case m := <-c.send:
+		if m.ID != sent[0].ID || m.Body != "call the office" {
+			t.Errorf("unexpected message %+v", m)
+		}
+	default:
+		t.Fatal("expected the message delivered to the socket on another instance")
+	}
+	if len(elsewhere.send) != 0 {
+		t.Error("expected another tenant's sockets left alone")
+	}
+}
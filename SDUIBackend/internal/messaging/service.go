@@ -0,0 +1,437 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/events"
+)
+
+// clientBuffer is how many messages may queue for a socket before it is
+// dropped; the app reconnects and receives them from the store.
+const clientBuffer = 32
+
+// Frame is what travels over the socket, as JSON text frames. The server
+// sends "message" frames with a message to the technician, "sent" frames
+// confirming a technician's message with the ClientID the app gave it, and
+// "error" frames. The app sends "ack" frames with the ID of a message it
+// received, and "message" frames with a Body for dispatch.
+type Frame struct {
+	Type     string   `json:"type"`
+	ID       string   `json:"id,omitempty"`
+	ClientID string   `json:"clientId,omitempty"`
+	Body     string   `json:"body,omitempty"`
+	Message  *Message `json:"message,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Frame types.
+const (
+	FrameMessage = "message"
+	FrameSent    = "sent"
+	FrameAck     = "ack"
+	FrameError   = "error"
+)
+
+// Send is a dispatcher's message, to a technician or to everyone on a route.
+type Send struct {
+	TechnicianID string `json:"technicianId,omitempty"`
+	RouteID      string `json:"routeId,omitempty"`
+	Body         string `json:"body"`
+}
+
+// client is one open socket.
+type client struct {
+	technicianID string
+	send         chan Message
+	done         chan struct{}
+	once         sync.Once
+}
+
+func (c *client) stop() { c.once.Do(func() { close(c.done) }) }
+
+// Service is the messaging hub: it stores messages and delivers them to the
+// sockets of the technicians they are for.
+type Service struct {
+	cfg    config.MessagingConfig
+	store  Store
+	repos  repository.Repository
+	logger *slog.Logger
+	now    func() time.Time
+	// bus and tenantID, set by Broadcast, carry sent messages to the
+	// sockets other instances hold.
+	bus      *events.Bus
+	tenantID string
+
+	mu      sync.Mutex
+	clients map[string]map[*client]struct{} // by technician
+	count   int
+}
+
+// NewService creates a messaging hub.
+func NewService(cfg config.MessagingConfig, store Store, repos repository.Repository, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, logger: logger, now: time.Now, clients: make(map[string]map[*client]struct{})}
+}
+
+// Broadcast emits the messages s sends onto bus, as message.sent events of
+// tenantID, and delivers those every instance of the tenant sends to the
+// sockets s holds. Without it, sockets on other instances pick messages up
+// from the store at their next ping. Call it before serving.
+func (s *Service) Broadcast(bus *events.Bus, tenantID string) {
+	s.bus, s.tenantID = bus, tenantID
+	bus.Subscribe(func(_ context.Context, e events.Event) {
+		if e.Type != events.EventMessageSent || e.TenantID != tenantID || !s.connectedTo(e.TechnicianID) {
+			return
+		}
+		var d events.MessageSentData
+		if e.Decode(&d) != nil {
+			return
+		}
+		m, err := s.store.GetMessage(d.MessageID)
+		if err != nil {
+			s.logger.Warn("get broadcast message", slog.String("message", d.MessageID), slog.Any("error", err))
+			return
+		}
+		s.deliver(m)
+	})
+}
+
+// connectedTo reports whether s holds a socket of the technician.
+func (s *Service) connectedTo(technicianID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients[technicianID]) > 0
+}
+
+// Send stores a dispatcher's message for each recipient and delivers it to
+// those connected. A route's messages go to the technicians assigned it.
+func (s *Service) Send(sender string, req Send) ([]Message, error) {
+	if err := s.validBody(req.Body); err != nil {
+		return nil, err
+	}
+	var recipients []string
+	switch {
+	case (req.TechnicianID == "") == (req.RouteID == ""):
+		return nil, fmt.Errorf("%w: exactly one of technicianId and routeId is required", ErrInvalidMessage)
+	case req.TechnicianID != "":
+		if _, err := s.repos.Technicians.GetByID(req.TechnicianID); err != nil {
+			return nil, fmt.Errorf("%w: technician %q", ErrNotFound, req.TechnicianID)
+		}
+		recipients = []string{req.TechnicianID}
+	default:
+		var err error
+		if recipients, err = s.assigned(req.RouteID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now().UTC()
+	out := make([]Message, 0, len(recipients))
+	for _, tech := range recipients {
+		m := Message{ID: uuid.NewString(), TechnicianID: tech, Direction: ToTechnician, Sender: sender, RouteID: req.RouteID, Body: req.Body, CreatedAt: now}
+		if err := s.store.SaveMessage(m); err != nil {
+			return nil, err
+		}
+		s.deliver(m)
+		s.bus.Emit(events.MessageSent(s.tenantID, tech, m.ID))
+		out = append(out, m)
+	}
+	s.logger.Info("message sent", slog.String("sender", sender), slog.Any("technicians", recipients), slog.String("route", req.RouteID))
+	return out, nil
+}
+
+// assigned returns the technicians on the route with a server ID.
+func (s *Service) assigned(routeID string) ([]string, error) {
+	routes, err := s.repos.Sync.ListRouteUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, r := range routes {
+		if r.ServerID() == routeID {
+			out = append(out, r.TechnicianID)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: route %q", ErrNotFound, routeID)
+	}
+	return out, nil
+}
+
+func (s *Service) validBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalidMessage)
+	}
+	if n := utf8.RuneCountInString(body); n > s.cfg.MaxLength {
+		return fmt.Errorf("%w: body is %d characters, more than %d", ErrInvalidMessage, n, s.cfg.MaxLength)
+	}
+	return nil
+}
+
+// deliver queues a message on its technician's sockets, dropping sockets
+// too far behind.
+func (s *Service) deliver(m Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients[m.TechnicianID] {
+		select {
+		case c.send <- m:
+		default:
+			s.logger.Warn("messaging socket lagging; dropped", slog.String("technician", m.TechnicianID))
+			c.stop()
+		}
+	}
+}
+
+// Conversation returns a technician's messages, newest first.
+func (s *Service) Conversation(technicianID string, limit int) ([]Message, error) {
+	return s.store.ListMessages(technicianID, limit)
+}
+
+// Connected returns the technicians with sockets open on this instance.
+func (s *Service) Connected() []Connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Connection, 0, len(s.clients))
+	for tech, cs := range s.clients {
+		out = append(out, Connection{TechnicianID: tech, Sockets: len(cs)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TechnicianID < out[j].TechnicianID })
+	return out
+}
+
+// connect registers a socket for a technician and returns the messages
+// awaiting acknowledgement, which are delivered first.
+func (s *Service) connect(technicianID string) (*client, []Message, error) {
+	s.mu.Lock()
+	if s.count >= s.cfg.MaxClients {
+		s.mu.Unlock()
+		return nil, nil, ErrTooManyClients
+	}
+	c := &client{technicianID: technicianID, send: make(chan Message, clientBuffer), done: make(chan struct{})}
+	if s.clients[technicianID] == nil {
+		s.clients[technicianID] = make(map[*client]struct{})
+	}
+	s.clients[technicianID][c] = struct{}{}
+	s.count++
+	s.mu.Unlock()
+
+	pending, err := s.unacked(technicianID, false)
+	if err != nil {
+		s.disconnect(c)
+		return nil, nil, err
+	}
+	return c, pending, nil
+}
+
+// unacked returns the messages to a technician the app has not
+// acknowledged, or only those no socket has received. Messages past
+// retention are pruned eventually; until then they are not delivered.
+func (s *Service) unacked(technicianID string, undelivered bool) ([]Message, error) {
+	pending, err := s.store.ListUnacked(technicianID)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := s.now().Add(-s.cfg.Retention)
+	kept := pending[:0]
+	for _, m := range pending {
+		if !m.CreatedAt.Before(cutoff) && (!undelivered || m.DeliveredAt == nil) {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
+
+func (s *Service) disconnect(c *client) {
+	c.stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c.technicianID][c]; !ok {
+		return
+	}
+	delete(s.clients[c.technicianID], c)
+	if len(s.clients[c.technicianID]) == 0 {
+		delete(s.clients, c.technicianID)
+	}
+	s.count--
+}
+
+// serve runs a socket until either side closes it: pending messages go
+// first, then new ones as they are sent, with pings while idle. Each ping
+// also picks up undelivered messages other instances stored. A message is
+// written to a socket once, though the app may see it again on another.
+func (s *Service) serve(c *client, ws *conn, pending []Message) {
+	defer s.disconnect(c)
+	go func() {
+		defer c.stop()
+		for {
+			data, err := ws.read()
+			if err != nil {
+				switch {
+				case errors.Is(err, errTooBig):
+					ws.close(closeTooBig, "message too large")
+				case errors.Is(err, errProtocol):
+					ws.close(closeProtocol, "protocol error")
+				case !errors.Is(err, io.EOF):
+					s.logger.Debug("messaging socket read failed", slog.String("technician", c.technicianID), slog.Any("error", err))
+				}
+				return
+			}
+			if reply := s.receive(c.technicianID, data); reply != nil {
+				if err := ws.writeJSON(reply); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	sent := make(map[string]bool)
+	send := func(msgs ...Message) bool {
+		for _, m := range msgs {
+			if sent[m.ID] {
+				continue
+			}
+			if !s.write(ws, m) {
+				return false
+			}
+			sent[m.ID] = true
+		}
+		return true
+	}
+	defer ws.close(closeGoingAway, "")
+	if !send(pending...) {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case m := <-c.send:
+			if !send(m) {
+				return
+			}
+		case <-ticker.C:
+			if err := ws.write(opPing, nil); err != nil {
+				return
+			}
+			undelivered, err := s.unacked(c.technicianID, true)
+			if err != nil {
+				s.logger.Warn("list undelivered messages", slog.String("technician", c.technicianID), slog.Any("error", err))
+			} else if !send(undelivered...) {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write sends a message to a socket and records its delivery.
+func (s *Service) write(ws *conn, m Message) bool {
+	if err := ws.writeJSON(Frame{Type: FrameMessage, Message: &m}); err != nil {
+		return false
+	}
+	if m.DeliveredAt == nil {
+		if err := s.store.MarkDelivered(m.ID, s.now().UTC()); err != nil && !errors.Is(err, ErrNotFound) {
+			s.logger.Error("mark message delivered", slog.String("message", m.ID), slog.Any("error", err))
+		}
+	}
+	return true
+}
+
+// receive handles a frame from a technician's app and returns the frame to
+// answer with, if any.
+func (s *Service) receive(technicianID string, data []byte) *Frame {
+	var f Frame
+	if err := json.Unmarshal(data, &f); err != nil {
+		return &Frame{Type: FrameError, Error: "frames are JSON objects"}
+	}
+	switch f.Type {
+	case FrameAck:
+		if err := s.ack(technicianID, f.ID); err != nil {
+			return &Frame{Type: FrameError, ID: f.ID, Error: err.Error()}
+		}
+		return nil
+	case FrameMessage:
+		m, err := s.reply(technicianID, f.Body)
+		if err != nil {
+			return &Frame{Type: FrameError, ClientID: f.ClientID, Error: err.Error()}
+		}
+		return &Frame{Type: FrameSent, ClientID: f.ClientID, Message: &m}
+	default:
+		return &Frame{Type: FrameError, Error: fmt.Sprintf("unknown frame type %q", f.Type)}
+	}
+}
+
+// ack records that a technician's app received a message.
+func (s *Service) ack(technicianID, id string) error {
+	m, err := s.store.GetMessage(id)
+	if err != nil || m.TechnicianID != technicianID || m.Direction != ToTechnician {
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w: message %q", ErrNotFound, id)
+	}
+	now := s.now().UTC()
+	if err := s.store.MarkDelivered(id, now); err != nil {
+		return err
+	}
+	return s.store.MarkAcked(id, now)
+}
+
+// reply stores a technician's message to dispatch.
+func (s *Service) reply(technicianID, body string) (Message, error) {
+	if err := s.validBody(body); err != nil {
+		return Message{}, err
+	}
+	m := Message{ID: uuid.NewString(), TechnicianID: technicianID, Direction: FromTechnician, Body: body, CreatedAt: s.now().UTC()}
+	if err := s.store.SaveMessage(m); err != nil {
+		return Message{}, err
+	}
+	s.logger.Info("message received", slog.String("technician", technicianID), slog.String("message", m.ID))
+	return m, nil
+}
+
+// Run prunes messages past retention until ctx is cancelled, then closes
+// every socket so the app reconnects to another instance.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			for _, cs := range s.clients {
+				for c := range cs {
+					c.stop()
+				}
+			}
+			s.mu.Unlock()
+			return
+		case <-ticker.C:
+			n, err := s.store.PruneMessages(s.now().Add(-s.cfg.Retention))
+			if err != nil {
+				s.logger.Error("prune messages", slog.Any("error", err))
+			} else if n > 0 {
+				s.logger.Info("pruned messages", slog.Int("messages", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package messaging
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This is the subset of RFC 6455 the app needs: unextended text frames,
+// fragmentation, ping, pong and close. Client frames must be masked; server
+// frames never are.
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Close codes sent to the app.
+const (
+	closeGoingAway = 1001
+	closeProtocol  = 1002
+	closeTooBig    = 1009
+)
+
+// acceptGUID is appended to the client's key to derive the accept header.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeWait bounds a single frame write, so a stalled client cannot hold a
+// writer forever.
+const writeWait = 10 * time.Second
+
+var (
+	errProtocol = errors.New("websocket protocol error")
+	errTooBig   = errors.New("websocket message too large")
+)
+
+// handshakeKey returns the Sec-WebSocket-Key of a valid upgrade request.
+func handshakeKey(r *http.Request) (string, bool) {
+	if r.Method != http.MethodGet || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return "", false
+	}
+	upgrade := false
+	for _, v := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				upgrade = true
+			}
+		}
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	return key, upgrade && key != ""
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// conn is a server-side WebSocket connection. Reads happen on one
+// goroutine; writes may come from any.
+type conn struct {
+	nc  net.Conn
+	r   *bufio.Reader
+	wmu sync.Mutex
+	// closed is set once a close frame is sent; nothing may follow it.
+	closed bool
+	limit  int64
+	// idle is how long a read may wait for the next frame.
+	idle time.Duration
+}
+
+// upgrade completes the handshake of a request handshakeKey accepted and
+// takes over its connection. The server's own deadlines are cleared; the
+// connection keeps its own.
+func upgrade(w http.ResponseWriter, key string, limit int64, idle time.Duration) (*conn, error) {
+	nc, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+	c := &conn{nc: nc, r: brw.Reader, limit: limit, idle: idle}
+	_ = nc.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := io.WriteString(nc, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: "+acceptKey(key)+"\r\n\r\n"); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// read returns the next data message, answering pings and reassembling
+// fragments on the way. It returns io.EOF once the client closes.
+func (c *conn) read() ([]byte, error) {
+	var msg []byte
+	fragmented := false
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.write(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = c.write(opClose, payload[:min(len(payload), 2)])
+			return nil, io.EOF
+		case opText, opBinary:
+			if fragmented {
+				return nil, errProtocol
+			}
+			msg = payload
+		case opContinuation:
+			if !fragmented {
+				return nil, errProtocol
+			}
+			msg = append(msg, payload...)
+		default:
+			return nil, errProtocol
+		}
+		if int64(len(msg)) > c.limit {
+			return nil, errTooBig
+		}
+		if fragmented = !fin; !fragmented {
+			return msg, nil
+		}
+	}
+}
+
+func (c *conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	_ = c.nc.SetReadDeadline(time.Now().Add(c.idle))
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = head[0]&0x80 != 0, head[0]&0x0f
+	if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+		// Extensions were not negotiated, and clients must mask.
+		return false, 0, nil, errProtocol
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= opClose && (n > 125 || !fin) {
+		return false, 0, nil, errProtocol
+	}
+	if n > uint64(c.limit) {
+		return false, 0, nil, errTooBig
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// write sends one unfragmented frame.
+func (c *conn) write(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	c.closed = op == opClose
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|op)
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, payload...)
+	_ = c.nc.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := c.nc.Write(frame)
+	return err
+}
+
+func (c *conn) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.write(opText, data)
+}
+
+// close sends a close frame with code and drops the connection without
+// waiting for the client's reply.
+func (c *conn) close(code uint16, reason string) {
+	_ = c.write(opClose, append(binary.BigEndian.AppendUint16(nil, code), reason...))
+	c.nc.Close()
+}
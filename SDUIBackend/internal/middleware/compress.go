@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Compress gzips responses for clients that accept gzip. Responses are
+// buffered until they reach minBytes: smaller ones, ones of types that are
+// already compressed such as photos and PDFs, and event streams are sent
+// as they are. Responses that set their own Content-Encoding or serve a
+// range are left alone. ETags are kept: they identify the content, which
+// is the same whatever its encoding.
+func Compress(minBytes, level int) func(http.Handler) http.Handler {
+	writers := sync.Pool{New: func() any {
+		// Config validation rejects invalid levels.
+		zw, _ := gzip.NewWriterLevel(io.Discard, level)
+		return zw
+	}}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, pool: &writers, minBytes: minBytes, status: http.StatusOK}
+			next.ServeHTTP(cw, r)
+			// Not deferred: after a panic the recoverer writes the error.
+			cw.close()
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows gzip,
+// by name or through *, with a nonzero quality.
+func acceptsGzip(header string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// compressible reports whether a content type is worth compressing.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	// Event streams flush every event, too little to compress.
+	if mediaType == "text/event-stream" {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/javascript" ||
+		mediaType == "application/x-ndjson"
+}
+
+// compressWriter holds a response back until it knows whether to compress
+// it: once minBytes are written, the handler flushes, or the handler
+// returns.
+type compressWriter struct {
+	http.ResponseWriter
+	pool     *sync.Pool
+	minBytes int
+	status   int
+	buf      []byte
+	zw       *gzip.Writer
+	started  bool // headers sent
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.started {
+		return
+	}
+	cw.status = code
+	// Bodiless and informational responses have nothing to compress.
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.started {
+		h := cw.Header()
+		if h.Get("Content-Type") == "" {
+			h.Set("Content-Type", http.DetectContentType(append(cw.buf, p...)))
+		}
+		if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" || !compressible(h.Get("Content-Type")) {
+			cw.start(false)
+		} else if len(cw.buf)+len(p) < cw.minBytes {
+			cw.buf = append(cw.buf, p...)
+			return len(p), nil
+		} else {
+			cw.start(true)
+		}
+	}
+	if cw.zw != nil {
+		return cw.zw.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far. Streamed responses are compressed
+// whatever their size so far.
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		h := cw.Header()
+		cw.start(len(cw.buf) > 0 && h.Get("Content-Encoding") == "" && h.Get("Content-Range") == "" && compressible(h.Get("Content-Type")))
+	}
+	if cw.zw != nil {
+		_ = cw.zw.Flush()
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// start sends the headers, with gzip's when compressing, and the body
+// buffered so far.
+func (cw *compressWriter) start(compress bool) {
+	cw.started = true
+	if compress {
+		cw.Header().Set("Content-Encoding", "gzip")
+		cw.Header().Del("Content-Length")
+		cw.zw = cw.pool.Get().(*gzip.Writer)
+		cw.zw.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) == 0 {
+		return
+	}
+	if cw.zw != nil {
+		_, _ = cw.zw.Write(cw.buf)
+	} else {
+		_, _ = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = nil
+}
+
+// close sends a response still held back, uncompressed as it is under
+// minBytes, and finishes a compressed one.
+func (cw *compressWriter) close() {
+	if !cw.started {
+		if cw.buf != nil {
+			cw.Header().Set("Content-Length", strconv.Itoa(len(cw.buf)))
+		}
+		cw.start(false)
+	}
+	if cw.zw != nil {
+		_ = cw.zw.Close()
+		cw.zw.Reset(io.Discard)
+		cw.pool.Put(cw.zw)
+		cw.zw = nil
+	}
+}
+
+// Decompress inflates gzip-encoded request bodies, which the app sends for
+// large sync batches, to at most maxBytes; reading past that fails with an
+// *http.MaxBytesError. Bodies in other encodings are refused.
+func Decompress(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+			case "", "identity":
+			case "gzip", "x-gzip":
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					respond.Error(w, http.StatusBadRequest, "invalid request encoding", "request body is not gzip: "+err.Error())
+					return
+				}
+				r.Body = http.MaxBytesReader(w, zr, maxBytes)
+				r.Header.Del("Content-Encoding")
+				r.Header.Del("Content-Length")
+				r.ContentLength = -1
+			default:
+				w.Header().Set("Accept-Encoding", "gzip")
+				respond.Error(w, http.StatusUnsupportedMediaType, "unsupported content encoding", "request bodies may be gzip-encoded or sent as they are")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// screenPayload is JSON shaped like a rendered screen.
+func screenPayload(stops int) []byte {
+	type component struct {
+		Type     string      `json:"type"`
+		ID       string      `json:"id"`
+		Text     string      `json:"text,omitempty"`
+		Children []component `json:"children,omitempty"`
+	}
+	root := component{Type: "vstack", ID: "root"}
+	for i := 0; i < stops; i++ {
+		root.Children = append(root.Children, component{Type: "card", ID: fmt.Sprintf("stop-%d", i), Children: []component{
+			{Type: "text", ID: fmt.Sprintf("stop-%d-customer", i), Text: "Miller Farms"},
+			{Type: "text", ID: fmt.Sprintf("stop-%d-address", i), Text: "1200 County Road 4, Springfield"},
+			{Type: "button", ID: fmt.Sprintf("stop-%d-start", i), Text: "Start service"},
+		}})
+	}
+	data, _ := json.Marshal(map[string]any{"version": 1, "component": root})
+	return data
+}
+
+func serve(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func gunzip(t testing.TB, data []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestCompress(t *testing.T) {
+	payload := screenPayload(50)
+	h := Compress(1024, -1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		// Written in pieces, as encoders do.
+		for _, chunk := range bytes.SplitAfter(payload, []byte("},")) {
+			_, _ = w.Write(chunk)
+		}
+	}))
+
+	rec := serve(h, "br;q=1.0, gzip;q=0.8")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" || rec.Header().Get("ETag") != `"v1"` {
+		t.Fatalf("expected a gzipped response, got %v", rec.Header())
+	}
+	if rec.Body.Len() >= len(payload)/4 {
+		t.Errorf("expected the screen compressed well, got %d of %d bytes", rec.Body.Len(), len(payload))
+	}
+	if got := gunzip(t, rec.Body.Bytes()); !bytes.Equal(got, payload) {
+		t.Error("expected the payload back after decompression")
+	}
+
+	for _, accept := range []string{"", "identity", "gzip;q=0", "*;q=0"} {
+		if rec := serve(h, accept); rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), payload) {
+			t.Errorf("Accept-Encoding %q: expected the payload as is, got %v", accept, rec.Header())
+		}
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	for name, tc := range map[string]struct {
+		contentType, encoding string
+		body                  []byte
+		status                int
+	}{
+		"small":      {"application/json", "", []byte(`{"ok":true}`), http.StatusOK},
+		"image":      {"image/jpeg", "", bytes.Repeat([]byte{0xff}, 4096), http.StatusOK},
+		"events":     {"text/event-stream", "", bytes.Repeat([]byte(": keepalive\n\n"), 400), http.StatusOK},
+		"encoded":    {"application/json", "br", bytes.Repeat([]byte("a"), 4096), http.StatusOK},
+		"no content": {"", "", nil, http.StatusNoContent},
+	} {
+		h := Compress(1024, -1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tc.contentType != "" {
+				w.Header().Set("Content-Type", tc.contentType)
+			}
+			if tc.encoding != "" {
+				w.Header().Set("Content-Encoding", tc.encoding)
+			}
+			w.WriteHeader(tc.status)
+			_, _ = w.Write(tc.body)
+		}))
+		rec := serve(h, "gzip")
+		if rec.Code != tc.status || rec.Header().Get("Content-Encoding") != tc.encoding || !bytes.Equal(rec.Body.Bytes(), tc.body) {
+			t.Errorf("%s: expected the response untouched, got %d %v", name, rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestCompressStreams(t *testing.T) {
+	h := Compress(1<<20, -1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "row,%d\n", i)
+			_ = http.NewResponseController(w).Flush()
+		}
+	}))
+	rec := serve(h, "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || !rec.Flushed {
+		t.Fatalf("expected a flushed response compressed, got %v", rec.Header())
+	}
+	if got := string(gunzip(t, rec.Body.Bytes())); got != "row,0\nrow,1\nrow,2\n" {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	var got []byte
+	var readErr error
+	h := Decompress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, readErr = io.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Error("expected Content-Encoding removed once the body is inflated")
+		}
+	}))
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/sync/batch", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	if rec := post("gzip", compress([]byte(`{"jobs":[]}`))); rec.Code != http.StatusOK || string(got) != `{"jobs":[]}` || readErr != nil {
+		t.Fatalf("expected the body inflated, got %d %q (%v)", rec.Code, got, readErr)
+	}
+	post("gzip", compress(bytes.Repeat([]byte("a"), 2048)))
+	var tooLarge *http.MaxBytesError
+	if !errors.As(readErr, &tooLarge) {
+		t.Errorf("expected a body inflating past the limit cut off, got %v", readErr)
+	}
+	if rec := post("gzip", []byte("not gzip")); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a body that is not gzip rejected, got %d", rec.Code)
+	}
+	if rec := post("br", []byte("...")); rec.Code != http.StatusUnsupportedMediaType || rec.Header().Get("Accept-Encoding") != "gzip" {
+		t.Errorf("expected other encodings refused, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := post("", []byte("plain")); rec.Code != http.StatusOK || string(got) != "plain" {
+		t.Errorf("expected plain bodies passed through, got %d %q", rec.Code, got)
+	}
+}
+
+func benchmarkScreen(b *testing.B, acceptEncoding string, level int) {
+	payload := screenPayload(50)
+	h := Compress(1024, level)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(payload)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	var sent int
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		sent = rec.Body.Len()
+	}
+	b.ReportMetric(float64(sent), "sent-bytes/op")
+}
+
+func BenchmarkScreenUncompressed(b *testing.B) { benchmarkScreen(b, "", -1) }
+func BenchmarkScreenGzipDefault(b *testing.B)  { benchmarkScreen(b, "gzip", -1) }
+func BenchmarkScreenGzipFastest(b *testing.B)  { benchmarkScreen(b, "gzip", 1) }
+
+func BenchmarkDecompressBatch(b *testing.B) {
+	payload := []byte(strings.Repeat(`{"id":"job-1","status":"completed","notes":"Treated perimeter"},`, 500))
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(payload)
+	_ = zw.Close()
+	h := Decompress(32 << 20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/sync/batch", bytes.NewReader(buf.Bytes()))
+		req.Header.Set("Content-Encoding", "gzip")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
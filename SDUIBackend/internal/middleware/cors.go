@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// corsMaxAge is how long browsers may cache a preflight response.
+const corsMaxAge = 10 * 60 // seconds
+
+// corsMethods are the methods cross-origin callers may use.
+const corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// corsExposed are the response headers cross-origin scripts may read,
+// beyond those browsers always expose.
+const corsExposed = "X-Correlation-ID, X-SDUI-Stale, X-SDUI-Experiment, Location, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, Content-Disposition, Deprecation, Sunset, Warning"
+
+// CORS lets browser tooling on the allowed origins call the API. An entry
+// of "*" allows any origin. With no origins configured, every origin is
+// allowed unless strict is set, as it is in prod, where cross-origin
+// requests are then refused. Preflight requests are answered here and go
+// no further; requests without an Origin, or from the API's own origin,
+// pass through untouched.
+func CORS(allowed []string, strict bool) func(http.Handler) http.Handler {
+	origins := make(map[string]bool, len(allowed))
+	for _, o := range allowed {
+		origins[strings.TrimSuffix(strings.ToLower(o), "/")] = true
+	}
+	permitted := func(origin string) bool {
+		if len(origins) == 0 {
+			return !strict
+		}
+		return origins["*"] || origins[strings.ToLower(origin)]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || sameOrigin(origin, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+			if !permitted(origin) {
+				respond.Error(w, http.StatusForbidden, "origin not allowed", "cross-origin requests from "+origin+" are not allowed")
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if !preflight {
+				w.Header().Set("Access-Control-Expose-Headers", corsExposed)
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// sameOrigin reports whether origin is the host the request was sent to;
+// browsers send Origin on some same-origin requests too.
+func sameOrigin(origin string, r *http.Request) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
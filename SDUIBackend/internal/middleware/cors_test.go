@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsRequest(t *testing.T, h http.Handler, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "http://api.example.com/v1/screens/today", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSAllowsConfiguredOrigins(t *testing.T) {
+	called := 0
+	h := CORS([]string{"https://App.example.com/"}, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+	}))
+
+	rec := corsRequest(t, h, http.MethodGet, "https://app.example.com", false)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("expected the allowed origin echoed, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") != corsExposed {
+		t.Fatalf("expected the correlation ID exposed, got %q", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+
+	rec = corsRequest(t, h, http.MethodOptions, "https://app.example.com", true)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Headers") != "Authorization, Content-Type" || rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Fatalf("expected the preflight answered, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = corsRequest(t, h, http.MethodGet, "https://evil.example.com", false)
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected another origin refused, got %d %v", rec.Code, rec.Header())
+	}
+
+	if rec = corsRequest(t, h, http.MethodGet, "", false); rec.Code != http.StatusOK {
+		t.Fatalf("expected requests without an origin to pass, got %d", rec.Code)
+	}
+	if rec = corsRequest(t, h, http.MethodGet, "http://api.example.com", false); rec.Code != http.StatusOK {
+		t.Fatalf("expected same-origin requests to pass, got %d", rec.Code)
+	}
+	if called != 3 {
+		t.Fatalf("expected the preflight and refused requests to stop at the middleware, handler ran %d times", called)
+	}
+}
+
+func TestCORSWithoutOrigins(t *testing.T) {
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	if rec := corsRequest(t, CORS(nil, false)(next), http.MethodGet, "http://localhost:3000", false); rec.Code != http.StatusOK {
+		t.Fatalf("expected any origin allowed outside prod, got %d", rec.Code)
+	}
+	if rec := corsRequest(t, CORS(nil, true)(next), http.MethodOptions, "http://localhost:3000", true); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected every origin refused in prod, got %d", rec.Code)
+	}
+	if rec := corsRequest(t, CORS([]string{"*"}, true)(next), http.MethodGet, "http://localhost:3000", false); rec.Code != http.StatusOK {
+		t.Fatalf("expected a wildcard to allow any origin, got %d", rec.Code)
+	}
+}
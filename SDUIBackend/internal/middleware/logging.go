@@ -11,11 +11,20 @@ import (
 // loggerKey is the context key for the request logger.
 type loggerKey struct{}
 
-// WithLogger injects the shared logger into the request context.
+// WithLogger injects the shared logger into the request context, carrying
+// the request's correlation ID, which problem responses echo, so a failure
+// a client reports can be found in the logs. It must run after Correlation.
 func WithLogger(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := context.WithValue(r.Context(), loggerKey{}, logger)
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			if id := FromContext(r.Context()); id != "" {
+				l = l.With(slog.String("correlationId", id))
+			}
+			ctx := context.WithValue(r.Context(), loggerKey{}, l)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -44,6 +53,12 @@ func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// ContextWithLogger returns ctx carrying logger, for middleware that enriches
+// the request logger with extra attributes.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
 // LoggerFrom extracts the logger from context.
 func LoggerFrom(ctx context.Context) *slog.Logger {
 	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
@@ -61,3 +76,5 @@ func (rw *responseWriter) WriteHeader(status int) {
 	rw.status = status
 	rw.ResponseWriter.WriteHeader(status)
 }
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Client is where a request came from.
+type Client struct {
+	// IP is the client's address: the connection's peer, or the address a
+	// trusted proxy forwarded the request for.
+	IP netip.Addr
+	// Proxied is set when the request arrived through a trusted proxy, so
+	// headers the proxy sets, such as a client country, can be believed.
+	Proxied bool
+}
+
+// clientKey is the context key for the request's client.
+type clientKey struct{}
+
+// ClientFrom returns the client of the request ctx belongs to, if known.
+func ClientFrom(ctx context.Context) (Client, bool) {
+	c, ok := ctx.Value(clientKey{}).(Client)
+	return c, ok && c.IP.IsValid()
+}
+
+// RealIP resolves the client address of each request and sets it as the
+// request's RemoteAddr. X-Forwarded-For and X-Real-IP are honored only when
+// the connection comes from one of the trusted proxies, given as addresses
+// or CIDR ranges; X-Forwarded-For is then read right to left, skipping the
+// trusted proxies, so addresses a client prepends are ignored. Entries that
+// do not parse are skipped; config rejects them.
+func RealIP(trusted []string) func(http.Handler) http.Handler {
+	proxies := make([]netip.Prefix, 0, len(trusted))
+	for _, t := range trusted {
+		if p, err := netip.ParsePrefix(t); err == nil {
+			proxies = append(proxies, p.Masked())
+		} else if a, err := netip.ParseAddr(t); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+		}
+	}
+	isProxy := func(a netip.Addr) bool {
+		for _, p := range proxies {
+			if p.Contains(a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, ok := parseIP(r.RemoteAddr)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			client := Client{IP: peer}
+			if isProxy(peer) {
+				client.Proxied = true
+				client.IP = forwardedFor(r.Header, peer, isProxy)
+				r.RemoteAddr = client.IP.String()
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+		})
+	}
+}
+
+// forwardedFor returns the nearest address in the forwarding headers that
+// is not a trusted proxy, or the farthest valid one when every address is.
+func forwardedFor(h http.Header, peer netip.Addr, isProxy func(netip.Addr) bool) netip.Addr {
+	var hops []string
+	for _, v := range h.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	if len(hops) == 0 {
+		if a, ok := parseIP(h.Get("X-Real-IP")); ok {
+			return a
+		}
+		return peer
+	}
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		a, ok := parseIP(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+		client = a
+		if !isProxy(a) {
+			break
+		}
+	}
+	return client
+}
+
+// parseIP parses an address with or without a port.
+func parseIP(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return a.Unmap(), true
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPHonorsOnlyTrustedProxies(t *testing.T) {
+	var got Client
+	var remote string
+	h := RealIP([]string{"10.0.0.0/8", "35.191.0.0/16", "::1"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ClientFrom(r.Context())
+		remote = r.RemoteAddr
+	}))
+	cases := []struct {
+		name, peer, forwarded, realIP string
+		want                          string
+		proxied                       bool
+	}{
+		{"direct", "198.51.100.7:4431", "", "", "198.51.100.7", false},
+		{"spoofed header from an untrusted peer", "198.51.100.7:4431", "203.0.113.9", "203.0.113.9", "198.51.100.7", false},
+		{"through the load balancer", "10.1.2.3:5000", "203.0.113.9, 35.191.4.5", "", "203.0.113.9", true},
+		{"client-prepended address ignored", "10.1.2.3:5000", "192.0.2.66, 203.0.113.9, 35.191.4.5", "", "203.0.113.9", true},
+		{"real ip header", "[::1]:5000", "", "203.0.113.9", "203.0.113.9", true},
+		{"garbage stops the walk", "10.1.2.3:5000", "203.0.113.9, not-an-ip, 35.191.4.5", "", "35.191.4.5", true},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/routes", nil)
+		req.RemoteAddr = tc.peer
+		if tc.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if tc.realIP != "" {
+			req.Header.Set("X-Real-IP", tc.realIP)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if got.IP.String() != tc.want || got.Proxied != tc.proxied {
+			t.Errorf("%s: expected %s (proxied %v), got %+v", tc.name, tc.want, tc.proxied, got)
+		}
+		if tc.proxied && remote != tc.want {
+			t.Errorf("%s: expected RemoteAddr %s, got %s", tc.name, tc.want, remote)
+		}
+	}
+}
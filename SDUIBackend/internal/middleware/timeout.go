@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout cancels each request's context after d, as chi's Timeout does,
+// except on the long-lived streams at the paths given, which end when the
+// client leaves.
+func Timeout(d time.Duration, streams ...string) func(http.Handler) http.Handler {
+	timeout := chimw.Timeout(d)
+	return func(next http.Handler) http.Handler {
+		limited := timeout(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range streams {
+				if r.URL.Path == path {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
@@ -4,18 +4,45 @@ import "time"
 
 // SDUIScreen mirrors the contract consumed by the iOS app.
 type SDUIScreen struct {
-	Version   int           `json:"version"`
-	Component SDUIComponent `json:"component"`
+	Version int `json:"version"`
+	// MinAppVersion is the oldest app build the screen can be shown on;
+	// older builds get the update fallback instead.
+	MinAppVersion string        `json:"minAppVersion,omitempty"`
+	Component     SDUIComponent `json:"component"`
+	// Theme is the branding of the technician's company, when it has any.
+	Theme *ScreenTheme `json:"theme,omitempty"`
 }
 
+// ScreenTheme brands a screen with hex colors and a logo. Component colors
+// naming BrandPrimary, BrandSecondary or BrandAccent are replaced with the
+// theme's color before the screen is served.
+type ScreenTheme struct {
+	PrimaryColor   string `json:"primaryColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty"`
+	AccentColor    string `json:"accentColor,omitempty"`
+	LogoURL        string `json:"logoUrl,omitempty"`
+}
+
+// Brand color tokens templates can use as component colors.
+const (
+	BrandPrimary   = "brand.primary"
+	BrandSecondary = "brand.secondary"
+	BrandAccent    = "brand.accent"
+)
+
 // SDUIComponent represents a single node in the component tree. Only the
 // commonly used fields are modelled here; the service can extend this struct as
 // new component capabilities are added.
 type SDUIComponent struct {
-	ID           string             `json:"id,omitempty"`
-	Type         string             `json:"type"`
-	Key          string             `json:"key,omitempty"`
-	Text         string             `json:"text,omitempty"`
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type"`
+	Key  string `json:"key,omitempty"`
+	Text string `json:"text,omitempty"`
+	// TextKey names a translation in the server's catalogs. It replaces
+	// Text, or Label on components with a label and no text, in the
+	// request's locale; Text and Label are the fallback when no catalog in
+	// the locale's chain has the key.
+	TextKey      string             `json:"textKey,omitempty"`
 	Label        string             `json:"label,omitempty"`
 	ActionID     string             `json:"actionId,omitempty"`
 	Font         string             `json:"font,omitempty"`
@@ -29,8 +56,29 @@ type SDUIComponent struct {
 	Children     []SDUIComponent    `json:"children,omitempty"`
 	ItemView     *SDUIComponent     `json:"itemView,omitempty"`
 	Options      []SDUIPickerOption `json:"options,omitempty"`
+	// Asset names an uploaded image; the server fills ImageURL with its
+	// current version's URL when the screen is served.
+	Asset    string `json:"asset,omitempty"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	// MinAppVersion is the oldest app build that can render the component.
+	// Older builds get the update fallback in its place.
+	MinAppVersion string `json:"minAppVersion,omitempty"`
+	// FlagKey, on a conditionalFlag, names the feature flag deciding
+	// whether its children render: they do when the flag equals FlagValue,
+	// or is truthy when FlagValue is empty. FlagDefault stands in for a
+	// flag that does not resolve. The server resolves every
+	// conditionalFlag, so clients never see one.
+	FlagKey     string `json:"flagKey,omitempty"`
+	FlagValue   string `json:"flagValue,omitempty"`
+	FlagDefault bool   `json:"flagDefault,omitempty"`
 }
 
+// RandomComponentID as a component's ID asks for a fresh random ID on every
+// render. It is for ad-hoc components the client must never match against
+// an earlier screen; components without an ID get one derived from their
+// position, which stays the same between renders.
+const RandomComponentID = "$random"
+
 // SDUIPickerOption supports picker-style components.
 type SDUIPickerOption struct {
 	ID    string `json:"id"`
@@ -55,6 +103,28 @@ type ServerUpdates struct {
 	Routes             []RouteUpdateData             `json:"routes"`
 	Chemicals          []ChemicalUpdateData          `json:"chemicals"`
 	ChemicalTreatments []ChemicalTreatmentUpdateData `json:"chemicalTreatments"`
+	// Deletions lists records deleted on the server; devices drop their
+	// copies.
+	Deletions DeletionsData `json:"deletions"`
+	// LastModified is the server-side watermark to send as since next time.
+	LastModified time.Time `json:"lastModified"`
+	// HasMore is set when the page was cut at the limit; fetch the rest by
+	// passing NextCursor back as cursor before relying on LastModified.
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// DeletionsData groups deleted records by entity type.
+type DeletionsData struct {
+	Jobs      []DeletionData `json:"jobs"`
+	Routes    []DeletionData `json:"routes"`
+	Chemicals []DeletionData `json:"chemicals"`
+}
+
+// DeletionData is the tombstone of one deleted record.
+type DeletionData struct {
+	ServerID  string    `json:"serverId"`
+	DeletedAt time.Time `json:"deletedAt"`
 }
 
 // JobUpdateData mirrors the structure consumed by the iOS sync manager.
@@ -65,28 +135,62 @@ type JobUpdateData struct {
 	ScheduledDate time.Time `json:"scheduledDate"`
 	Status        string    `json:"status"`
 	LastModified  time.Time `json:"lastModified"`
+	// Coordinate places the job on the map; absent until its address has
+	// been geocoded.
+	Coordinate *Coordinate `json:"coordinate,omitempty"`
+	// SignatureID is the photo ID of the customer's signature, and
+	// SignatureURL a signed URL of its image; GET /v1/photos/{id}/url
+	// signs a fresh one once it expires.
+	SignatureID  string `json:"signatureId,omitempty"`
+	SignatureURL string `json:"signatureUrl,omitempty"`
 }
 
 // RouteUpdateData describes technician route metadata.
 type RouteUpdateData struct {
-	ServerID     string    `json:"serverId"`
-	Name         string    `json:"name"`
-	Date         time.Time `json:"date"`
-	TechnicianID string    `json:"technicianId"`
-	LastModified time.Time `json:"lastModified"`
+	ServerID     string                `json:"serverId"`
+	Name         string                `json:"name"`
+	Date         time.Time             `json:"date"`
+	TechnicianID string                `json:"technicianId"`
+	Stops        []RouteStopUpdateData `json:"stops"`
+	LastModified time.Time             `json:"lastModified"`
+}
+
+// RouteStopUpdateData is one stop of a route, in visiting order.
+type RouteStopUpdateData struct {
+	CustomerID   string `json:"customerId"`
+	CustomerName string `json:"customerName"`
+	Address      string `json:"address"`
+	// Coordinate places the stop on the map; absent until its address has
+	// been geocoded.
+	Coordinate *Coordinate `json:"coordinate,omitempty"`
+}
+
+// Coordinate is a position in decimal degrees.
+type Coordinate struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 // ChemicalUpdateData captures inventory updates.
 type ChemicalUpdateData struct {
-	ServerID         string    `json:"serverId"`
-	Name             string    `json:"name"`
-	ActiveIngredient string    `json:"activeIngredient"`
-	ManufacturerName string    `json:"manufacturerName"`
-	EPARegistration  string    `json:"epaRegistrationNumber"`
-	QuantityInStock  float64   `json:"quantityInStock"`
-	UnitOfMeasure    string    `json:"unitOfMeasure"`
-	ExpirationDate   time.Time `json:"expirationDate"`
-	LastModified     time.Time `json:"lastModified"`
+	ServerID         string            `json:"serverId"`
+	Name             string            `json:"name"`
+	ActiveIngredient string            `json:"activeIngredient"`
+	ManufacturerName string            `json:"manufacturerName"`
+	EPARegistration  string            `json:"epaRegistrationNumber"`
+	QuantityInStock  float64           `json:"quantityInStock"`
+	UnitOfMeasure    string            `json:"unitOfMeasure"`
+	ExpirationDate   time.Time         `json:"expirationDate"`
+	Lots             []ChemicalLotData `json:"lots,omitempty"`
+	LastModified     time.Time         `json:"lastModified"`
+}
+
+// ChemicalLotData is a chemical's stock from one manufacturer lot.
+type ChemicalLotData struct {
+	LotNumber      string    `json:"lotNumber"`
+	ReceivedDate   time.Time `json:"receivedDate"`
+	ExpirationDate time.Time `json:"expirationDate,omitempty"`
+	Quantity       float64   `json:"quantity"`
 }
 
 // ChemicalTreatmentUpdateData mirrors on-device expectations.
@@ -94,11 +198,26 @@ type ChemicalTreatmentUpdateData struct {
 	ServerID          string    `json:"serverId"`
 	JobServerID       string    `json:"jobServerId"`
 	ChemicalServerID  string    `json:"chemicalServerId"`
+	LotNumber         string    `json:"lotNumber,omitempty"`
+	MixID             string    `json:"mixId,omitempty"`
+	EquipmentID       string    `json:"equipmentId,omitempty"`
 	ApplicationDate   time.Time `json:"applicationDate"`
 	ApplicationMethod string    `json:"applicationMethod"`
 	TargetPests       string    `json:"targetPests"`
 	QuantityUsed      float64   `json:"quantityUsed"`
-	LastModified      time.Time `json:"lastModified"`
+	// Weather is what the server fetched for the treatment's stop and time.
+	Weather      *WeatherReading `json:"weather,omitempty"`
+	LastModified time.Time       `json:"lastModified"`
+}
+
+// WeatherReading is the conditions a weather provider reported.
+type WeatherReading struct {
+	Source       string    `json:"source"`
+	TemperatureC float64   `json:"temperatureC"`
+	WindSpeedKPH float64   `json:"windSpeedKph"`
+	WindGustKPH  float64   `json:"windGustKph,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
+	At           time.Time `json:"at"`
 }
 
 // UploadResponse mirrors the shape expected by the iOS client after POSTs.
@@ -107,14 +226,18 @@ type UploadResponse struct {
 	JobID    string `json:"jobId"`
 	ServerID string `json:"serverId,omitempty"`
 	Message  string `json:"message,omitempty"`
+	// Warnings are accepted-but-noteworthy conditions, such as equipment
+	// due for calibration.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // PhotoUploadResponse is returned when image uploads complete.
 type PhotoUploadResponse struct {
-	Success bool   `json:"success"`
-	PhotoID string `json:"photoId"`
-	URL     string `json:"url,omitempty"`
-	Message string `json:"message,omitempty"`
+	Success   bool       `json:"success"`
+	PhotoID   string     `json:"photoId"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Message   string     `json:"message,omitempty"`
 }
 
 // DeviceRegistration matches the payload sent from the iOS notification manager.
@@ -122,6 +245,16 @@ type DeviceRegistration struct {
 	Token    string `json:"token"`
 	Platform string `json:"platform"`
 	BundleID string `json:"bundleId"`
+	// TechnicianID is used when the request has no userId query parameter.
+	TechnicianID string `json:"technicianId,omitempty"`
+}
+
+// DeviceData describes a device registered for push notifications.
+type DeviceData struct {
+	Token        string    `json:"token"`
+	Platform     string    `json:"platform"`
+	BundleID     string    `json:"bundleId"`
+	RegisteredAt time.Time `json:"registeredAt"`
 }
 
 // JobUploadData is received when the app sends pending job entities.
@@ -135,16 +268,17 @@ type JobUploadData struct {
 
 // ChemicalUploadData is the inbound chemical payload.
 type ChemicalUploadData struct {
-	ID               string    `json:"id"`
-	Name             string    `json:"name"`
-	ActiveIngredient string    `json:"activeIngredient"`
-	ManufacturerName string    `json:"manufacturerName"`
-	EPARegistration  string    `json:"epaRegistrationNumber"`
-	Concentration    float64   `json:"concentration"`
-	UnitOfMeasure    string    `json:"unitOfMeasure"`
-	QuantityInStock  float64   `json:"quantityInStock"`
-	ExpirationDate   time.Time `json:"expirationDate"`
-	LastModified     time.Time `json:"lastModified"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	ActiveIngredient string            `json:"activeIngredient"`
+	ManufacturerName string            `json:"manufacturerName"`
+	EPARegistration  string            `json:"epaRegistrationNumber"`
+	Concentration    float64           `json:"concentration"`
+	UnitOfMeasure    string            `json:"unitOfMeasure"`
+	QuantityInStock  float64           `json:"quantityInStock"`
+	ExpirationDate   time.Time         `json:"expirationDate"`
+	Lots             []ChemicalLotData `json:"lots,omitempty"`
+	LastModified     time.Time         `json:"lastModified"`
 }
 
 // ChemicalTreatmentUploadData is the inbound treatment record from the device.
@@ -152,6 +286,8 @@ type ChemicalTreatmentUploadData struct {
 	ID                 string    `json:"id"`
 	JobID              string    `json:"jobId"`
 	ChemicalID         string    `json:"chemicalId"`
+	LotNumber          string    `json:"lotNumber"` // required when the chemical tracks lots
+	EquipmentID        string    `json:"equipmentId,omitempty"`
 	ApplicatorName     string    `json:"applicatorName"`
 	ApplicationDate    time.Time `json:"applicationDate"`
 	ApplicationMethod  string    `json:"applicationMethod"`
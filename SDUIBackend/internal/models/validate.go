@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/validate"
+)
+
+// Platforms a device can register for push notifications on.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+)
+
+// Validate reports every field of the job that breaks a rule.
+func (p JobUploadData) Validate() error {
+	var errs validate.Errors
+	errs.Required("id", p.ID)
+	errs.Required("customerName", p.CustomerName)
+	errs.Required("address", p.Address)
+	errs.RequiredTime("scheduledDate", p.ScheduledDate)
+	errs.Required("status", p.Status)
+	return errs.Err()
+}
+
+// Validate reports every field of the chemical, and of its lots, that
+// breaks a rule. Lot numbers must be unique within the chemical.
+func (p ChemicalUploadData) Validate() error {
+	var errs validate.Errors
+	errs.Required("id", p.ID)
+	errs.Required("name", p.Name)
+	errs.NonNegative("concentration", p.Concentration)
+	errs.NonNegative("quantityInStock", p.QuantityInStock)
+	seen := make(map[string]bool, len(p.Lots))
+	for i, lot := range p.Lots {
+		field := fmt.Sprintf("lots[%d]", i)
+		number := strings.TrimSpace(lot.LotNumber)
+		switch {
+		case number == "":
+			errs.Add(field+".lotNumber", "is required")
+		case seen[number]:
+			errs.Add(field+".lotNumber", fmt.Sprintf("%s is listed more than once", number))
+		}
+		seen[number] = true
+		errs.NonNegative(field+".quantity", lot.Quantity)
+	}
+	return errs.Err()
+}
+
+// Validate reports every field of the treatment that breaks a rule.
+func (p ChemicalTreatmentUploadData) Validate() error {
+	var errs validate.Errors
+	errs.Required("id", p.ID)
+	errs.Required("jobId", p.JobID)
+	errs.Required("chemicalId", p.ChemicalID)
+	errs.RequiredTime("applicationDate", p.ApplicationDate)
+	errs.Positive("quantityUsed", p.QuantityUsed)
+	errs.NonNegative("dosageRate", p.DosageRate)
+	return errs.Err()
+}
+
+// Validate reports every field of the registration that breaks a rule.
+func (p DeviceRegistration) Validate() error {
+	var errs validate.Errors
+	errs.Required("token", p.Token)
+	errs.OneOf("platform", p.Platform, PlatformIOS, PlatformAndroid)
+	return errs.Err()
+}
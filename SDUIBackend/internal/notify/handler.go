@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"net/http"
+	"strconv"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Inbox sizes.
+const (
+	defaultInboxLimit = 50
+	maxInboxLimit     = 200
+)
+
+// Handler exposes the technician's inbox.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an inbox handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListInbox returns the technician's inbox messages, newest first, up to
+// ?limit=.
+func (h *Handler) ListInbox(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	limit := defaultInboxLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxInboxLimit {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and "+strconv.Itoa(maxInboxLimit))
+			return
+		}
+		limit = n
+	}
+	messages, err := h.service.Messages(me, limit)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to list inbox", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to list inbox", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	if messages == nil {
+		messages = []Message{}
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"messages": messages})
+}
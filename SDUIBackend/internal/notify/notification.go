@@ -0,0 +1,127 @@
+// Package notify delivers notifications to technicians as pushes and inbox
+// messages. Notifications are batched per technician and category for the
+// category's digest window, so a burst of related changes, such as dispatch
+// editing every stop of a route, reaches the technician as one summarized
+// push instead of dozens.
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// Categories group notifications for digesting.
+const (
+	CategoryRoute     = "route"
+	CategoryJob       = "job"
+	CategoryInventory = "inventory"
+	CategoryGeneral   = "general"
+)
+
+// digestTitles title digests of several notifications in a category.
+var digestTitles = map[string]string{
+	CategoryRoute:     "%d route updates",
+	CategoryJob:       "%d job updates",
+	CategoryInventory: "%d inventory updates",
+	CategoryGeneral:   "%d notifications",
+}
+
+// Notification is something a technician should hear about.
+type Notification struct {
+	TechnicianID string
+	Category     string
+	// CollapseKey identifies related notifications, such as changes to one
+	// route; a digest keeps only the latest notification per key. Empty
+	// keys never collapse.
+	CollapseKey string
+	Title       string
+	Body        string
+	Data        map[string]string
+}
+
+// Message is an inbox message: one notification, or the digest of several.
+type Message struct {
+	ID           string `json:"id"`
+	TechnicianID string `json:"technicianId"`
+	Category     string `json:"category"`
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	// Count is how many notifications the message summarizes.
+	Count     int               `json:"count"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// Push is what is sent to a technician's devices for a message.
+type Push struct {
+	MessageID string
+	Category  string
+	Title     string
+	Body      string
+	Data      map[string]string
+}
+
+// Sender delivers a push to one device.
+type Sender interface {
+	Send(ctx context.Context, device domain.DeviceToken, p Push) error
+}
+
+// LogSender logs pushes instead of delivering them, for environments without
+// push credentials.
+type LogSender struct {
+	Logger *slog.Logger
+}
+
+// Send logs p.
+func (s LogSender) Send(_ context.Context, device domain.DeviceToken, p Push) error {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("push notification", slog.String("technician", device.TechnicianID), slog.String("platform", device.Platform), slog.String("message", p.MessageID), slog.String("title", p.Title))
+	return nil
+}
+
+// Store persists inbox messages.
+type Store interface {
+	SaveMessage(m Message) error
+	// ListMessages returns a technician's messages, newest first, up to
+	// limit.
+	ListMessages(technicianID string, limit int) ([]Message, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	messages map[string][]Message // by technician, oldest first
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string][]Message)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveMessage(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[msg.TechnicianID] = append(m.messages[msg.TechnicianID], msg)
+	return nil
+}
+
+func (m *MemoryStore) ListMessages(technicianID string, limit int) ([]Message, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	messages := m.messages[technicianID]
+	var out []Message
+	for i := len(messages) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		out = append(out, messages[i])
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+type recordingSender struct {
+	mu     sync.Mutex
+	pushes []Push
+}
+
+func (s *recordingSender) Send(_ context.Context, _ domain.DeviceToken, p Push) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushes = append(s.pushes, p)
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *recordingSender, *time.Time) {
+	t.Helper()
+	store := storememory.NewStore()
+	if err := store.SaveDeviceToken(domain.DeviceToken{Token: "tok-1", TechnicianID: "tech-1", Platform: "ios"}); err != nil {
+		t.Fatalf("save device: %v", err)
+	}
+	sender := &recordingSender{}
+	svc := NewService(config.NotifyConfig{DigestWindows: "route=2m"}, NewMemoryStore(), repository.Repository{Devices: store}, sender, nil)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, sender, &now
+}
+
+func TestRouteEditsAreDigested(t *testing.T) {
+	svc, sender, now := newTestService(t)
+	ctx := context.Background()
+	for i := 0; i < 40; i++ {
+		svc.Notify(ctx, Notification{TechnicianID: "tech-1", Category: CategoryRoute, CollapseKey: "route-1", Title: "Route for Mon Mar 2 updated"})
+	}
+	for _, id := range []string{"route-2", "route-3", "route-4"} {
+		svc.Notify(ctx, Notification{TechnicianID: "tech-1", Category: CategoryRoute, CollapseKey: id, Title: id + " updated"})
+	}
+
+	svc.flush(ctx, now.Add(time.Minute))
+	if len(sender.pushes) != 0 {
+		t.Fatalf("expected nothing sent inside the window, got %d pushes", len(sender.pushes))
+	}
+
+	svc.flush(ctx, now.Add(2*time.Minute))
+	if len(sender.pushes) != 1 {
+		t.Fatalf("expected one digest push, got %d", len(sender.pushes))
+	}
+	push := sender.pushes[0]
+	if push.Title != "4 route updates" || push.Body != "Route for Mon Mar 2 updated; route-2 updated; route-3 updated and 1 more" {
+		t.Fatalf("unexpected digest %q / %q", push.Title, push.Body)
+	}
+	inbox, _ := svc.Messages("tech-1", 10)
+	if len(inbox) != 1 || inbox[0].Count != 43 || inbox[0].ID != push.MessageID {
+		t.Fatalf("expected one inbox message covering 43 notifications, got %+v", inbox)
+	}
+	if stats := svc.Stats(); stats["collapsed"] != 39 || stats["pending"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestCategoriesWithoutWindowSendAtOnce(t *testing.T) {
+	svc, sender, _ := newTestService(t)
+	svc.Notify(context.Background(), Notification{TechnicianID: "tech-1", Title: "Truck stock count due", Body: "Count before 5pm"})
+	if len(sender.pushes) != 1 || sender.pushes[0].Title != "Truck stock count due" || sender.pushes[0].Category != CategoryGeneral {
+		t.Fatalf("expected the notification sent as it is, got %+v", sender.pushes)
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(svc).ListInbox(rec, httptest.NewRequest(http.MethodGet, "/v1/inbox?userId=tech-1&limit=500", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an oversized limit rejected, got %d", rec.Code)
+	}
+}
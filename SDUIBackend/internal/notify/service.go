@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// flushInterval is how often Run looks for digests whose window has passed.
+const flushInterval = time.Second
+
+// digestLines is how many notification titles a digest's body lists.
+const digestLines = 3
+
+// Service batches notifications into digests and delivers them.
+type Service struct {
+	windows       map[string]time.Duration
+	defaultWindow time.Duration
+	store         Store
+	devices       repository.DeviceRepository
+	sender        Sender
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	pending map[batchKey]*batch
+
+	sent      atomic.Int64
+	collapsed atomic.Int64
+}
+
+type batchKey struct {
+	technicianID string
+	category     string
+}
+
+// batch holds a technician's notifications of one category until due.
+type batch struct {
+	due     time.Time
+	entries []Notification // latest per collapse key, in arrival order
+	count   int            // notifications received, collapsed or not
+}
+
+// NewService wires a notification service. cfg must already be validated.
+// Pushes go to the technician's devices in repos through sender; a nil
+// sender logs them.
+func NewService(cfg config.NotifyConfig, store Store, repos repository.Repository, sender Sender, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if sender == nil {
+		sender = LogSender{Logger: logger}
+	}
+	windows, _ := cfg.Windows()
+	return &Service{
+		windows:       windows,
+		defaultWindow: cfg.DefaultWindow,
+		store:         store,
+		devices:       repos.Devices,
+		sender:        sender,
+		logger:        logger,
+		now:           time.Now,
+		pending:       make(map[batchKey]*batch),
+	}
+}
+
+// window returns how long category's notifications are held.
+func (s *Service) window(category string) time.Duration {
+	if w, ok := s.windows[category]; ok {
+		return w
+	}
+	return s.defaultWindow
+}
+
+// Notify queues n for the technician. It is delivered when its category's
+// window, started by the first pending notification, has passed, or at once
+// when the category has no window. A nil service drops notifications.
+func (s *Service) Notify(ctx context.Context, n Notification) {
+	if s == nil {
+		return
+	}
+	if n.Category == "" {
+		n.Category = CategoryGeneral
+	}
+	window := s.window(n.Category)
+	if window <= 0 {
+		s.deliver(ctx, n.TechnicianID, n.Category, &batch{entries: []Notification{n}, count: 1})
+		return
+	}
+
+	key := batchKey{technicianID: n.TechnicianID, category: n.Category}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.pending[key]
+	if !ok {
+		b = &batch{due: s.now().Add(window)}
+		s.pending[key] = b
+	}
+	b.count++
+	if n.CollapseKey != "" {
+		for i, e := range b.entries {
+			if e.CollapseKey == n.CollapseKey {
+				b.entries = append(append(b.entries[:i:i], b.entries[i+1:]...), n)
+				s.collapsed.Add(1)
+				return
+			}
+		}
+	}
+	b.entries = append(b.entries, n)
+}
+
+// Run delivers digests as their windows pass until ctx is cancelled, then
+// delivers whatever is still pending.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background(), time.Time{})
+			return
+		case <-ticker.C:
+			s.flush(ctx, s.now())
+		}
+	}
+}
+
+// flush delivers the batches due by now, or every batch when now is zero.
+func (s *Service) flush(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := make(map[batchKey]*batch)
+	for key, b := range s.pending {
+		if now.IsZero() || !now.Before(b.due) {
+			due[key] = b
+			delete(s.pending, key)
+		}
+	}
+	s.mu.Unlock()
+	for key, b := range due {
+		s.deliver(ctx, key.technicianID, key.category, b)
+	}
+}
+
+// deliver saves a batch as one inbox message and pushes it to each of the
+// technician's devices.
+func (s *Service) deliver(ctx context.Context, technicianID, category string, b *batch) {
+	msg := digest(technicianID, category, b)
+	msg.ID = uuid.NewString()
+	msg.CreatedAt = s.now().UTC()
+	if err := s.store.SaveMessage(msg); err != nil {
+		s.logger.Error("save inbox message", slog.String("technician", technicianID), slog.Any("error", err))
+	}
+	s.sent.Add(1)
+
+	devices, err := s.devices.ListDeviceTokens(technicianID)
+	if err != nil {
+		s.logger.Error("list devices for push", slog.String("technician", technicianID), slog.Any("error", err))
+		return
+	}
+	push := Push{MessageID: msg.ID, Category: category, Title: msg.Title, Body: msg.Body, Data: msg.Data}
+	for _, device := range devices {
+		if err := s.sender.Send(ctx, device, push); err != nil {
+			s.logger.Warn("push failed", slog.String("technician", technicianID), slog.String("platform", device.Platform), slog.Any("error", err))
+		}
+	}
+}
+
+// digest summarizes a batch. A lone notification is sent as it is; several
+// are titled by count with the first few titles as the body.
+func digest(technicianID, category string, b *batch) Message {
+	msg := Message{TechnicianID: technicianID, Category: category, Count: b.count}
+	if len(b.entries) == 1 {
+		n := b.entries[0]
+		msg.Title, msg.Body, msg.Data = n.Title, n.Body, n.Data
+		return msg
+	}
+	format, ok := digestTitles[category]
+	if !ok {
+		format = digestTitles[CategoryGeneral]
+	}
+	msg.Title = fmt.Sprintf(format, len(b.entries))
+	lines := make([]string, 0, digestLines)
+	for _, n := range b.entries[:min(digestLines, len(b.entries))] {
+		lines = append(lines, n.Title)
+	}
+	msg.Body = strings.Join(lines, "; ")
+	if more := len(b.entries) - len(lines); more > 0 {
+		msg.Body += fmt.Sprintf(" and %d more", more)
+	}
+	return msg
+}
+
+// Messages returns a technician's inbox, newest first.
+func (s *Service) Messages(technicianID string, limit int) ([]Message, error) {
+	return s.store.ListMessages(technicianID, limit)
+}
+
+// Stats reports messages sent, notifications collapsed into a newer one for
+// the same subject, and batches waiting for their window.
+func (s *Service) Stats() map[string]int64 {
+	if s == nil {
+		return map[string]int64{}
+	}
+	s.mu.Lock()
+	pending := len(s.pending)
+	s.mu.Unlock()
+	return map[string]int64{
+		"sent":      s.sent.Load(),
+		"collapsed": s.collapsed.Load(),
+		"pending":   int64(pending),
+	}
+}
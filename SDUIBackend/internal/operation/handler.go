@@ -0,0 +1,113 @@
+package operation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes operations to the callers that started them.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates an operation handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the operation endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListOperations)
+	r.Get("/{operationId}", h.GetOperation)
+	r.Post("/{operationId}/cancel", h.CancelOperation)
+}
+
+// Accepted answers a request that started op, pointing the client at it.
+func Accepted(w http.ResponseWriter, op Operation) {
+	w.Header().Set("Location", Path+"/"+op.ID)
+	respond.JSON(w, http.StatusAccepted, op)
+}
+
+// ListOperations returns the caller's operations, newest first, optionally
+// of one ?kind=. Staff see everyone's, or one ?owner='s.
+func (h *Handler) ListOperations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 50
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	owner := q.Get("owner")
+	if id, ok := auth.FromContext(r.Context()); ok && !id.Staff() {
+		owner = id.Subject
+	}
+	ops, err := h.service.List(owner, q.Get("kind"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list operations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"operations": ops})
+}
+
+// GetOperation returns an operation's status, progress and result.
+func (h *Handler) GetOperation(w http.ResponseWriter, r *http.Request) {
+	op, err := h.visible(r)
+	if err != nil {
+		h.fail(w, r, "failed to load operation", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, op)
+}
+
+// CancelOperation asks a running operation to stop; poll it to see when it
+// has.
+func (h *Handler) CancelOperation(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.visible(r); err != nil {
+		h.fail(w, r, "failed to cancel operation", err)
+		return
+	}
+	op, err := h.service.Cancel(chi.URLParam(r, "operationId"))
+	if err != nil {
+		h.fail(w, r, "failed to cancel operation", err)
+		return
+	}
+	Accepted(w, op)
+}
+
+// visible loads the operation in the path. Technicians only see their own;
+// others are reported as not found.
+func (h *Handler) visible(r *http.Request) (Operation, error) {
+	op, err := h.service.Get(chi.URLParam(r, "operationId"))
+	if err != nil {
+		return Operation{}, err
+	}
+	if id, ok := auth.FromContext(r.Context()); ok && !id.Staff() && op.Owner != id.Subject {
+		return Operation{}, ErrNotFound
+	}
+	return op, nil
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrFinished):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
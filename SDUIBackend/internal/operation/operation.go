@@ -0,0 +1,157 @@
+// Package operation tracks long-running operations. Features that do work
+// asynchronously, such as manual exports or feed polls, register that work
+// by kind, start an operation, which runs from the job queue, and answer
+// 202 Accepted with it; clients then poll GET /v1/operations/{id} for
+// status, progress and result links, and may cancel it, instead of each
+// feature offering its own polling endpoint.
+package operation
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// Path is where operations are served, relative to the API host.
+const Path = "/v1/operations"
+
+// Operation statuses.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	// ErrNotFound is returned when an operation does not exist.
+	ErrNotFound = errors.New("operation not found")
+	// ErrFinished is returned when cancelling an operation that has ended.
+	ErrFinished = errors.New("operation already finished")
+)
+
+// Operation is one run of asynchronous work.
+type Operation struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"` // e.g. export, import, partner-file
+	// Target is the resource the operation acts on, such as a destination.
+	Target string `json:"target,omitempty"`
+	// Owner is the subject that started the operation; empty when the
+	// request was not authenticated.
+	Owner    string   `json:"owner,omitempty"`
+	Status   string   `json:"status"`
+	Progress Progress `json:"progress"`
+	// Links point at the resources the operation produced.
+	Links []Link `json:"links,omitempty"`
+	// Result is the feature's own record of the outcome, such as a delivery.
+	Result          any        `json:"result,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	CancelRequested bool       `json:"cancelRequested,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Progress counts the units of work done, in whatever the operation
+// processes (files, datasets); Total is zero until known.
+type Progress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// Link is a related resource, such as the run an import produced.
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// Done reports whether the operation has ended.
+func (o Operation) Done() bool {
+	return o.Status != StatusPending && o.Status != StatusRunning
+}
+
+// Store persists operations.
+type Store interface {
+	SaveOperation(o Operation) error
+	GetOperation(id string) (Operation, error)
+	// ListOperations returns operations, newest first, filtered by owner and
+	// kind when those are set.
+	ListOperations(owner, kind string, limit int) ([]Operation, error)
+	// PruneOperations removes operations that finished before cutoff and
+	// reports how many.
+	PruneOperations(cutoff time.Time) (int, error)
+}
+
+// DocumentStore keeps operations in the shared document store, so the
+// instance a job reaches finds the operation it runs and every instance
+// answers for it.
+type DocumentStore struct {
+	operations docstore.Collection[Operation]
+}
+
+// NewDocumentStore keeps operations in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{operations: docstore.NewCollection(docs, "operations", func(o Operation) docstore.Keys {
+		return docstore.Keys{"owner": o.Owner, "kind": o.Kind}
+	})}
+}
+
+// NewMemoryStore keeps operations in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveOperation(o Operation) error {
+	return d.operations.Put(o.ID, o)
+}
+
+func (d *DocumentStore) GetOperation(id string) (Operation, error) {
+	o, err := d.operations.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Operation{}, ErrNotFound
+	}
+	return o, err
+}
+
+func (d *DocumentStore) ListOperations(owner, kind string, limit int) ([]Operation, error) {
+	keys := docstore.Keys{}
+	if owner != "" {
+		keys["owner"] = owner
+	}
+	if kind != "" {
+		keys["kind"] = kind
+	}
+	out, err := d.operations.Find(keys)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// PruneOperations deletes one operation at a time; a failure part way
+// leaves the rest for the next run.
+func (d *DocumentStore) PruneOperations(cutoff time.Time) (int, error) {
+	all, err := d.operations.Find(nil)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, o := range all {
+		if o.FinishedAt != nil && o.FinishedAt.Before(cutoff) {
+			if err := d.operations.Delete(o.ID); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,192 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+)
+
+// newTestService returns a service whose memory queue runs until the test
+// ends. Operations of kind run fn.
+func newTestService(t *testing.T, timeout time.Duration, kind string, fn Func) *Service {
+	t.Helper()
+	jobs := jobqueue.NewMemory(config.QueueConfig{BufferSize: 10, MaxAttempts: 1, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, nil)
+	svc := NewService(config.OperationConfig{Timeout: timeout, Retention: time.Hour, PruneInterval: time.Hour, Concurrency: 2}, NewMemoryStore(), jobs, nil)
+	svc.Register(kind, fn)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobs.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return svc
+}
+
+// await polls until the operation has finished.
+func await(t *testing.T, svc *Service, id string) Operation {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		op, err := svc.Get(id)
+		if err != nil {
+			t.Fatalf("get operation: %v", err)
+		}
+		if op.Done() {
+			return op
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("operation %s did not finish", id)
+	return Operation{}
+}
+
+func TestOperationReportsProgressAndResult(t *testing.T) {
+	release := make(chan struct{})
+	svc := newTestService(t, 0, "export", func(ctx context.Context, target string, t *Tracker) (any, error) {
+		t.Progress(1, 2)
+		t.Link("deliveries", "/v1/admin/exports/destinations/"+target+"/deliveries")
+		<-release
+		t.Progress(2, 2)
+		return map[string]int{"rows": 7}, nil
+	})
+	ctx := auth.ContextWithIdentity(context.Background(), auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin})
+	op, err := svc.Start(ctx, "export", "dest-1")
+	if err != nil || op.Status != StatusPending || op.Owner != "admin-1" {
+		t.Fatalf("expected a pending operation owned by the caller, got %+v (%v)", op, err)
+	}
+	if _, err := svc.Start(ctx, "unknown", ""); err == nil {
+		t.Fatal("expected starting an unregistered kind to fail")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := svc.Get(op.ID)
+		if got.Status == StatusRunning && len(got.Links) == 1 && got.Progress.Completed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected progress while running, got %+v", got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	done := await(t, svc, op.ID)
+	if done.Status != StatusSucceeded || done.Progress != (Progress{Completed: 2, Total: 2}) || done.FinishedAt == nil || done.Result == nil {
+		t.Fatalf("expected a succeeded operation with its result, got %+v", done)
+	}
+	if _, err := svc.Cancel(op.ID); !errors.Is(err, ErrFinished) {
+		t.Fatalf("expected cancelling a finished operation to fail, got %v", err)
+	}
+}
+
+func TestOperationFailureKeepsResult(t *testing.T) {
+	svc := newTestService(t, 0, "partner-file", func(context.Context, string, *Tracker) (any, error) {
+		return map[string]string{"status": "failed"}, errors.New("sftp: connection refused")
+	})
+	op, _ := svc.Start(context.Background(), "partner-file", "acme")
+	done := await(t, svc, op.ID)
+	if done.Status != StatusFailed || done.Error != "sftp: connection refused" || done.Result == nil {
+		t.Fatalf("expected a failed operation keeping its result, got %+v", done)
+	}
+}
+
+func TestCancelAndTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	block := func(ctx context.Context, _ string, _ *Tracker) (any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	svc := newTestService(t, 0, "import", block)
+	op, _ := svc.Start(context.Background(), "import", "feed-1")
+	<-started
+	cancelled, err := svc.Cancel(op.ID)
+	if err != nil || !cancelled.CancelRequested {
+		t.Fatalf("expected cancellation requested, got %+v (%v)", cancelled, err)
+	}
+	if done := await(t, svc, op.ID); done.Status != StatusCancelled || done.Error != "cancelled on request" {
+		t.Fatalf("expected a cancelled operation, got %+v", done)
+	}
+
+	svc = newTestService(t, 10*time.Millisecond, "import", block)
+	op, _ = svc.Start(context.Background(), "import", "feed-1")
+	if done := await(t, svc, op.ID); done.Status != StatusFailed || done.Error != "timed out after 10ms" {
+		t.Fatalf("expected a timed out operation, got %+v", done)
+	}
+}
+
+func TestPruneRemovesExpiredOperations(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	svc := newTestService(t, 0, "export", func(_ context.Context, target string, _ *Tracker) (any, error) {
+		if target == "dest-2" {
+			<-release
+		}
+		return nil, nil
+	})
+	finished, _ := svc.Start(context.Background(), "export", "dest-1")
+	await(t, svc, finished.ID)
+	running, _ := svc.Start(context.Background(), "export", "dest-2")
+
+	svc.prune(time.Now().Add(2 * time.Hour))
+	if _, err := svc.Get(finished.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the finished operation pruned, got %v", err)
+	}
+	if _, err := svc.Get(running.ID); err != nil {
+		t.Fatalf("expected the running operation kept, got %v", err)
+	}
+}
+
+func TestHandlerHidesOtherTechniciansOperations(t *testing.T) {
+	svc := newTestService(t, 0, "export", func(context.Context, string, *Tracker) (any, error) { return nil, nil })
+	mine, _ := svc.Start(auth.ContextWithIdentity(context.Background(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician}), "export", "")
+	theirs, _ := svc.Start(auth.ContextWithIdentity(context.Background(), auth.Identity{Subject: "tech-2", Role: auth.RoleTechnician}), "export", "")
+	await(t, svc, mine.ID)
+	await(t, svc, theirs.ID)
+
+	router := chi.NewRouter()
+	router.Route(Path, NewHandler(svc).Routes)
+	do := func(method, target string, id auth.Identity) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), id))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	tech := auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician}
+
+	if rec := do(http.MethodGet, Path+"/"+mine.ID, tech); rec.Code != http.StatusOK {
+		t.Fatalf("expected own operation visible, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, Path+"/"+theirs.ID, tech); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected another technician's operation hidden, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, Path+"/"+mine.ID+"/cancel", tech); rec.Code != http.StatusConflict {
+		t.Fatalf("expected cancelling a finished operation to conflict, got %d", rec.Code)
+	}
+
+	var list struct{ Operations []Operation }
+	_ = json.NewDecoder(do(http.MethodGet, Path+"/?owner=tech-2", tech).Body).Decode(&list)
+	if len(list.Operations) != 1 || list.Operations[0].ID != mine.ID {
+		t.Fatalf("expected technicians to list only their own operations, got %+v", list.Operations)
+	}
+	_ = json.NewDecoder(do(http.MethodGet, Path+"/", auth.Identity{Subject: "disp", Role: auth.RoleDispatcher}).Body).Decode(&list)
+	if len(list.Operations) != 2 {
+		t.Fatalf("expected staff to list every operation, got %+v", list.Operations)
+	}
+}
@@ -0,0 +1,355 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Topic is the job queue topic operations run from.
+const Topic = "operations"
+
+// Func does the work of an operation on target, reporting progress and
+// links through t. It returns the feature's record of the outcome, which is
+// kept even when err is set. Work should stop when ctx is cancelled.
+type Func func(ctx context.Context, target string, t *Tracker) (any, error)
+
+// Service queues operations, runs them as the job queue delivers them, and
+// tracks them until they finish.
+type Service struct {
+	cfg    config.OperationConfig
+	store  Store
+	jobs   *jobqueue.Queue
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	funcs   map[string]Func // keyed by kind
+	running map[string]*running
+}
+
+// running is an operation in progress on this instance. op is the latest
+// state, saved to the store on every change.
+type running struct {
+	op     Operation
+	cancel context.CancelFunc
+}
+
+// job is the payload queued for an operation.
+type job struct {
+	OperationID string `json:"operationId"`
+}
+
+// NewService wires an operation service and subscribes it to Topic on jobs.
+func NewService(cfg config.OperationConfig, store Store, jobs *jobqueue.Queue, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Service{
+		cfg:     cfg,
+		store:   store,
+		jobs:    jobs,
+		logger:  logger,
+		now:     time.Now,
+		funcs:   make(map[string]Func),
+		running: make(map[string]*running),
+	}
+	jobs.Subscribe(jobqueue.Subscription{Topic: Topic, Handler: s.handle, DeadLetter: s.deadLetter, Concurrency: cfg.Concurrency})
+	return s
+}
+
+// Register sets the work done by operations of kind. Features register
+// their kinds when wired, before the queue runs.
+func (s *Service) Register(kind string, fn Func) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.funcs[kind] = fn
+}
+
+// Start records a pending operation of kind on target and queues it. The
+// operation is owned by the subject authenticated on ctx.
+func (s *Service) Start(ctx context.Context, kind, target string) (Operation, error) {
+	s.mu.Lock()
+	_, ok := s.funcs[kind]
+	s.mu.Unlock()
+	if !ok {
+		return Operation{}, fmt.Errorf("no operation kind %q registered", kind)
+	}
+	now := s.now().UTC()
+	op := Operation{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		Target:    target,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if id, ok := auth.FromContext(ctx); ok {
+		op.Owner = id.Subject
+	}
+	if err := s.store.SaveOperation(op); err != nil {
+		return Operation{}, err
+	}
+	if _, err := s.jobs.Enqueue(ctx, Topic, job{OperationID: op.ID}, 0); err != nil {
+		op.Status = StatusFailed
+		op.Error = "could not be queued"
+		op.FinishedAt = &now
+		if serr := s.store.SaveOperation(op); serr != nil {
+			middleware.LoggerFrom(ctx).Error("save unqueued operation", slog.String("operation", op.ID), slog.Any("error", serr))
+		}
+		return Operation{}, err
+	}
+	return op, nil
+}
+
+// handle runs a queued operation. Operations that have finished, for
+// instance when a job is delivered twice, are left alone. Only failing to
+// start has the job delivered again; work that fails is the operation's
+// outcome.
+func (s *Service) handle(ctx context.Context, j jobqueue.Job) error {
+	var payload job
+	if err := j.Decode(&payload); err != nil {
+		s.logger.Error("decode queued operation", slog.String("job", j.ID), slog.Any("error", err))
+		return nil
+	}
+	s.mu.Lock()
+	op, err := s.store.GetOperation(payload.OperationID)
+	if errors.Is(err, ErrNotFound) || (err == nil && op.Done()) {
+		s.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	logger := s.logger.With(slog.String("operation", op.ID), slog.String("kind", op.Kind))
+	fn, ok := s.funcs[op.Kind]
+	if !ok || op.CancelRequested {
+		s.mu.Unlock()
+		reason := "cancelled on request"
+		if !ok {
+			reason = fmt.Sprintf("no operation kind %q registered", op.Kind)
+		}
+		s.end(op, StatusCancelled, reason, logger)
+		return nil
+	}
+
+	var opCtx context.Context
+	var cancel context.CancelFunc
+	if s.cfg.Timeout > 0 {
+		opCtx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+	} else {
+		opCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	opCtx = middleware.ContextWithLogger(opCtx, logger)
+	op.Status = StatusRunning
+	op.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveOperation(op); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.running[op.ID] = &running{op: op, cancel: cancel}
+	s.mu.Unlock()
+
+	result, err := fn(opCtx, op.Target, &Tracker{service: s, id: op.ID})
+	s.finish(op.ID, result, err, opCtx.Err(), logger)
+	return nil
+}
+
+// deadLetter fails an operation the queue could not start.
+func (s *Service) deadLetter(_ context.Context, j jobqueue.Job, err error) {
+	var payload job
+	if derr := j.Decode(&payload); derr != nil {
+		return
+	}
+	op, gerr := s.store.GetOperation(payload.OperationID)
+	if gerr != nil || op.Done() {
+		return
+	}
+	s.end(op, StatusFailed, "could not be started: "+err.Error(), s.logger.With(slog.String("operation", op.ID), slog.String("kind", op.Kind)))
+}
+
+// end finishes an operation that never ran.
+func (s *Service) end(op Operation, status, reason string, logger *slog.Logger) {
+	now := s.now().UTC()
+	op.Status = status
+	op.Error = reason
+	op.UpdatedAt = now
+	op.FinishedAt = &now
+	if err := s.store.SaveOperation(op); err != nil {
+		logger.Error("save finished operation", slog.Any("error", err))
+	}
+	logger.Warn("operation ended", slog.String("status", op.Status), slog.String("error", op.Error))
+}
+
+// finish records the outcome of an operation. Work that failed after its
+// context ended was cancelled or timed out; work that succeeded regardless
+// is kept as a success.
+func (s *Service) finish(id string, result any, err, ctxErr error, logger *slog.Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.running[id]
+	if !ok {
+		return
+	}
+	delete(s.running, id)
+
+	now := s.now().UTC()
+	op := r.op
+	op.Result = result
+	op.UpdatedAt = now
+	op.FinishedAt = &now
+	switch {
+	case err == nil:
+		op.Status = StatusSucceeded
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		op.Status = StatusFailed
+		op.Error = fmt.Sprintf("timed out after %s", s.cfg.Timeout)
+	case ctxErr != nil && op.CancelRequested:
+		op.Status = StatusCancelled
+		op.Error = "cancelled on request"
+	case ctxErr != nil:
+		op.Status = StatusCancelled
+		op.Error = "cancelled by server shutdown"
+	default:
+		op.Status = StatusFailed
+		op.Error = err.Error()
+	}
+	if err := s.store.SaveOperation(op); err != nil {
+		logger.Error("save finished operation", slog.Any("error", err))
+	}
+	if op.Status == StatusSucceeded {
+		logger.Info("operation succeeded")
+	} else {
+		logger.Warn("operation ended", slog.String("status", op.Status), slog.String("error", op.Error))
+	}
+}
+
+// update applies change to an operation running here and saves it.
+// Finished operations are left alone. A cancellation requested through
+// another instance is noticed here.
+func (s *Service) update(id string, change func(*Operation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.running[id]
+	if !ok {
+		return
+	}
+	if cur, err := s.store.GetOperation(id); err == nil && cur.CancelRequested && !r.op.CancelRequested {
+		r.op.CancelRequested = true
+		r.cancel()
+	}
+	change(&r.op)
+	r.op.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveOperation(r.op); err != nil {
+		s.logger.Error("save operation", slog.String("operation", id), slog.Any("error", err))
+	}
+}
+
+// Get returns an operation.
+func (s *Service) Get(id string) (Operation, error) {
+	return s.store.GetOperation(id)
+}
+
+// List returns operations, newest first, filtered by owner and kind when
+// set.
+func (s *Service) List(owner, kind string, limit int) ([]Operation, error) {
+	return s.store.ListOperations(owner, kind, limit)
+}
+
+// Cancel asks an operation to stop. A pending operation is cancelled when
+// it is delivered; a running one stays running until its work notices,
+// then ends as cancelled. Cancelling it again is harmless.
+func (s *Service) Cancel(id string) (Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.running[id]; ok {
+		if !r.op.CancelRequested {
+			r.op.CancelRequested = true
+			r.op.UpdatedAt = s.now().UTC()
+			if err := s.store.SaveOperation(r.op); err != nil {
+				return Operation{}, err
+			}
+			r.cancel()
+		}
+		return r.op, nil
+	}
+	op, err := s.store.GetOperation(id)
+	if err != nil {
+		return Operation{}, err
+	}
+	if op.Done() {
+		return op, ErrFinished
+	}
+	if !op.CancelRequested {
+		op.CancelRequested = true
+		op.UpdatedAt = s.now().UTC()
+		if err := s.store.SaveOperation(op); err != nil {
+			return Operation{}, err
+		}
+	}
+	return op, nil
+}
+
+// Run prunes operations that finished more than Retention ago, every
+// PruneInterval, until ctx is cancelled. Operations themselves run as the
+// job queue delivers them, and end with it.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune(s.now())
+		}
+	}
+}
+
+func (s *Service) prune(now time.Time) {
+	n, err := s.store.PruneOperations(now.Add(-s.cfg.Retention))
+	if err != nil {
+		s.logger.Error("prune operations", slog.Any("error", err))
+	}
+	if n > 0 {
+		s.logger.Info("pruned finished operations", slog.Int("count", n))
+	}
+}
+
+// Tracker reports an operation's progress while it runs.
+type Tracker struct {
+	service *Service
+	id      string
+}
+
+// ID returns the operation's ID.
+func (t *Tracker) ID() string {
+	return t.id
+}
+
+// Progress records that completed of total units of work are done.
+func (t *Tracker) Progress(completed, total int) {
+	t.service.update(t.id, func(op *Operation) {
+		op.Progress = Progress{Completed: completed, Total: total}
+	})
+}
+
+// Link adds a link to a resource the operation produced.
+func (t *Tracker) Link(rel, href string) {
+	t.service.update(t.id, func(op *Operation) {
+		op.Links = append(op.Links, Link{Rel: rel, Href: href})
+	})
+}
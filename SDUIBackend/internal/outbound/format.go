@@ -0,0 +1,239 @@
+package outbound
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// record is one output row keyed by field source (e.g. "job.customerName").
+type record map[string]any
+
+var jobSources = []string{
+	"job.id", "job.technicianId", "job.customerName", "job.address",
+	"job.scheduledDate", "job.status", "job.receivedAt",
+}
+
+var treatmentSources = []string{
+	"treatment.id", "treatment.chemicalId", "treatment.lotNumber", "treatment.mixId", "treatment.equipmentId", "treatment.technicianId", "treatment.applicatorName",
+	"treatment.applicationDate", "treatment.applicationMethod", "treatment.targetPests",
+	"treatment.quantityUsed", "treatment.dosageRate", "treatment.dilutionRatio", "treatment.notes",
+}
+
+// datasetSources lists the field sources each dataset can map from.
+// Treatment rows are joined with their job so service records carry both.
+var datasetSources = map[string]map[string]bool{
+	DatasetJobs:       toSet(jobSources),
+	DatasetTreatments: toSet(append(append([]string{}, treatmentSources...), jobSources...)),
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func jobRecord(j models.JobUpload) record {
+	return record{
+		"job.id":            j.ID,
+		"job.technicianId":  j.TechnicianID,
+		"job.customerName":  j.CustomerName,
+		"job.address":       j.Address,
+		"job.scheduledDate": j.ScheduledDate,
+		"job.status":        j.Status,
+		"job.receivedAt":    j.ReceivedAt,
+	}
+}
+
+// loadRecords builds the rows of p's dataset from the records of p's tenant
+// the server stored after since, leaving out deleted ones.
+func loadRecords(repo repository.SyncRepository, p Partner, since time.Time) ([]record, error) {
+	all, err := repo.ListJobUpdatesSince(since)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []models.JobUpload
+	for _, j := range all {
+		if p.owns(j.TenantID) {
+			jobs = append(jobs, j)
+		}
+	}
+	if p.File.Dataset == DatasetJobs {
+		out := make([]record, 0, len(jobs))
+		for _, j := range jobs {
+			out = append(out, jobRecord(j))
+		}
+		return out, nil
+	}
+
+	byID := make(map[string]models.JobUpload, len(jobs))
+	for _, j := range jobs {
+		byID[j.ID] = j
+	}
+	updated, err := repo.ListTreatmentUpdatesSince(since)
+	if err != nil {
+		return nil, err
+	}
+	var treatments []models.ChemicalTreatmentUpload
+	for _, t := range updated {
+		if !p.owns(t.TenantID) {
+			continue
+		}
+		// Treatments recorded on a job stored before the window still
+		// carry its details.
+		if _, ok := byID[t.JobID]; !ok {
+			j, err := repo.GetJobUpload(t.JobID)
+			if err != nil && !errors.Is(err, repository.ErrJobNotFound) {
+				return nil, err
+			}
+			byID[t.JobID] = j
+		}
+		treatments = append(treatments, t)
+	}
+	out := make([]record, 0, len(treatments))
+	for _, t := range treatments {
+		rec := jobRecord(byID[t.JobID])
+		rec["job.id"] = t.JobID
+		rec["treatment.id"] = t.ID
+		rec["treatment.chemicalId"] = t.ChemicalID
+		rec["treatment.lotNumber"] = t.LotNumber
+		rec["treatment.mixId"] = t.MixID
+		rec["treatment.equipmentId"] = t.EquipmentID
+		rec["treatment.technicianId"] = t.TechnicianID
+		rec["treatment.applicatorName"] = t.ApplicatorName
+		rec["treatment.applicationDate"] = t.ApplicationDate
+		rec["treatment.applicationMethod"] = t.ApplicationMethod
+		rec["treatment.targetPests"] = t.TargetPests
+		rec["treatment.quantityUsed"] = t.QuantityUsed
+		rec["treatment.dosageRate"] = t.DosageRate
+		rec["treatment.dilutionRatio"] = t.DilutionRatio
+		rec["treatment.notes"] = t.Notes
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// templateData is passed to the fileName, header, and trailer templates.
+type templateData struct {
+	Partner string
+	Date    time.Time
+	Count   int
+}
+
+// render produces the partner file and its name.
+func render(p Partner, records []record, now time.Time) (name string, body []byte, err error) {
+	spec := p.File
+	data := templateData{Partner: p.ID, Date: now, Count: len(records)}
+	eol := spec.LineEnding
+	if eol == "" {
+		eol = "\r\n" // most EDI consumers expect CRLF
+	}
+
+	if name, err = execute("fileName", spec.FileName, data); err != nil {
+		return "", nil, err
+	}
+
+	var buf bytes.Buffer
+	if spec.Header != "" {
+		line, err := execute("header", spec.Header, data)
+		if err != nil {
+			return "", nil, err
+		}
+		buf.WriteString(line + eol)
+	}
+	for _, rec := range records {
+		cols := make([]string, len(spec.Fields))
+		for i, f := range spec.Fields {
+			cols[i] = formatField(f, rec[f.Source], spec)
+		}
+		sep := ""
+		if spec.Layout == LayoutDelimited {
+			sep = spec.Delimiter
+		}
+		buf.WriteString(strings.Join(cols, sep) + eol)
+	}
+	if spec.Trailer != "" {
+		line, err := execute("trailer", spec.Trailer, data)
+		if err != nil {
+			return "", nil, err
+		}
+		buf.WriteString(line + eol)
+	}
+	return name, buf.Bytes(), nil
+}
+
+func execute(name, text string, data templateData) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// formatField converts a source value to text and applies width and padding.
+func formatField(f FieldMapping, value any, spec FileSpec) string {
+	text := ""
+	switch v := value.(type) {
+	case string:
+		text = v
+	case time.Time:
+		if !v.IsZero() {
+			format := f.Format
+			if format == "" {
+				format = "20060102"
+			}
+			text = v.UTC().Format(format)
+		}
+	case float64:
+		places := -1
+		if f.Format != "" {
+			if n, err := strconv.Atoi(f.Format); err == nil {
+				places = n
+			}
+		}
+		text = strconv.FormatFloat(v, 'f', places, 64)
+	}
+	if text == "" {
+		text = f.Default
+	}
+
+	if spec.Layout == LayoutDelimited {
+		// Delimited partners rarely support quoting; blank out separators and
+		// line breaks rather than corrupt the row.
+		return strings.NewReplacer("\r", " ", "\n", " ", spec.Delimiter, " ").Replace(text)
+	}
+	return fit(text, f)
+}
+
+// fit truncates or pads text to the field width.
+func fit(text string, f FieldMapping) string {
+	text = strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+	if n := utf8.RuneCountInString(text); n > f.Width {
+		return string([]rune(text)[:f.Width])
+	} else if n == f.Width {
+		return text
+	}
+	pad := f.Pad
+	if pad == "" {
+		pad = " "
+	}
+	fill := strings.Repeat(pad, f.Width-utf8.RuneCountInString(text))
+	if f.Align == "right" {
+		return fill + text
+	}
+	return text + fill
+}
@@ -0,0 +1,197 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/operation"
+)
+
+// Operation kinds for manual runs and redeliveries.
+const (
+	OperationKind           = "partner-file"
+	RedeliveryOperationKind = "partner-redelivery"
+)
+
+// Handler exposes admin endpoints for outbound partner files.
+type Handler struct {
+	service *Service
+	ops     *operation.Service
+}
+
+// NewHandler creates an outbound handler. Manual runs and redeliveries run
+// as operations on ops.
+func NewHandler(service *Service, ops *operation.Service) *Handler {
+	h := &Handler{service: service, ops: ops}
+	ops.Register(OperationKind, h.deliver(service.RunNow))
+	ops.Register(RedeliveryOperationKind, h.deliver(service.Redeliver))
+	return h
+}
+
+// Routes mounts the outbound endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/partners", h.ListPartners)
+	r.Post("/partners", h.CreatePartner)
+	r.Get("/partners/{partnerId}", h.GetPartner)
+	r.Put("/partners/{partnerId}", h.UpdatePartner)
+	r.Delete("/partners/{partnerId}", h.DeletePartner)
+	r.Post("/partners/{partnerId}/run", h.RunNow)
+	r.Get("/partners/{partnerId}/runs", h.ListRuns)
+	r.Get("/runs/{runId}/file", h.DownloadArchive)
+	r.Post("/runs/{runId}/redeliver", h.Redeliver)
+}
+
+// CreatePartner registers a partner file definition.
+func (h *Handler) CreatePartner(w http.ResponseWriter, r *http.Request) {
+	var payload Partner
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	p, err := h.service.CreatePartner(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create partner", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, p)
+}
+
+// UpdatePartner replaces a partner file definition.
+func (h *Handler) UpdatePartner(w http.ResponseWriter, r *http.Request) {
+	var payload Partner
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	p, err := h.service.UpdatePartner(chi.URLParam(r, "partnerId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update partner", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, p)
+}
+
+// GetPartner returns a partner definition.
+func (h *Handler) GetPartner(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.Partner(chi.URLParam(r, "partnerId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch partner", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, p)
+}
+
+// ListPartners returns every partner definition.
+func (h *Handler) ListPartners(w http.ResponseWriter, r *http.Request) {
+	partners, err := h.service.Partners()
+	if err != nil {
+		h.fail(w, r, "failed to list partners", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"partners": partners})
+}
+
+// DeletePartner removes a partner definition.
+func (h *Handler) DeletePartner(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePartner(chi.URLParam(r, "partnerId")); err != nil {
+		h.fail(w, r, "failed to delete partner", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunNow starts generating and delivering a partner file outside the
+// schedule, and answers with the operation doing it.
+func (h *Handler) RunNow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "partnerId")
+	if _, err := h.service.Partner(id); err != nil {
+		h.fail(w, r, "failed to generate partner file", err)
+		return
+	}
+	h.start(w, r, OperationKind, id, "failed to generate partner file")
+}
+
+// ListRuns returns archived runs for a partner, newest first.
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+	runs, err := h.service.Runs(chi.URLParam(r, "partnerId"), limit)
+	if err != nil {
+		h.fail(w, r, "failed to list runs", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"runs": runs})
+}
+
+// DownloadArchive streams the archived file for a run.
+func (h *Handler) DownloadArchive(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.ArchivedRun(chi.URLParam(r, "runId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch archived file", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": run.FileName}))
+	_, _ = w.Write(run.Content)
+}
+
+// Redeliver starts re-sending an archived file, and answers with the
+// operation doing it.
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "runId")
+	if _, err := h.service.ArchivedRun(id); err != nil {
+		h.fail(w, r, "failed to redeliver file", err)
+		return
+	}
+	h.start(w, r, RedeliveryOperationKind, id, "failed to redeliver file")
+}
+
+// start starts an operation of kind on target and answers with it.
+func (h *Handler) start(w http.ResponseWriter, r *http.Request, kind, target, title string) {
+	op, err := h.ops.Start(r.Context(), kind, target)
+	if err != nil {
+		h.fail(w, r, title, err)
+		return
+	}
+	operation.Accepted(w, op)
+}
+
+// deliver returns the operation work that sends a file with send. Its
+// result is the run, and it links the run's archived file.
+func (h *Handler) deliver(send func(ctx context.Context, id string) (Run, error)) operation.Func {
+	return func(ctx context.Context, id string, t *operation.Tracker) (any, error) {
+		run, err := send(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		t.Link("file", "/v1/admin/integrations/outbound/runs/"+run.ID+"/file")
+		if run.Status == StatusFailed {
+			return run, errors.New(run.Error)
+		}
+		return run, nil
+	}
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidPartner):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,197 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func fixedWidthPartner() Partner {
+	return Partner{
+		Name:    "Franchisor",
+		Enabled: true,
+		HourUTC: 4,
+		File: FileSpec{
+			Dataset:    DatasetTreatments,
+			Layout:     LayoutFixed,
+			LineEnding: "\n",
+			FileName:   `SVC_{{.Date.Format "20060102"}}.txt`,
+			Header:     `HDR{{.Date.Format "20060102"}}`,
+			Trailer:    `TRL{{printf "%06d" .Count}}`,
+			Fields: []FieldMapping{
+				{Name: "job", Source: "job.id", Width: 6},
+				{Name: "customer", Source: "job.customerName", Width: 8},
+				{Name: "qty", Source: "treatment.quantityUsed", Width: 7, Align: "right", Pad: "0", Format: "2"},
+				{Name: "date", Source: "treatment.applicationDate", Width: 8},
+				{Name: "method", Source: "treatment.applicationMethod", Width: 4, Default: "NA"},
+			},
+		},
+		Delivery: Delivery{Method: MethodDirectory, RemoteDir: "inbox"},
+	}
+}
+
+type flakyTransport struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyTransport) Deliver(context.Context, Partner, string, []byte) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+func TestValidateRejectsUnknownSources(t *testing.T) {
+	p := fixedWidthPartner()
+	p.File.Fields = append(p.File.Fields, FieldMapping{Name: "x", Source: "chemical.name", Width: 3})
+	if err := p.Validate(); !errors.Is(err, ErrInvalidPartner) {
+		t.Fatalf("expected invalid partner, got %v", err)
+	}
+	if err := fixedWidthPartner().Validate(); err != nil {
+		t.Fatalf("expected valid partner, got %v", err)
+	}
+}
+
+func TestRenderFixedWidth(t *testing.T) {
+	p := fixedWidthPartner()
+	records := []record{{
+		"job.id":                      "J1",
+		"job.customerName":            "Rosalind Franklin",
+		"treatment.quantityUsed":      12.5,
+		"treatment.applicationDate":   time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC),
+		"treatment.applicationMethod": "",
+	}}
+
+	name, body, err := render(p, records, time.Date(2026, 4, 3, 4, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "SVC_20260403.txt" {
+		t.Errorf("unexpected file name %q", name)
+	}
+	want := "HDR20260403\n" +
+		"J1    Rosalind0012.5020260402NA  \n" +
+		"TRL000001\n"
+	if string(body) != want {
+		t.Fatalf("unexpected body:\n%q\nwant\n%q", body, want)
+	}
+}
+
+func TestScheduledRunRetriesAndArchives(t *testing.T) {
+	repo := storememory.NewStore()
+	_ = repo.SaveJobUpload(models.JobUpload{ID: "J1", CustomerName: "Smith"})
+	_ = repo.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "T1", JobID: "J1", QuantityUsed: 2})
+
+	transport := &flakyTransport{failures: 2}
+	cfg := config.OutboundConfig{Enabled: true, MaxAttempts: 3}
+	svc := NewService(cfg, "", NewMemoryStore(), repo, map[string]Transport{MethodDirectory: transport}, nil)
+	svc.sleep = func(time.Duration) {}
+	now := time.Date(2026, 4, 3, 5, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	p, err := svc.CreatePartner(fixedWidthPartner())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.runDue(context.Background())
+	svc.runDue(context.Background()) // already ran today
+
+	runs, _ := svc.Runs(p.ID, 0)
+	if len(runs) != 1 {
+		t.Fatalf("expected a single scheduled run, got %d", len(runs))
+	}
+	if runs[0].Status != StatusDelivered || runs[0].Attempts != 3 || runs[0].Records != 1 {
+		t.Fatalf("unexpected run: %+v", runs[0])
+	}
+	if len(runs[0].Content) == 0 {
+		t.Fatalf("expected file to be archived")
+	}
+
+	redelivered, err := svc.Redeliver(context.Background(), runs[0].ID)
+	if err != nil || redelivered.Status != StatusDelivered || string(redelivered.Content) != string(runs[0].Content) {
+		t.Fatalf("unexpected redelivery: %+v (%v)", redelivered, err)
+	}
+}
+
+func TestRunsCarryTheTenantsRecordsSinceTheLastDelivery(t *testing.T) {
+	repo := storememory.NewStore()
+	_ = repo.SaveJobUpload(models.JobUpload{ID: "J1", TenantID: "acme", CustomerName: "Smith"})
+	_ = repo.SaveJobUpload(models.JobUpload{ID: "J2", TenantID: "bugs", CustomerName: "Jones"})
+	_ = repo.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "T1", TenantID: "acme", JobID: "J1", QuantityUsed: 2})
+
+	transport := &flakyTransport{}
+	svc := NewService(config.OutboundConfig{MaxAttempts: 1}, "acme", NewMemoryStore(), repo, map[string]Transport{MethodDirectory: transport}, nil)
+	p, err := svc.CreatePartner(fixedWidthPartner())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first, err := svc.RunNow(context.Background(), p.ID)
+	if err != nil || first.Records != 1 {
+		t.Fatalf("expected only the tenant's treatment, got %+v (%v)", first, err)
+	}
+
+	// A later treatment on the same job goes out alone, with its job.
+	time.Sleep(time.Millisecond)
+	_ = repo.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "T2", TenantID: "acme", JobID: "J1", QuantityUsed: 3})
+	second, err := svc.RunNow(context.Background(), p.ID)
+	if err != nil || second.Records != 1 {
+		t.Fatalf("expected only the new treatment, got %+v (%v)", second, err)
+	}
+	if !strings.Contains(string(second.Content), "Smith") {
+		t.Fatalf("expected the treatment joined with its earlier job, got %q", second.Content)
+	}
+
+	// Records that missed a failed delivery go out with the next one.
+	time.Sleep(time.Millisecond)
+	_ = repo.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "T3", TenantID: "acme", JobID: "J1", QuantityUsed: 4})
+	transport.failures = transport.calls + 1
+	if failed, _ := svc.RunNow(context.Background(), p.ID); failed.Status != StatusFailed {
+		t.Fatalf("expected the delivery to fail, got %+v", failed)
+	}
+	if retried, err := svc.RunNow(context.Background(), p.ID); err != nil || retried.Records != 1 {
+		t.Fatalf("expected the undelivered treatment again, got %+v (%v)", retried, err)
+	}
+}
+
+func TestDirectoryTransport(t *testing.T) {
+	root := t.TempDir()
+	p := fixedWidthPartner()
+	p.ID = "partner-1"
+	p.Delivery.RemoteDir = "../../escape"
+
+	if err := (DirectoryTransport{Root: root}).Deliver(context.Background(), p, "out.txt", []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "partner-1", "escape", "out.txt"))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("expected file inside the partner directory, got %q (%v)", data, err)
+	}
+}
+
+func TestValidateRequiresSFTPHostKey(t *testing.T) {
+	p := fixedWidthPartner()
+	p.Delivery = Delivery{Method: MethodSFTP, Host: "sftp.example.com", Username: "pestgenie", CredentialsSecret: "PARTNER_SFTP"}
+	if err := p.Validate(); !errors.Is(err, ErrInvalidPartner) {
+		t.Fatalf("expected a missing host key rejected, got %v", err)
+	}
+	p.Delivery.HostKey = "ssh-ed25519 not-base64"
+	if err := p.Validate(); !errors.Is(err, ErrInvalidPartner) {
+		t.Fatalf("expected an unparsable host key rejected, got %v", err)
+	}
+	p.Delivery.HostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected valid partner, got %v", err)
+	}
+}
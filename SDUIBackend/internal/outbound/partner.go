@@ -0,0 +1,291 @@
+package outbound
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/sftp"
+)
+
+// File layouts.
+const (
+	LayoutFixed     = "fixed"
+	LayoutDelimited = "delimited"
+)
+
+// Datasets a partner file can be built from.
+const (
+	DatasetJobs       = "jobs"
+	DatasetTreatments = "treatments"
+)
+
+// Delivery methods.
+const (
+	MethodSFTP      = "sftp"
+	MethodDirectory = "directory"
+)
+
+// Run statuses.
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrNotFound is returned when a partner or run does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidPartner wraps partner validation failures.
+	ErrInvalidPartner = errors.New("invalid partner")
+)
+
+// Partner describes a franchisor or integrator that receives a nightly file.
+type Partner struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// TenantID is the tenant whose records the partner receives, set from
+	// the service that created it; empty when tenancy is disabled.
+	TenantID  string    `json:"tenantId,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	HourUTC   int       `json:"hourUtc"`
+	File      FileSpec  `json:"file"`
+	Delivery  Delivery  `json:"delivery"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// FileSpec is the per-partner format template. Header, Trailer, and FileName
+// are text/template strings evaluated with .Partner, .Date, and .Count.
+type FileSpec struct {
+	Dataset    string         `json:"dataset"`
+	Layout     string         `json:"layout"`
+	Delimiter  string         `json:"delimiter,omitempty"`
+	LineEnding string         `json:"lineEnding,omitempty"`
+	FileName   string         `json:"fileName"`
+	Header     string         `json:"header,omitempty"`
+	Trailer    string         `json:"trailer,omitempty"`
+	Fields     []FieldMapping `json:"fields"`
+}
+
+// FieldMapping maps one source attribute into an output column.
+type FieldMapping struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Width   int    `json:"width,omitempty"`   // fixed layout only
+	Align   string `json:"align,omitempty"`   // left (default) or right
+	Pad     string `json:"pad,omitempty"`     // single character, default space
+	Format  string `json:"format,omitempty"`  // time layout or decimal places for numbers
+	Default string `json:"default,omitempty"` // used when the source is empty
+}
+
+// Delivery describes where the generated file goes. For SFTP, the password or
+// private key is read from CredentialsSecret via the secret provider, and
+// HostKey is the server's public key in authorized_keys form; connections to
+// a server presenting any other key are refused.
+type Delivery struct {
+	Method            string `json:"method"`
+	Host              string `json:"host,omitempty"`
+	Port              int    `json:"port,omitempty"`
+	Username          string `json:"username,omitempty"`
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	HostKey           string `json:"hostKey,omitempty"`
+	RemoteDir         string `json:"remoteDir,omitempty"`
+}
+
+// owns reports whether a record of tenantID belongs in the partner's files.
+func (p Partner) owns(tenantID string) bool {
+	return p.TenantID == "" || tenantID == p.TenantID
+}
+
+// Validate checks the partner definition, including that every template
+// parses and every field source is known.
+func (p Partner) Validate() error {
+	var problems []string
+	if p.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if p.HourUTC < 0 || p.HourUTC > 23 {
+		problems = append(problems, "hourUtc must be between 0 and 23")
+	}
+
+	spec := p.File
+	sources, ok := datasetSources[spec.Dataset]
+	if !ok {
+		problems = append(problems, "file.dataset must be jobs or treatments")
+	}
+	switch spec.Layout {
+	case LayoutFixed:
+	case LayoutDelimited:
+		if len([]rune(spec.Delimiter)) != 1 {
+			problems = append(problems, "file.delimiter must be a single character")
+		}
+	default:
+		problems = append(problems, "file.layout must be fixed or delimited")
+	}
+	if spec.FileName == "" {
+		problems = append(problems, "file.fileName is required")
+	}
+	for name, text := range map[string]string{"fileName": spec.FileName, "header": spec.Header, "trailer": spec.Trailer} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			problems = append(problems, fmt.Sprintf("file.%s: %v", name, err))
+		}
+	}
+	if len(spec.Fields) == 0 {
+		problems = append(problems, "file.fields must not be empty")
+	}
+	for i, f := range spec.Fields {
+		if ok && !sources[f.Source] {
+			problems = append(problems, fmt.Sprintf("file.fields[%d].source %q is not available for %s", i, f.Source, spec.Dataset))
+		}
+		if spec.Layout == LayoutFixed && f.Width <= 0 {
+			problems = append(problems, fmt.Sprintf("file.fields[%d].width must be positive for fixed layout", i))
+		}
+		if f.Pad != "" && len([]rune(f.Pad)) != 1 {
+			problems = append(problems, fmt.Sprintf("file.fields[%d].pad must be a single character", i))
+		}
+	}
+
+	switch p.Delivery.Method {
+	case MethodDirectory:
+	case MethodSFTP:
+		if p.Delivery.Host == "" || p.Delivery.Username == "" || p.Delivery.CredentialsSecret == "" {
+			problems = append(problems, "sftp delivery requires host, username, and credentialsSecret")
+		}
+		if p.Delivery.HostKey == "" {
+			problems = append(problems, "sftp delivery requires hostKey")
+		} else if _, err := sftp.ParseHostKey(p.Delivery.HostKey); err != nil {
+			problems = append(problems, "delivery.hostKey must be a public key in authorized_keys form")
+		}
+	default:
+		problems = append(problems, "delivery.method must be sftp or directory")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidPartner, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Run records one generation and delivery attempt sequence. Content is the
+// archived file and is only served through the archive endpoint.
+type Run struct {
+	ID          string     `json:"id"`
+	PartnerID   string     `json:"partnerId"`
+	Trigger     string     `json:"trigger"` // schedule, manual, redeliver
+	FileName    string     `json:"fileName"`
+	Records     int        `json:"records"`
+	Bytes       int        `json:"bytes"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"startedAt"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	Content     []byte     `json:"-"`
+}
+
+// Store persists partners and archived runs.
+type Store interface {
+	SavePartner(p Partner) error
+	GetPartner(id string) (Partner, error)
+	ListPartners() ([]Partner, error)
+	DeletePartner(id string) error
+	SaveRun(r Run) error
+	GetRun(id string) (Run, error)
+	ListRuns(partnerID string, limit int) ([]Run, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	partners map[string]Partner
+	runs     map[string]Run
+	order    []string // run IDs, oldest first
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{partners: make(map[string]Partner), runs: make(map[string]Run)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SavePartner(p Partner) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.partners[p.ID] = p
+	return nil
+}
+
+func (m *MemoryStore) GetPartner(id string) (Partner, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.partners[id]
+	if !ok {
+		return Partner{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (m *MemoryStore) ListPartners() ([]Partner, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Partner, 0, len(m.partners))
+	for _, p := range m.partners {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (m *MemoryStore) DeletePartner(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.partners[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.partners, id)
+	return nil
+}
+
+func (m *MemoryStore) SaveRun(r Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.runs[r.ID]; !exists {
+		m.order = append(m.order, r.ID)
+	}
+	m.runs[r.ID] = r
+	return nil
+}
+
+func (m *MemoryStore) GetRun(id string) (Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.runs[id]
+	if !ok {
+		return Run{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// ListRuns returns the partner's runs, newest first.
+func (m *MemoryStore) ListRuns(partnerID string, limit int) ([]Run, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Run
+	for i := len(m.order) - 1; i >= 0; i-- {
+		r := m.runs[m.order[i]]
+		if r.PartnerID != partnerID {
+			continue
+		}
+		out = append(out, r)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,282 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Service generates partner files on a schedule and delivers them.
+type Service struct {
+	cfg        config.OutboundConfig
+	tenantID   string
+	store      Store
+	sync       repository.SyncRepository
+	transports map[string]Transport
+	logger     *slog.Logger
+	now        func() time.Time
+	sleep      func(time.Duration)
+}
+
+// NewService wires an outbound integration service for the tenant tenantID,
+// empty when tenancy is disabled. transports is keyed by delivery method.
+func NewService(cfg config.OutboundConfig, tenantID string, store Store, sync repository.SyncRepository, transports map[string]Transport, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		cfg:        cfg,
+		tenantID:   tenantID,
+		store:      store,
+		sync:       sync,
+		transports: transports,
+		logger:     logger,
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// CreatePartner validates and stores a new partner.
+func (s *Service) CreatePartner(p Partner) (Partner, error) {
+	if err := p.Validate(); err != nil {
+		return Partner{}, err
+	}
+	now := s.now().UTC()
+	p.ID = uuid.NewString()
+	p.TenantID = s.tenantID
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	return p, s.store.SavePartner(p)
+}
+
+// UpdatePartner replaces a partner definition.
+func (s *Service) UpdatePartner(id string, p Partner) (Partner, error) {
+	existing, err := s.store.GetPartner(id)
+	if err != nil {
+		return Partner{}, err
+	}
+	if err := p.Validate(); err != nil {
+		return Partner{}, err
+	}
+	p.ID = id
+	p.TenantID = existing.TenantID
+	p.CreatedAt = existing.CreatedAt
+	p.UpdatedAt = s.now().UTC()
+	return p, s.store.SavePartner(p)
+}
+
+// Partner returns a single partner.
+func (s *Service) Partner(id string) (Partner, error) {
+	return s.store.GetPartner(id)
+}
+
+// Partners lists every partner.
+func (s *Service) Partners() ([]Partner, error) {
+	return s.store.ListPartners()
+}
+
+// DeletePartner removes a partner. Archived runs are kept for audit.
+func (s *Service) DeletePartner(id string) error {
+	return s.store.DeletePartner(id)
+}
+
+// Runs lists a partner's archived runs, newest first.
+func (s *Service) Runs(partnerID string, limit int) ([]Run, error) {
+	if _, err := s.store.GetPartner(partnerID); err != nil {
+		return nil, err
+	}
+	return s.store.ListRuns(partnerID, limit)
+}
+
+// ArchivedRun returns a run including its archived file content.
+func (s *Service) ArchivedRun(id string) (Run, error) {
+	return s.store.GetRun(id)
+}
+
+// RunNow generates and delivers a partner's file immediately.
+func (s *Service) RunNow(ctx context.Context, partnerID string) (Run, error) {
+	p, err := s.store.GetPartner(partnerID)
+	if err != nil {
+		return Run{}, err
+	}
+	return s.generate(ctx, p, "manual")
+}
+
+// Redeliver re-sends an archived file without regenerating it, so the partner
+// receives exactly the bytes of the original run.
+func (s *Service) Redeliver(ctx context.Context, runID string) (Run, error) {
+	original, err := s.store.GetRun(runID)
+	if err != nil {
+		return Run{}, err
+	}
+	p, err := s.store.GetPartner(original.PartnerID)
+	if err != nil {
+		return Run{}, err
+	}
+	run := Run{
+		ID:        uuid.NewString(),
+		PartnerID: p.ID,
+		Trigger:   "redeliver",
+		FileName:  original.FileName,
+		Records:   original.Records,
+		Bytes:     original.Bytes,
+		StartedAt: s.now().UTC(),
+		Content:   original.Content,
+	}
+	s.deliver(ctx, p, &run)
+	return run, s.store.SaveRun(run)
+}
+
+// Run checks for due partners every CheckInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	interval := s.cfg.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *Service) runDue(ctx context.Context) {
+	partners, err := s.store.ListPartners()
+	if err != nil {
+		s.logger.Error("list outbound partners", slog.Any("error", err))
+		return
+	}
+	now := s.now().UTC()
+	for _, p := range partners {
+		due, err := s.due(p, now)
+		if err != nil {
+			s.logger.Error("check outbound schedule", slog.String("partner", p.ID), slog.Any("error", err))
+			continue
+		}
+		if due {
+			if _, err := s.generate(ctx, p, "schedule"); err != nil {
+				s.logger.Error("generate outbound file", slog.String("partner", p.ID), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// due reports whether today's scheduled file has not been produced yet. A
+// failed scheduled run is not retried automatically: it already exhausted its
+// delivery attempts and is redelivered from the archive once fixed.
+func (s *Service) due(p Partner, now time.Time) (bool, error) {
+	if !p.Enabled || now.Hour() < p.HourUTC {
+		return false, nil
+	}
+	runs, err := s.store.ListRuns(p.ID, 0)
+	if err != nil {
+		return false, err
+	}
+	today := now.Format("2006-01-02")
+	for _, r := range runs {
+		if r.Trigger == "schedule" && r.StartedAt.UTC().Format("2006-01-02") == today {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// generate renders the partner file, archives it, and attempts delivery.
+func (s *Service) generate(ctx context.Context, p Partner, trigger string) (Run, error) {
+	started := s.now().UTC()
+	since, err := s.windowStart(p, started)
+	if err != nil {
+		return Run{}, err
+	}
+	records, err := loadRecords(s.sync, p, since)
+	if err != nil {
+		return Run{}, fmt.Errorf("load records: %w", err)
+	}
+	name, body, err := render(p, records, started)
+	if err != nil {
+		return Run{}, fmt.Errorf("render file: %w", err)
+	}
+
+	run := Run{
+		ID:        uuid.NewString(),
+		PartnerID: p.ID,
+		Trigger:   trigger,
+		FileName:  name,
+		Records:   len(records),
+		Bytes:     len(body),
+		StartedAt: started,
+		Content:   body,
+	}
+	// Archive before delivering so the file survives a crash mid-delivery.
+	if err := s.store.SaveRun(run); err != nil {
+		return Run{}, err
+	}
+	s.deliver(ctx, p, &run)
+	return run, s.store.SaveRun(run)
+}
+
+// windowStart is when the records a new file for p carries begin: the start
+// of the last generated run the partner received, so records that missed a
+// failed delivery go out with the next one, or a day before now for a
+// partner that has received none.
+func (s *Service) windowStart(p Partner, now time.Time) (time.Time, error) {
+	runs, err := s.store.ListRuns(p.ID, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, r := range runs {
+		if r.Status == StatusDelivered && r.Trigger != "redeliver" {
+			return r.StartedAt, nil
+		}
+	}
+	return now.Add(-24 * time.Hour), nil
+}
+
+// deliver tries the partner's transport up to MaxAttempts times with linear
+// backoff and records the outcome on run.
+func (s *Service) deliver(ctx context.Context, p Partner, run *Run) {
+	transport, ok := s.transports[p.Delivery.Method]
+	if !ok {
+		run.Status = StatusFailed
+		run.Error = fmt.Sprintf("no transport for delivery method %q", p.Delivery.Method)
+		return
+	}
+
+	attempts := s.cfg.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		run.Attempts++
+		if lastErr = transport.Deliver(ctx, p, run.FileName, run.Content); lastErr == nil {
+			delivered := s.now().UTC()
+			run.Status = StatusDelivered
+			run.Error = ""
+			run.DeliveredAt = &delivered
+			s.logger.Info("outbound file delivered", slog.String("partner", p.ID), slog.String("file", run.FileName), slog.Int("records", run.Records))
+			return
+		}
+		if i < attempts-1 && ctx.Err() == nil {
+			s.sleep(s.cfg.RetryBackoff * time.Duration(i+1))
+		}
+	}
+	run.Status = StatusFailed
+	run.Error = lastErr.Error()
+	s.logger.Error("outbound delivery failed", slog.String("partner", p.ID), slog.String("file", run.FileName), slog.Int("attempts", run.Attempts), slog.Any("error", lastErr))
+}
@@ -0,0 +1,80 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+	"github.com/your-org/pestgenie-sdui/internal/sftp"
+)
+
+// Transport delivers a rendered file to a partner.
+type Transport interface {
+	Deliver(ctx context.Context, p Partner, name string, body []byte) error
+}
+
+// DirectoryTransport drops files under Root/<partner id>/<remote dir>. It is
+// used locally and by partners that pick files up from a shared mount.
+type DirectoryTransport struct {
+	Root string
+}
+
+// Deliver writes the file atomically so pollers never see a partial file.
+func (d DirectoryTransport) Deliver(_ context.Context, p Partner, name string, body []byte) error {
+	dir := filepath.Join(d.Root, p.ID, filepath.Clean("/"+p.Delivery.RemoteDir))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, filepath.Base(name))
+	tmp := target + ".part"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+// SFTPTransport uploads files to the partner's SFTP server, under RemoteDir
+// relative to the login directory.
+type SFTPTransport struct {
+	Secrets secret.Provider
+}
+
+// Deliver uploads to a .part name and renames it into place, so pollers never
+// see a partial file.
+func (s SFTPTransport) Deliver(ctx context.Context, p Partner, name string, body []byte) error {
+	credentials, err := s.Secrets.Get(p.Delivery.CredentialsSecret)
+	if err != nil {
+		return fmt.Errorf("resolve sftp credentials %q: %w", p.Delivery.CredentialsSecret, err)
+	}
+	c, err := sftp.Dial(ctx, sftp.Config{
+		Host:        p.Delivery.Host,
+		Port:        p.Delivery.Port,
+		Username:    p.Delivery.Username,
+		HostKey:     p.Delivery.HostKey,
+		Credentials: credentials,
+	})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	defer c.CloseWhenDone(ctx)()
+
+	dir := path.Clean(p.Delivery.RemoteDir)
+	if dir != "." {
+		if err := c.MkdirAll(dir); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+	target := path.Join(dir, path.Base(name))
+	tmp := target + ".part"
+	if err := c.WriteFile(tmp, body); err != nil {
+		return fmt.Errorf("upload %s: %w", tmp, err)
+	}
+	if err := c.Rename(tmp, target); err != nil {
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package photo
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// formOverhead is how much a multipart upload may exceed the photo size limit
+// to make room for the metadata fields and part headers.
+const formOverhead = 64 << 10
+
+// Handler exposes photo metadata, annotation, and search endpoints.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a photo handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// UploadPhoto accepts a multipart/form-data upload with the image in the
+// "photo" part and the fields photoId, jobId, treatmentId, technicianId,
+// kind (photo or signature), species (repeated or comma-separated),
+// location, severity, and notes. The image is streamed to blob storage
+// rather than buffered, so fields may come before or after it.
+func (h *Handler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.cfg.MaxBytes+formOverhead)
+	parts, err := r.MultipartReader()
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid upload", err.Error())
+		return
+	}
+
+	var (
+		photoID string
+		req     AnnotateRequest
+		blob    *Blob
+	)
+	// fail discards any stored blob; status 0 defers to h.fail.
+	fail := func(status int, title string, err error) {
+		if blob != nil {
+			h.service.discard(blob.Key)
+		}
+		if status == 0 {
+			h.fail(w, r, title, err)
+			return
+		}
+		respond.Error(w, status, title, err.Error())
+	}
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fail(streamStatus(err), "invalid upload", err)
+			return
+		}
+		name := part.FormName()
+		if name == "photo" {
+			if blob != nil {
+				fail(http.StatusBadRequest, "invalid upload", errors.New("only one photo part is allowed"))
+				return
+			}
+			stored, err := h.service.PutBlob(r.Context(), part.Header.Get("Content-Type"), part)
+			if err != nil {
+				fail(0, "failed to store photo", err)
+				return
+			}
+			blob = &stored
+			continue
+		}
+		raw, err := io.ReadAll(io.LimitReader(part, 4<<10))
+		if err != nil {
+			fail(streamStatus(err), "invalid upload", err)
+			return
+		}
+		value := strings.TrimSpace(string(raw))
+		switch name {
+		case "photoId":
+			photoID = value
+		case "jobId":
+			req.JobID = value
+		case "treatmentId":
+			req.TreatmentID = value
+		case "technicianId":
+			req.TechnicianID = value
+		case "kind":
+			req.Kind = value
+		case "species":
+			req.Species = append(req.Species, strings.Split(value, ",")...)
+		case "location":
+			req.Location = value
+		case "severity":
+			req.Severity = value
+		case "notes":
+			req.Notes = value
+		}
+	}
+	if blob == nil {
+		respond.Error(w, http.StatusBadRequest, "invalid upload", `missing "photo" part`)
+		return
+	}
+
+	meta, err := h.service.Attach(photoID, req, *blob)
+	if err != nil {
+		h.fail(w, r, "failed to save photo", err)
+		return
+	}
+	resp := transport.PhotoUploadResponse{Success: true, PhotoID: meta.ID}
+	if url, expires, err := h.service.URL(r.Context(), meta.ID); err != nil {
+		// The photo is saved; the device can fetch a URL later.
+		middleware.LoggerFrom(r.Context()).Warn("failed to sign photo url", slog.String("photo", meta.ID), slog.Any("error", err))
+		resp.Message = "photo saved; url unavailable, request it again later"
+	} else {
+		resp.URL, resp.ExpiresAt = url, &expires
+	}
+	respond.JSON(w, http.StatusCreated, resp)
+}
+
+// GetPhotoURL returns a fresh signed URL for a photo's image.
+func (h *Handler) GetPhotoURL(w http.ResponseWriter, r *http.Request) {
+	url, expires, err := h.service.URL(r.Context(), chi.URLParam(r, "photoId"))
+	if err != nil {
+		h.fail(w, r, "failed to sign photo url", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"url": url, "expiresAt": expires})
+}
+
+// streamStatus maps a failure reading the request body to 413 when the body
+// limit was hit and 400 otherwise.
+func streamStatus(err error) int {
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// GetVocabulary returns the terms photos can be tagged with.
+func (h *Handler) GetVocabulary(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, Terms())
+}
+
+// ListPhotos searches photos by ?jobId=, ?treatmentId=, ?species=,
+// ?location=, ?severity=, and ?minSeverity=.
+func (h *Handler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	photos, err := h.service.Search(Query{
+		JobID:       q.Get("jobId"),
+		TreatmentID: q.Get("treatmentId"),
+		Species:     q.Get("species"),
+		Location:    q.Get("location"),
+		Severity:    q.Get("severity"),
+		MinSeverity: q.Get("minSeverity"),
+	})
+	if err != nil {
+		h.fail(w, r, "failed to search photos", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"photos": photos})
+}
+
+// GetPhoto returns a photo's metadata.
+func (h *Handler) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.service.Photo(chi.URLParam(r, "photoId"))
+	if err != nil {
+		h.fail(w, r, "failed to load photo", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, meta)
+}
+
+// AnnotatePhoto replaces a photo's annotation.
+func (h *Handler) AnnotatePhoto(w http.ResponseWriter, r *http.Request) {
+	var payload AnnotateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	meta, err := h.service.Annotate(chi.URLParam(r, "photoId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to annotate photo", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, meta)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	var tooBig *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidPhoto):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, ErrTooLarge), errors.As(err, &tooBig):
+		respond.Error(w, http.StatusRequestEntityTooLarge, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,191 @@
+package photo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a photo does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidPhoto wraps photo metadata validation failures.
+	ErrInvalidPhoto = errors.New("invalid photo")
+	// ErrTooLarge is returned when an upload exceeds the configured limit.
+	ErrTooLarge = errors.New("photo too large")
+)
+
+// Annotation tags what a photo shows using the controlled vocabularies.
+type Annotation struct {
+	Species  []string `json:"species,omitempty"`
+	Location string   `json:"location,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+}
+
+// Validate checks the annotation against the vocabularies.
+func (a Annotation) Validate() error {
+	var problems []string
+	for _, s := range a.Species {
+		if !contains(vocabulary.Species, s) {
+			problems = append(problems, fmt.Sprintf("unknown species %q", s))
+		}
+	}
+	if a.Location != "" && !contains(vocabulary.Locations, a.Location) {
+		problems = append(problems, fmt.Sprintf("unknown location %q", a.Location))
+	}
+	if a.Severity != "" && !contains(vocabulary.Severities, a.Severity) {
+		problems = append(problems, fmt.Sprintf("unknown severity %q", a.Severity))
+	}
+	if len(a.Notes) > 2000 {
+		problems = append(problems, "notes must be at most 2000 characters")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidPhoto, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// normalize lowercases terms and drops duplicate species.
+func (a Annotation) normalize() Annotation {
+	seen := make(map[string]bool, len(a.Species))
+	species := make([]string, 0, len(a.Species))
+	for _, s := range a.Species {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			species = append(species, s)
+		}
+	}
+	sort.Strings(species)
+	a.Species = species
+	a.Location = strings.ToLower(strings.TrimSpace(a.Location))
+	a.Severity = strings.ToLower(strings.TrimSpace(a.Severity))
+	a.Notes = strings.TrimSpace(a.Notes)
+	return a
+}
+
+func (a Annotation) empty() bool {
+	return len(a.Species) == 0 && a.Location == "" && a.Severity == "" && a.Notes == ""
+}
+
+// KindSignature marks an image as a signature collected for a job rather
+// than a photo of the site.
+const KindSignature = "signature"
+
+// Metadata describes a job or treatment photo.
+type Metadata struct {
+	ID           string `json:"id"`
+	JobID        string `json:"jobId,omitempty"`
+	TreatmentID  string `json:"treatmentId,omitempty"`
+	TechnicianID string `json:"technicianId,omitempty"`
+	// Kind is empty for photos and KindSignature for signatures.
+	Kind       string     `json:"kind,omitempty"`
+	Annotation Annotation `json:"annotation"`
+	// Blob fields are set once the image itself has been uploaded.
+	BlobKey     string     `json:"-"`
+	ContentType string     `json:"contentType,omitempty"`
+	SizeBytes   int64      `json:"sizeBytes,omitempty"`
+	UploadedAt  *time.Time `json:"uploadedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// Query filters photos. Empty fields match everything; MinSeverity matches
+// photos at or above that severity.
+type Query struct {
+	JobID       string
+	TreatmentID string
+	Species     string
+	Location    string
+	Severity    string
+	MinSeverity string
+}
+
+// Validate checks that query terms come from the vocabularies.
+func (q Query) Validate() error {
+	a := Annotation{Location: q.Location, Severity: q.Severity}
+	if q.Species != "" {
+		a.Species = []string{q.Species}
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if q.MinSeverity != "" && !contains(vocabulary.Severities, q.MinSeverity) {
+		return fmt.Errorf("%w: unknown severity %q", ErrInvalidPhoto, q.MinSeverity)
+	}
+	return nil
+}
+
+// Matches reports whether m satisfies the query.
+func (q Query) Matches(m Metadata) bool {
+	switch {
+	case q.JobID != "" && m.JobID != q.JobID:
+		return false
+	case q.TreatmentID != "" && m.TreatmentID != q.TreatmentID:
+		return false
+	case q.Species != "" && !contains(m.Annotation.Species, q.Species):
+		return false
+	case q.Location != "" && m.Annotation.Location != q.Location:
+		return false
+	case q.Severity != "" && m.Annotation.Severity != q.Severity:
+		return false
+	case q.MinSeverity != "" && severityRank(m.Annotation.Severity) < severityRank(q.MinSeverity):
+		return false
+	}
+	return true
+}
+
+// Store persists photo metadata.
+type Store interface {
+	SaveMetadata(m Metadata) error
+	GetMetadata(id string) (Metadata, error)
+	ListMetadata(q Query) ([]Metadata, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	photos map[string]Metadata
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{photos: make(map[string]Metadata)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveMetadata(p Metadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.photos[p.ID] = p
+	return nil
+}
+
+func (m *MemoryStore) GetMetadata(id string) (Metadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.photos[id]
+	if !ok {
+		return Metadata{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (m *MemoryStore) ListMetadata(q Query) ([]Metadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Metadata
+	for _, p := range m.photos {
+		if q.Matches(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
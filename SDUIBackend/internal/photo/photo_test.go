@@ -0,0 +1,192 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+)
+
+// pngHeader is enough of a PNG for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestService(t *testing.T, maxBytes int64) *Service {
+	t.Helper()
+	cfg := config.PhotoConfig{LocalDir: t.TempDir(), MaxBytes: maxBytes, AllowedTypes: []string{"image/jpeg", "image/png"}, URLTTL: 15 * time.Minute}
+	blobs, err := storage.NewLocal(cfg.LocalDir, "http://api.test"+storage.LocalPathPrefix, []byte("key"))
+	if err != nil {
+		t.Fatalf("new local store: %v", err)
+	}
+	svc := NewService(cfg, NewMemoryStore(), blobs, nil)
+	clock := time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { clock = clock.Add(time.Minute); return clock }
+	return svc
+}
+
+func TestAnnotateNormalizesAndValidates(t *testing.T) {
+	svc := newTestService(t, 1<<10)
+	meta, err := svc.Annotate("p1", AnnotateRequest{
+		JobID:      "job-1",
+		Annotation: Annotation{Species: []string{"German_Cockroach ", "german_cockroach"}, Location: "Kitchen", Severity: "HIGH"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(meta.Annotation.Species) != 1 || meta.Annotation.Location != "kitchen" || meta.Annotation.Severity != SeverityHigh || meta.JobID != "job-1" {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	_, err = svc.Annotate("p1", AnnotateRequest{Annotation: Annotation{Species: []string{"dragon"}, Severity: "apocalyptic"}})
+	if !errors.Is(err, ErrInvalidPhoto) {
+		t.Fatalf("expected invalid photo, got %v", err)
+	}
+	if got, _ := svc.Photo("p1"); got.Annotation.Location != "kitchen" {
+		t.Fatalf("rejected annotation must not be saved, got %+v", got)
+	}
+}
+
+func TestSearchByTags(t *testing.T) {
+	svc := newTestService(t, 1<<10)
+	_, _ = svc.Annotate("p1", AnnotateRequest{JobID: "job-1", Annotation: Annotation{Species: []string{"bed_bug"}, Location: "bedroom", Severity: SeverityLow}})
+	_, _ = svc.Annotate("p2", AnnotateRequest{JobID: "job-1", Annotation: Annotation{Species: []string{"bed_bug", "flea"}, Location: "living_room", Severity: SeveritySevere}})
+	_, _ = svc.Annotate("p3", AnnotateRequest{JobID: "job-2", Annotation: Annotation{Location: "bedroom"}})
+
+	cases := []struct {
+		q    Query
+		want []string
+	}{
+		{Query{Species: "bed_bug"}, []string{"p1", "p2"}},
+		{Query{Location: "bedroom"}, []string{"p1", "p3"}},
+		{Query{JobID: "job-1", MinSeverity: SeverityModerate}, []string{"p2"}},
+		{Query{Species: "flea", Location: "bedroom"}, nil},
+	}
+	for _, tc := range cases {
+		got, err := svc.Search(tc.q)
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tc.q, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%+v: expected %v, got %+v", tc.q, tc.want, got)
+		}
+		for i := range got {
+			if got[i].ID != tc.want[i] {
+				t.Fatalf("%+v: expected %v, got %+v", tc.q, tc.want, got)
+			}
+		}
+	}
+
+	if _, err := svc.Search(Query{MinSeverity: "extreme"}); !errors.Is(err, ErrInvalidPhoto) {
+		t.Fatalf("expected invalid query, got %v", err)
+	}
+}
+
+func uploadRequest(t *testing.T, fields map[string]string, contentType string, image []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	if image != nil {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="photo"; filename="photo"`)
+		header.Set("Content-Type", contentType)
+		part, _ := mw.CreatePart(header)
+		_, _ = part.Write(image)
+	}
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/v1/photos", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestUploadPhotoStoresBlobAndKeepsAnnotation(t *testing.T) {
+	svc := newTestService(t, 1<<10)
+	h := NewHandler(svc)
+	if _, err := svc.Annotate("p1", AnnotateRequest{Annotation: Annotation{Species: []string{"flea"}}}); err != nil {
+		t.Fatalf("annotate: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.UploadPhoto(rec, uploadRequest(t, map[string]string{"photoId": "p1", "jobId": "job-1"}, "image/png", pngHeader))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp transport.PhotoUploadResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.PhotoID != "p1" || !strings.HasPrefix(resp.URL, "http://api.test/v1/blobs/photos/") || resp.ExpiresAt == nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	meta, _ := svc.Photo("p1")
+	if meta.JobID != "job-1" || meta.SizeBytes != int64(len(pngHeader)) || meta.UploadedAt == nil || len(meta.Annotation.Species) != 1 {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	body, err := svc.Open(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if !bytes.Equal(data, pngHeader) {
+		t.Fatalf("open returned %q", data)
+	}
+
+	rec = httptest.NewRecorder()
+	h.UploadPhoto(rec, uploadRequest(t, map[string]string{"photoId": "p1", "jobId": "job-1"}, "image/png", pngHeader))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("re-upload: expected 400, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.UploadPhoto(rec, uploadRequest(t, map[string]string{"photoId": "sig", "jobId": "job-1", "kind": "Signature"}, "image/png", pngHeader))
+	if sig, _ := svc.Photo("sig"); rec.Code != http.StatusCreated || sig.Kind != KindSignature {
+		t.Fatalf("expected a signature, got %d %+v", rec.Code, sig)
+	}
+}
+
+func TestUploadPhotoRejectsBadUploads(t *testing.T) {
+	svc := newTestService(t, 64)
+	h := NewHandler(svc)
+	cases := []struct {
+		name        string
+		fields      map[string]string
+		contentType string
+		image       []byte
+		want        int
+	}{
+		{"disallowed type", map[string]string{"jobId": "j"}, "image/gif", pngHeader, http.StatusBadRequest},
+		{"not an image", map[string]string{"jobId": "j"}, "image/png", []byte("<html><body>hi</body></html>"), http.StatusBadRequest},
+		{"too large", map[string]string{"jobId": "j"}, "image/png", append(pngHeader, make([]byte, 100)...), http.StatusRequestEntityTooLarge},
+		{"no job or treatment", nil, "image/png", pngHeader, http.StatusBadRequest},
+		{"signature without job", map[string]string{"treatmentId": "t", "kind": "signature"}, "image/png", pngHeader, http.StatusBadRequest},
+		{"unknown kind", map[string]string{"jobId": "j", "kind": "selfie"}, "image/png", pngHeader, http.StatusBadRequest},
+		{"missing photo", map[string]string{"jobId": "j"}, "", nil, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		h.UploadPhoto(rec, uploadRequest(t, tc.fields, tc.contentType, tc.image))
+		if rec.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Rejected uploads must not leave blobs behind.
+	entries, _ := filepath.Glob(filepath.Join(svc.cfg.LocalDir, "photos", "*"))
+	if len(entries) != 0 {
+		t.Fatalf("expected no stored blobs, got %v", entries)
+	}
+}
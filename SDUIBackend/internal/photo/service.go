@@ -0,0 +1,245 @@
+package photo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+)
+
+// Service manages photo uploads, metadata, and annotations.
+type Service struct {
+	cfg    config.PhotoConfig
+	store  Store
+	blobs  storage.BlobStore
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService wires a photo service.
+func NewService(cfg config.PhotoConfig, store Store, blobs storage.BlobStore, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, blobs: blobs, logger: logger, now: time.Now}
+}
+
+// AnnotateRequest tags a photo. Job, treatment, and technician are recorded
+// when the photo is first seen and ignored afterwards.
+type AnnotateRequest struct {
+	JobID        string `json:"jobId,omitempty"`
+	TreatmentID  string `json:"treatmentId,omitempty"`
+	TechnicianID string `json:"technicianId,omitempty"`
+	// Kind is only read on upload; see Metadata.Kind.
+	Kind string `json:"-"`
+	Annotation
+}
+
+// Annotate replaces a photo's annotation, creating its metadata when the
+// device tags a photo before the upload has landed.
+func (s *Service) Annotate(id string, req AnnotateRequest) (Metadata, error) {
+	annotation := req.Annotation.normalize()
+	if err := annotation.Validate(); err != nil {
+		return Metadata{}, err
+	}
+	now := s.now().UTC()
+	meta, err := s.store.GetMetadata(id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		meta = Metadata{ID: id, JobID: req.JobID, TreatmentID: req.TreatmentID, TechnicianID: req.TechnicianID, CreatedAt: now}
+	case err != nil:
+		return Metadata{}, err
+	}
+	meta.Annotation = annotation
+	meta.UpdatedAt = now
+	if err := s.store.SaveMetadata(meta); err != nil {
+		return Metadata{}, err
+	}
+	return meta, nil
+}
+
+// Blob is an image written to blob storage but not yet attached to a photo.
+type Blob struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// PutBlob streams an image to blob storage, enforcing the configured content
+// types and size limit. The declared type must be allowed and the content
+// must not sniff as something other than an image.
+func (s *Service) PutBlob(ctx context.Context, contentType string, body io.Reader) (Blob, error) {
+	if !allowed(s.cfg.AllowedTypes, contentType) {
+		return Blob{}, fmt.Errorf("%w: content type %q is not allowed (allowed: %s)", ErrInvalidPhoto, contentType, strings.Join(s.cfg.AllowedTypes, ", "))
+	}
+	buffered := bufio.NewReaderSize(body, 512)
+	head, _ := buffered.Peek(512)
+	if len(head) == 0 {
+		return Blob{}, fmt.Errorf("%w: photo is empty", ErrInvalidPhoto)
+	}
+	// HEIC is not recognised by the sniffer and comes back as octet-stream.
+	if sniffed := http.DetectContentType(head); !strings.HasPrefix(sniffed, "image/") && sniffed != "application/octet-stream" {
+		return Blob{}, fmt.Errorf("%w: content looks like %s, not an image", ErrInvalidPhoto, sniffed)
+	}
+
+	blob := Blob{Key: "photos/" + uuid.NewString() + extension(contentType), ContentType: contentType}
+	n, err := s.blobs.Put(ctx, blob.Key, contentType, io.LimitReader(buffered, s.cfg.MaxBytes+1))
+	blob.Size = n
+	if err == nil && n > s.cfg.MaxBytes {
+		err = fmt.Errorf("%w: limit is %d bytes", ErrTooLarge, s.cfg.MaxBytes)
+	}
+	if err != nil {
+		s.discard(blob.Key)
+		return Blob{}, err
+	}
+	return blob, nil
+}
+
+// Attach records an uploaded blob as a photo. A photo ID the device already
+// annotated keeps its annotation unless req carries a new one; otherwise a
+// new ID is assigned. The blob is removed when the photo is rejected.
+func (s *Service) Attach(photoID string, req AnnotateRequest, blob Blob) (Metadata, error) {
+	meta, err := s.attach(photoID, req, blob)
+	if err != nil {
+		s.discard(blob.Key)
+	}
+	return meta, err
+}
+
+func (s *Service) attach(photoID string, req AnnotateRequest, blob Blob) (Metadata, error) {
+	annotation := req.Annotation.normalize()
+	var problems []string
+	if req.JobID == "" && req.TreatmentID == "" {
+		problems = append(problems, "jobId or treatmentId is required")
+	}
+	if len(photoID) > 64 || strings.ContainsAny(photoID, "/?#") {
+		problems = append(problems, "photoId must be at most 64 characters without / ? #")
+	}
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	switch {
+	case kind == "photo":
+		kind = ""
+	case kind == KindSignature && req.JobID == "":
+		problems = append(problems, "signatures need a jobId")
+	case kind != "" && kind != KindSignature:
+		problems = append(problems, fmt.Sprintf("unknown kind %q", req.Kind))
+	}
+	if len(problems) > 0 {
+		return Metadata{}, fmt.Errorf("%w: %s", ErrInvalidPhoto, strings.Join(problems, "; "))
+	}
+	if err := annotation.Validate(); err != nil {
+		return Metadata{}, err
+	}
+
+	now := s.now().UTC()
+	meta := Metadata{ID: photoID, CreatedAt: now}
+	if photoID == "" {
+		meta.ID = uuid.NewString()
+	} else if existing, err := s.store.GetMetadata(photoID); err == nil {
+		if existing.BlobKey != "" {
+			return Metadata{}, fmt.Errorf("%w: photo %q was already uploaded", ErrInvalidPhoto, photoID)
+		}
+		meta = existing
+	} else if !errors.Is(err, ErrNotFound) {
+		return Metadata{}, err
+	}
+
+	meta.JobID, meta.TreatmentID, meta.TechnicianID, meta.Kind = req.JobID, req.TreatmentID, req.TechnicianID, kind
+	if !annotation.empty() {
+		meta.Annotation = annotation
+	}
+	meta.BlobKey, meta.ContentType, meta.SizeBytes = blob.Key, blob.ContentType, blob.Size
+	meta.UploadedAt = &now
+	meta.UpdatedAt = now
+	if err := s.store.SaveMetadata(meta); err != nil {
+		return Metadata{}, err
+	}
+	return meta, nil
+}
+
+// URL returns a fresh signed URL for a photo's image.
+func (s *Service) URL(ctx context.Context, id string) (string, time.Time, error) {
+	meta, err := s.store.GetMetadata(id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if meta.BlobKey == "" {
+		return "", time.Time{}, fmt.Errorf("%w: photo %q has not been uploaded", ErrNotFound, id)
+	}
+	expires := s.now().UTC().Add(s.cfg.URLTTL)
+	url, err := s.blobs.SignedURL(ctx, meta.BlobKey, s.cfg.URLTTL)
+	return url, expires, err
+}
+
+// Open reads a photo's image; the caller closes it.
+func (s *Service) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	meta, err := s.store.GetMetadata(id)
+	if err != nil {
+		return nil, err
+	}
+	if meta.BlobKey == "" {
+		return nil, fmt.Errorf("%w: photo %q has not been uploaded", ErrNotFound, id)
+	}
+	body, err := s.blobs.Get(ctx, meta.BlobKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: image of photo %q is missing", ErrNotFound, id)
+	}
+	return body, err
+}
+
+// Photo returns a photo's metadata.
+func (s *Service) Photo(id string) (Metadata, error) {
+	return s.store.GetMetadata(id)
+}
+
+// Search lists photos matching q, oldest first.
+func (s *Service) Search(q Query) ([]Metadata, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return s.store.ListMetadata(q)
+}
+
+// discard removes an orphaned blob; failures only leave an unreferenced
+// object behind, so they are logged rather than returned.
+func (s *Service) discard(key string) {
+	if err := s.blobs.Delete(context.Background(), key); err != nil {
+		s.logger.Warn("failed to delete orphaned photo blob", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+func allowed(types []string, contentType string) bool {
+	for _, t := range types {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func extension(contentType string) string {
+	switch strings.ToLower(contentType) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/heic":
+		return ".heic"
+	case "image/heif":
+		return ".heif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
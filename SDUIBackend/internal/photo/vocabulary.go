@@ -0,0 +1,59 @@
+package photo
+
+// Severity levels, ordered from least to most severe.
+const (
+	SeverityNone     = "none"
+	SeverityLow      = "low"
+	SeverityModerate = "moderate"
+	SeverityHigh     = "high"
+	SeveritySevere   = "severe"
+)
+
+// Vocabulary lists the controlled terms photos can be tagged with.
+type Vocabulary struct {
+	Species    []string `json:"species"`
+	Locations  []string `json:"locations"`
+	Severities []string `json:"severities"`
+}
+
+// vocabulary is kept in sync with the picker lists in the iOS app; add terms
+// here before shipping them to devices.
+var vocabulary = Vocabulary{
+	Species: []string{
+		"american_cockroach", "bed_bug", "black_widow", "brown_recluse",
+		"carpenter_ant", "carpenter_bee", "drywood_termite", "fire_ant",
+		"flea", "german_cockroach", "house_mouse", "norway_rat",
+		"odorous_house_ant", "other", "paper_wasp", "roof_rat",
+		"silverfish", "subterranean_termite", "tick", "unknown", "yellowjacket",
+	},
+	Locations: []string{
+		"attic", "basement", "bathroom", "bedroom", "crawlspace",
+		"exterior_perimeter", "garage", "kitchen", "laundry", "living_room",
+		"other", "roofline", "yard",
+	},
+	Severities: []string{SeverityNone, SeverityLow, SeverityModerate, SeverityHigh, SeveritySevere},
+}
+
+// Terms returns the controlled vocabularies.
+func Terms() Vocabulary {
+	return vocabulary
+}
+
+func contains(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
+
+// severityRank orders severities; unknown values rank below none.
+func severityRank(severity string) int {
+	for i, s := range vocabulary.Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package playback
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes location reporting to the app and day playback in the
+// admin API.
+type Handler struct {
+	service *Service
+	feed    *activity.Service
+}
+
+// NewHandler creates a playback handler. Every day viewed is recorded in
+// feed.
+func NewHandler(service *Service, feed *activity.Service) *Handler {
+	return &Handler{service: service, feed: feed}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/{technicianId}/{date}", h.GetDay)
+}
+
+// ReportLocation stores the points the authenticated technician's device
+// sends as {"points": [...]}.
+func (h *Handler) ReportLocation(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var payload struct {
+		Points []Point `json:"points"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	kept, err := h.service.RecordLocation(me, payload.Points)
+	if err != nil {
+		h.fail(w, r, "failed to record location", err)
+		return
+	}
+	respond.JSON(w, http.StatusAccepted, map[string]int{"accepted": kept})
+}
+
+// GetDay replays {technicianId}'s {date} for ?reason=dispute or training,
+// up to ?at= (RFC 3339) when given.
+func (h *Handler) GetDay(w http.ResponseWriter, r *http.Request) {
+	q := Query{
+		TechnicianID: chi.URLParam(r, "technicianId"),
+		Date:         chi.URLParam(r, "date"),
+		Reason:       r.URL.Query().Get("reason"),
+		Admin:        true,
+	}
+	if v := r.URL.Query().Get("at"); v != "" {
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid at", "at must be an RFC 3339 time")
+			return
+		}
+		q.At = at
+	}
+	// Without auth everyone is an admin, as RequireRole lets them through.
+	if id, ok := auth.FromContext(r.Context()); ok {
+		q.Admin = id.Role == auth.RoleAdmin
+	}
+	day, err := h.service.Day(q)
+	if err != nil {
+		h.fail(w, r, "failed to replay day", err)
+		return
+	}
+	h.feed.Record(r.Context(), activity.Event{Type: activity.PlaybackViewed, SubjectID: q.TechnicianID + "_" + q.Date, SubjectName: q.TechnicianID + " on " + q.Date + " for " + q.Reason})
+	respond.JSON(w, http.StatusOK, day)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, ErrForbidden):
+		respond.Error(w, http.StatusForbidden, title, err.Error())
+	case errors.Is(err, ErrExpired):
+		respond.Error(w, http.StatusGone, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
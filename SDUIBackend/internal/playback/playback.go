@@ -0,0 +1,187 @@
+// Package playback records how each technician's day unfolded, the route
+// assignments, stop changes and job status transitions the server stored
+// and the location trace devices report, so a day can be replayed to
+// resolve a customer dispute or review it for training. Records are kept
+// for a retention period, location for its own, usually shorter one.
+package playback
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExpired is returned for days older than the retention period.
+	ErrExpired = errors.New("playback expired")
+	// ErrInvalidRequest wraps bad playback queries and location uploads.
+	ErrInvalidRequest = errors.New("invalid playback request")
+	// ErrForbidden is returned when the caller may not view a day for the
+	// reason given.
+	ErrForbidden = errors.New("playback not allowed")
+)
+
+// Event types.
+const (
+	// RouteAssigned is the first version of a day's route.
+	RouteAssigned = "route.assigned"
+	// RouteReordered is a route whose stops were resequenced.
+	RouteReordered = "route.reordered"
+	RouteDeleted   = "route.deleted"
+	StopAdded      = "stop.added"
+	StopRemoved    = "stop.removed"
+	// StopRescheduled is a stop whose service window moved.
+	StopRescheduled = "stop.rescheduled"
+	// JobStatus is a job's status changing from PreviousStatus to Status.
+	JobStatus = "job.status"
+)
+
+// Reasons a day may be replayed for.
+const (
+	// ReasonDispute shows everything recorded; only admins may use it.
+	ReasonDispute = "dispute"
+	// ReasonTraining hides customers and coarsens the location trace.
+	ReasonTraining = "training"
+)
+
+// Event is one recorded change to a technician's day.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	TechnicianID string    `json:"technicianId"`
+	Date         string    `json:"date"` // service date, 2006-01-02
+	At           time.Time `json:"at"`   // when the server stored the change
+	RouteID      string    `json:"routeId,omitempty"`
+	CustomerID   string    `json:"customerId,omitempty"`
+	CustomerName string    `json:"customerName,omitempty"`
+	// Stops is the route, in order, after a route change.
+	Stops          []Stop `json:"stops,omitempty"`
+	JobID          string `json:"jobId,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+}
+
+// Stop is a stop of a recorded route.
+type Stop struct {
+	CustomerID   string    `json:"customerId"`
+	CustomerName string    `json:"customerName,omitempty"`
+	WindowStart  time.Time `json:"windowStart"`
+	WindowEnd    time.Time `json:"windowEnd"`
+}
+
+// Point is a location a technician's device reported.
+type Point struct {
+	At        time.Time `json:"at"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	// Accuracy is the radius of uncertainty in meters; 0 when unknown.
+	Accuracy float64 `json:"accuracy,omitempty"`
+}
+
+// Store persists playback records.
+type Store interface {
+	AppendEvent(e Event) error
+	// ListEvents returns a technician's events of a service date, oldest
+	// first.
+	ListEvents(technicianID, date string) ([]Event, error)
+	AppendPoints(technicianID string, points []Point) error
+	// ListPoints returns a technician's points reported for [from, to),
+	// oldest first.
+	ListPoints(technicianID string, from, to time.Time) ([]Point, error)
+	// PruneEvents removes events of service dates before date, and
+	// PrunePoints points taken before cutoff; both report how many went.
+	PruneEvents(date string) (int, error)
+	PrunePoints(cutoff time.Time) (int, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	events map[string][]Event // by technician
+	points map[string][]Point // by technician, sorted by At
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{events: make(map[string][]Event), points: make(map[string][]Point)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) AppendEvent(e Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[e.TechnicianID] = append(m.events[e.TechnicianID], e)
+	return nil
+}
+
+func (m *MemoryStore) ListEvents(technicianID, date string) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Event{}
+	for _, e := range m.events[technicianID] {
+		if e.Date == date {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+func (m *MemoryStore) AppendPoints(technicianID string, points []Point) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	all := append(m.points[technicianID], points...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].At.Before(all[j].At) })
+	m.points[technicianID] = all
+	return nil
+}
+
+func (m *MemoryStore) ListPoints(technicianID string, from, to time.Time) ([]Point, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Point{}
+	for _, p := range m.points[technicianID] {
+		if !p.At.Before(from) && p.At.Before(to) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) PruneEvents(date string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for tech, events := range m.events {
+		kept := events[:0]
+		for _, e := range events {
+			if e.Date < date {
+				n++
+				continue
+			}
+			kept = append(kept, e)
+		}
+		m.events[tech] = kept
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) PrunePoints(cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for tech, points := range m.points {
+		kept := points[:0]
+		for _, p := range points {
+			if p.At.Before(cutoff) {
+				n++
+				continue
+			}
+			kept = append(kept, p)
+		}
+		m.points[tech] = kept
+	}
+	return n, nil
+}
@@ -0,0 +1,145 @@
+package playback
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+func newService(now *time.Time) (*Service, repository.Repository) {
+	store := storememory.NewStore()
+	svc := NewService(config.PlaybackConfig{Retention: 90 * 24 * time.Hour, LocationRetention: 30 * 24 * time.Hour, PruneInterval: time.Hour, MaxPoints: 10}, NewMemoryStore(), nil, nil)
+	svc.now = func() time.Time { return *now }
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	return svc, Record(repos, svc)
+}
+
+func stop(id string, hour int) models.RouteStop {
+	return models.RouteStop{CustomerID: id, CustomerName: "Customer " + id, WindowStart: day.Add(time.Duration(hour) * time.Hour), WindowEnd: day.Add(time.Duration(hour+1) * time.Hour)}
+}
+
+func TestDay(t *testing.T) {
+	now := day.Add(8 * time.Hour)
+	svc, repos := newService(&now)
+	step := func(fn func() error) {
+		t.Helper()
+		now = now.Add(10 * time.Minute)
+		if err := fn(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	route := models.Route{TechnicianID: "tech-1", ServiceDate: day, CustomerStops: []models.RouteStop{stop("a", 9), stop("b", 10), stop("c", 11)}}
+	step(func() error { return repos.Routes.SaveRoute(route) })
+	job := models.JobUpload{ID: "job-1", TechnicianID: "tech-1", CustomerName: "Customer a", ScheduledDate: day, Status: "en_route"}
+	step(func() error { return repos.Sync.SaveJobUpload(job) })
+	step(func() error { return repos.Sync.SaveJobUpload(job) }) // unchanged, as when geocoded
+	midday := now
+	// c moves ahead of b, b is pushed back an hour and d replaces a.
+	route.CustomerStops = []models.RouteStop{stop("c", 11), stop("b", 12), stop("d", 13)}
+	step(func() error { return repos.Routes.SaveRoute(route) })
+	job.Status = "completed"
+	step(func() error { return repos.Sync.SaveJobUpload(job) })
+	if _, err := svc.RecordLocation("tech-1", []Point{
+		{At: day.Add(8 * time.Hour), Latitude: 39.78174, Longitude: -89.65012},
+		{At: day.Add(-time.Hour), Latitude: 39.7, Longitude: -89.6}, // the day before
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := svc.Day(Query{TechnicianID: "tech-1", Date: "2026-10-15", Reason: ReasonDispute, Admin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, e := range full.Events {
+		types = append(types, e.Type)
+	}
+	if got := strings.Join(types, " "); got != "route.assigned job.status stop.rescheduled stop.added stop.removed route.reordered job.status" {
+		t.Errorf("unexpected events %s", got)
+	}
+	if len(full.Stops) != 3 || full.Stops[0].CustomerID != "c" || len(full.Jobs) != 1 || full.Jobs[0].Status != "completed" || len(full.Jobs[0].Transitions) != 2 {
+		t.Errorf("expected the day's end state, got %+v", full)
+	}
+	if len(full.Trace) != 1 || full.Trace[0].Latitude != 39.78174 {
+		t.Errorf("expected the day's trace, got %+v", full.Trace)
+	}
+
+	earlier, err := svc.Day(Query{TechnicianID: "tech-1", Date: "2026-10-15", Reason: ReasonTraining, At: midday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !earlier.Redacted || len(earlier.Events) != 2 || earlier.Stops[0].CustomerID != "customer-1" || earlier.Stops[0].CustomerName != "" || earlier.Jobs[0].Status != "en_route" {
+		t.Errorf("expected the morning with customers hidden, got %+v", earlier)
+	}
+	if earlier.Trace[0].Latitude != 39.782 || earlier.Trace[0].Longitude != -89.65 {
+		t.Errorf("expected the trace coarsened, got %+v", earlier.Trace)
+	}
+
+	step(func() error { return repos.Routes.DeleteRoute("tech-1", day) })
+	if d, _ := svc.Day(Query{TechnicianID: "tech-1", Date: "2026-10-15", Reason: ReasonTraining}); len(d.Stops) != 0 {
+		t.Errorf("expected no stops after the route was deleted, got %+v", d.Stops)
+	}
+}
+
+func TestDayAccess(t *testing.T) {
+	now := day.Add(100 * 24 * time.Hour)
+	svc, _ := newService(&now)
+	for _, tc := range []struct {
+		q    Query
+		want error
+	}{
+		{Query{TechnicianID: "tech-1", Date: "2026-12-01", Reason: ReasonDispute}, ErrForbidden},
+		{Query{TechnicianID: "tech-1", Date: "2026-12-01"}, ErrInvalidRequest},
+		{Query{TechnicianID: "tech-1", Date: "12/01/2026", Reason: ReasonTraining}, ErrInvalidRequest},
+		{Query{TechnicianID: "tech-1", Date: "2026-10-15", Reason: ReasonTraining}, ErrExpired},
+	} {
+		if _, err := svc.Day(tc.q); !errors.Is(err, tc.want) {
+			t.Errorf("%+v: expected %v, got %v", tc.q, tc.want, err)
+		}
+	}
+
+	r := chi.NewRouter()
+	r.Route("/playback", NewHandler(svc, nil).Routes)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/playback/tech-1/2026-10-15?reason=training", nil))
+	if rec.Code != http.StatusGone {
+		t.Errorf("expected an expired day gone, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRecordLocation(t *testing.T) {
+	now := day.Add(12 * time.Hour)
+	svc, _ := newService(&now)
+	if _, err := svc.RecordLocation("tech-1", make([]Point, 11)); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected too many points refused, got %v", err)
+	}
+	if _, err := svc.RecordLocation("tech-1", []Point{{At: now, Latitude: 91}}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected an invalid coordinate refused, got %v", err)
+	}
+	kept, err := svc.RecordLocation("tech-1", []Point{{At: now}, {At: now.Add(-31 * 24 * time.Hour)}, {At: now.Add(time.Hour)}})
+	if err != nil || kept != 1 {
+		t.Errorf("expected old and future points dropped, got %d (%v)", kept, err)
+	}
+
+	now = now.Add(31 * 24 * time.Hour)
+	svc.prune(now)
+	if points, _ := svc.store.ListPoints("tech-1", day, now); len(points) != 0 {
+		t.Errorf("expected points past retention pruned, got %+v", points)
+	}
+	svc.cfg.LocationRetention = 0
+	if kept, err := svc.RecordLocation("tech-1", []Point{{At: now}}); err != nil || kept != 0 {
+		t.Errorf("expected nothing stored without location collection, got %d (%v)", kept, err)
+	}
+}
@@ -0,0 +1,89 @@
+package playback
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Record wraps the route and sync repositories so every route and job the
+// server stores is recorded; with a nil service repos is returned as is.
+// Nil repositories are left nil so Repository.Validate still reports them.
+func Record(repos repository.Repository, s *Service) repository.Repository {
+	if s == nil {
+		return repos
+	}
+	out := repos
+	if repos.Routes != nil {
+		out.Routes = routes{RouteRepository: repos.Routes, s: s}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{SyncRepository: repos.Sync, routes: repos.Routes, s: s}
+	}
+	return out
+}
+
+type routes struct {
+	repository.RouteRepository
+	s *Service
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	previous, err := r.GetRoute(route.TechnicianID, route.ServiceDate)
+	had := err == nil
+	if err := r.RouteRepository.SaveRoute(route); err != nil {
+		return err
+	}
+	r.s.routeSaved(previous, had, route)
+	return nil
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	route, err := r.GetRoute(technicianID, serviceDate)
+	if err := r.RouteRepository.DeleteRoute(technicianID, serviceDate); err != nil {
+		return err
+	}
+	if err == nil {
+		r.s.routeDeleted(route)
+	}
+	return nil
+}
+
+type syncRepo struct {
+	repository.SyncRepository
+	// routes looks up the routes a batch replaces.
+	routes repository.RouteRepository
+	s      *Service
+}
+
+func (r syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	if err := r.SyncRepository.SaveJobUpload(upload); err != nil {
+		return err
+	}
+	r.s.jobSaved(upload)
+	return nil
+}
+
+// SaveBatch records the batch's routes and jobs once all of them are stored,
+// comparing each route with the one it replaced.
+func (r syncRepo) SaveBatch(batch repository.Batch) error {
+	previous := make([]models.Route, len(batch.Routes))
+	had := make([]bool, len(batch.Routes))
+	if r.routes != nil {
+		for i, route := range batch.Routes {
+			p, err := r.routes.GetRoute(route.TechnicianID, route.ServiceDate)
+			previous[i], had[i] = p, err == nil
+		}
+	}
+	if err := r.SyncRepository.SaveBatch(batch); err != nil {
+		return err
+	}
+	for i, route := range batch.Routes {
+		r.s.routeSaved(previous[i], had[i], route)
+	}
+	for _, j := range batch.Jobs {
+		r.s.jobSaved(j)
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxClockSkew is how far in the future a device may date a location
+// before it is dropped.
+const maxClockSkew = 5 * time.Minute
+
+// coarseDecimals rounds training traces to about 100 meters.
+const coarseDecimals = 3
+
+// Service records technicians' days and replays them.
+type Service struct {
+	cfg       config.PlaybackConfig
+	store     Store
+	calendars *calendar.Service
+	logger    *slog.Logger
+	now       func() time.Time
+	// jobMu orders the status lookup and append of concurrent job saves,
+	// so a transition is recorded once.
+	jobMu sync.Mutex
+}
+
+// NewService wires a playback service. Days run midnight to midnight in
+// the time zone of the technician's branch calendar, or UTC without one.
+func NewService(cfg config.PlaybackConfig, store Store, calendars *calendar.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, calendars: calendars, logger: logger, now: time.Now}
+}
+
+// Query selects a day to replay.
+type Query struct {
+	TechnicianID string
+	Date         string // 2006-01-02
+	Reason       string
+	// At replays the day up to a moment; zero replays all of it.
+	At time.Time
+	// Admin is set for callers who may view days for ReasonDispute.
+	Admin bool
+}
+
+// Day is a technician's day as recorded.
+type Day struct {
+	TechnicianID string     `json:"technicianId"`
+	Date         string     `json:"date"`
+	Reason       string     `json:"reason"`
+	At           *time.Time `json:"at,omitempty"`
+	// Stops is the route as it stood at the end of the replay.
+	Stops  []Stop  `json:"stops"`
+	Jobs   []Job   `json:"jobs"`
+	Events []Event `json:"events"`
+	Trace  []Point `json:"trace"`
+	// Redacted is set when customers are pseudonymized and the trace
+	// coarsened, as they are for training.
+	Redacted bool `json:"redacted"`
+}
+
+// Job is a job's status history over the day.
+type Job struct {
+	JobID        string       `json:"jobId"`
+	CustomerName string       `json:"customerName,omitempty"`
+	Status       string       `json:"status"`
+	Transitions  []Transition `json:"transitions"`
+}
+
+// Transition is a job reaching a status.
+type Transition struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// Day replays a technician's day.
+func (s *Service) Day(q Query) (Day, error) {
+	switch q.Reason {
+	case ReasonDispute:
+		if !q.Admin {
+			return Day{}, fmt.Errorf("%w: only admins may view a day for a dispute", ErrForbidden)
+		}
+	case ReasonTraining:
+	default:
+		return Day{}, fmt.Errorf("%w: reason must be %s or %s", ErrInvalidRequest, ReasonDispute, ReasonTraining)
+	}
+	if q.TechnicianID == "" {
+		return Day{}, fmt.Errorf("%w: technician is required", ErrInvalidRequest)
+	}
+	date, err := time.Parse(dateLayout, q.Date)
+	if err != nil {
+		return Day{}, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidRequest)
+	}
+	if q.Date < s.oldestDate() {
+		return Day{}, fmt.Errorf("%w: days are kept for %s", ErrExpired, s.cfg.Retention)
+	}
+
+	events, err := s.store.ListEvents(q.TechnicianID, q.Date)
+	if err != nil {
+		return Day{}, err
+	}
+	day := Day{TechnicianID: q.TechnicianID, Date: q.Date, Reason: q.Reason, Stops: []Stop{}, Jobs: []Job{}, Events: []Event{}, Trace: []Point{}}
+	if !q.At.IsZero() {
+		at := q.At.UTC()
+		day.At = &at
+	}
+	jobs := make(map[string]int)
+	for _, e := range events {
+		if !q.At.IsZero() && e.At.After(q.At) {
+			break
+		}
+		day.Events = append(day.Events, e)
+		switch e.Type {
+		case RouteDeleted:
+			day.Stops = []Stop{}
+		case JobStatus:
+			i, ok := jobs[e.JobID]
+			if !ok {
+				i = len(day.Jobs)
+				jobs[e.JobID] = i
+				day.Jobs = append(day.Jobs, Job{JobID: e.JobID, CustomerName: e.CustomerName})
+			}
+			day.Jobs[i].Status = e.Status
+			day.Jobs[i].Transitions = append(day.Jobs[i].Transitions, Transition{Status: e.Status, At: e.At})
+		default:
+			if e.Stops != nil {
+				day.Stops = e.Stops
+			}
+		}
+	}
+
+	if s.cfg.LocationRetention > 0 {
+		y, m, d := date.Date()
+		from := time.Date(y, m, d, 0, 0, 0, 0, s.zone(q.TechnicianID))
+		to := from.AddDate(0, 0, 1)
+		if !q.At.IsZero() && q.At.Before(to) {
+			to = q.At.Add(time.Nanosecond)
+		}
+		if day.Trace, err = s.store.ListPoints(q.TechnicianID, from, to); err != nil {
+			return Day{}, err
+		}
+	}
+	if q.Reason == ReasonTraining {
+		redact(&day)
+	}
+	return day, nil
+}
+
+// redact pseudonymizes the customers of a day and coarsens its trace.
+func redact(day *Day) {
+	day.Redacted = true
+	aliases := make(map[string]string)
+	alias := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		if a, ok := aliases[id]; ok {
+			return a
+		}
+		a := fmt.Sprintf("customer-%d", len(aliases)+1)
+		aliases[id] = a
+		return a
+	}
+	stops := func(in []Stop) []Stop {
+		if in == nil {
+			return nil
+		}
+		out := make([]Stop, len(in))
+		for i, st := range in {
+			out[i] = Stop{CustomerID: alias(st.CustomerID), WindowStart: st.WindowStart, WindowEnd: st.WindowEnd}
+		}
+		return out
+	}
+	for i, e := range day.Events {
+		e.CustomerID = alias(e.CustomerID)
+		e.CustomerName = ""
+		e.Stops = stops(e.Stops)
+		day.Events[i] = e
+	}
+	day.Stops = stops(day.Stops)
+	for i := range day.Jobs {
+		day.Jobs[i].CustomerName = ""
+	}
+	scale := math.Pow(10, coarseDecimals)
+	for i, p := range day.Trace {
+		p.Latitude = math.Round(p.Latitude*scale) / scale
+		p.Longitude = math.Round(p.Longitude*scale) / scale
+		day.Trace[i] = p
+	}
+}
+
+// RecordLocation stores the points a technician's device reported and
+// returns how many were kept: points older than the location retention, or
+// dated in the future, are dropped. Nothing is kept when location is not
+// collected.
+func (s *Service) RecordLocation(technicianID string, points []Point) (int, error) {
+	if len(points) == 0 || len(points) > s.cfg.MaxPoints {
+		return 0, fmt.Errorf("%w: send 1 to %d points", ErrInvalidRequest, s.cfg.MaxPoints)
+	}
+	for i, p := range points {
+		if p.At.IsZero() || p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 || p.Accuracy < 0 {
+			return 0, fmt.Errorf("%w: point %d needs a time, a valid coordinate and a non-negative accuracy", ErrInvalidRequest, i)
+		}
+	}
+	if s.cfg.LocationRetention == 0 {
+		return 0, nil
+	}
+	now := s.now()
+	kept := make([]Point, 0, len(points))
+	for _, p := range points {
+		if p.At.Before(now.Add(-s.cfg.LocationRetention)) || p.At.After(now.Add(maxClockSkew)) {
+			continue
+		}
+		p.At = p.At.UTC()
+		kept = append(kept, p)
+	}
+	if len(kept) == 0 {
+		return 0, nil
+	}
+	if err := s.store.AppendPoints(technicianID, kept); err != nil {
+		return 0, err
+	}
+	return len(kept), nil
+}
+
+// routeSaved records how a route changed; had is false for its first
+// version. Saves that change no stop, such as geocoding, record nothing.
+func (s *Service) routeSaved(previous models.Route, had bool, route models.Route) {
+	if s == nil {
+		return
+	}
+	base := Event{TechnicianID: route.TechnicianID, Date: route.ServiceDate.Format(dateLayout), RouteID: route.ServerID(), Stops: stopsOf(route)}
+	if !had {
+		base.Type = RouteAssigned
+		s.append(base)
+		return
+	}
+
+	before := make(map[string]models.RouteStop, len(previous.CustomerStops))
+	for _, st := range previous.CustomerStops {
+		before[st.CustomerID] = st
+	}
+	after := make(map[string]bool, len(route.CustomerStops))
+	var events []Event
+	var kept []string
+	for _, st := range route.CustomerStops {
+		after[st.CustomerID] = true
+		e := base
+		e.CustomerID, e.CustomerName = st.CustomerID, st.CustomerName
+		old, ok := before[st.CustomerID]
+		switch {
+		case !ok:
+			e.Type = StopAdded
+			events = append(events, e)
+			continue
+		case !old.WindowStart.Equal(st.WindowStart) || !old.WindowEnd.Equal(st.WindowEnd):
+			e.Type = StopRescheduled
+			events = append(events, e)
+		}
+		kept = append(kept, st.CustomerID)
+	}
+	var was []string
+	for _, st := range previous.CustomerStops {
+		if after[st.CustomerID] {
+			was = append(was, st.CustomerID)
+			continue
+		}
+		e := base
+		e.Type, e.CustomerID, e.CustomerName = StopRemoved, st.CustomerID, st.CustomerName
+		events = append(events, e)
+	}
+	if fmt.Sprint(was) != fmt.Sprint(kept) {
+		e := base
+		e.Type = RouteReordered
+		events = append(events, e)
+	}
+	for _, e := range events {
+		s.append(e)
+	}
+}
+
+// routeDeleted records a route's deletion.
+func (s *Service) routeDeleted(route models.Route) {
+	if s == nil {
+		return
+	}
+	s.append(Event{Type: RouteDeleted, TechnicianID: route.TechnicianID, Date: route.ServiceDate.Format(dateLayout), RouteID: route.ServerID()})
+}
+
+// jobSaved records a job's status when it differs from the last one
+// recorded. Jobs without a scheduled date are filed under the day they
+// were saved.
+func (s *Service) jobSaved(job models.JobUpload) {
+	if s == nil || job.TechnicianID == "" || job.Status == "" {
+		return
+	}
+	date := job.ScheduledDate.Format(dateLayout)
+	if job.ScheduledDate.IsZero() {
+		date = s.now().In(s.zone(job.TechnicianID)).Format(dateLayout)
+	}
+
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+	events, err := s.store.ListEvents(job.TechnicianID, date)
+	if err != nil {
+		s.logger.Warn("playback: list events", slog.String("technician", job.TechnicianID), slog.Any("error", err))
+		return
+	}
+	previous := ""
+	for _, e := range events {
+		if e.Type == JobStatus && e.JobID == job.ID {
+			previous = e.Status
+		}
+	}
+	if previous == job.Status {
+		return
+	}
+	s.append(Event{Type: JobStatus, TechnicianID: job.TechnicianID, Date: date, JobID: job.ID, CustomerName: job.CustomerName, Status: job.Status, PreviousStatus: previous})
+}
+
+// append stores an event. Failures are logged: recording never fails the
+// save it describes.
+func (s *Service) append(e Event) {
+	e.ID = uuid.NewString()
+	e.At = s.now().UTC()
+	if err := s.store.AppendEvent(e); err != nil {
+		s.logger.Warn("playback: record event", slog.String("type", e.Type), slog.String("technician", e.TechnicianID), slog.Any("error", err))
+	}
+}
+
+func stopsOf(route models.Route) []Stop {
+	stops := make([]Stop, len(route.CustomerStops))
+	for i, st := range route.CustomerStops {
+		stops[i] = Stop{CustomerID: st.CustomerID, CustomerName: st.CustomerName, WindowStart: st.WindowStart, WindowEnd: st.WindowEnd}
+	}
+	return stops
+}
+
+// zone returns the time zone of a technician's days.
+func (s *Service) zone(technicianID string) *time.Location {
+	if h := s.calendars.ForTechnician(technicianID); h != nil {
+		return h.Location()
+	}
+	return time.UTC
+}
+
+// oldestDate is the earliest service date still kept.
+func (s *Service) oldestDate() string {
+	return s.now().UTC().Add(-s.cfg.Retention).Format(dateLayout)
+}
+
+// Run prunes records past their retention every PruneInterval until ctx
+// is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune(s.now())
+		}
+	}
+}
+
+func (s *Service) prune(now time.Time) {
+	events, err := s.store.PruneEvents(s.oldestDate())
+	if err != nil {
+		s.logger.Error("prune playback events", slog.Any("error", err))
+	}
+	points, err := s.store.PrunePoints(now.Add(-s.cfg.LocationRetention))
+	if err != nil {
+		s.logger.Error("prune playback locations", slog.Any("error", err))
+	}
+	if events > 0 || points > 0 {
+		s.logger.Info("pruned playback records", slog.Int("events", events), slog.Int("points", points))
+	}
+}
@@ -0,0 +1,218 @@
+package profit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// columns is the monthly export's CSV header.
+var columns = []string{
+	"service_date", "technician_id", "job_id", "customer_name", "status",
+	"revenue", "on_site_minutes", "drive_minutes", "labor_cost", "vehicle_cost",
+	"chemical_cost", "cost", "profit", "margin", "missing",
+}
+
+// Handler exposes profitability in the admin API: reports to admins and
+// dispatchers, and invoice and labor rate inputs to admins.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a profitability handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the reports.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.GetDay)
+	r.Get("/routes/{technicianId}/{date}", h.GetRoute)
+	r.Get("/jobs/{jobId}", h.GetJob)
+	r.Get("/export", h.Export)
+}
+
+// InvoiceRoutes mounts invoice amounts, which billing systems push.
+func (h *Handler) InvoiceRoutes(r chi.Router) {
+	r.Post("/", h.SaveInvoices)
+	r.Put("/{jobId}", h.SaveInvoice)
+}
+
+// RateRoutes mounts technicians' labor rates.
+func (h *Handler) RateRoutes(r chi.Router) {
+	r.Get("/", h.ListRates)
+	r.Put("/{technicianId}", h.SetRate)
+	r.Delete("/{technicianId}", h.DeleteRate)
+}
+
+// GetDay returns every route's profitability on ?serviceDate= (default
+// today), for the dispatcher dashboard.
+func (h *Handler) GetDay(w http.ResponseWriter, r *http.Request) {
+	date := h.service.now().UTC().Truncate(24 * time.Hour)
+	if v := r.URL.Query().Get("serviceDate"); v != "" {
+		var err error
+		if date, err = time.Parse(dateLayout, v); err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+			return
+		}
+	}
+	day, err := h.service.Day(r.Context(), date)
+	if err != nil {
+		h.fail(w, r, "failed to compute profitability", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, day)
+}
+
+// GetRoute returns the profitability of {technicianId}'s jobs on {date}.
+func (h *Handler) GetRoute(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse(dateLayout, chi.URLParam(r, "date"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid date", "expected YYYY-MM-DD")
+		return
+	}
+	route, err := h.service.Route(r.Context(), chi.URLParam(r, "technicianId"), date)
+	if err != nil {
+		h.fail(w, r, "failed to compute profitability", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, route)
+}
+
+// GetJob returns a job's profitability.
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.Job(r.Context(), chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.fail(w, r, "failed to compute profitability", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, job)
+}
+
+// Export returns the profitability of every job of ?month=YYYY-MM as CSV.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	month, err := time.Parse("2006-01", r.URL.Query().Get("month"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid month", "expected YYYY-MM")
+		return
+	}
+	jobs, err := h.service.Month(r.Context(), month)
+	if err != nil {
+		h.fail(w, r, "failed to compute profitability", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="profitability-`+month.Format("2006-01")+`.csv"`)
+	w.WriteHeader(http.StatusOK)
+	money := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	minutes := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	cw := csv.NewWriter(w)
+	_ = cw.Write(columns)
+	for _, j := range jobs {
+		margin := ""
+		if j.Margin != nil {
+			margin = strconv.FormatFloat(*j.Margin, 'f', -1, 64)
+		}
+		_ = cw.Write([]string{
+			j.ServiceDate, j.TechnicianID, j.JobID, j.CustomerName, j.Status,
+			money(j.Revenue), minutes(j.OnSiteMinutes), minutes(j.DriveMinutes), money(j.LaborCost), money(j.VehicleCost),
+			money(j.ChemicalCost), money(j.Cost), money(j.Profit), margin, strings.Join(j.Missing, ";"),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		// The status is sent; the client sees a truncated body.
+		middleware.LoggerFrom(r.Context()).Warn("profitability export write failed", slog.Any("error", err))
+	}
+}
+
+// SaveInvoices stores the invoices of {"invoices": [...]}, all or none.
+func (h *Handler) SaveInvoices(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Invoices []Invoice `json:"invoices"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	invoices, err := h.service.SaveInvoices(payload.Invoices...)
+	if err != nil {
+		h.fail(w, r, "failed to save invoices", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"invoices": invoices})
+}
+
+// SaveInvoice stores the invoice of {jobId}.
+func (h *Handler) SaveInvoice(w http.ResponseWriter, r *http.Request) {
+	var payload Invoice
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	payload.JobID = chi.URLParam(r, "jobId")
+	invoices, err := h.service.SaveInvoices(payload)
+	if err != nil {
+		h.fail(w, r, "failed to save invoice", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, invoices[0])
+}
+
+// ListRates returns the technicians' labor rates and the default one.
+func (h *Handler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.Rates()
+	if err != nil {
+		h.fail(w, r, "failed to list labor rates", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"defaultHourlyRate": h.service.cfg.LaborRate, "currency": h.service.cfg.Currency, "rates": rates})
+}
+
+// SetRate stores {technicianId}'s labor rate, sent as {"hourlyRate": n}.
+func (h *Handler) SetRate(w http.ResponseWriter, r *http.Request) {
+	var payload Rate
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	payload.TechnicianID = chi.URLParam(r, "technicianId")
+	rate, err := h.service.SetRate(payload)
+	if err != nil {
+		h.fail(w, r, "failed to set labor rate", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, rate)
+}
+
+// DeleteRate returns {technicianId} to the default labor rate.
+func (h *Handler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRate(chi.URLParam(r, "technicianId")); err != nil {
+		h.fail(w, r, "failed to delete labor rate", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidInput):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
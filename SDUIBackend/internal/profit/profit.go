@@ -0,0 +1,150 @@
+// Package profit estimates what each job and route earned: invoice amounts
+// less the labor of time on site and driving, vehicle costs, and the
+// chemicals applied at inventory prices. Inputs that were never recorded
+// are listed on each job so the numbers are not mistaken for complete.
+package profit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a job, route or labor rate does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput wraps invoice, rate and query validation failures.
+	ErrInvalidInput = errors.New("invalid profitability input")
+)
+
+// Inputs a job's profitability may lack.
+const (
+	MissingInvoice    = "invoice"
+	MissingOnSiteTime = "onSiteTime"
+	MissingDriveTime  = "driveTime"
+	// MissingPrice is followed by the chemical's name, as in
+	// "price:Termidor SC".
+	MissingPrice = "price"
+)
+
+// Invoice is what a job was billed, as the billing system reports it.
+type Invoice struct {
+	JobID     string    `json:"jobId"`
+	Amount    float64   `json:"amount"`
+	Number    string    `json:"number,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks an invoice before it is stored.
+func (i Invoice) Validate() error {
+	var problems []string
+	if strings.TrimSpace(i.JobID) == "" {
+		problems = append(problems, "jobId is required")
+	}
+	if !validAmount(i.Amount) {
+		problems = append(problems, "amount must be a number >= 0")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Rate is a technician's hourly labor cost, overriding the configured one.
+type Rate struct {
+	TechnicianID string    `json:"technicianId"`
+	HourlyRate   float64   `json:"hourlyRate"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func validAmount(f float64) bool {
+	return f >= 0 && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// Store persists invoices and labor rates.
+type Store interface {
+	SaveInvoices(invoices ...Invoice) error
+	// ListInvoices returns the invoices of the jobs given, by job.
+	ListInvoices(jobIDs []string) (map[string]Invoice, error)
+	// DeleteInvoice removes a job's invoice. Deleting a missing invoice
+	// succeeds.
+	DeleteInvoice(jobID string) error
+	SaveRate(r Rate) error
+	ListRates() ([]Rate, error)
+	DeleteRate(technicianID string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	invoices map[string]Invoice // by job
+	rates    map[string]Rate    // by technician
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{invoices: make(map[string]Invoice), rates: make(map[string]Rate)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveInvoices(invoices ...Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, i := range invoices {
+		m.invoices[i.JobID] = i
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListInvoices(jobIDs []string) (map[string]Invoice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]Invoice, len(jobIDs))
+	for _, id := range jobIDs {
+		if i, ok := m.invoices[id]; ok {
+			out[id] = i
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteInvoice(jobID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.invoices, jobID)
+	return nil
+}
+
+func (m *MemoryStore) SaveRate(r Rate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates[r.TechnicianID] = r
+	return nil
+}
+
+func (m *MemoryStore) ListRates() ([]Rate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Rate, 0, len(m.rates))
+	for _, r := range m.rates {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TechnicianID < out[j].TechnicianID })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteRate(technicianID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.rates[technicianID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.rates, technicianID)
+	return nil
+}
@@ -0,0 +1,145 @@
+package profit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+	"github.com/your-org/pestgenie-sdui/internal/routing"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+// quarterHour drives 15 minutes between any two points.
+type quarterHour struct{}
+
+func (quarterHour) Name() string { return "test" }
+
+func (quarterHour) Matrix(_ context.Context, points []routing.Point) ([][]time.Duration, error) {
+	out := make([][]time.Duration, len(points))
+	for i := range out {
+		out[i] = make([]time.Duration, len(points))
+		for j := range out[i] {
+			if i != j {
+				out[i][j] = 15 * time.Minute
+			}
+		}
+	}
+	return out, nil
+}
+
+var day = time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+// newTestService sets up tech-1's route on day: Avery, an hour on site with
+// 10 oz of a priced product and a $120 invoice, then Blake, 15 minutes'
+// drive away and with nothing else recorded.
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+	if err := store.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day, CustomerStops: []models.RouteStop{
+		{CustomerName: "Avery", Latitude: 39.78, Longitude: -89.65},
+		{CustomerName: "Blake", Latitude: 39.80, Longitude: -89.60},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-a", TechnicianID: "tech-1", CustomerName: "Avery", ScheduledDate: day, Status: "completed"})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-b", TechnicianID: "tech-1", CustomerName: "blake", ScheduledDate: day, Status: "scheduled"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", TechnicianID: "tech-1", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "oz"})
+	_ = store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", JobID: "job-a", ChemicalID: "chem-1", TechnicianID: "tech-1", QuantityUsed: 10})
+
+	durations := schedule.NewService(config.ScheduleConfig{}, schedule.NewMemoryStore(), repos, nil, nil, nil)
+	start := day.Add(9 * time.Hour)
+	if _, err := durations.Record(schedule.Observation{JobID: "job-a", ServiceType: "general_pest", StartedAt: start, CompletedAt: start.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	prices := inventory.NewService(config.InventoryConfig{}, inventory.NewMemoryStore(), repos, nil, nil)
+	if _, err := prices.SetPrice(inventory.Price{Product: inventory.Product{Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "OZ"}, UnitCost: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService(config.ProfitConfig{LaborRate: 30, VehicleRate: 12, Currency: "USD"}, NewMemoryStore(), repos, durations, quarterHour{}, prices, nil)
+	svc.now = func() time.Time { return day.Add(20 * time.Hour) }
+	if _, err := svc.SaveInvoices(Invoice{JobID: "job-a", Amount: 120}); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestRouteProfitability(t *testing.T) {
+	svc := newTestService(t)
+	route, err := svc.Route(context.Background(), "tech-1", day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Jobs) != 2 || route.Incomplete != 1 {
+		t.Fatalf("expected two jobs, one incomplete; got %+v", route)
+	}
+	a, b := route.Jobs[0], route.Jobs[1]
+	if a.LaborCost != 30 || a.VehicleCost != 0 || a.ChemicalCost != 20 || a.Profit != 70 || *a.Margin != 0.583 || len(a.Missing) != 0 {
+		t.Errorf("unexpected first stop %+v", a)
+	}
+	if b.DriveMinutes != 15 || b.LaborCost != 7.5 || b.VehicleCost != 3 || b.Profit != -10.5 || b.Margin != nil ||
+		strings.Join(b.Missing, ",") != MissingInvoice+","+MissingOnSiteTime {
+		t.Errorf("unexpected second stop %+v", b)
+	}
+	if route.Totals.Revenue != 120 || route.Totals.Cost != 60.5 || route.Totals.Profit != 59.5 {
+		t.Errorf("unexpected totals %+v", route.Totals)
+	}
+
+	if _, err := svc.SetRate(Rate{TechnicianID: "tech-1", HourlyRate: 40}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SetRate(Rate{TechnicianID: "nobody", HourlyRate: 40}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown technician refused, got %v", err)
+	}
+	job, err := svc.Job(context.Background(), "job-a")
+	if err != nil || job.LaborCost != 40 {
+		t.Errorf("expected the technician's rate applied, got %+v (%v)", job, err)
+	}
+	if _, err := svc.SaveInvoices(Invoice{JobID: "job-b", Amount: 80}, Invoice{Amount: -1}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected an invalid invoice refused, got %v", err)
+	}
+	if job, _ := svc.Job(context.Background(), "job-b"); job.Revenue != 0 {
+		t.Error("expected no invoice stored when one is invalid")
+	}
+}
+
+func TestExport(t *testing.T) {
+	r := chi.NewRouter()
+	r.Route("/", NewHandler(newTestService(t)).Routes)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/export?month=2026-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 3 || lines[2] != "2026-10-15,tech-1,job-b,blake,scheduled,0.00,0,15,7.50,3.00,0.00,10.50,-10.50,,invoice;onSiteTime" {
+		t.Errorf("unexpected export:\n%s", body)
+	}
+
+	resp, err = http.Get(srv.URL + "/?serviceDate=15-10-2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a bad date refused, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,498 @@
+package profit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+	"github.com/your-org/pestgenie-sdui/internal/routing"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+)
+
+const dateLayout = "2006-01-02"
+
+// Costs is what a job, route or day earned and cost. Money is in the
+// configured currency, rounded to cents.
+type Costs struct {
+	Revenue       float64 `json:"revenue"`
+	OnSiteMinutes float64 `json:"onSiteMinutes"`
+	DriveMinutes  float64 `json:"driveMinutes"`
+	LaborCost     float64 `json:"laborCost"`
+	VehicleCost   float64 `json:"vehicleCost"`
+	ChemicalCost  float64 `json:"chemicalCost"`
+	Cost          float64 `json:"cost"`
+	Profit        float64 `json:"profit"`
+	// Margin is Profit as a share of Revenue; absent without revenue.
+	Margin *float64 `json:"margin,omitempty"`
+}
+
+func (c *Costs) add(o Costs) {
+	c.Revenue += o.Revenue
+	c.OnSiteMinutes += o.OnSiteMinutes
+	c.DriveMinutes += o.DriveMinutes
+	c.LaborCost += o.LaborCost
+	c.VehicleCost += o.VehicleCost
+	c.ChemicalCost += o.ChemicalCost
+}
+
+// settle rounds the components and derives the totals from them.
+func (c *Costs) settle() {
+	cents := func(f float64) float64 { return math.Round(f*100) / 100 }
+	c.Revenue, c.LaborCost, c.VehicleCost, c.ChemicalCost = cents(c.Revenue), cents(c.LaborCost), cents(c.VehicleCost), cents(c.ChemicalCost)
+	c.OnSiteMinutes, c.DriveMinutes = math.Round(c.OnSiteMinutes*10)/10, math.Round(c.DriveMinutes*10)/10
+	c.Cost = cents(c.LaborCost + c.VehicleCost + c.ChemicalCost)
+	c.Profit = cents(c.Revenue - c.Cost)
+	c.Margin = nil
+	if c.Revenue > 0 {
+		m := math.Round(c.Profit/c.Revenue*1000) / 1000
+		c.Margin = &m
+	}
+}
+
+// Job is a job's profitability.
+type Job struct {
+	JobID        string `json:"jobId"`
+	TechnicianID string `json:"technicianId"`
+	CustomerName string `json:"customerName,omitempty"`
+	ServiceDate  string `json:"serviceDate"`
+	Status       string `json:"status"`
+	Costs
+	Chemicals []Chemical `json:"chemicals"`
+	// Missing lists the inputs that were not recorded, and so count as
+	// zero.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Chemical is a product applied on a job.
+type Chemical struct {
+	Name          string   `json:"name"`
+	Quantity      float64  `json:"quantity"`
+	UnitOfMeasure string   `json:"unitOfMeasure,omitempty"`
+	UnitCost      *float64 `json:"unitCost,omitempty"` // absent when unpriced
+	Cost          float64  `json:"cost"`
+}
+
+// Route is the profitability of a technician's jobs on a service date.
+type Route struct {
+	TechnicianID string `json:"technicianId"`
+	ServiceDate  string `json:"serviceDate"`
+	RouteID      string `json:"routeId,omitempty"`
+	Totals       Costs  `json:"totals"`
+	Jobs         []Job  `json:"jobs"`
+	// Incomplete counts the jobs missing inputs.
+	Incomplete int `json:"incomplete"`
+}
+
+// Day is the profitability of every route on a service date.
+type Day struct {
+	ServiceDate string  `json:"serviceDate"`
+	Currency    string  `json:"currency"`
+	Totals      Costs   `json:"totals"`
+	Routes      []Route `json:"routes"`
+}
+
+// Service computes job and route profitability.
+type Service struct {
+	cfg       config.ProfitConfig
+	store     Store
+	repos     repository.Repository
+	durations *schedule.Service
+	distances routing.DistanceProvider
+	prices    *inventory.Service
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService wires a profitability service. On-site times come from
+// durations, drive times between stops from distances and chemical prices
+// from prices; when one is nil, what it would provide is reported missing.
+func NewService(cfg config.ProfitConfig, store Store, repos repository.Repository, durations *schedule.Service, distances routing.DistanceProvider, prices *inventory.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, durations: durations, distances: distances, prices: prices, logger: logger, now: time.Now}
+}
+
+// Job returns a job's profitability.
+func (s *Service) Job(ctx context.Context, jobID string) (Job, error) {
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return Job{}, err
+	}
+	var job models.JobUpload
+	for _, j := range jobs {
+		if j.ID == jobID {
+			job = j
+		}
+	}
+	if job.ID == "" {
+		return Job{}, fmt.Errorf("%w: job %q", ErrNotFound, jobID)
+	}
+	// The drive to a job depends on the stops before it, so the whole
+	// route is costed.
+	date := job.ScheduledDate.Format(dateLayout)
+	in, err := s.load(func(j models.JobUpload) bool {
+		return j.TechnicianID == job.TechnicianID && j.ScheduledDate.Format(dateLayout) == date
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	for _, j := range s.route(ctx, in, job.TechnicianID, job.ScheduledDate, in.jobs).Jobs {
+		if j.JobID == jobID {
+			return j, nil
+		}
+	}
+	return Job{}, fmt.Errorf("%w: job %q", ErrNotFound, jobID)
+}
+
+// Route returns the profitability of a technician's jobs on a date.
+func (s *Service) Route(ctx context.Context, technicianID string, date time.Time) (Route, error) {
+	day := date.Format(dateLayout)
+	in, err := s.load(func(j models.JobUpload) bool {
+		return j.TechnicianID == technicianID && j.ScheduledDate.Format(dateLayout) == day
+	})
+	if err != nil {
+		return Route{}, err
+	}
+	if len(in.jobs) == 0 {
+		return Route{}, fmt.Errorf("%w: no jobs for %s on %s", ErrNotFound, technicianID, day)
+	}
+	return s.route(ctx, in, technicianID, date, in.jobs), nil
+}
+
+// Day returns the profitability of every technician's jobs on a date.
+func (s *Service) Day(ctx context.Context, date time.Time) (Day, error) {
+	day := date.Format(dateLayout)
+	in, err := s.load(func(j models.JobUpload) bool { return j.ScheduledDate.Format(dateLayout) == day })
+	if err != nil {
+		return Day{}, err
+	}
+	out := Day{ServiceDate: day, Currency: s.cfg.Currency, Routes: []Route{}}
+	for _, group := range byRoute(in.jobs) {
+		r := s.route(ctx, in, group[0].TechnicianID, date, group)
+		out.Totals.add(r.Totals)
+		out.Routes = append(out.Routes, r)
+	}
+	out.Totals.settle()
+	return out, nil
+}
+
+// Month returns the profitability of every job scheduled in the month of
+// t, by service date and technician.
+func (s *Service) Month(ctx context.Context, t time.Time) ([]Job, error) {
+	month := t.Format("2006-01")
+	in, err := s.load(func(j models.JobUpload) bool { return j.ScheduledDate.Format("2006-01") == month })
+	if err != nil {
+		return nil, err
+	}
+	out := []Job{}
+	for _, group := range byRoute(in.jobs) {
+		out = append(out, s.route(ctx, in, group[0].TechnicianID, group[0].ScheduledDate, group).Jobs...)
+	}
+	return out, nil
+}
+
+// byRoute groups jobs by service date and technician, in that order.
+func byRoute(jobs []models.JobUpload) [][]models.JobUpload {
+	groups := make(map[string][]models.JobUpload)
+	var keys []string
+	for _, j := range jobs {
+		key := j.ScheduledDate.Format(dateLayout) + "\x00" + j.TechnicianID
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], j)
+	}
+	sort.Strings(keys)
+	out := make([][]models.JobUpload, len(keys))
+	for i, k := range keys {
+		out[i] = groups[k]
+	}
+	return out
+}
+
+// inputs is what costing a set of jobs needs, loaded once.
+type inputs struct {
+	jobs       []models.JobUpload
+	treatments map[string][]models.ChemicalTreatmentUpload // by job
+	chemicals  map[string]models.ChemicalUpload
+	prices     inventory.PriceBook
+	invoices   map[string]Invoice
+	rates      map[string]float64
+}
+
+// load gathers the inputs of the jobs keep selects.
+func (s *Service) load(keep func(models.JobUpload) bool) (inputs, error) {
+	all, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return inputs{}, err
+	}
+	in := inputs{treatments: make(map[string][]models.ChemicalTreatmentUpload), chemicals: make(map[string]models.ChemicalUpload), rates: make(map[string]float64)}
+	var ids []string
+	wanted := make(map[string]bool)
+	for _, j := range all {
+		if keep(j) {
+			in.jobs = append(in.jobs, j)
+			ids = append(ids, j.ID)
+			wanted[j.ID] = true
+		}
+	}
+	if len(in.jobs) == 0 {
+		return in, nil
+	}
+
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return inputs{}, err
+	}
+	seen := make(map[string]int)
+	for _, t := range treatments {
+		if !wanted[t.JobID] {
+			continue
+		}
+		// Later versions of a treatment replace earlier ones.
+		if i, ok := seen[t.ID]; ok {
+			in.treatments[t.JobID][i] = t
+			continue
+		}
+		seen[t.ID] = len(in.treatments[t.JobID])
+		in.treatments[t.JobID] = append(in.treatments[t.JobID], t)
+	}
+	// Deleted chemicals are listed too, so old treatments are still costed.
+	chemicals, err := s.repos.Sync.ListPendingChemicals(0)
+	if err != nil {
+		return inputs{}, err
+	}
+	for _, c := range chemicals {
+		in.chemicals[c.ID] = c
+	}
+	if s.prices != nil {
+		if in.prices, err = s.prices.PriceBook(); err != nil {
+			return inputs{}, err
+		}
+	}
+	if in.invoices, err = s.store.ListInvoices(ids); err != nil {
+		return inputs{}, err
+	}
+	rates, err := s.store.ListRates()
+	if err != nil {
+		return inputs{}, err
+	}
+	for _, r := range rates {
+		in.rates[r.TechnicianID] = r.HourlyRate
+	}
+	return in, nil
+}
+
+// route costs a technician's jobs on a date. Each job is charged the
+// drive from the stop before its own; the first stop's drive, from
+// wherever the day started, is not known.
+func (s *Service) route(ctx context.Context, in inputs, technicianID string, date time.Time, jobs []models.JobUpload) Route {
+	out := Route{TechnicianID: technicianID, ServiceDate: date.Format(dateLayout), Jobs: make([]Job, 0, len(jobs))}
+	var stops []models.RouteStop
+	var legs []*time.Duration
+	if route, err := s.repos.Routes.GetRoute(technicianID, date); err == nil {
+		out.RouteID = route.ServerID()
+		stops = route.CustomerStops
+		legs = s.legs(ctx, stops)
+	}
+	for _, j := range jobs {
+		var drive *time.Duration
+		for i, stop := range stops {
+			if strings.EqualFold(stop.CustomerName, j.CustomerName) || (j.Address != "" && strings.EqualFold(stop.Address, j.Address)) {
+				drive = legs[i]
+				break
+			}
+		}
+		job := s.job(in, j, drive)
+		if len(job.Missing) > 0 {
+			out.Incomplete++
+		}
+		out.Totals.add(job.Costs)
+		out.Jobs = append(out.Jobs, job)
+	}
+	out.Totals.settle()
+	return out
+}
+
+// legs returns the drive to each stop from the one before it; nil where a
+// stop or the one before it is not geocoded, or distances are unavailable.
+func (s *Service) legs(ctx context.Context, stops []models.RouteStop) []*time.Duration {
+	out := make([]*time.Duration, len(stops))
+	if len(stops) == 0 {
+		return out
+	}
+	zero := time.Duration(0)
+	out[0] = &zero
+	if s.distances == nil || len(stops) == 1 {
+		return out
+	}
+	points := make([]routing.Point, len(stops))
+	for i, st := range stops {
+		points[i] = routing.Point{Latitude: st.Latitude, Longitude: st.Longitude}
+	}
+	matrix, err := s.distances.Matrix(ctx, points)
+	if err != nil {
+		s.logger.Warn("profit: drive times unavailable", slog.String("provider", s.distances.Name()), slog.Any("error", err))
+		return out
+	}
+	for i := 1; i < len(stops); i++ {
+		if stops[i-1].HasCoordinates() && stops[i].HasCoordinates() {
+			leg := matrix[i-1][i]
+			out[i] = &leg
+		}
+	}
+	return out
+}
+
+// job costs a job; drive is nil when the drive to it is not known.
+func (s *Service) job(in inputs, j models.JobUpload, drive *time.Duration) Job {
+	out := Job{JobID: j.ID, TechnicianID: j.TechnicianID, CustomerName: j.CustomerName, ServiceDate: j.ScheduledDate.Format(dateLayout), Status: j.Status, Chemicals: []Chemical{}}
+	if inv, ok := in.invoices[j.ID]; ok {
+		out.Revenue = inv.Amount
+	} else {
+		out.Missing = append(out.Missing, MissingInvoice)
+	}
+	if o, err := s.observation(j.ID); err == nil {
+		out.OnSiteMinutes = o.Minutes
+	} else {
+		out.Missing = append(out.Missing, MissingOnSiteTime)
+	}
+	if drive != nil {
+		out.DriveMinutes = drive.Minutes()
+	} else {
+		out.Missing = append(out.Missing, MissingDriveTime)
+	}
+	rate, ok := in.rates[j.TechnicianID]
+	if !ok {
+		rate = s.cfg.LaborRate
+	}
+	out.LaborCost = (out.OnSiteMinutes + out.DriveMinutes) / 60 * rate
+	out.VehicleCost = out.DriveMinutes / 60 * s.cfg.VehicleRate
+
+	for _, t := range in.treatments[j.ID] {
+		c := in.chemicals[t.ChemicalID]
+		chem := Chemical{Name: c.Name, Quantity: t.QuantityUsed, UnitOfMeasure: c.UnitOfMeasure}
+		if chem.Name == "" {
+			chem.Name = t.ChemicalID
+		}
+		// Chemicals cost what the product did when it was applied.
+		at := t.ApplicationDate
+		if at.IsZero() {
+			at = j.ScheduledDate
+		}
+		product := inventory.Product{Name: c.Name, EPARegistration: c.EPARegistration}
+		if unit, ok := in.prices.Cost(product, c.UnitOfMeasure, at); ok {
+			chem.UnitCost = &unit
+			chem.Cost = math.Round(t.QuantityUsed*unit*100) / 100
+		}
+		if chem.UnitCost == nil {
+			out.Missing = append(out.Missing, MissingPrice+":"+chem.Name)
+		}
+		out.ChemicalCost += chem.Cost
+		out.Chemicals = append(out.Chemicals, chem)
+	}
+	out.settle()
+	return out
+}
+
+func (s *Service) observation(jobID string) (schedule.Observation, error) {
+	if s.durations == nil {
+		return schedule.Observation{}, schedule.ErrNotFound
+	}
+	return s.durations.Observation(jobID)
+}
+
+// SaveInvoices stores invoice amounts, replacing earlier ones of the same
+// jobs. Either all are stored or, when one is invalid, none.
+func (s *Service) SaveInvoices(invoices ...Invoice) ([]Invoice, error) {
+	if len(invoices) == 0 {
+		return nil, fmt.Errorf("%w: no invoices", ErrInvalidInput)
+	}
+	now := s.now().UTC()
+	for i := range invoices {
+		if err := invoices[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invoice %d: %w", i, err)
+		}
+		invoices[i].UpdatedAt = now
+	}
+	if err := s.store.SaveInvoices(invoices...); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// Invoice returns a job's invoice.
+func (s *Service) Invoice(jobID string) (Invoice, error) {
+	invoices, err := s.store.ListInvoices([]string{jobID})
+	if err != nil {
+		return Invoice{}, err
+	}
+	inv, ok := invoices[jobID]
+	if !ok {
+		return Invoice{}, ErrNotFound
+	}
+	return inv, nil
+}
+
+// MoveInvoice reassigns the invoice of job from to job to, which must not
+// have one of its own, and reports whether from had an invoice to move.
+func (s *Service) MoveInvoice(from, to string) (bool, error) {
+	invoices, err := s.store.ListInvoices([]string{from, to})
+	if err != nil {
+		return false, err
+	}
+	inv, ok := invoices[from]
+	if !ok {
+		return false, nil
+	}
+	if _, taken := invoices[to]; taken {
+		return false, fmt.Errorf("%w: job %s already has an invoice", ErrInvalidInput, to)
+	}
+	inv.JobID = to
+	if err := s.store.SaveInvoices(inv); err != nil {
+		return false, err
+	}
+	return true, s.store.DeleteInvoice(from)
+}
+
+// Rates returns the technicians' labor rates.
+func (s *Service) Rates() ([]Rate, error) {
+	return s.store.ListRates()
+}
+
+// SetRate stores a technician's labor rate.
+func (s *Service) SetRate(r Rate) (Rate, error) {
+	if r.TechnicianID == "" || !validAmount(r.HourlyRate) {
+		return Rate{}, fmt.Errorf("%w: technicianId and an hourlyRate >= 0 are required", ErrInvalidInput)
+	}
+	if _, err := s.repos.Technicians.GetByID(r.TechnicianID); err != nil {
+		return Rate{}, fmt.Errorf("%w: technician %q", ErrNotFound, r.TechnicianID)
+	}
+	r.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveRate(r); err != nil {
+		return Rate{}, err
+	}
+	return r, nil
+}
+
+// DeleteRate returns a technician to the configured labor rate.
+func (s *Service) DeleteRate(technicianID string) error {
+	if err := s.store.DeleteRate(technicianID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: no rate for %q", ErrNotFound, technicianID)
+		}
+		return err
+	}
+	return nil
+}
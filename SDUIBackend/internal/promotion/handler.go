@@ -0,0 +1,97 @@
+package promotion
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+)
+
+// Handler exposes promotion bundles to admins and the promote command.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a promotion handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/bundle", h.ExportBundle)
+	r.Post("/diff", h.DiffBundle)
+	r.Post("/import", h.ImportBundle)
+}
+
+// ExportBundle returns the environment's signed bundle.
+func (h *Handler) ExportBundle(w http.ResponseWriter, r *http.Request) {
+	b, err := h.service.Export()
+	if err != nil {
+		h.fail(w, r, "failed to export bundle", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, b)
+}
+
+// DiffBundle reports what importing the bundle in the body would change.
+func (h *Handler) DiffBundle(w http.ResponseWriter, r *http.Request) {
+	b, ok := decodeBundle(w, r)
+	if !ok {
+		return
+	}
+	diff, err := h.service.Diff(b)
+	if err != nil {
+		h.fail(w, r, "failed to diff bundle", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, diff)
+}
+
+// ImportBundle applies the bundle in the body, all or nothing, and returns
+// the changes made.
+func (h *Handler) ImportBundle(w http.ResponseWriter, r *http.Request) {
+	b, ok := decodeBundle(w, r)
+	if !ok {
+		return
+	}
+	diff, err := h.service.Import(b)
+	if err != nil {
+		h.fail(w, r, "failed to import bundle", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, diff)
+}
+
+func decodeBundle(w http.ResponseWriter, r *http.Request) (Bundle, bool) {
+	var b Bundle
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return Bundle{}, false
+	}
+	return b, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrBadSignature):
+		respond.Error(w, http.StatusForbidden, title, err.Error())
+	case errors.Is(err, ErrInvalidBundle):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, sdui.ErrInvalidTemplate), errors.Is(err, experiment.ErrInvalidExperiment):
+		// The bundle is sound but this environment refuses part of it,
+		// such as a template missing a locale it requires.
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,156 @@
+package promotion
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+// keys serves secrets from a map.
+type keys map[string]string
+
+func (k keys) Get(name string) (string, error) {
+	v, ok := k[name]
+	if !ok {
+		return "", errors.New("no such secret")
+	}
+	return v, nil
+}
+
+var signingKey = keys{"promotion-key": strings.Repeat("k", 32)}
+
+type env struct {
+	service     *Service
+	screens     *sdui.Service
+	experiments *experiment.Service
+}
+
+func newEnv(t *testing.T, name config.Environment, provider flags.Provider) env {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	screens := sdui.NewService("", config.ScreenConfig{UnresolvedPlaceholders: sdui.UnresolvedKeep}, repos, brownout.NewMonitor(config.BrownoutConfig{}), time.Minute, nil, nil, nil, nil)
+	experiments := experiment.NewService(experiment.NewMemoryStore(), repos, nil)
+	svc := NewService(config.PromotionConfig{SigningKeySecret: "promotion-key"}, name, signingKey, screens, experiments, provider, nil)
+	return env{service: svc, screens: screens, experiments: experiments}
+}
+
+func text(s string) string {
+	return `{"version":1,"component":{"type":"text","text":"` + s + `"}}`
+}
+
+func publish(t *testing.T, e env, screenID string, payloads ...string) {
+	t.Helper()
+	for _, p := range payloads {
+		var err error
+		if _, lookErr := e.screens.Template(screenID, 0); errors.Is(lookErr, sdui.ErrTemplateNotFound) {
+			_, err = e.screens.CreateTemplate(screenID, []byte(p))
+		} else {
+			_, err = e.screens.UpdateTemplate(screenID, []byte(p))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPromote(t *testing.T) {
+	file, err := flags.NewStaticProvider(map[string]flags.Flag{"newHome": {Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := newEnv(t, config.EnvDev, file)
+	publish(t, dev, "home", text("one"), text("two"), text("three"))
+	if _, err := dev.experiments.Save("home-cta", experiment.Experiment{ScreenID: "home", Status: experiment.StatusRunning, Rollout: 50,
+		Variants: []experiment.Variant{{Name: "control", Weight: 1}, {Name: "two", Version: 2, Weight: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	// Prod numbers versions differently and already has dev's version 2.
+	prod := newEnv(t, config.EnvProd, nil)
+	publish(t, prod, "home", text("a"), text("b"), text("two"))
+	publish(t, prod, "legacy", text("legacy"))
+
+	bundle, err := dev.service.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Templates) != 2 || bundle.Templates[0].Version != 2 || !bundle.Templates[1].Latest || bundle.Flags["newHome"].DefaultVariant != "off" {
+		t.Fatalf("expected the latest version and the one experimented on, got %+v", bundle)
+	}
+
+	diff, err := prod.service.Diff(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 3 || diff.Unchanged != 1 || len(diff.Kept) != 1 || diff.Kept[0].ID != "legacy" || diff.Applied {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+	if c := diff.Changes[0]; c.Kind != KindTemplate || c.Action != ActionUpdate || c.Detail != "dev version 3 as version 4" {
+		t.Errorf("expected the latest version published after prod's, got %+v", c)
+	}
+	if c := diff.Changes[2]; c.Kind != KindFlag || !c.Manual {
+		t.Errorf("expected flags left to the operator, got %+v", c)
+	}
+
+	if _, err := prod.service.Import(bundle); err != nil {
+		t.Fatal(err)
+	}
+	if latest, _ := prod.screens.Template("home", 0); latest.Version != 4 || !sameJSON(latest.Screen, []byte(text("three"))) {
+		t.Errorf("expected dev's latest served, got %+v", latest)
+	}
+	if e, err := prod.experiments.Experiment("home-cta"); err != nil || e.Variants[1].Version != 3 {
+		t.Errorf("expected the experiment to serve prod's copy of version 2, got %+v (%v)", e, err)
+	}
+	if diff, err := prod.service.Diff(bundle); err != nil || len(diff.Changes) != 1 || diff.Changes[0].Kind != KindFlag {
+		t.Errorf("expected nothing left to import, got %+v (%v)", diff, err)
+	}
+
+	bundle.Templates[1].Screen = []byte(text("tampered"))
+	if _, err := prod.service.Import(bundle); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("expected a changed bundle refused, got %v", err)
+	}
+}
+
+func TestImportIsAllOrNothing(t *testing.T) {
+	prod := newEnv(t, config.EnvProd, nil)
+	bundle := Bundle{Format: Format, Environment: "dev", Templates: []Template{
+		{ScreenID: "alpha", Version: 1, Latest: true, Screen: []byte(text("alpha"))},
+		{ScreenID: "beta", Version: 1, Latest: true, Screen: []byte(`{"version":1,"component":{"type":"text","bogus":true}}`)},
+	}}
+	var err error
+	if bundle.Signature, err = bundle.sign([]byte(signingKey["promotion-key"])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prod.service.Import(bundle); !errors.Is(err, sdui.ErrInvalidTemplate) {
+		t.Fatalf("expected the invalid template refused, got %v", err)
+	}
+	if _, err := prod.screens.Template("alpha", 0); !errors.Is(err, sdui.ErrTemplateNotFound) {
+		t.Errorf("expected the screen published before the failure removed, got %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Route("/promotion", NewHandler(prod.service).Routes)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/promotion/import", strings.NewReader(`{"format":1,"signature":"hmac-sha256:00"}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected an unsigned bundle forbidden, got %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/promotion/diff", strings.NewReader(`{"format":1,`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed bundle rejected, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,493 @@
+package promotion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// minKeyLen is the shortest signing key accepted, as for HMAC-signed JWTs.
+const minKeyLen = 32
+
+// Change kinds and actions.
+const (
+	KindTemplate   = "template"
+	KindExperiment = "experiment"
+	KindFlag       = "flag"
+
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionKeep   = "keep"
+)
+
+// Change is a difference between a bundle and the importing environment.
+type Change struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+	// Manual is set on changes imports leave to the operator: flags ship
+	// with each environment's flag file.
+	Manual bool `json:"manual,omitempty"`
+}
+
+// Diff is what importing a bundle changes.
+type Diff struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Changes   []Change `json:"changes"`
+	Unchanged int      `json:"unchanged"`
+	// Kept lists what the target has and the bundle lacks. Imports create
+	// and update; they never delete.
+	Kept []Change `json:"kept"`
+	// Applied is set when the changes were imported.
+	Applied bool `json:"applied"`
+}
+
+// Service exports, diffs and imports bundles.
+type Service struct {
+	cfg         config.PromotionConfig
+	env         config.Environment
+	secrets     secret.Provider
+	screens     *sdui.Service
+	experiments *experiment.Service
+	flags       *flags.FileProvider
+	logger      *slog.Logger
+	now         func() time.Time
+	// importMu serializes imports, so each plans against the state the
+	// previous one left.
+	importMu sync.Mutex
+}
+
+// NewService wires a promotion service. Flags are promoted only between
+// environments whose provider is the file provider.
+func NewService(cfg config.PromotionConfig, env config.Environment, secrets secret.Provider, screens *sdui.Service, experiments *experiment.Service, provider flags.Provider, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	file, _ := provider.(*flags.FileProvider)
+	return &Service{cfg: cfg, env: env, secrets: secrets, screens: screens, experiments: experiments, flags: file, logger: logger, now: time.Now}
+}
+
+// Export returns the environment's bundle, signed.
+func (s *Service) Export() (Bundle, error) {
+	key, err := s.key()
+	if err != nil {
+		return Bundle{}, err
+	}
+	exps, err := s.experiments.Experiments("")
+	if err != nil {
+		return Bundle{}, err
+	}
+	served := make(map[string][]int)
+	for _, e := range exps {
+		for _, v := range e.Variants {
+			if v.Version != 0 {
+				served[e.ScreenID] = append(served[e.ScreenID], v.Version)
+			}
+		}
+	}
+	summaries, err := s.screens.Templates()
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	b := Bundle{Format: Format, Environment: string(s.env), ExportedAt: s.now().UTC(), Templates: []Template{}, Experiments: exps}
+	for _, sum := range summaries {
+		latest, err := s.screens.Template(sum.ScreenID, 0)
+		if err != nil {
+			return Bundle{}, fmt.Errorf("screen %s: %w", sum.ScreenID, err)
+		}
+		versions := served[sum.ScreenID]
+		sort.Ints(versions)
+		for i, v := range versions {
+			if v == latest.Version || (i > 0 && v == versions[i-1]) {
+				continue
+			}
+			tpl, err := s.screens.Template(sum.ScreenID, v)
+			if err != nil {
+				return Bundle{}, fmt.Errorf("screen %s version %d: %w", sum.ScreenID, v, err)
+			}
+			b.Templates = append(b.Templates, Template{ScreenID: tpl.ScreenID, Version: tpl.Version, Screen: tpl.Screen})
+		}
+		b.Templates = append(b.Templates, Template{ScreenID: latest.ScreenID, Version: latest.Version, Latest: true, Screen: latest.Screen})
+	}
+	if s.flags != nil {
+		b.Flags = s.flags.Flags()
+	}
+	if b.Signature, err = b.sign(key); err != nil {
+		return Bundle{}, err
+	}
+	return b, nil
+}
+
+// Diff verifies a bundle and reports what importing it would change.
+func (s *Service) Diff(b Bundle) (Diff, error) {
+	if err := s.verify(b); err != nil {
+		return Diff{}, err
+	}
+	p, err := s.plan(b)
+	if err != nil {
+		return Diff{}, err
+	}
+	return p.diff, nil
+}
+
+// Import verifies a bundle and applies its changes. Imports are all or
+// nothing: when a template or experiment is refused, the changes already
+// made are undone and the error returned.
+func (s *Service) Import(b Bundle) (Diff, error) {
+	if err := s.verify(b); err != nil {
+		return Diff{}, err
+	}
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	p, err := s.plan(b)
+	if err != nil {
+		return Diff{}, err
+	}
+	var undo []func() error
+	if err := s.apply(p, &undo); err != nil {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if uerr := undo[i](); uerr != nil {
+				s.logger.Error("failed to undo promotion change", slog.String("source", b.Environment), slog.Any("error", uerr))
+			}
+		}
+		return Diff{}, err
+	}
+	s.logger.Info("bundle imported", slog.String("source", b.Environment), slog.Int("changes", len(p.diff.Changes)))
+	p.diff.Applied = true
+	return p.diff, nil
+}
+
+// plan is a diff and the steps applying it.
+type plan struct {
+	diff Diff
+	// publish lists template versions to publish, in order; create marks
+	// the first version of a screen new to the target.
+	publish []publishStep
+	// versions maps each screen's bundle versions to the target's,
+	// including those publish is predicted to create.
+	versions    map[string]map[int]int
+	experiments []experiment.Experiment
+}
+
+type publishStep struct {
+	tpl    Template
+	create bool
+}
+
+// plan compares a bundle with the environment.
+func (s *Service) plan(b Bundle) (plan, error) {
+	p := plan{diff: Diff{Source: b.Environment, Target: string(s.env), Changes: []Change{}, Kept: []Change{}}, versions: make(map[string]map[int]int)}
+	if err := s.planTemplates(b, &p); err != nil {
+		return plan{}, err
+	}
+	if err := s.planExperiments(b, &p); err != nil {
+		return plan{}, err
+	}
+	s.planFlags(b, &p)
+	return p, nil
+}
+
+func (s *Service) planTemplates(b Bundle, p *plan) error {
+	byScreen := make(map[string][]Template)
+	for _, t := range b.Templates {
+		byScreen[t.ScreenID] = append(byScreen[t.ScreenID], t)
+	}
+	ids := make([]string, 0, len(byScreen))
+	for id := range byScreen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		target, err := s.targetVersions(id)
+		if err != nil {
+			return err
+		}
+		next := 1
+		if len(target) > 0 {
+			next = target[len(target)-1].Version + 1
+		}
+		mapped := make(map[int]int)
+		p.versions[id] = mapped
+		publish := func(t Template) {
+			create := len(target) == 0 && next == 1
+			action := ActionUpdate
+			if create {
+				action = ActionCreate
+			}
+			p.publish = append(p.publish, publishStep{tpl: t, create: create})
+			p.diff.Changes = append(p.diff.Changes, Change{Kind: KindTemplate, ID: id, Action: action, Detail: fmt.Sprintf("%s version %d as version %d", b.Environment, t.Version, next)})
+			mapped[t.Version] = next
+			next++
+		}
+
+		// Older versions are only needed by experiments; ones the target
+		// already has are reused under its numbering.
+		versions := byScreen[id]
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+		var latest Template
+		published := false
+		for _, t := range versions {
+			if t.Latest {
+				latest = t
+				continue
+			}
+			if v, ok := findVersion(target, t.Screen); ok {
+				mapped[t.Version] = v
+				p.diff.Unchanged++
+				continue
+			}
+			publish(t)
+			published = true
+		}
+		// The latest version must end up the target's latest, which
+		// publishing older ones displaces.
+		if !published && len(target) > 0 && sameJSON(target[len(target)-1].Screen, latest.Screen) {
+			mapped[latest.Version] = target[len(target)-1].Version
+			p.diff.Unchanged++
+			continue
+		}
+		publish(latest)
+	}
+
+	summaries, err := s.screens.Templates()
+	if err != nil {
+		return err
+	}
+	for _, sum := range summaries {
+		if _, ok := byScreen[sum.ScreenID]; !ok {
+			p.diff.Kept = append(p.diff.Kept, Change{Kind: KindTemplate, ID: sum.ScreenID, Action: ActionKeep})
+		}
+	}
+	return nil
+}
+
+// targetVersions returns a screen's published versions, oldest first, with
+// their payloads.
+func (s *Service) targetVersions(screenID string) ([]sdui.TemplateVersion, error) {
+	versions, err := s.screens.TemplateVersions(screenID)
+	if errors.Is(err, sdui.ErrTemplateNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range versions {
+		tpl, err := s.screens.Template(screenID, v.Version)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = tpl
+	}
+	return versions, nil
+}
+
+func findVersion(versions []sdui.TemplateVersion, screen json.RawMessage) (int, bool) {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if sameJSON(versions[i].Screen, screen) {
+			return versions[i].Version, true
+		}
+	}
+	return 0, false
+}
+
+func sameJSON(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+func (s *Service) planExperiments(b Bundle, p *plan) error {
+	inBundle := make(map[string]bool)
+	for _, e := range b.Experiments {
+		inBundle[e.ID] = true
+		mapped, err := p.mapVariants(e)
+		if err != nil {
+			return err
+		}
+		current, err := s.experiments.Experiment(e.ID)
+		switch {
+		case errors.Is(err, experiment.ErrNotFound):
+			p.diff.Changes = append(p.diff.Changes, Change{Kind: KindExperiment, ID: e.ID, Action: ActionCreate, Detail: experimentDetail(e)})
+		case err != nil:
+			return err
+		case sameExperiment(current, mapped):
+			p.diff.Unchanged++
+			continue
+		default:
+			p.diff.Changes = append(p.diff.Changes, Change{Kind: KindExperiment, ID: e.ID, Action: ActionUpdate, Detail: experimentDetail(e)})
+		}
+		p.experiments = append(p.experiments, e)
+	}
+	// A screen runs one experiment at a time: stop experiments before
+	// starting others.
+	sort.SliceStable(p.experiments, func(i, j int) bool {
+		return p.experiments[i].Status != experiment.StatusRunning && p.experiments[j].Status == experiment.StatusRunning
+	})
+
+	all, err := s.experiments.Experiments("")
+	if err != nil {
+		return err
+	}
+	for _, e := range all {
+		if !inBundle[e.ID] {
+			p.diff.Kept = append(p.diff.Kept, Change{Kind: KindExperiment, ID: e.ID, Action: ActionKeep})
+		}
+	}
+	return nil
+}
+
+// mapVariants returns e with its variants serving the target's versions.
+func (p *plan) mapVariants(e experiment.Experiment) (experiment.Experiment, error) {
+	variants := make([]experiment.Variant, len(e.Variants))
+	for i, v := range e.Variants {
+		if v.Version != 0 {
+			target, ok := p.versions[e.ScreenID][v.Version]
+			if !ok {
+				return experiment.Experiment{}, fmt.Errorf("%w: experiment %s serves version %d of screen %s, which the bundle lacks", ErrInvalidBundle, e.ID, v.Version, e.ScreenID)
+			}
+			v.Version = target
+		}
+		variants[i] = v
+	}
+	e.Variants = variants
+	return e, nil
+}
+
+func sameExperiment(a, b experiment.Experiment) bool {
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func experimentDetail(e experiment.Experiment) string {
+	return fmt.Sprintf("%s on screen %s, %d%% rollout", e.Status, e.ScreenID, e.Rollout)
+}
+
+// planFlags reports flag differences. Flags are served from the file the
+// environment was deployed with, so they are changed by hand.
+func (s *Service) planFlags(b Bundle, p *plan) {
+	if b.Flags == nil {
+		return
+	}
+	var target map[string]flags.Flag
+	if s.flags != nil {
+		target = s.flags.Flags()
+	}
+	detail := "update FLAGS_FILE and redeploy"
+	if s.flags == nil {
+		detail = "the target does not use the file flag provider"
+	}
+	keys := make([]string, 0, len(b.Flags))
+	for key := range b.Flags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		current, ok := target[key]
+		switch {
+		case !ok:
+			p.diff.Changes = append(p.diff.Changes, Change{Kind: KindFlag, ID: key, Action: ActionCreate, Detail: detail, Manual: true})
+		case sameFlag(current, b.Flags[key]):
+			p.diff.Unchanged++
+		default:
+			p.diff.Changes = append(p.diff.Changes, Change{Kind: KindFlag, ID: key, Action: ActionUpdate, Detail: detail, Manual: true})
+		}
+	}
+	var kept []string
+	for key := range target {
+		if _, ok := b.Flags[key]; !ok {
+			kept = append(kept, key)
+		}
+	}
+	sort.Strings(kept)
+	for _, key := range kept {
+		p.diff.Kept = append(p.diff.Kept, Change{Kind: KindFlag, ID: key, Action: ActionKeep})
+	}
+}
+
+func sameFlag(a, b flags.Flag) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// apply makes a plan's changes, recording how to undo each.
+func (s *Service) apply(p plan, undo *[]func() error) error {
+	for _, step := range p.publish {
+		t := step.tpl
+		publish := s.screens.UpdateTemplate
+		if step.create {
+			publish = s.screens.CreateTemplate
+		}
+		saved, err := publish(t.ScreenID, t.Screen)
+		if err != nil {
+			return fmt.Errorf("screen %s version %d: %w", t.ScreenID, t.Version, err)
+		}
+		*undo = append(*undo, func() error { return s.screens.DeleteTemplate(saved.ScreenID, saved.Version) })
+		// Another admin may have published since the plan was made.
+		p.versions[t.ScreenID][t.Version] = saved.Version
+	}
+	for _, e := range p.experiments {
+		mapped, err := p.mapVariants(e)
+		if err != nil {
+			return err
+		}
+		previous, err := s.experiments.Experiment(e.ID)
+		existed := err == nil
+		if err != nil && !errors.Is(err, experiment.ErrNotFound) {
+			return err
+		}
+		if _, err := s.experiments.Save(e.ID, mapped); err != nil {
+			return fmt.Errorf("experiment %s: %w", e.ID, err)
+		}
+		id := e.ID
+		*undo = append(*undo, func() error {
+			if !existed {
+				return s.experiments.Delete(id)
+			}
+			_, err := s.experiments.Save(id, previous)
+			return err
+		})
+	}
+	return nil
+}
+
+func (s *Service) verify(b Bundle) error {
+	key, err := s.key()
+	if err != nil {
+		return err
+	}
+	return b.verify(key)
+}
+
+func (s *Service) key() ([]byte, error) {
+	key, err := s.secrets.Get(s.cfg.SigningKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("promotion signing key: %w", err)
+	}
+	if len(key) < minKeyLen {
+		return nil, fmt.Errorf("promotion signing key must be at least %d bytes", minKeyLen)
+	}
+	return []byte(key), nil
+}
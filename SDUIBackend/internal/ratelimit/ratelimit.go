@@ -0,0 +1,173 @@
+// Package ratelimit throttles the device API so a device stuck in a retry
+// storm cannot starve everyone else. Each device, or each client IP for
+// requests without an authenticated technician, has a token bucket for
+// reads and another for writes; a request that finds its bucket empty is
+// refused with 429 and told when a token will be available.
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Classes of request, each with its own buckets.
+const (
+	Read  = "read"
+	Write = "write"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped.
+const sweepInterval = time.Minute
+
+// Limiter holds the token buckets. A nil Limiter allows everything, so
+// rate limiting can be turned off without nil checks.
+type Limiter struct {
+	limits map[string]limit
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+
+	limited atomic.Int64
+}
+
+// limit is a bucket's refill rate, in tokens per second, and capacity.
+type limit struct {
+	rate  float64
+	burst float64
+}
+
+type bucketKey struct {
+	class string
+	key   string
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time // when tokens was last brought up to date
+}
+
+// New returns a limiter for cfg, or nil when rate limiting is disabled.
+func New(cfg config.RateLimitConfig) *Limiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Limiter{
+		limits: map[string]limit{
+			Read:  {rate: float64(cfg.ReadPerMinute) / 60, burst: float64(cfg.ReadBurst)},
+			Write: {rate: float64(cfg.WritePerMinute) / 60, burst: float64(cfg.WriteBurst)},
+		},
+		now:     time.Now,
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+// Allow takes a token from key's bucket for class. It returns whether the
+// request may proceed, the tokens left, and, when refused, how long until a
+// token is available.
+func (l *Limiter) Allow(class, key string) (bool, int, time.Duration) {
+	lim := l.limits[class]
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+	k := bucketKey{class: class, key: key}
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{tokens: lim.burst, at: now}
+		l.buckets[k] = b
+	}
+	b.tokens = math.Min(lim.burst, b.tokens+now.Sub(b.at).Seconds()*lim.rate)
+	b.at = now
+	if b.tokens < 1 {
+		l.limited.Add(1)
+		wait := time.Duration((1 - b.tokens) / lim.rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// sweep drops buckets that would be full by now; a new bucket starts full,
+// so forgetting them changes nothing.
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		lim := l.limits[k.class]
+		if b.tokens+now.Sub(b.at).Seconds()*lim.rate >= lim.burst {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Limited returns how many requests have been refused since the server
+// started.
+func (l *Limiter) Limited() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.limited.Load()
+}
+
+// Middleware limits requests by the caller's device, or by client IP when
+// the request has no authenticated technician; it must run after authentication. GET and
+// HEAD requests are reads, everything else writes.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := Write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			class = Read
+		}
+		allowed, remaining, wait := l.Allow(class, callerKey(r))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(int(l.limits[class].burst)))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			respond.Error(w, http.StatusTooManyRequests, "rate limit exceeded", "too many "+class+" requests; retry after the Retry-After delay", respond.WithRetryAfter(wait))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerKey identifies who a request counts against. An authenticated
+// request counts against its device: each device signs in on its own, so
+// the session its token names tells it apart from the technician's other
+// devices, and a device stuck retrying cannot use up their allowance.
+// The session outlives token refreshes, so refreshing does not reset a
+// device's allowance. Tokens naming no session count against their
+// subject. Other requests count against the client IP resolved by
+// middleware.RealIP, which only believes forwarding headers from trusted
+// proxies.
+func callerKey(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok && id.Subject != "" {
+		if id.Session != "" {
+			return "device:" + id.Subject + ":" + id.Session
+		}
+		return "subject:" + id.Subject
+	}
+	if c, ok := middleware.ClientFrom(r.Context()); ok {
+		return "ip:" + c.IP.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+func newLimiter(t *testing.T) (*Limiter, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	l := New(config.RateLimitConfig{Enabled: true, ReadPerMinute: 60, ReadBurst: 2, WritePerMinute: 30, WriteBurst: 1})
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestBucketsRefill(t *testing.T) {
+	l, now := newLimiter(t)
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.Allow(Read, "ip:10.0.0.1"); !ok {
+			t.Fatalf("expected read %d within the burst", i)
+		}
+	}
+	ok, _, wait := l.Allow(Read, "ip:10.0.0.1")
+	if ok || wait != time.Second {
+		t.Fatalf("expected the third read refused for a second, got %v %s", ok, wait)
+	}
+	if ok, _, _ := l.Allow(Write, "ip:10.0.0.1"); !ok {
+		t.Fatal("expected writes to have their own bucket")
+	}
+	if ok, _, _ := l.Allow(Read, "ip:10.0.0.2"); !ok {
+		t.Fatal("expected other callers to have their own bucket")
+	}
+
+	*now = now.Add(time.Second)
+	if ok, _, _ := l.Allow(Read, "ip:10.0.0.1"); !ok {
+		t.Fatal("expected a token refilled after a second")
+	}
+	if l.Limited() != 1 {
+		t.Fatalf("expected one refusal counted, got %d", l.Limited())
+	}
+
+	*now = now.Add(time.Hour)
+	l.Allow(Read, "ip:10.0.0.3")
+	if len(l.buckets) != 1 {
+		t.Fatalf("expected refilled buckets swept, %d left", len(l.buckets))
+	}
+}
+
+func TestMiddlewareKeysByDeviceThenIP(t *testing.T) {
+	l, _ := newLimiter(t)
+	h := middleware.RealIP([]string{"10.0.0.0/8"})(l.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	post := func(remoteAddr, forwardedFor, technician, session string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/jobs", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if technician != "" {
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: technician, Role: auth.RoleTechnician, Session: session}))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("10.0.0.1:1234", "", "tech-1", "phone"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first write allowed, got %d", rec.Code)
+	}
+	rec := post("10.0.0.2:1234", "", "tech-1", "phone")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected the device limited across IPs with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := post("10.0.0.2:1234", "", "tech-1", "tablet"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the technician's other device allowed, got %d", rec.Code)
+	}
+	if rec := post("10.0.0.1:1234", "", "tech-2", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first write allowed, got %d", rec.Code)
+	}
+	if rec := post("10.0.0.2:1234", "", "tech-2", ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected tokens without a session limited by subject, got %d", rec.Code)
+	}
+
+	if rec := post("10.0.0.1:5678", "198.51.100.7", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected an unauthenticated caller keyed by IP, got %d", rec.Code)
+	}
+	if rec := post("10.0.0.3:9999", "198.51.100.7", "", ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the forwarded client limited behind any proxy, got %d", rec.Code)
+	}
+	// An untrusted peer cannot pick a fresh address to be keyed by.
+	if rec := post("203.0.113.9:1234", "192.0.2.1", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first write allowed, got %d", rec.Code)
+	}
+	if rec := post("203.0.113.9:1234", "192.0.2.2", "", ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected an untrusted peer keyed by its own address, got %d", rec.Code)
+	}
+
+	if New(config.RateLimitConfig{}) != nil {
+		t.Fatal("expected no limiter when disabled")
+	}
+}
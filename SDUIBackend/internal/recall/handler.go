@@ -0,0 +1,45 @@
+package recall
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes recall tracing in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a recall handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/lots/{lotNumber}", h.TraceLot)
+}
+
+// TraceLot reports the trucks, treatments and customers touched by a lot,
+// optionally narrowed to one product by ?epaRegistration=.
+func (h *Handler) TraceLot(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.Trace(Query{LotNumber: chi.URLParam(r, "lotNumber"), EPARegistration: r.URL.Query().Get("epaRegistration")})
+	if err != nil {
+		if errors.Is(err, ErrInvalidQuery) {
+			respond.Error(w, http.StatusBadRequest, "invalid recall", err.Error())
+			return
+		}
+		middleware.LoggerFrom(r.Context()).Error("failed to trace lot", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to trace lot", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	middleware.LoggerFrom(r.Context()).Info("lot traced", slog.String("lot", report.Query.LotNumber), slog.Int("exposures", len(report.Exposures)), slog.Int("customers", len(report.Customers)))
+	respond.JSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,63 @@
+package recall
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func TestTraceFindsHoldingsTreatmentsAndCustomers(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	day := time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "a-termidor", TechnicianID: "tech-a", Name: "Termidor SC", EPARegistration: "7969-210",
+		Lots: []models.ChemicalLot{{Number: "L1", Quantity: 2, ReceivedAt: day}, {Number: "L2", Quantity: 1}}})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "b-termidor", TechnicianID: "tech-b", Name: "Termidor SC", EPARegistration: "7969-210",
+		Lots: []models.ChemicalLot{{Number: "L1", Quantity: 0}}})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "b-talstar", TechnicianID: "tech-b", Name: "Talstar P", EPARegistration: "279-3206",
+		Lots: []models.ChemicalLot{{Number: "L1", Quantity: 4}}})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", CustomerName: "Ada Lovelace", Address: "1 Main St"})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-2", CustomerName: "Ada Lovelace", Address: "1 Main St"})
+	for _, tr := range []models.ChemicalTreatmentUpload{
+		{ID: "t1", JobID: "job-1", ChemicalID: "a-termidor", LotNumber: "L1", TechnicianID: "tech-a", ApplicationDate: day},
+		{ID: "t2", JobID: "job-2", ChemicalID: "b-termidor", LotNumber: "L1", TechnicianID: "tech-b", ApplicationDate: day.Add(48 * time.Hour)},
+		{ID: "t3", JobID: "job-9", ChemicalID: "a-termidor", LotNumber: "L1", TechnicianID: "tech-a", ApplicationDate: day.Add(time.Hour)},
+		{ID: "t4", JobID: "job-1", ChemicalID: "a-termidor", LotNumber: "L2", TechnicianID: "tech-a", ApplicationDate: day},
+		{ID: "t5", JobID: "job-1", ChemicalID: "b-talstar", LotNumber: "L1", TechnicianID: "tech-b", ApplicationDate: day},
+	} {
+		_ = store.SaveChemicalTreatment(tr)
+	}
+
+	svc := NewService(repos)
+	if _, err := svc.Trace(Query{LotNumber: " "}); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected a lot number to be required, got %v", err)
+	}
+	report, err := svc.Trace(Query{LotNumber: "L1", EPARegistration: "7969-210"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Holdings) != 1 || report.Holdings[0].TechnicianID != "tech-a" || report.Holdings[0].Quantity != 2 {
+		t.Fatalf("expected only tech-a to still hold the lot, got %+v", report.Holdings)
+	}
+	if len(report.Exposures) != 3 || report.Exposures[0].TreatmentID != "t1" || report.Unresolved != 1 {
+		t.Fatalf("unexpected exposures %+v (unresolved %d)", report.Exposures, report.Unresolved)
+	}
+	if len(report.Customers) != 1 {
+		t.Fatalf("expected one exposed customer, got %+v", report.Customers)
+	}
+	c := report.Customers[0]
+	if c.Treatments != 2 || len(c.JobIDs) != 2 || !c.LastExposure.Equal(day.Add(48*time.Hour)) {
+		t.Fatalf("unexpected customer %+v", c)
+	}
+
+	// Without the EPA registration the same lot number of another product
+	// is included.
+	if all, _ := svc.Trace(Query{LotNumber: "L1"}); len(all.Exposures) != 4 || len(all.Holdings) != 2 {
+		t.Fatalf("expected every product's lot L1, got %+v", all)
+	}
+}
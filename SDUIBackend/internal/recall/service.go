@@ -0,0 +1,193 @@
+// Package recall traces a manufacturer lot of a chemical through the field:
+// which trucks still hold it, which treatments applied it, and which
+// customers were exposed.
+package recall
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// ErrInvalidQuery is returned when a recall does not name a lot.
+var ErrInvalidQuery = errors.New("lot number is required")
+
+// Query names the recalled lot. Lot numbers are only unique per
+// manufacturer, so EPARegistration narrows the lot to one product.
+type Query struct {
+	LotNumber       string `json:"lotNumber"`
+	EPARegistration string `json:"epaRegistration,omitempty"`
+}
+
+// Holding is recalled stock still on a technician's truck.
+type Holding struct {
+	TechnicianID    string    `json:"technicianId"`
+	ChemicalID      string    `json:"chemicalId"`
+	Name            string    `json:"name"`
+	EPARegistration string    `json:"epaRegistration,omitempty"`
+	Quantity        float64   `json:"quantity"`
+	UnitOfMeasure   string    `json:"unitOfMeasure,omitempty"`
+	ReceivedAt      time.Time `json:"receivedAt"`
+}
+
+// Exposure is a treatment that applied the recalled lot.
+type Exposure struct {
+	TreatmentID     string    `json:"treatmentId"`
+	JobID           string    `json:"jobId"`
+	ChemicalID      string    `json:"chemicalId"`
+	TechnicianID    string    `json:"technicianId"`
+	ApplicationDate time.Time `json:"applicationDate"`
+	QuantityUsed    float64   `json:"quantityUsed"`
+	CustomerName    string    `json:"customerName,omitempty"`
+	Address         string    `json:"address,omitempty"`
+}
+
+// Customer is a customer exposed to the recalled lot.
+type Customer struct {
+	CustomerName  string    `json:"customerName"`
+	Address       string    `json:"address"`
+	JobIDs        []string  `json:"jobIds"`
+	Treatments    int       `json:"treatments"`
+	FirstExposure time.Time `json:"firstExposure"`
+	LastExposure  time.Time `json:"lastExposure"`
+}
+
+// Report is everything a recall of one lot touches.
+type Report struct {
+	Query      Query      `json:"query"`
+	Holdings   []Holding  `json:"holdings"`
+	Exposures  []Exposure `json:"exposures"`
+	Customers  []Customer `json:"customers"`
+	Unresolved int        `json:"unresolved"` // exposures whose job has not synced
+}
+
+// Service answers recall queries from synced chemical, treatment and job
+// records.
+type Service struct {
+	repos repository.Repository
+}
+
+// NewService creates a recall service.
+func NewService(repos repository.Repository) *Service {
+	return &Service{repos: repos}
+}
+
+// Trace finds the stock, treatments and customers touched by a lot.
+func (s *Service) Trace(q Query) (Report, error) {
+	q.LotNumber = strings.TrimSpace(q.LotNumber)
+	q.EPARegistration = strings.TrimSpace(q.EPARegistration)
+	if q.LotNumber == "" {
+		return Report{}, ErrInvalidQuery
+	}
+	report := Report{Query: q, Holdings: []Holding{}, Exposures: []Exposure{}, Customers: []Customer{}}
+
+	chemicals, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return Report{}, err
+	}
+	byID := make(map[string]models.ChemicalUpload, len(chemicals))
+	for _, c := range chemicals {
+		byID[c.ID] = c
+		if q.EPARegistration != "" && c.EPARegistration != q.EPARegistration {
+			continue
+		}
+		for _, lot := range c.Lots {
+			if lot.Number == q.LotNumber && lot.Quantity > 0 {
+				report.Holdings = append(report.Holdings, Holding{
+					TechnicianID:    c.TechnicianID,
+					ChemicalID:      c.ID,
+					Name:            c.Name,
+					EPARegistration: c.EPARegistration,
+					Quantity:        lot.Quantity,
+					UnitOfMeasure:   c.UnitOfMeasure,
+					ReceivedAt:      lot.ReceivedAt,
+				})
+			}
+		}
+	}
+
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return Report{}, err
+	}
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return Report{}, err
+	}
+	jobByID := make(map[string]models.JobUpload, len(jobs))
+	for _, j := range jobs {
+		jobByID[j.ID] = j
+	}
+
+	customers := make(map[string]*Customer)
+	for _, t := range treatments {
+		if t.LotNumber != q.LotNumber {
+			continue
+		}
+		if q.EPARegistration != "" && byID[t.ChemicalID].EPARegistration != q.EPARegistration {
+			continue
+		}
+		e := Exposure{
+			TreatmentID:     t.ID,
+			JobID:           t.JobID,
+			ChemicalID:      t.ChemicalID,
+			TechnicianID:    t.TechnicianID,
+			ApplicationDate: t.ApplicationDate,
+			QuantityUsed:    t.QuantityUsed,
+		}
+		job, ok := jobByID[t.JobID]
+		if !ok {
+			report.Unresolved++
+			report.Exposures = append(report.Exposures, e)
+			continue
+		}
+		e.CustomerName, e.Address = job.CustomerName, job.Address
+		report.Exposures = append(report.Exposures, e)
+
+		key := strings.ToLower(job.CustomerName + "|" + job.Address)
+		c, ok := customers[key]
+		if !ok {
+			c = &Customer{CustomerName: job.CustomerName, Address: job.Address, FirstExposure: t.ApplicationDate, LastExposure: t.ApplicationDate}
+			customers[key] = c
+		}
+		c.Treatments++
+		if !contains(c.JobIDs, job.ID) {
+			c.JobIDs = append(c.JobIDs, job.ID)
+		}
+		if t.ApplicationDate.Before(c.FirstExposure) {
+			c.FirstExposure = t.ApplicationDate
+		}
+		if t.ApplicationDate.After(c.LastExposure) {
+			c.LastExposure = t.ApplicationDate
+		}
+	}
+
+	for _, c := range customers {
+		sort.Strings(c.JobIDs)
+		report.Customers = append(report.Customers, *c)
+	}
+	sort.Slice(report.Holdings, func(i, j int) bool { return report.Holdings[i].TechnicianID < report.Holdings[j].TechnicianID })
+	sort.Slice(report.Exposures, func(i, j int) bool {
+		return report.Exposures[i].ApplicationDate.Before(report.Exposures[j].ApplicationDate)
+	})
+	sort.Slice(report.Customers, func(i, j int) bool {
+		if report.Customers[i].CustomerName != report.Customers[j].CustomerName {
+			return report.Customers[i].CustomerName < report.Customers[j].CustomerName
+		}
+		return report.Customers[i].Address < report.Customers[j].Address
+	})
+	return report, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
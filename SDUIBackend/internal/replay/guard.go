@@ -0,0 +1,161 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Headers of a signed request.
+const (
+	SignatureHeader = "X-PestGenie-Signature"
+	TimestampHeader = "X-PestGenie-Timestamp"
+	NonceHeader     = "X-PestGenie-Nonce"
+)
+
+// Nonces are 16 to 128 characters of letters, digits, '-' and '_', such as
+// a UUID.
+const (
+	minNonceLen = 16
+	maxNonceLen = 128
+)
+
+// maxSignedBody bounds the body read to check a signature; the endpoints
+// enforce their own, smaller limits afterwards.
+const maxSignedBody = 64 << 20
+
+// Guard checks the signature, timestamp and nonce of writes.
+type Guard struct {
+	cfg     config.SigningConfig
+	secrets secret.Provider
+	store   NonceStore
+	now     func() time.Time
+}
+
+// NewGuard returns a guard for cfg, or nil when signed requests are
+// disabled; a nil *Guard lets every request through.
+func NewGuard(cfg config.SigningConfig, secrets secret.Provider, store NonceStore) *Guard {
+	if cfg.KeySecret == "" {
+		return nil
+	}
+	return &Guard{cfg: cfg, secrets: secrets, store: store, now: time.Now}
+}
+
+// Sign returns the signature header value of a request: the hex
+// HMAC-SHA256, under key, of the timestamp, nonce, method, request URI and
+// hex SHA-256 of the body, joined by newlines.
+func Sign(key []byte, timestamp, nonce, method, requestURI string, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join([]string{timestamp, nonce, method, requestURI, hex.EncodeToString(sum[:])}, "\n")))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Middleware checks writes. A signed write must carry a valid signature, a
+// timestamp within the window and a nonce not used before; unsigned writes
+// are refused when signing is required. Reads are not checked.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	if g == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		signature := r.Header.Get(SignatureHeader)
+		if signature == "" {
+			if g.cfg.Required {
+				respond.Error(w, http.StatusUnauthorized, "signature required", "writes must be signed")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timestamp, nonce := r.Header.Get(TimestampHeader), r.Header.Get(NonceHeader)
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid signature headers", TimestampHeader+" must be Unix seconds")
+			return
+		}
+		if !validNonce(nonce) {
+			respond.Error(w, http.StatusBadRequest, "invalid signature headers", fmt.Sprintf("%s must be %d to %d letters, digits, '-' or '_'", NonceHeader, minNonceLen, maxNonceLen))
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+			return
+		}
+		if len(body) > maxSignedBody {
+			respond.Error(w, http.StatusRequestEntityTooLarge, "payload too large", "signed requests are limited to 64 MiB")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := g.secrets.Get(g.cfg.KeySecret)
+		if err != nil {
+			g.fail(w, r, "failed to check signature", fmt.Errorf("request signing key: %w", err))
+			return
+		}
+		want := Sign([]byte(key), timestamp, nonce, r.Method, r.URL.RequestURI(), body)
+		if !hmac.Equal([]byte(signature), []byte(want)) {
+			respond.Error(w, http.StatusUnauthorized, "invalid signature", "the signature does not match the request")
+			return
+		}
+
+		// Checked after the signature, so unsigned traffic cannot use up
+		// nonces.
+		at, now := time.Unix(signedAt, 0), g.now()
+		if at.Before(now.Add(-g.cfg.Window)) || at.After(now.Add(g.cfg.Window)) {
+			respond.Error(w, http.StatusConflict, "request expired", "the request timestamp is outside the validity window")
+			return
+		}
+		fresh, err := g.store.ReserveNonce(nonce, at.Add(g.cfg.Window))
+		if err != nil {
+			g.fail(w, r, "failed to check signature", err)
+			return
+		}
+		if !fresh {
+			middleware.LoggerFrom(r.Context()).Warn("replayed request refused", slog.String("nonce", nonce), slog.String("path", r.URL.Path))
+			respond.Error(w, http.StatusConflict, "request replayed", "the request nonce was already used")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validNonce(nonce string) bool {
+	if len(nonce) < minNonceLen || len(nonce) > maxNonceLen {
+		return false
+	}
+	for _, c := range nonce {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Guard) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+	respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+}
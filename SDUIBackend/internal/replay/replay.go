@@ -0,0 +1,59 @@
+// Package replay refuses signed requests that are sent more than once.
+// Devices sign writes with a shared key over the request, a timestamp and
+// a random nonce; a request whose timestamp is outside the validity window
+// or whose nonce was already used is refused, so a write captured on the
+// wire cannot be replayed against the sync API.
+package replay
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces until they expire. Deployments with several
+// instances need a store they share, or a nonce used on one instance is
+// accepted again on another.
+type NonceStore interface {
+	// ReserveNonce records nonce until expires and reports whether it was
+	// unused; it is atomic, so of two concurrent reservations of a nonce
+	// only one succeeds.
+	ReserveNonce(nonce string, expires time.Time) (bool, error)
+}
+
+// MemoryStore is an in-process NonceStore for local development and single
+// instances.
+type MemoryStore struct {
+	mu       sync.Mutex
+	nonces   map[string]time.Time
+	prunedAt time.Time
+	now      func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nonces: make(map[string]time.Time), now: time.Now}
+}
+
+var _ NonceStore = (*MemoryStore)(nil)
+
+// pruneInterval is how often expired nonces are dropped.
+const pruneInterval = time.Minute
+
+func (m *MemoryStore) ReserveNonce(nonce string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.prunedAt) >= pruneInterval {
+		for n, exp := range m.nonces {
+			if !now.Before(exp) {
+				delete(m.nonces, n)
+			}
+		}
+		m.prunedAt = now
+	}
+	if exp, ok := m.nonces[nonce]; ok && now.Before(exp) {
+		return false, nil
+	}
+	m.nonces[nonce] = expires
+	return true, nil
+}
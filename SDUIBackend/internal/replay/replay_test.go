@@ -0,0 +1,142 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("no secret " + name)
+}
+
+var testNow = time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestGuard(required bool) (http.Handler, *[]string) {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return testNow }
+	g := NewGuard(config.SigningConfig{KeySecret: "signing-key", Required: required, Window: 5 * time.Minute}, staticSecrets{"signing-key": "k3y"}, store)
+	g.now = store.now
+	var bodies []string
+	return g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+	})), &bodies
+}
+
+func signed(at time.Time, nonce, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/v1/jobs/?source=app", strings.NewReader(body))
+	ts := strconv.FormatInt(at.Unix(), 10)
+	r.Header.Set(TimestampHeader, ts)
+	r.Header.Set(NonceHeader, nonce)
+	r.Header.Set(SignatureHeader, Sign([]byte("k3y"), ts, nonce, http.MethodPost, "/v1/jobs/?source=app", []byte(body)))
+	return r
+}
+
+func problemType(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var p struct {
+		Type string `json:"type"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return p.Type
+}
+
+func TestGuardRefusesReplays(t *testing.T) {
+	h, bodies := newTestGuard(false)
+	const nonce = "4f1c2a9e-8b7d-4e36-a1f0-0c9d8e7f6a5b"
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signed(testNow.Add(-time.Minute), nonce, `{"id":"job-1"}`))
+	if rec.Code != http.StatusCreated || len(*bodies) != 1 || (*bodies)[0] != `{"id":"job-1"}` {
+		t.Fatalf("expected a signed write through with its body, got %d %q", rec.Code, *bodies)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, signed(testNow.Add(-time.Minute), nonce, `{"id":"job-1"}`))
+	if rec.Code != http.StatusConflict || problemType(t, rec) != "/problems/replayed-request" {
+		t.Fatalf("expected the replay refused, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, signed(testNow.Add(-6*time.Minute), "a-different-nonce-0001", `{"id":"job-1"}`))
+	if rec.Code != http.StatusConflict || problemType(t, rec) != "/problems/replayed-request" {
+		t.Fatalf("expected a stale timestamp refused, got %d", rec.Code)
+	}
+
+	tampered := signed(testNow, "a-different-nonce-0002", `{"id":"job-1"}`)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"id":"job-2"}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, tampered)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a tampered body refused, got %d", rec.Code)
+	}
+
+	short := signed(testNow, "short", `{}`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, short)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a short nonce refused, got %d", rec.Code)
+	}
+	if len(*bodies) != 1 {
+		t.Fatalf("expected only the first write handled, got %q", *bodies)
+	}
+}
+
+func TestGuardUnsignedRequests(t *testing.T) {
+	h, _ := newTestGuard(false)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/jobs/", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected unsigned writes through when signing is optional, got %d", rec.Code)
+	}
+
+	h, _ = newTestGuard(true)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/jobs/", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unsigned writes refused when signing is required, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/updates", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected reads unchecked, got %d", rec.Code)
+	}
+
+	var disabled *Guard
+	if NewGuard(config.SigningConfig{Window: time.Minute}, nil, nil) != nil || disabled.Middleware(http.NotFoundHandler()) == nil {
+		t.Fatal("expected signing disabled without a key")
+	}
+}
+
+func TestMemoryStoreExpiresNonces(t *testing.T) {
+	m := NewMemoryStore()
+	now := testNow
+	m.now = func() time.Time { return now }
+	if ok, _ := m.ReserveNonce("n", now.Add(time.Minute)); !ok {
+		t.Fatal("expected a new nonce reserved")
+	}
+	if ok, _ := m.ReserveNonce("n", now.Add(time.Minute)); ok {
+		t.Fatal("expected a used nonce refused")
+	}
+	now = now.Add(2 * time.Minute)
+	if ok, _ := m.ReserveNonce("other", now.Add(time.Minute)); !ok || len(m.nonces) != 1 {
+		t.Fatalf("expected expired nonces pruned, have %d", len(m.nonces))
+	}
+}
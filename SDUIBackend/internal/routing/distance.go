@@ -0,0 +1,200 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// DistanceMatrixURL is Google's public Distance Matrix endpoint.
+const DistanceMatrixURL = "https://maps.googleapis.com/maps/api/distancematrix/json"
+
+// DistanceProvider estimates driving times between points.
+type DistanceProvider interface {
+	Name() string
+	// Matrix returns the driving time from every point to every other:
+	// out[i][j] is the time from points[i] to points[j].
+	Matrix(ctx context.Context, points []Point) ([][]time.Duration, error)
+}
+
+// NewDistanceProvider returns the provider selected by cfg.
+func NewDistanceProvider(cfg config.RoutingConfig, secrets secret.Provider) DistanceProvider {
+	switch cfg.Provider {
+	case "google":
+		u := cfg.URL
+		if u == "" {
+			u = DistanceMatrixURL
+		}
+		return GoogleDistanceMatrix{URL: u, KeySecret: cfg.KeySecret, Secrets: secrets, Client: &http.Client{Timeout: cfg.RequestTimeout}}
+	default:
+		return Haversine{SpeedKPH: float64(cfg.SpeedKPH)}
+	}
+}
+
+// Haversine estimates driving time from the great-circle distance, padded
+// for roads not running straight, at an average speed.
+type Haversine struct {
+	SpeedKPH float64
+}
+
+// roadFactor is how much longer a typical drive is than the straight line.
+const roadFactor = 1.3
+
+const earthRadiusKm = 6371.0
+
+func (Haversine) Name() string { return "haversine" }
+
+func (h Haversine) Matrix(_ context.Context, points []Point) ([][]time.Duration, error) {
+	speed := h.SpeedKPH
+	if speed <= 0 {
+		speed = 40
+	}
+	out := make([][]time.Duration, len(points))
+	for i, from := range points {
+		out[i] = make([]time.Duration, len(points))
+		for j, to := range points {
+			if i == j {
+				continue
+			}
+			hours := distanceKm(from, to) * roadFactor / speed
+			out[i][j] = time.Duration(hours * float64(time.Hour)).Round(time.Second)
+		}
+	}
+	return out, nil
+}
+
+// distanceKm is the great-circle distance between two points.
+func distanceKm(a, b Point) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(b.Latitude - a.Latitude)
+	dLng := rad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(a.Latitude))*math.Cos(rad(b.Latitude))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// GoogleDistanceMatrix asks the Distance Matrix API for driving times.
+// Requests are split to stay within its 25 destinations and 100 elements
+// per request.
+type GoogleDistanceMatrix struct {
+	URL       string
+	KeySecret string // secret name of the API key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+const (
+	maxDestinations = 25
+	maxElements     = 100
+)
+
+func (GoogleDistanceMatrix) Name() string { return "google" }
+
+func (g GoogleDistanceMatrix) Matrix(ctx context.Context, points []Point) ([][]time.Duration, error) {
+	key, err := g.Secrets.Get(g.KeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("distance matrix key: %w", err)
+	}
+	out := make([][]time.Duration, len(points))
+	for i := range out {
+		out[i] = make([]time.Duration, len(points))
+	}
+	for d := 0; d < len(points); d += maxDestinations {
+		dests := points[d:min(d+maxDestinations, len(points))]
+		step := max(1, maxElements/len(dests))
+		for o := 0; o < len(points); o += step {
+			origins := points[o:min(o+step, len(points))]
+			block, err := g.fetch(ctx, key, origins, dests)
+			if err != nil {
+				return nil, err
+			}
+			for i, row := range block {
+				copy(out[o+i][d:], row)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (g GoogleDistanceMatrix) fetch(ctx context.Context, key string, origins, dests []Point) ([][]time.Duration, error) {
+	q := url.Values{}
+	q.Set("origins", joinPoints(origins))
+	q.Set("destinations", joinPoints(dests))
+	q.Set("mode", "driving")
+	q.Set("key", key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.URL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("distance matrix responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var body struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Rows         []struct {
+			Elements []struct {
+				Status   string `json:"status"`
+				Duration struct {
+					Value int64 `json:"value"` // seconds
+				} `json:"duration"`
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode distance matrix: %w", err)
+	}
+	if body.Status != "OK" {
+		return nil, fmt.Errorf("distance matrix status %s: %s", body.Status, body.ErrorMessage)
+	}
+	if len(body.Rows) != len(origins) {
+		return nil, fmt.Errorf("distance matrix returned %d rows for %d origins", len(body.Rows), len(origins))
+	}
+	out := make([][]time.Duration, len(origins))
+	for i, row := range body.Rows {
+		if len(row.Elements) != len(dests) {
+			return nil, fmt.Errorf("distance matrix returned %d elements for %d destinations", len(row.Elements), len(dests))
+		}
+		out[i] = make([]time.Duration, len(dests))
+		for j, e := range row.Elements {
+			if e.Status != "OK" {
+				// No road between the points; fall back to the straight line
+				// at a crawl rather than failing the whole route.
+				hours := distanceKm(origins[i], dests[j]) * roadFactor / 20
+				out[i][j] = time.Duration(hours * float64(time.Hour)).Round(time.Second)
+				continue
+			}
+			out[i][j] = time.Duration(e.Duration.Value) * time.Second
+		}
+	}
+	return out, nil
+}
+
+func joinPoints(points []Point) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = strconv.FormatFloat(p.Latitude, 'f', 6, 64) + "," + strconv.FormatFloat(p.Longitude, 'f', 6, 64)
+	}
+	return strings.Join(parts, "|")
+}
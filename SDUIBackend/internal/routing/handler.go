@@ -0,0 +1,70 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes route optimization to dispatchers.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a routing handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Post("/{id}/optimize", h.Optimize)
+}
+
+// Optimize reorders the stops of route {id} for ?technicianId= on
+// ?serviceDate=. The body is optional.
+func (h *Handler) Optimize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("technicianId") == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technicianId", "technicianId query parameter is required")
+		return
+	}
+	date, err := time.Parse("2006-01-02", q.Get("serviceDate"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	result, err := h.service.Optimize(r.Context(), chi.URLParam(r, "id"), q.Get("technicianId"), date, req)
+	if err != nil {
+		h.fail(w, r, "failed to optimize route", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, result)
+}
+
+// fail answers client errors with their apperr status and code, and logs
+// the rest.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch kind := apperr.KindOf(err); kind {
+	case apperr.KindNotFound, apperr.KindValidation:
+		respond.Error(w, kind.Status(), title, err.Error(), respond.WithCause(err))
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,189 @@
+package routing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// maxPasses bounds the local search; it settles well before on real routes.
+const maxPasses = 50
+
+// problem is a route prepared for optimization.
+type problem struct {
+	stops  []models.RouteStop
+	onSite []time.Duration
+	start  time.Time
+	// legs[i][j] is the drive from stop i to stop j, and origin[j] from the
+	// origin to stop j; origin is nil when the first leg is not counted.
+	legs   [][]time.Duration
+	origin []time.Duration
+}
+
+// plan is the projection of one stop order.
+type plan struct {
+	order []int
+	stops []Stop
+	late  time.Duration
+	drive time.Duration
+	sum   Summary
+}
+
+// evaluate projects the stops in order: arriving before a window means
+// waiting for it, and arriving after it closes makes the stop late.
+func (p *problem) evaluate(order []int) plan {
+	out := plan{order: order, stops: make([]Stop, 0, len(order))}
+	clock := p.start
+	for n, i := range order {
+		var leg time.Duration
+		switch {
+		case n > 0:
+			leg = p.legs[order[n-1]][i]
+		case p.origin != nil:
+			leg = p.origin[i]
+		}
+		clock = clock.Add(leg)
+		out.drive += leg
+		stop := p.stops[i]
+		if clock.Before(stop.WindowStart) {
+			clock = stop.WindowStart
+		}
+		s := Stop{
+			CustomerID:   stop.CustomerID,
+			CustomerName: stop.CustomerName,
+			Arrival:      clock,
+			Departure:    clock.Add(p.onSite[i]),
+			DriveMinutes: roundMinutes(leg),
+		}
+		if !stop.WindowEnd.IsZero() && clock.After(stop.WindowEnd) {
+			s.Late = true
+			out.sum.LateStops++
+			out.late += clock.Sub(stop.WindowEnd)
+		}
+		out.stops = append(out.stops, s)
+		clock = s.Departure
+	}
+	out.sum.DriveMinutes = roundMinutes(out.drive)
+	out.sum.LateMinutes = roundMinutes(out.late)
+	out.sum.Finish = clock
+	return out
+}
+
+// better reports whether a beats b: fewer late stops, then less lateness,
+// then less driving, then an earlier finish.
+func better(a, b plan) bool {
+	switch {
+	case a.sum.LateStops != b.sum.LateStops:
+		return a.sum.LateStops < b.sum.LateStops
+	case a.late != b.late:
+		return a.late < b.late
+	case a.drive != b.drive:
+		return a.drive < b.drive
+	default:
+		return a.sum.Finish.Before(b.sum.Finish)
+	}
+}
+
+// optimize starts from the best of the current order, earliest window
+// first and nearest neighbour, then improves it by moving single stops and
+// reversing runs of stops until neither helps.
+func (p *problem) optimize(current []int) plan {
+	best := p.evaluate(current)
+	for _, order := range [][]int{p.earliestDeadline(current), p.nearestNeighbour()} {
+		if candidate := p.evaluate(order); better(candidate, best) {
+			best = candidate
+		}
+	}
+
+	n := len(best.order)
+	for pass, improved := 0, true; improved && pass < maxPasses; pass++ {
+		improved = false
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i == j {
+					continue
+				}
+				if candidate := p.evaluate(relocate(best.order, i, j)); better(candidate, best) {
+					best, improved = candidate, true
+				}
+			}
+		}
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				if candidate := p.evaluate(reverse(best.order, i, j)); better(candidate, best) {
+					best, improved = candidate, true
+				}
+			}
+		}
+	}
+	return best
+}
+
+// earliestDeadline orders stops by window end; stops without a window go
+// last, in their current order.
+func (p *problem) earliestDeadline(current []int) []int {
+	order := append([]int(nil), current...)
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := p.stops[order[a]].WindowEnd, p.stops[order[b]].WindowEnd
+		if ea.IsZero() || eb.IsZero() {
+			return !ea.IsZero() && eb.IsZero()
+		}
+		return ea.Before(eb)
+	})
+	return order
+}
+
+// nearestNeighbour always drives to the closest stop not yet visited.
+func (p *problem) nearestNeighbour() []int {
+	n := len(p.stops)
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+	for len(order) < n {
+		next, nearest := -1, time.Duration(math.MaxInt64)
+		for j := 0; j < n; j++ {
+			if visited[j] {
+				continue
+			}
+			var leg time.Duration
+			switch {
+			case len(order) > 0:
+				leg = p.legs[order[len(order)-1]][j]
+			case p.origin != nil:
+				leg = p.origin[j]
+			}
+			if leg < nearest {
+				next, nearest = j, leg
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+	}
+	return order
+}
+
+// relocate moves the stop at position i to position j.
+func relocate(order []int, i, j int) []int {
+	out := make([]int, 0, len(order))
+	for n, stop := range order {
+		if n != i {
+			out = append(out, stop)
+		}
+	}
+	out = append(out[:j], append([]int{order[i]}, out[j:]...)...)
+	return out
+}
+
+// reverse reverses the stops from position i to j inclusive.
+func reverse(order []int, i, j int) []int {
+	out := append([]int(nil), order...)
+	for ; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func roundMinutes(d time.Duration) int {
+	return int(math.Round(d.Minutes()))
+}
@@ -0,0 +1,73 @@
+// Package routing reorders a route's stops to cut travel time. Stops are
+// ordered so that as few as possible are reached after their booked window
+// closes, then by total drive time; travel times come from a pluggable
+// DistanceProvider, straight-line distance locally and the Google Distance
+// Matrix API in production.
+package routing
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+)
+
+var (
+	// ErrNotFound is returned when the route to optimize does not exist.
+	ErrNotFound = repository.ErrRouteNotFound
+	// ErrInvalidRequest matches optimization requests that cannot be served.
+	ErrInvalidRequest = apperr.Validation("invalid_optimization_request", "invalid optimization request")
+)
+
+// Point is a location on the map.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Request tunes an optimization.
+type Request struct {
+	// Origin is where the technician starts the day, such as the branch;
+	// without it the first leg is not counted.
+	Origin *Point `json:"origin,omitempty"`
+	// Start is when the day starts; it defaults to SCHEDULE_SHIFT_START on
+	// the service date, or the earliest window if that opens sooner.
+	Start *time.Time `json:"start,omitempty"`
+	// DryRun returns the proposed order without saving it.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Result is an optimized stop order, with the projection of the order the
+// route had before for comparison.
+type Result struct {
+	RouteID      string    `json:"routeId"`
+	TechnicianID string    `json:"technicianId"`
+	ServiceDate  time.Time `json:"serviceDate"`
+	Provider     string    `json:"provider"`
+	Stops        []Stop    `json:"stops"`
+	Plan         Summary   `json:"plan"`
+	Before       Summary   `json:"before"`
+	// Changed is set when the order differs from the route's; Applied when
+	// the new order was saved.
+	Changed bool `json:"changed"`
+	Applied bool `json:"applied"`
+}
+
+// Stop is the projected timing of one stop in the optimized order.
+type Stop struct {
+	CustomerID   string    `json:"customerId,omitempty"`
+	CustomerName string    `json:"customerName"`
+	Arrival      time.Time `json:"arrival"`
+	Departure    time.Time `json:"departure"`
+	DriveMinutes int       `json:"driveMinutes"`
+	// Late is set when the projected arrival is after the booked window.
+	Late bool `json:"late,omitempty"`
+}
+
+// Summary totals a stop order.
+type Summary struct {
+	DriveMinutes int       `json:"driveMinutes"`
+	LateStops    int       `json:"lateStops"`
+	LateMinutes  int       `json:"lateMinutes"`
+	Finish       time.Time `json:"finish"`
+}
@@ -0,0 +1,202 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("secret not found")
+}
+
+type fixedEstimator struct{}
+
+func (fixedEstimator) Estimate(string, int) (schedule.Estimate, error) {
+	return schedule.Estimate{Minutes: 30, Basis: schedule.BasisDefault}, nil
+}
+
+func newTestService(t *testing.T, stops ...models.RouteStop) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	if err := store.SaveRoute(models.Route{ID: "r1", TechnicianID: "tech-1", ServiceDate: day, CustomerStops: stops}); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	cfg := config.RoutingConfig{Provider: "haversine", SpeedKPH: 40, MaxStops: 25, ShiftStart: 8 * time.Hour, FallbackLeg: 20 * time.Minute}
+	return NewService(cfg, repos, NewDistanceProvider(cfg, nil), fixedEstimator{}, nil, nil, nil), store
+}
+
+// stop places a stop on a parallel, lng hundredths of a degree east.
+func stop(id string, lng int) models.RouteStop {
+	return models.RouteStop{CustomerID: id, CustomerName: id, Latitude: 30, Longitude: -97 + float64(lng)/100}
+}
+
+func ids(stops []models.RouteStop) string {
+	var out []string
+	for _, s := range stops {
+		out = append(out, s.CustomerID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestOptimizeCutsDriving(t *testing.T) {
+	svc, store := newTestService(t, stop("c", 20), stop("a", 0), stop("d", 30), stop("b", 10))
+
+	result, err := svc.Optimize(context.Background(), "r1", "tech-1", day, Request{Origin: &Point{Latitude: 30, Longitude: -97}, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Changed || result.Applied || result.Plan.DriveMinutes >= result.Before.DriveMinutes {
+		t.Fatalf("expected a shorter unsaved plan, got %+v", result)
+	}
+	route, _ := store.GetRoute("tech-1", day)
+	if got := ids(route.CustomerStops); got != "c,a,d,b" {
+		t.Fatalf("expected a dry run to leave the route alone, got %s", got)
+	}
+
+	result, err = svc.Optimize(context.Background(), "r1", "tech-1", day, Request{Origin: &Point{Latitude: 30, Longitude: -97}})
+	if err != nil || !result.Applied {
+		t.Fatalf("expected the plan saved, got %+v, %v", result, err)
+	}
+	route, _ = store.GetRoute("tech-1", day)
+	if got := ids(route.CustomerStops); got != "a,b,c,d" {
+		t.Errorf("expected stops in driving order, got %s", got)
+	}
+	if first := result.Stops[0]; !first.Arrival.Equal(at(8)) || first.DriveMinutes != 0 {
+		t.Errorf("expected the day to start at the shift start by the first stop, got %+v", first)
+	}
+
+	result, err = svc.Optimize(context.Background(), "r1", "tech-1", day, Request{Origin: &Point{Latitude: 30, Longitude: -97}})
+	if err != nil || result.Changed || result.Applied {
+		t.Errorf("expected an optimal route to be left alone, got %+v, %v", result, err)
+	}
+}
+
+func TestOptimizeHonoursWindows(t *testing.T) {
+	far := stop("far", 30)
+	far.WindowStart, far.WindowEnd = at(8), at(9)
+	near := stop("near", 1)
+	near.WindowStart, near.WindowEnd = at(8), at(17)
+	mid := stop("mid", 15)
+	svc, store := newTestService(t, near, mid, far)
+
+	result, err := svc.Optimize(context.Background(), "r1", "tech-1", day, Request{Origin: &Point{Latitude: 30, Longitude: -97}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Before.LateStops != 1 || result.Plan.LateStops != 0 {
+		t.Fatalf("expected the late stop fixed, got before %+v, plan %+v", result.Before, result.Plan)
+	}
+	route, _ := store.GetRoute("tech-1", day)
+	if got := ids(route.CustomerStops); !strings.HasPrefix(got, "far,") {
+		t.Errorf("expected the closing window first, got %s", got)
+	}
+}
+
+func TestOptimizeHandler(t *testing.T) {
+	svc, _ := newTestService(t, stop("b", 10), stop("a", 0))
+	r := chi.NewRouter()
+	r.Route("/routes", NewHandler(svc).Routes)
+	do := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := do("/routes/r1/optimize?technicianId=tech-1&serviceDate=2026-04-02", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without a body, got %d: %s", rec.Code, rec.Body)
+	}
+	code := func(rec *httptest.ResponseRecorder) string {
+		var p struct {
+			Code string `json:"code"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&p)
+		return p.Code
+	}
+	for _, path := range []string{
+		"/routes/other/optimize?technicianId=tech-1&serviceDate=2026-04-02",
+		"/routes/r1/optimize?technicianId=tech-1&serviceDate=2026-04-03",
+	} {
+		if rec := do(path, "{}"); rec.Code != http.StatusNotFound || code(rec) != "route_not_found" {
+			t.Errorf("%s: expected 404 route_not_found, got %d %s", path, rec.Code, rec.Body)
+		}
+	}
+	if rec := do("/routes/r1/optimize?technicianId=tech-1", "{}"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a serviceDate, got %d", rec.Code)
+	}
+
+	svc.cfg.MaxStops = 1
+	if _, err := svc.Optimize(context.Background(), "r1", "tech-1", day, Request{}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected too many stops refused, got %v", err)
+	}
+	if rec := do("/routes/r1/optimize?technicianId=tech-1&serviceDate=2026-04-02", "{}"); rec.Code != http.StatusBadRequest || code(rec) != "invalid_optimization_request" {
+		t.Errorf("expected 400 invalid_optimization_request, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGoogleDistanceMatrixBatches(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("key") != "k" {
+			t.Errorf("expected the API key, got %q", r.URL.RawQuery)
+		}
+		origins := strings.Split(r.URL.Query().Get("origins"), "|")
+		dests := strings.Split(r.URL.Query().Get("destinations"), "|")
+		if len(origins)*len(dests) > maxElements || len(dests) > maxDestinations {
+			t.Errorf("request over the limits: %d x %d", len(origins), len(dests))
+		}
+		type element struct {
+			Status   string         `json:"status"`
+			Duration map[string]int `json:"duration"`
+		}
+		rows := make([]map[string][]element, len(origins))
+		for i := range rows {
+			for range dests {
+				rows[i] = map[string][]element{"elements": append(rows[i]["elements"], element{"OK", map[string]int{"value": 60}})}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "rows": rows})
+	}))
+	defer srv.Close()
+
+	secrets := staticSecrets{"maps": "k"}
+	g := NewDistanceProvider(config.RoutingConfig{Provider: "google", URL: srv.URL, KeySecret: "maps", RequestTimeout: time.Second}, secrets)
+	points := make([]Point, 30)
+	for i := range points {
+		points[i] = Point{Latitude: 30, Longitude: float64(i)}
+	}
+	matrix, err := g.Matrix(context.Background(), points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matrix[29][0] != time.Minute || matrix[0][29] != time.Minute {
+		t.Errorf("expected every cell filled, got %v and %v", matrix[29][0], matrix[0][29])
+	}
+	if calls < 9 {
+		t.Errorf("expected the matrix split into requests, got %d", calls)
+	}
+}
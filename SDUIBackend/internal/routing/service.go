@@ -0,0 +1,182 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/notify"
+	"github.com/your-org/pestgenie-sdui/internal/schedule"
+)
+
+// Estimator estimates the on-site time of a stop; *schedule.Service
+// implements it.
+type Estimator interface {
+	Estimate(serviceType string, propertySqFt int) (schedule.Estimate, error)
+}
+
+// Service optimizes stored routes.
+type Service struct {
+	cfg       config.RoutingConfig
+	repos     repository.Repository
+	distances DistanceProvider
+	estimator Estimator
+	feed      *activity.Service
+	notes     *notify.Service
+	logger    *slog.Logger
+}
+
+// NewService wires a routing service. Saved orders are recorded in feed
+// and pushed to the technician through notes; either may be nil.
+func NewService(cfg config.RoutingConfig, repos repository.Repository, distances DistanceProvider, estimator Estimator, feed *activity.Service, notes *notify.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, repos: repos, distances: distances, estimator: estimator, feed: feed, notes: notes, logger: logger}
+}
+
+// Optimize reorders the stops of a technician's route for serviceDate. The
+// route must be routeID, by its own ID or the ID devices know it by. The new
+// order is saved unless req.DryRun is set or it is no better than the
+// current one.
+func (s *Service) Optimize(ctx context.Context, routeID, technicianID string, serviceDate time.Time, req Request) (Result, error) {
+	route, err := s.repos.Routes.GetRoute(technicianID, serviceDate)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Result{}, err
+	}
+	if err != nil || (route.ID != routeID && route.ServerID() != routeID) {
+		return Result{}, ErrNotFound.Detailf("no route %s for technician on %s", routeID, serviceDate.Format(time.DateOnly))
+	}
+	if n := len(route.CustomerStops); n > s.cfg.MaxStops {
+		return Result{}, ErrInvalidRequest.Detailf("route has %d stops, more than the %d that can be optimized", n, s.cfg.MaxStops)
+	}
+
+	p, err := s.prepare(ctx, route, req)
+	if err != nil {
+		return Result{}, err
+	}
+	current := make([]int, len(route.CustomerStops))
+	for i := range current {
+		current[i] = i
+	}
+	before := p.evaluate(current)
+	best := p.optimize(current)
+
+	out := Result{
+		RouteID:      routeID,
+		TechnicianID: route.TechnicianID,
+		ServiceDate:  route.ServiceDate,
+		Provider:     s.distances.Name(),
+		Stops:        best.stops,
+		Plan:         best.sum,
+		Before:       before.sum,
+		Changed:      better(best, before),
+	}
+	if !out.Changed || req.DryRun {
+		if !out.Changed {
+			out.Stops, out.Plan = before.stops, before.sum
+		}
+		return out, nil
+	}
+
+	stops := make([]models.RouteStop, len(best.order))
+	for n, i := range best.order {
+		stops[n] = route.CustomerStops[i]
+	}
+	route.CustomerStops = stops
+	route.LastModified = time.Now()
+	if err := s.repos.Routes.SaveRoute(route); err != nil {
+		return Result{}, err
+	}
+	out.Applied = true
+
+	date := route.ServiceDate.Format(time.DateOnly)
+	s.logger.Info("route optimized", slog.String("route", routeID), slog.String("technician", route.TechnicianID),
+		slog.Int("driveMinutesBefore", before.sum.DriveMinutes), slog.Int("driveMinutes", best.sum.DriveMinutes))
+	s.feed.Record(ctx, activity.Event{Type: activity.RouteOptimized, SubjectID: routeID, SubjectName: fmt.Sprintf("%s for %s on %s", routeID, route.TechnicianID, date)})
+	s.notes.Notify(ctx, notify.Notification{
+		TechnicianID: route.TechnicianID,
+		Category:     notify.CategoryRoute,
+		CollapseKey:  routeID,
+		Title:        "Route for " + route.ServiceDate.Format("Mon Jan 2") + " reordered",
+		Body:         fmt.Sprintf("%d stops, about %d minutes of driving", len(stops), best.sum.DriveMinutes),
+		Data:         map[string]string{"routeId": routeID, "serviceDate": date},
+	})
+	return out, nil
+}
+
+// prepare gathers on-site estimates and drive times for route. Legs to or
+// from a stop without coordinates take FallbackLeg.
+func (s *Service) prepare(ctx context.Context, route models.Route, req Request) (*problem, error) {
+	n := len(route.CustomerStops)
+	p := &problem{stops: route.CustomerStops, onSite: make([]time.Duration, n)}
+
+	day := route.ServiceDate.UTC()
+	p.start = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).Add(s.cfg.ShiftStart)
+	for i, stop := range route.CustomerStops {
+		if !stop.WindowStart.IsZero() && stop.WindowStart.Before(p.start) {
+			p.start = stop.WindowStart
+		}
+		estimate, err := s.estimator.Estimate(stop.ServiceType, stop.PropertySqFt)
+		if err != nil {
+			return nil, err
+		}
+		p.onSite[i] = time.Duration(estimate.Minutes * float64(time.Minute))
+	}
+	if req.Start != nil {
+		p.start = *req.Start
+	}
+
+	// Index the geocoded stops, and the origin last, into one matrix.
+	var points []Point
+	index := make([]int, n)
+	for i, stop := range route.CustomerStops {
+		index[i] = -1
+		if stop.HasCoordinates() {
+			index[i] = len(points)
+			points = append(points, Point{Latitude: stop.Latitude, Longitude: stop.Longitude})
+		}
+	}
+	origin := -1
+	if req.Origin != nil {
+		origin = len(points)
+		points = append(points, *req.Origin)
+	}
+	var matrix [][]time.Duration
+	if len(points) > 1 {
+		var err error
+		if matrix, err = s.distances.Matrix(ctx, points); err != nil {
+			return nil, fmt.Errorf("%s distances: %w", s.distances.Name(), err)
+		}
+	}
+	leg := func(from, to int) time.Duration {
+		if from < 0 || to < 0 {
+			return s.cfg.FallbackLeg
+		}
+		return matrix[from][to]
+	}
+
+	p.legs = make([][]time.Duration, n)
+	for i := range p.legs {
+		p.legs[i] = make([]time.Duration, n)
+		for j := range p.legs[i] {
+			if i != j {
+				p.legs[i][j] = leg(index[i], index[j])
+			}
+		}
+	}
+	if req.Origin != nil {
+		p.origin = make([]time.Duration, n)
+		for j := range p.origin {
+			p.origin[j] = leg(origin, index[j])
+		}
+	}
+	return p, nil
+}
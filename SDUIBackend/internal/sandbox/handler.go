@@ -0,0 +1,58 @@
+package sandbox
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+)
+
+// Handler exposes admin endpoints for sandbox environments.
+type Handler struct {
+	manager *Manager
+}
+
+// NewHandler creates a sandbox admin handler.
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// Routes mounts the sandbox admin endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/{namespace}/reset", h.Reset)
+	r.Delete("/{namespace}", h.Delete)
+}
+
+// List returns every sandbox environment.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, map[string]any{"sandboxes": h.manager.List()})
+}
+
+// Reset reseeds a sandbox environment.
+func (h *Handler) Reset(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, h.manager.Reset(chi.URLParam(r, "namespace")))
+}
+
+// Delete removes a sandbox environment.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.manager.Delete(chi.URLParam(r, "namespace")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			respond.Error(w, http.StatusNotFound, "failed to delete sandbox", err.Error())
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, "failed to delete sandbox", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetOwn returns a handler that resets namespace; it is mounted inside the
+// sandbox API so integrators can reset their own data with their token.
+func (m *Manager) ResetOwn(namespace string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respond.JSON(w, http.StatusOK, m.Reset(namespace))
+	}
+}
@@ -0,0 +1,204 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+// ErrNotFound is returned when a sandbox namespace has not been created.
+var ErrNotFound = errors.New("not found")
+
+// purgeTimeout bounds deleting the blobs of a reset or deleted environment.
+const purgeTimeout = time.Minute
+
+// Builder constructs the public API over an isolated repository and blob
+// store. The handler must route the same paths as the production API.
+type Builder func(namespace string, repos repository.Repository, blobs storage.BlobStore) http.Handler
+
+// Manager keeps one isolated, seeded environment per namespace. Environments
+// are created on first use and always backed by an in-memory store, whatever
+// the production driver, so sandbox traffic can never reach production data.
+// Their photos and reports go to the production blob store, under a prefix
+// of their own, and are deleted when the environment is reset or deleted.
+type Manager struct {
+	build  Builder
+	blobs  storage.BlobStore
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu   sync.Mutex
+	envs map[string]*environment
+}
+
+type environment struct {
+	handler     http.Handler
+	blobs       *storage.Prefixed
+	createdAt   time.Time
+	lastResetAt *time.Time
+	resets      int
+}
+
+// Status describes a sandbox environment.
+type Status struct {
+	Namespace   string     `json:"namespace"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastResetAt *time.Time `json:"lastResetAt,omitempty"`
+	Resets      int        `json:"resets"`
+}
+
+// NewManager creates a sandbox manager keeping environments' blobs in
+// blobs.
+func NewManager(build Builder, blobs storage.BlobStore, logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{build: build, blobs: blobs, logger: logger, now: time.Now, envs: make(map[string]*environment)}
+}
+
+// Handler returns the API handler for namespace, creating the environment on
+// first use.
+func (m *Manager) Handler(namespace string) http.Handler {
+	m.mu.Lock()
+	env, ok := m.envs[namespace]
+	if !ok {
+		env = &environment{createdAt: m.now().UTC()}
+		m.fresh(namespace, env)
+		m.envs[namespace] = env
+		m.logger.Info("sandbox created", slog.String("namespace", namespace))
+	}
+	handler := env.handler
+	m.mu.Unlock()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Drop the production router's routing state so the sandbox router
+		// matches the full request path.
+		ctx := context.WithValue(r.Context(), chi.RouteCtxKey, nil)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Reset discards all sandbox data for namespace and reseeds it. In-flight
+// requests finish against the previous records; its blobs are deleted in
+// the background.
+func (m *Manager) Reset(namespace string) Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now().UTC()
+	env, ok := m.envs[namespace]
+	if !ok {
+		env = &environment{createdAt: now}
+		m.envs[namespace] = env
+	}
+	m.purge(namespace, env.blobs)
+	m.fresh(namespace, env)
+	env.lastResetAt = &now
+	env.resets++
+	m.logger.Info("sandbox reset", slog.String("namespace", namespace))
+	return env.status(namespace)
+}
+
+// Delete removes a sandbox environment entirely, its blobs in the
+// background.
+func (m *Manager) Delete(namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	env, ok := m.envs[namespace]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(m.envs, namespace)
+	m.purge(namespace, env.blobs)
+	return nil
+}
+
+// List returns every sandbox environment sorted by namespace.
+func (m *Manager) List() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Status, 0, len(m.envs))
+	for ns, env := range m.envs {
+		out = append(out, env.status(ns))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+func (e *environment) status(namespace string) Status {
+	return Status{Namespace: namespace, CreatedAt: e.createdAt, LastResetAt: e.lastResetAt, Resets: e.resets}
+}
+
+// fresh gives env a newly seeded store and blobs under a prefix no earlier
+// environment of namespace used, so blobs a purge missed stay unreachable.
+func (m *Manager) fresh(namespace string, env *environment) {
+	store := storememory.NewStore()
+	Seed(store, m.now())
+	env.blobs = storage.NewPrefixed(m.blobs, "sandbox/"+namespace+"/"+strconv.FormatInt(m.now().UnixNano(), 36)+"/")
+	env.handler = m.build(namespace, repository.Repository{
+		Technicians: store,
+		Routes:      store,
+		Screens:     store,
+		Sync:        store,
+		Devices:     store,
+	}, env.blobs)
+}
+
+// purge deletes the blobs of a discarded environment.
+func (m *Manager) purge(namespace string, blobs *storage.Prefixed) {
+	if blobs == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), purgeTimeout)
+		defer cancel()
+		if err := blobs.Purge(ctx); err != nil {
+			m.logger.Error("purge sandbox blobs", slog.String("namespace", namespace), slog.Any("error", err))
+		}
+	}()
+}
+
+// SeedTechnicianID is the technician every sandbox is seeded with.
+const SeedTechnicianID = "sandbox-technician"
+
+// Seed fills store with a technician and today's route so integrators get
+// realistic responses without creating data first.
+func Seed(store *storememory.Store, now time.Time) {
+	store.AddTechnician(models.Technician{
+		ID:             SeedTechnicianID,
+		Email:          "sandbox.tech@example.com",
+		DisplayName:    "Sandy Box",
+		Role:           "technician",
+		Region:         "sandbox",
+		Certifications: []string{"general-pest"},
+	})
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	_ = store.SaveRoute(models.Route{
+		ID:           "sandbox-route",
+		TechnicianID: SeedTechnicianID,
+		ServiceDate:  day,
+		CustomerStops: []models.RouteStop{
+			{CustomerID: "sandbox-cust-1", CustomerName: "Acme Bakery", Address: "100 Main St", WindowStart: at(8), WindowEnd: at(10), Priority: "high", Notes: "Rodent inspection", Latitude: 30.2682, Longitude: -97.7429},
+			{CustomerID: "sandbox-cust-2", CustomerName: "Riverside Apartments", Nickname: "the river complex", Address: "25 River Rd", WindowStart: at(11), WindowEnd: at(13), Priority: "normal", Latitude: 30.2553, Longitude: -97.7630},
+			{CustomerID: "sandbox-cust-3", CustomerName: "Oak Street Clinic", Address: "8 Oak St", WindowStart: at(14), WindowEnd: at(16), Priority: "normal", Notes: "Quarterly perimeter treatment", Latitude: 30.2849, Longitude: -97.7341},
+		},
+		Alerts: []models.RouteAlert{
+			{Type: "info", Message: "This is sandbox data and resets on request.", Severity: "low"},
+		},
+		LastModified: now,
+	})
+}
@@ -0,0 +1,135 @@
+package sandbox
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+)
+
+// routeAPI reports how many stops the seeded route has and lets tests add one
+// and upload a photo.
+func routeAPI(_ string, repos repository.Repository, blobs storage.BlobStore) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/v1/photos", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := blobs.Put(r.Context(), "photos/p-1.jpg", "image/jpeg", strings.NewReader("jpeg")); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	r.Get("/v1/stops", func(w http.ResponseWriter, _ *http.Request) {
+		route, err := repos.Routes.GetRoute(SeedTechnicianID, time.Now().UTC())
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte{byte('0' + len(route.CustomerStops))})
+	})
+	r.Post("/v1/stops", func(w http.ResponseWriter, _ *http.Request) {
+		route, _ := repos.Routes.GetRoute(SeedTechnicianID, time.Now().UTC())
+		route.CustomerStops = append(route.CustomerStops, models.RouteStop{CustomerName: "New"})
+		_ = repos.Routes.SaveRoute(route)
+		w.WriteHeader(http.StatusCreated)
+	})
+	return r
+}
+
+func call(h http.Handler, method string) string {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, "/v1/stops", nil))
+	return rec.Body.String()
+}
+
+func newBlobs(t *testing.T, root string) *storage.Local {
+	t.Helper()
+	blobs, err := storage.NewLocal(root, "http://localhost"+storage.LocalPathPrefix, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blobs
+}
+
+// files lists the blobs under root.
+func files(t *testing.T, root string) []string {
+	t.Helper()
+	var out []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return out
+}
+
+func TestSandboxesAreIsolatedAndReset(t *testing.T) {
+	m := NewManager(routeAPI, newBlobs(t, t.TempDir()), nil)
+
+	call(m.Handler("acme"), http.MethodPost)
+	if got := call(m.Handler("acme"), http.MethodGet); got != "4" {
+		t.Fatalf("expected write to persist in the sandbox, got %q", got)
+	}
+	if got := call(m.Handler("globex"), http.MethodGet); got != "3" {
+		t.Fatalf("expected other namespace to be untouched, got %q", got)
+	}
+
+	status := m.Reset("acme")
+	if status.Resets != 1 || status.LastResetAt == nil {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if got := call(m.Handler("acme"), http.MethodGet); got != "3" {
+		t.Fatalf("expected reset to restore seed data, got %q", got)
+	}
+	if len(m.List()) != 2 {
+		t.Fatalf("expected two sandboxes, got %+v", m.List())
+	}
+}
+
+func TestHandlerIgnoresOuterRouteContext(t *testing.T) {
+	m := NewManager(routeAPI, newBlobs(t, t.TempDir()), nil)
+	outer := chi.NewRouter()
+	outer.Route("/v1", func(r chi.Router) {
+		r.Get("/stops", m.Handler("acme").ServeHTTP)
+	})
+	if got := call(outer, http.MethodGet); got != "3" {
+		t.Fatalf("expected sandbox router to match the full path, got %q", got)
+	}
+}
+
+func TestSandboxBlobsArePrefixedAndPurged(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(routeAPI, newBlobs(t, root), nil)
+	upload := func(namespace string) {
+		rec := httptest.NewRecorder()
+		m.Handler(namespace).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/photos", nil))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("upload: %d", rec.Code)
+		}
+	}
+	upload("acme")
+	upload("globex")
+	got := files(t, root)
+	if len(got) != 2 || !strings.HasPrefix(got[0], "sandbox/acme/") || !strings.HasPrefix(got[1], "sandbox/globex/") || !strings.HasSuffix(got[0], "/photos/p-1.jpg") {
+		t.Fatalf("expected each sandbox's blobs under its own prefix, got %q", got)
+	}
+
+	m.Reset("acme")
+	_ = m.Delete("globex")
+	for deadline := time.Now().Add(5 * time.Second); len(files(t, root)) > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected reset and deleted sandboxes' blobs purged, got %q", files(t, root))
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/connector"
+)
+
+// Feasibility is the outcome of checking a route against the shift.
+type Feasibility struct {
+	TechnicianID   string         `json:"technicianId"`
+	RouteID        string         `json:"routeId,omitempty"`
+	ServiceDate    time.Time      `json:"serviceDate"`
+	Stops          []StopEstimate `json:"stops"`
+	ServiceMinutes int            `json:"serviceMinutes"`
+	DriveMinutes   int            `json:"driveMinutes"`
+	TotalMinutes   int            `json:"totalMinutes"`
+	ShiftMinutes   int            `json:"shiftMinutes"`
+	// Closed is set when the route falls on a day the branch is closed.
+	Closed        bool   `json:"closed,omitempty"`
+	Holiday       string `json:"holiday,omitempty"`
+	Overbooked    bool   `json:"overbooked"`
+	OverByMinutes int    `json:"overByMinutes,omitempty"`
+	LateStops     int    `json:"lateStops"`
+}
+
+// StopEstimate is the projected timing of one stop.
+type StopEstimate struct {
+	CustomerID   string    `json:"customerId,omitempty"`
+	CustomerName string    `json:"customerName"`
+	Estimate     Estimate  `json:"estimate"`
+	Arrival      time.Time `json:"arrival"`
+	Departure    time.Time `json:"departure"`
+	// Late is set when the projected arrival is after the booked window.
+	Late bool `json:"late,omitempty"`
+}
+
+// Feasibility checks the stored route for a technician's day.
+func (s *Service) Feasibility(technicianID string, serviceDate time.Time) (Feasibility, error) {
+	route, err := s.repos.Routes.GetRoute(technicianID, serviceDate)
+	if err != nil {
+		return Feasibility{}, fmt.Errorf("%w: no route for technician on %s", ErrNotFound, serviceDate.Format("2006-01-02"))
+	}
+	return s.CheckRoute(route)
+}
+
+// CheckRoute projects the route in stop order: the day starts when the branch
+// opens, or at ShiftStart without a calendar (or at the first window, if
+// earlier), each stop after the first adds DriveTimePerStop, and arriving
+// before a window means waiting for it. The route is overbooked when service
+// plus drive time exceeds ShiftLength.
+func (s *Service) CheckRoute(route models.Route) (Feasibility, error) {
+	day := route.ServiceDate.UTC()
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	out := Feasibility{
+		TechnicianID: route.TechnicianID,
+		RouteID:      route.ID,
+		ServiceDate:  day,
+		Stops:        make([]StopEstimate, 0, len(route.CustomerStops)),
+		ShiftMinutes: int(s.cfg.ShiftLength.Minutes()),
+	}
+
+	clock := day.Add(s.cfg.ShiftStart)
+	if hours := s.calendars.ForTechnician(route.TechnicianID); hours != nil {
+		business := hours.Day(day)
+		out.Holiday = business.Holiday
+		if business.Open {
+			clock = business.Opens.UTC()
+		} else {
+			out.Closed = true
+		}
+	}
+	if len(route.CustomerStops) > 0 {
+		if first := route.CustomerStops[0].WindowStart; !first.IsZero() && first.Before(clock) {
+			clock = first
+		}
+	}
+	var service, drive time.Duration
+	for i, stop := range route.CustomerStops {
+		estimate, err := s.Estimate(stop.ServiceType, stop.PropertySqFt)
+		if err != nil {
+			return Feasibility{}, err
+		}
+		if i > 0 {
+			clock = clock.Add(s.cfg.DriveTimePerStop)
+			drive += s.cfg.DriveTimePerStop
+		}
+		if clock.Before(stop.WindowStart) {
+			clock = stop.WindowStart
+		}
+		onSite := time.Duration(estimate.Minutes * float64(time.Minute))
+		projected := StopEstimate{
+			CustomerID:   stop.CustomerID,
+			CustomerName: stop.CustomerName,
+			Estimate:     estimate,
+			Arrival:      clock,
+			Departure:    clock.Add(onSite),
+			Late:         !stop.WindowEnd.IsZero() && clock.After(stop.WindowEnd),
+		}
+		if projected.Late {
+			out.LateStops++
+		}
+		out.Stops = append(out.Stops, projected)
+		service += onSite
+		clock = projected.Departure
+	}
+
+	out.ServiceMinutes = roundMinutes(service)
+	out.DriveMinutes = roundMinutes(drive)
+	out.TotalMinutes = roundMinutes(service + drive)
+	if over := service + drive - s.cfg.ShiftLength; over > 0 {
+		out.Overbooked = true
+		out.OverByMinutes = roundMinutes(over)
+	}
+	return out, nil
+}
+
+// CheckRoutes vets freshly ingested routes and returns a warning for each
+// closed or overbooked day or late stop. Overbooked days are also logged and published
+// as connector events so dispatch hears about them.
+func (s *Service) CheckRoutes(routes []models.Route) []string {
+	var warnings []string
+	for _, route := range routes {
+		f, err := s.CheckRoute(route)
+		if err != nil {
+			s.logger.Error("route feasibility check failed", slog.String("technician", route.TechnicianID), slog.Any("error", err))
+			continue
+		}
+		date := f.ServiceDate.Format("2006-01-02")
+		if f.Closed {
+			reason := "a closed day"
+			if f.Holiday != "" {
+				reason = f.Holiday
+			}
+			warnings = append(warnings, fmt.Sprintf("technician %s on %s is booked on %s", f.TechnicianID, date, reason))
+		}
+		if f.Overbooked {
+			warnings = append(warnings, fmt.Sprintf("technician %s on %s is overbooked by %d minutes (%d of %d)",
+				f.TechnicianID, date, f.OverByMinutes, f.TotalMinutes, f.ShiftMinutes))
+			s.logger.Warn("overbooked route",
+				slog.String("technician", f.TechnicianID),
+				slog.String("serviceDate", date),
+				slog.Int("totalMinutes", f.TotalMinutes),
+				slog.Int("shiftMinutes", f.ShiftMinutes),
+			)
+			s.events.Publish(connector.RouteOverbooked(route, f.TotalMinutes, f.ShiftMinutes))
+		}
+		if f.LateStops > 0 {
+			warnings = append(warnings, fmt.Sprintf("technician %s on %s has %d stops projected after their window", f.TechnicianID, date, f.LateStops))
+		}
+	}
+	return warnings
+}
+
+func roundMinutes(d time.Duration) int {
+	return int(math.Round(d.Minutes()))
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes duration recording, estimates, and feasibility checks.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a schedule handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the dispatch endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/estimate", h.GetEstimate)
+	r.Get("/feasibility", h.GetFeasibility)
+}
+
+// RecordDuration stores the on-site time of a completed job.
+func (h *Handler) RecordDuration(w http.ResponseWriter, r *http.Request) {
+	var payload Observation
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	payload.JobID = chi.URLParam(r, "jobId")
+	o, err := h.service.Record(payload)
+	if err != nil {
+		h.fail(w, r, "failed to record duration", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, o)
+}
+
+// GetEstimate returns the estimate for ?serviceType= and optional ?propertySqft=.
+func (h *Handler) GetEstimate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sqft := 0
+	if v := q.Get("propertySqft"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respond.Error(w, http.StatusBadRequest, "invalid propertySqft", "expected a non-negative integer")
+			return
+		}
+		sqft = n
+	}
+	estimate, err := h.service.Estimate(q.Get("serviceType"), sqft)
+	if err != nil {
+		h.fail(w, r, "failed to estimate duration", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, estimate)
+}
+
+// GetFeasibility checks the route for ?technicianId= on ?serviceDate=.
+func (h *Handler) GetFeasibility(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("technicianId") == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technicianId", "technicianId query parameter is required")
+		return
+	}
+	date, err := time.Parse("2006-01-02", q.Get("serviceDate"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+		return
+	}
+	f, err := h.service.Feasibility(q.Get("technicianId"), date)
+	if err != nil {
+		h.fail(w, r, "failed to check route", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, f)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidObservation):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
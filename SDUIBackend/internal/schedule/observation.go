@@ -0,0 +1,143 @@
+// Package schedule estimates job durations from observed on-site times and
+// checks whether routes fit in a technician's shift.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Property size buckets.
+const (
+	SizeUnknown = ""
+	SizeSmall   = "small"  // under 1,500 sq ft
+	SizeMedium  = "medium" // under 3,000 sq ft
+	SizeLarge   = "large"  // under 6,000 sq ft
+	SizeXLarge  = "xlarge"
+)
+
+// maxOnSite bounds plausible observations; longer durations are almost
+// always a job left open on the device.
+const maxOnSite = 12 * time.Hour
+
+var (
+	// ErrNotFound is returned when a route or observation does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidObservation wraps observation validation failures.
+	ErrInvalidObservation = errors.New("invalid duration observation")
+)
+
+// SizeBucket groups a property size so estimates have enough samples.
+func SizeBucket(sqft int) string {
+	switch {
+	case sqft <= 0:
+		return SizeUnknown
+	case sqft < 1500:
+		return SizeSmall
+	case sqft < 3000:
+		return SizeMedium
+	case sqft < 6000:
+		return SizeLarge
+	}
+	return SizeXLarge
+}
+
+// Observation is the actual on-site time of a completed job.
+type Observation struct {
+	ID           string    `json:"id"`
+	JobID        string    `json:"jobId"`
+	TechnicianID string    `json:"technicianId"`
+	ServiceType  string    `json:"serviceType"`
+	PropertySqFt int       `json:"propertySqft,omitempty"`
+	SizeBucket   string    `json:"sizeBucket,omitempty"`
+	StartedAt    time.Time `json:"startedAt"`
+	CompletedAt  time.Time `json:"completedAt"`
+	Minutes      float64   `json:"minutes"`
+	RecordedAt   time.Time `json:"recordedAt"`
+}
+
+// Validate checks an observation before it is recorded.
+func (o Observation) Validate() error {
+	var problems []string
+	if o.JobID == "" {
+		problems = append(problems, "jobId is required")
+	}
+	if strings.TrimSpace(o.ServiceType) == "" {
+		problems = append(problems, "serviceType is required")
+	}
+	if o.PropertySqFt < 0 {
+		problems = append(problems, "propertySqft must be >= 0")
+	}
+	if o.StartedAt.IsZero() || o.CompletedAt.IsZero() {
+		problems = append(problems, "startedAt and completedAt are required")
+	} else if d := o.CompletedAt.Sub(o.StartedAt); d < time.Minute || d > maxOnSite {
+		problems = append(problems, fmt.Sprintf("on-site time must be between 1m and %s", maxOnSite))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidObservation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Store persists duration observations.
+type Store interface {
+	// SaveObservation records o, replacing an earlier one for the same job.
+	SaveObservation(o Observation) error
+	// GetObservation returns a job's observation, or ErrNotFound.
+	GetObservation(jobID string) (Observation, error)
+	// ListObservations returns up to limit observations for a service type,
+	// most recent first. An empty size bucket matches every size.
+	ListObservations(serviceType, sizeBucket string, limit int) ([]Observation, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	byJob map[string]Observation
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{byJob: make(map[string]Observation)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveObservation(o Observation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byJob[o.JobID] = o
+	return nil
+}
+
+func (m *MemoryStore) GetObservation(jobID string) (Observation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	o, ok := m.byJob[jobID]
+	if !ok {
+		return Observation{}, ErrNotFound
+	}
+	return o, nil
+}
+
+func (m *MemoryStore) ListObservations(serviceType, sizeBucket string, limit int) ([]Observation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Observation
+	for _, o := range m.byJob {
+		if o.ServiceType == serviceType && (sizeBucket == "" || o.SizeBucket == sizeBucket) {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CompletedAt.After(out[j].CompletedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
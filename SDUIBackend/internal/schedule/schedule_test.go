@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func at(hour, minute int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func newTestService(t *testing.T) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.ScheduleConfig{
+		DefaultJobDuration: 45 * time.Minute,
+		DriveTimePerStop:   15 * time.Minute,
+		ShiftStart:         8 * time.Hour,
+		ShiftLength:        4 * time.Hour,
+		MinSamples:         2,
+		SampleWindow:       10,
+	}
+	return NewService(cfg, NewMemoryStore(), repos, nil, nil, nil), store
+}
+
+func record(t *testing.T, svc *Service, job, serviceType string, sqft, minutes int) {
+	t.Helper()
+	start := at(9, 0)
+	if _, err := svc.Record(Observation{JobID: job, ServiceType: serviceType, PropertySqFt: sqft, StartedAt: start, CompletedAt: start.Add(time.Duration(minutes) * time.Minute)}); err != nil {
+		t.Fatalf("record %s: %v", job, err)
+	}
+}
+
+func TestEstimateFallsBackBySpecificity(t *testing.T) {
+	svc, _ := newTestService(t)
+	record(t, svc, "j1", "Termite", 1000, 60)
+	record(t, svc, "j2", "termite", 1200, 80)
+	record(t, svc, "j3", "termite", 5000, 150)
+
+	cases := []struct {
+		serviceType string
+		sqft        int
+		minutes     float64
+		basis       string
+	}{
+		{"termite", 900, 70, BasisTypeAndSize},
+		{"termite", 4000, 80, BasisType}, // one large sample is not enough
+		{"termite", 0, 80, BasisType},
+		{"mosquito", 900, 45, BasisDefault},
+	}
+	for _, tc := range cases {
+		got, err := svc.Estimate(tc.serviceType, tc.sqft)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Minutes != tc.minutes || got.Basis != tc.basis {
+			t.Errorf("%s/%d: expected %.0f via %s, got %+v", tc.serviceType, tc.sqft, tc.minutes, tc.basis, got)
+		}
+	}
+
+	_, err := svc.Record(Observation{JobID: "j4", ServiceType: "termite", StartedAt: at(9, 0), CompletedAt: at(8, 0)})
+	if !errors.Is(err, ErrInvalidObservation) {
+		t.Fatalf("expected invalid observation, got %v", err)
+	}
+}
+
+func TestCheckRouteFlagsOverbookedAndLateStops(t *testing.T) {
+	svc, store := newTestService(t)
+	route := models.Route{
+		ID:           "r1",
+		TechnicianID: "tech-1",
+		ServiceDate:  day,
+		CustomerStops: []models.RouteStop{
+			{CustomerID: "c1", CustomerName: "One"},
+			{CustomerID: "c2", CustomerName: "Two", WindowStart: at(10, 0), WindowEnd: at(10, 30)},
+			{CustomerID: "c3", CustomerName: "Three", WindowStart: at(10, 0), WindowEnd: at(11, 0)},
+			{CustomerID: "c4", CustomerName: "Four"},
+			{CustomerID: "c5", CustomerName: "Five"},
+		},
+	}
+	_ = store.SaveRoute(route)
+
+	f, err := svc.Feasibility("tech-1", day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 5 x 45m on site + 4 x 15m driving = 285m against a 240m shift.
+	if !f.Overbooked || f.TotalMinutes != 285 || f.OverByMinutes != 45 || f.DriveMinutes != 60 {
+		t.Fatalf("unexpected feasibility %+v", f)
+	}
+	// One finishes 8:45, Two waits for 10:00, Three arrives 11:00 on the edge.
+	if !f.Stops[1].Arrival.Equal(at(10, 0)) || f.Stops[2].Late || f.LateStops != 0 {
+		t.Fatalf("unexpected stop projections %+v", f.Stops)
+	}
+
+	warnings := svc.CheckRoutes([]models.Route{route})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "overbooked by 45 minutes") {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	if _, err := svc.Feasibility("tech-2", day); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestCheckRouteUsesBranchCalendar(t *testing.T) {
+	svc, store := newTestService(t)
+	store.AddTechnician(models.Technician{ID: "tech-1", Region: "north"})
+	calendars, err := calendar.NewService(config.CalendarConfig{TimeZone: "UTC", Open: "08:00", Close: "17:00", Days: []string{"mon"}}, calendar.NewMemoryStore(), svc.repos, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours := []calendar.DayHours{{Day: "thu", Open: "07:00", Close: "15:00"}, {Day: "fri", Open: "07:00", Close: "15:00"}}
+	if _, err := calendars.Save("north", calendar.Calendar{TimeZone: "UTC", Hours: hours, Holidays: []calendar.Holiday{{Date: "2026-04-03", Name: "Good Friday"}}}); err != nil {
+		t.Fatal(err)
+	}
+	svc.calendars = calendars
+
+	open := models.Route{TechnicianID: "tech-1", ServiceDate: day, CustomerStops: []models.RouteStop{{CustomerName: "One"}}}
+	f, err := svc.CheckRoute(open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Closed || !f.Stops[0].Arrival.Equal(at(7, 0)) {
+		t.Fatalf("expected the day to start when the branch opens, got %+v", f)
+	}
+
+	holiday := open
+	holiday.ServiceDate = day.AddDate(0, 0, 1)
+	warnings := svc.CheckRoutes([]models.Route{holiday})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "booked on Good Friday") {
+		t.Fatalf("expected a closed-day warning, got %v", warnings)
+	}
+}
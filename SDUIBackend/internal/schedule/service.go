@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/connector"
+)
+
+// Estimate bases.
+const (
+	BasisTypeAndSize = "service_type_and_size"
+	BasisType        = "service_type"
+	BasisDefault     = "default"
+)
+
+// Service records on-site durations, estimates new jobs from them, and checks
+// routes against the shift length.
+type Service struct {
+	cfg       config.ScheduleConfig
+	store     Store
+	repos     repository.Repository
+	events    *connector.Service
+	calendars *calendar.Service
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService wires a schedule service. Overbooked routes found at ingestion
+// are published to events; a nil events disables publishing. Days start when
+// the technician's branch opens according to calendars; with nil calendars
+// they start at the configured ShiftStart and every day is a working day.
+func NewService(cfg config.ScheduleConfig, store Store, repos repository.Repository, events *connector.Service, calendars *calendar.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, events: events, calendars: calendars, logger: logger, now: time.Now}
+}
+
+// Record stores the on-site time of a completed job.
+func (s *Service) Record(o Observation) (Observation, error) {
+	o.ServiceType = normalizeType(o.ServiceType)
+	if err := o.Validate(); err != nil {
+		return Observation{}, err
+	}
+	o.ID = uuid.NewString()
+	o.SizeBucket = SizeBucket(o.PropertySqFt)
+	o.Minutes = o.CompletedAt.Sub(o.StartedAt).Minutes()
+	o.RecordedAt = s.now().UTC()
+	if err := s.store.SaveObservation(o); err != nil {
+		return Observation{}, err
+	}
+	return o, nil
+}
+
+// Observation returns the on-site time recorded for a job, or ErrNotFound.
+func (s *Service) Observation(jobID string) (Observation, error) {
+	return s.store.GetObservation(jobID)
+}
+
+// Estimate is the expected on-site time for a job.
+type Estimate struct {
+	ServiceType string  `json:"serviceType,omitempty"`
+	SizeBucket  string  `json:"sizeBucket,omitempty"`
+	Minutes     float64 `json:"minutes"`
+	Samples     int     `json:"samples"`
+	Basis       string  `json:"basis"`
+}
+
+// Estimate returns the median of recent observations for the service type
+// and property size, falling back to the service type alone and then to the
+// configured default when there are fewer than MinSamples.
+func (s *Service) Estimate(serviceType string, propertySqFt int) (Estimate, error) {
+	serviceType = normalizeType(serviceType)
+	bucket := SizeBucket(propertySqFt)
+	out := Estimate{ServiceType: serviceType, SizeBucket: bucket, Minutes: s.cfg.DefaultJobDuration.Minutes(), Basis: BasisDefault}
+	if serviceType == "" {
+		return out, nil
+	}
+
+	attempts := []struct{ bucket, basis string }{{bucket, BasisTypeAndSize}, {"", BasisType}}
+	if bucket == SizeUnknown {
+		attempts = attempts[1:]
+	}
+	for _, a := range attempts {
+		observed, err := s.store.ListObservations(serviceType, a.bucket, s.cfg.SampleWindow)
+		if err != nil {
+			return Estimate{}, err
+		}
+		if len(observed) >= s.cfg.MinSamples {
+			out.Minutes, out.Samples, out.Basis = median(observed), len(observed), a.basis
+			return out, nil
+		}
+	}
+	return out, nil
+}
+
+func median(observed []Observation) float64 {
+	minutes := make([]float64, len(observed))
+	for i, o := range observed {
+		minutes[i] = o.Minutes
+	}
+	sort.Float64s(minutes)
+	mid := len(minutes) / 2
+	if len(minutes)%2 == 0 {
+		return math.Round((minutes[mid-1]+minutes[mid])/2*10) / 10
+	}
+	return minutes[mid]
+}
+
+func normalizeType(serviceType string) string {
+	return strings.ToLower(strings.TrimSpace(serviceType))
+}
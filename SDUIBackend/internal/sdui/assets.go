@@ -0,0 +1,110 @@
+package sdui
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// ErrMissingAssets is returned when a template names assets that were never
+// uploaded.
+var ErrMissingAssets = apperr.Conflict("missing_assets", "missing assets")
+
+// AssetChanged records an asset's current URL, or forgets the asset when url
+// is empty. Screens are served the new URL from the next request.
+func (s *Service) AssetChanged(id, url string) {
+	s.assetsMu.Lock()
+	defer s.assetsMu.Unlock()
+	if url == "" {
+		delete(s.assetURLs, id)
+	} else {
+		s.assetURLs[id] = url
+	}
+}
+
+// AssetReferences returns the IDs of the assets named by the templates in
+// the cache, which Precompile fills with those on disk, and by every
+// published version, so none of them is garbage-collected.
+func (s *Service) AssetReferences() (map[string]bool, error) {
+	refs := make(map[string]bool)
+	s.templates.mu.RLock()
+	for _, tpl := range s.templates.compiled {
+		collectAssets(tpl.screen.Component, refs)
+	}
+	s.templates.mu.RUnlock()
+
+	all, err := s.repos.Screens.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for _, tpl := range all {
+		var screen models.SDUIScreen
+		if err := json.Unmarshal(tpl.PayloadJSON, &screen); err != nil {
+			continue
+		}
+		collectAssets(screen.Component, refs)
+	}
+	return refs, nil
+}
+
+// checkAssets fails with ErrMissingAssets when the template names an asset
+// that does not exist.
+func (s *Service) checkAssets(payload []byte) error {
+	var screen models.SDUIScreen
+	if err := json.Unmarshal(payload, &screen); err != nil {
+		return ErrInvalidTemplate.Detailf("%v", err)
+	}
+	refs := make(map[string]bool)
+	collectAssets(screen.Component, refs)
+	s.assetsMu.RLock()
+	var missing []string
+	for id := range refs {
+		if _, ok := s.assetURLs[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	s.assetsMu.RUnlock()
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return ErrMissingAssets.Detailf("%s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// resolveAssets sets the image URL of every component naming a known asset,
+// item views included.
+func (s *Service) resolveAssets(c *models.SDUIComponent) {
+	s.assetsMu.RLock()
+	defer s.assetsMu.RUnlock()
+	if len(s.assetURLs) == 0 {
+		return
+	}
+	var walk func(c *models.SDUIComponent)
+	walk = func(c *models.SDUIComponent) {
+		if c.Asset != "" {
+			c.ImageURL = s.assetURLs[c.Asset]
+		}
+		for i := range c.Children {
+			walk(&c.Children[i])
+		}
+		if c.ItemView != nil {
+			walk(c.ItemView)
+		}
+	}
+	walk(c)
+}
+
+func collectAssets(c models.SDUIComponent, refs map[string]bool) {
+	if c.Asset != "" {
+		refs[c.Asset] = true
+	}
+	for _, child := range c.Children {
+		collectAssets(child, refs)
+	}
+	if c.ItemView != nil {
+		collectAssets(*c.ItemView, refs)
+	}
+}
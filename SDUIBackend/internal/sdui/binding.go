@@ -0,0 +1,172 @@
+package sdui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Policies for placeholders the server cannot resolve.
+const (
+	UnresolvedKeep  = "keep"  // leave {{key}} for the client to resolve
+	UnresolvedBlank = "blank" // substitute an empty string
+	UnresolvedDrop  = "drop"  // remove the component carrying the placeholder
+)
+
+// Resolver looks up placeholder values by key.
+type Resolver interface {
+	Resolve(key string) (string, bool)
+}
+
+// binder interpolates {{key}} placeholders in a component tree. Conditional
+// components whose conditionKey resolves are evaluated on the server: a
+// truthy value renders their children in a vstack, a falsy one removes them.
+// conditionalFlag components are always evaluated, against flags.<flagKey>.
+// Components with a textKey are translated before they are interpolated, so
+// translations may carry placeholders.
+// List item views are rendered per element by the client against element
+// data and are left alone.
+type binder struct {
+	resolver   Resolver
+	unresolved string
+	// translator resolves textKey; nil leaves the fallback text.
+	translator *i18n.Translator
+	// dynamic, when set, restricts the walk to these component paths (see
+	// componentPath); everything else is known to be static.
+	dynamic map[string]bool
+}
+
+// bind interpolates the screen in place.
+func (b binder) bind(screen *models.SDUIScreen) {
+	if b.dynamic != nil && !b.dynamic["0"] {
+		return
+	}
+	if !b.component(&screen.Component, "0") {
+		// The root cannot be removed; render an empty screen instead.
+		screen.Component = models.SDUIComponent{ID: screen.Component.ID, Type: "vstack"}
+	}
+}
+
+// component interpolates c and reports whether it should be kept.
+func (b binder) component(c *models.SDUIComponent, path string) bool {
+	if c.Type == "conditional" && c.ConditionKey != "" {
+		if value, ok := b.resolver.Resolve(c.ConditionKey); ok {
+			if !truthy(value) {
+				return false
+			}
+			c.Type, c.ConditionKey = "vstack", ""
+		}
+	}
+	if c.Type == "conditionalFlag" {
+		if !b.flagOn(*c) {
+			return false
+		}
+		c.Type, c.FlagKey, c.FlagValue, c.FlagDefault = "vstack", "", "", false
+	}
+
+	if c.TextKey != "" {
+		if value, ok := b.translator.Text(c.TextKey); ok {
+			if c.Text == "" && c.Label != "" {
+				c.Label = value
+			} else {
+				c.Text = value
+			}
+		}
+	}
+
+	keep := true
+	for _, field := range []*string{&c.Text, &c.Label, &c.Placeholder} {
+		value, resolved := b.interpolate(*field)
+		*field = value
+		keep = keep && resolved
+	}
+	if !keep && b.unresolved == UnresolvedDrop {
+		return false
+	}
+
+	if len(c.Children) > 0 {
+		children := c.Children[:0]
+		for i := range c.Children {
+			child := c.Children[i]
+			childPath := componentPath(path, i)
+			if b.dynamic == nil || b.dynamic[childPath] {
+				if !b.component(&child, childPath) {
+					continue
+				}
+			}
+			children = append(children, child)
+		}
+		c.Children = children
+	}
+	return true
+}
+
+// interpolate substitutes placeholders in s and reports whether all of them
+// resolved. Unresolved placeholders follow the binder's policy, except under
+// drop, where they are kept so the caller can remove the component.
+func (b binder) interpolate(s string) (string, bool) {
+	if !hasPlaceholder(s) {
+		return s, true
+	}
+	var out strings.Builder
+	resolved := true
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start
+		out.WriteString(s[:start])
+		if value, ok := b.resolver.Resolve(strings.TrimSpace(s[start+2 : end])); ok {
+			out.WriteString(value)
+		} else {
+			resolved = false
+			if b.unresolved != UnresolvedBlank {
+				out.WriteString(s[start : end+2])
+			}
+		}
+		s = s[end+2:]
+	}
+	out.WriteString(s)
+	return out.String(), resolved
+}
+
+// flagOn reports whether a conditionalFlag renders its children.
+func (b binder) flagOn(c models.SDUIComponent) bool {
+	value, ok := b.resolver.Resolve("flags." + c.FlagKey)
+	switch {
+	case !ok:
+		return c.FlagDefault
+	case c.FlagValue != "":
+		return value == c.FlagValue
+	}
+	return truthy(value)
+}
+
+// truthy mirrors the client's conditional check: present and non-empty,
+// with "false" and "0" treated as false.
+func truthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0":
+		return false
+	}
+	return true
+}
+
+// mapResolver resolves from a fixed map.
+type mapResolver map[string]string
+
+func (m mapResolver) Resolve(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func formatBool(b bool) string {
+	return strconv.FormatBool(b)
+}
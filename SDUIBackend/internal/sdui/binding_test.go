@@ -0,0 +1,232 @@
+package sdui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+)
+
+func TestBinderUnresolvedPolicies(t *testing.T) {
+	screen := func() models.SDUIScreen {
+		return models.SDUIScreen{Component: models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+			{Type: "text", Text: "Hi {{name}}"},
+			{Type: "text", Text: "Synced {{lastSync}}"},
+			{Type: "button", Label: "{{ name }}", ActionID: "go"},
+		}}}
+	}
+	resolver := mapResolver{"name": "Ana"}
+
+	cases := map[string][]string{
+		UnresolvedKeep:  {"Hi Ana", "Synced {{lastSync}}", "Ana"},
+		UnresolvedBlank: {"Hi Ana", "Synced ", "Ana"},
+		UnresolvedDrop:  {"Hi Ana", "Ana"},
+	}
+	for policy, want := range cases {
+		s := screen()
+		binder{resolver: resolver, unresolved: policy}.bind(&s)
+		var got []string
+		for _, c := range s.Component.Children {
+			got = append(got, c.Text+c.Label)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %v, got %v", policy, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected %q, got %q", policy, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestBinderEvaluatesResolvedConditionals(t *testing.T) {
+	s := models.SDUIScreen{Component: models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+		{Type: "conditional", ConditionKey: "shown", Children: []models.SDUIComponent{{Type: "text", Text: "yes"}}},
+		{Type: "conditional", ConditionKey: "hidden", Children: []models.SDUIComponent{{Type: "text", Text: "no"}}},
+		{Type: "conditional", ConditionKey: "pinnedNotes", Children: []models.SDUIComponent{{Type: "text", Key: "pinnedNotes"}}},
+	}}}
+	binder{resolver: mapResolver{"shown": "true", "hidden": "false"}, unresolved: UnresolvedKeep}.bind(&s)
+
+	children := s.Component.Children
+	if len(children) != 2 {
+		t.Fatalf("expected the false conditional to be removed, got %+v", children)
+	}
+	if children[0].Type != "vstack" || children[0].ConditionKey != "" || children[0].Children[0].Text != "yes" {
+		t.Errorf("expected true conditional to render its children, got %+v", children[0])
+	}
+	if children[1].Type != "conditional" {
+		t.Errorf("conditionals the server cannot resolve are left for the client, got %+v", children[1])
+	}
+}
+
+func TestConditionalFlagComposesScreens(t *testing.T) {
+	s := models.SDUIScreen{Component: models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+		{Type: "conditionalFlag", FlagKey: "on", Children: []models.SDUIComponent{{Type: "text", Text: "on"}}},
+		{Type: "conditionalFlag", FlagKey: "off", Children: []models.SDUIComponent{{Type: "text", Text: "off"}}},
+		{Type: "conditionalFlag", FlagKey: "layout", FlagValue: "compact", Children: []models.SDUIComponent{{Type: "text", Text: "compact"}}},
+		{Type: "conditionalFlag", FlagKey: "missing", FlagDefault: true, Children: []models.SDUIComponent{{Type: "text", Text: "default"}}},
+		{Type: "conditionalFlag", FlagKey: "missing", Children: []models.SDUIComponent{{Type: "text", Text: "hidden"}}},
+	}}}
+	binder{resolver: mapResolver{"flags.on": "true", "flags.off": "false", "flags.layout": "compact"}, unresolved: UnresolvedKeep}.bind(&s)
+	var got []string
+	for _, c := range s.Component.Children {
+		if c.Type != "vstack" || c.FlagKey != "" {
+			t.Errorf("expected conditionalFlag resolved to a vstack, got %+v", c)
+		}
+		got = append(got, c.Children[0].Text)
+	}
+	if strings.Join(got, ",") != "on,compact,default" {
+		t.Fatalf("unexpected sections %q", got)
+	}
+
+	svc, store := newTestService(t, "")
+	provider, err := flags.NewEnvProvider(GamificationMetricsFlag + "=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.flags = flags.NewClient(provider, nil)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana"})
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(res.Screen)
+	if body := string(data); strings.Contains(body, "Jobs today") || !strings.Contains(body, "Communications") || strings.Contains(body, "conditionalFlag") {
+		t.Fatalf("expected the metrics row hidden and communications shown, got %s", body)
+	}
+}
+
+func TestDefaultScreenResolvesStatsAndAlerts(t *testing.T) {
+	svc, store := newTestService(t, "")
+	day := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC) // a Wednesday
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana", Email: "ana@example.com"})
+	_ = store.SaveRoute(domain.Route{
+		ID: "r1", TechnicianID: "t1", ServiceDate: day,
+		Alerts: []domain.RouteAlert{
+			{Type: "customer", Message: "Gate code 1234"},
+			{Type: "compliance", Message: "Renew applicator licence"},
+		},
+	})
+	for i, d := range []int{-3, -2, -1, 0, 0} {
+		_ = store.SaveJobUpload(domain.JobUpload{
+			ID: "j" + string(rune('a'+i)), TechnicianID: "t1", Status: "completed",
+			ScheduledDate: day.AddDate(0, 0, d),
+		})
+	}
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "other", TechnicianID: "t2", Status: "completed", ScheduledDate: day})
+
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1", ServiceDate: day})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	texts := make(map[string]bool)
+	var walk func(c models.SDUIComponent)
+	walk = func(c models.SDUIComponent) {
+		if c.Type == "conditional" {
+			t.Errorf("expected server-side conditionals to be evaluated, got %+v", c)
+		}
+		texts[c.Text] = true
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(res.Screen.Component)
+
+	for _, want := range []string{"2", "4", "4 days", "Gate code 1234", "Renew applicator licence"} {
+		if !texts[want] {
+			t.Errorf("expected rendered text %q", want)
+		}
+	}
+	for text := range texts {
+		if hasPlaceholder(text) {
+			t.Errorf("unexpected unresolved text %q", text)
+		}
+	}
+}
+
+// stubWeather warns about every route.
+type stubWeather struct{}
+
+func (stubWeather) RouteAlerts(_ context.Context, route domain.Route, _ time.Time) []domain.RouteAlert {
+	return []domain.RouteAlert{{Type: weatherAlert, Message: "the barn: High wind 30 km/h — avoid spraying", Severity: "warning"}}
+}
+
+func TestWeatherAlertsResolve(t *testing.T) {
+	svc, store := newTestService(t, "")
+	svc.weather = stubWeather{}
+	day := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana"})
+	_ = store.SaveRoute(domain.Route{ID: "r1", TechnicianID: "t1", ServiceDate: day, Alerts: []domain.RouteAlert{{Type: "customer", Message: "Gate code 1234"}}})
+	if _, err := svc.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"vstack","children":[{"type":"text","text":"{{route.hasWeatherWarnings}}"},{"type":"text","text":"{{route.weatherSummary}}"},{"type":"text","text":"{{route.alertCount}}"}]}}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1", ServiceDate: day})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range res.Screen.Component.Children {
+		got = append(got, c.Text)
+	}
+	if len(got) != 3 || got[0] != "true" || got[1] != "the barn: High wind 30 km/h — avoid spraying" || got[2] != "2" {
+		t.Fatalf("expected the weather warning alongside the route's alerts, got %q", got)
+	}
+	if route, _ := store.GetRoute("t1", day); len(route.Alerts) != 1 {
+		t.Fatalf("expected the stored route left alone, got %+v", route.Alerts)
+	}
+}
+
+func TestFlagsTargetScreens(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "broken.json", `{"version":1,"component":{"type":"vstack","children":[{"type":"button","label":"Go"}]}}`)
+	svc, store := newTestService(t, dir)
+	provider, err := flags.NewStaticProvider(map[string]flags.Flag{
+		"newHome":             {Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off", Rules: []flags.Rule{{Attribute: "appVersion", In: []string{"3.2.0"}, Variant: "on"}}},
+		"banner":              {Variants: map[string]any{"spring": "Spring promo"}, DefaultVariant: "spring"},
+		ValidateResponsesFlag: {Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off", Rules: []flags.Rule{{Attribute: "region", In: []string{"qa"}, Variant: "on"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.flags = flags.NewClient(provider, nil)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana"})
+	store.AddTechnician(domain.Technician{ID: "t2", DisplayName: "Sam", Region: "QA"})
+	if _, err := svc.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"vstack","children":[{"type":"conditional","conditionKey":"flags.newHome","children":[{"type":"text","text":"New home"}]},{"type":"text","text":"{{flags.banner}}"},{"type":"text","text":"{{flags.missing}}"}]}}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	texts := func(res Result) []string {
+		var got []string
+		for _, c := range res.Screen.Component.Children {
+			if c.Type == "vstack" {
+				c = c.Children[0]
+			}
+			got = append(got, c.Text)
+		}
+		return got
+	}
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1", AppVersion: "3.2.0"})
+	if got := texts(res); err != nil || len(got) != 3 || got[0] != "New home" || got[1] != "Spring promo" || got[2] != "{{flags.missing}}" {
+		t.Fatalf("expected the flagged section and banner, got %q (%v)", got, err)
+	}
+	res, err = svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1", AppVersion: "3.1.0"})
+	if got := texts(res); err != nil || len(got) != 2 || got[0] != "Spring promo" {
+		t.Fatalf("expected the flagged section left out, got %q (%v)", got, err)
+	}
+
+	if _, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "broken", UserID: "t1"}); err != nil {
+		t.Fatalf("expected no validation without the flag, got %v", err)
+	}
+	if _, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "broken", UserID: "t2"}); !errors.Is(err, validate.ErrInvalid) {
+		t.Fatalf("expected the flag to turn validation on for QA, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package sdui
+
+import (
+	"context"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Brander returns the branding screens served on ctx are drawn with, or
+// nil for none; *tenant.Service implements it.
+type Brander interface {
+	Theme(ctx context.Context) *models.ScreenTheme
+}
+
+// brand returns screen with theme attached and the brand color tokens in
+// its components replaced. The component tree is copied first, since the
+// rendered screen is also kept for serving stale.
+func brand(screen models.SDUIScreen, theme *models.ScreenTheme) models.SDUIScreen {
+	if theme == nil {
+		return screen
+	}
+	screen.Theme = theme
+	screen.Component = cloneComponent(screen.Component)
+	brandComponent(&screen.Component, theme)
+	return screen
+}
+
+func brandComponent(c *models.SDUIComponent, theme *models.ScreenTheme) {
+	c.Color = brandColor(c.Color, theme)
+	c.Foreground = brandColor(c.Foreground, theme)
+	c.Background = brandColor(c.Background, theme)
+	for i := range c.Children {
+		brandComponent(&c.Children[i], theme)
+	}
+	if c.ItemView != nil {
+		brandComponent(c.ItemView, theme)
+	}
+}
+
+// brandColor resolves a brand color token. Tokens for colors the theme
+// lacks are left for the client's own palette.
+func brandColor(color string, theme *models.ScreenTheme) string {
+	var v string
+	switch color {
+	case models.BrandPrimary:
+		v = theme.PrimaryColor
+	case models.BrandSecondary:
+		v = theme.SecondaryColor
+	case models.BrandAccent:
+		v = theme.AccentColor
+	}
+	if v == "" {
+		return color
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package sdui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// screenCache remembers the last successfully rendered screen per request so
+// it can be served stale while the datastore is in brownout.
+type screenCache struct {
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]cachedScreen
+}
+
+type cachedScreen struct {
+	screen   models.SDUIScreen
+	storedAt time.Time
+}
+
+func newScreenCache(ttl time.Duration) *screenCache {
+	return &screenCache{ttl: ttl, entries: make(map[string]cachedScreen)}
+}
+
+func (c *screenCache) get(key string) (cachedScreen, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.storedAt) > c.ttl {
+		return cachedScreen{}, false
+	}
+	return entry, true
+}
+
+func (c *screenCache) put(key string, screen models.SDUIScreen) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.Sub(entry.storedAt) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedScreen{screen: screen, storedAt: now}
+}
+
+// cacheKey identifies the personalisation inputs that shape a screen.
+func cacheKey(req models.ScreenRequest) string {
+	date := ""
+	if !req.ServiceDate.IsZero() {
+		date = req.ServiceDate.Format("2006-01-02")
+	}
+	return strings.Join([]string{req.ScreenID, req.UserID, req.RouteID, date, req.Locale, DeviceClass(req.DeviceModel), req.AppVersion}, "|")
+}
@@ -0,0 +1,271 @@
+package sdui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// statsLookback bounds the job history read for technician stats, and so the
+// longest streak that can be reported.
+const statsLookback = 35 * 24 * time.Hour
+
+// complianceAlert is the route alert type that carries compliance tasks.
+const complianceAlert = "compliance"
+
+// weatherAlert is the route alert type of spray condition warnings.
+const weatherAlert = "weather"
+
+// contextResolver resolves placeholders against a domain.ScreenContext:
+//
+//	technician.id, technician.name, technician.region
+//	route.id, route.label, route.stopCount, route.alertCount,
+//	route.alertSummary, route.hasCustomerAlerts, route.hasComplianceTasks,
+//	route.complianceHeadline, route.hasWeatherWarnings, route.weatherSummary
+//	serviceDate
+//	todayJobsCompleted, weekJobsCompleted, activeStreak, lastSync,
+//	profileCompleteness
+//	metadata.<key>
+//	flags.<key>
+//
+// Technician stats read job history, so they are computed on first use,
+// and flags are evaluated as they are used; without withFlags, flags are
+// unresolved.
+type contextResolver struct {
+	sc          domain.ScreenContext
+	serviceDate time.Time
+	values      map[string]string
+
+	sync       repository.SyncRepository
+	translator *i18n.Translator
+	logger     *slog.Logger
+	statsOnce  sync.Once
+	stats      map[string]string
+
+	ctx     context.Context
+	flags   FlagEvaluator
+	flagCtx flags.FlattenedContext
+	flagged map[string]flagValue
+}
+
+// flagValue is a flag as evaluated for the screen.
+type flagValue struct {
+	value string
+	ok    bool
+}
+
+// newScreenContext gathers the request, technician, and route into a
+// domain.ScreenContext. Device details are exposed as metadata.
+func newScreenContext(req models.ScreenRequest, tech domain.Technician, route domain.Route) domain.ScreenContext {
+	if route.ID == "" {
+		route.ID = req.RouteID
+	}
+	metadata := make(map[string]string)
+	for key, value := range map[string]string{
+		"deviceModel": req.DeviceModel,
+		"appVersion":  req.AppVersion,
+		"locale":      req.Locale,
+		"screenId":    req.ScreenID,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return domain.ScreenContext{Technician: tech, Route: route, Metadata: metadata}
+}
+
+// resolver builds the placeholder values for a screen. Labels and dates
+// are rendered in t's locale.
+func (s *Service) resolver(sc domain.ScreenContext, serviceDate time.Time, uploads repository.SyncRepository, t *i18n.Translator) *contextResolver {
+	if serviceDate.IsZero() {
+		serviceDate = time.Now()
+	}
+	tech, route := sc.Technician, sc.Route
+
+	routeLabel := t.Lookup("route.none", "No route assigned")
+	if route.ID != "" {
+		routeLabel = t.Sprintf("route.label", "Route %s", route.ID)
+	}
+	name := tech.DisplayName
+	if name == "" {
+		name = t.Lookup("technician.fallbackName", "Technician")
+	}
+	var customerAlerts, compliance, weather []string
+	for _, alert := range route.Alerts {
+		if alert.Type == complianceAlert {
+			compliance = append(compliance, alert.Message)
+		} else {
+			customerAlerts = append(customerAlerts, alert.Message)
+		}
+		if alert.Type == weatherAlert {
+			weather = append(weather, alert.Message)
+		}
+	}
+
+	values := map[string]string{
+		"technician.id":            tech.ID,
+		"technician.name":          name,
+		"technician.region":        tech.Region,
+		"route.id":                 route.ID,
+		"route.label":              routeLabel,
+		"route.stopCount":          strconv.Itoa(len(route.CustomerStops)),
+		"route.alertCount":         strconv.Itoa(len(route.Alerts)),
+		"route.alertSummary":       alertSummary(customerAlerts, t),
+		"route.hasCustomerAlerts":  formatBool(len(customerAlerts) > 0),
+		"route.hasComplianceTasks": formatBool(len(compliance) > 0),
+		"route.complianceHeadline": alertSummary(compliance, t),
+		"route.hasWeatherWarnings": formatBool(len(weather) > 0),
+		"route.weatherSummary":     alertSummary(weather, t),
+		"serviceDate":              t.FormatDate(serviceDate),
+	}
+	if tech.ID != "" {
+		values["profileCompleteness"] = profileCompleteness(tech)
+	}
+	return &contextResolver{
+		sc:          sc,
+		serviceDate: serviceDate,
+		values:      values,
+		sync:        uploads,
+		translator:  t,
+		logger:      s.logger,
+	}
+}
+
+// withFlags lets r resolve flags.<key> with evaluator, for the screen's
+// technician and device.
+func (r *contextResolver) withFlags(ctx context.Context, evaluator FlagEvaluator) *contextResolver {
+	r.ctx, r.flags = ctx, evaluator
+	return r
+}
+
+// flag evaluates a flag once per screen, so a flag used by several
+// components cannot change value halfway through a render.
+func (r *contextResolver) flag(key string) (string, bool) {
+	if v, ok := r.flagged[key]; ok {
+		return v.value, v.ok
+	}
+	if r.flagged == nil {
+		r.flagged = make(map[string]flagValue)
+		r.flagCtx = flags.TechnicianContext(r.sc.Technician, r.sc.Metadata)
+	}
+	value, ok := r.flags.Flag(r.ctx, key, r.flagCtx)
+	r.flagged[key] = flagValue{value: value, ok: ok}
+	return value, ok
+}
+
+// Resolve implements Resolver.
+func (r *contextResolver) Resolve(key string) (string, bool) {
+	if v, ok := r.values[key]; ok {
+		return v, true
+	}
+	if name, ok := strings.CutPrefix(key, "metadata."); ok {
+		v, ok := r.sc.Metadata[name]
+		return v, ok
+	}
+	if name, ok := strings.CutPrefix(key, "flags."); ok && r.flags != nil {
+		return r.flag(name)
+	}
+	switch key {
+	case "todayJobsCompleted", "weekJobsCompleted", "activeStreak", "lastSync":
+		r.statsOnce.Do(r.loadStats)
+		v, ok := r.stats[key]
+		return v, ok
+	}
+	return "", false
+}
+
+// loadStats computes job stats for the technician from recent sync history.
+// Failures leave the stats unresolved rather than failing the screen.
+func (r *contextResolver) loadStats() {
+	techID := r.sc.Technician.ID
+	if techID == "" || r.sync == nil {
+		return
+	}
+	today := startOfDay(r.serviceDate)
+	jobs, err := r.sync.ListJobUpdatesSince(today.Add(-statsLookback))
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Warn("screen stats unavailable", slog.String("technician", techID), slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	completedOn := make(map[time.Time]int)
+	var lastSync time.Time
+	for _, job := range jobs {
+		if job.TechnicianID != techID {
+			continue
+		}
+		if job.ReceivedAt.After(lastSync) {
+			lastSync = job.ReceivedAt
+		}
+		if job.Status != "completed" {
+			continue
+		}
+		day := job.ScheduledDate
+		if day.IsZero() {
+			day = job.ReceivedAt
+		}
+		completedOn[startOfDay(day)]++
+	}
+
+	week := 0
+	for day := weekStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		week += completedOn[day]
+	}
+	// A streak is not broken by a day that has not finished yet.
+	streak, day := 0, today
+	if completedOn[day] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for ; completedOn[day] > 0; day = day.AddDate(0, 0, -1) {
+		streak++
+	}
+
+	r.stats = map[string]string{
+		"todayJobsCompleted": strconv.Itoa(completedOn[today]),
+		"weekJobsCompleted":  strconv.Itoa(week),
+		"activeStreak":       strconv.Itoa(streak),
+	}
+	if !lastSync.IsZero() {
+		r.stats["lastSync"] = r.translator.FormatDateTime(lastSync.UTC())
+	}
+}
+
+func alertSummary(messages []string, t *i18n.Translator) string {
+	switch len(messages) {
+	case 0:
+		return ""
+	case 1:
+		return messages[0]
+	}
+	return t.Sprintf("alerts.more", "%s (+%d more)", messages[0], len(messages)-1)
+}
+
+// profileCompleteness is the share of optional profile fields filled in.
+func profileCompleteness(tech domain.Technician) string {
+	fields := []bool{tech.DisplayName != "", tech.Email != "", tech.Role != "", tech.Region != "", len(tech.Certifications) > 0}
+	filled := 0
+	for _, ok := range fields {
+		if ok {
+			filled++
+		}
+	}
+	return strconv.Itoa(filled*100/len(fields)) + "%"
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,113 @@
+package sdui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Device classes screens are resolved for.
+const (
+	DeviceClassPhone  = "phone"
+	DeviceClassTablet = "tablet"
+	DeviceClassWatch  = "watch"
+)
+
+// Watch limits used when ScreenConfig leaves them unset.
+const (
+	defaultWatchMaxItems = 5
+	defaultWatchMaxText  = 80
+)
+
+// DeviceClass derives the device class from a model name or identifier
+// such as "iPhone15,2", "iPad13,4" or "Watch6,1". Unknown models are
+// phones.
+func DeviceClass(model string) string {
+	m := strings.ToLower(model)
+	switch {
+	case strings.Contains(m, "watch"):
+		return DeviceClassWatch
+	case strings.HasPrefix(m, "ipad") || strings.Contains(m, "tablet"):
+		return DeviceClassTablet
+	default:
+		return DeviceClassPhone
+	}
+}
+
+// classScreenID names the template of screenID written for a device class,
+// such as home.watch, on disk or in the ScreenRepository.
+func classScreenID(screenID, class string) string {
+	return screenID + "." + class
+}
+
+// watchHidden are component types that cannot be used at a glance on a
+// watch: rich media, text entry and the workflow tools built for the phone.
+var watchHidden = map[string]bool{
+	"image": true, "mapView": true, "webView": true, "chart": true, "spacer": true,
+	"textField": true, "slider": true, "picker": true, "datePicker": true, "stepper": true, "segmentedControl": true,
+	"actionSheet": true, "qrScanner": true,
+	"equipmentInspector": true, "equipmentSelector": true, "digitalChecklist": true, "maintenanceScheduler": true, "calibrationTracker": true,
+	"weatherDashboard": true, "weatherForecast": true, "weatherMetrics": true, "treatmentConditions": true,
+	"chemicalSelector": true, "dosageCalculator": true, "chemicalInventory": true, "treatmentLogger": true, "epaCompliance": true, "mixingInstructions": true, "applicationTracker": true, "chemicalSearch": true,
+}
+
+// watchStacked are layouts too wide for a watch, restacked vertically.
+var watchStacked = map[string]bool{"hstack": true, "grid": true, "tabView": true}
+
+// watchFonts shrinks display fonts to what fits a watch face.
+var watchFonts = map[string]string{"largeTitle": "headline", "title": "headline", "title2": "headline", "title3": "headline"}
+
+// containerTypes lose their purpose once every child is simplified away.
+var containerTypes = map[string]bool{"vstack": true, "hstack": true, "list": true, "scroll": true, "grid": true, "tabView": true, "section": true}
+
+// compactor simplifies a rendered screen for a watch that has no template
+// of its own.
+type compactor struct {
+	maxItems int
+	maxText  int
+}
+
+// compact simplifies c in place and reports whether it should be kept:
+// hidden types are removed, wide layouts restacked, fonts shrunk, text
+// shortened, and containers cut to maxItems children, then dropped if
+// nothing is left in them.
+func (k compactor) compact(c *models.SDUIComponent) bool {
+	if watchHidden[c.Type] {
+		return false
+	}
+	if watchStacked[c.Type] {
+		c.Type = "vstack"
+	}
+	if font, ok := watchFonts[c.Font]; ok {
+		c.Font = font
+	}
+	c.Text = k.shorten(c.Text)
+	c.Label = k.shorten(c.Label)
+	if c.ItemView != nil && !k.compact(c.ItemView) {
+		c.ItemView = nil
+	}
+
+	kept := c.Children[:0]
+	for i := range c.Children {
+		if k.compact(&c.Children[i]) {
+			kept = append(kept, c.Children[i])
+		}
+	}
+	if len(kept) > k.maxItems {
+		kept = kept[:k.maxItems]
+	}
+	if c.Children != nil {
+		c.Children = kept
+	}
+	return !containerTypes[c.Type] || len(c.Children) > 0 || c.ItemView != nil
+}
+
+// shorten cuts text to maxText characters, ending with an ellipsis.
+func (k compactor) shorten(s string) string {
+	if utf8.RuneCountInString(s) <= k.maxText {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:k.maxText-1])) + "…"
+}
@@ -0,0 +1,99 @@
+package sdui
+
+import (
+	"github.com/your-org/pestgenie-sdui/internal/appversion"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// versionGate holds back components an app build is too old to render,
+// since older builds fail to decode component types they do not know.
+type versionGate struct {
+	// minVersions is the first app version that renders each component
+	// type.
+	minVersions map[string]string
+	// fallback is shown in place of held-back components; nil removes
+	// them.
+	fallback *models.SDUIComponent
+}
+
+// apply gates a rendered screen for appVersion. A screen the build is too
+// old for is replaced by the fallback alone. Builds that do not report a
+// version are served everything. prepare renders each fallback copy before
+// it is placed, so it can be translated.
+func (g versionGate) apply(screen *models.SDUIScreen, appVersion string, prepare func(*models.SDUIComponent)) {
+	if appVersion == "" {
+		return
+	}
+	if appversion.Below(appVersion, screen.MinAppVersion) || !g.supports(screen.Component, appVersion) {
+		root := models.SDUIComponent{ID: screen.Component.ID, Type: "vstack"}
+		if fb, ok := g.replacement(screen.Component, prepare); ok {
+			root.Children = []models.SDUIComponent{fb}
+		}
+		screen.Component = root
+		return
+	}
+	g.walk(&screen.Component, appVersion, prepare)
+}
+
+// supports reports whether appVersion can render c itself, by its own
+// minAppVersion and its type's.
+func (g versionGate) supports(c models.SDUIComponent, appVersion string) bool {
+	if appversion.Below(appVersion, c.MinAppVersion) {
+		return false
+	}
+	min, ok := g.minVersions[c.Type]
+	return !ok || !appversion.Below(appVersion, min)
+}
+
+// walk replaces unsupported descendants of c. A run of unsupported
+// siblings shares one fallback.
+func (g versionGate) walk(c *models.SDUIComponent, appVersion string, prepare func(*models.SDUIComponent)) {
+	if c.ItemView != nil {
+		if !g.supports(*c.ItemView, appVersion) {
+			if fb, ok := g.replacement(*c.ItemView, prepare); ok {
+				c.ItemView = &fb
+			} else {
+				c.ItemView = nil
+			}
+		} else {
+			g.walk(c.ItemView, appVersion, prepare)
+		}
+	}
+	if len(c.Children) == 0 {
+		return
+	}
+	kept := make([]models.SDUIComponent, 0, len(c.Children))
+	replaced := false
+	for _, child := range c.Children {
+		if g.supports(child, appVersion) {
+			g.walk(&child, appVersion, prepare)
+			kept = append(kept, child)
+			replaced = false
+			continue
+		}
+		if replaced {
+			continue
+		}
+		if fb, ok := g.replacement(child, prepare); ok {
+			kept = append(kept, fb)
+			replaced = true
+		}
+	}
+	c.Children = kept
+}
+
+// replacement returns a copy of the fallback standing in for c, under c's
+// ID so the client can match it against earlier screens.
+func (g versionGate) replacement(c models.SDUIComponent, prepare func(*models.SDUIComponent)) (models.SDUIComponent, bool) {
+	if g.fallback == nil {
+		return models.SDUIComponent{}, false
+	}
+	fb := cloneComponent(*g.fallback)
+	if fb.ID == "" {
+		fb.ID = c.ID
+	}
+	if prepare != nil {
+		prepare(&fb)
+	}
+	return fb, true
+}
@@ -2,29 +2,63 @@ package sdui
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"log/slog"
 
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
 	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
 	"github.com/your-org/pestgenie-sdui/internal/middleware"
 	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
 )
 
 // Handler exposes HTTP endpoints for SDUI screens.
 type Handler struct {
 	service *Service
+	feed    *activity.Service
+	brand   Brander
+	now     func() time.Time
+
+	legacyUserIDs  atomic.Int64
+	legacyRejected atomic.Int64
+}
+
+// NewHandler wires a Service into a HTTP presenter. Template changes are
+// recorded in the activity feed unless feed is nil, and served screens are
+// branded by brand unless it is nil.
+func NewHandler(service *Service, feed *activity.Service, brand Brander) *Handler {
+	return &Handler{service: service, feed: feed, brand: brand, now: time.Now}
 }
 
-// NewHandler wires a Service into a HTTP presenter.
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+// Routes mounts the admin endpoints for screen templates.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListTemplates)
+	r.Post("/", h.CreateTemplate)
+	r.Get("/precompile", h.GetPrecompileReport)
+	r.Post("/precompile", h.Precompile)
+	r.Post("/preview", h.Preview)
+	r.Get("/migrations", h.GetMigrations)
+	r.Get("/{screenId}", h.GetTemplate)
+	r.Put("/{screenId}", h.UpdateTemplate)
+	r.Delete("/{screenId}", h.DeleteTemplate)
+	r.Get("/{screenId}/versions", h.ListTemplateVersions)
+	r.Get("/{screenId}/strings", h.GetStrings)
+	r.Get("/{screenId}/coverage", h.GetCoverage)
+	r.Get("/{screenId}/versions/{version}", h.GetTemplate)
+	r.Delete("/{screenId}/versions/{version}", h.DeleteTemplate)
 }
 
-// GetScreen resolves a personalised screen for a technician.
+// GetScreen resolves a personalised screen for the authenticated technician.
 func (h *Handler) GetScreen(w http.ResponseWriter, r *http.Request) {
 	screenID := chi.URLParam(r, "screenId")
 	if screenID == "" {
@@ -32,6 +66,10 @@ func (h *Handler) GetScreen(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkLegacyUserID(w, r) {
+		return
+	}
+
 	q := r.URL.Query()
 
 	serviceDate := time.Time{}
@@ -41,27 +79,251 @@ func (h *Handler) GetScreen(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// An explicit ?locale= wins over the device's language preferences.
+	locale := q.Get("locale")
+	if locale == "" {
+		locale = i18n.Preferred(r.Header.Get("Accept-Language"))
+	}
+
 	req := models.ScreenRequest{
 		ScreenID:    screenID,
-		UserID:      q.Get("userId"),
+		UserID:      auth.TechnicianID(r),
 		RouteID:     q.Get("routeId"),
 		ServiceDate: serviceDate,
 		DeviceModel: q.Get("deviceModel"),
 		AppVersion:  q.Get("appVersion"),
-		Locale:      q.Get("locale"),
+		Locale:      locale,
 	}
 
-	screen, err := h.service.GetScreen(r.Context(), req)
+	result, err := h.service.GetScreen(r.Context(), req)
 	if err != nil {
 		logger := middleware.LoggerFrom(r.Context())
 		logger.Error("failed to resolve screen", slog.String("screen", screenID), slog.String("user", req.UserID), slog.Any("error", err))
-		respond.Error(w, http.StatusInternalServerError, "failed to resolve screen", "temporary error, please retry")
+		if errors.Is(err, validate.ErrInvalid) {
+			// Only enabled outside prod, where the problems help template authors.
+			respond.Error(w, http.StatusInternalServerError, "screen failed validation", err.Error())
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, "failed to resolve screen", "temporary error, please retry", respond.WithCause(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if result.Stale {
+		w.Header().Set("X-SDUI-Stale", "true")
+		w.Header().Set("Age", strconv.Itoa(int(result.Age.Seconds())))
+	}
+	if result.Experiment != nil {
+		w.Header().Set("X-SDUI-Experiment", result.Experiment.Header())
+	}
+	screen := *result.Screen
+	if h.brand != nil {
+		screen = brand(screen, h.brand.Theme(r.Context()))
+	}
 	if err := json.NewEncoder(w).Encode(screen); err != nil {
 		logger := middleware.LoggerFrom(r.Context())
 		logger.Error("failed to encode screen", slog.Any("error", err))
 	}
 }
+
+// GetPrecompileReport returns the outcome of the last template precompilation.
+func (h *Handler) GetPrecompileReport(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, h.service.PrecompileReport())
+}
+
+// Precompile re-runs template precompilation and returns the fresh report.
+func (h *Handler) Precompile(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Precompile()
+	if len(report.Failed) > 0 {
+		middleware.LoggerFrom(r.Context()).Warn("template precompilation failures", slog.Int("failed", len(report.Failed)))
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// GetMigrations reports the component migrations and the templates using
+// deprecated components, including those needing a manual fix.
+func (h *Handler) GetMigrations(w http.ResponseWriter, r *http.Request) {
+	respond.JSON(w, http.StatusOK, h.service.Migrations())
+}
+
+// Preview renders a template, or a screen's current one, against a
+// synthetic context and returns it inline. Nothing is stored.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	var req PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	result, err := h.service.Preview(req)
+	if err != nil {
+		h.fail(w, r, "failed to preview screen", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, result)
+}
+
+// ListTemplates summarises the screens published through the admin API.
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.Templates()
+	if err != nil {
+		h.fail(w, r, "failed to list templates", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"templates": templates})
+}
+
+// CreateTemplate publishes the first version of a new screen.
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ScreenID string          `json:"screenId"`
+		Screen   json.RawMessage `json:"screen"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	tpl, err := h.service.CreateTemplate(payload.ScreenID, payload.Screen)
+	if err != nil {
+		h.fail(w, r, "failed to create template", err)
+		return
+	}
+	h.recordTemplate(r, activity.TemplatePublished, tpl.ScreenID, tpl.Version)
+	respond.JSON(w, http.StatusCreated, tpl)
+}
+
+// UpdateTemplate publishes a new version of a screen.
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Screen json.RawMessage `json:"screen"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	tpl, err := h.service.UpdateTemplate(chi.URLParam(r, "screenId"), payload.Screen)
+	if err != nil {
+		h.fail(w, r, "failed to update template", err)
+		return
+	}
+	h.recordTemplate(r, activity.TemplatePublished, tpl.ScreenID, tpl.Version)
+	respond.JSON(w, http.StatusCreated, tpl)
+}
+
+// GetTemplate returns the version in the path, or the latest version.
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+	tpl, err := h.service.Template(chi.URLParam(r, "screenId"), version)
+	if err != nil {
+		h.fail(w, r, "failed to load template", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, tpl)
+}
+
+// ListTemplateVersions lists a screen's versions.
+func (h *Handler) ListTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.TemplateVersions(chi.URLParam(r, "screenId"))
+	if err != nil {
+		h.fail(w, r, "failed to list versions", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"versions": versions})
+}
+
+// DeleteTemplate deletes the version in the path, or every version.
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+	screenID := chi.URLParam(r, "screenId")
+	if err := h.service.DeleteTemplate(screenID, version); err != nil {
+		h.fail(w, r, "failed to delete template", err)
+		return
+	}
+	h.recordTemplate(r, activity.TemplateDeleted, screenID, version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStrings lists the translatable strings of ?version=, or of the
+// current template.
+func (h *Handler) GetStrings(w http.ResponseWriter, r *http.Request) {
+	version, ok := versionQuery(w, r)
+	if !ok {
+		return
+	}
+	strs, err := h.service.Strings(chi.URLParam(r, "screenId"), version)
+	if err != nil {
+		h.fail(w, r, "failed to extract strings", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, strs)
+}
+
+// GetCoverage reports the translation coverage of ?version=, or of the
+// current template, per locale.
+func (h *Handler) GetCoverage(w http.ResponseWriter, r *http.Request) {
+	version, ok := versionQuery(w, r)
+	if !ok {
+		return
+	}
+	coverage, err := h.service.Coverage(chi.URLParam(r, "screenId"), version)
+	if err != nil {
+		h.fail(w, r, "failed to load translation coverage", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, coverage)
+}
+
+// recordTemplate adds a template change to the activity feed. Version 0
+// stands for every version of the screen.
+func (h *Handler) recordTemplate(r *http.Request, eventType, screenID string, version int) {
+	name := screenID
+	if version > 0 {
+		name += " v" + strconv.Itoa(version)
+	}
+	h.feed.Record(r.Context(), activity.Event{Type: eventType, SubjectID: screenID, SubjectName: name})
+}
+
+// versionParam parses the optional {version} path parameter; 0 means none.
+func versionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := chi.URLParam(r, "version")
+	if raw == "" {
+		return 0, true
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		respond.Error(w, http.StatusBadRequest, "invalid version", "version must be a positive integer")
+		return 0, false
+	}
+	return version, true
+}
+
+// versionQuery parses the optional ?version= parameter; 0 means none.
+func versionQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("version")
+	if raw == "" {
+		return 0, true
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		respond.Error(w, http.StatusBadRequest, "invalid version", "version must be a positive integer")
+		return 0, false
+	}
+	return version, true
+}
+
+// fail answers client errors with their apperr status and code, and logs
+// the rest.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch kind := apperr.KindOf(err); kind {
+	case apperr.KindNotFound, apperr.KindConflict, apperr.KindValidation:
+		respond.Error(w, kind.Status(), title, err.Error(), respond.WithCause(err))
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,37 @@
+package sdui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// stableID derives a component ID from the screen and the component's key,
+// usually its path in the tree, so the same component has the same ID on
+// every render and clients can diff, cache and keep state across refreshes.
+func stableID(screenID, key string) string {
+	sum := sha256.Sum256([]byte(screenID + "/" + key))
+	return "c-" + hex.EncodeToString(sum[:8])
+}
+
+// assignIDs gives every component without an ID one derived from its path,
+// and a fresh random ID to components marked models.RandomComponentID.
+// Children of a component that already had an ID are pathed from that ID,
+// so a keyed subtree keeps its IDs when it moves. Item views are left
+// alone: the client stamps them out once per item.
+func assignIDs(screenID string, c *models.SDUIComponent, path string) {
+	switch c.ID {
+	case "":
+		c.ID = stableID(screenID, path)
+	case models.RandomComponentID:
+		c.ID = uuid.NewString()
+	default:
+		path = c.ID
+	}
+	for i := range c.Children {
+		assignIDs(screenID, &c.Children[i], componentPath(path, i))
+	}
+}
@@ -0,0 +1,61 @@
+package sdui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+func collectIDs(c models.SDUIComponent, ids map[string]string) {
+	ids[c.Text+"|"+c.Type] = c.ID
+	for _, child := range c.Children {
+		collectIDs(child, ids)
+	}
+}
+
+func TestComponentIDsAreStableAcrossRenders(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","text":"Hello"},
+		{"id":"banner","type":"vstack","children":[{"type":"text","text":"Inside"}]},
+		{"id":"$random","type":"text","text":"Toast"}]}}`)
+	svc, store := newTestService(t, dir)
+
+	render := func(screenID string) models.SDUIComponent {
+		t.Helper()
+		res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: screenID, UserID: "t1", ServiceDate: time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)})
+		if err != nil {
+			t.Fatalf("render %s: %v", screenID, err)
+		}
+		return res.Screen.Component
+	}
+
+	first, second := render("home"), render("home")
+	if first.Children[1].ID != "banner" {
+		t.Fatalf("expected the author's ID kept, got %q", first.Children[1].ID)
+	}
+	if first.Children[0].ID == "" || first.Children[0].ID != second.Children[0].ID || first.Children[1].Children[0].ID != second.Children[1].Children[0].ID {
+		t.Fatal("expected derived IDs to match between renders")
+	}
+	if toast := first.Children[2].ID; toast == models.RandomComponentID || toast == second.Children[2].ID {
+		t.Fatalf("expected a fresh random ID per render, got %q", toast)
+	}
+
+	day := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana"})
+	stops := []domain.RouteStop{{CustomerID: "c1", CustomerName: "Ada"}, {CustomerID: "c2", CustomerName: "Bo"}}
+	_ = store.SaveRoute(domain.Route{ID: "r1", TechnicianID: "t1", ServiceDate: day, CustomerStops: stops})
+	before := make(map[string]string)
+	collectIDs(render("dashboard"), before)
+	_ = store.SaveRoute(domain.Route{ID: "r1", TechnicianID: "t1", ServiceDate: day, CustomerStops: []domain.RouteStop{stops[1], stops[0]}})
+	after := make(map[string]string)
+	collectIDs(render("dashboard"), after)
+	for _, key := range []string{"Ada|text", "Bo|text"} {
+		if before[key] == "" || before[key] != after[key] {
+			t.Errorf("expected %s to keep its ID when the route is reordered, got %q and %q", key, before[key], after[key])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package sdui
+
+import (
+	"net/http"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/impersonate"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// legacyUserIDWarning is the Warning header sent with deprecated requests.
+const legacyUserIDWarning = `299 - "the userId query parameter is deprecated; authenticate with a bearer token"`
+
+// checkLegacyUserID handles screen requests that still send ?userId=.
+// Technicians are meant to be identified by their bearer token; a request
+// that names its technician only through the query string is served with
+// Deprecation, Sunset and Warning headers until the configured cutoff and
+// rejected from then on. A technician's token wins over any userId it also
+// sends, so those requests are only warned. Staff, impersonation sessions
+// and API tokens name the technician they act for with userId, which stays
+// supported. It reports whether the request may proceed.
+func (h *Handler) checkLegacyUserID(w http.ResponseWriter, r *http.Request) bool {
+	userID := r.URL.Query().Get("userId")
+	if userID == "" {
+		return true
+	}
+	id, authenticated := auth.FromContext(r.Context())
+	if authenticated && id.Staff() {
+		return true
+	}
+	if _, ok := impersonate.FromContext(r.Context()); ok {
+		return true
+	}
+	if _, ok := apitoken.FromContext(r.Context()); ok {
+		return true
+	}
+
+	cutoff := h.service.legacyCutoff
+	if !authenticated && !cutoff.IsZero() && !h.now().Before(cutoff) {
+		h.legacyRejected.Add(1)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="pestgenie"`)
+		respond.Error(w, http.StatusUnauthorized, "authentication required", "the userId query parameter is no longer accepted; provide a bearer token")
+		return false
+	}
+
+	h.legacyUserIDs.Add(1)
+	middleware.LoggerFrom(r.Context()).Debug("deprecated userId query parameter", slog.String("user", userID), slog.Bool("authenticated", authenticated))
+	w.Header().Set("Deprecation", "true")
+	if !cutoff.IsZero() {
+		w.Header().Set("Sunset", cutoff.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Warning", legacyUserIDWarning)
+	return true
+}
+
+// LegacyUserIDs returns how many screen requests have used the deprecated
+// ?userId= identity since the server started, and how many were rejected
+// after the cutoff.
+func (h *Handler) LegacyUserIDs() (served, rejected int64) {
+	return h.legacyUserIDs.Load(), h.legacyRejected.Load()
+}
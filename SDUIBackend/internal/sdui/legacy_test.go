@@ -0,0 +1,61 @@
+package sdui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+)
+
+func TestLegacyUserIDDeprecation(t *testing.T) {
+	svc, _ := newTestService(t, t.TempDir())
+	svc.legacyCutoff = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHandler(svc, nil, nil)
+	now := time.Date(2026, 5, 31, 23, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	router := chi.NewRouter()
+	router.Get("/v1/screens/{screenId}", h.GetScreen)
+
+	get := func(query string, id *auth.Identity) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/screens/technician-home"+query, nil)
+		if id != nil {
+			req = req.WithContext(auth.ContextWithIdentity(context.Background(), *id))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("?userId=t1", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Warning") == "" {
+		t.Fatalf("expected the legacy form served with deprecation headers, got %d %v", rec.Code, rec.Header())
+	}
+	if got := rec.Header().Get("Sunset"); got != "Mon, 01 Jun 2026 00:00:00 GMT" {
+		t.Fatalf("expected the cutoff as Sunset, got %q", got)
+	}
+	if rec := get("", &auth.Identity{Subject: "t1", Role: auth.RoleTechnician}); rec.Header().Get("Deprecation") != "" {
+		t.Fatal("expected token-only requests left alone")
+	}
+	if rec := get("?userId=t2", &auth.Identity{Subject: "d1", Role: auth.RoleDispatcher}); rec.Header().Get("Deprecation") != "" {
+		t.Fatal("expected staff acting for a technician left alone")
+	}
+
+	now = now.Add(time.Hour)
+	rec = get("?userId=t1", nil)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected the legacy form rejected after the cutoff, got %d", rec.Code)
+	}
+	rec = get("?userId=t1", &auth.Identity{Subject: "t1", Role: auth.RoleTechnician})
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "true" {
+		t.Fatalf("expected a token with a redundant userId served with a warning, got %d", rec.Code)
+	}
+
+	if served, rejected := h.LegacyUserIDs(); served != 2 || rejected != 1 {
+		t.Fatalf("expected 2 served and 1 rejected, got %d and %d", served, rejected)
+	}
+}
@@ -0,0 +1,257 @@
+package sdui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// ErrMissingTranslations is returned when a template is published before
+// its strings are translated for every required locale.
+var ErrMissingTranslations = apperr.Conflict("missing_translations", "missing translations")
+
+// TranslatableString is a textKey used by a template, with the template's
+// own text as the source for translators.
+type TranslatableString struct {
+	Key    string `json:"key"`
+	Source string `json:"source,omitempty"`
+	// ComponentIDs are the components showing the string, as served.
+	ComponentIDs []string `json:"componentIds"`
+}
+
+// TemplateStrings lists the strings of one template version.
+type TemplateStrings struct {
+	ScreenID string `json:"screenId"`
+	// Version is the published version; 0 for a template on disk.
+	Version int                  `json:"version"`
+	Strings []TranslatableString `json:"strings"`
+}
+
+// TranslationCoverage is how much of a template version each locale
+// translates.
+type TranslationCoverage struct {
+	ScreenID string           `json:"screenId"`
+	Version  int              `json:"version"`
+	Strings  int              `json:"strings"`
+	Locales  []LocaleCoverage `json:"locales"`
+}
+
+// LocaleCoverage is one locale's share of a template's strings. A string
+// counts as translated when the locale or its language has it; falling
+// back to the default locale does not count.
+type LocaleCoverage struct {
+	Locale     string   `json:"locale"`
+	Translated int      `json:"translated"`
+	Percent    int      `json:"percent"`
+	Missing    []string `json:"missing"`
+	// Required locales block publishing while anything is missing.
+	Required bool `json:"required"`
+}
+
+// Strings extracts the translatable strings of a screen's template
+// version, or of its current template when version is 0. Item views are
+// skipped: the client renders them.
+func (s *Service) Strings(screenID string, version int) (TemplateStrings, error) {
+	screen, version, err := s.templateScreen(screenID, version)
+	if err != nil {
+		return TemplateStrings{}, err
+	}
+	return TemplateStrings{ScreenID: screenID, Version: version, Strings: extractStrings(screenID, screen)}, nil
+}
+
+// Coverage reports the translation coverage of a screen's template version,
+// or of its current template when version is 0, for every locale with a
+// catalog and every required locale, except the default locale, whose
+// text the templates carry.
+func (s *Service) Coverage(screenID string, version int) (TranslationCoverage, error) {
+	screen, version, err := s.templateScreen(screenID, version)
+	if err != nil {
+		return TranslationCoverage{}, err
+	}
+	strs := extractStrings(screenID, screen)
+	catalogs := s.catalogs()
+
+	required := make(map[string]bool)
+	for _, l := range s.requiredLocales {
+		required[i18n.Canonical(l)] = true
+	}
+	locales := make(map[string]bool)
+	for _, l := range catalogs.Locales() {
+		locales[l] = true
+	}
+	for l := range required {
+		locales[l] = true
+	}
+	delete(locales, i18n.Canonical(s.defaultLocale))
+
+	out := TranslationCoverage{ScreenID: screenID, Version: version, Strings: len(strs), Locales: []LocaleCoverage{}}
+	for l := range locales {
+		c := coverage(catalogs, l, strs)
+		c.Required = required[l]
+		out.Locales = append(out.Locales, c)
+	}
+	sort.Slice(out.Locales, func(i, j int) bool { return out.Locales[i].Locale < out.Locales[j].Locale })
+	return out, nil
+}
+
+// UntranslatedStrings returns the source text of the strings of a screen's
+// template version, or of its current template when version is 0, that
+// locale does not translate, for machine translation drafts. found is false
+// when there is no such template.
+func (s *Service) UntranslatedStrings(screenID string, version int, locale string) (map[string]string, bool, error) {
+	screen, _, err := s.templateScreen(screenID, version)
+	if errors.Is(err, ErrTemplateNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	catalogs := s.catalogs()
+	locale = i18n.Canonical(locale)
+	out := make(map[string]string)
+	for _, str := range extractStrings(screenID, screen) {
+		if str.Source != "" && !catalogs.Translated(locale, str.Key) {
+			out[str.Key] = str.Source
+		}
+	}
+	return out, true, nil
+}
+
+// checkTranslations fails with ErrMissingTranslations when a required
+// locale lacks any of the screen's strings.
+func (s *Service) checkTranslations(screenID string, payload []byte) error {
+	if len(s.requiredLocales) == 0 {
+		return nil
+	}
+	var screen models.SDUIScreen
+	if err := json.Unmarshal(payload, &screen); err != nil {
+		return ErrInvalidTemplate.Detailf("%v", err)
+	}
+	strs := extractStrings(screenID, screen)
+	catalogs := s.catalogs()
+	var problems []string
+	for _, l := range s.requiredLocales {
+		if c := coverage(catalogs, i18n.Canonical(l), strs); len(c.Missing) > 0 {
+			problems = append(problems, fmt.Sprintf("%s lacks %s", c.Locale, strings.Join(c.Missing, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return ErrMissingTranslations.Detailf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// TranslationsUploaded layers a locale's uploaded messages over its
+// catalog file, or removes the layer when messages is nil. Screens are
+// rendered with the new translations from the next request.
+func (s *Service) TranslationsUploaded(locale string, messages map[string]string) {
+	locale = i18n.Canonical(locale)
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if messages == nil {
+		delete(s.templates.uploaded, locale)
+	} else {
+		s.templates.uploaded[locale] = messages
+	}
+	s.templates.catalogs = s.templates.layered()
+	s.templates.report.Locales = s.templates.catalogs.Locales()
+	if s.logger != nil {
+		s.logger.Info("screen translations updated", slog.String("locale", locale), slog.Int("keys", len(messages)))
+	}
+}
+
+// catalogs returns the translation catalogs screens are rendered with.
+func (s *Service) catalogs() *i18n.Catalogs {
+	s.templates.mu.RLock()
+	defer s.templates.mu.RUnlock()
+	return s.templates.catalogs
+}
+
+// layered merges the uploaded messages over the catalog files. Before
+// Precompile has loaded the files there is nothing to layer over; the
+// uploads are applied then. The caller must hold c.mu.
+func (c *templateCache) layered() *i18n.Catalogs {
+	if len(c.uploaded) == 0 || c.files == nil {
+		return c.files
+	}
+	out := c.files.Clone()
+	for locale, messages := range c.uploaded {
+		out.Add(locale, messages)
+	}
+	return out
+}
+
+// templateScreen returns a published version of a screen's template, or
+// its current template when version is 0, with the version found.
+func (s *Service) templateScreen(screenID string, version int) (models.SDUIScreen, int, error) {
+	tv, err := s.Template(screenID, version)
+	if errors.Is(err, ErrTemplateNotFound) && version == 0 {
+		if tpl, ok := s.template(screenID); ok {
+			return tpl.render(), 0, nil
+		}
+	}
+	if err != nil {
+		return models.SDUIScreen{}, 0, err
+	}
+	var screen models.SDUIScreen
+	if err := json.Unmarshal(tv.Screen, &screen); err != nil {
+		return models.SDUIScreen{}, 0, ErrInvalidTemplate.Detailf("%v", err)
+	}
+	return screen, tv.Version, nil
+}
+
+// extractStrings lists the textKeys of screen in first-use order.
+func extractStrings(screenID string, screen models.SDUIScreen) []TranslatableString {
+	root := cloneComponent(screen.Component)
+	assignIDs(screenID, &root, "0")
+	out := []TranslatableString{}
+	index := make(map[string]int)
+	var walk func(c models.SDUIComponent)
+	walk = func(c models.SDUIComponent) {
+		if c.TextKey != "" {
+			source := c.Text
+			if source == "" {
+				source = c.Label
+			}
+			i, ok := index[c.TextKey]
+			if !ok {
+				i = len(out)
+				index[c.TextKey] = i
+				out = append(out, TranslatableString{Key: c.TextKey, Source: source})
+			}
+			if out[i].Source == "" {
+				out[i].Source = source
+			}
+			out[i].ComponentIDs = append(out[i].ComponentIDs, c.ID)
+		}
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return out
+}
+
+// coverage counts the strs translated for locale.
+func coverage(catalogs *i18n.Catalogs, locale string, strs []TranslatableString) LocaleCoverage {
+	c := LocaleCoverage{Locale: locale, Missing: []string{}, Percent: 100}
+	for _, str := range strs {
+		if catalogs.Translated(locale, str.Key) {
+			c.Translated++
+		} else {
+			c.Missing = append(c.Missing, str.Key)
+		}
+	}
+	if len(strs) > 0 {
+		c.Percent = c.Translated * 100 / len(strs)
+	}
+	return c
+}
@@ -0,0 +1,252 @@
+// Package migrate rewrites renamed component types and fields in SDUI
+// templates as they are loaded, so templates written before a component
+// was renamed keep rendering on app builds that only know the new name.
+// Rules come from a JSON file of deprecations:
+//
+//	[
+//	  {"type": "weatherAlert", "renameTo": "alert", "fields": {"message": "text"}, "note": "weatherAlert is now a plain alert"},
+//	  {"type": "webView", "note": "webView will be removed in 3.0"},
+//	  {"type": "maintenanceScheduler", "retired": true, "note": "use a navigationLink to the maintenance screen"}
+//	]
+//
+// A rule with renameTo or fields is rewritten; one with only a note is a
+// deprecation that still renders; a retired type has no equivalent and
+// the template needs a manual fix.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// ErrInvalid wraps every problem with the rules.
+var ErrInvalid = errors.New("invalid component migrations")
+
+// Usage actions.
+const (
+	// ActionRewritten is a deprecated type or field that was rewritten to
+	// its replacement.
+	ActionRewritten = "rewritten"
+	// ActionDeprecated is a deprecated type that still renders as is.
+	ActionDeprecated = "deprecated"
+	// ActionManual is a construct that cannot be rewritten; the template
+	// has to be fixed by hand.
+	ActionManual = "manual"
+)
+
+// Rule deprecates a component type, or some of its fields.
+type Rule struct {
+	Type string `json:"type"`
+	// RenameTo is the type components of Type are rewritten to.
+	RenameTo string `json:"renameTo,omitempty"`
+	// Fields renames fields of the component, from the old name to the new.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Retired types have no equivalent and are left for a manual fix.
+	Retired bool `json:"retired,omitempty"`
+	// Note tells template authors what to use instead.
+	Note string `json:"note,omitempty"`
+}
+
+// Usage is one deprecated construct found in a template. Path is the
+// component's path as validation reports it, such as "0.2.1" or "0.item".
+type Usage struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Field  string `json:"field,omitempty"`
+	Action string `json:"action"`
+	// Message describes what was done or needs doing.
+	Message string `json:"message"`
+}
+
+// Report lists the deprecated constructs found in a template.
+type Report struct {
+	Usages []Usage `json:"usages"`
+}
+
+// Rewritten reports whether any usage was rewritten.
+func (r Report) Rewritten() bool {
+	for _, u := range r.Usages {
+		if u.Action == ActionRewritten {
+			return true
+		}
+	}
+	return false
+}
+
+// Manual returns the usages that need a manual fix.
+func (r Report) Manual() []Usage {
+	var out []Usage
+	for _, u := range r.Usages {
+		if u.Action == ActionManual {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// Registry holds the rules by component type. A nil *Registry migrates
+// nothing.
+type Registry struct {
+	rules map[string]Rule
+}
+
+// Load reads rules from a JSON file. An empty path loads nothing and
+// returns nil.
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, path, err)
+	}
+	return New(rules)
+}
+
+// New checks rules and returns a registry of them. Renames may chain, so
+// a type renamed twice reaches its latest name, but may not loop.
+func New(rules []Rule) (*Registry, error) {
+	r := &Registry{rules: make(map[string]Rule, len(rules))}
+	for _, rule := range rules {
+		switch {
+		case rule.Type == "":
+			return nil, fmt.Errorf("%w: a rule has no type", ErrInvalid)
+		case r.rules[rule.Type].Type != "":
+			return nil, fmt.Errorf("%w: %s has more than one rule", ErrInvalid, rule.Type)
+		case rule.RenameTo == rule.Type:
+			return nil, fmt.Errorf("%w: %s is renamed to itself", ErrInvalid, rule.Type)
+		case rule.Retired && (rule.RenameTo != "" || len(rule.Fields) > 0):
+			return nil, fmt.Errorf("%w: retired %s cannot be rewritten", ErrInvalid, rule.Type)
+		}
+		targets := make(map[string]bool, len(rule.Fields))
+		for from, to := range rule.Fields {
+			if from == "" || to == "" || from == to || from == "type" || to == "type" || targets[to] {
+				return nil, fmt.Errorf("%w: %s cannot rename field %q to %q", ErrInvalid, rule.Type, from, to)
+			}
+			targets[to] = true
+		}
+		r.rules[rule.Type] = rule
+	}
+	for typ := range r.rules {
+		seen := map[string]bool{typ: true}
+		for next := r.rules[typ].RenameTo; next != ""; next = r.rules[next].RenameTo {
+			if seen[next] {
+				return nil, fmt.Errorf("%w: renaming %s loops back to %s", ErrInvalid, typ, next)
+			}
+			seen[next] = true
+		}
+	}
+	return r, nil
+}
+
+// Rules returns the registry's rules sorted by type.
+func (r *Registry) Rules() []Rule {
+	if r == nil {
+		return []Rule{}
+	}
+	out := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// Apply rewrites the deprecated types and fields of a screen payload and
+// reports every deprecated construct it uses. The payload is returned
+// unchanged when nothing was rewritten, and as is when it is not a JSON
+// object, leaving the error to the template parser.
+func (r *Registry) Apply(payload []byte) ([]byte, Report) {
+	report := Report{Usages: []Usage{}}
+	if r == nil || len(r.rules) == 0 {
+		return payload, report
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var screen map[string]any
+	if err := dec.Decode(&screen); err != nil {
+		return payload, report
+	}
+	if root, ok := screen["component"].(map[string]any); ok {
+		r.walk(root, "0", &report)
+	}
+	if !report.Rewritten() {
+		return payload, report
+	}
+	out, err := json.Marshal(screen)
+	if err != nil {
+		return payload, report
+	}
+	return out, report
+}
+
+func (r *Registry) walk(c map[string]any, path string, report *Report) {
+	typ, _ := c["type"].(string)
+	authored := typ
+	for rule, ok := r.rules[typ]; ok; rule, ok = r.rules[typ] {
+		r.fields(c, path, authored, rule, report)
+		switch {
+		case rule.Retired:
+			report.Usages = append(report.Usages, Usage{Path: path, Type: authored, Action: ActionManual, Message: message(typ+" is retired", rule.Note)})
+		case rule.RenameTo != "":
+			c["type"] = rule.RenameTo
+			report.Usages = append(report.Usages, Usage{Path: path, Type: authored, Action: ActionRewritten, Message: message(typ+" is now "+rule.RenameTo, rule.Note)})
+			typ = rule.RenameTo
+			continue
+		case len(rule.Fields) == 0:
+			report.Usages = append(report.Usages, Usage{Path: path, Type: authored, Action: ActionDeprecated, Message: message(typ+" is deprecated", rule.Note)})
+		}
+		break
+	}
+
+	if children, ok := c["children"].([]any); ok {
+		for i, child := range children {
+			if child, ok := child.(map[string]any); ok {
+				r.walk(child, path+"."+strconv.Itoa(i), report)
+			}
+		}
+	}
+	if item, ok := c["itemView"].(map[string]any); ok {
+		r.walk(item, path+".item", report)
+	}
+}
+
+// fields renames the deprecated fields of c, in a stable order. A field
+// set under both its old and new names is left for a manual fix.
+func (r *Registry) fields(c map[string]any, path, authored string, rule Rule, report *Report) {
+	from := make([]string, 0, len(rule.Fields))
+	for f := range rule.Fields {
+		from = append(from, f)
+	}
+	sort.Strings(from)
+	for _, f := range from {
+		value, ok := c[f]
+		if !ok {
+			continue
+		}
+		to := rule.Fields[f]
+		if _, taken := c[to]; taken {
+			report.Usages = append(report.Usages, Usage{Path: path, Type: authored, Field: f, Action: ActionManual, Message: message(fmt.Sprintf("both %s and %s are set; remove %s", f, to, f), rule.Note)})
+			continue
+		}
+		delete(c, f)
+		c[to] = value
+		report.Usages = append(report.Usages, Usage{Path: path, Type: authored, Field: f, Action: ActionRewritten, Message: message(f+" is now "+to, rule.Note)})
+	}
+}
+
+func message(what, note string) string {
+	if note == "" {
+		return what
+	}
+	return what + ": " + note
+}
@@ -0,0 +1,114 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	r, err := New([]Rule{
+		{Type: "banner", RenameTo: "weatherAlert", Note: "banners were merged into alerts"},
+		{Type: "weatherAlert", RenameTo: "alert", Fields: map[string]string{"message": "text"}},
+		{Type: "webView", Note: "removed in 3.0"},
+		{Type: "maintenanceScheduler", Retired: true},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	payload := []byte(`{"version":1,"component":{"type":"vstack","children":[
+		{"type":"banner","message":"Wind","priority":2},
+		{"type":"list","itemView":{"type":"weatherAlert","message":"a","text":"b"}},
+		{"type":"webView"},
+		{"type":"maintenanceScheduler"}]}}`)
+
+	out, report := r.Apply(payload)
+	var screen struct {
+		Component struct {
+			Children []map[string]any `json:"children"`
+		} `json:"component"`
+	}
+	if err := json.Unmarshal(out, &screen); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	banner := screen.Component.Children[0]
+	if banner["type"] != "alert" || banner["text"] != "Wind" || banner["message"] != nil || banner["priority"] != 2.0 {
+		t.Fatalf("expected the banner renamed twice with its field moved, got %v", banner)
+	}
+	item := screen.Component.Children[1]["itemView"].(map[string]any)
+	if item["type"] != "alert" || item["message"] != "a" {
+		t.Fatalf("expected a conflicting field left alone, got %v", item)
+	}
+
+	want := []Usage{
+		{Path: "0.0", Type: "banner", Action: ActionRewritten, Message: "banner is now weatherAlert: banners were merged into alerts"},
+		{Path: "0.0", Type: "banner", Field: "message", Action: ActionRewritten, Message: "message is now text"},
+		{Path: "0.0", Type: "banner", Action: ActionRewritten},
+		{Path: "0.1.item", Type: "weatherAlert", Field: "message", Action: ActionManual, Message: "both message and text are set; remove message"},
+		{Path: "0.1.item", Type: "weatherAlert", Action: ActionRewritten},
+		{Path: "0.2", Type: "webView", Action: ActionDeprecated, Message: "webView is deprecated: removed in 3.0"},
+		{Path: "0.3", Type: "maintenanceScheduler", Action: ActionManual, Message: "maintenanceScheduler is retired"},
+	}
+	if len(report.Usages) != len(want) {
+		t.Fatalf("expected %d usages, got %+v", len(want), report.Usages)
+	}
+	for i, u := range report.Usages {
+		w := want[i]
+		if u.Path != w.Path || u.Type != w.Type || u.Field != w.Field || u.Action != w.Action || (w.Message != "" && u.Message != w.Message) {
+			t.Errorf("usage %d: expected %+v, got %+v", i, w, u)
+		}
+	}
+	if len(report.Manual()) != 2 {
+		t.Errorf("expected two manual fixes, got %+v", report.Manual())
+	}
+
+	untouched := []byte(`{"version":1, "component":{"type":"webView"}}`)
+	if out, report := r.Apply(untouched); string(out) != string(untouched) || len(report.Usages) != 1 {
+		t.Errorf("expected a payload with nothing rewritten returned as is, got %s %+v", out, report)
+	}
+	var none *Registry
+	if out, report := none.Apply(payload); string(out) != string(payload) || len(report.Usages) != 0 {
+		t.Errorf("expected a nil registry to migrate nothing")
+	}
+}
+
+func TestNewRejectsBadRules(t *testing.T) {
+	for name, rules := range map[string][]Rule{
+		"untyped":   {{RenameTo: "text"}},
+		"duplicate": {{Type: "a", Note: "x"}, {Type: "a", RenameTo: "b"}},
+		"self":      {{Type: "a", RenameTo: "a"}},
+		"loop":      {{Type: "a", RenameTo: "b"}, {Type: "b", RenameTo: "a"}},
+		"retired":   {{Type: "a", Retired: true, RenameTo: "b"}},
+		"field":     {{Type: "a", Fields: map[string]string{"type": "kind"}}},
+		"collision": {{Type: "a", Fields: map[string]string{"x": "z", "y": "z"}}},
+	} {
+		if _, err := New(rules); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected rules refused, got %v", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if r, err := Load(""); r != nil || err != nil {
+		t.Fatalf("expected no path to load nothing, got %v %v", r, err)
+	}
+	path := filepath.Join(t.TempDir(), "migrations.json")
+	if err := os.WriteFile(path, []byte(`[{"type":"b","note":"n"},{"type":"a","renameTo":"text"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if rules := r.Rules(); len(rules) != 2 || rules[0].Type != "a" {
+		t.Fatalf("expected sorted rules, got %+v", rules)
+	}
+	if err := os.WriteFile(path, []byte(`{"type":"a"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a malformed file refused, got %v", err)
+	}
+}
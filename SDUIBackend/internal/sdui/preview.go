@@ -0,0 +1,123 @@
+package sdui
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/migrate"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+)
+
+// PreviewRequest is a dry run of a screen against a made-up context.
+type PreviewRequest struct {
+	ScreenID string `json:"screenId"`
+	// Screen is the template to try out. When empty, the screen's current
+	// template, or the programmatic screen, is rendered instead.
+	Screen json.RawMessage `json:"screen,omitempty"`
+	// Context stands in for the technician, route and metadata production
+	// would look up. Metadata is added to the device details below.
+	Context     domain.ScreenContext `json:"context"`
+	ServiceDate time.Time            `json:"serviceDate,omitempty"`
+	DeviceModel string               `json:"deviceModel,omitempty"`
+	AppVersion  string               `json:"appVersion,omitempty"`
+	Locale      string               `json:"locale,omitempty"`
+}
+
+// PreviewResult is the rendered screen and what an author should look at.
+type PreviewResult struct {
+	Screen models.SDUIScreen `json:"screen"`
+	// Problems are validation failures of the rendered screen. Production
+	// serves such a screen only when response validation is off.
+	Problems []string `json:"problems"`
+	// Unresolved lists the placeholder keys the context had no value for.
+	Unresolved []string `json:"unresolved"`
+	// Deprecated lists the deprecated components and fields the template
+	// uses, as migrated for the render.
+	Deprecated []migrate.Usage `json:"deprecated,omitempty"`
+}
+
+// Preview renders a screen the way GetScreen would, but against the
+// request's context rather than the repositories, and without caching or
+// storing anything. Job stats are not computed and are reported
+// unresolved. Experiments are not applied.
+func (s *Service) Preview(req PreviewRequest) (PreviewResult, error) {
+	if !validScreenID(req.ScreenID) {
+		return PreviewResult{}, ErrInvalidTemplate.Detailf("invalid screen id %q", req.ScreenID)
+	}
+	class := DeviceClass(req.DeviceModel)
+	compact := class == DeviceClassWatch && !strings.HasSuffix(req.ScreenID, "."+DeviceClassWatch)
+
+	var tpl *compiledTemplate
+	if len(req.Screen) > 0 {
+		payload, migrated := s.migrations().Apply(req.Screen)
+		if err := ValidateTemplate(payload, s.rules); err != nil {
+			return PreviewResult{}, err
+		}
+		compiled, err := compileTemplate(req.ScreenID, "preview", payload)
+		if err != nil {
+			return PreviewResult{}, ErrInvalidTemplate.Detailf("%v", err)
+		}
+		compiled.deprecated = migrated.Usages
+		tpl = compiled
+	} else {
+		if class != DeviceClassPhone {
+			if classTpl, ok := s.template(classScreenID(req.ScreenID, class)); ok {
+				tpl, compact = classTpl, false
+			}
+		}
+		if tpl == nil {
+			tpl, _ = s.template(req.ScreenID)
+		}
+	}
+
+	screenReq := models.ScreenRequest{
+		ScreenID:    req.ScreenID,
+		UserID:      req.Context.Technician.ID,
+		RouteID:     req.Context.Route.ID,
+		ServiceDate: req.ServiceDate,
+		DeviceModel: req.DeviceModel,
+		AppVersion:  req.AppVersion,
+		Locale:      req.Locale,
+	}
+	tech, route := req.Context.Technician, req.Context.Route
+	sc := newScreenContext(screenReq, tech, route)
+	for key, value := range req.Context.Metadata {
+		sc.Metadata[key] = value
+	}
+	t := s.translator(req.Locale)
+	recorder := &recordingResolver{Resolver: s.resolver(sc, req.ServiceDate, nil, t), missing: make(map[string]bool)}
+	b := binder{resolver: recorder, unresolved: s.unresolved, translator: t}
+
+	screen := s.render(screenReq, tech, route, tpl, b, compact)
+	result := PreviewResult{Screen: screen, Problems: validate.Problems(screen, s.rules), Unresolved: []string{}}
+	if tpl != nil {
+		result.Deprecated = tpl.deprecated
+	}
+	if result.Problems == nil {
+		result.Problems = []string{}
+	}
+	for key := range recorder.missing {
+		result.Unresolved = append(result.Unresolved, key)
+	}
+	sort.Strings(result.Unresolved)
+	return result, nil
+}
+
+// recordingResolver notes the keys its Resolver cannot resolve.
+type recordingResolver struct {
+	Resolver
+	missing map[string]bool
+}
+
+// Resolve implements Resolver.
+func (r *recordingResolver) Resolve(key string) (string, bool) {
+	v, ok := r.Resolver.Resolve(key)
+	if !ok {
+		r.missing[key] = true
+	}
+	return v, ok
+}
@@ -0,0 +1,273 @@
+package sdui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+)
+
+var (
+	// ErrTemplateNotFound is returned when a screen template or version does
+	// not exist in the ScreenRepository.
+	ErrTemplateNotFound = repository.ErrTemplateNotFound
+	// ErrInvalidTemplate matches template validation failures.
+	ErrInvalidTemplate = apperr.Validation("invalid_template", "invalid template")
+	// ErrTemplateExists is returned when creating a screen that already has
+	// templates; publish a new version instead.
+	ErrTemplateExists = apperr.Conflict("template_exists", "template already exists")
+)
+
+// reservedScreenIDs are path segments of the admin screens API.
+var reservedScreenIDs = map[string]bool{"precompile": true, "preview": true, "migrations": true}
+
+// TemplateVersion is one published version of a screen template.
+type TemplateVersion struct {
+	ScreenID  string          `json:"screenId"`
+	Version   int             `json:"version"`
+	Screen    json.RawMessage `json:"screen,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// TemplateSummary describes a screen's published templates.
+type TemplateSummary struct {
+	ScreenID      string    `json:"screenId"`
+	LatestVersion int       `json:"latestVersion"`
+	Versions      int       `json:"versions"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ValidateTemplate checks a screen payload against the SDUIComponent schema:
+// no unknown fields, then the component rules of the validate package.
+func ValidateTemplate(payload []byte, rules validate.Rules) error {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	var screen models.SDUIScreen
+	if err := dec.Decode(&screen); err != nil {
+		return ErrInvalidTemplate.Detailf("%v", err)
+	}
+	if dec.More() {
+		return ErrInvalidTemplate.Detailf("unexpected data after the screen")
+	}
+	if problems := validate.Problems(screen, rules); len(problems) > 0 {
+		return ErrInvalidTemplate.Detailf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CreateTemplate publishes version 1 of a new screen.
+func (s *Service) CreateTemplate(screenID string, payload []byte) (TemplateVersion, error) {
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	if !validScreenID(screenID) || reservedScreenIDs[screenID] {
+		return TemplateVersion{}, ErrInvalidTemplate.Detailf("invalid screen id %q", screenID)
+	}
+	versions, err := s.versions(screenID)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	if len(versions) > 0 {
+		return TemplateVersion{}, ErrTemplateExists.Detailf("screen %q has %d versions", screenID, len(versions))
+	}
+	return s.publish(screenID, 1, payload)
+}
+
+// UpdateTemplate publishes a new version of an existing screen. Earlier
+// versions are kept.
+func (s *Service) UpdateTemplate(screenID string, payload []byte) (TemplateVersion, error) {
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	versions, err := s.versions(screenID)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	if len(versions) == 0 {
+		return TemplateVersion{}, ErrTemplateNotFound
+	}
+	return s.publish(screenID, versions[len(versions)-1].Version+1, payload)
+}
+
+// Template returns a version of a screen's template, or the latest when
+// version is 0.
+func (s *Service) Template(screenID string, version int) (TemplateVersion, error) {
+	versions, err := s.versions(screenID)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	if len(versions) == 0 {
+		return TemplateVersion{}, ErrTemplateNotFound
+	}
+	if version == 0 {
+		return templateVersion(versions[len(versions)-1], true), nil
+	}
+	for _, tpl := range versions {
+		if tpl.Version == version {
+			return templateVersion(tpl, true), nil
+		}
+	}
+	return TemplateVersion{}, ErrTemplateNotFound
+}
+
+// TemplateVersions lists a screen's versions, oldest first, without their
+// payloads.
+func (s *Service) TemplateVersions(screenID string) ([]TemplateVersion, error) {
+	versions, err := s.versions(screenID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+	out := make([]TemplateVersion, 0, len(versions))
+	for _, tpl := range versions {
+		out = append(out, templateVersion(tpl, false))
+	}
+	return out, nil
+}
+
+// Templates summarises every screen in the ScreenRepository.
+func (s *Service) Templates() ([]TemplateSummary, error) {
+	all, err := s.repos.Screens.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	out := []TemplateSummary{}
+	for _, tpl := range all {
+		i, ok := index[tpl.ID]
+		if !ok {
+			i = len(out)
+			index[tpl.ID] = i
+			out = append(out, TemplateSummary{ScreenID: tpl.ID})
+		}
+		sum := &out[i]
+		sum.Versions++
+		if tpl.Version > sum.LatestVersion {
+			sum.LatestVersion = tpl.Version
+		}
+		if tpl.UpdatedAt.After(sum.UpdatedAt) {
+			sum.UpdatedAt = tpl.UpdatedAt
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ScreenID < out[j].ScreenID })
+	return out, nil
+}
+
+// DeleteTemplate removes one version of a screen, or every version when
+// version is 0. Requests for the screen are then served by its latest
+// remaining version, its disk template, or the programmatic default.
+func (s *Service) DeleteTemplate(screenID string, version int) error {
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	versions, err := s.versions(screenID)
+	if err != nil {
+		return err
+	}
+	var remaining []domain.ScreenTemplate
+	deleted := 0
+	for _, tpl := range versions {
+		if version != 0 && tpl.Version != version {
+			remaining = append(remaining, tpl)
+			continue
+		}
+		if err := s.repos.Screens.DeleteTemplate(screenID, tpl.Version); err != nil {
+			return err
+		}
+		s.templates.mu.Lock()
+		delete(s.templates.versions, versionKey(screenID, tpl.Version))
+		s.templates.mu.Unlock()
+		deleted++
+	}
+	if deleted == 0 {
+		return ErrTemplateNotFound
+	}
+
+	if len(remaining) > 0 {
+		if err := s.TemplatePublished(remaining[len(remaining)-1]); err != nil && s.logger != nil {
+			s.logger.Warn("previous template version failed to compile", slog.String("screen", screenID), slog.String("error", err.Error()))
+		}
+	} else {
+		s.templates.evict(screenID, SourceRepository)
+	}
+	if s.logger != nil {
+		s.logger.Info("screen template deleted", slog.String("screen", screenID), slog.Int("version", version), slog.Int("deleted", deleted))
+	}
+	return nil
+}
+
+// publish validates and saves a template version, refusing one whose
+// strings a required locale lacks or that uses a deprecated component
+// needing a manual fix, then warms the cache.
+func (s *Service) publish(screenID string, version int, payload []byte) (TemplateVersion, error) {
+	// Templates are stored as written and checked as they will be loaded,
+	// with deprecated components migrated.
+	migrated, report := s.migrations().Apply(payload)
+	if manual := report.Manual(); len(manual) > 0 {
+		problems := make([]string, len(manual))
+		for i, u := range manual {
+			problems[i] = fmt.Sprintf("component at %s: %s", u.Path, u.Message)
+		}
+		return TemplateVersion{}, ErrInvalidTemplate.Detailf("%s", strings.Join(problems, "; "))
+	}
+	if err := ValidateTemplate(migrated, s.rules); err != nil {
+		return TemplateVersion{}, err
+	}
+	if err := s.checkTranslations(screenID, migrated); err != nil {
+		return TemplateVersion{}, err
+	}
+	if err := s.checkAssets(migrated); err != nil {
+		return TemplateVersion{}, err
+	}
+	if err := s.repos.Screens.SaveTemplate(domain.ScreenTemplate{ID: screenID, Version: version, PayloadJSON: payload}); err != nil {
+		return TemplateVersion{}, err
+	}
+	saved, err := s.repos.Screens.GetTemplate(screenID, version)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	if err := s.TemplatePublished(saved); err != nil {
+		return TemplateVersion{}, ErrInvalidTemplate.Detailf("%v", err)
+	}
+	if s.logger != nil {
+		s.logger.Info("screen template published", slog.String("screen", screenID), slog.Int("version", version))
+	}
+	return templateVersion(saved, true), nil
+}
+
+// versions returns a screen's templates sorted by version.
+func (s *Service) versions(screenID string) ([]domain.ScreenTemplate, error) {
+	all, err := s.repos.Screens.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.ScreenTemplate
+	for _, tpl := range all {
+		if tpl.ID == screenID {
+			out = append(out, tpl)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+func templateVersion(tpl domain.ScreenTemplate, withScreen bool) TemplateVersion {
+	v := TemplateVersion{ScreenID: tpl.ID, Version: tpl.Version, CreatedAt: tpl.CreatedAt, UpdatedAt: tpl.UpdatedAt}
+	if withScreen {
+		v.Screen = json.RawMessage(tpl.PayloadJSON)
+	}
+	return v
+}
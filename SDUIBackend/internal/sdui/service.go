@@ -2,117 +2,376 @@ package sdui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"log/slog"
 
-	"github.com/google/uuid"
-
-	domain "github.com/your-org/pestgenie-sdui/internal/domain/models"
-	"github.com/your-org/pestgenie-sdui/internal/domain/repository"
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
 	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+	"github.com/your-org/pestgenie-sdui/internal/tracing"
 )
 
 // Service encapsulates logic for selecting and personalising SDUI screens.
 type Service struct {
 	templateDir string
-	repos       repository.Repository
-	logger      *slog.Logger
+	// translationsDir holds the catalogs loaded by Precompile; locales
+	// without a translation fall back to defaultLocale.
+	translationsDir string
+	defaultLocale   string
+	// requiredLocales must translate a template before it is published.
+	requiredLocales []string
+	// migrationsPath is the component migrations file loaded by Precompile.
+	migrationsPath string
+	// templateRefresh is how often the template cache is checked against
+	// the ScreenRepository.
+	templateRefresh time.Duration
+	unresolved      string
+	rules           validate.Rules
+	// validateResponses checks every rendered screen before it is served.
+	validateResponses bool
+	repos             repository.Repository
+	brownout          *brownout.Monitor
+	stale             *screenCache
+	templates         *templateCache
+	experiments       *experiment.Service
+	weather           WeatherAdvisor
+	flags             FlagEvaluator
+	watch             compactor
+	gate              versionGate
+	logger            *slog.Logger
+
+	// legacyCutoff is when unauthenticated ?userId= requests start being
+	// rejected; zero means never.
+	legacyCutoff time.Time
+
+	// assetURLs are the current URLs of the uploaded assets, by ID.
+	assetsMu  sync.RWMutex
+	assetURLs map[string]string
+
+	// publishMu serializes template publishing so version numbers are not
+	// handed out twice.
+	publishMu sync.Mutex
+}
+
+// Result is a resolved screen along with how it was produced.
+type Result struct {
+	Screen *models.SDUIScreen
+	// Stale is set when the screen was served from the brownout cache rather
+	// than rendered against fresh repository data.
+	Stale bool
+	Age   time.Duration
+	// Experiment is the variant the screen was rendered for, when the
+	// technician is enrolled in an experiment on it.
+	Experiment *experiment.Assignment
 }
 
 // NewService creates a service pointing at the on-disk template directory. When
-// templateDir is empty the service falls back to programmatic defaults.
-func NewService(templateDir string, repos repository.Repository, logger *slog.Logger) *Service {
-	return &Service{templateDir: templateDir, repos: repos, logger: logger}
+// templateDir is empty the service falls back to programmatic defaults. While
+// the monitor reports brownout, previously rendered screens up to staleTTL old
+// are served instead of hitting the datastore. Placeholders the server cannot
+// resolve are handled according to cfg.UnresolvedPlaceholders, and with
+// cfg.ValidateResponses every rendered screen is validated before it is served.
+// cfg.LegacyUserIDCutoff, already validated by config, ends the deprecation
+// window for query-string identity. Technicians enrolled in one of
+// experiments are served their variant's template version; a nil
+// experiments serves every technician the same template. Watch screens
+// without a watch template are simplified within cfg.WatchMaxItems and
+// cfg.WatchMaxText. Screens are translated with the catalogs in
+// cfg.TranslationsDir, loaded by Precompile, and those uploaded through
+// TranslationsUploaded; templates are only published once every textKey is
+// translated for cfg.RequiredLocales. Components older app builds
+// cannot render, by cfg.ComponentMinVersions or their own minAppVersion, are
+// replaced with cfg.UpdateFallback. Components naming an asset are served
+// its URL as reported by AssetChanged, and templates naming an unknown
+// asset are not published. Routes get weather alerts for their stops from
+// weather; a nil weather adds none. Deprecated components are rewritten as
+// templates are loaded by the rules in cfg.ComponentMigrations, loaded by
+// Precompile. Templates read feature flags evaluated by flags for the
+// technician as flags.<key>, and the ValidateResponsesFlag flag overrides
+// cfg.ValidateResponses; a nil flags evaluates none.
+func NewService(templateDir string, cfg config.ScreenConfig, repos repository.Repository, monitor *brownout.Monitor, staleTTL time.Duration, experiments *experiment.Service, weather WeatherAdvisor, flags FlagEvaluator, logger *slog.Logger) *Service {
+	cutoff, _ := time.Parse(time.DateOnly, cfg.LegacyUserIDCutoff)
+	watch := compactor{maxItems: cfg.WatchMaxItems, maxText: cfg.WatchMaxText}
+	if watch.maxItems <= 0 {
+		watch.maxItems = defaultWatchMaxItems
+	}
+	if watch.maxText <= 0 {
+		watch.maxText = defaultWatchMaxText
+	}
+	refresh := cfg.TemplateRefresh
+	if refresh <= 0 {
+		refresh = defaultTemplateRefresh
+	}
+	// Both were checked by config.
+	gate := versionGate{}
+	gate.minVersions, _ = cfg.MinVersions()
+	if cfg.UpdateFallback != "" && cfg.UpdateFallback != "none" {
+		var fallback models.SDUIComponent
+		if err := json.Unmarshal([]byte(cfg.UpdateFallback), &fallback); err == nil {
+			gate.fallback = &fallback
+		}
+	}
+	return &Service{
+		templateDir:     templateDir,
+		translationsDir: cfg.TranslationsDir,
+		defaultLocale:   cfg.DefaultLocale,
+		requiredLocales: cfg.RequiredLocales,
+		migrationsPath:  cfg.ComponentMigrations,
+		templateRefresh: refresh,
+		unresolved:      cfg.UnresolvedPlaceholders,
+		rules:           validate.Rules{MaxDepth: cfg.MaxDepth},
+
+		validateResponses: cfg.ValidateResponses,
+		legacyCutoff:      cutoff,
+		repos:             repos,
+		brownout:          monitor,
+		stale:             newScreenCache(staleTTL),
+		templates:         newTemplateCache(),
+		assetURLs:         make(map[string]string),
+		experiments:       experiments,
+		weather:           weather,
+		flags:             flags,
+		watch:             watch,
+		gate:              gate,
+		logger:            logger,
+	}
 }
 
 // GetScreen resolves the requested screen and applies contextual data (user,
 // route, device) before returning it to the caller.
-func (s *Service) GetScreen(ctx context.Context, req models.ScreenRequest) (*models.SDUIScreen, error) {
-	tech, _ := s.repos.Technicians.GetByID(req.UserID)
+func (s *Service) GetScreen(ctx context.Context, req models.ScreenRequest) (_ Result, err error) {
+	ctx, span := tracing.Start(ctx, "sdui.GetScreen", tracing.String("screen.id", req.ScreenID))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	key := cacheKey(req)
+	if s.brownout.Degraded() {
+		if entry, ok := s.stale.get(key); ok {
+			screen := entry.screen
+			span.SetAttributes(tracing.Bool("screen.stale", true))
+			return Result{Screen: &screen, Stale: true, Age: time.Since(entry.storedAt)}, nil
+		}
+	}
+
+	repos := tracing.Repository(ctx, tenant.Repository(ctx, s.repos))
+	tech, _ := repos.Technicians.GetByID(req.UserID)
 
 	var route domain.Route
 	if !req.ServiceDate.IsZero() {
-		if r, err := s.repos.Routes.GetRoute(req.UserID, req.ServiceDate); err == nil {
-			route = r
+		if r, err := repos.Routes.GetRoute(req.UserID, req.ServiceDate); err == nil {
+			route = s.withWeather(ctx, r, req.ServiceDate)
 		}
 	}
 
-	screen := s.buildDefaultTechnicianScreen(req, tech, route)
+	// Templates from disk or the ScreenRepository take precedence; the
+	// programmatic screen is only used when no template exists.
+	t := s.translator(req.Locale)
+	sc := newScreenContext(req, tech, route)
+	b := binder{resolver: s.resolver(sc, req.ServiceDate, repos.Sync, t).withFlags(ctx, s.flags), unresolved: s.unresolved, translator: t}
+	_, renderSpan := tracing.Start(ctx, "sdui.render")
+	tpl, assignment, compact := s.pick(req, tech, true)
+	screen := s.render(req, tech, route, tpl, b, compact)
+	renderSpan.End()
+	if s.validates(ctx, sc) {
+		if err := validate.Screen(screen, s.rules); err != nil {
+			return Result{}, fmt.Errorf("screen %s: %w", req.ScreenID, err)
+		}
+	}
+
+	s.stale.put(key, screen)
+	return Result{Screen: &screen, Experiment: assignment}, nil
+}
+
+// ValidateResponsesFlag is the boolean flag that turns validation of
+// rendered screens on or off for a technician.
+const ValidateResponsesFlag = "sdui.validateResponses"
+
+// Boolean flags hiding sections of the default home screen; both sections
+// show while the flags are undefined.
+const (
+	GamificationMetricsFlag = "home.gamificationMetrics"
+	CommunicationsFlag      = "home.communications"
+)
+
+// FlagEvaluator evaluates feature flags; *flags.Client implements it.
+type FlagEvaluator interface {
+	Flag(ctx context.Context, key string, evalCtx flags.FlattenedContext) (string, bool)
+	Bool(ctx context.Context, key string, fallback bool, evalCtx flags.FlattenedContext) bool
+}
+
+// validates reports whether the screen rendered for sc is validated before
+// it is served.
+func (s *Service) validates(ctx context.Context, sc domain.ScreenContext) bool {
+	if s.flags == nil {
+		return s.validateResponses
+	}
+	return s.flags.Bool(ctx, ValidateResponsesFlag, s.validateResponses, flags.TechnicianContext(sc.Technician, sc.Metadata))
+}
+
+// WeatherAdvisor warns about the weather at a route's stops;
+// *weather.Service implements it.
+type WeatherAdvisor interface {
+	RouteAlerts(ctx context.Context, route domain.Route, serviceDate time.Time) []domain.RouteAlert
+}
 
-	_ = filepath.Join(s.templateDir, fmt.Sprintf("%s.json", req.ScreenID))
+// withWeather returns route with weather alerts for its stops added.
+func (s *Service) withWeather(ctx context.Context, route domain.Route, serviceDate time.Time) domain.Route {
+	if s.weather == nil {
+		return route
+	}
+	if alerts := s.weather.RouteAlerts(ctx, route, serviceDate); len(alerts) > 0 {
+		route.Alerts = append(append([]domain.RouteAlert(nil), route.Alerts...), alerts...)
+	}
+	return route
+}
 
-	return &screen, nil
+// pick chooses the template req is rendered with, nil meaning the
+// programmatic screen. A template written for the device class wins over
+// the screen's own template and its experiments; watches without one get
+// the phone screen simplified, reported by compact. With experiments, an
+// enrolled technician gets their variant's template version.
+func (s *Service) pick(req models.ScreenRequest, tech domain.Technician, experiments bool) (_ *compiledTemplate, _ *experiment.Assignment, compact bool) {
+	class := DeviceClass(req.DeviceModel)
+	tpl, ok := s.template(req.ScreenID)
+	var classTpl *compiledTemplate
+	hasClassTpl := false
+	if class != DeviceClassPhone {
+		classTpl, hasClassTpl = s.template(classScreenID(req.ScreenID, class))
+	}
+	var assignment *experiment.Assignment
+	if hasClassTpl {
+		tpl, ok = classTpl, true
+	} else if experiments {
+		assignment = s.assign(req, tech)
+	}
+	if assignment != nil && assignment.Version > 0 {
+		variant, err := s.templateVersion(req.ScreenID, assignment.Version)
+		if err != nil {
+			// Serve the current template and leave the request out of the
+			// experiment, so its outcome is not attributed to the variant.
+			if s.logger != nil {
+				s.logger.Warn("experiment variant unavailable", slog.String("screen", req.ScreenID), slog.String("experiment", assignment.ExperimentID), slog.Int("version", assignment.Version), slog.Any("error", err))
+			}
+			assignment = nil
+		} else {
+			tpl, ok = variant, true
+		}
+	}
+	if !ok {
+		tpl = nil
+	}
+	return tpl, assignment, class == DeviceClassWatch && !hasClassTpl
+}
+
+// render personalises tpl, or the programmatic screen when tpl is nil:
+// component IDs are assigned, placeholders and translations bound with b,
+// components req's app build cannot render replaced, and, with compact,
+// the screen simplified for a watch.
+func (s *Service) render(req models.ScreenRequest, tech domain.Technician, route domain.Route, tpl *compiledTemplate, b binder, compact bool) models.SDUIScreen {
+	var screen models.SDUIScreen
+	if tpl != nil {
+		screen = tpl.render()
+		b.dynamic = tpl.dynamic
+	} else {
+		screen = s.buildDefaultTechnicianScreen(req, tech, route, b.translator)
+	}
+	assignIDs(req.ScreenID, &screen.Component, "0")
+	b.bind(&screen)
+	s.resolveAssets(&screen.Component)
+	s.gate.apply(&screen, req.AppVersion, func(c *models.SDUIComponent) {
+		fallback := b
+		fallback.dynamic = nil
+		fallback.component(c, "0")
+	})
+	if compact {
+		s.watch.compact(&screen.Component)
+	}
+	return screen
+}
+
+// assign returns the experiment variant req's technician is enrolled in for
+// the screen, or nil.
+func (s *Service) assign(req models.ScreenRequest, tech domain.Technician) *experiment.Assignment {
+	a, ok := s.experiments.Assign(req.ScreenID, experiment.Subject{TechnicianID: req.UserID, Region: tech.Region, Role: tech.Role, AppVersion: req.AppVersion})
+	if !ok {
+		return nil
+	}
+	return &a
 }
 
-func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech domain.Technician, route domain.Route) models.SDUIScreen {
+func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech domain.Technician, route domain.Route, t *i18n.Translator) models.SDUIScreen {
 	serviceDate := req.ServiceDate
 	if serviceDate.IsZero() {
 		serviceDate = time.Now()
 	}
 
-	routeLabel := "No route assigned"
+	routeLabel := t.Lookup("route.none", "No route assigned")
 	if route.ID != "" {
-		routeLabel = fmt.Sprintf("Route %s", route.ID)
+		routeLabel = t.Sprintf("route.label", "Route %s", route.ID)
 	} else if req.RouteID != "" {
-		routeLabel = fmt.Sprintf("Route %s", req.RouteID)
+		routeLabel = t.Sprintf("route.label", "Route %s", req.RouteID)
 	}
 
-	greeting := "Good day, Technician"
-	if tech.DisplayName != "" {
-		greeting = fmt.Sprintf("Good day, %s", tech.DisplayName)
+	name := tech.DisplayName
+	if name == "" {
+		name = t.Lookup("technician.fallbackName", "Technician")
 	}
 
 	header := models.SDUIComponent{
-		ID:   uuid.NewString(),
 		Type: "text",
-		Text: greeting,
+		Text: t.Sprintf("home.greeting", "Good day, %s", name),
 		Font: "title2",
 	}
 
 	subheader := models.SDUIComponent{
-		ID:    uuid.NewString(),
 		Type:  "text",
-		Text:  fmt.Sprintf("%s • %s", routeLabel, serviceDate.Format("Jan 2, 2006")),
+		Text:  fmt.Sprintf("%s • %s", routeLabel, t.FormatDate(serviceDate)),
 		Font:  "subheadline",
 		Color: "secondary",
 	}
 
 	metricsRow := models.SDUIComponent{
-		ID:   uuid.NewString(),
 		Type: "hstack",
 		Children: []models.SDUIComponent{
 			{
-				ID:   uuid.NewString(),
 				Type: "vstack",
 				Children: []models.SDUIComponent{
-					{Type: "text", Text: "Jobs today", Font: "caption", Color: "secondary"},
+					{Type: "text", Text: t.Lookup("home.jobsToday", "Jobs today"), Font: "caption", Color: "secondary"},
 					{Type: "text", Text: "{{todayJobsCompleted}}", Font: "title3"},
 				},
 			},
 			{
-				ID:   uuid.NewString(),
 				Type: "vstack",
 				Children: []models.SDUIComponent{
-					{Type: "text", Text: "Week total", Font: "caption", Color: "secondary"},
+					{Type: "text", Text: t.Lookup("home.weekTotal", "Week total"), Font: "caption", Color: "secondary"},
 					{Type: "text", Text: "{{weekJobsCompleted}}", Font: "title3"},
 				},
 			},
 			{
-				ID:   uuid.NewString(),
 				Type: "vstack",
 				Children: []models.SDUIComponent{
-					{Type: "text", Text: "Streak", Font: "caption", Color: "secondary"},
-					{Type: "text", Text: "{{activeStreak}} days", Font: "title3"},
+					{Type: "text", Text: t.Lookup("home.streak", "Streak"), Font: "caption", Color: "secondary"},
+					{Type: "text", Text: t.Lookup("home.streakDays", "{{activeStreak}} days"), Font: "title3"},
 				},
 			},
 		},
 	}
 
 	jobList := models.SDUIComponent{
-		ID:   uuid.NewString(),
 		Type: "list",
 		ItemView: &models.SDUIComponent{
 			Type: "vstack",
@@ -142,9 +401,9 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 				{
 					Type: "hstack",
 					Children: []models.SDUIComponent{
-						{Type: "button", Label: "Start", ActionID: "startJob"},
-						{Type: "button", Label: "Complete", ActionID: "completeJob"},
-						{Type: "button", Label: "Skip", ActionID: "skipJob"},
+						{Type: "button", Label: t.Lookup("job.start", "Start"), ActionID: "startJob"},
+						{Type: "button", Label: t.Lookup("job.complete", "Complete"), ActionID: "completeJob"},
+						{Type: "button", Label: t.Lookup("job.skip", "Skip"), ActionID: "skipJob"},
 					},
 				},
 			},
@@ -155,9 +414,17 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 		jobList.ItemView = nil
 		jobList.Type = "vstack"
 		jobList.Children = make([]models.SDUIComponent, 0, len(route.CustomerStops))
+		seen := make(map[string]int, len(route.CustomerStops))
 		for _, stop := range route.CustomerStops {
+			// Keyed by customer rather than position so a stop keeps its ID
+			// when the route is reordered.
+			key := "stop/" + stop.CustomerID
+			if n := seen[stop.CustomerID]; n > 0 {
+				key += "/" + strconv.Itoa(n)
+			}
+			seen[stop.CustomerID]++
 			jobList.Children = append(jobList.Children, models.SDUIComponent{
-				ID:   uuid.NewString(),
+				ID:   stableID(req.ScreenID, key),
 				Type: "vstack",
 				Children: []models.SDUIComponent{
 					{
@@ -173,7 +440,7 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 					},
 					{
 						Type:  "text",
-						Text:  stop.WindowStart.Format("3:04 PM"),
+						Text:  t.FormatTime(stop.WindowStart),
 						Font:  "caption",
 						Color: "secondary",
 					},
@@ -183,10 +450,9 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 	}
 
 	communicationSection := models.SDUIComponent{
-		ID:   uuid.NewString(),
 		Type: "vstack",
 		Children: []models.SDUIComponent{
-			{Type: "text", Text: "Communications", Font: "headline"},
+			{Type: "text", Text: t.Lookup("home.communications", "Communications"), Font: "headline"},
 			{
 				Type:         "conditional",
 				ConditionKey: "route.hasCustomerAlerts",
@@ -205,9 +471,8 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 	}
 
 	return models.SDUIScreen{
-		Version: 5,
+		Version: 6,
 		Component: models.SDUIComponent{
-			ID:   uuid.NewString(),
 			Type: "scroll",
 			Children: []models.SDUIComponent{
 				{
@@ -215,7 +480,7 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 					Children: []models.SDUIComponent{
 						header,
 						subheader,
-						metricsRow,
+						{Type: "conditionalFlag", FlagKey: GamificationMetricsFlag, FlagDefault: true, Children: []models.SDUIComponent{metricsRow}},
 						{
 							Type: "divider",
 						},
@@ -223,10 +488,10 @@ func (s *Service) buildDefaultTechnicianScreen(req models.ScreenRequest, tech do
 						{
 							Type: "divider",
 						},
-						communicationSection,
+						{Type: "conditionalFlag", FlagKey: CommunicationsFlag, FlagDefault: true, Children: []models.SDUIComponent{communicationSection}},
 						{
 							Type:  "text",
-							Text:  "Last sync {{lastSync}} • Profile {{profileCompleteness}} complete",
+							Text:  t.Lookup("home.footer", "Last sync {{lastSync}} • Profile {{profileCompleteness}} complete"),
 							Font:  "caption",
 							Color: "secondary",
 						},
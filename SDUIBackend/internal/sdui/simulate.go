@@ -0,0 +1,105 @@
+package sdui
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+	"github.com/your-org/pestgenie-sdui/internal/tracing"
+)
+
+// ErrTechnicianNotFound is returned when a simulated technician does not
+// exist.
+var ErrTechnicianNotFound = repository.ErrTechnicianNotFound
+
+// SimulateRequest is a real technician's screen as an author chooses to
+// see it: on any service date, device, build and locale, with extra flags.
+type SimulateRequest struct {
+	ScreenID     string    `json:"screenId"`
+	TechnicianID string    `json:"technicianId"`
+	ServiceDate  time.Time `json:"serviceDate,omitempty"`
+	DeviceModel  string    `json:"deviceModel,omitempty"`
+	AppVersion   string    `json:"appVersion,omitempty"`
+	Locale       string    `json:"locale,omitempty"`
+	// Flags are added to the metadata, over the device details, so
+	// metadata.<flag> placeholders and conditions can be exercised.
+	Flags map[string]string `json:"flags,omitempty"`
+	// SkipExperiments renders the current template even when the
+	// technician is enrolled in an experiment on the screen.
+	SkipExperiments bool `json:"skipExperiments,omitempty"`
+}
+
+// SimulateResult is the simulated screen and what an author should look at.
+type SimulateResult struct {
+	Screen models.SDUIScreen `json:"screen"`
+	// Problems are validation failures of the rendered screen.
+	Problems []string `json:"problems"`
+	// Unresolved lists the placeholder keys that had no value.
+	Unresolved []string `json:"unresolved"`
+	// Experiment is the variant rendered, when the technician is enrolled.
+	Experiment *experiment.Assignment `json:"experiment,omitempty"`
+}
+
+// Simulate renders a screen the way GetScreen would for the technician,
+// against their profile, route and job history, but with the request's
+// date, device, build, locale and flags. Nothing is cached, and problems
+// are reported rather than failing the render.
+func (s *Service) Simulate(ctx context.Context, req SimulateRequest) (_ SimulateResult, err error) {
+	ctx, span := tracing.Start(ctx, "sdui.Simulate", tracing.String("screen.id", req.ScreenID))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	if !validScreenID(req.ScreenID) {
+		return SimulateResult{}, ErrInvalidTemplate.Detailf("invalid screen id %q", req.ScreenID)
+	}
+
+	repos := tracing.Repository(ctx, s.repos)
+	tech, err := repos.Technicians.GetByID(req.TechnicianID)
+	if errors.Is(err, ErrTechnicianNotFound) {
+		return SimulateResult{}, ErrTechnicianNotFound.Detailf("technician %q not found", req.TechnicianID)
+	}
+	if err != nil {
+		return SimulateResult{}, err
+	}
+	var route domain.Route
+	if !req.ServiceDate.IsZero() {
+		if r, err := repos.Routes.GetRoute(req.TechnicianID, req.ServiceDate); err == nil {
+			route = s.withWeather(ctx, r, req.ServiceDate)
+		}
+	}
+
+	screenReq := models.ScreenRequest{
+		ScreenID:    req.ScreenID,
+		UserID:      req.TechnicianID,
+		ServiceDate: req.ServiceDate,
+		DeviceModel: req.DeviceModel,
+		AppVersion:  req.AppVersion,
+		Locale:      req.Locale,
+	}
+	sc := newScreenContext(screenReq, tech, route)
+	for key, value := range req.Flags {
+		sc.Metadata[key] = value
+	}
+	t := s.translator(req.Locale)
+	recorder := &recordingResolver{Resolver: s.resolver(sc, req.ServiceDate, repos.Sync, t).withFlags(ctx, s.flags), missing: make(map[string]bool)}
+	b := binder{resolver: recorder, unresolved: s.unresolved, translator: t}
+
+	tpl, assignment, compact := s.pick(screenReq, tech, !req.SkipExperiments)
+	screen := s.render(screenReq, tech, route, tpl, b, compact)
+	result := SimulateResult{Screen: screen, Problems: validate.Problems(screen, s.rules), Unresolved: []string{}, Experiment: assignment}
+	if result.Problems == nil {
+		result.Problems = []string{}
+	}
+	for key := range recorder.missing {
+		result.Unresolved = append(result.Unresolved, key)
+	}
+	sort.Strings(result.Unresolved)
+	return result, nil
+}
@@ -0,0 +1,646 @@
+package sdui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/i18n"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/migrate"
+)
+
+// Template sources reported in the precompilation report.
+const (
+	SourceDisk         = "disk"
+	SourceRepository   = "repository"
+	SourceTranslations = "translations"
+	SourceMigrations   = "migrations"
+)
+
+// defaultTemplateRefresh is used when ScreenConfig leaves TemplateRefresh
+// unset.
+const defaultTemplateRefresh = 10 * time.Second
+
+// compiledTemplate is a parsed, validated template ready to be personalised.
+// dynamic holds the paths (see componentPath) of nodes that carry placeholders
+// or data bindings; everything else is static and can be reused as-is.
+// modTime is the file's modification time for disk templates, and version
+// and updatedAt identify the stored version of repository templates.
+// deprecated lists the deprecated constructs the template was loaded with.
+type compiledTemplate struct {
+	screenID   string
+	source     string
+	screen     models.SDUIScreen
+	dynamic    map[string]bool
+	deprecated []migrate.Usage
+	modTime    time.Time
+	version    int
+	updatedAt  time.Time
+	compiledAt time.Time
+}
+
+// PrecompileReport summarises the last precompilation run.
+type PrecompileReport struct {
+	CompiledAt time.Time          `json:"compiledAt"`
+	Compiled   []CompiledTemplate `json:"compiled"`
+	Failed     []TemplateFailure  `json:"failed"`
+	// Locales are the translation catalogs loaded with the templates.
+	Locales []string `json:"locales"`
+	// NeedsManualFix lists the compiled screens using deprecated
+	// components that could not be migrated automatically.
+	NeedsManualFix []string `json:"needsManualFix"`
+}
+
+// CompiledTemplate describes a template that is warm in the cache.
+type CompiledTemplate struct {
+	ScreenID     string `json:"screenId"`
+	Source       string `json:"source"`
+	Version      int    `json:"version"`
+	Components   int    `json:"components"`
+	DynamicNodes int    `json:"dynamicNodes"`
+	// Deprecated lists the deprecated components and fields the template
+	// uses, rewritten or not.
+	Deprecated []migrate.Usage `json:"deprecated,omitempty"`
+}
+
+// TemplateFailure describes a template that could not be precompiled.
+type TemplateFailure struct {
+	ScreenID string `json:"screenId"`
+	Source   string `json:"source"`
+	Version  int    `json:"version,omitempty"`
+	Error    string `json:"error"`
+}
+
+// templateCache holds compiled templates keyed by screen ID. versions holds
+// specific repository versions served to experiment variants, keyed by
+// versionKey; published versions never change, so they are only dropped
+// when deleted.
+type templateCache struct {
+	mu       sync.RWMutex
+	compiled map[string]*compiledTemplate
+	versions map[string]*compiledTemplate
+	// catalogs are the files catalogs with the uploaded messages layered
+	// over them (see layered).
+	catalogs *i18n.Catalogs
+	files    *i18n.Catalogs
+	uploaded map[string]map[string]string
+	// migrations rewrite deprecated components as templates are compiled.
+	migrations *migrate.Registry
+	report     PrecompileReport
+	// checked is when the repository templates were last compared with the
+	// ScreenRepository; refreshing lets one request at a time do so.
+	checked    time.Time
+	refreshing sync.Mutex
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{compiled: make(map[string]*compiledTemplate), versions: make(map[string]*compiledTemplate), uploaded: make(map[string]map[string]string)}
+}
+
+func versionKey(screenID string, version int) string {
+	return screenID + "@" + strconv.Itoa(version)
+}
+
+func (c *templateCache) get(screenID string) (*compiledTemplate, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tpl, ok := c.compiled[screenID]
+	return tpl, ok
+}
+
+// install caches a freshly compiled template and updates the report. The
+// caller must hold c.mu.
+func (c *templateCache) install(tpl *compiledTemplate) {
+	c.compiled[tpl.screenID] = tpl
+	c.report.Failed = withoutTemplate(c.report.Failed, tpl.screenID)
+	c.report.Compiled = append(withoutCompiled(c.report.Compiled, tpl.screenID), describe(tpl))
+	sort.Slice(c.report.Compiled, func(i, j int) bool { return c.report.Compiled[i].ScreenID < c.report.Compiled[j].ScreenID })
+}
+
+// evict drops screenID's compiled template if it came from source, so the
+// next request falls back to disk or the programmatic default.
+func (c *templateCache) evict(screenID, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tpl, ok := c.compiled[screenID]; ok && tpl.source == source {
+		delete(c.compiled, screenID)
+		c.report.Compiled = withoutCompiled(c.report.Compiled, screenID)
+	}
+	c.report.Failed = withoutTemplate(c.report.Failed, screenID)
+}
+
+// template returns the compiled template for screenID. Disk templates are
+// checked against their file on every call: edited files are recompiled,
+// deleted files are evicted, and files added since precompilation are
+// picked up. A file that fails to compile leaves the previous version in
+// place. Repository templates are kept current by TemplatePublished on the
+// instance that publishes them, and by refreshTemplates on the others.
+func (s *Service) template(screenID string) (*compiledTemplate, bool) {
+	s.refreshTemplates()
+	cached, ok := s.templates.get(screenID)
+	if (ok && cached.source != SourceDisk) || s.templateDir == "" || !validScreenID(screenID) {
+		return cached, ok
+	}
+
+	path := filepath.Join(s.templateDir, screenID+".json")
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if ok {
+			s.templates.mu.Lock()
+			if s.templates.compiled[screenID] == cached {
+				delete(s.templates.compiled, screenID)
+				s.templates.report.Compiled = withoutCompiled(s.templates.report.Compiled, screenID)
+			}
+			s.templates.mu.Unlock()
+		}
+		return nil, false
+	}
+	if err != nil || (ok && info.ModTime().Equal(cached.modTime)) {
+		return cached, ok
+	}
+
+	compiled, err := s.loadDiskTemplate(screenID, path, info)
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if current := s.templates.compiled[screenID]; current != cached {
+		// Precompile or another request got there first.
+		return current, current != nil
+	}
+	if err != nil {
+		report := &s.templates.report
+		report.Failed = append(withoutTemplate(report.Failed, screenID), TemplateFailure{ScreenID: screenID, Source: SourceDisk, Error: err.Error()})
+		if s.logger != nil {
+			s.logger.Warn("template reload failed", slog.String("screen", screenID), slog.String("error", err.Error()))
+		}
+		return cached, ok
+	}
+	s.templates.install(compiled)
+	return compiled, true
+}
+
+// templateVersion returns a specific repository version of screenID,
+// compiling it on first use.
+func (s *Service) templateVersion(screenID string, version int) (*compiledTemplate, error) {
+	s.refreshTemplates()
+	key := versionKey(screenID, version)
+	s.templates.mu.RLock()
+	tpl, ok := s.templates.versions[key]
+	s.templates.mu.RUnlock()
+	if ok {
+		return tpl, nil
+	}
+	saved, err := s.repos.Screens.GetTemplate(screenID, version)
+	if err != nil {
+		return nil, err
+	}
+	if tpl, err = s.compileSaved(saved); err != nil {
+		return nil, err
+	}
+	s.templates.mu.Lock()
+	s.templates.versions[key] = tpl
+	s.templates.mu.Unlock()
+	return tpl, nil
+}
+
+// Precompile parses and validates every template on disk and in the
+// ScreenRepository, replacing the warm cache. Repository templates win over
+// disk templates with the same screen ID since they are published at runtime.
+// Translation catalog files are reloaded along with the templates; uploaded
+// translations stay layered over them. So are the component migrations,
+// which are kept as they were when the file fails to load.
+func (s *Service) Precompile() PrecompileReport {
+	compiled := make(map[string]*compiledTemplate)
+	report := PrecompileReport{CompiledAt: time.Now(), Compiled: []CompiledTemplate{}, Failed: []TemplateFailure{}}
+
+	catalogs, err := i18n.Load(s.translationsDir, s.defaultLocale)
+	if err != nil {
+		report.Failed = append(report.Failed, TemplateFailure{Source: SourceTranslations, Error: err.Error()})
+	}
+	if migrations, err := migrate.Load(s.migrationsPath); err != nil {
+		report.Failed = append(report.Failed, TemplateFailure{Source: SourceMigrations, Error: err.Error()})
+	} else {
+		s.templates.mu.Lock()
+		s.templates.migrations = migrations
+		s.templates.mu.Unlock()
+	}
+
+	for _, tpl := range s.loadDiskTemplates(&report) {
+		compiled[tpl.screenID] = tpl
+	}
+	for _, tpl := range s.loadRepositoryTemplates(&report) {
+		compiled[tpl.screenID] = tpl
+	}
+
+	for _, tpl := range compiled {
+		report.Compiled = append(report.Compiled, describe(tpl))
+	}
+	sort.Slice(report.Compiled, func(i, j int) bool { return report.Compiled[i].ScreenID < report.Compiled[j].ScreenID })
+
+	s.templates.mu.Lock()
+	s.templates.compiled = compiled
+	s.templates.files = catalogs
+	s.templates.catalogs = s.templates.layered()
+	report.Locales = s.templates.catalogs.Locales()
+	report.NeedsManualFix = needsManualFix(report.Compiled)
+	s.templates.report = report
+	s.templates.checked = time.Now()
+	s.templates.mu.Unlock()
+	return report
+}
+
+// TemplatePublished recompiles a single template after it has been saved so
+// the next request for it is served warm. A template that fails to compile
+// leaves the previous version in the cache and is recorded in the report.
+func (s *Service) TemplatePublished(tpl domain.ScreenTemplate) error {
+	compiled, err := s.compileSaved(tpl)
+
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	s.templates.installSaved(tpl, compiled, err)
+	return err
+}
+
+// installSaved caches a repository template compiled from tpl, or records
+// why it failed to compile. The caller must hold c.mu.
+func (c *templateCache) installSaved(tpl domain.ScreenTemplate, compiled *compiledTemplate, err error) {
+	if err != nil {
+		c.report.Failed = append(withoutTemplate(c.report.Failed, tpl.ID), TemplateFailure{ScreenID: tpl.ID, Source: SourceRepository, Version: tpl.Version, Error: err.Error()})
+		return
+	}
+	c.install(compiled)
+}
+
+// refreshTemplates brings the repository templates in the cache in line
+// with the ScreenRepository, at most once per refresh interval, so versions
+// published or deleted through another instance are served here too. A
+// template changed in the cache meanwhile, such as by a publish on this
+// instance, is left as it is.
+func (s *Service) refreshTemplates() {
+	c := s.templates
+	c.mu.RLock()
+	due := time.Since(c.checked) >= s.templateRefresh
+	c.mu.RUnlock()
+	if !due || !c.refreshing.TryLock() {
+		return
+	}
+	defer c.refreshing.Unlock()
+
+	saved, err := s.repos.Screens.ListTemplates()
+	c.mu.Lock()
+	c.checked = time.Now()
+	c.mu.Unlock()
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("template refresh failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+	latest := make(map[string]domain.ScreenTemplate)
+	stored := make(map[string]bool, len(saved))
+	for _, tpl := range saved {
+		stored[versionKey(tpl.ID, tpl.Version)] = true
+		if current, ok := latest[tpl.ID]; !ok || tpl.Version > current.Version {
+			latest[tpl.ID] = tpl
+		}
+	}
+
+	type change struct {
+		tpl    domain.ScreenTemplate
+		cached *compiledTemplate
+	}
+	var changed []change
+	c.mu.Lock()
+	for id, cached := range c.compiled {
+		if _, ok := latest[id]; !ok && cached.source == SourceRepository {
+			delete(c.compiled, id)
+			c.report.Compiled = withoutCompiled(c.report.Compiled, id)
+		}
+	}
+	for key := range c.versions {
+		if !stored[key] {
+			delete(c.versions, key)
+		}
+	}
+	for id, tpl := range latest {
+		cached := c.compiled[id]
+		if cached == nil || cached.source != SourceRepository || cached.version != tpl.Version || !cached.updatedAt.Equal(tpl.UpdatedAt) {
+			changed = append(changed, change{tpl: tpl, cached: cached})
+		}
+	}
+	c.mu.Unlock()
+
+	for _, ch := range changed {
+		compiled, err := s.compileSaved(ch.tpl)
+		c.mu.Lock()
+		if c.compiled[ch.tpl.ID] == ch.cached {
+			c.installSaved(ch.tpl, compiled, err)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// PrecompileReport returns the result of the most recent precompilation.
+func (s *Service) PrecompileReport() PrecompileReport {
+	s.templates.mu.RLock()
+	defer s.templates.mu.RUnlock()
+	report := s.templates.report
+	report.Compiled = append([]CompiledTemplate(nil), report.Compiled...)
+	report.Failed = append([]TemplateFailure(nil), report.Failed...)
+	report.Locales = append([]string(nil), report.Locales...)
+	report.NeedsManualFix = needsManualFix(report.Compiled)
+	return report
+}
+
+// MigrationReport is the component migrations in effect and the compiled
+// templates that use deprecated components.
+type MigrationReport struct {
+	Rules          []migrate.Rule     `json:"rules"`
+	Templates      []CompiledTemplate `json:"templates"`
+	NeedsManualFix []string           `json:"needsManualFix"`
+}
+
+// Migrations reports the loaded migrations and the templates they apply to.
+func (s *Service) Migrations() MigrationReport {
+	s.templates.mu.RLock()
+	defer s.templates.mu.RUnlock()
+	report := MigrationReport{Rules: s.templates.migrations.Rules(), Templates: []CompiledTemplate{}}
+	for _, c := range s.templates.report.Compiled {
+		if len(c.Deprecated) > 0 {
+			report.Templates = append(report.Templates, c)
+		}
+	}
+	report.NeedsManualFix = needsManualFix(report.Templates)
+	return report
+}
+
+// translator returns a translator for locale from the loaded catalogs.
+func (s *Service) translator(locale string) *i18n.Translator {
+	s.templates.mu.RLock()
+	defer s.templates.mu.RUnlock()
+	return s.templates.catalogs.For(locale)
+}
+
+func (s *Service) loadDiskTemplates(report *PrecompileReport) []*compiledTemplate {
+	if s.templateDir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(s.templateDir, "*.json"))
+	if err != nil {
+		report.Failed = append(report.Failed, TemplateFailure{Source: SourceDisk, Error: err.Error()})
+		return nil
+	}
+
+	var out []*compiledTemplate
+	for _, path := range paths {
+		screenID := strings.TrimSuffix(filepath.Base(path), ".json")
+		info, err := os.Stat(path)
+		if err == nil {
+			var tpl *compiledTemplate
+			if tpl, err = s.loadDiskTemplate(screenID, path, info); err == nil {
+				out = append(out, tpl)
+				continue
+			}
+		}
+		report.Failed = append(report.Failed, TemplateFailure{ScreenID: screenID, Source: SourceDisk, Error: err.Error()})
+	}
+	return out
+}
+
+func (s *Service) loadDiskTemplate(screenID, path string, info fs.FileInfo) (*compiledTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := s.compile(screenID, SourceDisk, data)
+	if err != nil {
+		return nil, err
+	}
+	tpl.modTime = info.ModTime()
+	return tpl, nil
+}
+
+func (s *Service) loadRepositoryTemplates(report *PrecompileReport) []*compiledTemplate {
+	templates, err := s.repos.Screens.ListTemplates()
+	if err != nil {
+		report.Failed = append(report.Failed, TemplateFailure{Source: SourceRepository, Error: err.Error()})
+		return nil
+	}
+
+	latest := make(map[string]domain.ScreenTemplate)
+	for _, tpl := range templates {
+		if current, ok := latest[tpl.ID]; !ok || tpl.Version > current.Version {
+			latest[tpl.ID] = tpl
+		}
+	}
+
+	var out []*compiledTemplate
+	for _, tpl := range latest {
+		compiled, err := s.compileSaved(tpl)
+		if err != nil {
+			report.Failed = append(report.Failed, TemplateFailure{ScreenID: tpl.ID, Source: SourceRepository, Version: tpl.Version, Error: err.Error()})
+			continue
+		}
+		out = append(out, compiled)
+	}
+	return out
+}
+
+// compileSaved compiles a template stored in the ScreenRepository.
+func (s *Service) compileSaved(tpl domain.ScreenTemplate) (*compiledTemplate, error) {
+	compiled, err := s.compile(tpl.ID, SourceRepository, tpl.PayloadJSON)
+	if err != nil {
+		return nil, err
+	}
+	compiled.version, compiled.updatedAt = tpl.Version, tpl.UpdatedAt
+	return compiled, nil
+}
+
+// compile migrates a template's deprecated components and compiles it,
+// logging the deprecated constructs it uses.
+func (s *Service) compile(screenID, source string, payload []byte) (*compiledTemplate, error) {
+	payload, migrated := s.migrations().Apply(payload)
+	tpl, err := compileTemplate(screenID, source, payload)
+	if err != nil {
+		return nil, err
+	}
+	tpl.deprecated = migrated.Usages
+	if len(migrated.Usages) > 0 && s.logger != nil {
+		s.logger.Warn("template uses deprecated components",
+			slog.String("screen", screenID),
+			slog.String("source", source),
+			slog.Int("version", tpl.screen.Version),
+			slog.Int("usages", len(migrated.Usages)),
+			slog.Int("manual", len(migrated.Manual())),
+		)
+	}
+	return tpl, nil
+}
+
+// migrations returns the component migrations currently loaded.
+func (s *Service) migrations() *migrate.Registry {
+	s.templates.mu.RLock()
+	defer s.templates.mu.RUnlock()
+	return s.templates.migrations
+}
+
+// compileTemplate parses a template payload, checks it is structurally sound
+// and records which nodes need per-request rendering.
+func compileTemplate(screenID, source string, payload []byte) (*compiledTemplate, error) {
+	var screen models.SDUIScreen
+	if err := json.Unmarshal(payload, &screen); err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	if screen.Version <= 0 {
+		return nil, errors.New("template version must be positive")
+	}
+
+	dynamic := make(map[string]bool)
+	if _, err := markDynamic(screen.Component, "0", dynamic); err != nil {
+		return nil, err
+	}
+	return &compiledTemplate{
+		screenID:   screenID,
+		source:     source,
+		screen:     screen,
+		dynamic:    dynamic,
+		compiledAt: time.Now(),
+	}, nil
+}
+
+// markDynamic walks the tree, recording every node whose subtree contains a
+// placeholder or binding, and reports whether c itself is dynamic.
+func markDynamic(c models.SDUIComponent, path string, dynamic map[string]bool) (bool, error) {
+	if c.Type == "" {
+		return false, fmt.Errorf("component at %s has no type", path)
+	}
+
+	isDynamic := c.Key != "" || c.ValueKey != "" || c.ConditionKey != "" || c.Type == "conditionalFlag" || c.TextKey != "" ||
+		hasPlaceholder(c.Text) || hasPlaceholder(c.Label) || hasPlaceholder(c.Placeholder)
+
+	for i, child := range c.Children {
+		childDynamic, err := markDynamic(child, componentPath(path, i), dynamic)
+		if err != nil {
+			return false, err
+		}
+		isDynamic = isDynamic || childDynamic
+	}
+	if c.ItemView != nil {
+		// List item views are always rendered per element.
+		if _, err := markDynamic(*c.ItemView, path+".item", dynamic); err != nil {
+			return false, err
+		}
+		isDynamic = true
+	}
+
+	if isDynamic {
+		dynamic[path] = true
+	}
+	return isDynamic, nil
+}
+
+// render returns a copy of the compiled screen that callers may personalise
+// without mutating the cached tree.
+func (t *compiledTemplate) render() models.SDUIScreen {
+	return models.SDUIScreen{Version: t.screen.Version, MinAppVersion: t.screen.MinAppVersion, Component: cloneComponent(t.screen.Component)}
+}
+
+func cloneComponent(c models.SDUIComponent) models.SDUIComponent {
+	out := c
+	if c.Children != nil {
+		out.Children = make([]models.SDUIComponent, len(c.Children))
+		for i, child := range c.Children {
+			out.Children[i] = cloneComponent(child)
+		}
+	}
+	if c.ItemView != nil {
+		item := cloneComponent(*c.ItemView)
+		out.ItemView = &item
+	}
+	if c.Options != nil {
+		out.Options = append([]models.SDUIPickerOption(nil), c.Options...)
+	}
+	return out
+}
+
+func componentPath(parent string, index int) string {
+	return parent + "." + strconv.Itoa(index)
+}
+
+func hasPlaceholder(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+func countComponents(c models.SDUIComponent) int {
+	n := 1
+	for _, child := range c.Children {
+		n += countComponents(child)
+	}
+	if c.ItemView != nil {
+		n += countComponents(*c.ItemView)
+	}
+	return n
+}
+
+func describe(tpl *compiledTemplate) CompiledTemplate {
+	return CompiledTemplate{
+		ScreenID:     tpl.screenID,
+		Source:       tpl.source,
+		Version:      tpl.screen.Version,
+		Components:   countComponents(tpl.screen.Component),
+		DynamicNodes: len(tpl.dynamic),
+		Deprecated:   tpl.deprecated,
+	}
+}
+
+// needsManualFix returns the screens of compiled whose deprecated
+// components could not all be migrated.
+func needsManualFix(compiled []CompiledTemplate) []string {
+	out := []string{}
+	for _, c := range compiled {
+		for _, u := range c.Deprecated {
+			if u.Action == migrate.ActionManual {
+				out = append(out, c.ScreenID)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// validScreenID rejects IDs that could name a file outside the template
+// directory.
+func validScreenID(screenID string) bool {
+	return screenID != "" && screenID != "." && screenID != ".." && !strings.ContainsAny(screenID, `/\`)
+}
+
+func withoutCompiled(compiled []CompiledTemplate, screenID string) []CompiledTemplate {
+	out := make([]CompiledTemplate, 0, len(compiled))
+	for _, c := range compiled {
+		if c.ScreenID != screenID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func withoutTemplate(failures []TemplateFailure, screenID string) []TemplateFailure {
+	out := failures[:0]
+	for _, f := range failures {
+		if f.ScreenID != screenID {
+			out = append(out, f)
+		}
+	}
+	return out
+}
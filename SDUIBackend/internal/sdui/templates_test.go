@@ -0,0 +1,698 @@
+package sdui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/sdui/validate"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newTestService(t *testing.T, dir string) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{
+		Technicians: store,
+		Routes:      store,
+		Screens:     store,
+		Sync:        store,
+		Devices:     store,
+	}
+	monitor := brownout.NewMonitor(config.BrownoutConfig{})
+	return NewService(dir, config.ScreenConfig{UnresolvedPlaceholders: UnresolvedKeep}, repos, monitor, time.Minute, nil, nil, nil, nil), store
+}
+
+func writeTemplate(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestPrecompileReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":2,"component":{"type":"vstack","children":[{"type":"text","text":"Hello {{name}}"},{"type":"divider"}]}}`)
+	writeTemplate(t, dir, "broken.json", `{"version":1,"component":`)
+	writeTemplate(t, dir, "untyped.json", `{"version":1,"component":{"children":[{"type":"text"}]}}`)
+
+	svc, _ := newTestService(t, dir)
+	report := svc.Precompile()
+
+	if len(report.Compiled) != 1 || report.Compiled[0].ScreenID != "home" {
+		t.Fatalf("expected only home compiled, got %+v", report.Compiled)
+	}
+	if report.Compiled[0].Components != 3 || report.Compiled[0].DynamicNodes != 2 {
+		t.Errorf("unexpected compile stats: %+v", report.Compiled[0])
+	}
+	if len(report.Failed) != 2 {
+		t.Fatalf("expected 2 failures, got %+v", report.Failed)
+	}
+
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Screen.Version != 2 {
+		t.Fatalf("expected precompiled template version 2, got %d", res.Screen.Version)
+	}
+}
+
+func TestRepositoryTemplatesOverrideDisk(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"text","text":"disk"}}`)
+
+	svc, store := newTestService(t, dir)
+	_ = store.SaveTemplate(domain.ScreenTemplate{ID: "home", Version: 3, PayloadJSON: []byte(`{"version":3,"component":{"type":"text","text":"repo"}}`)})
+
+	report := svc.Precompile()
+	if len(report.Compiled) != 1 || report.Compiled[0].Source != SourceRepository {
+		t.Fatalf("expected repository template to win, got %+v", report.Compiled)
+	}
+}
+
+func TestTemplatePublishedKeepsPreviousOnFailure(t *testing.T) {
+	svc, _ := newTestService(t, "")
+	svc.Precompile()
+
+	good := domain.ScreenTemplate{ID: "jobs", Version: 1, PayloadJSON: []byte(`{"version":1,"component":{"type":"list","itemView":{"type":"text","key":"name"}}}`)}
+	if err := svc.TemplatePublished(good); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bad := domain.ScreenTemplate{ID: "jobs", Version: 2, PayloadJSON: []byte(`not json`)}
+	if err := svc.TemplatePublished(bad); err == nil {
+		t.Fatalf("expected compile error")
+	}
+
+	report := svc.PrecompileReport()
+	if len(report.Compiled) != 1 || report.Compiled[0].Version != 1 {
+		t.Fatalf("expected version 1 to stay warm, got %+v", report.Compiled)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Version != 2 {
+		t.Fatalf("expected version 2 failure recorded, got %+v", report.Failed)
+	}
+}
+
+func TestDiskTemplatesReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	svc, _ := newTestService(t, dir)
+	svc.Precompile()
+	ctx := context.Background()
+	req := models.ScreenRequest{ScreenID: "jobs"}
+
+	// Added after precompilation.
+	writeTemplate(t, dir, "jobs.json", `{"version":1,"component":{"type":"text","text":"v1"}}`)
+	if res, _ := svc.GetScreen(ctx, req); res.Screen.Component.Text != "v1" {
+		t.Fatalf("expected new disk template, got %+v", res.Screen.Component)
+	}
+
+	path := filepath.Join(dir, "jobs.json")
+	writeTemplate(t, dir, "jobs.json", `{"version":2,"component":{"type":"text","text":"v2"}}`)
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(path, later, later)
+	if res, _ := svc.GetScreen(ctx, req); res.Screen.Component.Text != "v2" {
+		t.Fatalf("expected reloaded template, got %+v", res.Screen.Component)
+	}
+
+	// A broken edit keeps serving the last good version.
+	writeTemplate(t, dir, "jobs.json", `{"version":3,`)
+	later = later.Add(time.Minute)
+	_ = os.Chtimes(path, later, later)
+	if res, _ := svc.GetScreen(ctx, req); res.Screen.Component.Text != "v2" {
+		t.Fatalf("expected previous template to stay, got %+v", res.Screen.Component)
+	}
+	if report := svc.PrecompileReport(); len(report.Failed) != 1 || report.Failed[0].ScreenID != "jobs" {
+		t.Fatalf("expected reload failure in report, got %+v", report.Failed)
+	}
+
+	_ = os.Remove(path)
+	if res, _ := svc.GetScreen(ctx, req); res.Screen.Component.Type != "scroll" {
+		t.Fatalf("expected default screen after delete, got %+v", res.Screen.Component)
+	}
+	if _, ok := svc.template("../jobs"); ok {
+		t.Fatalf("screen IDs must not escape the template directory")
+	}
+}
+
+func TestTemplateBindsContext(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","text":"Hi {{ technician.name }}, {{route.label}} has {{route.stopCount}} stops"},
+		{"type":"text","text":"{{todayJobsCompleted}} done"},
+		{"type":"list","itemView":{"type":"text","text":"{{technician.name}}"}}]}}`)
+	svc, store := newTestService(t, dir)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Ana"})
+	svc.Precompile()
+
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "t1", RouteID: "r9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	children := res.Screen.Component.Children
+	if got := children[0].Text; got != "Hi Ana, Route r9 has 0 stops" {
+		t.Errorf("unexpected bound text %q", got)
+	}
+	if got := children[1].Text; got != "0 done" {
+		t.Errorf("expected stats to resolve, got %q", got)
+	}
+	if got := children[2].ItemView.Text; got != "{{technician.name}}" {
+		t.Errorf("item views must be left for the client, got %q", got)
+	}
+
+	// The cached template must not be mutated by binding.
+	tpl, _ := svc.template("home")
+	if tpl.screen.Component.Children[0].Text == children[0].Text {
+		t.Fatalf("binding mutated the cached template")
+	}
+}
+
+func TestPublishTemplateVersions(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"text","text":"from disk"}}`)
+	svc, _ := newTestService(t, dir)
+	svc.Precompile()
+	text := func() string {
+		t.Helper()
+		res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res.Screen.Component.Text
+	}
+
+	for name, payload := range map[string]string{
+		"unknown field": `{"version":1,"component":{"type":"text","colour":"red"}}`,
+		"no type":       `{"version":1,"component":{"children":[{"type":"text"}]}}`,
+		"duplicate id":  `{"version":1,"component":{"type":"vstack","children":[{"id":"a","type":"text"},{"id":"a","type":"text"}]}}`,
+		"no version":    `{"component":{"type":"text"}}`,
+		"unknown type":  `{"version":1,"component":{"type":"marquee"}}`,
+	} {
+		if _, err := svc.CreateTemplate("home", []byte(payload)); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("%s: expected invalid template, got %v", name, err)
+		}
+	}
+	if _, err := svc.CreateTemplate("precompile", []byte(`{"version":1,"component":{"type":"text"}}`)); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("expected reserved screen id rejected, got %v", err)
+	}
+
+	v1, err := svc.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v1"}}`))
+	if err != nil || v1.Version != 1 || text() != "v1" {
+		t.Fatalf("unexpected create %+v (%v)", v1, err)
+	}
+	if _, err := svc.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"text"}}`)); !errors.Is(err, ErrTemplateExists) {
+		t.Fatalf("expected duplicate create rejected, got %v", err)
+	}
+	v2, err := svc.UpdateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v2"}}`))
+	if err != nil || v2.Version != 2 || text() != "v2" {
+		t.Fatalf("unexpected update %+v (%v)", v2, err)
+	}
+	if _, err := svc.UpdateTemplate("missing", []byte(`{"version":1,"component":{"type":"text"}}`)); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected update of a missing screen to fail, got %v", err)
+	}
+	if versions, err := svc.TemplateVersions("home"); err != nil || len(versions) != 2 || versions[0].Screen != nil {
+		t.Fatalf("unexpected versions %+v (%v)", versions, err)
+	}
+
+	// Deleting the latest version falls back to the previous one, and
+	// deleting the rest falls back to disk.
+	if err := svc.DeleteTemplate("home", 2); err != nil || text() != "v1" {
+		t.Fatalf("expected v1 after deleting v2 (%v)", err)
+	}
+	if err := svc.DeleteTemplate("home", 0); err != nil || text() != "from disk" {
+		t.Fatalf("expected the disk template after deleting every version (%v)", err)
+	}
+	if err := svc.DeleteTemplate("home", 0); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected not found once deleted, got %v", err)
+	}
+}
+
+func TestTemplatesPublishedElsewhereAreServed(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"text","text":"from disk"}}`)
+	publisher, store := newTestService(t, dir)
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.ScreenConfig{UnresolvedPlaceholders: UnresolvedKeep, TemplateRefresh: time.Millisecond}
+	other := NewService(dir, cfg, repos, brownout.NewMonitor(config.BrownoutConfig{}), time.Minute, nil, nil, nil, nil)
+	other.Precompile()
+	text := func() string {
+		t.Helper()
+		time.Sleep(2 * time.Millisecond)
+		res, err := other.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res.Screen.Component.Text
+	}
+
+	if _, err := publisher.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v1"}}`)); err != nil || text() != "v1" {
+		t.Fatalf("expected the other instance to serve v1 (%v)", err)
+	}
+	if _, err := publisher.UpdateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v2"}}`)); err != nil || text() != "v2" {
+		t.Fatalf("expected the other instance to serve v2 (%v)", err)
+	}
+	if err := publisher.DeleteTemplate("home", 0); err != nil || text() != "from disk" {
+		t.Fatalf("expected the other instance back on the disk template (%v)", err)
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "broken.json", `{"version":1,"component":{"type":"vstack","children":[{"type":"button","label":"Go"}]}}`)
+	svc, _ := newTestService(t, dir)
+	if _, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "broken"}); err != nil {
+		t.Fatalf("expected no validation by default, got %v", err)
+	}
+
+	svc.validateResponses = true
+	if _, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "broken"}); !errors.Is(err, validate.ErrInvalid) {
+		t.Fatalf("expected the broken screen rejected, got %v", err)
+	}
+	if _, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", ServiceDate: time.Now()}); err != nil {
+		t.Fatalf("expected the default screen to validate, got %v", err)
+	}
+}
+
+func TestExperimentServesVariantVersion(t *testing.T) {
+	svc, store := newTestService(t, "")
+	experiments := experiment.NewService(experiment.NewMemoryStore(), repository.Repository{Screens: store}, nil)
+	svc.experiments = experiments
+	if _, err := svc.CreateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v1"}}`)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.UpdateTemplate("home", []byte(`{"version":1,"component":{"type":"text","text":"v2"}}`)); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// Pin the current version as the live template while trialling v1.
+	if _, err := experiments.Save("home-v1", experiment.Experiment{
+		ScreenID: "home", Status: experiment.StatusRunning,
+		Variants: []experiment.Variant{{Name: "old", Version: 1, Weight: 1}},
+		Cohort:   experiment.Cohort{TechnicianIDs: []string{"t1"}},
+	}); err != nil {
+		t.Fatalf("save experiment: %v", err)
+	}
+
+	h := NewHandler(svc, nil, nil)
+	router := chi.NewRouter()
+	router.Get("/v1/screens/{screenId}", h.GetScreen)
+	get := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/screens/home", nil)
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: user, Role: auth.RoleTechnician}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("t1")
+	if rec.Header().Get("X-SDUI-Experiment") != "home-v1=old" || !strings.Contains(rec.Body.String(), `"v1"`) {
+		t.Fatalf("expected the pinned technician served v1 with the assignment header, got %v %s", rec.Header(), rec.Body)
+	}
+	rec = get("t2")
+	if rec.Header().Get("X-SDUI-Experiment") != "" || !strings.Contains(rec.Body.String(), `"v2"`) {
+		t.Fatalf("expected everyone else served the latest version, got %v %s", rec.Header(), rec.Body)
+	}
+
+	// A deleted variant version falls back to the current template without
+	// attributing the request to the experiment.
+	if err := svc.DeleteTemplate("home", 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	rec = get("t1")
+	if rec.Header().Get("X-SDUI-Experiment") != "" || !strings.Contains(rec.Body.String(), `"v2"`) {
+		t.Fatalf("expected the current template after the variant was deleted, got %v %s", rec.Header(), rec.Body)
+	}
+}
+
+func TestWatchScreens(t *testing.T) {
+	dir := t.TempDir()
+	long := strings.Repeat("word ", 30)
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","text":"Today","font":"largeTitle"},
+		{"type":"image","key":"logo"},
+		{"type":"hstack","children":[{"type":"text","text":"`+long+`"},{"type":"textField","valueKey":"note"}]},
+		{"type":"vstack","children":[{"type":"chart"}]},
+		{"type":"list","children":[{"type":"text","text":"1"},{"type":"text","text":"2"},{"type":"text","text":"3"}]}]}}`)
+	writeTemplate(t, dir, "route.json", `{"version":1,"component":{"type":"text","text":"phone route"}}`)
+	writeTemplate(t, dir, "route.watch.json", `{"version":1,"component":{"type":"text","text":"watch route"}}`)
+	svc, _ := newTestService(t, dir)
+	svc.watch = compactor{maxItems: 2, maxText: 20}
+	get := func(screenID, model string) models.SDUIComponent {
+		t.Helper()
+		res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: screenID, DeviceModel: model})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res.Screen.Component
+	}
+
+	if got := get("route", "Watch6,1").Text; got != "watch route" {
+		t.Fatalf("expected the watch template, got %q", got)
+	}
+	if got := get("route", "iPad13,4").Text; got != "phone route" {
+		t.Fatalf("expected tablets without a template served the phone screen, got %q", got)
+	}
+
+	phone := get("home", "iPhone15,2")
+	if len(phone.Children) != 5 {
+		t.Fatalf("expected the phone screen untouched, got %+v", phone)
+	}
+	watch := get("home", "Watch6,1")
+	if len(watch.Children) != 2 {
+		t.Fatalf("expected hidden components dropped and the rest capped, got %+v", watch.Children)
+	}
+	title, row := watch.Children[0], watch.Children[1]
+	if title.Font != "headline" || title.ID != phone.Children[0].ID {
+		t.Errorf("expected a shrunk title keeping its ID, got %+v", title)
+	}
+	if row.Type != "vstack" || len(row.Children) != 1 || len([]rune(row.Children[0].Text)) != 20 || !strings.HasSuffix(row.Children[0].Text, "…") {
+		t.Errorf("expected the row restacked with its text shortened, got %+v", row)
+	}
+}
+
+func TestTranslatedScreens(t *testing.T) {
+	dir, translations := t.TempDir(), t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","textKey":"home.title","text":"Today"},
+		{"type":"text","textKey":"home.greeting","text":"Hi {{technician.name}}"},
+		{"type":"button","textKey":"job.start","label":"Start","actionId":"startJob"},
+		{"type":"text","text":"{{serviceDate}}"}]}}`)
+	writeTemplate(t, translations, "es.json", `{"home":{"title":"Hoy","greeting":"Hola {{technician.name}}"},"job":{"start":"Iniciar"},"format":{"date":"2 Jan 2006"},"month":{"jan":"ene"}}`)
+	writeTemplate(t, translations, "es_MX.po", "# Mexican Spanish\nmsgid \"\"\nmsgstr \"Language: es-MX\\n\"\n\nmsgid \"home.title\"\nmsgstr \"Hoy mismo\"\n")
+	svc, store := newTestService(t, dir)
+	svc.translationsDir, svc.defaultLocale = translations, "en"
+	_ = store.SaveTechnician(domain.Technician{ID: "tech-1", DisplayName: "Ana"})
+	if report := svc.Precompile(); len(report.Failed) != 0 || strings.Join(report.Locales, ",") != "es,es-MX" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	get := func(locale string) []models.SDUIComponent {
+		t.Helper()
+		res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "tech-1", Locale: locale, ServiceDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res.Screen.Component.Children
+	}
+	mx := get("es-MX")
+	if mx[0].Text != "Hoy mismo" || mx[1].Text != "Hola Ana" || mx[2].Label != "Iniciar" || mx[3].Text != "5 ene 2026" {
+		t.Fatalf("expected es-MX falling back to es, got %+v", mx)
+	}
+	en := get("fr")
+	if en[0].Text != "Today" || en[1].Text != "Hi Ana" || en[2].Label != "Start" || en[3].Text != "Jan 5, 2026" {
+		t.Fatalf("expected untranslated locales to keep the template text, got %+v", en)
+	}
+}
+
+func TestOldAppsGetTheUpdateFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","text":"Today"},
+		{"type":"chart","id":"trend","key":"jobs"},
+		{"type":"text","text":"New","minAppVersion":"2.5"},
+		{"type":"text","text":"Tail"}]}}`)
+	writeTemplate(t, dir, "map.json", `{"version":1,"minAppVersion":"3.0","component":{"type":"vstack","children":[{"type":"text","text":"Map"}]}}`)
+	svc, _ := newTestService(t, dir)
+	svc.gate = versionGate{minVersions: map[string]string{"chart": "2.3"}, fallback: &models.SDUIComponent{Type: "text", Text: "Update to {{version}}"}}
+	get := func(screenID, version string) []models.SDUIComponent {
+		t.Helper()
+		res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: screenID, AppVersion: version})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res.Screen.Component.Children
+	}
+
+	if got := get("home", "2.5.0"); len(got) != 4 {
+		t.Fatalf("expected a current build served everything, got %+v", got)
+	}
+	if got := get("home", ""); len(got) != 4 {
+		t.Fatalf("expected a build without a version served everything, got %+v", got)
+	}
+	got := get("home", "2.4")
+	if len(got) != 4 || got[1].Type != "chart" || got[2].Text != "Update to {{version}}" {
+		t.Fatalf("expected the component newer than the build replaced, got %+v", got)
+	}
+	got = get("home", "2.2.9")
+	if len(got) != 3 || got[1].Type != "text" || got[1].ID != "trend" || got[2].Text != "Tail" {
+		t.Fatalf("expected adjacent held-back components to share one fallback under the first's ID, got %+v", got)
+	}
+	if got := get("map", "2.9"); len(got) != 1 || got[0].Text != "Update to {{version}}" {
+		t.Fatalf("expected a screen newer than the build replaced entirely, got %+v", got)
+	}
+
+	svc.gate.fallback = nil
+	if got := get("home", "2.2"); len(got) != 2 {
+		t.Fatalf("expected held-back components removed without a fallback, got %+v", got)
+	}
+}
+
+func TestPreviewRendersWithoutStoring(t *testing.T) {
+	dir := t.TempDir()
+	svc, store := newTestService(t, dir)
+	store.AddTechnician(domain.Technician{ID: "t1", DisplayName: "Stored"})
+
+	var req PreviewRequest
+	if err := json.Unmarshal([]byte(`{"screenId":"draft","screen":{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","text":"Hi {{technician.name}} on {{route.label}}"},
+		{"type":"text","text":"{{metadata.branch}} / {{metadata.deviceModel}}"},
+		{"type":"text","text":"{{todayJobsCompleted}} {{missing}}"}]}},
+		"context":{"technician":{"id":"t1","displayName":"Sam"},"route":{"id":"r2"},"metadata":{"branch":"North"}},
+		"deviceModel":"iPhone15,2"}`), &req); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	res, err := svc.Preview(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	children := res.Screen.Component.Children
+	if got := children[0].Text; got != "Hi Sam on Route r2" {
+		t.Errorf("expected the synthetic context, got %q", got)
+	}
+	if got := children[1].Text; got != "North / iPhone15,2" {
+		t.Errorf("expected metadata merged with device details, got %q", got)
+	}
+	if children[0].ID == "" {
+		t.Errorf("expected component IDs assigned")
+	}
+	if strings.Join(res.Unresolved, ",") != "missing,todayJobsCompleted" || len(res.Problems) != 0 {
+		t.Errorf("unexpected report: unresolved %v, problems %v", res.Unresolved, res.Problems)
+	}
+	if summaries, _ := svc.Templates(); len(summaries) != 0 {
+		t.Errorf("preview must not publish, got %+v", summaries)
+	}
+	if _, ok := svc.template("draft"); ok {
+		t.Errorf("preview must not install the template")
+	}
+
+	_, err = svc.Preview(PreviewRequest{ScreenID: "draft", Screen: json.RawMessage(`{"version":1,"component":{"type":"text","bogus":true}}`)})
+	if !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected ErrInvalidTemplate, got %v", err)
+	}
+
+	// Without a payload the screen's current rendering is previewed.
+	res, err = svc.Preview(PreviewRequest{ScreenID: "home", Context: domain.ScreenContext{Technician: domain.Technician{ID: "t1", DisplayName: "Sam"}}})
+	if err != nil || res.Screen.Component.Type == "" {
+		t.Fatalf("expected the programmatic screen, got %+v, %v", res.Screen, err)
+	}
+}
+
+func TestTranslationCoverageBlocksPublishing(t *testing.T) {
+	translations := t.TempDir()
+	writeTemplate(t, translations, "es.json", `{"home":{"title":"Hoy"}}`)
+	svc, _ := newTestService(t, t.TempDir())
+	svc.translationsDir, svc.defaultLocale, svc.requiredLocales = translations, "en", []string{"es"}
+	svc.Precompile()
+	payload := []byte(`{"version":1,"component":{"type":"vstack","children":[
+		{"type":"text","textKey":"home.title","text":"Today"},
+		{"type":"button","textKey":"job.start","label":"Start","actionId":"startJob"},
+		{"type":"text","textKey":"home.title"},
+		{"type":"list","itemView":{"type":"text","textKey":"row.title"}}]}}`)
+
+	_, err := svc.CreateTemplate("home", payload)
+	if !errors.Is(err, ErrMissingTranslations) || !strings.Contains(err.Error(), "es lacks job.start") {
+		t.Fatalf("expected publishing blocked on the missing string, got %v", err)
+	}
+
+	svc.TranslationsUploaded("es", map[string]string{"job.start": "Iniciar"})
+	svc.TranslationsUploaded("fr", map[string]string{"home.title": "Aujourd'hui"})
+	if _, err := svc.CreateTemplate("home", payload); err != nil {
+		t.Fatalf("expected publishing once translated, got %v", err)
+	}
+	strs, err := svc.Strings("home", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(strs.Strings) != 2 || strs.Strings[0].Key != "home.title" || strs.Strings[0].Source != "Today" || len(strs.Strings[0].ComponentIDs) != 2 || strs.Strings[1].Source != "Start" {
+		t.Fatalf("unexpected strings %+v", strs)
+	}
+	cov, err := svc.Coverage("home", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cov.Version != 1 || len(cov.Locales) != 2 {
+		t.Fatalf("unexpected coverage %+v", cov)
+	}
+	es, fr := cov.Locales[0], cov.Locales[1]
+	if !es.Required || es.Percent != 100 || fr.Required || fr.Percent != 50 || strings.Join(fr.Missing, ",") != "job.start" {
+		t.Errorf("unexpected locale coverage %+v %+v", es, fr)
+	}
+
+	// Uploads survive reloading the catalog files.
+	svc.Precompile()
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", Locale: "es"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := res.Screen.Component.Children[1].Label; got != "Iniciar" {
+		t.Errorf("expected the uploaded translation, got %q", got)
+	}
+}
+
+func TestAssetsResolvedAndCheckedAtPublish(t *testing.T) {
+	svc, _ := newTestService(t, t.TempDir())
+	payload := []byte(`{"version":1,"component":{"type":"vstack","children":[
+		{"type":"image","asset":"termite"},
+		{"type":"list","itemView":{"type":"image","asset":"bait-station"}}]}}`)
+
+	_, err := svc.CreateTemplate("home", payload)
+	if !errors.Is(err, ErrMissingAssets) || !strings.Contains(err.Error(), "bait-station, termite") {
+		t.Fatalf("expected publishing blocked on the missing assets, got %v", err)
+	}
+
+	svc.AssetChanged("termite", "https://cdn.example.com/assets/termite/1-abc.png")
+	svc.AssetChanged("bait-station", "https://cdn.example.com/assets/bait-station/1-def.svg")
+	if _, err := svc.CreateTemplate("home", payload); err != nil {
+		t.Fatalf("expected publishing once uploaded, got %v", err)
+	}
+	svc.AssetChanged("termite", "https://cdn.example.com/assets/termite/2-123.png")
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	children := res.Screen.Component.Children
+	if children[0].ImageURL != "https://cdn.example.com/assets/termite/2-123.png" || children[1].ItemView.ImageURL == "" {
+		t.Errorf("expected current asset URLs, got %+v", children)
+	}
+
+	refs, err := svc.AssetReferences()
+	if err != nil || !refs["termite"] || !refs["bait-station"] || len(refs) != 2 {
+		t.Errorf("expected both assets referenced, got %v, %v", refs, err)
+	}
+}
+
+func TestDeprecatedComponentsMigrated(t *testing.T) {
+	dir, rules := t.TempDir(), t.TempDir()
+	writeTemplate(t, dir, "home.json", `{"version":1,"component":{"type":"vstack","children":[{"type":"label","value":"Hi {{technician.name}}"},{"type":"webView"}]}}`)
+	writeTemplate(t, dir, "jobs.json", `{"version":1,"component":{"type":"vstack","children":[{"type":"maintenanceScheduler"}]}}`)
+	writeTemplate(t, rules, "migrations.json", `[
+		{"type":"label","renameTo":"text","fields":{"value":"text"}},
+		{"type":"webView","note":"removed in 3.0"},
+		{"type":"maintenanceScheduler","retired":true,"note":"link to the maintenance screen"}]`)
+	svc, store := newTestService(t, dir)
+	svc.migrationsPath = filepath.Join(rules, "migrations.json")
+	_ = store.SaveTechnician(domain.Technician{ID: "tech-1", DisplayName: "Ana"})
+
+	report := svc.Precompile()
+	if len(report.Failed) != 0 || strings.Join(report.NeedsManualFix, ",") != "jobs" {
+		t.Fatalf("expected jobs reported for a manual fix, got %+v", report)
+	}
+	res, err := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "tech-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := res.Screen.Component.Children[0]; got.Type != "text" || got.Text != "Hi Ana" {
+		t.Fatalf("expected the label rewritten to text, got %+v", got)
+	}
+	if m := svc.Migrations(); len(m.Rules) != 3 || len(m.Templates) != 2 || len(m.Templates[0].Deprecated) != 3 {
+		t.Fatalf("unexpected migration report %+v", m)
+	}
+
+	// Published templates are checked as migrated, and stored as written.
+	old := `{"version":1,"component":{"type":"label","value":"Old"}}`
+	tpl, err := svc.CreateTemplate("legacy", []byte(old))
+	if err != nil || string(tpl.Screen) != old {
+		t.Fatalf("expected an old template published as written, got %s (%v)", tpl.Screen, err)
+	}
+	if _, err := svc.CreateTemplate("retired", []byte(`{"version":1,"component":{"type":"maintenanceScheduler"}}`)); !errors.Is(err, ErrInvalidTemplate) || !strings.Contains(err.Error(), "link to the maintenance screen") {
+		t.Fatalf("expected a retired component refused, got %v", err)
+	}
+
+	writeTemplate(t, rules, "migrations.json", `[{"type":"label"`)
+	if report := svc.Precompile(); len(report.Failed) != 1 || report.Failed[0].Source != SourceMigrations {
+		t.Fatalf("expected a broken migrations file reported, got %+v", report.Failed)
+	}
+	if res, _ := svc.GetScreen(context.Background(), models.ScreenRequest{ScreenID: "home", UserID: "tech-1"}); res.Screen.Component.Children[0].Type != "text" {
+		t.Fatalf("expected the previous migrations kept")
+	}
+}
+
+func TestBrandReplacesColorTokens(t *testing.T) {
+	screen := models.SDUIScreen{Component: models.SDUIComponent{Type: "vstack", Background: models.BrandPrimary, Children: []models.SDUIComponent{
+		{Type: "button", Foreground: models.BrandAccent, Color: "#FFFFFF"},
+	}}}
+	theme := &models.ScreenTheme{PrimaryColor: "#1B5E20"}
+	branded := brand(screen, theme)
+	if branded.Theme != theme || branded.Component.Background != "#1B5E20" {
+		t.Fatalf("unexpected branded screen %+v", branded)
+	}
+	if got := branded.Component.Children[0].Foreground; got != models.BrandAccent {
+		t.Fatalf("expected a token without a theme color to be kept, got %q", got)
+	}
+	if screen.Component.Background != models.BrandPrimary {
+		t.Fatal("expected the rendered screen to be left unchanged")
+	}
+}
+
+func TestTemplateHandlerAnswersWithStableCodes(t *testing.T) {
+	svc, _ := newTestService(t, t.TempDir())
+	router := chi.NewRouter()
+	router.Route("/v1/admin/screens", NewHandler(svc, nil, nil).Routes)
+	do := func(method, path, body string) (int, string, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var p struct {
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&p)
+		return rec.Code, p.Code, p.Detail
+	}
+
+	screen := `{"screenId":"home","screen":{"version":1,"component":{"type":"text","text":"Hi"}}}`
+	if status, code, detail := do(http.MethodPost, "/v1/admin/screens", screen); status != http.StatusCreated {
+		t.Fatalf("create: %d %s %s", status, code, detail)
+	}
+	cases := []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{http.MethodGet, "/v1/admin/screens/missing", "", http.StatusNotFound, "template_not_found"},
+		{http.MethodGet, "/v1/admin/screens/home/versions/9", "", http.StatusNotFound, "template_not_found"},
+		{http.MethodDelete, "/v1/admin/screens/home/versions/9", "", http.StatusNotFound, "template_not_found"},
+		{http.MethodPost, "/v1/admin/screens", screen, http.StatusConflict, "template_exists"},
+		{http.MethodPut, "/v1/admin/screens/home", `{"screen":{"version":1,"component":{"type":"text","bogus":1}}}`, http.StatusBadRequest, "invalid_template"},
+	}
+	for _, tc := range cases {
+		status, code, detail := do(tc.method, tc.path, tc.body)
+		if status != tc.status || code != tc.code || detail == "" {
+			t.Errorf("%s %s: expected %d %s with a detail, got %d %s %q", tc.method, tc.path, tc.status, tc.code, status, code, detail)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package validate checks SDUI component trees against the contract the iOS
+// renderer decodes (PestGenie/SDUI.swift), so a bad template is rejected on
+// the server instead of failing to decode on every device.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/appversion"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// ErrInvalid wraps every validation failure.
+var ErrInvalid = errors.New("invalid screen")
+
+// DefaultMaxDepth is the deepest component nesting allowed when Rules does not
+// set one. SwiftUI view hierarchies much deeper than this render slowly.
+const DefaultMaxDepth = 32
+
+// Rules tunes validation.
+type Rules struct {
+	// MaxDepth is the deepest nesting allowed; the root component is depth 1.
+	MaxDepth int
+}
+
+// knownTypes are the SDUIComponentType cases of the iOS client. An unknown
+// type fails to decode there and blanks the whole screen.
+var knownTypes = map[string]bool{
+	// Layout containers
+	"vstack": true, "hstack": true, "list": true, "scroll": true, "grid": true, "tabView": true, "section": true,
+	// Basic UI elements
+	"text": true, "button": true, "spacer": true, "image": true, "divider": true, "progressView": true,
+	// Form input components
+	"textField": true, "toggle": true, "slider": true, "picker": true, "datePicker": true, "stepper": true, "segmentedControl": true,
+	// Navigation and presentation
+	"navigationLink": true, "actionSheet": true, "alert": true,
+	// Logic and flow control
+	"conditional": true, "forEach": true,
+	// Advanced components
+	"mapView": true, "webView": true, "chart": true, "gauge": true,
+	// Equipment management components
+	"equipmentInspector": true, "equipmentSelector": true, "qrScanner": true, "digitalChecklist": true, "maintenanceScheduler": true, "calibrationTracker": true,
+	// Weather and environmental components
+	"weatherDashboard": true, "weatherAlert": true, "weatherForecast": true, "weatherMetrics": true, "safetyIndicator": true, "treatmentConditions": true,
+	// Chemical management components
+	"chemicalSelector": true, "dosageCalculator": true, "chemicalInventory": true, "treatmentLogger": true, "epaCompliance": true, "mixingInstructions": true, "applicationTracker": true, "chemicalSearch": true,
+}
+
+// serverTypes are resolved by the server as screens are rendered, so
+// templates may use them although the client cannot decode them.
+var serverTypes = map[string]bool{"conditionalFlag": true}
+
+// inputTypes persist their value under valueKey on the device.
+var inputTypes = map[string]bool{
+	"textField": true, "toggle": true, "slider": true, "picker": true, "datePicker": true, "stepper": true, "segmentedControl": true,
+}
+
+// ComponentTypes lists the component types templates may use, sorted:
+// those the iOS client decodes and those the server resolves.
+func ComponentTypes() []string {
+	types := make([]string, 0, len(knownTypes)+len(serverTypes))
+	for t := range knownTypes {
+		types = append(types, t)
+	}
+	for t := range serverTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Screen validates a screen and its component tree.
+func Screen(screen models.SDUIScreen, rules Rules) error {
+	if problems := Problems(screen, rules); len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Problems lists everything wrong with a screen, sorted, or nil when it is
+// valid.
+func Problems(screen models.SDUIScreen, rules Rules) []string {
+	var problems []string
+	if screen.Version <= 0 {
+		problems = append(problems, "version must be positive")
+	}
+	if _, err := appversion.Parse(screen.MinAppVersion); screen.MinAppVersion != "" && err != nil {
+		problems = append(problems, fmt.Sprintf("screen minAppVersion %q is not a dotted version", screen.MinAppVersion))
+	}
+	problems = append(problems, check(screen.Component, rules)...)
+	sort.Strings(problems)
+	return problems
+}
+
+// check walks the tree collecting problems. Component paths are child
+// indexes from the root ("0.2.1"), with ".item" for a list's itemView.
+func check(root models.SDUIComponent, rules Rules) []string {
+	maxDepth := rules.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	var problems []string
+	ids := make(map[string]bool)
+	valueKeys := make(map[string]bool)
+	tooDeep := false
+
+	var walk func(c models.SDUIComponent, path string, depth int)
+	walk = func(c models.SDUIComponent, path string, depth int) {
+		if depth > maxDepth {
+			if !tooDeep {
+				problems = append(problems, fmt.Sprintf("component at %s is nested deeper than %d", path, maxDepth))
+				tooDeep = true
+			}
+			return
+		}
+		switch {
+		case strings.TrimSpace(c.Type) == "":
+			problems = append(problems, fmt.Sprintf("component at %s has no type", path))
+		case !knownTypes[c.Type] && !serverTypes[c.Type]:
+			problems = append(problems, fmt.Sprintf("component at %s has unknown type %q", path, c.Type))
+		}
+		if c.ID != "" && c.ID != models.RandomComponentID {
+			if ids[c.ID] {
+				problems = append(problems, fmt.Sprintf("component id %q is used more than once", c.ID))
+			}
+			ids[c.ID] = true
+		}
+		problems = append(problems, required(c, path)...)
+		if _, err := appversion.Parse(c.MinAppVersion); c.MinAppVersion != "" && err != nil {
+			problems = append(problems, fmt.Sprintf("minAppVersion %q of component at %s is not a dotted version", c.MinAppVersion, path))
+		}
+		// Inside an item view the client scopes valueKey to each job, so only
+		// keys outside item views have to be unique.
+		if inputTypes[c.Type] && c.ValueKey != "" && !strings.Contains(path, ".item") {
+			if valueKeys[c.ValueKey] {
+				problems = append(problems, fmt.Sprintf("valueKey %q is used more than once", c.ValueKey))
+			}
+			valueKeys[c.ValueKey] = true
+		}
+		for i, opt := range c.Options {
+			if opt.ID == "" {
+				problems = append(problems, fmt.Sprintf("option %d of component at %s has no id", i, path))
+			}
+		}
+		for i, child := range c.Children {
+			walk(child, fmt.Sprintf("%s.%d", path, i), depth+1)
+		}
+		if c.ItemView != nil {
+			walk(*c.ItemView, path+".item", depth+1)
+		}
+	}
+	walk(root, "0", 1)
+	return problems
+}
+
+// required reports fields a component type cannot render without.
+func required(c models.SDUIComponent, path string) []string {
+	var problems []string
+	missing := func(field string) {
+		problems = append(problems, fmt.Sprintf("%s at %s requires %s", c.Type, path, field))
+	}
+	switch c.Type {
+	case "list", "forEach":
+		if c.ItemView == nil && len(c.Children) == 0 {
+			missing("itemView or children")
+		}
+	case "conditional":
+		if c.ConditionKey == "" {
+			missing("conditionKey")
+		}
+	case "conditionalFlag":
+		if c.FlagKey == "" {
+			missing("flagKey")
+		}
+	case "button":
+		if c.ActionID == "" {
+			missing("actionId")
+		}
+	case "navigationLink":
+		if c.Destination == "" {
+			missing("destination")
+		}
+	case "picker", "segmentedControl":
+		if len(c.Options) == 0 {
+			missing("options")
+		}
+	}
+	if inputTypes[c.Type] && c.ValueKey == "" {
+		missing("valueKey")
+	}
+	return problems
+}
@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+func TestScreen(t *testing.T) {
+	valid := models.SDUIScreen{Version: 1, Component: models.SDUIComponent{
+		Type: "vstack",
+		Children: []models.SDUIComponent{
+			{ID: "title", Type: "text", Text: "Today"},
+			{Type: "list", ItemView: &models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+				{Type: "textField", ValueKey: "notes"},
+				{Type: "button", Label: "Start", ActionID: "startJob"},
+			}}},
+			{Type: "conditional", ConditionKey: "pinnedNotes", Children: []models.SDUIComponent{{Type: "text", Key: "pinnedNotes"}}},
+			{Type: "picker", ValueKey: "notes", Options: []models.SDUIPickerOption{{ID: "a", Text: "A"}}},
+		},
+	}}
+	if err := Screen(valid, Rules{}); err != nil {
+		t.Fatalf("expected a valid screen, got %v", err)
+	}
+
+	invalid := models.SDUIScreen{Component: models.SDUIComponent{
+		Type: "vstack",
+		Children: []models.SDUIComponent{
+			{ID: "a", Type: "marquee"},
+			{ID: "a", Type: "list"},
+			{Type: "conditional"},
+			{Type: "toggle", ValueKey: "k"},
+			{Type: "slider", ValueKey: "k"},
+			{Type: "picker", ValueKey: "p", Options: []models.SDUIPickerOption{{Text: "no id"}}},
+			{Type: "button", Label: "Go"},
+			{Type: "textField"},
+			{Type: "conditionalFlag"},
+		},
+	}}
+	err := Screen(invalid, Rules{})
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected an invalid screen, got %v", err)
+	}
+	for _, want := range []string{
+		"version must be positive",
+		`unknown type "marquee"`,
+		`component id "a" is used more than once`,
+		"list at 0.1 requires itemView or children",
+		"conditional at 0.2 requires conditionKey",
+		`valueKey "k" is used more than once`,
+		"option 0 of component at 0.5 has no id",
+		"button at 0.6 requires actionId",
+		"textField at 0.7 requires valueKey",
+		"conditionalFlag at 0.8 requires flagKey",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestScreenMaxDepth(t *testing.T) {
+	c := models.SDUIComponent{Type: "text"}
+	for i := 0; i < 4; i++ {
+		c = models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{c}}
+	}
+	screen := models.SDUIScreen{Version: 1, Component: c}
+	if err := Screen(screen, Rules{MaxDepth: 5}); err != nil {
+		t.Fatalf("expected depth 5 allowed, got %v", err)
+	}
+	problems := Problems(screen, Rules{MaxDepth: 4})
+	if len(problems) != 1 || !strings.Contains(problems[0], "deeper than 4") {
+		t.Fatalf("expected one depth problem, got %v", problems)
+	}
+}
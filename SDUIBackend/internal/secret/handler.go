@@ -0,0 +1,40 @@
+package secret
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes cache invalidation so operators can roll a rotated secret
+// out without a restart.
+type Handler struct {
+	cache *CachedProvider
+}
+
+// NewHandler creates a handler for cache.
+func NewHandler(cache *CachedProvider) *Handler {
+	return &Handler{cache: cache}
+}
+
+// Routes mounts the secret endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Post("/invalidate", h.Invalidate)
+}
+
+// Invalidate expires the ?name= secret, or every cached secret without one,
+// so the next use fetches the current value.
+func (h *Handler) Invalidate(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		h.cache.InvalidateAll()
+	} else {
+		h.cache.Invalidate(name)
+	}
+	middleware.LoggerFrom(r.Context()).Info("secret cache invalidated", slog.String("secret", name))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package secret
 
 import (
+	"context"
 	"errors"
 	"os"
 	"sync"
@@ -24,12 +25,18 @@ func (e EnvProvider) Get(name string) (string, error) {
 }
 
 // CachedProvider wraps another provider and caches values for the specified TTL.
+// Rotated secrets are picked up early by invalidating them, or by refreshing
+// entries in the background before they expire; OnChange hooks hear about
+// values that changed either way.
 type CachedProvider struct {
-	base Provider
-	ttl  time.Duration
+	base         Provider
+	ttl          time.Duration
+	refreshAhead time.Duration
+	now          func() time.Time
 
 	mu    sync.RWMutex
 	cache map[string]cachedSecret
+	hooks []func(name, value string)
 }
 
 type cachedSecret struct {
@@ -37,9 +44,23 @@ type cachedSecret struct {
 	expiresAt time.Time
 }
 
+// CacheOption configures a CachedProvider.
+type CacheOption func(*CachedProvider)
+
+// WithRefreshAhead has Run re-fetch cached secrets when they are within d of
+// expiring, so callers never wait on the base provider and rotations are
+// seen within a TTL.
+func WithRefreshAhead(d time.Duration) CacheOption {
+	return func(c *CachedProvider) { c.refreshAhead = d }
+}
+
 // NewCachedProvider creates a caching decorator.
-func NewCachedProvider(base Provider, ttl time.Duration) *CachedProvider {
-	return &CachedProvider{base: base, ttl: ttl, cache: make(map[string]cachedSecret)}
+func NewCachedProvider(base Provider, ttl time.Duration, opts ...CacheOption) *CachedProvider {
+	c := &CachedProvider{base: base, ttl: ttl, now: time.Now, cache: make(map[string]cachedSecret)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Get returns a cached secret value or fetches it from the base provider.
@@ -47,26 +68,102 @@ func (c *CachedProvider) Get(name string) (string, error) {
 	if c == nil {
 		return "", errors.New("nil provider")
 	}
-	now := time.Now()
 
 	c.mu.RLock()
 	entry, ok := c.cache[name]
 	c.mu.RUnlock()
-	if ok && entry.expiresAt.After(now) {
+	if ok && entry.expiresAt.After(c.now()) {
 		return entry.value, nil
 	}
+	return c.fetch(name)
+}
 
+// fetch reads name from the base provider into the cache and runs the
+// OnChange hooks when it differs from the value cached before.
+func (c *CachedProvider) fetch(name string) (string, error) {
 	value, err := c.base.Get(name)
 	if err != nil {
 		return "", err
 	}
 
 	c.mu.Lock()
-	c.cache[name] = cachedSecret{value: value, expiresAt: now.Add(c.ttl)}
+	previous, known := c.cache[name]
+	c.cache[name] = cachedSecret{value: value, expiresAt: c.now().Add(c.ttl)}
+	hooks := c.hooks
 	c.mu.Unlock()
+	if known && previous.value != value {
+		for _, hook := range hooks {
+			hook(name, value)
+		}
+	}
 	return value, nil
 }
 
+// Invalidate expires name so the next Get fetches it again. The old value is
+// kept to tell whether the fetched one changed.
+func (c *CachedProvider) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.cache[name]; ok {
+		entry.expiresAt = time.Time{}
+		c.cache[name] = entry
+	}
+}
+
+// InvalidateAll expires every cached secret.
+func (c *CachedProvider) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, entry := range c.cache {
+		entry.expiresAt = time.Time{}
+		c.cache[name] = entry
+	}
+}
+
+// OnChange registers fn to be called with a secret's new value whenever a
+// fetch finds it changed, so dependents can rebuild clients or connections
+// that hold the old one. Hooks run on the fetching goroutine.
+func (c *CachedProvider) OnChange(fn func(name, value string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, fn)
+}
+
+// Run refreshes secrets nearing expiry until ctx is cancelled. It returns at
+// once unless the provider was created WithRefreshAhead. Failed refreshes
+// leave the cached value to expire normally.
+func (c *CachedProvider) Run(ctx context.Context) {
+	if c == nil || c.refreshAhead <= 0 {
+		return
+	}
+	ticker := time.NewTicker(max(c.refreshAhead/2, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+// refresh re-fetches the secrets expiring within refreshAhead.
+func (c *CachedProvider) refresh() {
+	soon := c.now().Add(c.refreshAhead)
+	var due []string
+	c.mu.RLock()
+	for name, entry := range c.cache {
+		if entry.expiresAt.Before(soon) {
+			due = append(due, name)
+		}
+	}
+	c.mu.RUnlock()
+	for _, name := range due {
+		_, _ = c.fetch(name)
+	}
+}
+
 // GCPSecretManager is a placeholder stub that can be expanded with the official
 // cloud.google.com/go/secretmanager client once network access and dependencies
 // are available. For now it returns an informative error so callers can fall
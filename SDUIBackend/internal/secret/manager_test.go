@@ -57,3 +57,45 @@ func TestCachedProvider(t *testing.T) {
 		t.Fatalf("expected refreshed value 2, got %s", val)
 	}
 }
+
+type mapProvider map[string]string
+
+func (m mapProvider) Get(name string) (string, error) {
+	return m[name], nil
+}
+
+func TestCachedProviderRotation(t *testing.T) {
+	base := mapProvider{"APNS_KEY": "v1", "DB_PASSWORD": "p1"}
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cached := NewCachedProvider(base, time.Hour, WithRefreshAhead(5*time.Minute))
+	cached.now = func() time.Time { return now }
+	var changed []string
+	cached.OnChange(func(name, value string) { changed = append(changed, name+"="+value) })
+
+	cached.Get("APNS_KEY")
+	cached.Get("DB_PASSWORD")
+	base["APNS_KEY"], base["DB_PASSWORD"] = "v2", "p2"
+
+	cached.Invalidate("APNS_KEY")
+	if val, _ := cached.Get("APNS_KEY"); val != "v2" {
+		t.Fatalf("expected the invalidated secret re-fetched, got %s", val)
+	}
+	if val, _ := cached.Get("DB_PASSWORD"); val != "p1" {
+		t.Fatalf("expected other secrets still cached, got %s", val)
+	}
+
+	now = now.Add(56 * time.Minute)
+	cached.refresh()
+	if val, _ := cached.Get("DB_PASSWORD"); val != "p2" {
+		t.Fatalf("expected the secret refreshed before expiry, got %s", val)
+	}
+	if len(changed) != 2 || changed[0] != "APNS_KEY=v2" || changed[1] != "DB_PASSWORD=p2" {
+		t.Fatalf("unexpected change notifications %v", changed)
+	}
+
+	base["APNS_KEY"] = "v3"
+	cached.InvalidateAll()
+	if val, _ := cached.Get("APNS_KEY"); val != "v3" {
+		t.Fatalf("expected every secret invalidated, got %s", val)
+	}
+}
@@ -0,0 +1,81 @@
+package serviceplan
+
+import (
+	"fmt"
+
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// checklistVersion is the SDUI schema version checklist screens target.
+const checklistVersion = 5
+
+// Checklist returns the SDUI form for a planned job: the visit's expected
+// products followed by its checklist items. Item values are bound to
+// "checklist.<itemId>" and submitted with the submitChecklist action.
+func (s *Service) Checklist(jobID string) (models.SDUIScreen, error) {
+	program, err := s.store.GetProgramByJob(jobID)
+	if err != nil {
+		return models.SDUIScreen{}, err
+	}
+	for _, job := range program.Jobs {
+		if job.JobID == jobID {
+			return checklistScreen(program, job), nil
+		}
+	}
+	return models.SDUIScreen{}, ErrNotFound
+}
+
+func checklistScreen(program Program, job PlannedJob) models.SDUIScreen {
+	children := []models.SDUIComponent{
+		{Type: "text", Text: job.Visit, Font: "title2"},
+		{Type: "text", Text: fmt.Sprintf("%s • %s", program.TemplateName, job.ScheduledDate.Format("Jan 2, 2006")), Font: "subheadline", Color: "secondary"},
+	}
+
+	if len(job.Chemicals) > 0 {
+		products := models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+			{Type: "text", Text: "Planned products", Font: "headline"},
+		}}
+		for _, c := range job.Chemicals {
+			line := chemicalLabel(c)
+			if c.TargetPests != "" {
+				line += " — " + c.TargetPests
+			}
+			if c.MaxQuantity > 0 {
+				line += fmt.Sprintf(" (%g-%g)", c.MinQuantity, c.MaxQuantity)
+			}
+			products.Children = append(products.Children, models.SDUIComponent{Type: "text", Text: line, Font: "body"})
+		}
+		children = append(children, models.SDUIComponent{Type: "divider"}, products)
+	}
+
+	if len(job.Checklist) > 0 {
+		form := models.SDUIComponent{Type: "vstack", Children: []models.SDUIComponent{
+			{Type: "text", Text: "Checklist", Font: "headline"},
+		}}
+		for _, item := range job.Checklist {
+			label := item.Label
+			if item.Required {
+				label += " *"
+			}
+			field := models.SDUIComponent{ID: "checklist-" + item.ID, ValueKey: "checklist." + item.ID}
+			switch item.Kind {
+			case ItemText:
+				field.Type, field.Placeholder = "textField", label
+			default:
+				field.Type, field.Label = "toggle", label
+			}
+			form.Children = append(form.Children, field)
+		}
+		children = append(children, models.SDUIComponent{Type: "divider"}, form)
+	}
+
+	children = append(children, models.SDUIComponent{Type: "button", Label: "Submit checklist", ActionID: "submitChecklist"})
+	return models.SDUIScreen{
+		Version: checklistVersion,
+		Component: models.SDUIComponent{
+			ID:       "service-plan-checklist-" + job.JobID,
+			Type:     "scroll",
+			Children: []models.SDUIComponent{{Type: "vstack", Children: children}},
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package serviceplan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// Drift kinds.
+const (
+	DriftMissed             = "missed"              // past due and never completed
+	DriftSkipped            = "skipped"             // the technician skipped the visit
+	DriftOffSchedule        = "off_schedule"        // done outside the schedule tolerance
+	DriftMissingChemical    = "missing_chemical"    // an expected product was not applied
+	DriftUnexpectedChemical = "unexpected_chemical" // a product outside the plan was applied
+	DriftQuantity           = "quantity"            // applied quantity outside the plan's bounds
+)
+
+// Drift compares a program with what was reported from the field.
+type Drift struct {
+	ProgramID string       `json:"programId"`
+	CheckedAt time.Time    `json:"checkedAt"`
+	Completed int          `json:"completed"`
+	Upcoming  int          `json:"upcoming"`
+	Issues    []DriftIssue `json:"issues"`
+}
+
+// DriftIssue is a single divergence from the plan.
+type DriftIssue struct {
+	JobID         string    `json:"jobId"`
+	Visit         string    `json:"visit"`
+	ScheduledDate time.Time `json:"scheduledDate"`
+	Kind          string    `json:"kind"`
+	Detail        string    `json:"detail"`
+}
+
+// Drift reports where field reality has diverged from a program.
+func (s *Service) Drift(programID string) (Drift, error) {
+	program, err := s.store.GetProgram(programID)
+	if err != nil {
+		return Drift{}, err
+	}
+	// Program jobs are published after CreatedAt is taken, so everything
+	// relevant was stored after it; the margin absorbs clock granularity.
+	since := program.CreatedAt.Add(-time.Second)
+	jobs, err := s.repos.Sync.ListJobUpdatesSince(since)
+	if err != nil {
+		return Drift{}, err
+	}
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(since)
+	if err != nil {
+		return Drift{}, err
+	}
+	return s.drift(program, jobs, treatments, s.now().UTC()), nil
+}
+
+func (s *Service) drift(program Program, jobs []models.JobUpload, treatments []models.ChemicalTreatmentUpload, now time.Time) Drift {
+	latest := make(map[string]models.JobUpload, len(jobs))
+	for _, job := range jobs {
+		latest[job.ID] = job
+	}
+	applied := make(map[string]map[string]float64)
+	for _, t := range treatments {
+		if applied[t.JobID] == nil {
+			applied[t.JobID] = make(map[string]float64)
+		}
+		applied[t.JobID][t.ChemicalID] += t.QuantityUsed
+	}
+
+	out := Drift{ProgramID: program.ID, CheckedAt: now, Issues: []DriftIssue{}}
+	for _, planned := range program.Jobs {
+		issue := func(kind, detail string) {
+			out.Issues = append(out.Issues, DriftIssue{JobID: planned.JobID, Visit: planned.Visit, ScheduledDate: planned.ScheduledDate, Kind: kind, Detail: detail})
+		}
+		job, reported := latest[planned.JobID]
+		switch status := strings.ToLower(job.Status); {
+		case reported && status == "skipped":
+			issue(DriftSkipped, "visit was skipped")
+			continue
+		case reported && status == "completed":
+			out.Completed++
+		case now.After(planned.ScheduledDate.Add(s.cfg.MissedGrace)):
+			issue(DriftMissed, fmt.Sprintf("not completed within %s of the scheduled date", s.cfg.MissedGrace))
+			continue
+		default:
+			out.Upcoming++
+			continue
+		}
+
+		if !job.ScheduledDate.IsZero() {
+			if diff := job.ScheduledDate.Sub(planned.ScheduledDate).Abs(); diff > s.cfg.ScheduleTolerance {
+				issue(DriftOffSchedule, fmt.Sprintf("done on %s, planned for %s", job.ScheduledDate.Format("2006-01-02"), planned.ScheduledDate.Format("2006-01-02")))
+			}
+		}
+		used := applied[planned.JobID]
+		expected := make(map[string]bool, len(planned.Chemicals))
+		for _, c := range planned.Chemicals {
+			expected[c.ChemicalID] = true
+			qty, ok := used[c.ChemicalID]
+			switch {
+			case !ok:
+				issue(DriftMissingChemical, fmt.Sprintf("%s was not applied", chemicalLabel(c)))
+			case qty < c.MinQuantity || (c.MaxQuantity > 0 && qty > c.MaxQuantity):
+				issue(DriftQuantity, fmt.Sprintf("%s applied %g, planned %g-%g", chemicalLabel(c), qty, c.MinQuantity, c.MaxQuantity))
+			}
+		}
+		var unexpected []string
+		for id := range used {
+			if !expected[id] {
+				unexpected = append(unexpected, id)
+			}
+		}
+		sort.Strings(unexpected)
+		for _, id := range unexpected {
+			issue(DriftUnexpectedChemical, fmt.Sprintf("chemical %s is not in the plan", id))
+		}
+	}
+	return out
+}
+
+func chemicalLabel(c ExpectedChemical) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return "chemical " + c.ChemicalID
+}
@@ -0,0 +1,148 @@
+package serviceplan
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes plan template and program management and the device
+// checklist endpoint.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a service plan handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/templates", h.ListTemplates)
+	r.Post("/templates", h.CreateTemplate)
+	r.Get("/templates/{templateId}", h.GetTemplate)
+	r.Put("/templates/{templateId}", h.UpdateTemplate)
+	r.Get("/programs", h.ListPrograms)
+	r.Post("/programs", h.AttachProgram)
+	r.Get("/programs/{programId}", h.GetProgram)
+	r.Get("/programs/{programId}/drift", h.GetDrift)
+}
+
+// ListTemplates returns all plan templates.
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.Templates()
+	if err != nil {
+		h.fail(w, r, "failed to list templates", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"templates": templates})
+}
+
+// CreateTemplate adds a plan template.
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	h.saveTemplate(w, r, "", http.StatusCreated)
+}
+
+// UpdateTemplate replaces a plan template.
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	h.saveTemplate(w, r, chi.URLParam(r, "templateId"), http.StatusOK)
+}
+
+func (h *Handler) saveTemplate(w http.ResponseWriter, r *http.Request, id string, status int) {
+	var payload Template
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	tpl, err := h.service.SaveTemplate(id, payload)
+	if err != nil {
+		h.fail(w, r, "failed to save template", err)
+		return
+	}
+	respond.JSON(w, status, tpl)
+}
+
+// GetTemplate returns a plan template.
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, err := h.service.Template(chi.URLParam(r, "templateId"))
+	if err != nil {
+		h.fail(w, r, "failed to load template", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, tpl)
+}
+
+// ListPrograms returns programs, filtered by ?customerId= when given.
+func (h *Handler) ListPrograms(w http.ResponseWriter, r *http.Request) {
+	programs, err := h.service.Programs(r.URL.Query().Get("customerId"))
+	if err != nil {
+		h.fail(w, r, "failed to list programs", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"programs": programs})
+}
+
+// AttachProgram attaches a template to a customer.
+func (h *Handler) AttachProgram(w http.ResponseWriter, r *http.Request) {
+	var payload AttachRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	program, err := h.service.Attach(payload)
+	if err != nil {
+		h.fail(w, r, "failed to attach service plan", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, program)
+}
+
+// GetProgram returns a program with its planned jobs.
+func (h *Handler) GetProgram(w http.ResponseWriter, r *http.Request) {
+	program, err := h.service.Program(chi.URLParam(r, "programId"))
+	if err != nil {
+		h.fail(w, r, "failed to load program", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, program)
+}
+
+// GetDrift reports where a program has diverged from the field.
+func (h *Handler) GetDrift(w http.ResponseWriter, r *http.Request) {
+	drift, err := h.service.Drift(chi.URLParam(r, "programId"))
+	if err != nil {
+		h.fail(w, r, "failed to check drift", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, drift)
+}
+
+// GetChecklist returns the SDUI checklist form for a planned job.
+func (h *Handler) GetChecklist(w http.ResponseWriter, r *http.Request) {
+	screen, err := h.service.Checklist(chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.fail(w, r, "failed to load checklist", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, screen)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidPlan):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,280 @@
+// Package serviceplan manages seasonal service plan templates and the
+// treatment programs they expand into when attached to a customer.
+package serviceplan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checklist item kinds.
+const (
+	ItemCheck = "check"
+	ItemText  = "text"
+)
+
+var (
+	// ErrNotFound is returned when a template or program does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidPlan wraps template and program validation failures.
+	ErrInvalidPlan = errors.New("invalid service plan")
+)
+
+// Template is a reusable service plan, e.g. a quarterly perimeter program.
+type Template struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Months      int       `json:"months"` // program length once attached; defaults to 12
+	Visits      []Visit   `json:"visits"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Visit is a recurring visit within a plan. It is scheduled on Day of each
+// calendar month listed in Months; days past the end of a month fall on its
+// last day.
+type Visit struct {
+	Name      string             `json:"name"`
+	Months    []int              `json:"months"`
+	Day       int                `json:"day,omitempty"`
+	Chemicals []ExpectedChemical `json:"chemicals,omitempty"`
+	Checklist []ChecklistItem    `json:"checklist,omitempty"`
+}
+
+// ExpectedChemical is a product a visit is expected to apply. Zero quantity
+// bounds are not checked.
+type ExpectedChemical struct {
+	ChemicalID  string  `json:"chemicalId"`
+	Name        string  `json:"name,omitempty"`
+	TargetPests string  `json:"targetPests,omitempty"`
+	MinQuantity float64 `json:"minQuantity,omitempty"`
+	MaxQuantity float64 `json:"maxQuantity,omitempty"`
+}
+
+// ChecklistItem is one line of a visit's checklist form.
+type ChecklistItem struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Kind     string `json:"kind"` // check, text
+	Required bool   `json:"required,omitempty"`
+}
+
+// Validate checks a template.
+func (t Template) Validate() error {
+	var problems []string
+	if strings.TrimSpace(t.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if t.Months < 0 || t.Months > 36 {
+		problems = append(problems, "months must be between 1 and 36")
+	}
+	if len(t.Visits) == 0 {
+		problems = append(problems, "at least one visit is required")
+	}
+	for i, v := range t.Visits {
+		prefix := fmt.Sprintf("visits[%d]", i)
+		if strings.TrimSpace(v.Name) == "" {
+			problems = append(problems, prefix+": name is required")
+		}
+		if len(v.Months) == 0 {
+			problems = append(problems, prefix+": at least one month is required")
+		}
+		for _, m := range v.Months {
+			if m < 1 || m > 12 {
+				problems = append(problems, fmt.Sprintf("%s: invalid month %d", prefix, m))
+			}
+		}
+		if v.Day < 0 || v.Day > 31 {
+			problems = append(problems, prefix+": day must be between 1 and 31")
+		}
+		for _, c := range v.Chemicals {
+			if c.ChemicalID == "" {
+				problems = append(problems, prefix+": chemicalId is required")
+			}
+			if c.MinQuantity < 0 || (c.MaxQuantity > 0 && c.MaxQuantity < c.MinQuantity) {
+				problems = append(problems, fmt.Sprintf("%s: chemical %q quantity must satisfy 0 <= min <= max", prefix, c.ChemicalID))
+			}
+		}
+		ids := make(map[string]bool, len(v.Checklist))
+		for _, item := range v.Checklist {
+			switch {
+			case item.ID == "" || item.Label == "":
+				problems = append(problems, prefix+": checklist items need an id and label")
+			case ids[item.ID]:
+				problems = append(problems, fmt.Sprintf("%s: duplicate checklist item %q", prefix, item.ID))
+			case item.Kind != ItemCheck && item.Kind != ItemText:
+				problems = append(problems, fmt.Sprintf("%s: checklist item %q has invalid kind %q", prefix, item.ID, item.Kind))
+			}
+			ids[item.ID] = true
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidPlan, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// AttachRequest attaches a template to a customer.
+type AttachRequest struct {
+	TemplateID   string    `json:"templateId"`
+	CustomerID   string    `json:"customerId"`
+	CustomerName string    `json:"customerName"`
+	Address      string    `json:"address,omitempty"`
+	TechnicianID string    `json:"technicianId"`
+	StartDate    time.Time `json:"startDate"`
+}
+
+// Validate checks an attach request.
+func (r AttachRequest) Validate() error {
+	var problems []string
+	if r.TemplateID == "" {
+		problems = append(problems, "templateId is required")
+	}
+	if r.CustomerID == "" {
+		problems = append(problems, "customerId is required")
+	}
+	if r.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if r.StartDate.IsZero() {
+		problems = append(problems, "startDate is required")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidPlan, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Program is a template attached to a customer, expanded into jobs.
+type Program struct {
+	ID           string       `json:"id"`
+	TemplateID   string       `json:"templateId"`
+	TemplateName string       `json:"templateName"`
+	CustomerID   string       `json:"customerId"`
+	CustomerName string       `json:"customerName"`
+	Address      string       `json:"address,omitempty"`
+	TechnicianID string       `json:"technicianId"`
+	StartDate    time.Time    `json:"startDate"`
+	EndDate      time.Time    `json:"endDate"`
+	Jobs         []PlannedJob `json:"jobs"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
+
+// PlannedJob is one scheduled visit of a program.
+type PlannedJob struct {
+	JobID         string    `json:"jobId"`
+	Visit         string    `json:"visit"`
+	ScheduledDate time.Time `json:"scheduledDate"`
+	// RescheduledFrom is the plan date when it fell on a closed day.
+	RescheduledFrom *time.Time         `json:"rescheduledFrom,omitempty"`
+	Chemicals       []ExpectedChemical `json:"chemicals,omitempty"`
+	Checklist       []ChecklistItem    `json:"checklist,omitempty"`
+}
+
+// Store persists templates and programs.
+type Store interface {
+	SaveTemplate(t Template) error
+	GetTemplate(id string) (Template, error)
+	ListTemplates() ([]Template, error)
+	SaveProgram(p Program) error
+	GetProgram(id string) (Program, error)
+	GetProgramByJob(jobID string) (Program, error)
+	ListPrograms(customerID string) ([]Program, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+	programs  map[string]Program
+	byJob     map[string]string // job ID -> program ID
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		templates: make(map[string]Template),
+		programs:  make(map[string]Program),
+		byJob:     make(map[string]string),
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveTemplate(t Template) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates[t.ID] = t
+	return nil
+}
+
+func (m *MemoryStore) GetTemplate(id string) (Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.templates[id]
+	if !ok {
+		return Template{}, ErrNotFound
+	}
+	return t, nil
+}
+
+func (m *MemoryStore) ListTemplates() ([]Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Template, 0, len(m.templates))
+	for _, t := range m.templates {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (m *MemoryStore) SaveProgram(p Program) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.programs[p.ID] = p
+	for _, job := range p.Jobs {
+		m.byJob[job.JobID] = p.ID
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetProgram(id string) (Program, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.programs[id]
+	if !ok {
+		return Program{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (m *MemoryStore) GetProgramByJob(jobID string) (Program, error) {
+	m.mu.RLock()
+	id, ok := m.byJob[jobID]
+	m.mu.RUnlock()
+	if !ok {
+		return Program{}, ErrNotFound
+	}
+	return m.GetProgram(id)
+}
+
+func (m *MemoryStore) ListPrograms(customerID string) ([]Program, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Program
+	for _, p := range m.programs {
+		if customerID == "" || p.CustomerID == customerID {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
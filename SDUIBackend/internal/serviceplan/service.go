@@ -0,0 +1,210 @@
+package serviceplan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// defaultMonths is the program length for templates that do not set one.
+const defaultMonths = 12
+
+// jobStatusPending matches the device's JobStatus for jobs not yet started.
+const jobStatusPending = "pending"
+
+// Service manages plan templates, attaches them to customers, and checks
+// programs for drift.
+type Service struct {
+	cfg       config.ServicePlanConfig
+	store     Store
+	repos     repository.Repository
+	calendars *calendar.Service
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService wires a service plan service. Visits that fall on a day the
+// technician's branch is closed move to its next business day; nil calendars
+// disables this.
+func NewService(cfg config.ServicePlanConfig, store Store, repos repository.Repository, calendars *calendar.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, store: store, repos: repos, calendars: calendars, logger: logger, now: time.Now}
+}
+
+// SaveTemplate creates a template, or replaces it when id is set. Programs
+// already attached keep the visits they were expanded with.
+func (s *Service) SaveTemplate(id string, t Template) (Template, error) {
+	if err := t.Validate(); err != nil {
+		return Template{}, err
+	}
+	now := s.now().UTC()
+	t.ID, t.CreatedAt, t.UpdatedAt = id, now, now
+	if id == "" {
+		t.ID = uuid.NewString()
+	} else {
+		existing, err := s.store.GetTemplate(id)
+		if err != nil {
+			return Template{}, err
+		}
+		t.CreatedAt = existing.CreatedAt
+	}
+	if t.Months == 0 {
+		t.Months = defaultMonths
+	}
+	if err := s.store.SaveTemplate(t); err != nil {
+		return Template{}, err
+	}
+	return t, nil
+}
+
+// Template returns a template.
+func (s *Service) Template(id string) (Template, error) {
+	return s.store.GetTemplate(id)
+}
+
+// Templates lists templates by name.
+func (s *Service) Templates() ([]Template, error) {
+	return s.store.ListTemplates()
+}
+
+// Attach expands a template into a program for a customer and publishes its
+// jobs to the sync repository so they reach the technician's device on the
+// next sync.
+func (s *Service) Attach(req AttachRequest) (Program, error) {
+	if err := req.Validate(); err != nil {
+		return Program{}, err
+	}
+	tpl, err := s.store.GetTemplate(req.TemplateID)
+	if err != nil {
+		return Program{}, err
+	}
+
+	program := expand(tpl, req, s.calendars.ForTechnician(req.TechnicianID))
+	program.ID = uuid.NewString()
+	program.CreatedAt = s.now().UTC()
+	if len(program.Jobs) == 0 {
+		return Program{}, fmt.Errorf("%w: no visits fall between %s and %s", ErrInvalidPlan,
+			program.StartDate.Format("2006-01-02"), program.EndDate.Format("2006-01-02"))
+	}
+
+	for _, job := range program.Jobs {
+		err := s.repos.Sync.SaveJobUpload(models.JobUpload{
+			ID:            job.JobID,
+			TechnicianID:  req.TechnicianID,
+			CustomerName:  req.CustomerName,
+			Address:       req.Address,
+			ScheduledDate: job.ScheduledDate,
+			Status:        jobStatusPending,
+		})
+		if err != nil {
+			return Program{}, fmt.Errorf("publish job %s: %w", job.JobID, err)
+		}
+	}
+	if err := s.store.SaveProgram(program); err != nil {
+		return Program{}, err
+	}
+	s.logger.Info("service plan attached",
+		slog.String("program", program.ID),
+		slog.String("template", tpl.ID),
+		slog.String("customer", req.CustomerID),
+		slog.Int("jobs", len(program.Jobs)),
+	)
+	return program, nil
+}
+
+// Program returns a program.
+func (s *Service) Program(id string) (Program, error) {
+	return s.store.GetProgram(id)
+}
+
+// Programs lists programs, optionally for a single customer.
+func (s *Service) Programs(customerID string) ([]Program, error) {
+	return s.store.ListPrograms(customerID)
+}
+
+// expand schedules every visit of tpl that falls within the program window,
+// which starts on req.StartDate and runs for tpl.Months. With hours, visits
+// on closed days move to the next business day.
+func expand(tpl Template, req AttachRequest, hours *calendar.BusinessHours) Program {
+	months := tpl.Months
+	if months == 0 {
+		months = defaultMonths
+	}
+	start := req.StartDate.UTC()
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, months, 0)
+
+	program := Program{
+		TemplateID:   tpl.ID,
+		TemplateName: tpl.Name,
+		CustomerID:   req.CustomerID,
+		CustomerName: req.CustomerName,
+		Address:      req.Address,
+		TechnicianID: req.TechnicianID,
+		StartDate:    start,
+		EndDate:      end,
+	}
+	for i := 0; i <= months; i++ {
+		first := time.Date(start.Year(), start.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		for _, visit := range tpl.Visits {
+			if !containsMonth(visit.Months, int(first.Month())) {
+				continue
+			}
+			date := dayOfMonth(first, visit.Day)
+			job := PlannedJob{
+				JobID:     uuid.NewString(),
+				Visit:     visit.Name,
+				Chemicals: append([]ExpectedChemical(nil), visit.Chemicals...),
+				Checklist: append([]ChecklistItem(nil), visit.Checklist...),
+			}
+			if hours != nil {
+				if moved := hours.NextBusinessDay(date); !moved.Equal(date) {
+					planned := date
+					job.RescheduledFrom = &planned
+					date = moved
+				}
+			}
+			if date.Before(start) || !date.Before(end) {
+				continue
+			}
+			job.ScheduledDate = date
+			program.Jobs = append(program.Jobs, job)
+		}
+	}
+	sort.SliceStable(program.Jobs, func(i, j int) bool {
+		return program.Jobs[i].ScheduledDate.Before(program.Jobs[j].ScheduledDate)
+	})
+	return program
+}
+
+// dayOfMonth returns day of first's month, clamped to the month's last day.
+func dayOfMonth(first time.Time, day int) time.Time {
+	if day < 1 {
+		day = 1
+	}
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func containsMonth(months []int, month int) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package serviceplan
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calendar"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func newTestService(t *testing.T) (*Service, *storememory.Store) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.ServicePlanConfig{ScheduleTolerance: 7 * 24 * time.Hour, MissedGrace: 3 * 24 * time.Hour}
+	svc := NewService(cfg, NewMemoryStore(), repos, nil, nil)
+	svc.now = func() time.Time { return date(time.February, 20) }
+	return svc, store
+}
+
+var quarterly = Template{
+	Name: "Quarterly perimeter",
+	Visits: []Visit{{
+		Name:      "Perimeter treatment",
+		Months:    []int{3, 6, 9, 12},
+		Day:       31,
+		Chemicals: []ExpectedChemical{{ChemicalID: "bifen", Name: "Bifenthrin", MinQuantity: 1, MaxQuantity: 4}},
+		Checklist: []ChecklistItem{{ID: "eaves", Label: "Eaves swept", Kind: ItemCheck, Required: true}, {ID: "notes", Label: "Notes", Kind: ItemText}},
+	}},
+}
+
+func TestTemplateValidation(t *testing.T) {
+	svc, _ := newTestService(t)
+	bad := Template{Visits: []Visit{{Name: "x", Months: []int{13}, Checklist: []ChecklistItem{{ID: "a", Label: "A", Kind: "slider"}}}}}
+	if _, err := svc.SaveTemplate("", bad); !errors.Is(err, ErrInvalidPlan) {
+		t.Fatalf("expected invalid plan, got %v", err)
+	}
+	tpl, err := svc.SaveTemplate("", quarterly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tpl.ID == "" || tpl.Months != 12 {
+		t.Fatalf("expected id and default length, got %+v", tpl)
+	}
+	if _, err := svc.SaveTemplate("missing", quarterly); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found updating unknown template, got %v", err)
+	}
+}
+
+func TestAttachExpandsAndPublishesJobs(t *testing.T) {
+	svc, store := newTestService(t)
+	tpl, _ := svc.SaveTemplate("", quarterly)
+
+	program, err := svc.Attach(AttachRequest{TemplateID: tpl.ID, CustomerID: "c1", CustomerName: "Acme", TechnicianID: "tech-1", StartDate: date(time.April, 10)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Time{date(time.June, 30), date(time.September, 30), date(time.December, 31), time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC)}
+	if len(program.Jobs) != len(want) {
+		t.Fatalf("expected %d jobs, got %+v", len(want), program.Jobs)
+	}
+	for i, job := range program.Jobs {
+		if !job.ScheduledDate.Equal(want[i]) {
+			t.Errorf("job %d: expected %s, got %s", i, want[i].Format("2006-01-02"), job.ScheduledDate.Format("2006-01-02"))
+		}
+	}
+
+	published, _ := store.ListJobUpdatesSince(time.Time{})
+	if len(published) != len(want) || published[0].Status != jobStatusPending || published[0].TechnicianID != "tech-1" {
+		t.Fatalf("expected planned jobs published to sync, got %+v", published)
+	}
+
+	screen, err := svc.Checklist(program.Jobs[0].JobID)
+	if err != nil {
+		t.Fatalf("checklist: %v", err)
+	}
+	if screen.Component.Type != "scroll" {
+		t.Fatalf("unexpected checklist screen %+v", screen)
+	}
+	if _, err := svc.Checklist("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestAttachMovesVisitsOffClosedDays(t *testing.T) {
+	svc, store := newTestService(t)
+	store.AddTechnician(models.Technician{ID: "tech-1", Region: "north"})
+	calendars, err := calendar.NewService(config.CalendarConfig{TimeZone: "UTC", Open: "08:00", Close: "17:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}}, calendar.NewMemoryStore(), svc.repos, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = calendars.Save("north", calendar.Calendar{
+		TimeZone: "UTC",
+		Hours:    []calendar.DayHours{{Day: "mon", Open: "08:00", Close: "17:00"}, {Day: "tue", Open: "08:00", Close: "17:00"}, {Day: "wed", Open: "08:00", Close: "17:00"}, {Day: "thu", Open: "08:00", Close: "17:00"}, {Day: "fri", Open: "08:00", Close: "17:00"}},
+		Holidays: []calendar.Holiday{{Date: "2026-12-31", Name: "New Year's Eve"}, {Date: "2027-01-01", Name: "New Year's Day"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.calendars = calendars
+	tpl, _ := svc.SaveTemplate("", quarterly)
+
+	program, err := svc.Attach(AttachRequest{TemplateID: tpl.ID, CustomerID: "c1", CustomerName: "Acme", TechnicianID: "tech-1", StartDate: date(time.April, 10)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	moved := program.Jobs[2]
+	if want := time.Date(2027, time.January, 4, 0, 0, 0, 0, time.UTC); !moved.ScheduledDate.Equal(want) || moved.RescheduledFrom == nil || !moved.RescheduledFrom.Equal(date(time.December, 31)) {
+		t.Fatalf("expected the holiday visit moved to the next business day, got %+v", moved)
+	}
+	if program.Jobs[0].RescheduledFrom != nil {
+		t.Fatalf("visits on business days must not move, got %+v", program.Jobs[0])
+	}
+}
+
+func TestDriftDetection(t *testing.T) {
+	svc, _ := newTestService(t)
+	program := Program{
+		ID: "p1",
+		Jobs: []PlannedJob{
+			{JobID: "on-plan", Visit: "v", ScheduledDate: date(time.January, 5), Chemicals: quarterly.Visits[0].Chemicals},
+			{JobID: "late", Visit: "v", ScheduledDate: date(time.January, 10), Chemicals: quarterly.Visits[0].Chemicals},
+			{JobID: "missed", Visit: "v", ScheduledDate: date(time.February, 1)},
+			{JobID: "skipped", Visit: "v", ScheduledDate: date(time.February, 2)},
+			{JobID: "upcoming", Visit: "v", ScheduledDate: date(time.February, 19)},
+		},
+	}
+	jobs := []models.JobUpload{
+		{ID: "on-plan", Status: "completed", ScheduledDate: date(time.January, 6)},
+		{ID: "late", Status: "completed", ScheduledDate: date(time.January, 25)},
+		{ID: "missed", Status: "pending"},
+		{ID: "skipped", Status: "skipped"},
+	}
+	treatments := []models.ChemicalTreatmentUpload{
+		{JobID: "on-plan", ChemicalID: "bifen", QuantityUsed: 1.5},
+		{JobID: "on-plan", ChemicalID: "bifen", QuantityUsed: 1},
+		{JobID: "late", ChemicalID: "bifen", QuantityUsed: 9},
+		{JobID: "late", ChemicalID: "fipronil", QuantityUsed: 1},
+	}
+
+	drift := svc.drift(program, jobs, treatments, date(time.February, 20))
+	if drift.Completed != 2 || drift.Upcoming != 1 {
+		t.Fatalf("unexpected counts %+v", drift)
+	}
+	got := make(map[string][]string)
+	for _, issue := range drift.Issues {
+		got[issue.JobID] = append(got[issue.JobID], issue.Kind)
+	}
+	want := map[string][]string{
+		"late":    {DriftOffSchedule, DriftQuantity, DriftUnexpectedChemical},
+		"missed":  {DriftMissed},
+		"skipped": {DriftSkipped},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected issues %v, got %v", want, got)
+	}
+	for job, kinds := range want {
+		if len(got[job]) != len(kinds) {
+			t.Fatalf("%s: expected %v, got %v", job, kinds, got[job])
+		}
+		for i := range kinds {
+			if got[job][i] != kinds[i] {
+				t.Fatalf("%s: expected %v, got %v", job, kinds, got[job])
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package servicereport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler serves service reports.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a report handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetReport returns the PDF service report of a completed job.
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobId")
+	report, err := h.service.PDF(r.Context(), jobID)
+	if err != nil {
+		h.fail(w, r, "failed to build service report", err)
+		return
+	}
+	defer report.Close()
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || r >= 0x7f || r == '"' || r == '\\' || r == '/' {
+			return '_'
+		}
+		return r
+	}, jobID)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="service-report-%s.pdf"`, name))
+	if _, err := io.Copy(w, report); err != nil {
+		middleware.LoggerFrom(r.Context()).Warn("failed to send service report", slog.String("job", jobID), slog.Any("error", err))
+	}
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrNotCompleted):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
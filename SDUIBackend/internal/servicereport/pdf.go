@@ -0,0 +1,301 @@
+package servicereport
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // decoders for embedded photos
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImageSide is the longest side, in pixels, of photos that have to be
+// re-encoded; larger ones are scaled down so reports stay small.
+const maxImageSide = 1600
+
+// pdf is a minimal PDF 1.4 writer: text in the standard Helvetica fonts,
+// which every viewer has, lines and images on fixed-size pages.
+// Coordinates are in points from the bottom left of the page.
+type pdf struct {
+	width, height float64
+	pages         []*bytes.Buffer
+	current       int // page drawn on
+	images        []pdfImage
+}
+
+type pdfImage struct {
+	width, height int
+	colorSpace    string
+	filter        string
+	data          []byte
+}
+
+func newPDF(width, height float64) *pdf {
+	return &pdf{width: width, height: height}
+}
+
+// addPage starts a page and draws on it.
+func (p *pdf) addPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+	p.current = len(p.pages) - 1
+}
+
+func (p *pdf) page() *bytes.Buffer {
+	return p.pages[p.current]
+}
+
+// text draws s with its baseline starting at (x, y).
+func (p *pdf) text(x, y float64, bold bool, size float64, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page(), "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), encodeText(s))
+}
+
+// line strokes a line of the given width.
+func (p *pdf) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(p.page(), "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// drawImage places an added image in the box with its bottom left corner
+// at (x, y).
+func (p *pdf) drawImage(index int, x, y, w, h float64) {
+	fmt.Fprintf(p.page(), "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(w), num(h), num(x), num(y), index)
+}
+
+// addImage embeds an image and returns its index and size in pixels.
+// Baseline JPEGs are embedded as they are; anything else the image package
+// decodes is re-encoded as compressed RGB over white.
+func (p *pdf) addImage(data []byte) (index, width, height int, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	img := pdfImage{width: cfg.Width, height: cfg.Height, filter: "DCTDecode", data: data}
+	switch {
+	case format == "jpeg" && cfg.ColorModel == color.YCbCrModel:
+		img.colorSpace = "DeviceRGB"
+	case format == "jpeg" && cfg.ColorModel == color.GrayModel:
+		img.colorSpace = "DeviceGray"
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if img, err = flateImage(decoded); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	p.images = append(p.images, img)
+	return len(p.images) - 1, img.width, img.height, nil
+}
+
+// flateImage flattens src onto white, scaling it down to maxImageSide.
+func flateImage(src image.Image) (pdfImage, error) {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return pdfImage{}, fmt.Errorf("image is empty")
+	}
+	scale := 1.0
+	if longest := max(w, h); longest > maxImageSide {
+		scale = float64(longest) / maxImageSide
+		w, h = max(1, int(float64(w)/scale)), max(1, int(float64(h)/scale))
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 0, w*3)
+	for y := 0; y < h; y++ {
+		row = row[:0]
+		for x := 0; x < w; x++ {
+			r, g, bl, a := src.At(b.Min.X+int(float64(x)*scale), b.Min.Y+int(float64(y)*scale)).RGBA()
+			white := 0xffff - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8))
+		}
+		if _, err := zw.Write(row); err != nil {
+			return pdfImage{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return pdfImage{}, err
+	}
+	return pdfImage{width: w, height: h, colorSpace: "DeviceRGB", filter: "FlateDecode", data: buf.Bytes()}, nil
+}
+
+// writeTo writes the document, its info dictionary naming title.
+func (p *pdf) writeTo(w io.Writer, title string, created time.Time) error {
+	// Objects 1 to 4 are the catalog, page tree and the two fonts; images
+	// and then each page with its content stream follow.
+	var objects [][]byte
+	add := func(body []byte) int {
+		objects = append(objects, body)
+		return len(objects)
+	}
+	catalog := add(nil)
+	tree := add(nil)
+	add([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"))
+	add([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"))
+	var xobjects strings.Builder
+	for i, img := range p.images {
+		header := fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s", img.width, img.height, img.colorSpace, img.filter)
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i, add(stream(header, img.data)))
+	}
+	var kids []string
+	for _, content := range p.pages {
+		compressed, err := deflate(content.Bytes())
+		if err != nil {
+			return err
+		}
+		contents := add(stream("<< /Filter /FlateDecode", compressed))
+		page := add([]byte(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> /XObject <<%s >> >> /Contents %d 0 R >>",
+			tree, num(p.width), num(p.height), xobjects.String(), contents)))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	objects[catalog-1] = []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", tree))
+	objects[tree-1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	info := add([]byte(fmt.Sprintf("<< /Title (%s) /Producer (PestGenie) /CreationDate (D:%s) >>", encodeText(title), created.UTC().Format("20060102150405Z"))))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, info, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// stream completes a stream object from its unterminated dictionary.
+func stream(dict string, data []byte) []byte {
+	out := []byte(fmt.Sprintf("%s /Length %d >>\nstream\n", dict, len(data)))
+	out = append(out, data...)
+	return append(out, "\nendstream"...)
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// winAnsi maps the characters outside Latin-1 that the WinAnsi encoding
+// of the standard fonts has.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encodeText encodes s as a WinAnsi string literal body. Characters the
+// encoding lacks become ?, and control characters spaces.
+func encodeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if c, ok := winAnsi[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// helvetica is the advance width of the printable ASCII characters in
+// Helvetica, in thousandths of the font size.
+var helvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth measures s in points. Other characters count as a digit, and
+// bold text as slightly wider regular text, which is close enough to wrap
+// lines.
+func textWidth(s string, bold bool, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			total += helvetica[r-0x20]
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if bold {
+		w *= 1.06
+	}
+	return w
+}
+
+// wrap breaks s into lines at most width wide, at spaces where it can and
+// inside words longer than a line. Newlines in s are kept.
+func wrap(s string, bold bool, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, bold, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = word
+			for textWidth(line, bold, size) > width {
+				cut := len([]rune(line)) - 1
+				for cut > 1 && textWidth(string([]rune(line)[:cut]), bold, size) > width {
+					cut--
+				}
+				lines = append(lines, string([]rune(line)[:cut]))
+				line = string([]rune(line)[cut:])
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
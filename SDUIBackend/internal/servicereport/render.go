@@ -0,0 +1,337 @@
+package servicereport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// maxReportBytes bounds the PDFs a remote renderer may return.
+const maxReportBytes = 64 << 20
+
+// Renderer renders a report laid out by a layout as a PDF.
+type Renderer interface {
+	// Name identifies the renderer and its version; reports rendered by
+	// another renderer are not reused.
+	Name() string
+	Render(ctx context.Context, w io.Writer, layout Layout, r Report) error
+}
+
+// NewRenderer returns the renderer selected by cfg.
+func NewRenderer(cfg config.ReportConfig) Renderer {
+	if cfg.Renderer == "remote" {
+		return &RemoteRenderer{URL: cfg.RendererURL, Client: &http.Client{Timeout: cfg.RequestTimeout}}
+	}
+	return PDFRenderer{}
+}
+
+// PDFRenderer renders reports itself, with the standard PDF fonts. Photos
+// in formats the image package cannot decode, such as HEIC, are listed by
+// caption only.
+type PDFRenderer struct{}
+
+func (PDFRenderer) Name() string { return "builtin/1" }
+
+// Text sizes and spacing, in points.
+const (
+	margin      = 54
+	headingSize = 20
+	sectionSize = 13
+	bodySize    = 10
+	captionSize = 8
+	leading     = 1.35
+	labelWidth  = 120
+	gap         = 12
+)
+
+func (PDFRenderer) Render(ctx context.Context, w io.Writer, layout Layout, r Report) error {
+	size := pageSizes[layout.PageSize]
+	c := &canvas{pdf: newPDF(size[0], size[1])}
+	c.newPage()
+	for i, b := range layout.Blocks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		text, err := execute(b.text, r)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+		switch b.Type {
+		case BlockHeading:
+			c.paragraph(margin, c.contentWidth(), true, headingSize, text)
+			c.y -= gap
+		case BlockText:
+			c.paragraph(margin, c.contentWidth(), false, bodySize, text)
+			c.y -= gap
+		case BlockFields:
+			if err := c.fields(b.Fields, r); err != nil {
+				return fmt.Errorf("block %d: %w", i, err)
+			}
+		case BlockTreatments:
+			c.section(text)
+			c.treatments(r.Treatments)
+		case BlockSignature:
+			c.section(text)
+			c.signature(r)
+		case BlockPhotos:
+			c.section(text)
+			c.photos(r.Photos, b.Columns)
+		}
+	}
+	c.footers(r.Job.ID)
+	title, err := execute(layout.title, r)
+	if err != nil {
+		return fmt.Errorf("title: %w", err)
+	}
+	return c.pdf.writeTo(w, title, r.GeneratedAt)
+}
+
+// canvas flows blocks down pages, starting a page when one is full.
+type canvas struct {
+	pdf *pdf
+	y   float64 // top of the free space on the current page
+}
+
+func (c *canvas) newPage() {
+	c.pdf.addPage()
+	c.y = c.pdf.height - margin
+}
+
+func (c *canvas) contentWidth() float64 {
+	return c.pdf.width - 2*margin
+}
+
+// need starts a new page unless height points fit on this one.
+func (c *canvas) need(height float64) {
+	if c.y-height < margin && c.y < c.pdf.height-margin {
+		c.newPage()
+	}
+}
+
+// paragraph draws wrapped text at x, starting pages as it fills them.
+func (c *canvas) paragraph(x, width float64, bold bool, size float64, s string) {
+	for _, line := range wrap(s, bold, size, width) {
+		c.need(size * leading)
+		c.y -= size * leading
+		c.pdf.text(x, c.y+size*(leading-1), bold, size, line)
+	}
+}
+
+func (c *canvas) section(title string) {
+	if title == "" {
+		return
+	}
+	c.need(sectionSize*leading + 3*bodySize*leading)
+	c.paragraph(margin, c.contentWidth(), true, sectionSize, title)
+	c.pdf.line(margin, c.y, c.pdf.width-margin, c.y, 0.5)
+	c.y -= bodySize / 2
+}
+
+func (c *canvas) fields(fields []Field, r Report) error {
+	for _, f := range fields {
+		value, err := execute(f.value, r)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Label, err)
+		}
+		if value == "" {
+			continue
+		}
+		// The label sits on the baseline of the value's first line.
+		c.need(bodySize * leading)
+		c.pdf.text(margin, c.y-bodySize, true, bodySize, f.Label)
+		c.paragraph(margin+labelWidth, c.contentWidth()-labelWidth, false, bodySize, value)
+	}
+	c.y -= gap
+	return nil
+}
+
+func (c *canvas) treatments(treatments []Treatment) {
+	if len(treatments) == 0 {
+		c.paragraph(margin, c.contentWidth(), false, bodySize, "No treatments were recorded.")
+		c.y -= gap
+		return
+	}
+	for _, t := range treatments {
+		name := t.ChemicalName
+		if name == "" {
+			name = t.ChemicalID
+		}
+		epa := t.EPARegistration
+		if epa == "" {
+			epa = "not recorded"
+		}
+		details := []string{"EPA Reg. No. " + epa}
+		if t.ActiveIngredient != "" {
+			details = append(details, "Active ingredient: "+t.ActiveIngredient)
+		}
+		applied := strconv.FormatFloat(t.QuantityUsed, 'f', -1, 64)
+		if t.UnitOfMeasure != "" {
+			applied += " " + t.UnitOfMeasure
+		}
+		if t.ApplicationMethod != "" {
+			applied += " by " + t.ApplicationMethod
+		}
+		if !t.ApplicationDate.IsZero() {
+			applied += ", " + t.ApplicationDate.Format("Jan 2, 2006 3:04 PM MST")
+		}
+		details = append(details, "Applied: "+applied)
+		for _, d := range [][2]string{
+			{"Target pests", t.TargetPests},
+			{"Dilution", t.DilutionRatio},
+			{"Applicator", t.ApplicatorName},
+			{"Notes", t.Notes},
+		} {
+			if strings.TrimSpace(d[1]) != "" {
+				details = append(details, d[0]+": "+strings.TrimSpace(d[1]))
+			}
+		}
+		c.need(bodySize * leading * float64(len(details)+1))
+		c.paragraph(margin, c.contentWidth(), true, bodySize+1, name)
+		c.paragraph(margin+gap, c.contentWidth()-gap, false, bodySize, strings.Join(details, "\n"))
+		c.y -= gap / 2
+	}
+	c.y -= gap / 2
+}
+
+func (c *canvas) signature(r Report) {
+	const boxWidth, boxHeight = 220, 90
+	name := r.Technician.DisplayName
+	if name == "" {
+		name = r.Job.TechnicianID
+	}
+	if r.Signature == nil {
+		c.paragraph(margin, c.contentWidth(), false, bodySize, "Not signed.")
+		c.y -= gap
+		return
+	}
+	c.need(boxHeight + 2*captionSize*leading)
+	if index, w, h, err := c.pdf.addImage(r.Signature.Data); err == nil {
+		dw, dh := fit(w, h, boxWidth, boxHeight)
+		c.pdf.drawImage(index, margin, c.y-dh, dw, dh)
+	} else {
+		c.pdf.text(margin, c.y-boxHeight/2, false, captionSize, "(signature image cannot be shown)")
+	}
+	c.y -= boxHeight
+	c.pdf.line(margin, c.y, margin+boxWidth, c.y, 0.75)
+	caption := name
+	if r.Signature.UploadedAt != nil {
+		caption += ", signed " + r.Signature.UploadedAt.Format("Jan 2, 2006 3:04 PM MST")
+	}
+	c.paragraph(margin, boxWidth, false, captionSize, caption)
+	c.y -= gap
+}
+
+func (c *canvas) photos(photos []Image, columns int) {
+	if len(photos) == 0 {
+		c.paragraph(margin, c.contentWidth(), false, bodySize, "No photos were taken.")
+		c.y -= gap
+		return
+	}
+	cellWidth := (c.contentWidth() - gap*float64(columns-1)) / float64(columns)
+	cellHeight := cellWidth * 3 / 4
+	for start := 0; start < len(photos); start += columns {
+		row := photos[start:min(start+columns, len(photos))]
+		c.need(cellHeight + 3*captionSize*leading)
+		top := c.y
+		bottom := top
+		for i, p := range row {
+			x := margin + float64(i)*(cellWidth+gap)
+			c.y = top
+			if index, w, h, err := c.pdf.addImage(p.Data); err == nil {
+				dw, dh := fit(w, h, cellWidth, cellHeight)
+				c.pdf.drawImage(index, x+(cellWidth-dw)/2, top-dh, dw, dh)
+			} else {
+				c.pdf.text(x, top-cellHeight/2, false, captionSize, fmt.Sprintf("(%s photo cannot be shown)", p.ContentType))
+			}
+			c.y = top - cellHeight
+			if caption := photoCaption(p); caption != "" {
+				lines := wrap(caption, false, captionSize, cellWidth)
+				for _, line := range lines[:min(3, len(lines))] {
+					c.y -= captionSize * leading
+					c.pdf.text(x, c.y+captionSize*(leading-1), false, captionSize, line)
+				}
+			}
+			bottom = min(bottom, c.y)
+		}
+		c.y = bottom - gap
+	}
+}
+
+// photoCaption describes a photo from its annotation.
+func photoCaption(p Image) string {
+	a := p.Annotation
+	var parts []string
+	if len(a.Species) > 0 {
+		parts = append(parts, strings.ReplaceAll(strings.Join(a.Species, ", "), "_", " "))
+	}
+	if a.Location != "" {
+		parts = append(parts, strings.ReplaceAll(a.Location, "_", " "))
+	}
+	if a.Severity != "" {
+		parts = append(parts, a.Severity+" severity")
+	}
+	if a.Notes != "" {
+		parts = append(parts, a.Notes)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// footers numbers the pages.
+func (c *canvas) footers(jobID string) {
+	for i := range c.pdf.pages {
+		c.pdf.current = i
+		footer := fmt.Sprintf("Job %s · Page %d of %d", jobID, i+1, len(c.pdf.pages))
+		c.pdf.text(c.pdf.width-margin-textWidth(footer, false, captionSize), margin/2, false, captionSize, footer)
+	}
+}
+
+// fit scales a w by h image to fit in a box, without enlarging it past
+// twice its size.
+func fit(w, h int, boxWidth, boxHeight float64) (float64, float64) {
+	scale := min(boxWidth/float64(w), boxHeight/float64(h), 2)
+	return float64(w) * scale, float64(h) * scale
+}
+
+// RemoteRenderer renders reports with an HTTP service: it POSTs
+// {"layout": ..., "report": ...} as JSON, images base64-encoded, and
+// expects the PDF in return.
+type RemoteRenderer struct {
+	URL    string
+	Client *http.Client
+}
+
+func (r *RemoteRenderer) Name() string { return "remote " + r.URL }
+
+func (r *RemoteRenderer) Render(ctx context.Context, w io.Writer, layout Layout, report Report) error {
+	body, err := json.Marshal(map[string]any{"layout": layout, "report": report})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/pdf")
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("report renderer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("report renderer returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/pdf") {
+		return fmt.Errorf("report renderer returned %q, not a PDF", ct)
+	}
+	_, err = io.Copy(w, io.LimitReader(resp.Body, maxReportBytes))
+	return err
+}
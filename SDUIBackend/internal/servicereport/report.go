@@ -0,0 +1,209 @@
+// Package servicereport renders the PDF service report customers receive
+// for a completed job: its details, the treatments applied with their EPA
+// registration numbers, the job's signature and its photos.
+package servicereport
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+)
+
+var (
+	// ErrNotFound is returned when a job does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrNotCompleted is returned for jobs that are not completed yet.
+	ErrNotCompleted = errors.New("job is not completed")
+	// ErrInvalidLayout wraps problems with a report layout.
+	ErrInvalidLayout = errors.New("invalid report layout")
+)
+
+// Report is what a service report shows.
+type Report struct {
+	Job        models.JobUpload
+	Technician models.Technician // zero when the technician is unknown
+	Treatments []Treatment       // in application order
+	Signature  *Image            // the job's signature, or the latest; nil when unsigned
+	Photos     []Image           // oldest first
+	// GeneratedAt is when the report was first rendered.
+	GeneratedAt time.Time
+}
+
+// Treatment is a treatment applied on the job with the chemical it used.
+type Treatment struct {
+	models.ChemicalTreatmentUpload
+	ChemicalName     string
+	EPARegistration  string
+	ActiveIngredient string
+	UnitOfMeasure    string
+}
+
+// Image is an uploaded photo or signature with its content.
+type Image struct {
+	photo.Metadata
+	Data []byte `json:"data,omitempty"`
+}
+
+// Block types.
+const (
+	BlockHeading    = "heading"
+	BlockText       = "text"
+	BlockFields     = "fields"
+	BlockTreatments = "treatments"
+	BlockSignature  = "signature"
+	BlockPhotos     = "photos"
+)
+
+// Layout arranges a report as a sequence of blocks. Title, block texts and
+// field values are text/template templates executed with the Report, with
+// the functions date and datetime formatting times:
+//
+//	{"pageSize": "letter", "title": "Service report {{.Job.ID}}", "blocks": [
+//	  {"type": "heading", "text": "Service report"},
+//	  {"type": "fields", "fields": [{"label": "Customer", "value": "{{.Job.CustomerName}}"}]},
+//	  {"type": "treatments", "text": "Treatments applied"},
+//	  {"type": "signature", "text": "Signature"},
+//	  {"type": "photos", "text": "Photos", "columns": 2}]}
+type Layout struct {
+	PageSize string  `json:"pageSize,omitempty"` // letter (default) or a4
+	Title    string  `json:"title"`
+	Blocks   []Block `json:"blocks"`
+
+	title *template.Template
+}
+
+// Block is part of a layout. Text is the content of heading and text
+// blocks and the section title of the others; fields blocks list Fields,
+// skipping those that come out empty, and photos blocks place Columns
+// photos per row.
+type Block struct {
+	Type    string  `json:"type"`
+	Text    string  `json:"text,omitempty"`
+	Fields  []Field `json:"fields,omitempty"`
+	Columns int     `json:"columns,omitempty"`
+
+	text *template.Template
+}
+
+// Field is a labelled value.
+type Field struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+
+	value *template.Template
+}
+
+//go:embed layout.json
+var defaultLayout []byte
+
+// LoadLayout reads the layout in path, or the built-in one when path is
+// empty.
+func LoadLayout(path string) (Layout, error) {
+	data := defaultLayout
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return Layout{}, err
+		}
+	}
+	var l Layout
+	if err := json.Unmarshal(data, &l); err != nil {
+		return Layout{}, fmt.Errorf("%w: %v", ErrInvalidLayout, err)
+	}
+	if err := l.compile(); err != nil {
+		return Layout{}, err
+	}
+	return l, nil
+}
+
+// pageSizes are page sizes in points.
+var pageSizes = map[string][2]float64{
+	"letter": {612, 792},
+	"a4":     {595, 842},
+}
+
+func (l *Layout) compile() error {
+	l.PageSize = strings.ToLower(l.PageSize)
+	if l.PageSize == "" {
+		l.PageSize = "letter"
+	}
+	if _, ok := pageSizes[l.PageSize]; !ok {
+		return fmt.Errorf("%w: unknown page size %q", ErrInvalidLayout, l.PageSize)
+	}
+	if len(l.Blocks) == 0 {
+		return fmt.Errorf("%w: no blocks", ErrInvalidLayout)
+	}
+	var err error
+	if l.title, err = parse("title", l.Title); err != nil {
+		return err
+	}
+	for i := range l.Blocks {
+		b := &l.Blocks[i]
+		switch b.Type {
+		case BlockHeading, BlockText, BlockTreatments, BlockSignature:
+		case BlockFields:
+			if len(b.Fields) == 0 {
+				return fmt.Errorf("%w: block %d has no fields", ErrInvalidLayout, i)
+			}
+		case BlockPhotos:
+			if b.Columns == 0 {
+				b.Columns = 2
+			}
+			if b.Columns < 1 || b.Columns > 4 {
+				return fmt.Errorf("%w: block %d has %d columns, want 1 to 4", ErrInvalidLayout, i, b.Columns)
+			}
+		default:
+			return fmt.Errorf("%w: block %d has unknown type %q", ErrInvalidLayout, i, b.Type)
+		}
+		if b.text, err = parse(fmt.Sprintf("block %d", i), b.Text); err != nil {
+			return err
+		}
+		for j := range b.Fields {
+			if b.Fields[j].value, err = parse(fmt.Sprintf("block %d field %d", i, j), b.Fields[j].Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("Jan 2, 2006")
+	},
+	"datetime": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("Jan 2, 2006 3:04 PM MST")
+	},
+}
+
+func parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLayout, err)
+	}
+	return t, nil
+}
+
+// execute renders a compiled template, trimmed.
+func execute(t *template.Template, r Report) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, r); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,223 @@
+package servicereport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// Photos is what reports need of the photo service.
+type Photos interface {
+	Search(q photo.Query) ([]photo.Metadata, error)
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+}
+
+// Service assembles and renders service reports. Rendered reports are
+// kept in blob storage under a hash of everything they show, so a report
+// is rendered again only once its job, treatments, photos, layout or
+// renderer change.
+type Service struct {
+	cfg      config.ReportConfig
+	repos    repository.Repository
+	photos   Photos
+	blobs    storage.BlobStore
+	renderer Renderer
+	layout   Layout
+	// layoutSum identifies the layout in report keys.
+	layoutSum string
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService creates a report service laying reports out with layout.
+func NewService(cfg config.ReportConfig, repos repository.Repository, photos Photos, blobs storage.BlobStore, renderer Renderer, layout Layout, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	// Compiled templates are unexported, so this is the layout as written.
+	data, _ := json.Marshal(layout)
+	sum := sha256.Sum256(data)
+	return &Service{
+		cfg:       cfg,
+		repos:     repos,
+		photos:    photos,
+		blobs:     blobs,
+		renderer:  renderer,
+		layout:    layout,
+		layoutSum: hex.EncodeToString(sum[:]),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// PDF returns the report of a completed job; the caller closes it.
+func (s *Service) PDF(ctx context.Context, jobID string) (io.ReadCloser, error) {
+	r, err := s.assemble(tenant.Repository(ctx, s.repos), jobID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.key(r)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := s.blobs.Get(ctx, key)
+	if err == nil {
+		return cached, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		s.logger.Warn("failed to read stored service report", slog.String("job", jobID), slog.Any("error", err))
+	}
+
+	if r.Signature != nil {
+		s.load(ctx, r.Signature)
+	}
+	for i := range r.Photos {
+		s.load(ctx, &r.Photos[i])
+	}
+	r.GeneratedAt = s.now().UTC()
+	var buf bytes.Buffer
+	if err := s.renderer.Render(ctx, &buf, s.layout, r); err != nil {
+		return nil, fmt.Errorf("render report for job %s: %w", jobID, err)
+	}
+	// The report is served either way; it is rendered again next time.
+	if _, err := s.blobs.Put(ctx, key, "application/pdf", bytes.NewReader(buf.Bytes())); err != nil {
+		s.logger.Warn("failed to store service report", slog.String("job", jobID), slog.Any("error", err))
+	}
+	return io.NopCloser(&buf), nil
+}
+
+// assemble gathers a completed job's report, without image data.
+func (s *Service) assemble(repos repository.Repository, jobID string) (Report, error) {
+	jobs, err := repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return Report{}, err
+	}
+	var r Report
+	found := false
+	for _, j := range jobs {
+		if j.ID == jobID {
+			r.Job, found = j, true
+		}
+	}
+	if !found {
+		return Report{}, fmt.Errorf("%w: job %q", ErrNotFound, jobID)
+	}
+	if !strings.EqualFold(r.Job.Status, "completed") {
+		return Report{}, fmt.Errorf("%w: job %q is %s", ErrNotCompleted, jobID, r.Job.Status)
+	}
+	if tech, err := repos.Technicians.GetByID(r.Job.TechnicianID); err == nil {
+		r.Technician = tech
+	}
+
+	if r.Treatments, err = s.treatments(repos, jobID); err != nil {
+		return Report{}, err
+	}
+
+	uploads, err := s.photos.Search(photo.Query{JobID: jobID})
+	if err != nil {
+		return Report{}, err
+	}
+	for _, m := range uploads {
+		switch {
+		case m.UploadedAt == nil:
+		case m.Kind == photo.KindSignature:
+			// The signature linked to the job wins over others.
+			if r.Signature == nil || r.Signature.ID != r.Job.SignatureID {
+				r.Signature = &Image{Metadata: m}
+			}
+		case len(r.Photos) < s.cfg.MaxPhotos:
+			r.Photos = append(r.Photos, Image{Metadata: m})
+		}
+	}
+	return r, nil
+}
+
+// treatments returns the treatments applied on a job with their chemicals,
+// in application order.
+func (s *Service) treatments(repos repository.Repository, jobID string) ([]Treatment, error) {
+	uploads, err := repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]models.ChemicalTreatmentUpload{}
+	for _, t := range uploads {
+		if t.JobID == jobID {
+			latest[t.ID] = t
+		}
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	// Deleted chemicals are listed too, so old treatments keep their names.
+	chemicals, err := repos.Sync.ListAllChemicals()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.ChemicalUpload, len(chemicals))
+	for _, c := range chemicals {
+		byID[c.ID] = c
+	}
+	out := make([]Treatment, 0, len(latest))
+	for _, t := range latest {
+		c := byID[t.ChemicalID]
+		out = append(out, Treatment{
+			ChemicalTreatmentUpload: t,
+			ChemicalName:            c.Name,
+			EPARegistration:         c.EPARegistration,
+			ActiveIngredient:        c.ActiveIngredient,
+			UnitOfMeasure:           c.UnitOfMeasure,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ApplicationDate.Equal(out[j].ApplicationDate) {
+			return out[i].ApplicationDate.Before(out[j].ApplicationDate)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// key names the stored report of r, which has no image data or
+// generation time yet.
+func (s *Service) key(r Report) (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	h.Write([]byte(s.layoutSum + "\x00" + s.renderer.Name()))
+	return "reports/" + hex.EncodeToString(h.Sum(nil))[:32] + ".pdf", nil
+}
+
+// load reads an image's data. An image that cannot be read is left empty,
+// and the renderer shows it as missing rather than failing the report.
+func (s *Service) load(ctx context.Context, img *Image) {
+	body, err := s.photos.Open(ctx, img.ID)
+	if err != nil {
+		s.logger.Warn("failed to read report image", slog.String("photo", img.ID), slog.Any("error", err))
+		return
+	}
+	defer body.Close()
+	if img.Data, err = io.ReadAll(body); err != nil {
+		img.Data = nil
+		s.logger.Warn("failed to read report image", slog.String("photo", img.ID), slog.Any("error", err))
+	}
+}
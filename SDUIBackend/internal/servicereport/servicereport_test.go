@@ -0,0 +1,211 @@
+package servicereport
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+// countingRenderer counts renders.
+type countingRenderer struct {
+	Renderer
+	renders int
+}
+
+func (c *countingRenderer) Render(ctx context.Context, w io.Writer, layout Layout, r Report) error {
+	c.renders++
+	return c.Renderer.Render(ctx, w, layout, r)
+}
+
+func encodeImage(t *testing.T, format string) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		img.Set(x, 15, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pageText inflates a PDF's streams and returns the text drawn in them.
+func pageText(t *testing.T, doc []byte) string {
+	t.Helper()
+	var text strings.Builder
+	for _, m := range regexp.MustCompile(`(?s)/FlateDecode /Length (\d+) >>\nstream\n`).FindAllSubmatchIndex(doc, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(doc[m[1]:]))
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(zr)
+		for _, s := range regexp.MustCompile(`\((.*?)\) Tj`).FindAllSubmatch(data, -1) {
+			text.Write(s[1])
+			text.WriteByte('\n')
+		}
+	}
+	return text.String()
+}
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	applied := time.Date(2026, 5, 4, 14, 30, 0, 0, time.UTC)
+	for _, err := range []error{
+		store.SaveTechnician(models.Technician{ID: "sam", DisplayName: "Sam Ortiz"}),
+		store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "sam", CustomerName: "Miller Farms", Address: "12 Oak Rd", ScheduledDate: applied, Status: "completed"}),
+		store.SaveJobUpload(models.JobUpload{ID: "job-2", TechnicianID: "sam", Status: "in_progress"}),
+		store.SaveChemicalUpload(models.ChemicalUpload{ID: "c1", Name: "Termidor SC", EPARegistration: "7969-210", UnitOfMeasure: "oz"}),
+		store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t1", JobID: "job-1", ChemicalID: "c1", QuantityUsed: 2.5, ApplicationMethod: "perimeter spray", ApplicationDate: applied}),
+		store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t2", JobID: "job-other", ChemicalID: "c1", ApplicationDate: applied}),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	blobs, err := storage.NewLocal(dir, "http://api.test"+storage.LocalPathPrefix, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photos := photo.NewService(config.PhotoConfig{MaxBytes: 1 << 20, AllowedTypes: []string{"image/jpeg", "image/png"}, URLTTL: time.Minute}, photo.NewMemoryStore(), blobs, nil)
+	for _, up := range []struct {
+		id, kind, contentType string
+		data                  []byte
+	}{
+		{"p1", "", "image/png", encodeImage(t, "png")},
+		{"sig", photo.KindSignature, "image/jpeg", encodeImage(t, "jpeg")},
+	} {
+		blob, err := photos.PutBlob(ctx, up.contentType, bytes.NewReader(up.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := photos.Attach(up.id, photo.AnnotateRequest{JobID: "job-1", Kind: up.kind, Annotation: photo.Annotation{Notes: "Entry point by the " + up.id}}, blob); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	layout, err := LoadLayout("")
+	if err != nil {
+		t.Fatalf("built-in layout: %v", err)
+	}
+	renderer := &countingRenderer{Renderer: PDFRenderer{}}
+	svc := NewService(config.ReportConfig{MaxPhotos: 12}, repos, photos, blobs, renderer, layout, nil)
+	r := chi.NewRouter()
+	r.Get("/jobs/{jobId}/report.pdf", NewHandler(svc).GetReport)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	rec := get("/jobs/job-1/report.pdf")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	doc := rec.Body.Bytes()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("expected a complete PDF")
+	}
+	text := pageText(t, doc)
+	for _, want := range []string{"Miller Farms", "Sam Ortiz", "Termidor SC", "EPA Reg. No. 7969-210", "2.5 oz by perimeter spray", "Entry point by the p1", "Page 1 of"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in the report, got:\n%s", want, text)
+		}
+	}
+	if got := bytes.Count(doc, []byte("/Subtype /Image")); got != 2 {
+		t.Errorf("expected the photo and signature embedded, got %d images", got)
+	}
+
+	if rec := get("/jobs/job-1/report.pdf"); rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), doc) || renderer.renders != 1 {
+		t.Errorf("expected the stored report served again, got %d after %d renders", rec.Code, renderer.renders)
+	}
+	if err := store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t3", JobID: "job-1", ChemicalID: "c1", ApplicationDate: applied.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get("/jobs/job-1/report.pdf"); rec.Code != http.StatusOK || renderer.renders != 2 {
+		t.Errorf("expected a new treatment to render the report again, got %d after %d renders", rec.Code, renderer.renders)
+	}
+
+	if rec := get("/jobs/job-2/report.pdf"); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a job in progress, got %d", rec.Code)
+	}
+	if rec := get("/jobs/nope/report.pdf"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job, got %d", rec.Code)
+	}
+}
+
+func TestLoadLayoutRejectsBadLayouts(t *testing.T) {
+	for name, layout := range map[string]string{
+		"no blocks":     `{"title": "x", "blocks": []}`,
+		"unknown block": `{"blocks": [{"type": "table"}]}`,
+		"bad template":  `{"blocks": [{"type": "text", "text": "{{.Job.ID"}]}`,
+		"page size":     `{"pageSize": "legal", "blocks": [{"type": "text"}]}`,
+		"columns":       `{"blocks": [{"type": "photos", "columns": 9}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "layout.json")
+		if err := os.WriteFile(path, []byte(layout), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLayout(path); !errors.Is(err, ErrInvalidLayout) {
+			t.Errorf("%s: expected ErrInvalidLayout, got %v", name, err)
+		}
+	}
+}
+
+func TestRemoteRenderer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !bytes.Contains(body, []byte(`"layout"`)) || !bytes.Contains(body, []byte(`"CustomerName":"Miller Farms"`)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-remote"))
+	}))
+	defer srv.Close()
+	layout, err := LoadLayout("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	renderer := &RemoteRenderer{URL: srv.URL, Client: srv.Client()}
+	if err := renderer.Render(context.Background(), &out, layout, Report{Job: models.JobUpload{CustomerName: "Miller Farms"}}); err != nil || out.String() != "%PDF-remote" {
+		t.Fatalf("unexpected render %q: %v", out.String(), err)
+	}
+	if err := renderer.Render(context.Background(), &out, layout, Report{}); err == nil {
+		t.Error("expected a failed render reported")
+	}
+}
@@ -0,0 +1,167 @@
+// Package sftp connects to partner SFTP servers. It verifies the server's
+// host key, signs in with a password or private key, and hands back a
+// github.com/pkg/sftp client with the few helpers partner file exchanges
+// need on top.
+package sftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// defaultTimeout bounds connecting and the SSH handshake.
+	defaultTimeout = 30 * time.Second
+	// posixRename is the OpenSSH extension that replaces an existing target.
+	posixRename = "posix-rename@openssh.com"
+)
+
+// Config is how to reach and sign in to a server.
+type Config struct {
+	Host string
+	// Port defaults to 22.
+	Port     int
+	Username string
+	// HostKey is the server's public key in authorized_keys form, such as
+	// "ssh-ed25519 AAAA..."; a server presenting another key is refused.
+	HostKey string
+	// Credentials is a password, or a PEM-encoded private key.
+	Credentials string
+	// Timeout bounds connecting and the SSH handshake; 30s when zero.
+	Timeout time.Duration
+}
+
+// ParseHostKey parses a public key in authorized_keys form.
+func ParseHostKey(s string) (ssh.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(s)))
+	if err != nil {
+		return nil, fmt.Errorf("parse host key: %w", err)
+	}
+	return key, nil
+}
+
+// Client is an SFTP session and the SSH connection it runs over. Missing
+// files are reported as fs.ErrNotExist.
+type Client struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+// Dial connects to the server, verifies its host key, signs in and starts
+// the sftp subsystem.
+func Dial(ctx context.Context, cfg Config) (*Client, error) {
+	hostKey, err := ParseHostKey(cfg.HostKey)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := authMethod(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 22
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	dialer := net.Dialer{Timeout: timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	_ = nc.SetDeadline(time.Now().Add(timeout))
+	sc, chans, reqs, err := ssh.NewClientConn(nc, addr, &ssh.ClientConfig{
+		User:            cfg.Username,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         timeout,
+	})
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", addr, err)
+	}
+	_ = nc.SetDeadline(time.Time{})
+	conn := ssh.NewClient(sc, chans, reqs)
+
+	c, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("start sftp subsystem: %w", err)
+	}
+	return &Client{Client: c, conn: conn}, nil
+}
+
+// authMethod signs in with a private key when credentials hold one, else
+// with them as the password.
+func authMethod(credentials string) (ssh.AuthMethod, error) {
+	if strings.Contains(credentials, "PRIVATE KEY-----") {
+		signer, err := ssh.ParsePrivateKey([]byte(credentials))
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		return ssh.PublicKeys(signer), nil
+	}
+	return ssh.Password(credentials), nil
+}
+
+// Close ends the session and the connection. Closing a client that is
+// serving a request makes the request fail, which is how callers cancel.
+func (c *Client) Close() error {
+	_ = c.Client.Close()
+	return c.conn.Close()
+}
+
+// CloseWhenDone closes the client when ctx is done, and returns a function
+// that stops waiting for it.
+func (c *Client) CloseWhenDone(ctx context.Context) (stop func() bool) {
+	return context.AfterFunc(ctx, func() { _ = c.Close() })
+}
+
+// ReadFile returns the content of the file at name.
+func (c *Client) ReadFile(name string) ([]byte, error) {
+	f, err := c.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile creates or truncates the file at name and writes data to it.
+func (c *Client) WriteFile(name string, data []byte) error {
+	f, err := c.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Rename moves from to to, replacing an existing target. Servers with the
+// posix-rename extension do so in one step; on others the target is removed
+// first.
+func (c *Client) Rename(from, to string) error {
+	if _, ok := c.HasExtension(posixRename); ok {
+		return c.PosixRename(from, to)
+	}
+	if err := c.Remove(to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return c.Client.Rename(from, to)
+}
@@ -0,0 +1,208 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer serves SFTP over SSH from a temporary directory, accepting the
+// password "secret" for the user "partner".
+type testServer struct {
+	addr    string
+	hostKey string
+	root    string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "partner" && string(pass) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s := &testServer{addr: ln.Addr().String(), hostKey: string(ssh.MarshalAuthorizedKey(signer.PublicKey())), root: t.TempDir()}
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(nc, cfg)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serveConn(nc net.Conn, cfg *ssh.ServerConfig) {
+	defer nc.Close()
+	_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			_ = nch.Reject(ssh.UnknownChannelType, "sessions only")
+			continue
+		}
+		ch, requests, err := nch.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					go func() {
+						defer ch.Close()
+						if srv, err := sftp.NewServer(ch, sftp.WithServerWorkingDirectory(s.root)); err == nil {
+							_ = srv.Serve()
+						}
+					}()
+				}
+			}
+		}()
+	}
+}
+
+func (s *testServer) config() Config {
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	return Config{Host: host, Port: p, Username: "partner", HostKey: s.hostKey, Credentials: "secret"}
+}
+
+func TestDialVerifiesTheHostKeyAndCredentials(t *testing.T) {
+	srv := newTestServer(t)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _ := ssh.NewPublicKey(other)
+
+	cfg := srv.config()
+	cfg.HostKey = string(ssh.MarshalAuthorizedKey(otherKey))
+	if _, err := Dial(context.Background(), cfg); err == nil {
+		t.Fatal("expected a server with another host key refused")
+	}
+	cfg = srv.config()
+	cfg.Credentials = "wrong"
+	if _, err := Dial(context.Background(), cfg); err == nil {
+		t.Fatal("expected wrong credentials refused")
+	}
+	cfg.HostKey = "not a key"
+	if _, err := Dial(context.Background(), cfg); err == nil {
+		t.Fatal("expected an unparsable host key refused")
+	}
+}
+
+func TestClientTransfersFiles(t *testing.T) {
+	srv := newTestServer(t)
+	c, err := Dial(context.Background(), srv.config())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.MkdirAll("outbound/daily"); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := c.MkdirAll("outbound/daily"); err != nil {
+		t.Fatalf("expected an existing directory accepted, got %v", err)
+	}
+	// Larger than one chunk, so reads and writes take several requests.
+	body := bytes.Repeat([]byte("0123456789abcdef"), 5000)
+	if err := c.WriteFile("outbound/daily/jobs.txt.part", body); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := c.Rename("outbound/daily/jobs.txt.part", "outbound/daily/jobs.txt"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	got, err := c.ReadFile("outbound/daily/jobs.txt")
+	if err != nil || !bytes.Equal(got, body) {
+		t.Fatalf("read back %d bytes (%v), want %d", len(got), err, len(body))
+	}
+
+	entries, err := c.ReadDir("outbound/daily")
+	if err != nil || len(entries) != 1 || entries[0].Name() != "jobs.txt" || entries[0].Size() != int64(len(body)) || entries[0].IsDir() {
+		t.Fatalf("unexpected listing %+v (%v)", entries, err)
+	}
+	if e, err := c.Stat("outbound"); err != nil || !e.IsDir() {
+		t.Fatalf("expected a directory, got %+v (%v)", e, err)
+	}
+	if _, err := c.Stat("outbound/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a missing file reported as not existing, got %v", err)
+	}
+	if _, err := c.ReadDir("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a missing directory reported as not existing, got %v", err)
+	}
+
+	// Renames replace an existing target.
+	for i := 0; i < 2; i++ {
+		if err := c.WriteFile("outbound/daily/jobs.txt.part", []byte("v2")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := c.Rename("outbound/daily/jobs.txt.part", "outbound/daily/jobs.txt"); err != nil {
+			t.Fatalf("rename over an existing file: %v", err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(srv.root, "outbound", "daily", "jobs.txt")); string(got) != "v2" {
+			t.Fatalf("expected the file replaced, got %q", got)
+		}
+	}
+
+	if err := c.Remove("outbound/daily/jobs.txt"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if entries, err := c.ReadDir("outbound/daily"); err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty directory, got %+v (%v)", entries, err)
+	}
+}
+
+func TestCloseWhenDoneCancels(t *testing.T) {
+	srv := newTestServer(t)
+	c, err := Dial(context.Background(), srv.config())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer c.CloseWhenDone(ctx)()
+	cancel()
+	// AfterFunc runs in its own goroutine; the closed connection fails the
+	// next request once it has.
+	for i := 0; i < 1000; i++ {
+		if _, err := c.Stat("."); err != nil {
+			return
+		}
+	}
+	t.Fatal("expected requests to fail once the context is done")
+}
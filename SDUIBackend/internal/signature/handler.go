@@ -0,0 +1,65 @@
+package signature
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+)
+
+// maxBodyBytes bounds a signature upload; base64 PNGs and stroke data of
+// the sizes accepted fit well within it.
+const maxBodyBytes = 8 << 20
+
+// Handler exposes signature capture to the app.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a signature handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// UploadSignature stores the signature of {jobId}, sent as JSON with a
+// base64 PNG in "png" or vector data in "strokes", and "signerName".
+func (h *Handler) UploadSignature(w http.ResponseWriter, r *http.Request) {
+	var payload Upload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&payload); err != nil {
+		var tooBig *http.MaxBytesError
+		status := http.StatusBadRequest
+		if errors.As(err, &tooBig) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		respond.Error(w, status, "invalid payload", err.Error())
+		return
+	}
+	sig, err := h.service.Save(r.Context(), chi.URLParam(r, "jobId"), auth.TechnicianID(r), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save signature", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, sig)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, photo.ErrTooLarge):
+		respond.Error(w, http.StatusRequestEntityTooLarge, title, err.Error())
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, photo.ErrInvalidPhoto):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,92 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// Service stores signatures and links them to their jobs.
+type Service struct {
+	repos  repository.Repository
+	photos *photo.Service
+	logger *slog.Logger
+}
+
+// NewService wires a signature service. Signature images are stored with
+// photos, in its blob store.
+func NewService(repos repository.Repository, photos *photo.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{repos: repos, photos: photos, logger: logger}
+}
+
+// Save stores a job's signature, replacing any it had. technicianID is who
+// collected it.
+func (s *Service) Save(ctx context.Context, jobID, technicianID string, u Upload) (Signature, error) {
+	data, err := u.Image()
+	if err != nil {
+		return Signature{}, err
+	}
+	repos := tenant.Repository(ctx, s.repos)
+	job, err := s.job(repos, jobID)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	blob, err := s.photos.PutBlob(ctx, "image/png", bytes.NewReader(data))
+	if err != nil {
+		return Signature{}, err
+	}
+	req := photo.AnnotateRequest{JobID: job.ID, TechnicianID: technicianID, Kind: photo.KindSignature}
+	if u.SignerName != "" {
+		req.Notes = "Signed by " + u.SignerName
+	}
+	meta, err := s.photos.Attach("", req, blob)
+	if err != nil {
+		return Signature{}, err
+	}
+	// Saving the job again sends it, with its signature, to devices.
+	job.SignatureID = meta.ID
+	if err := repos.Sync.SaveJobUpload(job); err != nil {
+		return Signature{}, fmt.Errorf("link signature to job %s: %w", job.ID, err)
+	}
+	s.logger.Info("signature saved", slog.String("job", job.ID), slog.String("photo", meta.ID))
+
+	sig := Signature{JobID: job.ID, PhotoID: meta.ID, SignerName: u.SignerName}
+	if url, _, err := s.photos.URL(ctx, meta.ID); err != nil {
+		// The signature is saved; the device can sign a URL later.
+		s.logger.Warn("failed to sign signature url", slog.String("photo", meta.ID), slog.Any("error", err))
+	} else {
+		sig.URL = url
+	}
+	return sig, nil
+}
+
+// job returns the latest version of a job.
+func (s *Service) job(repos repository.Repository, id string) (models.JobUpload, error) {
+	jobs, err := repos.Sync.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return models.JobUpload{}, err
+	}
+	var job models.JobUpload
+	found := false
+	for _, j := range jobs {
+		if j.ID == id {
+			job, found = j, true
+		}
+	}
+	if !found {
+		return models.JobUpload{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return job, nil
+}
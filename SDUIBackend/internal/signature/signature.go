@@ -0,0 +1,191 @@
+// Package signature stores the customer signatures the app captures when a
+// job is completed. Apps send either a PNG or the strokes drawn on the
+// signature pad, which are rasterized to a PNG. Signatures are stored as
+// photos of kind signature, so service reports include them, and linked
+// to their job so devices are sent them with job updates.
+package signature
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the job being signed does not exist.
+	ErrNotFound = errors.New("job not found")
+	// ErrInvalidSignature wraps signature validation failures.
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Bounds on uploaded signatures. Signature pads are a few hundred points
+// wide; anything far larger is not a signature.
+const (
+	maxSide      = 2000 // pixels of a PNG, or points of a stroke canvas
+	maxPoints    = 20000
+	maxSignerLen = 200
+	// strokeScale renders strokes at twice their canvas size, so they stay
+	// sharp on retina screens and in reports.
+	strokeScale = 2
+	// strokeWidth is the pen width in canvas points.
+	strokeWidth = 2.0
+)
+
+// Upload is a captured signature: a PNG or strokes, not both.
+type Upload struct {
+	// PNG is a base64 PNG image, optionally as a data:image/png URL.
+	PNG string `json:"png,omitempty"`
+	// Strokes is the signature as drawn on the pad.
+	Strokes *Strokes `json:"strokes,omitempty"`
+	// SignerName is who signed, such as the customer's printed name.
+	SignerName string `json:"signerName,omitempty"`
+}
+
+// Strokes is vector signature data: lines of points on a Width by Height
+// canvas with its origin at the top left.
+type Strokes struct {
+	Width  float64   `json:"width"`
+	Height float64   `json:"height"`
+	Lines  [][]Point `json:"lines"`
+}
+
+// Point is a point of a stroke.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Signature is a stored signature.
+type Signature struct {
+	JobID      string `json:"jobId"`
+	PhotoID    string `json:"photoId"`
+	SignerName string `json:"signerName,omitempty"`
+	// URL is a signed URL of the image; GET /v1/photos/{photoId}/url
+	// signs a fresh one.
+	URL string `json:"url,omitempty"`
+}
+
+// Image returns the signature as PNG data.
+func (u Upload) Image() ([]byte, error) {
+	var problems []string
+	switch {
+	case u.PNG == "" && u.Strokes == nil:
+		problems = append(problems, "png or strokes is required")
+	case u.PNG != "" && u.Strokes != nil:
+		problems = append(problems, "send png or strokes, not both")
+	}
+	if len(u.SignerName) > maxSignerLen {
+		problems = append(problems, fmt.Sprintf("signerName must be at most %d characters", maxSignerLen))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, strings.Join(problems, "; "))
+	}
+	if u.Strokes != nil {
+		return u.Strokes.render()
+	}
+	return decodePNG(u.PNG)
+}
+
+// decodePNG decodes a base64 PNG and checks it is one of reasonable size.
+func decodePNG(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if rest, ok := strings.CutPrefix(encoded, "data:"); ok {
+		mediaType, data, ok := strings.Cut(rest, ",")
+		if !ok || !strings.EqualFold(mediaType, "image/png;base64") {
+			return nil, fmt.Errorf("%w: png must be a base64 data:image/png URL", ErrInvalidSignature)
+		}
+		encoded = data
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: png is not base64: %v", ErrInvalidSignature, err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: png is not a PNG image", ErrInvalidSignature)
+	}
+	if cfg.Width > maxSide || cfg.Height > maxSide {
+		return nil, fmt.Errorf("%w: png must be at most %dx%d pixels", ErrInvalidSignature, maxSide, maxSide)
+	}
+	return data, nil
+}
+
+// validate checks strokes can be rendered.
+func (s Strokes) validate() error {
+	var problems []string
+	if s.Width <= 0 || s.Height <= 0 || s.Width > maxSide || s.Height > maxSide {
+		problems = append(problems, fmt.Sprintf("strokes width and height must be between 1 and %d", maxSide))
+	}
+	points := 0
+	for _, line := range s.Lines {
+		points += len(line)
+	}
+	switch {
+	case points == 0:
+		problems = append(problems, "strokes has no points")
+	case points > maxPoints:
+		problems = append(problems, fmt.Sprintf("strokes must have at most %d points", maxPoints))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// render draws strokes in black on a transparent PNG. Points outside the
+// canvas are clipped.
+func (s Strokes) render() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	w, h := int(math.Ceil(s.Width*strokeScale)), int(math.Ceil(s.Height*strokeScale))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	radius := strokeWidth * strokeScale / 2
+	for _, line := range s.Lines {
+		for i, p := range line {
+			from := p
+			if i > 0 {
+				from = line[i-1]
+			}
+			segment(img, from.X*strokeScale, from.Y*strokeScale, p.X*strokeScale, p.Y*strokeScale, radius)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// segment draws a line of the given radius with round ends by stamping
+// discs along it.
+func segment(img *image.NRGBA, x0, y0, x1, y1, radius float64) {
+	steps := int(math.Ceil(math.Hypot(x1-x0, y1-y0) / (radius / 2)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		disc(img, x0+(x1-x0)*t, y0+(y1-y0)*t, radius)
+	}
+}
+
+func disc(img *image.NRGBA, cx, cy, radius float64) {
+	b := img.Bounds()
+	for y := int(cy - radius); y <= int(cy+radius); y++ {
+		for x := int(cx - radius); x <= int(cx+radius); x++ {
+			if !(image.Point{X: x, Y: y}).In(b) {
+				continue
+			}
+			if math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) <= radius {
+				img.SetNRGBA(x, y, color.NRGBA{A: 255})
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/storage"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+	syncapi "github.com/your-org/pestgenie-sdui/internal/sync"
+)
+
+func setup(t *testing.T) (*storememory.Store, *photo.Service, http.Handler) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	if err := store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "sam", CustomerName: "Miller Farms", Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := storage.NewLocal(t.TempDir(), "http://api.test"+storage.LocalPathPrefix, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photos := photo.NewService(config.PhotoConfig{MaxBytes: 1 << 20, AllowedTypes: []string{"image/png"}, URLTTL: time.Minute}, photo.NewMemoryStore(), blobs, nil)
+	r := chi.NewRouter()
+	r.Post("/jobs/{jobId}/signature", NewHandler(NewService(repos, photos, nil)).UploadSignature)
+	r.Get("/updates", syncapi.NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, nil, photos, nil).GetUpdates)
+	return store, photos, r
+}
+
+func do(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestStrokeSignature(t *testing.T) {
+	store, photos, r := setup(t)
+	rec := do(r, http.MethodPost, "/jobs/job-1/signature", `{"signerName": "Pat Miller", "strokes": {"width": 300, "height": 100, "lines": [[{"x": 10, "y": 50}, {"x": 150, "y": 20}, {"x": 290, "y": 80}], [{"x": 40, "y": 90}]]}}`)
+	var sig Signature
+	if err := json.Unmarshal(rec.Body.Bytes(), &sig); err != nil || rec.Code != http.StatusCreated || sig.PhotoID == "" || !strings.HasPrefix(sig.URL, "http://api.test") {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+
+	meta, err := photos.Photo(sig.PhotoID)
+	if err != nil || meta.Kind != photo.KindSignature || meta.JobID != "job-1" || meta.Annotation.Notes != "Signed by Pat Miller" {
+		t.Fatalf("expected a signature photo of the job, got %+v (%v)", meta, err)
+	}
+	body, err := photos.Open(context.Background(), sig.PhotoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	img, err := png.Decode(body)
+	if err != nil || img.Bounds() != image.Rect(0, 0, 600, 200) {
+		t.Fatalf("expected the strokes rendered at twice their size, got %v (%v)", img.Bounds(), err)
+	}
+	if _, _, _, a := img.At(20, 100).RGBA(); a == 0 {
+		t.Error("expected ink at the start of the first stroke")
+	}
+	if _, _, _, a := img.At(580, 20).RGBA(); a != 0 {
+		t.Error("expected no ink away from the strokes")
+	}
+
+	// Devices re-upload the job without its signature.
+	if err := store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "sam", CustomerName: "Miller Farms", Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	rec = do(r, http.MethodGet, "/updates", "")
+	var updates transport.ServerUpdates
+	if err := json.Unmarshal(rec.Body.Bytes(), &updates); err != nil || len(updates.Jobs) != 1 {
+		t.Fatalf("unexpected updates %d: %s", rec.Code, rec.Body)
+	}
+	if job := updates.Jobs[0]; job.SignatureID != sig.PhotoID || !strings.HasPrefix(job.SignatureURL, "http://api.test") {
+		t.Errorf("expected the job sent with its signature, got %+v", job)
+	}
+}
+
+func TestPNGSignature(t *testing.T) {
+	_, photos, r := setup(t)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	for _, value := range []string{encoded, "data:image/png;base64," + encoded} {
+		rec := do(r, http.MethodPost, "/jobs/job-1/signature", `{"png": "`+value+`"}`)
+		var sig Signature
+		if err := json.Unmarshal(rec.Body.Bytes(), &sig); err != nil || rec.Code != http.StatusCreated {
+			t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+		}
+		body, err := photos.Open(context.Background(), sig.PhotoID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := io.ReadAll(body)
+		body.Close()
+		if !bytes.Equal(stored, buf.Bytes()) {
+			t.Error("expected the PNG stored as sent")
+		}
+	}
+}
+
+func TestRejectsBadSignatures(t *testing.T) {
+	_, _, r := setup(t)
+	for name, tc := range map[string]struct {
+		path, body string
+		status     int
+	}{
+		"unknown job":  {"/jobs/nope/signature", `{"strokes": {"width": 10, "height": 10, "lines": [[{"x": 1, "y": 1}]]}}`, http.StatusNotFound},
+		"empty":        {"/jobs/job-1/signature", `{}`, http.StatusBadRequest},
+		"both":         {"/jobs/job-1/signature", `{"png": "iVBORw0KGgo=", "strokes": {"width": 10, "height": 10, "lines": [[{"x": 1, "y": 1}]]}}`, http.StatusBadRequest},
+		"not base64":   {"/jobs/job-1/signature", `{"png": "%%%"}`, http.StatusBadRequest},
+		"not a png":    {"/jobs/job-1/signature", `{"png": "` + base64.StdEncoding.EncodeToString([]byte("GIF89a")) + `"}`, http.StatusBadRequest},
+		"no points":    {"/jobs/job-1/signature", `{"strokes": {"width": 10, "height": 10, "lines": [[]]}}`, http.StatusBadRequest},
+		"huge canvas":  {"/jobs/job-1/signature", `{"strokes": {"width": 100000, "height": 10, "lines": [[{"x": 1, "y": 1}]]}}`, http.StatusBadRequest},
+		"invalid json": {"/jobs/job-1/signature", `{"png":`, http.StatusBadRequest},
+	} {
+		if rec := do(r, http.MethodPost, tc.path, tc.body); rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d %s", name, tc.status, rec.Code, rec.Body)
+		}
+	}
+	if _, err := (Upload{Strokes: &Strokes{Width: 1, Height: 1}}).Image(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package simulate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// PreviewHeader marks a response as a simulated render, naming its audit
+// record, so it is never mistaken for a technician's own screen.
+const PreviewHeader = "X-PestGenie-Preview"
+
+// Handler exposes screen simulation to template authors.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a simulation handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListSimulations)
+	r.Post("/", h.Simulate)
+	r.Get("/{simulationId}", h.GetSimulation)
+}
+
+// Simulate renders a screen as the technician in the body.
+func (h *Handler) Simulate(w http.ResponseWriter, r *http.Request) {
+	var payload Request
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	// An authenticated author simulates as themselves.
+	if id, ok := auth.FromContext(r.Context()); ok {
+		payload.AuthorID = id.Subject
+	}
+	result, err := h.service.Simulate(r.Context(), payload)
+	if err != nil {
+		h.fail(w, r, "failed to simulate screen", err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(PreviewHeader, result.Simulation.ID)
+	respond.JSON(w, http.StatusOK, result)
+}
+
+// ListSimulations returns the audit trail, optionally filtered by
+// ?authorId=, ?technicianId= and ?screenId=.
+func (h *Handler) ListSimulations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	simulations, err := h.service.Simulations(Filter{AuthorID: q.Get("authorId"), TechnicianID: q.Get("technicianId"), ScreenID: q.Get("screenId")})
+	if err != nil {
+		h.fail(w, r, "failed to list simulations", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"simulations": simulations})
+}
+
+// GetSimulation returns an audit record.
+func (h *Handler) GetSimulation(w http.ResponseWriter, r *http.Request) {
+	sim, err := h.service.Simulation(chi.URLParam(r, "simulationId"))
+	if err != nil {
+		h.fail(w, r, "failed to fetch simulation", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, sim)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidRequest):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
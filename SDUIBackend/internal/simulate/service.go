@@ -0,0 +1,97 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+)
+
+// Renderer renders a technician's screen without serving it;
+// *sdui.Service implements it.
+type Renderer interface {
+	Simulate(ctx context.Context, req sdui.SimulateRequest) (sdui.SimulateResult, error)
+}
+
+// Service runs and audits simulations.
+type Service struct {
+	store    Store
+	renderer Renderer
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService wires a simulation service rendering with renderer.
+func NewService(store Store, renderer Renderer, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, renderer: renderer, logger: logger, now: time.Now}
+}
+
+// Result is a simulated screen and its audit record.
+type Result struct {
+	Simulation Simulation `json:"simulation"`
+	sdui.SimulateResult
+}
+
+// Simulate renders the requested screen as the technician and records it
+// in the audit trail. A render that cannot be audited is not returned.
+func (s *Service) Simulate(ctx context.Context, req Request) (Result, error) {
+	if err := req.Validate(); err != nil {
+		return Result{}, err
+	}
+	rendered, err := s.renderer.Simulate(ctx, req.SimulateRequest)
+	switch {
+	case errors.Is(err, sdui.ErrTechnicianNotFound):
+		return Result{}, fmt.Errorf("%w: technician %q", ErrNotFound, req.TechnicianID)
+	case errors.Is(err, sdui.ErrInvalidTemplate):
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	case err != nil:
+		return Result{}, err
+	}
+
+	sim := Simulation{
+		ID:           uuid.NewString(),
+		AuthorID:     req.AuthorID,
+		TechnicianID: req.TechnicianID,
+		ScreenID:     req.ScreenID,
+		Reason:       strings.TrimSpace(req.Reason),
+		ServiceDate:  req.ServiceDate,
+		DeviceModel:  req.DeviceModel,
+		AppVersion:   req.AppVersion,
+		Locale:       req.Locale,
+		Flags:        req.Flags,
+		Experiment:   rendered.Experiment,
+		Problems:     len(rendered.Problems),
+		At:           s.now().UTC(),
+	}
+	if err := s.store.SaveSimulation(sim); err != nil {
+		return Result{}, fmt.Errorf("audit simulation: %w", err)
+	}
+	s.logger.Warn("screen simulated",
+		slog.String("simulation", sim.ID),
+		slog.String("author", sim.AuthorID),
+		slog.String("technician", sim.TechnicianID),
+		slog.String("screen", sim.ScreenID),
+		slog.String("reason", sim.Reason),
+	)
+	return Result{Simulation: sim, SimulateResult: rendered}, nil
+}
+
+// Simulation returns a single audit record.
+func (s *Service) Simulation(id string) (Simulation, error) {
+	return s.store.GetSimulation(id)
+}
+
+// Simulations lists the audit trail, newest first.
+func (s *Service) Simulations(f Filter) ([]Simulation, error) {
+	return s.store.ListSimulations(f)
+}
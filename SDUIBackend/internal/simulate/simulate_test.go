@@ -0,0 +1,105 @@
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+const homeV1 = `{"version":1,"component":{"type":"vstack","children":[{"type":"text","text":"Hi {{technician.name}}, {{route.label}}"},{"type":"text","text":"{{metadata.beta}}"}]}}`
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	store := storememory.NewStore()
+	store.AddTechnician(domain.Technician{ID: "tech-1", DisplayName: "Sam", Region: "north"})
+	day := time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)
+	if err := store.SaveRoute(domain.Route{ID: "r-7", TechnicianID: "tech-1", ServiceDate: day}); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	screens := sdui.NewService("", config.ScreenConfig{UnresolvedPlaceholders: sdui.UnresolvedKeep}, repos, brownout.NewMonitor(config.BrownoutConfig{}), time.Minute, nil, nil, nil, nil)
+	if _, err := screens.CreateTemplate("home", []byte(homeV1)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	svc := NewService(NewMemoryStore(), screens, nil)
+	svc.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestSimulate(t *testing.T) {
+	svc := newTestService(t)
+	req := Request{
+		SimulateRequest: sdui.SimulateRequest{
+			ScreenID:     "home",
+			TechnicianID: "tech-1",
+			ServiceDate:  time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC),
+			Flags:        map[string]string{"beta": "on"},
+		},
+		AuthorID: "author-1",
+	}
+	if _, err := svc.Simulate(context.Background(), req); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected a simulation without a reason refused, got %v", err)
+	}
+	req.Reason = "checking the route label"
+	result, err := svc.Simulate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	screen, _ := json.Marshal(result.Screen)
+	if !strings.Contains(string(screen), "Hi Sam, Route r-7") || !strings.Contains(string(screen), `"on"`) {
+		t.Fatalf("expected the technician's route and the flag rendered, got %s", screen)
+	}
+	if result.Simulation.ID == "" || result.Simulation.Reason != "checking the route label" {
+		t.Fatalf("unexpected audit record %+v", result.Simulation)
+	}
+
+	req.TechnicianID = "nobody"
+	if _, err := svc.Simulate(context.Background(), req); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected an unknown technician reported, got %v", err)
+	}
+	sims, _ := svc.Simulations(Filter{TechnicianID: "tech-1"})
+	if len(sims) != 1 || sims[0].AuthorID != "author-1" || sims[0].Flags["beta"] != "on" {
+		t.Fatalf("expected only the rendered simulation audited, got %+v", sims)
+	}
+}
+
+func TestSimulateHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Route("/simulations", NewHandler(newTestService(t)).Routes)
+
+	body := `{"screenId":"home","technicianId":"tech-1","authorId":"author-1","reason":"review","locale":"es"}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/simulations", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || rec.Header().Get(PreviewHeader) == "" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected a marked, uncached render, got %d %v: %s", rec.Code, rec.Header(), rec.Body)
+	}
+	var result Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Simulation.Locale != "es" {
+		t.Fatalf("unexpected result %s (%v)", rec.Body, err)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/simulations/"+result.Simulation.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the audit record, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/simulations?authorId=someone-else", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"simulations":[]`) {
+		t.Fatalf("expected no simulations by another author, got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,149 @@
+// Package simulate lets template authors render a screen as a particular
+// technician would see it, on a date, device, build and locale of their
+// choosing and with extra flags, to check personalization edge cases.
+// Simulations read the technician's real data without the technician's
+// ownership checks, so each one states a reason and is audited.
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/experiment"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+)
+
+var (
+	// ErrNotFound is returned when a technician or simulation does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidRequest wraps simulation request validation failures.
+	ErrInvalidRequest = errors.New("invalid simulation request")
+)
+
+// maxFlags bounds the flags a simulation can set.
+const maxFlags = 32
+
+// Request asks to render a screen as a technician.
+type Request struct {
+	sdui.SimulateRequest
+	AuthorID string `json:"authorId"`
+	Reason   string `json:"reason"` // e.g. the template change being checked
+}
+
+// Validate checks a simulation request.
+func (r Request) Validate() error {
+	var problems []string
+	if r.AuthorID == "" {
+		problems = append(problems, "authorId is required")
+	}
+	if r.ScreenID == "" {
+		problems = append(problems, "screenId is required")
+	}
+	if r.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		problems = append(problems, "reason is required")
+	}
+	if len(r.Flags) > maxFlags {
+		problems = append(problems, fmt.Sprintf("at most %d flags are allowed", maxFlags))
+	}
+	for key := range r.Flags {
+		if strings.TrimSpace(key) == "" {
+			problems = append(problems, "flag names must not be empty")
+			break
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidRequest, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Simulation is the audit record of one simulated render.
+type Simulation struct {
+	ID           string            `json:"id"`
+	AuthorID     string            `json:"authorId"`
+	TechnicianID string            `json:"technicianId"`
+	ScreenID     string            `json:"screenId"`
+	Reason       string            `json:"reason"`
+	ServiceDate  time.Time         `json:"serviceDate,omitempty"`
+	DeviceModel  string            `json:"deviceModel,omitempty"`
+	AppVersion   string            `json:"appVersion,omitempty"`
+	Locale       string            `json:"locale,omitempty"`
+	Flags        map[string]string `json:"flags,omitempty"`
+	// Experiment is the variant rendered, when the technician is enrolled.
+	Experiment *experiment.Assignment `json:"experiment,omitempty"`
+	Problems   int                    `json:"problems"`
+	At         time.Time              `json:"at"`
+}
+
+// Filter narrows a simulation listing; empty fields match everything.
+type Filter struct {
+	AuthorID     string
+	TechnicianID string
+	ScreenID     string
+}
+
+func (f Filter) matches(s Simulation) bool {
+	return (f.AuthorID == "" || s.AuthorID == f.AuthorID) &&
+		(f.TechnicianID == "" || s.TechnicianID == f.TechnicianID) &&
+		(f.ScreenID == "" || s.ScreenID == f.ScreenID)
+}
+
+// Store persists the simulation audit trail.
+type Store interface {
+	SaveSimulation(s Simulation) error
+	GetSimulation(id string) (Simulation, error)
+	// ListSimulations returns the simulations f matches, newest first.
+	ListSimulations(f Filter) ([]Simulation, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	simulations map[string]Simulation
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{simulations: make(map[string]Simulation)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveSimulation(s Simulation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.simulations[s.ID] = s
+	return nil
+}
+
+func (m *MemoryStore) GetSimulation(id string) (Simulation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.simulations[id]
+	if !ok {
+		return Simulation{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *MemoryStore) ListSimulations(f Filter) ([]Simulation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Simulation{}
+	for _, s := range m.simulations {
+		if f.matches(s) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	return out, nil
+}
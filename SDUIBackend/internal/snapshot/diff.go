@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// maxDiffs is the most differences listed per check; a template change
+// near the root would otherwise list the whole screen.
+const maxDiffs = 50
+
+// canonical encodes v with its object keys sorted, so equal screens encode
+// to equal bytes.
+func canonical(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	generic, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// differ collects the differences between two decoded screens.
+type differ struct {
+	diffs     []Diff
+	truncated bool
+}
+
+func (d *differ) add(diff Diff) {
+	if len(d.diffs) >= maxDiffs {
+		d.truncated = true
+		return
+	}
+	d.diffs = append(d.diffs, diff)
+}
+
+func (d *differ) compare(path string, golden, render any) {
+	switch g := golden.(type) {
+	case map[string]any:
+		r, ok := render.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(g)+len(r))
+		for k := range g {
+			keys = append(keys, k)
+		}
+		for k := range r {
+			if _, ok := g[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			gv, inGolden := g[k]
+			rv, inRender := r[k]
+			switch {
+			case !inRender:
+				d.add(Diff{Path: join(path, k), Kind: "removed", Golden: gv})
+			case !inGolden:
+				d.add(Diff{Path: join(path, k), Kind: "added", Render: rv})
+			default:
+				d.compare(join(path, k), gv, rv)
+			}
+		}
+		return
+	case []any:
+		r, ok := render.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(g), len(r)); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(r):
+				d.add(Diff{Path: p, Kind: "removed", Golden: g[i]})
+			case i >= len(g):
+				d.add(Diff{Path: p, Kind: "added", Render: r[i]})
+			default:
+				d.compare(p, g[i], r[i])
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(golden, render) {
+		d.add(Diff{Path: path, Kind: "changed", Golden: golden, Render: render})
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// diff lists the structural differences between two canonical screens,
+// reporting whether there were more than maxDiffs.
+func diff(golden, render json.RawMessage) ([]Diff, bool, error) {
+	g, err := decode(golden)
+	if err != nil {
+		return nil, false, err
+	}
+	r, err := decode(render)
+	if err != nil {
+		return nil, false, err
+	}
+	var d differ
+	d.compare("", g, r)
+	return d.diffs, d.truncated, nil
+}
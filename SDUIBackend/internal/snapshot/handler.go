@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes snapshot recording and verification to template authors.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a snapshot handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListGoldens)
+	r.Post("/record", h.Record)
+	r.Post("/verify", h.Verify)
+	r.Get("/cases", h.ListCases)
+	r.Get("/cases/{caseName}", h.GetCase)
+	r.Put("/cases/{caseName}", h.SaveCase)
+	r.Delete("/cases/{caseName}", h.DeleteCase)
+}
+
+// ListGoldens returns the recorded goldens, optionally of one ?screenId=.
+func (h *Handler) ListGoldens(w http.ResponseWriter, r *http.Request) {
+	goldens, err := h.service.Goldens(r.URL.Query().Get("screenId"))
+	if err != nil {
+		h.fail(w, r, "failed to list snapshots", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"snapshots": goldens})
+}
+
+// Record renders the published screens against every case and stores the
+// renders as goldens.
+func (h *Handler) Record(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.service.Record(req)
+	if err != nil {
+		h.fail(w, r, "failed to record snapshots", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// Verify compares fresh renders, of candidate templates when the body has
+// some, with the goldens. A report that did not pass is still a 200; check
+// its passed field.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.service.Verify(req)
+	if err != nil {
+		h.fail(w, r, "failed to verify snapshots", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// ListCases returns the canned contexts.
+func (h *Handler) ListCases(w http.ResponseWriter, r *http.Request) {
+	cases, err := h.service.Cases()
+	if err != nil {
+		h.fail(w, r, "failed to list snapshot cases", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"cases": cases})
+}
+
+// GetCase returns a canned context.
+func (h *Handler) GetCase(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Case(chi.URLParam(r, "caseName"))
+	if err != nil {
+		h.fail(w, r, "failed to load snapshot case", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// SaveCase creates or replaces a canned context.
+func (h *Handler) SaveCase(w http.ResponseWriter, r *http.Request) {
+	var payload Case
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	c, err := h.service.SaveCase(chi.URLParam(r, "caseName"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save snapshot case", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, c)
+}
+
+// DeleteCase removes a canned context and its goldens.
+func (h *Handler) DeleteCase(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteCase(chi.URLParam(r, "caseName")); err != nil {
+		h.fail(w, r, "failed to delete snapshot case", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRequest reads an optional Request body.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return Request{}, false
+	}
+	return req, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrCaseNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidCase), errors.Is(err, ErrInvalidRequest):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,248 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+)
+
+// Renderer renders screens without serving or storing them; *sdui.Service
+// implements it.
+type Renderer interface {
+	Templates() ([]sdui.TemplateSummary, error)
+	Template(screenID string, version int) (sdui.TemplateVersion, error)
+	Preview(req sdui.PreviewRequest) (sdui.PreviewResult, error)
+}
+
+// Service records and verifies screen snapshots.
+type Service struct {
+	store    Store
+	renderer Renderer
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService wires a snapshot service rendering with renderer.
+func NewService(store Store, renderer Renderer, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, renderer: renderer, logger: logger, now: time.Now}
+}
+
+// SaveCase creates or replaces a canned context. Goldens recorded against
+// the case before are kept until recorded again.
+func (s *Service) SaveCase(name string, c Case) (Case, error) {
+	c.Name = name
+	if err := c.validate(); err != nil {
+		return Case{}, err
+	}
+	c.UpdatedAt = s.now().UTC()
+	if err := s.store.SaveCase(c); err != nil {
+		return Case{}, err
+	}
+	return c, nil
+}
+
+// Case returns a canned context.
+func (s *Service) Case(name string) (Case, error) {
+	return s.store.GetCase(name)
+}
+
+// Cases lists the canned contexts.
+func (s *Service) Cases() ([]Case, error) {
+	return s.store.ListCases()
+}
+
+// DeleteCase removes a canned context and its goldens.
+func (s *Service) DeleteCase(name string) error {
+	if err := s.store.DeleteCase(name); err != nil {
+		return err
+	}
+	return s.store.DeleteGoldens(name)
+}
+
+// Goldens lists the recorded goldens, of one screen when screenID is set.
+func (s *Service) Goldens(screenID string) ([]Golden, error) {
+	return s.store.ListGoldens(screenID)
+}
+
+// Request selects what to record or verify.
+type Request struct {
+	// ScreenIDs limits the run to these screens; empty means every
+	// published screen.
+	ScreenIDs []string `json:"screenIds,omitempty"`
+	// Templates are candidate templates, by screen ID, verified in place of
+	// the published ones. They are not recorded.
+	Templates map[string]json.RawMessage `json:"templates,omitempty"`
+}
+
+// Record renders each selected published screen against every case and
+// stores the renders as the new goldens. Renders that fail are reported and
+// leave their golden as it was.
+func (s *Service) Record(req Request) (Report, error) {
+	if len(req.Templates) > 0 {
+		return Report{}, fmt.Errorf("%w: candidate templates are verified, not recorded; publish them first", ErrInvalidRequest)
+	}
+	report, err := s.run(req, func(tpl target, c Case, render json.RawMessage, check *Check) error {
+		check.Status = StatusRecorded
+		return s.store.SaveGolden(Golden{ScreenID: tpl.screenID, Case: c.Name, TemplateVersion: tpl.version, Screen: render, RecordedAt: s.now().UTC()})
+	})
+	if err == nil {
+		s.logger.Info("snapshots recorded", slog.Int("recorded", report.Recorded), slog.Int("failed", report.Failed))
+	}
+	return report, err
+}
+
+// Verify renders each selected screen against every case, candidates from
+// the request in place of their published templates, and compares the
+// renders with the goldens.
+func (s *Service) Verify(req Request) (Report, error) {
+	goldens, err := s.store.ListGoldens("")
+	if err != nil {
+		return Report{}, err
+	}
+	byKey := make(map[string]Golden, len(goldens))
+	for _, g := range goldens {
+		byKey[g.ScreenID+"/"+g.Case] = g
+	}
+	return s.run(req, func(tpl target, c Case, render json.RawMessage, check *Check) error {
+		g, ok := byKey[tpl.screenID+"/"+c.Name]
+		if !ok {
+			check.Status = StatusNew
+			return nil
+		}
+		check.GoldenVersion = g.TemplateVersion
+		if bytes.Equal(g.Screen, render) {
+			check.Status = StatusMatch
+			return nil
+		}
+		diffs, truncated, err := diff(g.Screen, render)
+		if err != nil {
+			return fmt.Errorf("diff %s/%s: %w", tpl.screenID, c.Name, err)
+		}
+		check.Status, check.Diffs, check.Truncated = StatusChanged, diffs, truncated
+		if len(diffs) == 0 {
+			// Equal once decoded, e.g. numbers spelled differently.
+			check.Status = StatusMatch
+		}
+		return nil
+	})
+}
+
+// target is a template a run renders.
+type target struct {
+	screenID  string
+	version   int
+	payload   json.RawMessage
+	candidate bool
+}
+
+// run renders every target against every case, handing each render to
+// visit to fill in its check.
+func (s *Service) run(req Request, visit func(tpl target, c Case, render json.RawMessage, check *Check) error) (Report, error) {
+	cases, err := s.store.ListCases()
+	if err != nil {
+		return Report{}, err
+	}
+	if len(cases) == 0 {
+		return Report{}, fmt.Errorf("%w: no snapshot cases; add one first", ErrInvalidRequest)
+	}
+	targets, err := s.targets(req)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Checks: []Check{}, CheckedAt: s.now().UTC()}
+	for _, tpl := range targets {
+		for _, c := range cases {
+			check := Check{ScreenID: tpl.screenID, Case: c.Name, TemplateVersion: tpl.version, Candidate: tpl.candidate}
+			result, err := s.renderer.Preview(sdui.PreviewRequest{
+				ScreenID:    tpl.screenID,
+				Screen:      tpl.payload,
+				Context:     c.Context,
+				ServiceDate: c.ServiceDate,
+				DeviceModel: c.DeviceModel,
+				AppVersion:  c.AppVersion,
+				Locale:      c.Locale,
+			})
+			var render json.RawMessage
+			if err == nil {
+				check.Problems = result.Problems
+				render, err = canonical(result.Screen)
+			}
+			if err != nil {
+				check.Status, check.Error = StatusFailed, err.Error()
+			} else if err := visit(tpl, c, render, &check); err != nil {
+				return Report{}, err
+			}
+			report.add(check)
+		}
+	}
+	report.Passed = report.Changed == 0 && report.Failed == 0
+	return report, nil
+}
+
+// targets resolves the screens a request selects to the templates to
+// render.
+func (s *Service) targets(req Request) ([]target, error) {
+	ids := req.ScreenIDs
+	if len(ids) == 0 {
+		summaries, err := s.renderer.Templates()
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, sum := range summaries {
+			ids = append(ids, sum.ScreenID)
+			seen[sum.ScreenID] = true
+		}
+		for id := range req.Templates {
+			if !seen[id] {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	out := make([]target, 0, len(ids))
+	for _, id := range ids {
+		if payload, ok := req.Templates[id]; ok {
+			out = append(out, target{screenID: id, payload: payload, candidate: true})
+			continue
+		}
+		tpl, err := s.renderer.Template(id, 0)
+		if errors.Is(err, sdui.ErrTemplateNotFound) {
+			return nil, fmt.Errorf("%w: screen %q has no published template", ErrInvalidRequest, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, target{screenID: id, version: tpl.Version, payload: tpl.Screen})
+	}
+	return out, nil
+}
+
+func (r *Report) add(c Check) {
+	r.Checked++
+	switch c.Status {
+	case StatusRecorded:
+		r.Recorded++
+	case StatusMatch:
+		r.Matched++
+	case StatusChanged:
+		r.Changed++
+	case StatusNew:
+		r.New++
+	case StatusFailed:
+		r.Failed++
+	}
+	r.Checks = append(r.Checks, c)
+}
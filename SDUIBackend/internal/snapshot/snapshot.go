@@ -0,0 +1,220 @@
+// Package snapshot guards screens against unintended changes. Authors keep
+// a set of canned contexts, made-up technicians and routes; recording
+// renders every published screen against each of them and stores the
+// canonical JSON as a golden. Verifying renders them again, optionally with
+// candidate templates, and reports where each render differs from its
+// golden, so a change can be reviewed before it is rolled out.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+var (
+	// ErrCaseNotFound is returned when a canned context does not exist.
+	ErrCaseNotFound = errors.New("snapshot case not found")
+	// ErrInvalidCase wraps canned context validation failures.
+	ErrInvalidCase = errors.New("invalid snapshot case")
+	// ErrInvalidRequest wraps record and verify request failures.
+	ErrInvalidRequest = errors.New("invalid snapshot request")
+)
+
+// Check statuses.
+const (
+	StatusRecorded = "recorded" // the render was stored as the golden
+	StatusMatch    = "match"    // the render equals its golden
+	StatusChanged  = "changed"  // the render differs from its golden; see Diffs
+	StatusNew      = "new"      // there is no golden to compare with
+	StatusFailed   = "failed"   // the screen could not be rendered; see Error
+)
+
+// namePattern is what a case can be named.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Case is a canned context screens are rendered against.
+type Case struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Context     domain.ScreenContext `json:"context"`
+	// ServiceDate is required: placeholders such as the date would change
+	// from one render to the next without it.
+	ServiceDate time.Time `json:"serviceDate"`
+	DeviceModel string    `json:"deviceModel,omitempty"`
+	AppVersion  string    `json:"appVersion,omitempty"`
+	Locale      string    `json:"locale,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (c Case) validate() error {
+	if !namePattern.MatchString(c.Name) {
+		return fmt.Errorf("%w: name %q must be 1-64 lowercase letters, digits, - or _", ErrInvalidCase, c.Name)
+	}
+	if c.ServiceDate.IsZero() {
+		return fmt.Errorf("%w: serviceDate is required so renders are reproducible", ErrInvalidCase)
+	}
+	return nil
+}
+
+// Golden is the recorded render of a screen for a case.
+type Golden struct {
+	ScreenID string `json:"screenId"`
+	Case     string `json:"case"`
+	// TemplateVersion is the published version that was rendered.
+	TemplateVersion int             `json:"templateVersion"`
+	Screen          json.RawMessage `json:"screen"`
+	RecordedAt      time.Time       `json:"recordedAt"`
+}
+
+// Diff is one structural difference between a golden and a render. Path
+// locates it in the screen, e.g. component.children[2].text.
+type Diff struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"` // added, removed or changed
+	Golden any    `json:"golden,omitempty"`
+	Render any    `json:"render,omitempty"`
+}
+
+// Check is the recording or verification of one screen against one case.
+type Check struct {
+	ScreenID        string `json:"screenId"`
+	Case            string `json:"case"`
+	Status          string `json:"status"`
+	GoldenVersion   int    `json:"goldenVersion,omitempty"`
+	TemplateVersion int    `json:"templateVersion,omitempty"`
+	// Candidate is set when a template from the request was rendered
+	// rather than the published one.
+	Candidate bool   `json:"candidate,omitempty"`
+	Diffs     []Diff `json:"diffs,omitempty"`
+	// Truncated is set when there were more differences than are listed.
+	Truncated bool `json:"truncated,omitempty"`
+	// Problems are validation failures of the render.
+	Problems []string `json:"problems,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Report sums up a recording or verification. Passed is false when any
+// render changed or failed; screens without goldens do not fail it.
+type Report struct {
+	Passed    bool      `json:"passed"`
+	Checked   int       `json:"checked"`
+	Recorded  int       `json:"recorded,omitempty"`
+	Matched   int       `json:"matched"`
+	Changed   int       `json:"changed"`
+	New       int       `json:"new"`
+	Failed    int       `json:"failed"`
+	Checks    []Check   `json:"checks"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Store persists cases and goldens.
+type Store interface {
+	SaveCase(c Case) error
+	GetCase(name string) (Case, error)
+	// ListCases returns every case, ordered by name.
+	ListCases() ([]Case, error)
+	DeleteCase(name string) error
+
+	// SaveGolden stores g, replacing the golden of the same screen and case.
+	SaveGolden(g Golden) error
+	// ListGoldens returns the goldens of a screen, or every golden when
+	// screenID is empty, ordered by screen and case.
+	ListGoldens(screenID string) ([]Golden, error)
+	// DeleteGoldens removes every golden of a case.
+	DeleteGoldens(caseName string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	cases   map[string]Case
+	goldens map[string]Golden // keyed by screen ID and case name
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{cases: make(map[string]Case), goldens: make(map[string]Golden)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveCase(c Case) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cases[c.Name] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCase(name string) (Case, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.cases[name]
+	if !ok {
+		return Case{}, ErrCaseNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) ListCases() ([]Case, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Case, 0, len(m.cases))
+	for _, c := range m.cases {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteCase(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.cases[name]; !ok {
+		return ErrCaseNotFound
+	}
+	delete(m.cases, name)
+	return nil
+}
+
+func (m *MemoryStore) SaveGolden(g Golden) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.goldens[g.ScreenID+"/"+g.Case] = g
+	return nil
+}
+
+func (m *MemoryStore) ListGoldens(screenID string) ([]Golden, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Golden{}
+	for _, g := range m.goldens {
+		if screenID == "" || g.ScreenID == screenID {
+			out = append(out, g)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ScreenID != out[j].ScreenID {
+			return out[i].ScreenID < out[j].ScreenID
+		}
+		return out[i].Case < out[j].Case
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteGoldens(caseName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, g := range m.goldens {
+		if g.Case == caseName {
+			delete(m.goldens, key)
+		}
+	}
+	return nil
+}
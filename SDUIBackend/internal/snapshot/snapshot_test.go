@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/sdui"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newTestService(t *testing.T) (*Service, *sdui.Service) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	screens := sdui.NewService("", config.ScreenConfig{UnresolvedPlaceholders: sdui.UnresolvedKeep}, repos, brownout.NewMonitor(config.BrownoutConfig{}), time.Minute, nil, nil, nil, nil)
+	svc := NewService(NewMemoryStore(), screens, nil)
+	svc.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return svc, screens
+}
+
+const homeV1 = `{"version":1,"component":{"type":"vstack","children":[{"type":"text","text":"Hi {{technician.name}}"},{"type":"divider"}]}}`
+
+func TestRecordAndVerify(t *testing.T) {
+	svc, screens := newTestService(t)
+	if _, err := svc.Record(Request{}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected recording without cases refused, got %v", err)
+	}
+	if _, err := svc.SaveCase("sam", Case{}); !errors.Is(err, ErrInvalidCase) {
+		t.Fatalf("expected a case without a service date refused, got %v", err)
+	}
+	day := time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)
+	for name, tech := range map[string]string{"sam": "Sam", "ana": "Ana"} {
+		c := Case{Context: domain.ScreenContext{Technician: domain.Technician{ID: name, DisplayName: tech}}, ServiceDate: day}
+		if _, err := svc.SaveCase(name, c); err != nil {
+			t.Fatalf("save case: %v", err)
+		}
+	}
+	if _, err := screens.CreateTemplate("home", []byte(homeV1)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	rec, err := svc.Record(Request{})
+	if err != nil || rec.Recorded != 2 || !rec.Passed {
+		t.Fatalf("expected two goldens recorded, got %+v (%v)", rec, err)
+	}
+	if goldens, _ := svc.Goldens("home"); len(goldens) != 2 || goldens[0].Case != "ana" || goldens[0].TemplateVersion != 1 || !strings.Contains(string(goldens[0].Screen), "Hi Ana") {
+		t.Fatalf("unexpected goldens %+v", goldens)
+	}
+
+	if report, err := svc.Verify(Request{}); err != nil || !report.Passed || report.Matched != 2 {
+		t.Fatalf("expected unchanged renders to match, got %+v (%v)", report, err)
+	}
+
+	candidate := `{"version":1,"component":{"type":"vstack","children":[{"type":"text","text":"Hello {{technician.name}}"}]}}`
+	report, err := svc.Verify(Request{Templates: map[string]json.RawMessage{"home": json.RawMessage(candidate), "jobs": json.RawMessage(homeV1)}})
+	if err != nil || report.Passed || report.Changed != 2 || report.New != 2 {
+		t.Fatalf("expected the candidate flagged and the new screen reported, got %+v (%v)", report, err)
+	}
+	check := report.Checks[0]
+	if check.ScreenID != "home" || check.Case != "ana" || !check.Candidate || len(check.Diffs) != 2 {
+		t.Fatalf("unexpected check %+v", check)
+	}
+	want := []Diff{
+		{Path: "component.children[0].text", Kind: "changed", Golden: "Hi Ana", Render: "Hello Ana"},
+		{Path: "component.children[1]", Kind: "removed"},
+	}
+	for i, d := range check.Diffs {
+		if d.Path != want[i].Path || d.Kind != want[i].Kind || d.Golden == nil || (want[i].Render != nil && d.Render != want[i].Render) {
+			t.Errorf("diff %d: expected %+v, got %+v", i, want[i], d)
+		}
+	}
+
+	bad := `{"version":1,"component":{"type":"text","bogus":true}}`
+	if report, _ := svc.Verify(Request{ScreenIDs: []string{"home"}, Templates: map[string]json.RawMessage{"home": json.RawMessage(bad)}}); report.Failed != 2 || report.Checks[0].Error == "" {
+		t.Fatalf("expected an invalid candidate to fail, got %+v", report)
+	}
+	if _, err := svc.Verify(Request{ScreenIDs: []string{"missing"}}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected an unpublished screen refused, got %v", err)
+	}
+
+	if err := svc.DeleteCase("ana"); err != nil {
+		t.Fatalf("delete case: %v", err)
+	}
+	if goldens, _ := svc.Goldens(""); len(goldens) != 1 {
+		t.Fatalf("expected the case's goldens deleted, got %+v", goldens)
+	}
+}
+
+func TestVerifyHandler(t *testing.T) {
+	svc, screens := newTestService(t)
+	r := chi.NewRouter()
+	r.Route("/snapshots", NewHandler(svc).Routes)
+
+	body := `{"context":{"technician":{"id":"sam","displayName":"Sam"}},"serviceDate":"2026-06-02T00:00:00Z"}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/snapshots/cases/sam", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the case saved, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := screens.CreateTemplate("home", []byte(homeV1)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/snapshots/verify", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"new"`) {
+		t.Fatalf("expected a report with the screen new, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/snapshots/record", strings.NewReader(`{"templates":{"home":{}}}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected recording candidates refused, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/snapshots/cases/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+// Handler serves the public status page and its admin endpoints.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a status page handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// PublicRoutes mounts the unauthenticated status page.
+func (h *Handler) PublicRoutes(r chi.Router) {
+	r.Get("/", h.GetPage)
+	r.Get("/incidents", h.ListHistory)
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/incidents", h.ListIncidents)
+	r.Post("/incidents", h.OpenIncident)
+	r.Get("/incidents/{incidentId}", h.GetIncident)
+	r.Post("/incidents/{incidentId}/updates", h.UpdateIncident)
+	r.Get("/maintenance", h.ListMaintenance)
+	r.Post("/maintenance", h.ScheduleMaintenance)
+	r.Delete("/maintenance/{maintenanceId}", h.CancelMaintenance)
+}
+
+func (h *Handler) cache(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.service.cfg.MaxAge.Seconds())))
+}
+
+// GetPage returns component health, open incidents and maintenance.
+func (h *Handler) GetPage(w http.ResponseWriter, r *http.Request) {
+	page, err := h.service.Page()
+	if err != nil {
+		h.fail(w, r, "failed to load status", err)
+		return
+	}
+	h.cache(w)
+	respond.JSON(w, http.StatusOK, page)
+}
+
+// ListHistory returns recent incidents, newest first, up to ?limit=.
+func (h *Handler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHistoryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxHistoryLimit {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+			return
+		}
+		limit = n
+	}
+	incidents, err := h.service.History(limit)
+	if err != nil {
+		h.fail(w, r, "failed to list incidents", err)
+		return
+	}
+	if incidents == nil {
+		incidents = []Incident{}
+	}
+	h.cache(w)
+	respond.JSON(w, http.StatusOK, map[string]any{"incidents": incidents})
+}
+
+// ListIncidents returns every incident, newest first.
+func (h *Handler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents, err := h.service.Incidents()
+	if err != nil {
+		h.fail(w, r, "failed to list incidents", err)
+		return
+	}
+	if incidents == nil {
+		incidents = []Incident{}
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"incidents": incidents})
+}
+
+// GetIncident returns an incident with its updates.
+func (h *Handler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	i, err := h.service.Incident(chi.URLParam(r, "incidentId"))
+	if err != nil {
+		h.fail(w, r, "failed to load incident", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, i)
+}
+
+// OpenIncident declares an incident.
+func (h *Handler) OpenIncident(w http.ResponseWriter, r *http.Request) {
+	var payload IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	i, err := h.service.OpenIncident(payload)
+	if err != nil {
+		h.fail(w, r, "failed to open incident", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, i)
+}
+
+// UpdateIncident posts an update on an incident, resolving it with
+// {"status":"resolved"}.
+func (h *Handler) UpdateIncident(w http.ResponseWriter, r *http.Request) {
+	var payload IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	i, err := h.service.UpdateIncident(chi.URLParam(r, "incidentId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update incident", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, i)
+}
+
+// ListMaintenance returns maintenance windows that have not ended.
+func (h *Handler) ListMaintenance(w http.ResponseWriter, r *http.Request) {
+	windows, err := h.service.MaintenanceWindows()
+	if err != nil {
+		h.fail(w, r, "failed to list maintenance", err)
+		return
+	}
+	if windows == nil {
+		windows = []Maintenance{}
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"maintenance": windows})
+}
+
+// ScheduleMaintenance announces a maintenance window.
+func (h *Handler) ScheduleMaintenance(w http.ResponseWriter, r *http.Request) {
+	var payload Maintenance
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	m, err := h.service.ScheduleMaintenance(payload)
+	if err != nil {
+		h.fail(w, r, "failed to schedule maintenance", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, m)
+}
+
+// CancelMaintenance withdraws a maintenance window.
+func (h *Handler) CancelMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.CancelMaintenance(chi.URLParam(r, "maintenanceId")); err != nil {
+		h.fail(w, r, "failed to cancel maintenance", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalid):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	case errors.Is(err, ErrResolved):
+		respond.Error(w, http.StatusConflict, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
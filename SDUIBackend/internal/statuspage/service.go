@@ -0,0 +1,291 @@
+package statuspage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// Component is a part of the service shown on the status page. Check, when
+// set, reports its live status; components without one are operational
+// unless an incident or maintenance window says otherwise.
+type Component struct {
+	ID    string
+	Name  string
+	Check func() string
+}
+
+// ComponentStatus is a component as the status page shows it.
+type ComponentStatus struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// Page is the public status page.
+type Page struct {
+	Status      string            `json:"status"` // the worst component status
+	Components  []ComponentStatus `json:"components"`
+	Incidents   []Incident        `json:"incidents"`   // open incidents
+	Maintenance []Maintenance     `json:"maintenance"` // in progress and upcoming
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+// Service builds the status page and manages incidents and maintenance.
+type Service struct {
+	cfg        config.StatusPageConfig
+	components []Component
+	known      map[string]bool
+	store      Store
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// NewService wires a status page over components, in the order the page
+// lists them.
+func NewService(cfg config.StatusPageConfig, components []Component, store Store, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	known := make(map[string]bool, len(components))
+	for _, c := range components {
+		known[c.ID] = true
+	}
+	return &Service{cfg: cfg, components: components, known: known, store: store, logger: logger, now: time.Now}
+}
+
+// Page returns the status page as of now: each component takes the worst
+// of its check, the open incidents affecting it and maintenance in
+// progress.
+func (s *Service) Page() (Page, error) {
+	now := s.now().UTC()
+	incidents, err := s.store.ListIncidents(now.Add(-s.cfg.History))
+	if err != nil {
+		return Page{}, err
+	}
+	windows, err := s.store.ListMaintenance(now)
+	if err != nil {
+		return Page{}, err
+	}
+
+	affected := make(map[string]string)
+	page := Page{Status: StatusOperational, Incidents: []Incident{}, Maintenance: windows, UpdatedAt: now}
+	if page.Maintenance == nil {
+		page.Maintenance = []Maintenance{}
+	}
+	for _, i := range incidents {
+		if !i.Active() {
+			continue
+		}
+		page.Incidents = append(page.Incidents, i)
+		for _, id := range i.Components {
+			affected[id] = worse(affected[id], i.Impact)
+		}
+	}
+	for _, w := range windows {
+		if !w.InProgress(now) {
+			continue
+		}
+		for _, id := range w.Components {
+			affected[id] = worse(affected[id], StatusMaintenance)
+		}
+	}
+
+	for _, c := range s.components {
+		status := StatusOperational
+		if c.Check != nil {
+			status = worse(status, c.Check())
+		}
+		status = worse(status, affected[c.ID])
+		page.Components = append(page.Components, ComponentStatus{ID: c.ID, Name: c.Name, Status: status})
+		page.Status = worse(page.Status, status)
+	}
+	return page, nil
+}
+
+// History returns up to limit incidents started within the configured
+// history, newest first, open ones included.
+func (s *Service) History(limit int) ([]Incident, error) {
+	incidents, err := s.store.ListIncidents(s.now().Add(-s.cfg.History))
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(incidents) > limit {
+		incidents = incidents[:limit]
+	}
+	return incidents, nil
+}
+
+// Incidents returns every incident, newest first.
+func (s *Service) Incidents() ([]Incident, error) {
+	return s.store.ListIncidents(time.Time{})
+}
+
+// Incident returns an incident.
+func (s *Service) Incident(id string) (Incident, error) {
+	return s.store.GetIncident(id)
+}
+
+// IncidentRequest opens an incident, or posts an update on one.
+type IncidentRequest struct {
+	Title      string   `json:"title,omitempty"`
+	Impact     string   `json:"impact,omitempty"`
+	Components []string `json:"components,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	Message    string   `json:"message"`
+}
+
+func checkImpact(impact string) []string {
+	switch impact {
+	case StatusDegraded, StatusPartialOutage, StatusMajorOutage:
+		return nil
+	}
+	return []string{"impact must be degraded, partial_outage or major_outage"}
+}
+
+func checkIncidentStatus(status string) []string {
+	switch status {
+	case IncidentInvestigating, IncidentIdentified, IncidentMonitoring, IncidentResolved:
+		return nil
+	}
+	return []string{"status must be investigating, identified, monitoring or resolved"}
+}
+
+// OpenIncident declares an incident, investigating unless req says
+// otherwise, with req's message as its first update.
+func (s *Service) OpenIncident(req IncidentRequest) (Incident, error) {
+	if req.Status == "" {
+		req.Status = IncidentInvestigating
+	}
+	var problems []string
+	if strings.TrimSpace(req.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		problems = append(problems, "message is required")
+	}
+	problems = append(problems, checkImpact(req.Impact)...)
+	problems = append(problems, checkIncidentStatus(req.Status)...)
+	problems = append(problems, checkComponents(req.Components, s.known)...)
+	if err := invalid(problems); err != nil {
+		return Incident{}, err
+	}
+
+	now := s.now().UTC()
+	i := Incident{
+		ID:         uuid.NewString(),
+		Title:      strings.TrimSpace(req.Title),
+		Impact:     req.Impact,
+		Components: req.Components,
+		Status:     req.Status,
+		Updates:    []Update{{Status: req.Status, Message: strings.TrimSpace(req.Message), At: now}},
+		StartedAt:  now,
+		UpdatedAt:  now,
+	}
+	if i.Status == IncidentResolved {
+		i.ResolvedAt = &now
+	}
+	if err := s.store.SaveIncident(i); err != nil {
+		return Incident{}, err
+	}
+	s.logger.Warn("incident opened", slog.String("incident", i.ID), slog.String("impact", i.Impact), slog.Any("components", i.Components))
+	return i, nil
+}
+
+// UpdateIncident posts an update on an open incident, moving it to the
+// update's status and, when given, a new impact or set of components.
+// Resolving it closes it for good.
+func (s *Service) UpdateIncident(id string, req IncidentRequest) (Incident, error) {
+	i, err := s.store.GetIncident(id)
+	if err != nil {
+		return Incident{}, err
+	}
+	if !i.Active() {
+		return Incident{}, fmt.Errorf("%w: %s", ErrResolved, id)
+	}
+	if req.Status == "" {
+		req.Status = i.Status
+	}
+	var problems []string
+	if strings.TrimSpace(req.Message) == "" {
+		problems = append(problems, "message is required")
+	}
+	if req.Impact != "" {
+		problems = append(problems, checkImpact(req.Impact)...)
+	}
+	if req.Components != nil {
+		problems = append(problems, checkComponents(req.Components, s.known)...)
+	}
+	problems = append(problems, checkIncidentStatus(req.Status)...)
+	if err := invalid(problems); err != nil {
+		return Incident{}, err
+	}
+
+	now := s.now().UTC()
+	if req.Impact != "" {
+		i.Impact = req.Impact
+	}
+	if req.Components != nil {
+		i.Components = req.Components
+	}
+	i.Status = req.Status
+	i.Updates = append([]Update{{Status: req.Status, Message: strings.TrimSpace(req.Message), At: now}}, i.Updates...)
+	i.UpdatedAt = now
+	if i.Status == IncidentResolved {
+		i.ResolvedAt = &now
+	}
+	if err := s.store.SaveIncident(i); err != nil {
+		return Incident{}, err
+	}
+	s.logger.Info("incident updated", slog.String("incident", i.ID), slog.String("status", i.Status))
+	return i, nil
+}
+
+// MaintenanceWindows returns windows that have not ended, soonest first.
+func (s *Service) MaintenanceWindows() ([]Maintenance, error) {
+	return s.store.ListMaintenance(s.now())
+}
+
+// ScheduleMaintenance announces a maintenance window.
+func (s *Service) ScheduleMaintenance(m Maintenance) (Maintenance, error) {
+	var problems []string
+	if strings.TrimSpace(m.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	if m.StartsAt.IsZero() || m.EndsAt.IsZero() {
+		problems = append(problems, "startsAt and endsAt are required")
+	} else if !m.EndsAt.After(m.StartsAt) {
+		problems = append(problems, "endsAt must be after startsAt")
+	} else if !m.EndsAt.After(s.now()) {
+		problems = append(problems, "endsAt must be in the future")
+	}
+	problems = append(problems, checkComponents(m.Components, s.known)...)
+	if err := invalid(problems); err != nil {
+		return Maintenance{}, err
+	}
+
+	m.ID = uuid.NewString()
+	m.Title = strings.TrimSpace(m.Title)
+	m.StartsAt, m.EndsAt = m.StartsAt.UTC(), m.EndsAt.UTC()
+	m.CreatedAt = s.now().UTC()
+	if err := s.store.SaveMaintenance(m); err != nil {
+		return Maintenance{}, err
+	}
+	s.logger.Info("maintenance scheduled", slog.String("maintenance", m.ID), slog.Time("startsAt", m.StartsAt))
+	return m, nil
+}
+
+// CancelMaintenance withdraws a maintenance window.
+func (s *Service) CancelMaintenance(id string) error {
+	if err := s.store.DeleteMaintenance(id); err != nil {
+		return err
+	}
+	s.logger.Info("maintenance cancelled", slog.String("maintenance", id))
+	return nil
+}
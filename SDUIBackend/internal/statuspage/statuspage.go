@@ -0,0 +1,220 @@
+// Package statuspage publishes the service's health for the people using
+// it: how each component is doing, the incidents affecting them and the
+// maintenance planned. Unlike the health probes it is meant to be read by
+// branch managers, and incidents and maintenance are declared by staff.
+package statuspage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an incident or maintenance window does
+	// not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid wraps incident and maintenance validation failures.
+	ErrInvalid = errors.New("invalid status entry")
+	// ErrResolved is returned when updating a resolved incident.
+	ErrResolved = errors.New("incident already resolved")
+)
+
+// Component statuses, from best to worst.
+const (
+	StatusOperational   = "operational"
+	StatusMaintenance   = "maintenance"
+	StatusDegraded      = "degraded"
+	StatusPartialOutage = "partial_outage"
+	StatusMajorOutage   = "major_outage"
+)
+
+// severity orders component statuses; the worst one applies.
+var severity = map[string]int{
+	StatusOperational:   0,
+	StatusMaintenance:   1,
+	StatusDegraded:      2,
+	StatusPartialOutage: 3,
+	StatusMajorOutage:   4,
+}
+
+func worse(a, b string) string {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// Incident progress, in the order incidents usually move through it.
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+// Incident is a problem affecting some components, with the updates posted
+// while it was worked on.
+type Incident struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Impact is the status the affected components show while the
+	// incident is open: degraded, partial_outage or major_outage.
+	Impact     string     `json:"impact"`
+	Components []string   `json:"components"`
+	Status     string     `json:"status"`
+	Updates    []Update   `json:"updates"` // newest first
+	StartedAt  time.Time  `json:"startedAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// Update is a message posted on an incident.
+type Update struct {
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// Active reports whether the incident is still open.
+func (i Incident) Active() bool {
+	return i.ResolvedAt == nil
+}
+
+// Maintenance is planned work during which components may be unavailable.
+type Maintenance struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Components  []string  `json:"components"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// InProgress reports whether the window is open at now.
+func (m Maintenance) InProgress(now time.Time) bool {
+	return !now.Before(m.StartsAt) && now.Before(m.EndsAt)
+}
+
+// checkComponents validates a list of affected component IDs.
+func checkComponents(ids []string, known map[string]bool) []string {
+	if len(ids) == 0 {
+		return []string{"components is required"}
+	}
+	var problems []string
+	for _, id := range ids {
+		if !known[id] {
+			problems = append(problems, fmt.Sprintf("unknown component %q", id))
+		}
+	}
+	return problems
+}
+
+func invalid(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+}
+
+// Store persists incidents and maintenance windows.
+type Store interface {
+	SaveIncident(i Incident) error
+	GetIncident(id string) (Incident, error)
+	// ListIncidents returns incidents started after since, newest first.
+	ListIncidents(since time.Time) ([]Incident, error)
+	SaveMaintenance(m Maintenance) error
+	GetMaintenance(id string) (Maintenance, error)
+	// ListMaintenance returns windows ending after since, soonest first.
+	ListMaintenance(since time.Time) ([]Maintenance, error)
+	DeleteMaintenance(id string) error
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	incidents   map[string]Incident
+	maintenance map[string]Maintenance
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{incidents: make(map[string]Incident), maintenance: make(map[string]Maintenance)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveIncident(i Incident) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.incidents[i.ID] = i
+	return nil
+}
+
+func (m *MemoryStore) GetIncident(id string) (Incident, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.incidents[id]
+	if !ok {
+		return Incident{}, ErrNotFound
+	}
+	return i, nil
+}
+
+func (m *MemoryStore) ListIncidents(since time.Time) ([]Incident, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Incident
+	for _, i := range m.incidents {
+		if i.StartedAt.After(since) {
+			out = append(out, i)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].StartedAt.After(out[b].StartedAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) SaveMaintenance(w Maintenance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maintenance[w.ID] = w
+	return nil
+}
+
+func (m *MemoryStore) GetMaintenance(id string) (Maintenance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.maintenance[id]
+	if !ok {
+		return Maintenance{}, ErrNotFound
+	}
+	return w, nil
+}
+
+func (m *MemoryStore) ListMaintenance(since time.Time) ([]Maintenance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Maintenance
+	for _, w := range m.maintenance {
+		if w.EndsAt.After(since) {
+			out = append(out, w)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].StartsAt.Before(out[b].StartsAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteMaintenance(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.maintenance[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.maintenance, id)
+	return nil
+}
@@ -0,0 +1,128 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+func newTestService(syncStatus *string) (*Service, *time.Time) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewService(config.StatusPageConfig{History: 30 * 24 * time.Hour, MaxAge: 30 * time.Second}, []Component{
+		{ID: "app", Name: "Technician app"},
+		{ID: "sync", Name: "Sync", Check: func() string { return *syncStatus }},
+		{ID: "notifications", Name: "Push notifications"},
+	}, NewMemoryStore(), nil)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func statuses(p Page) map[string]string {
+	out := make(map[string]string, len(p.Components))
+	for _, c := range p.Components {
+		out[c.ID] = c.Status
+	}
+	return out
+}
+
+func TestPageCombinesChecksIncidentsAndMaintenance(t *testing.T) {
+	syncStatus := StatusOperational
+	svc, now := newTestService(&syncStatus)
+
+	page, err := svc.Page()
+	if err != nil || page.Status != StatusOperational || len(page.Components) != 3 {
+		t.Fatalf("unexpected page %+v (%v)", page, err)
+	}
+
+	syncStatus = StatusDegraded
+	inc, err := svc.OpenIncident(IncidentRequest{Title: "Push delays", Impact: StatusPartialOutage, Components: []string{"notifications"}, Message: "APNs is slow"})
+	if err != nil {
+		t.Fatalf("open incident: %v", err)
+	}
+	if _, err := svc.ScheduleMaintenance(Maintenance{Title: "Database upgrade", Components: []string{"app"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("schedule maintenance: %v", err)
+	}
+	if _, err := svc.ScheduleMaintenance(Maintenance{Title: "Later", Components: []string{"sync"}, StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(25 * time.Hour)}); err != nil {
+		t.Fatalf("schedule maintenance: %v", err)
+	}
+
+	page, _ = svc.Page()
+	got := statuses(page)
+	if got["app"] != StatusMaintenance || got["sync"] != StatusDegraded || got["notifications"] != StatusPartialOutage {
+		t.Fatalf("unexpected component statuses %v", got)
+	}
+	if page.Status != StatusPartialOutage || len(page.Incidents) != 1 || len(page.Maintenance) != 2 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	*now = now.Add(2 * time.Hour)
+	if _, err := svc.UpdateIncident(inc.ID, IncidentRequest{Status: IncidentResolved, Message: "Delivered"}); err != nil {
+		t.Fatalf("resolve incident: %v", err)
+	}
+	syncStatus = StatusOperational
+	page, _ = svc.Page()
+	if page.Status != StatusOperational || len(page.Incidents) != 0 || len(page.Maintenance) != 1 {
+		t.Fatalf("expected an operational page, got %+v", page)
+	}
+	if _, err := svc.UpdateIncident(inc.ID, IncidentRequest{Message: "again"}); !errors.Is(err, ErrResolved) {
+		t.Fatalf("expected resolved incidents to be closed, got %v", err)
+	}
+
+	history, _ := svc.History(10)
+	if len(history) != 1 || len(history[0].Updates) != 2 || history[0].Updates[0].Status != IncidentResolved || history[0].ResolvedAt == nil {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	*now = now.Add(31 * 24 * time.Hour)
+	if history, _ := svc.History(10); len(history) != 0 {
+		t.Fatalf("expected old incidents to leave the history, got %+v", history)
+	}
+}
+
+func TestIncidentValidation(t *testing.T) {
+	syncStatus := StatusOperational
+	svc, now := newTestService(&syncStatus)
+	cases := []IncidentRequest{
+		{Impact: StatusDegraded, Components: []string{"app"}, Message: "m"},
+		{Title: "t", Impact: StatusMaintenance, Components: []string{"app"}, Message: "m"},
+		{Title: "t", Impact: StatusDegraded, Components: []string{"billing"}, Message: "m"},
+		{Title: "t", Impact: StatusDegraded, Components: []string{"app"}},
+	}
+	for _, req := range cases {
+		if _, err := svc.OpenIncident(req); !errors.Is(err, ErrInvalid) {
+			t.Errorf("OpenIncident(%+v) = %v, want invalid", req, err)
+		}
+	}
+	if _, err := svc.ScheduleMaintenance(Maintenance{Title: "t", Components: []string{"app"}, StartsAt: now.Add(time.Hour), EndsAt: *now}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a window ending before it starts to be rejected, got %v", err)
+	}
+}
+
+func TestPublicPageIsCacheable(t *testing.T) {
+	syncStatus := StatusOperational
+	svc, _ := newTestService(&syncStatus)
+	r := chi.NewRouter()
+	r.Route("/status", NewHandler(svc).PublicRoutes)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "public, max-age=30" {
+		t.Fatalf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+	var page Page
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil || page.Status != StatusOperational {
+		t.Fatalf("unexpected page %+v (%v)", page, err)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status/incidents?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid limit to be rejected, got %d", rec.Code)
+	}
+}
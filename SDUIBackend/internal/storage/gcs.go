@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/gcp"
+)
+
+// maxSignedURLTTL is the longest lifetime GCS accepts for V4 signed URLs.
+const maxSignedURLTTL = 7 * 24 * time.Hour
+
+// GCS stores blobs in a Cloud Storage bucket through the JSON API, using the
+// runtime service account. Signed URLs are V4 URLs signed with the IAM
+// signBlob API, so the account needs roles/iam.serviceAccountTokenCreator on
+// itself; no key file is required.
+type GCS struct {
+	bucket string
+	client *http.Client
+	tokens gcp.TokenSource
+	now    func() time.Time
+
+	mu    sync.Mutex
+	email string // runtime service account, resolved lazily
+}
+
+var _ BlobStore = (*GCS)(nil)
+
+// NewGCS creates a GCS store for bucket.
+func NewGCS(bucket string, timeout time.Duration) *GCS {
+	return &GCS{
+		bucket: bucket,
+		client: &http.Client{Timeout: timeout},
+		tokens: gcp.NewMetadataTokenSource(),
+		now:    time.Now,
+	}
+}
+
+func (g *GCS) Put(ctx context.Context, key, contentType string, body io.Reader) (int64, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return 0, err
+	}
+	target := "https://storage.googleapis.com/upload/storage/v1/b/" + url.PathEscape(g.bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(key)
+	counter := &countingReader{r: body}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, counter)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := g.do(req, nil); err != nil {
+		return counter.n, fmt.Errorf("upload %s: %w", key, err)
+	}
+	return counter.n, nil
+}
+
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return nil, err
+	}
+	target := "https://storage.googleapis.com/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(key) + "?alt=media"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	target := "https://storage.googleapis.com/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target, nil)
+	if err != nil {
+		return err
+	}
+	err = g.do(req, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL returns a V4 signed GET URL for key.
+func (g *GCS) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+	if ttl > maxSignedURLTTL {
+		ttl = maxSignedURLTTL
+	}
+	email, err := g.serviceAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := g.now().UTC()
+	stamp := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/auto/storage/goog4_request"
+	const host = "storage.googleapis.com"
+	escapedPath := "/" + g.bucket + "/" + escapeSegments(key)
+
+	query := map[string]string{
+		"X-Goog-Algorithm":     "GOOG4-RSA-SHA256",
+		"X-Goog-Credential":    email + "/" + scope,
+		"X-Goog-Date":          stamp,
+		"X-Goog-Expires":       strconv.Itoa(int(ttl.Seconds())),
+		"X-Goog-SignedHeaders": "host",
+	}
+	canonicalQuery := encodeSorted(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		escapedPath,
+		canonicalQuery,
+		"host:" + host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "GOOG4-RSA-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	signature, err := g.signBlob(ctx, email, []byte(stringToSign))
+	if err != nil {
+		return "", err
+	}
+	return "https://" + host + escapedPath + "?" + canonicalQuery + "&X-Goog-Signature=" + hex.EncodeToString(signature), nil
+}
+
+func (g *GCS) serviceAccount(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.email == "" {
+		email, err := gcp.ServiceAccountEmail(ctx)
+		if err != nil {
+			return "", fmt.Errorf("resolve service account: %w", err)
+		}
+		g.email = email
+	}
+	return g.email, nil
+}
+
+func (g *GCS) signBlob(ctx context.Context, email string, payload []byte) ([]byte, error) {
+	body, _ := json.Marshal(map[string]string{"payload": base64.StdEncoding.EncodeToString(payload)})
+	target := "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/" + url.PathEscape(email) + ":signBlob"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var out struct {
+		SignedBlob string `json:"signedBlob"`
+	}
+	if err := g.do(req, &out); err != nil {
+		return nil, fmt.Errorf("sign url: %w", err)
+	}
+	return base64.StdEncoding.DecodeString(out.SignedBlob)
+}
+
+func (g *GCS) do(req *http.Request, out any) error {
+	resp, err := g.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// send makes an authorized request, turning error statuses into errors.
+// On success the caller closes the response body.
+func (g *GCS) send(req *http.Request) (*http.Response, error) {
+	token, err := g.tokens.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("gcs token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// encodeSorted renders query parameters sorted by name with RFC 3986
+// escaping, as V4 signing requires.
+func encodeSorted(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = rfc3986(name) + "=" + rfc3986(params[name])
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapeSegments(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = rfc3986(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// rfc3986 percent-encodes everything except unreserved characters.
+func rfc3986(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LocalPathPrefix is where the API serves blobs from a Local store. Mount
+// Local.ServeHTTP at LocalPathPrefix + "*".
+const LocalPathPrefix = "/v1/blobs/"
+
+// Local stores blobs on disk and serves them through the API using
+// HMAC-signed, expiring URLs. It is meant for development and single-node
+// installs.
+type Local struct {
+	root    string
+	baseURL string // absolute URL ending in LocalPathPrefix
+	key     []byte
+	now     func() time.Time
+}
+
+var _ BlobStore = (*Local)(nil)
+
+// NewLocal creates a Local store rooted at root. A nil signingKey is
+// replaced with a random one, invalidating URLs on restart.
+func NewLocal(root, baseURL string, signingKey []byte) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+	}
+	return &Local{root: root, baseURL: baseURL, key: signingKey, now: time.Now}, nil
+}
+
+func (l *Local) Put(ctx context.Context, key, contentType string, body io.Reader) (int64, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return 0, err
+	}
+	target := filepath.Join(l.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	counter := &countingReader{r: body}
+	if _, err := io.Copy(tmp, counter); err != nil {
+		tmp.Close()
+		return counter.n, err
+	}
+	if err := tmp.Close(); err != nil {
+		return counter.n, err
+	}
+	// Rename so readers never see a partially written blob.
+	return counter.n, os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(l.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(ttl).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {l.sign(key, expires)}}
+	return l.baseURL + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(l.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ServeHTTP serves a blob when the request carries a valid, unexpired
+// signature. Invalid and unknown blobs are indistinguishable.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, err := checkKey(chi.URLParam(r, "*"))
+	q := r.URL.Query()
+	expires, convErr := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || convErr != nil || l.now().Unix() > expires ||
+		!hmac.Equal([]byte(q.Get("signature")), []byte(l.sign(key, q.Get("expires")))) {
+		http.NotFound(w, r)
+		return
+	}
+	l.serve(w, r, key, "private, max-age="+strconv.FormatInt(expires-l.now().Unix(), 10))
+}
+
+// Public serves the blobs under prefix without a signature, for content
+// that is public and never changes under its key, such as versioned
+// template assets. Mount it like ServeHTTP; the wildcard is the key after
+// prefix.
+func (l *Local) Public(prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := checkKey(path.Join(prefix, chi.URLParam(r, "*")))
+		if err != nil || !strings.HasPrefix(key, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		l.serve(w, r, key, "public, max-age=31536000, immutable")
+	}
+}
+
+func (l *Local) serve(w http.ResponseWriter, r *http.Request, key, cacheControl string) {
+	f, err := os.Open(filepath.Join(l.root, filepath.FromSlash(key)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if ct := contentTypeFor(key); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), f)
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// contentTypeFor maps a key's extension to a content type, covering the
+// HEIC/HEIF formats iOS cameras produce that the mime table lacks.
+func contentTypeFor(key string) string {
+	ext := strings.ToLower(path.Ext(key))
+	switch ext {
+	case ".heic":
+		return "image/heic"
+	case ".heif":
+		return "image/heif"
+	}
+	return mime.TypeByExtension(ext)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Prefixed keeps its blobs under a prefix of another store and remembers
+// the keys written through it, so they can be purged together. Sandboxes
+// use one per environment to keep their uploads apart from production's.
+type Prefixed struct {
+	store  BlobStore
+	prefix string
+
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// NewPrefixed returns a view of store under prefix, which should end in a
+// slash.
+func NewPrefixed(store BlobStore, prefix string) *Prefixed {
+	return &Prefixed{store: store, prefix: prefix, keys: make(map[string]struct{})}
+}
+
+var _ BlobStore = (*Prefixed)(nil)
+
+func (p *Prefixed) key(key string) (string, error) {
+	if _, err := checkKey(key); err != nil {
+		return "", err
+	}
+	return checkKey(p.prefix + key)
+}
+
+func (p *Prefixed) Put(ctx context.Context, key, contentType string, body io.Reader) (int64, error) {
+	full, err := p.key(key)
+	if err != nil {
+		return 0, err
+	}
+	// Recorded first, so a partly written blob is purged too.
+	p.mu.Lock()
+	p.keys[full] = struct{}{}
+	p.mu.Unlock()
+	return p.store.Put(ctx, full, contentType, body)
+}
+
+func (p *Prefixed) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	full, err := p.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.Get(ctx, full)
+}
+
+func (p *Prefixed) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	full, err := p.key(key)
+	if err != nil {
+		return "", err
+	}
+	return p.store.SignedURL(ctx, full, ttl)
+}
+
+func (p *Prefixed) Delete(ctx context.Context, key string) error {
+	full, err := p.key(key)
+	if err != nil {
+		return err
+	}
+	if err := p.store.Delete(ctx, full); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	delete(p.keys, full)
+	p.mu.Unlock()
+	return nil
+}
+
+// Purge deletes every blob written through p. Blobs it fails to delete
+// are reported once it has tried them all, and kept for another try.
+func (p *Prefixed) Purge(ctx context.Context) error {
+	p.mu.Lock()
+	keys := make([]string, 0, len(p.keys))
+	for k := range p.keys {
+		keys = append(keys, k)
+	}
+	p.mu.Unlock()
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		if err := p.store.Delete(ctx, k); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p.mu.Lock()
+		delete(p.keys, k)
+		p.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
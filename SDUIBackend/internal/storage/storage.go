@@ -0,0 +1,67 @@
+// Package storage provides blob storage for uploaded media.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+var (
+	// ErrNotFound is returned when a blob does not exist.
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for keys that are empty or escape the store.
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore stores opaque objects under slash-separated keys.
+type BlobStore interface {
+	// Put streams body to key and returns the number of bytes written.
+	Put(ctx context.Context, key, contentType string, body io.Reader) (int64, error)
+	// Get opens key for reading; the caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// SignedURL returns a URL that allows reading key until ttl elapses.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the blob store selected by cfg.
+func New(cfg config.PhotoConfig, logger *slog.Logger) (BlobStore, error) {
+	switch cfg.Storage {
+	case "gcs":
+		return NewGCS(cfg.Bucket, cfg.RequestTimeout), nil
+	default:
+		if cfg.SigningKey == "" && logger != nil {
+			logger.Warn("PHOTOS_URL_SIGNING_KEY not set, signed photo URLs will not survive a restart")
+		}
+		return NewLocal(cfg.LocalDir, cfg.PublicBaseURL+LocalPathPrefix, []byte(cfg.SigningKey))
+	}
+}
+
+// checkKey rejects keys that are empty, absolute, unclean, or climb out of
+// the root.
+func checkKey(key string) (string, error) {
+	if key == "" || path.Clean("/" + key)[1:] != key {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return key, nil
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
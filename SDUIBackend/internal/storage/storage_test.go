@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestLocal(t *testing.T) (*Local, http.Handler) {
+	t.Helper()
+	store, err := NewLocal(t.TempDir(), "http://api.test"+LocalPathPrefix, []byte("secret"))
+	if err != nil {
+		t.Fatalf("new local store: %v", err)
+	}
+	router := chi.NewRouter()
+	router.Get(LocalPathPrefix+"*", store.ServeHTTP)
+	return store, router
+}
+
+func fetch(t *testing.T, router http.Handler, signed string) *httptest.ResponseRecorder {
+	t.Helper()
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse %q: %v", signed, err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+	return rec
+}
+
+func TestLocalRoundTripAndSignature(t *testing.T) {
+	store, router := newTestLocal(t)
+	ctx := context.Background()
+	n, err := store.Put(ctx, "photos/a.heic", "image/heic", strings.NewReader("image-bytes"))
+	if err != nil || n != 11 {
+		t.Fatalf("put: n=%d err=%v", n, err)
+	}
+
+	signed, _ := store.SignedURL(ctx, "photos/a.heic", time.Minute)
+	rec := fetch(t, router, signed)
+	if rec.Code != http.StatusOK || rec.Body.String() != "image-bytes" || rec.Header().Get("Content-Type") != "image/heic" {
+		t.Fatalf("unexpected response %d %q %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	if rec := fetch(t, router, strings.Replace(signed, "signature=", "signature=00", 1)); rec.Code != http.StatusNotFound {
+		t.Fatalf("tampered signature: expected 404, got %d", rec.Code)
+	}
+	store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if rec := fetch(t, router, signed); rec.Code != http.StatusNotFound {
+		t.Fatalf("expired url: expected 404, got %d", rec.Code)
+	}
+
+	body, err := store.Get(ctx, "photos/a.heic")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "image-bytes" {
+		t.Fatalf("get returned %q", data)
+	}
+
+	if err := store.Delete(ctx, "photos/a.heic"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "photos/a.heic"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a deleted blob, got %v", err)
+	}
+	if err := store.Delete(ctx, "photos/a.heic"); err != nil {
+		t.Fatalf("deleting a missing blob should succeed, got %v", err)
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	store, _ := newTestLocal(t)
+	for _, key := range []string{"", "../etc/passwd", "/abs", "photos/../../x", "photos//x"} {
+		if _, err := store.Put(context.Background(), key, "image/png", io.LimitReader(strings.NewReader("x"), 1)); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("%q: expected invalid key, got %v", key, err)
+		}
+	}
+}
+
+func TestLocalPublicServesOnlyItsPrefix(t *testing.T) {
+	store, _ := newTestLocal(t)
+	router := chi.NewRouter()
+	router.Get("/v1/assets/*", store.Public("assets"))
+	ctx := context.Background()
+	if _, err := store.Put(ctx, "assets/logo/1-ab.png", "image/png", strings.NewReader("png")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := store.Put(ctx, "photos/a.png", "image/png", strings.NewReader("private")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	rec := fetch(t, router, "http://api.test/v1/assets/logo/1-ab.png")
+	if rec.Code != http.StatusOK || rec.Body.String() != "png" || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Fatalf("unexpected response %d %q %q", rec.Code, rec.Header().Get("Cache-Control"), rec.Body.String())
+	}
+	if rec := fetch(t, router, "http://api.test/v1/assets/../photos/a.png"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected blobs outside the prefix hidden, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,333 @@
+package firestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/gcp"
+)
+
+// errNotFound is returned by the client when a document does not exist.
+var errNotFound = errors.New("document not found")
+
+// errExists is returned when creating a document that already exists.
+var errExists = errors.New("document already exists")
+
+// errChanged is returned when a conditional write finds the document
+// changed since it was read.
+var errChanged = errors.New("document changed")
+
+// client is a minimal Firestore REST client covering the document operations
+// the repositories need. It talks to the emulator when one is configured.
+type client struct {
+	base    string // .../v1/projects/{project}/databases/{database}/documents
+	name    string // projects/{project}/databases/{database}/documents
+	http    *http.Client
+	tokens  gcp.TokenSource
+	timeout time.Duration
+}
+
+func newClient(cfg config.DatastoreConfig, tokens gcp.TokenSource) (*client, error) {
+	if cfg.FirestoreProject == "" {
+		return nil, errors.New("firestore project is required")
+	}
+	database := cfg.FirestoreDatabase
+	if database == "" {
+		database = "(default)"
+	}
+	timeout := cfg.RequestTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	host := "https://firestore.googleapis.com"
+	if cfg.FirestoreEmulator != "" {
+		host = "http://" + strings.TrimPrefix(cfg.FirestoreEmulator, "http://")
+		// The emulator accepts this fixed token and bypasses security rules.
+		tokens = gcp.StaticToken("owner")
+	}
+	if tokens == nil {
+		tokens = gcp.NewMetadataTokenSource()
+	}
+	name := fmt.Sprintf("projects/%s/databases/%s/documents", url.PathEscape(cfg.FirestoreProject), url.PathEscape(database))
+	return &client{
+		base:    host + "/v1/" + name,
+		name:    name,
+		http:    &http.Client{Timeout: timeout},
+		tokens:  tokens,
+		timeout: timeout,
+	}, nil
+}
+
+// document is a Firestore document in REST form.
+type document struct {
+	Name       string `json:"name,omitempty"`
+	Fields     fields `json:"fields"`
+	UpdateTime string `json:"updateTime,omitempty"`
+}
+
+// id is the document's ID, undoing escapeID.
+func (d document) id() string {
+	name := d.Name[strings.LastIndex(d.Name, "/")+1:]
+	return strings.NewReplacer("%2F", "/", "%25", "%").Replace(name)
+}
+
+func (c *client) docURL(collection, id string) string {
+	return c.base + "/" + collection + "/" + url.PathEscape(escapeID(id))
+}
+
+// get fetches a document, returning errNotFound when it does not exist.
+func (c *client) get(collection, id string) (document, error) {
+	var doc document
+	err := c.do(http.MethodGet, c.docURL(collection, id), nil, &doc)
+	return doc, err
+}
+
+// set creates or fully replaces a document.
+func (c *client) set(collection, id string, f fields) error {
+	return c.do(http.MethodPatch, c.docURL(collection, id), document{Fields: f}, nil)
+}
+
+// replace fully replaces a document unless it was written after the read
+// that returned updateTime, returning errChanged if it was.
+func (c *client) replace(collection, id string, f fields, updateTime string) error {
+	err := c.do(http.MethodPatch, c.docURL(collection, id)+"?currentDocument.updateTime="+url.QueryEscape(updateTime), document{Fields: f}, nil)
+	if err != nil && strings.Contains(err.Error(), "FAILED_PRECONDITION") {
+		// Firestore answers 400 when the precondition fails.
+		return errChanged
+	}
+	return err
+}
+
+// create writes a new document, returning errExists when one with the ID
+// exists; unlike set it never replaces a document.
+func (c *client) create(collection, id string, f fields) error {
+	return c.do(http.MethodPost, c.base+"/"+collection+"?documentId="+url.QueryEscape(escapeID(id)), document{Fields: f}, nil)
+}
+
+// write is one document a commit sets.
+type write struct {
+	collection, id string
+	fields         fields
+}
+
+// commit sets every document of writes in one atomic commit: either all of
+// them are written or none is.
+func (c *client) commit(writes []write) error {
+	type update struct {
+		Update document `json:"update"`
+	}
+	body := struct {
+		Writes []update `json:"writes"`
+	}{Writes: make([]update, len(writes))}
+	for i, w := range writes {
+		body.Writes[i].Update = document{Name: c.name + "/" + w.collection + "/" + escapeID(w.id), Fields: w.fields}
+	}
+	return c.do(http.MethodPost, c.base+":commit", body, nil)
+}
+
+// remove deletes a document. Deleting a missing document succeeds.
+func (c *client) remove(collection, id string) error {
+	return c.do(http.MethodDelete, c.docURL(collection, id), nil, nil)
+}
+
+// list returns every document in a collection.
+func (c *client) list(collection string) ([]document, error) {
+	var out []document
+	pageToken := ""
+	for {
+		u := c.base + "/" + collection + "?pageSize=300"
+		if pageToken != "" {
+			u += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		var page struct {
+			Documents     []document `json:"documents"`
+			NextPageToken string     `json:"nextPageToken"`
+		}
+		if err := c.do(http.MethodGet, u, nil, &page); err != nil {
+			return nil, err
+		}
+		out = append(out, page.Documents...)
+		if page.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// oldest returns up to limit documents of a collection ordered by field
+// ascending; limit <= 0 returns all of them.
+func (c *client) oldest(collection, field string, limit int) ([]document, error) {
+	query := map[string]any{
+		"from":    []map[string]any{{"collectionId": collection}},
+		"orderBy": []map[string]any{{"field": map[string]string{"fieldPath": field}, "direction": "ASCENDING"}},
+	}
+	if limit > 0 {
+		query["limit"] = limit
+	}
+	return c.runQuery(query)
+}
+
+func (c *client) runQuery(query map[string]any) ([]document, error) {
+	var results []struct {
+		Document *document `json:"document"`
+	}
+	if err := c.do(http.MethodPost, c.base+":runQuery", map[string]any{"structuredQuery": query}, &results); err != nil {
+		return nil, err
+	}
+	out := make([]document, 0, len(results))
+	for _, r := range results {
+		if r.Document != nil {
+			out = append(out, *r.Document)
+		}
+	}
+	return out, nil
+}
+
+// after returns the documents of a collection whose field is later than
+// since, ordered by that field ascending.
+func (c *client) after(collection, field string, since time.Time) ([]document, error) {
+	if since.IsZero() {
+		return c.oldest(collection, field, 0)
+	}
+	query := map[string]any{
+		"from": []map[string]any{{"collectionId": collection}},
+		"where": map[string]any{"fieldFilter": map[string]any{
+			"field": map[string]string{"fieldPath": field},
+			"op":    "GREATER_THAN",
+			"value": timeV(since),
+		}},
+		"orderBy": []map[string]any{{"field": map[string]string{"fieldPath": field}, "direction": "ASCENDING"}},
+	}
+	return c.runQuery(query)
+}
+
+// pageAfter returns up to limit documents of a collection whose field
+// equals v, or any document when field is empty, ordered by the order
+// fields ascending and starting after the position the start values give
+// them; limit <= 0 returns all of them. Documents without every order field
+// are left out.
+func (c *client) pageAfter(collection, field string, v value, order []string, start []value, limit int) ([]document, error) {
+	orderBy := make([]map[string]any, len(order))
+	for i, f := range order {
+		orderBy[i] = map[string]any{"field": map[string]string{"fieldPath": f}, "direction": "ASCENDING"}
+	}
+	query := map[string]any{
+		"from":    []map[string]any{{"collectionId": collection}},
+		"orderBy": orderBy,
+		"startAt": map[string]any{"values": start, "before": false},
+	}
+	if field != "" {
+		query["where"] = map[string]any{"fieldFilter": map[string]any{
+			"field": map[string]string{"fieldPath": field},
+			"op":    "EQUAL",
+			"value": v,
+		}}
+	}
+	if limit > 0 {
+		query["limit"] = limit
+	}
+	return c.runQuery(query)
+}
+
+// equal returns the documents of a collection whose fields equal every value
+// in match.
+func (c *client) equal(collection string, match map[string]value) ([]document, error) {
+	filters := make([]map[string]any, 0, len(match))
+	for field, v := range match {
+		filters = append(filters, map[string]any{"fieldFilter": map[string]any{
+			"field": map[string]string{"fieldPath": field},
+			"op":    "EQUAL",
+			"value": v,
+		}})
+	}
+	query := map[string]any{"from": []map[string]any{{"collectionId": collection}}}
+	if len(filters) == 1 {
+		query["where"] = filters[0]
+	} else {
+		query["where"] = map[string]any{"compositeFilter": map[string]any{"op": "AND", "filters": filters}}
+	}
+	return c.runQuery(query)
+}
+
+// where returns the documents of a collection whose field compares to v
+// with op (e.g. EQUAL, LESS_THAN).
+func (c *client) where(collection, field, op string, v value) ([]document, error) {
+	query := map[string]any{
+		"from": []map[string]any{{"collectionId": collection}},
+		"where": map[string]any{"fieldFilter": map[string]any{
+			"field": map[string]string{"fieldPath": field},
+			"op":    op,
+			"value": v,
+		}},
+	}
+	return c.runQuery(query)
+}
+
+func (c *client) do(method, target string, body, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("firestore token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return apperr.Unavailable("datastore_unavailable", "the datastore is unavailable", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return errExists
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		err := fmt.Errorf("firestore %s: %s: %s", method, resp.Status, strings.TrimSpace(string(msg)))
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			// Quota exhaustion and overload clear on their own.
+			return apperr.Unavailable("datastore_unavailable", "the datastore is unavailable", err)
+		}
+		return err
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// escapeID makes an arbitrary string safe as a single document ID segment.
+func escapeID(id string) string {
+	return strings.NewReplacer("%", "%25", "/", "%2F").Replace(id)
+}
@@ -0,0 +1,355 @@
+package firestore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+)
+
+func encodeTechnician(t models.Technician) fields {
+	return fields{
+		"tenantId":       stringV(t.TenantID),
+		"email":          stringV(t.Email),
+		"displayName":    stringV(t.DisplayName),
+		"role":           stringV(t.Role),
+		"region":         stringV(t.Region),
+		"branchId":       stringV(t.BranchID),
+		"certifications": stringsV(t.Certifications),
+	}
+}
+
+func decodeTechnician(id string, f fields) models.Technician {
+	return models.Technician{
+		ID:             id,
+		TenantID:       f.str("tenantId"),
+		Email:          f.str("email"),
+		DisplayName:    f.str("displayName"),
+		Role:           f.str("role"),
+		Region:         f.str("region"),
+		BranchID:       f.str("branchId"),
+		Certifications: f.strings("certifications"),
+	}
+}
+
+func encodeRoute(r models.Route) fields {
+	stops := make([]value, len(r.CustomerStops))
+	for i, stop := range r.CustomerStops {
+		f := fields{
+			"customerId":   stringV(stop.CustomerID),
+			"customerName": stringV(stop.CustomerName),
+			"address":      stringV(stop.Address),
+			"windowStart":  timeV(stop.WindowStart),
+			"windowEnd":    timeV(stop.WindowEnd),
+			"priority":     stringV(stop.Priority),
+			"nickname":     stringV(stop.Nickname),
+			"notes":        stringV(stop.Notes),
+			"serviceType":  stringV(stop.ServiceType),
+			"propertySqFt": intV(int64(stop.PropertySqFt)),
+			"latitude":     doubleV(stop.Latitude),
+			"longitude":    doubleV(stop.Longitude),
+		}
+		if p := stop.PostalAddress; p != nil {
+			f["postalAddress"] = mapV(fields{
+				"street": stringV(p.Street),
+				"unit":   stringV(p.Unit),
+				"city":   stringV(p.City),
+				"state":  stringV(p.State),
+				"zip":    stringV(p.ZIP),
+				"zip4":   stringV(p.ZIP4),
+				"status": stringV(p.Status),
+			})
+		}
+		stops[i] = mapV(f)
+	}
+	alerts := make([]value, len(r.Alerts))
+	for i, alert := range r.Alerts {
+		alerts[i] = mapV(fields{
+			"type":     stringV(alert.Type),
+			"message":  stringV(alert.Message),
+			"severity": stringV(alert.Severity),
+		})
+	}
+	return fields{
+		"id":            stringV(r.ID),
+		"technicianId":  stringV(r.TechnicianID),
+		"tenantId":      stringV(r.TenantID),
+		"serviceDate":   timeV(r.ServiceDate),
+		"customerStops": arrayV(stops),
+		"alerts":        arrayV(alerts),
+		"lastModified":  timeV(r.LastModified),
+	}
+}
+
+func decodeRoute(f fields) models.Route {
+	route := models.Route{
+		ID:           f.str("id"),
+		TechnicianID: f.str("technicianId"),
+		TenantID:     f.str("tenantId"),
+		ServiceDate:  f.time("serviceDate"),
+		LastModified: f.time("lastModified"),
+	}
+	for _, v := range f.array("customerStops") {
+		stop := v.fields()
+		decoded := models.RouteStop{
+			CustomerID:   stop.str("customerId"),
+			CustomerName: stop.str("customerName"),
+			Address:      stop.str("address"),
+			WindowStart:  stop.time("windowStart"),
+			WindowEnd:    stop.time("windowEnd"),
+			Priority:     stop.str("priority"),
+			Nickname:     stop.str("nickname"),
+			Notes:        stop.str("notes"),
+			ServiceType:  stop.str("serviceType"),
+			PropertySqFt: int(stop.integer("propertySqFt")),
+			Latitude:     stop.double("latitude"),
+			Longitude:    stop.double("longitude"),
+		}
+		if pv, ok := stop["postalAddress"]; ok && pv.MapValue != nil {
+			p := pv.fields()
+			decoded.PostalAddress = &models.PostalAddress{
+				Street: p.str("street"),
+				Unit:   p.str("unit"),
+				City:   p.str("city"),
+				State:  p.str("state"),
+				ZIP:    p.str("zip"),
+				ZIP4:   p.str("zip4"),
+				Status: p.str("status"),
+			}
+		}
+		route.CustomerStops = append(route.CustomerStops, decoded)
+	}
+	for _, v := range f.array("alerts") {
+		alert := v.fields()
+		route.Alerts = append(route.Alerts, models.RouteAlert{
+			Type:     alert.str("type"),
+			Message:  alert.str("message"),
+			Severity: alert.str("severity"),
+		})
+	}
+	return route
+}
+
+func encodeTemplate(t models.ScreenTemplate) fields {
+	return fields{
+		"id":          stringV(t.ID),
+		"tenantId":    stringV(t.TenantID),
+		"version":     intV(int64(t.Version)),
+		"payloadJson": bytesV(t.PayloadJSON),
+		"createdAt":   timeV(t.CreatedAt),
+		"updatedAt":   timeV(t.UpdatedAt),
+	}
+}
+
+func decodeTemplate(f fields) models.ScreenTemplate {
+	return models.ScreenTemplate{
+		ID:          f.str("id"),
+		TenantID:    f.str("tenantId"),
+		Version:     int(f.integer("version")),
+		PayloadJSON: f.bytes("payloadJson"),
+		CreatedAt:   f.time("createdAt"),
+		UpdatedAt:   f.time("updatedAt"),
+	}
+}
+
+func encodeJob(u models.JobUpload) fields {
+	return fields{
+		"id":            stringV(u.ID),
+		"technicianId":  stringV(u.TechnicianID),
+		"tenantId":      stringV(u.TenantID),
+		"customerName":  stringV(u.CustomerName),
+		"address":       stringV(u.Address),
+		"scheduledDate": timeV(u.ScheduledDate),
+		"status":        stringV(u.Status),
+		"receivedAt":    timeV(u.ReceivedAt),
+		"latitude":      doubleV(u.Latitude),
+		"longitude":     doubleV(u.Longitude),
+		"signatureId":   stringV(u.SignatureID),
+	}
+}
+
+func decodeJob(f fields) models.JobUpload {
+	return models.JobUpload{
+		ID:            f.str("id"),
+		TechnicianID:  f.str("technicianId"),
+		TenantID:      f.str("tenantId"),
+		CustomerName:  f.str("customerName"),
+		Address:       f.str("address"),
+		ScheduledDate: f.time("scheduledDate"),
+		Status:        f.str("status"),
+		ReceivedAt:    f.time("receivedAt"),
+		Latitude:      f.double("latitude"),
+		Longitude:     f.double("longitude"),
+		SignatureID:   f.str("signatureId"),
+	}
+}
+
+func encodeChemical(u models.ChemicalUpload) fields {
+	lots := make([]value, len(u.Lots))
+	for i, lot := range u.Lots {
+		lots[i] = mapV(fields{
+			"number":         stringV(lot.Number),
+			"receivedAt":     timeV(lot.ReceivedAt),
+			"expirationDate": timeV(lot.ExpirationDate),
+			"quantity":       doubleV(lot.Quantity),
+		})
+	}
+	return fields{
+		"id":               stringV(u.ID),
+		"technicianId":     stringV(u.TechnicianID),
+		"tenantId":         stringV(u.TenantID),
+		"name":             stringV(u.Name),
+		"activeIngredient": stringV(u.ActiveIngredient),
+		"manufacturerName": stringV(u.ManufacturerName),
+		"epaRegistration":  stringV(u.EPARegistration),
+		"concentration":    doubleV(u.Concentration),
+		"unitOfMeasure":    stringV(u.UnitOfMeasure),
+		"quantityInStock":  doubleV(u.QuantityInStock),
+		"expirationDate":   timeV(u.ExpirationDate),
+		"lots":             arrayV(lots),
+		"lastModified":     timeV(u.LastModified),
+	}
+}
+
+func decodeChemical(f fields) models.ChemicalUpload {
+	chemical := models.ChemicalUpload{
+		ID:               f.str("id"),
+		TechnicianID:     f.str("technicianId"),
+		TenantID:         f.str("tenantId"),
+		Name:             f.str("name"),
+		ActiveIngredient: f.str("activeIngredient"),
+		ManufacturerName: f.str("manufacturerName"),
+		EPARegistration:  f.str("epaRegistration"),
+		Concentration:    f.double("concentration"),
+		UnitOfMeasure:    f.str("unitOfMeasure"),
+		QuantityInStock:  f.double("quantityInStock"),
+		ExpirationDate:   f.time("expirationDate"),
+		LastModified:     f.time("lastModified"),
+	}
+	for _, v := range f.array("lots") {
+		lot := v.fields()
+		chemical.Lots = append(chemical.Lots, models.ChemicalLot{
+			Number:         lot.str("number"),
+			ReceivedAt:     lot.time("receivedAt"),
+			ExpirationDate: lot.time("expirationDate"),
+			Quantity:       lot.double("quantity"),
+		})
+	}
+	return chemical
+}
+
+func encodeTreatment(u models.ChemicalTreatmentUpload) fields {
+	f := fields{
+		"id":                 stringV(u.ID),
+		"jobId":              stringV(u.JobID),
+		"chemicalId":         stringV(u.ChemicalID),
+		"lotNumber":          stringV(u.LotNumber),
+		"mixId":              stringV(u.MixID),
+		"equipmentId":        stringV(u.EquipmentID),
+		"technicianId":       stringV(u.TechnicianID),
+		"tenantId":           stringV(u.TenantID),
+		"applicatorName":     stringV(u.ApplicatorName),
+		"applicationDate":    timeV(u.ApplicationDate),
+		"applicationMethod":  stringV(u.ApplicationMethod),
+		"targetPests":        stringV(u.TargetPests),
+		"quantityUsed":       doubleV(u.QuantityUsed),
+		"dosageRate":         doubleV(u.DosageRate),
+		"dilutionRatio":      stringV(u.DilutionRatio),
+		"environmentalNotes": stringV(u.EnvironmentalNotes),
+		"weatherConditions":  stringV(u.WeatherConditions),
+		"notes":              stringV(u.Notes),
+		"lastModified":       timeV(u.LastModified),
+	}
+	if w := u.Weather; w != nil {
+		f["weather"] = mapV(fields{
+			"source":       stringV(w.Source),
+			"temperatureC": doubleV(w.TemperatureC),
+			"windSpeedKph": doubleV(w.WindSpeedKPH),
+			"windGustKph":  doubleV(w.WindGustKPH),
+			"summary":      stringV(w.Summary),
+			"at":           timeV(w.At),
+		})
+	}
+	return f
+}
+
+func decodeTreatment(f fields) models.ChemicalTreatmentUpload {
+	treatment := models.ChemicalTreatmentUpload{
+		ID:                 f.str("id"),
+		JobID:              f.str("jobId"),
+		ChemicalID:         f.str("chemicalId"),
+		LotNumber:          f.str("lotNumber"),
+		MixID:              f.str("mixId"),
+		EquipmentID:        f.str("equipmentId"),
+		TechnicianID:       f.str("technicianId"),
+		TenantID:           f.str("tenantId"),
+		ApplicatorName:     f.str("applicatorName"),
+		ApplicationDate:    f.time("applicationDate"),
+		ApplicationMethod:  f.str("applicationMethod"),
+		TargetPests:        f.str("targetPests"),
+		QuantityUsed:       f.double("quantityUsed"),
+		DosageRate:         f.double("dosageRate"),
+		DilutionRatio:      f.str("dilutionRatio"),
+		EnvironmentalNotes: f.str("environmentalNotes"),
+		WeatherConditions:  f.str("weatherConditions"),
+		Notes:              f.str("notes"),
+		LastModified:       f.time("lastModified"),
+	}
+	if v, ok := f["weather"]; ok && v.MapValue != nil {
+		w := v.fields()
+		treatment.Weather = &models.WeatherReading{
+			Source:       w.str("source"),
+			TemperatureC: w.double("temperatureC"),
+			WindSpeedKPH: w.double("windSpeedKph"),
+			WindGustKPH:  w.double("windGustKph"),
+			Summary:      w.str("summary"),
+			At:           w.time("at"),
+		}
+	}
+	return treatment
+}
+
+func encodeDevice(t models.DeviceToken) fields {
+	return fields{
+		"token":        stringV(t.Token),
+		"technicianId": stringV(t.TechnicianID),
+		"tenantId":     stringV(t.TenantID),
+		"platform":     stringV(t.Platform),
+		"bundleId":     stringV(t.BundleID),
+		"registeredAt": timeV(t.RegisteredAt),
+	}
+}
+
+func decodeDevice(f fields) models.DeviceToken {
+	return models.DeviceToken{
+		Token:        f.str("token"),
+		TechnicianID: f.str("technicianId"),
+		TenantID:     f.str("tenantId"),
+		Platform:     f.str("platform"),
+		BundleID:     f.str("bundleId"),
+		RegisteredAt: f.time("registeredAt"),
+	}
+}
+
+// encodeFlag keeps the definition as JSON: variant values may be any JSON
+// value, which Firestore fields cannot hold without a schema.
+func encodeFlag(f flags.StoredFlag) (fields, error) {
+	definition, err := json.Marshal(f.Flag)
+	if err != nil {
+		return nil, err
+	}
+	return fields{
+		"key":        stringV(f.Key),
+		"definition": stringV(string(definition)),
+		"updatedAt":  timeV(f.UpdatedAt),
+	}, nil
+}
+
+func decodeFlag(f fields) (flags.StoredFlag, error) {
+	stored := flags.StoredFlag{Key: f.str("key"), UpdatedAt: f.time("updatedAt")}
+	if err := json.Unmarshal([]byte(f.str("definition")), &stored.Flag); err != nil {
+		return flags.StoredFlag{}, fmt.Errorf("decode flag %s: %w", stored.Key, err)
+	}
+	return stored, nil
+}
@@ -0,0 +1,1020 @@
+// Package firestore implements the repository interfaces on Cloud Firestore
+// using its REST API.
+package firestore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/replay"
+)
+
+// Collection names.
+const (
+	technicians = "technicians"
+	routes      = "routes"
+	templates   = "screenTemplates"
+	jobs        = "jobUploads"
+	chemicals   = "chemicalUploads"
+	treatments  = "chemicalTreatments"
+	devices     = "deviceTokens"
+	flagDefs    = "featureFlags"
+	nonces      = "requestNonces"
+)
+
+// savedAt is the server write time, ordering the pending queues and delta
+// queries.
+const savedAt = "savedAt"
+
+// deletedAt marks a soft-deleted document and orders its tombstone.
+const deletedAt = "deletedAt"
+
+// processed is false while the upload worker has yet to process the
+// version of a record last written; deleting the record sets it, since
+// there is nothing left to process. Documents written before the field
+// existed have neither value and wait for their next save.
+const processed = "processed"
+
+// serverID is Route.ServerID on route documents, which the unprocessed
+// route query pages on.
+const serverID = "serverId"
+
+// Store is a Firestore-backed repository implementation.
+type Store struct {
+	client *client
+	now    func() time.Time
+}
+
+// NewStore creates a Store for cfg. When cfg.FirestoreEmulator is set the
+// store talks to the emulator; otherwise tokens come from the metadata
+// server of the runtime service account.
+func NewStore(cfg config.DatastoreConfig) (*Store, error) {
+	c, err := newClient(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: c, now: time.Now}, nil
+}
+
+// Ensure Store satisfies repository interfaces at compile time.
+var _ repository.TechnicianRepository = (*Store)(nil)
+var _ repository.RouteRepository = (*Store)(nil)
+var _ repository.ScreenRepository = (*Store)(nil)
+var _ repository.SyncRepository = (*Store)(nil)
+var _ repository.DeviceRepository = (*Store)(nil)
+var _ flags.Store = (*Store)(nil)
+var _ replay.NonceStore = (*Store)(nil)
+var _ docstore.Store = (*Store)(nil)
+
+// get wraps client.get, turning a missing document into notFound.
+func (s *Store) get(collection, id string, notFound error) (fields, error) {
+	doc, err := s.client.get(collection, id)
+	if errors.Is(err, errNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s/%s: %w", collection, id, err)
+	}
+	return doc.Fields, nil
+}
+
+// Technician operations
+
+func (s *Store) GetByID(id string) (models.Technician, error) {
+	f, err := s.get(technicians, id, repository.ErrTechnicianNotFound)
+	if err != nil {
+		return models.Technician{}, err
+	}
+	return decodeTechnician(id, f), nil
+}
+
+// AddTechnician writes a technician profile (helper for tests/dev).
+func (s *Store) AddTechnician(t models.Technician) error {
+	return s.SaveTechnician(t)
+}
+
+func (s *Store) SaveTechnician(t models.Technician) error {
+	return s.client.set(technicians, t.ID, encodeTechnician(t))
+}
+
+func (s *Store) ListTechnicians() ([]models.Technician, error) {
+	docs, err := s.client.list(technicians)
+	if err != nil {
+		return nil, fmt.Errorf("list technicians: %w", err)
+	}
+	out := make([]models.Technician, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeTechnician(doc.id(), doc.Fields))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Route operations
+
+func (s *Store) GetRoute(technicianID string, serviceDate time.Time) (models.Route, error) {
+	f, err := s.get(routes, routeID(technicianID, serviceDate), repository.ErrRouteNotFound)
+	if err != nil {
+		return models.Route{}, err
+	}
+	if deleted(f) {
+		return models.Route{}, repository.ErrRouteNotFound
+	}
+	return decodeRoute(f), nil
+}
+
+func (s *Store) SaveRoute(route models.Route) error {
+	w := s.routeWrite(route)
+	return s.client.set(w.collection, w.id, w.fields)
+}
+
+func (s *Store) routeWrite(route models.Route) write {
+	if route.LastModified.IsZero() {
+		route.LastModified = s.now()
+	}
+	f := encodeRoute(route)
+	f[serverID] = stringV(route.ServerID())
+	return write{routes, routeID(route.TechnicianID, route.ServiceDate), s.stamp(f)}
+}
+
+func (s *Store) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	return s.softDelete(routes, routeID(technicianID, serviceDate))
+}
+
+func routeID(technicianID string, serviceDate time.Time) string {
+	return technicianID + "_" + serviceDate.Format("2006-01-02")
+}
+
+// Screen operations
+
+func (s *Store) GetTemplate(id string, version int) (models.ScreenTemplate, error) {
+	f, err := s.get(templates, templateID(id, version), repository.ErrTemplateNotFound)
+	if err != nil {
+		return models.ScreenTemplate{}, err
+	}
+	return decodeTemplate(f), nil
+}
+
+func (s *Store) SaveTemplate(template models.ScreenTemplate) error {
+	if template.Version == 0 {
+		template.Version = 1
+	}
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = s.now()
+	}
+	template.UpdatedAt = s.now()
+	return s.client.set(templates, templateID(template.ID, template.Version), encodeTemplate(template))
+}
+
+func (s *Store) ListTemplates() ([]models.ScreenTemplate, error) {
+	docs, err := s.client.list(templates)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	out := make([]models.ScreenTemplate, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeTemplate(doc.Fields))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ID != out[j].ID {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+func (s *Store) DeleteTemplate(id string, version int) error {
+	return s.client.remove(templates, templateID(id, version))
+}
+
+func templateID(id string, version int) string {
+	return id + "_v" + strconv.Itoa(version)
+}
+
+// Sync operations
+
+func (s *Store) SaveJobUpload(upload models.JobUpload) error {
+	w, err := s.jobWrite(upload)
+	if err != nil {
+		return err
+	}
+	return s.client.set(w.collection, w.id, w.fields)
+}
+
+func (s *Store) jobWrite(upload models.JobUpload) (write, error) {
+	upload.ReceivedAt = s.now()
+	if upload.SignatureID == "" && upload.ID != "" {
+		doc, err := s.client.get(jobs, documentID(upload.ID))
+		switch {
+		case err == nil:
+			upload.SignatureID = decodeJob(doc.Fields).SignatureID
+		case !errors.Is(err, errNotFound):
+			return write{}, fmt.Errorf("load job: %w", err)
+		}
+	}
+	return write{jobs, documentID(upload.ID), s.stamp(encodeJob(upload))}, nil
+}
+
+func (s *Store) GetJobUpload(id string) (models.JobUpload, error) {
+	f, err := s.get(jobs, documentID(id), repository.ErrJobNotFound)
+	if err != nil {
+		return models.JobUpload{}, err
+	}
+	if deleted(f) {
+		return models.JobUpload{}, repository.ErrJobNotFound
+	}
+	job := decodeJob(f)
+	job.ReceivedAt = f.time(savedAt)
+	return job, nil
+}
+
+func (s *Store) SaveChemicalUpload(upload models.ChemicalUpload) error {
+	return s.client.set(chemicals, documentID(upload.ID), s.stamp(encodeChemical(upload)))
+}
+
+func (s *Store) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	w := s.treatmentWrite(upload)
+	return s.client.set(w.collection, w.id, w.fields)
+}
+
+func (s *Store) treatmentWrite(upload models.ChemicalTreatmentUpload) write {
+	return write{treatments, documentID(upload.ID), s.stamp(encodeTreatment(upload))}
+}
+
+// The pending queues of jobs and chemicals page like the delta queries, so
+// deleted documents are left out without cutting a page short.
+
+func (s *Store) ListPendingJobs(limit int) ([]models.JobUpload, error) {
+	docs, err := s.updates(jobs, "id", repository.Page{Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("list pending jobs: %w", err)
+	}
+	out := make([]models.JobUpload, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeJob(doc.Fields))
+	}
+	return out, nil
+}
+
+func (s *Store) ListPendingChemicals(limit int) ([]models.ChemicalUpload, error) {
+	docs, err := s.updates(chemicals, "id", repository.Page{Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("list pending chemicals: %w", err)
+	}
+	out := make([]models.ChemicalUpload, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeChemical(doc.Fields))
+	}
+	return out, nil
+}
+
+func (s *Store) ListAllJobs() ([]models.JobUpload, error) {
+	docs, err := s.client.oldest(jobs, savedAt, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list all jobs: %w", err)
+	}
+	out := make([]models.JobUpload, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeJob(doc.Fields))
+	}
+	return out, nil
+}
+
+func (s *Store) ListAllChemicals() ([]models.ChemicalUpload, error) {
+	docs, err := s.client.oldest(chemicals, savedAt, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list all chemicals: %w", err)
+	}
+	out := make([]models.ChemicalUpload, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeChemical(doc.Fields))
+	}
+	return out, nil
+}
+
+func (s *Store) ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error) {
+	docs, err := s.client.oldest(treatments, savedAt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list pending treatments: %w", err)
+	}
+	out := make([]models.ChemicalTreatmentUpload, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeTreatment(doc.Fields))
+	}
+	return out, nil
+}
+
+// Delta queries return documents written after since, with LastModified
+// (ReceivedAt for jobs) set to the server write time. Documents are keyed by
+// record ID, so each query already yields the latest version only.
+
+func (s *Store) ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error) {
+	docs, err := s.client.after(jobs, savedAt, since)
+	if err != nil {
+		return nil, fmt.Errorf("list job updates: %w", err)
+	}
+	out := make([]models.JobUpload, 0, len(docs))
+	for _, doc := range docs {
+		if deleted(doc.Fields) {
+			continue
+		}
+		job := decodeJob(doc.Fields)
+		job.ReceivedAt = doc.Fields.time(savedAt)
+		out = append(out, job)
+	}
+	return out, nil
+}
+
+func (s *Store) ListRouteUpdatesSince(since time.Time) ([]models.Route, error) {
+	docs, err := s.client.after(routes, savedAt, since)
+	if err != nil {
+		return nil, fmt.Errorf("list route updates: %w", err)
+	}
+	out := make([]models.Route, 0, len(docs))
+	for _, doc := range docs {
+		if deleted(doc.Fields) {
+			continue
+		}
+		route := decodeRoute(doc.Fields)
+		route.LastModified = doc.Fields.time(savedAt)
+		out = append(out, route)
+	}
+	return out, nil
+}
+
+func (s *Store) ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error) {
+	docs, err := s.client.after(chemicals, savedAt, since)
+	if err != nil {
+		return nil, fmt.Errorf("list chemical updates: %w", err)
+	}
+	out := make([]models.ChemicalUpload, 0, len(docs))
+	for _, doc := range docs {
+		if deleted(doc.Fields) {
+			continue
+		}
+		chemical := decodeChemical(doc.Fields)
+		chemical.LastModified = doc.Fields.time(savedAt)
+		out = append(out, chemical)
+	}
+	return out, nil
+}
+
+func (s *Store) ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error) {
+	docs, err := s.client.after(treatments, savedAt, since)
+	if err != nil {
+		return nil, fmt.Errorf("list treatment updates: %w", err)
+	}
+	out := make([]models.ChemicalTreatmentUpload, 0, len(docs))
+	for _, doc := range docs {
+		treatment := decodeTreatment(doc.Fields)
+		treatment.LastModified = doc.Fields.time(savedAt)
+		out = append(out, treatment)
+	}
+	return out, nil
+}
+
+// The paged delta queries order by savedAt and then record ID, which needs
+// a composite index on (savedAt, id) for each upload collection and
+// (savedAt, serverId) for routes. Deleted documents are skipped and the page
+// filled from the documents after them.
+
+func (s *Store) updates(collection, idField string, page repository.Page) ([]document, error) {
+	want := page.Limit
+	var out []document
+	for {
+		docs, err := s.client.pageAfter(collection, "", value{}, []string{savedAt, idField},
+			[]value{timeV(page.After), stringV(page.AfterID)}, page.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			if !deleted(doc.Fields) {
+				out = append(out, doc)
+			}
+		}
+		if want <= 0 || len(docs) < page.Limit || len(out) == want {
+			return out, nil
+		}
+		last := docs[len(docs)-1].Fields
+		page = repository.Page{After: last.time(savedAt), AfterID: last.str(idField), Limit: want - len(out)}
+	}
+}
+
+func (s *Store) ListJobUpdates(page repository.Page) ([]models.JobUpload, error) {
+	docs, err := s.updates(jobs, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list job updates: %w", err)
+	}
+	out := make([]models.JobUpload, 0, len(docs))
+	for _, doc := range docs {
+		job := decodeJob(doc.Fields)
+		job.ReceivedAt = doc.Fields.time(savedAt)
+		out = append(out, job)
+	}
+	return out, nil
+}
+
+func (s *Store) ListRouteUpdates(page repository.Page) ([]models.Route, error) {
+	docs, err := s.updates(routes, serverID, page)
+	if err != nil {
+		return nil, fmt.Errorf("list route updates: %w", err)
+	}
+	out := make([]models.Route, 0, len(docs))
+	for _, doc := range docs {
+		route := decodeRoute(doc.Fields)
+		route.LastModified = doc.Fields.time(savedAt)
+		out = append(out, route)
+	}
+	return out, nil
+}
+
+func (s *Store) ListChemicalUpdates(page repository.Page) ([]models.ChemicalUpload, error) {
+	docs, err := s.updates(chemicals, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list chemical updates: %w", err)
+	}
+	out := make([]models.ChemicalUpload, 0, len(docs))
+	for _, doc := range docs {
+		chemical := decodeChemical(doc.Fields)
+		chemical.LastModified = doc.Fields.time(savedAt)
+		out = append(out, chemical)
+	}
+	return out, nil
+}
+
+func (s *Store) ListTreatmentUpdates(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	docs, err := s.updates(treatments, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list treatment updates: %w", err)
+	}
+	out := make([]models.ChemicalTreatmentUpload, 0, len(docs))
+	for _, doc := range docs {
+		treatment := decodeTreatment(doc.Fields)
+		treatment.LastModified = doc.Fields.time(savedAt)
+		out = append(out, treatment)
+	}
+	return out, nil
+}
+
+// The unprocessed queries page through documents whose processed field is
+// false, ordered by savedAt and then record ID, which needs a composite
+// index on (processed, savedAt, id) for each upload collection and
+// (processed, savedAt, serverId) for routes.
+
+func (s *Store) unprocessed(collection, idField string, page repository.Page) ([]document, error) {
+	return s.client.pageAfter(collection, processed, boolV(false), []string{savedAt, idField},
+		[]value{timeV(page.After), stringV(page.AfterID)}, page.Limit)
+}
+
+func (s *Store) ListUnprocessedJobs(page repository.Page) ([]models.JobUpload, error) {
+	docs, err := s.unprocessed(jobs, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed jobs: %w", err)
+	}
+	out := make([]models.JobUpload, 0, len(docs))
+	for _, doc := range docs {
+		job := decodeJob(doc.Fields)
+		job.ReceivedAt = doc.Fields.time(savedAt)
+		out = append(out, job)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedChemicals(page repository.Page) ([]models.ChemicalUpload, error) {
+	docs, err := s.unprocessed(chemicals, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed chemicals: %w", err)
+	}
+	out := make([]models.ChemicalUpload, 0, len(docs))
+	for _, doc := range docs {
+		chemical := decodeChemical(doc.Fields)
+		chemical.LastModified = doc.Fields.time(savedAt)
+		out = append(out, chemical)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedTreatments(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	docs, err := s.unprocessed(treatments, "id", page)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed treatments: %w", err)
+	}
+	out := make([]models.ChemicalTreatmentUpload, 0, len(docs))
+	for _, doc := range docs {
+		treatment := decodeTreatment(doc.Fields)
+		treatment.LastModified = doc.Fields.time(savedAt)
+		out = append(out, treatment)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedRoutes(page repository.Page) ([]models.Route, error) {
+	docs, err := s.unprocessed(routes, serverID, page)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed routes: %w", err)
+	}
+	out := make([]models.Route, 0, len(docs))
+	for _, doc := range docs {
+		route := decodeRoute(doc.Fields)
+		route.LastModified = doc.Fields.time(savedAt)
+		out = append(out, route)
+	}
+	return out, nil
+}
+
+// MarkProcessed sets processed on the document unless it was written again
+// after savedAt, even between the read and the write.
+func (s *Store) MarkProcessed(kind, id string, at time.Time) error {
+	collection := map[string]string{
+		repository.UploadJob:       jobs,
+		repository.UploadChemical:  chemicals,
+		repository.UploadTreatment: treatments,
+		repository.UploadRoute:     routes,
+	}[kind]
+	if collection == "" {
+		return fmt.Errorf("mark processed: unknown upload kind %q", kind)
+	}
+	var doc document
+	var err error
+	if kind == repository.UploadRoute {
+		// Route documents are keyed by technician and date, not ServerID.
+		var docs []document
+		docs, err = s.client.equal(routes, map[string]value{serverID: stringV(id)})
+		if len(docs) > 0 {
+			doc = docs[0]
+		}
+	} else {
+		doc, err = s.client.get(collection, id)
+	}
+	if errors.Is(err, errNotFound) || err == nil && (doc.Name == "" || !doc.Fields.time(savedAt).Equal(at)) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get %s/%s: %w", collection, id, err)
+	}
+	doc.Fields[processed] = boolV(true)
+	err = s.client.replace(collection, doc.id(), doc.Fields, doc.UpdateTime)
+	if err != nil && !errors.Is(err, errChanged) {
+		return fmt.Errorf("mark %s/%s processed: %w", collection, id, err)
+	}
+	return nil
+}
+
+// Soft deletes mark the document with deletedAt; saving the record again
+// replaces the document and so restores it.
+
+func (s *Store) DeleteJob(id string) error {
+	return s.softDelete(jobs, id)
+}
+
+func (s *Store) DeleteChemical(id string) error {
+	return s.softDelete(chemicals, id)
+}
+
+func (s *Store) softDelete(collection, id string) error {
+	w, ok, err := s.deleteWrite(collection, id)
+	if err != nil || !ok {
+		return err
+	}
+	if err := s.client.set(w.collection, w.id, w.fields); err != nil {
+		return fmt.Errorf("delete %s/%s: %w", collection, id, err)
+	}
+	return nil
+}
+
+// deleteWrite returns the write that soft-deletes a document, and false
+// when it is missing or already deleted.
+func (s *Store) deleteWrite(collection, id string) (write, bool, error) {
+	doc, err := s.client.get(collection, id)
+	if errors.Is(err, errNotFound) {
+		return write{}, false, nil
+	}
+	if err != nil {
+		return write{}, false, fmt.Errorf("get %s/%s: %w", collection, id, err)
+	}
+	if deleted(doc.Fields) {
+		return write{}, false, nil
+	}
+	doc.Fields[deletedAt] = timeV(s.now())
+	doc.Fields[processed] = boolV(true)
+	return write{collection, id, doc.Fields}, true, nil
+}
+
+// SaveBatch reads what the writes depend on, such as the signatures jobs
+// keep, then sets every document in one commit.
+func (s *Store) SaveBatch(batch repository.Batch) error {
+	var writes []write
+	for _, r := range batch.Routes {
+		writes = append(writes, s.routeWrite(r))
+	}
+	for _, j := range batch.Jobs {
+		w, err := s.jobWrite(j)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, w)
+	}
+	for _, t := range batch.Treatments {
+		writes = append(writes, s.treatmentWrite(t))
+	}
+	for _, id := range batch.DeletedJobs {
+		w, ok, err := s.deleteWrite(jobs, id)
+		if err != nil {
+			return err
+		}
+		if ok {
+			writes = append(writes, w)
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	if err := s.client.commit(writes); err != nil {
+		return fmt.Errorf("save batch: %w", err)
+	}
+	return nil
+}
+
+// tombstoneCollections pairs each collection that keeps tombstones with the
+// kind its tombstones report and how to read a record's server ID and
+// technician from a document.
+var tombstoneCollections = []struct {
+	collection string
+	kind       string
+	owner      func(doc document) (id, technicianID string)
+}{
+	{jobs, models.TombstoneJob, func(doc document) (string, string) { return doc.id(), doc.Fields.str("technicianId") }},
+	{routes, models.TombstoneRoute, func(doc document) (string, string) {
+		route := decodeRoute(doc.Fields)
+		return route.ServerID(), route.TechnicianID
+	}},
+	{chemicals, models.TombstoneChemical, func(doc document) (string, string) { return doc.id(), doc.Fields.str("technicianId") }},
+}
+
+func (s *Store) ListDeletionsSince(since time.Time) ([]models.Tombstone, error) {
+	var out []models.Tombstone
+	for _, c := range tombstoneCollections {
+		docs, err := s.client.after(c.collection, deletedAt, since)
+		if err != nil {
+			return nil, fmt.Errorf("list %s deletions: %w", c.kind, err)
+		}
+		for _, doc := range docs {
+			id, technicianID := c.owner(doc)
+			out = append(out, models.Tombstone{Kind: c.kind, ID: id, TenantID: doc.Fields.str("tenantId"), TechnicianID: technicianID, DeletedAt: doc.Fields.time(deletedAt)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(out[j].DeletedAt) {
+			return out[i].DeletedAt.Before(out[j].DeletedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// ListDeletions pages each collection on (deletedAt, id), or (deletedAt,
+// serverId) for routes, which needs a composite index on each, and merges
+// the pages.
+func (s *Store) ListDeletions(page repository.Page) ([]models.Tombstone, error) {
+	var out []models.Tombstone
+	for _, c := range tombstoneCollections {
+		idField := "id"
+		if c.collection == routes {
+			idField = serverID
+		}
+		docs, err := s.client.pageAfter(c.collection, "", value{}, []string{deletedAt, idField},
+			[]value{timeV(page.After), stringV(page.AfterID)}, page.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("list %s deletions: %w", c.kind, err)
+		}
+		for _, doc := range docs {
+			id, technicianID := c.owner(doc)
+			out = append(out, models.Tombstone{Kind: c.kind, ID: id, TenantID: doc.Fields.str("tenantId"), TechnicianID: technicianID, DeletedAt: doc.Fields.time(deletedAt)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(out[j].DeletedAt) {
+			return out[i].DeletedAt.Before(out[j].DeletedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	if page.Limit > 0 && len(out) > page.Limit {
+		out = out[:page.Limit]
+	}
+	return out, nil
+}
+
+// PruneTombstones removes expired documents one at a time; a failure part
+// way leaves the rest for the next run.
+func (s *Store) PruneTombstones(cutoff time.Time) (int, error) {
+	pruned := 0
+	for _, c := range tombstoneCollections {
+		docs, err := s.client.where(c.collection, deletedAt, "LESS_THAN", timeV(cutoff))
+		if err != nil {
+			return pruned, fmt.Errorf("list expired %s tombstones: %w", c.kind, err)
+		}
+		for _, doc := range docs {
+			if err := s.client.remove(c.collection, doc.id()); err != nil && !errors.Is(err, errNotFound) {
+				return pruned, fmt.Errorf("prune %s tombstone: %w", c.kind, err)
+			}
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func deleted(f fields) bool {
+	return !f.time(deletedAt).IsZero()
+}
+
+// stamp records when a document was written so pending queues keep
+// insertion order and delta queries have a server-side watermark, and
+// queues the version for the upload worker. Uploads without an ID cannot
+// be marked processed, so they are never queued.
+func (s *Store) stamp(f fields) fields {
+	f[savedAt] = timeV(s.now())
+	f[processed] = boolV(f.str("id") == "" && f.str(serverID) == "")
+	return f
+}
+
+// documentID keeps client-supplied IDs so re-uploads overwrite rather than
+// duplicate, and generates one when the client sent none.
+func documentID(id string) string {
+	if id == "" {
+		return uuid.NewString()
+	}
+	return id
+}
+
+// Device tokens
+
+// SaveDeviceToken upserts a registration keyed by a hash of the token, so
+// re-registering a device does not create duplicates.
+func (s *Store) SaveDeviceToken(token models.DeviceToken) error {
+	if token.RegisteredAt.IsZero() {
+		token.RegisteredAt = s.now()
+	}
+	return s.client.set(devices, deviceID(token.Token), encodeDevice(token))
+}
+
+func (s *Store) ListDeviceTokens(technicianID string) ([]models.DeviceToken, error) {
+	docs, err := s.client.where(devices, "technicianId", "EQUAL", stringV(technicianID))
+	if err != nil {
+		return nil, fmt.Errorf("list device tokens: %w", err)
+	}
+	out := make([]models.DeviceToken, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeDevice(doc.Fields))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RegisteredAt.After(out[j].RegisteredAt) })
+	return out, nil
+}
+
+func (s *Store) DeleteDeviceToken(token string) error {
+	if err := s.client.remove(devices, deviceID(token)); err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("delete device token: %w", err)
+	}
+	return nil
+}
+
+// PruneDeviceTokens deletes stale registrations one document at a time; a
+// failure part way leaves the rest for the next run.
+func (s *Store) PruneDeviceTokens(cutoff time.Time) (int, error) {
+	docs, err := s.client.where(devices, "registeredAt", "LESS_THAN", timeV(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("list stale device tokens: %w", err)
+	}
+	pruned := 0
+	for _, doc := range docs {
+		if err := s.DeleteDeviceToken(doc.Fields.str("token")); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// deviceID keys registrations by a hash of the token.
+func deviceID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Feature flags
+
+// SaveFlag upserts a runtime flag definition keyed by the flag.
+func (s *Store) SaveFlag(f flags.StoredFlag) error {
+	encoded, err := encodeFlag(f)
+	if err != nil {
+		return fmt.Errorf("save flag: %w", err)
+	}
+	return s.client.set(flagDefs, f.Key, encoded)
+}
+
+func (s *Store) GetFlag(key string) (flags.StoredFlag, error) {
+	doc, err := s.client.get(flagDefs, key)
+	if errors.Is(err, errNotFound) {
+		return flags.StoredFlag{}, flags.ErrNotFound
+	}
+	if err != nil {
+		return flags.StoredFlag{}, fmt.Errorf("get flag: %w", err)
+	}
+	return decodeFlag(doc.Fields)
+}
+
+func (s *Store) ListFlags() ([]flags.StoredFlag, error) {
+	docs, err := s.client.list(flagDefs)
+	if err != nil {
+		return nil, fmt.Errorf("list flags: %w", err)
+	}
+	out := make([]flags.StoredFlag, 0, len(docs))
+	for _, doc := range docs {
+		f, err := decodeFlag(doc.Fields)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+// DeleteFlag removes a runtime flag definition. Firestore deletes succeed
+// for missing documents, so the flag is read first to report ErrNotFound.
+func (s *Store) DeleteFlag(key string) error {
+	if _, err := s.GetFlag(key); err != nil {
+		return err
+	}
+	if err := s.client.remove(flagDefs, key); err != nil {
+		return fmt.Errorf("delete flag: %w", err)
+	}
+	return nil
+}
+
+// Request nonces
+
+// ReserveNonce creates a document per nonce, so the first instance to see
+// a nonce wins. A nonce whose document has expired is taken over; a TTL
+// policy on expiresAt keeps the collection small.
+func (s *Store) ReserveNonce(nonce string, expires time.Time) (bool, error) {
+	f := fields{"expiresAt": timeV(expires)}
+	err := s.client.create(nonces, nonce, f)
+	if !errors.Is(err, errExists) {
+		if err != nil {
+			return false, fmt.Errorf("reserve nonce: %w", err)
+		}
+		return true, nil
+	}
+	doc, err := s.client.get(nonces, nonce)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return false, fmt.Errorf("reserve nonce: %w", err)
+	}
+	if err == nil && s.now().Before(doc.Fields.time("expiresAt")) {
+		return false, nil
+	}
+	if err := s.client.set(nonces, nonce, f); err != nil {
+		return false, fmt.Errorf("reserve nonce: %w", err)
+	}
+	return true, nil
+}
+
+// Documents
+
+// documentCollection keeps docstore collections apart from the
+// repositories' collections.
+func documentCollection(collection string) string {
+	return "docs_" + collection
+}
+
+// updateAttempts bounds how often UpdateDocument retries a document other
+// writers keep changing.
+const updateAttempts = 10
+
+func (s *Store) encodeDocument(doc docstore.Document) fields {
+	keys := fields{}
+	for k, v := range doc.Keys {
+		keys[k] = stringV(v)
+	}
+	return fields{"keys": mapV(keys), "body": stringV(string(doc.Body)), "updatedAt": timeV(s.now())}
+}
+
+func decodeDocument(doc document) docstore.Document {
+	out := docstore.Document{ID: doc.id(), Body: json.RawMessage(doc.Fields.str("body")), UpdatedAt: doc.Fields.time("updatedAt")}
+	if keys := doc.Fields["keys"].fields(); len(keys) > 0 {
+		out.Keys = docstore.Keys{}
+		for k := range keys {
+			out.Keys[k] = keys.str(k)
+		}
+	}
+	return out
+}
+
+func (s *Store) PutDocument(collection string, doc docstore.Document) error {
+	if err := s.client.set(documentCollection(collection), doc.ID, s.encodeDocument(doc)); err != nil {
+		return fmt.Errorf("put document: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) CreateDocument(collection string, doc docstore.Document) error {
+	err := s.client.create(documentCollection(collection), doc.ID, s.encodeDocument(doc))
+	switch {
+	case errors.Is(err, errExists):
+		return docstore.ErrExists
+	case err != nil:
+		return fmt.Errorf("create document: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetDocument(collection, id string) (docstore.Document, error) {
+	doc, err := s.client.get(documentCollection(collection), id)
+	switch {
+	case errors.Is(err, errNotFound):
+		return docstore.Document{}, docstore.ErrNotFound
+	case err != nil:
+		return docstore.Document{}, fmt.Errorf("get document: %w", err)
+	}
+	return decodeDocument(doc), nil
+}
+
+func (s *Store) FindDocuments(collection string, keys docstore.Keys) ([]docstore.Document, error) {
+	var docs []document
+	var err error
+	if len(keys) == 0 {
+		docs, err = s.client.list(documentCollection(collection))
+	} else {
+		match := make(map[string]value, len(keys))
+		for k, v := range keys {
+			match["keys."+k] = stringV(v)
+		}
+		docs, err = s.client.equal(documentCollection(collection), match)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find documents: %w", err)
+	}
+	out := make([]docstore.Document, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, decodeDocument(doc))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// UpdateDocument writes on condition that the document is unchanged since
+// it was read, or still missing, and starts over with the newer version
+// when another writer got there first.
+func (s *Store) UpdateDocument(collection, id string, fn func(current *docstore.Document) (docstore.Document, error)) (docstore.Document, error) {
+	name := documentCollection(collection)
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		var current *docstore.Document
+		doc, err := s.client.get(name, id)
+		switch {
+		case err == nil:
+			decoded := decodeDocument(doc)
+			current = &decoded
+		case !errors.Is(err, errNotFound):
+			return docstore.Document{}, fmt.Errorf("update document: %w", err)
+		}
+		next, err := fn(current)
+		if err != nil {
+			return docstore.Document{}, err
+		}
+		next.ID, next.UpdatedAt = id, s.now().UTC()
+		if current == nil {
+			err = s.client.create(name, id, s.encodeDocument(next))
+		} else {
+			err = s.client.replace(name, id, s.encodeDocument(next), doc.UpdateTime)
+		}
+		switch {
+		case err == nil:
+			return next, nil
+		case !errors.Is(err, errExists) && !errors.Is(err, errChanged):
+			return docstore.Document{}, fmt.Errorf("update document: %w", err)
+		}
+	}
+	return docstore.Document{}, apperr.Unavailable("datastore_unavailable", "the datastore is unavailable",
+		fmt.Errorf("update document %s/%s: still contended after %d attempts", collection, id, updateAttempts))
+}
+
+func (s *Store) DeleteDocument(collection, id string) error {
+	if err := s.client.remove(documentCollection(collection), id); err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("delete document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package firestore
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/storetest"
+)
+
+var testTime = time.Date(2026, 4, 2, 8, 30, 0, 0, time.UTC)
+
+// roundTrip pushes fields through the REST JSON encoding, as a write and
+// subsequent read would.
+func roundTrip(t *testing.T, f fields) fields {
+	t.Helper()
+	data, err := json.Marshal(document{Fields: f})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return doc.Fields
+}
+
+func TestRouteCodecRoundTrip(t *testing.T) {
+	route := models.Route{
+		ID:           "route-1",
+		TechnicianID: "tech-1",
+		ServiceDate:  time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC),
+		CustomerStops: []models.RouteStop{
+			{CustomerID: "c1", CustomerName: "First", WindowStart: testTime, WindowEnd: testTime.Add(time.Hour), Priority: "high", ServiceType: "termite", PropertySqFt: 2400},
+			{CustomerID: "c2", CustomerName: "Second"},
+		},
+		Alerts:       []models.RouteAlert{{Type: "weather", Message: "Rain after 2pm", Severity: "info"}},
+		LastModified: testTime,
+	}
+	if got := decodeRoute(roundTrip(t, encodeRoute(route))); !reflect.DeepEqual(got, route) {
+		t.Fatalf("route mismatch:\n got %+v\nwant %+v", got, route)
+	}
+}
+
+func TestUploadCodecRoundTrip(t *testing.T) {
+	chemical := models.ChemicalUpload{ID: "chem-1", Name: "Termidor", Concentration: 9.1, QuantityInStock: 3, ExpirationDate: testTime,
+		Lots: []models.ChemicalLot{{Number: "L-2291", ReceivedAt: testTime, ExpirationDate: testTime, Quantity: 3}}}
+	if got := decodeChemical(roundTrip(t, encodeChemical(chemical))); !reflect.DeepEqual(got, chemical) {
+		t.Fatalf("chemical mismatch: got %+v", got)
+	}
+
+	tpl := models.ScreenTemplate{ID: "route_list", Version: 3, PayloadJSON: []byte(`{"type":"list"}`), CreatedAt: testTime, UpdatedAt: testTime}
+	if got := decodeTemplate(roundTrip(t, encodeTemplate(tpl))); !reflect.DeepEqual(got, tpl) {
+		t.Fatalf("template mismatch: got %+v", got)
+	}
+
+	device := models.DeviceToken{Token: "abc", TechnicianID: "tech-1", Platform: "ios", BundleID: "com.pestgenie.app", RegisteredAt: testTime}
+	if got := decodeDevice(roundTrip(t, encodeDevice(device))); !reflect.DeepEqual(got, device) {
+		t.Fatalf("device mismatch: got %+v", got)
+	}
+}
+
+func TestFlagCodecRoundTrip(t *testing.T) {
+	f := flags.StoredFlag{Key: "gamificationMetrics", UpdatedAt: testTime, Flag: flags.Flag{
+		State: flags.StateEnabled, Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "on",
+		Rules: []flags.Rule{{Attribute: "region", In: []string{"TX"}, Variant: "off"}},
+	}}
+	encoded, err := encodeFlag(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := decodeFlag(roundTrip(t, encoded)); err != nil || !reflect.DeepEqual(got, f) {
+		t.Fatalf("flag mismatch: got %+v (%v)", got, err)
+	}
+}
+
+// newEmulatorStore returns a store on a fresh project in the Firestore
+// emulator, skipping the test when FIRESTORE_EMULATOR_HOST is unset.
+func newEmulatorStore(t *testing.T) *Store {
+	t.Helper()
+	host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if host == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set")
+	}
+	store, err := NewStore(config.DatastoreConfig{
+		FirestoreProject:  "test-" + uuid.NewString()[:8],
+		FirestoreEmulator: host,
+		RequestTimeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return store
+}
+
+// TestEmulatorConformance runs the repository suite shared by every
+// backend against the emulator.
+func TestEmulatorConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Store { return newEmulatorStore(t) })
+}
+
+// TestEmulatorDocumentConformance runs the docstore suite shared by every
+// backend against the emulator.
+func TestEmulatorDocumentConformance(t *testing.T) {
+	storetest.RunDocuments(t, func(t *testing.T) docstore.Store { return newEmulatorStore(t) })
+}
+
+func TestEmulatorTechniciansAndRoutes(t *testing.T) {
+	store := newEmulatorStore(t)
+	if _, err := store.GetByID("tech-1"); err == nil || err.Error() != "technician not found" {
+		t.Fatalf("expected technician not found, got %v", err)
+	}
+	tech := models.Technician{ID: "tech-1", DisplayName: "Maria Lopez", BranchID: "north", Certifications: []string{"QAL"}}
+	if err := store.AddTechnician(tech); err != nil {
+		t.Fatalf("add technician: %v", err)
+	}
+	if got, err := store.GetByID("tech-1"); err != nil || !reflect.DeepEqual(got, tech) {
+		t.Fatalf("unexpected technician %+v (%v)", got, err)
+	}
+	if err := store.SaveTechnician(models.Technician{ID: "tech/2"}); err != nil {
+		t.Fatalf("save technician: %v", err)
+	}
+	if list, err := store.ListTechnicians(); err != nil || len(list) != 2 || list[0].ID != "tech-1" || list[1].ID != "tech/2" {
+		t.Fatalf("unexpected technicians %+v (%v)", list, err)
+	}
+
+	route := models.Route{TechnicianID: "tech/1", ServiceDate: testTime, CustomerStops: []models.RouteStop{{CustomerID: "c1"}}}
+	if err := store.SaveRoute(route); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	got, err := store.GetRoute("tech/1", testTime.Truncate(24*time.Hour))
+	if err != nil || len(got.CustomerStops) != 1 || got.LastModified.IsZero() {
+		t.Fatalf("unexpected route %+v (%v)", got, err)
+	}
+}
+
+func TestEmulatorTemplates(t *testing.T) {
+	store := newEmulatorStore(t)
+	for _, tpl := range []models.ScreenTemplate{
+		{ID: "today", Version: 2, PayloadJSON: []byte(`{}`)},
+		{ID: "route", PayloadJSON: []byte(`{}`)},
+		{ID: "today", Version: 1, PayloadJSON: []byte(`{}`)},
+	} {
+		if err := store.SaveTemplate(tpl); err != nil {
+			t.Fatalf("save template: %v", err)
+		}
+	}
+	got, err := store.GetTemplate("route", 1)
+	if err != nil || string(got.PayloadJSON) != `{}` || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected template %+v (%v)", got, err)
+	}
+	list, err := store.ListTemplates()
+	if err != nil || len(list) != 3 {
+		t.Fatalf("unexpected templates %+v (%v)", list, err)
+	}
+	if list[0].ID != "route" || list[1].Version != 1 || list[2].Version != 2 {
+		t.Fatalf("templates not sorted: %+v", list)
+	}
+	if err := store.DeleteTemplate("today", 1); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if _, err := store.GetTemplate("today", 1); err == nil {
+		t.Fatal("expected deleted template to be gone")
+	}
+}
+
+func TestEmulatorPendingUploadsKeepOrder(t *testing.T) {
+	store := newEmulatorStore(t)
+	clock := testTime
+	store.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for _, id := range []string{"job-b", "job-a", ""} {
+		if err := store.SaveJobUpload(models.JobUpload{ID: id, TechnicianID: "tech-1"}); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+	}
+	jobs, err := store.ListPendingJobs(2)
+	if err != nil || len(jobs) != 2 || jobs[0].ID != "job-b" || jobs[1].ID != "job-a" {
+		t.Fatalf("unexpected pending jobs %+v (%v)", jobs, err)
+	}
+	if all, _ := store.ListPendingJobs(0); len(all) != 3 {
+		t.Fatalf("expected all jobs, got %d", len(all))
+	}
+
+	if err := store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t1", QuantityUsed: 2.5}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	treatments, err := store.ListPendingTreatments(0)
+	if err != nil || len(treatments) != 1 || treatments[0].QuantityUsed != 2.5 {
+		t.Fatalf("unexpected treatments %+v (%v)", treatments, err)
+	}
+	if err := store.SaveDeviceToken(models.DeviceToken{Token: "abc", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("save device token: %v", err)
+	}
+	if err := store.SaveDeviceToken(models.DeviceToken{Token: "abc", TechnicianID: "tech-1", Platform: "ios"}); err != nil {
+		t.Fatalf("re-register device token: %v", err)
+	}
+	tokens, err := store.ListDeviceTokens("tech-1")
+	if err != nil || len(tokens) != 1 || tokens[0].Platform != "ios" {
+		t.Fatalf("expected one upserted device token, got %+v (%v)", tokens, err)
+	}
+	if n, err := store.PruneDeviceTokens(time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected one pruned token, got %d (%v)", n, err)
+	}
+	if err := store.DeleteDeviceToken("abc"); err != nil {
+		t.Fatalf("deleting a missing token must succeed: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package firestore
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+// value is a Firestore typed value in REST form. Exactly one field is set.
+type value struct {
+	NullValue      *string   `json:"nullValue,omitempty"`
+	BooleanValue   *bool     `json:"booleanValue,omitempty"`
+	IntegerValue   *string   `json:"integerValue,omitempty"`
+	DoubleValue    *float64  `json:"doubleValue,omitempty"`
+	TimestampValue *string   `json:"timestampValue,omitempty"`
+	StringValue    *string   `json:"stringValue,omitempty"`
+	BytesValue     *string   `json:"bytesValue,omitempty"`
+	ArrayValue     *arrayVal `json:"arrayValue,omitempty"`
+	MapValue       *mapValue `json:"mapValue,omitempty"`
+}
+
+type arrayVal struct {
+	Values []value `json:"values,omitempty"`
+}
+
+type mapValue struct {
+	Fields fields `json:"fields,omitempty"`
+}
+
+func stringV(s string) value { return value{StringValue: &s} }
+
+func boolV(b bool) value { return value{BooleanValue: &b} }
+
+func intV(n int64) value {
+	s := strconv.FormatInt(n, 10)
+	return value{IntegerValue: &s}
+}
+
+func doubleV(f float64) value { return value{DoubleValue: &f} }
+
+func bytesV(b []byte) value {
+	s := base64.StdEncoding.EncodeToString(b)
+	return value{BytesValue: &s}
+}
+
+// timeV encodes a timestamp; zero times are stored as null so they decode
+// back to the zero value.
+func timeV(t time.Time) value {
+	if t.IsZero() {
+		null := "NULL_VALUE"
+		return value{NullValue: &null}
+	}
+	s := t.UTC().Format(time.RFC3339Nano)
+	return value{TimestampValue: &s}
+}
+
+func stringsV(items []string) value {
+	values := make([]value, len(items))
+	for i, s := range items {
+		values[i] = stringV(s)
+	}
+	return arrayV(values)
+}
+
+func arrayV(values []value) value { return value{ArrayValue: &arrayVal{Values: values}} }
+
+func mapV(f fields) value { return value{MapValue: &mapValue{Fields: f}} }
+
+// fields is a document's field map with typed accessors. Missing or
+// mistyped fields decode to the zero value.
+type fields map[string]value
+
+func (f fields) str(key string) string {
+	if v, ok := f[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (f fields) integer(key string) int64 {
+	if v, ok := f[key]; ok && v.IntegerValue != nil {
+		n, _ := strconv.ParseInt(*v.IntegerValue, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func (f fields) double(key string) float64 {
+	v, ok := f[key]
+	switch {
+	case !ok:
+		return 0
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.IntegerValue != nil:
+		// Whole numbers written by other clients may come back as integers.
+		n, _ := strconv.ParseFloat(*v.IntegerValue, 64)
+		return n
+	}
+	return 0
+}
+
+func (f fields) time(key string) time.Time {
+	if v, ok := f[key]; ok && v.TimestampValue != nil {
+		t, _ := time.Parse(time.RFC3339Nano, *v.TimestampValue)
+		return t
+	}
+	return time.Time{}
+}
+
+func (f fields) bytes(key string) []byte {
+	if v, ok := f[key]; ok && v.BytesValue != nil {
+		b, _ := base64.StdEncoding.DecodeString(*v.BytesValue)
+		return b
+	}
+	return nil
+}
+
+func (f fields) array(key string) []value {
+	if v, ok := f[key]; ok && v.ArrayValue != nil {
+		return v.ArrayValue.Values
+	}
+	return nil
+}
+
+func (f fields) strings(key string) []string {
+	values := f.array(key)
+	if values == nil {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v.StringValue != nil {
+			out = append(out, *v.StringValue)
+		}
+	}
+	return out
+}
+
+func (v value) fields() fields {
+	if v.MapValue == nil {
+		return fields{}
+	}
+	return v.MapValue.Fields
+}
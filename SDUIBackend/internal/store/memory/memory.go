@@ -1,13 +1,14 @@
 package memory
 
 import (
-	"errors"
+	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/your-org/pestgenie-sdui/internal/domain/models"
-	"github.com/your-org/pestgenie-sdui/internal/domain/repository"
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
 )
 
 // Store is a thread-safe in-memory repository implementation for local development.
@@ -20,7 +21,28 @@ type Store struct {
 	jobs        []models.JobUpload
 	chemicals   []models.ChemicalUpload
 	treatments  []models.ChemicalTreatmentUpload
-	devices     []models.DeviceToken
+	devices     map[string]models.DeviceToken // keyed by token
+
+	// Latest version of each record with the server time it was stored,
+	// backing the delta queries used by device sync.
+	routeSaved   map[routeKey]time.Time
+	routeDeleted map[routeKey]time.Time
+	// routeProcessed holds the routes whose latest version the upload
+	// worker has processed.
+	routeProcessed map[routeKey]bool
+	jobVersions    map[string]stamped[models.JobUpload]
+	chemVersions   map[string]stamped[models.ChemicalUpload]
+	treatVersions  map[string]stamped[models.ChemicalTreatmentUpload]
+}
+
+// stamped pairs a record with the server time it was written, once
+// soft-deleted the time it was deleted, and whether the upload worker has
+// processed it.
+type stamped[T any] struct {
+	value     T
+	saved     time.Time
+	deleted   time.Time
+	processed bool
 }
 
 // NewStore creates an empty in-memory store.
@@ -29,6 +51,14 @@ func NewStore() *Store {
 		technicians: make(map[string]models.Technician),
 		routes:      make(map[routeKey]models.Route),
 		templates:   make(map[string]models.ScreenTemplate),
+		devices:     make(map[string]models.DeviceToken),
+
+		routeSaved:     make(map[routeKey]time.Time),
+		routeDeleted:   make(map[routeKey]time.Time),
+		routeProcessed: make(map[routeKey]bool),
+		jobVersions:    make(map[string]stamped[models.JobUpload]),
+		chemVersions:   make(map[string]stamped[models.ChemicalUpload]),
+		treatVersions:  make(map[string]stamped[models.ChemicalTreatmentUpload]),
 	}
 }
 
@@ -52,7 +82,7 @@ func (s *Store) GetByID(id string) (models.Technician, error) {
 	defer s.mu.RUnlock()
 	tech, ok := s.technicians[id]
 	if !ok {
-		return models.Technician{}, errors.New("technician not found")
+		return models.Technician{}, repository.ErrTechnicianNotFound
 	}
 	return tech, nil
 }
@@ -64,6 +94,22 @@ func (s *Store) AddTechnician(t models.Technician) {
 	s.technicians[t.ID] = t
 }
 
+func (s *Store) SaveTechnician(t models.Technician) error {
+	s.AddTechnician(t)
+	return nil
+}
+
+func (s *Store) ListTechnicians() ([]models.Technician, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.Technician, 0, len(s.technicians))
+	for _, t := range s.technicians {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
 // Route operations
 
 func (s *Store) GetRoute(technicianID string, serviceDate time.Time) (models.Route, error) {
@@ -71,8 +117,8 @@ func (s *Store) GetRoute(technicianID string, serviceDate time.Time) (models.Rou
 	defer s.mu.RUnlock()
 	key := routeKey{technicianID: technicianID, serviceDate: serviceDate.Format("2006-01-02")}
 	route, ok := s.routes[key]
-	if !ok {
-		return models.Route{}, errors.New("route not found")
+	if _, deleted := s.routeDeleted[key]; !ok || deleted {
+		return models.Route{}, repository.ErrRouteNotFound
 	}
 	return route, nil
 }
@@ -80,11 +126,31 @@ func (s *Store) GetRoute(technicianID string, serviceDate time.Time) (models.Rou
 func (s *Store) SaveRoute(route models.Route) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.saveRoute(route)
+	return nil
+}
+
+func (s *Store) saveRoute(route models.Route) {
 	key := routeKey{technicianID: route.TechnicianID, serviceDate: route.ServiceDate.Format("2006-01-02")}
 	if route.LastModified.IsZero() {
 		route.LastModified = time.Now()
 	}
 	s.routes[key] = route
+	s.routeSaved[key] = time.Now()
+	delete(s.routeDeleted, key)
+	delete(s.routeProcessed, key)
+}
+
+func (s *Store) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := routeKey{technicianID: technicianID, serviceDate: serviceDate.Format("2006-01-02")}
+	if _, ok := s.routes[key]; !ok {
+		return nil
+	}
+	if _, deleted := s.routeDeleted[key]; !deleted {
+		s.routeDeleted[key] = time.Now()
+	}
 	return nil
 }
 
@@ -96,7 +162,7 @@ func (s *Store) GetTemplate(id string, version int) (models.ScreenTemplate, erro
 	key := templateKey(id, version)
 	tpl, ok := s.templates[key]
 	if !ok {
-		return models.ScreenTemplate{}, errors.New("template not found")
+		return models.ScreenTemplate{}, repository.ErrTemplateNotFound
 	}
 	return tpl, nil
 }
@@ -115,6 +181,29 @@ func (s *Store) SaveTemplate(template models.ScreenTemplate) error {
 	return nil
 }
 
+func (s *Store) ListTemplates() ([]models.ScreenTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.ScreenTemplate, 0, len(s.templates))
+	for _, tpl := range s.templates {
+		out = append(out, tpl)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ID != out[j].ID {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+func (s *Store) DeleteTemplate(id string, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.templates, templateKey(id, version))
+	return nil
+}
+
 func templateKey(id string, version int) string {
 	return id + "#" + strconv.Itoa(version)
 }
@@ -124,36 +213,433 @@ func templateKey(id string, version int) string {
 func (s *Store) SaveJobUpload(upload models.JobUpload) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.saveJobUpload(upload)
+	return nil
+}
+
+func (s *Store) saveJobUpload(upload models.JobUpload) {
 	upload.ReceivedAt = time.Now()
+	if prev, ok := s.jobVersions[upload.ID]; ok && upload.SignatureID == "" {
+		upload.SignatureID = prev.value.SignatureID
+	}
 	s.jobs = append(s.jobs, upload)
-	return nil
+	if upload.ID != "" {
+		s.jobVersions[upload.ID] = stamped[models.JobUpload]{value: upload, saved: upload.ReceivedAt}
+	}
+}
+
+func (s *Store) GetJobUpload(id string) (models.JobUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.jobVersions[id]
+	if !ok || !v.deleted.IsZero() {
+		return models.JobUpload{}, repository.ErrJobNotFound
+	}
+	job := v.value
+	job.ReceivedAt = v.saved
+	return job, nil
 }
 
 func (s *Store) SaveChemicalUpload(upload models.ChemicalUpload) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.chemicals = append(s.chemicals, upload)
+	if upload.ID != "" {
+		s.chemVersions[upload.ID] = stamped[models.ChemicalUpload]{value: upload, saved: time.Now()}
+	}
 	return nil
 }
 
 func (s *Store) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.treatments = append(s.treatments, upload)
+	s.saveTreatment(upload)
 	return nil
 }
 
+func (s *Store) saveTreatment(upload models.ChemicalTreatmentUpload) {
+	s.treatments = append(s.treatments, upload)
+	if upload.ID != "" {
+		s.treatVersions[upload.ID] = stamped[models.ChemicalTreatmentUpload]{value: upload, saved: time.Now()}
+	}
+}
+
 func (s *Store) ListPendingJobs(limit int) ([]models.JobUpload, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if limit <= 0 || limit > len(s.jobs) {
-		limit = len(s.jobs)
+	return pending(s.jobs, limit, func(j models.JobUpload) bool { return !s.jobVersions[j.ID].deleted.IsZero() }), nil
+}
+
+func (s *Store) ListPendingChemicals(limit int) ([]models.ChemicalUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return pending(s.chemicals, limit, func(c models.ChemicalUpload) bool { return !s.chemVersions[c.ID].deleted.IsZero() }), nil
+}
+
+func (s *Store) ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if limit <= 0 || limit > len(s.treatments) {
+		limit = len(s.treatments)
+	}
+	out := make([]models.ChemicalTreatmentUpload, limit)
+	copy(out, s.treatments[:limit])
+	return out, nil
+}
+
+func (s *Store) ListAllJobs() ([]models.JobUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.jobVersions, repository.Page{}, false, true, func(j *models.JobUpload, t time.Time) { j.ReceivedAt = t }), nil
+}
+
+func (s *Store) ListAllChemicals() ([]models.ChemicalUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.chemVersions, repository.Page{}, false, true, func(c *models.ChemicalUpload, t time.Time) { c.LastModified = t }), nil
+}
+
+// pending returns up to limit uploads from the queue, or all of them when
+// limit is not positive, leaving out those of deleted records.
+func pending[T any](queue []T, limit int, deleted func(T) bool) []T {
+	out := []T{}
+	for _, upload := range queue {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		if !deleted(upload) {
+			out = append(out, upload)
+		}
+	}
+	return out
+}
+
+// Delta queries return the latest version of each record stored after since,
+// oldest first, with LastModified (ReceivedAt for jobs) set to the server
+// write time so callers can use it as a watermark. Uploads without an ID
+// cannot be reconciled by devices and are left out.
+
+func (s *Store) ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return updatesSince(s.jobVersions, since, func(j *models.JobUpload, t time.Time) { j.ReceivedAt = t }), nil
+}
+
+func (s *Store) ListRouteUpdatesSince(since time.Time) ([]models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make(map[string]stamped[models.Route], len(s.routes))
+	for key, route := range s.routes {
+		versions[key.technicianID+"/"+key.serviceDate] = stamped[models.Route]{value: route, saved: s.routeSaved[key], deleted: s.routeDeleted[key]}
+	}
+	return updatesSince(versions, since, func(r *models.Route, t time.Time) { r.LastModified = t }), nil
+}
+
+func (s *Store) ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return updatesSince(s.chemVersions, since, func(c *models.ChemicalUpload, t time.Time) { c.LastModified = t }), nil
+}
+
+func (s *Store) ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return updatesSince(s.treatVersions, since, func(t *models.ChemicalTreatmentUpload, at time.Time) { t.LastModified = at }), nil
+}
+
+func (s *Store) ListJobUpdates(page repository.Page) ([]models.JobUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.jobVersions, page, false, false, func(j *models.JobUpload, t time.Time) { j.ReceivedAt = t }), nil
+}
+
+func (s *Store) ListRouteUpdates(page repository.Page) ([]models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.routeVersions(), page, false, false, func(r *models.Route, t time.Time) { r.LastModified = t }), nil
+}
+
+func (s *Store) ListChemicalUpdates(page repository.Page) ([]models.ChemicalUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.chemVersions, page, false, false, func(c *models.ChemicalUpload, t time.Time) { c.LastModified = t }), nil
+}
+
+func (s *Store) ListTreatmentUpdates(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.treatVersions, page, false, false, func(t *models.ChemicalTreatmentUpload, at time.Time) { t.LastModified = at }), nil
+}
+
+// The unprocessed queries page through the same versions as the delta
+// queries, leaving out those marked processed.
+
+func (s *Store) ListUnprocessedJobs(page repository.Page) ([]models.JobUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.jobVersions, page, true, false, func(j *models.JobUpload, t time.Time) { j.ReceivedAt = t }), nil
+}
+
+func (s *Store) ListUnprocessedChemicals(page repository.Page) ([]models.ChemicalUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.chemVersions, page, true, false, func(c *models.ChemicalUpload, t time.Time) { c.LastModified = t }), nil
+}
+
+func (s *Store) ListUnprocessedTreatments(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.treatVersions, page, true, false, func(t *models.ChemicalTreatmentUpload, at time.Time) { t.LastModified = at }), nil
+}
+
+func (s *Store) ListUnprocessedRoutes(page repository.Page) ([]models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return paged(s.routeVersions(), page, true, false, func(r *models.Route, t time.Time) { r.LastModified = t }), nil
+}
+
+// routeVersions returns the stored routes keyed by ServerID. Callers hold
+// s.mu.
+func (s *Store) routeVersions() map[string]stamped[models.Route] {
+	versions := make(map[string]stamped[models.Route], len(s.routes))
+	for key, route := range s.routes {
+		versions[route.ServerID()] = stamped[models.Route]{value: route, saved: s.routeSaved[key], deleted: s.routeDeleted[key], processed: s.routeProcessed[key]}
+	}
+	return versions
+}
+
+func (s *Store) MarkProcessed(kind, id string, savedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch kind {
+	case repository.UploadJob:
+		markProcessed(s.jobVersions, id, savedAt)
+	case repository.UploadChemical:
+		markProcessed(s.chemVersions, id, savedAt)
+	case repository.UploadTreatment:
+		markProcessed(s.treatVersions, id, savedAt)
+	case repository.UploadRoute:
+		for key, route := range s.routes {
+			if route.ServerID() == id && s.routeSaved[key].Equal(savedAt) {
+				s.routeProcessed[key] = true
+			}
+		}
+	default:
+		return fmt.Errorf("mark processed: unknown upload kind %q", kind)
+	}
+	return nil
+}
+
+func markProcessed[T any](versions map[string]stamped[T], id string, savedAt time.Time) {
+	if v, ok := versions[id]; ok && v.saved.Equal(savedAt) {
+		v.processed = true
+		versions[id] = v
+	}
+}
+
+// paged returns a page of the versions that are not deleted unless
+// withDeleted is set, and with unprocessed only those not marked processed
+// either.
+func paged[T any](versions map[string]stamped[T], page repository.Page, unprocessed, withDeleted bool, stamp func(*T, time.Time)) []T {
+	type entry struct {
+		key string
+		stamped[T]
+	}
+	var matched []entry
+	for key, v := range versions {
+		if !(unprocessed && v.processed) && (withDeleted || v.deleted.IsZero()) && page.Includes(v.saved, key) {
+			matched = append(matched, entry{key: key, stamped: v})
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].saved.Equal(matched[j].saved) {
+			return matched[i].saved.Before(matched[j].saved)
+		}
+		return matched[i].key < matched[j].key
+	})
+	if page.Limit > 0 && len(matched) > page.Limit {
+		matched = matched[:page.Limit]
+	}
+	out := make([]T, len(matched))
+	for i, m := range matched {
+		out[i] = m.value
+		stamp(&out[i], m.saved)
+	}
+	return out
+}
+
+// ScanTreatments copies the matching treatments under the lock and calls
+// fn outside it, so fn may use the store.
+func (s *Store) ScanTreatments(from, to time.Time, fn func(models.ChemicalTreatmentUpload) error) error {
+	s.mu.RLock()
+	var matched []models.ChemicalTreatmentUpload
+	for _, v := range s.treatVersions {
+		if at := v.value.ApplicationDate; !at.Before(from) && at.Before(to) {
+			t := v.value
+			t.LastModified = v.saved
+			matched = append(matched, t)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].ApplicationDate.Equal(matched[j].ApplicationDate) {
+			return matched[i].ApplicationDate.Before(matched[j].ApplicationDate)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	for _, t := range matched {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updatesSince[T any](versions map[string]stamped[T], since time.Time, stamp func(*T, time.Time)) []T {
+	type entry struct {
+		key string
+		stamped[T]
+	}
+	var matched []entry
+	for key, v := range versions {
+		if v.saved.After(since) && v.deleted.IsZero() {
+			matched = append(matched, entry{key: key, stamped: v})
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].saved.Equal(matched[j].saved) {
+			return matched[i].saved.Before(matched[j].saved)
+		}
+		return matched[i].key < matched[j].key
+	})
+	out := make([]T, len(matched))
+	for i, m := range matched {
+		out[i] = m.value
+		stamp(&out[i], m.saved)
+	}
+	return out
+}
+
+// Soft deletes keep the latest version with its deletion time, so the
+// record drops out of the delta queries and is listed as a tombstone until
+// pruned.
+
+func (s *Store) DeleteJob(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	softDelete(s.jobVersions, id)
+	return nil
+}
+
+func (s *Store) DeleteChemical(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	softDelete(s.chemVersions, id)
+	return nil
+}
+
+// SaveBatch makes every write under one hold of the lock, so no reader sees
+// part of the batch.
+func (s *Store) SaveBatch(batch repository.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range batch.Routes {
+		s.saveRoute(r)
+	}
+	for _, j := range batch.Jobs {
+		s.saveJobUpload(j)
+	}
+	for _, t := range batch.Treatments {
+		s.saveTreatment(t)
+	}
+	for _, id := range batch.DeletedJobs {
+		softDelete(s.jobVersions, id)
+	}
+	return nil
+}
+
+func softDelete[T any](versions map[string]stamped[T], id string) {
+	if v, ok := versions[id]; ok && v.deleted.IsZero() {
+		v.deleted = time.Now()
+		versions[id] = v
+	}
+}
+
+func (s *Store) ListDeletionsSince(since time.Time) ([]models.Tombstone, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tombstones(func(t models.Tombstone) bool { return t.DeletedAt.After(since) }), nil
+}
+
+func (s *Store) ListDeletions(page repository.Page) ([]models.Tombstone, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := s.tombstones(func(t models.Tombstone) bool { return page.Includes(t.DeletedAt, t.ID) })
+	if page.Limit > 0 && len(out) > page.Limit {
+		out = out[:page.Limit]
 	}
-	out := make([]models.JobUpload, limit)
-	copy(out, s.jobs[:limit])
 	return out, nil
 }
 
+// tombstones returns the tombstones keep accepts, by deletion time and then
+// ID. Callers hold s.mu.
+func (s *Store) tombstones(keep func(models.Tombstone) bool) []models.Tombstone {
+	var out []models.Tombstone
+	add := func(t models.Tombstone) {
+		if keep(t) {
+			out = append(out, t)
+		}
+	}
+	for id, v := range s.jobVersions {
+		if !v.deleted.IsZero() {
+			add(models.Tombstone{Kind: models.TombstoneJob, ID: id, TenantID: v.value.TenantID, TechnicianID: v.value.TechnicianID, DeletedAt: v.deleted})
+		}
+	}
+	for key, at := range s.routeDeleted {
+		route := s.routes[key]
+		add(models.Tombstone{Kind: models.TombstoneRoute, ID: route.ServerID(), TenantID: route.TenantID, TechnicianID: route.TechnicianID, DeletedAt: at})
+	}
+	for id, v := range s.chemVersions {
+		if !v.deleted.IsZero() {
+			add(models.Tombstone{Kind: models.TombstoneChemical, ID: id, TenantID: v.value.TenantID, TechnicianID: v.value.TechnicianID, DeletedAt: v.deleted})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(out[j].DeletedAt) {
+			return out[i].DeletedAt.Before(out[j].DeletedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func (s *Store) PruneTombstones(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := pruneDeleted(s.jobVersions, cutoff) + pruneDeleted(s.chemVersions, cutoff)
+	for key, at := range s.routeDeleted {
+		if at.Before(cutoff) {
+			delete(s.routes, key)
+			delete(s.routeSaved, key)
+			delete(s.routeDeleted, key)
+			delete(s.routeProcessed, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func pruneDeleted[T any](versions map[string]stamped[T], cutoff time.Time) int {
+	pruned := 0
+	for id, v := range versions {
+		if !v.deleted.IsZero() && v.deleted.Before(cutoff) {
+			delete(versions, id)
+			pruned++
+		}
+	}
+	return pruned
+}
+
 // Device tokens
 
 func (s *Store) SaveDeviceToken(token models.DeviceToken) error {
@@ -162,6 +648,39 @@ func (s *Store) SaveDeviceToken(token models.DeviceToken) error {
 	if token.RegisteredAt.IsZero() {
 		token.RegisteredAt = time.Now()
 	}
-	s.devices = append(s.devices, token)
+	s.devices[token.Token] = token
+	return nil
+}
+
+func (s *Store) ListDeviceTokens(technicianID string) ([]models.DeviceToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.DeviceToken, 0)
+	for _, token := range s.devices {
+		if token.TechnicianID == technicianID {
+			out = append(out, token)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RegisteredAt.After(out[j].RegisteredAt) })
+	return out, nil
+}
+
+func (s *Store) DeleteDeviceToken(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.devices, token)
 	return nil
 }
+
+func (s *Store) PruneDeviceTokens(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for key, token := range s.devices {
+		if token.RegisteredAt.Before(cutoff) {
+			delete(s.devices, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
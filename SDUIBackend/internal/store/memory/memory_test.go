@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(*testing.T) storetest.Store { return NewStore() })
+}
+
+func TestSnapshotSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("open without a snapshot: %v", err)
+	}
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	store.AddTechnician(models.Technician{ID: "tech-1", DisplayName: "Ada"})
+	_ = store.SaveRoute(models.Route{ID: "route-1", TechnicianID: "tech-1", ServiceDate: day})
+	_ = store.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day.AddDate(0, 0, 1)})
+	_ = store.DeleteRoute("tech-1", day.AddDate(0, 0, 1))
+	_ = store.SaveTemplate(models.ScreenTemplate{ID: "home", Version: 2, PayloadJSON: []byte(`{"version":2}`)})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Lots: []models.ChemicalLot{{Number: "L1", Quantity: 2}}})
+	_ = store.DeleteChemical("chem-1")
+	_ = store.SaveDeviceToken(models.DeviceToken{Token: "aa", TechnicianID: "tech-1"})
+	if err := store.SaveSnapshot(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	restored, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if tech, err := restored.GetByID("tech-1"); err != nil || tech.DisplayName != "Ada" {
+		t.Fatalf("expected the technician restored, got %+v %v", tech, err)
+	}
+	if _, err := restored.GetRoute("tech-1", day); err != nil {
+		t.Fatalf("expected the route restored: %v", err)
+	}
+	if tpl, err := restored.GetTemplate("home", 2); err != nil || string(tpl.PayloadJSON) != `{"version":2}` {
+		t.Fatalf("expected the template restored, got %+v %v", tpl, err)
+	}
+	jobs, _ := restored.ListJobUpdatesSince(time.Time{})
+	pending, _ := restored.ListPendingJobs(0)
+	if len(jobs) != 1 || len(pending) != 1 {
+		t.Fatalf("expected the job restored, got %d updates and %d pending", len(jobs), len(pending))
+	}
+	deletions, _ := restored.ListDeletionsSince(time.Time{})
+	if len(deletions) != 2 {
+		t.Fatalf("expected the route and chemical tombstones restored, got %+v", deletions)
+	}
+	if devices, _ := restored.ListDeviceTokens("tech-1"); len(devices) != 1 {
+		t.Fatalf("expected the device restored, got %+v", devices)
+	}
+
+	before, _ := store.MarshalSnapshot()
+	after, _ := restored.MarshalSnapshot()
+	if string(before) != string(after) {
+		t.Fatal("expected a restored store to snapshot identically")
+	}
+}
+
+func TestPersistWritesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store := NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.Persist(ctx, path, time.Millisecond, nil)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a snapshot written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if err := os.WriteFile(path, []byte(`{"version":99}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Fatal("expected an unknown snapshot version refused")
+	}
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// snapshotVersion is the format of snapshots this build writes and reads.
+const snapshotVersion = 1
+
+// snapshot is a store's contents as JSON. Entries are sorted so unchanged
+// contents encode to the same bytes.
+type snapshot struct {
+	Version     int                              `json:"version"`
+	Technicians []models.Technician              `json:"technicians"`
+	Routes      []routeEntry                     `json:"routes"`
+	Templates   []models.ScreenTemplate          `json:"templates"`
+	Devices     []models.DeviceToken             `json:"devices"`
+	Jobs        []models.JobUpload               `json:"jobs"`
+	Chemicals   []models.ChemicalUpload          `json:"chemicals"`
+	Treatments  []models.ChemicalTreatmentUpload `json:"treatments"`
+
+	JobVersions   []versionEntry[models.JobUpload]               `json:"jobVersions"`
+	ChemVersions  []versionEntry[models.ChemicalUpload]          `json:"chemicalVersions"`
+	TreatVersions []versionEntry[models.ChemicalTreatmentUpload] `json:"treatmentVersions"`
+}
+
+type routeEntry struct {
+	Route   models.Route `json:"route"`
+	Saved   time.Time    `json:"saved"`
+	Deleted time.Time    `json:"deleted"`
+}
+
+type versionEntry[T any] struct {
+	ID      string    `json:"id"`
+	Value   T         `json:"value"`
+	Saved   time.Time `json:"saved"`
+	Deleted time.Time `json:"deleted"`
+}
+
+// MarshalSnapshot encodes the store's contents as JSON.
+func (s *Store) MarshalSnapshot() ([]byte, error) {
+	s.mu.RLock()
+	snap := snapshot{
+		Version:       snapshotVersion,
+		Technicians:   make([]models.Technician, 0, len(s.technicians)),
+		Routes:        make([]routeEntry, 0, len(s.routes)),
+		Templates:     make([]models.ScreenTemplate, 0, len(s.templates)),
+		Devices:       make([]models.DeviceToken, 0, len(s.devices)),
+		Jobs:          append([]models.JobUpload(nil), s.jobs...),
+		Chemicals:     append([]models.ChemicalUpload(nil), s.chemicals...),
+		Treatments:    append([]models.ChemicalTreatmentUpload(nil), s.treatments...),
+		JobVersions:   versionEntries(s.jobVersions),
+		ChemVersions:  versionEntries(s.chemVersions),
+		TreatVersions: versionEntries(s.treatVersions),
+	}
+	for _, t := range s.technicians {
+		snap.Technicians = append(snap.Technicians, t)
+	}
+	for key, route := range s.routes {
+		snap.Routes = append(snap.Routes, routeEntry{Route: route, Saved: s.routeSaved[key], Deleted: s.routeDeleted[key]})
+	}
+	for _, tpl := range s.templates {
+		snap.Templates = append(snap.Templates, tpl)
+	}
+	for _, d := range s.devices {
+		snap.Devices = append(snap.Devices, d)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(snap.Technicians, func(i, j int) bool { return snap.Technicians[i].ID < snap.Technicians[j].ID })
+	sort.Slice(snap.Routes, func(i, j int) bool {
+		a, b := snap.Routes[i].Route, snap.Routes[j].Route
+		if a.TechnicianID != b.TechnicianID {
+			return a.TechnicianID < b.TechnicianID
+		}
+		return a.ServiceDate.Before(b.ServiceDate)
+	})
+	sort.Slice(snap.Templates, func(i, j int) bool {
+		return templateKey(snap.Templates[i].ID, snap.Templates[i].Version) < templateKey(snap.Templates[j].ID, snap.Templates[j].Version)
+	})
+	sort.Slice(snap.Devices, func(i, j int) bool { return snap.Devices[i].Token < snap.Devices[j].Token })
+	return json.Marshal(snap)
+}
+
+// UnmarshalSnapshot replaces the store's contents with a snapshot written by
+// MarshalSnapshot.
+func (s *Store) UnmarshalSnapshot(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	fresh := NewStore()
+	for _, t := range snap.Technicians {
+		fresh.technicians[t.ID] = t
+	}
+	for _, e := range snap.Routes {
+		key := routeKey{technicianID: e.Route.TechnicianID, serviceDate: e.Route.ServiceDate.Format("2006-01-02")}
+		fresh.routes[key] = e.Route
+		fresh.routeSaved[key] = e.Saved
+		if !e.Deleted.IsZero() {
+			fresh.routeDeleted[key] = e.Deleted
+		}
+	}
+	for _, tpl := range snap.Templates {
+		fresh.templates[templateKey(tpl.ID, tpl.Version)] = tpl
+	}
+	for _, d := range snap.Devices {
+		fresh.devices[d.Token] = d
+	}
+	fresh.jobs, fresh.chemicals, fresh.treatments = snap.Jobs, snap.Chemicals, snap.Treatments
+	restoreVersions(fresh.jobVersions, snap.JobVersions)
+	restoreVersions(fresh.chemVersions, snap.ChemVersions)
+	restoreVersions(fresh.treatVersions, snap.TreatVersions)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.technicians, s.routes, s.templates, s.devices = fresh.technicians, fresh.routes, fresh.templates, fresh.devices
+	s.jobs, s.chemicals, s.treatments = fresh.jobs, fresh.chemicals, fresh.treatments
+	s.routeSaved, s.routeDeleted = fresh.routeSaved, fresh.routeDeleted
+	s.jobVersions, s.chemVersions, s.treatVersions = fresh.jobVersions, fresh.chemVersions, fresh.treatVersions
+	return nil
+}
+
+func versionEntries[T any](versions map[string]stamped[T]) []versionEntry[T] {
+	out := make([]versionEntry[T], 0, len(versions))
+	for id, v := range versions {
+		out = append(out, versionEntry[T]{ID: id, Value: v.value, Saved: v.saved, Deleted: v.deleted})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func restoreVersions[T any](versions map[string]stamped[T], entries []versionEntry[T]) {
+	for _, e := range entries {
+		versions[e.ID] = stamped[T]{value: e.Value, saved: e.Saved, deleted: e.Deleted}
+	}
+}
+
+// Open returns a store holding the snapshot at path, or an empty store when
+// there is no snapshot yet.
+func Open(path string) (*Store, error) {
+	s := NewStore()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.UnmarshalSnapshot(data); err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSnapshot writes the store's contents to path. The snapshot is written
+// beside it and renamed into place, so a crash mid-write leaves the previous
+// snapshot intact.
+func (s *Store) SaveSnapshot(path string) error {
+	data, err := s.MarshalSnapshot()
+	if err != nil {
+		return err
+	}
+	return writeSnapshot(path, data)
+}
+
+func writeSnapshot(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Persist snapshots the store to path every interval until ctx is
+// cancelled, skipping writes when nothing changed since the last one.
+func (s *Store) Persist(ctx context.Context, path string, interval time.Duration, logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := s.MarshalSnapshot()
+		if err == nil && bytes.Equal(data, last) {
+			continue
+		}
+		if err == nil {
+			err = writeSnapshot(path, data)
+		}
+		if err != nil {
+			logger.Warn("snapshot memory store", slog.String("path", path), slog.Any("error", err))
+			continue
+		}
+		last = data
+	}
+}
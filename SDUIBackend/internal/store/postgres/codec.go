@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+)
+
+// Nested values are stored as JSONB. These mirror the domain types with
+// stable JSON names so renaming a Go field does not orphan stored data.
+
+type stopJSON struct {
+	CustomerID    string      `json:"customerId"`
+	CustomerName  string      `json:"customerName"`
+	Nickname      string      `json:"nickname,omitempty"`
+	Address       string      `json:"address"`
+	WindowStart   time.Time   `json:"windowStart"`
+	WindowEnd     time.Time   `json:"windowEnd"`
+	Priority      string      `json:"priority"`
+	Notes         string      `json:"notes"`
+	ServiceType   string      `json:"serviceType"`
+	PropertySqFt  int         `json:"propertySqft"`
+	Latitude      float64     `json:"latitude,omitempty"`
+	Longitude     float64     `json:"longitude,omitempty"`
+	PostalAddress *postalJSON `json:"postalAddress,omitempty"`
+}
+
+type postalJSON struct {
+	Street string `json:"street"`
+	Unit   string `json:"unit,omitempty"`
+	City   string `json:"city"`
+	State  string `json:"state"`
+	ZIP    string `json:"zip"`
+	ZIP4   string `json:"zip4,omitempty"`
+	Status string `json:"status"`
+}
+
+type alertJSON struct {
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+type lotJSON struct {
+	Number         string    `json:"number"`
+	ReceivedAt     time.Time `json:"receivedAt"`
+	ExpirationDate time.Time `json:"expirationDate"`
+	Quantity       float64   `json:"quantity"`
+}
+
+type weatherJSON struct {
+	Source       string    `json:"source"`
+	TemperatureC float64   `json:"temperatureC"`
+	WindSpeedKPH float64   `json:"windSpeedKph"`
+	WindGustKPH  float64   `json:"windGustKph,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+func encodeStops(stops []models.RouteStop) ([]byte, error) {
+	out := make([]stopJSON, len(stops))
+	for i, s := range stops {
+		out[i] = stopJSON{
+			CustomerID:   s.CustomerID,
+			CustomerName: s.CustomerName,
+			Nickname:     s.Nickname,
+			Address:      s.Address,
+			WindowStart:  s.WindowStart,
+			WindowEnd:    s.WindowEnd,
+			Priority:     s.Priority,
+			Notes:        s.Notes,
+			ServiceType:  s.ServiceType,
+			PropertySqFt: s.PropertySqFt,
+			Latitude:     s.Latitude,
+			Longitude:    s.Longitude,
+		}
+		if s.PostalAddress != nil {
+			p := postalJSON(*s.PostalAddress)
+			out[i].PostalAddress = &p
+		}
+	}
+	return json.Marshal(out)
+}
+
+func decodeStops(data []byte) ([]models.RouteStop, error) {
+	var rows []stopJSON
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	var out []models.RouteStop
+	for _, s := range rows {
+		stop := models.RouteStop{
+			CustomerID:   s.CustomerID,
+			CustomerName: s.CustomerName,
+			Nickname:     s.Nickname,
+			Address:      s.Address,
+			WindowStart:  s.WindowStart.UTC(),
+			WindowEnd:    s.WindowEnd.UTC(),
+			Priority:     s.Priority,
+			Notes:        s.Notes,
+			ServiceType:  s.ServiceType,
+			PropertySqFt: s.PropertySqFt,
+			Latitude:     s.Latitude,
+			Longitude:    s.Longitude,
+		}
+		if s.PostalAddress != nil {
+			p := models.PostalAddress(*s.PostalAddress)
+			stop.PostalAddress = &p
+		}
+		out = append(out, stop)
+	}
+	return out, nil
+}
+
+func encodeAlerts(alerts []models.RouteAlert) ([]byte, error) {
+	out := make([]alertJSON, len(alerts))
+	for i, a := range alerts {
+		out[i] = alertJSON(a)
+	}
+	return json.Marshal(out)
+}
+
+func decodeAlerts(data []byte) ([]models.RouteAlert, error) {
+	var rows []alertJSON
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	var out []models.RouteAlert
+	for _, a := range rows {
+		out = append(out, models.RouteAlert(a))
+	}
+	return out, nil
+}
+
+func encodeLots(lots []models.ChemicalLot) ([]byte, error) {
+	out := make([]lotJSON, len(lots))
+	for i, l := range lots {
+		out[i] = lotJSON(l)
+	}
+	return json.Marshal(out)
+}
+
+func decodeLots(data []byte) ([]models.ChemicalLot, error) {
+	var rows []lotJSON
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	var out []models.ChemicalLot
+	for _, l := range rows {
+		l.ReceivedAt, l.ExpirationDate = l.ReceivedAt.UTC(), l.ExpirationDate.UTC()
+		out = append(out, models.ChemicalLot(l))
+	}
+	return out, nil
+}
+
+// encodeWeather returns nil, stored as NULL, for a treatment without a
+// reading.
+func encodeWeather(w *models.WeatherReading) ([]byte, error) {
+	if w == nil {
+		return nil, nil
+	}
+	return json.Marshal(weatherJSON(*w))
+}
+
+func decodeWeather(data []byte) (*models.WeatherReading, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var row weatherJSON
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, err
+	}
+	row.At = row.At.UTC()
+	w := models.WeatherReading(row)
+	return &w, nil
+}
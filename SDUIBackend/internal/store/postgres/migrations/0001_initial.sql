@@ -0,0 +1,94 @@
+-- Initial schema for the repository interfaces. saved_at is the server write
+-- time: it orders the pending queues and is the delta sync watermark.
+
+CREATE TABLE technicians (
+    id             TEXT PRIMARY KEY,
+    email          TEXT NOT NULL DEFAULT '',
+    display_name   TEXT NOT NULL DEFAULT '',
+    role           TEXT NOT NULL DEFAULT '',
+    region         TEXT NOT NULL DEFAULT '',
+    branch_id      TEXT NOT NULL DEFAULT '',
+    certifications TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE routes (
+    technician_id  TEXT NOT NULL,
+    service_date   DATE NOT NULL,
+    id             TEXT NOT NULL DEFAULT '',
+    customer_stops JSONB NOT NULL DEFAULT '[]',
+    alerts         JSONB NOT NULL DEFAULT '[]',
+    last_modified  TIMESTAMPTZ NOT NULL,
+    saved_at       TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (technician_id, service_date)
+);
+CREATE INDEX routes_saved_at ON routes (saved_at);
+
+CREATE TABLE screen_templates (
+    id         TEXT NOT NULL,
+    version    INTEGER NOT NULL,
+    payload    BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (id, version)
+);
+
+CREATE TABLE job_uploads (
+    id             TEXT PRIMARY KEY,
+    technician_id  TEXT NOT NULL DEFAULT '',
+    customer_name  TEXT NOT NULL DEFAULT '',
+    address        TEXT NOT NULL DEFAULT '',
+    scheduled_date TIMESTAMPTZ NOT NULL,
+    status         TEXT NOT NULL DEFAULT '',
+    saved_at       TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX job_uploads_saved_at ON job_uploads (saved_at);
+
+CREATE TABLE chemical_uploads (
+    id                TEXT PRIMARY KEY,
+    technician_id     TEXT NOT NULL DEFAULT '',
+    name              TEXT NOT NULL DEFAULT '',
+    active_ingredient TEXT NOT NULL DEFAULT '',
+    manufacturer_name TEXT NOT NULL DEFAULT '',
+    epa_registration  TEXT NOT NULL DEFAULT '',
+    concentration     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit_of_measure   TEXT NOT NULL DEFAULT '',
+    quantity_in_stock DOUBLE PRECISION NOT NULL DEFAULT 0,
+    expiration_date   TIMESTAMPTZ NOT NULL,
+    lots              JSONB NOT NULL DEFAULT '[]',
+    last_modified     TIMESTAMPTZ NOT NULL,
+    saved_at          TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX chemical_uploads_saved_at ON chemical_uploads (saved_at);
+CREATE INDEX chemical_uploads_technician ON chemical_uploads (technician_id);
+
+CREATE TABLE chemical_treatments (
+    id                  TEXT PRIMARY KEY,
+    job_id              TEXT NOT NULL DEFAULT '',
+    chemical_id         TEXT NOT NULL DEFAULT '',
+    lot_number          TEXT NOT NULL DEFAULT '',
+    technician_id       TEXT NOT NULL DEFAULT '',
+    applicator_name     TEXT NOT NULL DEFAULT '',
+    application_date    TIMESTAMPTZ NOT NULL,
+    application_method  TEXT NOT NULL DEFAULT '',
+    target_pests        TEXT NOT NULL DEFAULT '',
+    quantity_used       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    dosage_rate         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    dilution_ratio      TEXT NOT NULL DEFAULT '',
+    environmental_notes TEXT NOT NULL DEFAULT '',
+    weather_conditions  TEXT NOT NULL DEFAULT '',
+    notes               TEXT NOT NULL DEFAULT '',
+    last_modified       TIMESTAMPTZ NOT NULL,
+    saved_at            TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX chemical_treatments_saved_at ON chemical_treatments (saved_at);
+CREATE INDEX chemical_treatments_lot ON chemical_treatments (lot_number) WHERE lot_number <> '';
+
+CREATE TABLE device_tokens (
+    token         TEXT PRIMARY KEY,
+    technician_id TEXT NOT NULL,
+    platform      TEXT NOT NULL DEFAULT '',
+    bundle_id     TEXT NOT NULL DEFAULT '',
+    registered_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX device_tokens_technician ON device_tokens (technician_id);
+CREATE INDEX device_tokens_registered_at ON device_tokens (registered_at);
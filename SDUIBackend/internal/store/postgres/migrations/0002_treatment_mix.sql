@@ -0,0 +1,3 @@
+-- Treatments logged as part of a tank mix reference the mix recipe.
+
+ALTER TABLE chemical_treatments ADD COLUMN mix_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Treatments record the calibrated equipment they were applied with.
+
+ALTER TABLE chemical_treatments ADD COLUMN equipment_id TEXT NOT NULL DEFAULT '';
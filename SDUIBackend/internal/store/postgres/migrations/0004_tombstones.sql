@@ -0,0 +1,10 @@
+-- Soft deletes: deleted_at marks a record devices must drop and orders its
+-- tombstone until the row is pruned.
+
+ALTER TABLE job_uploads ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE routes ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE chemical_uploads ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX job_uploads_deleted_at ON job_uploads (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX routes_deleted_at ON routes (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX chemical_uploads_deleted_at ON chemical_uploads (deleted_at) WHERE deleted_at IS NOT NULL;
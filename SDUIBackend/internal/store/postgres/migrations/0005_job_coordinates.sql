@@ -0,0 +1,5 @@
+-- Jobs carry the coordinates of their address once the worker has geocoded
+-- it; zero until then, as route stops do.
+
+ALTER TABLE job_uploads ADD COLUMN latitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE job_uploads ADD COLUMN longitude DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Treatments carry the conditions the server fetched for their stop and
+-- time, next to the weather the technician typed; NULL when none were.
+
+ALTER TABLE chemical_treatments ADD COLUMN weather JSONB;
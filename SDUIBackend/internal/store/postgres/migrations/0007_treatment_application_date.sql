@@ -0,0 +1,3 @@
+-- Usage reports scan treatments by application date.
+
+CREATE INDEX chemical_treatments_application_date ON chemical_treatments (application_date);
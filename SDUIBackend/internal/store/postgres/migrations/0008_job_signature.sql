@@ -0,0 +1,3 @@
+-- Jobs link the customer's signature, a photo, once it is captured.
+
+ALTER TABLE job_uploads ADD COLUMN signature_id TEXT NOT NULL DEFAULT '';
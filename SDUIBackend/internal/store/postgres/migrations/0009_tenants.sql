@@ -0,0 +1,16 @@
+-- Records belong to a tenant, the company whose data they are. Rows written
+-- before tenancy keep the empty tenant.
+
+ALTER TABLE technicians ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE routes ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE screen_templates ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE job_uploads ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE chemical_uploads ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE chemical_treatments ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE device_tokens ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX technicians_tenant_id ON technicians (tenant_id);
+CREATE INDEX routes_tenant_id ON routes (tenant_id, saved_at);
+CREATE INDEX job_uploads_tenant_id ON job_uploads (tenant_id, saved_at);
+CREATE INDEX chemical_uploads_tenant_id ON chemical_uploads (tenant_id, saved_at);
+CREATE INDEX chemical_treatments_tenant_id ON chemical_treatments (tenant_id, saved_at);
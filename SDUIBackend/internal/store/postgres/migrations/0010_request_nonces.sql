@@ -0,0 +1,9 @@
+-- Nonces of signed requests, shared by every instance so a request replayed
+-- against another instance is refused too. Expired rows are pruned as new
+-- nonces are reserved.
+
+CREATE TABLE request_nonces (
+    nonce      TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX request_nonces_expires_at ON request_nonces (expires_at);
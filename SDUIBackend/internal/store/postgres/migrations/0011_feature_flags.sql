@@ -0,0 +1,8 @@
+-- Feature flags defined at runtime through the admin API. definition holds
+-- the flag as JSON, as its variant values may be any JSON value.
+
+CREATE TABLE feature_flags (
+    key        TEXT PRIMARY KEY,
+    definition JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,13 @@
+-- Documents of the features stored through docstore, such as tenants and
+-- API tokens. keys holds the secondary keys each is found by.
+
+CREATE TABLE documents (
+    collection TEXT NOT NULL,
+    id         TEXT NOT NULL,
+    keys       JSONB NOT NULL DEFAULT '{}',
+    body       JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (collection, id)
+);
+
+CREATE INDEX documents_keys ON documents USING GIN (keys jsonb_path_ops);
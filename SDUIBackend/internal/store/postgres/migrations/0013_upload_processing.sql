@@ -0,0 +1,13 @@
+-- processed_at marks the version of an upload the worker has processed;
+-- saving the record again clears it. The partial indexes keep the worker's
+-- scan to the records still waiting.
+
+ALTER TABLE job_uploads ADD COLUMN processed_at TIMESTAMPTZ;
+ALTER TABLE chemical_uploads ADD COLUMN processed_at TIMESTAMPTZ;
+ALTER TABLE chemical_treatments ADD COLUMN processed_at TIMESTAMPTZ;
+ALTER TABLE routes ADD COLUMN processed_at TIMESTAMPTZ;
+
+CREATE INDEX job_uploads_unprocessed ON job_uploads (saved_at, id) WHERE processed_at IS NULL;
+CREATE INDEX chemical_uploads_unprocessed ON chemical_uploads (saved_at, id) WHERE processed_at IS NULL;
+CREATE INDEX chemical_treatments_unprocessed ON chemical_treatments (saved_at, id) WHERE processed_at IS NULL;
+CREATE INDEX routes_unprocessed ON routes (saved_at) WHERE processed_at IS NULL;
@@ -0,0 +1,11 @@
+-- Device delta sync pages on (saved_at, id) and tombstones on
+-- (deleted_at, id), so a page is an index range rather than a sort of
+-- everything since the cursor. Routes, one per technician and day, page on
+-- routes_saved_at.
+
+CREATE INDEX job_uploads_saved_at_id ON job_uploads (saved_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX chemical_uploads_saved_at_id ON chemical_uploads (saved_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX chemical_treatments_saved_at_id ON chemical_treatments (saved_at, id);
+
+CREATE INDEX job_uploads_deleted_at_id ON job_uploads (deleted_at, id) WHERE deleted_at IS NOT NULL;
+CREATE INDEX chemical_uploads_deleted_at_id ON chemical_uploads (deleted_at, id) WHERE deleted_at IS NOT NULL;
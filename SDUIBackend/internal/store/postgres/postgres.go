@@ -0,0 +1,1041 @@
+// Package postgres implements the repository interfaces on PostgreSQL (for
+// example Cloud SQL) using pgx and a connection pool.
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/apperr"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/replay"
+)
+
+// Store is a PostgreSQL-backed repository implementation.
+type Store struct {
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	now     func() time.Time
+
+	pruneMu        sync.Mutex // guards noncesPrunedAt
+	noncesPrunedAt time.Time
+}
+
+// NewStore connects a pool for cfg and, when cfg.PostgresMigrate is set,
+// brings the schema up to date before returning.
+func NewStore(ctx context.Context, cfg config.DatastoreConfig) (*Store, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.PostgresURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres url: %w", err)
+	}
+	poolCfg.MaxConns = int32(cfg.PostgresMaxConns)
+	poolCfg.MinConns = int32(cfg.PostgresMinConns)
+	if cfg.PostgresMaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.PostgresMaxConnLifetime
+	}
+	if cfg.PostgresMaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.PostgresMaxConnIdleTime
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect postgres: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+	if cfg.PostgresMigrate {
+		if _, err := Migrate(ctx, pool); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	return &Store{pool: pool, timeout: cfg.RequestTimeout, now: time.Now}, nil
+}
+
+// Close releases the pool's connections.
+func (s *Store) Close() {
+	s.pool.Close()
+}
+
+// Ensure Store satisfies repository interfaces at compile time.
+var _ repository.TechnicianRepository = (*Store)(nil)
+var _ repository.RouteRepository = (*Store)(nil)
+var _ repository.ScreenRepository = (*Store)(nil)
+var _ repository.SyncRepository = (*Store)(nil)
+var _ repository.DeviceRepository = (*Store)(nil)
+var _ flags.Store = (*Store)(nil)
+var _ replay.NonceStore = (*Store)(nil)
+var _ docstore.Store = (*Store)(nil)
+
+// ctx bounds one repository call; the interfaces predate contexts.
+func (s *Store) ctx() (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+// exec runs a statement under the call timeout.
+func (s *Store) exec(sql string, args ...any) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	_, err := s.pool.Exec(ctx, sql, args...)
+	return unavailable(err)
+}
+
+// statement is a write and its arguments, built apart from running it so
+// SaveBatch can queue it in a transaction.
+type statement struct {
+	sql  string
+	args []any
+}
+
+// collect runs a query and scans every row with scan.
+func collect[T any](s *Store, scan func(pgx.Row) (T, error), sql string, args ...any) ([]T, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	defer rows.Close()
+	out := []T{}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, unavailable(err)
+		}
+		out = append(out, v)
+	}
+	return out, unavailable(rows.Err())
+}
+
+// one runs a query expected to return a single row, turning no rows into
+// notFound.
+func one[T any](s *Store, scan func(pgx.Row) (T, error), notFound error, sql string, args ...any) (T, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	v, err := scan(s.pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var zero T
+		return zero, notFound
+	}
+	return v, unavailable(err)
+}
+
+// unavailable marks failures a retry can get past, timeouts and failed
+// connections, as apperr.Unavailable so clients are told to retry.
+func unavailable(err error) error {
+	var connect *pgconn.ConnectError
+	if err != nil && (pgconn.Timeout(err) || errors.As(err, &connect)) {
+		return apperr.Unavailable("datastore_unavailable", "the datastore is unavailable", err)
+	}
+	return err
+}
+
+// limitArg turns the repositories' "0 means all" limit into a LIMIT value.
+func limitArg(limit int) any {
+	if limit <= 0 {
+		return nil // LIMIT NULL is no limit
+	}
+	return limit
+}
+
+// Technician operations
+
+const technicianColumns = `id, email, display_name, role, region, branch_id, certifications, tenant_id`
+
+func scanTechnician(row pgx.Row) (models.Technician, error) {
+	var t models.Technician
+	err := row.Scan(&t.ID, &t.Email, &t.DisplayName, &t.Role, &t.Region, &t.BranchID, &t.Certifications, &t.TenantID)
+	if len(t.Certifications) == 0 {
+		t.Certifications = nil
+	}
+	return t, err
+}
+
+func (s *Store) GetByID(id string) (models.Technician, error) {
+	return one(s, scanTechnician, repository.ErrTechnicianNotFound, `SELECT `+technicianColumns+` FROM technicians WHERE id = $1`, id)
+}
+
+// AddTechnician writes a technician profile (helper for tests/dev).
+func (s *Store) AddTechnician(t models.Technician) error {
+	return s.SaveTechnician(t)
+}
+
+func (s *Store) SaveTechnician(t models.Technician) error {
+	certs := t.Certifications
+	if certs == nil {
+		certs = []string{}
+	}
+	return s.exec(`INSERT INTO technicians (`+technicianColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, display_name = EXCLUDED.display_name, role = EXCLUDED.role,
+			region = EXCLUDED.region, branch_id = EXCLUDED.branch_id, certifications = EXCLUDED.certifications,
+			tenant_id = EXCLUDED.tenant_id`,
+		t.ID, t.Email, t.DisplayName, t.Role, t.Region, t.BranchID, certs, t.TenantID)
+}
+
+func (s *Store) ListTechnicians() ([]models.Technician, error) {
+	out, err := collect(s, scanTechnician, `SELECT `+technicianColumns+` FROM technicians ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list technicians: %w", err)
+	}
+	return out, nil
+}
+
+// Route operations
+
+const routeColumns = `id, technician_id, service_date, customer_stops, alerts, last_modified, saved_at, tenant_id`
+
+// scanRoute reads a route; with stamped its LastModified is the server
+// write time.
+func scanRoute(stamped bool) func(pgx.Row) (models.Route, error) {
+	return func(row pgx.Row) (models.Route, error) {
+		var r models.Route
+		var stops, alerts []byte
+		var saved time.Time
+		if err := row.Scan(&r.ID, &r.TechnicianID, &r.ServiceDate, &stops, &alerts, &r.LastModified, &saved, &r.TenantID); err != nil {
+			return models.Route{}, err
+		}
+		var err error
+		if r.CustomerStops, err = decodeStops(stops); err != nil {
+			return models.Route{}, fmt.Errorf("route %s stops: %w", r.ID, err)
+		}
+		if r.Alerts, err = decodeAlerts(alerts); err != nil {
+			return models.Route{}, fmt.Errorf("route %s alerts: %w", r.ID, err)
+		}
+		r.ServiceDate, r.LastModified = r.ServiceDate.UTC(), r.LastModified.UTC()
+		if stamped {
+			r.LastModified = saved.UTC()
+		}
+		return r, nil
+	}
+}
+
+func (s *Store) GetRoute(technicianID string, serviceDate time.Time) (models.Route, error) {
+	return one(s, scanRoute(false), repository.ErrRouteNotFound,
+		`SELECT `+routeColumns+` FROM routes WHERE technician_id = $1 AND service_date = $2 AND deleted_at IS NULL`,
+		technicianID, serviceDate.Format("2006-01-02"))
+}
+
+func (s *Store) SaveRoute(route models.Route) error {
+	st, err := s.saveRoute(route)
+	if err != nil {
+		return err
+	}
+	return s.exec(st.sql, st.args...)
+}
+
+func (s *Store) saveRoute(route models.Route) (statement, error) {
+	now := s.now()
+	if route.LastModified.IsZero() {
+		route.LastModified = now
+	}
+	stops, err := encodeStops(route.CustomerStops)
+	if err != nil {
+		return statement{}, err
+	}
+	alerts, err := encodeAlerts(route.Alerts)
+	if err != nil {
+		return statement{}, err
+	}
+	return statement{`INSERT INTO routes (` + routeColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (technician_id, service_date) DO UPDATE SET id = EXCLUDED.id, customer_stops = EXCLUDED.customer_stops,
+			alerts = EXCLUDED.alerts, last_modified = EXCLUDED.last_modified, saved_at = EXCLUDED.saved_at, deleted_at = NULL,
+			processed_at = NULL, tenant_id = EXCLUDED.tenant_id`,
+		[]any{route.ID, route.TechnicianID, route.ServiceDate.Format("2006-01-02"), stops, alerts, route.LastModified, now, route.TenantID}}, nil
+}
+
+func (s *Store) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	return s.exec(`UPDATE routes SET deleted_at = $3 WHERE technician_id = $1 AND service_date = $2 AND deleted_at IS NULL`,
+		technicianID, serviceDate.Format("2006-01-02"), s.now())
+}
+
+// Screen operations
+
+const templateColumns = `id, version, payload, created_at, updated_at, tenant_id`
+
+func scanTemplate(row pgx.Row) (models.ScreenTemplate, error) {
+	var t models.ScreenTemplate
+	err := row.Scan(&t.ID, &t.Version, &t.PayloadJSON, &t.CreatedAt, &t.UpdatedAt, &t.TenantID)
+	t.CreatedAt, t.UpdatedAt = t.CreatedAt.UTC(), t.UpdatedAt.UTC()
+	return t, err
+}
+
+func (s *Store) GetTemplate(id string, version int) (models.ScreenTemplate, error) {
+	return one(s, scanTemplate, repository.ErrTemplateNotFound, `SELECT `+templateColumns+` FROM screen_templates WHERE id = $1 AND version = $2`, id, version)
+}
+
+func (s *Store) SaveTemplate(template models.ScreenTemplate) error {
+	if template.Version == 0 {
+		template.Version = 1
+	}
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = s.now()
+	}
+	template.UpdatedAt = s.now()
+	return s.exec(`INSERT INTO screen_templates (`+templateColumns+`) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id, version) DO UPDATE SET payload = EXCLUDED.payload, updated_at = EXCLUDED.updated_at, tenant_id = EXCLUDED.tenant_id`,
+		template.ID, template.Version, template.PayloadJSON, template.CreatedAt, template.UpdatedAt, template.TenantID)
+}
+
+func (s *Store) ListTemplates() ([]models.ScreenTemplate, error) {
+	out, err := collect(s, scanTemplate, `SELECT `+templateColumns+` FROM screen_templates ORDER BY id, version`)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteTemplate(id string, version int) error {
+	return s.exec(`DELETE FROM screen_templates WHERE id = $1 AND version = $2`, id, version)
+}
+
+// Sync operations
+//
+// Uploads are keyed by their client-supplied ID so re-uploads overwrite
+// rather than duplicate; saved_at is refreshed on every write, which moves
+// the record to the end of the pending queue and past device watermarks.
+
+const jobColumns = `id, technician_id, customer_name, address, scheduled_date, status, latitude, longitude, signature_id, saved_at, tenant_id`
+
+func scanJob(row pgx.Row) (models.JobUpload, error) {
+	var j models.JobUpload
+	err := row.Scan(&j.ID, &j.TechnicianID, &j.CustomerName, &j.Address, &j.ScheduledDate, &j.Status, &j.Latitude, &j.Longitude, &j.SignatureID, &j.ReceivedAt, &j.TenantID)
+	j.ScheduledDate, j.ReceivedAt = j.ScheduledDate.UTC(), j.ReceivedAt.UTC()
+	return j, err
+}
+
+func (s *Store) SaveJobUpload(upload models.JobUpload) error {
+	st := s.saveJobUpload(upload)
+	return s.exec(st.sql, st.args...)
+}
+
+func (s *Store) saveJobUpload(upload models.JobUpload) statement {
+	return statement{`INSERT INTO job_uploads (` + jobColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET tenant_id = EXCLUDED.tenant_id, technician_id = EXCLUDED.technician_id, customer_name = EXCLUDED.customer_name,
+			address = EXCLUDED.address, scheduled_date = EXCLUDED.scheduled_date, status = EXCLUDED.status,
+			latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+			signature_id = COALESCE(NULLIF(EXCLUDED.signature_id, ''), job_uploads.signature_id),
+			saved_at = EXCLUDED.saved_at, deleted_at = NULL, processed_at = NULL`,
+		[]any{recordID(upload.ID), upload.TechnicianID, upload.CustomerName, upload.Address, upload.ScheduledDate, upload.Status,
+			upload.Latitude, upload.Longitude, upload.SignatureID, s.now(), upload.TenantID}}
+}
+
+func (s *Store) GetJobUpload(id string) (models.JobUpload, error) {
+	return one(s, scanJob, repository.ErrJobNotFound, `SELECT `+jobColumns+` FROM job_uploads WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+const chemicalColumns = `id, technician_id, name, active_ingredient, manufacturer_name, epa_registration, concentration,
+	unit_of_measure, quantity_in_stock, expiration_date, lots, last_modified, saved_at, tenant_id`
+
+func scanChemical(stamped bool) func(pgx.Row) (models.ChemicalUpload, error) {
+	return func(row pgx.Row) (models.ChemicalUpload, error) {
+		var c models.ChemicalUpload
+		var lots []byte
+		var saved time.Time
+		if err := row.Scan(&c.ID, &c.TechnicianID, &c.Name, &c.ActiveIngredient, &c.ManufacturerName, &c.EPARegistration, &c.Concentration,
+			&c.UnitOfMeasure, &c.QuantityInStock, &c.ExpirationDate, &lots, &c.LastModified, &saved, &c.TenantID); err != nil {
+			return models.ChemicalUpload{}, err
+		}
+		var err error
+		if c.Lots, err = decodeLots(lots); err != nil {
+			return models.ChemicalUpload{}, fmt.Errorf("chemical %s lots: %w", c.ID, err)
+		}
+		c.ExpirationDate, c.LastModified = c.ExpirationDate.UTC(), c.LastModified.UTC()
+		if stamped {
+			c.LastModified = saved.UTC()
+		}
+		return c, nil
+	}
+}
+
+func (s *Store) SaveChemicalUpload(upload models.ChemicalUpload) error {
+	lots, err := encodeLots(upload.Lots)
+	if err != nil {
+		return err
+	}
+	return s.exec(`INSERT INTO chemical_uploads (`+chemicalColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id) DO UPDATE SET tenant_id = EXCLUDED.tenant_id, technician_id = EXCLUDED.technician_id, name = EXCLUDED.name,
+			active_ingredient = EXCLUDED.active_ingredient, manufacturer_name = EXCLUDED.manufacturer_name,
+			epa_registration = EXCLUDED.epa_registration, concentration = EXCLUDED.concentration,
+			unit_of_measure = EXCLUDED.unit_of_measure, quantity_in_stock = EXCLUDED.quantity_in_stock,
+			expiration_date = EXCLUDED.expiration_date, lots = EXCLUDED.lots, last_modified = EXCLUDED.last_modified,
+			saved_at = EXCLUDED.saved_at, deleted_at = NULL, processed_at = NULL`,
+		recordID(upload.ID), upload.TechnicianID, upload.Name, upload.ActiveIngredient, upload.ManufacturerName, upload.EPARegistration,
+		upload.Concentration, upload.UnitOfMeasure, upload.QuantityInStock, upload.ExpirationDate, lots, upload.LastModified, s.now(), upload.TenantID)
+}
+
+const treatmentColumns = `id, job_id, chemical_id, lot_number, mix_id, equipment_id, technician_id, applicator_name, application_date,
+	application_method, target_pests, quantity_used, dosage_rate, dilution_ratio, environmental_notes,
+	weather_conditions, weather, notes, last_modified, saved_at, tenant_id`
+
+func scanTreatment(stamped bool) func(pgx.Row) (models.ChemicalTreatmentUpload, error) {
+	return func(row pgx.Row) (models.ChemicalTreatmentUpload, error) {
+		var t models.ChemicalTreatmentUpload
+		var saved time.Time
+		var weather []byte
+		if err := row.Scan(&t.ID, &t.JobID, &t.ChemicalID, &t.LotNumber, &t.MixID, &t.EquipmentID, &t.TechnicianID, &t.ApplicatorName, &t.ApplicationDate,
+			&t.ApplicationMethod, &t.TargetPests, &t.QuantityUsed, &t.DosageRate, &t.DilutionRatio, &t.EnvironmentalNotes,
+			&t.WeatherConditions, &weather, &t.Notes, &t.LastModified, &saved, &t.TenantID); err != nil {
+			return models.ChemicalTreatmentUpload{}, err
+		}
+		var err error
+		if t.Weather, err = decodeWeather(weather); err != nil {
+			return models.ChemicalTreatmentUpload{}, fmt.Errorf("treatment %s weather: %w", t.ID, err)
+		}
+		t.ApplicationDate, t.LastModified = t.ApplicationDate.UTC(), t.LastModified.UTC()
+		if stamped {
+			t.LastModified = saved.UTC()
+		}
+		return t, nil
+	}
+}
+
+func (s *Store) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	st, err := s.saveTreatment(upload)
+	if err != nil {
+		return err
+	}
+	return s.exec(st.sql, st.args...)
+}
+
+func (s *Store) saveTreatment(upload models.ChemicalTreatmentUpload) (statement, error) {
+	weather, err := encodeWeather(upload.Weather)
+	if err != nil {
+		return statement{}, err
+	}
+	return statement{`INSERT INTO chemical_treatments (` + treatmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		ON CONFLICT (id) DO UPDATE SET tenant_id = EXCLUDED.tenant_id, job_id = EXCLUDED.job_id, chemical_id = EXCLUDED.chemical_id,
+			lot_number = EXCLUDED.lot_number, mix_id = EXCLUDED.mix_id,
+			equipment_id = EXCLUDED.equipment_id, technician_id = EXCLUDED.technician_id,
+			applicator_name = EXCLUDED.applicator_name, application_date = EXCLUDED.application_date,
+			application_method = EXCLUDED.application_method, target_pests = EXCLUDED.target_pests,
+			quantity_used = EXCLUDED.quantity_used, dosage_rate = EXCLUDED.dosage_rate,
+			dilution_ratio = EXCLUDED.dilution_ratio, environmental_notes = EXCLUDED.environmental_notes,
+			weather_conditions = EXCLUDED.weather_conditions, weather = EXCLUDED.weather, notes = EXCLUDED.notes,
+			last_modified = EXCLUDED.last_modified, saved_at = EXCLUDED.saved_at, processed_at = NULL`,
+		[]any{recordID(upload.ID), upload.JobID, upload.ChemicalID, upload.LotNumber, upload.MixID, upload.EquipmentID, upload.TechnicianID, upload.ApplicatorName,
+			upload.ApplicationDate, upload.ApplicationMethod, upload.TargetPests, upload.QuantityUsed, upload.DosageRate,
+			upload.DilutionRatio, upload.EnvironmentalNotes, upload.WeatherConditions, weather, upload.Notes, upload.LastModified, s.now(), upload.TenantID}}, nil
+}
+
+func (s *Store) ListPendingJobs(limit int) ([]models.JobUpload, error) {
+	out, err := collect(s, scanJob, `SELECT `+jobColumns+` FROM job_uploads WHERE deleted_at IS NULL ORDER BY saved_at, id LIMIT $1`, limitArg(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list pending jobs: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListPendingChemicals(limit int) ([]models.ChemicalUpload, error) {
+	out, err := collect(s, scanChemical(false), `SELECT `+chemicalColumns+` FROM chemical_uploads WHERE deleted_at IS NULL ORDER BY saved_at, id LIMIT $1`, limitArg(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list pending chemicals: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListAllJobs() ([]models.JobUpload, error) {
+	out, err := collect(s, scanJob, `SELECT `+jobColumns+` FROM job_uploads ORDER BY saved_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list all jobs: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListAllChemicals() ([]models.ChemicalUpload, error) {
+	out, err := collect(s, scanChemical(false), `SELECT `+chemicalColumns+` FROM chemical_uploads ORDER BY saved_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list all chemicals: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error) {
+	out, err := collect(s, scanTreatment(false), `SELECT `+treatmentColumns+` FROM chemical_treatments ORDER BY saved_at, id LIMIT $1`, limitArg(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list pending treatments: %w", err)
+	}
+	return out, nil
+}
+
+// ScanTreatments streams treatments row by row. The call timeout does not
+// apply, since a scan lasts as long as fn takes over its rows.
+func (s *Store) ScanTreatments(from, to time.Time, fn func(models.ChemicalTreatmentUpload) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows, err := s.pool.Query(ctx, `SELECT `+treatmentColumns+` FROM chemical_treatments
+		WHERE application_date >= $1 AND application_date < $2 ORDER BY application_date, id`, from, to)
+	if err != nil {
+		return fmt.Errorf("scan treatments: %w", err)
+	}
+	defer rows.Close()
+	scan := scanTreatment(true)
+	for rows.Next() {
+		t, err := scan(rows)
+		if err != nil {
+			return fmt.Errorf("scan treatments: %w", err)
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("scan treatments: %w", err)
+	}
+	return nil
+}
+
+// Delta queries return rows written after since, oldest first, with
+// LastModified (ReceivedAt for jobs) set to the server write time.
+
+func (s *Store) ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error) {
+	out, err := collect(s, scanJob, `SELECT `+jobColumns+` FROM job_uploads WHERE saved_at > $1 AND deleted_at IS NULL ORDER BY saved_at, id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list job updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListRouteUpdatesSince(since time.Time) ([]models.Route, error) {
+	out, err := collect(s, scanRoute(true), `SELECT `+routeColumns+` FROM routes WHERE saved_at > $1 AND deleted_at IS NULL ORDER BY saved_at, technician_id, service_date`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list route updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error) {
+	out, err := collect(s, scanChemical(true), `SELECT `+chemicalColumns+` FROM chemical_uploads WHERE saved_at > $1 AND deleted_at IS NULL ORDER BY saved_at, id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list chemical updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error) {
+	out, err := collect(s, scanTreatment(true), `SELECT `+treatmentColumns+` FROM chemical_treatments WHERE saved_at > $1 ORDER BY saved_at, id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list treatment updates: %w", err)
+	}
+	return out, nil
+}
+
+// The paged queries page on (saved_at, id), which the indexes of
+// migrations 0013 and 0014 cover; routes page on their server ID.
+
+// routeServerID is Route.ServerID in SQL.
+const routeServerID = `COALESCE(NULLIF(id, ''), technician_id || '_' || to_char(service_date, 'YYYY-MM-DD'))`
+
+// pageArgs returns the arguments of a query whose $1, $2 and $3 are a page's
+// start and limit.
+func pageArgs(page repository.Page) []any {
+	return []any{page.After, page.AfterID, limitArg(page.Limit)}
+}
+
+func (s *Store) ListJobUpdates(page repository.Page) ([]models.JobUpload, error) {
+	out, err := collect(s, scanJob, `SELECT `+jobColumns+` FROM job_uploads
+		WHERE deleted_at IS NULL AND (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list job updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListRouteUpdates(page repository.Page) ([]models.Route, error) {
+	out, err := collect(s, scanRoute(true), `SELECT `+routeColumns+` FROM routes
+		WHERE deleted_at IS NULL AND (saved_at, `+routeServerID+`) > ($1, $2)
+		ORDER BY saved_at, `+routeServerID+` LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list route updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListChemicalUpdates(page repository.Page) ([]models.ChemicalUpload, error) {
+	out, err := collect(s, scanChemical(true), `SELECT `+chemicalColumns+` FROM chemical_uploads
+		WHERE deleted_at IS NULL AND (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list chemical updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListTreatmentUpdates(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	out, err := collect(s, scanTreatment(true), `SELECT `+treatmentColumns+` FROM chemical_treatments
+		WHERE (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list treatment updates: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedJobs(page repository.Page) ([]models.JobUpload, error) {
+	out, err := collect(s, scanJob, `SELECT `+jobColumns+` FROM job_uploads
+		WHERE processed_at IS NULL AND deleted_at IS NULL AND (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed jobs: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedChemicals(page repository.Page) ([]models.ChemicalUpload, error) {
+	out, err := collect(s, scanChemical(true), `SELECT `+chemicalColumns+` FROM chemical_uploads
+		WHERE processed_at IS NULL AND deleted_at IS NULL AND (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed chemicals: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedTreatments(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	out, err := collect(s, scanTreatment(true), `SELECT `+treatmentColumns+` FROM chemical_treatments
+		WHERE processed_at IS NULL AND (saved_at, id) > ($1, $2) ORDER BY saved_at, id LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed treatments: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListUnprocessedRoutes(page repository.Page) ([]models.Route, error) {
+	out, err := collect(s, scanRoute(true), `SELECT `+routeColumns+` FROM routes
+		WHERE processed_at IS NULL AND deleted_at IS NULL AND (saved_at, `+routeServerID+`) > ($1, $2)
+		ORDER BY saved_at, `+routeServerID+` LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list unprocessed routes: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) MarkProcessed(kind, id string, savedAt time.Time) error {
+	var sql string
+	switch kind {
+	case repository.UploadJob:
+		sql = `UPDATE job_uploads SET processed_at = $3 WHERE id = $1 AND saved_at = $2`
+	case repository.UploadChemical:
+		sql = `UPDATE chemical_uploads SET processed_at = $3 WHERE id = $1 AND saved_at = $2`
+	case repository.UploadTreatment:
+		sql = `UPDATE chemical_treatments SET processed_at = $3 WHERE id = $1 AND saved_at = $2`
+	case repository.UploadRoute:
+		sql = `UPDATE routes SET processed_at = $3 WHERE ` + routeServerID + ` = $1 AND saved_at = $2`
+	default:
+		return fmt.Errorf("mark processed: unknown upload kind %q", kind)
+	}
+	return s.exec(sql, id, savedAt, s.now())
+}
+
+// Soft deletes set deleted_at; saving the record again clears it.
+
+func (s *Store) DeleteJob(id string) error {
+	st := s.deleteJob(id)
+	return s.exec(st.sql, st.args...)
+}
+
+func (s *Store) deleteJob(id string) statement {
+	return statement{`UPDATE job_uploads SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, []any{id, s.now()}}
+}
+
+func (s *Store) DeleteChemical(id string) error {
+	return s.exec(`UPDATE chemical_uploads SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, s.now())
+}
+
+// SaveBatch runs the batch's writes in one transaction.
+func (s *Store) SaveBatch(b repository.Batch) error {
+	var batch pgx.Batch
+	for _, r := range b.Routes {
+		st, err := s.saveRoute(r)
+		if err != nil {
+			return err
+		}
+		batch.Queue(st.sql, st.args...)
+	}
+	for _, j := range b.Jobs {
+		st := s.saveJobUpload(j)
+		batch.Queue(st.sql, st.args...)
+	}
+	for _, t := range b.Treatments {
+		st, err := s.saveTreatment(t)
+		if err != nil {
+			return err
+		}
+		batch.Queue(st.sql, st.args...)
+	}
+	for _, id := range b.DeletedJobs {
+		st := s.deleteJob(id)
+		batch.Queue(st.sql, st.args...)
+	}
+
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("save batch: %w", unavailable(err))
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.SendBatch(ctx, &batch).Close(); err != nil {
+		return fmt.Errorf("save batch: %w", unavailable(err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("save batch: %w", unavailable(err))
+	}
+	return nil
+}
+
+func scanTombstone(row pgx.Row) (models.Tombstone, error) {
+	var t models.Tombstone
+	err := row.Scan(&t.Kind, &t.ID, &t.TenantID, &t.TechnicianID, &t.DeletedAt)
+	t.DeletedAt = t.DeletedAt.UTC()
+	return t, err
+}
+
+func (s *Store) ListDeletionsSince(since time.Time) ([]models.Tombstone, error) {
+	// Route server IDs fall back to technician and date as in
+	// models.Route.ServerID.
+	out, err := collect(s, scanTombstone, `
+		SELECT 'job', id, tenant_id, technician_id, deleted_at FROM job_uploads WHERE deleted_at > $1
+		UNION ALL
+		SELECT 'route', CASE WHEN id <> '' THEN id ELSE technician_id || '_' || to_char(service_date, 'YYYY-MM-DD') END,
+			tenant_id, technician_id, deleted_at FROM routes WHERE deleted_at > $1
+		UNION ALL
+		SELECT 'chemical', id, tenant_id, technician_id, deleted_at FROM chemical_uploads WHERE deleted_at > $1
+		ORDER BY 5, 2`, since)
+	if err != nil {
+		return nil, fmt.Errorf("list deletions: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) ListDeletions(page repository.Page) ([]models.Tombstone, error) {
+	out, err := collect(s, scanTombstone, `
+		SELECT 'job', id, tenant_id, technician_id, deleted_at FROM job_uploads WHERE (deleted_at, id) > ($1, $2)
+		UNION ALL
+		SELECT 'route', `+routeServerID+`, tenant_id, technician_id, deleted_at FROM routes WHERE (deleted_at, `+routeServerID+`) > ($1, $2)
+		UNION ALL
+		SELECT 'chemical', id, tenant_id, technician_id, deleted_at FROM chemical_uploads WHERE (deleted_at, id) > ($1, $2)
+		ORDER BY 5, 2 LIMIT $3`, pageArgs(page)...)
+	if err != nil {
+		return nil, fmt.Errorf("list deletions: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) PruneTombstones(cutoff time.Time) (int, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	pruned := 0
+	for _, table := range []string{"job_uploads", "routes", "chemical_uploads"} {
+		tag, err := s.pool.Exec(ctx, `DELETE FROM `+table+` WHERE deleted_at < $1`, cutoff)
+		if err != nil {
+			return pruned, fmt.Errorf("prune %s tombstones: %w", table, err)
+		}
+		pruned += int(tag.RowsAffected())
+	}
+	return pruned, nil
+}
+
+// recordID keeps client-supplied IDs and generates one when the client sent
+// none.
+func recordID(id string) string {
+	if id == "" {
+		return uuid.NewString()
+	}
+	return id
+}
+
+// Device tokens
+
+const deviceColumns = `token, technician_id, platform, bundle_id, registered_at, tenant_id`
+
+func scanDevice(row pgx.Row) (models.DeviceToken, error) {
+	var d models.DeviceToken
+	err := row.Scan(&d.Token, &d.TechnicianID, &d.Platform, &d.BundleID, &d.RegisteredAt, &d.TenantID)
+	d.RegisteredAt = d.RegisteredAt.UTC()
+	return d, err
+}
+
+func (s *Store) SaveDeviceToken(token models.DeviceToken) error {
+	if token.RegisteredAt.IsZero() {
+		token.RegisteredAt = s.now()
+	}
+	return s.exec(`INSERT INTO device_tokens (`+deviceColumns+`) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (token) DO UPDATE SET tenant_id = EXCLUDED.tenant_id, technician_id = EXCLUDED.technician_id, platform = EXCLUDED.platform,
+			bundle_id = EXCLUDED.bundle_id, registered_at = EXCLUDED.registered_at`,
+		token.Token, token.TechnicianID, token.Platform, token.BundleID, token.RegisteredAt, token.TenantID)
+}
+
+func (s *Store) ListDeviceTokens(technicianID string) ([]models.DeviceToken, error) {
+	out, err := collect(s, scanDevice, `SELECT `+deviceColumns+` FROM device_tokens WHERE technician_id = $1 ORDER BY registered_at DESC`, technicianID)
+	if err != nil {
+		return nil, fmt.Errorf("list device tokens: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteDeviceToken(token string) error {
+	if err := s.exec(`DELETE FROM device_tokens WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("delete device token: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) PruneDeviceTokens(cutoff time.Time) (int, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tag, err := s.pool.Exec(ctx, `DELETE FROM device_tokens WHERE registered_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("prune device tokens: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Feature flags
+
+func scanFlag(row pgx.Row) (flags.StoredFlag, error) {
+	var f flags.StoredFlag
+	var definition []byte
+	if err := row.Scan(&f.Key, &definition, &f.UpdatedAt); err != nil {
+		return flags.StoredFlag{}, err
+	}
+	f.UpdatedAt = f.UpdatedAt.UTC()
+	if err := json.Unmarshal(definition, &f.Flag); err != nil {
+		return flags.StoredFlag{}, fmt.Errorf("decode flag %s: %w", f.Key, err)
+	}
+	return f, nil
+}
+
+// SaveFlag upserts a runtime flag definition keyed by the flag.
+func (s *Store) SaveFlag(f flags.StoredFlag) error {
+	definition, err := json.Marshal(f.Flag)
+	if err != nil {
+		return fmt.Errorf("save flag: %w", err)
+	}
+	if err := s.exec(`INSERT INTO feature_flags (key, definition, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET definition = EXCLUDED.definition, updated_at = EXCLUDED.updated_at`,
+		f.Key, definition, f.UpdatedAt); err != nil {
+		return fmt.Errorf("save flag: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetFlag(key string) (flags.StoredFlag, error) {
+	f, err := one(s, scanFlag, flags.ErrNotFound, `SELECT key, definition, updated_at FROM feature_flags WHERE key = $1`, key)
+	if err != nil && !errors.Is(err, flags.ErrNotFound) {
+		return flags.StoredFlag{}, fmt.Errorf("get flag: %w", err)
+	}
+	return f, err
+}
+
+func (s *Store) ListFlags() ([]flags.StoredFlag, error) {
+	out, err := collect(s, scanFlag, `SELECT key, definition, updated_at FROM feature_flags ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("list flags: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteFlag(key string) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tag, err := s.pool.Exec(ctx, `DELETE FROM feature_flags WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("delete flag: %w", unavailable(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return flags.ErrNotFound
+	}
+	return nil
+}
+
+// Request nonces
+
+// noncePruneInterval is how often ReserveNonce drops expired nonces.
+const noncePruneInterval = time.Minute
+
+// ReserveNonce inserts a row per nonce, so the first instance to see a
+// nonce wins. A nonce whose row has expired is taken over.
+func (s *Store) ReserveNonce(nonce string, expires time.Time) (bool, error) {
+	if err := s.pruneNonces(); err != nil {
+		return false, fmt.Errorf("prune nonces: %w", err)
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tag, err := s.pool.Exec(ctx, `INSERT INTO request_nonces (nonce, expires_at) VALUES ($1, $2)
+		ON CONFLICT (nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at WHERE request_nonces.expires_at <= $3`,
+		nonce, expires, s.now())
+	if err != nil {
+		return false, fmt.Errorf("reserve nonce: %w", unavailable(err))
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// pruneNonces deletes expired nonces at most once per noncePruneInterval.
+func (s *Store) pruneNonces() error {
+	s.pruneMu.Lock()
+	now := s.now()
+	due := now.Sub(s.noncesPrunedAt) >= noncePruneInterval
+	if due {
+		s.noncesPrunedAt = now
+	}
+	s.pruneMu.Unlock()
+	if !due {
+		return nil
+	}
+	return s.exec(`DELETE FROM request_nonces WHERE expires_at <= $1`, now)
+}
+
+// Documents
+
+func scanDocument(row pgx.Row) (docstore.Document, error) {
+	var doc docstore.Document
+	var keys, body []byte
+	if err := row.Scan(&doc.ID, &keys, &body, &doc.UpdatedAt); err != nil {
+		return docstore.Document{}, err
+	}
+	doc.UpdatedAt = doc.UpdatedAt.UTC()
+	doc.Body = body
+	if err := json.Unmarshal(keys, &doc.Keys); err != nil {
+		return docstore.Document{}, fmt.Errorf("decode document %s keys: %w", doc.ID, err)
+	}
+	return doc, nil
+}
+
+// documentKeys encodes a document's keys; a document without keys is
+// stored with an empty object so FindDocuments can match any document.
+func documentKeys(keys docstore.Keys) ([]byte, error) {
+	if keys == nil {
+		keys = docstore.Keys{}
+	}
+	return json.Marshal(keys)
+}
+
+func (s *Store) PutDocument(collection string, doc docstore.Document) error {
+	keys, err := documentKeys(doc.Keys)
+	if err != nil {
+		return fmt.Errorf("put document: %w", err)
+	}
+	if err := s.exec(`INSERT INTO documents (collection, id, keys, body, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (collection, id) DO UPDATE SET keys = EXCLUDED.keys, body = EXCLUDED.body, updated_at = EXCLUDED.updated_at`,
+		collection, doc.ID, keys, []byte(doc.Body), s.now()); err != nil {
+		return fmt.Errorf("put document: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) CreateDocument(collection string, doc docstore.Document) error {
+	keys, err := documentKeys(doc.Keys)
+	if err != nil {
+		return fmt.Errorf("create document: %w", err)
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tag, err := s.pool.Exec(ctx, `INSERT INTO documents (collection, id, keys, body, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (collection, id) DO NOTHING`, collection, doc.ID, keys, []byte(doc.Body), s.now())
+	if err != nil {
+		return fmt.Errorf("create document: %w", unavailable(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return docstore.ErrExists
+	}
+	return nil
+}
+
+func (s *Store) GetDocument(collection, id string) (docstore.Document, error) {
+	doc, err := one(s, scanDocument, docstore.ErrNotFound, `SELECT id, keys, body, updated_at FROM documents WHERE collection = $1 AND id = $2`, collection, id)
+	if err != nil && !errors.Is(err, docstore.ErrNotFound) {
+		return docstore.Document{}, fmt.Errorf("get document: %w", err)
+	}
+	return doc, err
+}
+
+func (s *Store) FindDocuments(collection string, keys docstore.Keys) ([]docstore.Document, error) {
+	want, err := documentKeys(keys)
+	if err != nil {
+		return nil, fmt.Errorf("find documents: %w", err)
+	}
+	out, err := collect(s, scanDocument, `SELECT id, keys, body, updated_at FROM documents
+		WHERE collection = $1 AND keys @> $2 ORDER BY id`, collection, want)
+	if err != nil {
+		return nil, fmt.Errorf("find documents: %w", err)
+	}
+	return out, nil
+}
+
+// UpdateDocument locks the document's row for the update. A missing
+// document has no row to lock, so when another instance inserts it first
+// the update starts over with that version.
+func (s *Store) UpdateDocument(collection, id string, fn func(current *docstore.Document) (docstore.Document, error)) (docstore.Document, error) {
+	for {
+		doc, done, err := s.updateDocument(collection, id, fn)
+		if err != nil {
+			return docstore.Document{}, err
+		}
+		if done {
+			return doc, nil
+		}
+	}
+}
+
+func (s *Store) updateDocument(collection, id string, fn func(current *docstore.Document) (docstore.Document, error)) (doc docstore.Document, done bool, err error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return docstore.Document{}, false, fmt.Errorf("update document: %w", unavailable(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var current *docstore.Document
+	existing, err := scanDocument(tx.QueryRow(ctx, `SELECT id, keys, body, updated_at FROM documents
+		WHERE collection = $1 AND id = $2 FOR UPDATE`, collection, id))
+	switch {
+	case err == nil:
+		current = &existing
+	case !errors.Is(err, pgx.ErrNoRows):
+		return docstore.Document{}, false, fmt.Errorf("update document: %w", unavailable(err))
+	}
+	next, err := fn(current)
+	if err != nil {
+		return docstore.Document{}, false, err
+	}
+	next.ID, next.UpdatedAt = id, s.now().UTC()
+	keys, err := documentKeys(next.Keys)
+	if err != nil {
+		return docstore.Document{}, false, fmt.Errorf("update document: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `INSERT INTO documents (collection, id, keys, body, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (collection, id) DO UPDATE SET keys = EXCLUDED.keys, body = EXCLUDED.body, updated_at = EXCLUDED.updated_at
+		WHERE $6::boolean`, collection, id, keys, []byte(next.Body), next.UpdatedAt, current != nil)
+	if err != nil {
+		return docstore.Document{}, false, fmt.Errorf("update document: %w", unavailable(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return docstore.Document{}, false, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return docstore.Document{}, false, fmt.Errorf("update document: %w", unavailable(err))
+	}
+	return next, true, nil
+}
+
+func (s *Store) DeleteDocument(collection, id string) error {
+	if err := s.exec(`DELETE FROM documents WHERE collection = $1 AND id = $2`, collection, id); err != nil {
+		return fmt.Errorf("delete document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/storetest"
+)
+
+var testTime = time.Date(2026, 4, 2, 8, 30, 0, 0, time.UTC)
+
+func TestLoadMigrations(t *testing.T) {
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if len(all) == 0 || all[0].version != 1 || all[0].name != "initial" {
+		t.Fatalf("unexpected migrations %+v", all)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].version <= all[i-1].version {
+			t.Fatalf("migrations not sorted: %d after %d", all[i].version, all[i-1].version)
+		}
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	stops := []models.RouteStop{
+		{CustomerID: "c1", CustomerName: "First", Nickname: "the barn", WindowStart: testTime, WindowEnd: testTime.Add(time.Hour), Priority: "high", ServiceType: "termite", PropertySqFt: 2400, Latitude: 30.2672, Longitude: -97.7431},
+		{CustomerID: "c2", CustomerName: "Second", PostalAddress: &models.PostalAddress{Street: "123 MAIN ST", Unit: "APT 4", City: "AUSTIN", State: "TX", ZIP: "78701", ZIP4: "1234", Status: "verified"}},
+	}
+	data, err := encodeStops(stops)
+	if err != nil {
+		t.Fatalf("encode stops: %v", err)
+	}
+	if got, err := decodeStops(data); err != nil || !reflect.DeepEqual(got, stops) {
+		t.Fatalf("stops mismatch: got %+v (%v)", got, err)
+	}
+
+	alerts := []models.RouteAlert{{Type: "weather", Message: "Rain after 2pm", Severity: "info"}}
+	data, err = encodeAlerts(alerts)
+	if err != nil {
+		t.Fatalf("encode alerts: %v", err)
+	}
+	if got, err := decodeAlerts(data); err != nil || !reflect.DeepEqual(got, alerts) {
+		t.Fatalf("alerts mismatch: got %+v (%v)", got, err)
+	}
+
+	lots := []models.ChemicalLot{{Number: "L-2291", ReceivedAt: testTime, ExpirationDate: testTime, Quantity: 3}}
+	data, err = encodeLots(lots)
+	if err != nil {
+		t.Fatalf("encode lots: %v", err)
+	}
+	if got, err := decodeLots(data); err != nil || !reflect.DeepEqual(got, lots) {
+		t.Fatalf("lots mismatch: got %+v (%v)", got, err)
+	}
+
+	data, _ = encodeLots(nil)
+	if got, err := decodeLots(data); err != nil || got != nil {
+		t.Fatalf("expected no lots, got %+v (%v)", got, err)
+	}
+
+	weather := &models.WeatherReading{Source: "nws", TemperatureC: 21.5, WindSpeedKPH: 12, WindGustKPH: 24, Summary: "Sunny", At: testTime}
+	data, err = encodeWeather(weather)
+	if err != nil {
+		t.Fatalf("encode weather: %v", err)
+	}
+	if got, err := decodeWeather(data); err != nil || !reflect.DeepEqual(got, weather) {
+		t.Fatalf("weather mismatch: got %+v (%v)", got, err)
+	}
+	if data, _ = encodeWeather(nil); data != nil {
+		t.Fatalf("expected no weather stored as NULL, got %s", data)
+	}
+}
+
+// newTestStore returns a migrated store in a fresh schema of the database
+// at POSTGRES_TEST_URL, skipping the test when it is unset.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL not set")
+	}
+	ctx := context.Background()
+	admin, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.Exec(context.Background(), `DROP SCHEMA `+schema+` CASCADE`)
+		admin.Close()
+	})
+
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	store, err := NewStore(ctx, config.DatastoreConfig{
+		PostgresURL:      url + sep + "search_path=" + schema,
+		PostgresMaxConns: 4,
+		PostgresMigrate:  true,
+		RequestTimeout:   5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+// TestConformance runs the repository suite shared by every backend.
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Store { return newTestStore(t) })
+}
+
+// TestDocumentConformance runs the docstore suite shared by every backend.
+func TestDocumentConformance(t *testing.T) {
+	storetest.RunDocuments(t, func(t *testing.T) docstore.Store { return newTestStore(t) })
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	store := newTestStore(t)
+	applied, err := Migrate(context.Background(), store.pool)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected nothing to apply, got %v (%v)", applied, err)
+	}
+}
+
+func TestTechniciansAndRoutes(t *testing.T) {
+	store := newTestStore(t)
+	if _, err := store.GetByID("tech-1"); err == nil || err.Error() != "technician not found" {
+		t.Fatalf("expected technician not found, got %v", err)
+	}
+	tech := models.Technician{ID: "tech-1", DisplayName: "Maria Lopez", BranchID: "north", Certifications: []string{"QAL"}}
+	if err := store.AddTechnician(tech); err != nil {
+		t.Fatalf("add technician: %v", err)
+	}
+	if got, err := store.GetByID("tech-1"); err != nil || !reflect.DeepEqual(got, tech) {
+		t.Fatalf("unexpected technician %+v (%v)", got, err)
+	}
+	if err := store.SaveTechnician(models.Technician{ID: "tech-2"}); err != nil {
+		t.Fatalf("save technician: %v", err)
+	}
+	if list, err := store.ListTechnicians(); err != nil || len(list) != 2 || list[0].ID != "tech-1" || list[1].ID != "tech-2" {
+		t.Fatalf("unexpected technicians %+v (%v)", list, err)
+	}
+
+	route := models.Route{ID: "r1", TechnicianID: "tech-1", ServiceDate: testTime, CustomerStops: []models.RouteStop{{CustomerID: "c1"}}}
+	if err := store.SaveRoute(route); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	got, err := store.GetRoute("tech-1", testTime.Truncate(24*time.Hour))
+	if err != nil || len(got.CustomerStops) != 1 || got.LastModified.IsZero() {
+		t.Fatalf("unexpected route %+v (%v)", got, err)
+	}
+	if _, err := store.GetRoute("tech-1", testTime.AddDate(0, 0, 1)); err == nil || err.Error() != "route not found" {
+		t.Fatalf("expected route not found, got %v", err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	store := newTestStore(t)
+	for _, tpl := range []models.ScreenTemplate{
+		{ID: "today", Version: 2, PayloadJSON: []byte(`{}`)},
+		{ID: "route", PayloadJSON: []byte(`{}`)},
+		{ID: "today", Version: 1, PayloadJSON: []byte(`{}`)},
+	} {
+		if err := store.SaveTemplate(tpl); err != nil {
+			t.Fatalf("save template: %v", err)
+		}
+	}
+	list, err := store.ListTemplates()
+	if err != nil || len(list) != 3 || list[0].ID != "route" || list[1].Version != 1 || list[2].Version != 2 {
+		t.Fatalf("unexpected templates %+v (%v)", list, err)
+	}
+	if err := store.DeleteTemplate("today", 1); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if _, err := store.GetTemplate("today", 1); err == nil || err.Error() != "template not found" {
+		t.Fatalf("expected template not found, got %v", err)
+	}
+}
+
+func TestPendingUploadsAndDeltas(t *testing.T) {
+	store := newTestStore(t)
+	clock := testTime
+	store.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for _, id := range []string{"job-b", "job-a", ""} {
+		if err := store.SaveJobUpload(models.JobUpload{ID: id, TechnicianID: "tech-1"}); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+	}
+	jobs, err := store.ListPendingJobs(2)
+	if err != nil || len(jobs) != 2 || jobs[0].ID != "job-b" || jobs[1].ID != "job-a" {
+		t.Fatalf("unexpected pending jobs %+v (%v)", jobs, err)
+	}
+	if all, _ := store.ListPendingJobs(0); len(all) != 3 {
+		t.Fatalf("expected all jobs, got %d", len(all))
+	}
+	if updates, err := store.ListJobUpdatesSince(testTime.Add(2 * time.Second)); err != nil || len(updates) != 1 {
+		t.Fatalf("expected one job update, got %+v (%v)", updates, err)
+	}
+
+	chem := models.ChemicalUpload{ID: "chem-1", Name: "Termidor", Lots: []models.ChemicalLot{{Number: "L-1", Quantity: 2}}}
+	if err := store.SaveChemicalUpload(chem); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	chems, err := store.ListChemicalUpdatesSince(time.Time{})
+	if err != nil || len(chems) != 1 || len(chems[0].Lots) != 1 || !chems[0].LastModified.Equal(clock) {
+		t.Fatalf("unexpected chemical updates %+v (%v)", chems, err)
+	}
+
+	if err := store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t1", QuantityUsed: 2.5}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	treatments, err := store.ListPendingTreatments(0)
+	if err != nil || len(treatments) != 1 || treatments[0].QuantityUsed != 2.5 {
+		t.Fatalf("unexpected treatments %+v (%v)", treatments, err)
+	}
+}
+
+func TestSoftDeletes(t *testing.T) {
+	store := newTestStore(t)
+	clock := testTime
+	store.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+	day := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	if err := store.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day}); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1"})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1"})
+	for _, err := range []error{store.DeleteRoute("tech-1", day), store.DeleteJob("job-1"), store.DeleteChemical("chem-1"), store.DeleteJob("missing")} {
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	}
+
+	if _, err := store.GetRoute("tech-1", day); err == nil {
+		t.Fatal("expected a deleted route to be not found")
+	}
+	if jobs, err := store.ListJobUpdatesSince(time.Time{}); err != nil || len(jobs) != 0 {
+		t.Fatalf("expected no job updates, got %+v (%v)", jobs, err)
+	}
+	tombstones, err := store.ListDeletionsSince(time.Time{})
+	if err != nil || len(tombstones) != 3 || tombstones[0].Kind != models.TombstoneRoute || tombstones[0].ID != "tech-1_2026-04-02" || tombstones[1].TechnicianID != "tech-1" {
+		t.Fatalf("unexpected tombstones %+v (%v)", tombstones, err)
+	}
+
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1"})
+	if jobs, _ := store.ListJobUpdatesSince(time.Time{}); len(jobs) != 1 {
+		t.Fatalf("expected saving again to restore the job, got %+v", jobs)
+	}
+	if n, err := store.PruneTombstones(clock.Add(time.Second)); err != nil || n != 2 {
+		t.Fatalf("expected two pruned tombstones, got %d (%v)", n, err)
+	}
+}
+
+func TestDeviceTokens(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.SaveDeviceToken(models.DeviceToken{Token: "abc", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("save device token: %v", err)
+	}
+	if err := store.SaveDeviceToken(models.DeviceToken{Token: "abc", TechnicianID: "tech-1", Platform: "ios"}); err != nil {
+		t.Fatalf("re-register device token: %v", err)
+	}
+	tokens, err := store.ListDeviceTokens("tech-1")
+	if err != nil || len(tokens) != 1 || tokens[0].Platform != "ios" {
+		t.Fatalf("expected one upserted device token, got %+v (%v)", tokens, err)
+	}
+	if n, err := store.PruneDeviceTokens(time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected one pruned token, got %d (%v)", n, err)
+	}
+	if err := store.DeleteDeviceToken("abc"); err != nil {
+		t.Fatalf("deleting a missing token must succeed: %v", err)
+	}
+}
+
+func TestReserveNonce(t *testing.T) {
+	store := newTestStore(t)
+	clock := time.Now()
+	store.now = func() time.Time { return clock }
+	nonce := uuid.NewString()
+	if ok, err := store.ReserveNonce(nonce, clock.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("expected a new nonce reserved, got %v (%v)", ok, err)
+	}
+	if ok, err := store.ReserveNonce(nonce, clock.Add(time.Minute)); err != nil || ok {
+		t.Fatalf("expected a used nonce refused, got %v (%v)", ok, err)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if ok, err := store.ReserveNonce(nonce, clock.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("expected an expired nonce taken over, got %v (%v)", ok, err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	store := newTestStore(t)
+	f := flags.StoredFlag{Key: "home.communications", Flag: flags.Flag{
+		State: "ENABLED", Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "on",
+	}, UpdatedAt: testTime}
+	if err := store.SaveFlag(f); err != nil {
+		t.Fatalf("save flag: %v", err)
+	}
+	f.DefaultVariant = "off"
+	if err := store.SaveFlag(f); err != nil {
+		t.Fatalf("update flag: %v", err)
+	}
+	if got, err := store.GetFlag(f.Key); err != nil || !reflect.DeepEqual(got, f) {
+		t.Fatalf("expected the updated flag, got %+v (%v)", got, err)
+	}
+	if all, err := store.ListFlags(); err != nil || len(all) != 1 {
+		t.Fatalf("expected one flag, got %+v (%v)", all, err)
+	}
+	if err := store.DeleteFlag(f.Key); err != nil {
+		t.Fatalf("delete flag: %v", err)
+	}
+	if err := store.DeleteFlag(f.Key); !errors.Is(err, flags.ErrNotFound) {
+		t.Fatalf("expected a missing flag reported, got %v", err)
+	}
+	if _, err := store.GetFlag(f.Key); !errors.Is(err, flags.ErrNotFound) {
+		t.Fatalf("expected a deleted flag gone, got %v", err)
+	}
+}
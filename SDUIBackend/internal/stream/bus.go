@@ -0,0 +1,178 @@
+// Package stream pushes route and job changes to connected devices as
+// server-sent events, so urgent changes reach the app without waiting for
+// its next /v1/updates poll. Each instance's bus follows the domain events
+// every instance publishes, or, in sandboxes, the repository writes it
+// serves; each device's stream receives the events of its technician.
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// ErrTooManyClients is returned when the instance already serves as many
+// streams as configured.
+var ErrTooManyClients = errors.New("too many streams")
+
+// Event types.
+const (
+	RouteUpdated = "route.updated"
+	RouteDeleted = "route.deleted"
+	JobUpdated   = "job.updated"
+	JobDeleted   = "job.deleted"
+	// Resync tells the client events were missed: it should fetch
+	// /v1/updates before relying on the stream again.
+	Resync = "resync"
+)
+
+// Event is a change to a technician's route or job. It names what changed;
+// the app fetches the records themselves with /v1/updates.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	TechnicianID string    `json:"technicianId"`
+	RouteID      string    `json:"routeId,omitempty"`
+	ServiceDate  string    `json:"serviceDate,omitempty"` // 2006-01-02
+	JobID        string    `json:"jobId,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	At           time.Time `json:"at"`
+
+	seq uint64
+}
+
+// Bus fans published events out to subscriptions and keeps the most recent
+// ones for clients resuming a stream.
+type Bus struct {
+	cfg config.StreamConfig
+	// epoch prefixes event IDs so IDs from before a restart, or from
+	// another instance, are recognized as unknown.
+	epoch string
+	now   func() time.Time
+
+	mu     sync.Mutex
+	seq    uint64
+	recent []Event // the last cfg.Replay events, oldest first
+	subs   map[*Subscription]struct{}
+}
+
+// NewBus creates a bus.
+func NewBus(cfg config.StreamConfig) *Bus {
+	return &Bus{
+		cfg:   cfg,
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		now:   time.Now,
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events of one technician, or of every
+// technician when subscribed without one.
+type Subscription struct {
+	bus          *Bus
+	technicianID string
+	events       chan Event
+	// lagged is set, and events closed, when the subscriber fell more
+	// than the buffer behind and was dropped.
+	lagged bool
+}
+
+// Events delivers the subscription's events. It is closed when the
+// subscriber falls behind; Lagged then reports true.
+func (s *Subscription) Events() <-chan Event { return s.events }
+
+// Lagged reports whether events were dropped because the subscriber fell
+// behind. It is only meaningful once Events is closed.
+func (s *Subscription) Lagged() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.lagged
+}
+
+// Close unsubscribes.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.events)
+	}
+}
+
+// Publish stamps an event and delivers it to matching subscriptions. It
+// never blocks: subscribers whose buffer is full are dropped. Nil buses
+// publish nothing.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e.seq = b.seq
+	e.ID = b.epoch + "-" + strconv.FormatUint(e.seq, 10)
+	e.At = b.now().UTC()
+	if b.cfg.Replay > 0 {
+		if len(b.recent) == b.cfg.Replay {
+			b.recent = append(b.recent[:0], b.recent[1:]...)
+		}
+		b.recent = append(b.recent, e)
+	}
+	for s := range b.subs {
+		if s.technicianID != "" && s.technicianID != e.TechnicianID {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			s.lagged = true
+			delete(b.subs, s)
+			close(s.events)
+		}
+	}
+}
+
+// Subscribe starts a subscription. lastEventID is the ID of the last event
+// the client received, or "" for a new stream; the events it missed since
+// are returned. complete is false when they are no longer all kept, or the
+// ID is unknown, and the client must resync.
+func (b *Bus) Subscribe(technicianID, lastEventID string) (sub *Subscription, missed []Event, complete bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) >= b.cfg.MaxClients {
+		return nil, nil, false, fmt.Errorf("%w: %d streams open", ErrTooManyClients, len(b.subs))
+	}
+	sub = &Subscription{bus: b, technicianID: technicianID, events: make(chan Event, b.cfg.Buffer)}
+	b.subs[sub] = struct{}{}
+
+	complete = true
+	if lastEventID != "" {
+		epoch, seqText, _ := strings.Cut(lastEventID, "-")
+		seq, parseErr := strconv.ParseUint(seqText, 10, 64)
+		switch {
+		case parseErr != nil || epoch != b.epoch || seq > b.seq:
+			complete = false
+		case seq < b.seq && (len(b.recent) == 0 || b.recent[0].seq > seq+1):
+			complete = false
+		default:
+			for _, e := range b.recent {
+				if e.seq > seq && (technicianID == "" || e.TechnicianID == technicianID) {
+					missed = append(missed, e)
+				}
+			}
+		}
+	}
+	return sub, missed, complete, nil
+}
+
+// Clients returns how many streams are open.
+func (b *Bus) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
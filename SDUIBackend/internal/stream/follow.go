@@ -0,0 +1,51 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/your-org/pestgenie-sdui/internal/events"
+)
+
+// Follow publishes to bus the route and job changes of tenantID that
+// source delivers. With Pub/Sub those are the changes made through every
+// instance, so a device's stream sees writes whichever instance served
+// them; empty tenantID follows deployments without tenancy.
+func Follow(source *events.Bus, tenantID string, bus *Bus) {
+	source.Subscribe(func(_ context.Context, e events.Event) {
+		if e.TenantID != tenantID {
+			return
+		}
+		if change, ok := fromDomain(e); ok {
+			bus.Publish(change)
+		}
+	})
+}
+
+// fromDomain converts a route or job domain event into a stream event.
+func fromDomain(e events.Event) (Event, bool) {
+	switch e.Type {
+	case events.EventRouteChanged:
+		var d events.RouteChangedData
+		if e.Decode(&d) != nil {
+			return Event{}, false
+		}
+		change := Event{Type: RouteUpdated, TechnicianID: e.TechnicianID, RouteID: d.RouteID, ServiceDate: d.ServiceDate}
+		if d.Deleted {
+			change.Type = RouteDeleted
+		}
+		return change, true
+	case events.EventJobUploaded:
+		var d events.JobUploadedData
+		if e.Decode(&d) != nil {
+			return Event{}, false
+		}
+		return Event{Type: JobUpdated, TechnicianID: e.TechnicianID, JobID: d.JobID, Status: d.Status}, true
+	case events.EventJobDeleted:
+		var d events.JobDeletedData
+		if e.Decode(&d) != nil {
+			return Event{}, false
+		}
+		return Event{Type: JobDeleted, TechnicianID: e.TechnicianID, JobID: d.JobID}, true
+	}
+	return Event{}, false
+}
@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// retryMillis is how long the app waits before reconnecting a dropped
+// stream.
+const retryMillis = 5000
+
+// Handler serves the event stream to the app.
+type Handler struct {
+	cfg config.StreamConfig
+	bus *Bus
+}
+
+// NewHandler creates a stream handler.
+func NewHandler(cfg config.StreamConfig, bus *Bus) *Handler {
+	return &Handler{cfg: cfg, bus: bus}
+}
+
+// Stream sends route and job changes as server-sent events until the
+// client disconnects or the stream reaches its maximum duration. Clients
+// reconnecting with Last-Event-ID receive the events they missed, or a
+// resync event when those are no longer kept. Technicians receive their
+// own changes; staff and unauthenticated legacy clients those of ?userId=,
+// or everyone's without it.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("userId")
+	if id, ok := auth.FromContext(r.Context()); ok && !id.Staff() {
+		owner = id.Subject
+	}
+	sub, missed, complete, err := h.bus.Subscribe(owner, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Warn("stream refused", slog.Any("error", err))
+		respond.Error(w, http.StatusServiceUnavailable, "too many streams", "poll /v1/updates or reconnect later", respond.WithRetryAfter(30*time.Second))
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout would end the stream; each write gets
+	// until the next heartbeat instead.
+	extend := func() { _ = rc.SetWriteDeadline(time.Now().Add(2 * h.cfg.HeartbeatInterval)) }
+	extend()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	if !complete {
+		writeEvent(w, Event{Type: Resync, TechnicianID: owner})
+	}
+	for _, e := range missed {
+		writeEvent(w, e)
+	}
+	if err := rc.Flush(); err != nil {
+		middleware.LoggerFrom(r.Context()).Error("stream cannot flush", slog.Any("error", err))
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(h.cfg.MaxDuration)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			extend()
+			_, _ = io.WriteString(w, ": keepalive\n\n")
+		case e, ok := <-sub.Events():
+			extend()
+			if !ok {
+				// Dropped for falling behind.
+				writeEvent(w, Event{Type: Resync, TechnicianID: owner})
+				_ = rc.Flush()
+				return
+			}
+			writeEvent(w, e)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes e in the event stream format. Resync events send an
+// empty ID, which clears the client's, so it reconnects to a new stream
+// once it has fetched /v1/updates.
+func writeEvent(w io.Writer, e Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %s\n", e.ID)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
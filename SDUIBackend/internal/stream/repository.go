@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Publish wraps the route and sync repositories so every route and job
+// write is published to bus once it succeeds; with a nil bus repos is
+// returned as is. Nil repositories are left nil so Repository.Validate
+// still reports them.
+func Publish(repos repository.Repository, bus *Bus) repository.Repository {
+	if bus == nil {
+		return repos
+	}
+	out := repos
+	if repos.Routes != nil {
+		out.Routes = routes{RouteRepository: repos.Routes, bus: bus}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{SyncRepository: repos.Sync, bus: bus}
+	}
+	return out
+}
+
+type routes struct {
+	repository.RouteRepository
+	bus *Bus
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	if err := r.RouteRepository.SaveRoute(route); err != nil {
+		return err
+	}
+	r.bus.Publish(Event{Type: RouteUpdated, TechnicianID: route.TechnicianID, RouteID: route.ServerID(), ServiceDate: route.ServiceDate.Format("2006-01-02")})
+	return nil
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	route, err := r.GetRoute(technicianID, serviceDate)
+	if err != nil {
+		route = models.Route{TechnicianID: technicianID, ServiceDate: serviceDate}
+	}
+	if err := r.RouteRepository.DeleteRoute(technicianID, serviceDate); err != nil {
+		return err
+	}
+	r.bus.Publish(Event{Type: RouteDeleted, TechnicianID: technicianID, RouteID: route.ServerID(), ServiceDate: serviceDate.Format("2006-01-02")})
+	return nil
+}
+
+type syncRepo struct {
+	repository.SyncRepository
+	bus *Bus
+}
+
+func (r syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	if err := r.SyncRepository.SaveJobUpload(upload); err != nil {
+		return err
+	}
+	r.bus.Publish(Event{Type: JobUpdated, TechnicianID: upload.TechnicianID, JobID: upload.ID, Status: upload.Status})
+	return nil
+}
+
+// DeleteJob publishes the deletion to the technician its tombstone names,
+// since the job itself is gone once deleted.
+func (r syncRepo) DeleteJob(id string) error {
+	since := time.Now().Add(-time.Second)
+	if err := r.SyncRepository.DeleteJob(id); err != nil {
+		return err
+	}
+	r.publishDeleted(since, id)
+	return nil
+}
+
+// SaveBatch publishes the batch's writes once all of them are stored.
+func (r syncRepo) SaveBatch(batch repository.Batch) error {
+	since := time.Now().Add(-time.Second)
+	if err := r.SyncRepository.SaveBatch(batch); err != nil {
+		return err
+	}
+	for _, route := range batch.Routes {
+		r.bus.Publish(Event{Type: RouteUpdated, TechnicianID: route.TechnicianID, RouteID: route.ServerID(), ServiceDate: route.ServiceDate.Format("2006-01-02")})
+	}
+	for _, j := range batch.Jobs {
+		r.bus.Publish(Event{Type: JobUpdated, TechnicianID: j.TechnicianID, JobID: j.ID, Status: j.Status})
+	}
+	r.publishDeleted(since, batch.DeletedJobs...)
+	return nil
+}
+
+// publishDeleted publishes the deletions of jobs ids deleted after since.
+// The jobs are deleted either way; devices see them on their next poll.
+func (r syncRepo) publishDeleted(since time.Time, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	deletions, err := r.ListDeletionsSince(since)
+	if err != nil {
+		return
+	}
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	for _, t := range deletions {
+		if t.Kind == models.TombstoneJob && wanted[t.ID] {
+			r.bus.Publish(Event{Type: JobDeleted, TechnicianID: t.TechnicianID, JobID: t.ID})
+			delete(wanted, t.ID)
+		}
+	}
+}
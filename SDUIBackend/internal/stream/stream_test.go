@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/events"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var cfg = config.StreamConfig{HeartbeatInterval: 20 * time.Millisecond, MaxDuration: time.Minute, Replay: 3, Buffer: 2, MaxClients: 2}
+
+func TestBus(t *testing.T) {
+	bus := NewBus(cfg)
+	mine, _, _, err := bus.Subscribe("tech-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _, _, _ := bus.Subscribe("", "")
+	if _, _, _, err := bus.Subscribe("tech-3", ""); err == nil {
+		t.Error("expected streams past the limit refused")
+	}
+
+	bus.Publish(Event{Type: JobUpdated, TechnicianID: "tech-1", JobID: "job-1"})
+	bus.Publish(Event{Type: JobUpdated, TechnicianID: "tech-2", JobID: "job-2"})
+	if e := <-mine.Events(); e.JobID != "job-1" || e.ID == "" {
+		t.Errorf("expected the technician's event, got %+v", e)
+	}
+	if len(mine.Events()) != 0 || len(all.Events()) != 2 {
+		t.Errorf("expected events filtered by technician")
+	}
+	bus.Publish(Event{Type: JobUpdated, TechnicianID: "tech-1", JobID: "job-3"})
+	if _, open := <-all.Events(); !open {
+		t.Fatal("expected buffered events delivered")
+	}
+	<-all.Events()
+	if _, open := <-all.Events(); open || !all.Lagged() {
+		t.Error("expected a subscriber past its buffer dropped")
+	}
+	mine.Close()
+	if n := bus.Clients(); n != 0 {
+		t.Errorf("expected no streams open, got %d", n)
+	}
+}
+
+func TestResume(t *testing.T) {
+	bus := NewBus(cfg)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: RouteUpdated, TechnicianID: "tech-1"})
+		ids = append(ids, bus.recent[len(bus.recent)-1].ID)
+	}
+	sub, missed, complete, _ := bus.Subscribe("tech-1", ids[2])
+	sub.Close()
+	if !complete || len(missed) != 2 || missed[0].ID != ids[3] {
+		t.Errorf("expected the two missed events, got %+v (%v)", missed, complete)
+	}
+	for _, last := range []string{ids[0], "other-5", "garbage"} {
+		sub, _, complete, _ := bus.Subscribe("tech-1", last)
+		sub.Close()
+		if complete {
+			t.Errorf("%s: expected a resync", last)
+		}
+	}
+}
+
+func TestFollowDomainEvents(t *testing.T) {
+	store := storememory.NewStore()
+	source := events.NewBus(config.EventsConfig{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour}, events.NewMemoryPublisher(), nil)
+	repos := events.Publish(repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, source)
+	follow := cfg
+	follow.Buffer = 10
+	bus := NewBus(follow)
+	Follow(source, "acme", bus)
+	sub, _, _, _ := bus.Subscribe("", "")
+	defer sub.Close()
+
+	_ = repos.Routes.SaveRoute(models.Route{TenantID: "acme", TechnicianID: "tech-1", ServiceDate: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)})
+	_ = repos.Sync.SaveJobUpload(models.JobUpload{TenantID: "other", ID: "job-2", TechnicianID: "tech-2"})
+	_ = repos.Sync.SaveJobUpload(models.JobUpload{TenantID: "acme", ID: "job-1", TechnicianID: "tech-1"})
+	_ = repos.Sync.DeleteJob("job-1")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source.Run(ctx)
+
+	var got []string
+	for len(sub.Events()) > 0 {
+		e := <-sub.Events()
+		got = append(got, e.Type+" "+e.TechnicianID+" "+e.RouteID+e.JobID)
+	}
+	want := []string{"route.updated tech-1 tech-1_2026-10-15", "job.updated tech-1 job-1"}
+	if len(got) < 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected the tenant's changes, got %q", got)
+	}
+	if len(got) != 3 || got[2] != "job.deleted tech-1 job-1" {
+		t.Errorf("expected the deletion followed, got %q", got)
+	}
+}
+
+func TestStream(t *testing.T) {
+	bus := NewBus(cfg)
+	store := storememory.NewStore()
+	repos := Publish(repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}, bus)
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(cfg, bus).Stream))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?userId=tech-1", nil)
+	req.Header.Set("Last-Event-ID", "unknown-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func(prefix string) string {
+		t.Helper()
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), prefix) {
+				return lines.Text()
+			}
+		}
+		t.Fatalf("stream ended before %q", prefix)
+		return ""
+	}
+	if got := next("event:"); got != "event: resync" {
+		t.Errorf("expected an unknown ID to resync, got %q", got)
+	}
+	next(": keepalive")
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	if err := repos.Routes.SaveRoute(models.Route{TechnicianID: "tech-2", ServiceDate: day}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Routes.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day}); err != nil {
+		t.Fatal(err)
+	}
+	if got := next("data:"); !strings.Contains(got, `"type":"route.updated","technicianId":"tech-1","routeId":"tech-1_2026-10-15"`) {
+		t.Errorf("expected the technician's route change, got %q", got)
+	}
+	if err := repos.Sync.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1", Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Sync.DeleteJob("job-1"); err != nil {
+		t.Fatal(err)
+	}
+	next("event: job.updated")
+	if got := next("event:"); got != "event: job.deleted" {
+		t.Errorf("expected the deletion streamed, got %q", got)
+	}
+}
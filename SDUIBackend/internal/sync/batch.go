@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/your-org/pestgenie-sdui/internal/apitoken"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Kinds of batch items, as named in results.
+const (
+	batchJob       = "job"
+	batchChemical  = "chemical"
+	batchTreatment = "chemicalTreatment"
+)
+
+// batchUpload is the body of a batch upload. Items stay raw so one that does
+// not decode fails alone.
+type batchUpload struct {
+	Jobs               []json.RawMessage `json:"jobs"`
+	Chemicals          []json.RawMessage `json:"chemicals"`
+	ChemicalTreatments []json.RawMessage `json:"chemicalTreatments"`
+}
+
+// UploadBatch stores jobs, then chemicals, then treatments, each as if
+// uploaded on its own, and answers 207 Multi-Status with a result per item.
+// Items are idempotent by ID, so a device resends only the failed items
+// whose problem is retryable.
+func (h *Handler) UploadBatch(w http.ResponseWriter, r *http.Request) {
+	var batch batchUpload
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	total := len(batch.Jobs) + len(batch.Chemicals) + len(batch.ChemicalTreatments)
+	if total == 0 {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", "batch is empty")
+		return
+	}
+	if h.cfg.BatchMaxItems > 0 && total > h.cfg.BatchMaxItems {
+		respond.Error(w, http.StatusRequestEntityTooLarge, "batch too large", fmt.Sprintf("send at most %d items per batch", h.cfg.BatchMaxItems))
+		return
+	}
+
+	results := make([]respond.ItemResult, 0, total)
+	results = append(results, batchItems(h, w, r, batchJob, apitoken.ScopeJobsWrite, batch.Jobs,
+		func(p transport.JobUploadData) string { return p.ID }, h.saveJob)...)
+	results = append(results, batchItems(h, w, r, batchChemical, apitoken.ScopeChemicalsWrite, batch.Chemicals,
+		func(p transport.ChemicalUploadData) string { return p.ID }, h.saveChemical)...)
+	results = append(results, batchItems(h, w, r, batchTreatment, apitoken.ScopeTreatmentsWrite, batch.ChemicalTreatments,
+		func(p transport.ChemicalTreatmentUploadData) string { return p.ID }, h.saveTreatment)...)
+	respond.MultiStatus(w, results)
+}
+
+// batchItems decodes and saves items of one kind. An API token without the
+// scope the kind's own endpoint requires fails each item rather than the
+// batch.
+func batchItems[T any](h *Handler, w http.ResponseWriter, r *http.Request, kind, scope string, items []json.RawMessage,
+	id func(T) string, save func(*http.Request, T) (transport.UploadResponse, *uploadError)) []respond.ItemResult {
+	token, hasToken := apitoken.FromContext(r.Context())
+	out := make([]respond.ItemResult, 0, len(items))
+	for i, raw := range items {
+		var payload T
+		err := json.Unmarshal(raw, &payload)
+		result := respond.ItemResult{Index: i, Kind: kind, EntityID: id(payload)}
+		var e *uploadError
+		var resp transport.UploadResponse
+		switch {
+		case err != nil:
+			e = rejected("invalid payload", err.Error())
+		case hasToken && !token.HasScope(scope):
+			e = &uploadError{status: http.StatusForbidden, title: "insufficient scope", detail: "token lacks scope " + scope}
+		default:
+			resp, e = save(r, payload)
+		}
+		if e != nil {
+			problem := respond.NewProblem(w, e.status, e.title, e.detail, h.problemOptions(result.EntityID, e)...)
+			result.Status, result.Problem = e.status, &problem
+		} else {
+			result.Status, result.Result = http.StatusAccepted, resp
+		}
+		out = append(out, result)
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Update kinds, in the order records with the same lastModified and id are
+// returned.
+const (
+	kindChemical        = "chemical"
+	kindDeletedChemical = "deleted-chemical"
+	kindDeletedJob      = "deleted-job"
+	kindDeletedRoute    = "deleted-route"
+	kindJob             = "job"
+	kindRoute           = "route"
+	kindTreatment       = "treatment"
+)
+
+var errInvalidCursor = errors.New("cursor is malformed; pass nextCursor back unchanged")
+
+// updateCursor is a position in the order updates are returned in:
+// lastModified, then id, then kind.
+type updateCursor struct {
+	At   time.Time
+	ID   string
+	Kind string
+}
+
+// before reports whether c sorts before o.
+func (c updateCursor) before(o updateCursor) bool {
+	if !c.At.Equal(o.At) {
+		return c.At.Before(o.At)
+	}
+	if c.ID != o.ID {
+		return c.ID < o.ID
+	}
+	return c.Kind < o.Kind
+}
+
+// String encodes the cursor opaquely for nextCursor.
+func (c updateCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.At.UTC().Format(time.RFC3339Nano) + "|" + c.Kind + "|" + c.ID))
+}
+
+func parseCursor(s string) (updateCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return updateCursor{}, errInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || parts[1] == "" {
+		return updateCursor{}, errInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return updateCursor{}, errInvalidCursor
+	}
+	return updateCursor{At: at, Kind: parts[1], ID: parts[2]}, nil
+}
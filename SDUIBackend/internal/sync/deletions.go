@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// AdminRoutes mounts the dispatcher endpoints that soft-delete synced
+// records. Devices learn of each deletion from the deletions section of
+// GET /v1/updates.
+func (h *Handler) AdminRoutes(r chi.Router) {
+	r.Delete("/jobs/{jobId}", h.DeleteJob)
+	r.Delete("/chemicals/{chemicalId}", h.DeleteChemical)
+	r.Delete("/routes/{technicianId}/{serviceDate}", h.DeleteRoute)
+}
+
+// DeleteJob soft-deletes a job. Deleting a missing job succeeds.
+func (h *Handler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "jobId")
+	h.softDelete(w, r, "failed to delete job", func() error { return h.reposFor(r).Sync.DeleteJob(id) })
+}
+
+// DeleteChemical soft-deletes a chemical. Deleting a missing chemical
+// succeeds.
+func (h *Handler) DeleteChemical(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "chemicalId")
+	h.softDelete(w, r, "failed to delete chemical", func() error { return h.reposFor(r).Sync.DeleteChemical(id) })
+}
+
+// DeleteRoute soft-deletes a technician's route for a YYYY-MM-DD service
+// date. Deleting a missing route succeeds.
+func (h *Handler) DeleteRoute(w http.ResponseWriter, r *http.Request) {
+	technicianID := chi.URLParam(r, "technicianId")
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "serviceDate"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid service date", "expected YYYY-MM-DD")
+		return
+	}
+	h.softDelete(w, r, "failed to delete route", func() error { return h.reposFor(r).Routes.DeleteRoute(technicianID, date) })
+}
+
+func (h *Handler) softDelete(w http.ResponseWriter, r *http.Request, title string, remove func() error) {
+	if err := h.saveWithRetry(remove); err != nil {
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PruneTombstones permanently removes records deleted more than
+// TombstoneRetention ago, every TombstonePruneInterval until ctx is
+// cancelled.
+func (h *Handler) PruneTombstones(ctx context.Context) {
+	if h.cfg.TombstoneRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.TombstonePruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.pruneTombstones(time.Now())
+		}
+	}
+}
+
+func (h *Handler) pruneTombstones(now time.Time) {
+	n, err := h.repos.Sync.PruneTombstones(now.Add(-h.cfg.TombstoneRetention))
+	if err != nil {
+		h.logger.Error("prune tombstones", slog.Any("error", err))
+	}
+	if n > 0 {
+		h.logger.Info("pruned expired tombstones", slog.Int("count", n))
+	}
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// ListDevices returns the devices registered to the authenticated technician.
+func (h *Handler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	technicianID, ok := h.technician(w, r, "")
+	if !ok {
+		return
+	}
+	tokens, err := h.reposFor(r).Devices.ListDeviceTokens(technicianID)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to list device tokens", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to list devices", "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
+		return
+	}
+	devices := make([]transport.DeviceData, 0, len(tokens))
+	for _, t := range tokens {
+		devices = append(devices, transport.DeviceData{Token: t.Token, Platform: t.Platform, BundleID: t.BundleID, RegisteredAt: t.RegisteredAt})
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"devices": devices})
+}
+
+// DeleteDevice revokes one of the authenticated technician's device tokens so
+// it no longer receives pushes.
+func (h *Handler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	technicianID, ok := h.technician(w, r, "")
+	if !ok {
+		return
+	}
+	logger := middleware.LoggerFrom(r.Context())
+	token := chi.URLParam(r, "token")
+	tokens, err := h.reposFor(r).Devices.ListDeviceTokens(technicianID)
+	if err != nil {
+		logger.Error("failed to list device tokens", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to revoke device", "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
+		return
+	}
+	owned := false
+	for _, t := range tokens {
+		owned = owned || t.Token == token
+	}
+	if !owned {
+		respond.Error(w, http.StatusNotFound, "device not found", "the token is not registered to this technician")
+		return
+	}
+	if err := h.saveWithRetry(func() error { return h.reposFor(r).Devices.DeleteDeviceToken(token) }); err != nil {
+		logger.Error("failed to delete device token", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to revoke device", "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// technician resolves the technician a device request acts for: the
+// authenticated subject or ?userId=, falling back to fallback. It writes a
+// 400 and returns false unless that names a known technician.
+func (h *Handler) technician(w http.ResponseWriter, r *http.Request, fallback string) (string, bool) {
+	id := auth.TechnicianID(r)
+	if id == "" {
+		id = fallback
+	}
+	if id == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return "", false
+	}
+	if _, err := h.reposFor(r).Technicians.GetByID(id); err != nil {
+		respond.Error(w, http.StatusBadRequest, "unknown technician", "the technician is not known")
+		return "", false
+	}
+	return id, true
+}
+
+// PruneDevices removes registrations not refreshed within DeviceStaleAfter,
+// every DevicePruneInterval until ctx is cancelled.
+func (h *Handler) PruneDevices(ctx context.Context) {
+	if h.cfg.DeviceStaleAfter <= 0 {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.DevicePruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.pruneDevices(time.Now())
+		}
+	}
+}
+
+func (h *Handler) pruneDevices(now time.Time) {
+	n, err := h.repos.Devices.PruneDeviceTokens(now.Add(-h.cfg.DeviceStaleAfter))
+	if err != nil {
+		h.logger.Error("prune device tokens", slog.Any("error", err))
+	}
+	if n > 0 {
+		h.logger.Info("pruned stale device tokens", slog.Int("count", n))
+	}
+}
@@ -1,44 +1,131 @@
 package sync
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"log/slog"
 
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/activity"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/brownout"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+	"github.com/your-org/pestgenie-sdui/internal/chemical"
 	"github.com/your-org/pestgenie-sdui/internal/config"
-	domain "github.com/your-org/pestgenie-sdui/internal/domain/models"
-	"github.com/your-org/pestgenie-sdui/internal/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/connector"
 	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/liveactivity"
 	"github.com/your-org/pestgenie-sdui/internal/middleware"
 	transport "github.com/your-org/pestgenie-sdui/internal/models"
+	"github.com/your-org/pestgenie-sdui/internal/photo"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+	"github.com/your-org/pestgenie-sdui/internal/validate"
+	"github.com/your-org/pestgenie-sdui/internal/weather"
 )
 
 // Handler exposes the sync endpoints consumed by the mobile client.
 type Handler struct {
-	repos  repository.Repository
-	cfg    config.SyncConfig
-	logger *slog.Logger
+	repos    repository.Repository
+	cfg      config.SyncConfig
+	deferred *brownout.DeferredWrites
+	events   *connector.Service
+	feed     *activity.Service
+	equip    *calibration.Service
+	live     *liveactivity.Service
+	weather  *weather.Service
+	catalog  *chemical.Catalog
+	photos   *photo.Service
+	logger   *slog.Logger
 }
 
-// NewHandler creates a sync handler with its dependencies injected.
-func NewHandler(repos repository.Repository, cfg config.SyncConfig, logger *slog.Logger) *Handler {
-	return &Handler{repos: repos, cfg: cfg, logger: logger}
+// NewHandler creates a sync handler with its dependencies injected. Writes
+// that are not needed for the technician's immediate workflow are pushed onto
+// deferred while the datastore is in brownout. Persisted uploads are published
+// to events; a nil events disables publishing. Chemical uploads are recorded
+// in the activity feed unless feed is nil. Treatments are checked against
+// equipment calibration; a nil equip skips the check. Job status changes
+// update the technician's Live Activity through live, unless it is nil.
+// Treatments are stored with the conditions weather reports for their stop;
+// a nil weather stores only what the technician typed. Chemicals are
+// cross-referenced with catalog, unless it is nil. Jobs with a signature
+// are sent a signed URL of it from photos; a nil photos sends its ID only.
+func NewHandler(repos repository.Repository, cfg config.SyncConfig, deferred *brownout.DeferredWrites, events *connector.Service, feed *activity.Service, equip *calibration.Service, live *liveactivity.Service, weather *weather.Service, catalog *chemical.Catalog, photos *photo.Service, logger *slog.Logger) *Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Handler{repos: repos, cfg: cfg, deferred: deferred, events: events, feed: feed, equip: equip, live: live, weather: weather, catalog: catalog, photos: photos, logger: logger}
+}
+
+// uploadError is an upload the server refused or failed to store, written
+// as a problem naming the upload.
+type uploadError struct {
+	status int
+	title  string
+	detail string
+	cause  error // field errors of an upload that failed validation, or why it failed
+}
+
+func rejected(title, detail string) *uploadError {
+	return &uploadError{status: http.StatusBadRequest, title: title, detail: detail}
+}
+
+// invalid refuses an upload whose fields failed validation with err.
+func invalid(title string, err error) *uploadError {
+	return &uploadError{status: http.StatusBadRequest, title: title, detail: err.Error(), cause: err}
+}
+
+func failed(title string, err error) *uploadError {
+	return &uploadError{status: http.StatusInternalServerError, title: title, detail: "temporary error, please retry", cause: err}
+}
+
+// problemOptions name the upload, and ask for a backoff before retrying
+// temporary failures.
+func (h *Handler) problemOptions(id string, e *uploadError) []respond.Option {
+	opts := []respond.Option{respond.WithEntityID(id)}
+	if e.cause != nil {
+		opts = append(opts, respond.WithErrors(e.cause), respond.WithCause(e.cause))
+	}
+	if e.status >= http.StatusInternalServerError {
+		opts = append(opts, h.retryAfter())
+	}
+	return opts
+}
+
+func (h *Handler) writeUpload(w http.ResponseWriter, id string, resp transport.UploadResponse, e *uploadError) {
+	if e != nil {
+		respond.Error(w, e.status, e.title, e.detail, h.problemOptions(id, e)...)
+		return
+	}
+	respond.JSON(w, http.StatusAccepted, resp)
 }
 
 // CreateJob receives pending job payloads from the device for persistence.
 func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	var payload transport.JobUploadData
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error(), respond.WithEntityID(payload.ID))
 		return
 	}
+	resp, e := h.saveJob(r, payload)
+	h.writeUpload(w, payload.ID, resp, e)
+}
 
+func (h *Handler) saveJob(r *http.Request, payload transport.JobUploadData) (transport.UploadResponse, *uploadError) {
+	if err := payload.Validate(); err != nil {
+		return transport.UploadResponse{}, invalid("invalid job", err)
+	}
 	logger := middleware.LoggerFrom(r.Context())
 	job := domain.JobUpload{
 		ID:            payload.ID,
+		TechnicianID:  auth.TechnicianID(r),
 		CustomerName:  payload.CustomerName,
 		Address:       payload.Address,
 		ScheduledDate: payload.ScheduledDate,
@@ -46,31 +133,62 @@ func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 		ReceivedAt:    time.Now(),
 	}
 
-	if err := h.saveWithRetry(func() error { return h.repos.Sync.SaveJobUpload(job) }); err != nil {
+	// Devices re-upload jobs as they sync, so job.completed is only sent
+	// when the stored job was not completed yet.
+	repos := h.reposFor(r)
+	wasCompleted := false
+	if prev, err := repos.Sync.GetJobUpload(job.ID); err == nil {
+		wasCompleted = completed(prev)
+	} else if !errors.Is(err, repository.ErrJobNotFound) {
+		logger.Warn("failed to load stored job", slog.Any("error", err))
+	}
+	if err := h.saveWithRetry(func() error { return repos.Sync.SaveJobUpload(job) }); err != nil {
 		logger.Error("failed to persist job upload", slog.Any("error", err))
-		respond.Error(w, http.StatusInternalServerError, "failed to queue job", "temporary error, please retry")
-		return
+		return transport.UploadResponse{}, failed("failed to queue job", err)
+	}
+	h.events.Publish(connector.JobUploaded(job))
+	if completed(job) && !wasCompleted {
+		h.events.Publish(connector.JobCompleted(job))
 	}
+	h.live.JobUpdated(r.Context(), job)
 
-	respond.JSON(w, http.StatusAccepted, transport.UploadResponse{
+	return transport.UploadResponse{
 		Success:  true,
 		JobID:    payload.ID,
 		ServerID: payload.ID,
 		Message:  "queued",
-	})
+	}, nil
+}
+
+func completed(job domain.JobUpload) bool {
+	return strings.EqualFold(job.Status, "completed")
 }
 
 // CreateChemical ingests chemical inventory updates.
 func (h *Handler) CreateChemical(w http.ResponseWriter, r *http.Request) {
 	var payload transport.ChemicalUploadData
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error(), respond.WithEntityID(payload.ID))
 		return
 	}
+	resp, e := h.saveChemical(r, payload)
+	h.writeUpload(w, payload.ID, resp, e)
+}
+
+func (h *Handler) saveChemical(r *http.Request, payload transport.ChemicalUploadData) (transport.UploadResponse, *uploadError) {
+	payload.EPARegistration = strings.TrimSpace(payload.EPARegistration)
+	errs := validate.FieldErrors(payload.Validate())
+	if payload.EPARegistration != "" && !chemical.ValidRegistration(payload.EPARegistration) {
+		errs.Add("epaRegistrationNumber", "must be an EPA registration number such as 100-1066")
+	}
+	if err := errs.Err(); err != nil {
+		return transport.UploadResponse{}, invalid("invalid chemical", err)
+	}
 
 	logger := middleware.LoggerFrom(r.Context())
 	upload := domain.ChemicalUpload{
 		ID:               payload.ID,
+		TechnicianID:     auth.TechnicianID(r),
 		Name:             payload.Name,
 		ActiveIngredient: payload.ActiveIngredient,
 		ManufacturerName: payload.ManufacturerName,
@@ -79,36 +197,66 @@ func (h *Handler) CreateChemical(w http.ResponseWriter, r *http.Request) {
 		UnitOfMeasure:    payload.UnitOfMeasure,
 		QuantityInStock:  payload.QuantityInStock,
 		ExpirationDate:   payload.ExpirationDate,
+		Lots:             chemicalLots(payload.Lots),
 		LastModified:     payload.LastModified,
 	}
 
-	if err := h.saveWithRetry(func() error { return h.repos.Sync.SaveChemicalUpload(upload) }); err != nil {
+	if err := h.saveWithRetry(func() error { return h.reposFor(r).Sync.SaveChemicalUpload(upload) }); err != nil {
 		logger.Error("failed to persist chemical upload", slog.Any("error", err))
-		respond.Error(w, http.StatusInternalServerError, "failed to queue chemical", "temporary error, please retry")
-		return
+		return transport.UploadResponse{}, failed("failed to queue chemical", err)
 	}
+	h.events.Publish(connector.ChemicalUpdated(upload))
+	h.feed.Record(r.Context(), activity.Event{Type: activity.ChemicalUpdated, ActorID: upload.TechnicianID, SubjectID: upload.ID, SubjectName: upload.Name})
 
-	respond.JSON(w, http.StatusAccepted, transport.UploadResponse{
+	return transport.UploadResponse{
 		Success:  true,
 		JobID:    payload.ID,
 		ServerID: payload.ID,
 		Message:  "queued",
-	})
+		Warnings: h.catalog.Check(upload.EPARegistration, upload.ActiveIngredient),
+	}, nil
 }
 
 // CreateChemicalTreatment ingests treatment logs from the device.
 func (h *Handler) CreateChemicalTreatment(w http.ResponseWriter, r *http.Request) {
 	var payload transport.ChemicalTreatmentUploadData
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error(), respond.WithEntityID(payload.ID))
 		return
 	}
+	resp, e := h.saveTreatment(r, payload)
+	h.writeUpload(w, payload.ID, resp, e)
+}
 
+func (h *Handler) saveTreatment(r *http.Request, payload transport.ChemicalTreatmentUploadData) (transport.UploadResponse, *uploadError) {
+	if err := payload.Validate(); err != nil {
+		return transport.UploadResponse{}, invalid("invalid treatment", err)
+	}
 	logger := middleware.LoggerFrom(r.Context())
+	if err := h.checkLot(payload.ChemicalID, payload.LotNumber); err != nil {
+		if errors.Is(err, errLotRequired) {
+			return transport.UploadResponse{}, rejected("lot required", err.Error())
+		}
+		logger.Error("failed to load chemical lots", slog.Any("error", err))
+		return transport.UploadResponse{}, failed("failed to queue treatment", err)
+	}
+	warning, err := h.equip.Check(payload.EquipmentID, payload.ApplicationMethod, payload.ApplicationDate)
+	switch {
+	case errors.Is(err, calibration.ErrCalibrationOverdue):
+		return transport.UploadResponse{}, rejected("calibration overdue", err.Error())
+	case errors.Is(err, calibration.ErrNotFound):
+		return transport.UploadResponse{}, rejected("unknown equipment", err.Error())
+	case err != nil:
+		logger.Error("failed to check equipment calibration", slog.Any("error", err))
+		return transport.UploadResponse{}, failed("failed to queue treatment", err)
+	}
 	upload := domain.ChemicalTreatmentUpload{
 		ID:                 payload.ID,
 		JobID:              payload.JobID,
 		ChemicalID:         payload.ChemicalID,
+		LotNumber:          payload.LotNumber,
+		EquipmentID:        payload.EquipmentID,
+		TechnicianID:       auth.TechnicianID(r),
 		ApplicatorName:     payload.ApplicatorName,
 		ApplicationDate:    payload.ApplicationDate,
 		ApplicationMethod:  payload.ApplicationMethod,
@@ -121,71 +269,429 @@ func (h *Handler) CreateChemicalTreatment(w http.ResponseWriter, r *http.Request
 		Notes:              payload.Notes,
 		LastModified:       payload.LastModified,
 	}
+	weatherWarnings := h.attachWeather(r, &upload)
 
-	if err := h.saveWithRetry(func() error { return h.repos.Sync.SaveChemicalTreatment(upload) }); err != nil {
+	if err := h.saveWithRetry(func() error { return h.reposFor(r).Sync.SaveChemicalTreatment(upload) }); err != nil {
 		logger.Error("failed to persist chemical treatment", slog.Any("error", err))
-		respond.Error(w, http.StatusInternalServerError, "failed to queue treatment", "temporary error, please retry")
-		return
+		return transport.UploadResponse{}, failed("failed to queue treatment", err)
 	}
+	h.events.Publish(connector.TreatmentRecorded(upload))
 
-	respond.JSON(w, http.StatusAccepted, transport.UploadResponse{
+	resp := transport.UploadResponse{
 		Success:  true,
 		JobID:    payload.ID,
 		ServerID: payload.ID,
 		Message:  "queued",
-	})
+	}
+	if warning != "" {
+		resp.Warnings = []string{warning}
+	}
+	resp.Warnings = append(resp.Warnings, weatherWarnings...)
+	return resp, nil
 }
 
-// RegisterDevice stores the APNs token for push notifications.
+// reposFor returns the repositories scoped to the tenant r acts for.
+func (h *Handler) reposFor(r *http.Request) repository.Repository {
+	return tenant.Repository(r.Context(), h.repos)
+}
+
+// attachWeather adds the conditions at the stop the technician was
+// servicing when the treatment was applied, and returns their warnings.
+// Treatments without a route that day are stored as typed.
+func (h *Handler) attachWeather(r *http.Request, upload *domain.ChemicalTreatmentUpload) []string {
+	if h.weather == nil || h.repos.Routes == nil {
+		return nil
+	}
+	route, err := h.reposFor(r).Routes.GetRoute(upload.TechnicianID, upload.ApplicationDate)
+	if err != nil {
+		return nil
+	}
+	reading, warnings := h.weather.Treatment(r.Context(), route, upload.ApplicationDate)
+	upload.Weather = reading
+	return warnings
+}
+
+// RegisterDevice stores the APNs token for push notifications against the
+// authenticated technician (or the payload's technicianId).
+// Re-registering a token refreshes it and moves it to that technician.
 func (h *Handler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
 	var payload transport.DeviceRegistration
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
 		return
 	}
+	if err := payload.Validate(); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid device", err.Error(), respond.WithErrors(err))
+		return
+	}
+	technicianID, ok := h.technician(w, r, payload.TechnicianID)
+	if !ok {
+		return
+	}
 
 	logger := middleware.LoggerFrom(r.Context())
 	device := domain.DeviceToken{
 		Token:        payload.Token,
+		TechnicianID: technicianID,
 		Platform:     payload.Platform,
 		BundleID:     payload.BundleID,
 		RegisteredAt: time.Now(),
 	}
 
-	if err := h.saveWithRetry(func() error { return h.repos.Devices.SaveDeviceToken(device) }); err != nil {
+	save := func() error { return h.reposFor(r).Devices.SaveDeviceToken(device) }
+
+	// Device registration is non-critical: push delivery can lag a few
+	// minutes, so don't add load to a struggling datastore.
+	if h.deferred.Active() {
+		if err := h.deferred.Defer("device token", save); err == nil {
+			respond.JSON(w, http.StatusAccepted, map[string]string{"status": "deferred"})
+			return
+		}
+	}
+
+	if err := h.saveWithRetry(save); err != nil {
 		logger.Error("failed to save device token", slog.Any("error", err))
-		respond.Error(w, http.StatusInternalServerError, "failed to register device", "temporary error, please retry")
+		respond.Error(w, http.StatusInternalServerError, "failed to register device", "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
 		return
 	}
 
 	respond.JSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
 }
 
-// GetUpdates returns route/job deltas since the provided timestamp.
+// GetUpdates returns the records the server stored after ?since= (RFC 3339;
+// omitted means everything). The response's lastModified is the newest
+// server write time included and should be sent as since on the next call;
+// it echoes since when nothing changed.
+//
+// Deleted records are listed under deletions, by type, for as long as the
+// server keeps their tombstones; a since or cursor older than that gets 410
+// and the device must sync again from scratch.
+//
+// Records are ordered by lastModified, then id. With ?limit= (or the
+// configured default) a page cut short sets hasMore and nextCursor, which
+// is sent back as ?cursor= in place of since until hasMore is false.
 func (h *Handler) GetUpdates(w http.ResponseWriter, r *http.Request) {
-	sinceParam := r.URL.Query().Get("since")
-
-	var since time.Time
-	var err error
-	if sinceParam != "" {
-		since, err = time.Parse(time.RFC3339, sinceParam)
+	query := r.URL.Query()
+	var page updatesPage
+	if sinceParam := query.Get("since"); sinceParam != "" {
+		since, err := time.Parse(time.RFC3339, sinceParam)
 		if err != nil {
 			respond.Error(w, http.StatusBadRequest, "invalid since parameter", err.Error())
 			return
 		}
+		page.since = since
+	}
+	if cursorParam := query.Get("cursor"); cursorParam != "" {
+		after, err := parseCursor(cursorParam)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid cursor", err.Error())
+			return
+		}
+		page.after = &after
+	}
+	if h.beyondRetention(page, time.Now()) {
+		respond.Error(w, http.StatusGone, "resync required", "since is older than the deletion retention window; discard synced records and sync again without since")
+		return
+	}
+	page.limit = h.cfg.UpdatesDefaultLimit
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		page.limit = limit
+	}
+	if h.cfg.UpdatesMaxLimit > 0 && (page.limit <= 0 || page.limit > h.cfg.UpdatesMaxLimit) {
+		page.limit = h.cfg.UpdatesMaxLimit
 	}
 
 	logger := middleware.LoggerFrom(r.Context())
-	logger.Info("updates requested", slog.Time("since", since))
+	logger.Info("updates requested", slog.Time("since", page.since), slog.Bool("cursor", page.after != nil), slog.Int("limit", page.limit))
+
+	// Technicians only see their own records; staff and unauthenticated
+	// legacy clients see everything.
+	var owner string
+	if id, ok := auth.FromContext(r.Context()); ok && !id.Staff() {
+		owner = id.Subject
+	}
+	payload, err := h.collectUpdates(r.Context(), page, owner)
+	if err != nil {
+		logger.Error("failed to load updates", slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to load updates", "temporary error, please retry", h.retryAfter(), respond.WithCause(err))
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, payload)
+}
+
+// updatesPage selects a page of updates: those after the cursor when there
+// is one and otherwise those stored after since, at most limit of them when
+// limit is positive.
+type updatesPage struct {
+	since time.Time
+	after *updateCursor
+	limit int
+}
+
+// includes reports whether an update at pos falls in the page, before the
+// limit is applied.
+func (p updatesPage) includes(pos updateCursor) bool {
+	if p.after != nil {
+		return p.after.before(pos)
+	}
+	return pos.At.After(p.since)
+}
+
+// start is where listing updates of kinds sorting up to last begins: right
+// after the cursor's record when none of them can sort after it at its
+// position, else at the cursor's timestamp, so records sharing it are
+// considered too.
+func (p updatesPage) start(last string) repository.Page {
+	switch {
+	case p.after == nil:
+		return repository.Page{After: p.since}
+	case last <= p.after.Kind:
+		return repository.Page{After: p.after.At, AfterID: p.after.ID}
+	default:
+		return repository.Page{After: p.after.At.Add(-time.Nanosecond)}
+	}
+}
+
+// beyondRetention reports whether page starts before the oldest tombstones
+// the server may still hold, so deletions since then could be missed.
+func (h *Handler) beyondRetention(page updatesPage, now time.Time) bool {
+	from := page.since
+	if page.after != nil {
+		from = page.after.At
+	}
+	return h.cfg.TombstoneRetention > 0 && !from.IsZero() && from.Before(now.Add(-h.cfg.TombstoneRetention))
+}
+
+// signatureURL signs a URL of a job's signature. Failures are logged and
+// leave the URL out: the device can sign one with the signature's ID.
+func (h *Handler) signatureURL(ctx context.Context, photoID string) string {
+	if photoID == "" || h.photos == nil {
+		return ""
+	}
+	url, _, err := h.photos.URL(ctx, photoID)
+	if err != nil {
+		middleware.LoggerFrom(ctx).Warn("failed to sign signature url", slog.String("photo", photoID), slog.Any("error", err))
+		return ""
+	}
+	return url
+}
+
+// update is one record of a page, added to the payload once the page is cut.
+type update struct {
+	pos updateCursor
+	add func(*transport.ServerUpdates)
+}
+
+// listUpdates lists the updates in page of kinds sorting up to last, with
+// convert turning a record into its update and whether the device may see
+// it. With
+// a limit it pages until it has one more visible update than the limit, so the merged page can tell whether more follow, or the records
+// run out; without one it lists them all.
+func listUpdates[T any](page updatesPage, last string, list func(repository.Page) ([]T, error), convert func(T) (update, bool)) ([]update, error) {
+	want := 0
+	if page.limit > 0 {
+		want = page.limit + 1
+	}
+	next := page.start(last)
+	next.Limit = want
+	var out []update
+	for {
+		records, err := list(next)
+		if err != nil {
+			return nil, err
+		}
+		var last update
+		for _, record := range records {
+			u, visible := convert(record)
+			last = u
+			if visible && page.includes(u.pos) {
+				out = append(out, u)
+			}
+		}
+		if want == 0 || len(records) < next.Limit || len(out) >= want {
+			return out, nil
+		}
+		next = next.Next(last.pos.At, last.pos.ID)
+	}
+}
 
+// collectUpdates gathers a page of updates. With an owner, routes of other
+// technicians and records attributed to them are left out; records without
+// a technician predate attribution and stay visible.
+func (h *Handler) collectUpdates(ctx context.Context, page updatesPage, owner string) (transport.ServerUpdates, error) {
+	repos := tenant.Repository(ctx, h.repos)
 	payload := transport.ServerUpdates{
 		Jobs:               []transport.JobUpdateData{},
 		Routes:             []transport.RouteUpdateData{},
 		Chemicals:          []transport.ChemicalUpdateData{},
 		ChemicalTreatments: []transport.ChemicalTreatmentUpdateData{},
+		Deletions: transport.DeletionsData{
+			Jobs:      []transport.DeletionData{},
+			Routes:    []transport.DeletionData{},
+			Chemicals: []transport.DeletionData{},
+		},
+		LastModified: page.since,
 	}
+	if page.after != nil {
+		payload.LastModified = page.after.At
+	}
+	hidden := func(technicianID string) bool {
+		return owner != "" && technicianID != "" && technicianID != owner
+	}
+	var updates []update
 
-	respond.JSON(w, http.StatusOK, payload)
+	jobs, err := listUpdates(page, kindJob, repos.Sync.ListJobUpdates, func(j domain.JobUpload) (update, bool) {
+		data := transport.JobUpdateData{
+			ServerID:      j.ID,
+			CustomerName:  j.CustomerName,
+			Address:       j.Address,
+			ScheduledDate: j.ScheduledDate,
+			Status:        j.Status,
+			LastModified:  j.ReceivedAt,
+			SignatureID:   j.SignatureID,
+		}
+		if j.HasCoordinates() {
+			data.Coordinate = &transport.Coordinate{Latitude: j.Latitude, Longitude: j.Longitude}
+		}
+		return update{
+			pos: updateCursor{At: data.LastModified, ID: data.ServerID, Kind: kindJob},
+			add: func(p *transport.ServerUpdates) {
+				data.SignatureURL = h.signatureURL(ctx, data.SignatureID)
+				p.Jobs = append(p.Jobs, data)
+			},
+		}, !hidden(j.TechnicianID)
+	})
+	if err != nil {
+		return payload, err
+	}
+	updates = append(updates, jobs...)
+
+	routes, err := listUpdates(page, kindRoute, repos.Sync.ListRouteUpdates, func(rt domain.Route) (update, bool) {
+		data := transport.RouteUpdateData{
+			ServerID:     rt.ServerID(),
+			Name:         "Route " + rt.ServiceDate.Format("Mon Jan 2"),
+			Date:         rt.ServiceDate,
+			TechnicianID: rt.TechnicianID,
+			Stops:        make([]transport.RouteStopUpdateData, len(rt.CustomerStops)),
+			LastModified: rt.LastModified,
+		}
+		for i, stop := range rt.CustomerStops {
+			data.Stops[i] = transport.RouteStopUpdateData{CustomerID: stop.CustomerID, CustomerName: stop.CustomerName, Address: stop.Address}
+			if stop.HasCoordinates() {
+				data.Stops[i].Coordinate = &transport.Coordinate{Latitude: stop.Latitude, Longitude: stop.Longitude}
+			}
+		}
+		return update{
+			pos: updateCursor{At: data.LastModified, ID: data.ServerID, Kind: kindRoute},
+			add: func(p *transport.ServerUpdates) { p.Routes = append(p.Routes, data) },
+		}, owner == "" || rt.TechnicianID == owner
+	})
+	if err != nil {
+		return payload, err
+	}
+	updates = append(updates, routes...)
+
+	chemicals, err := listUpdates(page, kindChemical, repos.Sync.ListChemicalUpdates, func(c domain.ChemicalUpload) (update, bool) {
+		data := transport.ChemicalUpdateData{
+			ServerID:         c.ID,
+			Name:             c.Name,
+			ActiveIngredient: c.ActiveIngredient,
+			ManufacturerName: c.ManufacturerName,
+			EPARegistration:  c.EPARegistration,
+			QuantityInStock:  c.QuantityInStock,
+			UnitOfMeasure:    c.UnitOfMeasure,
+			ExpirationDate:   c.ExpirationDate,
+			Lots:             lotData(c.Lots),
+			LastModified:     c.LastModified,
+		}
+		return update{
+			pos: updateCursor{At: data.LastModified, ID: data.ServerID, Kind: kindChemical},
+			add: func(p *transport.ServerUpdates) { p.Chemicals = append(p.Chemicals, data) },
+		}, !hidden(c.TechnicianID)
+	})
+	if err != nil {
+		return payload, err
+	}
+	updates = append(updates, chemicals...)
+
+	treatments, err := listUpdates(page, kindTreatment, repos.Sync.ListTreatmentUpdates, func(t domain.ChemicalTreatmentUpload) (update, bool) {
+		data := transport.ChemicalTreatmentUpdateData{
+			ServerID:          t.ID,
+			JobServerID:       t.JobID,
+			ChemicalServerID:  t.ChemicalID,
+			LotNumber:         t.LotNumber,
+			MixID:             t.MixID,
+			EquipmentID:       t.EquipmentID,
+			ApplicationDate:   t.ApplicationDate,
+			ApplicationMethod: t.ApplicationMethod,
+			TargetPests:       t.TargetPests,
+			QuantityUsed:      t.QuantityUsed,
+			LastModified:      t.LastModified,
+		}
+		if w := t.Weather; w != nil {
+			data.Weather = &transport.WeatherReading{Source: w.Source, TemperatureC: w.TemperatureC, WindSpeedKPH: w.WindSpeedKPH, WindGustKPH: w.WindGustKPH, Summary: w.Summary, At: w.At}
+		}
+		return update{
+			pos: updateCursor{At: data.LastModified, ID: data.ServerID, Kind: kindTreatment},
+			add: func(p *transport.ServerUpdates) { p.ChemicalTreatments = append(p.ChemicalTreatments, data) },
+		}, !hidden(t.TechnicianID)
+	})
+	if err != nil {
+		return payload, err
+	}
+	updates = append(updates, treatments...)
+
+	deletions, err := listUpdates(page, kindDeletedRoute, repos.Sync.ListDeletions, func(d domain.Tombstone) (update, bool) {
+		data := transport.DeletionData{ServerID: d.ID, DeletedAt: d.DeletedAt}
+		u := update{pos: updateCursor{At: data.DeletedAt, ID: data.ServerID}}
+		visible := !hidden(d.TechnicianID)
+		switch d.Kind {
+		case domain.TombstoneJob:
+			u.pos.Kind, u.add = kindDeletedJob, func(p *transport.ServerUpdates) { p.Deletions.Jobs = append(p.Deletions.Jobs, data) }
+		case domain.TombstoneRoute:
+			visible = owner == "" || d.TechnicianID == owner
+			u.pos.Kind, u.add = kindDeletedRoute, func(p *transport.ServerUpdates) { p.Deletions.Routes = append(p.Deletions.Routes, data) }
+		case domain.TombstoneChemical:
+			u.pos.Kind, u.add = kindDeletedChemical, func(p *transport.ServerUpdates) { p.Deletions.Chemicals = append(p.Deletions.Chemicals, data) }
+		default:
+			visible = false
+		}
+		return u, visible
+	})
+	if err != nil {
+		return payload, err
+	}
+	updates = append(updates, deletions...)
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].pos.before(updates[j].pos) })
+	if page.limit > 0 && len(updates) > page.limit {
+		updates = updates[:page.limit]
+		payload.HasMore = true
+		payload.NextCursor = updates[len(updates)-1].pos.String()
+	}
+	for _, u := range updates {
+		u.add(&payload)
+		if u.pos.At.After(payload.LastModified) {
+			payload.LastModified = u.pos.At
+		}
+	}
+	return payload, nil
+}
+
+// retryAfter is how long a device should wait before resending after a
+// temporary failure: the backoff the server already retried with.
+func (h *Handler) retryAfter() respond.Option {
+	backoff := h.cfg.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return respond.WithRetryAfter(backoff)
 }
 
 func (h *Handler) saveWithRetry(fn func() error) error {
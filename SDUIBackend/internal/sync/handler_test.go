@@ -0,0 +1,615 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+	"github.com/your-org/pestgenie-sdui/internal/chemical"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+	"github.com/your-org/pestgenie-sdui/internal/weather"
+)
+
+func getUpdates(t *testing.T, h *Handler, since time.Time) transport.ServerUpdates {
+	t.Helper()
+	target := "/v1/updates"
+	if !since.IsZero() {
+		target += "?since=" + since.Format(time.RFC3339Nano)
+	}
+	rec := httptest.NewRecorder()
+	h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var out transport.ServerUpdates
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return out
+}
+
+func TestGetUpdatesReturnsDeltasSinceWatermark(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), CustomerStops: []domain.RouteStop{
+		{CustomerID: "c1", Address: "100 Main St", Latitude: 30.2682, Longitude: -97.7429},
+		{CustomerID: "c2", Address: "25 River Rd"},
+	}})
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "job-1", Status: "scheduled", Latitude: 30.2553, Longitude: -97.7630})
+	_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: "chem-1", Name: "Termidor"})
+
+	first := getUpdates(t, h, time.Time{})
+	if len(first.Routes) != 1 || len(first.Jobs) != 1 || len(first.Chemicals) != 1 || first.LastModified.IsZero() {
+		t.Fatalf("unexpected initial updates %+v", first)
+	}
+	if first.Routes[0].ServerID != "tech-1_2026-04-02" {
+		t.Fatalf("unexpected route id %q", first.Routes[0].ServerID)
+	}
+	if stops := first.Routes[0].Stops; len(stops) != 2 || stops[0].Coordinate == nil || stops[0].Coordinate.Latitude != 30.2682 || stops[1].Coordinate != nil {
+		t.Fatalf("expected stops with coordinates once geocoded, got %+v", stops)
+	}
+	if c := first.Jobs[0].Coordinate; c == nil || c.Longitude != -97.7630 {
+		t.Fatalf("expected the job's coordinates, got %+v", c)
+	}
+
+	if again := getUpdates(t, h, first.LastModified); len(again.Jobs)+len(again.Routes)+len(again.Chemicals) != 0 || !again.LastModified.Equal(first.LastModified) {
+		t.Fatalf("expected no changes with unchanged watermark, got %+v", again)
+	}
+
+	time.Sleep(time.Millisecond)
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "job-1", Status: "completed"})
+	_ = store.SaveChemicalTreatment(domain.ChemicalTreatmentUpload{ID: "t-1", JobID: "job-1", QuantityUsed: 2})
+
+	next := getUpdates(t, h, first.LastModified)
+	if len(next.Jobs) != 1 || next.Jobs[0].Status != "completed" || len(next.ChemicalTreatments) != 1 || len(next.Routes) != 0 {
+		t.Fatalf("unexpected delta %+v", next)
+	}
+	if !next.LastModified.After(first.LastModified) {
+		t.Fatalf("expected watermark to advance, got %s", next.LastModified)
+	}
+}
+
+func TestGetUpdatesReportsDeletions(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1, TombstoneRetention: time.Hour}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	day := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	_ = store.SaveRoute(domain.Route{TechnicianID: "tech-1", ServiceDate: day})
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "job-1"})
+	_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: "chem-1"})
+	first := getUpdates(t, h, time.Time{})
+
+	time.Sleep(time.Millisecond)
+	_ = store.DeleteJob("job-1")
+	_ = store.DeleteChemical("chem-1")
+	_ = store.DeleteRoute("tech-1", day)
+	_ = store.DeleteJob("job-missing")
+
+	next := getUpdates(t, h, first.LastModified)
+	del := next.Deletions
+	if len(del.Jobs) != 1 || del.Jobs[0].ServerID != "job-1" || len(del.Chemicals) != 1 || len(del.Routes) != 1 || del.Routes[0].ServerID != "tech-1_2026-04-02" {
+		t.Fatalf("unexpected deletions %+v", del)
+	}
+	if !next.LastModified.After(first.LastModified) {
+		t.Fatalf("expected deletions to advance the watermark, got %s", next.LastModified)
+	}
+	if all := getUpdates(t, h, time.Time{}); len(all.Jobs)+len(all.Routes)+len(all.Chemicals) != 0 {
+		t.Fatalf("deleted records must not be listed as updates, got %+v", all)
+	}
+	if _, err := store.GetRoute("tech-1", day); err == nil {
+		t.Fatal("expected a deleted route to be not found")
+	}
+
+	time.Sleep(time.Millisecond)
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "job-1"})
+	restored := getUpdates(t, h, next.LastModified)
+	if len(restored.Jobs) != 1 || len(restored.Deletions.Jobs) != 0 {
+		t.Fatalf("expected saving again to restore the job, got %+v", restored)
+	}
+
+	rec := httptest.NewRecorder()
+	stale := time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, "/v1/updates?since="+stale, nil))
+	if rec.Code != http.StatusGone {
+		t.Fatalf("expected 410 for a watermark older than retention, got %d", rec.Code)
+	}
+
+	h.pruneTombstones(time.Now().Add(2 * time.Hour))
+	if tombstones, _ := store.ListDeletionsSince(time.Time{}); len(tombstones) != 0 {
+		t.Fatalf("expected expired tombstones pruned, got %+v", tombstones)
+	}
+	if jobs, _ := store.ListJobUpdatesSince(time.Time{}); len(jobs) != 1 {
+		t.Fatalf("pruning must keep live records, got %+v", jobs)
+	}
+}
+
+func TestGetUpdatesPagesWithCursor(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1, UpdatesMaxLimit: 4}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	for _, id := range []string{"job-c", "job-a", "job-b"} {
+		_ = store.SaveJobUpload(domain.JobUpload{ID: id})
+	}
+	for _, id := range []string{"chem-1", "chem-2", "chem-3"} {
+		_ = store.SaveChemicalUpload(domain.ChemicalUpload{ID: id})
+	}
+	_ = store.SaveChemicalTreatment(domain.ChemicalTreatmentUpload{ID: "t-1"})
+
+	page := func(query string) transport.ServerUpdates {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, "/v1/updates?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+		var out transport.ServerUpdates
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	var seen []string
+	var last time.Time
+	query := "limit=3"
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("paging did not finish")
+		}
+		p := page(query)
+		for _, j := range p.Jobs {
+			seen = append(seen, j.ServerID)
+		}
+		for _, c := range p.Chemicals {
+			seen = append(seen, c.ServerID)
+		}
+		for _, tr := range p.ChemicalTreatments {
+			seen = append(seen, tr.ServerID)
+		}
+		if p.LastModified.Before(last) {
+			t.Fatalf("watermark went backwards: %s after %s", p.LastModified, last)
+		}
+		last = p.LastModified
+		if !p.HasMore {
+			if p.NextCursor != "" {
+				t.Fatalf("expected no cursor on the last page, got %q", p.NextCursor)
+			}
+			break
+		}
+		query = "limit=3&cursor=" + p.NextCursor
+	}
+	sort.Strings(seen)
+	if strings.Join(seen, ",") != "chem-1,chem-2,chem-3,job-a,job-b,job-c,t-1" {
+		t.Fatalf("expected every record exactly once, got %v", seen)
+	}
+
+	if p := page(""); !p.HasMore || len(p.Jobs)+len(p.Chemicals) != 4 {
+		t.Fatalf("expected pages capped at the configured maximum, got %+v", p)
+	}
+	for _, bad := range []string{"limit=0", "limit=x", "cursor=%21%21", "cursor=" + updateCursor{}.String()[:4]} {
+		rec := httptest.NewRecorder()
+		h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, "/v1/updates?"+bad, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, rec.Code)
+		}
+	}
+}
+
+// countingSync counts the jobs the paged delta query returns.
+type countingSync struct {
+	repository.SyncRepository
+	jobsRead int
+}
+
+func (c *countingSync) ListJobUpdates(page repository.Page) ([]domain.JobUpload, error) {
+	jobs, err := c.SyncRepository.ListJobUpdates(page)
+	c.jobsRead += len(jobs)
+	return jobs, err
+}
+
+func TestGetUpdatesReadsOnlyAPage(t *testing.T) {
+	store := storememory.NewStore()
+	sync := &countingSync{SyncRepository: store}
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: sync, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1, UpdatesMaxLimit: 10}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	for i := 0; i < 50; i++ {
+		_ = store.SaveJobUpload(domain.JobUpload{ID: fmt.Sprintf("job-%02d", i)})
+	}
+
+	query := "limit=3"
+	for pages := 0; pages < 3; pages++ {
+		sync.jobsRead = 0
+		rec := httptest.NewRecorder()
+		h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, "/v1/updates?"+query, nil))
+		var p transport.ServerUpdates
+		if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || len(p.Jobs) != 3 || !p.HasMore {
+			t.Fatalf("expected a full page, got %+v (%v)", p, err)
+		}
+		if sync.jobsRead > 4 {
+			t.Fatalf("expected a page of 3 to read at most 4 jobs, read %d", sync.jobsRead)
+		}
+		query = "limit=3&cursor=" + p.NextCursor
+	}
+}
+
+func TestUpdateCursorOrdersTies(t *testing.T) {
+	at := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	a := updateCursor{At: at, ID: "x", Kind: kindChemical}
+	b := updateCursor{At: at, ID: "x", Kind: kindJob}
+	c := updateCursor{At: at, ID: "y", Kind: kindChemical}
+	if !a.before(b) || !b.before(c) || c.before(a) {
+		t.Fatal("expected ties broken by id and then kind")
+	}
+	parsed, err := parseCursor(c.String())
+	if err != nil || parsed != c {
+		t.Fatalf("cursor did not round-trip: %+v (%v)", parsed, err)
+	}
+}
+
+func TestGetUpdatesRejectsInvalidSince(t *testing.T) {
+	store := storememory.NewStore()
+	h := NewHandler(repository.Repository{Sync: store}, config.SyncConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	rec := httptest.NewRecorder()
+	h.GetUpdates(rec, httptest.NewRequest(http.MethodGet, "/v1/updates?since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestDeviceRegistrationAndManagement(t *testing.T) {
+	store := storememory.NewStore()
+	store.AddTechnician(domain.Technician{ID: "tech-1"})
+	store.AddTechnician(domain.Technician{ID: "tech-2"})
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1, DeviceStaleAfter: time.Hour}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	register := func(query, body string) int {
+		rec := httptest.NewRecorder()
+		h.RegisterDevice(rec, httptest.NewRequest(http.MethodPost, "/v1/devices/register"+query, strings.NewReader(body)))
+		return rec.Code
+	}
+	if code := register("", `{"token":"aa","platform":"ios"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected registration without a technician to fail, got %d", code)
+	}
+	if code := register("?userId=ghost", `{"token":"aa","platform":"ios"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected unknown technician to fail, got %d", code)
+	}
+	for _, req := range [][2]string{
+		{"?userId=tech-1", `{"token":"aa","platform":"ios"}`},
+		{"?userId=tech-1", `{"token":"aa","platform":"ios","bundleId":"app"}`},
+		{"", `{"token":"bb","platform":"ios","technicianId":"tech-2"}`},
+	} {
+		if code := register(req[0], req[1]); code != http.StatusAccepted {
+			t.Fatalf("register %s: unexpected status %d", req[1], code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ListDevices(rec, httptest.NewRequest(http.MethodGet, "/v1/devices?userId=tech-1", nil))
+	var listed struct {
+		Devices []transport.DeviceData `json:"devices"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed.Devices) != 1 || listed.Devices[0].BundleID != "app" {
+		t.Fatalf("expected one deduplicated device, got %s (%v)", rec.Body.String(), err)
+	}
+
+	remove := func(userID, token string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/devices/"+token+"?userId="+userID, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("token", token)
+		rec := httptest.NewRecorder()
+		h.DeleteDevice(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+		return rec.Code
+	}
+	if code := remove("tech-1", "bb"); code != http.StatusNotFound {
+		t.Fatalf("expected another technician's token to be hidden, got %d", code)
+	}
+	if code := remove("tech-1", "aa"); code != http.StatusNoContent {
+		t.Fatalf("expected revocation, got %d", code)
+	}
+	if tokens, _ := store.ListDeviceTokens("tech-1"); len(tokens) != 0 {
+		t.Fatalf("expected token removed, got %+v", tokens)
+	}
+
+	h.pruneDevices(time.Now().Add(2 * time.Hour))
+	if tokens, _ := store.ListDeviceTokens("tech-2"); len(tokens) != 0 {
+		t.Fatalf("expected stale registration pruned, got %+v", tokens)
+	}
+}
+
+func TestUploadsUseAuthenticatedTechnician(t *testing.T) {
+	store := storememory.NewStore()
+	store.AddTechnician(domain.Technician{ID: "tech-1"})
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	authenticated := func(req *http.Request) *http.Request {
+		return req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "tech-1"}))
+	}
+	rec := httptest.NewRecorder()
+	h.CreateJob(rec, authenticated(httptest.NewRequest(http.MethodPost, "/v1/jobs?userId=tech-2", strings.NewReader(`{"id":"job-1","status":"completed","customerName":"Ada Lovelace","address":"1 Main St","scheduledDate":"2026-03-02T09:00:00Z"}`))))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body)
+	}
+	if jobs, _ := store.ListPendingJobs(0); len(jobs) != 1 || jobs[0].TechnicianID != "tech-1" {
+		t.Fatalf("expected the job attributed to the token subject, got %+v", jobs)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RegisterDevice(rec, authenticated(httptest.NewRequest(http.MethodPost, "/v1/devices/register", strings.NewReader(`{"token":"aa","platform":"ios","technicianId":"tech-2"}`))))
+	if tokens, _ := store.ListDeviceTokens("tech-1"); rec.Code != http.StatusAccepted || len(tokens) != 1 {
+		t.Fatalf("expected the device registered to the token subject, got %d %+v", rec.Code, tokens)
+	}
+}
+
+func TestUploadsListEveryInvalidField(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.CreateChemicalTreatment(rec, httptest.NewRequest(http.MethodPost, "/v1/chemical-treatments?userId=tech-1",
+		strings.NewReader(`{"id":"t1","chemicalId":"chem-1","quantityUsed":-2,"dosageRate":-1}`)))
+	var problem respond.ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{}
+	for _, fe := range problem.Errors {
+		fields[fe.Field] = fe.Detail
+	}
+	if rec.Code != http.StatusBadRequest || problem.Type != "/problems/validation-failed" || problem.EntityID != "t1" {
+		t.Fatalf("expected a validation problem naming the treatment, got %d %+v", rec.Code, problem)
+	}
+	if len(fields) != 4 || fields["jobId"] != "is required" || fields["applicationDate"] != "is required" || fields["quantityUsed"] != "must be > 0" || fields["dosageRate"] != "must be >= 0" {
+		t.Fatalf("expected every invalid field listed, got %+v", problem.Errors)
+	}
+	treatments, _ := store.ListTreatmentUpdatesSince(time.Time{})
+	if len(treatments) != 0 {
+		t.Fatal("expected the invalid treatment not stored")
+	}
+
+	rec = httptest.NewRecorder()
+	h.CreateChemical(rec, httptest.NewRequest(http.MethodPost, "/v1/chemicals?userId=tech-1",
+		strings.NewReader(`{"id":"chem-1","name":"Termidor SC","lots":[{"lotNumber":"L1","quantity":2},{"lotNumber":"L1","quantity":-1}]}`)))
+	problem = respond.ProblemDetails{}
+	_ = json.NewDecoder(rec.Body).Decode(&problem)
+	if len(problem.Errors) != 2 || problem.Errors[0].Field != "lots[1].lotNumber" || problem.Errors[1].Field != "lots[1].quantity" {
+		t.Fatalf("expected the second lot's fields listed, got %+v", problem.Errors)
+	}
+}
+
+func TestChemicalsCheckedAgainstTheCatalog(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	catalog, err := chemical.Load(config.ChemicalCatalogConfig{SearchLimit: 5})
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, catalog, nil, nil)
+	upload := func(body string) (*httptest.ResponseRecorder, transport.UploadResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.CreateChemical(rec, httptest.NewRequest(http.MethodPost, "/v1/chemicals?userId=tech-1", strings.NewReader(body)))
+		var resp transport.UploadResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		return rec, resp
+	}
+
+	if rec, resp := upload(`{"id":"chem-1","name":"Demand","activeIngredient":"Lambda Cyhalothrin 9.7%","epaRegistrationNumber":" 100-1066 "}`); rec.Code != http.StatusAccepted || len(resp.Warnings) != 0 {
+		t.Fatalf("expected a matching chemical accepted without warnings, got %d %+v", rec.Code, resp)
+	}
+	if saved, _ := store.ListChemicalUpdatesSince(time.Time{}); len(saved) != 1 || saved[0].EPARegistration != "100-1066" {
+		t.Fatalf("expected the registration stored trimmed, got %+v", saved)
+	}
+	if _, resp := upload(`{"id":"chem-2","name":"Termidor","activeIngredient":"bifenthrin","epaRegistrationNumber":"7969-210"}`); len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "fipronil") {
+		t.Fatalf("expected an ingredient mismatch flagged, got %+v", resp)
+	}
+	if _, resp := upload(`{"id":"chem-3","name":"Own label","epaRegistrationNumber":"1-2-3"}`); len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "not in the chemical catalog") {
+		t.Fatalf("expected an unknown registration flagged, got %+v", resp)
+	}
+
+	rec := httptest.NewRecorder()
+	h.CreateChemical(rec, httptest.NewRequest(http.MethodPost, "/v1/chemicals?userId=tech-1", strings.NewReader(`{"id":"chem-4","name":"","epaRegistrationNumber":"EPA 100"}`)))
+	var problem respond.ProblemDetails
+	_ = json.NewDecoder(rec.Body).Decode(&problem)
+	if rec.Code != http.StatusBadRequest || len(problem.Errors) != 2 || problem.Errors[1].Field != "epaRegistrationNumber" {
+		t.Fatalf("expected a malformed registration refused with the other fields, got %d %+v", rec.Code, problem.Errors)
+	}
+}
+
+func TestTreatmentsRequireALotOfTheChemical(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	post := func(handler http.HandlerFunc, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, target+"?userId=tech-1", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := post(h.CreateChemical, "/v1/chemicals", `{"id":"chem-1","name":"Termidor SC","lots":[{"lotNumber":"L1","quantity":2},{"lotNumber":"L1","quantity":1}]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected duplicate lots rejected, got %d", rec.Code)
+	}
+	if rec := post(h.CreateChemical, "/v1/chemicals", `{"id":"chem-1","name":"Termidor SC","quantityInStock":3,"lots":[{"lotNumber":"L1","receivedDate":"2026-03-01T00:00:00Z","quantity":2},{"lotNumber":"L2","quantity":1}]}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected chemical accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post(h.CreateChemicalTreatment, "/v1/chemical-treatments", `{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a treatment without a lot rejected, got %d", rec.Code)
+	}
+	rec := post(h.CreateChemicalTreatment, "/v1/chemical-treatments", `{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"lotNumber":"L9"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown lot rejected, got %d", rec.Code)
+	}
+	var problem respond.ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil || problem.EntityID != "t1" || problem.Retryable || problem.Type != "/problems/lot-required" {
+		t.Fatalf("expected a non-retryable problem naming the treatment, got %+v (%v)", problem, err)
+	}
+	if rec := post(h.CreateChemicalTreatment, "/v1/chemical-treatments", `{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"lotNumber":"L2"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected a treatment with a lot accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	// Chemicals that do not track lots keep accepting treatments without one.
+	if rec := post(h.CreateChemicalTreatment, "/v1/chemical-treatments", `{"id":"t2","jobId":"job-1","chemicalId":"legacy","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected a legacy treatment accepted, got %d", rec.Code)
+	}
+
+	updates := getUpdates(t, h, time.Time{})
+	if len(updates.Chemicals) != 1 || len(updates.Chemicals[0].Lots) != 2 || updates.Chemicals[0].Lots[0].ReceivedDate.IsZero() {
+		t.Fatalf("expected lots in chemical updates, got %+v", updates.Chemicals)
+	}
+	if len(updates.ChemicalTreatments) != 2 || updates.ChemicalTreatments[0].LotNumber != "L2" {
+		t.Fatalf("expected the lot in treatment updates, got %+v", updates.ChemicalTreatments)
+	}
+}
+
+func TestTreatmentsCheckEquipmentCalibration(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	equip := calibration.NewService(config.CalibrationConfig{Interval: 30 * 24 * time.Hour, DueSoon: 7 * 24 * time.Hour, RequiredMethods: []string{"fumigation"}}, calibration.NewMemoryStore(), repos, nil)
+	fogger, err := equip.CreateAsset(calibration.Asset{Name: "Fogger"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := equip.Record(fogger.ID, calibration.Calibration{TechnicianID: "tech-1", CalibratedAt: time.Now().Add(-40 * 24 * time.Hour), OutputRate: 1, OutputUnit: "gal/min"}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, equip, nil, nil, nil, nil, nil)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.CreateChemicalTreatment(rec, httptest.NewRequest(http.MethodPost, "/v1/chemical-treatments?userId=tech-1", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := post(`{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"applicationMethod":"Fumigation","equipmentId":"` + fogger.ID + `"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected overdue equipment rejected for a gated method, got %d", rec.Code)
+	}
+	if rec := post(`{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"equipmentId":"missing"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown equipment rejected, got %d", rec.Code)
+	}
+	rec := post(`{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"applicationMethod":"spray","equipmentId":"` + fogger.ID + `"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected an ungated treatment accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp transport.UploadResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Warnings) != 1 {
+		t.Fatalf("expected a calibration warning, got %+v (%v)", resp, err)
+	}
+
+	updates := getUpdates(t, h, time.Time{})
+	if len(updates.ChemicalTreatments) != 1 || updates.ChemicalTreatments[0].EquipmentID != fogger.ID {
+		t.Fatalf("expected the equipment in treatment updates, got %+v", updates.ChemicalTreatments)
+	}
+}
+
+// windyProvider reports the same blustery conditions everywhere.
+type windyProvider struct{}
+
+func (windyProvider) Name() string { return "stub" }
+
+func (windyProvider) Conditions(_ context.Context, _, _ float64, at time.Time) (weather.Conditions, error) {
+	return weather.Conditions{TemperatureC: 18, WindSpeedKPH: 30, Summary: "Breezy", At: at}, nil
+}
+
+func TestTreatmentsRecordFetchedWeather(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	applied := time.Now().UTC().Add(-30 * time.Minute).Truncate(time.Second)
+	_ = store.SaveRoute(domain.Route{ID: "r1", TechnicianID: "tech-1", ServiceDate: applied, CustomerStops: []domain.RouteStop{
+		{CustomerID: "c1", CustomerName: "Far", Latitude: 1, Longitude: 1},
+		{CustomerID: "c2", CustomerName: "Here", WindowStart: applied.Add(-time.Hour), WindowEnd: applied.Add(time.Hour), Latitude: 30.27, Longitude: -97.74},
+	}})
+	cfg := config.WeatherConfig{MaxWindKPH: 16, MinTemperatureC: 4, MaxTemperatureC: 32, CacheTTL: time.Minute}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1}, nil, nil, nil, nil, nil, weather.NewService(cfg, windyProvider{}, nil), nil, nil, nil)
+
+	body := `{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"` + applied.Format(time.RFC3339) + `","quantityUsed":1.5,"weatherConditions":"calm"}`
+	rec := httptest.NewRecorder()
+	h.CreateChemicalTreatment(rec, httptest.NewRequest(http.MethodPost, "/v1/chemical-treatments?userId=tech-1", strings.NewReader(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected the treatment accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp transport.UploadResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "avoid spraying") {
+		t.Fatalf("expected a wind warning, got %+v (%v)", resp, err)
+	}
+
+	treatments, _ := store.ListPendingTreatments(0)
+	if len(treatments) != 1 || treatments[0].WeatherConditions != "calm" || treatments[0].Weather == nil || treatments[0].Weather.WindSpeedKPH != 30 {
+		t.Fatalf("expected the fetched weather stored beside the typed one, got %+v", treatments)
+	}
+	updates := getUpdates(t, h, time.Time{})
+	if len(updates.ChemicalTreatments) != 1 || updates.ChemicalTreatments[0].Weather == nil || updates.ChemicalTreatments[0].Weather.Source != "stub" {
+		t.Fatalf("expected the weather in treatment updates, got %+v", updates.ChemicalTreatments)
+	}
+}
+
+func TestUploadBatchReportsEachItem(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	h := NewHandler(repos, config.SyncConfig{MaxRetries: 1, BatchMaxItems: 5}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.UploadBatch(rec, httptest.NewRequest(http.MethodPost, "/v1/batch?userId=tech-1", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{
+		"jobs": [{"id":"job-1","status":"scheduled","customerName":"Ada Lovelace","address":"1 Main St","scheduledDate":"2026-03-02T09:00:00Z"}, {"id":"job-2","scheduledDate":"not a date"}],
+		"chemicals": [{"id":"chem-1","name":"Termidor SC","lots":[{"lotNumber":"L1","quantity":2}]}],
+		"chemicalTreatments": [{"id":"t1","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5}, {"id":"t2","jobId":"job-1","chemicalId":"chem-1","applicationDate":"2026-03-02T09:00:00Z","quantityUsed":1.5,"lotNumber":"L1"}]
+	}`)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rec.Code, rec.Body)
+	}
+	var body respond.MultiStatusBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Results) != 5 || body.Succeeded != 3 || body.Failed != 2 || body.Retryable != 0 {
+		t.Fatalf("unexpected batch result %+v", body)
+	}
+	// Chemicals are stored before treatments, so t2 can name chem-1's lot.
+	for i, want := range []struct {
+		kind, id string
+		status   int
+		problem  string
+	}{
+		{batchJob, "job-1", http.StatusAccepted, ""},
+		{batchJob, "job-2", http.StatusBadRequest, "/problems/invalid-request"},
+		{batchChemical, "chem-1", http.StatusAccepted, ""},
+		{batchTreatment, "t1", http.StatusBadRequest, "/problems/lot-required"},
+		{batchTreatment, "t2", http.StatusAccepted, ""},
+	} {
+		got := body.Results[i]
+		if got.Kind != want.kind || got.EntityID != want.id || got.Status != want.status {
+			t.Errorf("result %d: got %+v, want %+v", i, got, want)
+		}
+		if want.problem != "" && (got.Problem == nil || got.Problem.Type != want.problem || got.Problem.EntityID != want.id) {
+			t.Errorf("result %d: unexpected problem %+v", i, got.Problem)
+		}
+	}
+
+	if rec := post(`{"jobs":[{},{},{},{},{},{}]}`); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected an oversized batch rejected, got %d", rec.Code)
+	}
+	if rec := post(`{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an empty batch rejected, got %d", rec.Code)
+	}
+}
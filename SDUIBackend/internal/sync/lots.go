@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// errLotRequired is returned when a treatment does not name one of its
+// chemical's lots.
+var errLotRequired = errors.New("lot required")
+
+// chemicalLots converts the lots of a chemical upload, which Validate has
+// checked.
+func chemicalLots(in []transport.ChemicalLotData) []domain.ChemicalLot {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]domain.ChemicalLot, len(in))
+	for i, lot := range in {
+		out[i] = domain.ChemicalLot{Number: strings.TrimSpace(lot.LotNumber), ReceivedAt: lot.ReceivedDate, ExpirationDate: lot.ExpirationDate, Quantity: lot.Quantity}
+	}
+	return out
+}
+
+func lotData(lots []domain.ChemicalLot) []transport.ChemicalLotData {
+	if len(lots) == 0 {
+		return nil
+	}
+	out := make([]transport.ChemicalLotData, len(lots))
+	for i, lot := range lots {
+		out[i] = transport.ChemicalLotData{LotNumber: lot.Number, ReceivedDate: lot.ReceivedAt, ExpirationDate: lot.ExpirationDate, Quantity: lot.Quantity}
+	}
+	return out
+}
+
+// checkLot requires a treatment of a chemical that tracks lots to name one
+// of them, so the application can be traced in a recall. Chemicals without
+// lots, or not yet synced, accept any lot number.
+func (h *Handler) checkLot(chemicalID, lotNumber string) error {
+	chemicals, err := h.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, c := range chemicals {
+		if c.ID != chemicalID || len(c.Lots) == 0 {
+			continue
+		}
+		numbers := make([]string, 0, len(c.Lots))
+		for _, lot := range c.Lots {
+			if lot.Number == lotNumber {
+				return nil
+			}
+			numbers = append(numbers, lot.Number)
+		}
+		if lotNumber == "" {
+			return fmt.Errorf("%w: %s tracks lots; select one of %s", errLotRequired, c.Name, strings.Join(numbers, ", "))
+		}
+		return fmt.Errorf("%w: lot %q is not a lot of %s; select one of %s", errLotRequired, lotNumber, c.Name, strings.Join(numbers, ", "))
+	}
+	return nil
+}
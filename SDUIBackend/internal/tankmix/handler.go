@@ -0,0 +1,153 @@
+package tankmix
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes recipes, batch calculation and mix applications to
+// technicians, and recipe management in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a tank mix handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListRecipes)
+	r.Post("/", h.CreateRecipe)
+	r.Get("/{mixId}", h.GetRecipe)
+	r.Put("/{mixId}", h.UpdateRecipe)
+	r.Delete("/{mixId}", h.DeleteRecipe)
+	r.Get("/{mixId}/applications", h.ListApplications)
+}
+
+// ListRecipes returns every recipe.
+func (h *Handler) ListRecipes(w http.ResponseWriter, r *http.Request) {
+	recipes, err := h.service.Recipes()
+	if err != nil {
+		h.fail(w, r, "failed to list tank mixes", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"mixes": recipes})
+}
+
+// GetRecipe returns a recipe.
+func (h *Handler) GetRecipe(w http.ResponseWriter, r *http.Request) {
+	recipe, err := h.service.Recipe(chi.URLParam(r, "mixId"))
+	if err != nil {
+		h.fail(w, r, "failed to load tank mix", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, recipe)
+}
+
+// CreateRecipe adds a recipe.
+func (h *Handler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
+	var payload Recipe
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	recipe, err := h.service.CreateRecipe(payload)
+	if err != nil {
+		h.fail(w, r, "failed to create tank mix", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, recipe)
+}
+
+// UpdateRecipe replaces a recipe.
+func (h *Handler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	var payload Recipe
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	recipe, err := h.service.UpdateRecipe(chi.URLParam(r, "mixId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to update tank mix", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, recipe)
+}
+
+// DeleteRecipe removes a recipe.
+func (h *Handler) DeleteRecipe(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRecipe(chi.URLParam(r, "mixId")); err != nil {
+		h.fail(w, r, "failed to delete tank mix", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListApplications returns a mix's applications.
+func (h *Handler) ListApplications(w http.ResponseWriter, r *http.Request) {
+	applications, err := h.service.Applications(chi.URLParam(r, "mixId"))
+	if err != nil {
+		h.fail(w, r, "failed to list tank mix applications", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"applications": applications})
+}
+
+// CalculateBatch returns per-product quantities for a tank of ?tankSize=.
+func (h *Handler) CalculateBatch(w http.ResponseWriter, r *http.Request) {
+	tankSize, err := strconv.ParseFloat(r.URL.Query().Get("tankSize"), 64)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid tankSize", "tankSize must be a number in the mix's volume unit")
+		return
+	}
+	batch, err := h.service.Calculate(chi.URLParam(r, "mixId"), tankSize)
+	if err != nil {
+		h.fail(w, r, "failed to calculate batch", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, batch)
+}
+
+// ApplyMix logs a mix application by the authenticated technician.
+func (h *Handler) ApplyMix(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var payload ApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	application, err := h.service.Apply(me, chi.URLParam(r, "mixId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to log tank mix application", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, application)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidRecipe), errors.Is(err, ErrInvalidBatch), errors.Is(err, ErrInvalidApplication):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,224 @@
+// Package tankmix manages tank mix recipes: several products mixed into one
+// carrier at fixed rates. Technicians calculate per-product quantities for
+// the tank they are filling, and log what they sprayed as a mix application,
+// which records a treatment per component and draws each component down on
+// their truck.
+package tankmix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+)
+
+var (
+	// ErrNotFound is returned when a recipe or application does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidRecipe wraps recipe validation failures.
+	ErrInvalidRecipe = errors.New("invalid recipe")
+	// ErrInvalidBatch is returned for a tank size a batch cannot be
+	// calculated for.
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrInvalidApplication wraps mix application failures.
+	ErrInvalidApplication = errors.New("invalid application")
+)
+
+// Component is one product in a recipe. Product.UnitOfMeasure is the unit of
+// Rate, and the product is matched to a technician's chemicals like stock
+// transfers are: by EPA registration or, without one, by name.
+type Component struct {
+	Product inventory.Product `json:"product"`
+	Rate    float64           `json:"rate"` // quantity per recipe CarrierVolume
+}
+
+// Recipe is a tank mix: components at rates per CarrierVolume of carrier.
+type Recipe struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Carrier     string `json:"carrier"` // defaults to water
+	// CarrierVolume is the volume of carrier the component rates are for,
+	// in VolumeUnit; tank sizes are given in the same unit.
+	CarrierVolume float64     `json:"carrierVolume"`
+	VolumeUnit    string      `json:"volumeUnit"`
+	Components    []Component `json:"components"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+}
+
+// Validate checks a recipe.
+func (r Recipe) Validate() error {
+	var problems []string
+	if strings.TrimSpace(r.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if r.CarrierVolume <= 0 {
+		problems = append(problems, "carrierVolume must be > 0")
+	}
+	if strings.TrimSpace(r.VolumeUnit) == "" {
+		problems = append(problems, "volumeUnit is required")
+	}
+	if len(r.Components) == 0 {
+		problems = append(problems, "at least one component is required")
+	}
+	seen := make(map[string]bool, len(r.Components))
+	for i, c := range r.Components {
+		if strings.TrimSpace(c.Product.Name) == "" {
+			problems = append(problems, fmt.Sprintf("components[%d].product.name is required", i))
+			continue
+		}
+		if seen[c.Product.Key()] {
+			problems = append(problems, fmt.Sprintf("%s is listed more than once", c.Product.Name))
+		}
+		seen[c.Product.Key()] = true
+		if c.Product.UnitOfMeasure == "" {
+			problems = append(problems, fmt.Sprintf("components[%d].product.unitOfMeasure is required", i))
+		}
+		if c.Rate <= 0 {
+			problems = append(problems, fmt.Sprintf("components[%d].rate must be > 0", i))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidRecipe, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Usage is one component of an application: the treatment recorded for it
+// and how much was drawn from the technician's chemical.
+type Usage struct {
+	TreatmentID string            `json:"treatmentId"`
+	ChemicalID  string            `json:"chemicalId"`
+	Product     inventory.Product `json:"product"`
+	LotNumber   string            `json:"lotNumber,omitempty"`
+	Quantity    float64           `json:"quantity"`
+}
+
+// Application is a technician spraying a volume of a mix on a job.
+type Application struct {
+	ID              string    `json:"id"`
+	MixID           string    `json:"mixId"`
+	MixName         string    `json:"mixName"`
+	TechnicianID    string    `json:"technicianId"`
+	JobID           string    `json:"jobId"`
+	VolumeApplied   float64   `json:"volumeApplied"`
+	VolumeUnit      string    `json:"volumeUnit"`
+	ApplicationDate time.Time `json:"applicationDate"`
+	EquipmentID     string    `json:"equipmentId,omitempty"`
+	Usage           []Usage   `json:"usage"`
+	// Warnings were raised when the application was logged, such as
+	// equipment due for calibration.
+	Warnings   []string  `json:"warnings,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Store persists recipes and applications.
+type Store interface {
+	SaveRecipe(r Recipe) error
+	GetRecipe(id string) (Recipe, error)
+	ListRecipes() ([]Recipe, error)
+	DeleteRecipe(id string) error
+
+	SaveApplication(a Application) error
+	GetApplication(id string) (Application, error)
+	// ListApplications returns a mix's applications, oldest first.
+	ListApplications(mixID string) ([]Application, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	recipes      map[string]Recipe
+	applications map[string]Application
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{recipes: make(map[string]Recipe), applications: make(map[string]Application)}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveRecipe(r Recipe) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recipes[r.ID] = r
+	return nil
+}
+
+func (m *MemoryStore) GetRecipe(id string) (Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.recipes[id]
+	if !ok {
+		return Recipe{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *MemoryStore) ListRecipes() ([]Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Recipe, 0, len(m.recipes))
+	for _, r := range m.recipes {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) DeleteRecipe(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.recipes[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.recipes, id)
+	return nil
+}
+
+func (m *MemoryStore) SaveApplication(a Application) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.applications[a.ID] = a
+	return nil
+}
+
+func (m *MemoryStore) GetApplication(id string) (Application, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.applications[id]
+	if !ok {
+		return Application{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (m *MemoryStore) ListApplications(mixID string) ([]Application, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Application{}
+	for _, a := range m.applications {
+		if a.MixID == mixID {
+			out = append(out, a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ApplicationDate.Equal(out[j].ApplicationDate) {
+			return out[i].ApplicationDate.Before(out[j].ApplicationDate)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
@@ -0,0 +1,353 @@
+package tankmix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/calibration"
+	"github.com/your-org/pestgenie-sdui/internal/connector"
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+)
+
+// Batch is a recipe scaled to a tank.
+type Batch struct {
+	MixID      string     `json:"mixId"`
+	MixName    string     `json:"mixName"`
+	Carrier    string     `json:"carrier"`
+	TankSize   float64    `json:"tankSize"`
+	VolumeUnit string     `json:"volumeUnit"`
+	Components []Quantity `json:"components"`
+}
+
+// Quantity is how much of a product goes into a batch.
+type Quantity struct {
+	Product  inventory.Product `json:"product"`
+	Rate     float64           `json:"rate"`
+	Quantity float64           `json:"quantity"`
+}
+
+// ApplyRequest logs a volume of a mix sprayed on a job.
+type ApplyRequest struct {
+	// ID is generated by the device; re-sending an application with the
+	// same ID returns the recorded one instead of drawing stock down twice.
+	ID              string    `json:"id"`
+	JobID           string    `json:"jobId"`
+	VolumeApplied   float64   `json:"volumeApplied"` // finished mix, in the recipe's volumeUnit
+	ApplicationDate time.Time `json:"applicationDate"`
+	// Lots names the lot drawn from for components whose chemical tracks
+	// lots, keyed by the component's EPA registration (or lower-case name
+	// when it has none).
+	Lots               map[string]string `json:"lots,omitempty"`
+	EquipmentID        string            `json:"equipmentId,omitempty"`
+	ApplicatorName     string            `json:"applicatorName"`
+	ApplicationMethod  string            `json:"applicationMethod"`
+	TargetPests        string            `json:"targetPests"`
+	EnvironmentalNotes string            `json:"environmentalConditions"`
+	WeatherSummary     string            `json:"weatherConditions"`
+	Notes              string            `json:"notes"`
+}
+
+// Service manages recipes and logs mix applications against technicians'
+// synced chemicals.
+type Service struct {
+	store  Store
+	repos  repository.Repository
+	events *connector.Service
+	equip  *calibration.Service
+	logger *slog.Logger
+	now    func() time.Time
+
+	// mu serializes applications so concurrent draws on the same chemical
+	// record do not overwrite each other.
+	mu sync.Mutex
+}
+
+// NewService creates a tank mix service. Recorded treatments and updated
+// chemicals are published to events; a nil events disables publishing.
+// Applications are checked against equipment calibration; a nil equip skips
+// the check.
+func NewService(store Store, repos repository.Repository, events *connector.Service, equip *calibration.Service, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, repos: repos, events: events, equip: equip, logger: logger, now: time.Now}
+}
+
+// CreateRecipe adds a recipe.
+func (s *Service) CreateRecipe(r Recipe) (Recipe, error) {
+	r.ID = uuid.NewString()
+	r.CreatedAt = time.Time{}
+	return s.save(r)
+}
+
+// UpdateRecipe replaces a recipe. Recorded applications keep the quantities
+// they were logged with.
+func (s *Service) UpdateRecipe(id string, r Recipe) (Recipe, error) {
+	existing, err := s.store.GetRecipe(id)
+	if err != nil {
+		return Recipe{}, err
+	}
+	r.ID, r.CreatedAt = id, existing.CreatedAt
+	return s.save(r)
+}
+
+func (s *Service) save(r Recipe) (Recipe, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Carrier = strings.TrimSpace(r.Carrier)
+	if r.Carrier == "" {
+		r.Carrier = "water"
+	}
+	for i := range r.Components {
+		r.Components[i].Product.Name = strings.TrimSpace(r.Components[i].Product.Name)
+		r.Components[i].Product.EPARegistration = strings.TrimSpace(r.Components[i].Product.EPARegistration)
+	}
+	if err := r.Validate(); err != nil {
+		return Recipe{}, err
+	}
+	now := s.now().UTC()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	if err := s.store.SaveRecipe(r); err != nil {
+		return Recipe{}, err
+	}
+	s.logger.Info("tank mix saved", slog.String("mix", r.ID), slog.String("name", r.Name), slog.Int("components", len(r.Components)))
+	return r, nil
+}
+
+// Recipe returns a recipe.
+func (s *Service) Recipe(id string) (Recipe, error) {
+	return s.store.GetRecipe(id)
+}
+
+// Recipes lists every recipe by name.
+func (s *Service) Recipes() ([]Recipe, error) {
+	return s.store.ListRecipes()
+}
+
+// DeleteRecipe removes a recipe. Its applications are kept.
+func (s *Service) DeleteRecipe(id string) error {
+	return s.store.DeleteRecipe(id)
+}
+
+// Applications lists a mix's applications, oldest first.
+func (s *Service) Applications(mixID string) ([]Application, error) {
+	if _, err := s.store.GetRecipe(mixID); err != nil {
+		return nil, err
+	}
+	return s.store.ListApplications(mixID)
+}
+
+// Calculate scales a recipe to a tank of tankSize, in the recipe's volume
+// unit.
+func (s *Service) Calculate(mixID string, tankSize float64) (Batch, error) {
+	if tankSize <= 0 || math.IsInf(tankSize, 0) || math.IsNaN(tankSize) {
+		return Batch{}, fmt.Errorf("%w: tankSize must be > 0", ErrInvalidBatch)
+	}
+	r, err := s.store.GetRecipe(mixID)
+	if err != nil {
+		return Batch{}, err
+	}
+	b := Batch{MixID: r.ID, MixName: r.Name, Carrier: r.Carrier, TankSize: tankSize, VolumeUnit: r.VolumeUnit,
+		Components: make([]Quantity, len(r.Components))}
+	for i, c := range r.Components {
+		b.Components[i] = Quantity{Product: c.Product, Rate: c.Rate, Quantity: scale(c.Rate, tankSize, r.CarrierVolume)}
+	}
+	return b, nil
+}
+
+// Apply logs a technician spraying a volume of a mix. Each component is
+// matched to one of the technician's chemicals and recorded as a treatment
+// referencing the mix, with the component's share of the volume as the
+// quantity used; the chemical's stock (and lot, when it tracks lots) is
+// drawn down by the same amount.
+func (s *Service) Apply(technicianID, mixID string, req ApplyRequest) (Application, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.ID != "" {
+		existing, err := s.store.GetApplication(req.ID)
+		switch {
+		case err == nil && existing.TechnicianID == technicianID && existing.MixID == mixID:
+			return existing, nil
+		case err == nil:
+			return Application{}, fmt.Errorf("%w: application %q was already recorded for another mix or technician", ErrInvalidApplication, req.ID)
+		case !errors.Is(err, ErrNotFound):
+			return Application{}, err
+		}
+	}
+	r, err := s.store.GetRecipe(mixID)
+	if err != nil {
+		return Application{}, err
+	}
+	var problems []string
+	if strings.TrimSpace(req.JobID) == "" {
+		problems = append(problems, "jobId is required")
+	}
+	if req.VolumeApplied <= 0 {
+		problems = append(problems, "volumeApplied must be > 0")
+	}
+	if len(problems) > 0 {
+		return Application{}, fmt.Errorf("%w: %s", ErrInvalidApplication, strings.Join(problems, "; "))
+	}
+	warning, err := s.equip.Check(req.EquipmentID, req.ApplicationMethod, req.ApplicationDate)
+	switch {
+	case errors.Is(err, calibration.ErrCalibrationOverdue), errors.Is(err, calibration.ErrNotFound):
+		return Application{}, fmt.Errorf("%w: %v", ErrInvalidApplication, err)
+	case err != nil:
+		return Application{}, err
+	}
+
+	now := s.now().UTC()
+	a := Application{ID: req.ID, MixID: r.ID, MixName: r.Name, TechnicianID: technicianID, JobID: req.JobID,
+		VolumeApplied: req.VolumeApplied, VolumeUnit: r.VolumeUnit, ApplicationDate: req.ApplicationDate.UTC(),
+		EquipmentID: req.EquipmentID, RecordedAt: now}
+	if warning != "" {
+		a.Warnings = []string{warning}
+	}
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	if a.ApplicationDate.IsZero() {
+		a.ApplicationDate = now
+	}
+
+	chemicals, err := s.chemicals(technicianID)
+	if err != nil {
+		return Application{}, err
+	}
+	drawn := make([]models.ChemicalUpload, len(r.Components))
+	for i, c := range r.Components {
+		chemical, ok := chemicals[c.Product.Key()]
+		if !ok {
+			return Application{}, fmt.Errorf("%w: %s is not on technician %q's truck", ErrInvalidApplication, c.Product.Name, technicianID)
+		}
+		u := Usage{
+			TreatmentID: a.ID + "-" + strconv.Itoa(i+1),
+			ChemicalID:  chemical.ID,
+			Product:     c.Product,
+			Quantity:    scale(c.Rate, req.VolumeApplied, r.CarrierVolume),
+		}
+		if len(chemical.Lots) > 0 {
+			u.LotNumber = req.Lots[c.Product.Key()]
+			if !hasLot(chemical.Lots, u.LotNumber) {
+				return Application{}, fmt.Errorf("%w: %s tracks lots; lots[%q] must name one of them", ErrInvalidApplication, c.Product.Name, c.Product.Key())
+			}
+		}
+		a.Usage = append(a.Usage, u)
+		drawn[i] = draw(chemical, u, now)
+	}
+
+	// Treatments are keyed by ID, so a retry after a partial failure
+	// overwrites rather than duplicates them. The application is saved
+	// before stock is drawn down: a retry of a recorded application never
+	// draws twice, and a missed draw shows up as a stock count variance.
+	for i, u := range a.Usage {
+		c := r.Components[i]
+		t := models.ChemicalTreatmentUpload{
+			ID:                 u.TreatmentID,
+			JobID:              a.JobID,
+			ChemicalID:         u.ChemicalID,
+			LotNumber:          u.LotNumber,
+			MixID:              a.MixID,
+			EquipmentID:        a.EquipmentID,
+			TechnicianID:       technicianID,
+			ApplicatorName:     req.ApplicatorName,
+			ApplicationDate:    a.ApplicationDate,
+			ApplicationMethod:  req.ApplicationMethod,
+			TargetPests:        req.TargetPests,
+			QuantityUsed:       u.Quantity,
+			DosageRate:         c.Rate,
+			DilutionRatio:      fmt.Sprintf("%g %s per %g %s %s", c.Rate, c.Product.UnitOfMeasure, r.CarrierVolume, r.VolumeUnit, r.Carrier),
+			EnvironmentalNotes: req.EnvironmentalNotes,
+			WeatherConditions:  req.WeatherSummary,
+			Notes:              mixNote(r, req.Notes),
+			LastModified:       now,
+		}
+		if err := s.repos.Sync.SaveChemicalTreatment(t); err != nil {
+			return Application{}, err
+		}
+		s.events.Publish(connector.TreatmentRecorded(t))
+	}
+	if err := s.store.SaveApplication(a); err != nil {
+		return Application{}, err
+	}
+	for _, chemical := range drawn {
+		if err := s.repos.Sync.SaveChemicalUpload(chemical); err != nil {
+			s.logger.Error("failed to draw down tank mix component", slog.String("application", a.ID), slog.String("chemical", chemical.ID), slog.Any("error", err))
+			continue
+		}
+		s.events.Publish(connector.ChemicalUpdated(chemical))
+	}
+	s.logger.Info("tank mix applied", slog.String("application", a.ID), slog.String("mix", a.MixID), slog.String("technician", technicianID), slog.Float64("volume", a.VolumeApplied))
+	return a, nil
+}
+
+// chemicals returns a technician's chemical records keyed like recipe
+// components.
+func (s *Service) chemicals(technicianID string) (map[string]models.ChemicalUpload, error) {
+	all, err := s.repos.Sync.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]models.ChemicalUpload)
+	for _, c := range all {
+		if c.TechnicianID == technicianID {
+			out[(inventory.Product{Name: c.Name, EPARegistration: c.EPARegistration}).Key()] = c
+		}
+	}
+	return out, nil
+}
+
+// draw returns chemical with u's quantity taken off its stock and lot. Stock
+// does not go below zero; the next count reconciles any shortfall.
+func draw(chemical models.ChemicalUpload, u Usage, now time.Time) models.ChemicalUpload {
+	chemical.QuantityInStock = math.Max(chemical.QuantityInStock-u.Quantity, 0)
+	if u.LotNumber != "" {
+		lots := make([]models.ChemicalLot, len(chemical.Lots))
+		copy(lots, chemical.Lots)
+		for i := range lots {
+			if lots[i].Number == u.LotNumber {
+				lots[i].Quantity = math.Max(lots[i].Quantity-u.Quantity, 0)
+			}
+		}
+		chemical.Lots = lots
+	}
+	chemical.LastModified = now
+	return chemical
+}
+
+func hasLot(lots []models.ChemicalLot, number string) bool {
+	for _, lot := range lots {
+		if number != "" && lot.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+func mixNote(r Recipe, notes string) string {
+	note := "Tank mix: " + r.Name
+	if notes != "" {
+		note += ". " + notes
+	}
+	return note
+}
+
+// scale returns rate per carrierVolume scaled to volume, rounded to a
+// thousandth of the product's unit.
+func scale(rate, volume, carrierVolume float64) float64 {
+	return math.Round(rate*volume/carrierVolume*1000) / 1000
+}
@@ -0,0 +1,133 @@
+package tankmix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/inventory"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newService(t *testing.T) (*Service, *storememory.Store, Recipe) {
+	t.Helper()
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	svc := NewService(NewMemoryStore(), repos, nil, nil, nil)
+	now := time.Date(2026, 4, 2, 17, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	recipe, err := svc.CreateRecipe(Recipe{
+		Name:          "Perimeter + IGR",
+		CarrierVolume: 1,
+		VolumeUnit:    "gal",
+		Components: []Component{
+			{Product: inventory.Product{Name: "Talstar P", EPARegistration: "279-3206", UnitOfMeasure: "fl oz"}, Rate: 1},
+			{Product: inventory.Product{Name: "Gentrol IGR", UnitOfMeasure: "fl oz"}, Rate: 0.25},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc, store, recipe
+}
+
+func TestRecipeValidation(t *testing.T) {
+	svc, _, recipe := newService(t)
+	if recipe.Carrier != "water" || recipe.CreatedAt.IsZero() {
+		t.Fatalf("unexpected recipe %+v", recipe)
+	}
+	for name, r := range map[string]Recipe{
+		"no components":  {Name: "Empty", CarrierVolume: 1, VolumeUnit: "gal"},
+		"no volume":      {Name: "Dry", VolumeUnit: "gal", Components: recipe.Components},
+		"duplicate":      {Name: "Twice", CarrierVolume: 1, VolumeUnit: "gal", Components: append(recipe.Components, recipe.Components[0])},
+		"zero rate":      {Name: "Weak", CarrierVolume: 1, VolumeUnit: "gal", Components: []Component{{Product: inventory.Product{Name: "X", UnitOfMeasure: "oz"}}}},
+		"unit-less rate": {Name: "Vague", CarrierVolume: 1, VolumeUnit: "gal", Components: []Component{{Product: inventory.Product{Name: "X"}, Rate: 1}}},
+	} {
+		if _, err := svc.CreateRecipe(r); !errors.Is(err, ErrInvalidRecipe) {
+			t.Errorf("%s: expected invalid recipe, got %v", name, err)
+		}
+	}
+}
+
+func TestCalculateBatch(t *testing.T) {
+	svc, _, recipe := newService(t)
+	batch, err := svc.Calculate(recipe.ID, 2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Components) != 2 || batch.Components[0].Quantity != 2.5 || batch.Components[1].Quantity != 0.625 || batch.VolumeUnit != "gal" {
+		t.Fatalf("unexpected batch %+v", batch)
+	}
+	if _, err := svc.Calculate(recipe.ID, 0); !errors.Is(err, ErrInvalidBatch) {
+		t.Fatalf("expected invalid batch, got %v", err)
+	}
+	if _, err := svc.Calculate("missing", 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestApplyRecordsComponentTreatments(t *testing.T) {
+	svc, store, recipe := newService(t)
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "talstar", TechnicianID: "tech-a", Name: "Talstar P", EPARegistration: "279-3206", QuantityInStock: 64,
+		Lots: []models.ChemicalLot{{Number: "L-1", Quantity: 32}, {Number: "L-2", Quantity: 32}}})
+	req := ApplyRequest{ID: "app-1", JobID: "job-1", VolumeApplied: 4}
+	if _, err := svc.Apply("tech-a", recipe.ID, req); !errors.Is(err, ErrInvalidApplication) {
+		t.Fatalf("expected a missing component rejected, got %v", err)
+	}
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "gentrol", TechnicianID: "tech-a", Name: "gentrol igr", QuantityInStock: 0.5})
+	if _, err := svc.Apply("tech-a", recipe.ID, req); !errors.Is(err, ErrInvalidApplication) {
+		t.Fatalf("expected a missing lot rejected, got %v", err)
+	}
+
+	req.Lots = map[string]string{"279-3206": "L-2"}
+	a, err := svc.Apply("tech-a", recipe.ID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Usage) != 2 || a.Usage[0].Quantity != 4 || a.Usage[0].LotNumber != "L-2" || a.Usage[1].Quantity != 1 {
+		t.Fatalf("unexpected application %+v", a)
+	}
+
+	treatments, _ := store.ListPendingTreatments(0)
+	if len(treatments) != 2 {
+		t.Fatalf("expected a treatment per component, got %+v", treatments)
+	}
+	for _, tr := range treatments {
+		if tr.MixID != recipe.ID || tr.JobID != "job-1" || tr.TechnicianID != "tech-a" {
+			t.Fatalf("unexpected treatment %+v", tr)
+		}
+	}
+
+	chemicals, _ := store.ListChemicalUpdatesSince(time.Time{})
+	stock := map[string]models.ChemicalUpload{}
+	for _, c := range chemicals {
+		stock[c.ID] = c
+	}
+	if c := stock["talstar"]; c.QuantityInStock != 60 || c.Lots[0].Quantity != 32 || c.Lots[1].Quantity != 28 {
+		t.Fatalf("unexpected talstar stock %+v", c)
+	}
+	if c := stock["gentrol"]; c.QuantityInStock != 0 {
+		t.Fatalf("stock must not go below zero, got %+v", c)
+	}
+
+	// A retried upload returns the recorded application without drawing twice.
+	again, err := svc.Apply("tech-a", recipe.ID, req)
+	if err != nil || again.RecordedAt != a.RecordedAt {
+		t.Fatalf("expected the recorded application, got %+v (%v)", again, err)
+	}
+	chemicals, _ = store.ListChemicalUpdatesSince(time.Time{})
+	for _, c := range chemicals {
+		if c.ID == "talstar" && c.QuantityInStock != 60 {
+			t.Fatalf("retry drew stock down again: %+v", c)
+		}
+	}
+	if _, err := svc.Apply("tech-b", recipe.ID, req); !errors.Is(err, ErrInvalidApplication) {
+		t.Fatalf("expected another technician's application ID rejected, got %v", err)
+	}
+	if list, err := svc.Applications(recipe.ID); err != nil || len(list) != 1 {
+		t.Fatalf("unexpected applications %+v (%v)", list, err)
+	}
+}
@@ -0,0 +1,79 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes tenant management in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a tenant handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListTenants)
+	r.Get("/{tenantId}", h.GetTenant)
+	r.Put("/{tenantId}", h.SaveTenant)
+}
+
+// ListTenants returns every tenant.
+func (h *Handler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.Tenants()
+	if err != nil {
+		h.fail(w, r, "failed to list tenants", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"tenants": tenants})
+}
+
+// GetTenant returns a tenant.
+func (h *Handler) GetTenant(w http.ResponseWriter, r *http.Request) {
+	t, err := h.service.Tenant(chi.URLParam(r, "tenantId"))
+	if err != nil {
+		h.fail(w, r, "failed to load tenant", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+// SaveTenant creates or replaces a tenant, with its branding and flag
+// overrides.
+func (h *Handler) SaveTenant(w http.ResponseWriter, r *http.Request) {
+	var payload Tenant
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	t, err := h.service.Save(chi.URLParam(r, "tenantId"), payload)
+	if err != nil {
+		h.fail(w, r, "failed to save tenant", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidTenant):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
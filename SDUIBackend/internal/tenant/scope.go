@@ -0,0 +1,476 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Repository scopes every repository to the tenant ctx acts for: reads see
+// only its records, and writes are stamped with it and refused over
+// another tenant's records. Repositories take no context, so callers with
+// one bind them per call; without a scope on ctx repos is returned as is.
+// Nil repositories are left nil so Repository.Validate still reports them.
+func Repository(ctx context.Context, repos repository.Repository) repository.Repository {
+	s, ok := ScopeFrom(ctx)
+	if !ok {
+		return repos
+	}
+	return s.Repository(repos)
+}
+
+// Repository scopes repos to s.
+func (s Scope) Repository(repos repository.Repository) repository.Repository {
+	out := repos
+	if repos.Technicians != nil {
+		out.Technicians = technicians{base: repos.Technicians, scope: s}
+	}
+	if repos.Routes != nil {
+		out.Routes = routes{base: repos.Routes, technicians: repos.Technicians, scope: s}
+	}
+	if repos.Screens != nil {
+		out.Screens = screens{base: repos.Screens, scope: s}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{base: repos.Sync, routes: repos.Routes, technicians: repos.Technicians, scope: s}
+	}
+	if repos.Devices != nil {
+		out.Devices = devices{base: repos.Devices, technicians: repos.Technicians, scope: s}
+	}
+	return out
+}
+
+// checkTechnician refuses records for a technician of another tenant.
+// Unknown technicians are allowed: uploads can arrive before the profile.
+func (s Scope) checkTechnician(techs repository.TechnicianRepository, id string) error {
+	if techs == nil || id == "" {
+		return nil
+	}
+	t, err := techs.GetByID(id)
+	if err != nil || s.owns(t.TenantID) {
+		return nil
+	}
+	return ErrForeignTenant
+}
+
+// filter returns the items of list the scope owns.
+func filter[T any](s Scope, list []T, tenantOf func(T) string) []T {
+	out := list[:0:0]
+	for _, item := range list {
+		if s.owns(tenantOf(item)) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// filterPage lists a page of the scope's records from a base query that
+// returns every tenant's, reading on past other tenants' records until the
+// page is full or the query runs out. position gives a record's place in
+// the query order.
+func filterPage[T any](s Scope, page repository.Page, list func(repository.Page) ([]T, error), tenantOf func(T) string, position func(T) (time.Time, string)) ([]T, error) {
+	var out []T
+	want := page.Limit
+	for {
+		batch, err := list(page)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, filter(s, batch, tenantOf)...)
+		if want <= 0 || len(batch) < page.Limit || len(out) >= want {
+			return out, nil
+		}
+		page = page.Next(position(batch[len(batch)-1]))
+		page.Limit = want - len(out)
+	}
+}
+
+type technicians struct {
+	base  repository.TechnicianRepository
+	scope Scope
+}
+
+// GetByID reports other tenants' technicians as missing, as the route and
+// template getters do theirs, so callers cannot tell them apart.
+func (t technicians) GetByID(id string) (models.Technician, error) {
+	tech, err := t.base.GetByID(id)
+	if err != nil {
+		return tech, err
+	}
+	if !t.scope.owns(tech.TenantID) {
+		return models.Technician{}, repository.ErrTechnicianNotFound
+	}
+	return tech, nil
+}
+
+func (t technicians) SaveTechnician(tech models.Technician) error {
+	if err := t.scope.checkTechnician(t.base, tech.ID); err != nil {
+		return err
+	}
+	tech.TenantID = t.scope.Tenant.ID
+	return t.base.SaveTechnician(tech)
+}
+
+func (t technicians) ListTechnicians() ([]models.Technician, error) {
+	list, err := t.base.ListTechnicians()
+	return filter(t.scope, list, func(t models.Technician) string { return t.TenantID }), err
+}
+
+type routes struct {
+	base        repository.RouteRepository
+	technicians repository.TechnicianRepository
+	scope       Scope
+}
+
+func (r routes) GetRoute(technicianID string, serviceDate time.Time) (models.Route, error) {
+	route, err := r.base.GetRoute(technicianID, serviceDate)
+	if err != nil {
+		return route, err
+	}
+	if !r.scope.owns(route.TenantID) {
+		return models.Route{}, repository.ErrRouteNotFound
+	}
+	return route, nil
+}
+
+func (r routes) SaveRoute(route models.Route) error {
+	route, err := r.claim(route)
+	if err != nil {
+		return err
+	}
+	return r.base.SaveRoute(route)
+}
+
+// claim stamps route with the tenant, refusing one that would overwrite
+// another tenant's route or belongs to its technician.
+func (r routes) claim(route models.Route) (models.Route, error) {
+	if existing, err := r.base.GetRoute(route.TechnicianID, route.ServiceDate); err == nil && !r.scope.owns(existing.TenantID) {
+		return models.Route{}, ErrForeignTenant
+	}
+	if err := r.scope.checkTechnician(r.technicians, route.TechnicianID); err != nil {
+		return models.Route{}, err
+	}
+	route.TenantID = r.scope.Tenant.ID
+	return route, nil
+}
+
+// DeleteRoute leaves other tenants' routes alone; as with a missing route,
+// the delete still succeeds.
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) error {
+	if existing, err := r.base.GetRoute(technicianID, serviceDate); err == nil && !r.scope.owns(existing.TenantID) {
+		return nil
+	}
+	return r.base.DeleteRoute(technicianID, serviceDate)
+}
+
+// screens shows a tenant its own templates and those every tenant shares.
+// Shared templates are managed through the admin API, not by tenants.
+type screens struct {
+	base  repository.ScreenRepository
+	scope Scope
+}
+
+func (s screens) visible(tenantID string) bool {
+	return tenantID == "" || tenantID == s.scope.Tenant.ID
+}
+
+func (s screens) GetTemplate(id string, version int) (models.ScreenTemplate, error) {
+	tpl, err := s.base.GetTemplate(id, version)
+	if err != nil {
+		return tpl, err
+	}
+	if !s.visible(tpl.TenantID) {
+		return models.ScreenTemplate{}, repository.ErrTemplateNotFound
+	}
+	return tpl, nil
+}
+
+func (s screens) SaveTemplate(tpl models.ScreenTemplate) error {
+	if existing, err := s.base.GetTemplate(tpl.ID, tpl.Version); err == nil && existing.TenantID != s.scope.Tenant.ID {
+		return ErrForeignTenant
+	}
+	tpl.TenantID = s.scope.Tenant.ID
+	return s.base.SaveTemplate(tpl)
+}
+
+func (s screens) ListTemplates() ([]models.ScreenTemplate, error) {
+	list, err := s.base.ListTemplates()
+	out := list[:0:0]
+	for _, tpl := range list {
+		if s.visible(tpl.TenantID) {
+			out = append(out, tpl)
+		}
+	}
+	return out, err
+}
+
+func (s screens) DeleteTemplate(id string, version int) error {
+	if existing, err := s.base.GetTemplate(id, version); err == nil && existing.TenantID != s.scope.Tenant.ID {
+		return ErrForeignTenant
+	}
+	return s.base.DeleteTemplate(id, version)
+}
+
+type syncRepo struct {
+	base repository.SyncRepository
+	// routes checks the routes of a batch as SaveRoute does.
+	routes      repository.RouteRepository
+	technicians repository.TechnicianRepository
+	scope       Scope
+}
+
+// Uploads are checked through their technician only: their IDs are
+// generated on devices, and looking each one up would cost a scan of the
+// tenant's history on every upload.
+
+func (r syncRepo) SaveJobUpload(upload models.JobUpload) error {
+	if err := r.scope.checkTechnician(r.technicians, upload.TechnicianID); err != nil {
+		return err
+	}
+	upload.TenantID = r.scope.Tenant.ID
+	return r.base.SaveJobUpload(upload)
+}
+
+func (r syncRepo) GetJobUpload(id string) (models.JobUpload, error) {
+	job, err := r.base.GetJobUpload(id)
+	if err != nil {
+		return job, err
+	}
+	if !r.scope.owns(job.TenantID) {
+		return models.JobUpload{}, repository.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (r syncRepo) SaveChemicalUpload(upload models.ChemicalUpload) error {
+	if err := r.scope.checkTechnician(r.technicians, upload.TechnicianID); err != nil {
+		return err
+	}
+	upload.TenantID = r.scope.Tenant.ID
+	return r.base.SaveChemicalUpload(upload)
+}
+
+func (r syncRepo) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) error {
+	if err := r.scope.checkTechnician(r.technicians, upload.TechnicianID); err != nil {
+		return err
+	}
+	upload.TenantID = r.scope.Tenant.ID
+	return r.base.SaveChemicalTreatment(upload)
+}
+
+// The pending queues are filtered after the store applies limit, so a
+// tenant may get fewer than limit records while more are pending.
+
+func (r syncRepo) ListPendingJobs(limit int) ([]models.JobUpload, error) {
+	list, err := r.base.ListPendingJobs(limit)
+	return filter(r.scope, list, func(j models.JobUpload) string { return j.TenantID }), err
+}
+
+func (r syncRepo) ListPendingChemicals(limit int) ([]models.ChemicalUpload, error) {
+	list, err := r.base.ListPendingChemicals(limit)
+	return filter(r.scope, list, func(c models.ChemicalUpload) string { return c.TenantID }), err
+}
+
+func (r syncRepo) ListAllJobs() ([]models.JobUpload, error) {
+	list, err := r.base.ListAllJobs()
+	return filter(r.scope, list, func(j models.JobUpload) string { return j.TenantID }), err
+}
+
+func (r syncRepo) ListAllChemicals() ([]models.ChemicalUpload, error) {
+	list, err := r.base.ListAllChemicals()
+	return filter(r.scope, list, func(c models.ChemicalUpload) string { return c.TenantID }), err
+}
+
+func (r syncRepo) ListPendingTreatments(limit int) ([]models.ChemicalTreatmentUpload, error) {
+	list, err := r.base.ListPendingTreatments(limit)
+	return filter(r.scope, list, func(t models.ChemicalTreatmentUpload) string { return t.TenantID }), err
+}
+
+func (r syncRepo) ListJobUpdatesSince(since time.Time) ([]models.JobUpload, error) {
+	list, err := r.base.ListJobUpdatesSince(since)
+	return filter(r.scope, list, func(j models.JobUpload) string { return j.TenantID }), err
+}
+
+func (r syncRepo) ListRouteUpdatesSince(since time.Time) ([]models.Route, error) {
+	list, err := r.base.ListRouteUpdatesSince(since)
+	return filter(r.scope, list, func(rt models.Route) string { return rt.TenantID }), err
+}
+
+func (r syncRepo) ListChemicalUpdatesSince(since time.Time) ([]models.ChemicalUpload, error) {
+	list, err := r.base.ListChemicalUpdatesSince(since)
+	return filter(r.scope, list, func(c models.ChemicalUpload) string { return c.TenantID }), err
+}
+
+func (r syncRepo) ListTreatmentUpdatesSince(since time.Time) ([]models.ChemicalTreatmentUpload, error) {
+	list, err := r.base.ListTreatmentUpdatesSince(since)
+	return filter(r.scope, list, func(t models.ChemicalTreatmentUpload) string { return t.TenantID }), err
+}
+
+func (r syncRepo) ListJobUpdates(page repository.Page) ([]models.JobUpload, error) {
+	return filterPage(r.scope, page, r.base.ListJobUpdates, func(j models.JobUpload) string { return j.TenantID },
+		func(j models.JobUpload) (time.Time, string) { return j.ReceivedAt, j.ID })
+}
+
+func (r syncRepo) ListRouteUpdates(page repository.Page) ([]models.Route, error) {
+	return filterPage(r.scope, page, r.base.ListRouteUpdates, func(rt models.Route) string { return rt.TenantID },
+		func(rt models.Route) (time.Time, string) { return rt.LastModified, rt.ServerID() })
+}
+
+func (r syncRepo) ListChemicalUpdates(page repository.Page) ([]models.ChemicalUpload, error) {
+	return filterPage(r.scope, page, r.base.ListChemicalUpdates, func(c models.ChemicalUpload) string { return c.TenantID },
+		func(c models.ChemicalUpload) (time.Time, string) { return c.LastModified, c.ID })
+}
+
+func (r syncRepo) ListTreatmentUpdates(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	return filterPage(r.scope, page, r.base.ListTreatmentUpdates, func(t models.ChemicalTreatmentUpload) string { return t.TenantID },
+		func(t models.ChemicalTreatmentUpload) (time.Time, string) { return t.LastModified, t.ID })
+}
+
+func (r syncRepo) ListUnprocessedJobs(page repository.Page) ([]models.JobUpload, error) {
+	return filterPage(r.scope, page, r.base.ListUnprocessedJobs, func(j models.JobUpload) string { return j.TenantID },
+		func(j models.JobUpload) (time.Time, string) { return j.ReceivedAt, j.ID })
+}
+
+func (r syncRepo) ListUnprocessedChemicals(page repository.Page) ([]models.ChemicalUpload, error) {
+	return filterPage(r.scope, page, r.base.ListUnprocessedChemicals, func(c models.ChemicalUpload) string { return c.TenantID },
+		func(c models.ChemicalUpload) (time.Time, string) { return c.LastModified, c.ID })
+}
+
+func (r syncRepo) ListUnprocessedTreatments(page repository.Page) ([]models.ChemicalTreatmentUpload, error) {
+	return filterPage(r.scope, page, r.base.ListUnprocessedTreatments, func(t models.ChemicalTreatmentUpload) string { return t.TenantID },
+		func(t models.ChemicalTreatmentUpload) (time.Time, string) { return t.LastModified, t.ID })
+}
+
+func (r syncRepo) ListUnprocessedRoutes(page repository.Page) ([]models.Route, error) {
+	return filterPage(r.scope, page, r.base.ListUnprocessedRoutes, func(rt models.Route) string { return rt.TenantID },
+		func(rt models.Route) (time.Time, string) { return rt.LastModified, rt.ServerID() })
+}
+
+// MarkProcessed is called with records the scoped queries returned, so it
+// is passed through as saves are checked: by the record, not its ID.
+func (r syncRepo) MarkProcessed(kind, id string, savedAt time.Time) error {
+	return r.base.MarkProcessed(kind, id, savedAt)
+}
+
+// DeleteJob leaves other tenants' jobs alone; as with a missing job, the
+// delete still succeeds. Deletes are rare enough to afford the scan.
+func (r syncRepo) DeleteJob(id string) error {
+	jobs, err := r.base.ListJobUpdatesSince(time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.ID == id && !r.scope.owns(j.TenantID) {
+			return nil
+		}
+	}
+	return r.base.DeleteJob(id)
+}
+
+// SaveBatch checks and stamps every record as the single writes do, and
+// leaves other tenants' jobs out of the deletes, so a batch is refused as a
+// whole rather than stored in part.
+func (r syncRepo) SaveBatch(batch repository.Batch) error {
+	out := repository.Batch{}
+	rs := routes{base: r.routes, technicians: r.technicians, scope: r.scope}
+	for _, route := range batch.Routes {
+		route, err := rs.claim(route)
+		if err != nil {
+			return err
+		}
+		out.Routes = append(out.Routes, route)
+	}
+	for _, j := range batch.Jobs {
+		if err := r.scope.checkTechnician(r.technicians, j.TechnicianID); err != nil {
+			return err
+		}
+		j.TenantID = r.scope.Tenant.ID
+		out.Jobs = append(out.Jobs, j)
+	}
+	for _, t := range batch.Treatments {
+		if err := r.scope.checkTechnician(r.technicians, t.TechnicianID); err != nil {
+			return err
+		}
+		t.TenantID = r.scope.Tenant.ID
+		out.Treatments = append(out.Treatments, t)
+	}
+	if len(batch.DeletedJobs) > 0 {
+		jobs, err := r.base.ListJobUpdatesSince(time.Time{})
+		if err != nil {
+			return err
+		}
+		foreign := make(map[string]bool)
+		for _, j := range jobs {
+			if !r.scope.owns(j.TenantID) {
+				foreign[j.ID] = true
+			}
+		}
+		for _, id := range batch.DeletedJobs {
+			if !foreign[id] {
+				out.DeletedJobs = append(out.DeletedJobs, id)
+			}
+		}
+	}
+	return r.base.SaveBatch(out)
+}
+
+// DeleteChemical leaves other tenants' chemicals alone, like DeleteJob.
+func (r syncRepo) DeleteChemical(id string) error {
+	chemicals, err := r.base.ListChemicalUpdatesSince(time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, c := range chemicals {
+		if c.ID == id && !r.scope.owns(c.TenantID) {
+			return nil
+		}
+	}
+	return r.base.DeleteChemical(id)
+}
+
+func (r syncRepo) ListDeletionsSince(since time.Time) ([]models.Tombstone, error) {
+	list, err := r.base.ListDeletionsSince(since)
+	return filter(r.scope, list, func(t models.Tombstone) string { return t.TenantID }), err
+}
+
+func (r syncRepo) ListDeletions(page repository.Page) ([]models.Tombstone, error) {
+	return filterPage(r.scope, page, r.base.ListDeletions, func(t models.Tombstone) string { return t.TenantID },
+		func(t models.Tombstone) (time.Time, string) { return t.DeletedAt, t.ID })
+}
+
+// PruneTombstones is retention housekeeping across every tenant.
+func (r syncRepo) PruneTombstones(cutoff time.Time) (int, error) {
+	return r.base.PruneTombstones(cutoff)
+}
+
+type devices struct {
+	base        repository.DeviceRepository
+	technicians repository.TechnicianRepository
+	scope       Scope
+}
+
+func (d devices) SaveDeviceToken(token models.DeviceToken) error {
+	if err := d.scope.checkTechnician(d.technicians, token.TechnicianID); err != nil {
+		return err
+	}
+	token.TenantID = d.scope.Tenant.ID
+	return d.base.SaveDeviceToken(token)
+}
+
+func (d devices) ListDeviceTokens(technicianID string) ([]models.DeviceToken, error) {
+	list, err := d.base.ListDeviceTokens(technicianID)
+	return filter(d.scope, list, func(t models.DeviceToken) string { return t.TenantID }), err
+}
+
+// DeleteDeviceToken passes through: the token itself is the credential,
+// and whoever holds it may unregister the device.
+func (d devices) DeleteDeviceToken(token string) error {
+	return d.base.DeleteDeviceToken(token)
+}
+
+// PruneDeviceTokens is retention housekeeping across every tenant.
+func (d devices) PruneDeviceTokens(cutoff time.Time) (int, error) {
+	return d.base.PruneDeviceTokens(cutoff)
+}
@@ -0,0 +1,277 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/access"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// Service manages tenants and resolves the tenant each request acts for.
+// A nil *Service means tenancy is disabled: requests pass through and see
+// every record.
+type Service struct {
+	cfg    config.TenancyConfig
+	store  Store
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService wires a tenant service, registering the default tenant when
+// one is configured and missing. It returns nil when tenancy is disabled.
+func NewService(cfg config.TenancyConfig, store Store, logger *slog.Logger) (*Service, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Service{cfg: cfg, store: store, logger: logger, now: time.Now}
+	if cfg.Default == "" {
+		return s, nil
+	}
+	if _, err := store.GetTenant(cfg.Default); errors.Is(err, ErrNotFound) {
+		if _, err := s.Save(cfg.Default, Tenant{Name: cfg.Default}); err != nil {
+			return nil, fmt.Errorf("register default tenant: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("load default tenant: %w", err)
+	}
+	return s, nil
+}
+
+// Save creates or replaces a tenant.
+func (s *Service) Save(id string, t Tenant) (Tenant, error) {
+	t.ID = strings.TrimSpace(id)
+	if err := t.Validate(); err != nil {
+		return Tenant{}, err
+	}
+	now := s.now().UTC()
+	t.CreatedAt, t.UpdatedAt = now, now
+	if existing, err := s.store.GetTenant(t.ID); err == nil {
+		t.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, ErrNotFound) {
+		return Tenant{}, err
+	}
+	if err := s.store.SaveTenant(t); err != nil {
+		return Tenant{}, err
+	}
+	s.logger.Info("tenant saved", slog.String("tenant", t.ID))
+	return t, nil
+}
+
+// Tenant returns a tenant by ID.
+func (s *Service) Tenant(id string) (Tenant, error) {
+	return s.store.GetTenant(id)
+}
+
+// Tenants returns every tenant, ordered by ID.
+func (s *Service) Tenants() ([]Tenant, error) {
+	return s.store.ListTenants()
+}
+
+// Scope returns the scope of requests acting for tenant id, for work done
+// on a tenant's behalf outside a request. ok is false when tenancy is
+// disabled and nothing is scoped.
+func (s *Service) Scope(id string) (scope Scope, ok bool, err error) {
+	if s == nil {
+		return Scope{}, false, nil
+	}
+	t, err := s.store.GetTenant(id)
+	if err != nil {
+		return Scope{}, false, err
+	}
+	return Scope{Tenant: t, Untagged: t.ID == s.cfg.Default}, true, nil
+}
+
+// Credentials resolves the tenant of requests authenticated by something
+// other than a JWT; *apitoken.Service and *impersonate.Service implement it. ok reports whether the
+// request carries such a credential, and tenant is empty when the
+// credential is not bound to one.
+type Credentials interface {
+	CredentialTenant(r *http.Request) (tenant string, ok bool)
+}
+
+// Middleware resolves the tenant of public API requests and scopes the
+// request to it. A token's tenant claim binds the request to its tenant, as
+// does a tenant credentials find, such as the one an API token is bound to
+// or that of the technician an impersonation session views. Only staff
+// tokens naming no tenant may pick one with the tenant header; technicians,
+// unbound API tokens and anonymous requests act for the default tenant. A
+// header naming another tenant than the one the request is bound to is
+// refused rather than ignored.
+func (s *Service) Middleware(credentials ...Credentials) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if s == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, claimed, mayChoose := s.bound(r, credentials)
+			header := strings.TrimSpace(r.Header.Get(s.cfg.Header))
+			switch {
+			case header == "" || header == id:
+			case mayChoose:
+				id = header
+			case claimed:
+				respond.Error(w, http.StatusForbidden, "tenant mismatch", "the "+s.cfg.Header+" header names a different tenant than the token")
+				return
+			default:
+				respond.Error(w, http.StatusForbidden, "tenant mismatch", "only staff tokens that name no tenant may choose one with the "+s.cfg.Header+" header")
+				return
+			}
+			if id == "" {
+				respond.Error(w, http.StatusBadRequest, "tenant required", "use a token bound to a tenant, or a staff token and the "+s.cfg.Header+" header")
+				return
+			}
+
+			scope, _, err := s.Scope(id)
+			if err != nil {
+				if !errors.Is(err, ErrNotFound) {
+					middleware.LoggerFrom(r.Context()).Error("tenant lookup failed", slog.Any("error", err))
+					respond.Error(w, http.StatusInternalServerError, "tenant lookup failed", "temporary error, please retry", respond.WithCause(err))
+					return
+				}
+				respond.Error(w, http.StatusForbidden, "unknown tenant", "tenant "+id+" is not registered")
+				return
+			}
+
+			ctx := ContextWithScope(r.Context(), scope)
+			ctx = middleware.ContextWithLogger(ctx, middleware.LoggerFrom(ctx).With(slog.String("tenant", scope.Tenant.ID)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bound returns the tenant r is bound to by its credentials, whether a
+// token claim named it, and whether the caller may choose another with the
+// tenant header instead.
+func (s *Service) bound(r *http.Request, credentials []Credentials) (id string, claimed, mayChoose bool) {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		if id.Tenant != "" {
+			return id.Tenant, true, false
+		}
+		return s.cfg.Default, false, id.Staff()
+	}
+	for _, c := range credentials {
+		if t, ok := c.CredentialTenant(r); ok {
+			if t != "" {
+				return t, true, false
+			}
+			break
+		}
+	}
+	return s.cfg.Default, false, false
+}
+
+// RequirePlatform refuses tokens that belong to a tenant. It guards the
+// admin APIs that manage what every tenant shares, such as screens, flags,
+// credentials and the tenants themselves, so only the platform's own staff
+// may use them.
+func (s *Service) RequirePlatform(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := auth.FromContext(r.Context()); ok && id.Tenant != "" {
+			respond.Error(w, http.StatusForbidden, "platform staff only", "this endpoint manages what every tenant shares, so tenant tokens cannot use it")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AccessPolicies returns the access policies of the tenants a request is
+// held to: the default tenant's, and that of the tenant its verified token
+// belongs to. Run it after authentication; the tenant header is ignored, as
+// naming a tenant must not choose which policy applies. Unknown tenants
+// are left for Middleware to refuse.
+func (s *Service) AccessPolicies(r *http.Request) ([]access.Policy, error) {
+	if s == nil {
+		return nil, nil
+	}
+	ids := []string{s.cfg.Default}
+	if id, ok := auth.FromContext(r.Context()); ok && id.Tenant != "" && id.Tenant != s.cfg.Default {
+		ids = append(ids, id.Tenant)
+	}
+	var policies []access.Policy
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		t, err := s.store.GetTenant(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, t.Access)
+	}
+	return policies, nil
+}
+
+// Theme returns the branding of the tenant ctx acts for, or nil.
+func (s *Service) Theme(ctx context.Context) *models.ScreenTheme {
+	t, ok := FromContext(ctx)
+	if s == nil || !ok {
+		return nil
+	}
+	return t.theme()
+}
+
+// FlagEvaluator evaluates feature flags; *flags.Client implements it, as
+// does the evaluator Flags returns.
+type FlagEvaluator interface {
+	Flag(ctx context.Context, key string, evalCtx flags.FlattenedContext) (string, bool)
+	Bool(ctx context.Context, key string, fallback bool, evalCtx flags.FlattenedContext) bool
+}
+
+// Flags returns next with the flag overrides of the tenant each call's ctx
+// acts for applied first. next may be nil, leaving only the overrides.
+func (s *Service) Flags(next FlagEvaluator) FlagEvaluator {
+	if s == nil {
+		return next
+	}
+	return tenantFlags{next: next}
+}
+
+type tenantFlags struct {
+	next FlagEvaluator
+}
+
+func (f tenantFlags) Flag(ctx context.Context, key string, evalCtx flags.FlattenedContext) (string, bool) {
+	if t, ok := FromContext(ctx); ok {
+		if v, ok := t.Flags[key]; ok {
+			return v, true
+		}
+	}
+	if f.next == nil {
+		return "", false
+	}
+	return f.next.Flag(ctx, key, evalCtx)
+}
+
+func (f tenantFlags) Bool(ctx context.Context, key string, fallback bool, evalCtx flags.FlattenedContext) bool {
+	if t, ok := FromContext(ctx); ok {
+		if b, err := strconv.ParseBool(t.Flags[key]); err == nil {
+			return b
+		}
+	}
+	if f.next == nil {
+		return fallback
+	}
+	return f.next.Bool(ctx, key, fallback, evalCtx)
+}
@@ -0,0 +1,177 @@
+// Package tenant partitions data between the pest control companies that
+// share one backend. A request's tenant is resolved once, from its token or
+// a header, and the repositories bound to the request see only that
+// tenant's records and stamp it on everything they write. Tenants also
+// override feature flags, brand the screens their technicians see and
+// restrict where admin and sign-in requests may come from.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/access"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when a tenant does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidTenant wraps tenant validation failures.
+	ErrInvalidTenant = errors.New("invalid tenant")
+	// ErrForeignTenant is returned for writes over another tenant's records.
+	ErrForeignTenant = errors.New("record belongs to another tenant")
+)
+
+// Tenant is a company using the backend, with the configuration it
+// overrides.
+type Tenant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Branding colors are hex, such as #1B5E20. Screens carry them as their
+	// theme, and component colors naming brand.primary, brand.secondary or
+	// brand.accent take the tenant's color.
+	Branding models.ScreenTheme `json:"branding"`
+	// Flags override feature flags for the tenant's requests by key, with
+	// values as the flag service returns them, such as "true" or a variant.
+	Flags map[string]string `json:"flags,omitempty"`
+	// Access restricts the addresses and countries admin and sign-in
+	// requests acting for the tenant may come from.
+	Access    access.Policy `json:"access"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9A-Fa-f]{6}|[0-9A-Fa-f]{8})$`)
+
+// Validate checks a tenant.
+func (t Tenant) Validate() error {
+	var problems []string
+	if strings.TrimSpace(t.ID) == "" {
+		problems = append(problems, "id is required")
+	} else if strings.ContainsAny(t.ID, "/ ") {
+		problems = append(problems, "id must not contain slashes or spaces")
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	for field, color := range map[string]string{
+		"primaryColor":   t.Branding.PrimaryColor,
+		"secondaryColor": t.Branding.SecondaryColor,
+		"accentColor":    t.Branding.AccentColor,
+	} {
+		if color != "" && !hexColor.MatchString(color) {
+			problems = append(problems, fmt.Sprintf("branding.%s must be a hex color such as #1B5E20", field))
+		}
+	}
+	if t.Branding.LogoURL != "" {
+		if u, err := url.Parse(t.Branding.LogoURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			problems = append(problems, "branding.logoUrl must be an http or https URL")
+		}
+	}
+	for key := range t.Flags {
+		if strings.TrimSpace(key) == "" {
+			problems = append(problems, "flag keys must not be empty")
+			break
+		}
+	}
+	for _, p := range t.Access.Problems() {
+		problems = append(problems, "access."+p)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidTenant, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// theme returns the tenant's branding, or nil when it has none.
+func (t Tenant) theme() *models.ScreenTheme {
+	if t.Branding == (models.ScreenTheme{}) {
+		return nil
+	}
+	theme := t.Branding
+	return &theme
+}
+
+// Scope is the tenant a request acts for.
+type Scope struct {
+	Tenant Tenant
+	// Untagged is set for the default tenant, which also owns records
+	// written before tenancy was enabled, with no tenant on them.
+	Untagged bool
+}
+
+// owns reports whether a record tagged with tenantID belongs to the scope.
+func (s Scope) owns(tenantID string) bool {
+	return tenantID == s.Tenant.ID || (tenantID == "" && s.Untagged)
+}
+
+// scopeKey is the context key for the request's scope.
+type scopeKey struct{}
+
+// ContextWithScope returns ctx acting for scope.
+func ContextWithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFrom returns the scope ctx acts for, if any.
+func ScopeFrom(ctx context.Context) (Scope, bool) {
+	s, ok := ctx.Value(scopeKey{}).(Scope)
+	return s, ok
+}
+
+// FromContext returns the tenant ctx acts for, if any.
+func FromContext(ctx context.Context) (Tenant, bool) {
+	s, ok := ScopeFrom(ctx)
+	return s.Tenant, ok
+}
+
+// Store persists tenants.
+type Store interface {
+	SaveTenant(t Tenant) error
+	GetTenant(id string) (Tenant, error)
+	ListTenants() ([]Tenant, error)
+}
+
+// DocumentStore keeps tenants in the shared document store, so every
+// instance resolves the same tenants and they survive restarts.
+type DocumentStore struct {
+	tenants docstore.Collection[Tenant]
+}
+
+// NewDocumentStore keeps tenants in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{tenants: docstore.NewCollection[Tenant](docs, "tenants", nil)}
+}
+
+// NewMemoryStore keeps tenants in process memory, for tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveTenant(t Tenant) error {
+	return d.tenants.Put(t.ID, t)
+}
+
+func (d *DocumentStore) GetTenant(id string) (Tenant, error) {
+	t, err := d.tenants.Get(id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Tenant{}, ErrNotFound
+	}
+	return t, err
+}
+
+// ListTenants returns every tenant ordered by ID.
+func (d *DocumentStore) ListTenants() ([]Tenant, error) {
+	return d.tenants.Find(nil)
+}
@@ -0,0 +1,283 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/access"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/flags"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newService(t *testing.T) *Service {
+	t.Helper()
+	svc, err := NewService(config.TenancyConfig{Enabled: true, Header: "X-Tenant-ID", Default: "acme"}, NewMemoryStore(), nil)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, err := svc.Save("bugsbgone", Tenant{Name: "Bugs B Gone", Flags: map[string]string{"sdui.validateResponses": "true", "home.layout": "compact"}}); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	return svc
+}
+
+func TestScopeFiltersAndStampsRecords(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	day := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	_ = store.SaveTechnician(models.Technician{ID: "legacy"})
+	_ = store.SaveRoute(models.Route{ID: "r-legacy", TechnicianID: "legacy", ServiceDate: day})
+
+	acme := Scope{Tenant: Tenant{ID: "acme"}, Untagged: true}.Repository(repos)
+	bugs := Scope{Tenant: Tenant{ID: "bugsbgone"}}.Repository(repos)
+
+	if err := bugs.Technicians.SaveTechnician(models.Technician{ID: "tech-b"}); err != nil {
+		t.Fatalf("save technician: %v", err)
+	}
+	if err := bugs.Routes.SaveRoute(models.Route{ID: "r-b", TechnicianID: "tech-b", ServiceDate: day}); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	if err := bugs.Sync.SaveJobUpload(models.JobUpload{ID: "job-b", TechnicianID: "tech-b"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	if stored, _ := store.GetRoute("tech-b", day); stored.TenantID != "bugsbgone" {
+		t.Fatalf("expected the route to be stamped, got %q", stored.TenantID)
+	}
+
+	if techs, _ := acme.Technicians.ListTechnicians(); len(techs) != 1 || techs[0].ID != "legacy" {
+		t.Fatalf("expected the default tenant to see only untagged technicians, got %+v", techs)
+	}
+	if techs, _ := bugs.Technicians.ListTechnicians(); len(techs) != 1 || techs[0].ID != "tech-b" {
+		t.Fatalf("expected bugsbgone to see its technician, got %+v", techs)
+	}
+	if _, err := acme.Routes.GetRoute("tech-b", day); err == nil {
+		t.Fatal("expected another tenant's route to be hidden")
+	}
+	if _, err := bugs.Technicians.GetByID("legacy"); err == nil {
+		t.Fatal("expected untagged technicians to be hidden from other tenants")
+	}
+	if jobs, _ := acme.Sync.ListJobUpdatesSince(time.Time{}); len(jobs) != 0 {
+		t.Fatalf("expected no jobs for the default tenant, got %+v", jobs)
+	}
+
+	if err := acme.Routes.SaveRoute(models.Route{ID: "r-b", TechnicianID: "tech-b", ServiceDate: day}); !errors.Is(err, ErrForeignTenant) {
+		t.Fatalf("expected a foreign route write to be refused, got %v", err)
+	}
+	if err := acme.Sync.SaveJobUpload(models.JobUpload{ID: "job-x", TechnicianID: "tech-b"}); !errors.Is(err, ErrForeignTenant) {
+		t.Fatalf("expected a job for a foreign technician to be refused, got %v", err)
+	}
+	if err := acme.Sync.DeleteJob("job-b"); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+	if jobs, _ := bugs.Sync.ListJobUpdatesSince(time.Time{}); len(jobs) != 1 {
+		t.Fatalf("expected a foreign delete to leave the job, got %+v", jobs)
+	}
+	if err := acme.Routes.DeleteRoute("tech-b", day); err != nil {
+		t.Fatalf("delete route: %v", err)
+	}
+	if _, err := bugs.Routes.GetRoute("tech-b", day); err != nil {
+		t.Fatalf("expected a foreign delete to leave the route, got %v", err)
+	}
+}
+
+func TestScopedUnprocessedPagesSkipOtherTenants(t *testing.T) {
+	store := storememory.NewStore()
+	repos := repository.Repository{Technicians: store, Sync: store}
+	for i, tenantID := range []string{"acme", "bugsbgone", "bugsbgone", "acme", "bugsbgone", "acme"} {
+		_ = store.SaveJobUpload(models.JobUpload{ID: fmt.Sprintf("job-%d", i), TenantID: tenantID})
+		time.Sleep(time.Millisecond)
+	}
+	acme := Scope{Tenant: Tenant{ID: "acme"}}.Repository(repos)
+
+	first, err := acme.Sync.ListUnprocessedJobs(repository.Page{Limit: 2})
+	if err != nil || len(first) != 2 || first[0].ID != "job-0" || first[1].ID != "job-3" {
+		t.Fatalf("expected a full page of acme jobs, got %+v (%v)", first, err)
+	}
+	last := first[len(first)-1]
+	rest, err := acme.Sync.ListUnprocessedJobs(repository.Page{Limit: 2}.Next(last.ReceivedAt, last.ID))
+	if err != nil || len(rest) != 1 || rest[0].ID != "job-5" {
+		t.Fatalf("expected the last acme job, got %+v (%v)", rest, err)
+	}
+}
+
+// apiTokens stands in for API tokens, bound to the tenant of their secret.
+type apiTokens map[string]string
+
+func (a apiTokens) CredentialTenant(r *http.Request) (string, bool) {
+	t, ok := a[r.Header.Get("Authorization")]
+	return t, ok
+}
+
+func TestMiddlewareResolvesTenant(t *testing.T) {
+	svc := newService(t)
+	var got Scope
+	h := svc.Middleware(apiTokens{"Bearer pg_bound": "bugsbgone", "Bearer pg_unbound": ""})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ScopeFrom(r.Context())
+	}))
+	serve := func(caller, header string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/screens/home", nil)
+		switch caller {
+		case "technician":
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician}))
+		case "claim":
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician, Tenant: "bugsbgone"}))
+		case "staff":
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "d-1", Role: auth.RoleDispatcher}))
+		case "bound", "unbound":
+			req.Header.Set("Authorization", "Bearer pg_"+caller)
+		}
+		if header != "" {
+			req.Header.Set("X-Tenant-ID", header)
+		}
+		got = Scope{}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cases := []struct {
+		caller, header string
+		status         int
+		tenant         string
+	}{
+		{"claim", "", http.StatusOK, "bugsbgone"},
+		{"claim", "bugsbgone", http.StatusOK, "bugsbgone"},
+		{"claim", "acme", http.StatusForbidden, ""},
+		{"staff", "bugsbgone", http.StatusOK, "bugsbgone"},
+		{"staff", "", http.StatusOK, "acme"},
+		{"staff", "unknown", http.StatusForbidden, ""},
+		{"technician", "", http.StatusOK, "acme"},
+		{"technician", "bugsbgone", http.StatusForbidden, ""},
+		{"bound", "", http.StatusOK, "bugsbgone"},
+		{"bound", "acme", http.StatusForbidden, ""},
+		{"unbound", "", http.StatusOK, "acme"},
+		{"unbound", "bugsbgone", http.StatusForbidden, ""},
+		{"", "", http.StatusOK, "acme"},
+		{"", "acme", http.StatusOK, "acme"},
+		{"", "bugsbgone", http.StatusForbidden, ""},
+	}
+	for _, tc := range cases {
+		if status := serve(tc.caller, tc.header); status != tc.status || got.Tenant.ID != tc.tenant {
+			t.Errorf("%q with header %q: got %d %q, want %d %q", tc.caller, tc.header, status, got.Tenant.ID, tc.status, tc.tenant)
+		}
+	}
+	if serve("", ""); !got.Untagged {
+		t.Fatal("expected the default tenant to own untagged records")
+	}
+
+	var disabled *Service
+	rec := httptest.NewRecorder()
+	disabled.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ScopeFrom(r.Context()); ok {
+			t.Error("expected no scope with tenancy disabled")
+		}
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/screens/home", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a disabled service to pass requests through, got %d", rec.Code)
+	}
+}
+
+func TestRequirePlatformRefusesTenantTokens(t *testing.T) {
+	svc := newService(t)
+	h := svc.RequirePlatform(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/flags", nil)
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "d-1", Role: auth.RoleDispatcher, Tenant: "bugsbgone"}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+type staticFlags map[string]string
+
+func (f staticFlags) Flag(_ context.Context, key string, _ flags.FlattenedContext) (string, bool) {
+	v, ok := f[key]
+	return v, ok
+}
+
+func (f staticFlags) Bool(_ context.Context, key string, fallback bool, _ flags.FlattenedContext) bool {
+	if v, ok := f[key]; ok {
+		return v == "true"
+	}
+	return fallback
+}
+
+func TestFlagsAndThemeFollowTheTenant(t *testing.T) {
+	svc := newService(t)
+	bugs, _ := svc.Tenant("bugsbgone")
+	bugs.Branding.PrimaryColor = "#1B5E20"
+	if _, err := svc.Save("bugsbgone", bugs); err != nil {
+		t.Fatalf("save branding: %v", err)
+	}
+	bugs, _ = svc.Tenant("bugsbgone")
+
+	ev := svc.Flags(staticFlags{"home.layout": "classic", "sdui.validateResponses": "false"})
+	ctx := ContextWithScope(context.Background(), Scope{Tenant: bugs})
+	if v, _ := ev.Flag(ctx, "home.layout", nil); v != "compact" {
+		t.Fatalf("expected the tenant override, got %q", v)
+	}
+	if !ev.Bool(ctx, "sdui.validateResponses", false, nil) {
+		t.Fatal("expected the tenant's boolean override")
+	}
+	if v, _ := ev.Flag(context.Background(), "home.layout", nil); v != "classic" {
+		t.Fatalf("expected the flag service's value without a tenant, got %q", v)
+	}
+
+	if theme := svc.Theme(ctx); theme == nil || theme.PrimaryColor != "#1B5E20" {
+		t.Fatalf("unexpected theme %+v", theme)
+	}
+	if theme := svc.Theme(context.Background()); theme != nil {
+		t.Fatalf("expected no theme without a tenant, got %+v", theme)
+	}
+
+	bugs.Branding.AccentColor = "orange"
+	if _, err := svc.Save("bugsbgone", bugs); !errors.Is(err, ErrInvalidTenant) {
+		t.Fatalf("expected an invalid color to be rejected, got %v", err)
+	}
+}
+
+func TestAccessPoliciesOnlyAddRestrictions(t *testing.T) {
+	svc := newService(t)
+	if _, err := svc.Save("acme", Tenant{Name: "Acme", Access: access.Policy{AllowedIPs: []string{"203.0.113.0/24"}}}); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	if _, err := svc.Save("bugsbgone", Tenant{Name: "Bugs B Gone", Access: access.Policy{BlockedCountries: []string{"RU"}}}); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	if _, err := svc.Save("bad", Tenant{Name: "Bad", Access: access.Policy{AllowedIPs: []string{"10.0.0/8"}}}); !errors.Is(err, ErrInvalidTenant) {
+		t.Fatalf("expected an invalid allowlist refused, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/routes", nil)
+	policies, err := svc.AccessPolicies(req)
+	if err != nil || len(policies) != 1 || policies[0].AllowedIPs[0] != "203.0.113.0/24" {
+		t.Fatalf("expected the default tenant's policy, got %+v %v", policies, err)
+	}
+	req.Header.Set("X-Tenant-ID", "bugsbgone")
+	if policies, _ = svc.AccessPolicies(req); len(policies) != 1 {
+		t.Fatalf("expected the header ignored, got %+v", policies)
+	}
+	claimed := req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "d-1", Role: auth.RoleAdmin, Tenant: "bugsbgone"}))
+	if policies, _ = svc.AccessPolicies(claimed); len(policies) != 2 || policies[1].BlockedCountries[0] != "RU" {
+		t.Fatalf("expected the token's tenant's policy added to the default's, got %+v", policies)
+	}
+	unknown := req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "d-1", Role: auth.RoleAdmin, Tenant: "unknown"}))
+	if policies, _ = svc.AccessPolicies(unknown); len(policies) != 1 {
+		t.Fatalf("expected unknown tenants ignored, got %+v", policies)
+	}
+
+	var disabled *Service
+	if policies, err := disabled.AccessPolicies(req); policies != nil || err != nil {
+		t.Fatalf("expected no policies without tenancy, got %+v %v", policies, err)
+	}
+}
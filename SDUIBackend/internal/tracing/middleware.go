@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Middleware starts a server span for each request, continuing the trace of
+// a traceparent header when the caller sent one. The span carries the
+// request's correlation ID, so it must run after middleware.Correlation,
+// and the request logger gains the trace ID so logs and traces line up.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID, parent, ok := parseTraceparent(r.Header.Get("traceparent"))
+		if !ok {
+			traceID, parent = newTraceID(), [8]byte{}
+		}
+		ctx, span := t.start(r.Context(), r.Method, kindServer, traceID, parent, []Attr{
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+			String("correlation.id", middleware.FromContext(r.Context())),
+		})
+		defer span.End()
+		ctx = middleware.ContextWithLogger(ctx, middleware.LoggerFrom(ctx).With(slog.String("traceId", span.TraceID())))
+
+		rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		// The route pattern is only known once chi has routed the request.
+		if rc := chi.RouteContext(ctx); rc != nil && rc.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rc.RoutePattern())
+			span.SetAttributes(String("http.route", rc.RoutePattern()))
+		}
+		span.SetAttributes(Int("http.response.status_code", rw.status))
+		if rw.status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("%d %s", rw.status, http.StatusText(rw.status)))
+		}
+	})
+}
+
+// parseTraceparent reads a W3C traceparent header:
+// version-traceid-parentid-flags, all hex.
+func parseTraceparent(h string) (traceID [16]byte, parent [8]byte, ok bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return traceID, parent, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil {
+		return traceID, parent, false
+	}
+	if _, err := hex.Decode(parent[:], []byte(parts[2])); err != nil {
+		return traceID, parent, false
+	}
+	// All-zero IDs are invalid.
+	if traceID == ([16]byte{}) || parent == ([8]byte{}) {
+		return traceID, parent, false
+	}
+	return traceID, parent, true
+}
+
+// statusWriter records the status written to the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// scopeName identifies this service's instrumentation in exported spans.
+const scopeName = "github.com/your-org/pestgenie-sdui"
+
+// OTLP/JSON status codes.
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+// export sends spans to the collector as an OTLP/JSON trace request.
+func (t *Tracer) export(ctx context.Context, spans []*Span) error {
+	out := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, s.otlp())
+	}
+	body, err := json.Marshal(map[string]any{
+		"resourceSpans": []map[string]any{{
+			"resource": map[string]any{"attributes": otlpAttrs([]Attr{String("service.name", t.service)})},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]string{"name": scopeName},
+				"spans": out,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// otlp renders a finished span in OTLP/JSON, where IDs are hex and times
+// are nanoseconds as strings.
+func (s *Span) otlp() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	span := map[string]any{
+		"traceId":           hexID(s.traceID[:]),
+		"spanId":            hexID(s.id[:]),
+		"name":              s.name,
+		"kind":              s.kind,
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+		"attributes":        otlpAttrs(s.attrs),
+		"status":            map[string]any{"code": statusOK},
+	}
+	if s.parent != ([8]byte{}) {
+		span["parentSpanId"] = hexID(s.parent[:])
+	}
+	if s.failed {
+		span["status"] = map[string]any{"code": statusError, "message": s.errMsg}
+	}
+	return span
+}
+
+func otlpAttrs(attrs []Attr) []map[string]any {
+	out := make([]map[string]any, 0, len(attrs))
+	for _, a := range attrs {
+		var value map[string]any
+		switch v := a.Value.(type) {
+		case string:
+			value = map[string]any{"stringValue": v}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int:
+			value = map[string]any{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, map[string]any{"key": a.Key, "value": value})
+	}
+	return out
+}
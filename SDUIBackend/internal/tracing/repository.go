@@ -0,0 +1,287 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// Repository wraps every repository so each call is a child span of the
+// span on ctx. Repositories take no context, so callers with one bind them
+// per call; without a span on ctx repos is returned as is. Nil
+// repositories are left nil so Repository.Validate still reports them.
+func Repository(ctx context.Context, repos repository.Repository) repository.Repository {
+	if FromContext(ctx) == nil {
+		return repos
+	}
+	c := caller{ctx: ctx}
+	out := repos
+	if repos.Technicians != nil {
+		out.Technicians = technicians{base: repos.Technicians, caller: c}
+	}
+	if repos.Routes != nil {
+		out.Routes = routes{base: repos.Routes, caller: c}
+	}
+	if repos.Screens != nil {
+		out.Screens = screens{base: repos.Screens, caller: c}
+	}
+	if repos.Sync != nil {
+		out.Sync = syncRepo{base: repos.Sync, caller: c}
+	}
+	if repos.Devices != nil {
+		out.Devices = devices{base: repos.Devices, caller: c}
+	}
+	return out
+}
+
+// caller starts repository spans under ctx's span.
+type caller struct {
+	ctx context.Context
+}
+
+// span starts a span for method; the returned func ends it with the
+// call's error.
+func (c caller) span(method string) func(*error) {
+	_, s := Start(c.ctx, "repository."+method)
+	return func(err *error) {
+		s.RecordError(*err)
+		s.End()
+	}
+}
+
+type technicians struct {
+	base repository.TechnicianRepository
+	caller
+}
+
+func (t technicians) GetByID(id string) (_ models.Technician, err error) {
+	defer t.span("GetByID")(&err)
+	return t.base.GetByID(id)
+}
+
+func (t technicians) SaveTechnician(tech models.Technician) (err error) {
+	defer t.span("SaveTechnician")(&err)
+	return t.base.SaveTechnician(tech)
+}
+
+func (t technicians) ListTechnicians() (_ []models.Technician, err error) {
+	defer t.span("ListTechnicians")(&err)
+	return t.base.ListTechnicians()
+}
+
+type routes struct {
+	base repository.RouteRepository
+	caller
+}
+
+func (r routes) GetRoute(technicianID string, serviceDate time.Time) (_ models.Route, err error) {
+	defer r.span("GetRoute")(&err)
+	return r.base.GetRoute(technicianID, serviceDate)
+}
+
+func (r routes) SaveRoute(route models.Route) (err error) {
+	defer r.span("SaveRoute")(&err)
+	return r.base.SaveRoute(route)
+}
+
+func (r routes) DeleteRoute(technicianID string, serviceDate time.Time) (err error) {
+	defer r.span("DeleteRoute")(&err)
+	return r.base.DeleteRoute(technicianID, serviceDate)
+}
+
+type screens struct {
+	base repository.ScreenRepository
+	caller
+}
+
+func (s screens) GetTemplate(id string, version int) (_ models.ScreenTemplate, err error) {
+	defer s.span("GetTemplate")(&err)
+	return s.base.GetTemplate(id, version)
+}
+
+func (s screens) SaveTemplate(template models.ScreenTemplate) (err error) {
+	defer s.span("SaveTemplate")(&err)
+	return s.base.SaveTemplate(template)
+}
+
+func (s screens) ListTemplates() (_ []models.ScreenTemplate, err error) {
+	defer s.span("ListTemplates")(&err)
+	return s.base.ListTemplates()
+}
+
+func (s screens) DeleteTemplate(id string, version int) (err error) {
+	defer s.span("DeleteTemplate")(&err)
+	return s.base.DeleteTemplate(id, version)
+}
+
+type syncRepo struct {
+	base repository.SyncRepository
+	caller
+}
+
+func (s syncRepo) SaveJobUpload(upload models.JobUpload) (err error) {
+	defer s.span("SaveJobUpload")(&err)
+	return s.base.SaveJobUpload(upload)
+}
+
+func (s syncRepo) GetJobUpload(id string) (_ models.JobUpload, err error) {
+	defer s.span("GetJobUpload")(&err)
+	return s.base.GetJobUpload(id)
+}
+
+func (s syncRepo) SaveChemicalUpload(upload models.ChemicalUpload) (err error) {
+	defer s.span("SaveChemicalUpload")(&err)
+	return s.base.SaveChemicalUpload(upload)
+}
+
+func (s syncRepo) SaveChemicalTreatment(upload models.ChemicalTreatmentUpload) (err error) {
+	defer s.span("SaveChemicalTreatment")(&err)
+	return s.base.SaveChemicalTreatment(upload)
+}
+
+func (s syncRepo) ListPendingJobs(limit int) (_ []models.JobUpload, err error) {
+	defer s.span("ListPendingJobs")(&err)
+	return s.base.ListPendingJobs(limit)
+}
+
+func (s syncRepo) ListPendingChemicals(limit int) (_ []models.ChemicalUpload, err error) {
+	defer s.span("ListPendingChemicals")(&err)
+	return s.base.ListPendingChemicals(limit)
+}
+
+func (s syncRepo) ListAllJobs() (_ []models.JobUpload, err error) {
+	defer s.span("ListAllJobs")(&err)
+	return s.base.ListAllJobs()
+}
+
+func (s syncRepo) ListAllChemicals() (_ []models.ChemicalUpload, err error) {
+	defer s.span("ListAllChemicals")(&err)
+	return s.base.ListAllChemicals()
+}
+
+func (s syncRepo) ListPendingTreatments(limit int) (_ []models.ChemicalTreatmentUpload, err error) {
+	defer s.span("ListPendingTreatments")(&err)
+	return s.base.ListPendingTreatments(limit)
+}
+
+func (s syncRepo) ListJobUpdatesSince(since time.Time) (_ []models.JobUpload, err error) {
+	defer s.span("ListJobUpdatesSince")(&err)
+	return s.base.ListJobUpdatesSince(since)
+}
+
+func (s syncRepo) ListRouteUpdatesSince(since time.Time) (_ []models.Route, err error) {
+	defer s.span("ListRouteUpdatesSince")(&err)
+	return s.base.ListRouteUpdatesSince(since)
+}
+
+func (s syncRepo) ListChemicalUpdatesSince(since time.Time) (_ []models.ChemicalUpload, err error) {
+	defer s.span("ListChemicalUpdatesSince")(&err)
+	return s.base.ListChemicalUpdatesSince(since)
+}
+
+func (s syncRepo) ListTreatmentUpdatesSince(since time.Time) (_ []models.ChemicalTreatmentUpload, err error) {
+	defer s.span("ListTreatmentUpdatesSince")(&err)
+	return s.base.ListTreatmentUpdatesSince(since)
+}
+
+func (s syncRepo) ListJobUpdates(page repository.Page) (_ []models.JobUpload, err error) {
+	defer s.span("ListJobUpdates")(&err)
+	return s.base.ListJobUpdates(page)
+}
+
+func (s syncRepo) ListRouteUpdates(page repository.Page) (_ []models.Route, err error) {
+	defer s.span("ListRouteUpdates")(&err)
+	return s.base.ListRouteUpdates(page)
+}
+
+func (s syncRepo) ListChemicalUpdates(page repository.Page) (_ []models.ChemicalUpload, err error) {
+	defer s.span("ListChemicalUpdates")(&err)
+	return s.base.ListChemicalUpdates(page)
+}
+
+func (s syncRepo) ListTreatmentUpdates(page repository.Page) (_ []models.ChemicalTreatmentUpload, err error) {
+	defer s.span("ListTreatmentUpdates")(&err)
+	return s.base.ListTreatmentUpdates(page)
+}
+
+func (s syncRepo) ListUnprocessedJobs(page repository.Page) (_ []models.JobUpload, err error) {
+	defer s.span("ListUnprocessedJobs")(&err)
+	return s.base.ListUnprocessedJobs(page)
+}
+
+func (s syncRepo) ListUnprocessedChemicals(page repository.Page) (_ []models.ChemicalUpload, err error) {
+	defer s.span("ListUnprocessedChemicals")(&err)
+	return s.base.ListUnprocessedChemicals(page)
+}
+
+func (s syncRepo) ListUnprocessedTreatments(page repository.Page) (_ []models.ChemicalTreatmentUpload, err error) {
+	defer s.span("ListUnprocessedTreatments")(&err)
+	return s.base.ListUnprocessedTreatments(page)
+}
+
+func (s syncRepo) ListUnprocessedRoutes(page repository.Page) (_ []models.Route, err error) {
+	defer s.span("ListUnprocessedRoutes")(&err)
+	return s.base.ListUnprocessedRoutes(page)
+}
+
+func (s syncRepo) MarkProcessed(kind, id string, savedAt time.Time) (err error) {
+	defer s.span("MarkProcessed")(&err)
+	return s.base.MarkProcessed(kind, id, savedAt)
+}
+
+func (s syncRepo) DeleteJob(id string) (err error) {
+	defer s.span("DeleteJob")(&err)
+	return s.base.DeleteJob(id)
+}
+
+func (s syncRepo) DeleteChemical(id string) (err error) {
+	defer s.span("DeleteChemical")(&err)
+	return s.base.DeleteChemical(id)
+}
+
+func (s syncRepo) ListDeletionsSince(since time.Time) (_ []models.Tombstone, err error) {
+	defer s.span("ListDeletionsSince")(&err)
+	return s.base.ListDeletionsSince(since)
+}
+
+func (s syncRepo) ListDeletions(page repository.Page) (_ []models.Tombstone, err error) {
+	defer s.span("ListDeletions")(&err)
+	return s.base.ListDeletions(page)
+}
+
+func (s syncRepo) PruneTombstones(cutoff time.Time) (_ int, err error) {
+	defer s.span("PruneTombstones")(&err)
+	return s.base.PruneTombstones(cutoff)
+}
+
+func (s syncRepo) SaveBatch(batch repository.Batch) (err error) {
+	defer s.span("SaveBatch")(&err)
+	return s.base.SaveBatch(batch)
+}
+
+type devices struct {
+	base repository.DeviceRepository
+	caller
+}
+
+func (d devices) SaveDeviceToken(token models.DeviceToken) (err error) {
+	defer d.span("SaveDeviceToken")(&err)
+	return d.base.SaveDeviceToken(token)
+}
+
+func (d devices) ListDeviceTokens(technicianID string) (_ []models.DeviceToken, err error) {
+	defer d.span("ListDeviceTokens")(&err)
+	return d.base.ListDeviceTokens(technicianID)
+}
+
+func (d devices) DeleteDeviceToken(token string) (err error) {
+	defer d.span("DeleteDeviceToken")(&err)
+	return d.base.DeleteDeviceToken(token)
+}
+
+func (d devices) PruneDeviceTokens(cutoff time.Time) (_ int, err error) {
+	defer d.span("PruneDeviceTokens")(&err)
+	return d.base.PruneDeviceTokens(cutoff)
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Span kinds, as numbered by OTLP.
+const (
+	kindInternal = 1
+	kindServer   = 2
+)
+
+// spanKey is the context key for the current span.
+type spanKey struct{}
+
+// Attr is a span attribute. Values are strings, ints, int64s, float64s or
+// bools; others are recorded as their fmt representation.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: value} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// Span is one timed operation in a trace. A nil Span ignores every call,
+// so callers need not check whether tracing is on.
+type Span struct {
+	tracer  *Tracer
+	name    string
+	kind    int
+	traceID [16]byte
+	id      [8]byte
+	parent  [8]byte
+	start   time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  []Attr
+	errMsg string
+	failed bool
+}
+
+// Start begins a child of the span on ctx, returning a context carrying the
+// child. Without a span on ctx, as in background loops or with tracing
+// off, it returns ctx and a nil span.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, name, kindInternal, parent.traceID, parent.id, attrs)
+}
+
+// FromContext returns the span on ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceID returns the span's trace ID in hex, or "" for a nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hexID(s.traceID[:])
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetName renames the span, for names only known once work is under way,
+// such as a request's route pattern.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// RecordError marks the span failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.errMsg = err.Error()
+}
+
+// End finishes the span and queues it for export. Ending a span twice
+// has no further effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.finished(s)
+}
@@ -0,0 +1,164 @@
+// Package tracing records request traces and exports them to an
+// OpenTelemetry collector over OTLP/HTTP. Middleware starts a server span
+// per request, continuing a W3C traceparent sent by the caller; features
+// start child spans from the request context with Start. Spans are batched
+// in memory and exported by Run, so a slow collector never delays a
+// request; when the batch is full new spans are dropped and counted.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+const (
+	// maxPending bounds the finished spans held for export.
+	maxPending = 4096
+	// batchSize is the most spans sent in one export.
+	batchSize = 512
+	// flushInterval is how often Run exports pending spans.
+	flushInterval = 5 * time.Second
+)
+
+// Tracer records spans and exports them to the OTLP endpoint. A nil Tracer
+// records nothing, so tracing can be turned off without nil checks.
+type Tracer struct {
+	service  string
+	endpoint string // .../v1/traces
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	pending []*Span
+	full    chan struct{} // signalled when a batch is ready
+
+	dropped atomic.Int64
+}
+
+// New returns a tracer exporting to cfg.OTLPEndpoint, or nil when tracing
+// is disabled or no endpoint is configured.
+func New(cfg config.TelemetryConfig, logger *slog.Logger) *Tracer {
+	if !cfg.EnableTracing || cfg.OTLPEndpoint == "" {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	endpoint := strings.TrimSuffix(cfg.OTLPEndpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	return &Tracer{
+		service:  cfg.ServiceName,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		full:     make(chan struct{}, 1),
+	}
+}
+
+// Run exports finished spans every flushInterval, or as soon as a batch is
+// ready, until ctx is cancelled.
+func (t *Tracer) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-t.full:
+		}
+		if err := t.Flush(ctx); err != nil && ctx.Err() == nil {
+			t.logger.Warn("export traces", slog.Any("error", err))
+		}
+	}
+}
+
+// Flush exports every finished span. Spans that fail to export are
+// dropped rather than retried.
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	for {
+		t.mu.Lock()
+		n := min(len(t.pending), batchSize)
+		batch := t.pending[:n:n]
+		t.pending = t.pending[n:]
+		t.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if err := t.export(ctx, batch); err != nil {
+			t.dropped.Add(int64(n))
+			return err
+		}
+	}
+}
+
+// Dropped returns how many spans have been dropped, because the buffer was
+// full or their export failed, since the server started.
+func (t *Tracer) Dropped() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.dropped.Load()
+}
+
+// finished queues a span for export.
+func (t *Tracer) finished(s *Span) {
+	t.mu.Lock()
+	if len(t.pending) >= maxPending {
+		t.mu.Unlock()
+		t.dropped.Add(1)
+		return
+	}
+	t.pending = append(t.pending, s)
+	ready := len(t.pending) >= batchSize
+	t.mu.Unlock()
+	if ready {
+		select {
+		case t.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// start begins a span of kind in trace traceID, under parent when set.
+func (t *Tracer) start(ctx context.Context, name string, kind int, traceID [16]byte, parent [8]byte, attrs []Attr) (context.Context, *Span) {
+	s := &Span{
+		tracer:  t,
+		name:    name,
+		kind:    kind,
+		traceID: traceID,
+		parent:  parent,
+		start:   time.Now(),
+		attrs:   attrs,
+	}
+	_, _ = rand.Read(s.id[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func newTraceID() [16]byte {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func hexID(b []byte) string {
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func (s exportedSpan) attr(key string) any {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			for _, v := range a.Value {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// collector returns a tracer exporting to a fake OTLP collector, and the
+// spans it has received by name.
+func collector(t *testing.T) (*Tracer, map[string]exportedSpan) {
+	t.Helper()
+	spans := map[string]exportedSpan{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return New(config.TelemetryConfig{ServiceName: "test", OTLPEndpoint: srv.URL, EnableTracing: true}, nil), spans
+}
+
+func TestMiddlewareTracesRequests(t *testing.T) {
+	tracer, spans := collector(t)
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-1"})
+	repos := repository.Repository{Technicians: store}
+
+	router := chi.NewRouter()
+	router.Use(middleware.Correlation())
+	router.Use(tracer.Middleware)
+	router.Get("/v1/screens/{screenId}", func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := Start(r.Context(), "sdui.GetScreen")
+		defer span.End()
+		if _, err := Repository(ctx, repos).Technicians.GetByID("missing"); err == nil {
+			t.Error("expected a missing technician")
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/screens/today", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	server, child, repo := spans["GET /v1/screens/{screenId}"], spans["sdui.GetScreen"], spans["repository.GetByID"]
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID != "00f067aa0ba902b7" || server.Kind != kindServer {
+		t.Fatalf("expected the server span to continue the caller's trace, got %+v", server)
+	}
+	if server.attr("correlation.id") != "corr-1" || server.attr("http.response.status_code") != "502" || server.Status.Code != statusError {
+		t.Fatalf("expected the correlation ID and failed status recorded, got %+v", server)
+	}
+	if child.ParentSpanID != server.SpanID || repo.ParentSpanID != child.SpanID || repo.TraceID != server.TraceID {
+		t.Fatalf("expected nested child spans, got %+v and %+v", child, repo)
+	}
+	if repo.Status.Code != statusError || repo.Status.Message != repository.ErrTechnicianNotFound.Error() {
+		t.Fatalf("expected the repository error recorded, got %+v", repo)
+	}
+}
+
+func TestDisabledTracingIsANoOp(t *testing.T) {
+	tracer := New(config.TelemetryConfig{EnableTracing: true}, nil)
+	if tracer != nil {
+		t.Fatal("expected no tracer without an endpoint")
+	}
+	ctx, span := Start(context.Background(), "work")
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("boom"))
+	span.End()
+	repos := repository.Repository{Technicians: storememory.NewStore()}
+	if span != nil || FromContext(ctx) != nil || Repository(ctx, repos) != repos || tracer.Dropped() != 0 {
+		t.Fatal("expected spans without a parent to be nil")
+	}
+
+	called := false
+	tracer.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called {
+		t.Fatal("expected a nil tracer's middleware to pass requests through")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for h, want := range map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": true,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01": false,
+		"": false,
+	} {
+		if _, _, ok := parseTraceparent(h); ok != want {
+			t.Errorf("parseTraceparent(%q) = %v, want %v", h, ok, want)
+		}
+	}
+}
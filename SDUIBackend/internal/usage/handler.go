@@ -0,0 +1,185 @@
+package usage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Report formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// flushEvery is how many rows are written between flushes to the client.
+const flushEvery = 500
+
+// columns is the CSV header.
+var columns = []string{
+	"chemical_id", "chemical_name", "epa_registration", "active_ingredient",
+	"technician_id", "technician_name", "county", "region",
+	"unit_of_measure", "quantity", "treatments", "first_applied", "last_applied",
+}
+
+// Handler exposes usage reports in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a usage report handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/chemical-usage", h.GetChemicalUsage)
+}
+
+// GetChemicalUsage reports chemical usage between from and to, as JSON or,
+// with format=csv or an Accept header asking for text/csv, as CSV.
+// X-Held-Treatments counts the treatments left out while held for review.
+func (h *Handler) GetChemicalUsage(w http.ResponseWriter, r *http.Request) {
+	f, ok := filter(w, r)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatJSON
+		if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			format = FormatCSV
+		}
+	}
+	if format != FormatJSON && format != FormatCSV {
+		respond.Error(w, http.StatusBadRequest, "invalid format", "expected csv or json")
+		return
+	}
+	rows, held, err := h.service.report(f)
+	if err != nil {
+		h.fail(w, r, "failed to build usage report", err)
+		return
+	}
+	// Treatments waiting for supervisor review are not reported yet.
+	w.Header().Set("X-Held-Treatments", strconv.Itoa(held))
+
+	flush := func() {
+		_ = http.NewResponseController(w).Flush()
+	}
+	if format == FormatCSV {
+		name := fmt.Sprintf("chemical-usage-%s-%s.csv", f.From.Format("20060102"), f.To.Format("20060102"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		w.WriteHeader(http.StatusOK)
+		err = writeCSV(w, rows, flush)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, f, rows, flush)
+	}
+	if err != nil {
+		// The status is sent; the client sees a truncated body.
+		middleware.LoggerFrom(r.Context()).Warn("usage report write failed", slog.Any("error", err))
+	}
+}
+
+// writeCSV writes rows under the CSV header, flushing as it goes.
+func writeCSV(w io.Writer, rows []Row, flush func()) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		record := []string{
+			row.ChemicalID, row.ChemicalName, row.EPARegistration, row.ActiveIngredient,
+			row.TechnicianID, row.TechnicianName, row.County, row.Region,
+			row.UnitOfMeasure, strconv.FormatFloat(row.Quantity, 'f', -1, 64), strconv.Itoa(row.Treatments),
+			row.FirstApplied.UTC().Format(time.RFC3339), row.LastApplied.UTC().Format(time.RFC3339),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		if (i+1)%flushEvery == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			flush()
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes the report as a JSON object, row by row, flushing as
+// it goes.
+func writeJSON(w io.Writer, f Filter, rows []Row, flush func()) error {
+	head, err := json.Marshal(struct {
+		From         time.Time `json:"from"`
+		To           time.Time `json:"to"`
+		TechnicianID string    `json:"technicianId,omitempty"`
+	}{f.From, f.To, f.TechnicianID})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(head[:len(head)-1])+`,"rows":[`); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if (i+1)%flushEvery == 0 {
+			flush()
+		}
+	}
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// filter reads the report range and technician from the query; both ends
+// of the range are required.
+func filter(w http.ResponseWriter, r *http.Request) (Filter, bool) {
+	q := r.URL.Query()
+	f := Filter{TechnicianID: q.Get("technicianId")}
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		t, err := time.Parse(time.RFC3339, q.Get(name))
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid "+name, "expected RFC 3339")
+			return Filter{}, false
+		}
+		*dst = t
+	}
+	return f, true
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRange):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,216 @@
+package usage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/anomaly"
+	"github.com/your-org/pestgenie-sdui/internal/branch"
+)
+
+// Service aggregates treatments into usage reports.
+type Service struct {
+	repos    repository.Repository
+	branches *branch.Service
+	counties Counties
+	reviews  *anomaly.Service
+}
+
+// NewService creates a usage report service. A technician's region is
+// their branch's region, or their legacy region when branches is nil or
+// they have no branch; counties come from job addresses and are left
+// blank when counties is nil. Treatments reviews holds for supervisor
+// review are left out; a nil reviews reports every treatment.
+func NewService(repos repository.Repository, branches *branch.Service, counties Counties, reviews *anomaly.Service) *Service {
+	return &Service{repos: repos, branches: branches, counties: counties, reviews: reviews}
+}
+
+// key groups treatments into rows.
+type key struct {
+	chemicalID, technicianID, county, region string
+}
+
+// technician is what rows need of a technician.
+type technician struct {
+	name, region string
+}
+
+// ChemicalUsage aggregates the quantities of the treatments f selects by
+// chemical, technician, county and region, sorted by chemical name,
+// technician, county and region.
+func (s *Service) ChemicalUsage(f Filter) ([]Row, error) {
+	rows, _, err := s.report(f)
+	return rows, err
+}
+
+// report returns the rows of ChemicalUsage and how many treatments in the
+// range were held for review.
+func (s *Service) report(f Filter) ([]Row, int, error) {
+	if f.From.IsZero() || f.To.IsZero() {
+		return nil, 0, fmt.Errorf("%w: from and to are required", ErrInvalidRange)
+	}
+	if !f.To.After(f.From) {
+		return nil, 0, fmt.Errorf("%w: to must be after from", ErrInvalidRange)
+	}
+
+	chemicals, err := s.chemicals()
+	if err != nil {
+		return nil, 0, err
+	}
+	jobCounties, err := s.jobCounties()
+	if err != nil {
+		return nil, 0, err
+	}
+	held, err := s.reviews.Held()
+	if err != nil {
+		return nil, 0, err
+	}
+	withheld := 0
+	techs := map[string]technician{}
+	rows := map[key]*Row{}
+	err = s.scan(f.From, f.To, func(t models.ChemicalTreatmentUpload) error {
+		if f.TechnicianID != "" && t.TechnicianID != f.TechnicianID {
+			return nil
+		}
+		if held[t.ID] {
+			withheld++
+			return nil
+		}
+		tech, ok := techs[t.TechnicianID]
+		if !ok {
+			tech = s.technician(t.TechnicianID)
+			techs[t.TechnicianID] = tech
+		}
+		k := key{chemicalID: t.ChemicalID, technicianID: t.TechnicianID, county: jobCounties[t.JobID], region: tech.region}
+		row := rows[k]
+		if row == nil {
+			chem := chemicals[t.ChemicalID]
+			row = &Row{
+				ChemicalID:       t.ChemicalID,
+				ChemicalName:     chem.Name,
+				EPARegistration:  chem.EPARegistration,
+				ActiveIngredient: chem.ActiveIngredient,
+				TechnicianID:     t.TechnicianID,
+				TechnicianName:   tech.name,
+				County:           k.county,
+				Region:           k.region,
+				UnitOfMeasure:    chem.UnitOfMeasure,
+				FirstApplied:     t.ApplicationDate,
+				LastApplied:      t.ApplicationDate,
+			}
+			rows[k] = row
+		}
+		row.Quantity += t.QuantityUsed
+		row.Treatments++
+		if t.ApplicationDate.Before(row.FirstApplied) {
+			row.FirstApplied = t.ApplicationDate
+		}
+		if t.ApplicationDate.After(row.LastApplied) {
+			row.LastApplied = t.ApplicationDate
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		// Sums of device-typed quantities pick up float noise.
+		row.Quantity = math.Round(row.Quantity*1e4) / 1e4
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch {
+		case !strings.EqualFold(a.ChemicalName, b.ChemicalName):
+			return strings.ToLower(a.ChemicalName) < strings.ToLower(b.ChemicalName)
+		case a.ChemicalID != b.ChemicalID:
+			return a.ChemicalID < b.ChemicalID
+		case a.TechnicianID != b.TechnicianID:
+			return a.TechnicianID < b.TechnicianID
+		case a.County != b.County:
+			return a.County < b.County
+		default:
+			return a.Region < b.Region
+		}
+	})
+	return out, withheld, nil
+}
+
+// scan streams the treatments applied in [from, to) to fn. Stores that
+// cannot scan are read whole and filtered.
+func (s *Service) scan(from, to time.Time, fn func(models.ChemicalTreatmentUpload) error) error {
+	if scanner, ok := s.repos.Sync.(repository.TreatmentScanner); ok {
+		return scanner.ScanTreatments(from, to, fn)
+	}
+	treatments, err := s.repos.Sync.ListTreatmentUpdatesSince(time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, t := range treatments {
+		if t.ApplicationDate.Before(from) || !t.ApplicationDate.Before(to) {
+			continue
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chemicals returns the latest version of every chemical ever synced,
+// deleted ones included, by ID.
+func (s *Service) chemicals() (map[string]models.ChemicalUpload, error) {
+	uploads, err := s.repos.Sync.ListAllChemicals()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]models.ChemicalUpload, len(uploads))
+	for _, c := range uploads {
+		out[c.ID] = c
+	}
+	return out, nil
+}
+
+// jobCounties returns the county of each job's address, by job ID. It is
+// empty when no counties are configured.
+func (s *Service) jobCounties() (map[string]string, error) {
+	out := map[string]string{}
+	if len(s.counties) == 0 {
+		return out, nil
+	}
+	jobs, err := s.repos.Sync.ListAllJobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		if county := s.counties.Of(j.Address); county != "" {
+			out[j.ID] = county
+		} else {
+			delete(out, j.ID)
+		}
+	}
+	return out, nil
+}
+
+// technician returns a technician's name and region, both blank when the
+// technician is unknown.
+func (s *Service) technician(id string) technician {
+	tech, err := s.repos.Technicians.GetByID(id)
+	if err != nil {
+		return technician{}
+	}
+	region := tech.Region
+	if s.branches != nil && tech.BranchID != "" {
+		if b, err := s.branches.Branch(tech.BranchID); err == nil && b.Region != "" {
+			region = b.Region
+		}
+	}
+	return technician{name: tech.DisplayName, region: strings.ToUpper(strings.TrimSpace(region))}
+}
@@ -0,0 +1,101 @@
+// Package usage reports how much of each chemical was applied, by
+// technician and by the county and region it was applied in, for state
+// pesticide use reporting. Treatments are streamed from stores that can
+// scan them, so a long range costs the memory of the report rows rather
+// than of every treatment in it.
+package usage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRange is returned for a missing or inverted report range.
+	ErrInvalidRange = errors.New("invalid report range")
+	// ErrInvalidCounties wraps problems with the county file.
+	ErrInvalidCounties = errors.New("invalid county file")
+)
+
+// Filter selects the treatments a report covers: those applied in
+// [From, To), by TechnicianID when it is set.
+type Filter struct {
+	From         time.Time
+	To           time.Time
+	TechnicianID string
+}
+
+// Row is the usage of one chemical by one technician in one county and
+// region. Chemical details are as last synced, so a chemical deleted since
+// keeps its name.
+type Row struct {
+	ChemicalID       string    `json:"chemicalId"`
+	ChemicalName     string    `json:"chemicalName"`
+	EPARegistration  string    `json:"epaRegistration"`
+	ActiveIngredient string    `json:"activeIngredient"`
+	TechnicianID     string    `json:"technicianId"`
+	TechnicianName   string    `json:"technicianName"`
+	County           string    `json:"county"`
+	Region           string    `json:"region"`
+	UnitOfMeasure    string    `json:"unitOfMeasure"`
+	Quantity         float64   `json:"quantity"`
+	Treatments       int       `json:"treatments"`
+	FirstApplied     time.Time `json:"firstApplied"`
+	LastApplied      time.Time `json:"lastApplied"`
+}
+
+// Counties places US addresses in counties by ZIP code.
+type Counties map[string]string
+
+// zipPattern matches a ZIP or ZIP+4 code.
+var zipPattern = regexp.MustCompile(`\b(\d{5})(?:-\d{4})?\b`)
+
+// LoadCounties reads a CSV file with a zip,county header. An empty path
+// loads nothing and returns nil.
+func LoadCounties(path string) (Counties, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+	if _, err := r.Read(); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidCounties, err)
+	}
+	counties := Counties{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return counties, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCounties, err)
+		}
+		zip, county := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if len(zip) != 5 || !zipPattern.MatchString(zip) || county == "" {
+			return nil, fmt.Errorf("%w: %q is not a zip code and county", ErrInvalidCounties, strings.Join(record, ","))
+		}
+		counties[zip] = county
+	}
+}
+
+// Of returns the county of an address by its last ZIP code, or "" when it
+// has none or the ZIP code is not known.
+func (c Counties) Of(address string) string {
+	zips := zipPattern.FindAllStringSubmatch(address, -1)
+	if len(zips) == 0 {
+		return ""
+	}
+	return c[zips[len(zips)-1][1]]
+}
@@ -0,0 +1,132 @@
+package usage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "counties.csv")
+	if err := os.WriteFile(path, []byte("zip,county\n78701,Travis\n78664, Williamson\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	counties, err := LoadCounties(path)
+	if err != nil {
+		t.Fatalf("load counties: %v", err)
+	}
+
+	store := storememory.NewStore()
+	for _, tech := range []models.Technician{{ID: "sam", DisplayName: "Sam", Region: "tx"}, {ID: "ana", DisplayName: "Ana", Region: "TX"}} {
+		if err := store.SaveTechnician(tech); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []models.ChemicalUpload{
+		{ID: "c1", Name: "Termidor SC", EPARegistration: "7969-210", ActiveIngredient: "Fipronil", UnitOfMeasure: "oz"},
+		{ID: "c2", Name: "Demand CS", EPARegistration: "100-1066", ActiveIngredient: "Lambda-cyhalothrin", UnitOfMeasure: "ml"},
+	} {
+		if err := store.SaveChemicalUpload(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.DeleteChemical("c2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range []models.JobUpload{{ID: "j1", Address: "1 Congress Ave, Austin, TX 78701"}, {ID: "j2", Address: "9 Main St, Round Rock, TX 78664-1234"}} {
+		if err := store.SaveJobUpload(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tr := range []models.ChemicalTreatmentUpload{
+		{ID: "t1", JobID: "j1", ChemicalID: "c1", TechnicianID: "sam", ApplicationDate: day, QuantityUsed: 0.1},
+		{ID: "t2", JobID: "j1", ChemicalID: "c1", TechnicianID: "sam", ApplicationDate: day.Add(time.Hour), QuantityUsed: 0.2},
+		{ID: "t3", JobID: "j2", ChemicalID: "c1", TechnicianID: "sam", ApplicationDate: day, QuantityUsed: 1},
+		{ID: "t4", JobID: "j2", ChemicalID: "c2", TechnicianID: "ana", ApplicationDate: day, QuantityUsed: 30},
+		{ID: "t5", JobID: "j1", ChemicalID: "c1", TechnicianID: "sam", ApplicationDate: day.AddDate(0, 1, 0), QuantityUsed: 5},
+	} {
+		if err := store.SaveChemicalTreatment(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	return NewService(repos, nil, counties, nil)
+}
+
+func TestChemicalUsage(t *testing.T) {
+	svc := newTestService(t)
+	if _, err := svc.ChemicalUsage(Filter{From: day, To: day}); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("expected an empty range refused, got %v", err)
+	}
+
+	rows, err := svc.ChemicalUsage(Filter{From: day.AddDate(0, 0, -1), To: day.AddDate(0, 0, 1)})
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("expected three rows, got %+v (%v)", rows, err)
+	}
+	demand, travis, williamson := rows[0], rows[1], rows[2]
+	if demand.ChemicalName != "Demand CS" || demand.TechnicianName != "Ana" || demand.Quantity != 30 || demand.UnitOfMeasure != "ml" {
+		t.Errorf("expected the deleted chemical reported by name, got %+v", demand)
+	}
+	if travis.County != "Travis" || travis.Region != "TX" || travis.Quantity != 0.3 || travis.Treatments != 2 || !travis.LastApplied.Equal(day.Add(time.Hour)) {
+		t.Errorf("unexpected Travis row %+v", travis)
+	}
+	if williamson.County != "Williamson" || williamson.Quantity != 1 {
+		t.Errorf("expected the ZIP+4 address placed in Williamson, got %+v", williamson)
+	}
+
+	if rows, _ := svc.ChemicalUsage(Filter{From: day.AddDate(0, 0, -1), To: day.AddDate(0, 2, 0), TechnicianID: "sam"}); len(rows) != 2 || rows[0].Quantity != 5.3 {
+		t.Errorf("expected only Sam's treatments, got %+v", rows)
+	}
+}
+
+func TestChemicalUsageHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Route("/reports", NewHandler(newTestService(t)).Routes)
+	query := "/reports/chemical-usage?from=2026-05-01T00:00:00Z&to=2026-06-01T00:00:00Z"
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, query, nil))
+	var report struct {
+		To   time.Time `json:"to"`
+		Rows []Row     `json:"rows"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &report) != nil || len(report.Rows) != 3 || !report.To.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a JSON report, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, query, nil)
+	req.Header.Set("Accept", "text/csv")
+	r.ServeHTTP(rec, req)
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(records) != 4 || records[0][0] != "chemical_id" || records[2][6] != "Travis" || records[2][9] != "0.3" {
+		t.Fatalf("expected a CSV report, got %q (%v)", records, err)
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), "chemical-usage-20260501-20260601.csv") {
+		t.Errorf("unexpected disposition %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	for _, bad := range []string{"/reports/chemical-usage?from=2026-05-01T00:00:00Z", query + "&format=xml"} {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, bad, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, rec.Code)
+		}
+	}
+}
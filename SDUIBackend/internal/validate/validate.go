@@ -0,0 +1,91 @@
+// Package validate collects the field-level problems of a request body, so
+// handlers can reject it with every violation listed at once rather than
+// the first one found.
+package validate
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// FieldError is one field that breaks a rule.
+type FieldError struct {
+	// Field is the field's JSON name, with indexes for list items, such as
+	// lots[0].quantity.
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// Errors lists every invalid field of a body. It is an error so validation
+// can return it as one.
+type Errors []FieldError
+
+// Error reads as the details joined by semicolons, each after its field.
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + " " + fe.Detail
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Add records that field breaks a rule.
+func (e *Errors) Add(field, detail string) {
+	*e = append(*e, FieldError{Field: field, Detail: detail})
+}
+
+// Required records field as missing when value is blank.
+func (e *Errors) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, "is required")
+	}
+}
+
+// RequiredTime records field as missing when t is the zero time.
+func (e *Errors) RequiredTime(field string, t time.Time) {
+	if t.IsZero() {
+		e.Add(field, "is required")
+	}
+}
+
+// NonNegative records field as invalid when v is below zero.
+func (e *Errors) NonNegative(field string, v float64) {
+	if v < 0 {
+		e.Add(field, "must be >= 0")
+	}
+}
+
+// Positive records field as invalid unless v is above zero.
+func (e *Errors) Positive(field string, v float64) {
+	if v <= 0 {
+		e.Add(field, "must be > 0")
+	}
+}
+
+// OneOf records field as invalid unless value is one of allowed.
+func (e *Errors) OneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.Add(field, "must be one of "+strings.Join(allowed, ", "))
+}
+
+// Err returns e as an error, or nil when no field is invalid.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// FieldErrors returns the field errors within err, if it carries any.
+func FieldErrors(err error) Errors {
+	var e Errors
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package voicenote
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes voice note upload, playback, job history, and note search.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a voice note handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints on r.
+func (h *Handler) Routes(r chi.Router) {
+	r.Post("/{noteId}/transcribe", h.Retranscribe)
+}
+
+// UploadNote stores the raw audio request body as a voice note on a job.
+// The technician comes from ?technicianId= and the recording length, when
+// known, from ?durationSeconds=.
+func (h *Handler) UploadNote(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	upload := Upload{
+		JobID:        chi.URLParam(r, "jobId"),
+		TechnicianID: q.Get("technicianId"),
+	}
+	if ct, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		upload.ContentType = ct
+	}
+	if v := q.Get("durationSeconds"); v != "" {
+		d, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid durationSeconds", err.Error())
+			return
+		}
+		upload.DurationSeconds = d
+	}
+
+	audio, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.service.cfg.MaxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond.Error(w, http.StatusRequestEntityTooLarge, "audio too large", err.Error())
+			return
+		}
+		respond.Error(w, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	note, err := h.service.Upload(upload, audio)
+	if err != nil {
+		h.fail(w, r, "failed to save voice note", err)
+		return
+	}
+	respond.JSON(w, http.StatusCreated, note)
+}
+
+// ListNotes returns a job's voice notes.
+func (h *Handler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := h.service.Notes(chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.fail(w, r, "failed to list voice notes", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"notes": notes})
+}
+
+// GetNote returns a single voice note with its transcript.
+func (h *Handler) GetNote(w http.ResponseWriter, r *http.Request) {
+	note, err := h.service.Note(chi.URLParam(r, "jobId"), chi.URLParam(r, "noteId"))
+	if err != nil {
+		h.fail(w, r, "failed to load voice note", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, note)
+}
+
+// GetAudio streams a voice note's recording.
+func (h *Handler) GetAudio(w http.ResponseWriter, r *http.Request) {
+	audio, contentType, err := h.service.Audio(chi.URLParam(r, "jobId"), chi.URLParam(r, "noteId"))
+	if err != nil {
+		h.fail(w, r, "failed to load audio", err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(audio)
+}
+
+// JobHistory returns the events recorded against a job.
+func (h *Handler) JobHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.History(chi.URLParam(r, "jobId"))
+	if err != nil {
+		h.fail(w, r, "failed to load job history", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"history": history})
+}
+
+// SearchNotes searches job notes for ?q=, optionally limited to
+// ?technicianId= and ?limit= results (default 20).
+func (h *Handler) SearchNotes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("q") == "" {
+		respond.Error(w, http.StatusBadRequest, "missing q", "q query parameter is required")
+		return
+	}
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respond.Error(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	hits := h.service.Search(q.Get("q"), q.Get("technicianId"), limit)
+	respond.JSON(w, http.StatusOK, map[string]any{"results": hits})
+}
+
+// Retranscribe queues a note for another transcription attempt.
+func (h *Handler) Retranscribe(w http.ResponseWriter, r *http.Request) {
+	note, err := h.service.Retranscribe(chi.URLParam(r, "noteId"))
+	if err != nil {
+		h.fail(w, r, "failed to queue transcription", err)
+		return
+	}
+	respond.JSON(w, http.StatusAccepted, note)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+	case errors.Is(err, ErrInvalidNote):
+		respond.Error(w, http.StatusBadRequest, title, err.Error())
+	default:
+		middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+	}
+}
@@ -0,0 +1,118 @@
+package voicenote
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// IndexEntry is a searchable piece of job note text.
+type IndexEntry struct {
+	ID           string    `json:"id"` // unique per source, e.g. the note ID
+	JobID        string    `json:"jobId"`
+	TechnicianID string    `json:"technicianId"`
+	Source       string    `json:"source"` // e.g. "voice_note"
+	Text         string    `json:"text"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Hit is a search result.
+type Hit struct {
+	IndexEntry
+	Score int `json:"score"`
+}
+
+// Index is an in-memory inverted index over job note text. Entries match
+// when they contain every query term; more occurrences rank higher.
+type Index struct {
+	mu      sync.RWMutex
+	entries map[string]IndexEntry
+	terms   map[string]map[string]int // term -> entry ID -> occurrences
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]IndexEntry), terms: make(map[string]map[string]int)}
+}
+
+// Put adds or replaces an entry.
+func (x *Index) Put(e IndexEntry) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(e.ID)
+	x.entries[e.ID] = e
+	for _, term := range tokenize(e.Text) {
+		if x.terms[term] == nil {
+			x.terms[term] = make(map[string]int)
+		}
+		x.terms[term][e.ID]++
+	}
+}
+
+func (x *Index) remove(id string) {
+	old, ok := x.entries[id]
+	if !ok {
+		return
+	}
+	for _, term := range tokenize(old.Text) {
+		delete(x.terms[term], id)
+		if len(x.terms[term]) == 0 {
+			delete(x.terms, term)
+		}
+	}
+	delete(x.entries, id)
+}
+
+// Search returns up to limit entries matching query, best first. A non-empty
+// technicianID restricts results to that technician's notes.
+func (x *Index) Search(query, technicianID string, limit int) []Hit {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	scores := make(map[string]int)
+	for id, n := range x.terms[terms[0]] {
+		scores[id] = n
+	}
+	for _, term := range terms[1:] {
+		postings := x.terms[term]
+		for id := range scores {
+			n, ok := postings[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += n
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		e := x.entries[id]
+		if technicianID != "" && e.TechnicianID != technicianID {
+			continue
+		}
+		hits = append(hits, Hit{IndexEntry: e, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,182 @@
+package voicenote
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transcription statuses.
+const (
+	StatusNotRequested = "not_requested" // no transcriber configured
+	StatusPending      = "pending"
+	StatusTranscribed  = "transcribed"
+	StatusFailed       = "failed"
+)
+
+// Job history events recorded for voice notes.
+const (
+	EventUploaded    = "voice_note.uploaded"
+	EventTranscribed = "voice_note.transcribed"
+	EventFailed      = "voice_note.transcription_failed"
+)
+
+var (
+	// ErrNotFound is returned when a note does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidNote wraps upload validation failures.
+	ErrInvalidNote = errors.New("invalid voice note")
+)
+
+// Upload describes an audio recording attached to a job.
+type Upload struct {
+	JobID           string
+	TechnicianID    string
+	ContentType     string
+	DurationSeconds float64
+}
+
+// Validate checks an upload and its audio.
+func (u Upload) Validate(size, maxBytes int64) error {
+	var problems []string
+	if u.JobID == "" {
+		problems = append(problems, "jobId is required")
+	}
+	if u.TechnicianID == "" {
+		problems = append(problems, "technicianId is required")
+	}
+	if !strings.HasPrefix(u.ContentType, "audio/") {
+		problems = append(problems, "content type must be audio/*")
+	}
+	if size == 0 {
+		problems = append(problems, "audio is empty")
+	}
+	if size > maxBytes {
+		problems = append(problems, fmt.Sprintf("audio exceeds %d bytes", maxBytes))
+	}
+	if u.DurationSeconds < 0 {
+		problems = append(problems, "durationSeconds must be >= 0")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidNote, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Note is a voice recording attached to a job and its transcript.
+type Note struct {
+	ID              string     `json:"id"`
+	JobID           string     `json:"jobId"`
+	TechnicianID    string     `json:"technicianId"`
+	ContentType     string     `json:"contentType"`
+	SizeBytes       int64      `json:"sizeBytes"`
+	DurationSeconds float64    `json:"durationSeconds,omitempty"`
+	Status          string     `json:"status"`
+	Transcript      string     `json:"transcript,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	TranscribedAt   *time.Time `json:"transcribedAt,omitempty"`
+}
+
+// HistoryEntry is one event in a job's history.
+type HistoryEntry struct {
+	JobID  string    `json:"jobId"`
+	At     time.Time `json:"at"`
+	Event  string    `json:"event"`
+	NoteID string    `json:"noteId,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Store persists notes, their audio, and job history.
+type Store interface {
+	SaveNote(n Note) error
+	GetNote(id string) (Note, error)
+	ListNotes(jobID string) ([]Note, error)
+	SaveAudio(noteID string, audio []byte) error
+	GetAudio(noteID string) ([]byte, error)
+	AppendHistory(e HistoryEntry) error
+	ListHistory(jobID string) ([]HistoryEntry, error)
+}
+
+// MemoryStore is an in-process Store for local development.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	notes   map[string]Note
+	audio   map[string][]byte
+	history map[string][]HistoryEntry
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		notes:   make(map[string]Note),
+		audio:   make(map[string][]byte),
+		history: make(map[string][]HistoryEntry),
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (m *MemoryStore) SaveNote(n Note) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notes[n.ID] = n
+	return nil
+}
+
+func (m *MemoryStore) GetNote(id string) (Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, ok := m.notes[id]
+	if !ok {
+		return Note{}, ErrNotFound
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) ListNotes(jobID string) ([]Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []Note
+	for _, n := range m.notes {
+		if n.JobID == jobID {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) SaveAudio(noteID string, audio []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audio[noteID] = append([]byte(nil), audio...)
+	return nil
+}
+
+func (m *MemoryStore) GetAudio(noteID string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	audio, ok := m.audio[noteID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return audio, nil
+}
+
+func (m *MemoryStore) AppendHistory(e HistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history[e.JobID] = append(m.history[e.JobID], e)
+	return nil
+}
+
+func (m *MemoryStore) ListHistory(jobID string) ([]HistoryEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]HistoryEntry(nil), m.history[jobID]...), nil
+}
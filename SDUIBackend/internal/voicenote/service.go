@@ -0,0 +1,214 @@
+package voicenote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// SourceVoiceNote marks index entries that come from voice note transcripts.
+const SourceVoiceNote = "voice_note"
+
+// Service stores job voice notes and transcribes them in the background.
+type Service struct {
+	cfg         config.VoiceNoteConfig
+	store       Store
+	index       *Index
+	transcriber Transcriber
+	queue       chan string
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewService wires a voice note service. A nil transcriber stores audio
+// without transcribing it.
+func NewService(cfg config.VoiceNoteConfig, store Store, index *Index, transcriber Transcriber, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = 100
+	}
+	return &Service{
+		cfg:         cfg,
+		store:       store,
+		index:       index,
+		transcriber: transcriber,
+		queue:       make(chan string, size),
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Upload stores a recording and queues it for transcription.
+func (s *Service) Upload(u Upload, audio []byte) (Note, error) {
+	if err := u.Validate(int64(len(audio)), s.cfg.MaxBytes); err != nil {
+		return Note{}, err
+	}
+	note := Note{
+		ID:              uuid.NewString(),
+		JobID:           u.JobID,
+		TechnicianID:    u.TechnicianID,
+		ContentType:     u.ContentType,
+		SizeBytes:       int64(len(audio)),
+		DurationSeconds: u.DurationSeconds,
+		Status:          StatusNotRequested,
+		CreatedAt:       s.now().UTC(),
+	}
+	if s.transcriber != nil {
+		note.Status = StatusPending
+	}
+	if err := s.store.SaveAudio(note.ID, audio); err != nil {
+		return Note{}, err
+	}
+	if err := s.store.SaveNote(note); err != nil {
+		return Note{}, err
+	}
+	s.record(note, EventUploaded, "")
+	s.enqueue(note)
+	return note, nil
+}
+
+// Retranscribe queues a note for another transcription attempt.
+func (s *Service) Retranscribe(id string) (Note, error) {
+	if s.transcriber == nil {
+		return Note{}, fmt.Errorf("%w: transcription is not configured", ErrInvalidNote)
+	}
+	note, err := s.store.GetNote(id)
+	if err != nil {
+		return Note{}, err
+	}
+	note.Status = StatusPending
+	note.Error = ""
+	if err := s.store.SaveNote(note); err != nil {
+		return Note{}, err
+	}
+	s.enqueue(note)
+	return note, nil
+}
+
+// Note returns a note of a job.
+func (s *Service) Note(jobID, id string) (Note, error) {
+	note, err := s.store.GetNote(id)
+	if err != nil {
+		return Note{}, err
+	}
+	if note.JobID != jobID {
+		return Note{}, ErrNotFound
+	}
+	return note, nil
+}
+
+// Notes lists a job's voice notes, oldest first.
+func (s *Service) Notes(jobID string) ([]Note, error) {
+	return s.store.ListNotes(jobID)
+}
+
+// Audio returns a note's recording with its content type.
+func (s *Service) Audio(jobID, id string) ([]byte, string, error) {
+	note, err := s.Note(jobID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	audio, err := s.store.GetAudio(id)
+	if err != nil {
+		return nil, "", err
+	}
+	return audio, note.ContentType, nil
+}
+
+// History returns a job's history, oldest first.
+func (s *Service) History(jobID string) ([]HistoryEntry, error) {
+	return s.store.ListHistory(jobID)
+}
+
+// Search queries the notes index.
+func (s *Service) Search(query, technicianID string, limit int) []Hit {
+	return s.index.Search(query, technicianID, limit)
+}
+
+// Run transcribes queued notes until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.transcribe(ctx, id)
+		}
+	}
+}
+
+// enqueue hands a pending note to Run. Notes that do not fit stay pending
+// and can be retried by an admin.
+func (s *Service) enqueue(note Note) {
+	if note.Status != StatusPending {
+		return
+	}
+	select {
+	case s.queue <- note.ID:
+	default:
+		s.logger.Warn("voice note transcription queue full", slog.String("note", note.ID))
+	}
+}
+
+func (s *Service) transcribe(ctx context.Context, id string) {
+	note, err := s.store.GetNote(id)
+	if err != nil {
+		s.logger.Error("load voice note", slog.String("note", id), slog.Any("error", err))
+		return
+	}
+	audio, err := s.store.GetAudio(id)
+	if err == nil {
+		var text string
+		text, err = s.transcriber.Transcribe(ctx, audio, note.ContentType)
+		note.Transcript = text
+	}
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	now := s.now().UTC()
+	if err != nil {
+		note.Status = StatusFailed
+		note.Error = err.Error()
+		s.logger.Warn("voice note transcription failed", slog.String("note", id), slog.Any("error", err))
+	} else {
+		note.Status = StatusTranscribed
+		note.Error = ""
+		note.TranscribedAt = &now
+	}
+	if err := s.store.SaveNote(note); err != nil {
+		s.logger.Error("save voice note", slog.String("note", id), slog.Any("error", err))
+		return
+	}
+
+	if note.Status == StatusFailed {
+		s.record(note, EventFailed, note.Error)
+		return
+	}
+	s.index.Put(IndexEntry{
+		ID:           note.ID,
+		JobID:        note.JobID,
+		TechnicianID: note.TechnicianID,
+		Source:       SourceVoiceNote,
+		Text:         note.Transcript,
+		CreatedAt:    note.CreatedAt,
+	})
+	s.record(note, EventTranscribed, note.Transcript)
+}
+
+func (s *Service) record(note Note, event, detail string) {
+	entry := HistoryEntry{JobID: note.JobID, At: s.now().UTC(), Event: event, NoteID: note.ID, Detail: detail}
+	if err := s.store.AppendHistory(entry); err != nil {
+		s.logger.Warn("append job history", slog.String("job", note.JobID), slog.Any("error", err))
+	}
+}
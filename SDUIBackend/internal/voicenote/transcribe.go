@@ -0,0 +1,83 @@
+package voicenote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Transcriber converts recorded speech to text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio []byte, contentType string) (string, error)
+}
+
+// NewTranscriber returns the transcriber selected by cfg, or nil when
+// transcription is disabled.
+func NewTranscriber(cfg config.VoiceNoteConfig, secrets secret.Provider) Transcriber {
+	switch cfg.Transcriber {
+	case "http":
+		return HTTPTranscriber{
+			URL:       cfg.TranscriberURL,
+			KeySecret: cfg.TranscriberKey,
+			Secrets:   secrets,
+			Client:    &http.Client{Timeout: cfg.RequestTimeout},
+		}
+	default:
+		return nil
+	}
+}
+
+// HTTPTranscriber posts raw audio to a speech-to-text endpoint and expects a
+// JSON response of the form {"text": "..."}. Most hosted providers can sit
+// behind a thin adapter speaking this contract.
+type HTTPTranscriber struct {
+	URL       string
+	KeySecret string // secret name of the bearer token; empty sends none
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (t HTTPTranscriber) Transcribe(ctx context.Context, audio []byte, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(audio))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if t.KeySecret != "" {
+		key, err := t.Secrets.Get(t.KeySecret)
+		if err != nil {
+			return "", fmt.Errorf("transcriber key: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	client := t.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return "", fmt.Errorf("transcriber responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var body struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode transcript: %w", err)
+	}
+	return strings.TrimSpace(body.Text), nil
+}
@@ -0,0 +1,145 @@
+package voicenote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+type fakeTranscriber struct {
+	text string
+	err  error
+}
+
+func (f fakeTranscriber) Transcribe(context.Context, []byte, string) (string, error) {
+	return f.text, f.err
+}
+
+func newTestService(transcriber Transcriber) *Service {
+	svc := NewService(config.VoiceNoteConfig{MaxBytes: 1024, QueueSize: 10}, NewMemoryStore(), NewIndex(), transcriber, nil)
+	svc.now = func() time.Time { return time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+// drain transcribes everything queued so far.
+func drain(svc *Service) {
+	for {
+		select {
+		case id := <-svc.queue:
+			svc.transcribe(context.Background(), id)
+		default:
+			return
+		}
+	}
+}
+
+func TestTranscriptIsIndexedAndRecorded(t *testing.T) {
+	svc := newTestService(fakeTranscriber{text: "Found termite damage near the back porch"})
+	note, err := svc.Upload(Upload{JobID: "job-1", TechnicianID: "tech-1", ContentType: "audio/m4a"}, []byte("audio"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if note.Status != StatusPending {
+		t.Fatalf("expected pending note, got %s", note.Status)
+	}
+	drain(svc)
+
+	got, _ := svc.Note("job-1", note.ID)
+	if got.Status != StatusTranscribed || got.TranscribedAt == nil {
+		t.Fatalf("expected transcribed note, got %+v", got)
+	}
+	hits := svc.Search("termite porch", "", 10)
+	if len(hits) != 1 || hits[0].JobID != "job-1" || hits[0].Source != SourceVoiceNote {
+		t.Fatalf("unexpected hits %+v", hits)
+	}
+	if hits := svc.Search("termite", "tech-2", 10); len(hits) != 0 {
+		t.Fatalf("expected technician filter to exclude hit, got %+v", hits)
+	}
+	if hits := svc.Search("termite roof", "", 10); len(hits) != 0 {
+		t.Fatalf("expected all terms to be required, got %+v", hits)
+	}
+
+	history, _ := svc.History("job-1")
+	if len(history) != 2 || history[0].Event != EventUploaded || history[1].Event != EventTranscribed {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
+
+func TestFailedTranscriptionCanBeRetried(t *testing.T) {
+	svc := newTestService(fakeTranscriber{err: errors.New("provider unavailable")})
+	note, _ := svc.Upload(Upload{JobID: "job-1", TechnicianID: "tech-1", ContentType: "audio/wav"}, []byte("audio"))
+	drain(svc)
+	if got, _ := svc.Note("job-1", note.ID); got.Status != StatusFailed || got.Error == "" {
+		t.Fatalf("expected failed note, got %+v", got)
+	}
+
+	svc.transcriber = fakeTranscriber{text: "second try"}
+	if _, err := svc.Retranscribe(note.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	drain(svc)
+	if got, _ := svc.Note("job-1", note.ID); got.Status != StatusTranscribed || got.Transcript != "second try" {
+		t.Fatalf("expected transcribed note, got %+v", got)
+	}
+}
+
+func TestUploadWithoutTranscriber(t *testing.T) {
+	svc := newTestService(nil)
+	note, err := svc.Upload(Upload{JobID: "job-1", TechnicianID: "tech-1", ContentType: "audio/wav"}, []byte("audio"))
+	if err != nil || note.Status != StatusNotRequested {
+		t.Fatalf("unexpected note %+v (%v)", note, err)
+	}
+	if _, err := svc.Retranscribe(note.ID); !errors.Is(err, ErrInvalidNote) {
+		t.Fatalf("expected invalid note, got %v", err)
+	}
+	if _, err := svc.Upload(Upload{JobID: "job-1", TechnicianID: "tech-1", ContentType: "text/plain"}, []byte("x")); !errors.Is(err, ErrInvalidNote) {
+		t.Fatalf("expected invalid note, got %v", err)
+	}
+}
+
+func TestUploadAndPlaybackOverHTTP(t *testing.T) {
+	h := NewHandler(newTestService(nil))
+	r := chi.NewRouter()
+	r.Post("/jobs/{jobId}/voice-notes", h.UploadNote)
+	r.Get("/jobs/{jobId}/voice-notes/{noteId}/audio", h.GetAudio)
+
+	req := httptest.NewRequest(http.MethodPost, "/jobs/job-1/voice-notes?technicianId=tech-1&durationSeconds=4.5", strings.NewReader("RIFF"))
+	req.Header.Set("Content-Type", "audio/wav")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	notes, _ := h.service.Notes("job-1")
+	if len(notes) != 1 || notes[0].DurationSeconds != 4.5 {
+		t.Fatalf("unexpected notes %+v", notes)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/voice-notes/"+notes[0].ID+"/audio", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "RIFF" || rec.Header().Get("Content-Type") != "audio/wav" {
+		t.Fatalf("unexpected playback %d %q", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-2/voice-notes/"+notes[0].ID+"/audio", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for another job, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/jobs/job-1/voice-notes?technicianId=tech-1", strings.NewReader(strings.Repeat("a", 2048)))
+	req.Header.Set("Content-Type", "audio/wav")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,250 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/secret"
+)
+
+// Public endpoints of the supported providers.
+const (
+	OpenWeatherURL = "https://api.openweathermap.org/data/2.5"
+	NWSURL         = "https://api.weather.gov"
+)
+
+// Conditions are the weather at a place and time, in metric units.
+type Conditions struct {
+	TemperatureC float64 `json:"temperatureC"`
+	WindSpeedKPH float64 `json:"windSpeedKph"`
+	WindGustKPH  float64 `json:"windGustKph,omitempty"`
+	Summary      string  `json:"summary,omitempty"`
+	// At is when the conditions were observed or forecast for.
+	At time.Time `json:"at"`
+}
+
+// Provider reports the weather at a place, observed or forecast for the
+// time closest to at.
+type Provider interface {
+	Name() string
+	Conditions(ctx context.Context, lat, lng float64, at time.Time) (Conditions, error)
+}
+
+// NewProvider returns the provider selected by cfg, or nil when weather is
+// disabled.
+func NewProvider(cfg config.WeatherConfig, secrets secret.Provider) Provider {
+	client := &http.Client{Timeout: cfg.RequestTimeout}
+	switch cfg.Provider {
+	case "openweather":
+		u := cfg.URL
+		if u == "" {
+			u = OpenWeatherURL
+		}
+		return OpenWeather{URL: strings.TrimRight(u, "/"), KeySecret: cfg.KeySecret, Secrets: secrets, Client: client}
+	case "nws":
+		u := cfg.URL
+		if u == "" {
+			u = NWSURL
+		}
+		return NWS{URL: strings.TrimRight(u, "/"), Client: client}
+	default:
+		return nil
+	}
+}
+
+// currentWindow is how far ahead a time can be for current conditions to
+// stand in for it rather than a forecast.
+const currentWindow = time.Hour
+
+// OpenWeather calls the OpenWeather current weather and 5 day forecast
+// APIs.
+type OpenWeather struct {
+	URL       string
+	KeySecret string // secret name of the API key
+	Secrets   secret.Provider
+	Client    *http.Client
+}
+
+func (OpenWeather) Name() string { return "openweather" }
+
+// owReading is a current observation or forecast entry.
+type owReading struct {
+	Dt   int64 `json:"dt"`
+	Main struct {
+		Temp float64 `json:"temp"`
+	} `json:"main"`
+	Wind struct {
+		Speed float64 `json:"speed"` // m/s in metric units
+		Gust  float64 `json:"gust"`
+	} `json:"wind"`
+	Weather []struct {
+		Description string `json:"description"`
+	} `json:"weather"`
+}
+
+func (r owReading) conditions() Conditions {
+	c := Conditions{
+		TemperatureC: r.Main.Temp,
+		WindSpeedKPH: r.Wind.Speed * 3.6,
+		WindGustKPH:  r.Wind.Gust * 3.6,
+		At:           time.Unix(r.Dt, 0).UTC(),
+	}
+	if len(r.Weather) > 0 {
+		c.Summary = r.Weather[0].Description
+	}
+	return c
+}
+
+func (o OpenWeather) Conditions(ctx context.Context, lat, lng float64, at time.Time) (Conditions, error) {
+	key, err := o.Secrets.Get(o.KeySecret)
+	if err != nil {
+		return Conditions{}, fmt.Errorf("weather key: %w", err)
+	}
+	q := url.Values{}
+	q.Set("lat", strconv.FormatFloat(lat, 'f', 4, 64))
+	q.Set("lon", strconv.FormatFloat(lng, 'f', 4, 64))
+	q.Set("units", "metric")
+	q.Set("appid", key)
+
+	if at.Before(time.Now().Add(currentWindow)) {
+		var current owReading
+		if err := get(ctx, o.Client, o.URL+"/weather?"+q.Encode(), &current); err != nil {
+			return Conditions{}, err
+		}
+		return current.conditions(), nil
+	}
+	var forecast struct {
+		List []owReading `json:"list"`
+	}
+	if err := get(ctx, o.Client, o.URL+"/forecast?"+q.Encode(), &forecast); err != nil {
+		return Conditions{}, err
+	}
+	if len(forecast.List) == 0 {
+		return Conditions{}, fmt.Errorf("weather forecast is empty")
+	}
+	best := forecast.List[0]
+	for _, r := range forecast.List[1:] {
+		if distance(time.Unix(r.Dt, 0), at) < distance(time.Unix(best.Dt, 0), at) {
+			best = r
+		}
+	}
+	return best.conditions(), nil
+}
+
+// NWS calls the US National Weather Service API, which needs no key but
+// only covers the United States. It forecasts hourly from the current hour,
+// so earlier times get the current hour's forecast.
+type NWS struct {
+	URL    string
+	Client *http.Client
+}
+
+func (NWS) Name() string { return "nws" }
+
+// nwsSpeed matches the numbers in wind speeds like "5 to 10 mph".
+var nwsSpeed = regexp.MustCompile(`\d+(\.\d+)?`)
+
+func (n NWS) Conditions(ctx context.Context, lat, lng float64, at time.Time) (Conditions, error) {
+	var point struct {
+		Properties struct {
+			ForecastHourly string `json:"forecastHourly"`
+		} `json:"properties"`
+	}
+	if err := get(ctx, n.Client, fmt.Sprintf("%s/points/%.4f,%.4f", n.URL, lat, lng), &point); err != nil {
+		return Conditions{}, err
+	}
+	if point.Properties.ForecastHourly == "" {
+		return Conditions{}, fmt.Errorf("weather point has no hourly forecast")
+	}
+	var forecast struct {
+		Properties struct {
+			Periods []struct {
+				StartTime       time.Time `json:"startTime"`
+				Temperature     float64   `json:"temperature"`
+				TemperatureUnit string    `json:"temperatureUnit"`
+				WindSpeed       string    `json:"windSpeed"`
+				WindGust        string    `json:"windGust"`
+				ShortForecast   string    `json:"shortForecast"`
+			} `json:"periods"`
+		} `json:"properties"`
+	}
+	if err := get(ctx, n.Client, point.Properties.ForecastHourly, &forecast); err != nil {
+		return Conditions{}, err
+	}
+	periods := forecast.Properties.Periods
+	if len(periods) == 0 {
+		return Conditions{}, fmt.Errorf("weather forecast is empty")
+	}
+	best := periods[0]
+	for _, p := range periods[1:] {
+		if distance(p.StartTime, at) < distance(best.StartTime, at) {
+			best = p
+		}
+	}
+	temp := best.Temperature
+	if strings.EqualFold(best.TemperatureUnit, "F") {
+		temp = (temp - 32) * 5 / 9
+	}
+	return Conditions{
+		TemperatureC: temp,
+		WindSpeedKPH: mphToKPH(best.WindSpeed),
+		WindGustKPH:  mphToKPH(best.WindGust),
+		Summary:      best.ShortForecast,
+		At:           best.StartTime.UTC(),
+	}, nil
+}
+
+// mphToKPH converts the fastest speed in an NWS wind speed to km/h.
+func mphToKPH(s string) float64 {
+	fastest := 0.0
+	for _, m := range nwsSpeed.FindAllString(s, -1) {
+		if v, err := strconv.ParseFloat(m, 64); err == nil && v > fastest {
+			fastest = v
+		}
+	}
+	return fastest * 1.609344
+}
+
+func distance(a, b time.Time) time.Duration {
+	d := a.Sub(b)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func get(ctx context.Context, client *http.Client, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	// The NWS API requires an identifying user agent.
+	req.Header.Set("User-Agent", "pestgenie-sdui")
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("weather provider responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode weather response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Package weather fetches spray conditions for route stops and treatments.
+// Technicians type the weather into their treatment logs; the server adds
+// what a provider reported for the stop and time, and warns on route
+// screens when wind or temperature is outside what most labels allow.
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+// AlertType is the route alert type weather warnings are added as.
+const AlertType = "weather"
+
+// ErrDisabled is returned by a nil Service.
+var ErrDisabled = errors.New("weather is disabled")
+
+const (
+	// horizon is how far ahead conditions are fetched; providers do not
+	// forecast much further.
+	horizon = 5 * 24 * time.Hour
+	// maxAge is how long ago a treatment can have been applied for
+	// current conditions to still describe it.
+	maxAge = 3 * time.Hour
+	// routeBudget bounds the lookups for one route, so a slow provider
+	// delays a screen by at most this long.
+	routeBudget = 2 * time.Second
+)
+
+// Service looks up and caches conditions and turns them into warnings. A
+// nil *Service fetches nothing.
+type Service struct {
+	cfg      config.WeatherConfig
+	provider Provider
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedConditions
+}
+
+type cachedConditions struct {
+	conditions Conditions
+	storedAt   time.Time
+}
+
+// NewService wires a weather service fetching from provider. It returns
+// nil when provider is nil, that is when weather is disabled.
+func NewService(cfg config.WeatherConfig, provider Provider, logger *slog.Logger) *Service {
+	if provider == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{cfg: cfg, provider: provider, logger: logger, now: time.Now, cache: make(map[string]cachedConditions)}
+}
+
+// Conditions returns the weather at a place and time. Lookups for places
+// within about a kilometre and the same hour share a cached answer.
+func (s *Service) Conditions(ctx context.Context, lat, lng float64, at time.Time) (Conditions, error) {
+	if s == nil {
+		return Conditions{}, ErrDisabled
+	}
+	key := fmt.Sprintf("%.2f,%.2f,%d", lat, lng, at.Truncate(time.Hour).Unix())
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && s.now().Sub(entry.storedAt) < s.cfg.CacheTTL {
+		return entry.conditions, nil
+	}
+	c, err := s.provider.Conditions(ctx, lat, lng, at)
+	if err != nil {
+		return Conditions{}, err
+	}
+	s.mu.Lock()
+	s.cache[key] = cachedConditions{conditions: c, storedAt: s.now()}
+	s.mu.Unlock()
+	return c, nil
+}
+
+// Warnings describes what in c is outside the configured spraying limits.
+func (s *Service) Warnings(c Conditions) []string {
+	if s == nil {
+		return nil
+	}
+	var out []string
+	if wind := math.Max(c.WindSpeedKPH, c.WindGustKPH); wind >= float64(s.cfg.MaxWindKPH) {
+		out = append(out, fmt.Sprintf("High wind %.0f km/h — avoid spraying", wind))
+	}
+	switch {
+	case c.TemperatureC > float64(s.cfg.MaxTemperatureC):
+		out = append(out, fmt.Sprintf("%.0f°C — above %d°C, check label temperature limits", c.TemperatureC, s.cfg.MaxTemperatureC))
+	case c.TemperatureC < float64(s.cfg.MinTemperatureC):
+		out = append(out, fmt.Sprintf("%.0f°C — below %d°C, check label temperature limits", c.TemperatureC, s.cfg.MinTemperatureC))
+	}
+	return out
+}
+
+// RouteAlerts returns a weather alert for each warning at each geocoded
+// stop of route, for the stop's service window. Stops the provider cannot
+// answer for in time are left out.
+func (s *Service) RouteAlerts(ctx context.Context, route domain.Route, serviceDate time.Time) []domain.RouteAlert {
+	if s == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, routeBudget)
+	defer cancel()
+
+	now := s.now()
+	alerts := make([][]domain.RouteAlert, len(route.CustomerStops))
+	var wg sync.WaitGroup
+	for i, stop := range route.CustomerStops {
+		at := stopTime(stop, serviceDate, now)
+		if !stop.HasCoordinates() || at.Before(now.Add(-maxAge)) || at.After(now.Add(horizon)) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, stop domain.RouteStop) {
+			defer wg.Done()
+			c, err := s.Conditions(ctx, stop.Latitude, stop.Longitude, at)
+			if err != nil {
+				s.logger.Warn("weather lookup failed", slog.String("route", route.ServerID()), slog.String("customer", stop.CustomerID), slog.Any("error", err))
+				return
+			}
+			for _, w := range s.Warnings(c) {
+				alerts[i] = append(alerts[i], domain.RouteAlert{Type: AlertType, Message: stopName(stop) + ": " + w, Severity: "warning"})
+			}
+		}(i, stop)
+	}
+	wg.Wait()
+
+	var out []domain.RouteAlert
+	for _, a := range alerts {
+		out = append(out, a...)
+	}
+	return out
+}
+
+// Treatment returns the conditions at the geocoded stop of route being
+// serviced at the treatment's application time, and their warnings. It
+// returns nil when there is no such stop or the application was too long
+// ago for current conditions to describe it.
+func (s *Service) Treatment(ctx context.Context, route domain.Route, appliedAt time.Time) (*domain.WeatherReading, []string) {
+	if s == nil || appliedAt.IsZero() {
+		return nil, nil
+	}
+	now := s.now()
+	if appliedAt.Before(now.Add(-maxAge)) || appliedAt.After(now.Add(currentWindow)) {
+		return nil, nil
+	}
+	var best *domain.RouteStop
+	for i, stop := range route.CustomerStops {
+		if !stop.HasCoordinates() {
+			continue
+		}
+		if best == nil || distance(stopTime(stop, route.ServiceDate, now), appliedAt) < distance(stopTime(*best, route.ServiceDate, now), appliedAt) {
+			best = &route.CustomerStops[i]
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	c, err := s.Conditions(ctx, best.Latitude, best.Longitude, appliedAt)
+	if err != nil {
+		s.logger.Warn("weather lookup failed", slog.String("route", route.ServerID()), slog.String("customer", best.CustomerID), slog.Any("error", err))
+		return nil, nil
+	}
+	reading := &domain.WeatherReading{
+		Source:       s.provider.Name(),
+		TemperatureC: c.TemperatureC,
+		WindSpeedKPH: c.WindSpeedKPH,
+		WindGustKPH:  c.WindGustKPH,
+		Summary:      c.Summary,
+		At:           c.At,
+	}
+	return reading, s.Warnings(c)
+}
+
+// stopTime is when a stop is serviced: the middle of its window, its start,
+// or midday on the service date when it has no window.
+func stopTime(stop domain.RouteStop, serviceDate, now time.Time) time.Time {
+	switch {
+	case !stop.WindowStart.IsZero() && !stop.WindowEnd.IsZero():
+		return stop.WindowStart.Add(stop.WindowEnd.Sub(stop.WindowStart) / 2)
+	case !stop.WindowStart.IsZero():
+		return stop.WindowStart
+	case !serviceDate.IsZero():
+		y, m, d := serviceDate.Date()
+		return time.Date(y, m, d, 12, 0, 0, 0, serviceDate.Location())
+	default:
+		return now
+	}
+}
+
+func stopName(stop domain.RouteStop) string {
+	switch {
+	case stop.Nickname != "":
+		return stop.Nickname
+	case stop.CustomerName != "":
+		return stop.CustomerName
+	default:
+		return stop.Address
+	}
+}
@@ -0,0 +1,171 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	v, ok := s[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+func TestOpenWeather(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "k" || r.URL.Query().Get("units") != "metric" {
+			t.Errorf("expected the API key and metric units, got %q", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/weather":
+			fmt.Fprintf(w, `{"dt":%d,"main":{"temp":21.5},"wind":{"speed":5,"gust":8},"weather":[{"description":"clear sky"}]}`, now.Unix())
+		case "/forecast":
+			fmt.Fprintf(w, `{"list":[{"dt":%d,"main":{"temp":10},"wind":{"speed":1}},{"dt":%d,"main":{"temp":30},"wind":{"speed":2},"weather":[{"description":"light rain"}]}]}`,
+				now.Add(24*time.Hour).Unix(), now.Add(48*time.Hour).Unix())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	p := NewProvider(config.WeatherConfig{Provider: "openweather", URL: srv.URL, KeySecret: "ow"}, staticSecrets{"ow": "k"})
+	ctx := context.Background()
+
+	c, err := p.Conditions(ctx, 30.27, -97.74, now.Add(-time.Hour))
+	if err != nil || c.TemperatureC != 21.5 || c.WindSpeedKPH != 18 || math.Abs(c.WindGustKPH-28.8) > 1e-9 || c.Summary != "clear sky" {
+		t.Fatalf("unexpected current conditions %+v (%v)", c, err)
+	}
+	c, err = p.Conditions(ctx, 30.27, -97.74, now.Add(47*time.Hour))
+	if err != nil || c.TemperatureC != 30 || c.Summary != "light rain" || !c.At.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("expected the closest forecast entry, got %+v (%v)", c, err)
+	}
+}
+
+func TestNWS(t *testing.T) {
+	start := time.Date(2026, 6, 2, 14, 0, 0, 0, time.UTC)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("expected an identifying user agent")
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/points/"):
+			if r.URL.Path != "/points/30.2700,-97.7400" {
+				t.Errorf("unexpected point %s", r.URL.Path)
+			}
+			fmt.Fprintf(w, `{"properties":{"forecastHourly":"%s/gridpoints/EWX/156,91/forecast/hourly"}}`, srv.URL)
+		case strings.HasSuffix(r.URL.Path, "/forecast/hourly"):
+			fmt.Fprintf(w, `{"properties":{"periods":[
+				{"startTime":"%s","temperature":68,"temperatureUnit":"F","windSpeed":"5 mph","shortForecast":"Sunny"},
+				{"startTime":"%s","temperature":95,"temperatureUnit":"F","windSpeed":"10 to 15 mph","windGust":"25 mph","shortForecast":"Hot"}]}}`,
+				start.Format(time.RFC3339), start.Add(time.Hour).Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	p := NewProvider(config.WeatherConfig{Provider: "nws", URL: srv.URL}, nil)
+
+	c, err := p.Conditions(context.Background(), 30.27, -97.74, start.Add(50*time.Minute))
+	if err != nil {
+		t.Fatalf("conditions: %v", err)
+	}
+	if c.Summary != "Hot" || math.Abs(c.TemperatureC-35) > 1e-9 || math.Abs(c.WindSpeedKPH-15*1.609344) > 1e-9 || math.Abs(c.WindGustKPH-25*1.609344) > 1e-9 {
+		t.Fatalf("expected the closest hour converted to metric, got %+v", c)
+	}
+	if NewProvider(config.WeatherConfig{Provider: "none"}, nil) != nil {
+		t.Error("expected no provider when weather is disabled")
+	}
+}
+
+// stubProvider reports fixed conditions and counts lookups.
+type stubProvider struct {
+	conditions Conditions
+	calls      atomic.Int32
+}
+
+func (*stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Conditions(_ context.Context, _, _ float64, at time.Time) (Conditions, error) {
+	p.calls.Add(1)
+	c := p.conditions
+	c.At = at
+	return c, nil
+}
+
+func newTestService(p Provider) *Service {
+	cfg := config.WeatherConfig{MaxWindKPH: 16, MinTemperatureC: 4, MaxTemperatureC: 32, CacheTTL: time.Hour}
+	svc := NewService(cfg, p, nil)
+	svc.now = func() time.Time { return time.Date(2026, 6, 2, 8, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestWarnings(t *testing.T) {
+	svc := newTestService(&stubProvider{})
+	for _, tc := range []struct {
+		c    Conditions
+		want []string
+	}{
+		{Conditions{TemperatureC: 20, WindSpeedKPH: 10}, nil},
+		{Conditions{TemperatureC: 20, WindSpeedKPH: 10, WindGustKPH: 20}, []string{"High wind 20 km/h — avoid spraying"}},
+		{Conditions{TemperatureC: 35, WindSpeedKPH: 16}, []string{"High wind 16 km/h — avoid spraying", "35°C — above 32°C, check label temperature limits"}},
+		{Conditions{TemperatureC: 1}, []string{"1°C — below 4°C, check label temperature limits"}},
+	} {
+		if got := svc.Warnings(tc.c); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("warnings for %+v: expected %q, got %q", tc.c, tc.want, got)
+		}
+	}
+	var disabled *Service
+	if disabled.Warnings(Conditions{WindSpeedKPH: 99}) != nil || disabled.RouteAlerts(context.Background(), domain.Route{}, time.Time{}) != nil {
+		t.Error("expected a nil service to warn about nothing")
+	}
+}
+
+func TestRouteAlertsAndTreatment(t *testing.T) {
+	provider := &stubProvider{conditions: Conditions{TemperatureC: 20, WindSpeedKPH: 25}}
+	svc := newTestService(provider)
+	day := time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)
+	route := domain.Route{ID: "r1", ServiceDate: day, CustomerStops: []domain.RouteStop{
+		{CustomerID: "c1", CustomerName: "Miller", Nickname: "the barn", WindowStart: day.Add(9 * time.Hour), WindowEnd: day.Add(10 * time.Hour), Latitude: 30.27, Longitude: -97.74},
+		{CustomerID: "c2", CustomerName: "Not geocoded"},
+		{CustomerID: "c3", CustomerName: "Next door", WindowStart: day.Add(9 * time.Hour), Latitude: 30.35, Longitude: -97.8},
+	}}
+
+	alerts := svc.RouteAlerts(context.Background(), route, day)
+	if len(alerts) != 2 || alerts[0].Type != AlertType || alerts[0].Message != "the barn: High wind 25 km/h — avoid spraying" || alerts[1].Message != "Next door: High wind 25 km/h — avoid spraying" {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	if calls := provider.calls.Load(); calls != 2 {
+		t.Fatalf("expected one lookup per geocoded stop, got %d", calls)
+	}
+	if alerts := svc.RouteAlerts(context.Background(), route, day); len(alerts) != 2 || provider.calls.Load() != 2 {
+		t.Fatalf("expected the stops served from the cache, got %d calls", provider.calls.Load())
+	}
+	if alerts := svc.RouteAlerts(context.Background(), domain.Route{ServiceDate: day.AddDate(0, 0, 10), CustomerStops: route.CustomerStops[:1]}, day.AddDate(0, 0, 10)); len(alerts) != 1 {
+		// The stop's window, not the far-off service date, decides.
+		t.Fatalf("expected the stop window used, got %+v", alerts)
+	}
+
+	reading, warnings := svc.Treatment(context.Background(), route, day.Add(7*time.Hour+30*time.Minute))
+	if reading == nil || reading.Source != "stub" || reading.WindSpeedKPH != 25 || len(warnings) != 1 {
+		t.Fatalf("unexpected treatment weather %+v %q", reading, warnings)
+	}
+	if reading, _ := svc.Treatment(context.Background(), route, day.Add(-24*time.Hour)); reading != nil {
+		t.Fatalf("expected no reading for a treatment applied long ago, got %+v", reading)
+	}
+}
@@ -0,0 +1,63 @@
+package widget
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes the widget timeline to the app.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a widget handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetTimeline returns the authenticated technician's timeline for
+// ?serviceDate=, today by default. Responses are private, cacheable until
+// the suggested reload, and answered with 304 when the widget already has
+// the same content.
+func (h *Handler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	me := auth.TechnicianID(r)
+	if me == "" {
+		respond.Error(w, http.StatusBadRequest, "missing technician", "authenticate with a bearer token or pass userId")
+		return
+	}
+	var date time.Time
+	if v := r.URL.Query().Get("serviceDate"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, "invalid serviceDate", "expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+	t, err := h.service.Timeline(r.Context(), me, date)
+	if err != nil {
+		middleware.LoggerFrom(r.Context()).Error("failed to load widget timeline", slog.String("technician", me), slog.Any("error", err))
+		respond.Error(w, http.StatusInternalServerError, "failed to load widget timeline", "temporary error, please retry", respond.WithCause(err))
+		return
+	}
+
+	etag := t.ETag()
+	maxAge := int(t.ReloadAfter.Sub(h.service.now()).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	respond.JSON(w, http.StatusOK, t)
+}
@@ -0,0 +1,177 @@
+package widget
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/tenant"
+)
+
+// Job statuses, as uploaded by the app, that the widget tells apart.
+var (
+	startedStatuses = map[string]bool{"inProgress": true, "in_progress": true}
+	doneStatuses    = map[string]bool{"completed": true, "skipped": true}
+)
+
+// Service builds widget timelines from routes and uploaded job progress.
+type Service struct {
+	cfg   config.WidgetConfig
+	repos repository.Repository
+	now   func() time.Time
+}
+
+// NewService wires a widget service over the routes and job uploads in
+// repos.
+func NewService(cfg config.WidgetConfig, repos repository.Repository) *Service {
+	return &Service{cfg: cfg, repos: repos, now: time.Now}
+}
+
+// Timeline returns the technician's timeline for date, today when zero. A
+// technician without a route that day gets an empty timeline. Reads are
+// scoped to the tenant ctx acts for.
+func (s *Service) Timeline(ctx context.Context, technicianID string, date time.Time) (Timeline, error) {
+	repos := tenant.Repository(ctx, s.repos)
+	now := s.now().UTC()
+	if date.IsZero() {
+		date = now
+	}
+	day := startOfDay(date)
+	t := Timeline{ServiceDate: day.Format(time.DateOnly), Stops: []Stop{}, RefreshAt: []time.Time{}}
+
+	route, err := repos.Routes.GetRoute(technicianID, day)
+	if err != nil || len(route.CustomerStops) == 0 {
+		t.ReloadAfter = now.Add(s.cfg.MaxRefresh)
+		return t, nil
+	}
+	stops := append([]domain.RouteStop(nil), route.CustomerStops...)
+	sort.SliceStable(stops, func(i, j int) bool {
+		if stops[i].WindowStart.IsZero() || stops[j].WindowStart.IsZero() {
+			return false
+		}
+		return stops[i].WindowStart.Before(stops[j].WindowStart)
+	})
+	statuses, err := s.statuses(repos.Sync, technicianID, day, stops)
+	if err != nil {
+		return Timeline{}, err
+	}
+
+	t.Counts.Total = len(stops)
+	var ahead []Stop
+	inProgress := false
+	for i, stop := range stops {
+		switch statuses[i] {
+		case "completed":
+			t.Counts.Completed++
+			continue
+		case "skipped":
+			t.Counts.Skipped++
+			continue
+		}
+		t.Counts.Remaining++
+		w := Stop{CustomerName: stop.CustomerName, Address: stop.Address, Priority: stop.Priority, Status: StatusUpcoming}
+		if !stop.WindowStart.IsZero() {
+			start := stop.WindowStart.UTC()
+			w.WindowStart = &start
+		}
+		if !stop.WindowEnd.IsZero() {
+			end := stop.WindowEnd.UTC()
+			w.WindowEnd = &end
+		}
+		switch {
+		case statuses[i] == StatusInProgress:
+			w.Status = StatusInProgress
+			inProgress = true
+		case w.WindowEnd != nil && !w.WindowEnd.After(now):
+			w.Status = StatusOverdue
+		}
+		ahead = append(ahead, w)
+	}
+	// The stop being worked is what the technician wants to see first.
+	sort.SliceStable(ahead, func(i, j int) bool {
+		return ahead[i].Status == StatusInProgress && ahead[j].Status != StatusInProgress
+	})
+	if len(ahead) > s.cfg.MaxStops {
+		ahead = ahead[:s.cfg.MaxStops]
+	}
+	t.Stops = append(t.Stops, ahead...)
+	t.RefreshAt = refreshTimes(ahead, now)
+	t.ReloadAfter = s.reloadAfter(t, inProgress, now)
+	return t, nil
+}
+
+// statuses returns the latest uploaded status of each stop, matched to the
+// technician's jobs for the day by customer name or address, in
+// stop order. Stops without a job, or with a job not yet started, are "".
+func (s *Service) statuses(uploads repository.SyncRepository, technicianID string, day time.Time, stops []domain.RouteStop) ([]string, error) {
+	jobs, err := uploads.ListJobUpdatesSince(day)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].ReceivedAt.Before(jobs[j].ReceivedAt) })
+	out := make([]string, len(stops))
+	for _, job := range jobs {
+		if job.TechnicianID != technicianID || (!job.ScheduledDate.IsZero() && !startOfDay(job.ScheduledDate).Equal(day)) {
+			continue
+		}
+		status := ""
+		if doneStatuses[job.Status] {
+			status = job.Status
+		} else if startedStatuses[job.Status] {
+			status = StatusInProgress
+		}
+		for i, stop := range stops {
+			if strings.EqualFold(stop.CustomerName, job.CustomerName) || (job.Address != "" && strings.EqualFold(stop.Address, job.Address)) {
+				out[i] = status
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// refreshTimes lists the window openings and closings of stops still to
+// come, in order.
+func refreshTimes(stops []Stop, now time.Time) []time.Time {
+	seen := make(map[time.Time]bool)
+	out := []time.Time{}
+	for _, stop := range stops {
+		for _, at := range []*time.Time{stop.WindowStart, stop.WindowEnd} {
+			if at != nil && at.After(now) && !seen[*at] {
+				seen[*at] = true
+				out = append(out, *at)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// reloadAfter suggests when to fetch again: soon while a job is being
+// worked, since it may finish at any moment, otherwise when the next window
+// opens, within the configured bounds.
+func (s *Service) reloadAfter(t Timeline, inProgress bool, now time.Time) time.Time {
+	wait := s.cfg.MaxRefresh
+	switch {
+	case inProgress:
+		wait = s.cfg.MinRefresh
+	case len(t.RefreshAt) > 0:
+		wait = t.RefreshAt[0].Sub(now)
+	}
+	if wait < s.cfg.MinRefresh {
+		wait = s.cfg.MinRefresh
+	}
+	if wait > s.cfg.MaxRefresh {
+		wait = s.cfg.MaxRefresh
+	}
+	return now.Add(wait)
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,62 @@
+// Package widget serves the iOS home-screen widget a compact timeline of
+// the technician's day: the next few stops, how many are done and left,
+// and when the widget should redraw and reload. It is much smaller than
+// the screen payload and changes only when the route or job progress does,
+// so responses carry an ETag and a max-age matching the suggested reload.
+package widget
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Stop statuses shown on the widget.
+const (
+	StatusInProgress = "in_progress"
+	StatusUpcoming   = "upcoming"
+	// StatusOverdue is a stop whose window closed before it was finished.
+	StatusOverdue = "overdue"
+)
+
+// Timeline is the widget's payload for one service date.
+type Timeline struct {
+	ServiceDate string `json:"serviceDate"`
+	Counts      Counts `json:"counts"`
+	// Stops are the next unfinished stops, the one in progress first.
+	Stops []Stop `json:"stops"`
+	// RefreshAt are the moments the listed stops' windows open or close,
+	// for the widget's timeline entries: the display changes then without
+	// new data.
+	RefreshAt []time.Time `json:"refreshAt"`
+	// ReloadAfter is when the widget should fetch the timeline again.
+	ReloadAfter time.Time `json:"reloadAfter"`
+}
+
+// Counts summarises the day's stops.
+type Counts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Skipped   int `json:"skipped"`
+	Remaining int `json:"remaining"`
+}
+
+// Stop is one upcoming stop.
+type Stop struct {
+	CustomerName string     `json:"customerName"`
+	Address      string     `json:"address,omitempty"`
+	WindowStart  *time.Time `json:"windowStart,omitempty"`
+	WindowEnd    *time.Time `json:"windowEnd,omitempty"`
+	Priority     string     `json:"priority,omitempty"`
+	Status       string     `json:"status"`
+}
+
+// ETag identifies the timeline's content. ReloadAfter is left out: it moves
+// with the clock while what the widget shows stays the same.
+func (t Timeline) ETag() string {
+	t.ReloadAfter = time.Time{}
+	body, _ := json.Marshal(t)
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
@@ -0,0 +1,78 @@
+package widget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/auth"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+var day = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time {
+	return day.Add(time.Duration(hour) * time.Hour)
+}
+
+func TestTimelineListsWhatIsLeft(t *testing.T) {
+	store := storememory.NewStore()
+	_ = store.SaveRoute(domain.Route{
+		TechnicianID: "tech-1",
+		ServiceDate:  day,
+		CustomerStops: []domain.RouteStop{
+			{CustomerName: "Fourth", WindowStart: at(14), WindowEnd: at(15)},
+			{CustomerName: "First", WindowStart: at(8), WindowEnd: at(9)},
+			{CustomerName: "Second", WindowStart: at(9), WindowEnd: at(10)},
+			{CustomerName: "Third", Address: "3 Pine St", WindowStart: at(11), WindowEnd: at(12)},
+			{CustomerName: "Fifth", WindowStart: at(16), WindowEnd: at(17)},
+		},
+	})
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "j1", TechnicianID: "tech-1", CustomerName: "First", ScheduledDate: day, Status: "completed"})
+	_ = store.SaveJobUpload(domain.JobUpload{ID: "j3", TechnicianID: "tech-1", Address: "3 pine st", ScheduledDate: day, Status: "inProgress"})
+	cfg := config.WidgetConfig{MaxStops: 3, MinRefresh: 15 * time.Minute, MaxRefresh: 2 * time.Hour}
+	svc := NewService(cfg, repository.Repository{Routes: store, Sync: store})
+	svc.now = func() time.Time { return at(11).Add(10 * time.Minute) }
+
+	tl, err := svc.Timeline(context.Background(), "tech-1", day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tl.Counts != (Counts{Total: 5, Completed: 1, Remaining: 4}) {
+		t.Fatalf("unexpected counts %+v", tl.Counts)
+	}
+	if len(tl.Stops) != 3 || tl.Stops[0].CustomerName != "Third" || tl.Stops[0].Status != StatusInProgress ||
+		tl.Stops[1].CustomerName != "Second" || tl.Stops[1].Status != StatusOverdue || tl.Stops[2].CustomerName != "Fourth" {
+		t.Fatalf("expected the stop in progress, then the rest in window order, got %+v", tl.Stops)
+	}
+	if len(tl.RefreshAt) != 3 || !tl.RefreshAt[0].Equal(at(12)) || !tl.RefreshAt[2].Equal(at(15)) {
+		t.Fatalf("unexpected refresh times %v", tl.RefreshAt)
+	}
+	if !tl.ReloadAfter.Equal(svc.now().Add(15 * time.Minute)) {
+		t.Fatalf("expected a quick reload while a job is in progress, got %v", tl.ReloadAfter)
+	}
+
+	h := NewHandler(svc)
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/widgets/timeline?serviceDate=2026-04-02", nil)
+		req = req.WithContext(auth.ContextWithIdentity(context.Background(), auth.Identity{Subject: "tech-1", Role: auth.RoleTechnician}))
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		h.GetTimeline(rec, req)
+		return rec
+	}
+	rec := get("")
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "private, max-age=900" {
+		t.Fatalf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+	if again := get(rec.Header().Get("ETag")); again.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for an unchanged timeline, got %d", again.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+)
+
+// DeadLetter is a record whose processing failed on every attempt the queue
+// made. The dispatcher leaves it alone until it is retried or re-uploaded
+// changed.
+type DeadLetter struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	RecordID    string    `json:"recordId"`
+	Fingerprint string    `json:"fingerprint"`
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+	DeadAt      time.Time `json:"deadAt"`
+	Task        Task      `json:"task"`
+}
+
+// deadLetter keeps a record the queue gave up on as a dead letter.
+func (s *Service) deadLetter(_ context.Context, job jobqueue.Job, err error) {
+	var t Task
+	if derr := job.Decode(&t); derr != nil {
+		s.logger.Error("decode dead-lettered upload", slog.String("job", job.ID), slog.Any("error", derr))
+		return
+	}
+	logger := s.logger.With(slog.String("kind", t.Kind), slog.String("id", t.ID))
+	d := DeadLetter{
+		ID:          uuid.NewString(),
+		Kind:        t.Kind,
+		RecordID:    t.ID,
+		Fingerprint: t.Fingerprint,
+		Attempts:    job.Attempt,
+		Error:       err.Error(),
+		DeadAt:      s.now().UTC(),
+		Task:        t,
+	}
+	if err := s.store.SaveDeadLetter(d); err != nil {
+		logger.Error("save dead letter", slog.Any("error", err))
+		return
+	}
+	s.deadLettered.Add(1)
+	s.markProcessed(t, logger)
+	logger.Error("upload dead-lettered", slog.String("deadLetter", d.ID), slog.Int("attempts", d.Attempts), slog.Any("error", err))
+}
+
+// DeadLettered returns how many records have been dead-lettered since the
+// server started.
+func (s *Service) DeadLettered() int64 {
+	return s.deadLettered.Load()
+}
+
+// DeadLetters lists dead letters, most recent first, of one kind when set.
+func (s *Service) DeadLetters(kind string) ([]DeadLetter, error) {
+	return s.store.ListDeadLetters(kind)
+}
+
+// DeadLetter returns a dead letter.
+func (s *Service) DeadLetter(id string) (DeadLetter, error) {
+	return s.store.GetDeadLetter(id)
+}
+
+// RetryDeadLetter queues a dead-lettered record again with a fresh set of
+// attempts, and removes its dead letter.
+func (s *Service) RetryDeadLetter(ctx context.Context, id string) (DeadLetter, error) {
+	d, err := s.store.GetDeadLetter(id)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	if _, err := s.jobs.Enqueue(ctx, Topic, d.Task, 0); err != nil {
+		return DeadLetter{}, fmt.Errorf("queue %s: %w", d.Task.key(), err)
+	}
+	if err := s.store.DeleteDeadLetter(id); err != nil {
+		return DeadLetter{}, err
+	}
+	s.logger.Info("dead letter retried", slog.String("deadLetter", id), slog.String("kind", d.Kind), slog.String("id", d.RecordID))
+	return d, nil
+}
+
+// clearDeadLetter removes a record's dead letter once a version of it has
+// been processed.
+func (s *Service) clearDeadLetter(t Task) error {
+	d, err := s.store.DeadLetterFor(t.Kind, t.ID)
+	if errors.Is(err, ErrDeadLetterNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.store.DeleteDeadLetter(d.ID)
+}
+
+func (d *DocumentStore) SaveDeadLetter(letter DeadLetter) error {
+	return d.deadLetters.Put(letter.Kind+"/"+letter.RecordID, letter)
+}
+
+func (d *DocumentStore) GetDeadLetter(id string) (DeadLetter, error) {
+	found, err := d.deadLetters.Find(docstore.Keys{"id": id})
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	if len(found) == 0 {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return found[0], nil
+}
+
+func (d *DocumentStore) DeadLetterFor(kind, recordID string) (DeadLetter, error) {
+	letter, err := d.deadLetters.Get(kind + "/" + recordID)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return letter, err
+}
+
+func (d *DocumentStore) ListDeadLetters(kind string) ([]DeadLetter, error) {
+	keys := docstore.Keys{}
+	if kind != "" {
+		keys["kind"] = kind
+	}
+	out, err := d.deadLetters.Find(keys)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeadAt.Equal(out[j].DeadAt) {
+			return out[i].DeadAt.After(out[j].DeadAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (d *DocumentStore) DeleteDeadLetter(id string) error {
+	letter, err := d.GetDeadLetter(id)
+	if err != nil {
+		return err
+	}
+	return d.deadLetters.Delete(letter.Kind + "/" + letter.RecordID)
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/internal/geocode"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+)
+
+// needsGeocoding reports whether a route has a stop with an address but no
+// coordinates.
+func needsGeocoding(r models.Route) bool {
+	for _, stop := range r.CustomerStops {
+		if !stop.HasCoordinates() && strings.TrimSpace(stop.Address) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// geocode resolves the addresses of t's record that have no coordinates yet
+// and saves the record with them, so devices receive them on their next
+// sync. It reports how many addresses it resolved. Addresses the geocoder
+// cannot place are left as they are; any other error has the queue retry.
+func (s *Service) geocode(ctx context.Context, t Task) (int, error) {
+	if s.geo == nil {
+		return 0, nil
+	}
+	switch t.Kind {
+	case KindJob:
+		return s.geocodeJob(ctx, *t.Job)
+	case KindRoute:
+		return s.geocodeRoute(ctx, *t.Route)
+	}
+	return 0, nil
+}
+
+func (s *Service) geocodeJob(ctx context.Context, j models.JobUpload) (int, error) {
+	if j.HasCoordinates() || strings.TrimSpace(j.Address) == "" {
+		return 0, nil
+	}
+	c, ok, err := s.lookup(ctx, j.Address)
+	if err != nil || !ok {
+		return 0, err
+	}
+	j.Latitude, j.Longitude = c.Latitude, c.Longitude
+	if err := s.repos.Sync.SaveJobUpload(j); err != nil {
+		return 0, fmt.Errorf("save geocoded job: %w", err)
+	}
+	return 1, nil
+}
+
+// geocodeRoute works on the route as stored now rather than as queued, so
+// edits made in the meantime, such as a new stop order, are kept.
+func (s *Service) geocodeRoute(ctx context.Context, queued models.Route) (int, error) {
+	r, err := s.repos.Routes.GetRoute(queued.TechnicianID, queued.ServiceDate)
+	if err != nil {
+		return 0, fmt.Errorf("load route: %w", err)
+	}
+	resolved := make(map[string]transport.Coordinate)
+	count := 0
+	for i, stop := range r.CustomerStops {
+		address := strings.TrimSpace(stop.Address)
+		if stop.HasCoordinates() || address == "" {
+			continue
+		}
+		c, ok := resolved[address]
+		if !ok {
+			if c, ok, err = s.lookup(ctx, address); err != nil {
+				return 0, err
+			}
+			if !ok {
+				continue
+			}
+			resolved[address] = c
+		}
+		r.CustomerStops[i].Latitude, r.CustomerStops[i].Longitude = c.Latitude, c.Longitude
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	r.LastModified = s.now().UTC()
+	if err := s.repos.Routes.SaveRoute(r); err != nil {
+		return 0, fmt.Errorf("save geocoded route: %w", err)
+	}
+	return count, nil
+}
+
+// lookup geocodes address, reporting false when the geocoder cannot place
+// it.
+func (s *Service) lookup(ctx context.Context, address string) (transport.Coordinate, bool, error) {
+	c, err := s.geo.Geocode(ctx, address)
+	switch {
+	case errors.Is(err, geocode.ErrNoMatch):
+		s.logger.Warn("address not geocoded", slog.String("geocoder", s.geo.Name()), slog.String("address", address))
+		return transport.Coordinate{}, false, nil
+	case err != nil:
+		return transport.Coordinate{}, false, fmt.Errorf("geocode: %w", err)
+	}
+	return c, true, nil
+}
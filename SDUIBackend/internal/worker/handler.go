@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/internal/http/respond"
+	"github.com/your-org/pestgenie-sdui/internal/middleware"
+)
+
+// Handler exposes processing results and dead letters in the admin API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a worker handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes mounts the admin endpoints.
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/", h.ListResults)
+	r.Get("/{kind}/{id}", h.GetResult)
+}
+
+// DeadLetterRoutes mounts the dead-letter endpoints.
+func (h *Handler) DeadLetterRoutes(r chi.Router) {
+	r.Get("/", h.ListDeadLetters)
+	r.Get("/{deadLetterId}", h.GetDeadLetter)
+	r.Post("/{deadLetterId}/retry", h.RetryDeadLetter)
+}
+
+// ListResults returns processing results, most recent first, optionally of
+// one ?kind= and ?status=.
+func (h *Handler) ListResults(w http.ResponseWriter, r *http.Request) {
+	results, err := h.service.Results(r.URL.Query().Get("kind"), r.URL.Query().Get("status"))
+	if err != nil {
+		h.fail(w, r, "failed to list processing results", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+// GetResult returns the processing result of one record.
+func (h *Handler) GetResult(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Result(chi.URLParam(r, "kind"), chi.URLParam(r, "id"))
+	if err != nil {
+		h.fail(w, r, "failed to load processing result", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, res)
+}
+
+// ListDeadLetters returns records whose processing kept failing, most
+// recent first, optionally of one ?kind=.
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := h.service.DeadLetters(r.URL.Query().Get("kind"))
+	if err != nil {
+		h.fail(w, r, "failed to list dead letters", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"deadLetters": letters})
+}
+
+// GetDeadLetter returns a dead letter with every attempt's error.
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.DeadLetter(chi.URLParam(r, "deadLetterId"))
+	if err != nil {
+		h.fail(w, r, "failed to load dead letter", err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, d)
+}
+
+// RetryDeadLetter queues a dead-lettered record for processing again;
+// its outcome shows up in the processing results.
+func (h *Handler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.RetryDeadLetter(r.Context(), chi.URLParam(r, "deadLetterId"))
+	if err != nil {
+		h.fail(w, r, "failed to retry dead letter", err)
+		return
+	}
+	respond.JSON(w, http.StatusAccepted, d)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, title string, err error) {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeadLetterNotFound) {
+		respond.Error(w, http.StatusNotFound, title, err.Error())
+		return
+	}
+	middleware.LoggerFrom(r.Context()).Error(title, slog.Any("error", err))
+	respond.Error(w, http.StatusInternalServerError, title, "temporary error, please retry", respond.WithCause(err))
+}
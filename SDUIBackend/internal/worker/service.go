@@ -0,0 +1,365 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/geocode"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+)
+
+// Topic is the job queue topic uploaded records are processed from.
+const Topic = "sync-records"
+
+// Service dispatches pending uploads to the job queue and processes them.
+type Service struct {
+	cfg    config.WorkerConfig
+	repos  repository.Repository
+	jobs   *jobqueue.Queue
+	store  Store
+	claims docstore.Collection[claim]
+	geo    geocode.Geocoder // nil disables geocoding
+	logger *slog.Logger
+	now    func() time.Time
+
+	deadLettered atomic.Int64
+}
+
+// claim is a record version an instance has queued. Until it expires no
+// instance queues the version again, so each is processed once however
+// many instances dispatch.
+type claim struct {
+	SavedAt time.Time `json:"savedAt"`
+	Until   time.Time `json:"until"`
+}
+
+// errClaimed aborts taking a claim another dispatch holds.
+var errClaimed = errors.New("record already queued")
+
+// scanPage is how many records a dispatch reads at a time when BatchSize
+// does not bound it.
+const scanPage = 100
+
+// NewService wires a worker service and subscribes it to Topic on jobs,
+// processing Concurrency records at once. Records the queue dead-letters
+// are kept as dead letters in store; claims on queued records are kept in
+// documents, which every instance shares. geo may be nil, leaving
+// addresses without coordinates.
+func NewService(cfg config.WorkerConfig, repos repository.Repository, jobs *jobqueue.Queue, store Store, documents docstore.Store, geo geocode.Geocoder, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Service{
+		cfg:    cfg,
+		repos:  repos,
+		jobs:   jobs,
+		store:  store,
+		claims: docstore.NewCollection[claim](documents, "worker_claims", nil),
+		geo:    geo,
+		logger: logger,
+		now:    time.Now,
+	}
+	jobs.Subscribe(jobqueue.Subscription{Topic: Topic, Handler: s.handle, DeadLetter: s.deadLetter, Concurrency: cfg.Concurrency})
+	return s
+}
+
+// Run scans pending uploads every PollInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Dispatch(ctx); err != nil {
+			s.logger.Error("dispatch pending uploads", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch queues up to BatchSize records the repository has not marked
+// processed in their current version, skipping those another dispatch
+// queued within RetryAfter, and reports how many it queued. It pages
+// through jobs, then chemicals, then treatments, each oldest first; with a
+// geocoder, routes follow.
+func (s *Service) Dispatch(ctx context.Context) (int, error) {
+	now := s.now()
+	limit := s.cfg.BatchSize
+	if limit <= 0 {
+		limit = scanPage
+	}
+	count := 0
+	for _, scan := range s.scans() {
+		page := repository.Page{Limit: limit}
+		for s.cfg.BatchSize <= 0 || count < s.cfg.BatchSize {
+			tasks, err := scan(page)
+			if err != nil {
+				return count, err
+			}
+			for _, t := range tasks {
+				if s.cfg.BatchSize > 0 && count >= s.cfg.BatchSize {
+					break
+				}
+				queued, err := s.dispatch(ctx, t, now)
+				if err != nil {
+					return count, err
+				}
+				if queued {
+					count++
+				}
+			}
+			if len(tasks) < page.Limit {
+				break
+			}
+			last := tasks[len(tasks)-1]
+			page = page.Next(last.SavedAt, last.ID)
+		}
+	}
+	if count > 0 {
+		s.logger.Info("queued uploads for processing", slog.Int("count", count))
+	}
+	return count, nil
+}
+
+// scans lists the queries Dispatch pages through, turning records into
+// tasks.
+func (s *Service) scans() []func(repository.Page) ([]Task, error) {
+	scans := []func(repository.Page) ([]Task, error){
+		func(page repository.Page) ([]Task, error) {
+			jobs, err := s.repos.Sync.ListUnprocessedJobs(page)
+			if err != nil {
+				return nil, fmt.Errorf("list unprocessed jobs: %w", err)
+			}
+			return tasks(jobs, jobTask), nil
+		},
+		func(page repository.Page) ([]Task, error) {
+			chemicals, err := s.repos.Sync.ListUnprocessedChemicals(page)
+			if err != nil {
+				return nil, fmt.Errorf("list unprocessed chemicals: %w", err)
+			}
+			return tasks(chemicals, chemicalTask), nil
+		},
+		func(page repository.Page) ([]Task, error) {
+			treatments, err := s.repos.Sync.ListUnprocessedTreatments(page)
+			if err != nil {
+				return nil, fmt.Errorf("list unprocessed treatments: %w", err)
+			}
+			return tasks(treatments, treatmentTask), nil
+		},
+	}
+	if s.geo == nil {
+		return scans
+	}
+	return append(scans, func(page repository.Page) ([]Task, error) {
+		routes, err := s.repos.Sync.ListUnprocessedRoutes(page)
+		if err != nil {
+			return nil, fmt.Errorf("list unprocessed routes: %w", err)
+		}
+		return tasks(routes, routeTask), nil
+	})
+}
+
+func tasks[T any](records []T, task func(T) Task) []Task {
+	out := make([]Task, len(records))
+	for i, r := range records {
+		out[i] = task(r)
+	}
+	return out
+}
+
+// dispatch queues t unless there is nothing to do for it or another
+// dispatch holds its claim, and reports whether it did.
+func (s *Service) dispatch(ctx context.Context, t Task, now time.Time) (bool, error) {
+	done, err := s.settled(t)
+	if err != nil {
+		return false, err
+	}
+	if done {
+		return false, s.repos.Sync.MarkProcessed(t.Kind, t.ID, t.SavedAt)
+	}
+	_, err = s.claims.Update(t.key(), func(current *claim) (claim, error) {
+		if current != nil && current.SavedAt.Equal(t.SavedAt) && now.Before(current.Until) {
+			return claim{}, errClaimed
+		}
+		return claim{SavedAt: t.SavedAt, Until: now.Add(s.cfg.RetryAfter)}, nil
+	})
+	if errors.Is(err, errClaimed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim %s: %w", t.key(), err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, Topic, t, 0); err != nil {
+		return false, fmt.Errorf("queue %s: %w", t.key(), err)
+	}
+	return true, nil
+}
+
+// settled reports whether t's content was already processed or
+// dead-lettered, as when processing wrote the record back, or is a route
+// with nothing left to geocode.
+func (s *Service) settled(t Task) (bool, error) {
+	if t.Kind == KindRoute && !needsGeocoding(*t.Route) {
+		return true, nil
+	}
+	res, err := s.store.GetResult(t.Kind, t.ID)
+	switch {
+	case err == nil && res.Fingerprint == t.Fingerprint:
+		return true, nil
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return false, fmt.Errorf("get result %s: %w", t.key(), err)
+	}
+	dead, err := s.store.DeadLetterFor(t.Kind, t.ID)
+	switch {
+	case err == nil && dead.Fingerprint == t.Fingerprint:
+		return true, nil
+	case err != nil && !errors.Is(err, ErrDeadLetterNotFound):
+		return false, fmt.Errorf("get dead letter %s: %w", t.key(), err)
+	}
+	return false, nil
+}
+
+// markProcessed marks the version of the record t carries processed and
+// releases its claim. A failure is only logged: the next dispatch finds
+// the result and marks the record then.
+func (s *Service) markProcessed(t Task, logger *slog.Logger) {
+	if err := s.repos.Sync.MarkProcessed(t.Kind, t.ID, t.SavedAt); err != nil {
+		logger.Error("mark upload processed", slog.Any("error", err))
+		return
+	}
+	if err := s.claims.Delete(t.key()); err != nil {
+		logger.Error("release claim", slog.Any("error", err))
+	}
+}
+
+// handle processes one queued record. An error has the queue deliver it
+// again after a backoff, until it is dead-lettered.
+func (s *Service) handle(ctx context.Context, job jobqueue.Job) error {
+	var t Task
+	if err := job.Decode(&t); err != nil {
+		// Redelivering a task that does not decode cannot help; the next
+		// Dispatch queues the record again.
+		s.logger.Error("decode queued upload", slog.String("job", job.ID), slog.Any("error", err))
+		return nil
+	}
+	logger := s.logger.With(slog.String("kind", t.Kind), slog.String("id", t.ID))
+	res, err := s.Process(t)
+	if err == nil && res.Status == StatusProcessed {
+		res.Geocoded, err = s.geocode(ctx, t)
+	}
+	if err == nil {
+		err = s.store.SaveResult(res)
+	}
+	if err != nil {
+		logger.Error("process upload", slog.Int("attempt", job.Attempt), slog.Any("error", err))
+		return err
+	}
+	s.markProcessed(t, logger)
+	if err := s.clearDeadLetter(t); err != nil {
+		logger.Error("clear dead letter", slog.Any("error", err))
+	}
+	if res.Status == StatusRejected {
+		logger.Warn("upload rejected", slog.String("problems", strings.Join(res.Problems, "; ")))
+	}
+	return nil
+}
+
+// Process validates a record and enriches it with its technician's profile.
+// Records that fail validation are still processed, with status rejected and
+// the problems found; an error means the record could not be processed.
+func (s *Service) Process(t Task) (Result, error) {
+	var problems []string
+	var technicianID string
+	switch {
+	case t.Kind == KindJob && t.Job != nil:
+		j := t.Job
+		technicianID = j.TechnicianID
+		if strings.TrimSpace(j.CustomerName) == "" {
+			problems = append(problems, "customerName is required")
+		}
+		if j.ScheduledDate.IsZero() {
+			problems = append(problems, "scheduledDate is required")
+		}
+	case t.Kind == KindChemical && t.Chemical != nil:
+		c := t.Chemical
+		technicianID = c.TechnicianID
+		if strings.TrimSpace(c.Name) == "" {
+			problems = append(problems, "name is required")
+		}
+		if c.QuantityInStock < 0 {
+			problems = append(problems, "quantityInStock must be >= 0")
+		}
+		for _, lot := range c.Lots {
+			if lot.Quantity < 0 {
+				problems = append(problems, fmt.Sprintf("lot %s quantity must be >= 0", lot.Number))
+			}
+		}
+	case t.Kind == KindTreatment && t.Treatment != nil:
+		tr := t.Treatment
+		technicianID = tr.TechnicianID
+		if tr.JobID == "" {
+			problems = append(problems, "jobId is required")
+		}
+		if tr.ChemicalID == "" {
+			problems = append(problems, "chemicalId is required")
+		}
+		if tr.QuantityUsed <= 0 {
+			problems = append(problems, "quantityUsed must be > 0")
+		}
+		if tr.ApplicationDate.IsZero() {
+			problems = append(problems, "applicationDate is required")
+		}
+	case t.Kind == KindRoute && t.Route != nil:
+		technicianID = t.Route.TechnicianID
+	default:
+		return Result{}, fmt.Errorf("task %s carries no %s record", t.key(), t.Kind)
+	}
+
+	res := Result{
+		Kind:         t.Kind,
+		ID:           t.ID,
+		Fingerprint:  t.Fingerprint,
+		Status:       StatusProcessed,
+		ProcessedAt:  s.now().UTC(),
+		TechnicianID: technicianID,
+	}
+	if technicianID != "" {
+		if tech, err := s.repos.Technicians.GetByID(technicianID); err != nil {
+			problems = append(problems, fmt.Sprintf("technician %q is not known", technicianID))
+		} else {
+			res.TechnicianName = tech.DisplayName
+			res.BranchID = tech.BranchID
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		res.Status = StatusRejected
+		res.Problems = problems
+	}
+	return res, nil
+}
+
+// Result returns the processing result of a record.
+func (s *Service) Result(kind, id string) (Result, error) {
+	return s.store.GetResult(kind, id)
+}
+
+// Results lists processing results, filtered by kind and status when set.
+func (s *Service) Results(kind, status string) ([]Result, error) {
+	return s.store.ListResults(kind, status)
+}
@@ -0,0 +1,194 @@
+// Package worker processes uploaded sync records in the background. A
+// dispatcher pages through the uploads the repository has not marked
+// processed and queues them; workers drain the queue, validate and enrich
+// each record, and mark it processed in the repository. With a geocoder, jobs and route stops are
+// also given the coordinates of their addresses.
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// Record kinds, as the repository marks them processed.
+const (
+	KindJob       = repository.UploadJob
+	KindChemical  = repository.UploadChemical
+	KindTreatment = repository.UploadTreatment
+	KindRoute     = repository.UploadRoute // a route with stops to geocode
+)
+
+// Result statuses.
+const (
+	StatusProcessed = "processed" // valid and enriched
+	StatusRejected  = "rejected"  // failed validation; see Problems
+)
+
+var (
+	// ErrNotFound is returned for records that have not been processed.
+	ErrNotFound = errors.New("processing result not found")
+	// ErrDeadLetterNotFound is returned when a dead letter does not exist.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// Task is one uploaded record to process. It carries the record itself, so
+// workers need no lookup and a queue can cross process boundaries.
+type Task struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// Fingerprint identifies the version of the record; a re-upload that
+	// changes it is processed again.
+	Fingerprint string `json:"fingerprint"`
+	// SavedAt is the server write time of the version, which marking it
+	// processed names.
+	SavedAt   time.Time                       `json:"savedAt"`
+	Job       *models.JobUpload               `json:"job,omitempty"`
+	Chemical  *models.ChemicalUpload          `json:"chemical,omitempty"`
+	Treatment *models.ChemicalTreatmentUpload `json:"treatment,omitempty"`
+	Route     *models.Route                   `json:"route,omitempty"`
+}
+
+// key identifies the record a task is for.
+func (t Task) key() string {
+	return t.Kind + "/" + t.ID
+}
+
+// Tasks leave the write times saving refreshes out of the fingerprint, and
+// jobTask and routeTask coordinates too: geocoding writes the record back,
+// which must not make it new again.
+func jobTask(j models.JobUpload) Task {
+	bare := j
+	bare.Latitude, bare.Longitude, bare.ReceivedAt = 0, 0, time.Time{}
+	return Task{Kind: KindJob, ID: j.ID, Fingerprint: fingerprint(bare), SavedAt: j.ReceivedAt, Job: &j}
+}
+
+func routeTask(r models.Route) Task {
+	bare := r
+	bare.LastModified = time.Time{}
+	bare.CustomerStops = make([]models.RouteStop, len(r.CustomerStops))
+	for i, stop := range r.CustomerStops {
+		stop.Latitude, stop.Longitude = 0, 0
+		bare.CustomerStops[i] = stop
+	}
+	return Task{Kind: KindRoute, ID: r.ServerID(), Fingerprint: fingerprint(bare), SavedAt: r.LastModified, Route: &r}
+}
+
+func chemicalTask(c models.ChemicalUpload) Task {
+	bare := c
+	bare.LastModified = time.Time{}
+	return Task{Kind: KindChemical, ID: c.ID, Fingerprint: fingerprint(bare), SavedAt: c.LastModified, Chemical: &c}
+}
+
+func treatmentTask(t models.ChemicalTreatmentUpload) Task {
+	bare := t
+	bare.LastModified = time.Time{}
+	return Task{Kind: KindTreatment, ID: t.ID, Fingerprint: fingerprint(bare), SavedAt: t.LastModified, Treatment: &t}
+}
+
+// fingerprint hashes a record's content. Stores differ in which timestamps
+// they keep, so the content is the one version marker all of them share.
+func fingerprint(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Result is the outcome of processing one version of a record.
+type Result struct {
+	Kind        string    `json:"kind"`
+	ID          string    `json:"id"`
+	Fingerprint string    `json:"fingerprint"`
+	Status      string    `json:"status"`
+	Problems    []string  `json:"problems,omitempty"`
+	ProcessedAt time.Time `json:"processedAt"`
+
+	// Enrichment, from the technician the record is attributed to.
+	TechnicianID   string `json:"technicianId,omitempty"`
+	TechnicianName string `json:"technicianName,omitempty"`
+	BranchID       string `json:"branchId,omitempty"`
+	// Geocoded is how many addresses were resolved to coordinates.
+	Geocoded int `json:"geocoded,omitempty"`
+}
+
+// Store persists processing results, one per record, and dead letters.
+type Store interface {
+	SaveResult(r Result) error
+	GetResult(kind, id string) (Result, error)
+	// ListResults returns results, most recently processed first, filtered
+	// by kind and status when those are set.
+	ListResults(kind, status string) ([]Result, error)
+
+	// SaveDeadLetter stores d, replacing any dead letter for the same
+	// record.
+	SaveDeadLetter(d DeadLetter) error
+	GetDeadLetter(id string) (DeadLetter, error)
+	// DeadLetterFor returns the dead letter of a record.
+	DeadLetterFor(kind, recordID string) (DeadLetter, error)
+	// ListDeadLetters returns dead letters, most recent first, of one kind
+	// when set.
+	ListDeadLetters(kind string) ([]DeadLetter, error)
+	DeleteDeadLetter(id string) error
+}
+
+// DocumentStore keeps results and dead letters in the shared document
+// store, so every instance sees them and they survive restarts.
+type DocumentStore struct {
+	results     docstore.Collection[Result]     // keyed by kind/id
+	deadLetters docstore.Collection[DeadLetter] // keyed by kind/id of the record
+}
+
+// NewDocumentStore keeps results and dead letters in docs.
+func NewDocumentStore(docs docstore.Store) *DocumentStore {
+	return &DocumentStore{
+		results: docstore.NewCollection(docs, "worker_results", func(r Result) docstore.Keys {
+			return docstore.Keys{"kind": r.Kind, "status": r.Status}
+		}),
+		deadLetters: docstore.NewCollection(docs, "worker_dead_letters", func(d DeadLetter) docstore.Keys {
+			return docstore.Keys{"id": d.ID, "kind": d.Kind}
+		}),
+	}
+}
+
+// NewMemoryStore keeps results and dead letters in process memory, for
+// tests.
+func NewMemoryStore() *DocumentStore {
+	return NewDocumentStore(docstore.NewMemoryStore())
+}
+
+var _ Store = (*DocumentStore)(nil)
+
+func (d *DocumentStore) SaveResult(r Result) error {
+	return d.results.Put(r.Kind+"/"+r.ID, r)
+}
+
+func (d *DocumentStore) GetResult(kind, id string) (Result, error) {
+	r, err := d.results.Get(kind + "/" + id)
+	if errors.Is(err, docstore.ErrNotFound) {
+		return Result{}, ErrNotFound
+	}
+	return r, err
+}
+
+func (d *DocumentStore) ListResults(kind, status string) ([]Result, error) {
+	keys := docstore.Keys{}
+	if kind != "" {
+		keys["kind"] = kind
+	}
+	if status != "" {
+		keys["status"] = status
+	}
+	out, err := d.results.Find(keys)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ProcessedAt.After(out[j].ProcessedAt) })
+	return out, nil
+}
@@ -0,0 +1,267 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+	"github.com/your-org/pestgenie-sdui/internal/config"
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+	"github.com/your-org/pestgenie-sdui/internal/geocode"
+	"github.com/your-org/pestgenie-sdui/internal/jobqueue"
+	transport "github.com/your-org/pestgenie-sdui/internal/models"
+	storememory "github.com/your-org/pestgenie-sdui/internal/store/memory"
+)
+
+func newService(t *testing.T) (*Service, *storememory.Store, *time.Time) {
+	t.Helper()
+	store := storememory.NewStore()
+	store.AddTechnician(models.Technician{ID: "tech-a", DisplayName: "Ana", BranchID: "north"})
+	repos := repository.Repository{Technicians: store, Routes: store, Screens: store, Sync: store, Devices: store}
+	cfg := config.WorkerConfig{Enabled: true, Concurrency: 1, PollInterval: time.Minute, BatchSize: 10, RetryAfter: time.Minute}
+	svc := NewService(cfg, repos, newQueue(), NewMemoryStore(), docstore.NewMemoryStore(), nil, nil)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, store, &now
+}
+
+// newQueue returns a memory queue that gives up on a job after two
+// attempts, retrying at once.
+func newQueue() *jobqueue.Queue {
+	return jobqueue.NewMemory(config.QueueConfig{BufferSize: 10, MaxAttempts: 2, MinBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}, nil)
+}
+
+// drain processes every queued task.
+func drain(t *testing.T, svc *Service) {
+	t.Helper()
+	svc.jobs.Drain(context.Background())
+}
+
+func TestDispatchProcessesEachVersionOnce(t *testing.T) {
+	svc, store, now := newService(t)
+	ctx := context.Background()
+	scheduled := time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-a", CustomerName: "Smith", ScheduledDate: scheduled})
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-2", TechnicianID: "ghost"})
+	_ = store.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", JobID: "job-1", ChemicalID: "chem-1", QuantityUsed: 2, ApplicationDate: scheduled})
+	_ = store.SaveJobUpload(models.JobUpload{CustomerName: "no id"})
+
+	if n, err := svc.Dispatch(ctx); err != nil || n != 3 {
+		t.Fatalf("expected three records queued, got %d (%v)", n, err)
+	}
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected queued records not queued again within RetryAfter, got %d", n)
+	}
+	drain(t, svc)
+
+	ok, err := svc.Result(KindJob, "job-1")
+	if err != nil || ok.Status != StatusProcessed || ok.BranchID != "north" || ok.TechnicianName != "Ana" {
+		t.Fatalf("expected job-1 processed and enriched, got %+v (%v)", ok, err)
+	}
+	bad, _ := svc.Result(KindJob, "job-2")
+	if bad.Status != StatusRejected || len(bad.Problems) != 3 || !strings.Contains(strings.Join(bad.Problems, ";"), `technician "ghost" is not known`) {
+		t.Fatalf("expected job-2 rejected with three problems, got %+v", bad)
+	}
+	if rejected, _ := svc.Results("", StatusRejected); len(rejected) != 1 {
+		t.Fatalf("expected one rejected result, got %+v", rejected)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected processed records left alone, got %d queued", n)
+	}
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-2", TechnicianID: "tech-a", CustomerName: "Jones", ScheduledDate: scheduled})
+	if n, _ := svc.Dispatch(ctx); n != 1 {
+		t.Fatalf("expected the changed record queued again, got %d", n)
+	}
+	drain(t, svc)
+	if fixed, _ := svc.Result(KindJob, "job-2"); fixed.Status != StatusProcessed {
+		t.Fatalf("expected the corrected job processed, got %+v", fixed)
+	}
+}
+
+func TestUnprocessedRecordsAreRequeuedAfterRetryAfter(t *testing.T) {
+	svc, store, now := newService(t)
+	ctx := context.Background()
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"})
+	if n, _ := svc.Dispatch(ctx); n != 1 {
+		t.Fatalf("expected one record queued, got %d", n)
+	}
+	// The task is lost, as when a memory queue dies with the process.
+	svc.jobs = newQueue()
+	svc.jobs.Subscribe(jobqueue.Subscription{Topic: Topic, Handler: svc.handle, DeadLetter: svc.deadLetter})
+
+	*now = now.Add(30 * time.Second)
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected no requeue within RetryAfter, got %d", n)
+	}
+	*now = now.Add(time.Minute)
+	if n, _ := svc.Dispatch(ctx); n != 1 {
+		t.Fatalf("expected a requeue after RetryAfter, got %d", n)
+	}
+	drain(t, svc)
+	if res, err := svc.Result(KindChemical, "chem-1"); err != nil || res.Status != StatusProcessed {
+		t.Fatalf("expected the chemical processed, got %+v (%v)", res, err)
+	}
+}
+
+// flakyStore fails to save results while failing is set.
+type flakyStore struct {
+	*DocumentStore
+	failing bool
+}
+
+func (f *flakyStore) SaveResult(r Result) error {
+	if f.failing {
+		return errors.New("datastore unavailable")
+	}
+	return f.DocumentStore.SaveResult(r)
+}
+
+func TestFailedRecordsAreDeadLetteredAndRetried(t *testing.T) {
+	svc, store, now := newService(t)
+	results := &flakyStore{DocumentStore: NewMemoryStore(), failing: true}
+	svc.store = results
+	ctx := context.Background()
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"})
+
+	if n, _ := svc.Dispatch(ctx); n != 1 {
+		t.Fatalf("expected the record queued, got %d", n)
+	}
+	drain(t, svc)
+	letters, _ := svc.DeadLetters("")
+	if len(letters) != 1 || letters[0].RecordID != "chem-1" || letters[0].Attempts != 2 || letters[0].Error != "datastore unavailable" {
+		t.Fatalf("expected one dead letter after two failures, got %+v", letters)
+	}
+	if svc.DeadLettered() != 1 {
+		t.Fatalf("expected the dead-letter count incremented, got %d", svc.DeadLettered())
+	}
+	*now = now.Add(2 * time.Minute)
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected a dead-lettered record left alone, got %d queued", n)
+	}
+
+	results.failing = false
+	if _, err := svc.RetryDeadLetter(ctx, "missing"); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Fatalf("expected an unknown dead letter not found, got %v", err)
+	}
+	if _, err := svc.RetryDeadLetter(ctx, letters[0].ID); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	drain(t, svc)
+	if res, err := svc.Result(KindChemical, "chem-1"); err != nil || res.Status != StatusProcessed {
+		t.Fatalf("expected the retried record processed, got %+v (%v)", res, err)
+	}
+	if letters, _ := svc.DeadLetters(""); len(letters) != 0 {
+		t.Fatalf("expected the dead letter removed, got %+v", letters)
+	}
+}
+
+// fakeGeocoder places the addresses it knows and counts its calls.
+type fakeGeocoder struct {
+	known map[string]transport.Coordinate
+	calls int
+}
+
+func (*fakeGeocoder) Name() string { return "fake" }
+
+func (f *fakeGeocoder) Geocode(_ context.Context, address string) (transport.Coordinate, error) {
+	f.calls++
+	c, ok := f.known[address]
+	if !ok {
+		return transport.Coordinate{}, geocode.ErrNoMatch
+	}
+	return c, nil
+}
+
+func TestGeocodesJobsAndRouteStops(t *testing.T) {
+	svc, store, now := newService(t)
+	geo := &fakeGeocoder{known: map[string]transport.Coordinate{
+		"100 Main St": {Latitude: 30.2682, Longitude: -97.7429},
+		"25 River Rd": {Latitude: 30.2553, Longitude: -97.7630},
+	}}
+	svc.geo = geo
+	ctx := context.Background()
+	scheduled := time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-a", CustomerName: "Smith", Address: "100 Main St", ScheduledDate: scheduled})
+	_ = store.SaveRoute(models.Route{TechnicianID: "tech-a", ServiceDate: scheduled, CustomerStops: []models.RouteStop{
+		{CustomerID: "c1", Address: "100 Main St"},
+		{CustomerID: "c2", Address: "Nowhere"},
+		{CustomerID: "c3", Address: "25 River Rd", Latitude: 1, Longitude: 1},
+		{CustomerID: "c4", Address: "100 Main St"},
+	}})
+
+	if n, err := svc.Dispatch(ctx); err != nil || n != 2 {
+		t.Fatalf("expected the job and the route queued, got %d (%v)", n, err)
+	}
+	drain(t, svc)
+
+	if res, _ := svc.Result(KindJob, "job-1"); res.Status != StatusProcessed || res.Geocoded != 1 {
+		t.Fatalf("expected the job geocoded, got %+v", res)
+	}
+	jobs, _ := store.ListJobUpdatesSince(time.Time{})
+	if len(jobs) != 1 || jobs[0].Latitude != 30.2682 || jobs[0].Longitude != -97.7429 {
+		t.Fatalf("expected the job saved with its coordinates, got %+v", jobs)
+	}
+	if res, _ := svc.Result(KindRoute, "tech-a_2026-05-02"); res.Geocoded != 2 {
+		t.Fatalf("expected two route stops geocoded, got %+v", res)
+	}
+	route, _ := store.GetRoute("tech-a", scheduled)
+	stops := route.CustomerStops
+	if stops[0].Latitude != 30.2682 || stops[1].HasCoordinates() || stops[2].Latitude != 1 || stops[3].Longitude != -97.7429 {
+		t.Fatalf("expected the known addresses geocoded and others kept, got %+v", stops)
+	}
+	if geo.calls != 3 {
+		t.Fatalf("expected each address looked up once, got %d calls", geo.calls)
+	}
+
+	// Writing the coordinates back does not make either record new again,
+	// and an address the geocoder cannot place is not retried.
+	*now = now.Add(2 * time.Minute)
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected geocoded records left alone, got %d queued", n)
+	}
+}
+
+func TestProcessedStateOutlivesTheInstance(t *testing.T) {
+	svc, store, _ := newService(t)
+	geo := &fakeGeocoder{known: map[string]transport.Coordinate{"100 Main St": {Latitude: 30.2682, Longitude: -97.7429}}}
+	svc.geo = geo
+	ctx := context.Background()
+	scheduled := time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)
+	_ = store.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-a", CustomerName: "Smith", Address: "100 Main St", ScheduledDate: scheduled})
+	_ = store.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"})
+
+	// A second instance shares the repository and the claims, but not the
+	// first one's results.
+	docs := docstore.NewMemoryStore()
+	svc.claims = docstore.NewCollection[claim](docs, "worker_claims", nil)
+	other := NewService(svc.cfg, svc.repos, newQueue(), NewMemoryStore(), docs, geo, nil)
+	other.now = svc.now
+	if n, _ := svc.Dispatch(ctx); n != 2 {
+		t.Fatalf("expected both records queued, got %d", n)
+	}
+	if n, _ := other.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected records another instance queued left alone, got %d", n)
+	}
+	drain(t, svc)
+
+	// After a restart, only the job the geocoder wrote back is read again,
+	// and it is marked processed without another lookup.
+	restarted := NewService(svc.cfg, svc.repos, newQueue(), NewMemoryStore(), docs, geo, nil)
+	restarted.now = svc.now
+	if n, _ := restarted.Dispatch(ctx); n != 1 {
+		t.Fatalf("expected the written-back job queued once more, got %d", n)
+	}
+	drain(t, restarted)
+	if n, _ := restarted.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected nothing left to process, got %d", n)
+	}
+	if geo.calls != 1 {
+		t.Fatalf("expected the address looked up once, got %d calls", geo.calls)
+	}
+}
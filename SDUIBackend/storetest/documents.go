@@ -0,0 +1,149 @@
+package storetest
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/your-org/pestgenie-sdui/internal/docstore"
+)
+
+// RunDocuments runs the docstore.Store suite against stores returned by
+// newStore, which must return an empty store each time it is called.
+func RunDocuments(t *testing.T, newStore func(t *testing.T) docstore.Store) {
+	t.Run("Documents", func(t *testing.T) { testDocuments(t, newStore(t)) })
+	t.Run("FindDocuments", func(t *testing.T) { testFindDocuments(t, newStore(t)) })
+	t.Run("UpdateDocument", func(t *testing.T) { testUpdateDocument(t, newStore(t)) })
+}
+
+func testDocuments(t *testing.T, s docstore.Store) {
+	if _, err := s.GetDocument("widgets", "w/1"); !errors.Is(err, docstore.ErrNotFound) {
+		t.Fatalf("expected a missing document reported as ErrNotFound, got %v", err)
+	}
+	doc := docstore.Document{ID: "w/1", Keys: docstore.Keys{"owner": "tech-1"}, Body: json.RawMessage(`{"name":"first"}`)}
+	if err := s.CreateDocument("widgets", doc); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := s.CreateDocument("widgets", doc); !errors.Is(err, docstore.ErrExists) {
+		t.Fatalf("expected creating an existing document to fail with ErrExists, got %v", err)
+	}
+	got, err := s.GetDocument("widgets", "w/1")
+	if err != nil || got.ID != "w/1" || got.Keys["owner"] != "tech-1" || got.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected document %+v (%v)", got, err)
+	}
+	var body struct{ Name string }
+	if err := json.Unmarshal(got.Body, &body); err != nil || body.Name != "first" {
+		t.Fatalf("unexpected body %s (%v)", got.Body, err)
+	}
+	if _, err := s.GetDocument("gadgets", "w/1"); !errors.Is(err, docstore.ErrNotFound) {
+		t.Fatalf("expected collections kept apart, got %v", err)
+	}
+
+	doc.Body = json.RawMessage(`{"name":"second"}`)
+	if err := s.PutDocument("widgets", doc); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if got, _ := s.GetDocument("widgets", "w/1"); !jsonEqual(got.Body, `{"name":"second"}`) {
+		t.Fatalf("expected put to replace the document, got %s", got.Body)
+	}
+	if err := s.DeleteDocument("widgets", "w/1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.GetDocument("widgets", "w/1"); !errors.Is(err, docstore.ErrNotFound) {
+		t.Fatalf("expected the document deleted, got %v", err)
+	}
+	if err := s.DeleteDocument("widgets", "w/1"); err != nil {
+		t.Fatalf("expected deleting a missing document to succeed, got %v", err)
+	}
+}
+
+func testFindDocuments(t *testing.T, s docstore.Store) {
+	for _, doc := range []docstore.Document{
+		{ID: "c", Keys: docstore.Keys{"owner": "tech-1", "day": "2026-04-02"}, Body: json.RawMessage(`{}`)},
+		{ID: "a", Keys: docstore.Keys{"owner": "tech-1", "day": "2026-04-03"}, Body: json.RawMessage(`{}`)},
+		{ID: "b", Keys: docstore.Keys{"owner": "tech-2", "day": "2026-04-02"}, Body: json.RawMessage(`{}`)},
+	} {
+		if err := s.PutDocument("widgets", doc); err != nil {
+			t.Fatalf("put %s: %v", doc.ID, err)
+		}
+	}
+	for _, tc := range []struct {
+		keys docstore.Keys
+		want string
+	}{
+		{nil, "abc"},
+		{docstore.Keys{"owner": "tech-1"}, "ac"},
+		{docstore.Keys{"owner": "tech-1", "day": "2026-04-02"}, "c"},
+		{docstore.Keys{"owner": "tech-3"}, ""},
+	} {
+		docs, err := s.FindDocuments("widgets", tc.keys)
+		if err != nil {
+			t.Fatalf("find %v: %v", tc.keys, err)
+		}
+		got := ""
+		for _, d := range docs {
+			got += d.ID
+		}
+		if got != tc.want {
+			t.Fatalf("find %v: expected %q in ID order, got %q", tc.keys, tc.want, got)
+		}
+	}
+	if docs, err := s.FindDocuments("gadgets", nil); err != nil || len(docs) != 0 {
+		t.Fatalf("expected an empty collection, got %+v (%v)", docs, err)
+	}
+}
+
+func testUpdateDocument(t *testing.T, s docstore.Store) {
+	increment := func(current *docstore.Document) (docstore.Document, error) {
+		n := 0
+		if current != nil {
+			n, _ = strconv.Atoi(string(current.Body))
+		}
+		return docstore.Document{Keys: docstore.Keys{"kind": "counter"}, Body: json.RawMessage(strconv.Itoa(n + 1))}, nil
+	}
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.UpdateDocument("counters", "hits", increment); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := s.GetDocument("counters", "hits")
+	if err != nil || string(got.Body) != strconv.Itoa(writers) || got.Keys["kind"] != "counter" {
+		t.Fatalf("expected %d concurrent updates kept, got %s (%v)", writers, got.Body, err)
+	}
+
+	refused := errors.New("refused")
+	if _, err := s.UpdateDocument("counters", "hits", func(*docstore.Document) (docstore.Document, error) {
+		return docstore.Document{}, refused
+	}); !errors.Is(err, refused) {
+		t.Fatalf("expected fn's error returned, got %v", err)
+	}
+	if got, _ := s.GetDocument("counters", "hits"); string(got.Body) != strconv.Itoa(writers) {
+		t.Fatalf("expected a refused update to change nothing, got %s", got.Body)
+	}
+}
+
+// jsonEqual reports whether data holds the same JSON as want, however the
+// backend formats it.
+func jsonEqual(data json.RawMessage, want string) bool {
+	var a, b any
+	if json.Unmarshal(data, &a) != nil || json.Unmarshal([]byte(want), &b) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
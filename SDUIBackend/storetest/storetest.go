@@ -0,0 +1,570 @@
+// Package storetest is the behavioral test suite for the repository
+// interfaces. The memory, Postgres and Firestore stores all run it, so they
+// provably behave the same, and custom backends, such as a DynamoDB store
+// for an AWS-only deployment, can run it to check they conform without
+// reading those stores' internals:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) storetest.Store {
+//			return dynamo.NewStore(newTestTable(t))
+//		})
+//	}
+//
+// The suite checks what the interfaces document, using the wall clock, so a
+// store needs no test hooks. It sleeps briefly between writes it must tell
+// apart, and needs timestamps precise to a millisecond.
+package storetest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/pestgenie-sdui/domain/models"
+	"github.com/your-org/pestgenie-sdui/domain/repository"
+)
+
+// errStop is returned from scan callbacks to stop the scan.
+var errStop = errors.New("stop")
+
+// Store is a backend implementing every repository interface.
+type Store interface {
+	repository.TechnicianRepository
+	repository.RouteRepository
+	repository.ScreenRepository
+	repository.SyncRepository
+	repository.DeviceRepository
+}
+
+// serviceDate is a route's service date, mid-morning so backends keying
+// routes by calendar date must drop the time of day.
+var serviceDate = time.Date(2026, 4, 2, 8, 30, 0, 0, time.UTC)
+
+// Run runs the suite against stores returned by newStore, which must
+// return an empty store each time it is called.
+func Run(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Run("Technicians", func(t *testing.T) { testTechnicians(t, newStore(t)) })
+	t.Run("Routes", func(t *testing.T) { testRoutes(t, newStore(t)) })
+	t.Run("Templates", func(t *testing.T) { testTemplates(t, newStore(t)) })
+	t.Run("PendingUploads", func(t *testing.T) { testPendingUploads(t, newStore(t)) })
+	t.Run("UpdatesSince", func(t *testing.T) { testUpdatesSince(t, newStore(t)) })
+	t.Run("UpdatePages", func(t *testing.T) { testUpdatePages(t, newStore(t)) })
+	t.Run("UnprocessedUploads", func(t *testing.T) { testUnprocessedUploads(t, newStore(t)) })
+	t.Run("SoftDeletes", func(t *testing.T) { testSoftDeletes(t, newStore(t)) })
+	t.Run("Batches", func(t *testing.T) { testBatches(t, newStore(t)) })
+	t.Run("DeviceTokens", func(t *testing.T) { testDeviceTokens(t, newStore(t)) })
+	t.Run("TreatmentScan", func(t *testing.T) { testTreatmentScan(t, newStore(t)) })
+}
+
+// mark returns a watermark strictly between the writes made before and
+// after it, however coarse the backend's timestamps.
+func mark() time.Time {
+	time.Sleep(5 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	return at
+}
+
+func testTechnicians(t *testing.T, s Store) {
+	if _, err := s.GetByID("tech-1"); !errors.Is(err, repository.ErrTechnicianNotFound) {
+		t.Fatalf("expected a missing technician reported as ErrTechnicianNotFound, got %v", err)
+	}
+	tech := models.Technician{ID: "tech-1", TenantID: "acme", DisplayName: "Maria Lopez", BranchID: "north", Certifications: []string{"QAL"}}
+	for _, tc := range []models.Technician{{ID: "tech-1", DisplayName: "Old name"}, tech, {ID: "tech/2"}, {ID: "tech-0"}} {
+		if err := s.SaveTechnician(tc); err != nil {
+			t.Fatalf("save technician %s: %v", tc.ID, err)
+		}
+	}
+	if got, err := s.GetByID("tech-1"); err != nil || !reflect.DeepEqual(got, tech) {
+		t.Fatalf("expected the technician replaced, got %+v (%v)", got, err)
+	}
+	list, err := s.ListTechnicians()
+	if err != nil || len(list) != 3 || list[0].ID != "tech-0" || list[1].ID != "tech-1" || list[2].ID != "tech/2" {
+		t.Fatalf("expected three technicians ordered by ID, got %+v (%v)", list, err)
+	}
+}
+
+func testRoutes(t *testing.T, s Store) {
+	route := models.Route{
+		ID:            "route-1",
+		TenantID:      "acme",
+		TechnicianID:  "tech-1",
+		ServiceDate:   serviceDate,
+		CustomerStops: []models.RouteStop{{CustomerID: "c1", CustomerName: "First", Priority: "high"}, {CustomerID: "c2"}},
+		Alerts:        []models.RouteAlert{{Type: "weather", Message: "Rain after 2pm", Severity: "info"}},
+	}
+	if err := s.SaveRoute(route); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	got, err := s.GetRoute("tech-1", serviceDate.Truncate(24*time.Hour))
+	if err != nil || len(got.CustomerStops) != 2 || got.CustomerStops[0].CustomerName != "First" || len(got.Alerts) != 1 || got.LastModified.IsZero() || got.TenantID != "acme" {
+		t.Fatalf("expected the route found by its date, got %+v (%v)", got, err)
+	}
+	if _, err := s.GetRoute("tech-1", serviceDate.AddDate(0, 0, 1)); !errors.Is(err, repository.ErrRouteNotFound) {
+		t.Fatalf("expected another day's route reported as ErrRouteNotFound, got %v", err)
+	}
+
+	since := mark()
+	for i := 0; i < 2; i++ {
+		if err := s.DeleteRoute("tech-1", serviceDate); err != nil {
+			t.Fatalf("delete route: %v", err)
+		}
+	}
+	if err := s.DeleteRoute("tech-1", serviceDate.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("deleting a missing route must succeed: %v", err)
+	}
+	if _, err := s.GetRoute("tech-1", serviceDate); !errors.Is(err, repository.ErrRouteNotFound) {
+		t.Fatalf("expected a deleted route reported as ErrRouteNotFound, got %v", err)
+	}
+	tombstones, err := s.ListDeletionsSince(since)
+	if err != nil || len(tombstones) != 1 || tombstones[0].Kind != models.TombstoneRoute || tombstones[0].TechnicianID != "tech-1" || tombstones[0].TenantID != "acme" {
+		t.Fatalf("expected one route tombstone, got %+v (%v)", tombstones, err)
+	}
+
+	if err := s.SaveRoute(route); err != nil {
+		t.Fatalf("restore route: %v", err)
+	}
+	if _, err := s.GetRoute("tech-1", serviceDate); err != nil {
+		t.Fatalf("expected saving a deleted route to restore it, got %v", err)
+	}
+	if tombstones, _ := s.ListDeletionsSince(since); len(tombstones) != 0 {
+		t.Fatalf("expected a restored route to leave no tombstone, got %+v", tombstones)
+	}
+}
+
+func testTemplates(t *testing.T, s Store) {
+	for _, tpl := range []models.ScreenTemplate{
+		{ID: "today", Version: 2, PayloadJSON: []byte(`{"v":2}`)},
+		{ID: "route", PayloadJSON: []byte(`{}`)},
+		{ID: "today", Version: 1, PayloadJSON: []byte(`{"v":1}`)},
+	} {
+		if err := s.SaveTemplate(tpl); err != nil {
+			t.Fatalf("save template: %v", err)
+		}
+	}
+	got, err := s.GetTemplate("route", 1)
+	if err != nil || string(got.PayloadJSON) != `{}` || got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+		t.Fatalf("expected an unversioned template saved as version 1, got %+v (%v)", got, err)
+	}
+	list, err := s.ListTemplates()
+	if err != nil || len(list) != 3 || list[0].ID != "route" || list[1].Version != 1 || list[2].Version != 2 {
+		t.Fatalf("expected templates ordered by ID and version, got %+v (%v)", list, err)
+	}
+	if err := s.DeleteTemplate("today", 1); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if err := s.DeleteTemplate("today", 9); err != nil {
+		t.Fatalf("deleting a missing template must succeed: %v", err)
+	}
+	if _, err := s.GetTemplate("today", 1); !errors.Is(err, repository.ErrTemplateNotFound) {
+		t.Fatalf("expected a deleted template reported as ErrTemplateNotFound, got %v", err)
+	}
+	if kept, err := s.GetTemplate("today", 2); err != nil || string(kept.PayloadJSON) != `{"v":2}` {
+		t.Fatalf("expected other versions kept, got %+v (%v)", kept, err)
+	}
+}
+
+func testPendingUploads(t *testing.T, s Store) {
+	for _, id := range []string{"job-b", "job-a", "job-c"} {
+		if err := s.SaveJobUpload(models.JobUpload{ID: id, TechnicianID: "tech-1"}); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	jobs, err := s.ListPendingJobs(2)
+	if err != nil || len(jobs) != 2 || jobs[0].ID != "job-b" || jobs[1].ID != "job-a" || jobs[0].ReceivedAt.IsZero() {
+		t.Fatalf("expected the oldest two jobs in arrival order, got %+v (%v)", jobs, err)
+	}
+	if all, err := s.ListPendingJobs(0); err != nil || len(all) != 3 {
+		t.Fatalf("expected every job without a limit, got %d (%v)", len(all), err)
+	}
+
+	chem := models.ChemicalUpload{ID: "chem-1", Name: "Termidor", Concentration: 9.1, Lots: []models.ChemicalLot{{Number: "L-1", Quantity: 2}}}
+	if err := s.SaveChemicalUpload(chem); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	chems, err := s.ListPendingChemicals(0)
+	if err != nil || len(chems) != 1 || chems[0].Name != "Termidor" || len(chems[0].Lots) != 1 {
+		t.Fatalf("unexpected pending chemicals %+v (%v)", chems, err)
+	}
+	weather := &models.WeatherReading{Source: "nws", TemperatureC: 21.5, WindSpeedKPH: 12, Summary: "Sunny", At: time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)}
+	if err := s.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", ChemicalID: "chem-1", QuantityUsed: 2.5, Weather: weather}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	treatments, err := s.ListPendingTreatments(0)
+	if err != nil || len(treatments) != 1 || treatments[0].QuantityUsed != 2.5 {
+		t.Fatalf("unexpected pending treatments %+v (%v)", treatments, err)
+	}
+	if got := treatments[0].Weather; got == nil || got.WindSpeedKPH != 12 || got.Summary != "Sunny" || !got.At.Equal(weather.At) {
+		t.Fatalf("expected the fetched weather round-tripped, got %+v", got)
+	}
+}
+
+func testUpdatesSince(t *testing.T, s Store) {
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-old", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	since := mark()
+	for _, job := range []models.JobUpload{{ID: "job-1", CustomerName: "First", SignatureID: "sig-1"}, {ID: "job-2", Latitude: 30.2672, Longitude: -97.7431}, {ID: "job-1", CustomerName: "Second"}} {
+		if err := s.SaveJobUpload(job); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	jobs, err := s.ListJobUpdatesSince(since)
+	if err != nil || len(jobs) != 2 || jobs[0].ID != "job-2" || jobs[1].ID != "job-1" || jobs[1].CustomerName != "Second" {
+		t.Fatalf("expected the latest version of each job since the mark, oldest first, got %+v (%v)", jobs, err)
+	}
+	if !jobs[0].ReceivedAt.After(since) || jobs[1].ReceivedAt.Before(jobs[0].ReceivedAt) {
+		t.Fatalf("expected ReceivedAt set to the write time, got %+v", jobs)
+	}
+	if jobs[0].Latitude != 30.2672 || jobs[0].Longitude != -97.7431 || jobs[1].HasCoordinates() {
+		t.Fatalf("expected job coordinates kept, got %+v", jobs)
+	}
+	if jobs[1].SignatureID != "sig-1" || jobs[0].SignatureID != "" {
+		t.Fatalf("expected a re-uploaded job to keep its signature, got %+v", jobs)
+	}
+
+	if err := s.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: serviceDate}); err != nil {
+		t.Fatalf("save route: %v", err)
+	}
+	routes, err := s.ListRouteUpdatesSince(since)
+	if err != nil || len(routes) != 1 || !routes[0].LastModified.After(since) {
+		t.Fatalf("expected one route update stamped with its write time, got %+v (%v)", routes, err)
+	}
+	if err := s.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"}); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	chems, err := s.ListChemicalUpdatesSince(since)
+	if err != nil || len(chems) != 1 || !chems[0].LastModified.After(since) {
+		t.Fatalf("expected one chemical update stamped with its write time, got %+v (%v)", chems, err)
+	}
+	if err := s.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", QuantityUsed: 1}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	treatments, err := s.ListTreatmentUpdatesSince(since)
+	if err != nil || len(treatments) != 1 || !treatments[0].LastModified.After(since) {
+		t.Fatalf("expected one treatment update stamped with its write time, got %+v (%v)", treatments, err)
+	}
+	if later, err := s.ListJobUpdatesSince(mark()); err != nil || len(later) != 0 {
+		t.Fatalf("expected no updates after the last write, got %+v (%v)", later, err)
+	}
+}
+
+func testUpdatePages(t *testing.T, s Store) {
+	for _, id := range []string{"job-b", "job-a", "job-d", "job-c"} {
+		if err := s.SaveJobUpload(models.JobUpload{ID: id, TechnicianID: "tech-1"}); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.DeleteJob("job-d"); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+	first, err := s.ListJobUpdates(repository.Page{Limit: 2})
+	if err != nil || len(first) != 2 || first[0].ID != "job-b" || first[1].ID != "job-a" || first[0].ReceivedAt.IsZero() {
+		t.Fatalf("expected the oldest two jobs stamped with their write time, got %+v (%v)", first, err)
+	}
+	rest, err := s.ListJobUpdates(repository.Page{Limit: 2}.Next(first[1].ReceivedAt, first[1].ID))
+	if err != nil || len(rest) != 1 || rest[0].ID != "job-c" {
+		t.Fatalf("expected the next page to skip the deleted job, got %+v (%v)", rest, err)
+	}
+
+	for _, day := range []time.Time{serviceDate, serviceDate.AddDate(0, 0, 1)} {
+		if err := s.SaveRoute(models.Route{TechnicianID: "tech-1", ServiceDate: day}); err != nil {
+			t.Fatalf("save route: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	routes, err := s.ListRouteUpdates(repository.Page{Limit: 1})
+	if err != nil || len(routes) != 1 || !routes[0].ServiceDate.Equal(serviceDate) {
+		t.Fatalf("expected the first route, got %+v (%v)", routes, err)
+	}
+	if routes, err := s.ListRouteUpdates(repository.Page{}.Next(routes[0].LastModified, routes[0].ServerID())); err != nil || len(routes) != 1 || routes[0].ServiceDate.Equal(serviceDate) {
+		t.Fatalf("expected the second route after the first, got %+v (%v)", routes, err)
+	}
+
+	if err := s.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"}); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	if chems, err := s.ListChemicalUpdates(repository.Page{}); err != nil || len(chems) != 1 || chems[0].LastModified.IsZero() {
+		t.Fatalf("expected the chemical stamped with its write time, got %+v (%v)", chems, err)
+	}
+	if err := s.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", QuantityUsed: 1}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	if treatments, err := s.ListTreatmentUpdates(repository.Page{}); err != nil || len(treatments) != 1 || treatments[0].LastModified.IsZero() {
+		t.Fatalf("expected the treatment stamped with its write time, got %+v (%v)", treatments, err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := s.DeleteChemical("chem-1"); err != nil {
+		t.Fatalf("delete chemical: %v", err)
+	}
+	tombstones, err := s.ListDeletions(repository.Page{Limit: 1})
+	if err != nil || len(tombstones) != 1 || tombstones[0].ID != "job-d" {
+		t.Fatalf("expected the job's tombstone first, got %+v (%v)", tombstones, err)
+	}
+	tombstones, err = s.ListDeletions(repository.Page{Limit: 1}.Next(tombstones[0].DeletedAt, tombstones[0].ID))
+	if err != nil || len(tombstones) != 1 || tombstones[0].ID != "chem-1" || tombstones[0].Kind != models.TombstoneChemical {
+		t.Fatalf("expected the chemical's tombstone next, got %+v (%v)", tombstones, err)
+	}
+}
+
+func testUnprocessedUploads(t *testing.T, s Store) {
+	for _, id := range []string{"job-b", "job-a", "job-c"} {
+		if err := s.SaveJobUpload(models.JobUpload{ID: id, TechnicianID: "tech-1"}); err != nil {
+			t.Fatalf("save job: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	first, err := s.ListUnprocessedJobs(repository.Page{Limit: 2})
+	if err != nil || len(first) != 2 || first[0].ID != "job-b" || first[1].ID != "job-a" {
+		t.Fatalf("expected the oldest two jobs, got %+v (%v)", first, err)
+	}
+	rest, err := s.ListUnprocessedJobs(repository.Page{Limit: 2}.Next(first[1].ReceivedAt, first[1].ID))
+	if err != nil || len(rest) != 1 || rest[0].ID != "job-c" {
+		t.Fatalf("expected the next page to hold the last job, got %+v (%v)", rest, err)
+	}
+
+	if err := s.MarkProcessed(repository.UploadJob, "job-b", first[0].ReceivedAt); err != nil {
+		t.Fatalf("mark processed: %v", err)
+	}
+	if err := s.MarkProcessed(repository.UploadJob, "job-a", first[1].ReceivedAt.Add(-time.Millisecond)); err != nil {
+		t.Fatalf("mark processed: %v", err)
+	}
+	if err := s.DeleteJob("job-c"); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+	jobs, err := s.ListUnprocessedJobs(repository.Page{})
+	if err != nil || len(jobs) != 1 || jobs[0].ID != "job-a" {
+		t.Fatalf("expected processed, deleted and stale marks left out, got %+v (%v)", jobs, err)
+	}
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-b", TechnicianID: "tech-1", CustomerName: "Changed"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	if jobs, err := s.ListUnprocessedJobs(repository.Page{}); err != nil || len(jobs) != 2 || jobs[1].ID != "job-b" {
+		t.Fatalf("expected a saved job unprocessed again, got %+v (%v)", jobs, err)
+	}
+
+	if err := s.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", Name: "Termidor"}); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	chems, err := s.ListUnprocessedChemicals(repository.Page{})
+	if err != nil || len(chems) != 1 || chems[0].LastModified.IsZero() {
+		t.Fatalf("expected the chemical stamped with its write time, got %+v (%v)", chems, err)
+	}
+	if err := s.MarkProcessed(repository.UploadChemical, "chem-1", chems[0].LastModified); err != nil {
+		t.Fatalf("mark processed: %v", err)
+	}
+	if chems, err := s.ListUnprocessedChemicals(repository.Page{}); err != nil || len(chems) != 0 {
+		t.Fatalf("expected no unprocessed chemicals, got %+v (%v)", chems, err)
+	}
+
+	if err := s.SaveChemicalTreatment(models.ChemicalTreatmentUpload{ID: "t-1", QuantityUsed: 1}); err != nil {
+		t.Fatalf("save treatment: %v", err)
+	}
+	treatments, err := s.ListUnprocessedTreatments(repository.Page{})
+	if err != nil || len(treatments) != 1 {
+		t.Fatalf("expected one unprocessed treatment, got %+v (%v)", treatments, err)
+	}
+	if err := s.MarkProcessed(repository.UploadTreatment, "t-1", treatments[0].LastModified); err != nil {
+		t.Fatalf("mark processed: %v", err)
+	}
+	if treatments, err := s.ListUnprocessedTreatments(repository.Page{}); err != nil || len(treatments) != 0 {
+		t.Fatalf("expected no unprocessed treatments, got %+v (%v)", treatments, err)
+	}
+
+	for _, route := range []models.Route{{TechnicianID: "tech-1", ServiceDate: serviceDate}, {ID: "route-2", TechnicianID: "tech-2", ServiceDate: serviceDate}} {
+		if err := s.SaveRoute(route); err != nil {
+			t.Fatalf("save route: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	routes, err := s.ListUnprocessedRoutes(repository.Page{Limit: 1})
+	if err != nil || len(routes) != 1 || routes[0].ServerID() != "tech-1_"+serviceDate.Format("2006-01-02") {
+		t.Fatalf("expected the oldest route, got %+v (%v)", routes, err)
+	}
+	if err := s.MarkProcessed(repository.UploadRoute, routes[0].ServerID(), routes[0].LastModified); err != nil {
+		t.Fatalf("mark processed: %v", err)
+	}
+	if routes, err := s.ListUnprocessedRoutes(repository.Page{}); err != nil || len(routes) != 1 || routes[0].ID != "route-2" {
+		t.Fatalf("expected only the unprocessed route, got %+v (%v)", routes, err)
+	}
+}
+
+// testTreatmentScan checks the optional repository.TreatmentScanner.
+func testTreatmentScan(t *testing.T, s Store) {
+	scanner, ok := s.(repository.TreatmentScanner)
+	if !ok {
+		t.Skip("store does not implement repository.TreatmentScanner")
+	}
+	for _, tr := range []models.ChemicalTreatmentUpload{
+		{ID: "t-1", ApplicationDate: serviceDate.Add(2 * time.Hour), QuantityUsed: 1},
+		{ID: "t-2", ApplicationDate: serviceDate, QuantityUsed: 2},
+		{ID: "t-3", ApplicationDate: serviceDate.Add(24 * time.Hour), QuantityUsed: 3},
+		{ID: "t-1", ApplicationDate: serviceDate.Add(2 * time.Hour), QuantityUsed: 4},
+	} {
+		if err := s.SaveChemicalTreatment(tr); err != nil {
+			t.Fatalf("save treatment: %v", err)
+		}
+	}
+	var got []models.ChemicalTreatmentUpload
+	err := scanner.ScanTreatments(serviceDate, serviceDate.Add(24*time.Hour), func(tr models.ChemicalTreatmentUpload) error {
+		got = append(got, tr)
+		return nil
+	})
+	if err != nil || len(got) != 2 || got[0].ID != "t-2" || got[1].ID != "t-1" || got[1].QuantityUsed != 4 {
+		t.Fatalf("expected the latest version of the treatments applied in the range, in application order, got %+v (%v)", got, err)
+	}
+	calls := 0
+	err = scanner.ScanTreatments(serviceDate, serviceDate.Add(48*time.Hour), func(models.ChemicalTreatmentUpload) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Fatalf("expected the scan to stop at fn's error, got %d calls (%v)", calls, err)
+	}
+}
+
+func testSoftDeletes(t *testing.T, s Store) {
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-1", TenantID: "acme", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	if err := s.SaveChemicalUpload(models.ChemicalUpload{ID: "chem-1", TechnicianID: "tech-2", Name: "Termidor"}); err != nil {
+		t.Fatalf("save chemical: %v", err)
+	}
+	if job, err := s.GetJobUpload("job-1"); err != nil || job.TechnicianID != "tech-1" || job.ReceivedAt.IsZero() {
+		t.Fatalf("expected the saved job with its write time, got %+v (%v)", job, err)
+	}
+	since := mark()
+	for _, del := range []func() error{
+		func() error { return s.DeleteJob("job-1") },
+		func() error { return s.DeleteChemical("chem-1") },
+		func() error { return s.DeleteJob("job-1") },
+		func() error { return s.DeleteJob("missing") },
+		func() error { return s.DeleteChemical("missing") },
+	} {
+		if err := del(); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if jobs, _ := s.ListJobUpdatesSince(time.Time{}); len(jobs) != 0 {
+		t.Fatalf("expected a deleted job out of the deltas, got %+v", jobs)
+	}
+	if chems, _ := s.ListChemicalUpdatesSince(time.Time{}); len(chems) != 0 {
+		t.Fatalf("expected a deleted chemical out of the deltas, got %+v", chems)
+	}
+	tombstones, err := s.ListDeletionsSince(since)
+	if err != nil || len(tombstones) != 2 {
+		t.Fatalf("expected two tombstones, got %+v (%v)", tombstones, err)
+	}
+	if tombstones[0].Kind != models.TombstoneJob || tombstones[0].ID != "job-1" || tombstones[0].TechnicianID != "tech-1" || tombstones[0].TenantID != "acme" ||
+		tombstones[1].Kind != models.TombstoneChemical || tombstones[1].ID != "chem-1" || tombstones[1].TechnicianID != "tech-2" {
+		t.Fatalf("expected tombstones oldest first with their technicians, got %+v", tombstones)
+	}
+	if pending, _ := s.ListPendingJobs(0); len(pending) != 0 {
+		t.Fatalf("expected a deleted job out of the pending queue, got %+v", pending)
+	}
+	if pending, _ := s.ListPendingChemicals(0); len(pending) != 0 {
+		t.Fatalf("expected a deleted chemical out of the pending queue, got %+v", pending)
+	}
+	if all, err := s.ListAllJobs(); err != nil || len(all) != 1 || all[0].ID != "job-1" {
+		t.Fatalf("expected a deleted job still listed with all jobs, got %+v (%v)", all, err)
+	}
+	if all, err := s.ListAllChemicals(); err != nil || len(all) != 1 || all[0].ID != "chem-1" {
+		t.Fatalf("expected a deleted chemical still listed with all chemicals, got %+v (%v)", all, err)
+	}
+	for _, id := range []string{"job-1", "missing"} {
+		if _, err := s.GetJobUpload(id); !errors.Is(err, repository.ErrJobNotFound) {
+			t.Fatalf("expected %s not found, got %v", id, err)
+		}
+	}
+
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("restore job: %v", err)
+	}
+	if jobs, _ := s.ListJobUpdatesSince(since); len(jobs) != 1 {
+		t.Fatalf("expected saving a deleted job to restore it, got %+v", jobs)
+	}
+	if _, err := s.GetJobUpload("job-1"); err != nil {
+		t.Fatalf("expected a restored job found, got %v", err)
+	}
+	if pending, _ := s.ListPendingJobs(0); len(pending) == 0 {
+		t.Fatal("expected a restored job back in the pending queue")
+	}
+	if n, err := s.PruneTombstones(time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected the chemical's tombstone pruned, got %d (%v)", n, err)
+	}
+	if tombstones, _ := s.ListDeletionsSince(time.Time{}); len(tombstones) != 0 {
+		t.Fatalf("expected no tombstones after pruning, got %+v", tombstones)
+	}
+}
+
+func testBatches(t *testing.T, s Store) {
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-1", TechnicianID: "tech-1", SignatureID: "sig-1"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	if err := s.SaveJobUpload(models.JobUpload{ID: "job-2", TechnicianID: "tech-1"}); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	err := s.SaveBatch(repository.Batch{
+		Routes:      []models.Route{{TechnicianID: "tech-1", ServiceDate: serviceDate, CustomerStops: []models.RouteStop{{CustomerID: "c1", CustomerName: "Smith"}}}},
+		Jobs:        []models.JobUpload{{ID: "job-1", TechnicianID: "tech-1", CustomerName: "Smith"}},
+		Treatments:  []models.ChemicalTreatmentUpload{{ID: "t-1", JobID: "job-1", ChemicalID: "chem-1"}},
+		DeletedJobs: []string{"job-2", "missing"},
+	})
+	if err != nil {
+		t.Fatalf("save batch: %v", err)
+	}
+	if r, err := s.GetRoute("tech-1", serviceDate); err != nil || len(r.CustomerStops) != 1 || r.CustomerStops[0].CustomerName != "Smith" {
+		t.Fatalf("expected the batch's route saved, got %+v (%v)", r, err)
+	}
+	if j, err := s.GetJobUpload("job-1"); err != nil || j.CustomerName != "Smith" || j.SignatureID != "sig-1" {
+		t.Fatalf("expected the batch's job saved keeping its signature, got %+v (%v)", j, err)
+	}
+	if ts, _ := s.ListTreatmentUpdatesSince(time.Time{}); len(ts) != 1 || ts[0].JobID != "job-1" {
+		t.Fatalf("expected the batch's treatment saved, got %+v", ts)
+	}
+	if _, err := s.GetJobUpload("job-2"); !errors.Is(err, repository.ErrJobNotFound) {
+		t.Fatalf("expected the batch's job deleted, got %v", err)
+	}
+	if tombstones, _ := s.ListDeletionsSince(time.Time{}); len(tombstones) != 1 || tombstones[0].ID != "job-2" {
+		t.Fatalf("expected a tombstone for the deleted job only, got %+v", tombstones)
+	}
+	if err := s.SaveBatch(repository.Batch{}); err != nil {
+		t.Fatalf("expected an empty batch to succeed, got %v", err)
+	}
+}
+
+func testDeviceTokens(t *testing.T, s Store) {
+	older := time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond)
+	for _, d := range []models.DeviceToken{
+		{Token: "abc", TechnicianID: "tech-1", Platform: "android"},
+		{Token: "def", TechnicianID: "tech-1", Platform: "ios", RegisteredAt: older},
+		{Token: "ghi", TechnicianID: "tech-2"},
+		{Token: "abc", TechnicianID: "tech-1", Platform: "ios"},
+	} {
+		if err := s.SaveDeviceToken(d); err != nil {
+			t.Fatalf("save device token: %v", err)
+		}
+	}
+	tokens, err := s.ListDeviceTokens("tech-1")
+	if err != nil || len(tokens) != 2 || tokens[0].Token != "abc" || tokens[0].Platform != "ios" || !tokens[1].RegisteredAt.Equal(older) {
+		t.Fatalf("expected the upserted registrations, most recent first, got %+v (%v)", tokens, err)
+	}
+	if err := s.DeleteDeviceToken("missing"); err != nil {
+		t.Fatalf("deleting a missing token must succeed: %v", err)
+	}
+	if n, err := s.PruneDeviceTokens(time.Now().Add(-time.Minute)); err != nil || n != 1 {
+		t.Fatalf("expected the stale registration pruned, got %d (%v)", n, err)
+	}
+	if err := s.DeleteDeviceToken("abc"); err != nil {
+		t.Fatalf("delete device token: %v", err)
+	}
+	if tokens, _ := s.ListDeviceTokens("tech-1"); len(tokens) != 0 {
+		t.Fatalf("expected no registrations left, got %+v", tokens)
+	}
+}